@@ -13,6 +13,7 @@ import (
 	grpc_logrus "github.com/grpc-ecosystem/go-grpc-middleware/logging/logrus"
 	"github.com/sirupsen/logrus"
 	"google.golang.org/grpc/codes"
+	grpcmd "google.golang.org/grpc/metadata"
 	"google.golang.org/grpc/reflection"
 
 	"github.com/joho/godotenv"
@@ -592,9 +593,27 @@ func (serv *MetadataServer) CreateSourceVariant(ctx context.Context, source *pb.
 				qry = afterSplit[1]
 			}
 			source.Definition.(*pb.SourceVariant_Transformation).Transformation.Type.(*pb.Transformation_SQLTransformation).SQLTransformation.Source = sources
+		case *pb.Transformation_StreamingTransformation:
+			sources, err := metadata.SQLTemplateSources(transformationType.StreamingTransformation.Query)
+			if err != nil {
+				return nil, err
+			}
+			transformationType.StreamingTransformation.Source = sources
 		}
 	}
-	return serv.meta.CreateSourceVariant(ctx, source)
+	var header grpcmd.MD
+	resp, err := serv.meta.CreateSourceVariant(ctx, source, grpc.Header(&header))
+	forwardHeader(ctx, header)
+	return resp, err
+}
+
+// forwardHeader passes the metadata server's response header, such as the
+// name of a generated variant, on to the client.
+func forwardHeader(ctx context.Context, header grpcmd.MD) {
+	if len(header) == 0 {
+		return
+	}
+	grpc.SetHeader(ctx, header)
 }
 
 func (serv *MetadataServer) CreateEntity(ctx context.Context, entity *pb.Entity) (*pb.Empty, error) {
@@ -607,9 +626,22 @@ func (serv *MetadataServer) RequestScheduleChange(ctx context.Context, req *pb.S
 	return serv.meta.RequestScheduleChange(ctx, req)
 }
 
+func (serv *MetadataServer) SetFeatureApproval(ctx context.Context, req *pb.FeatureApprovalRequest) (*pb.Empty, error) {
+	serv.Logger.Infow("Setting Feature Approval", "feature", req.Feature, "state", req.State, "user", req.User)
+	return serv.meta.SetFeatureApproval(ctx, req)
+}
+
+func (serv *MetadataServer) BulkCreate(ctx context.Context, req *pb.BulkCreateRequest) (*pb.BulkCreateResponse, error) {
+	serv.Logger.Infow("Bulk Creating Resources", "count", len(req.Resources), "validate_only", req.ValidateOnly)
+	return serv.meta.BulkCreate(ctx, req)
+}
+
 func (serv *MetadataServer) CreateFeatureVariant(ctx context.Context, feature *pb.FeatureVariant) (*pb.Empty, error) {
 	serv.Logger.Infow("Creating Feature Variant", "name", feature.Name, "variant", feature.Variant)
-	return serv.meta.CreateFeatureVariant(ctx, feature)
+	var header grpcmd.MD
+	resp, err := serv.meta.CreateFeatureVariant(ctx, feature, grpc.Header(&header))
+	forwardHeader(ctx, header)
+	return resp, err
 }
 
 func (serv *MetadataServer) CreateLabelVariant(ctx context.Context, label *pb.LabelVariant) (*pb.Empty, error) {
@@ -622,7 +654,9 @@ func (serv *MetadataServer) CreateLabelVariant(ctx context.Context, label *pb.La
 		return nil, err
 	}
 	label.Provider = source.Provider()
-	resp, err := serv.meta.CreateLabelVariant(ctx, label)
+	var header grpcmd.MD
+	resp, err := serv.meta.CreateLabelVariant(ctx, label, grpc.Header(&header))
+	forwardHeader(ctx, header)
 	serv.Logger.Debugw("Created label variant", "response", resp)
 	if err != nil {
 		serv.Logger.Errorw("Could not create label variant", "response", resp, "error", err)
@@ -644,7 +678,10 @@ func (serv *MetadataServer) CreateTrainingSetVariant(ctx context.Context, train
 		}
 	}
 	train.Provider = label.Provider()
-	return serv.meta.CreateTrainingSetVariant(ctx, train)
+	var header grpcmd.MD
+	resp, err := serv.meta.CreateTrainingSetVariant(ctx, train, grpc.Header(&header))
+	forwardHeader(ctx, header)
+	return resp, err
 }
 
 func (serv *MetadataServer) CreateModel(ctx context.Context, model *pb.Model) (*pb.Empty, error) {
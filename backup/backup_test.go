@@ -9,6 +9,7 @@ import (
 	"go.etcd.io/etcd/api/v3/mvccpb"
 	clientv3 "go.etcd.io/etcd/client/v3"
 	"io/ioutil"
+	"os"
 	"testing"
 	"time"
 )
@@ -23,7 +24,25 @@ func TestGenerateSnapshotName(t *testing.T) {
 	}
 }
 
+// inTempDir runs the rest of the test in a temporary directory, so the
+// snapshots and uploads it writes to relative paths aren't left in the tree.
+func inTempDir(t *testing.T) {
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatalf("Could not get working directory: %v", err)
+	}
+	if err := os.Chdir(t.TempDir()); err != nil {
+		t.Fatalf("Could not change to temporary directory: %v", err)
+	}
+	t.Cleanup(func() {
+		if err := os.Chdir(wd); err != nil {
+			t.Fatalf("Could not restore working directory: %v", err)
+		}
+	})
+}
+
 func TestBackup_Save(t *testing.T) {
+	inTempDir(t)
 	emptyClient := myClient{}
 
 	type fields struct {
@@ -89,6 +108,7 @@ func (c myClient) Delete(ctx context.Context, key string, opts ...clientv3.OpOpt
 }
 
 func TestBackup_takeSnapshot(t *testing.T) {
+	inTempDir(t)
 
 	client := myClient{}
 
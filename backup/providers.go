@@ -2,13 +2,11 @@ package backup
 
 import (
 	"bytes"
-	"context"
 	"encoding/json"
 	"fmt"
 
 	"github.com/featureform/provider"
 	pc "github.com/featureform/provider/provider_config"
-	"gocloud.dev/gcp"
 )
 
 type Provider interface {
@@ -145,29 +143,14 @@ type GCS struct {
 	store                  provider.FileStore
 }
 
-func (g *GCS) getDefaultCredentials() ([]byte, error) {
-	if creds, err := gcp.DefaultCredentials(context.Background()); err != nil {
-		return nil, err
-	} else {
-		return creds.JSON, nil
-	}
-}
-
+// checkEmptyCredentials returns the service account key, which is empty if
+// there isn't one and the file store uses Application Default Credentials.
 func (g *GCS) checkEmptyCredentials() (map[string]interface{}, error) {
-	var serializedCreds []byte
 	creds := make(map[string]interface{})
-
 	if bytes.Equal(g.Credentials, []byte("")) {
-		var err error
-		serializedCreds, err = g.getDefaultCredentials()
-		if err != nil {
-			return nil, fmt.Errorf("could not get default credentials: %v", err)
-		}
-	} else {
-		serializedCreds = g.Credentials
+		return creds, nil
 	}
-
-	err := json.Unmarshal(serializedCreds, &creds)
+	err := json.Unmarshal(g.Credentials, &creds)
 	if err != nil {
 		return nil, fmt.Errorf("could not deserialize credentials: %v", err)
 	}
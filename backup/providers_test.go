@@ -35,6 +35,7 @@ func TestLocalInit(t *testing.T) {
 }
 
 func TestLocalUpload(t *testing.T) {
+	inTempDir(t)
 	type fields struct {
 		Path  string
 		store provider.FileStore
@@ -119,6 +120,7 @@ func TestAzure_Upload(t *testing.T) {
 	if testing.Short() {
 		t.Skip()
 	}
+	inTempDir(t)
 	_ = godotenv.Load(".env")
 	type fields struct {
 		AzureStorageAccount string
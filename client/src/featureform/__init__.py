@@ -21,6 +21,7 @@ from .serving import ServingClient
 from .resources import (
     DatabricksCredentials,
     EMRCredentials,
+    EMRServerlessCredentials,
     AWSCredentials,
     GCPCredentials,
     SparkCredentials,
@@ -35,6 +36,7 @@ Client = Client
 # Executor Credentials
 DatabricksCredentials = DatabricksCredentials
 EMRCredentials = EMRCredentials
+EMRServerlessCredentials = EMRServerlessCredentials
 SparkCredentials = SparkCredentials
 
 # Cloud Provider Credentials
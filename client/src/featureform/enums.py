@@ -96,6 +96,8 @@ class SourceType(str, Enum):
     DIRECTORY = "DIRECTORY"
     DF_TRANSFORMATION = "DF"
     SQL_TRANSFORMATION = "SQL"
+    DBT_TRANSFORMATION = "DBT"
+    STREAMING_TRANSFORMATION = "STREAMING"
 
 
 @typechecked
@@ -0,0 +1,42 @@
+import json
+
+import pyarrow as pa
+import pyarrow.flight as flight
+
+
+class FlightReader:
+    """Reads training sets and materializations from the Arrow Flight endpoint
+    of a Featureform serving instance, which returns them as columnar batches
+    instead of one row at a time.
+
+    **Examples**:
+    ``` py
+        reader = FlightReader("grpc://localhost:8815")
+        df = reader.training_set("fraud_training", "quickstart").to_pandas()
+    ```
+    """
+
+    def __init__(self, location: str):
+        """
+        Args:
+            location (str): The Flight endpoint, like grpc://host:port, or
+                grpc+tls://host:port if it's served with TLS.
+        """
+        self._client = flight.FlightClient(location)
+
+    def training_set(self, name: str, variant: str) -> pa.Table:
+        """Returns a training set, with a column for each feature and its label."""
+        return self._read("training_set", name, variant)
+
+    def materialization(self, name: str, variant: str) -> pa.Table:
+        """Returns a feature's materialized entity, value and ts columns."""
+        return self._read("materialization", name, variant)
+
+    def _read(self, resource_type: str, name: str, variant: str) -> pa.Table:
+        return self._client.do_get(ticket(resource_type, name, variant)).read_all()
+
+
+def ticket(resource_type: str, name: str, variant: str) -> flight.Ticket:
+    return flight.Ticket(
+        json.dumps({"type": resource_type, "name": name, "variant": variant}).encode()
+    )
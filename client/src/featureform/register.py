@@ -5,7 +5,7 @@ from os.path import exists
 from datetime import timedelta
 
 from typeguard import typechecked
-from typing import Dict, Tuple, Callable, List, Union
+from typing import Dict, Tuple, Callable, List, Union, Optional
 import warnings
 import inspect
 from pathlib import Path
@@ -52,6 +52,9 @@ from .resources import (
     Directory,
     SQLTransformation,
     DFTransformation,
+    DBTTransformation,
+    dbt_transformation_definition,
+    StreamingTransformation,
     Entity,
     Feature,
     Label,
@@ -71,6 +74,8 @@ from .resources import (
     FilePrefix,
     OnDemandFeature,
     WeaviateConfig,
+    KafkaConfig,
+    KafkaTopic,
 )
 
 from .proto import metadata_pb2_grpc as ff_grpc
@@ -131,23 +136,45 @@ class OfflineSQLProvider(OfflineProvider):
         description: str = "",
         tags: List[str] = [],
         properties: dict = {},
+        schema: str = "",
+        database: str = "",
     ):
         """Register a SQL table as a primary data source.
 
+        Tables outside of the provider's database and schema are registered by
+        setting them, or by qualifying the table's name. In BigQuery, the schema
+        is the table's dataset and the database is its project. Postgres can only
+        read tables in other schemas of the provider's database.
+
+        **Examples**:
+        ```
+        transactions = snowflake.register_table(
+            name="transactions",
+            table="transactions",
+            schema="payments",
+            database="analytics",
+        )
+        ```
+
         Args:
             name (str): Name of table to be registered
             variant (str): Name of variant to be registered
             table (str): Name of SQL table
             owner (Union[str, UserRegistrar]): Owner
             description (str): Description of table to be registered
+            schema (str): Schema, or BigQuery dataset, of the table
+            database (str): Database, or BigQuery project, of the table
 
         Returns:
             source (ColumnSourceRegistrar): source
         """
+        if database and not schema:
+            raise ValueError("a table's schema must be set with its database")
+        qualified_table = ".".join(part for part in [database, schema, table] if part)
         return self.__registrar.register_primary_data(
             name=name,
             variant=variant,
-            location=SQLTable(table),
+            location=SQLTable(qualified_table),
             owner=owner,
             provider=self.name(),
             description=description,
@@ -224,7 +251,10 @@ class OfflineSparkProvider(OfflineProvider):
         owner: Union[str, UserRegistrar] = "",
         description: str = "",
     ):
-        if self.__provider.config.executor_type != "EMR" and file_path.startswith(
+        if self.__provider.config.executor_type not in (
+            "EMR",
+            "EMR_SERVERLESS",
+        ) and file_path.startswith(
             FilePrefix.S3.value
         ):
             file_path = file_path.replace(FilePrefix.S3.value, FilePrefix.S3A.value)
@@ -469,6 +499,73 @@ class OfflineK8sProvider(OfflineProvider):
         )
 
 
+class KafkaProvider:
+    def __init__(self, registrar, provider):
+        self.__registrar = registrar
+        self.__provider = provider
+
+    def name(self) -> str:
+        return self.__provider.name
+
+    def register_topic(
+        self,
+        name: str,
+        topic: str,
+        variant: str = "",
+        value_format: str = "json",
+        starting_offsets: str = "earliest",
+        owner: Union[str, UserRegistrar] = "",
+        description: str = "",
+        tags: List[str] = [],
+        properties: dict = {},
+    ):
+        """Register a Kafka topic as a primary data source. Topics can only be
+        read by streaming transformations, which keep the offsets they've read
+        up to in their metadata.
+
+        **Examples**:
+        ```
+        clicks = kafka.register_topic(
+            name="clicks",
+            topic="user-clicks",
+            value_format="avro",
+        )
+        ```
+
+        Args:
+            name (str): Name of source to be registered
+            topic (str): Name of Kafka topic
+            variant (str): Name of variant to be registered
+            value_format (str): Format of messages' values: "raw", "json" or "avro"
+            starting_offsets (str): Where streaming transformations start reading
+                the topic: "earliest", "latest" or the offsets of its partitions
+                as JSON
+            owner (Union[str, UserRegistrar]): Owner
+            description (str): Description of source to be registered
+            tags (List[str]): Optional grouping mechanism for resources
+            properties (dict): Optional grouping mechanism for resources
+
+        Returns:
+            source (ColumnSourceRegistrar): source
+        """
+        if value_format not in ("raw", "json", "avro"):
+            raise ValueError(f"Unsupported Kafka value format: {value_format}")
+        return self.__registrar.register_primary_data(
+            name=name,
+            variant=variant,
+            location=KafkaTopic(
+                topic=topic,
+                value_format=value_format,
+                starting_offsets=starting_offsets,
+            ),
+            owner=owner,
+            provider=self.name(),
+            description=description,
+            tags=tags,
+            properties=properties,
+        )
+
+
 class OnlineProvider:
     def __init__(self, registrar, provider):
         self.__registrar = registrar
@@ -2216,6 +2313,11 @@ class Registrar:
         team: str = "",
         tags: List[str] = [],
         properties: dict = {},
+        role_arn: str = "",
+        external_id: str = "",
+        access_point: str = "",
+        endpoint: str = "",
+        path_style: bool = False,
     ):
         """Register a S3 store provider.
 
@@ -2243,6 +2345,11 @@ class Registrar:
             team (str): the name of the team registering the filestore
             tags (List[str]): Optional grouping mechanism for resources
             properties (dict): Optional grouping mechanism for resources
+            role_arn (str): IAM role to assume to access buckets in other accounts
+            external_id (str): External ID the role's trust policy requires, if any
+            access_point (str): ARN of an S3 access point to access the bucket through
+            endpoint (str): Custom S3 endpoint URL, such as a VPC endpoint
+            path_style (bool): Address the bucket in the endpoint URL's path
         Returns:
             s3 (FileStoreProvider): Provider
                 has all the functionality of OfflineProvider
@@ -2253,6 +2360,11 @@ class Registrar:
             bucket_region=bucket_region,
             credentials=credentials,
             path=path,
+            role_arn=role_arn,
+            external_id=external_id,
+            access_point=access_point,
+            endpoint=endpoint,
+            path_style=path_style,
         )
 
         provider = Provider(
@@ -2804,6 +2916,77 @@ class Registrar:
         self.__resources.append(provider)
         return OfflineSQLProvider(self, provider)
 
+    def register_kafka(
+        self,
+        name: str,
+        brokers: List[str],
+        description: str = "",
+        team: str = "",
+        username: str = "",
+        password: str = "",
+        sasl_mechanism: str = "",
+        tls: bool = False,
+        schema_registry_url: str = "",
+        schema_registry_username: str = "",
+        schema_registry_password: str = "",
+        tags: List[str] = None,
+        properties: dict = None,
+    ):
+        """Register a Kafka provider. Its topics are registered as sources that
+        streaming transformations read from.
+
+        **Examples**:
+        ```
+        kafka = ff.register_kafka(
+            name="kafka-quickstart",
+            brokers=["kafka:9092"],
+            username="featureform",
+            password="password", #pragma: allowlist secret
+            schema_registry_url="https://schema-registry:8081",
+        )
+        ```
+        Args:
+            name (str): Name of Kafka provider to be registered
+            brokers (List[str]): Addresses of the cluster's brokers
+            description (str): Description of Kafka provider to be registered
+            team (str): Name of team
+            username (str): SASL username, if the cluster requires authentication
+            password (str): SASL password
+            sasl_mechanism (str): "PLAIN", "SCRAM-SHA-256" or "SCRAM-SHA-512";
+                PLAIN if not set
+            tls (bool): Connect to the brokers with TLS
+            schema_registry_url (str): Schema registry of topics with Avro values
+            schema_registry_username (str): Schema registry basic auth username
+            schema_registry_password (str): Schema registry basic auth password
+            tags (List[str]): Optional grouping mechanism for resources
+            properties (dict): Optional grouping mechanism for resources
+
+        Returns:
+            kafka (KafkaProvider): Provider
+        """
+        config = KafkaConfig(
+            brokers=brokers,
+            username=username,
+            password=password,
+            sasl_mechanism=sasl_mechanism,
+            tls=tls,
+            schema_registry_url=schema_registry_url,
+            schema_registry_username=schema_registry_username,
+            schema_registry_password=schema_registry_password,
+        )
+        provider = Provider(
+            name=name,
+            function="OFFLINE",
+            description=description,
+            team=team,
+            config=config,
+            tags=tags or [],
+            properties=properties or {},
+        )
+
+        self.__resources.append(provider)
+        return KafkaProvider(self, provider)
+
     def register_redshift(
         self,
         name: str,
@@ -2816,6 +2999,10 @@ class Registrar:
         database: str = "dev",
         tags: List[str] = [],
         properties: dict = {},
+        cluster_identifier: str = "",
+        workgroup: str = "",
+        region: str = "",
+        credentials: Optional[AWSCredentials] = None,
     ):
         """Register a Redshift provider.
 
@@ -2842,12 +3029,27 @@ class Registrar:
             database (str): Database
             tags (List[str]): Optional grouping mechanism for resources
             properties (dict): Optional grouping mechanism for resources
+            cluster_identifier (str): Cluster to connect to with temporary IAM
+                credentials for the user, instead of the password
+            workgroup (str): Redshift Serverless workgroup to connect to with
+                temporary IAM credentials, instead of the user and password
+            region (str): AWS region of the cluster or workgroup
+            credentials (AWSCredentials): AWS credentials to get IAM credentials
+                with. Defaults to the AWS credentials of Featureform's deployment
 
         Returns:
             redshift (OfflineSQLProvider): Provider
         """
         config = RedshiftConfig(
-            host=host, port=port, database=database, user=user, password=password
+            host=host,
+            port=port,
+            database=database,
+            user=user,
+            password=password,
+            cluster_identifier=cluster_identifier,
+            workgroup=workgroup,
+            region=region,
+            credentials=credentials,
         )
         provider = Provider(
             name=name,
@@ -2890,6 +3092,7 @@ class Registrar:
             project_id (str): The Project name in GCP
             dataset_id (str): The Dataset name in GCP under the Project Id
             credentials_path (str): A path to a Google Credentials file with access permissions for BigQuery
+                If it's empty, Application Default Credentials, such as a GKE workload identity, are used
             tags (List[str]): Optional grouping mechanism for resources
             properties (dict): Optional grouping mechanism for resources
 
@@ -3138,6 +3341,158 @@ class Registrar:
         self.__resources.append(source)
         return ColumnSourceRegistrar(self, source)
 
+    def register_dbt_transformation(
+        self,
+        name: str,
+        project_dir: str,
+        model: str,
+        provider: Union[str, OfflineProvider],
+        variant: str = "",
+        owner: Union[str, UserRegistrar] = "",
+        description: str = "",
+        inputs: list = [],
+        profiles_dir: str = "",
+        target: str = "",
+        table: str = "",
+        schedule: str = "",
+        args: K8sArgs = None,
+        tags: List[str] = [],
+        properties: dict = {},
+    ):
+        """Register a dbt model as a transformation source. Featureform runs the
+        model with `dbt run`, then uses the table it builds as the source.
+
+        **Examples**:
+        ``` py
+        orders = ff.register_dbt_transformation(
+            name="orders",
+            project_dir="/dbt/shop",
+            model="orders",
+            provider=postgres,
+            inputs=[("transactions", "quickstart")],
+        )
+        ```
+
+        Args:
+            name (str): Name of source
+            project_dir (str): Path to the dbt project where the model is run
+            model (str): Name of the dbt model
+            provider (Union[str, OfflineProvider]): Provider the model builds in
+            variant (str): Name of variant
+            owner (Union[str, UserRegistrar]): Owner
+            description (str): Description of dbt transformation
+            inputs (list): Sources the model reads from, recorded as its lineage
+            profiles_dir (str): Directory of the dbt profiles.yml, if not the default
+            target (str): dbt target to run the model with
+            table (str): Table the model builds, if it isn't the model's name
+            schedule (str): Kubernetes CronJob schedule string ("* * * * *")
+            args (K8sArgs): Additional transformation arguments
+            tags (List[str]): Optional grouping mechanism for resources
+            properties (dict): Optional grouping mechanism for resources
+
+        Returns:
+            source (ColumnSourceRegistrar): Source
+        """
+        if not isinstance(owner, str):
+            owner = owner.name()
+        if owner == "":
+            owner = self.must_get_default_owner()
+        if variant == "":
+            variant = self.__run
+        if not isinstance(provider, str):
+            provider = provider.name()
+        inputs = [nv if isinstance(nv, tuple) else nv.name_variant() for nv in inputs]
+        source = Source(
+            name=name,
+            variant=variant,
+            definition=DBTTransformation(
+                project_dir=project_dir,
+                model=model,
+                inputs=inputs,
+                profiles_dir=profiles_dir,
+                target=target,
+                table=table,
+                args=args,
+            ),
+            owner=owner,
+            schedule=schedule,
+            provider=provider,
+            description=description,
+            tags=tags,
+            properties=properties,
+        )
+        self.__resources.append(source)
+        return ColumnSourceRegistrar(self, source)
+
+    def register_streaming_transformation(
+        self,
+        name: str,
+        query: str,
+        provider: Union[str, OfflineProvider],
+        variant: str = "",
+        owner: Union[str, UserRegistrar] = "",
+        description: str = "",
+        trigger: str = "",
+        args: K8sArgs = None,
+        tags: List[str] = [],
+        properties: dict = {},
+    ):
+        """Register a SQL query that runs continuously as a Spark Structured
+        Streaming job. Each micro-batch of the query's output is appended to
+        the transformation's table, and written to the online store of its
+        features once they're materialized. The job keeps running until it's
+        stopped.
+
+        **Examples**:
+        ``` py
+        clicks = ff.register_streaming_transformation(
+            name="user_clicks",
+            query="SELECT user, COUNT(*) AS clicks FROM {{ events.v1 }} GROUP BY user",
+            provider=spark,
+            trigger="1 minute",
+        )
+        ```
+
+        Args:
+            name (str): Name of source
+            query (str): SQL query run over the sources as they're appended to
+            provider (Union[str, OfflineProvider]): Spark provider the job runs on
+            variant (str): Name of variant
+            owner (Union[str, UserRegistrar]): Owner
+            description (str): Description of streaming transformation
+            trigger (str): How often micro-batches run (eg. "1 minute"); they run back to back if it's empty
+            args (K8sArgs): Additional transformation arguments
+            tags (List[str]): Optional grouping mechanism for resources
+            properties (dict): Optional grouping mechanism for resources
+
+        Returns:
+            source (ColumnSourceRegistrar): Source
+        """
+        if not isinstance(owner, str):
+            owner = owner.name()
+        if owner == "":
+            owner = self.must_get_default_owner()
+        if variant == "":
+            variant = self.__run
+        if not isinstance(provider, str):
+            provider = provider.name()
+        source = Source(
+            name=name,
+            variant=variant,
+            definition=StreamingTransformation(
+                query=query,
+                trigger=trigger,
+                args=args,
+            ),
+            owner=owner,
+            provider=provider,
+            description=description,
+            tags=tags,
+            properties=properties,
+        )
+        self.__resources.append(source)
+        return ColumnSourceRegistrar(self, source)
+
     def sql_transformation(
         self,
         provider: Union[str, OfflineProvider],
@@ -4476,6 +4831,15 @@ class ResourceClient:
     def _get_source_definition(self, source):
         if source.primaryData.table.name:
             return PrimaryData(SQLTable(source.primaryData.table.name))
+        elif source.primaryData.kafka_topic.topic:
+            topic = source.primaryData.kafka_topic
+            return PrimaryData(
+                KafkaTopic(
+                    topic=topic.topic,
+                    value_format=topic.value_format,
+                    starting_offsets=topic.starting_offsets,
+                )
+            )
         elif source.transformation:
             return self._get_transformation_definition(source)
         else:
@@ -4490,6 +4854,15 @@ class ResourceClient:
             )
         elif source.transformation.SQLTransformation.query != "":
             return SQLTransformation(source.transformation.SQLTransformation.query)
+        elif source.transformation.DBTTransformation.model != "":
+            return dbt_transformation_definition(
+                source.transformation.DBTTransformation
+            )
+        elif source.transformation.StreamingTransformation.query != "":
+            transformation = source.transformation.StreamingTransformation
+            return StreamingTransformation(
+                query=transformation.query, trigger=transformation.trigger
+            )
         else:
             raise Exception(f"Invalid transformation type {source}")
 
@@ -5293,6 +5666,7 @@ register_snowflake = global_registrar.register_snowflake
 register_snowflake_legacy = global_registrar.register_snowflake_legacy
 register_postgres = global_registrar.register_postgres
 register_redshift = global_registrar.register_redshift
+register_kafka = global_registrar.register_kafka
 register_spark = global_registrar.register_spark
 register_k8s = global_registrar.register_k8s
 register_s3 = global_registrar.register_s3
@@ -5305,6 +5679,8 @@ register_training_set = global_registrar.register_training_set
 register_model = global_registrar.register_model
 sql_transformation = global_registrar.sql_transformation
 register_sql_transformation = global_registrar.register_sql_transformation
+register_dbt_transformation = global_registrar.register_dbt_transformation
+register_streaming_transformation = global_registrar.register_streaming_transformation
 get_entity = global_registrar.get_entity
 get_source = global_registrar.get_source
 get_local_provider = global_registrar.get_local_provider
@@ -345,6 +345,34 @@ def test_register_table_offline_store(registrar):
     )
 
 
+@pytest.mark.parametrize(
+    "args,expected",
+    [
+        ({}, "name"),
+        ({"schema": "payments"}, "payments.name"),
+        ({"schema": "payments", "database": "analytics"}, "analytics.payments.name"),
+    ],
+)
+def test_register_table_qualified_name(registrar, args, expected):
+    user = registrar.register_user(**minimal_user_args)
+    postgres = registrar.register_postgres(**minimal_postgres_args)
+    postgres.register_table(
+        name="data", variant="var", table="name", owner=user, **args
+    )
+    sources = [
+        resource
+        for resource in registrar.state().sorted_list()
+        if isinstance(resource, Source)
+    ]
+    assert sources[0].definition == SQLTable(expected)
+
+
+def test_register_table_database_without_schema(registrar):
+    postgres = registrar.register_postgres(**minimal_postgres_args)
+    with pytest.raises(ValueError):
+        postgres.register_table(name="data", table="name", database="analytics")
+
+
 def name():
     """doc string"""
     return "query"
@@ -83,6 +83,40 @@ class RedisConfig:
         return bytes(json.dumps(config), "utf-8")
 
 
+@typechecked
+@dataclass
+class KafkaConfig:
+    brokers: List[str]
+    username: str = ""
+    password: str = ""
+    sasl_mechanism: str = ""
+    tls: bool = False
+    schema_registry_url: str = ""
+    schema_registry_username: str = ""
+    schema_registry_password: str = ""
+
+    def software(self) -> str:
+        return "kafka"
+
+    def type(self) -> str:
+        return "KAFKA"
+
+    def serialize(self) -> bytes:
+        config = {
+            "Brokers": self.brokers,
+            "Username": self.username,
+            "Password": self.password,
+            "Mechanism": self.sasl_mechanism,
+            "TLS": self.tls,
+            "SchemaRegistry": {
+                "URL": self.schema_registry_url,
+                "Username": self.schema_registry_username,
+                "Password": self.schema_registry_password,
+            },
+        }
+        return bytes(json.dumps(config), "utf-8")
+
+
 @typechecked
 @dataclass
 class PineconeConfig:
@@ -175,16 +209,20 @@ class GCPCredentials:
 
     Attributes:
         project_id (str): GCP Project ID
-        credentials_path (str): Path to GCP Credentials JSON file
+        credentials_path (str): Path to GCP Credentials JSON file. If it's empty,
+            Application Default Credentials, such as a GKE workload identity,
+            are used instead.
     """
 
     def __init__(
         self,
         project_id: str,
-        credentials_path: str,
+        credentials_path: str = "",
     ):
         self.project_id = project_id
-        self.credentials = json.load(open(credentials_path))
+        self.credentials = {}
+        if credentials_path:
+            self.credentials = json.load(open(credentials_path))
 
     def type(self):
         return "GCPCredentials"
@@ -272,16 +310,28 @@ class S3StoreConfig:
         bucket_region: str,
         credentials: AWSCredentials,
         path: str = "",
+        role_arn: str = "",
+        external_id: str = "",
+        access_point: str = "",
+        endpoint: str = "",
+        path_style: bool = False,
     ):
         bucket_path_ends_with_slash = len(bucket_path) != 0 and bucket_path[-1] == "/"
 
         if bucket_path_ends_with_slash:
             raise Exception("The 'bucket_path' cannot end with '/'.")
+        if external_id and not role_arn:
+            raise ValueError("An 'external_id' requires a 'role_arn' to assume.")
 
         self.bucket_path = bucket_path
         self.bucket_region = bucket_region
         self.credentials = credentials
         self.path = path
+        self.role_arn = role_arn
+        self.external_id = external_id
+        self.access_point = access_point
+        self.endpoint = endpoint
+        self.path_style = path_style
 
     def software(self) -> str:
         return "S3"
@@ -294,12 +344,21 @@ class S3StoreConfig:
         return bytes(json.dumps(config), "utf-8")
 
     def config(self):
-        return {
+        config = {
             "Credentials": self.credentials.config(),
             "BucketRegion": self.bucket_region,
             "BucketPath": self.bucket_path,
             "Path": self.path,
         }
+        if self.role_arn:
+            config["RoleARN"] = self.role_arn
+            config["ExternalID"] = self.external_id
+        if self.access_point:
+            config["AccessPoint"] = self.access_point
+        if self.endpoint:
+            config["Endpoint"] = self.endpoint
+            config["PathStyle"] = self.path_style
+        return config
 
     def store_type(self):
         return self.type()
@@ -560,6 +619,10 @@ class RedshiftConfig:
     database: str
     user: str
     password: str
+    cluster_identifier: str = ""
+    workgroup: str = ""
+    region: str = ""
+    credentials: Optional[AWSCredentials] = None
 
     def software(self) -> str:
         return "redshift"
@@ -574,7 +637,12 @@ class RedshiftConfig:
             "Username": self.user,
             "Password": self.password,
             "Database": self.database,
+            "ClusterIdentifier": self.cluster_identifier,
+            "Workgroup": self.workgroup,
+            "Region": self.region,
         }
+        if self.credentials is not None:
+            config["Credentials"] = self.credentials.config()
         return bytes(json.dumps(config), "utf-8")
 
 
@@ -583,7 +651,7 @@ class RedshiftConfig:
 class BigQueryConfig:
     project_id: str
     dataset_id: str
-    credentials_path: str
+    credentials_path: str = ""
 
     def software(self) -> str:
         return "bigquery"
@@ -592,10 +660,14 @@ class BigQueryConfig:
         return "BIGQUERY_OFFLINE"
 
     def serialize(self) -> bytes:
+        # Without a credentials file, Application Default Credentials are used.
+        credentials = {}
+        if self.credentials_path:
+            credentials = json.load(open(self.credentials_path))
         config = {
             "ProjectID": self.project_id,
             "DatasetID": self.dataset_id,
-            "Credentials": json.load(open(self.credentials_path)),
+            "Credentials": credentials,
         }
         return bytes(json.dumps(config), "utf-8")
 
@@ -631,6 +703,8 @@ class K8sResourceSpecs:
     cpu_limit: str = ""
     memory_request: str = ""
     memory_limit: str = ""
+    gpu_request: str = ""
+    architecture: str = ""
 
 
 @typechecked
@@ -648,6 +722,10 @@ class K8sArgs:
                 self.specs.memory_request
             )
             transformation.kubernetes_args.specs.memory_limit = self.specs.memory_limit
+            transformation.kubernetes_args.specs.gpu_request = self.specs.gpu_request
+            transformation.kubernetes_args.specs.architecture = (
+                self.specs.architecture
+            )
         return transformation
 
 
@@ -722,6 +800,7 @@ Config = Union[
     EmptyConfig,
     HDFSConfig,
     WeaviateConfig,
+    KafkaConfig,
 ]
 
 
@@ -866,7 +945,15 @@ class Directory:
     path: str
 
 
-Location = Union[SQLTable, Directory]
+@typechecked
+@dataclass
+class KafkaTopic:
+    topic: str
+    value_format: str = "json"
+    starting_offsets: str = "earliest"
+
+
+Location = Union[SQLTable, Directory, KafkaTopic]
 
 
 @typechecked
@@ -875,6 +962,16 @@ class PrimaryData:
     location: Location
 
     def kwargs(self):
+        if isinstance(self.location, KafkaTopic):
+            return {
+                "primaryData": pb.PrimaryData(
+                    kafka_topic=pb.KafkaTopic(
+                        topic=self.location.topic,
+                        value_format=self.location.value_format,
+                        starting_offsets=self.location.starting_offsets,
+                    ),
+                ),
+            }
         return {
             "primaryData": pb.PrimaryData(
                 table=pb.PrimarySQLTable(
@@ -940,6 +1037,73 @@ class DFTransformation(Transformation):
         return {"transformation": transformation}
 
 
+@typechecked
+@dataclass
+class DBTTransformation(Transformation):
+    project_dir: str
+    model: str
+    inputs: list
+    profiles_dir: str = ""
+    target: str = ""
+    table: str = ""
+    args: K8sArgs = None
+
+    def type(self):
+        return SourceType.DBT_TRANSFORMATION.value
+
+    def kwargs(self):
+        transformation = pb.Transformation(
+            DBTTransformation=pb.DBTTransformation(
+                project_dir=self.project_dir,
+                profiles_dir=self.profiles_dir,
+                target=self.target,
+                model=self.model,
+                table=self.table,
+                inputs=[pb.NameVariant(name=v[0], variant=v[1]) for v in self.inputs],
+            )
+        )
+
+        if self.args is not None:
+            transformation = self.args.apply(transformation)
+
+        return {"transformation": transformation}
+
+
+@typechecked
+@dataclass
+class StreamingTransformation(Transformation):
+    query: str
+    trigger: str = ""
+    args: K8sArgs = None
+
+    def type(self):
+        return SourceType.STREAMING_TRANSFORMATION.value
+
+    def kwargs(self):
+        transformation = pb.Transformation(
+            StreamingTransformation=pb.StreamingTransformation(
+                query=self.query,
+                trigger=self.trigger,
+            )
+        )
+
+        if self.args is not None:
+            transformation = self.args.apply(transformation)
+
+        return {"transformation": transformation}
+
+
+def dbt_transformation_definition(transformation) -> DBTTransformation:
+    return DBTTransformation(
+        project_dir=transformation.project_dir,
+        model=transformation.model,
+        inputs=[(input.name, input.variant) for input in transformation.inputs],
+        profiles_dir=transformation.profiles_dir,
+        target=transformation.target,
+        table=transformation.table,
+    )
+
+
 SourceDefinition = Union[PrimaryData, Transformation, str]
 
 
@@ -1000,6 +1164,15 @@ class Source:
     def _get_source_definition(self, source):
         if source.primaryData.table.name:
             return PrimaryData(SQLTable(source.primaryData.table.name))
+        elif source.primaryData.kafka_topic.topic:
+            topic = source.primaryData.kafka_topic
+            return PrimaryData(
+                KafkaTopic(
+                    topic=topic.topic,
+                    value_format=topic.value_format,
+                    starting_offsets=topic.starting_offsets,
+                )
+            )
         elif source.transformation:
             return self._get_transformation_definition(source)
         else:
@@ -1014,6 +1187,15 @@ class Source:
             )
         elif source.transformation.SQLTransformation.query != "":
             return SQLTransformation(source.transformation.SQLTransformation.query)
+        elif source.transformation.DBTTransformation.model != "":
+            return dbt_transformation_definition(
+                source.transformation.DBTTransformation
+            )
+        elif source.transformation.StreamingTransformation.query != "":
+            transformation = source.transformation.StreamingTransformation
+            return StreamingTransformation(
+                query=transformation.query, trigger=transformation.trigger
+            )
         else:
             raise Exception(f"Invalid transformation type {source}")
 
@@ -1040,6 +1222,10 @@ class Source:
         elif type(self.definition) == SQLTransformation:
             self.is_transformation = SourceType.SQL_TRANSFORMATION.value
             self.definition = self.definition.query
+        elif type(self.definition) == DBTTransformation:
+            raise ValueError("dbt transformations aren't supported in local mode")
+        elif type(self.definition) == StreamingTransformation:
+            raise ValueError("streaming transformations aren't supported in local mode")
         elif type(self.definition) == PrimaryData:
             if isinstance(self.definition.location, Directory):
                 self.definition = self.definition.path()
@@ -1047,6 +1233,8 @@ class Source:
             elif isinstance(self.definition.location, SQLTable):
                 self.definition = self.definition.name()
                 self.is_transformation = SourceType.PRIMARY_SOURCE.value
+            elif isinstance(self.definition.location, KafkaTopic):
+                raise ValueError("kafka topics aren't supported in local mode")
             else:
                 raise ValueError(
                     f"Invalid Primary Data Type {self.definition.location}"
@@ -2106,6 +2294,33 @@ class EMRCredentials:
         }
 
 
+@typechecked
+@dataclass
+class EMRServerlessCredentials:
+    def __init__(
+        self,
+        application_id: str,
+        execution_role_arn: str,
+        region: str,
+        credentials: AWSCredentials,
+    ):
+        self.application_id = application_id
+        self.execution_role_arn = execution_role_arn
+        self.region = region
+        self.credentials = credentials
+
+    def type(self):
+        return "EMR_SERVERLESS"
+
+    def config(self):
+        return {
+            "ApplicationId": self.application_id,
+            "ExecutionRoleArn": self.execution_role_arn,
+            "Region": self.region,
+            "Credentials": self.credentials.config(),
+        }
+
+
 @typechecked
 @dataclass
 class SparkCredentials:
@@ -2203,4 +2418,6 @@ class SparkCredentials:
         }
 
 
-ExecutorCredentials = Union[EMRCredentials, DatabricksCredentials, SparkCredentials]
+ExecutorCredentials = Union[
+    EMRCredentials, EMRServerlessCredentials, DatabricksCredentials, SparkCredentials
+]
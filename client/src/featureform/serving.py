@@ -195,6 +195,10 @@ class HostedClientImpl:
             # in a `oneof` field
             elif value_type == serving_pb2.Vector32:
                 parsed_value = parsed_value.value
+            elif value_type == serving_pb2.MapValue:
+                parsed_value = parse_proto_map(parsed_value)
+            elif value_type == serving_pb2.ListValue:
+                parsed_value = parse_proto_list(parsed_value)
 
             feature_values.append(parsed_value)
 
@@ -1186,3 +1190,13 @@ class BatchRow:
 def parse_proto_value(value):
     """parse_proto_value is used to parse the one of Value message"""
     return getattr(value, value.WhichOneof("value"))
+
+
+def parse_proto_map(map_value):
+    """parse_proto_map converts a MapValue message into a dict"""
+    return {key: parse_proto_value(val) for key, val in map_value.values.items()}
+
+
+def parse_proto_list(list_value):
+    """parse_proto_list converts a ListValue message into a list"""
+    return [parse_proto_value(val) for val in list_value.values]
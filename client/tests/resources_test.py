@@ -1,6 +1,7 @@
 # This Source Code Form is subject to the terms of the Mozilla Public
 # License, v. 2.0. If a copy of the MPL was not distributed with this
 # file, You can obtain one at https://mozilla.org/MPL/2.0/.
+import json
 import os.path
 import sys
 
@@ -37,6 +38,11 @@ from featureform.resources import (
     K8sArgs,
     K8sResourceSpecs,
     SparkCredentials,
+    KafkaConfig,
+    S3StoreConfig,
+    AWSCredentials,
+    GCPCredentials,
+    KafkaTopic,
 )
 
 from featureform.register import OfflineK8sProvider, Registrar, FileStoreProvider
@@ -162,6 +168,64 @@ def test_bigquery_config(bigquery_config):
     return bigquery_config.serialize()
 
 
+def test_application_default_credentials():
+    config = BigQueryConfig(project_id="project", dataset_id="dataset")
+    assert json.loads(config.serialize())["Credentials"] == {}
+    assert GCPCredentials("project").config() == {"ProjectId": "project", "JSON": {}}
+
+
+def test_kafka_config():
+    config = KafkaConfig(
+        brokers=["broker-1:9092", "broker-2:9092"],
+        username="user",
+        password="password",
+        tls=True,
+        schema_registry_url="https://registry:8081",
+    )
+    assert json.loads(config.serialize()) == {
+        "Brokers": ["broker-1:9092", "broker-2:9092"],
+        "Username": "user",
+        "Password": "password",
+        "Mechanism": "",
+        "TLS": True,
+        "SchemaRegistry": {
+            "URL": "https://registry:8081",
+            "Username": "",
+            "Password": "",
+        },
+    }
+
+
+def test_s3_config_assumed_role():
+    config = S3StoreConfig(
+        "lake",
+        "us-east-1",
+        AWSCredentials("id", "secret"),
+        role_arn="arn:aws:iam::123456789012:role/lake",
+        external_id="external",
+        endpoint="http://minio:9000",
+        path_style=True,
+    )
+    serialized = json.loads(config.serialize())
+    assert serialized["RoleARN"] == "arn:aws:iam::123456789012:role/lake"
+    assert serialized["ExternalID"] == "external"
+    assert serialized["Endpoint"] == "http://minio:9000"
+    assert serialized["PathStyle"] is True
+    assert "AccessPoint" not in serialized
+    with pytest.raises(ValueError):
+        S3StoreConfig(
+            "lake", "us-east-1", AWSCredentials("id", "secret"), external_id="x"
+        )
+
+
+def test_kafka_topic_primary_data():
+    definition = PrimaryData(location=KafkaTopic(topic="clicks", value_format="avro"))
+    kafka_topic = definition.kwargs()["primaryData"].kafka_topic
+    assert kafka_topic.topic == "clicks"
+    assert kafka_topic.value_format == "avro"
+    assert kafka_topic.starting_offsets == "earliest"
+
+
 @pytest.fixture
 def postgres_provider(postgres_config):
     return Provider(
@@ -0,0 +1,347 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/featureform/helpers"
+	"go.uber.org/zap"
+)
+
+const (
+	DefaultTunablesPath         = "/etc/featureform/config.json"
+	DefaultTunablesPollInterval = 10 * time.Second
+)
+
+// Tunables are server settings that can be changed at runtime by editing the
+// mounted config file (usually a ConfigMap). Values missing from the file fall
+// back to their environment variables, and then to the built-in defaults.
+type Tunables struct {
+	MaterializeChunkRows int64 `json:"materializeChunkRows"`
+	// MaterializeChunkBytes caps the size of a materialization chunk, from
+	// the average size of a sample of its rows, so that chunks of wide values
+	// like embeddings have fewer rows than chunks of scalars. Chunks never
+	// have more than MaterializeChunkRows rows. Zero sizes chunks by rows
+	// alone.
+	MaterializeChunkBytes int64   `json:"materializeChunkBytes"`
+	MaxJobAttempts        int     `json:"maxJobAttempts"`
+	AuditSampleRate       float64 `json:"auditSampleRate"`
+	// WriteDedupWindowSeconds skips online writes of a value already written
+	// for the entity within the window. Zero disables deduplication.
+	WriteDedupWindowSeconds int `json:"writeDedupWindowSeconds"`
+	// RequireFeatureApproval stops the feature server from serving feature
+	// variants that haven't reached the SERVING_ENABLED approval state.
+	RequireFeatureApproval bool `json:"requireFeatureApproval"`
+	// WriteBufferSize buffers up to this many entities' online writes in
+	// memory and flushes them as a batch. Zero disables buffering.
+	WriteBufferSize int `json:"writeBufferSize"`
+	// WriteBufferFlushMillis is how often buffered writes are flushed even if
+	// the buffer isn't full.
+	WriteBufferFlushMillis int `json:"writeBufferFlushMillis"`
+	// MaterializeBatchSize is how many rows materialization chunks write to
+	// the online store at once, on providers that can write batches. Zero or
+	// one writes each row on its own.
+	MaterializeBatchSize int `json:"materializeBatchSize"`
+	// ProviderProbeIntervalSeconds is how often the feature server probes
+	// each online provider with a synthetic write and read. Zero disables
+	// probing. It's only read at startup.
+	ProviderProbeIntervalSeconds int `json:"providerProbeIntervalSeconds"`
+	// OnlineReadThrough makes the feature server look up entities missing
+	// from the online store in the feature's offline materialization, and
+	// write the values it finds back to the online store.
+	OnlineReadThrough bool `json:"onlineReadThrough"`
+	// OnlineWriteRateLimits caps how many entities per second materializations
+	// write to each type of online provider, as a comma separated list such
+	// as "DYNAMODB_ONLINE=500,REDIS_ONLINE=2000". Providers that aren't listed
+	// aren't limited.
+	OnlineWriteRateLimits string `json:"onlineWriteRateLimits"`
+	// OnlineProviderWriteRateLimits caps how many write requests per second
+	// materializations make to each type of online provider, in the same
+	// format as OnlineWriteRateLimits. A batch write is one request however
+	// many entities are in it.
+	OnlineProviderWriteRateLimits string `json:"onlineProviderWriteRateLimits"`
+	// MaterializeRowRateLimit caps how many rows per second each
+	// materialization copies to the online store, across all of its chunks.
+	// Zero disables the limit.
+	MaterializeRowRateLimit int `json:"materializeRowRateLimit"`
+	// TableStatsIntervalSeconds is how often the feature server records the
+	// size of each online table. Zero disables collection. It's only read at
+	// startup.
+	TableStatsIntervalSeconds int `json:"tableStatsIntervalSeconds"`
+	// FullRefreshMaterializations truncates a feature's online table before
+	// an update rewrites it, so entities removed from the source stop being
+	// served. Entities are missing from the table until they're rewritten.
+	FullRefreshMaterializations bool `json:"fullRefreshMaterializations"`
+	// RequireProviderTLS refuses to register or use providers whose configs
+	// connect without TLS, unless they're in ProviderTLSAllowlist.
+	RequireProviderTLS bool `json:"requireProviderTLS"`
+	// ProviderTLSAllowlist is a comma separated list of provider names that
+	// may connect without TLS even if RequireProviderTLS is set.
+	ProviderTLSAllowlist string `json:"providerTLSAllowlist"`
+	// ApproximateChunkPlanning splits materializations into chunks by an
+	// estimate of their rows from table statistics, on providers that keep
+	// them, instead of counting the rows. The last chunk copies every row
+	// after its start, so rows beyond the estimate aren't missed.
+	ApproximateChunkPlanning bool `json:"approximateChunkPlanning"`
+	// LocalMaterializeWorkers caps how many chunks of a materialization are
+	// copied at once when it runs locally rather than in Kubernetes. Zero
+	// uses one worker per CPU.
+	LocalMaterializeWorkers int `json:"localMaterializeWorkers"`
+	// ChunkMaxAttempts is how many times a materialization chunk is attempted
+	// before it fails, waiting ChunkRetryBackoffMillis before the first retry
+	// and twice as long before each after it, up to ChunkRetryMaxBackoffMillis.
+	ChunkMaxAttempts           int `json:"chunkMaxAttempts"`
+	ChunkRetryBackoffMillis    int `json:"chunkRetryBackoffMillis"`
+	ChunkRetryMaxBackoffMillis int `json:"chunkRetryMaxBackoffMillis"`
+}
+
+// TLSExempt returns true if the provider named name may connect without TLS.
+func (t Tunables) TLSExempt(name string) bool {
+	if !t.RequireProviderTLS {
+		return true
+	}
+	for _, allowed := range strings.Split(t.ProviderTLSAllowlist, ",") {
+		if allowed = strings.TrimSpace(allowed); allowed != "" && allowed == name {
+			return true
+		}
+	}
+	return false
+}
+
+// WriteRateLimit returns the write rate limit of the online provider type, or
+// zero if it isn't limited.
+func (t Tunables) WriteRateLimit(providerType string) float64 {
+	limits, _ := parseWriteRateLimits(t.OnlineWriteRateLimits)
+	return limits[providerType]
+}
+
+// ProviderWriteRateLimit returns the write request rate limit of the online
+// provider type, or zero if it isn't limited.
+func (t Tunables) ProviderWriteRateLimit(providerType string) float64 {
+	limits, _ := parseWriteRateLimits(t.OnlineProviderWriteRateLimits)
+	return limits[providerType]
+}
+
+func parseWriteRateLimits(value string) (map[string]float64, error) {
+	limits := make(map[string]float64)
+	for _, entry := range strings.Split(value, ",") {
+		if entry = strings.TrimSpace(entry); entry == "" {
+			continue
+		}
+		providerType, limit, found := strings.Cut(entry, "=")
+		if !found {
+			return nil, fmt.Errorf("expected PROVIDER_TYPE=limit: %q", entry)
+		}
+		parsed, err := strconv.ParseFloat(strings.TrimSpace(limit), 64)
+		if err != nil || parsed <= 0 {
+			return nil, fmt.Errorf("limit of %s must be a positive number: %q", providerType, limit)
+		}
+		limits[strings.TrimSpace(providerType)] = parsed
+	}
+	return limits, nil
+}
+
+func (t Tunables) validate() error {
+	if t.MaterializeChunkRows <= 0 {
+		return fmt.Errorf("materializeChunkRows must be positive: %d", t.MaterializeChunkRows)
+	}
+	if t.MaterializeChunkBytes < 0 {
+		return fmt.Errorf("materializeChunkBytes must not be negative: %d", t.MaterializeChunkBytes)
+	}
+	if t.MaxJobAttempts <= 0 {
+		return fmt.Errorf("maxJobAttempts must be positive: %d", t.MaxJobAttempts)
+	}
+	if t.AuditSampleRate < 0 || t.AuditSampleRate > 1 {
+		return fmt.Errorf("auditSampleRate must be between 0 and 1: %v", t.AuditSampleRate)
+	}
+	if t.WriteDedupWindowSeconds < 0 {
+		return fmt.Errorf("writeDedupWindowSeconds must not be negative: %d", t.WriteDedupWindowSeconds)
+	}
+	if t.WriteBufferSize < 0 {
+		return fmt.Errorf("writeBufferSize must not be negative: %d", t.WriteBufferSize)
+	}
+	if t.WriteBufferFlushMillis <= 0 {
+		return fmt.Errorf("writeBufferFlushMillis must be positive: %d", t.WriteBufferFlushMillis)
+	}
+	if t.MaterializeBatchSize < 0 {
+		return fmt.Errorf("materializeBatchSize must not be negative: %d", t.MaterializeBatchSize)
+	}
+	if t.ProviderProbeIntervalSeconds < 0 {
+		return fmt.Errorf("providerProbeIntervalSeconds must not be negative: %d", t.ProviderProbeIntervalSeconds)
+	}
+	if _, err := parseWriteRateLimits(t.OnlineWriteRateLimits); err != nil {
+		return fmt.Errorf("invalid onlineWriteRateLimits: %w", err)
+	}
+	if _, err := parseWriteRateLimits(t.OnlineProviderWriteRateLimits); err != nil {
+		return fmt.Errorf("invalid onlineProviderWriteRateLimits: %w", err)
+	}
+	if t.MaterializeRowRateLimit < 0 {
+		return fmt.Errorf("materializeRowRateLimit must not be negative: %d", t.MaterializeRowRateLimit)
+	}
+	if t.TableStatsIntervalSeconds < 0 {
+		return fmt.Errorf("tableStatsIntervalSeconds must not be negative: %d", t.TableStatsIntervalSeconds)
+	}
+	if t.LocalMaterializeWorkers < 0 {
+		return fmt.Errorf("localMaterializeWorkers must not be negative: %d", t.LocalMaterializeWorkers)
+	}
+	if t.ChunkMaxAttempts <= 0 {
+		return fmt.Errorf("chunkMaxAttempts must be positive: %d", t.ChunkMaxAttempts)
+	}
+	if t.ChunkRetryBackoffMillis < 0 {
+		return fmt.Errorf("chunkRetryBackoffMillis must not be negative: %d", t.ChunkRetryBackoffMillis)
+	}
+	if t.ChunkRetryMaxBackoffMillis < t.ChunkRetryBackoffMillis {
+		return fmt.Errorf("chunkRetryMaxBackoffMillis must be at least chunkRetryBackoffMillis: %d", t.ChunkRetryMaxBackoffMillis)
+	}
+	return nil
+}
+
+func EnvTunables() Tunables {
+	return Tunables{
+		MaterializeChunkRows:          int64(helpers.GetEnvInt("MATERIALIZE_CHUNK_ROWS", 16777216)),
+		MaterializeChunkBytes:         int64(helpers.GetEnvInt("MATERIALIZE_CHUNK_BYTES", 1<<30)),
+		MaxJobAttempts:                helpers.GetEnvInt("MAX_JOB_ATTEMPTS", 3),
+		AuditSampleRate:               helpers.GetEnvFloat64("AUDIT_SAMPLE_RATE", 1.0),
+		WriteDedupWindowSeconds:       helpers.GetEnvInt("WRITE_DEDUP_WINDOW_SECONDS", 0),
+		RequireFeatureApproval:        helpers.GetEnvBool("REQUIRE_FEATURE_APPROVAL", false),
+		WriteBufferSize:               helpers.GetEnvInt("WRITE_BUFFER_SIZE", 0),
+		WriteBufferFlushMillis:        helpers.GetEnvInt("WRITE_BUFFER_FLUSH_MILLIS", 1000),
+		MaterializeBatchSize:          helpers.GetEnvInt("MATERIALIZE_BATCH_SIZE", 500),
+		ProviderProbeIntervalSeconds:  helpers.GetEnvInt("PROVIDER_PROBE_INTERVAL_SECONDS", 0),
+		OnlineReadThrough:             helpers.GetEnvBool("ONLINE_READ_THROUGH", false),
+		OnlineWriteRateLimits:         helpers.GetEnv("ONLINE_WRITE_RATE_LIMITS", ""),
+		OnlineProviderWriteRateLimits: helpers.GetEnv("ONLINE_PROVIDER_WRITE_RATE_LIMITS", ""),
+		MaterializeRowRateLimit:       helpers.GetEnvInt("MATERIALIZE_ROW_RATE_LIMIT", 0),
+		TableStatsIntervalSeconds:     helpers.GetEnvInt("TABLE_STATS_INTERVAL_SECONDS", 0),
+		FullRefreshMaterializations:   helpers.GetEnvBool("FULL_REFRESH_MATERIALIZATIONS", false),
+		RequireProviderTLS:            helpers.GetEnvBool("REQUIRE_PROVIDER_TLS", false),
+		ProviderTLSAllowlist:          helpers.GetEnv("PROVIDER_TLS_ALLOWLIST", ""),
+		ApproximateChunkPlanning:      helpers.GetEnvBool("APPROXIMATE_CHUNK_PLANNING", false),
+		LocalMaterializeWorkers:       helpers.GetEnvInt("LOCAL_MATERIALIZE_WORKERS", 0),
+		ChunkMaxAttempts:              helpers.GetEnvInt("CHUNK_MAX_ATTEMPTS", 3),
+		ChunkRetryBackoffMillis:       helpers.GetEnvInt("CHUNK_RETRY_BACKOFF_MILLIS", 1000),
+		ChunkRetryMaxBackoffMillis:    helpers.GetEnvInt("CHUNK_RETRY_MAX_BACKOFF_MILLIS", 60000),
+	}
+}
+
+type TunablesWatcher struct {
+	Path        string
+	Interval    time.Duration
+	Logger      *zap.SugaredLogger
+	current     Tunables
+	lastRead    []byte
+	subscribers []func(Tunables)
+	mu          sync.RWMutex
+}
+
+func NewTunablesWatcher(path string, interval time.Duration, logger *zap.SugaredLogger) *TunablesWatcher {
+	return &TunablesWatcher{
+		Path:     path,
+		Interval: interval,
+		Logger:   logger,
+		current:  EnvTunables(),
+	}
+}
+
+func (w *TunablesWatcher) Get() Tunables {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+	return w.current
+}
+
+// Subscribe registers fn to be called with the new tunables every time the
+// config file changes.
+func (w *TunablesWatcher) Subscribe(fn func(Tunables)) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.subscribers = append(w.subscribers, fn)
+}
+
+// Reload reads the config file and applies it if its contents changed. A
+// missing file isn't an error; the environment defaults stay in effect.
+func (w *TunablesWatcher) Reload() error {
+	data, err := os.ReadFile(w.Path)
+	if os.IsNotExist(err) {
+		return nil
+	} else if err != nil {
+		return fmt.Errorf("could not read config file %s: %w", w.Path, err)
+	}
+	w.mu.RLock()
+	unchanged := bytes.Equal(data, w.lastRead)
+	w.mu.RUnlock()
+	if unchanged {
+		return nil
+	}
+	tunables := EnvTunables()
+	if err := json.Unmarshal(data, &tunables); err != nil {
+		return fmt.Errorf("could not parse config file %s: %w", w.Path, err)
+	}
+	if err := tunables.validate(); err != nil {
+		return fmt.Errorf("invalid config file %s: %w", w.Path, err)
+	}
+	w.mu.Lock()
+	w.current = tunables
+	w.lastRead = data
+	subscribers := make([]func(Tunables), len(w.subscribers))
+	copy(subscribers, w.subscribers)
+	w.mu.Unlock()
+	for _, fn := range subscribers {
+		fn(tunables)
+	}
+	return nil
+}
+
+// Watch polls the config file until stop is closed. Polling is used rather
+// than filesystem events because Kubernetes updates mounted ConfigMaps by
+// swapping symlinks, which inotify watches on the file don't observe.
+func (w *TunablesWatcher) Watch(stop <-chan struct{}) {
+	ticker := time.NewTicker(w.Interval)
+	defer ticker.Stop()
+	for {
+		if err := w.Reload(); err != nil && w.Logger != nil {
+			w.Logger.Errorw("Failed to reload tunables", "Path", w.Path, "Error", err)
+		}
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+var (
+	tunablesWatcher     *TunablesWatcher
+	tunablesWatcherLock sync.RWMutex
+)
+
+// StartTunablesWatch loads the config file at TUNABLES_CONFIG_PATH and keeps it
+// up to date in the background. Until it's called, GetTunables returns the
+// environment defaults.
+func StartTunablesWatch(logger *zap.SugaredLogger) *TunablesWatcher {
+	path := helpers.GetEnv("TUNABLES_CONFIG_PATH", DefaultTunablesPath)
+	interval := time.Duration(helpers.GetEnvInt("TUNABLES_POLL_SECONDS", int(DefaultTunablesPollInterval/time.Second))) * time.Second
+	watcher := NewTunablesWatcher(path, interval, logger)
+	if err := watcher.Reload(); err != nil {
+		logger.Errorw("Failed to load tunables, using defaults", "Path", path, "Error", err)
+	}
+	tunablesWatcherLock.Lock()
+	tunablesWatcher = watcher
+	tunablesWatcherLock.Unlock()
+	go watcher.Watch(make(chan struct{}))
+	return watcher
+}
+
+func GetTunables() Tunables {
+	tunablesWatcherLock.RLock()
+	defer tunablesWatcherLock.RUnlock()
+	if tunablesWatcher == nil {
+		return EnvTunables()
+	}
+	return tunablesWatcher.Get()
+}
@@ -0,0 +1,48 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestTunablesWatcherReload(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.json")
+	watcher := NewTunablesWatcher(path, DefaultTunablesPollInterval, nil)
+	if err := watcher.Reload(); err != nil {
+		t.Fatalf("Missing config file should not be an error: %s", err)
+	}
+	if watcher.Get() != EnvTunables() {
+		t.Fatalf("Expected env defaults, got %v", watcher.Get())
+	}
+	var notified []Tunables
+	watcher.Subscribe(func(t Tunables) {
+		notified = append(notified, t)
+	})
+	if err := os.WriteFile(path, []byte(`{"maxJobAttempts": 5}`), 0644); err != nil {
+		t.Fatalf("Failed to write config: %s", err)
+	}
+	if err := watcher.Reload(); err != nil {
+		t.Fatalf("Failed to reload: %s", err)
+	}
+	expected := EnvTunables()
+	expected.MaxJobAttempts = 5
+	if watcher.Get() != expected {
+		t.Fatalf("Expected %v, got %v", expected, watcher.Get())
+	}
+	if err := watcher.Reload(); err != nil {
+		t.Fatalf("Failed to reload: %s", err)
+	}
+	if len(notified) != 1 || notified[0] != expected {
+		t.Fatalf("Expected a single notification with %v, got %v", expected, notified)
+	}
+	if err := os.WriteFile(path, []byte(`{"auditSampleRate": 2}`), 0644); err != nil {
+		t.Fatalf("Failed to write config: %s", err)
+	}
+	if err := watcher.Reload(); err == nil {
+		t.Fatalf("Succeeded in loading an invalid config")
+	}
+	if watcher.Get() != expected {
+		t.Fatalf("Invalid config was applied: %v", watcher.Get())
+	}
+}
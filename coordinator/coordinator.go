@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"os"
 	"strings"
+	"sync"
 	"time"
 
 	db "github.com/jackc/pgx/v4"
@@ -35,6 +36,30 @@ func retryWithDelays(name string, retries int, delay time.Duration, idempotentFu
 	return fmt.Errorf("retried %s %d times unsuccessfully: Latest error message: %v", name, retries, err)
 }
 
+// jobProgressInterval is how often the progress of a running materialization
+// or training set job is logged.
+const jobProgressInterval = 30 * time.Second
+
+// waitWithProgress waits for a job to complete, logging its progress every
+// interval while it runs.
+func (c *Coordinator) waitWithProgress(resID metadata.ResourceID, watcher types.CompletionWatcher, interval time.Duration) error {
+	done := make(chan error, 1)
+	go func() {
+		done <- watcher.Wait()
+	}()
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case err := <-done:
+			return err
+		case <-ticker.C:
+			progress := types.ProgressOf(watcher)
+			c.Logger.Infow("Job progress", "name", resID.Name, "variant", resID.Variant, "type", resID.Type, "chunks_done", progress.ChunksDone, "chunks_total", progress.ChunksTotal, "rows_written", progress.RowsWritten, "eta", progress.ETA)
+		}
+	}
+}
+
 type Config []byte
 
 func templateReplace(template string, replacements map[string]string, offlineStore provider.OfflineStore) (string, error) {
@@ -87,6 +112,10 @@ type Coordinator struct {
 	KVClient   *clientv3.KV
 	Spawner    JobSpawner
 	Timeout    int
+	// cancels are the cancel funcs of the jobs running in this coordinator
+	// that can be cancelled, by their resource.
+	cancels   map[metadata.ResourceID]context.CancelFunc
+	cancelMtx sync.Mutex
 }
 
 type ETCDConfig struct {
@@ -231,8 +260,6 @@ func NewCoordinator(meta *metadata.Client, logger *zap.SugaredLogger, cli *clien
 	}, nil
 }
 
-const MAX_ATTEMPTS = 3
-
 func (c *Coordinator) checkError(err error, jobName string) {
 	switch err.(type) {
 	case JobDoesNotExistError:
@@ -351,7 +378,7 @@ func (c *Coordinator) mapNameVariantsToTables(sources []metadata.NameVariant) (m
 			return nil, fmt.Errorf("could not fetch source provider: %v", err)
 		}
 
-		if (sourceProvider.Type() == "SPARK_OFFLINE" || sourceProvider.Type() == "K8S_OFFLINE") && (source.IsDFTransformation() || source.IsSQLTransformation()) {
+		if (sourceProvider.Type() == "SPARK_OFFLINE" || sourceProvider.Type() == "K8S_OFFLINE") && (source.IsDFTransformation() || source.IsSQLTransformation() || source.IsDBTTransformation() || source.IsStreamingTransformation()) {
 			providerResourceID.Type = provider.Transformation
 			tableName, err = provider.GetTransformationTableName(providerResourceID)
 			if err != nil {
@@ -472,6 +499,58 @@ func (c *Coordinator) runTransformationJob(transformationConfig provider.Transfo
 			return fmt.Errorf("set transformation succesful schedule status: %v", err)
 		}
 	}
+	if err := c.scheduleMaintenance(resID, transformationConfig.TargetTableID, transformation.Properties(), sourceProvider); err != nil {
+		return fmt.Errorf("schedule transformation maintenance: %v", err)
+	}
+	return nil
+}
+
+// additionalOnlineTargets fetches the additional online providers in a
+// feature's properties, which its materialization is written to as well.
+func (c *Coordinator) additionalOnlineTargets(properties metadata.Properties) ([]runner.OnlineTarget, error) {
+	names := runner.AdditionalOnlineFromProperties(properties)
+	targets := make([]runner.OnlineTarget, len(names))
+	for i, name := range names {
+		onlineProvider, err := c.Metadata.GetProvider(context.Background(), name)
+		if err != nil {
+			return nil, fmt.Errorf("could not fetch additional online provider %s: %v", name, err)
+		}
+		if err := onlineProvider.CheckTLSPolicy(); err != nil {
+			return nil, err
+		}
+		targets[i] = runner.OnlineTarget{Type: pt.Type(onlineProvider.Type()), Config: onlineProvider.SerializedConfig()}
+	}
+	return targets, nil
+}
+
+// scheduleMaintenance schedules maintenance of a resource's offline table if
+// it was requested in the resource's properties.
+func (c *Coordinator) scheduleMaintenance(resID metadata.ResourceID, tableID provider.ResourceID, properties metadata.Properties, offlineProvider *metadata.Provider) error {
+	schedule, maintenanceConfig := runner.MaintenanceFromProperties(tableID, properties)
+	if schedule == "" {
+		return nil
+	}
+	c.Logger.Infow("Scheduling maintenance", "resource", resID, "schedule", schedule)
+	runnerConfig := runner.MaintenanceRunnerConfig{
+		OfflineType:       pt.Type(offlineProvider.Type()),
+		OfflineConfig:     offlineProvider.SerializedConfig(),
+		MaintenanceConfig: maintenanceConfig,
+	}
+	serialized, err := runnerConfig.Serialize()
+	if err != nil {
+		return fmt.Errorf("serialize maintenance runner config: %v", err)
+	}
+	jobRunner, err := c.Spawner.GetJobRunner(runner.MAINTAIN_OFFLINE, serialized, resID)
+	if err != nil {
+		return fmt.Errorf("creating maintenance job runner: %v", err)
+	}
+	cronRunner, isCronRunner := jobRunner.(kubernetes.CronRunner)
+	if !isCronRunner {
+		return fmt.Errorf("kubernetes runner does not implement schedule")
+	}
+	if err := cronRunner.ScheduleJob(kubernetes.CronSchedule(schedule)); err != nil {
+		return fmt.Errorf("schedule maintenance job in kubernetes: %v", err)
+	}
 	return nil
 }
 
@@ -556,6 +635,228 @@ func (c *Coordinator) runDFTransformationJob(transformSource *metadata.SourceVar
 	return nil
 }
 
+// runDBTTransformationJob runs a dbt model once its input sources are ready,
+// and uses the table it builds as the transformation.
+func (c *Coordinator) runDBTTransformationJob(transformSource *metadata.SourceVariant, resID metadata.ResourceID, schedule string, sourceProvider *metadata.Provider) error {
+	c.Logger.Info("Running dbt transformation job on resource: ", resID)
+	dbt := transformSource.DBTTransformation()
+	if err := c.verifyCompletionOfSources(dbt.Inputs); err != nil {
+		return fmt.Errorf("the sources were not completed: %s", err)
+	}
+
+	providerResourceID := provider.ResourceID{Name: resID.Name, Variant: resID.Variant, Type: provider.Transformation}
+	transformationConfig := provider.TransformationConfig{
+		Type:          provider.DBTTransformation,
+		TargetTableID: providerResourceID,
+		DBT: provider.DBTModel{
+			ProjectDir:  dbt.ProjectDir,
+			ProfilesDir: dbt.ProfilesDir,
+			Target:      dbt.Target,
+			Model:       dbt.Model,
+			Table:       dbt.Table,
+		},
+		Args: transformSource.TransformationArgs(),
+	}
+	if err := transformationConfig.DBT.Validate(); err != nil {
+		return err
+	}
+
+	return c.runTransformationJob(transformationConfig, resID, schedule, sourceProvider)
+}
+
+// streamingTransformationConfig builds the config that a streaming
+// transformation's job is started with, writing to the given online sinks.
+func (c *Coordinator) streamingTransformationConfig(transformSource *metadata.SourceVariant, offlineStore provider.OfflineStore, sinks []provider.StreamingOnlineSink) (provider.TransformationConfig, error) {
+	streaming := transformSource.StreamingTransformation()
+	sourceMap, err := c.mapNameVariantsToTables(streaming.Sources)
+	if err != nil {
+		return provider.TransformationConfig{}, fmt.Errorf("map name: %v sources: %v", err, streaming.Sources)
+	}
+	sourceMapping, err := getSourceMapping(streaming.Query, sourceMap)
+	if err != nil {
+		return provider.TransformationConfig{}, fmt.Errorf("getSourceMapping replace: %v source map: %v, template: %s", err, sourceMap, streaming.Query)
+	}
+	query, err := templateReplace(streaming.Query, sourceMap, offlineStore)
+	if err != nil {
+		return provider.TransformationConfig{}, fmt.Errorf("template replace: %v source map: %v, template: %s", err, sourceMap, streaming.Query)
+	}
+	kafkaPaths, err := c.kafkaSourcePaths(transformSource)
+	if err != nil {
+		return provider.TransformationConfig{}, err
+	}
+	for i, mapping := range sourceMapping {
+		if path, has := kafkaPaths[mapping.Source]; has {
+			sourceMapping[i].Source = path
+		}
+	}
+	return provider.TransformationConfig{
+		Type:          provider.StreamingTransformation,
+		TargetTableID: provider.ResourceID{Name: transformSource.Name(), Variant: transformSource.Variant(), Type: provider.Transformation},
+		Query:         query,
+		SourceMapping: sourceMapping,
+		Args:          transformSource.TransformationArgs(),
+		Streaming: provider.StreamingConfig{
+			Trigger:     streaming.Trigger,
+			OnlineSinks: sinks,
+		},
+	}, nil
+}
+
+// kafkaSourcePaths returns the source paths of a streaming transformation's
+// Kafka topics, keyed by their primary table names. A topic is read from the
+// offsets last recorded for the transformation, so a job that's lost its
+// checkpoint doesn't read the topic again from its starting offsets.
+func (c *Coordinator) kafkaSourcePaths(transformSource *metadata.SourceVariant) (map[string]string, error) {
+	_, recorded := transformSource.StreamingOffsets()
+	paths := make(map[string]string)
+	for _, nameVariant := range transformSource.StreamingTransformationSources() {
+		source, err := c.Metadata.GetSourceVariant(context.Background(), nameVariant)
+		if err != nil {
+			return nil, err
+		}
+		if !source.IsKafkaTopic() {
+			continue
+		}
+		sourceProvider, err := source.FetchProvider(c.Metadata, context.Background())
+		if err != nil {
+			return nil, fmt.Errorf("could not fetch source provider: %v", err)
+		}
+		kafkaConfig := pc.KafkaConfig{}
+		if err := kafkaConfig.Deserialize(sourceProvider.SerializedConfig()); err != nil {
+			return nil, fmt.Errorf("invalid kafka config: %v", err)
+		}
+		topic := source.KafkaTopic()
+		topicSource := provider.KafkaTopicSource{
+			Topic:           topic.Topic,
+			ValueFormat:     provider.KafkaValueFormat(topic.ValueFormat),
+			StartingOffsets: topic.StartingOffsets,
+		}
+		partitionOffsets := make(map[int32]int64)
+		for _, offset := range recorded {
+			if offset.Topic == topic.Topic {
+				partitionOffsets[offset.Partition] = offset.Offset
+			}
+		}
+		if len(partitionOffsets) > 0 {
+			topicSource.StartingOffsets, err = provider.KafkaStartingOffsets(topic.Topic, partitionOffsets)
+			if err != nil {
+				return nil, err
+			}
+		}
+		path, err := provider.KafkaSourcePath(kafkaConfig, topicSource)
+		if err != nil {
+			return nil, err
+		}
+		tableName, err := provider.GetPrimaryTableName(provider.ResourceID{Name: source.Name(), Variant: source.Variant(), Type: provider.Primary})
+		if err != nil {
+			return nil, err
+		}
+		paths[tableName] = path
+	}
+	return paths, nil
+}
+
+// runStreamingTransformationJob starts the job of a streaming transformation
+// once its sources are ready. The transformation is ready as soon as its job
+// has started, and its job keeps running until it's stopped.
+func (c *Coordinator) runStreamingTransformationJob(transformSource *metadata.SourceVariant, resID metadata.ResourceID, offlineStore provider.OfflineStore, schedule string) error {
+	c.Logger.Info("Running streaming transformation job on resource: ", resID)
+	status := transformSource.Status()
+	if status == metadata.READY {
+		return ResourceAlreadyCompleteError{
+			resourceID: resID,
+		}
+	}
+	if status == metadata.FAILED {
+		return ResourceAlreadyFailedError{
+			resourceID: resID,
+		}
+	}
+	if schedule != "" {
+		return fmt.Errorf("streaming transformations run continuously and can't be scheduled")
+	}
+	streamingStore, ok := offlineStore.(provider.StreamingOfflineStore)
+	if !ok {
+		return &provider.StreamingNotSupported{ProviderType: offlineStore.Type().String()}
+	}
+	if err := c.Metadata.SetStatus(context.Background(), resID, metadata.PENDING, ""); err != nil {
+		return fmt.Errorf("set pending status for streaming transformation job: %v", err)
+	}
+	if err := c.verifyCompletionOfSources(transformSource.StreamingTransformationSources()); err != nil {
+		return fmt.Errorf("the sources were not completed: %s", err)
+	}
+	transformationConfig, err := c.streamingTransformationConfig(transformSource, offlineStore, nil)
+	if err != nil {
+		return err
+	}
+	if err := streamingStore.StartStreamingTransformation(transformationConfig); err != nil {
+		return fmt.Errorf("start streaming transformation: %v", err)
+	}
+	if err := retryWithDelays("set status to ready", 5, time.Millisecond*10, func() error { return c.Metadata.SetStatus(context.Background(), resID, metadata.READY, "") }); err != nil {
+		return fmt.Errorf("set streaming transformation job running status: %v", err)
+	}
+	return nil
+}
+
+// streamingOnlineSinks returns the online sinks of a streaming
+// transformation: the online tables of its ready features. Only Redis tables
+// can be written to by the job, so features in other online stores are only
+// updated when they're materialized.
+func (c *Coordinator) streamingOnlineSinks(transformSource *metadata.SourceVariant) ([]provider.StreamingOnlineSink, error) {
+	features, err := transformSource.FetchFeatures(c.Metadata, context.Background())
+	if err != nil {
+		return nil, fmt.Errorf("fetch features of streaming transformation: %v", err)
+	}
+	sinks := []provider.StreamingOnlineSink{}
+	for _, feature := range features {
+		if feature.Status() != metadata.READY {
+			continue
+		}
+		featureProvider, err := feature.FetchProvider(c.Metadata, context.Background())
+		if err != nil {
+			return nil, fmt.Errorf("fetch online provider of feature %s %s: %v", feature.Name(), feature.Variant(), err)
+		}
+		if pt.Type(featureProvider.Type()) != pt.RedisOnline {
+			c.Logger.Infow("Streaming transformation can't write to online store", "feature", feature.Name(), "variant", feature.Variant(), "provider", featureProvider.Type())
+			continue
+		}
+		columns := feature.LocationColumns().(metadata.ResourceVariantColumns)
+		sinks = append(sinks, provider.StreamingOnlineSink{
+			Type:    pt.Type(featureProvider.Type()),
+			Config:  featureProvider.SerializedConfig(),
+			Feature: feature.Name(),
+			Variant: feature.Variant(),
+			Entity:  columns.Entity,
+			Value:   columns.Value,
+		})
+	}
+	return sinks, nil
+}
+
+// restartStreamingTransformation stops a streaming transformation's job and
+// starts it again writing to the online tables of all of its ready features.
+// The new job carries on from the checkpoint of the one it replaces.
+func (c *Coordinator) restartStreamingTransformation(transformSource *metadata.SourceVariant, offlineStore provider.OfflineStore) error {
+	streamingStore, ok := offlineStore.(provider.StreamingOfflineStore)
+	if !ok {
+		return &provider.StreamingNotSupported{ProviderType: offlineStore.Type().String()}
+	}
+	sinks, err := c.streamingOnlineSinks(transformSource)
+	if err != nil {
+		return err
+	}
+	transformationConfig, err := c.streamingTransformationConfig(transformSource, offlineStore, sinks)
+	if err != nil {
+		return err
+	}
+	c.Logger.Infow("Restarting streaming transformation", "name", transformSource.Name(), "variant", transformSource.Variant(), "online_sinks", len(sinks))
+	err = streamingStore.StopStreamingTransformation(transformationConfig.TargetTableID)
+	if _, notFound := err.(*provider.StreamingJobNotFound); err != nil && !notFound {
+		return err
+	}
+	return streamingStore.StartStreamingTransformation(transformationConfig)
+}
+
 func (c *Coordinator) runPrimaryTableJob(transformSource *metadata.SourceVariant, resID metadata.ResourceID, offlineStore provider.OfflineStore, schedule string) error {
 	c.Logger.Info("Running primary table job on resource: ", resID)
 	providerResourceID := provider.ResourceID{Name: resID.Name, Variant: resID.Variant, Type: provider.Primary}
@@ -572,6 +873,40 @@ func (c *Coordinator) runPrimaryTableJob(transformSource *metadata.SourceVariant
 	return nil
 }
 
+// runKafkaTopicJob checks that a registered Kafka topic exists. Nothing is
+// copied from it: it's only read by the streaming transformations of it.
+func (c *Coordinator) runKafkaTopicJob(topicSource *metadata.SourceVariant, resID metadata.ResourceID, p provider.Provider) error {
+	c.Logger.Info("Running kafka topic job on resource: ", resID)
+	kafka, ok := p.(*provider.KafkaProvider)
+	if !ok {
+		return fmt.Errorf("kafka topics can't be registered on %s providers", p.Type())
+	}
+	kafkaConfig := pc.KafkaConfig{}
+	if err := kafkaConfig.Deserialize(p.Config()); err != nil {
+		return fmt.Errorf("invalid kafka config: %v", err)
+	}
+	topic := topicSource.KafkaTopic()
+	err := provider.KafkaTopicSource{
+		Topic:           topic.Topic,
+		ValueFormat:     provider.KafkaValueFormat(topic.ValueFormat),
+		StartingOffsets: topic.StartingOffsets,
+	}.Validate(kafkaConfig)
+	if err != nil {
+		return err
+	}
+	partitions, err := kafka.TopicPartitions(topic.Topic)
+	if err != nil {
+		return err
+	}
+	if len(partitions) == 0 {
+		return fmt.Errorf("kafka topic %s does not exist", topic.Topic)
+	}
+	if err := c.Metadata.SetStatus(context.Background(), resID, metadata.READY, ""); err != nil {
+		return fmt.Errorf("set done status for registering kafka topic: %v", err)
+	}
+	return nil
+}
+
 func (c *Coordinator) runRegisterSourceJob(resID metadata.ResourceID, schedule string) error {
 	c.Logger.Info("Running register source job on resource: ", resID)
 	source, err := c.Metadata.GetSourceVariant(context.Background(), metadata.NameVariant{resID.Name, resID.Variant})
@@ -582,10 +917,16 @@ func (c *Coordinator) runRegisterSourceJob(resID metadata.ResourceID, schedule s
 	if err != nil {
 		return fmt.Errorf("fetch source's dependent provider in metadata: %v", err)
 	}
+	if err := sourceProvider.CheckTLSPolicy(); err != nil {
+		return err
+	}
 	p, err := provider.Get(pt.Type(sourceProvider.Type()), sourceProvider.SerializedConfig())
 	if err != nil {
 		return fmt.Errorf("get source's dependent provider in offline store: %v", err)
 	}
+	if source.IsKafkaTopic() {
+		return c.runKafkaTopicJob(source, resID, p)
+	}
 	sourceStore, err := p.AsOfflineStore()
 	if err != nil {
 		return fmt.Errorf("convert source provider to offline store interface: %v", err)
@@ -600,6 +941,10 @@ func (c *Coordinator) runRegisterSourceJob(resID metadata.ResourceID, schedule s
 		return c.runSQLTransformationJob(source, resID, sourceStore, schedule, sourceProvider)
 	} else if source.IsDFTransformation() {
 		return c.runDFTransformationJob(source, resID, sourceStore, schedule, sourceProvider)
+	} else if source.IsDBTTransformation() {
+		return c.runDBTTransformationJob(source, resID, schedule, sourceProvider)
+	} else if source.IsStreamingTransformation() {
+		return c.runStreamingTransformationJob(source, resID, sourceStore, schedule)
 	} else if source.IsPrimaryDataSQLTable() {
 		return c.runPrimaryTableJob(source, resID, sourceStore, schedule)
 	} else {
@@ -635,10 +980,16 @@ func (c *Coordinator) runLabelRegisterJob(resID metadata.ResourceID, schedule st
 	if err != nil {
 		return fmt.Errorf("source of could not complete job: %v", err)
 	}
+	if source.IsKafkaTopic() {
+		return fmt.Errorf("kafka topic %s can't be materialized directly: register a streaming transformation of it", source.Name())
+	}
 	sourceProvider, err := source.FetchProvider(c.Metadata, context.Background())
 	if err != nil {
 		return fmt.Errorf("could not fetch online provider: %v", err)
 	}
+	if err := sourceProvider.CheckTLSPolicy(); err != nil {
+		return err
+	}
 	p, err := provider.Get(pt.Type(sourceProvider.Type()), sourceProvider.SerializedConfig())
 	if err != nil {
 		return fmt.Errorf("could not get offline provider config: %v", err)
@@ -654,7 +1005,7 @@ func (c *Coordinator) runLabelRegisterJob(resID metadata.ResourceID, schedule st
 		}
 	}(sourceStore)
 	var sourceTableName string
-	if source.IsSQLTransformation() || source.IsDFTransformation() {
+	if source.IsSQLTransformation() || source.IsDFTransformation() || source.IsDBTTransformation() || source.IsStreamingTransformation() {
 		sourceResourceID := provider.ResourceID{sourceNameVariant.Name, sourceNameVariant.Variant, provider.Transformation}
 		sourceTable, err := sourceStore.GetTransformationTable(sourceResourceID)
 		if err != nil {
@@ -676,11 +1027,16 @@ func (c *Coordinator) runLabelRegisterJob(resID metadata.ResourceID, schedule st
 		Type:    provider.Label,
 	}
 	tmpSchema := label.LocationColumns().(metadata.ResourceVariantColumns)
+	timezone, err := source.TimestampTimezone()
+	if err != nil {
+		return err
+	}
 	schema := provider.ResourceSchema{
 		Entity:      tmpSchema.Entity,
 		Value:       tmpSchema.Value,
 		TS:          tmpSchema.TS,
 		SourceTable: sourceTableName,
+		TSTimezone:  timezone,
 	}
 	c.Logger.Debugw("Creating Label Resource Table", "id", labelID, "schema", schema)
 	_, err = sourceStore.RegisterResourceFromSourceTable(labelID, schema)
@@ -724,10 +1080,16 @@ func (c *Coordinator) runFeatureMaterializeJob(resID metadata.ResourceID, schedu
 	if err != nil {
 		return fmt.Errorf("source of could not complete job: %v", err)
 	}
+	if source.IsKafkaTopic() {
+		return fmt.Errorf("kafka topic %s can't be materialized directly: register a streaming transformation of it", source.Name())
+	}
 	sourceProvider, err := source.FetchProvider(c.Metadata, context.Background())
 	if err != nil {
 		return fmt.Errorf("could not fetch online provider: %v", err)
 	}
+	if err := sourceProvider.CheckTLSPolicy(); err != nil {
+		return err
+	}
 	p, err := provider.Get(pt.Type(sourceProvider.Type()), sourceProvider.SerializedConfig())
 	if err != nil {
 		return err
@@ -746,35 +1108,93 @@ func (c *Coordinator) runFeatureMaterializeJob(resID metadata.ResourceID, schedu
 	if err != nil {
 		return fmt.Errorf("could not fetch  onlineprovider: %v", err)
 	}
+	if err := featureProvider.CheckTLSPolicy(); err != nil {
+		return err
+	}
+	entity, err := c.Metadata.GetEntity(context.Background(), feature.Entity())
+	if err != nil {
+		return fmt.Errorf("could not fetch entity: %v", err)
+	}
+	keyRules, err := entity.KeyRules()
+	if err != nil {
+		return fmt.Errorf("invalid entity key rules: %v", err)
+	}
 	var vType provider.ValueType
 	if feature.IsEmbedding() {
-		vType = provider.VectorType{
-			ScalarType:  provider.ScalarType(featureType),
-			Dimension:   feature.Dimension(),
-			IsEmbedding: true,
+		metric, err := feature.DistanceMetric()
+		if err != nil {
+			return err
+		}
+		options, err := feature.IndexOptions()
+		if err != nil {
+			return err
+		}
+		storage, err := feature.VectorStorage()
+		if err != nil {
+			return err
 		}
+		names, err := feature.VectorNames()
+		if err != nil {
+			return err
+		}
+		vectorType := provider.VectorType{
+			ScalarType:     provider.ScalarType(featureType),
+			Dimension:      feature.Dimension(),
+			IsEmbedding:    true,
+			DistanceMetric: provider.DistanceMetric(metric),
+			StorageType:    provider.ScalarType(storage),
+		}
+		if options != (metadata.IndexOptions{}) {
+			vectorType.Index = &provider.IndexOptions{
+				M:              options.M,
+				EfConstruction: options.EfConstruction,
+				EfRuntime:      options.EfRuntime,
+				NLists:         options.NLists,
+			}
+		}
+		if len(names) > 0 {
+			vectorType.Named = &provider.NamedVectors{Names: names}
+		}
+		vType = vectorType
 	} else {
-		vType = provider.ScalarType(featureType)
+		vType = provider.ParseValueType(featureType)
+	}
+	if err != nil {
+		return err
+	}
+	dedup, err := runner.DedupFromProperties(feature.Properties())
+	if err != nil {
+		return err
 	}
+	expectations, err := runner.ExpectationsFromProperties(feature.Properties())
+	if err != nil {
+		return err
+	}
+	additionalOnline, err := c.additionalOnlineTargets(feature.Properties())
 	if err != nil {
 		return err
 	}
 	materializedRunnerConfig := runner.MaterializedRunnerConfig{
-		OnlineType:    pt.Type(featureProvider.Type()),
-		OfflineType:   pt.Type(sourceProvider.Type()),
-		OnlineConfig:  featureProvider.SerializedConfig(),
-		OfflineConfig: sourceProvider.SerializedConfig(),
-		ResourceID:    provider.ResourceID{Name: resID.Name, Variant: resID.Variant, Type: provider.Feature},
-		VType:         provider.ValueTypeJSONWrapper{ValueType: vType},
-		Cloud:         runner.LocalMaterializeRunner,
-		IsUpdate:      false,
+		OnlineType:       pt.Type(featureProvider.Type()),
+		OfflineType:      pt.Type(sourceProvider.Type()),
+		OnlineConfig:     featureProvider.SerializedConfig(),
+		AdditionalOnline: additionalOnline,
+		OfflineConfig:    sourceProvider.SerializedConfig(),
+		ResourceID:       provider.ResourceID{Name: resID.Name, Variant: resID.Variant, Type: provider.Feature},
+		VType:            provider.ValueTypeJSONWrapper{ValueType: vType},
+		Cloud:            runner.LocalMaterializeRunner,
+		IsUpdate:         false,
+		Dedup:            dedup,
+		Entity:           entity.Name(),
+		KeyRules:         keyRules,
+		Expectations:     expectations,
 	}
 	serialized, err := materializedRunnerConfig.Serialize()
 	if err != nil {
 		return fmt.Errorf("could not get online provider config: %v", err)
 	}
 	var sourceTableName string
-	if source.IsSQLTransformation() || source.IsDFTransformation() {
+	if source.IsSQLTransformation() || source.IsDFTransformation() || source.IsDBTTransformation() || source.IsStreamingTransformation() {
 		sourceResourceID := provider.ResourceID{sourceNameVariant.Name, sourceNameVariant.Variant, provider.Transformation}
 		sourceTable, err := sourceStore.GetTransformationTable(sourceResourceID)
 		if err != nil {
@@ -796,11 +1216,16 @@ func (c *Coordinator) runFeatureMaterializeJob(resID metadata.ResourceID, schedu
 		Type:    provider.Feature,
 	}
 	tmpSchema := feature.LocationColumns().(metadata.ResourceVariantColumns)
+	timezone, err := source.TimestampTimezone()
+	if err != nil {
+		return err
+	}
 	schema := provider.ResourceSchema{
 		Entity:      tmpSchema.Entity,
 		Value:       tmpSchema.Value,
 		TS:          tmpSchema.TS,
 		SourceTable: sourceTableName,
+		TSTimezone:  timezone,
 	}
 	c.Logger.Debugw("Creating Resource Table", "id", featID, "schema", schema)
 	_, err = sourceStore.RegisterResourceFromSourceTable(featID, schema)
@@ -808,18 +1233,20 @@ func (c *Coordinator) runFeatureMaterializeJob(resID metadata.ResourceID, schedu
 		return fmt.Errorf("materialize feature register: %v", err)
 	}
 	c.Logger.Debugw("Resource Table Created", "id", featID, "schema", schema)
-	needsOnlineMaterialization := strings.Split(string(featureProvider.Type()), "_")[1] == "ONLINE"
+	needsOnlineMaterialization := strings.HasSuffix(featureProvider.Type(), "_ONLINE")
 	if needsOnlineMaterialization {
 		c.Logger.Info("Starting Materialize")
 		jobRunner, err := c.Spawner.GetJobRunner(runner.MATERIALIZE, serialized, resID)
 		if err != nil {
 			return fmt.Errorf("could not use store as online store: %w", err)
 		}
-		completionWatcher, err := jobRunner.Run()
+		ctx, done := c.startCancellableJob(resID)
+		defer done()
+		completionWatcher, err := types.RunContext(ctx, jobRunner)
 		if err != nil {
 			return fmt.Errorf("creating watcher for completion runner: %w", err)
 		}
-		if err := completionWatcher.Wait(); err != nil {
+		if err := c.waitWithProgress(resID, completionWatcher, jobProgressInterval); err != nil {
 			return fmt.Errorf("completion watcher running: %w", err)
 		}
 	}
@@ -828,14 +1255,22 @@ func (c *Coordinator) runFeatureMaterializeJob(resID metadata.ResourceID, schedu
 	}
 	if schedule != "" && needsOnlineMaterialization {
 		scheduleMaterializeRunnerConfig := runner.MaterializedRunnerConfig{
-			OnlineType:    pt.Type(featureProvider.Type()),
-			OfflineType:   pt.Type(sourceProvider.Type()),
-			OnlineConfig:  featureProvider.SerializedConfig(),
-			OfflineConfig: sourceProvider.SerializedConfig(),
-			ResourceID:    provider.ResourceID{Name: resID.Name, Variant: resID.Variant, Type: provider.Feature},
-			VType:         provider.ValueTypeJSONWrapper{ValueType: vType},
-			Cloud:         runner.LocalMaterializeRunner,
-			IsUpdate:      true,
+			OnlineType:       pt.Type(featureProvider.Type()),
+			OfflineType:      pt.Type(sourceProvider.Type()),
+			OnlineConfig:     featureProvider.SerializedConfig(),
+			AdditionalOnline: additionalOnline,
+			OfflineConfig:    sourceProvider.SerializedConfig(),
+			ResourceID:       provider.ResourceID{Name: resID.Name, Variant: resID.Variant, Type: provider.Feature},
+			VType:            provider.ValueTypeJSONWrapper{ValueType: vType},
+			Cloud:            runner.LocalMaterializeRunner,
+			IsUpdate:         true,
+			// Rows are only newer than the watermark if they have
+			// timestamps, so features without them are updated in full.
+			Incremental:  schema.TS != "" && runner.IncrementalFromProperties(feature.Properties()),
+			Dedup:        dedup,
+			Entity:       entity.Name(),
+			KeyRules:     keyRules,
+			Expectations: expectations,
 		}
 		serializedUpdate, err := scheduleMaterializeRunnerConfig.Serialize()
 		if err != nil {
@@ -856,6 +1291,17 @@ func (c *Coordinator) runFeatureMaterializeJob(resID metadata.ResourceID, schedu
 			return fmt.Errorf("set succesful update status for materialize job in kubernetes: %v", err)
 		}
 	}
+	if needsOnlineMaterialization && source.IsStreamingTransformation() {
+		if err := c.restartStreamingTransformation(source, sourceStore); err != nil {
+			return fmt.Errorf("restart streaming transformation with online sinks: %v", err)
+		}
+	}
+	if needsOnlineMaterialization {
+		matID := provider.ResourceID{Name: resID.Name, Variant: resID.Variant, Type: provider.FeatureMaterialization}
+		if err := c.scheduleMaintenance(resID, matID, feature.Properties(), sourceProvider); err != nil {
+			return fmt.Errorf("schedule materialization maintenance: %v", err)
+		}
+	}
 	return nil
 }
 
@@ -883,6 +1329,9 @@ func (c *Coordinator) runTrainingSetJob(resID metadata.ResourceID, schedule stri
 	if err != nil {
 		return fmt.Errorf("fetch training set variant offline provider: %v", err)
 	}
+	if err := providerEntry.CheckTLSPolicy(); err != nil {
+		return err
+	}
 	p, err := provider.Get(pt.Type(providerEntry.Type()), providerEntry.SerializedConfig())
 	if err != nil {
 		return fmt.Errorf("fetch offline store interface of training set provider: %v", err)
@@ -962,11 +1411,13 @@ func (c *Coordinator) runTrainingSetJob(resID metadata.ResourceID, schedule stri
 	if err != nil {
 		return fmt.Errorf("create training set job runner: %v", err)
 	}
-	completionWatcher, err := jobRunner.Run()
+	ctx, done := c.startCancellableJob(resID)
+	defer done()
+	completionWatcher, err := types.RunContext(ctx, jobRunner)
 	if err != nil {
 		return fmt.Errorf("start training set job runner: %v", err)
 	}
-	if err := completionWatcher.Wait(); err != nil {
+	if err := c.waitWithProgress(resID, completionWatcher, jobProgressInterval); err != nil {
 		return fmt.Errorf("wait for training set job runner completion: %v", err)
 	}
 	if err := c.Metadata.SetStatus(context.Background(), resID, metadata.READY, ""); err != nil {
@@ -1105,12 +1556,13 @@ func (c *Coordinator) ExecuteJob(jobKey string) error {
 		return err
 	}
 	c.Logger.Debugf("Job %s is on attempt %d", jobKey, job.Attempts)
-	if job.Attempts > MAX_ATTEMPTS {
+	maxAttempts := cfg.GetTunables().MaxJobAttempts
+	if job.Attempts > maxAttempts {
 		if err := c.deleteJob(mtx, jobKey); err != nil {
 			c.Logger.Debugw("Error deleting job", "error", err)
 			return fmt.Errorf("job delete: %v", err)
 		}
-		return fmt.Errorf("job failed after %d attempts. Cancelling coordinator flow", MAX_ATTEMPTS)
+		return fmt.Errorf("job failed after %d attempts. Cancelling coordinator flow", maxAttempts)
 	}
 	if err := c.incrementJobAttempts(mtx, job, jobKey); err != nil {
 		return fmt.Errorf("increment attempt: %v", err)
@@ -1246,3 +1698,363 @@ func (c *Coordinator) changeJobSchedule(key string, value string) error {
 	}
 	return nil
 }
+
+// WatchForStopStreamingJobs stops the jobs of streaming transformations as
+// stop requests are added under the STOPSTREAM_ prefix.
+func (c *Coordinator) WatchForStopStreamingJobs() error {
+	c.Logger.Info("Watching for streaming jobs to stop")
+	getResp, err := (*c.KVClient).Get(context.Background(), "STOPSTREAM_", clientv3.WithPrefix())
+	if err != nil {
+		return fmt.Errorf("fetch existing etcd stop streaming jobs: %v", err)
+	}
+	for _, kv := range getResp.Kvs {
+		go func(kv *mvccpb.KeyValue) {
+			err := c.stopStreamingJob(string(kv.Key), string(kv.Value))
+			if err != nil {
+				c.Logger.Errorw("Error stopping streaming job: Initial search", "error", err)
+			}
+		}(kv)
+	}
+	for {
+		rch := c.EtcdClient.Watch(context.Background(), "STOPSTREAM_", clientv3.WithPrefix())
+		for wresp := range rch {
+			for _, ev := range wresp.Events {
+				if ev.Type == mvccpb.PUT {
+					go func(ev *clientv3.Event) {
+						err := c.stopStreamingJob(string(ev.Kv.Key), string(ev.Kv.Value))
+						if err != nil {
+							c.Logger.Errorw("Error stopping streaming job: Polling search", "error", err)
+						}
+					}(ev)
+				}
+			}
+		}
+	}
+}
+
+// streamingOfflineStore returns the offline store that a streaming
+// transformation runs in. It has to be closed by the caller.
+func (c *Coordinator) streamingOfflineStore(source *metadata.SourceVariant) (provider.StreamingOfflineStore, error) {
+	sourceProvider, err := source.FetchProvider(c.Metadata, context.Background())
+	if err != nil {
+		return nil, fmt.Errorf("fetch source's dependent provider in metadata: %v", err)
+	}
+	if err := sourceProvider.CheckTLSPolicy(); err != nil {
+		return nil, err
+	}
+	p, err := provider.Get(pt.Type(sourceProvider.Type()), sourceProvider.SerializedConfig())
+	if err != nil {
+		return nil, fmt.Errorf("get source's dependent provider in offline store: %v", err)
+	}
+	offlineStore, err := p.AsOfflineStore()
+	if err != nil {
+		return nil, fmt.Errorf("convert source provider to offline store interface: %v", err)
+	}
+	streamingStore, ok := offlineStore.(provider.StreamingOfflineStore)
+	if !ok {
+		offlineStore.Close()
+		return nil, &provider.StreamingNotSupported{ProviderType: sourceProvider.Type()}
+	}
+	return streamingStore, nil
+}
+
+func (c *Coordinator) stopStreamingJob(key string, value string) error {
+	c.Logger.Info("Stopping streaming job: ", key)
+	s, err := concurrency.NewSession(c.EtcdClient, concurrency.WithTTL(1))
+	if err != nil {
+		return fmt.Errorf("create new concurrency session for stop streaming job: %v", err)
+	}
+	defer s.Close()
+	mtx, err := c.createJobLock(key, s)
+	if err != nil {
+		return fmt.Errorf("create lock on stop streaming job with key %s: %v", key, err)
+	}
+	defer func() {
+		if err := mtx.Unlock(context.Background()); err != nil {
+			c.Logger.Debugw("Error unlocking mutex:", "error", err)
+		}
+	}()
+	stopJob := &metadata.CoordinatorStopStreamingJob{}
+	if err := stopJob.Deserialize([]byte(value)); err != nil {
+		return fmt.Errorf("deserialize stop streaming job: %v", err)
+	}
+	source, err := c.Metadata.GetSourceVariant(context.Background(), metadata.NameVariant{stopJob.Resource.Name, stopJob.Resource.Variant})
+	if err != nil {
+		return fmt.Errorf("get source variant from metadata: %v", err)
+	}
+	streamingStore, err := c.streamingOfflineStore(source)
+	if err != nil {
+		return err
+	}
+	defer streamingStore.Close()
+	id := provider.ResourceID{Name: source.Name(), Variant: source.Variant(), Type: provider.Transformation}
+	if err := streamingStore.StopStreamingTransformation(id); err != nil {
+		return fmt.Errorf("stop streaming transformation: %v", err)
+	}
+	c.Logger.Info("Successfully stopped streaming job with key: ", key)
+	if err := c.deleteJob(mtx, key); err != nil {
+		return fmt.Errorf("delete stop streaming job: %v", err)
+	}
+	return nil
+}
+
+// startCancellableJob returns the context of a job of resID, which CancelJob
+// cancels until done is called once the job has finished.
+func (c *Coordinator) startCancellableJob(resID metadata.ResourceID) (context.Context, func()) {
+	ctx, cancel := context.WithCancel(context.Background())
+	c.cancelMtx.Lock()
+	defer c.cancelMtx.Unlock()
+	if c.cancels == nil {
+		c.cancels = make(map[metadata.ResourceID]context.CancelFunc)
+	}
+	c.cancels[resID] = cancel
+	return ctx, func() {
+		c.cancelMtx.Lock()
+		defer c.cancelMtx.Unlock()
+		delete(c.cancels, resID)
+		cancel()
+	}
+}
+
+// CancelJob cancels the job of resID if it's running in this coordinator, and
+// returns whether it was. The job fails with context.Canceled.
+func (c *Coordinator) CancelJob(resID metadata.ResourceID) bool {
+	c.cancelMtx.Lock()
+	defer c.cancelMtx.Unlock()
+	cancel, has := c.cancels[resID]
+	if has {
+		cancel()
+	}
+	return has
+}
+
+// WatchForCancelJobs cancels running materialization and training set jobs
+// as cancel requests are added under the CANCELJOB_ prefix. Every coordinator
+// watches the prefix, and the one running the job deletes its request.
+func (c *Coordinator) WatchForCancelJobs() error {
+	c.Logger.Info("Watching for jobs to cancel")
+	for {
+		rch := c.EtcdClient.Watch(context.Background(), "CANCELJOB_", clientv3.WithPrefix())
+		for wresp := range rch {
+			for _, ev := range wresp.Events {
+				if ev.Type == mvccpb.PUT {
+					if err := c.cancelJob(string(ev.Kv.Key), ev.Kv.Value); err != nil {
+						c.Logger.Errorw("Error cancelling job", "error", err)
+					}
+				}
+			}
+		}
+	}
+}
+
+func (c *Coordinator) cancelJob(key string, value []byte) error {
+	cancelJob := &metadata.CoordinatorCancelJob{}
+	if err := cancelJob.Deserialize(value); err != nil {
+		return fmt.Errorf("deserialize cancel job: %v", err)
+	}
+	if !c.CancelJob(cancelJob.Resource) {
+		return nil
+	}
+	c.Logger.Infow("Cancelled job", "name", cancelJob.Resource.Name, "variant", cancelJob.Resource.Variant, "type", cancelJob.Resource.Type)
+	if _, err := (*c.KVClient).Delete(context.Background(), key); err != nil {
+		return fmt.Errorf("delete cancel job: %v", err)
+	}
+	return nil
+}
+
+// WatchForDeleteFromOnlineJobs deletes the online tables of feature variants
+// as deletion requests are added under the DELETEONLINE_ prefix.
+func (c *Coordinator) WatchForDeleteFromOnlineJobs() error {
+	c.Logger.Info("Watching for features to delete from online stores")
+	getResp, err := (*c.KVClient).Get(context.Background(), "DELETEONLINE_", clientv3.WithPrefix())
+	if err != nil {
+		return fmt.Errorf("fetch existing etcd delete from online jobs: %v", err)
+	}
+	for _, kv := range getResp.Kvs {
+		go func(kv *mvccpb.KeyValue) {
+			err := c.deleteFromOnlineJob(string(kv.Key), string(kv.Value))
+			if err != nil {
+				c.Logger.Errorw("Error deleting from online: Initial search", "error", err)
+			}
+		}(kv)
+	}
+	for {
+		rch := c.EtcdClient.Watch(context.Background(), "DELETEONLINE_", clientv3.WithPrefix())
+		for wresp := range rch {
+			for _, ev := range wresp.Events {
+				if ev.Type == mvccpb.PUT {
+					go func(ev *clientv3.Event) {
+						err := c.deleteFromOnlineJob(string(ev.Kv.Key), string(ev.Kv.Value))
+						if err != nil {
+							c.Logger.Errorw("Error deleting from online: Polling search", "error", err)
+						}
+					}(ev)
+				}
+			}
+		}
+	}
+}
+
+func (c *Coordinator) deleteFromOnlineJob(key string, value string) error {
+	c.Logger.Info("Deleting from online: ", key)
+	s, err := concurrency.NewSession(c.EtcdClient, concurrency.WithTTL(1))
+	if err != nil {
+		return fmt.Errorf("create new concurrency session for delete from online job: %v", err)
+	}
+	defer s.Close()
+	mtx, err := c.createJobLock(key, s)
+	if err != nil {
+		return fmt.Errorf("create lock on delete from online job with key %s: %v", key, err)
+	}
+	defer func() {
+		if err := mtx.Unlock(context.Background()); err != nil {
+			c.Logger.Debugw("Error unlocking mutex:", "error", err)
+		}
+	}()
+	deleteJob := &metadata.CoordinatorDeleteFromOnlineJob{}
+	if err := deleteJob.Deserialize([]byte(value)); err != nil {
+		return fmt.Errorf("deserialize delete from online job: %v", err)
+	}
+	resID := deleteJob.Resource
+	feature, err := c.Metadata.GetFeatureVariant(context.Background(), metadata.NameVariant{resID.Name, resID.Variant})
+	if err != nil {
+		return fmt.Errorf("get feature variant from metadata: %v", err)
+	}
+	featureProvider, err := feature.FetchProvider(c.Metadata, context.Background())
+	if err != nil {
+		return fmt.Errorf("could not fetch online provider: %v", err)
+	}
+	if err := featureProvider.CheckTLSPolicy(); err != nil {
+		return err
+	}
+	additionalOnline, err := c.additionalOnlineTargets(feature.Properties())
+	if err != nil {
+		return err
+	}
+	deletionConfig := runner.OnlineDeletionRunnerConfig{
+		OnlineType:       pt.Type(featureProvider.Type()),
+		OnlineConfig:     featureProvider.SerializedConfig(),
+		AdditionalOnline: additionalOnline,
+		ResourceID:       provider.ResourceID{Name: resID.Name, Variant: resID.Variant, Type: provider.Feature},
+	}
+	serialized, err := deletionConfig.Serialize()
+	if err != nil {
+		return err
+	}
+	jobRunner, err := c.Spawner.GetJobRunner(runner.DELETE_FROM_ONLINE, serialized, resID)
+	if err != nil {
+		return fmt.Errorf("create delete from online job runner: %v", err)
+	}
+	completionWatcher, err := jobRunner.Run()
+	if err != nil {
+		return fmt.Errorf("start delete from online job runner: %v", err)
+	}
+	if err := completionWatcher.Wait(); err != nil {
+		return fmt.Errorf("wait for delete from online job runner completion: %v", err)
+	}
+	c.Logger.Info("Successfully deleted from online with key: ", key)
+	if err := c.deleteJob(mtx, key); err != nil {
+		return fmt.Errorf("delete delete from online job: %v", err)
+	}
+	return nil
+}
+
+// RunJob runs the job of the runner registered under name, including custom
+// runners, for resID and waits for it to complete. The runner is spawned like
+// the coordinator's own jobs, so with Kubernetes it must be registered in the
+// worker image. It can be cancelled with CancelJob.
+func (c *Coordinator) RunJob(name string, config runner.Config, resID metadata.ResourceID) error {
+	ctx, done := c.startCancellableJob(resID)
+	defer done()
+	jobRunner, err := c.Spawner.GetJobRunner(name, config, resID)
+	if err != nil {
+		return fmt.Errorf("create %s job runner: %w", name, err)
+	}
+	completionWatcher, err := types.RunContext(ctx, jobRunner)
+	if err != nil {
+		return fmt.Errorf("start %s job runner: %w", name, err)
+	}
+	if err := c.waitWithProgress(resID, completionWatcher, jobProgressInterval); err != nil {
+		return fmt.Errorf("wait for %s job runner completion: %w", name, err)
+	}
+	return nil
+}
+
+// MonitorStreamingTransformations checks on the jobs of ready streaming
+// transformations every interval, and marks a transformation as failed if
+// its job has failed.
+func (c *Coordinator) MonitorStreamingTransformations(interval time.Duration) error {
+	c.Logger.Info("Monitoring streaming transformations")
+	for {
+		if err := c.checkStreamingTransformations(); err != nil {
+			c.Logger.Errorw("Error checking streaming transformations", "error", err)
+		}
+		time.Sleep(interval)
+	}
+}
+
+func (c *Coordinator) checkStreamingTransformations() error {
+	sources, err := c.Metadata.ListSources(context.Background())
+	if err != nil {
+		return fmt.Errorf("list sources: %v", err)
+	}
+	for _, source := range sources {
+		ids := make([]metadata.NameVariant, len(source.Variants()))
+		for i, variant := range source.Variants() {
+			ids[i] = metadata.NameVariant{Name: source.Name(), Variant: variant}
+		}
+		variants, err := c.Metadata.GetSourceVariants(context.Background(), ids)
+		if err != nil {
+			return fmt.Errorf("get source variants of %s: %v", source.Name(), err)
+		}
+		for _, variant := range variants {
+			if !variant.IsStreamingTransformation() || variant.Status() != metadata.READY {
+				continue
+			}
+			if err := c.checkStreamingTransformation(variant); err != nil {
+				c.Logger.Errorw("Error checking streaming transformation", "name", variant.Name(), "variant", variant.Variant(), "error", err)
+			}
+		}
+	}
+	return nil
+}
+
+func (c *Coordinator) checkStreamingTransformation(source *metadata.SourceVariant) error {
+	streamingStore, err := c.streamingOfflineStore(source)
+	if err != nil {
+		return err
+	}
+	defer streamingStore.Close()
+	id := provider.ResourceID{Name: source.Name(), Variant: source.Variant(), Type: provider.Transformation}
+	status, err := streamingStore.StreamingTransformationStatus(id)
+	if err != nil {
+		return err
+	}
+	if status.State != provider.StreamingJobFailed {
+		return c.recordStreamingOffsets(source, streamingStore, id)
+	}
+	resID := metadata.ResourceID{Name: source.Name(), Variant: source.Variant(), Type: metadata.SOURCE_VARIANT}
+	message := fmt.Sprintf("streaming job failed: %s", status.Message)
+	return c.Metadata.SetStatus(context.Background(), resID, metadata.FAILED, message)
+}
+
+// recordStreamingOffsets records the Kafka offsets of a streaming
+// transformation's latest checkpoint in metadata.
+func (c *Coordinator) recordStreamingOffsets(source *metadata.SourceVariant, streamingStore provider.StreamingOfflineStore, id provider.ResourceID) error {
+	checkpoint, err := streamingStore.StreamingCheckpoint(id)
+	if _, notFound := err.(*provider.StreamingCheckpointNotFound); notFound {
+		return nil
+	} else if err != nil {
+		return err
+	}
+	batch, _ := source.StreamingOffsets()
+	kafkaOffsets := checkpoint.KafkaOffsets()
+	if len(kafkaOffsets) == 0 || checkpoint.Batch <= batch {
+		return nil
+	}
+	offsets := make([]metadata.KafkaOffset, len(kafkaOffsets))
+	for i, offset := range kafkaOffsets {
+		offsets[i] = metadata.KafkaOffset{Topic: offset.Topic, Partition: offset.Partition, Offset: offset.Offset}
+	}
+	return c.Metadata.SetStreamingOffsets(context.Background(), metadata.NameVariant{Name: source.Name(), Variant: source.Variant()}, checkpoint.Batch, offsets)
+}
@@ -20,6 +20,7 @@ import (
 	pc "github.com/featureform/provider/provider_config"
 	pt "github.com/featureform/provider/provider_type"
 	"github.com/featureform/runner"
+	"github.com/featureform/types"
 	"github.com/jackc/pgx/v4/pgxpool"
 	"github.com/joho/godotenv"
 	clientv3 "go.etcd.io/etcd/client/v3"
@@ -141,6 +142,54 @@ func TestMemoryJobRunnerError(t *testing.T) {
 	}
 }
 
+type customRunner struct {
+	config runner.Config
+	ran    bool
+}
+
+type customWatcher struct{}
+
+func (r *customRunner) Run() (types.CompletionWatcher, error) {
+	r.ran = true
+	return customWatcher{}, nil
+}
+
+func (r *customRunner) Resource() metadata.ResourceID {
+	return metadata.ResourceID{}
+}
+
+func (r *customRunner) IsUpdateJob() bool {
+	return false
+}
+
+func (customWatcher) Complete() bool { return true }
+func (customWatcher) String() string { return "" }
+func (customWatcher) Wait() error    { return nil }
+func (customWatcher) Err() error     { return nil }
+
+func TestRunCustomJob(t *testing.T) {
+	custom := &customRunner{}
+	factory := func(config runner.Config) (types.Runner, error) {
+		custom.config = config
+		return custom, nil
+	}
+	if err := runner.RegisterFactory("Push to flags", factory); err != nil {
+		t.Fatalf("Failed to register custom runner: %v", err)
+	}
+	defer runner.UnregisterFactory("Push to flags")
+	coord := &Coordinator{Logger: zap.NewExample().Sugar(), Spawner: &MemoryJobSpawner{}}
+	resID := metadata.ResourceID{Name: "flags", Variant: "default", Type: metadata.FEATURE_VARIANT}
+	if err := coord.RunJob("Push to flags", runner.Config("config"), resID); err != nil {
+		t.Fatalf("Failed to run custom job: %v", err)
+	}
+	if !custom.ran || string(custom.config) != "config" {
+		t.Fatalf("Expected the custom runner to run with its config")
+	}
+	if err := coord.RunJob("Not registered", runner.Config{}, resID); err == nil {
+		t.Fatalf("Expected an unregistered runner to fail")
+	}
+}
+
 func TestRunSQLJobError(t *testing.T) {
 	if testing.Short() {
 		return
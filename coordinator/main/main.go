@@ -2,13 +2,17 @@ package main
 
 import (
 	"fmt"
+	"net/http"
 	"time"
 
+	cfg "github.com/featureform/config"
 	"github.com/featureform/coordinator"
 	help "github.com/featureform/helpers"
 	"github.com/featureform/logging"
 	"github.com/featureform/metadata"
 	"github.com/featureform/runner"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
 	clientv3 "go.etcd.io/etcd/client/v3"
 )
 
@@ -39,21 +43,27 @@ func main() {
 			panic(fmt.Errorf("failed to close etcd client: %w", err))
 		}
 	}(cli)
-	if err := runner.RegisterFactory(string(runner.COPY_TO_ONLINE), runner.MaterializedChunkRunnerFactory); err != nil {
-		panic(fmt.Errorf("failed to register 'Copy to Online' runner factory: %w", err))
-	}
-	if err := runner.RegisterFactory(string(runner.MATERIALIZE), runner.MaterializeRunnerFactory); err != nil {
-		panic(fmt.Errorf("failed to register 'Materialize' runner factory: %w", err))
-	}
-	if err := runner.RegisterFactory(string(runner.CREATE_TRANSFORMATION), runner.CreateTransformationRunnerFactory); err != nil {
-		panic(fmt.Errorf("failed to register 'Create Transformation' runner factory: %w", err))
-	}
-	if err := runner.RegisterFactory(string(runner.CREATE_TRAINING_SET), runner.TrainingSetRunnerFactory); err != nil {
-		panic(fmt.Errorf("failed to register 'Create Training Set' runner factory: %w", err))
+	if err := runner.RegisterBuiltinFactories(); err != nil {
+		panic(fmt.Errorf("failed to register runner factories: %w", err))
 	}
 	logger := logging.NewLogger("coordinator")
 	defer logger.Sync()
 	logger.Debug("Connected to ETCD")
+	cfg.StartTunablesWatch(logger)
+	if err := runner.RegisterRunnerMetrics(prometheus.DefaultRegisterer); err != nil {
+		logger.Errorw("Failed to register runner metrics", "error", err)
+		panic(err)
+	}
+	// Metrics of the jobs run in the coordinator's process are served on
+	// METRICS_PORT, like ":9090", if it's set.
+	if metricsPort := help.GetEnv("METRICS_PORT", ""); metricsPort != "" {
+		http.Handle("/metrics", promhttp.Handler())
+		go func() {
+			if err := http.ListenAndServe(metricsPort, nil); err != nil {
+				logger.Errorw("Metrics server failed", "error", err)
+			}
+		}()
+	}
 	client, err := metadata.NewClient(metadataUrl, logger)
 	if err != nil {
 		logger.Errorw("Failed to connect: %v", err)
@@ -71,6 +81,22 @@ func main() {
 		logger.Errorw("Failed to set up coordinator: %v", err)
 		panic(err)
 	}
+	go func() {
+		if err := coord.WatchForStopStreamingJobs(); err != nil {
+			logger.Errorw("Stop streaming job watch failed", "error", err)
+		}
+	}()
+	go func() {
+		if err := coord.WatchForDeleteFromOnlineJobs(); err != nil {
+			logger.Errorw("Delete from online job watch failed", "error", err)
+		}
+	}()
+	go func() {
+		if err := coord.WatchForCancelJobs(); err != nil {
+			logger.Errorw("Cancel job watch failed", "error", err)
+		}
+	}()
+	go coord.MonitorStreamingTransformations(time.Minute)
 	logger.Debug("Begin Job Watch")
 	if err := coord.WatchForNewJobs(); err != nil {
 		logger.Errorw(err.Error())
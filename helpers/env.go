@@ -44,6 +44,13 @@ func GetEnvInt32(key string, fallback int32) int32 {
 	}).(int32)
 }
 
+func GetEnvFloat64(key string, fallback float64) float64 {
+	return getEnvGeneric(key, fallback, func(val string) (interface{}, error) {
+		parsedValue, err := strconv.ParseFloat(val, 64)
+		return parsedValue, err
+	}).(float64)
+}
+
 func GetEnvBool(key string, fallback bool) bool {
 	return getEnvGeneric(key, fallback, func(val string) (interface{}, error) {
 		parsedValue, err := strconv.ParseBool(val)
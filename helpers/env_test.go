@@ -29,6 +29,8 @@ func TestGetEnv(t *testing.T) {
 		{name: "Test GetEnvInt", args: args{"VALID_ENV_VAR", 8888}, setKey: testKey{"VALID_ENV_VAR", "1234"}, want: 1234, testFn: GetEnvInt},
 		{name: "Test GetEnvInt32 Fallback", args: args{"INVALID_ENV_VAR", int32(8888)}, setKey: testKey{"", ""}, want: int32(8888), testFn: GetEnvInt32},
 		{name: "Test GetEnvInt32", args: args{"VALID_ENV_VAR", int32(8888)}, setKey: testKey{"VALID_ENV_VAR", "1234"}, want: int32(1234), testFn: GetEnvInt32},
+		{name: "Test GetEnvFloat64 Fallback", args: args{"INVALID_ENV_VAR", 0.5}, setKey: testKey{"", ""}, want: 0.5, testFn: GetEnvFloat64},
+		{name: "Test GetEnvFloat64", args: args{"VALID_ENV_VAR", 0.5}, setKey: testKey{"VALID_ENV_VAR", "0.25"}, want: 0.25, testFn: GetEnvFloat64},
 		{name: "Test GetEnvBool Fallback", args: args{"INVALID_ENV_VAR", true}, setKey: testKey{"", ""}, want: true, testFn: GetEnvBool},
 		{name: "Test GetEnvBool", args: args{"VALID_ENV_VAR", true}, setKey: testKey{"VALID_ENV_VAR", "false"}, want: false, testFn: GetEnvBool},
 	}
@@ -48,6 +50,8 @@ func TestGetEnv(t *testing.T) {
 				got = fn(tt.args.key, tt.args.fallback.(int))
 			case func(string, int32) int32:
 				got = fn(tt.args.key, tt.args.fallback.(int32))
+			case func(string, float64) float64:
+				got = fn(tt.args.key, tt.args.fallback.(float64))
 			case func(string, bool) bool:
 				got = fn(tt.args.key, tt.args.fallback.(bool))
 			}
@@ -31,6 +31,16 @@ type CronSchedule string
 
 const MaxJobNameLength = 52
 
+const (
+	GPUResource v1.ResourceName = "nvidia.com/gpu"
+	ArchLabel                   = "kubernetes.io/arch"
+)
+
+var supportedArchitectures = map[string]bool{
+	"amd64": true,
+	"arm64": true,
+}
+
 // CreateJobName Only the first value in prefixes will be used.
 func CreateJobName(id metadata.ResourceID, prefixes ...string) string {
 	jobNameBase := fmt.Sprintf("%s-%s-%s", id.Type, id.Name, id.Variant)
@@ -136,12 +146,65 @@ func validateJobLimits(specs metadata.KubernetesResourceSpecs) (v1.ResourceRequi
 		rsrcReq.Limits[v1.ResourceMemory] = qty
 		parseErr = err
 	}
+	// Extended resources like GPUs can't be overcommitted, so Kubernetes
+	// requires the request and limit to match.
+	if specs.GPURequest != "" {
+		qty, err := resource.ParseQuantity(specs.GPURequest)
+		rsrcReq.Requests[GPUResource] = qty
+		rsrcReq.Limits[GPUResource] = qty
+		parseErr = err
+	}
+	if specs.Architecture != "" && !supportedArchitectures[specs.Architecture] {
+		return rsrcReq, fmt.Errorf("unsupported architecture: %s", specs.Architecture)
+	}
 	if parseErr != nil {
 		return rsrcReq, parseErr
 	}
 	return rsrcReq, nil
 }
 
+// ImageVariant returns the image built for the hardware requested in specs.
+// GPU images take precedence over architecture specific images, e.g.
+// featureformcom/k8s_runner:latest becomes featureformcom/k8s_runner:latest-gpu.
+func ImageVariant(image string, specs metadata.KubernetesResourceSpecs) string {
+	var suffix string
+	if specs.GPURequest != "" {
+		suffix = "gpu"
+	} else if specs.Architecture == "arm64" {
+		suffix = "arm64"
+	} else {
+		return image
+	}
+	// A colon after the last slash separates the tag; anything before it is
+	// part of the registry host.
+	if strings.LastIndex(image, ":") > strings.LastIndex(image, "/") {
+		return fmt.Sprintf("%s-%s", image, suffix)
+	}
+	return fmt.Sprintf("%s:latest-%s", image, suffix)
+}
+
+func nodeSelector(specs metadata.KubernetesResourceSpecs) map[string]string {
+	if specs.Architecture == "" {
+		return nil
+	}
+	return map[string]string{ArchLabel: specs.Architecture}
+}
+
+// GPU node pools are usually tainted so that only pods requesting GPUs are
+// scheduled on them.
+func tolerations(specs metadata.KubernetesResourceSpecs) []v1.Toleration {
+	if specs.GPURequest == "" {
+		return nil
+	}
+	return []v1.Toleration{
+		{
+			Key:      string(GPUResource),
+			Operator: v1.TolerationOpExists,
+			Effect:   v1.TaintEffectNoSchedule,
+		},
+	}
+}
+
 func newJobSpec(config KubernetesRunnerConfig, rsrcReqs v1.ResourceRequirements) batchv1.JobSpec {
 	containerID := uuid.New().String()
 	envVars := generateKubernetesEnvVars(config.EnvVars)
@@ -178,9 +241,14 @@ func newJobSpec(config KubernetesRunnerConfig, rsrcReqs v1.ResourceRequirements)
 						Env:             envVars,
 						ImagePullPolicy: pullPolicy,
 						Resources:       rsrcReqs,
+						// Workers write their job's status to the termination
+						// log. The tail of the logs is used if they don't.
+						TerminationMessagePolicy: v1.TerminationMessageFallbackToLogsOnError,
 					},
 				},
 				RestartPolicy: v1.RestartPolicyNever,
+				NodeSelector:  nodeSelector(config.Specs),
+				Tolerations:   tolerations(config.Specs),
 			},
 		},
 	}
@@ -203,6 +271,9 @@ type JobClient interface {
 	UpdateCronJob(cronJob *batchv1.CronJob) (*batchv1.CronJob, error)
 	Watch() (watch.Interface, error)
 	Create(jobSpec *batchv1.JobSpec) (*batchv1.Job, error)
+	// Delete deletes the job along with its pods, stopping any that are
+	// still running.
+	Delete() error
 	SetJobSchedule(schedule CronSchedule, jobSpec *batchv1.JobSpec) error
 	GetJobSchedule(jobName string) (CronSchedule, error)
 }
@@ -227,6 +298,23 @@ func (k KubernetesCompletionWatcher) Complete() bool {
 	return false
 }
 
+// Progress counts the job's succeeded pods as its completed chunks. Rows are
+// written by the pods, so they aren't counted.
+func (k KubernetesCompletionWatcher) Progress() types.Progress {
+	job, err := k.jobClient.Get()
+	if err != nil {
+		return types.Progress{}
+	}
+	progress := types.Progress{ChunksDone: int64(job.Status.Succeeded), ChunksTotal: 1}
+	if job.Spec.Completions != nil {
+		progress.ChunksTotal = int64(*job.Spec.Completions)
+	}
+	if job.Status.StartTime != nil {
+		progress.ETA = types.EstimateETA(job.Status.StartTime.Time, progress.ChunksDone, progress.ChunksTotal)
+	}
+	return progress
+}
+
 func (k KubernetesCompletionWatcher) String() string {
 	job, err := k.jobClient.Get()
 	if err != nil {
@@ -277,6 +365,47 @@ func getPodLogs(namespace string, name string) string {
 	return str
 }
 
+// getPodStatuses returns the termination messages of the job's failed pods,
+// which hold the JSON status the worker wrote when its job completed.
+func getPodStatuses(namespace string, name string) ([]string, error) {
+	config, err := rest.InClusterConfig()
+	if err != nil {
+		return nil, fmt.Errorf("error in getting config: %w", err)
+	}
+	clientset, err := kubernetes.NewForConfig(config)
+	if err != nil {
+		return nil, fmt.Errorf("error in getting access to K8S: %w", err)
+	}
+	podList, err := clientset.CoreV1().Pods(namespace).List(context.TODO(), metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("could not get pod list: %w", err)
+	}
+	statuses := []string{}
+	for _, pod := range podList.Items {
+		if !strings.Contains(pod.GetName(), name) {
+			continue
+		}
+		for _, container := range pod.Status.ContainerStatuses {
+			terminated := container.State.Terminated
+			if terminated != nil && terminated.ExitCode != 0 && terminated.Message != "" {
+				statuses = append(statuses, terminated.Message)
+			}
+		}
+	}
+	return statuses, nil
+}
+
+// failedPodsError describes why the job's pods failed, from the statuses
+// they wrote if there are any, or else from their logs.
+func failedPodsError(job *batchv1.Job) error {
+	statuses, err := getPodStatuses(job.Namespace, job.GetName())
+	if err != nil || len(statuses) == 0 {
+		return fmt.Errorf("job failed while running: container: %s: error: %s",
+			job.Name, getPodLogs(job.Namespace, job.GetName()))
+	}
+	return fmt.Errorf("job failed while running: container: %s: status: %s", job.Name, strings.Join(statuses, "\n"))
+}
+
 func (k KubernetesCompletionWatcher) Wait() error {
 	watcher, err := k.jobClient.Watch()
 	if err != nil {
@@ -292,8 +421,7 @@ func (k KubernetesCompletionWatcher) Wait() error {
 				return nil
 			}
 			if failed := job.Status.Failed; failed > 0 {
-				return fmt.Errorf("job failed while running: container: %s: error: %s",
-					job.Name, getPodLogs(job.Namespace, job.GetName()))
+				return failedPodsError(job)
 			}
 		}
 
@@ -327,6 +455,24 @@ func (k KubernetesRunner) Run() (types.CompletionWatcher, error) {
 	return KubernetesCompletionWatcher{jobClient: k.jobClient}, nil
 }
 
+// RunContext creates the job, and deletes it if ctx is cancelled before it
+// completes.
+func (k KubernetesRunner) RunContext(ctx context.Context) (types.CompletionWatcher, error) {
+	watcher, err := k.Run()
+	if err != nil {
+		return nil, err
+	}
+	contextWatcher := types.WatchContext(ctx, watcher)
+	go func() {
+		if err := contextWatcher.Wait(); err != nil && ctx.Err() != nil {
+			if err := k.jobClient.Delete(); err != nil {
+				fmt.Printf("could not delete cancelled job %s: %v\n", k.jobClient.GetJobName(), err)
+			}
+		}
+	}()
+	return contextWatcher, nil
+}
+
 func (k KubernetesRunner) ScheduleJob(schedule CronSchedule) error {
 	if err := k.jobClient.SetJobSchedule(schedule, k.jobSpec); err != nil {
 		return err
@@ -410,6 +556,11 @@ func (k KubernetesJobClient) Create(jobSpec *batchv1.JobSpec) (*batchv1.Job, err
 	return k.Clientset.BatchV1().Jobs(k.Namespace).Create(context.TODO(), job, metav1.CreateOptions{})
 }
 
+func (k KubernetesJobClient) Delete() error {
+	propagation := metav1.DeletePropagationBackground
+	return k.Clientset.BatchV1().Jobs(k.Namespace).Delete(context.TODO(), k.JobName, metav1.DeleteOptions{PropagationPolicy: &propagation})
+}
+
 func (k KubernetesJobClient) SetJobSchedule(schedule CronSchedule, jobSpec *batchv1.JobSpec) error {
 	successfulJobsHistoryLimit := helpers.GetEnvInt32("SUCCESSFUL_JOBS_HISTORY_LIMIT", 2)
 	failedJobsHistoryLimit := helpers.GetEnvInt32("FAILED_JOBS_HISTORY_LIMIT", 1)
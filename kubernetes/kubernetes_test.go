@@ -6,6 +6,7 @@ package kubernetes
 
 import (
 	"errors"
+	"github.com/featureform/metadata"
 	"github.com/google/uuid"
 	batchv1 "k8s.io/api/batch/v1"
 	watch "k8s.io/apimachinery/pkg/watch"
@@ -59,6 +60,10 @@ func (m MockJobClient) Create(jobSpec *batchv1.JobSpec) (*batchv1.Job, error) {
 	return &batchv1.Job{}, nil
 }
 
+func (m MockJobClient) Delete() error {
+	return nil
+}
+
 func (m MockJobClient) SetJobSchedule(schedule CronSchedule, jobSpec *batchv1.JobSpec) error {
 	return nil
 }
@@ -114,6 +119,10 @@ func (m MockJobClientBroken) Watch() (watch.Interface, error) {
 	return nil, errors.New("cannot get watcher")
 }
 
+func (m MockJobClientBroken) Delete() error {
+	return nil
+}
+
 func (m MockJobClientBroken) SetJobSchedule(schedule CronSchedule, jobSpec *batchv1.JobSpec) error {
 	return errors.New("cannot schedule job")
 }
@@ -158,6 +167,10 @@ func (m MockJobClientRunBroken) Watch() (watch.Interface, error) {
 	return nil, errors.New("cannot get watcher")
 }
 
+func (m MockJobClientRunBroken) Delete() error {
+	return nil
+}
+
 func (m MockJobClientRunBroken) SetJobSchedule(schedule CronSchedule, jobSpec *batchv1.JobSpec) error {
 	return errors.New("cannot set job schedule")
 }
@@ -223,6 +236,10 @@ func (m MockJobClientFailChannel) Watch() (watch.Interface, error) {
 	return MockWatch{}, nil
 }
 
+func (m MockJobClientFailChannel) Delete() error {
+	return nil
+}
+
 func (m MockJobClientFailChannel) SetJobSchedule(schedule CronSchedule, jobSpec *batchv1.JobSpec) error {
 	return nil
 }
@@ -364,3 +381,67 @@ func TestEveryNDays(t *testing.T) {
 		t.Fatalf("Failed to trigger error on invalid every n days schedule")
 	}
 }
+
+func TestGPUJobSpec(t *testing.T) {
+	specs := metadata.KubernetesResourceSpecs{GPURequest: "2", Architecture: "amd64"}
+	rsrcReqs, err := validateJobLimits(specs)
+	if err != nil {
+		t.Fatalf("Failed to validate GPU limits: %s", err)
+	}
+	if qty := rsrcReqs.Requests[GPUResource]; qty.Value() != 2 {
+		t.Fatalf("Expected GPU request of 2, got %s", qty.String())
+	}
+	if qty := rsrcReqs.Limits[GPUResource]; qty.Value() != 2 {
+		t.Fatalf("Expected GPU limit of 2, got %s", qty.String())
+	}
+	jobSpec := newJobSpec(KubernetesRunnerConfig{Image: "test", NumTasks: 1, Specs: specs}, rsrcReqs)
+	podSpec := jobSpec.Template.Spec
+	if podSpec.NodeSelector[ArchLabel] != "amd64" {
+		t.Fatalf("Expected amd64 node selector, got %v", podSpec.NodeSelector)
+	}
+	if len(podSpec.Tolerations) != 1 || podSpec.Tolerations[0].Key != string(GPUResource) {
+		t.Fatalf("Expected GPU toleration, got %v", podSpec.Tolerations)
+	}
+}
+
+func TestDefaultJobSpecHasNoSchedulingConstraints(t *testing.T) {
+	rsrcReqs, err := validateJobLimits(metadata.KubernetesResourceSpecs{})
+	if err != nil {
+		t.Fatalf("Failed to validate empty limits: %s", err)
+	}
+	jobSpec := newJobSpec(KubernetesRunnerConfig{Image: "test", NumTasks: 1}, rsrcReqs)
+	podSpec := jobSpec.Template.Spec
+	if podSpec.NodeSelector != nil || podSpec.Tolerations != nil {
+		t.Fatalf("Expected no node selector or tolerations, got %v %v", podSpec.NodeSelector, podSpec.Tolerations)
+	}
+}
+
+func TestUnsupportedArchitecture(t *testing.T) {
+	if _, err := validateJobLimits(metadata.KubernetesResourceSpecs{Architecture: "riscv64"}); err == nil {
+		t.Fatalf("Failed to trigger error on unsupported architecture")
+	}
+}
+
+func TestImageVariant(t *testing.T) {
+	tests := []struct {
+		name  string
+		image string
+		specs metadata.KubernetesResourceSpecs
+		want  string
+	}{
+		{"No Requirements", "featureformcom/k8s_runner:0.7.0", metadata.KubernetesResourceSpecs{}, "featureformcom/k8s_runner:0.7.0"},
+		{"GPU", "featureformcom/k8s_runner:0.7.0", metadata.KubernetesResourceSpecs{GPURequest: "1"}, "featureformcom/k8s_runner:0.7.0-gpu"},
+		{"GPU Over Arch", "featureformcom/k8s_runner:0.7.0", metadata.KubernetesResourceSpecs{GPURequest: "1", Architecture: "arm64"}, "featureformcom/k8s_runner:0.7.0-gpu"},
+		{"Arm64", "featureformcom/k8s_runner:0.7.0", metadata.KubernetesResourceSpecs{Architecture: "arm64"}, "featureformcom/k8s_runner:0.7.0-arm64"},
+		{"Amd64", "featureformcom/k8s_runner:0.7.0", metadata.KubernetesResourceSpecs{Architecture: "amd64"}, "featureformcom/k8s_runner:0.7.0"},
+		{"Untagged", "featureformcom/k8s_runner", metadata.KubernetesResourceSpecs{GPURequest: "1"}, "featureformcom/k8s_runner:latest-gpu"},
+		{"Registry Port", "localhost:5000/k8s_runner", metadata.KubernetesResourceSpecs{Architecture: "arm64"}, "localhost:5000/k8s_runner:latest-arm64"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ImageVariant(tt.image, tt.specs); got != tt.want {
+				t.Fatalf("ImageVariant() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,108 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package metadata
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	pb "github.com/featureform/metadata/proto"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	tspb "google.golang.org/protobuf/types/known/timestamppb"
+)
+
+type ApprovalState int32
+
+const (
+	DRAFT           ApprovalState = ApprovalState(pb.ApprovalState_DRAFT)
+	APPROVED                      = ApprovalState(pb.ApprovalState_APPROVED)
+	SERVING_ENABLED               = ApprovalState(pb.ApprovalState_SERVING_ENABLED)
+)
+
+func (s ApprovalState) String() string {
+	return pb.ApprovalState_name[int32(s)]
+}
+
+func (s ApprovalState) Serialized() pb.ApprovalState {
+	return pb.ApprovalState(s)
+}
+
+// Approval is a recorded change to a feature variant's approval state.
+type Approval struct {
+	State   ApprovalState
+	User    string
+	Time    time.Time
+	Comment string
+}
+
+type InvalidApprovalTransition struct {
+	ID       ResourceID
+	From, To ApprovalState
+	Reason   string
+}
+
+func (err *InvalidApprovalTransition) Error() string {
+	return fmt.Sprintf("cannot move %s %s variant %s from %s to %s: %s", err.ID.Type, err.ID.Name, err.ID.Variant, err.From, err.To, err.Reason)
+}
+
+func (err *InvalidApprovalTransition) GRPCStatus() *status.Status {
+	return status.New(codes.FailedPrecondition, err.Error())
+}
+
+// checkApprovalTransition returns an error unless the variant can move from
+// one state to the next. States can't be skipped, and the owner of a variant
+// can't approve it themselves.
+func checkApprovalTransition(id ResourceID, owner, user string, from, to ApprovalState) error {
+	var reason string
+	switch {
+	case to == DRAFT:
+		return nil
+	case to == APPROVED && from != DRAFT:
+		reason = "only drafts can be approved"
+	case to == APPROVED && user == owner:
+		reason = "owners cannot approve their own features"
+	case to == SERVING_ENABLED && from != APPROVED:
+		reason = "serving can only be enabled once approved"
+	case to != APPROVED && to != SERVING_ENABLED:
+		reason = "unknown approval state"
+	default:
+		return nil
+	}
+	return &InvalidApprovalTransition{ID: id, From: from, To: to, Reason: reason}
+}
+
+func (serv *MetadataServer) SetFeatureApproval(ctx context.Context, req *pb.FeatureApprovalRequest) (*pb.Empty, error) {
+	serv.Logger.Infow("Setting feature approval", "request", req.String())
+	id := ResourceID{Name: req.GetFeature().GetName(), Variant: req.GetFeature().GetVariant(), Type: FEATURE_VARIANT}
+	if _, err := serv.lookup.Lookup(ResourceID{Name: req.User, Type: USER}); err != nil {
+		return nil, err
+	}
+	res, err := serv.lookup.Lookup(id)
+	if err != nil {
+		return nil, err
+	}
+	variant, ok := res.(*featureVariantResource)
+	if !ok {
+		return nil, fmt.Errorf("resource %v is not a feature variant", id)
+	}
+	from, to := ApprovalState(variant.serialized.ApprovalState), ApprovalState(req.State)
+	if err := checkApprovalTransition(id, variant.serialized.Owner, req.User, from, to); err != nil {
+		return nil, err
+	}
+	variant.serialized.ApprovalState = req.State
+	variant.serialized.Approvals = append(variant.serialized.Approvals, &pb.Approval{
+		State:   req.State,
+		User:    req.User,
+		Time:    tspb.Now(),
+		Comment: req.Comment,
+	})
+	if err := serv.lookup.Set(id, variant); err != nil {
+		serv.Logger.Errorw("Could not set feature approval", "error", err.Error())
+		return nil, err
+	}
+	return &pb.Empty{}, nil
+}
@@ -0,0 +1,82 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package metadata
+
+import (
+	"context"
+	"testing"
+)
+
+func TestCheckApprovalTransition(t *testing.T) {
+	id := ResourceID{"feature", "variant", FEATURE_VARIANT}
+	tests := []struct {
+		name     string
+		user     string
+		from, to ApprovalState
+		valid    bool
+	}{
+		{"Approve Draft", "Other", DRAFT, APPROVED, true},
+		{"Owner Approves", "Featureform", DRAFT, APPROVED, false},
+		{"Enable Approved", "Featureform", APPROVED, SERVING_ENABLED, true},
+		{"Enable Draft", "Other", DRAFT, SERVING_ENABLED, false},
+		{"Approve Enabled", "Other", SERVING_ENABLED, APPROVED, false},
+		{"Revoke Enabled", "Featureform", SERVING_ENABLED, DRAFT, true},
+		{"Unknown State", "Other", DRAFT, ApprovalState(7), false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := checkApprovalTransition(id, "Featureform", tt.user, tt.from, tt.to)
+			if (err == nil) != tt.valid {
+				t.Fatalf("checkApprovalTransition() error = %v, expected valid %v", err, tt.valid)
+			}
+		})
+	}
+}
+
+func TestSetFeatureApproval(t *testing.T) {
+	ctx := testContext{
+		Defs: filledResourceDefs(),
+	}
+	client, err := ctx.Create(t)
+	if err != nil {
+		t.Fatalf("Failed to create resources: %s", err)
+	}
+	defer ctx.Destroy()
+	id := NameVariant{"feature", "variant"}
+	variant, err := client.GetFeatureVariant(context.Background(), id)
+	if err != nil {
+		t.Fatalf("Failed to get feature variant: %s", err)
+	}
+	if variant.ApprovalState() != DRAFT {
+		t.Fatalf("Expected new variant to be a draft, got %s", variant.ApprovalState())
+	}
+	if err := client.SetFeatureApproval(context.Background(), id, SERVING_ENABLED, "Other", ""); err == nil {
+		t.Fatalf("Succeeded in enabling serving of an unapproved feature")
+	}
+	if err := client.SetFeatureApproval(context.Background(), id, APPROVED, "Unknown", ""); err == nil {
+		t.Fatalf("Succeeded in approving a feature as an unknown user")
+	}
+	if err := client.SetFeatureApproval(context.Background(), id, APPROVED, "Other", "looks good"); err != nil {
+		t.Fatalf("Failed to approve feature: %s", err)
+	}
+	if err := client.SetFeatureApproval(context.Background(), id, SERVING_ENABLED, "Featureform", ""); err != nil {
+		t.Fatalf("Failed to enable serving: %s", err)
+	}
+	variant, err = client.GetFeatureVariant(context.Background(), id)
+	if err != nil {
+		t.Fatalf("Failed to get feature variant: %s", err)
+	}
+	if variant.ApprovalState() != SERVING_ENABLED {
+		t.Fatalf("Expected %s, got %s", SERVING_ENABLED, variant.ApprovalState())
+	}
+	approvals := variant.Approvals()
+	if len(approvals) != 2 {
+		t.Fatalf("Expected 2 approvals, got %d", len(approvals))
+	}
+	first := approvals[0]
+	if first.State != APPROVED || first.User != "Other" || first.Comment != "looks good" || first.Time.IsZero() {
+		t.Fatalf("Wrong approval recorded: %+v", first)
+	}
+}
@@ -0,0 +1,433 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package metadata
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	pb "github.com/featureform/metadata/proto"
+	"google.golang.org/protobuf/proto"
+)
+
+// bulkItem is a resource from a bulk create request and the call that
+// creates it.
+type bulkItem struct {
+	index  int
+	res    Resource
+	create func(context.Context) error
+}
+
+func (serv *MetadataServer) newBulkItem(index int, def *pb.ResourceDefinition) (*bulkItem, error) {
+	item := &bulkItem{index: index}
+	switch casted := def.GetResource().(type) {
+	case *pb.ResourceDefinition_User:
+		item.res = &userResource{casted.User}
+		item.create = func(ctx context.Context) error {
+			_, err := serv.CreateUser(ctx, casted.User)
+			return err
+		}
+	case *pb.ResourceDefinition_Provider:
+		item.res = &providerResource{casted.Provider}
+		item.create = func(ctx context.Context) error {
+			_, err := serv.CreateProvider(ctx, casted.Provider)
+			return err
+		}
+	case *pb.ResourceDefinition_Entity:
+		item.res = &entityResource{casted.Entity}
+		item.create = func(ctx context.Context) error {
+			_, err := serv.CreateEntity(ctx, casted.Entity)
+			return err
+		}
+	case *pb.ResourceDefinition_SourceVariant:
+		item.res = &sourceVariantResource{casted.SourceVariant}
+		item.create = func(ctx context.Context) error {
+			_, err := serv.CreateSourceVariant(ctx, casted.SourceVariant)
+			return err
+		}
+	case *pb.ResourceDefinition_FeatureVariant:
+		item.res = &featureVariantResource{casted.FeatureVariant}
+		item.create = func(ctx context.Context) error {
+			_, err := serv.CreateFeatureVariant(ctx, casted.FeatureVariant)
+			return err
+		}
+	case *pb.ResourceDefinition_LabelVariant:
+		item.res = &labelVariantResource{casted.LabelVariant}
+		item.create = func(ctx context.Context) error {
+			_, err := serv.CreateLabelVariant(ctx, casted.LabelVariant)
+			return err
+		}
+	case *pb.ResourceDefinition_TrainingSetVariant:
+		item.res = &trainingSetVariantResource{casted.TrainingSetVariant}
+		item.create = func(ctx context.Context) error {
+			_, err := serv.CreateTrainingSetVariant(ctx, casted.TrainingSetVariant)
+			return err
+		}
+	case *pb.ResourceDefinition_Model:
+		item.res = &modelResource{casted.Model}
+		item.create = func(ctx context.Context) error {
+			_, err := serv.CreateModel(ctx, casted.Model)
+			return err
+		}
+	case nil:
+		return nil, fmt.Errorf("resource definition is empty")
+	default:
+		return nil, fmt.Errorf("resource definition has unexpected type %T", casted)
+	}
+	return item, nil
+}
+
+// BulkCreate validates every resource in the request and returns a result
+// for each. Resources are only created if all of them are valid and the
+// request isn't validate only. Creation stops at the first failure.
+func (serv *MetadataServer) BulkCreate(ctx context.Context, req *pb.BulkCreateRequest) (*pb.BulkCreateResponse, error) {
+	serv.Logger.Infow("Bulk creating resources", "count", len(req.Resources), "validate_only", req.ValidateOnly)
+	batch := &bulkBatch{
+		ResourceLookup: serv.lookup,
+		items:          make(map[ResourceID]*bulkItem),
+		planned:        make(map[ResourceID]bool),
+	}
+	items := make([]*bulkItem, len(req.Resources))
+	results := make([]*pb.BulkCreateResult, len(req.Resources))
+	valid := true
+	for i, def := range req.Resources {
+		results[i] = &pb.BulkCreateResult{Index: int32(i)}
+		item, err := serv.newBulkItem(i, def)
+		if err != nil {
+			results[i].Errors = []string{err.Error()}
+			valid = false
+			continue
+		}
+		items[i] = item
+		id := item.res.ID()
+		results[i].ResourceId = &pb.ResourceID{Resource: id.Proto(), ResourceType: id.Type.Serialized()}
+		if errs := batch.validate(item); len(errs) > 0 {
+			results[i].Errors = errs
+			valid = false
+		}
+	}
+	if !valid || req.ValidateOnly {
+		return &pb.BulkCreateResponse{Results: results}, nil
+	}
+	for i, item := range items {
+		if err := item.create(ctx); err != nil {
+			serv.Logger.Errorw("Bulk create failed", "index", i, "id", item.res.ID(), "error", err)
+			results[i].Errors = append(results[i].Errors, err.Error())
+			break
+		}
+		results[i].Created = true
+	}
+	return &pb.BulkCreateResponse{Results: results}, nil
+}
+
+// bulkBatch is the resources of a bulk request seen so far, layered over the
+// existing resources. Its Submap only checks that every ID exists or will be
+// created earlier in the request; it's used to validate a resource's
+// Dependencies before anything is written.
+type bulkBatch struct {
+	ResourceLookup
+	items   map[ResourceID]*bulkItem
+	planned map[ResourceID]bool
+}
+
+type missingReferences struct {
+	IDs []ResourceID
+}
+
+func (err *missingReferences) Error() string {
+	missing := make([]string, len(err.IDs))
+	for i, id := range err.IDs {
+		missing[i] = fmt.Sprintf("%s %s", id.Type, NameVariant{id.Name, id.Variant}.ClientString())
+		if id.Variant == "" {
+			missing[i] = fmt.Sprintf("%s %s", id.Type, id.Name)
+		}
+	}
+	return fmt.Sprintf("references resources that don't exist: %s", strings.Join(missing, ", "))
+}
+
+func (batch *bulkBatch) Submap(ids []ResourceID) (ResourceLookup, error) {
+	resources := make(localResourceLookup)
+	var missing []ResourceID
+	for _, id := range ids {
+		if batch.planned[id] {
+			continue
+		}
+		res, err := batch.ResourceLookup.Lookup(id)
+		if _, notFound := err.(*ResourceNotFound); notFound {
+			missing = append(missing, id)
+			continue
+		} else if err != nil {
+			return nil, err
+		}
+		resources[id] = res
+	}
+	if len(missing) > 0 {
+		return nil, &missingReferences{missing}
+	}
+	return resources, nil
+}
+
+// definition returns the proto of a resource earlier in the request, or of
+// an existing resource.
+func (batch *bulkBatch) definition(id ResourceID) (proto.Message, error) {
+	if item, has := batch.items[id]; has {
+		return item.res.Proto(), nil
+	}
+	res, err := batch.ResourceLookup.Lookup(id)
+	if err != nil {
+		return nil, err
+	}
+	return res.Proto(), nil
+}
+
+// validate checks item against the resources before it and records it as
+// planned. Fields that the API server would otherwise fill in from other
+// resources, like a label's provider, are resolved first.
+func (batch *bulkBatch) validate(item *bulkItem) []string {
+	id := item.res.ID()
+	var errs []string
+	if err := resourceNamedSafely(id); err != nil {
+		errs = append(errs, err.Error())
+	}
+	if first, has := batch.items[id]; has {
+		errs = append(errs, fmt.Sprintf("defined more than once, first at index %d", first.index))
+		return errs
+	}
+	if err := batch.resolve(item.res.Proto()); err != nil {
+		errs = append(errs, err.Error())
+	}
+	if parent, hasParent := id.Parent(); hasParent {
+		batch.planned[parent] = true
+	}
+	if missing := missingFields(item.res.Proto()); len(missing) > 0 {
+		// Dependencies can't be found without these fields.
+		errs = append(errs, fmt.Sprintf("required fields are not set: %s", strings.Join(missing, ", ")))
+	} else {
+		if _, err := item.res.Dependencies(batch); err != nil {
+			var missing *missingReferences
+			if errors.As(err, &missing) {
+				err = missing
+			}
+			errs = append(errs, err.Error())
+		}
+		if _, err := batch.Submap(transformationInputs(item.res.Proto())); err != nil {
+			errs = append(errs, err.Error())
+		}
+	}
+	errs = append(errs, batch.checkTypes(item.res.Proto())...)
+	if provider, isProvider := item.res.Proto().(*pb.Provider); isProvider {
+		if err := checkProviderTLS(provider); err != nil {
+			errs = append(errs, err.Error())
+		}
+	}
+	if existing, err := batch.ResourceLookup.Lookup(id); err == nil && definitionConflicts(existing.Proto(), item.res.Proto()) {
+		errs = append(errs, "conflicts with the existing definition of this variant")
+	}
+	batch.items[id] = item
+	batch.planned[id] = true
+	return errs
+}
+
+func (batch *bulkBatch) resolve(msg proto.Message) error {
+	switch casted := msg.(type) {
+	case *pb.SourceVariant:
+		if streaming := casted.GetTransformation().GetStreamingTransformation(); streaming != nil {
+			sources, err := SQLTemplateSources(streaming.Query)
+			if err != nil {
+				return err
+			}
+			streaming.Source = sources
+			return nil
+		}
+		sql := casted.GetTransformation().GetSQLTransformation()
+		if sql == nil {
+			return nil
+		}
+		sources, err := SQLTemplateSources(sql.Query)
+		if err != nil {
+			return err
+		}
+		sql.Source = sources
+	case *pb.LabelVariant:
+		if casted.Provider != "" || casted.Source == nil {
+			return nil
+		}
+		source, err := batch.definition(ResourceID{casted.Source.Name, casted.Source.Variant, SOURCE_VARIANT})
+		if err == nil {
+			casted.Provider = source.(*pb.SourceVariant).Provider
+		}
+	case *pb.TrainingSetVariant:
+		if casted.Provider != "" || casted.Label == nil {
+			return nil
+		}
+		label, err := batch.definition(ResourceID{casted.Label.Name, casted.Label.Variant, LABEL_VARIANT})
+		if err == nil {
+			casted.Provider = label.(*pb.LabelVariant).Provider
+		}
+	}
+	return nil
+}
+
+func missingFields(msg proto.Message) []string {
+	var missing []string
+	switch casted := msg.(type) {
+	case *pb.FeatureVariant:
+		if PRECOMPUTED.Equals(casted.Mode) && casted.Source == nil {
+			missing = append(missing, "source")
+		}
+	case *pb.LabelVariant:
+		if casted.Source == nil {
+			missing = append(missing, "source")
+		}
+	case *pb.TrainingSetVariant:
+		if casted.Label == nil {
+			missing = append(missing, "label")
+		}
+	}
+	return missing
+}
+
+// transformationInputs returns the sources a transformation reads from,
+// which aren't part of its Dependencies.
+func transformationInputs(msg proto.Message) []ResourceID {
+	source, isSource := msg.(*pb.SourceVariant)
+	if !isSource {
+		return nil
+	}
+	transformation := source.GetTransformation()
+	inputs := append(transformation.GetSQLTransformation().GetSource(), transformation.GetDFTransformation().GetInputs()...)
+	inputs = append(inputs, transformation.GetDBTTransformation().GetInputs()...)
+	inputs = append(inputs, transformation.GetStreamingTransformation().GetSource()...)
+	ids := make([]ResourceID, len(inputs))
+	for i, input := range inputs {
+		ids[i] = ResourceID{Name: input.Name, Variant: input.Variant, Type: SOURCE_VARIANT}
+	}
+	return ids
+}
+
+func (batch *bulkBatch) checkTypes(msg proto.Message) []string {
+	var errs []string
+	// Resources in a batch refer to each other by variant, so their variants
+	// have to be known up front.
+	if variant, ok := msg.(variantMessage); ok {
+		if (fetchPropertiesFn{variant}).Properties()[VariantStrategyProperty] != "" {
+			errs = append(errs, fmt.Sprintf("%s is not supported in bulk registration", VariantStrategyProperty))
+		}
+	}
+	switch casted := msg.(type) {
+	case *pb.Entity:
+		if _, err := ParseEntityKeyRules(fetchPropertiesFn{casted}.Properties()); err != nil {
+			errs = append(errs, err.Error())
+		}
+	case *pb.SourceVariant:
+		if _, err := ParseTimestampTimezone(fetchPropertiesFn{casted}.Properties()); err != nil {
+			errs = append(errs, err.Error())
+		}
+	case *pb.FeatureVariant:
+		if PRECOMPUTED.Equals(casted.Mode) && casted.Type == "" {
+			errs = append(errs, "feature type is not set")
+		}
+		if casted.IsEmbedding && casted.Dimension <= 0 {
+			errs = append(errs, fmt.Sprintf("embedding dimension must be positive: %d", casted.Dimension))
+		}
+		if _, err := ParseDistanceMetric(fetchPropertiesFn{casted}.Properties()); err != nil {
+			errs = append(errs, err.Error())
+		}
+		if _, err := ParseIndexOptions(fetchPropertiesFn{casted}.Properties()); err != nil {
+			errs = append(errs, err.Error())
+		}
+		if _, err := ParseVectorStorage(fetchPropertiesFn{casted}.Properties()); err != nil {
+			errs = append(errs, err.Error())
+		}
+		if _, err := ParseVectorNames(fetchPropertiesFn{casted}.Properties()); err != nil {
+			errs = append(errs, err.Error())
+		}
+	case *pb.LabelVariant:
+		if casted.Type == "" {
+			errs = append(errs, "label type is not set")
+		}
+	case *pb.TrainingSetVariant:
+		if casted.Label == nil {
+			return errs
+		}
+		// Missing labels and features are already reported as missing
+		// references.
+		label, err := batch.definition(ResourceID{casted.Label.Name, casted.Label.Variant, LABEL_VARIANT})
+		if err != nil {
+			return errs
+		}
+		entity := label.(*pb.LabelVariant).Entity
+		for _, nv := range casted.Features {
+			feature, err := batch.definition(ResourceID{nv.Name, nv.Variant, FEATURE_VARIANT})
+			if err != nil {
+				continue
+			}
+			if featureEntity := feature.(*pb.FeatureVariant).Entity; featureEntity != entity {
+				errs = append(errs, fmt.Sprintf("feature %s has entity %s but label has entity %s", NameVariant{nv.Name, nv.Variant}.ClientString(), featureEntity, entity))
+			}
+		}
+	}
+	return errs
+}
+
+// definitionConflicts returns true if updated redefines a variant that
+// already exists. Fields that re-registering a variant may change, such as
+// its tags and properties, are ignored.
+func definitionConflicts(existing, updated proto.Message) bool {
+	existingDef, updatedDef := definitionOf(existing), definitionOf(updated)
+	if existingDef == nil || updatedDef == nil {
+		return false
+	}
+	return !proto.Equal(existingDef, updatedDef)
+}
+
+// definitionOf returns a copy of the variant with only the fields that
+// define it set, or nil if msg isn't a variant.
+func definitionOf(msg proto.Message) proto.Message {
+	switch v := msg.(type) {
+	case *pb.FeatureVariant:
+		return &pb.FeatureVariant{Source: v.Source, Type: v.Type, Entity: v.Entity, Provider: v.Provider, Location: v.Location, Mode: v.Mode, IsEmbedding: v.IsEmbedding, Dimension: v.Dimension}
+	case *pb.LabelVariant:
+		return &pb.LabelVariant{Source: v.Source, Type: v.Type, Entity: v.Entity, Provider: v.Provider, Location: v.Location}
+	case *pb.SourceVariant:
+		return &pb.SourceVariant{Provider: v.Provider, Definition: v.Definition}
+	case *pb.TrainingSetVariant:
+		return &pb.TrainingSetVariant{Provider: v.Provider, Label: v.Label, Features: v.Features}
+	default:
+		return nil
+	}
+}
+
+// SQLTemplateSources returns the sources referenced by a SQL transformation,
+// written as {{ name.variant }} in its query.
+func SQLTemplateSources(query string) ([]*pb.NameVariant, error) {
+	if strings.TrimSpace(query) == "" {
+		return nil, fmt.Errorf("SQL transformation query is empty")
+	}
+	sources := make([]*pb.NameVariant, 0)
+	rest := query
+	for {
+		start := strings.Index(rest, "{{")
+		end := strings.Index(rest, "}}")
+		if start == -1 && end == -1 {
+			return sources, nil
+		}
+		if start == -1 || end < start {
+			return nil, fmt.Errorf("SQL transformation query has unmatched }}: %s", query)
+		}
+		if end == -1 || strings.Contains(rest[start+2:end], "{{") {
+			return nil, fmt.Errorf("SQL transformation query has unmatched {{: %s", query)
+		}
+		key := strings.TrimSpace(rest[start+2 : end])
+		name, variant, hasVariant := strings.Cut(key, ".")
+		if !hasVariant || name == "" || variant == "" {
+			return nil, fmt.Errorf("SQL transformation source %q must be written as name.variant", key)
+		}
+		sources = append(sources, &pb.NameVariant{Name: name, Variant: variant})
+		rest = rest[end+2:]
+	}
+}
@@ -0,0 +1,143 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package metadata
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+
+	pb "github.com/featureform/metadata/proto"
+)
+
+func TestSQLTemplateSources(t *testing.T) {
+	tests := []struct {
+		name     string
+		query    string
+		expected []*pb.NameVariant
+		wantErr  bool
+	}{
+		{"No Sources", "SELECT 1", []*pb.NameVariant{}, false},
+		{"Sources", "SELECT * FROM {{ a.v1 }} JOIN {{b.v2}}", []*pb.NameVariant{{Name: "a", Variant: "v1"}, {Name: "b", Variant: "v2"}}, false},
+		{"Empty", "  ", nil, true},
+		{"No Variant", "SELECT * FROM {{ a }}", nil, true},
+		{"Unclosed", "SELECT * FROM {{ a.v1", nil, true},
+		{"Nested", "SELECT * FROM {{ {{ a.v1 }}", nil, true},
+		{"Unopened", "SELECT * FROM a.v1 }}", nil, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sources, err := SQLTemplateSources(tt.query)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("SQLTemplateSources() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if len(sources) != len(tt.expected) {
+				t.Fatalf("Expected %d sources, got %d", len(tt.expected), len(sources))
+			}
+			for i := range sources {
+				if sources[i].Name != tt.expected[i].Name || sources[i].Variant != tt.expected[i].Variant {
+					t.Fatalf("Expected source %v, got %v", tt.expected[i], sources[i])
+				}
+			}
+		})
+	}
+}
+
+func TestBulkCreate(t *testing.T) {
+	ctx := testContext{}
+	client, err := ctx.Create(t)
+	if err != nil {
+		t.Fatalf("Failed to create context: %s", err)
+	}
+	defer ctx.Destroy()
+	defs := filledResourceDefs()
+	results, err := client.BulkCreate(context.Background(), defs, false)
+	if err != nil {
+		t.Fatalf("Failed to bulk create: %s", err)
+	}
+	if len(results) != len(defs) {
+		t.Fatalf("Expected %d results, got %d", len(defs), len(results))
+	}
+	for i, result := range results {
+		if len(result.Errors) > 0 || !result.Created {
+			t.Fatalf("Resource %d not created: %v", i, result.Errors)
+		}
+	}
+	if _, err := client.GetTrainingSetVariant(context.Background(), NameVariant{"training-set", "variant"}); err != nil {
+		t.Fatalf("Failed to get bulk created training set: %s", err)
+	}
+	// Applying the same definitions again is allowed.
+	results, err = client.BulkCreate(context.Background(), defs, true)
+	if err != nil {
+		t.Fatalf("Failed to validate existing resources: %s", err)
+	}
+	for i, result := range results {
+		if len(result.Errors) > 0 {
+			t.Fatalf("Existing resource %d failed validation: %v", i, result.Errors)
+		}
+	}
+}
+
+func TestBulkCreateValidation(t *testing.T) {
+	ctx := testContext{}
+	client, err := ctx.Create(t)
+	if err != nil {
+		t.Fatalf("Failed to create context: %s", err)
+	}
+	defer ctx.Destroy()
+	defs := filledResourceDefs()
+	var invalid []int
+	for i, def := range defs {
+		switch casted := def.(type) {
+		case SourceDef:
+			if casted.Variant == "var" {
+				casted.Definition = TransformationSource{
+					TransformationType: SQLTransformationType{Query: "SELECT * FROM {{ missing.variant }}"},
+				}
+				defs[i] = casted
+				invalid = append(invalid, i)
+			}
+		case FeatureDef:
+			if casted.Name == "feature2" {
+				casted.Entity = "item"
+				defs[i] = casted
+			}
+		case TrainingSetDef:
+			if casted.Variant == "variant2" {
+				// Now has a feature with a different entity than its label.
+				invalid = append(invalid, i)
+			}
+		case ModelDef:
+			defs = append(defs, casted)
+			invalid = append(invalid, len(defs)-1)
+		}
+	}
+	results, err := client.BulkCreate(context.Background(), defs, false)
+	if err != nil {
+		t.Fatalf("Failed to bulk create: %s", err)
+	}
+	var failed []int
+	for i, result := range results {
+		if result.Created {
+			t.Fatalf("Resource %d created despite invalid request", i)
+		}
+		if len(result.Errors) > 0 {
+			failed = append(failed, i)
+		}
+	}
+	if !reflect.DeepEqual(failed, invalid) {
+		t.Fatalf("Expected failures at %v, got %v: %v", invalid, failed, results)
+	}
+	if !strings.Contains(results[invalid[0]].Errors[0], "missing.variant") {
+		t.Fatalf("Expected missing source in error, got %v", results[invalid[0]].Errors)
+	}
+	if _, err := client.GetUser(context.Background(), "Featureform"); err == nil {
+		t.Fatalf("Resources were created despite invalid request")
+	}
+}
@@ -11,7 +11,10 @@ import (
 	"reflect"
 	"time"
 
+	cfg "github.com/featureform/config"
 	pb "github.com/featureform/metadata/proto"
+	pc "github.com/featureform/provider/provider_config"
+	pt "github.com/featureform/provider/provider_type"
 	"go.uber.org/zap"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/credentials/insecure"
@@ -104,6 +107,19 @@ func (client *Client) RequestScheduleChange(ctx context.Context, resID ResourceI
 	return err
 }
 
+// SetFeatureApproval moves a feature variant to a new approval state on
+// behalf of user. The change is recorded in the variant's approval history.
+func (client *Client) SetFeatureApproval(ctx context.Context, id NameVariant, state ApprovalState, user, comment string) error {
+	req := pb.FeatureApprovalRequest{
+		Feature: id.Serialize(),
+		State:   state.Serialized(),
+		User:    user,
+		Comment: comment,
+	}
+	_, err := client.grpcConn.SetFeatureApproval(ctx, &req)
+	return err
+}
+
 func (client *Client) SetStatus(ctx context.Context, resID ResourceID, status ResourceStatus, errorMessage string) error {
 	nameVariant := pb.NameVariant{Name: resID.Name, Variant: resID.Variant}
 	resourceID := pb.ResourceID{Resource: &nameVariant, ResourceType: resID.Type.Serialized()}
@@ -113,6 +129,22 @@ func (client *Client) SetStatus(ctx context.Context, resID ResourceID, status Re
 	return err
 }
 
+// SetStreamingOffsets records the offsets of the Kafka sources that a
+// streaming transformation's job has processed up to, as of a batch of its
+// checkpoint.
+func (client *Client) SetStreamingOffsets(ctx context.Context, id NameVariant, checkpointBatch int64, offsets []KafkaOffset) error {
+	req := pb.StreamingOffsetsRequest{
+		Source:          &pb.NameVariant{Name: id.Name, Variant: id.Variant},
+		CheckpointBatch: checkpointBatch,
+		Offsets:         make([]*pb.KafkaOffset, len(offsets)),
+	}
+	for i, offset := range offsets {
+		req.Offsets[i] = &pb.KafkaOffset{Topic: offset.Topic, Partition: offset.Partition, Offset: offset.Offset}
+	}
+	_, err := client.grpcConn.SetStreamingOffsets(ctx, &req)
+	return err
+}
+
 func (client *Client) CreateAll(ctx context.Context, defs []ResourceDef) error {
 	for _, def := range defs {
 		if err := client.Create(ctx, def); err != nil {
@@ -145,6 +177,94 @@ func (client *Client) Create(ctx context.Context, def ResourceDef) error {
 	}
 }
 
+// BulkCreateResult is the outcome of validating, and possibly creating, one
+// resource of a BulkCreate call.
+type BulkCreateResult struct {
+	Index   int
+	ID      ResourceID
+	Errors  []string
+	Created bool
+}
+
+// BulkCreate validates all of defs in one call and creates them, in order,
+// only if every one is valid. A result is returned for each def.
+func (client *Client) BulkCreate(ctx context.Context, defs []ResourceDef, validateOnly bool) ([]BulkCreateResult, error) {
+	req := &pb.BulkCreateRequest{
+		Resources:    make([]*pb.ResourceDefinition, len(defs)),
+		ValidateOnly: validateOnly,
+	}
+	for i, def := range defs {
+		serialized, err := serializeResourceDef(def)
+		if err != nil {
+			return nil, fmt.Errorf("could not serialize resource %d: %w", i, err)
+		}
+		req.Resources[i] = serialized
+	}
+	resp, err := client.grpcConn.BulkCreate(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	results := make([]BulkCreateResult, len(resp.Results))
+	for i, result := range resp.Results {
+		results[i] = BulkCreateResult{
+			Index: int(result.Index),
+			ID: ResourceID{
+				Name:    result.GetResourceId().GetResource().GetName(),
+				Variant: result.GetResourceId().GetResource().GetVariant(),
+				Type:    ResourceType(result.GetResourceId().GetResourceType()),
+			},
+			Errors:  result.Errors,
+			Created: result.Created,
+		}
+	}
+	return results, nil
+}
+
+func serializeResourceDef(def ResourceDef) (*pb.ResourceDefinition, error) {
+	serialized := &pb.ResourceDefinition{}
+	var err error
+	switch casted := def.(type) {
+	case FeatureDef:
+		var variant *pb.FeatureVariant
+		variant, err = casted.serialize()
+		serialized.Resource = &pb.ResourceDefinition_FeatureVariant{FeatureVariant: variant}
+	case LabelDef:
+		var variant *pb.LabelVariant
+		variant, err = casted.serialize()
+		serialized.Resource = &pb.ResourceDefinition_LabelVariant{LabelVariant: variant}
+	case TrainingSetDef:
+		var variant *pb.TrainingSetVariant
+		variant, err = casted.serialize()
+		serialized.Resource = &pb.ResourceDefinition_TrainingSetVariant{TrainingSetVariant: variant}
+	case SourceDef:
+		var variant *pb.SourceVariant
+		variant, err = casted.serialize()
+		serialized.Resource = &pb.ResourceDefinition_SourceVariant{SourceVariant: variant}
+	case UserDef:
+		var user *pb.User
+		user, err = casted.serialize()
+		serialized.Resource = &pb.ResourceDefinition_User{User: user}
+	case ProviderDef:
+		var provider *pb.Provider
+		provider, err = casted.serialize()
+		serialized.Resource = &pb.ResourceDefinition_Provider{Provider: provider}
+	case EntityDef:
+		var entity *pb.Entity
+		entity, err = casted.serialize()
+		serialized.Resource = &pb.ResourceDefinition_Entity{Entity: entity}
+	case ModelDef:
+		var model *pb.Model
+		model, err = casted.serialize()
+		serialized.Resource = &pb.ResourceDefinition_Model{Model: model}
+	default:
+		return nil, fmt.Errorf("%T not implemented in BulkCreate", casted)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return serialized, nil
+}
+
 func (client *Client) ListFeatures(ctx context.Context) ([]*Feature, error) {
 	stream, err := client.grpcConn.ListFeatures(ctx, &pb.Empty{})
 	if err != nil {
@@ -269,6 +389,15 @@ func (def FeatureDef) ResourceType() ResourceType {
 }
 
 func (client *Client) CreateFeatureVariant(ctx context.Context, def FeatureDef) error {
+	serialized, err := def.serialize()
+	if err != nil {
+		return err
+	}
+	_, err = client.grpcConn.CreateFeatureVariant(ctx, serialized)
+	return err
+}
+
+func (def FeatureDef) serialize() (*pb.FeatureVariant, error) {
 	serialized := &pb.FeatureVariant{
 		Name:        def.Name,
 		Variant:     def.Variant,
@@ -291,12 +420,11 @@ func (client *Client) CreateFeatureVariant(ctx context.Context, def FeatureDef)
 	case PythonFunction:
 		serialized.Location = def.Location.(PythonFunction).SerializePythonFunction()
 	case nil:
-		return fmt.Errorf("FeatureDef Columns not set")
+		return nil, fmt.Errorf("FeatureDef Columns not set")
 	default:
-		return fmt.Errorf("FeatureDef Columns has unexpected type %T", x)
+		return nil, fmt.Errorf("FeatureDef Columns has unexpected type %T", x)
 	}
-	_, err := client.grpcConn.CreateFeatureVariant(ctx, serialized)
-	return err
+	return serialized, nil
 }
 
 type featureStream interface {
@@ -387,6 +515,15 @@ func (def LabelDef) ResourceType() ResourceType {
 }
 
 func (client *Client) CreateLabelVariant(ctx context.Context, def LabelDef) error {
+	serialized, err := def.serialize()
+	if err != nil {
+		return err
+	}
+	_, err = client.grpcConn.CreateLabelVariant(ctx, serialized)
+	return err
+}
+
+func (def LabelDef) serialize() (*pb.LabelVariant, error) {
 	serialized := &pb.LabelVariant{
 		Name:        def.Name,
 		Variant:     def.Variant,
@@ -404,12 +541,11 @@ func (client *Client) CreateLabelVariant(ctx context.Context, def LabelDef) erro
 	case ResourceVariantColumns:
 		serialized.Location = def.Location.(ResourceVariantColumns).SerializeLabelColumns()
 	case nil:
-		return fmt.Errorf("LabelDef Primary not set")
+		return nil, fmt.Errorf("LabelDef Primary not set")
 	default:
-		return fmt.Errorf("LabelDef Primary has unexpected type %T", x)
+		return nil, fmt.Errorf("LabelDef Primary has unexpected type %T", x)
 	}
-	_, err := client.grpcConn.CreateLabelVariant(ctx, serialized)
-	return err
+	return serialized, nil
 }
 
 func (client *Client) GetLabelVariants(ctx context.Context, ids []NameVariant) ([]*LabelVariant, error) {
@@ -524,6 +660,15 @@ func (def TrainingSetDef) ResourceType() ResourceType {
 }
 
 func (client *Client) CreateTrainingSetVariant(ctx context.Context, def TrainingSetDef) error {
+	serialized, err := def.serialize()
+	if err != nil {
+		return err
+	}
+	_, err = client.grpcConn.CreateTrainingSetVariant(ctx, serialized)
+	return err
+}
+
+func (def TrainingSetDef) serialize() (*pb.TrainingSetVariant, error) {
 	serialized := &pb.TrainingSetVariant{
 		Name:        def.Name,
 		Variant:     def.Variant,
@@ -537,8 +682,7 @@ func (client *Client) CreateTrainingSetVariant(ctx context.Context, def Training
 		Tags:        &pb.Tags{Tag: def.Tags},
 		Properties:  def.Properties.Serialize(),
 	}
-	_, err := client.grpcConn.CreateTrainingSetVariant(ctx, serialized)
-	return err
+	return serialized, nil
 }
 
 func (client *Client) GetTrainingSetVariant(ctx context.Context, id NameVariant) (*TrainingSetVariant, error) {
@@ -661,9 +805,18 @@ func (t PrimaryDataSource) isSourceType() bool {
 func (t SQLTransformationType) IsTransformationType() bool {
 	return true
 }
+func (t DBTTransformationType) IsTransformationType() bool {
+	return true
+}
+func (t StreamingTransformationType) IsTransformationType() bool {
+	return true
+}
 func (t SQLTable) isPrimaryData() bool {
 	return true
 }
+func (t KafkaTopic) isPrimaryData() bool {
+	return true
+}
 
 type TransformationSource struct {
 	TransformationType TransformationType
@@ -678,6 +831,25 @@ type SQLTransformationType struct {
 	Sources NameVariants
 }
 
+// DBTTransformationType is a dbt model that's run to build a transformation.
+// Table is the table the model builds, and defaults to the model's name.
+type DBTTransformationType struct {
+	ProjectDir  string
+	ProfilesDir string
+	Target      string
+	Model       string
+	Table       string
+	Inputs      NameVariants
+}
+
+// StreamingTransformationType is a SQL query that runs as a long-lived Spark
+// Structured Streaming job. Trigger is how often its micro-batches run.
+type StreamingTransformationType struct {
+	Query   string
+	Sources NameVariants
+	Trigger string
+}
+
 type PrimaryDataSource struct {
 	Location PrimaryDataLocationType
 }
@@ -690,6 +862,22 @@ type SQLTable struct {
 	Name string
 }
 
+// KafkaTopic is a topic on a Kafka provider. ValueFormat is how its messages
+// are decoded, and StartingOffsets is where jobs without a checkpoint start
+// reading it.
+type KafkaTopic struct {
+	Topic           string
+	ValueFormat     string
+	StartingOffsets string
+}
+
+// KafkaOffset is the next offset to read from a partition of a topic.
+type KafkaOffset struct {
+	Topic     string
+	Partition int32
+	Offset    int64
+}
+
 type TransformationSourceDef struct {
 	Def interface{}
 }
@@ -706,6 +894,29 @@ func (s TransformationSource) Serialize() (*pb.SourceVariant_Transformation, err
 				},
 			},
 		}
+	case DBTTransformationType:
+		transformation = &pb.Transformation{
+			Type: &pb.Transformation_DBTTransformation{
+				DBTTransformation: &pb.DBTTransformation{
+					ProjectDir:  x.ProjectDir,
+					ProfilesDir: x.ProfilesDir,
+					Target:      x.Target,
+					Model:       x.Model,
+					Table:       x.Table,
+					Inputs:      x.Inputs.Serialize(),
+				},
+			},
+		}
+	case StreamingTransformationType:
+		transformation = &pb.Transformation{
+			Type: &pb.Transformation_StreamingTransformation{
+				StreamingTransformation: &pb.StreamingTransformation{
+					Query:   x.Query,
+					Source:  x.Sources.Serialize(),
+					Trigger: x.Trigger,
+				},
+			},
+		}
 	case nil:
 		return nil, fmt.Errorf("TransformationSource Type not set")
 	default:
@@ -727,6 +938,16 @@ func (s PrimaryDataSource) Serialize() (*pb.SourceVariant_PrimaryData, error) {
 				},
 			},
 		}
+	case KafkaTopic:
+		primaryData = &pb.PrimaryData{
+			Location: &pb.PrimaryData_KafkaTopic{
+				KafkaTopic: &pb.KafkaTopic{
+					Topic:           x.Topic,
+					ValueFormat:     x.ValueFormat,
+					StartingOffsets: x.StartingOffsets,
+				},
+			},
+		}
 	case nil:
 		return nil, fmt.Errorf("PrimaryDataSource Type not set")
 	default:
@@ -742,6 +963,15 @@ func (def SourceDef) ResourceType() ResourceType {
 }
 
 func (client *Client) CreateSourceVariant(ctx context.Context, def SourceDef) error {
+	serialized, err := def.serialize()
+	if err != nil {
+		return err
+	}
+	_, err = client.grpcConn.CreateSourceVariant(ctx, serialized)
+	return err
+}
+
+func (def SourceDef) serialize() (*pb.SourceVariant, error) {
 	serialized := &pb.SourceVariant{
 		Name:        def.Name,
 		Variant:     def.Variant,
@@ -760,15 +990,14 @@ func (client *Client) CreateSourceVariant(ctx context.Context, def SourceDef) er
 	case PrimaryDataSource:
 		serialized.Definition, err = def.Definition.(PrimaryDataSource).Serialize()
 	case nil:
-		return fmt.Errorf("SourceDef Definition not set")
+		return nil, fmt.Errorf("SourceDef Definition not set")
 	default:
-		return fmt.Errorf("SourceDef Definition has unexpected type %T", x)
+		return nil, fmt.Errorf("SourceDef Definition has unexpected type %T", x)
 	}
 	if err != nil {
-		return err
+		return nil, err
 	}
-	_, err = client.grpcConn.CreateSourceVariant(ctx, serialized)
-	return err
+	return serialized, nil
 }
 
 func (client *Client) GetSourceVariants(ctx context.Context, ids []NameVariant) ([]*SourceVariant, error) {
@@ -884,13 +1113,21 @@ func (def UserDef) ResourceType() ResourceType {
 }
 
 func (client *Client) CreateUser(ctx context.Context, def UserDef) error {
+	serialized, err := def.serialize()
+	if err != nil {
+		return err
+	}
+	_, err = client.grpcConn.CreateUser(ctx, serialized)
+	return err
+}
+
+func (def UserDef) serialize() (*pb.User, error) {
 	serialized := &pb.User{
 		Name:       def.Name,
 		Tags:       &pb.Tags{Tag: def.Tags},
 		Properties: def.Properties.Serialize(),
 	}
-	_, err := client.grpcConn.CreateUser(ctx, serialized)
-	return err
+	return serialized, nil
 }
 
 type userStream interface {
@@ -960,6 +1197,46 @@ func (def ProviderDef) ResourceType() ResourceType {
 }
 
 func (client *Client) CreateProvider(ctx context.Context, def ProviderDef) error {
+	serialized, err := def.serialize()
+	if err != nil {
+		return err
+	}
+	_, err = client.grpcConn.CreateProvider(ctx, serialized)
+	return err
+}
+
+// CutOverDualWrite swaps the primary and secondary stores of a dual-write
+// provider, so that features are served from the store being migrated to
+// while writes still go to both. Calling it again rolls the cut over back.
+func (client *Client) CutOverDualWrite(ctx context.Context, name string) error {
+	provider, err := client.GetProvider(ctx, name)
+	if err != nil {
+		return err
+	}
+	if pt.Type(provider.Type()) != pt.DualWriteOnline {
+		return fmt.Errorf("provider %s is a %s provider, not %s", name, provider.Type(), pt.DualWriteOnline)
+	}
+	config := pc.DualWriteConfig{}
+	if err := config.Deserialize(provider.SerializedConfig()); err != nil {
+		return fmt.Errorf("could not deserialize dual-write config: %w", err)
+	}
+	cutOver, err := config.CutOver()
+	if err != nil {
+		return err
+	}
+	return client.CreateProvider(ctx, ProviderDef{
+		Name:             provider.Name(),
+		Description:      provider.Description(),
+		Type:             provider.Type(),
+		Software:         provider.Software(),
+		Team:             provider.Team(),
+		SerializedConfig: cutOver.Serialized(),
+		Tags:             provider.Tags(),
+		Properties:       provider.Properties(),
+	})
+}
+
+func (def ProviderDef) serialize() (*pb.Provider, error) {
 	serialized := &pb.Provider{
 		Name:             def.Name,
 		Description:      def.Description,
@@ -971,8 +1248,7 @@ func (client *Client) CreateProvider(ctx context.Context, def ProviderDef) error
 		Tags:             &pb.Tags{Tag: def.Tags},
 		Properties:       def.Properties.Serialize(),
 	}
-	_, err := client.grpcConn.CreateProvider(ctx, serialized)
-	return err
+	return serialized, nil
 }
 
 type providerStream interface {
@@ -1038,6 +1314,15 @@ func (def EntityDef) ResourceType() ResourceType {
 }
 
 func (client *Client) CreateEntity(ctx context.Context, def EntityDef) error {
+	serialized, err := def.serialize()
+	if err != nil {
+		return err
+	}
+	_, err = client.grpcConn.CreateEntity(ctx, serialized)
+	return err
+}
+
+func (def EntityDef) serialize() (*pb.Entity, error) {
 	serialized := &pb.Entity{
 		Name:        def.Name,
 		Status:      &pb.ResourceStatus{Status: pb.ResourceStatus_NO_STATUS},
@@ -1045,8 +1330,7 @@ func (client *Client) CreateEntity(ctx context.Context, def EntityDef) error {
 		Tags:        &pb.Tags{Tag: def.Tags},
 		Properties:  def.Properties.Serialize(),
 	}
-	_, err := client.grpcConn.CreateEntity(ctx, serialized)
-	return err
+	return serialized, nil
 }
 
 type entityStream interface {
@@ -1114,6 +1398,15 @@ func (def ModelDef) ResourceType() ResourceType {
 }
 
 func (client *Client) CreateModel(ctx context.Context, def ModelDef) error {
+	serialized, err := def.serialize()
+	if err != nil {
+		return err
+	}
+	_, err = client.grpcConn.CreateModel(ctx, serialized)
+	return err
+}
+
+func (def ModelDef) serialize() (*pb.Model, error) {
 	serialized := &pb.Model{
 		Name:         def.Name,
 		Description:  def.Description,
@@ -1122,8 +1415,7 @@ func (client *Client) CreateModel(ctx context.Context, def ModelDef) error {
 		Tags:         &pb.Tags{Tag: def.Tags},
 		Properties:   def.Properties.Serialize(),
 	}
-	_, err := client.grpcConn.CreateModel(ctx, serialized)
-	return err
+	return serialized, nil
 }
 
 type modelStream interface {
@@ -1501,6 +1793,23 @@ func (variant *FeatureVariant) Properties() Properties {
 	return variant.fetchPropertiesFn.Properties()
 }
 
+func (variant *FeatureVariant) ApprovalState() ApprovalState {
+	return ApprovalState(variant.serialized.GetApprovalState())
+}
+
+func (variant *FeatureVariant) Approvals() []Approval {
+	approvals := make([]Approval, len(variant.serialized.GetApprovals()))
+	for i, approval := range variant.serialized.GetApprovals() {
+		approvals[i] = Approval{
+			State:   ApprovalState(approval.GetState()),
+			User:    approval.GetUser(),
+			Time:    approval.GetTime().AsTime(),
+			Comment: approval.GetComment(),
+		}
+	}
+	return approvals
+}
+
 func (variant *FeatureVariant) Mode() ComputationMode {
 	return ComputationMode(variant.serialized.GetMode())
 }
@@ -1623,6 +1932,12 @@ func (provider *Provider) SerializedConfig() []byte {
 	return provider.serialized.GetSerializedConfig()
 }
 
+// CheckTLSPolicy returns an error if the deployment requires providers to
+// connect over TLS and this provider's config doesn't.
+func (provider *Provider) CheckTLSPolicy() error {
+	return pc.CheckTLSPolicy(cfg.GetTunables(), provider.Name(), pt.Type(provider.Type()), provider.SerializedConfig())
+}
+
 func (provider *Provider) Status() ResourceStatus {
 	if provider.serialized.GetStatus() != nil {
 		return ResourceStatus(provider.serialized.GetStatus().Status)
@@ -1941,6 +2256,8 @@ type KubernetesResourceSpecs struct {
 	CPULimit      string
 	MemoryRequest string
 	MemoryLimit   string
+	GPURequest    string
+	Architecture  string
 }
 
 type KubernetesArgs struct {
@@ -1955,6 +2272,8 @@ func (arg KubernetesArgs) Format() map[string]string {
 		"CPU Limit":      arg.Specs.CPULimit,
 		"Memory Request": arg.Specs.MemoryRequest,
 		"Memory Limit":   arg.Specs.MemoryLimit,
+		"GPU Request":    arg.Specs.GPURequest,
+		"Architecture":   arg.Specs.Architecture,
 	}
 }
 
@@ -1972,6 +2291,8 @@ func (variant *SourceVariant) parseKubernetesArgs() KubernetesArgs {
 			CPULimit:      specs.GetCpuLimit(),
 			MemoryRequest: specs.GetMemoryRequest(),
 			MemoryLimit:   specs.GetMemoryLimit(),
+			GPURequest:    specs.GetGpuRequest(),
+			Architecture:  specs.GetArchitecture(),
 		},
 	}
 }
@@ -2086,6 +2407,84 @@ func (variant *SourceVariant) DFTransformationSources() []NameVariant {
 	return variants
 }
 
+func (variant *SourceVariant) IsDBTTransformation() bool {
+	if !variant.IsTransformation() {
+		return false
+	}
+	return reflect.TypeOf(variant.serialized.GetTransformation().Type) == reflect.TypeOf(&pb.Transformation_DBTTransformation{})
+}
+
+func (variant *SourceVariant) DBTTransformation() DBTTransformationType {
+	if !variant.IsDBTTransformation() {
+		return DBTTransformationType{}
+	}
+	dbt := variant.serialized.GetTransformation().GetDBTTransformation()
+	return DBTTransformationType{
+		ProjectDir:  dbt.GetProjectDir(),
+		ProfilesDir: dbt.GetProfilesDir(),
+		Target:      dbt.GetTarget(),
+		Model:       dbt.GetModel(),
+		Table:       dbt.GetTable(),
+		Inputs:      variant.DBTTransformationSources(),
+	}
+}
+
+func (variant *SourceVariant) DBTTransformationSources() []NameVariant {
+	if !variant.IsDBTTransformation() {
+		return nil
+	}
+	var variants []NameVariant
+	for _, nv := range variant.serialized.GetTransformation().GetDBTTransformation().GetInputs() {
+		variants = append(variants, NameVariant{Name: nv.Name, Variant: nv.Variant})
+	}
+	return variants
+}
+
+func (variant *SourceVariant) IsStreamingTransformation() bool {
+	if !variant.IsTransformation() {
+		return false
+	}
+	return reflect.TypeOf(variant.serialized.GetTransformation().Type) == reflect.TypeOf(&pb.Transformation_StreamingTransformation{})
+}
+
+func (variant *SourceVariant) StreamingTransformation() StreamingTransformationType {
+	if !variant.IsStreamingTransformation() {
+		return StreamingTransformationType{}
+	}
+	streaming := variant.serialized.GetTransformation().GetStreamingTransformation()
+	return StreamingTransformationType{
+		Query:   streaming.GetQuery(),
+		Sources: variant.StreamingTransformationSources(),
+		Trigger: streaming.GetTrigger(),
+	}
+}
+
+func (variant *SourceVariant) StreamingTransformationSources() []NameVariant {
+	if !variant.IsStreamingTransformation() {
+		return nil
+	}
+	var variants []NameVariant
+	for _, nv := range variant.serialized.GetTransformation().GetStreamingTransformation().GetSource() {
+		variants = append(variants, NameVariant{Name: nv.Name, Variant: nv.Variant})
+	}
+	return variants
+}
+
+// StreamingOffsets returns the offsets of the Kafka sources that the
+// transformation's job has processed up to, and the checkpoint batch they're
+// as of.
+func (variant *SourceVariant) StreamingOffsets() (int64, []KafkaOffset) {
+	if !variant.IsStreamingTransformation() {
+		return 0, nil
+	}
+	streaming := variant.serialized.GetTransformation().GetStreamingTransformation()
+	offsets := make([]KafkaOffset, len(streaming.GetOffsets()))
+	for i, offset := range streaming.GetOffsets() {
+		offsets[i] = KafkaOffset{Topic: offset.Topic, Partition: offset.Partition, Offset: offset.Offset}
+	}
+	return streaming.GetCheckpointBatch(), offsets
+}
+
 func (variant *SourceVariant) HasKubernetesArgs() bool {
 	return variant.serialized.GetTransformation().GetKubernetesArgs() != nil
 }
@@ -2119,6 +2518,25 @@ func (variant *SourceVariant) PrimaryDataSQLTableName() string {
 	return variant.serialized.GetPrimaryData().GetTable().GetName()
 }
 
+func (variant *SourceVariant) IsKafkaTopic() bool {
+	if !variant.isPrimaryData() {
+		return false
+	}
+	return reflect.TypeOf(variant.serialized.GetPrimaryData().GetLocation()) == reflect.TypeOf(&pb.PrimaryData_KafkaTopic{})
+}
+
+func (variant *SourceVariant) KafkaTopic() KafkaTopic {
+	if !variant.IsKafkaTopic() {
+		return KafkaTopic{}
+	}
+	topic := variant.serialized.GetPrimaryData().GetKafkaTopic()
+	return KafkaTopic{
+		Topic:           topic.GetTopic(),
+		ValueFormat:     topic.GetValueFormat(),
+		StartingOffsets: topic.GetStartingOffsets(),
+	}
+}
+
 func (variant *SourceVariant) Tags() Tags {
 	return variant.fetchTagsFn.Tags()
 }
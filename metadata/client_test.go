@@ -80,6 +80,8 @@ func TestSourceVariant_TransformationArgs(t *testing.T) {
 										CpuRequest:    "0.5",
 										MemoryLimit:   "500M",
 										MemoryRequest: "1G",
+										GpuRequest:    "1",
+										Architecture:  "arm64",
 									},
 								},
 							},
@@ -94,6 +96,8 @@ func TestSourceVariant_TransformationArgs(t *testing.T) {
 					CPURequest:    "0.5",
 					MemoryLimit:   "500M",
 					MemoryRequest: "1G",
+					GPURequest:    "1",
+					Architecture:  "arm64",
 				},
 			},
 		},
@@ -150,13 +154,13 @@ func TestKubernetesArgs_Format(t *testing.T) {
 		wantErr bool
 	}{
 		{"Empty", fields{},
-			map[string]string{"Docker Image": "", "CPU Request": "", "CPU Limit": "", "Memory Request": "", "Memory Limit": ""}, false},
+			map[string]string{"Docker Image": "", "CPU Request": "", "CPU Limit": "", "Memory Request": "", "Memory Limit": "", "GPU Request": "", "Architecture": ""}, false},
 		{"With Image", fields{
 			DockerImage: "my/test:image"},
-			map[string]string{"Docker Image": "my/test:image", "CPU Request": "", "CPU Limit": "", "Memory Request": "", "Memory Limit": ""}, false},
+			map[string]string{"Docker Image": "my/test:image", "CPU Request": "", "CPU Limit": "", "Memory Request": "", "Memory Limit": "", "GPU Request": "", "Architecture": ""}, false},
 		{"With Specs", fields{
-			Specs: KubernetesResourceSpecs{"1", "2", "3", "4"}},
-			map[string]string{"Docker Image": "", "CPU Request": "1", "CPU Limit": "2", "Memory Request": "3", "Memory Limit": "4"}, false},
+			Specs: KubernetesResourceSpecs{"1", "2", "3", "4", "1", "arm64"}},
+			map[string]string{"Docker Image": "", "CPU Request": "1", "CPU Limit": "2", "Memory Request": "3", "Memory Limit": "4", "GPU Request": "1", "Architecture": "arm64"}, false},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
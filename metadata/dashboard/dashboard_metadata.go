@@ -249,6 +249,12 @@ func sourceShallowMap(variant *metadata.SourceVariant) metadata.SourceVariantRes
 func getSourceString(variant *metadata.SourceVariant) string {
 	if variant.IsSQLTransformation() {
 		return variant.SQLTransformationQuery()
+	} else if variant.IsDBTTransformation() {
+		return variant.DBTTransformation().Model
+	} else if variant.IsStreamingTransformation() {
+		return variant.StreamingTransformation().Query
+	} else if variant.IsKafkaTopic() {
+		return variant.KafkaTopic().Topic
 	} else {
 		return variant.PrimaryDataSQLTableName()
 	}
@@ -259,6 +265,12 @@ func getSourceType(variant *metadata.SourceVariant) string {
 		return "SQL Transformation"
 	} else if variant.IsDFTransformation() {
 		return "Dataframe Transformation"
+	} else if variant.IsDBTTransformation() {
+		return "dbt Transformation"
+	} else if variant.IsStreamingTransformation() {
+		return "Streaming Transformation"
+	} else if variant.IsKafkaTopic() {
+		return "Kafka Topic"
 	} else {
 		return "Primary Table"
 	}
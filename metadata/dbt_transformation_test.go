@@ -0,0 +1,38 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package metadata
+
+import (
+	"reflect"
+	"testing"
+
+	pb "github.com/featureform/metadata/proto"
+)
+
+func TestDBTTransformationSource(t *testing.T) {
+	dbt := DBTTransformationType{
+		ProjectDir: "/dbt/shop",
+		Target:     "prod",
+		Model:      "orders",
+		Table:      "analytics.orders",
+		Inputs:     NameVariants{{Name: "transactions", Variant: "v1"}},
+	}
+	definition, err := TransformationSource{TransformationType: dbt}.Serialize()
+	if err != nil {
+		t.Fatalf("Failed to serialize dbt transformation: %s", err)
+	}
+	serialized := &pb.SourceVariant{Name: "orders", Variant: "v1", Definition: definition}
+	variant := wrapProtoSourceVariant(serialized)
+	if !variant.IsDBTTransformation() || variant.IsSQLTransformation() {
+		t.Fatalf("Expected only a dbt transformation")
+	}
+	if !reflect.DeepEqual(variant.DBTTransformation(), dbt) {
+		t.Fatalf("Expected %v, got %v", dbt, variant.DBTTransformation())
+	}
+	expectedInputs := []ResourceID{{Name: "transactions", Variant: "v1", Type: SOURCE_VARIANT}}
+	if inputs := transformationInputs(serialized); !reflect.DeepEqual(inputs, expectedInputs) {
+		t.Fatalf("Expected inputs %v, got %v", expectedInputs, inputs)
+	}
+}
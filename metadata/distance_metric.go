@@ -0,0 +1,36 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package metadata
+
+import (
+	"fmt"
+)
+
+// FeatureDistanceMetricProperty is the feature property naming the distance
+// metric that an embedding's vector index ranks neighbors by. It's one of
+// "cosine", "l2" or "inner_product", and defaults to cosine. Since it's
+// built into the index, it can't be changed once the feature is registered.
+const FeatureDistanceMetricProperty = "distance_metric"
+
+var distanceMetrics = map[string]bool{
+	"cosine":        true,
+	"l2":            true,
+	"inner_product": true,
+}
+
+// ParseDistanceMetric reads and validates a feature's distance metric from
+// its properties. It returns an empty string if none is set.
+func ParseDistanceMetric(properties Properties) (string, error) {
+	metric := properties[FeatureDistanceMetricProperty]
+	if metric != "" && !distanceMetrics[metric] {
+		return "", fmt.Errorf("invalid %s %q: must be cosine, l2 or inner_product", FeatureDistanceMetricProperty, metric)
+	}
+	return metric, nil
+}
+
+// DistanceMetric returns the distance metric of the embedding's index.
+func (variant *FeatureVariant) DistanceMetric() (string, error) {
+	return ParseDistanceMetric(variant.Properties())
+}
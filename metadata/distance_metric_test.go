@@ -0,0 +1,35 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package metadata
+
+import (
+	"testing"
+)
+
+func TestParseDistanceMetric(t *testing.T) {
+	tests := []struct {
+		name       string
+		properties Properties
+		expected   string
+		wantErr    bool
+	}{
+		{"Unset", Properties{}, "", false},
+		{"Cosine", Properties{FeatureDistanceMetricProperty: "cosine"}, "cosine", false},
+		{"Inner Product", Properties{FeatureDistanceMetricProperty: "inner_product"}, "inner_product", false},
+		{"Invalid", Properties{FeatureDistanceMetricProperty: "manhattan"}, "", true},
+		{"Case Sensitive", Properties{FeatureDistanceMetricProperty: "L2"}, "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			metric, err := ParseDistanceMetric(tt.properties)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Expected error %v, got %v", tt.wantErr, err)
+			}
+			if metric != tt.expected {
+				t.Fatalf("Expected %q, got %q", tt.expected, metric)
+			}
+		})
+	}
+}
@@ -0,0 +1,134 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package metadata
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Entity properties declaring how the entity's keys are normalized and
+// validated. They're applied the same way when features are materialized and
+// when they're served, so "User123 " and "user123" can refer to one entity.
+const (
+	EntityKeyTrimProperty      = "key_trim"
+	EntityKeyLowercaseProperty = "key_lowercase"
+	EntityKeyPatternProperty   = "key_pattern"
+	EntityKeyMaxLengthProperty = "key_max_length"
+)
+
+// EntityKeyRules are an entity's key rules. Keys are trimmed of surrounding
+// whitespace and lowercased first, then checked against MaxLength (in
+// characters) and Pattern. The zero value leaves keys unchanged.
+type EntityKeyRules struct {
+	Trim      bool
+	Lowercase bool
+	Pattern   string
+	MaxLength int
+}
+
+func (rules EntityKeyRules) IsZero() bool {
+	return rules == EntityKeyRules{}
+}
+
+// ParseEntityKeyRules reads key rules from an entity's properties.
+func ParseEntityKeyRules(properties Properties) (EntityKeyRules, error) {
+	rules := EntityKeyRules{Pattern: properties[EntityKeyPatternProperty]}
+	var err error
+	if rules.Trim, err = parseBoolProperty(properties, EntityKeyTrimProperty); err != nil {
+		return EntityKeyRules{}, err
+	}
+	if rules.Lowercase, err = parseBoolProperty(properties, EntityKeyLowercaseProperty); err != nil {
+		return EntityKeyRules{}, err
+	}
+	if maxLength, has := properties[EntityKeyMaxLengthProperty]; has && maxLength != "" {
+		rules.MaxLength, err = strconv.Atoi(maxLength)
+		if err != nil || rules.MaxLength < 0 {
+			return EntityKeyRules{}, fmt.Errorf("invalid %s %q: must be a non-negative integer", EntityKeyMaxLengthProperty, maxLength)
+		}
+	}
+	if _, err := regexp.Compile(rules.Pattern); err != nil {
+		return EntityKeyRules{}, fmt.Errorf("invalid %s %q: %w", EntityKeyPatternProperty, rules.Pattern, err)
+	}
+	return rules, nil
+}
+
+func parseBoolProperty(properties Properties, key string) (bool, error) {
+	value, has := properties[key]
+	if !has || value == "" {
+		return false, nil
+	}
+	parsed, err := strconv.ParseBool(value)
+	if err != nil {
+		return false, fmt.Errorf("invalid %s %q: must be true or false", key, value)
+	}
+	return parsed, nil
+}
+
+// KeyRules returns the entity's declared key rules.
+func (entity *Entity) KeyRules() (EntityKeyRules, error) {
+	return ParseEntityKeyRules(entity.Properties())
+}
+
+type InvalidEntityKey struct {
+	Entity, Key, Reason string
+}
+
+func (err *InvalidEntityKey) Error() string {
+	return fmt.Sprintf("invalid key %q for entity %s: %s", err.Key, err.Entity, err.Reason)
+}
+
+func (err *InvalidEntityKey) GRPCStatus() *status.Status {
+	return status.New(codes.InvalidArgument, err.Error())
+}
+
+// EntityKeyNormalizer applies an entity's key rules. A nil normalizer
+// leaves keys unchanged.
+type EntityKeyNormalizer struct {
+	entity  string
+	rules   EntityKeyRules
+	pattern *regexp.Regexp
+}
+
+func NewEntityKeyNormalizer(entity string, rules EntityKeyRules) (*EntityKeyNormalizer, error) {
+	normalizer := &EntityKeyNormalizer{entity: entity, rules: rules}
+	if rules.Pattern != "" {
+		// The pattern has to match the whole key, not just part of it.
+		pattern, err := regexp.Compile("^(?:" + rules.Pattern + ")$")
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s %q: %w", EntityKeyPatternProperty, rules.Pattern, err)
+		}
+		normalizer.pattern = pattern
+	}
+	return normalizer, nil
+}
+
+// Normalize returns the normalized key, or an InvalidEntityKey error if the
+// normalized key breaks the rules.
+func (normalizer *EntityKeyNormalizer) Normalize(key string) (string, error) {
+	if normalizer == nil {
+		return key, nil
+	}
+	normalized := key
+	if normalizer.rules.Trim {
+		normalized = strings.TrimSpace(normalized)
+	}
+	if normalizer.rules.Lowercase {
+		normalized = strings.ToLower(normalized)
+	}
+	if max := normalizer.rules.MaxLength; max > 0 && utf8.RuneCountInString(normalized) > max {
+		return "", &InvalidEntityKey{normalizer.entity, key, fmt.Sprintf("key is longer than %d characters", max)}
+	}
+	if normalizer.pattern != nil && !normalizer.pattern.MatchString(normalized) {
+		return "", &InvalidEntityKey{normalizer.entity, key, fmt.Sprintf("key does not match %s", normalizer.rules.Pattern)}
+	}
+	return normalized, nil
+}
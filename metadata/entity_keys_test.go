@@ -0,0 +1,74 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package metadata
+
+import (
+	"testing"
+)
+
+func TestParseEntityKeyRules(t *testing.T) {
+	tests := []struct {
+		name       string
+		properties Properties
+		expected   EntityKeyRules
+		wantErr    bool
+	}{
+		{"Unset", Properties{}, EntityKeyRules{}, false},
+		{"All", Properties{
+			EntityKeyTrimProperty:      "true",
+			EntityKeyLowercaseProperty: "true",
+			EntityKeyPatternProperty:   "user[0-9]+",
+			EntityKeyMaxLengthProperty: "16",
+		}, EntityKeyRules{Trim: true, Lowercase: true, Pattern: "user[0-9]+", MaxLength: 16}, false},
+		{"Invalid Bool", Properties{EntityKeyTrimProperty: "yes please"}, EntityKeyRules{}, true},
+		{"Invalid Max Length", Properties{EntityKeyMaxLengthProperty: "-1"}, EntityKeyRules{}, true},
+		{"Invalid Pattern", Properties{EntityKeyPatternProperty: "user[0-9"}, EntityKeyRules{}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rules, err := ParseEntityKeyRules(tt.properties)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Expected error %v, got %v", tt.wantErr, err)
+			}
+			if rules != tt.expected {
+				t.Fatalf("Expected %+v, got %+v", tt.expected, rules)
+			}
+		})
+	}
+}
+
+func TestEntityKeyNormalizer(t *testing.T) {
+	rules := EntityKeyRules{Trim: true, Lowercase: true, Pattern: "user[0-9]+", MaxLength: 8}
+	normalizer, err := NewEntityKeyNormalizer("user", rules)
+	if err != nil {
+		t.Fatalf("Failed to create normalizer: %s", err)
+	}
+	tests := []struct {
+		key, expected string
+		wantErr       bool
+	}{
+		{"user123", "user123", false},
+		{" User123 ", "user123", false},
+		{"user1234567", "", true},
+		{"admin1", "", true},
+		{"xuser1", "", true},
+	}
+	for _, tt := range tests {
+		normalized, err := normalizer.Normalize(tt.key)
+		if (err != nil) != tt.wantErr {
+			t.Fatalf("Expected error %v for %q, got %v", tt.wantErr, tt.key, err)
+		}
+		if _, ok := err.(*InvalidEntityKey); err != nil && !ok {
+			t.Fatalf("Expected InvalidEntityKey, got %T", err)
+		}
+		if normalized != tt.expected {
+			t.Fatalf("Expected %q to normalize to %q, got %q", tt.key, tt.expected, normalized)
+		}
+	}
+	var unset *EntityKeyNormalizer
+	if normalized, err := unset.Normalize(" Key "); err != nil || normalized != " Key " {
+		t.Fatalf("Expected nil normalizer to leave key unchanged, got %q %v", normalized, err)
+	}
+}
@@ -61,6 +61,48 @@ func (c *CoordinatorScheduleJob) Deserialize(serialized []byte) error {
 	return nil
 }
 
+// CoordinatorStopStreamingJob asks the coordinator to stop the job of a
+// streaming transformation.
+type CoordinatorStopStreamingJob struct {
+	Resource ResourceID
+}
+
+func (c *CoordinatorStopStreamingJob) Serialize() ([]byte, error) {
+	return json.Marshal(c)
+}
+
+func (c *CoordinatorStopStreamingJob) Deserialize(serialized []byte) error {
+	return json.Unmarshal(serialized, c)
+}
+
+// CoordinatorCancelJob asks the coordinator that is running the
+// materialization or training set job of a resource to cancel it.
+type CoordinatorCancelJob struct {
+	Resource ResourceID
+}
+
+func (c *CoordinatorCancelJob) Serialize() ([]byte, error) {
+	return json.Marshal(c)
+}
+
+func (c *CoordinatorCancelJob) Deserialize(serialized []byte) error {
+	return json.Unmarshal(serialized, c)
+}
+
+// CoordinatorDeleteFromOnlineJob asks the coordinator to delete a feature
+// variant's table from the online stores it was materialized to.
+type CoordinatorDeleteFromOnlineJob struct {
+	Resource ResourceID
+}
+
+func (c *CoordinatorDeleteFromOnlineJob) Serialize() ([]byte, error) {
+	return json.Marshal(c)
+}
+
+func (c *CoordinatorDeleteFromOnlineJob) Deserialize(serialized []byte) error {
+	return json.Unmarshal(serialized, c)
+}
+
 type TempJob struct {
 	Attempts int
 	Name     string
@@ -362,6 +404,18 @@ func GetScheduleJobKey(id ResourceID) string {
 	return fmt.Sprintf("SCHEDULEJOB__%s__%s__%s", id.Type, id.Name, id.Variant)
 }
 
+func GetStopStreamingJobKey(id ResourceID) string {
+	return fmt.Sprintf("STOPSTREAM__%s__%s__%s", id.Type, id.Name, id.Variant)
+}
+
+func GetDeleteFromOnlineJobKey(id ResourceID) string {
+	return fmt.Sprintf("DELETEONLINE__%s__%s__%s", id.Type, id.Name, id.Variant)
+}
+
+func GetCancelJobKey(id ResourceID) string {
+	return fmt.Sprintf("CANCELJOB__%s__%s__%s", id.Type, id.Name, id.Variant)
+}
+
 func (lookup etcdResourceLookup) HasJob(id ResourceID) (bool, error) {
 	job_key := GetJobKey(id)
 	count, err := lookup.connection.GetCountWithPrefix(job_key)
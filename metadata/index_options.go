@@ -0,0 +1,64 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package metadata
+
+import (
+	"fmt"
+	"strconv"
+)
+
+// Feature properties that tune an embedding's approximate nearest neighbor
+// index. Each is a positive integer, and unset properties use the vector
+// store's defaults. FeatureIndexNListsProperty builds an IVF index instead
+// of HNSW on stores that support it. Except for the runtime candidates, they
+// are built into the index, so they can't be changed once the feature is
+// registered.
+const (
+	FeatureIndexMProperty              = "index_m"
+	FeatureIndexEfConstructionProperty = "index_ef_construction"
+	FeatureIndexEfRuntimeProperty      = "index_ef_runtime"
+	FeatureIndexNListsProperty         = "index_nlists"
+)
+
+// immutableIndexProperties are the index properties that can't be updated.
+var immutableIndexProperties = []string{
+	FeatureIndexMProperty,
+	FeatureIndexEfConstructionProperty,
+	FeatureIndexNListsProperty,
+}
+
+// IndexOptions are an embedding's index parameters, which are zero if unset.
+type IndexOptions struct {
+	M, EfConstruction, EfRuntime, NLists int32
+}
+
+// ParseIndexOptions reads and validates a feature's index options from its
+// properties.
+func ParseIndexOptions(properties Properties) (IndexOptions, error) {
+	options := IndexOptions{}
+	fields := map[string]*int32{
+		FeatureIndexMProperty:              &options.M,
+		FeatureIndexEfConstructionProperty: &options.EfConstruction,
+		FeatureIndexEfRuntimeProperty:      &options.EfRuntime,
+		FeatureIndexNListsProperty:         &options.NLists,
+	}
+	for property, field := range fields {
+		value, has := properties[property]
+		if !has {
+			continue
+		}
+		parsed, err := strconv.ParseInt(value, 10, 32)
+		if err != nil || parsed <= 0 {
+			return IndexOptions{}, fmt.Errorf("invalid %s %q: must be a positive integer", property, value)
+		}
+		*field = int32(parsed)
+	}
+	return options, nil
+}
+
+// IndexOptions returns the options of the embedding's index.
+func (variant *FeatureVariant) IndexOptions() (IndexOptions, error) {
+	return ParseIndexOptions(variant.Properties())
+}
@@ -0,0 +1,36 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package metadata
+
+import (
+	"testing"
+)
+
+func TestParseIndexOptions(t *testing.T) {
+	tests := []struct {
+		name       string
+		properties Properties
+		expected   IndexOptions
+		wantErr    bool
+	}{
+		{"Unset", Properties{}, IndexOptions{}, false},
+		{"HNSW", Properties{FeatureIndexMProperty: "32", FeatureIndexEfConstructionProperty: "400", FeatureIndexEfRuntimeProperty: "64"}, IndexOptions{M: 32, EfConstruction: 400, EfRuntime: 64}, false},
+		{"IVF", Properties{FeatureIndexNListsProperty: "100"}, IndexOptions{NLists: 100}, false},
+		{"Zero", Properties{FeatureIndexMProperty: "0"}, IndexOptions{}, true},
+		{"Negative", Properties{FeatureIndexEfRuntimeProperty: "-1"}, IndexOptions{}, true},
+		{"Not A Number", Properties{FeatureIndexNListsProperty: "many"}, IndexOptions{}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			options, err := ParseIndexOptions(tt.properties)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Expected error %v, got %v", tt.wantErr, err)
+			}
+			if options != tt.expected {
+				t.Fatalf("Expected %+v, got %+v", tt.expected, options)
+			}
+		})
+	}
+}
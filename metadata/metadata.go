@@ -14,6 +14,7 @@ import (
 
 	"github.com/pkg/errors"
 
+	cfg "github.com/featureform/config"
 	pb "github.com/featureform/metadata/proto"
 	"github.com/featureform/metadata/search"
 	pc "github.com/featureform/provider/provider_config"
@@ -463,6 +464,13 @@ func (resource *sourceVariantResource) Update(lookup ResourceLookup, updateRes R
 	if !ok {
 		return errors.New("failed to deserialize existing source variant record")
 	}
+	properties := fetchPropertiesFn{resource.serialized}.Properties()
+	for key, value := range (fetchPropertiesFn{variantUpdate}).Properties() {
+		properties[key] = value
+	}
+	if _, err := ParseTimestampTimezone(properties); err != nil {
+		return status.Error(codes.InvalidArgument, err.Error())
+	}
 	resource.serialized.Tags = unionTags(resource.serialized.Tags, variantUpdate.Tags)
 	resource.serialized.Properties = mergeProperties(resource.serialized.Properties, variantUpdate.Properties)
 	return nil
@@ -599,6 +607,16 @@ func (resource *featureVariantResource) Update(lookup ResourceLookup, updateRes
 	if !ok {
 		return errors.New("failed to deserialize existing feature variant record")
 	}
+	existing := fetchPropertiesFn{resource.serialized}.Properties()
+	updated := fetchPropertiesFn{variantUpdate}.Properties()
+	for _, property := range append([]string{FeatureDistanceMetricProperty, FeatureVectorStorageProperty, FeatureVectorNamesProperty}, immutableIndexProperties...) {
+		if value, has := updated[property]; has && value != existing[property] {
+			return status.Errorf(codes.InvalidArgument, "%s can't be changed after a feature is registered", property)
+		}
+	}
+	if _, err := ParseIndexOptions(updated); err != nil {
+		return status.Error(codes.InvalidArgument, err.Error())
+	}
 	resource.serialized.Tags = unionTags(resource.serialized.Tags, variantUpdate.Tags)
 	resource.serialized.Properties = mergeProperties(resource.serialized.Properties, variantUpdate.Properties)
 	return nil
@@ -1095,10 +1113,34 @@ func (resource *providerResource) isValidConfigUpdate(configUpdate pc.Serialized
 		return isValidSnowflakeConfigUpdate(resource.serialized.SerializedConfig, configUpdate)
 	case pt.RedshiftOffline:
 		return isValidRedshiftConfigUpdate(resource.serialized.SerializedConfig, configUpdate)
+	case pt.ClickHouseOffline:
+		return isValidClickHouseConfigUpdate(resource.serialized.SerializedConfig, configUpdate)
+	case pt.DuckDBOffline:
+		return isValidDuckDBConfigUpdate(resource.serialized.SerializedConfig, configUpdate)
+	case pt.AthenaOffline:
+		return isValidAthenaConfigUpdate(resource.serialized.SerializedConfig, configUpdate)
+	case pt.DatabricksSQLOffline:
+		return isValidDatabricksSQLConfigUpdate(resource.serialized.SerializedConfig, configUpdate)
 	case pt.K8sOffline:
 		return isValidK8sConfigUpdate(resource.serialized.SerializedConfig, configUpdate)
 	case pt.SparkOffline:
 		return isValidSparkConfigUpdate(resource.serialized.SerializedConfig, configUpdate)
+	case pt.DualWriteOnline:
+		return isValidDualWriteConfigUpdate(resource.serialized.SerializedConfig, configUpdate)
+	case pt.ShardedRedisOnline:
+		return isValidShardedRedisConfigUpdate(resource.serialized.SerializedConfig, configUpdate)
+	case pt.WeaviateOnline:
+		return isValidWeaviateConfigUpdate(resource.serialized.SerializedConfig, configUpdate)
+	case pt.PgvectorOnline:
+		return isValidPgvectorConfigUpdate(resource.serialized.SerializedConfig, configUpdate)
+	case pt.OpenSearchOnline:
+		return isValidOpenSearchConfigUpdate(resource.serialized.SerializedConfig, configUpdate)
+	case pt.ChromaOnline:
+		return isValidChromaConfigUpdate(resource.serialized.SerializedConfig, configUpdate)
+	case pt.VespaOnline:
+		return isValidVespaConfigUpdate(resource.serialized.SerializedConfig, configUpdate)
+	case pt.Kafka:
+		return isValidKafkaConfigUpdate(resource.serialized.SerializedConfig, configUpdate)
 	case pt.S3, pt.HDFS, pt.GCS, pt.AZURE, pt.BlobOnline:
 		return true, nil
 	default:
@@ -1106,6 +1148,12 @@ func (resource *providerResource) isValidConfigUpdate(configUpdate pc.Serialized
 	}
 }
 
+// checkProviderTLS enforces the deployment's encryption-in-transit policy on
+// a provider that's being registered or updated.
+func checkProviderTLS(provider *pb.Provider) error {
+	return pc.CheckTLSPolicy(cfg.GetTunables(), provider.Name, pt.Type(provider.Type), provider.SerializedConfig)
+}
+
 type entityResource struct {
 	serialized *pb.Entity
 }
@@ -1160,6 +1208,13 @@ func (resource *entityResource) Update(lookup ResourceLookup, updateRes Resource
 	if !ok {
 		return errors.New("failed to deserialize existing training entity record")
 	}
+	properties := fetchPropertiesFn{resource.serialized}.Properties()
+	for key, value := range (fetchPropertiesFn{entityUpdate}).Properties() {
+		properties[key] = value
+	}
+	if _, err := ParseEntityKeyRules(properties); err != nil {
+		return status.Error(codes.InvalidArgument, err.Error())
+	}
 	resource.serialized.Tags = unionTags(resource.serialized.Tags, entityUpdate.Tags)
 	resource.serialized.Properties = mergeProperties(resource.serialized.Properties, entityUpdate.Properties)
 	return nil
@@ -1292,6 +1347,36 @@ func (serv *MetadataServer) SetResourceStatus(ctx context.Context, req *pb.SetSt
 	return &pb.Empty{}, err
 }
 
+// SetStreamingOffsets records the offsets a streaming transformation's job
+// has read its Kafka sources up to. Offsets from an earlier checkpoint batch
+// than the ones already recorded are ignored, so that reports that arrive out
+// of order don't move them backwards.
+func (serv *MetadataServer) SetStreamingOffsets(ctx context.Context, req *pb.StreamingOffsetsRequest) (*pb.Empty, error) {
+	id := ResourceID{Name: req.GetSource().GetName(), Variant: req.GetSource().GetVariant(), Type: SOURCE_VARIANT}
+	res, err := serv.lookup.Lookup(id)
+	if err != nil {
+		return nil, err
+	}
+	variant, ok := res.(*sourceVariantResource)
+	if !ok {
+		return nil, fmt.Errorf("resource %v is not a source variant", id)
+	}
+	streaming := variant.serialized.GetTransformation().GetStreamingTransformation()
+	if streaming == nil {
+		return nil, status.Errorf(codes.InvalidArgument, "source %s %s is not a streaming transformation", id.Name, id.Variant)
+	}
+	if req.CheckpointBatch < streaming.CheckpointBatch {
+		return &pb.Empty{}, nil
+	}
+	streaming.CheckpointBatch = req.CheckpointBatch
+	streaming.Offsets = req.Offsets
+	if err := serv.lookup.Set(id, variant); err != nil {
+		serv.Logger.Errorw("Could not set streaming offsets", "error", err.Error())
+		return nil, err
+	}
+	return &pb.Empty{}, nil
+}
+
 func (serv *MetadataServer) ListFeatures(_ *pb.Empty, stream pb.Metadata_ListFeaturesServer) error {
 	return serv.genericList(FEATURE, func(msg proto.Message) error {
 		return stream.Send(msg.(*pb.Feature))
@@ -1299,7 +1384,26 @@ func (serv *MetadataServer) ListFeatures(_ *pb.Empty, stream pb.Metadata_ListFea
 }
 
 func (serv *MetadataServer) CreateFeatureVariant(ctx context.Context, variant *pb.FeatureVariant) (*pb.Empty, error) {
+	if err := serv.nameVariant(ctx, variant, FEATURE, func(name string) { variant.Variant = name }); err != nil {
+		return nil, err
+	}
+	if _, err := ParseDistanceMetric(fetchPropertiesFn{variant}.Properties()); err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	if _, err := ParseIndexOptions(fetchPropertiesFn{variant}.Properties()); err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	if _, err := ParseVectorStorage(fetchPropertiesFn{variant}.Properties()); err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	if _, err := ParseVectorNames(fetchPropertiesFn{variant}.Properties()); err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
 	variant.Created = tspb.New(time.Now())
+	// New variants always start as drafts; approvals can only be recorded
+	// through SetFeatureApproval.
+	variant.ApprovalState = pb.ApprovalState_DRAFT
+	variant.Approvals = nil
 	return serv.genericCreate(ctx, &featureVariantResource{variant}, func(name, variant string) Resource {
 		return &featureResource{
 			&pb.Feature{
@@ -1331,6 +1435,9 @@ func (serv *MetadataServer) ListLabels(_ *pb.Empty, stream pb.Metadata_ListLabel
 }
 
 func (serv *MetadataServer) CreateLabelVariant(ctx context.Context, variant *pb.LabelVariant) (*pb.Empty, error) {
+	if err := serv.nameVariant(ctx, variant, LABEL, func(name string) { variant.Variant = name }); err != nil {
+		return nil, err
+	}
 	variant.Created = tspb.New(time.Now())
 	return serv.genericCreate(ctx, &labelVariantResource{variant}, func(name, variant string) Resource {
 		return &labelResource{
@@ -1363,6 +1470,9 @@ func (serv *MetadataServer) ListTrainingSets(_ *pb.Empty, stream pb.Metadata_Lis
 }
 
 func (serv *MetadataServer) CreateTrainingSetVariant(ctx context.Context, variant *pb.TrainingSetVariant) (*pb.Empty, error) {
+	if err := serv.nameVariant(ctx, variant, TRAINING_SET, func(name string) { variant.Variant = name }); err != nil {
+		return nil, err
+	}
 	variant.Created = tspb.New(time.Now())
 	return serv.genericCreate(ctx, &trainingSetVariantResource{variant}, func(name, variant string) Resource {
 		return &trainingSetResource{
@@ -1395,6 +1505,12 @@ func (serv *MetadataServer) ListSources(_ *pb.Empty, stream pb.Metadata_ListSour
 }
 
 func (serv *MetadataServer) CreateSourceVariant(ctx context.Context, variant *pb.SourceVariant) (*pb.Empty, error) {
+	if err := serv.nameVariant(ctx, variant, SOURCE, func(name string) { variant.Variant = name }); err != nil {
+		return nil, err
+	}
+	if _, err := ParseTimestampTimezone(fetchPropertiesFn{variant}.Properties()); err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
 	variant.Created = tspb.New(time.Now())
 	return serv.genericCreate(ctx, &sourceVariantResource{variant}, func(name, variant string) Resource {
 		return &sourceResource{
@@ -1444,6 +1560,9 @@ func (serv *MetadataServer) ListProviders(_ *pb.Empty, stream pb.Metadata_ListPr
 }
 
 func (serv *MetadataServer) CreateProvider(ctx context.Context, provider *pb.Provider) (*pb.Empty, error) {
+	if err := checkProviderTLS(provider); err != nil {
+		return nil, status.Error(codes.FailedPrecondition, err.Error())
+	}
 	return serv.genericCreate(ctx, &providerResource{provider}, nil)
 }
 
@@ -1460,6 +1579,9 @@ func (serv *MetadataServer) ListEntities(_ *pb.Empty, stream pb.Metadata_ListEnt
 }
 
 func (serv *MetadataServer) CreateEntity(ctx context.Context, entity *pb.Entity) (*pb.Empty, error) {
+	if _, err := ParseEntityKeyRules(fetchPropertiesFn{entity}.Properties()); err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
 	return serv.genericCreate(ctx, &entityResource{entity}, nil)
 }
 
@@ -1711,6 +1833,12 @@ type SourceVariantResource struct {
 func getSourceString(variant *SourceVariant) string {
 	if variant.IsSQLTransformation() {
 		return variant.SQLTransformationQuery()
+	} else if variant.IsDBTTransformation() {
+		return variant.DBTTransformation().Model
+	} else if variant.IsStreamingTransformation() {
+		return variant.StreamingTransformation().Query
+	} else if variant.IsKafkaTopic() {
+		return variant.KafkaTopic().Topic
 	} else {
 		return variant.PrimaryDataSQLTableName()
 	}
@@ -1721,6 +1849,12 @@ func getSourceType(variant *SourceVariant) string {
 		return "SQL Transformation"
 	} else if variant.IsDFTransformation() {
 		return "Dataframe Transformation"
+	} else if variant.IsDBTTransformation() {
+		return "dbt Transformation"
+	} else if variant.IsStreamingTransformation() {
+		return "Streaming Transformation"
+	} else if variant.IsKafkaTopic() {
+		return "Kafka Topic"
 	} else {
 		return "Primary Table"
 	}
@@ -114,6 +114,38 @@ func isValidRedisConfigUpdate(sa, sb pc.SerializedConfig) (bool, error) {
 	return a.MutableFields().Contains(diff), nil
 }
 
+func isValidDualWriteConfigUpdate(sa, sb pc.SerializedConfig) (bool, error) {
+	a := pc.DualWriteConfig{}
+	b := pc.DualWriteConfig{}
+	if err := a.Deserialize(sa); err != nil {
+		return false, err
+	}
+	if err := b.Deserialize(sb); err != nil {
+		return false, err
+	}
+	diff, err := a.DifferingFields(b)
+	if err != nil {
+		return false, err
+	}
+	return a.MutableFields().Contains(diff), nil
+}
+
+func isValidShardedRedisConfigUpdate(sa, sb pc.SerializedConfig) (bool, error) {
+	a := pc.ShardedRedisConfig{}
+	b := pc.ShardedRedisConfig{}
+	if err := a.Deserialize(sa); err != nil {
+		return false, err
+	}
+	if err := b.Deserialize(sb); err != nil {
+		return false, err
+	}
+	diff, err := a.DifferingFields(b)
+	if err != nil {
+		return false, err
+	}
+	return a.MutableFields().Contains(diff), nil
+}
+
 func isValidSnowflakeConfigUpdate(sa, sb pc.SerializedConfig) (bool, error) {
 	a := pc.SnowflakeConfig{}
 	b := pc.SnowflakeConfig{}
@@ -146,6 +178,70 @@ func isValidRedshiftConfigUpdate(sa, sb pc.SerializedConfig) (bool, error) {
 	return a.MutableFields().Contains(diff), nil
 }
 
+func isValidClickHouseConfigUpdate(sa, sb pc.SerializedConfig) (bool, error) {
+	a := pc.ClickHouseConfig{}
+	b := pc.ClickHouseConfig{}
+	if err := a.Deserialize(sa); err != nil {
+		return false, err
+	}
+	if err := b.Deserialize(sb); err != nil {
+		return false, err
+	}
+	diff, err := a.DifferingFields(b)
+	if err != nil {
+		return false, err
+	}
+	return a.MutableFields().Contains(diff), nil
+}
+
+func isValidDuckDBConfigUpdate(sa, sb pc.SerializedConfig) (bool, error) {
+	a := pc.DuckDBConfig{}
+	b := pc.DuckDBConfig{}
+	if err := a.Deserialize(sa); err != nil {
+		return false, err
+	}
+	if err := b.Deserialize(sb); err != nil {
+		return false, err
+	}
+	diff, err := a.DifferingFields(b)
+	if err != nil {
+		return false, err
+	}
+	return a.MutableFields().Contains(diff), nil
+}
+
+func isValidAthenaConfigUpdate(sa, sb pc.SerializedConfig) (bool, error) {
+	a := pc.AthenaConfig{}
+	b := pc.AthenaConfig{}
+	if err := a.Deserialize(sa); err != nil {
+		return false, err
+	}
+	if err := b.Deserialize(sb); err != nil {
+		return false, err
+	}
+	diff, err := a.DifferingFields(b)
+	if err != nil {
+		return false, err
+	}
+	return a.MutableFields().Contains(diff), nil
+}
+
+func isValidDatabricksSQLConfigUpdate(sa, sb pc.SerializedConfig) (bool, error) {
+	a := pc.DatabricksSQLConfig{}
+	b := pc.DatabricksSQLConfig{}
+	if err := a.Deserialize(sa); err != nil {
+		return false, err
+	}
+	if err := b.Deserialize(sb); err != nil {
+		return false, err
+	}
+	diff, err := a.DifferingFields(b)
+	if err != nil {
+		return false, err
+	}
+	return a.MutableFields().Contains(diff), nil
+}
+
 func isValidK8sConfigUpdate(sa, sb pc.SerializedConfig) (bool, error) {
 	a := pc.K8sConfig{}
 	b := pc.K8sConfig{}
@@ -177,3 +273,99 @@ func isValidSparkConfigUpdate(sa, sb pc.SerializedConfig) (bool, error) {
 	}
 	return a.MutableFields().Contains(diff), nil
 }
+
+func isValidWeaviateConfigUpdate(sa, sb pc.SerializedConfig) (bool, error) {
+	a := pc.WeaviateConfig{}
+	b := pc.WeaviateConfig{}
+	if err := a.Deserialize(sa); err != nil {
+		return false, err
+	}
+	if err := b.Deserialize(sb); err != nil {
+		return false, err
+	}
+	diff, err := a.DifferingFields(b)
+	if err != nil {
+		return false, err
+	}
+	return a.MutableFields().Contains(diff), nil
+}
+
+func isValidPgvectorConfigUpdate(sa, sb pc.SerializedConfig) (bool, error) {
+	a := pc.PgvectorConfig{}
+	b := pc.PgvectorConfig{}
+	if err := a.Deserialize(sa); err != nil {
+		return false, err
+	}
+	if err := b.Deserialize(sb); err != nil {
+		return false, err
+	}
+	diff, err := a.DifferingFields(b)
+	if err != nil {
+		return false, err
+	}
+	return a.MutableFields().Contains(diff), nil
+}
+
+func isValidOpenSearchConfigUpdate(sa, sb pc.SerializedConfig) (bool, error) {
+	a := pc.OpenSearchConfig{}
+	b := pc.OpenSearchConfig{}
+	if err := a.Deserialize(sa); err != nil {
+		return false, err
+	}
+	if err := b.Deserialize(sb); err != nil {
+		return false, err
+	}
+	diff, err := a.DifferingFields(b)
+	if err != nil {
+		return false, err
+	}
+	return a.MutableFields().Contains(diff), nil
+}
+
+func isValidChromaConfigUpdate(sa, sb pc.SerializedConfig) (bool, error) {
+	a := pc.ChromaConfig{}
+	b := pc.ChromaConfig{}
+	if err := a.Deserialize(sa); err != nil {
+		return false, err
+	}
+	if err := b.Deserialize(sb); err != nil {
+		return false, err
+	}
+	diff, err := a.DifferingFields(b)
+	if err != nil {
+		return false, err
+	}
+	return a.MutableFields().Contains(diff), nil
+}
+
+func isValidVespaConfigUpdate(sa, sb pc.SerializedConfig) (bool, error) {
+	a := pc.VespaConfig{}
+	b := pc.VespaConfig{}
+	if err := a.Deserialize(sa); err != nil {
+		return false, err
+	}
+	if err := b.Deserialize(sb); err != nil {
+		return false, err
+	}
+	diff, err := a.DifferingFields(b)
+	if err != nil {
+		return false, err
+	}
+	return a.MutableFields().Contains(diff), nil
+}
+
+func isValidKafkaConfigUpdate(sa, sb pc.SerializedConfig) (bool, error) {
+	a := pc.KafkaConfig{}
+	b := pc.KafkaConfig{}
+	if err := a.Deserialize(sa); err != nil {
+		return false, err
+	}
+	if err := b.Deserialize(sb); err != nil {
+		return false, err
+	}
+	diff, err := a.DifferingFields(b)
+	if err != nil {
+		return false, err
+	}
+	return a.MutableFields().Contains(diff), nil
+}
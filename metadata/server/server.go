@@ -9,6 +9,7 @@ import (
 	"github.com/featureform/metadata/search"
 	"os"
 
+	cfg "github.com/featureform/config"
 	help "github.com/featureform/helpers"
 	"github.com/featureform/metadata"
 	"go.uber.org/zap"
@@ -19,6 +20,7 @@ func main() {
 	etcdHost := help.GetEnv("ETCD_HOST", "localhost")
 	etcdPort := help.GetEnv("ETCD_PORT", "2379")
 	logger := zap.NewExample().Sugar()
+	cfg.StartTunablesWatch(logger)
 	addr := help.GetEnv("METADATA_PORT", "8080")
 	enableSearch := help.GetEnv("ENABLE_SEARCH", "true")
 	storageProvider := metadata.EtcdStorageProvider{
@@ -0,0 +1,34 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package metadata
+
+import (
+	"fmt"
+	"time"
+)
+
+// SourceTimestampTimezoneProperty is the source property naming the IANA
+// time zone, like "America/Los_Angeles", of timestamp columns that don't
+// store one. Those timestamps are converted to UTC when features and labels
+// are registered from the source. Without it, they're assumed to be in UTC.
+const SourceTimestampTimezoneProperty = "timestamp_timezone"
+
+// ParseTimestampTimezone reads and validates a source's timestamp time zone
+// from its properties. It returns an empty string if none is set.
+func ParseTimestampTimezone(properties Properties) (string, error) {
+	timezone := properties[SourceTimestampTimezoneProperty]
+	if timezone == "" {
+		return "", nil
+	}
+	if _, err := time.LoadLocation(timezone); err != nil {
+		return "", fmt.Errorf("invalid %s %q: %w", SourceTimestampTimezoneProperty, timezone, err)
+	}
+	return timezone, nil
+}
+
+// TimestampTimezone returns the time zone of the source's naive timestamps.
+func (variant *SourceVariant) TimestampTimezone() (string, error) {
+	return ParseTimestampTimezone(variant.Properties())
+}
@@ -0,0 +1,35 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package metadata
+
+import (
+	"testing"
+)
+
+func TestParseTimestampTimezone(t *testing.T) {
+	tests := []struct {
+		name       string
+		properties Properties
+		expected   string
+		wantErr    bool
+	}{
+		{"Unset", Properties{}, "", false},
+		{"Empty", Properties{SourceTimestampTimezoneProperty: ""}, "", false},
+		{"Valid", Properties{SourceTimestampTimezoneProperty: "America/Los_Angeles"}, "America/Los_Angeles", false},
+		{"Invalid", Properties{SourceTimestampTimezoneProperty: "Pacific Time"}, "", true},
+		{"Quoted", Properties{SourceTimestampTimezoneProperty: "UTC'; DROP TABLE x; --"}, "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			timezone, err := ParseTimestampTimezone(tt.properties)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Expected error %v, got %v", tt.wantErr, err)
+			}
+			if timezone != tt.expected {
+				t.Fatalf("Expected %q, got %q", tt.expected, timezone)
+			}
+		})
+	}
+}
@@ -0,0 +1,99 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package metadata
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	pb "github.com/featureform/metadata/proto"
+	"go.uber.org/zap"
+)
+
+func TestStreamingTransformationSource(t *testing.T) {
+	streaming := StreamingTransformationType{
+		Query:   "SELECT user, COUNT(*) AS clicks FROM {{ events.v1 }} GROUP BY user",
+		Sources: NameVariants{{Name: "events", Variant: "v1"}},
+		Trigger: "1 minute",
+	}
+	definition, err := TransformationSource{TransformationType: streaming}.Serialize()
+	if err != nil {
+		t.Fatalf("Failed to serialize streaming transformation: %s", err)
+	}
+	serialized := &pb.SourceVariant{Name: "clicks", Variant: "v1", Definition: definition}
+	variant := wrapProtoSourceVariant(serialized)
+	if !variant.IsStreamingTransformation() || variant.IsSQLTransformation() || variant.IsDBTTransformation() {
+		t.Fatalf("Expected only a streaming transformation")
+	}
+	if !reflect.DeepEqual(variant.StreamingTransformation(), streaming) {
+		t.Fatalf("Expected %v, got %v", streaming, variant.StreamingTransformation())
+	}
+	expectedInputs := []ResourceID{{Name: "events", Variant: "v1", Type: SOURCE_VARIANT}}
+	if inputs := transformationInputs(serialized); !reflect.DeepEqual(inputs, expectedInputs) {
+		t.Fatalf("Expected inputs %v, got %v", expectedInputs, inputs)
+	}
+}
+
+func TestKafkaTopicSource(t *testing.T) {
+	topic := KafkaTopic{Topic: "clicks", ValueFormat: "avro", StartingOffsets: "latest"}
+	definition, err := PrimaryDataSource{Location: topic}.Serialize()
+	if err != nil {
+		t.Fatalf("Failed to serialize kafka topic: %s", err)
+	}
+	variant := wrapProtoSourceVariant(&pb.SourceVariant{Name: "clicks", Variant: "v1", Definition: definition})
+	if !variant.IsKafkaTopic() || variant.IsPrimaryDataSQLTable() || variant.IsTransformation() {
+		t.Fatalf("Expected only a kafka topic")
+	}
+	if variant.KafkaTopic() != topic {
+		t.Fatalf("Expected %v, got %v", topic, variant.KafkaTopic())
+	}
+}
+
+func TestSetStreamingOffsets(t *testing.T) {
+	definition, err := TransformationSource{TransformationType: StreamingTransformationType{
+		Query:   "SELECT * FROM {{ clicks.v1 }}",
+		Sources: NameVariants{{Name: "clicks", Variant: "v1"}},
+	}}.Serialize()
+	if err != nil {
+		t.Fatalf("Failed to serialize streaming transformation: %s", err)
+	}
+	id := ResourceID{Name: "stream", Variant: "v1", Type: SOURCE_VARIANT}
+	lookup := make(localResourceLookup)
+	lookup[id] = &sourceVariantResource{&pb.SourceVariant{Name: id.Name, Variant: id.Variant, Definition: definition}}
+	serv := &MetadataServer{Logger: zap.NewNop().Sugar(), lookup: lookup}
+
+	set := func(batch int64, offset int64) {
+		_, err := serv.SetStreamingOffsets(context.Background(), &pb.StreamingOffsetsRequest{
+			Source:          &pb.NameVariant{Name: id.Name, Variant: id.Variant},
+			CheckpointBatch: batch,
+			Offsets:         []*pb.KafkaOffset{{Topic: "clicks", Partition: 0, Offset: offset}},
+		})
+		if err != nil {
+			t.Fatalf("Failed to set offsets: %s", err)
+		}
+	}
+	set(4, 120)
+	// Offsets of an older batch, which are reported late, are ignored.
+	set(3, 100)
+	batch, offsets := wrapProtoSourceVariant(lookup[id].(*sourceVariantResource).serialized).StreamingOffsets()
+	expected := []KafkaOffset{{Topic: "clicks", Partition: 0, Offset: 120}}
+	if batch != 4 || !reflect.DeepEqual(offsets, expected) {
+		t.Fatalf("Expected batch 4 offsets %v, got batch %d offsets %v", expected, batch, offsets)
+	}
+
+	topicID := ResourceID{Name: "clicks", Variant: "v1", Type: SOURCE_VARIANT}
+	topicDefinition, err := PrimaryDataSource{Location: KafkaTopic{Topic: "clicks"}}.Serialize()
+	if err != nil {
+		t.Fatalf("Failed to serialize kafka topic: %s", err)
+	}
+	lookup[topicID] = &sourceVariantResource{&pb.SourceVariant{Name: topicID.Name, Variant: topicID.Variant, Definition: topicDefinition}}
+	_, err = serv.SetStreamingOffsets(context.Background(), &pb.StreamingOffsetsRequest{
+		Source: &pb.NameVariant{Name: topicID.Name, Variant: topicID.Variant},
+	})
+	if err == nil {
+		t.Fatalf("Expected setting offsets of a kafka topic to fail")
+	}
+}
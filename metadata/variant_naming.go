@@ -0,0 +1,185 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package metadata
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"regexp"
+	"strconv"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	grpcmd "google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+
+	pb "github.com/featureform/metadata/proto"
+)
+
+// Variant properties that have the server name a variant registered without
+// one, so that pipelines don't have to invent their own naming schemes.
+const (
+	VariantStrategyProperty   = "variant_strategy"
+	VariantSemverBumpProperty = "variant_semver_bump"
+)
+
+// GeneratedVariantHeader is the response header that a generated variant's
+// name is returned in.
+const GeneratedVariantHeader = "featureform-variant"
+
+type VariantStrategy string
+
+const (
+	// TimestampVariants are named after the time they're registered, in UTC
+	// with millisecond precision, like 20230102T150405.000Z.
+	TimestampVariants VariantStrategy = "timestamp"
+	// SemverVariants bump the highest vMAJOR.MINOR.PATCH variant of the
+	// resource. The part that's bumped is set by VariantSemverBumpProperty
+	// and defaults to the patch version. The first variant is v1.0.0.
+	SemverVariants VariantStrategy = "semver"
+	// ContentHashVariants are named after a hash of their definition, so
+	// registering an identical definition again reuses its variant.
+	ContentHashVariants VariantStrategy = "content_hash"
+)
+
+var semverVariantPattern = regexp.MustCompile(`^v(\d+)\.(\d+)\.(\d+)$`)
+
+// ParseVariantStrategy reads and validates a variant's naming strategy from
+// its properties. It returns an empty strategy if none is set.
+func ParseVariantStrategy(properties Properties) (VariantStrategy, error) {
+	strategy := VariantStrategy(properties[VariantStrategyProperty])
+	switch strategy {
+	case "", TimestampVariants, ContentHashVariants:
+	case SemverVariants:
+		switch bump := properties[VariantSemverBumpProperty]; bump {
+		case "", "major", "minor", "patch":
+		default:
+			return "", fmt.Errorf("invalid %s %q: must be major, minor or patch", VariantSemverBumpProperty, bump)
+		}
+	default:
+		return "", fmt.Errorf("invalid %s %q: must be %s, %s or %s", VariantStrategyProperty, strategy, TimestampVariants, SemverVariants, ContentHashVariants)
+	}
+	return strategy, nil
+}
+
+// variantMessage is implemented by the protos of every resource variant.
+type variantMessage interface {
+	proto.Message
+	GetName() string
+	GetVariant() string
+	GetProperties() *pb.Properties
+}
+
+// nameVariant names a variant registered without one using its naming
+// strategy, if it has one, and sends the name back in the response header.
+// parentType is the type of the resource the variant belongs to.
+func (serv *MetadataServer) nameVariant(ctx context.Context, msg variantMessage, parentType ResourceType, setVariant func(string)) error {
+	properties := fetchPropertiesFn{msg}.Properties()
+	strategy, err := ParseVariantStrategy(properties)
+	if err != nil {
+		return status.Error(codes.InvalidArgument, err.Error())
+	}
+	if strategy == "" {
+		return nil
+	}
+	if msg.GetVariant() != "" {
+		return status.Errorf(codes.InvalidArgument, "variant %s must be empty to be named by %s %s", msg.GetVariant(), VariantStrategyProperty, strategy)
+	}
+	var variant string
+	switch strategy {
+	case TimestampVariants:
+		variant = time.Now().UTC().Format("20060102T150405.000Z")
+	case SemverVariants:
+		variants, err := serv.existingVariants(ResourceID{Name: msg.GetName(), Type: parentType})
+		if err != nil {
+			return err
+		}
+		variant = nextSemverVariant(variants, properties[VariantSemverBumpProperty])
+	case ContentHashVariants:
+		variant, err = contentHashVariant(msg)
+		if err != nil {
+			return err
+		}
+	}
+	setVariant(variant)
+	if err := grpc.SetHeader(ctx, grpcmd.Pairs(GeneratedVariantHeader, variant)); err != nil {
+		// The server is being called directly rather than over gRPC.
+		serv.Logger.Debugw("Could not set generated variant header", "name", msg.GetName(), "variant", variant, "error", err)
+	}
+	return nil
+}
+
+func (serv *MetadataServer) existingVariants(parentID ResourceID) ([]string, error) {
+	parent, err := serv.lookup.Lookup(parentID)
+	if _, notFound := err.(*ResourceNotFound); notFound {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+	withVariants, ok := parent.Proto().(interface{ GetVariants() []string })
+	if !ok {
+		return nil, fmt.Errorf("%s has no variants", parentID)
+	}
+	return withVariants.GetVariants(), nil
+}
+
+func nextSemverVariant(variants []string, bump string) string {
+	var latest [3]int
+	found := false
+	for _, variant := range variants {
+		match := semverVariantPattern.FindStringSubmatch(variant)
+		if match == nil {
+			continue
+		}
+		var version [3]int
+		for i := range version {
+			version[i], _ = strconv.Atoi(match[i+1])
+		}
+		if !found || compareVersions(version, latest) > 0 {
+			latest = version
+			found = true
+		}
+	}
+	if !found {
+		return "v1.0.0"
+	}
+	switch bump {
+	case "major":
+		latest = [3]int{latest[0] + 1, 0, 0}
+	case "minor":
+		latest = [3]int{latest[0], latest[1] + 1, 0}
+	default:
+		latest[2]++
+	}
+	return fmt.Sprintf("v%d.%d.%d", latest[0], latest[1], latest[2])
+}
+
+func compareVersions(a, b [3]int) int {
+	for i := range a {
+		if a[i] != b[i] {
+			return a[i] - b[i]
+		}
+	}
+	return 0
+}
+
+// contentHashVariant hashes the fields that define the variant, so variants
+// that only differ in their description, tags or properties share a hash.
+func contentHashVariant(msg proto.Message) (string, error) {
+	definition := definitionOf(msg)
+	if definition == nil {
+		return "", fmt.Errorf("%T has no definition to hash", msg)
+	}
+	serialized, err := proto.MarshalOptions{Deterministic: true}.Marshal(definition)
+	if err != nil {
+		return "", fmt.Errorf("could not serialize definition: %w", err)
+	}
+	sum := sha256.Sum256(serialized)
+	return hex.EncodeToString(sum[:8]), nil
+}
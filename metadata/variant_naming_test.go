@@ -0,0 +1,97 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package metadata
+
+import (
+	"testing"
+
+	pb "github.com/featureform/metadata/proto"
+)
+
+func TestParseVariantStrategy(t *testing.T) {
+	tests := []struct {
+		name       string
+		properties Properties
+		expected   VariantStrategy
+		wantErr    bool
+	}{
+		{"Unset", Properties{}, "", false},
+		{"Timestamp", Properties{VariantStrategyProperty: "timestamp"}, TimestampVariants, false},
+		{"Semver", Properties{VariantStrategyProperty: "semver", VariantSemverBumpProperty: "minor"}, SemverVariants, false},
+		{"Content Hash", Properties{VariantStrategyProperty: "content_hash"}, ContentHashVariants, false},
+		{"Unknown", Properties{VariantStrategyProperty: "random"}, "", true},
+		{"Invalid Bump", Properties{VariantStrategyProperty: "semver", VariantSemverBumpProperty: "build"}, "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			strategy, err := ParseVariantStrategy(tt.properties)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Expected error %v, got %v", tt.wantErr, err)
+			}
+			if strategy != tt.expected {
+				t.Fatalf("Expected %q, got %q", tt.expected, strategy)
+			}
+		})
+	}
+}
+
+func TestNextSemverVariant(t *testing.T) {
+	tests := []struct {
+		name     string
+		variants []string
+		bump     string
+		expected string
+	}{
+		{"First", nil, "", "v1.0.0"},
+		{"Ignores Others", []string{"default", "1.2.3", "v1.2"}, "", "v1.0.0"},
+		{"Patch", []string{"v1.2.3", "default"}, "", "v1.2.4"},
+		{"Minor", []string{"v1.2.3"}, "minor", "v1.3.0"},
+		{"Major", []string{"v1.2.3"}, "major", "v2.0.0"},
+		{"Highest", []string{"v1.10.0", "v1.9.5", "v0.20.0"}, "patch", "v1.10.1"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if next := nextSemverVariant(tt.variants, tt.bump); next != tt.expected {
+				t.Fatalf("Expected %s, got %s", tt.expected, next)
+			}
+		})
+	}
+}
+
+func TestContentHashVariant(t *testing.T) {
+	newVariant := func(entity, description string) *pb.FeatureVariant {
+		return &pb.FeatureVariant{
+			Name:        "feature",
+			Source:      &pb.NameVariant{Name: "source", Variant: "v1"},
+			Entity:      entity,
+			Type:        "int",
+			Description: description,
+		}
+	}
+	hash, err := contentHashVariant(newVariant("user", "first"))
+	if err != nil {
+		t.Fatalf("Failed to hash variant: %s", err)
+	}
+	if len(hash) != 16 {
+		t.Fatalf("Expected 16 character hash, got %q", hash)
+	}
+	same, err := contentHashVariant(newVariant("user", "second"))
+	if err != nil {
+		t.Fatalf("Failed to hash variant: %s", err)
+	}
+	if same != hash {
+		t.Fatalf("Expected variants differing only in description to share a hash: %s != %s", same, hash)
+	}
+	different, err := contentHashVariant(newVariant("item", "first"))
+	if err != nil {
+		t.Fatalf("Failed to hash variant: %s", err)
+	}
+	if different == hash {
+		t.Fatalf("Expected variants with different definitions to have different hashes")
+	}
+	if _, err := contentHashVariant(&pb.Entity{Name: "user"}); err == nil {
+		t.Fatalf("Expected error hashing a resource without variants")
+	}
+}
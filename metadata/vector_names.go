@@ -0,0 +1,48 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package metadata
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// FeatureVectorNamesProperty is the feature property listing, comma
+// separated, the named vectors each entity of an embedding holds, like
+// "title,body". The first name is the one written by materializations and
+// searched by default. Since the names are built into the index, they can't
+// be changed once the feature is registered.
+const FeatureVectorNamesProperty = "vector_names"
+
+var vectorNamePattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// ParseVectorNames reads and validates a feature's vector names from its
+// properties. It returns nil if none are set.
+func ParseVectorNames(properties Properties) ([]string, error) {
+	value := properties[FeatureVectorNamesProperty]
+	if value == "" {
+		return nil, nil
+	}
+	names := strings.Split(value, ",")
+	seen := make(map[string]bool, len(names))
+	for i, name := range names {
+		name = strings.TrimSpace(name)
+		if !vectorNamePattern.MatchString(name) {
+			return nil, fmt.Errorf("invalid %s %q: names must be letters, digits and underscores", FeatureVectorNamesProperty, value)
+		}
+		if seen[name] {
+			return nil, fmt.Errorf("invalid %s %q: duplicate name %q", FeatureVectorNamesProperty, value, name)
+		}
+		seen[name] = true
+		names[i] = name
+	}
+	return names, nil
+}
+
+// VectorNames returns the names of the embedding's vectors.
+func (variant *FeatureVariant) VectorNames() ([]string, error) {
+	return ParseVectorNames(variant.Properties())
+}
@@ -0,0 +1,36 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package metadata
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseVectorNames(t *testing.T) {
+	tests := []struct {
+		name       string
+		properties Properties
+		expected   []string
+		wantErr    bool
+	}{
+		{"Unset", Properties{}, nil, false},
+		{"Names", Properties{FeatureVectorNamesProperty: "title, body"}, []string{"title", "body"}, false},
+		{"Duplicate", Properties{FeatureVectorNamesProperty: "title,title"}, nil, true},
+		{"Empty Name", Properties{FeatureVectorNamesProperty: "title,"}, nil, true},
+		{"Invalid Name", Properties{FeatureVectorNamesProperty: "title-vector"}, nil, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			names, err := ParseVectorNames(tt.properties)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Expected error %v, got %v", tt.wantErr, err)
+			}
+			if !reflect.DeepEqual(names, tt.expected) {
+				t.Fatalf("Expected %v, got %v", tt.expected, names)
+			}
+		})
+	}
+}
@@ -0,0 +1,38 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package metadata
+
+import (
+	"fmt"
+)
+
+// FeatureVectorStorageProperty is the feature property naming the element
+// type an embedding's vectors are stored as in its vector index. It's one of
+// "float16", "float32" or "float64", and defaults to the feature's type.
+// Half precision halves the size of an index on stores that support it.
+// Since it's built into the index, it can't be changed once the feature is
+// registered.
+const FeatureVectorStorageProperty = "vector_storage"
+
+var vectorStorageTypes = map[string]bool{
+	"float16": true,
+	"float32": true,
+	"float64": true,
+}
+
+// ParseVectorStorage reads and validates a feature's vector storage type
+// from its properties. It returns an empty string if none is set.
+func ParseVectorStorage(properties Properties) (string, error) {
+	storage := properties[FeatureVectorStorageProperty]
+	if storage != "" && !vectorStorageTypes[storage] {
+		return "", fmt.Errorf("invalid %s %q: must be float16, float32 or float64", FeatureVectorStorageProperty, storage)
+	}
+	return storage, nil
+}
+
+// VectorStorage returns the element type of the embedding's stored vectors.
+func (variant *FeatureVariant) VectorStorage() (string, error) {
+	return ParseVectorStorage(variant.Properties())
+}
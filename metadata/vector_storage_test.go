@@ -0,0 +1,34 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package metadata
+
+import (
+	"testing"
+)
+
+func TestParseVectorStorage(t *testing.T) {
+	tests := []struct {
+		name       string
+		properties Properties
+		expected   string
+		wantErr    bool
+	}{
+		{"Unset", Properties{}, "", false},
+		{"Half Precision", Properties{FeatureVectorStorageProperty: "float16"}, "float16", false},
+		{"Double Precision", Properties{FeatureVectorStorageProperty: "float64"}, "float64", false},
+		{"Invalid", Properties{FeatureVectorStorageProperty: "int8"}, "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			storage, err := ParseVectorStorage(tt.properties)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Expected error %v, got %v", tt.wantErr, err)
+			}
+			if storage != tt.expected {
+				t.Fatalf("Expected %q, got %q", tt.expected, storage)
+			}
+		})
+	}
+}
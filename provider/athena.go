@@ -0,0 +1,329 @@
+package provider
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	pc "github.com/featureform/provider/provider_config"
+	pt "github.com/featureform/provider/provider_type"
+	"github.com/google/uuid"
+)
+
+type athenaColumnType string
+
+const (
+	atInt       athenaColumnType = "integer"
+	atBigInt    athenaColumnType = "bigint"
+	atDouble    athenaColumnType = "double"
+	atString    athenaColumnType = "varchar"
+	atBool      athenaColumnType = "boolean"
+	atTimestamp athenaColumnType = "timestamp"
+)
+
+func athenaOfflineStoreFactory(config pc.SerializedConfig) (Provider, error) {
+	sc := pc.AthenaConfig{}
+	if err := sc.Deserialize(config); err != nil {
+		return nil, errors.New("invalid athena config")
+	}
+	if sc.OutputLocation == "" {
+		return nil, errors.New("athena config requires an OutputLocation to write tables to")
+	}
+	if sc.Database == "" {
+		sc.Database = "default"
+	}
+	queries := athenaSQLQueries{
+		Database:       sc.Database,
+		TablesLocation: fmt.Sprintf("%s/tables", strings.TrimSuffix(sc.OutputLocation, "/")),
+	}
+	queries.setVariableBinding(MySQLBindingStyle)
+	sgConfig := SQLOfflineStoreConfig{
+		Config:        config,
+		ConnectionURL: string(sc.Serialize()),
+		Driver:        athenaDriverName,
+		ProviderType:  pt.AthenaOffline,
+		QueryImpl:     &queries,
+	}
+
+	store, err := NewSQLOfflineStore(sgConfig)
+	if err != nil {
+		return nil, err
+	}
+	return store, nil
+}
+
+// athenaSQLQueries creates the tables that are written to, such as primary
+// and resource tables, as Iceberg tables so that they can be updated. Tables
+// created from queries are Parquet tables in the output location.
+//
+// Athena runs DDL, like CREATE TABLE with columns and DROP TABLE, with Hive,
+// which quotes names with backticks rather than double quotes. The catalog
+// stores names in lowercase.
+type athenaSQLQueries struct {
+	defaultOfflineSQLQueries
+	Database       string
+	TablesLocation string
+}
+
+func athenaDDLName(name string) string {
+	return fmt.Sprintf("`%s`", strings.ReplaceAll(name, "`", ""))
+}
+
+// tableLocation returns a new S3 prefix for a table's data. Dropping a table
+// leaves its data behind, so a table that's recreated can't reuse it.
+func (q athenaSQLQueries) tableLocation(name string) string {
+	return fmt.Sprintf("%s/%s/%s/", q.TablesLocation, strings.ToLower(name), uuid.NewString())
+}
+
+func (q athenaSQLQueries) tableExists() string {
+	return fmt.Sprintf("SELECT COUNT(*) FROM information_schema.tables WHERE table_schema='%s' AND table_type='BASE TABLE' AND table_name=lower(?)", q.Database)
+}
+
+func (q athenaSQLQueries) viewExists() string {
+	return fmt.Sprintf("SELECT COUNT(*) FROM information_schema.tables WHERE table_schema='%s' AND table_type='VIEW' AND table_name=lower(?)", q.Database)
+}
+
+func (q athenaSQLQueries) getTable() string {
+	return fmt.Sprintf("SELECT table_name FROM information_schema.tables WHERE table_schema='%s' AND table_name=lower(?)", q.Database)
+}
+
+func (q athenaSQLQueries) materializationExists() string {
+	return q.getTable()
+}
+
+func (q athenaSQLQueries) transformationExists() string {
+	return q.getTable()
+}
+
+func (q athenaSQLQueries) getColumns(db *sql.DB, name string) ([]TableColumn, error) {
+	qry := fmt.Sprintf("SELECT column_name FROM information_schema.columns WHERE table_schema='%s' AND table_name=lower(?) ORDER BY ordinal_position", q.Database)
+	rows, err := db.Query(qry, name)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	columnNames := make([]TableColumn, 0)
+	for rows.Next() {
+		var column string
+		if err := rows.Scan(&column); err != nil {
+			return nil, err
+		}
+		columnNames = append(columnNames, TableColumn{Name: column})
+	}
+	return columnNames, rows.Err()
+}
+
+func (q athenaSQLQueries) registerResources(db *sql.DB, tableName string, schema ResourceSchema, timestamp bool) error {
+	var query string
+	if timestamp {
+		ts := fmt.Sprintf("CAST(%s AS timestamp)", sanitize(schema.TS))
+		if schema.TSTimezone != "" {
+			ts = fmt.Sprintf("CAST(with_timezone(CAST(%s AS timestamp), '%s') AT TIME ZONE 'UTC' AS timestamp)", sanitize(schema.TS), schema.TSTimezone)
+		}
+		query = fmt.Sprintf("CREATE VIEW %s AS SELECT %s as entity, %s as value, %s as ts FROM %s", sanitize(tableName),
+			sanitize(schema.Entity), sanitize(schema.Value), ts, sanitize(schema.SourceTable))
+	} else {
+		query = fmt.Sprintf("CREATE VIEW %s AS SELECT %s as entity, %s as value, TIMESTAMP '%s' as ts FROM %s", sanitize(tableName),
+			sanitize(schema.Entity), sanitize(schema.Value), time.UnixMilli(0).UTC().Format(athenaTimeLayout), sanitize(schema.SourceTable))
+	}
+	if _, err := db.Exec(query); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (q athenaSQLQueries) primaryTableRegister(tableName string, sourceName string) string {
+	return fmt.Sprintf("CREATE VIEW %s AS SELECT * FROM %s", sanitize(tableName), sourceName)
+}
+
+func (q athenaSQLQueries) primaryTableCreate(name string, columnString string) string {
+	return fmt.Sprintf("CREATE TABLE %s ( %s ) LOCATION '%s' TBLPROPERTIES ('table_type'='ICEBERG')", athenaDDLName(name), columnString, q.tableLocation(name))
+}
+
+// athenaCTAS creates a Parquet table from a query. Athena writes its data
+// under the query results in the output location.
+func athenaCTAS(tableName string, query string) string {
+	return fmt.Sprintf("CREATE TABLE %s WITH (format='PARQUET') AS %s", tableName, query)
+}
+
+// replaceTable replaces a table with the results of a query. Athena can't
+// rename tables or run statements in a transaction, so the results are
+// written to a temporary table first and the table is recreated from it.
+// The table is briefly missing while it's recreated.
+func (q athenaSQLQueries) replaceTable(db *sql.DB, tableName string, query string) error {
+	tempName := fmt.Sprintf("tmp_%s", tableName)
+	statements := []string{
+		fmt.Sprintf("DROP TABLE IF EXISTS %s", athenaDDLName(tempName)),
+		athenaCTAS(sanitize(tempName), query),
+		fmt.Sprintf("DROP TABLE IF EXISTS %s", athenaDDLName(tableName)),
+		athenaCTAS(sanitize(tableName), fmt.Sprintf("SELECT * FROM %s", sanitize(tempName))),
+		fmt.Sprintf("DROP TABLE %s", athenaDDLName(tempName)),
+	}
+	for _, statement := range statements {
+		if _, err := db.Exec(statement); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (q athenaSQLQueries) materializationSelect(sourceName string) string {
+	return fmt.Sprintf(
+		"SELECT entity, value, ts, row_number() over(ORDER BY entity) as row_number FROM "+
+			"(SELECT entity, ts, value, row_number() OVER (PARTITION BY entity ORDER BY ts desc) "+
+			"AS rn FROM %s) t WHERE rn=1", sanitize(sourceName))
+}
+
+func (q athenaSQLQueries) materializationCreate(tableName string, sourceName string) string {
+	return athenaCTAS(sanitize(tableName), q.materializationSelect(sourceName))
+}
+
+func (q athenaSQLQueries) materializationUpdate(db *sql.DB, tableName string, sourceName string) error {
+	return q.replaceTable(db, tableName, q.materializationSelect(sourceName))
+}
+
+func (q athenaSQLQueries) materializationDrop(tableName string) string {
+	return fmt.Sprintf("DROP TABLE %s", athenaDDLName(tableName))
+}
+
+func (q athenaSQLQueries) dropTable(tableName string) string {
+	return fmt.Sprintf("DROP TABLE %s", athenaDDLName(tableName))
+}
+
+// determineColumnType returns the Hive type of a column, which is what
+// tables with columns are created with.
+func (q athenaSQLQueries) determineColumnType(valueType ValueType) (string, error) {
+	switch valueType {
+	case Int, Int64:
+		return "bigint", nil
+	case Int32:
+		return "int", nil
+	case Float32:
+		return "float", nil
+	case Float64:
+		return "double", nil
+	case String:
+		return "string", nil
+	case Bool:
+		return "boolean", nil
+	case Timestamp:
+		return "timestamp", nil
+	case NilType:
+		return "string", nil
+	default:
+		return "", fmt.Errorf("cannot find column type for value type: %s", valueType)
+	}
+}
+
+// athenaResourceTypes maps the generic column types that resource tables are
+// created with to Hive's types.
+var athenaResourceTypes = map[string]string{
+	"INT":         "bigint",
+	"FLOAT8":      "double",
+	"VARCHAR":     "string",
+	"BOOLEAN":     "boolean",
+	"TIMESTAMPTZ": "timestamp",
+}
+
+func (q athenaSQLQueries) newSQLOfflineTable(name string, columnType string) string {
+	if atType, ok := athenaResourceTypes[columnType]; ok {
+		columnType = atType
+	}
+	return fmt.Sprintf("CREATE TABLE %s (entity string, value %s, ts timestamp) LOCATION '%s' TBLPROPERTIES ('table_type'='ICEBERG')",
+		athenaDDLName(name), columnType, q.tableLocation(name))
+}
+
+func (q athenaSQLQueries) writeExists(table string) string {
+	return fmt.Sprintf("SELECT COUNT(*) FROM %s WHERE entity=? AND ts=?", table)
+}
+
+func (q athenaSQLQueries) trainingSetCreate(store *sqlOfflineStore, def TrainingSetDef, tableName string, labelName string) error {
+	return q.trainingSetQuery(store, def, tableName, labelName, false)
+}
+
+func (q athenaSQLQueries) trainingSetUpdate(store *sqlOfflineStore, def TrainingSetDef, tableName string, labelName string) error {
+	return q.trainingSetQuery(store, def, tableName, labelName, true)
+}
+
+// trainingSetQuery selects each feature's latest value at or before every
+// label with a correlated subquery, since Athena doesn't have ASOF joins.
+func (q athenaSQLQueries) trainingSetQuery(store *sqlOfflineStore, def TrainingSetDef, tableName string, labelName string, isUpdate bool) error {
+	columns := make([]string, 0)
+	for _, feature := range def.Features {
+		featureTable, err := store.getResourceTableName(feature)
+		if err != nil {
+			return err
+		}
+		sanitizedName := sanitize(featureTable)
+		columns = append(columns, fmt.Sprintf("(SELECT max_by(f.value, f.ts) FROM %s f WHERE f.entity=l.entity AND f.ts<=l.ts) AS %s",
+			sanitizedName, sanitizedName))
+	}
+	for _, lagFeature := range def.LagFeatures {
+		featureTable, err := store.getResourceTableName(ResourceID{lagFeature.FeatureName, lagFeature.FeatureVariant, Feature})
+		if err != nil {
+			return err
+		}
+		lagColumnName := sanitize(lagFeature.LagName)
+		if lagFeature.LagName == "" {
+			lagColumnName = sanitize(fmt.Sprintf("%s_lag_%s", featureTable, lagFeature.LagDelta))
+		}
+		columns = append(columns, fmt.Sprintf("(SELECT max_by(f.value, f.ts) FROM %s f WHERE f.entity=l.entity AND f.ts<=l.ts - parse_duration('%dms')) AS %s",
+			sanitize(featureTable), lagFeature.LagDelta.Milliseconds(), lagColumnName))
+	}
+	query := fmt.Sprintf("SELECT %s, l.value AS label FROM %s l", strings.Join(columns, ", "), sanitize(labelName))
+	if isUpdate {
+		return q.replaceTable(store.db, tableName, query)
+	}
+	_, err := store.db.Exec(athenaCTAS(sanitize(tableName), query))
+	return err
+}
+
+func (q athenaSQLQueries) castTableItemType(v interface{}, t interface{}) interface{} {
+	if v == nil {
+		return v
+	}
+	switch t {
+	case atInt, atBigInt:
+		return int(v.(int64))
+	case atDouble:
+		return v.(float64)
+	case atString:
+		return v.(string)
+	case atBool:
+		return v.(bool)
+	case atTimestamp:
+		return v.(time.Time).UTC()
+	default:
+		return v
+	}
+}
+
+func (q athenaSQLQueries) getValueColumnType(t *sql.ColumnType) interface{} {
+	switch t.DatabaseTypeName() {
+	case "TINYINT", "SMALLINT", "INTEGER", "INT":
+		return atInt
+	case "BIGINT":
+		return atBigInt
+	case "REAL", "FLOAT", "DOUBLE":
+		return atDouble
+	case "BOOLEAN":
+		return atBool
+	case "TIMESTAMP", "DATE":
+		return atTimestamp
+	}
+	return atString
+}
+
+func (q athenaSQLQueries) numRows(n interface{}) (int64, error) {
+	return n.(int64), nil
+}
+
+func (q athenaSQLQueries) transformationCreate(name string, query string) string {
+	return athenaCTAS(sanitize(name), query)
+}
+
+func (q athenaSQLQueries) transformationUpdate(db *sql.DB, tableName string, query string) error {
+	return q.replaceTable(db, tableName, query)
+}
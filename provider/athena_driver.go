@@ -0,0 +1,333 @@
+package provider
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/credentials"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/athena"
+	"github.com/aws/aws-sdk-go/service/athena/athenaiface"
+	pc "github.com/featureform/provider/provider_config"
+)
+
+const (
+	athenaDriverName    = "athena"
+	athenaPollInterval  = 500 * time.Millisecond
+	athenaTimeLayout    = "2006-01-02 15:04:05.000"
+	athenaResultsPerGet = 1000
+)
+
+func init() {
+	sql.Register(athenaDriverName, athenaDriver{})
+}
+
+// athenaDriver is a database/sql driver that runs each statement as an
+// Athena query. Its data source name is a serialized AthenaConfig. Athena
+// has no transactions or server-side bind variables, so arguments are bound
+// as literals before the query is started.
+type athenaDriver struct{}
+
+func (athenaDriver) Open(dsn string) (driver.Conn, error) {
+	config := pc.AthenaConfig{}
+	if err := config.Deserialize(pc.SerializedConfig(dsn)); err != nil {
+		return nil, fmt.Errorf("invalid athena config: %w", err)
+	}
+	awsConfig := &aws.Config{Region: aws.String(config.Region)}
+	if config.AccessKey != "" {
+		awsConfig.Credentials = credentials.NewStaticCredentials(config.AccessKey, config.SecretKey, "")
+	}
+	sess, err := session.NewSession(awsConfig)
+	if err != nil {
+		return nil, err
+	}
+	return &athenaConn{client: athena.New(sess), config: config}, nil
+}
+
+type athenaConn struct {
+	client athenaiface.AthenaAPI
+	config pc.AthenaConfig
+}
+
+func (c *athenaConn) Prepare(query string) (driver.Stmt, error) {
+	return &athenaStmt{conn: c, query: query}, nil
+}
+
+func (c *athenaConn) Close() error {
+	return nil
+}
+
+func (c *athenaConn) Begin() (driver.Tx, error) {
+	return nil, errors.New("athena doesn't support transactions")
+}
+
+func (c *athenaConn) ExecContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	if _, err := c.run(ctx, query, namedValues(args)); err != nil {
+		return nil, err
+	}
+	return driver.ResultNoRows, nil
+}
+
+func (c *athenaConn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	execution, err := c.run(ctx, query, namedValues(args))
+	if err != nil {
+		return nil, err
+	}
+	rows := &athenaRows{
+		ctx:     ctx,
+		client:  c.client,
+		queryID: aws.StringValue(execution.QueryExecutionId),
+		// Athena returns the column names as the first row of a SELECT.
+		skipHeader: aws.StringValue(execution.StatementType) == athena.StatementTypeDml,
+	}
+	if err := rows.fetch(); err != nil {
+		return nil, err
+	}
+	return rows, nil
+}
+
+// run starts the query and waits for it to finish.
+func (c *athenaConn) run(ctx context.Context, query string, args []driver.Value) (*athena.QueryExecution, error) {
+	bound, err := athenaBind(query, args)
+	if err != nil {
+		return nil, err
+	}
+	input := &athena.StartQueryExecutionInput{
+		QueryString: aws.String(bound),
+		QueryExecutionContext: &athena.QueryExecutionContext{
+			Database: aws.String(c.config.Database),
+		},
+	}
+	if c.config.Catalog != "" {
+		input.QueryExecutionContext.Catalog = aws.String(c.config.Catalog)
+	}
+	if c.config.Workgroup != "" {
+		input.WorkGroup = aws.String(c.config.Workgroup)
+	}
+	if c.config.OutputLocation != "" {
+		input.ResultConfiguration = &athena.ResultConfiguration{OutputLocation: aws.String(c.config.OutputLocation)}
+	}
+	started, err := c.client.StartQueryExecutionWithContext(ctx, input)
+	if err != nil {
+		return nil, err
+	}
+	for {
+		out, err := c.client.GetQueryExecutionWithContext(ctx, &athena.GetQueryExecutionInput{QueryExecutionId: started.QueryExecutionId})
+		if err != nil {
+			return nil, err
+		}
+		execution := out.QueryExecution
+		switch aws.StringValue(execution.Status.State) {
+		case athena.QueryExecutionStateSucceeded:
+			return execution, nil
+		case athena.QueryExecutionStateFailed, athena.QueryExecutionStateCancelled:
+			return nil, fmt.Errorf("athena query %s %s: %s", aws.StringValue(started.QueryExecutionId),
+				strings.ToLower(aws.StringValue(execution.Status.State)), aws.StringValue(execution.Status.StateChangeReason))
+		}
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(athenaPollInterval):
+		}
+	}
+}
+
+type athenaStmt struct {
+	conn  *athenaConn
+	query string
+}
+
+func (s *athenaStmt) Close() error {
+	return nil
+}
+
+func (s *athenaStmt) NumInput() int {
+	return -1
+}
+
+func (s *athenaStmt) Exec(args []driver.Value) (driver.Result, error) {
+	if _, err := s.conn.run(context.Background(), s.query, args); err != nil {
+		return nil, err
+	}
+	return driver.ResultNoRows, nil
+}
+
+func (s *athenaStmt) Query(args []driver.Value) (driver.Rows, error) {
+	named := make([]driver.NamedValue, len(args))
+	for i, arg := range args {
+		named[i] = driver.NamedValue{Ordinal: i + 1, Value: arg}
+	}
+	return s.conn.QueryContext(context.Background(), s.query, named)
+}
+
+func namedValues(args []driver.NamedValue) []driver.Value {
+	values := make([]driver.Value, len(args))
+	for i, arg := range args {
+		values[i] = arg.Value
+	}
+	return values
+}
+
+// athenaBind replaces each ? outside of quoted strings and names with the next
+// argument as a SQL literal.
+func athenaBind(query string, args []driver.Value) (string, error) {
+	var bound strings.Builder
+	next := 0
+	var quote rune
+	for _, r := range query {
+		switch {
+		case quote != 0:
+			if r == quote {
+				quote = 0
+			}
+		case r == '\'' || r == '"' || r == '`':
+			quote = r
+		case r == '?':
+			if next >= len(args) {
+				return "", fmt.Errorf("athena query has more placeholders than the %d arguments", len(args))
+			}
+			literal, err := athenaLiteral(args[next])
+			if err != nil {
+				return "", err
+			}
+			bound.WriteString(literal)
+			next++
+			continue
+		}
+		bound.WriteRune(r)
+	}
+	if next != len(args) {
+		return "", fmt.Errorf("athena query has %d placeholders for %d arguments", next, len(args))
+	}
+	return bound.String(), nil
+}
+
+func athenaLiteral(v driver.Value) (string, error) {
+	switch value := v.(type) {
+	case nil:
+		return "NULL", nil
+	case string:
+		return fmt.Sprintf("'%s'", strings.ReplaceAll(value, "'", "''")), nil
+	case int64:
+		return strconv.FormatInt(value, 10), nil
+	case float64:
+		return fmt.Sprintf("DOUBLE '%s'", strconv.FormatFloat(value, 'g', -1, 64)), nil
+	case bool:
+		return strings.ToUpper(strconv.FormatBool(value)), nil
+	case time.Time:
+		return fmt.Sprintf("TIMESTAMP '%s'", value.UTC().Format(athenaTimeLayout)), nil
+	case []byte:
+		return fmt.Sprintf("X'%s'", hex.EncodeToString(value)), nil
+	default:
+		return "", fmt.Errorf("athena can't bind argument of type %T", v)
+	}
+}
+
+type athenaRows struct {
+	ctx        context.Context
+	client     athenaiface.AthenaAPI
+	queryID    string
+	columns    []*athena.ColumnInfo
+	rows       []*athena.Row
+	next       int
+	nextToken  *string
+	fetched    bool
+	skipHeader bool
+}
+
+// fetch gets the next page of results.
+func (r *athenaRows) fetch() error {
+	out, err := r.client.GetQueryResultsWithContext(r.ctx, &athena.GetQueryResultsInput{
+		QueryExecutionId: aws.String(r.queryID),
+		MaxResults:       aws.Int64(athenaResultsPerGet),
+		NextToken:        r.nextToken,
+	})
+	if err != nil {
+		return err
+	}
+	r.rows = out.ResultSet.Rows
+	r.next = 0
+	if !r.fetched {
+		if out.ResultSet.ResultSetMetadata != nil {
+			r.columns = out.ResultSet.ResultSetMetadata.ColumnInfo
+		}
+		if r.skipHeader && len(r.rows) > 0 {
+			r.next = 1
+		}
+		r.fetched = true
+	}
+	r.nextToken = out.NextToken
+	return nil
+}
+
+func (r *athenaRows) Columns() []string {
+	names := make([]string, len(r.columns))
+	for i, column := range r.columns {
+		names[i] = aws.StringValue(column.Name)
+	}
+	return names
+}
+
+func (r *athenaRows) ColumnTypeDatabaseTypeName(index int) string {
+	return strings.ToUpper(aws.StringValue(r.columns[index].Type))
+}
+
+func (r *athenaRows) Close() error {
+	return nil
+}
+
+func (r *athenaRows) Next(dest []driver.Value) error {
+	for r.next >= len(r.rows) {
+		if r.nextToken == nil {
+			return io.EOF
+		}
+		if err := r.fetch(); err != nil {
+			return err
+		}
+	}
+	row := r.rows[r.next]
+	r.next++
+	for i := range dest {
+		var value *string
+		if i < len(row.Data) {
+			value = row.Data[i].VarCharValue
+		}
+		parsed, err := athenaValue(value, aws.StringValue(r.columns[i].Type))
+		if err != nil {
+			return err
+		}
+		dest[i] = parsed
+	}
+	return nil
+}
+
+// athenaValue parses a value from Athena's string representation of its
+// type. Athena returns NULL as a missing value.
+func athenaValue(value *string, columnType string) (driver.Value, error) {
+	if value == nil {
+		return nil, nil
+	}
+	switch strings.ToLower(columnType) {
+	case "tinyint", "smallint", "integer", "int", "bigint":
+		return strconv.ParseInt(*value, 10, 64)
+	case "real", "float", "double":
+		return strconv.ParseFloat(*value, 64)
+	case "boolean":
+		return strconv.ParseBool(*value)
+	case "timestamp":
+		return time.Parse("2006-01-02 15:04:05.999999999", *value)
+	case "date":
+		return time.Parse("2006-01-02", *value)
+	default:
+		return *value, nil
+	}
+}
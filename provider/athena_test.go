@@ -0,0 +1,181 @@
+package provider
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/service/athena"
+	"github.com/aws/aws-sdk-go/service/athena/athenaiface"
+	pc "github.com/featureform/provider/provider_config"
+)
+
+// fakeAthena succeeds every query and returns pages of results for SELECTs.
+type fakeAthena struct {
+	athenaiface.AthenaAPI
+	queries []string
+	pages   [][]*athena.Row
+	columns []*athena.ColumnInfo
+	fail    bool
+}
+
+func (f *fakeAthena) StartQueryExecutionWithContext(_ aws.Context, input *athena.StartQueryExecutionInput, _ ...request.Option) (*athena.StartQueryExecutionOutput, error) {
+	f.queries = append(f.queries, aws.StringValue(input.QueryString))
+	return &athena.StartQueryExecutionOutput{QueryExecutionId: aws.String("query-id")}, nil
+}
+
+func (f *fakeAthena) GetQueryExecutionWithContext(aws.Context, *athena.GetQueryExecutionInput, ...request.Option) (*athena.GetQueryExecutionOutput, error) {
+	state := athena.QueryExecutionStateSucceeded
+	if f.fail {
+		state = athena.QueryExecutionStateFailed
+	}
+	return &athena.GetQueryExecutionOutput{QueryExecution: &athena.QueryExecution{
+		QueryExecutionId: aws.String("query-id"),
+		StatementType:    aws.String(athena.StatementTypeDml),
+		Status: &athena.QueryExecutionStatus{
+			State:             aws.String(state),
+			StateChangeReason: aws.String("TABLE_NOT_FOUND"),
+		},
+	}}, nil
+}
+
+func (f *fakeAthena) GetQueryResultsWithContext(_ aws.Context, input *athena.GetQueryResultsInput, _ ...request.Option) (*athena.GetQueryResultsOutput, error) {
+	page := 0
+	if input.NextToken != nil {
+		page = 1
+	}
+	out := &athena.GetQueryResultsOutput{ResultSet: &athena.ResultSet{
+		ResultSetMetadata: &athena.ResultSetMetadata{ColumnInfo: f.columns},
+		Rows:              f.pages[page],
+	}}
+	if page+1 < len(f.pages) {
+		out.NextToken = aws.String("next")
+	}
+	return out, nil
+}
+
+type fakeAthenaConnector struct {
+	conn *athenaConn
+}
+
+func (c fakeAthenaConnector) Connect(context.Context) (driver.Conn, error) {
+	return c.conn, nil
+}
+
+func (c fakeAthenaConnector) Driver() driver.Driver {
+	return athenaDriver{}
+}
+
+func athenaRow(values ...*string) *athena.Row {
+	row := &athena.Row{}
+	for _, value := range values {
+		row.Data = append(row.Data, &athena.Datum{VarCharValue: value})
+	}
+	return row
+}
+
+func TestAthenaBind(t *testing.T) {
+	ts := time.Date(2023, 1, 2, 3, 4, 5, 6000000, time.UTC)
+	query, err := athenaBind("SELECT '?', \"a?\" FROM t WHERE entity=? AND ts=? AND value=? AND b=? AND n=?",
+		[]driver.Value{"o'brien", ts, 1.5, true, nil})
+	if err != nil {
+		t.Fatalf("Failed to bind: %s", err)
+	}
+	expected := "SELECT '?', \"a?\" FROM t WHERE entity='o''brien' AND ts=TIMESTAMP '2023-01-02 03:04:05.006' AND value=DOUBLE '1.5' AND b=TRUE AND n=NULL"
+	if query != expected {
+		t.Fatalf("Expected %s, got %s", expected, query)
+	}
+	if _, err := athenaBind("SELECT ?", nil); err == nil {
+		t.Fatalf("Expected error for missing argument")
+	}
+	if _, err := athenaBind("SELECT 1", []driver.Value{int64(1)}); err == nil {
+		t.Fatalf("Expected error for extra argument")
+	}
+}
+
+func TestAthenaDriverQuery(t *testing.T) {
+	client := &fakeAthena{
+		columns: []*athena.ColumnInfo{
+			{Name: aws.String("entity"), Type: aws.String("varchar")},
+			{Name: aws.String("value"), Type: aws.String("bigint")},
+			{Name: aws.String("ts"), Type: aws.String("timestamp")},
+		},
+		pages: [][]*athena.Row{
+			{
+				athenaRow(aws.String("entity"), aws.String("value"), aws.String("ts")),
+				athenaRow(aws.String("a"), aws.String("1"), aws.String("2023-01-01 00:00:00.000")),
+			},
+			{
+				athenaRow(aws.String("b"), nil, aws.String("2023-01-02 00:00:00.500")),
+			},
+		},
+	}
+	db := sql.OpenDB(fakeAthenaConnector{&athenaConn{client: client, config: pc.AthenaConfig{Database: "features"}}})
+	rows, err := db.Query("SELECT entity, value, ts FROM t WHERE entity<>?", "c")
+	if err != nil {
+		t.Fatalf("Failed to query: %s", err)
+	}
+	defer rows.Close()
+	types, err := rows.ColumnTypes()
+	if err != nil {
+		t.Fatalf("Failed to get column types: %s", err)
+	}
+	if (athenaSQLQueries{}).getValueColumnType(types[1]) != atBigInt {
+		t.Fatalf("Expected bigint value column, got %s", types[1].DatabaseTypeName())
+	}
+	type record struct {
+		entity string
+		value  interface{}
+		ts     time.Time
+	}
+	actual := make([]record, 0)
+	for rows.Next() {
+		var rec record
+		if err := rows.Scan(&rec.entity, &rec.value, &rec.ts); err != nil {
+			t.Fatalf("Failed to scan: %s", err)
+		}
+		actual = append(actual, rec)
+	}
+	if err := rows.Err(); err != nil {
+		t.Fatalf("Failed to iterate: %s", err)
+	}
+	expected := []record{
+		{"a", int64(1), time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)},
+		{"b", nil, time.Date(2023, 1, 2, 0, 0, 0, 500000000, time.UTC)},
+	}
+	if len(actual) != len(expected) {
+		t.Fatalf("Expected %v, got %v", expected, actual)
+	}
+	for i := range expected {
+		if actual[i].entity != expected[i].entity || actual[i].value != expected[i].value || !actual[i].ts.Equal(expected[i].ts) {
+			t.Fatalf("Expected %v, got %v", expected, actual)
+		}
+	}
+	if client.queries[0] != "SELECT entity, value, ts FROM t WHERE entity<>'c'" {
+		t.Fatalf("Unexpected query %s", client.queries[0])
+	}
+
+	client.fail = true
+	if _, err := db.Exec("DROP TABLE `t`"); err == nil || !strings.Contains(err.Error(), "TABLE_NOT_FOUND") {
+		t.Fatalf("Expected the failure reason, got %v", err)
+	}
+}
+
+func TestAthenaSQLQueries(t *testing.T) {
+	q := athenaSQLQueries{Database: "features", TablesLocation: "s3://bucket/featureform/tables"}
+	table := q.newSQLOfflineTable("featureform_resource_feature__Amount__default", "TIMESTAMPTZ")
+	if !strings.HasPrefix(table, "CREATE TABLE `featureform_resource_feature__Amount__default` (entity string, value timestamp, ts timestamp) LOCATION 's3://bucket/featureform/tables/featureform_resource_feature__amount__default/") {
+		t.Fatalf("Unexpected resource table query: %s", table)
+	}
+	if q.tableLocation("t") == q.tableLocation("t") {
+		t.Fatalf("Expected recreated tables to get new locations")
+	}
+	if _, err := athenaOfflineStoreFactory((&pc.AthenaConfig{Region: "us-east-1"}).Serialize()); err == nil {
+		t.Fatalf("Expected an output location to be required")
+	}
+}
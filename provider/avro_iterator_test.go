@@ -0,0 +1,58 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package provider
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+
+	"github.com/linkedin/goavro/v2"
+)
+
+func TestAvroIterator(t *testing.T) {
+	var buf bytes.Buffer
+	writer, err := goavro.NewOCFWriter(goavro.OCFConfig{
+		W: &buf,
+		Schema: `{"type": "record", "name": "transaction", "fields": [
+			{"name": "entity", "type": "string"},
+			{"name": "amount", "type": ["null", "double"]},
+			{"name": "count", "type": "long"}
+		]}`,
+	})
+	if err != nil {
+		t.Fatalf("Failed to create writer: %s", err)
+	}
+	records := []interface{}{
+		map[string]interface{}{"entity": "a", "amount": goavro.Union("double", 12.5), "count": int64(1)},
+		map[string]interface{}{"entity": "b", "amount": goavro.Union("null", nil), "count": int64(2)},
+		map[string]interface{}{"entity": "c", "amount": goavro.Union("double", 3.0), "count": int64(3)},
+	}
+	if err := writer.Append(records); err != nil {
+		t.Fatalf("Failed to write records: %s", err)
+	}
+
+	iter, err := newAvroIterator(buf.Bytes(), 2)
+	if err != nil {
+		t.Fatalf("Failed to create iterator: %s", err)
+	}
+	if columns := iter.Columns(); !reflect.DeepEqual(columns, []string{"entity", "amount", "count"}) {
+		t.Fatalf("Unexpected columns: %v", columns)
+	}
+	var values []GenericRecord
+	for iter.Next() {
+		values = append(values, iter.Values())
+	}
+	if err := iter.Err(); err != nil {
+		t.Fatalf("Failed to iterate: %s", err)
+	}
+	expected := []GenericRecord{
+		{"a", 12.5, int64(1)},
+		{"b", nil, int64(2)},
+	}
+	if !reflect.DeepEqual(values, expected) {
+		t.Fatalf("Expected %v, got %v", expected, values)
+	}
+}
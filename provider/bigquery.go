@@ -2,7 +2,6 @@ package provider
 
 import (
 	"context"
-	"encoding/json"
 	"errors"
 	"fmt"
 	"strings"
@@ -55,6 +54,8 @@ type BQOfflineTableQueries interface {
 	setTablePrefix(prefix string)
 	getContext() context.Context
 	setContext()
+	setJobLabels(labels map[string]string)
+	newQuery(client *bigquery.Client, query string) *bigquery.Query
 	castTableItemType(v interface{}, t interface{}) interface{}
 	materializationExists(tableName string) string
 	materializationDrop(tableName string) string
@@ -75,6 +76,8 @@ type BQOfflineTableQueries interface {
 type defaultBQQueries struct {
 	TablePrefix string
 	Ctx         context.Context
+	// JobLabels are applied to every query job.
+	JobLabels map[string]string
 }
 
 type bqGenericTableIterator struct {
@@ -103,7 +106,7 @@ func (pt *bqPrimaryTable) IterateSegment(n int64) (GenericTableIterator, error)
 	} else {
 		query = fmt.Sprintf("SELECT * FROM `%s` LIMIT %d", tableName, n)
 	}
-	bqQ := pt.client.Query(query)
+	bqQ := pt.query.newQuery(pt.client, query)
 	it, err := bqQ.Read(pt.query.getContext())
 	if err != nil {
 		return nil, err
@@ -116,7 +119,7 @@ func (pt *bqPrimaryTable) NumRows() (int64, error) {
 	tableName := pt.query.getTableName(pt.name)
 	query := fmt.Sprintf("SELECT COUNT(*) FROM `%s`", tableName)
 
-	bqQ := pt.client.Query(query)
+	bqQ := pt.query.newQuery(pt.client, query)
 
 	it, err := bqQ.Read(pt.query.getContext())
 	if err != nil {
@@ -138,7 +141,7 @@ func (pt *bqPrimaryTable) Write(rec GenericRecord) error {
 	placeholder := pt.query.createValuePlaceholderString(columns)
 	upsertQuery := pt.query.upsertQuery(tb, columnsString, placeholder)
 
-	bqQ := pt.client.Query(upsertQuery)
+	bqQ := pt.query.newQuery(pt.client, upsertQuery)
 	bqQ.Parameters = recordsParameter
 
 	_, err := bqQ.Read(pt.query.getContext())
@@ -220,14 +223,19 @@ func newBigQueryGenericTableIterator(it *bigquery.RowIterator, query BQOfflineTa
 func (q defaultBQQueries) registerResources(client *bigquery.Client, tableName string, schema ResourceSchema, timestamp bool) error {
 	var query string
 	if timestamp {
-		query = fmt.Sprintf("CREATE VIEW `%s` AS SELECT `%s` as entity, `%s` as value, `%s` as ts, CURRENT_TIMESTAMP() as insert_ts FROM `%s`", q.getTableName(tableName),
-			schema.Entity, schema.Value, schema.TS, q.getTableName(schema.SourceTable))
+		ts := fmt.Sprintf("`%s`", schema.TS)
+		if schema.TSTimezone != "" {
+			// TIMESTAMP reads a DATETIME as a time in the zone.
+			ts = fmt.Sprintf("TIMESTAMP(%s, '%s')", ts, schema.TSTimezone)
+		}
+		query = fmt.Sprintf("CREATE VIEW `%s` AS SELECT `%s` as entity, `%s` as value, %s as ts, CURRENT_TIMESTAMP() as insert_ts FROM `%s`", q.getTableName(tableName),
+			schema.Entity, schema.Value, ts, q.getTableName(schema.SourceTable))
 	} else {
 		query = fmt.Sprintf("CREATE VIEW `%s` AS SELECT `%s` as entity, `%s` as value, PARSE_TIMESTAMP('%%Y-%%m-%%d %%H:%%M:%%S +0000 UTC', '%s') as ts, CURRENT_TIMESTAMP() as insert_ts FROM `%s`", q.getTableName(tableName),
 			schema.Entity, schema.Value, time.UnixMilli(0).UTC(), q.getTableName(schema.SourceTable))
 	}
 
-	bqQ := client.Query(query)
+	bqQ := q.newQuery(client, query)
 	_, err := bqQ.Read(q.getContext())
 	return err
 }
@@ -326,6 +334,19 @@ func (q *defaultBQQueries) getContext() context.Context {
 	return q.Ctx
 }
 
+func (q *defaultBQQueries) setJobLabels(labels map[string]string) {
+	q.JobLabels = labels
+}
+
+// newQuery creates a query job with the store's job labels.
+func (q defaultBQQueries) newQuery(client *bigquery.Client, query string) *bigquery.Query {
+	bqQ := client.Query(query)
+	if len(q.JobLabels) > 0 {
+		bqQ.Labels = q.JobLabels
+	}
+	return bqQ
+}
+
 func (q defaultBQQueries) castTableItemType(v interface{}, t interface{}) interface{} {
 	if v == nil {
 		return v
@@ -376,7 +397,7 @@ func (q defaultBQQueries) materializationUpdate(client *bigquery.Client, tableNa
 
 	query := fmt.Sprintf("%s %s %s", materializationCreateQuery, alterTables, dropTable)
 
-	bqQ := client.Query(query)
+	bqQ := q.newQuery(client, query)
 	job, err := bqQ.Run(q.getContext())
 	if err != nil {
 		return err
@@ -413,7 +434,7 @@ func (q defaultBQQueries) transformationCreate(name string, query string) string
 func (q defaultBQQueries) getColumns(client *bigquery.Client, name string) ([]TableColumn, error) {
 	qry := fmt.Sprintf("SELECT column_name FROM `%s.INFORMATION_SCHEMA.COLUMNS` WHERE table_name=\"%s\" ORDER BY ordinal_position", q.getTablePrefix(), name)
 
-	bqQ := client.Query(qry)
+	bqQ := q.newQuery(client, qry)
 	it, err := bqQ.Read(q.getContext())
 	if err != nil {
 		return nil, err
@@ -455,7 +476,7 @@ func (q defaultBQQueries) atomicUpdate(client *bigquery.Client, tableName string
 			"DROP TABLE `%s`;"+
 			"", query, bqTableName, bqTableName, bqTempTableName, bqTempTableName)
 
-	bdQ := client.Query(updateQuery)
+	bdQ := q.newQuery(client, updateQuery)
 	job, err := bdQ.Run(q.getContext())
 	if err != nil {
 		return err
@@ -502,7 +523,7 @@ func (q defaultBQQueries) trainingSetQuery(store *bqOfflineStore, def TrainingSe
 				"SELECT t0.entity AS e, t0.value AS label, t0.ts AS time, %s, %s FROM `%s` AS t0 %s )",
 			q.getTableName(tableName), columnStr, selectColumnStr, columnStr, selectColumnStr, q.getTableName(labelName), query)
 
-		bqQ := store.client.Query(fullQuery)
+		bqQ := store.query.newQuery(store.client, fullQuery)
 		job, err := bqQ.Run(store.query.getContext())
 		if err != nil {
 			return err
@@ -527,7 +548,25 @@ func (q defaultBQQueries) trainingRowSelect(columns string, trainingSetName stri
 }
 
 func (q defaultBQQueries) primaryTableRegister(tableName string, sourceName string) string {
-	return fmt.Sprintf("CREATE VIEW `%s` AS SELECT * FROM `%s`", q.getTableName(tableName), q.getTableName(sourceName))
+	return fmt.Sprintf("CREATE VIEW `%s` AS SELECT * FROM `%s`", q.getTableName(tableName), q.sourceTableName(sourceName))
+}
+
+// sourceTableName is the full name of a table that a primary source is
+// registered from. Tables in the provider's dataset are referenced by name,
+// those in another dataset by dataset.table and those in another project by
+// project.dataset.table.
+func (q defaultBQQueries) sourceTableName(sourceName string) string {
+	parts := strings.Split(strings.ReplaceAll(sourceName, "`", ""), ".")
+	switch len(parts) {
+	case 1:
+		return q.getTableName(sourceName)
+	case 2:
+		prefix := q.getTablePrefix()
+		project := prefix[:strings.LastIndex(prefix, ".")+1]
+		return project + strings.Join(parts, ".")
+	default:
+		return strings.Join(parts, ".")
+	}
 }
 
 func (q defaultBQQueries) getTableName(tableName string) string {
@@ -549,7 +588,7 @@ func (mat *bqMaterialization) NumRows() (int64, error) {
 	var n []bigquery.Value
 	query := mat.query.getNumRowsQuery(mat.tableName)
 
-	bqQ := mat.client.Query(query)
+	bqQ := mat.query.newQuery(mat.client, query)
 	it, err := bqQ.Read(mat.query.getContext())
 	if err != nil {
 		return 0, err
@@ -569,7 +608,7 @@ func (mat *bqMaterialization) NumRows() (int64, error) {
 func (mat *bqMaterialization) IterateSegment(start, end int64) (FeatureIterator, error) {
 	query := mat.query.materializationIterateSegment(mat.tableName, start, end)
 
-	bqQ := mat.client.Query(query)
+	bqQ := mat.query.newQuery(mat.client, query)
 	it, err := bqQ.Read(mat.query.getContext())
 	if err != nil {
 		return nil, err
@@ -642,7 +681,7 @@ func (table *bqOfflineTable) Write(rec ResourceRecord) error {
 	var n []bigquery.Value
 	existsQuery := table.query.writeExists(tb)
 
-	bqQ := table.client.Query(existsQuery)
+	bqQ := table.query.newQuery(table.client, existsQuery)
 	bqQ.Parameters = []bigquery.QueryParameter{
 		{
 			Value: rec.Entity,
@@ -676,7 +715,7 @@ func (table *bqOfflineTable) Write(rec ResourceRecord) error {
 		params = []bigquery.QueryParameter{bigquery.QueryParameter{Value: rec.Value}, bigquery.QueryParameter{Value: rec.Entity}, bigquery.QueryParameter{Value: rec.TS}}
 	}
 
-	bqQ = table.client.Query(writeQuery)
+	bqQ = table.query.newQuery(table.client, writeQuery)
 	bqQ.Parameters = params
 
 	_, err = bqQ.Read(table.query.getContext())
@@ -697,15 +736,19 @@ func NewBQOfflineStore(config BQOfflineStoreConfig) (*bqOfflineStore, error) {
 		return nil, errors.New("invalid bigquery config")
 	}
 
-	creds, err := json.Marshal(sc.Credentials)
+	creds, err := googleCredentials(context.TODO(), sc.Credentials)
 	if err != nil {
-		return nil, fmt.Errorf("could not serialize bigquery credentials")
+		return nil, err
 	}
-	client, err := bigquery.NewClient(context.TODO(), config.ProjectId, option.WithCredentialsJSON(creds))
+	client, err := bigquery.NewClient(context.TODO(), sc.JobProject(), option.WithCredentials(creds))
 	if err != nil {
 		return nil, err
 	}
 	defer client.Close()
+	client.Location = sc.Location
+	if err := labelBigQueryDataset(context.TODO(), client, sc.ProjectId, sc.DatasetId, sc.Labels); err != nil {
+		return nil, fmt.Errorf("could not label dataset: %w", err)
+	}
 
 	return &bqOfflineStore{
 		client: client,
@@ -718,6 +761,32 @@ func NewBQOfflineStore(config BQOfflineStoreConfig) (*bqOfflineStore, error) {
 	}, nil
 }
 
+// labelBigQueryDataset adds the labels to the dataset, overwriting existing
+// labels with the same keys. The dataset is only updated if a label changed.
+func labelBigQueryDataset(ctx context.Context, client *bigquery.Client, projectID, datasetID string, labels map[string]string) error {
+	if len(labels) == 0 {
+		return nil
+	}
+	dataset := client.DatasetInProject(projectID, datasetID)
+	metadata, err := dataset.Metadata(ctx)
+	if err != nil {
+		return err
+	}
+	var update bigquery.DatasetMetadataToUpdate
+	changed := false
+	for key, value := range labels {
+		if current, has := metadata.Labels[key]; !has || current != value {
+			update.SetLabel(key, value)
+			changed = true
+		}
+	}
+	if !changed {
+		return nil
+	}
+	_, err = dataset.Update(ctx, update, metadata.ETag)
+	return err
+}
+
 func bigQueryOfflineStoreFactory(config pc.SerializedConfig) (Provider, error) {
 	sc := pc.BigQueryConfig{}
 	if err := sc.Deserialize(config); err != nil {
@@ -726,6 +795,7 @@ func bigQueryOfflineStoreFactory(config pc.SerializedConfig) (Provider, error) {
 	queries := defaultBQQueries{}
 	queries.setTablePrefix(fmt.Sprintf("%s.%s", sc.ProjectId, sc.DatasetId))
 	queries.setContext()
+	queries.setJobLabels(sc.JobLabels)
 	sgConfig := BQOfflineStoreConfig{
 		Config:       config,
 		ProjectId:    sc.ProjectId,
@@ -752,6 +822,9 @@ func (store *bqOfflineStore) RegisterResourceFromSourceTable(id ResourceID, sche
 	if schema.Entity == "" || schema.Value == "" {
 		return nil, fmt.Errorf("non-empty entity and value columns required")
 	}
+	if _, err := schema.tsLocation(); err != nil {
+		return nil, err
+	}
 	tableName, err := store.getResourceTableName(id)
 	if err != nil {
 		return nil, fmt.Errorf("get name: %w", err)
@@ -789,7 +862,7 @@ func (store *bqOfflineStore) RegisterPrimaryFromSourceTable(id ResourceID, sourc
 	}
 	query := store.query.primaryTableRegister(tableName, sourceName)
 
-	bqQ := store.client.Query(query)
+	bqQ := store.query.newQuery(store.client, query)
 	job, err := bqQ.Run(store.query.getContext())
 	if err != nil {
 		return nil, err
@@ -816,7 +889,7 @@ func (store *bqOfflineStore) CreateTransformation(config TransformationConfig) e
 	}
 	query := store.query.transformationCreate(name, config.Query)
 
-	bqQ := store.client.Query(query)
+	bqQ := store.query.newQuery(store.client, query)
 	job, err := bqQ.Run(store.query.getContext())
 	if err != nil {
 		return err
@@ -850,7 +923,7 @@ func (store *bqOfflineStore) GetTransformationTable(id ResourceID) (Transformati
 	}
 
 	existsQuery := store.query.tableExists(name)
-	bqQ := store.client.Query(existsQuery)
+	bqQ := store.query.newQuery(store.client, existsQuery)
 	it, err := bqQ.Read(store.query.getContext())
 	if err != nil {
 		return nil, err
@@ -981,7 +1054,7 @@ func (store *bqOfflineStore) newbqOfflineTable(client *bigquery.Client, name str
 		return nil, err
 	}
 	tableCreateQry := store.query.newBQOfflineTable(name, columnType)
-	bqQ := client.Query(tableCreateQry)
+	bqQ := store.query.newQuery(client, tableCreateQry)
 	_, err = bqQ.Read(store.query.getContext())
 	if err != nil {
 		return nil, err
@@ -1010,7 +1083,7 @@ func (store *bqOfflineStore) CreateMaterialization(id ResourceID) (Materializati
 	matTableName := store.getMaterializationTableName(matID)
 	materializeQry := store.query.materializationCreate(matTableName, resTable.name)
 
-	bqQ := store.client.Query(materializeQry)
+	bqQ := store.query.newQuery(store.client, materializeQry)
 	_, err = bqQ.Read(store.query.getContext())
 	if err != nil {
 		return nil, fmt.Errorf("ready query result: %v", err)
@@ -1049,7 +1122,7 @@ func (store *bqOfflineStore) GetMaterialization(id MaterializationID) (Materiali
 	tableName := store.getMaterializationTableName(id)
 	getMatQry := store.query.materializationExists(tableName)
 
-	bqQry := store.client.Query(getMatQry)
+	bqQry := store.query.newQuery(store.client, getMatQry)
 	it, err := bqQry.Read(store.query.getContext())
 	if err != nil {
 		return nil, fmt.Errorf("could not get materialization: %w", err)
@@ -1072,6 +1145,10 @@ func (store *bqOfflineStore) GetMaterialization(id MaterializationID) (Materiali
 	}, err
 }
 
+func (store *bqOfflineStore) GetFeatureMaterialization(id ResourceID) (Materialization, error) {
+	return store.GetMaterialization(MaterializationID(id.Name))
+}
+
 func (store *bqOfflineStore) UpdateMaterialization(id ResourceID) (Materialization, error) {
 	matID := MaterializationID(id.Name)
 	tableName := store.getMaterializationTableName(matID)
@@ -1081,7 +1158,7 @@ func (store *bqOfflineStore) UpdateMaterialization(id ResourceID) (Materializati
 		return nil, err
 	}
 
-	bqQ := store.client.Query(getMatQry)
+	bqQ := store.query.newQuery(store.client, getMatQry)
 	it, err := bqQ.Read(store.query.getContext())
 	var row []bigquery.Value
 	err = it.Next(&row)
@@ -1113,7 +1190,7 @@ func (store *bqOfflineStore) DeleteMaterialization(id MaterializationID) error {
 		return &MaterializationNotFound{id}
 	}
 	query := store.query.materializationDrop(tableName)
-	bqQ := store.client.Query(query)
+	bqQ := store.query.newQuery(store.client, query)
 	_, err := bqQ.Read(store.query.getContext())
 
 	return err
@@ -1123,7 +1200,7 @@ func (store *bqOfflineStore) materializationExists(id MaterializationID) (bool,
 	tableName := store.getMaterializationTableName(id)
 	getMatQry := store.query.materializationExists(tableName)
 
-	bqQ := store.client.Query(getMatQry)
+	bqQ := store.query.newQuery(store.client, getMatQry)
 	it, err := bqQ.Read(store.query.getContext())
 	if err != nil {
 		return false, err
@@ -1200,7 +1277,7 @@ func (store *bqOfflineStore) GetTrainingSet(id ResourceID) (TrainingSetIterator,
 	trainingSetQry := store.query.trainingRowSelect(columns, trainingSetName)
 
 	fmt.Printf("Training Set Query: %s\n", trainingSetQry)
-	bqQ := store.client.Query(trainingSetQry)
+	bqQ := store.query.newQuery(store.client, trainingSetQry)
 	iter, err := bqQ.Read(store.query.getContext())
 	if err != nil {
 		return nil, err
@@ -1296,7 +1373,7 @@ func (store *bqOfflineStore) tableExists(id ResourceID) (bool, error) {
 	}
 
 	query := store.query.tableExists(tableName)
-	bqQ := store.client.Query(query)
+	bqQ := store.query.newQuery(store.client, query)
 
 	iter, err := bqQ.Read(store.query.getContext())
 	if err != nil {
@@ -1311,7 +1388,7 @@ func (store *bqOfflineStore) tableExists(id ResourceID) (bool, error) {
 	}
 
 	query = store.query.viewExists(tableName)
-	bqQ = store.client.Query(query)
+	bqQ = store.query.newQuery(store.client, query)
 
 	iter, err = bqQ.Read(store.query.getContext())
 	if err != nil {
@@ -1333,7 +1410,7 @@ func (store *bqOfflineStore) newBigQueryPrimaryTable(client *bigquery.Client, na
 		return nil, err
 	}
 
-	qry := client.Query(query)
+	qry := store.query.newQuery(client, query)
 	_, err = qry.Read(store.query.getContext())
 	if err != nil {
 		return nil, err
@@ -0,0 +1,46 @@
+package provider
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"cloud.google.com/go/bigquery"
+	"google.golang.org/api/option"
+)
+
+func TestBigQueryJobLabels(t *testing.T) {
+	client, err := bigquery.NewClient(context.Background(), "ff-gcp-proj-id", option.WithoutAuthentication())
+	if err != nil {
+		t.Fatalf("Failed to create client: %s", err)
+	}
+	defer client.Close()
+	queries := defaultBQQueries{}
+	if labels := queries.newQuery(client, "SELECT 1").Labels; len(labels) != 0 {
+		t.Fatalf("Expected no job labels, got %v", labels)
+	}
+	labels := map[string]string{"team": "fraud"}
+	queries.setJobLabels(labels)
+	if actual := queries.newQuery(client, "SELECT 1").Labels; !reflect.DeepEqual(actual, labels) {
+		t.Fatalf("Expected job labels %v, got %v", labels, actual)
+	}
+}
+
+func TestBigQuerySourceTableName(t *testing.T) {
+	q := defaultBQQueries{TablePrefix: "project.dataset"}
+	tests := map[string]string{
+		"table":                                   "project.dataset.table",
+		"other_dataset.table":                     "project.other_dataset.table",
+		"other-project.other_dataset.table":       "other-project.other_dataset.table",
+		"`other-project`.`other_dataset`.`table`": "other-project.other_dataset.table",
+	}
+	for source, expected := range tests {
+		if name := q.sourceTableName(source); name != expected {
+			t.Errorf("Expected %s for %s, got %s", expected, source, name)
+		}
+	}
+	expected := "CREATE VIEW `project.dataset.featureform_primary__name__variant` AS SELECT * FROM `other-project.other_dataset.table`"
+	if query := q.primaryTableRegister("featureform_primary__name__variant", "other-project.other_dataset.table"); query != expected {
+		t.Fatalf("Expected %s, got %s", expected, query)
+	}
+}
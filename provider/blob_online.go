@@ -14,6 +14,10 @@ const STORE_PREFIX = ".featureform/inferencestore"
 type OnlineFileStore struct {
 	FileStore
 	Prefix string
+	// Format and Shards are the layout of new tables. Existing tables keep
+	// the layout they were created with.
+	Format string
+	Shards int
 	BaseProvider
 }
 
@@ -31,14 +35,28 @@ func NewOnlineFileStore(config *pc.OnlineBlobConfig) (*OnlineFileStore, error) {
 		return nil, fmt.Errorf("could not serialize blob store config")
 	}
 
+	shards := config.Shards
+	switch config.Format {
+	case BlobPerEntityFormat:
+	case BlobShardedFormat:
+		if shards == 0 {
+			shards = defaultBlobShards
+		} else if shards < 0 {
+			return nil, fmt.Errorf("number of shards must be positive: %d", shards)
+		}
+	default:
+		return nil, fmt.Errorf("unknown blob online store format: %s", config.Format)
+	}
 	FileStore, err := CreateFileStore(string(config.Type), Config(serializedBlob))
 	if err != nil {
 		return nil, fmt.Errorf("could not create blob store: %v", err)
 	}
 	return &OnlineFileStore{
-		FileStore,
-		config.Config.Path,
-		BaseProvider{
+		FileStore: FileStore,
+		Prefix:    config.Config.Path,
+		Format:    config.Format,
+		Shards:    shards,
+		BaseProvider: BaseProvider{
 			ProviderType:   pt.BlobOnline,
 			ProviderConfig: config.Serialized(),
 		},
@@ -64,12 +82,16 @@ func (store OnlineFileStore) readTableValue(feature, variant string) (ValueType,
 	if err != nil {
 		return NilType, err
 	}
-	return ScalarType(string(value)), nil
+	return deserializeValueType(string(value))
 }
 
 func (store OnlineFileStore) writeTableValue(feature, variant string, valueType ValueType) error {
 	tableKey := blobTableKey(store.Prefix, feature, variant)
-	return store.Write(tableKey, []byte(valueType.Scalar()))
+	serializedType, err := serializeValueType(valueType)
+	if err != nil {
+		return err
+	}
+	return store.Write(tableKey, []byte(serializedType))
 }
 
 func (store OnlineFileStore) deleteTable(feature, variant string) error {
@@ -81,7 +103,7 @@ func (store OnlineFileStore) deleteTable(feature, variant string) error {
 	if err := store.DeleteAll(entityDirectory); err != nil {
 		return fmt.Errorf("could not delete entity directory %s: %v", entityDirectory, err)
 	}
-	return nil
+	return store.deleteShards(feature, variant)
 }
 
 func (store OnlineFileStore) GetTable(feature, variant string) (OnlineStoreTable, error) {
@@ -96,7 +118,15 @@ func (store OnlineFileStore) GetTable(feature, variant string) (OnlineStoreTable
 	if err != nil {
 		return nil, err
 	}
-	return OnlineFileStoreTable{store, feature, variant, store.Prefix, tableType}, nil
+	table := OnlineFileStoreTable{store, feature, variant, store.Prefix, tableType}
+	layout, err := store.readLayout(feature, variant)
+	if err != nil {
+		return nil, err
+	}
+	if layout.Format == BlobShardedFormat {
+		return &shardedFileStoreTable{table, layout, store}, nil
+	}
+	return table, nil
 }
 
 func (store OnlineFileStore) CreateTable(feature, variant string, valueType ValueType) (OnlineStoreTable, error) {
@@ -110,7 +140,15 @@ func (store OnlineFileStore) CreateTable(feature, variant string, valueType Valu
 	if err := store.writeTableValue(feature, variant, valueType); err != nil {
 		return nil, err
 	}
-	return OnlineFileStoreTable{store, feature, variant, store.Prefix, valueType}, nil
+	table := OnlineFileStoreTable{store, feature, variant, store.Prefix, valueType}
+	if store.Format == BlobShardedFormat {
+		layout := blobTableLayout{Format: BlobShardedFormat, Shards: store.Shards}
+		if err := store.writeLayout(feature, variant, layout); err != nil {
+			return nil, err
+		}
+		return &shardedFileStoreTable{table, layout, store}, nil
+	}
+	return table, nil
 }
 
 type OnlineFileStoreTable struct {
@@ -132,6 +170,12 @@ func (store OnlineFileStore) DeleteTable(feature, variant string) error {
 	return store.deleteTable(feature, variant)
 }
 
+func (store OnlineFileStore) Capabilities() OnlineCapabilities {
+	return OnlineCapabilities{
+		BulkLoad: store.CanBulkLoad(),
+	}
+}
+
 func entityDirectory(prefix, feature, variant string) string {
 	return fmt.Sprintf("%s/%s/values/%s/%s", prefix, STORE_PREFIX, feature, variant)
 }
@@ -142,10 +186,20 @@ func entityValueKey(prefix, feature, variant, entity string) string {
 
 func (table OnlineFileStoreTable) setEntityValue(feature, variant, entity string, value interface{}) error {
 	entityValueKey := entityValueKey(table.prefix, feature, variant, entity)
-	valueBytes := []byte(fmt.Sprintf("%v", value.(interface{})))
+	valueBytes, err := serializeBlobValue(value, table.valueType)
+	if err != nil {
+		return err
+	}
 	return table.store.Write(entityValueKey, valueBytes)
 }
 
+func serializeBlobValue(value interface{}, valueType ValueType) ([]byte, error) {
+	if composite, isComposite := valueType.(compositeType); isComposite {
+		return serializeComposite(composite, value)
+	}
+	return []byte(fmt.Sprintf("%v", value)), nil
+}
+
 func (table OnlineFileStoreTable) getEntityValue(feature, variant, entity string) (interface{}, error) {
 	entityValueKey := entityValueKey(table.prefix, feature, variant, entity)
 	exists, err := table.store.Exists(entityValueKey)
@@ -175,6 +229,9 @@ func (table OnlineFileStoreTable) Get(entity string) (interface{}, error) {
 }
 
 func castBytesToValue(value []byte, valueType ValueType) (interface{}, error) {
+	if composite, isComposite := valueType.(compositeType); isComposite {
+		return deserializeComposite(composite, value)
+	}
 	valueString := string(value)
 	var val interface{}
 	var err error
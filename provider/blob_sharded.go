@@ -0,0 +1,265 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package provider
+
+import (
+	"encoding/json"
+	"fmt"
+	"hash/fnv"
+	"sort"
+	"sync"
+
+	"github.com/google/uuid"
+)
+
+const (
+	// BlobPerEntityFormat stores each entity's value in its own blob.
+	BlobPerEntityFormat = ""
+	// BlobShardedFormat stores materializations as shard files sorted by
+	// entity, each with a sidecar index of where every entity's value is.
+	BlobShardedFormat = "sharded"
+
+	defaultBlobShards = 64
+	// Shard indexes are immutable once written, so they're cached by key.
+	maxCachedShardIndexes = 1024
+)
+
+// blobTableLayout is stored alongside a table's value type. Tables created
+// before layouts were recorded have none and use BlobPerEntityFormat.
+type blobTableLayout struct {
+	Format string
+	Shards int
+	// Generation is the directory of the shard files of the last bulk load,
+	// or empty if the table hasn't been bulk loaded.
+	Generation string
+}
+
+type shardIndexEntry struct {
+	Entity string `json:"e"`
+	Offset int64  `json:"o"`
+	Length int64  `json:"l"`
+}
+
+type blobRangeReader interface {
+	ReadRange(key string, offset, length int64) ([]byte, error)
+}
+
+var shardIndexCache = struct {
+	indexes map[string][]shardIndexEntry
+	mu      sync.Mutex
+}{indexes: make(map[string][]shardIndexEntry)}
+
+func blobLayoutKey(prefix, feature, variant string) string {
+	return blobTableKey(prefix, feature, variant) + ".layout"
+}
+
+func blobShardDirectory(prefix, feature, variant string) string {
+	return fmt.Sprintf("%s/%s/shards/%s/%s", prefix, STORE_PREFIX, feature, variant)
+}
+
+func blobShardKeys(prefix, feature, variant, generation string, shard int) (string, string) {
+	base := fmt.Sprintf("%s/%s/%05d", blobShardDirectory(prefix, feature, variant), generation, shard)
+	return base + ".data", base + ".index"
+}
+
+func blobShard(entity string, shards int) int {
+	h := fnv.New32a()
+	h.Write([]byte(entity))
+	return int(h.Sum32() % uint32(shards))
+}
+
+func (store OnlineFileStore) readLayout(feature, variant string) (blobTableLayout, error) {
+	key := blobLayoutKey(store.Prefix, feature, variant)
+	exists, err := store.Exists(key)
+	if err != nil || !exists {
+		return blobTableLayout{}, err
+	}
+	data, err := store.Read(key)
+	if err != nil {
+		return blobTableLayout{}, err
+	}
+	var layout blobTableLayout
+	if err := json.Unmarshal(data, &layout); err != nil {
+		return blobTableLayout{}, fmt.Errorf("could not parse table layout %s: %w", key, err)
+	}
+	return layout, nil
+}
+
+func (store OnlineFileStore) writeLayout(feature, variant string, layout blobTableLayout) error {
+	data, err := json.Marshal(layout)
+	if err != nil {
+		return err
+	}
+	return store.Write(blobLayoutKey(store.Prefix, feature, variant), data)
+}
+
+func (store OnlineFileStore) deleteShards(feature, variant string) error {
+	layoutKey := blobLayoutKey(store.Prefix, feature, variant)
+	exists, err := store.Exists(layoutKey)
+	if err != nil || !exists {
+		return err
+	}
+	if err := store.DeleteAll(blobShardDirectory(store.Prefix, feature, variant) + "/"); err != nil {
+		return fmt.Errorf("could not delete shard directory: %v", err)
+	}
+	return store.Delete(layoutKey)
+}
+
+// readRange reads part of a blob, reading the whole blob if the file store
+// doesn't support range reads.
+func (store OnlineFileStore) readRange(key string, offset, length int64) ([]byte, error) {
+	if reader, ok := store.FileStore.(blobRangeReader); ok {
+		return reader.ReadRange(key, offset, length)
+	}
+	data, err := store.Read(key)
+	if err != nil {
+		return nil, err
+	}
+	if offset+length > int64(len(data)) {
+		return nil, fmt.Errorf("range %d+%d is past the end of %s", offset, length, key)
+	}
+	return data[offset : offset+length], nil
+}
+
+func (store OnlineFileStore) readShardIndex(key string) ([]shardIndexEntry, error) {
+	shardIndexCache.mu.Lock()
+	index, has := shardIndexCache.indexes[key]
+	shardIndexCache.mu.Unlock()
+	if has {
+		return index, nil
+	}
+	data, err := store.Read(key)
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, &index); err != nil {
+		return nil, fmt.Errorf("could not parse shard index %s: %w", key, err)
+	}
+	shardIndexCache.mu.Lock()
+	defer shardIndexCache.mu.Unlock()
+	if len(shardIndexCache.indexes) >= maxCachedShardIndexes {
+		for cached := range shardIndexCache.indexes {
+			delete(shardIndexCache.indexes, cached)
+			break
+		}
+	}
+	shardIndexCache.indexes[key] = index
+	return index, nil
+}
+
+func (store OnlineFileStore) CanBulkLoad() bool {
+	return store.Format == BlobShardedFormat
+}
+
+// BulkLoad writes the records as a new generation of shard files and then
+// switches the table to it. Values Set on the table before the switch are
+// removed, since the bulk loaded values replace them.
+//
+// Each shard is sorted in memory, so memory use is proportional to the
+// size of the largest shard.
+func (store OnlineFileStore) BulkLoad(feature, variant string, records FeatureIterator) error {
+	table, err := store.GetTable(feature, variant)
+	if err != nil {
+		return err
+	}
+	sharded, ok := table.(*shardedFileStoreTable)
+	if !ok {
+		return fmt.Errorf("table %s %s is not sharded", feature, variant)
+	}
+	layout := sharded.layout
+	shards := make([]map[string][]byte, layout.Shards)
+	for i := range shards {
+		shards[i] = make(map[string][]byte)
+	}
+	for records.Next() {
+		rec := records.Value()
+		value, err := serializeBlobValue(rec.Value, sharded.valueType)
+		if err != nil {
+			return err
+		}
+		shards[blobShard(rec.Entity, layout.Shards)][rec.Entity] = value
+	}
+	if err := records.Err(); err != nil {
+		return err
+	}
+	previous := layout.Generation
+	layout.Generation = uuid.NewString()
+	for i, values := range shards {
+		if err := store.writeShard(feature, variant, layout.Generation, i, values); err != nil {
+			return err
+		}
+	}
+	if err := store.writeLayout(feature, variant, layout); err != nil {
+		return err
+	}
+	if err := store.DeleteAll(entityDirectory(store.Prefix, feature, variant) + "/"); err != nil {
+		return fmt.Errorf("could not delete entity values replaced by bulk load: %v", err)
+	}
+	if previous != "" {
+		if err := store.DeleteAll(fmt.Sprintf("%s/%s/", blobShardDirectory(store.Prefix, feature, variant), previous)); err != nil {
+			return fmt.Errorf("could not delete previous shards: %v", err)
+		}
+	}
+	return nil
+}
+
+func (store OnlineFileStore) writeShard(feature, variant, generation string, shard int, values map[string][]byte) error {
+	entities := make([]string, 0, len(values))
+	for entity := range values {
+		entities = append(entities, entity)
+	}
+	sort.Strings(entities)
+	index := make([]shardIndexEntry, len(entities))
+	var data []byte
+	for i, entity := range entities {
+		index[i] = shardIndexEntry{Entity: entity, Offset: int64(len(data)), Length: int64(len(values[entity]))}
+		data = append(data, values[entity]...)
+	}
+	serializedIndex, err := json.Marshal(index)
+	if err != nil {
+		return err
+	}
+	dataKey, indexKey := blobShardKeys(store.Prefix, feature, variant, generation, shard)
+	if err := store.Write(dataKey, data); err != nil {
+		return fmt.Errorf("could not write shard %s: %v", dataKey, err)
+	}
+	if err := store.Write(indexKey, serializedIndex); err != nil {
+		return fmt.Errorf("could not write shard index %s: %v", indexKey, err)
+	}
+	return nil
+}
+
+// shardedFileStoreTable reads bulk loaded values from the table's shard
+// files. Values Set on the table are written per entity, like in
+// BlobPerEntityFormat, and take precedence until the next bulk load.
+type shardedFileStoreTable struct {
+	OnlineFileStoreTable
+	layout blobTableLayout
+	files  OnlineFileStore
+}
+
+func (table *shardedFileStoreTable) Get(entity string) (interface{}, error) {
+	value, err := table.OnlineFileStoreTable.Get(entity)
+	if _, notFound := err.(*EntityNotFound); !notFound {
+		return value, err
+	}
+	if table.layout.Generation == "" {
+		return nil, err
+	}
+	dataKey, indexKey := blobShardKeys(table.prefix, table.feature, table.variant, table.layout.Generation, blobShard(entity, table.layout.Shards))
+	index, indexErr := table.files.readShardIndex(indexKey)
+	if indexErr != nil {
+		return nil, indexErr
+	}
+	i := sort.Search(len(index), func(i int) bool { return index[i].Entity >= entity })
+	if i == len(index) || index[i].Entity != entity {
+		return nil, err
+	}
+	data, err := table.files.readRange(dataKey, index[i].Offset, index[i].Length)
+	if err != nil {
+		return nil, err
+	}
+	return castBytesToValue(data, table.valueType)
+}
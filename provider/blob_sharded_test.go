@@ -0,0 +1,112 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package provider
+
+import (
+	"fmt"
+	"testing"
+
+	pc "github.com/featureform/provider/provider_config"
+)
+
+func newShardedTestStore(t *testing.T) OnlineFileStore {
+	config := pc.LocalFileStoreConfig{DirPath: fmt.Sprintf("file:///%s", t.TempDir())}
+	serialized, err := config.Serialize()
+	if err != nil {
+		t.Fatalf("Failed to serialize file store config: %s", err)
+	}
+	files, err := NewLocalFileStore(serialized)
+	if err != nil {
+		t.Fatalf("Failed to create file store: %s", err)
+	}
+	return OnlineFileStore{FileStore: files, Prefix: "online", Format: BlobShardedFormat, Shards: 4}
+}
+
+func TestShardedBlobTable(t *testing.T) {
+	store := newShardedTestStore(t)
+	if !store.CanBulkLoad() {
+		t.Fatalf("Sharded store can't bulk load")
+	}
+	if _, err := store.CreateTable("feature", "variant", Int); err != nil {
+		t.Fatalf("Failed to create table: %s", err)
+	}
+	records := make([]ResourceRecord, 0, 100)
+	for i := 0; i < 100; i++ {
+		records = append(records, ResourceRecord{Entity: fmt.Sprintf("entity%d", i), Value: i})
+	}
+	// Later records for an entity replace earlier ones.
+	records = append(records, ResourceRecord{Entity: "entity0", Value: 1000})
+	if err := store.BulkLoad("feature", "variant", newMemoryFeatureIterator(records)); err != nil {
+		t.Fatalf("Failed to bulk load: %s", err)
+	}
+	table, err := store.GetTable("feature", "variant")
+	if err != nil {
+		t.Fatalf("Failed to get table: %s", err)
+	}
+	expected := map[string]int{"entity0": 1000, "entity1": 1, "entity57": 57, "entity99": 99}
+	for entity, value := range expected {
+		got, err := table.Get(entity)
+		if err != nil {
+			t.Fatalf("Failed to get %s: %s", entity, err)
+		}
+		if got != value {
+			t.Fatalf("Expected %s to be %d, got %v", entity, value, got)
+		}
+	}
+	if _, err := table.Get("missing"); err == nil {
+		t.Fatalf("Expected missing entity to fail")
+	} else if _, ok := err.(*EntityNotFound); !ok {
+		t.Fatalf("Expected EntityNotFound, got %T: %s", err, err)
+	}
+
+	// Values set after the bulk load take precedence until the next one.
+	if err := table.Set("entity1", 11); err != nil {
+		t.Fatalf("Failed to set value: %s", err)
+	}
+	if got, err := table.Get("entity1"); err != nil || got != 11 {
+		t.Fatalf("Expected set value 11, got %v: %v", got, err)
+	}
+	reload := []ResourceRecord{{Entity: "entity2", Value: 22}}
+	if err := store.BulkLoad("feature", "variant", newMemoryFeatureIterator(reload)); err != nil {
+		t.Fatalf("Failed to reload: %s", err)
+	}
+	table, err = store.GetTable("feature", "variant")
+	if err != nil {
+		t.Fatalf("Failed to get table: %s", err)
+	}
+	if got, err := table.Get("entity2"); err != nil || got != 22 {
+		t.Fatalf("Expected reloaded value 22, got %v: %v", got, err)
+	}
+	for _, entity := range []string{"entity1", "entity57"} {
+		if _, err := table.Get(entity); err == nil {
+			t.Fatalf("Expected %s to be replaced by the reload", entity)
+		}
+	}
+
+	if err := store.DeleteTable("feature", "variant"); err != nil {
+		t.Fatalf("Failed to delete table: %s", err)
+	}
+	if _, err := store.GetTable("feature", "variant"); err == nil {
+		t.Fatalf("Expected deleted table to be missing")
+	}
+}
+
+func TestPerEntityBlobTableCantBulkLoad(t *testing.T) {
+	store := newShardedTestStore(t)
+	store.Format = BlobPerEntityFormat
+	if store.CanBulkLoad() {
+		t.Fatalf("Per-entity store can bulk load")
+	}
+	table, err := store.CreateTable("feature", "variant", String)
+	if err != nil {
+		t.Fatalf("Failed to create table: %s", err)
+	}
+	if _, ok := table.(OnlineFileStoreTable); !ok {
+		t.Fatalf("Expected per-entity table, got %T", table)
+	}
+	if err := store.BulkLoad("feature", "variant", newMemoryFeatureIterator(nil)); err == nil {
+		t.Fatalf("Expected bulk load into per-entity table to fail")
+	}
+}
@@ -0,0 +1,139 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package provider
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+// BufferedTable wraps an online table and holds Set calls in memory until
+// they're flushed as a batch, either every interval or once maxEntries
+// entities are pending. Writes to the same entity between flushes are
+// coalesced so only the last value is written. Tables that implement
+// BatchSettableTable are written with SetBatch; others fall back to Set.
+//
+// Values aren't durable until flushed. Close must be called to flush the
+// remaining values and stop the background flush.
+type BufferedTable struct {
+	OnlineStoreTable
+	maxEntries int
+	pending    map[string]interface{}
+	// flushing is the batch being written by Flush, if any.
+	flushing map[string]interface{}
+	// flushErr is the error of the last background flush, returned by the
+	// next call to Set so that failures aren't lost.
+	flushErr error
+	mu       sync.Mutex
+	// flushMu serializes flushes so batches are written in order.
+	flushMu sync.Mutex
+	stop    chan struct{}
+	stopped sync.WaitGroup
+}
+
+func NewBufferedTable(table OnlineStoreTable, maxEntries int, interval time.Duration) (*BufferedTable, error) {
+	if maxEntries <= 0 {
+		return nil, fmt.Errorf("buffer size must be positive: %d", maxEntries)
+	}
+	if interval <= 0 {
+		return nil, fmt.Errorf("buffer flush interval must be positive: %s", interval)
+	}
+	buffered := &BufferedTable{
+		OnlineStoreTable: table,
+		maxEntries:       maxEntries,
+		pending:          make(map[string]interface{}),
+		stop:             make(chan struct{}),
+	}
+	buffered.stopped.Add(1)
+	go buffered.flushEvery(interval)
+	return buffered, nil
+}
+
+func (table *BufferedTable) flushEvery(interval time.Duration) {
+	defer table.stopped.Done()
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			if err := table.Flush(); err != nil {
+				table.mu.Lock()
+				table.flushErr = err
+				table.mu.Unlock()
+			}
+		case <-table.stop:
+			return
+		}
+	}
+}
+
+// Set buffers the value, flushing first if the buffer is full. Memory is
+// bounded by making writers wait on the flush.
+func (table *BufferedTable) Set(entity string, value interface{}) error {
+	table.mu.Lock()
+	if err := table.flushErr; err != nil {
+		table.flushErr = nil
+		table.mu.Unlock()
+		return fmt.Errorf("buffered flush failed: %w", err)
+	}
+	table.pending[entity] = value
+	full := len(table.pending) >= table.maxEntries
+	table.mu.Unlock()
+	if full {
+		return table.Flush()
+	}
+	return nil
+}
+
+// Get returns the entity's buffered value if it hasn't been written yet.
+func (table *BufferedTable) Get(entity string) (interface{}, error) {
+	table.mu.Lock()
+	value, has := table.pending[entity]
+	if !has {
+		value, has = table.flushing[entity]
+	}
+	table.mu.Unlock()
+	if has {
+		return value, nil
+	}
+	return table.OnlineStoreTable.Get(entity)
+}
+
+// Flush writes all buffered values. If the write fails, values that haven't
+// since been overwritten are buffered again to be retried on the next flush.
+func (table *BufferedTable) Flush() error {
+	table.flushMu.Lock()
+	defer table.flushMu.Unlock()
+	table.mu.Lock()
+	batch := table.pending
+	table.pending = make(map[string]interface{}, len(batch))
+	table.flushing = batch
+	table.mu.Unlock()
+	if len(batch) == 0 {
+		return nil
+	}
+	err := setBatch(table.OnlineStoreTable, batch)
+	table.mu.Lock()
+	defer table.mu.Unlock()
+	table.flushing = nil
+	if err != nil {
+		for entity, value := range batch {
+			if _, has := table.pending[entity]; !has {
+				table.pending[entity] = value
+			}
+		}
+	}
+	return err
+}
+
+// Close stops the background flush and flushes the remaining values. Values
+// from failed background flushes are still buffered, so a nil error means
+// every value has been written.
+func (table *BufferedTable) Close() error {
+	close(table.stop)
+	table.stopped.Wait()
+	return table.Flush()
+}
@@ -0,0 +1,153 @@
+package provider
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+)
+
+type batchRecordingTable struct {
+	*localOnlineTable
+	batches []map[string]interface{}
+	fail    bool
+	mu      sync.Mutex
+}
+
+func (table *batchRecordingTable) SetBatch(values map[string]interface{}) error {
+	table.mu.Lock()
+	defer table.mu.Unlock()
+	if table.fail {
+		return fmt.Errorf("batch failed")
+	}
+	table.batches = append(table.batches, values)
+	return table.localOnlineTable.SetBatch(values)
+}
+
+func newBatchRecordingTable(t *testing.T) *batchRecordingTable {
+	store := NewLocalOnlineStore()
+	table, err := store.CreateTable("feature", "variant", Int)
+	if err != nil {
+		t.Fatalf("Failed to create table: %s", err)
+	}
+	return &batchRecordingTable{localOnlineTable: table.(*localOnlineTable)}
+}
+
+func TestBufferedTableCoalescesWrites(t *testing.T) {
+	inner := newBatchRecordingTable(t)
+	table, err := NewBufferedTable(inner, 3, time.Hour)
+	if err != nil {
+		t.Fatalf("Failed to create buffered table: %s", err)
+	}
+	for i, entity := range []string{"a", "a", "b", "a"} {
+		if err := table.Set(entity, i); err != nil {
+			t.Fatalf("Failed to set entity: %s", err)
+		}
+	}
+	if len(inner.batches) != 0 {
+		t.Fatalf("Expected no flush before the buffer is full, got %d", len(inner.batches))
+	}
+	if val, err := table.Get("a"); err != nil || val != 3 {
+		t.Fatalf("Expected buffered value 3, got %v %v", val, err)
+	}
+	if err := table.Set("c", 4); err != nil {
+		t.Fatalf("Failed to set entity: %s", err)
+	}
+	if len(inner.batches) != 1 || len(inner.batches[0]) != 3 {
+		t.Fatalf("Expected one flush of 3 entities, got %v", inner.batches)
+	}
+	if err := table.Set("d", 5); err != nil {
+		t.Fatalf("Failed to set entity: %s", err)
+	}
+	if err := table.Close(); err != nil {
+		t.Fatalf("Failed to close buffered table: %s", err)
+	}
+	expected := map[string]interface{}{"a": 3, "b": 2, "c": 4, "d": 5}
+	for entity, value := range expected {
+		if actual, err := inner.Get(entity); err != nil || actual != value {
+			t.Fatalf("Expected %s to be %v, got %v %v", entity, value, actual, err)
+		}
+	}
+}
+
+func TestBufferedTableFlushesOnInterval(t *testing.T) {
+	inner := newBatchRecordingTable(t)
+	table, err := NewBufferedTable(inner, 100, 10*time.Millisecond)
+	if err != nil {
+		t.Fatalf("Failed to create buffered table: %s", err)
+	}
+	defer table.Close()
+	if err := table.Set("a", 1); err != nil {
+		t.Fatalf("Failed to set entity: %s", err)
+	}
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		if _, err := inner.Get("a"); err == nil {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("Value was not flushed on interval")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func TestBufferedTableRetriesFailedFlush(t *testing.T) {
+	inner := newBatchRecordingTable(t)
+	inner.fail = true
+	table, err := NewBufferedTable(inner, 100, time.Hour)
+	if err != nil {
+		t.Fatalf("Failed to create buffered table: %s", err)
+	}
+	if err := table.Set("a", 1); err != nil {
+		t.Fatalf("Failed to set entity: %s", err)
+	}
+	if err := table.Flush(); err == nil {
+		t.Fatalf("Expected flush to fail")
+	}
+	if err := table.Set("a", 2); err != nil {
+		t.Fatalf("Failed to set entity: %s", err)
+	}
+	if err := table.Set("b", 3); err != nil {
+		t.Fatalf("Failed to set entity: %s", err)
+	}
+	inner.mu.Lock()
+	inner.fail = false
+	inner.mu.Unlock()
+	if err := table.Close(); err != nil {
+		t.Fatalf("Failed to close buffered table: %s", err)
+	}
+	for entity, value := range map[string]interface{}{"a": 2, "b": 3} {
+		if actual, err := inner.Get(entity); err != nil || actual != value {
+			t.Fatalf("Expected %s to be %v, got %v %v", entity, value, actual, err)
+		}
+	}
+}
+
+func TestBufferedTableInvalidConfig(t *testing.T) {
+	if _, err := NewBufferedTable(&localOnlineTable{}, 0, time.Second); err == nil {
+		t.Fatalf("Succeeded in creating buffer with no capacity")
+	}
+	if _, err := NewBufferedTable(&localOnlineTable{}, 1, 0); err == nil {
+		t.Fatalf("Succeeded in creating buffer with no flush interval")
+	}
+}
+
+func TestBufferedTableFallsBackToSet(t *testing.T) {
+	inner := &countingTable{}
+	table, err := NewBufferedTable(inner, 100, time.Hour)
+	if err != nil {
+		t.Fatalf("Failed to create buffered table: %s", err)
+	}
+	for _, entity := range []string{"a", "b", "a"} {
+		if err := table.Set(entity, 1); err != nil {
+			t.Fatalf("Failed to set entity: %s", err)
+		}
+	}
+	if err := table.Close(); err != nil {
+		t.Fatalf("Failed to close buffered table: %s", err)
+	}
+	if inner.sets != 2 {
+		t.Fatalf("Expected 2 sets, got %d", inner.sets)
+	}
+}
@@ -8,6 +8,8 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"reflect"
+	"time"
 
 	pc "github.com/featureform/provider/provider_config"
 	pt "github.com/featureform/provider/provider_type"
@@ -57,6 +59,9 @@ func NewCassandraOnlineStore(options *pc.CassandraConfig) (*cassandraOnlineStore
 		Username: options.Username,
 		Password: options.Password,
 	}
+	if options.TLS {
+		cassandraCluster.SslOpts = &gocql.SslOptions{EnableHostVerification: true}
+	}
 	err := cassandraCluster.Consistency.UnmarshalText([]byte(options.Consistency))
 	if err != nil {
 		return nil, err
@@ -111,6 +116,16 @@ func GetMetadataTableName(keyspace string) string {
 func (store *cassandraOnlineStore) CreateTable(feature, variant string, valueType ValueType) (OnlineStoreTable, error) {
 	tableName := GetTableName(store.keyspace, feature, variant)
 	vType := cassandraTypeMap[string(valueType.Scalar())]
+	switch typed := valueType.(type) {
+	case ListType:
+		vType = fmt.Sprintf("list<%s>", vType)
+	case MapType:
+		vType = fmt.Sprintf("map<%s, %s>", cassandraTypeMap[string(typed.KeyType)], vType)
+	}
+	serializedType, err := serializeValueType(valueType)
+	if err != nil {
+		return nil, err
+	}
 	key := cassandraTableKey{store.keyspace, feature, variant}
 	getTable, _ := store.GetTable(feature, variant)
 	if getTable != nil {
@@ -119,7 +134,7 @@ func (store *cassandraOnlineStore) CreateTable(feature, variant string, valueTyp
 
 	metadataTableName := GetMetadataTableName(store.keyspace)
 	query := fmt.Sprintf("INSERT INTO %s (tableName, tableType) VALUES (?, ?)", metadataTableName)
-	err := store.session.Query(query, tableName, string(valueType.Scalar())).WithContext(context.TODO()).Exec()
+	err = store.session.Query(query, tableName, serializedType).WithContext(context.TODO()).Exec()
 	if err != nil {
 		return nil, err
 	}
@@ -154,11 +169,15 @@ func (store *cassandraOnlineStore) GetTable(feature, variant string) (OnlineStor
 	if err != nil {
 		return nil, err
 	}
+	valueType, err := deserializeValueType(vType)
+	if err != nil {
+		return nil, err
+	}
 
 	table := &cassandraOnlineTable{
 		session:   store.session,
 		key:       key,
-		valueType: ScalarType(vType),
+		valueType: valueType,
 	}
 
 	return table, nil
@@ -181,6 +200,21 @@ func (store *cassandraOnlineStore) DeleteTable(feature, variant string) error {
 	return nil
 }
 
+func (store *cassandraOnlineStore) Capabilities() OnlineCapabilities {
+	return OnlineCapabilities{
+		BatchWrites: true,
+		Truncate:    true,
+	}
+}
+
+func (store *cassandraOnlineStore) Truncate(feature, variant string) error {
+	if _, err := store.GetTable(feature, variant); err != nil {
+		return err
+	}
+	query := fmt.Sprintf("TRUNCATE %s", GetTableName(store.keyspace, feature, variant))
+	return store.session.Query(query).WithContext(context.TODO()).Exec()
+}
+
 func (table cassandraOnlineTable) Set(entity string, value interface{}) error {
 	key := table.key
 	tableName := GetTableName(key.Keyspace, key.Feature, key.Variant)
@@ -194,11 +228,38 @@ func (table cassandraOnlineTable) Set(entity string, value interface{}) error {
 	return nil
 }
 
+// cassandraBatchSize is the most values written in one unlogged batch, which
+// keeps batches under Cassandra's batch size warning threshold.
+const cassandraBatchSize = 100
+
+func (table cassandraOnlineTable) SetBatch(values map[string]interface{}) error {
+	key := table.key
+	tableName := GetTableName(key.Keyspace, key.Feature, key.Variant)
+	query := fmt.Sprintf("INSERT INTO %s (entity, value) VALUES (?, ?)", tableName)
+	batch := table.session.NewBatch(gocql.UnloggedBatch).WithContext(context.TODO())
+	for entity, value := range values {
+		batch.Query(query, entity, value)
+		if batch.Size() == cassandraBatchSize {
+			if err := table.session.ExecuteBatch(batch); err != nil {
+				return err
+			}
+			batch = table.session.NewBatch(gocql.UnloggedBatch).WithContext(context.TODO())
+		}
+	}
+	if batch.Size() > 0 {
+		return table.session.ExecuteBatch(batch)
+	}
+	return nil
+}
+
 func (table cassandraOnlineTable) Get(entity string) (interface{}, error) {
 
 	key := table.key
 	tableName := GetTableName(key.Keyspace, key.Feature, key.Variant)
 
+	if composite, isComposite := table.valueType.(compositeType); isComposite {
+		return table.getComposite(entity, composite)
+	}
 	var ptr interface{}
 	switch table.valueType {
 	case Int:
@@ -213,6 +274,8 @@ func (table cassandraOnlineTable) Get(entity string) (interface{}, error) {
 		ptr = new(bool)
 	case String, NilType:
 		ptr = new(string)
+	case Timestamp, Datetime:
+		ptr = new(time.Time)
 	default:
 		return nil, fmt.Errorf("data type not recognized")
 	}
@@ -240,9 +303,30 @@ func (table cassandraOnlineTable) Get(entity string) (interface{}, error) {
 		val = *casted
 	case *string:
 		val = *casted
+	case *time.Time:
+		val = casted.UTC()
 	default:
 		return nil, fmt.Errorf("data type not recognized")
 	}
 	return val, nil
 
 }
+
+func (table cassandraOnlineTable) getComposite(entity string, composite compositeType) (interface{}, error) {
+	key := table.key
+	tableName := GetTableName(key.Keyspace, key.Feature, key.Variant)
+	goType, err := composite.goType()
+	if err != nil {
+		return nil, err
+	}
+	ptr := reflect.New(goType)
+	query := fmt.Sprintf("SELECT value FROM %s WHERE entity = '%s'", tableName, entity)
+	err = table.session.Query(query).WithContext(context.TODO()).Scan(ptr.Interface())
+	if err == gocql.ErrNotFound {
+		return nil, &EntityNotFound{entity}
+	}
+	if err != nil {
+		return nil, err
+	}
+	return ptr.Elem().Interface(), nil
+}
@@ -0,0 +1,197 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package provider
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/segmentio/kafka-go"
+	"github.com/segmentio/kafka-go/sasl"
+	"github.com/segmentio/kafka-go/sasl/plain"
+	"github.com/segmentio/kafka-go/sasl/scram"
+
+	pc "github.com/featureform/provider/provider_config"
+)
+
+// ChangeStream is a stream of row-level changes to a source, such as a Kafka
+// topic that Debezium publishes a table's changes to. Changes are read in the
+// order they were made to each row, and are read again by the next consumer
+// unless they've been committed.
+type ChangeStream interface {
+	// Next blocks until the next message is read, or ctx is done.
+	Next(ctx context.Context) (ChangeMessage, error)
+	// Commit marks the messages as processed, so that they aren't read again.
+	Commit(ctx context.Context, messages []ChangeMessage) error
+	Close() error
+}
+
+type ChangeMessage struct {
+	// Value is the message's payload, which is empty for tombstones.
+	Value   []byte
+	message kafka.Message
+}
+
+type kafkaChangeStream struct {
+	reader *kafka.Reader
+}
+
+// NewKafkaChangeStream reads a topic as a member of the config's consumer
+// group, starting from the group's committed offsets, or the start of the
+// topic if it has none.
+func NewKafkaChangeStream(config pc.KafkaConfig) (ChangeStream, error) {
+	if len(config.Brokers) == 0 || config.Topic == "" || config.GroupID == "" {
+		return nil, fmt.Errorf("kafka brokers, topic and group id are required to read changes")
+	}
+	dialer, err := kafkaDialer(config)
+	if err != nil {
+		return nil, err
+	}
+	reader := kafka.NewReader(kafka.ReaderConfig{
+		Brokers:     config.Brokers,
+		Topic:       config.Topic,
+		GroupID:     config.GroupID,
+		Dialer:      dialer,
+		StartOffset: kafka.FirstOffset,
+	})
+	return &kafkaChangeStream{reader: reader}, nil
+}
+
+// kafkaDialer connects to the config's brokers with its TLS and SASL
+// settings.
+func kafkaDialer(config pc.KafkaConfig) (*kafka.Dialer, error) {
+	dialer := &kafka.Dialer{Timeout: 10 * time.Second, DualStack: true}
+	if config.TLS {
+		dialer.TLS = &tls.Config{MinVersion: tls.VersionTLS12}
+	}
+	if config.Username != "" {
+		mechanism, err := kafkaSASLMechanism(config)
+		if err != nil {
+			return nil, err
+		}
+		dialer.SASLMechanism = mechanism
+	}
+	return dialer, nil
+}
+
+func kafkaSASLMechanism(config pc.KafkaConfig) (sasl.Mechanism, error) {
+	switch config.Mechanism {
+	case "", pc.KafkaSASLPlain:
+		return plain.Mechanism{Username: config.Username, Password: config.Password}, nil
+	case pc.KafkaSASLScramSHA256:
+		return scram.Mechanism(scram.SHA256, config.Username, config.Password)
+	case pc.KafkaSASLScramSHA512:
+		return scram.Mechanism(scram.SHA512, config.Username, config.Password)
+	default:
+		return nil, fmt.Errorf("unsupported kafka sasl mechanism: %s", config.Mechanism)
+	}
+}
+
+func (stream *kafkaChangeStream) Next(ctx context.Context) (ChangeMessage, error) {
+	message, err := stream.reader.FetchMessage(ctx)
+	if err != nil {
+		return ChangeMessage{}, err
+	}
+	return ChangeMessage{Value: message.Value, message: message}, nil
+}
+
+func (stream *kafkaChangeStream) Commit(ctx context.Context, messages []ChangeMessage) error {
+	if len(messages) == 0 {
+		return nil
+	}
+	kafkaMessages := make([]kafka.Message, len(messages))
+	for i, message := range messages {
+		kafkaMessages[i] = message.message
+	}
+	return stream.reader.CommitMessages(ctx, kafkaMessages...)
+}
+
+func (stream *kafkaChangeStream) Close() error {
+	return stream.reader.Close()
+}
+
+type ChangeOperation string
+
+const (
+	ChangeUpsert ChangeOperation = "upsert"
+	ChangeDelete ChangeOperation = "delete"
+)
+
+// FeatureChange is a change to a feature's value for an entity.
+type FeatureChange struct {
+	Operation ChangeOperation
+	Entity    string
+	// Value is the new value of upserts. It's nil for deletes.
+	Value interface{}
+}
+
+// debeziumEnvelope is a Debezium change event. If the JSON converter includes
+// schemas, the envelope is the event's payload.
+type debeziumEnvelope struct {
+	Payload *debeziumEnvelope      `json:"payload"`
+	Before  map[string]interface{} `json:"before"`
+	After   map[string]interface{} `json:"after"`
+	Op      string                 `json:"op"`
+}
+
+// ParseDebeziumChange returns the change to a feature in a Debezium change
+// event, whose rows have the schema's entity and value columns. It returns
+// false for messages that don't change a value: tombstones, truncates, and
+// rows whose value is null.
+func ParseDebeziumChange(message []byte, schema ResourceSchema, valueType ValueType) (FeatureChange, bool, error) {
+	if len(bytes.TrimSpace(message)) == 0 || bytes.Equal(bytes.TrimSpace(message), []byte("null")) {
+		return FeatureChange{}, false, nil
+	}
+	decoder := json.NewDecoder(bytes.NewReader(message))
+	// Entities and values that are large integers aren't rounded to floats.
+	decoder.UseNumber()
+	envelope := &debeziumEnvelope{}
+	if err := decoder.Decode(envelope); err != nil {
+		return FeatureChange{}, false, fmt.Errorf("invalid debezium event: %w", err)
+	}
+	if envelope.Payload != nil {
+		envelope = envelope.Payload
+	}
+	var row map[string]interface{}
+	var operation ChangeOperation
+	switch envelope.Op {
+	case "c", "u", "r":
+		row, operation = envelope.After, ChangeUpsert
+	case "d":
+		row, operation = envelope.Before, ChangeDelete
+	case "t":
+		return FeatureChange{}, false, nil
+	default:
+		return FeatureChange{}, false, fmt.Errorf("unknown debezium operation %q", envelope.Op)
+	}
+	if row == nil {
+		return FeatureChange{}, false, fmt.Errorf("debezium %q event has no row", envelope.Op)
+	}
+	entity, has := row[schema.Entity]
+	if !has || entity == nil {
+		return FeatureChange{}, false, fmt.Errorf("debezium event has no entity column %s", schema.Entity)
+	}
+	change := FeatureChange{Operation: operation, Entity: fmt.Sprint(entity)}
+	if operation == ChangeDelete {
+		return change, true, nil
+	}
+	value, has := row[schema.Value]
+	if !has {
+		return FeatureChange{}, false, fmt.Errorf("debezium event has no value column %s", schema.Value)
+	}
+	if value == nil {
+		return FeatureChange{}, false, nil
+	}
+	cast, err := castImportValue(valueType, value)
+	if err != nil {
+		return FeatureChange{}, false, fmt.Errorf("could not cast value of %s: %w", change.Entity, err)
+	}
+	change.Value = cast
+	return change, true, nil
+}
@@ -0,0 +1,53 @@
+package provider
+
+import (
+	"testing"
+)
+
+func TestParseDebeziumChange(t *testing.T) {
+	schema := ResourceSchema{Entity: "user_id", Value: "balance", TS: "updated_at"}
+	tests := []struct {
+		name     string
+		message  string
+		expected FeatureChange
+		ok       bool
+	}{
+		{"Create", `{"before": null, "after": {"user_id": "a", "balance": 10}, "op": "c"}`, FeatureChange{ChangeUpsert, "a", 10}, true},
+		{"Update With Schema", `{"schema": {"type": "struct"}, "payload": {"before": {"user_id": "a", "balance": 10}, "after": {"user_id": "a", "balance": "12"}, "op": "u"}}`, FeatureChange{ChangeUpsert, "a", 12}, true},
+		{"Snapshot", `{"after": {"user_id": 9007199254740993, "balance": 1}, "op": "r"}`, FeatureChange{ChangeUpsert, "9007199254740993", 1}, true},
+		{"Delete", `{"before": {"user_id": "a", "balance": 12}, "after": null, "op": "d"}`, FeatureChange{Operation: ChangeDelete, Entity: "a"}, true},
+		{"Tombstone", ``, FeatureChange{}, false},
+		{"Truncate", `{"payload": {"op": "t"}}`, FeatureChange{}, false},
+		{"Null Value", `{"after": {"user_id": "a", "balance": null}, "op": "c"}`, FeatureChange{}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			change, ok, err := ParseDebeziumChange([]byte(tt.message), schema, Int)
+			if err != nil {
+				t.Fatalf("Failed to parse change: %s", err)
+			}
+			if ok != tt.ok || change != tt.expected {
+				t.Fatalf("Expected %v %v, got %v %v", tt.expected, tt.ok, change, ok)
+			}
+		})
+	}
+}
+
+func TestParseDebeziumChangeErrors(t *testing.T) {
+	schema := ResourceSchema{Entity: "user_id", Value: "balance"}
+	messages := map[string]string{
+		"Invalid JSON":     `{"op": `,
+		"Unknown Op":       `{"after": {"user_id": "a", "balance": 1}, "op": "x"}`,
+		"Missing Row":      `{"after": null, "op": "c"}`,
+		"Missing Entity":   `{"after": {"balance": 1}, "op": "c"}`,
+		"Missing Value":    `{"after": {"user_id": "a"}, "op": "u"}`,
+		"Uncastable Value": `{"after": {"user_id": "a", "balance": "ten"}, "op": "u"}`,
+	}
+	for name, message := range messages {
+		t.Run(name, func(t *testing.T) {
+			if _, _, err := ParseDebeziumChange([]byte(message), schema, Int); err == nil {
+				t.Fatalf("Expected an error")
+			}
+		})
+	}
+}
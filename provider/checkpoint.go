@@ -0,0 +1,191 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package provider
+
+import (
+	"encoding/json"
+	"fmt"
+	"path"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// ChunkPlan is how a materialization was split into chunks to copy it to the
+// online store. Chunks are only the same rows as when they were checkpointed
+// if the materialization is copied with the same plan.
+type ChunkPlan struct {
+	Materialization MaterializationID
+	ChunkSize       int64
+	NumChunks       int64
+	Approximate     bool
+	Partitioned     bool
+	// CopyID identifies the copy that the plan is of. A chunk is the same
+	// write as another if they have the same CopyID and index, so chunks of
+	// a copy whose checkpoint has been replaced by a newer copy's aren't
+	// written again.
+	CopyID string
+	// Rows is how many rows the materialization had when it was planned, or
+	// zero if the plan is approximate or partitioned.
+	Rows int64
+}
+
+// ChunkCheckpoint is the plan of a copy of a feature's materialization to the
+// online store that hasn't finished, and the indexes of its chunks that have.
+type ChunkCheckpoint struct {
+	Plan      ChunkPlan
+	Completed map[int64]bool
+}
+
+// ChunkCheckpointStore is implemented by offline stores that can record which
+// chunks of a feature's materialization have been copied to the online store,
+// so that a copy that crashes or is restarted resumes from the chunks that
+// hadn't completed instead of copying every row again.
+type ChunkCheckpointStore interface {
+	// GetChunkCheckpoint returns a *ChunkCheckpointNotFound error if the
+	// feature has no copy in progress.
+	GetChunkCheckpoint(id ResourceID) (ChunkCheckpoint, error)
+	// StartChunkCheckpoint replaces the feature's checkpoint with one of plan
+	// that has no completed chunks.
+	StartChunkCheckpoint(id ResourceID, plan ChunkPlan) error
+	CompleteChunk(id ResourceID, chunk int64) error
+	DeleteChunkCheckpoint(id ResourceID) error
+}
+
+type ChunkCheckpointNotFound struct {
+	ID ResourceID
+}
+
+func (err *ChunkCheckpointNotFound) Error() string {
+	return fmt.Sprintf("no chunk checkpoint of %s %s", err.ID.Name, err.ID.Variant)
+}
+
+// memoryChunkCheckpoints keeps checkpoints for the memory offline store.
+// Chunks are copied concurrently, so they're guarded by a lock unlike the
+// rest of the store.
+type memoryChunkCheckpoints struct {
+	checkpoints map[ResourceID]ChunkCheckpoint
+	mu          sync.Mutex
+}
+
+func (store *memoryChunkCheckpoints) GetChunkCheckpoint(id ResourceID) (ChunkCheckpoint, error) {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+	checkpoint, has := store.checkpoints[checkpointKey(id)]
+	if !has {
+		return ChunkCheckpoint{}, &ChunkCheckpointNotFound{id}
+	}
+	completed := make(map[int64]bool, len(checkpoint.Completed))
+	for chunk := range checkpoint.Completed {
+		completed[chunk] = true
+	}
+	return ChunkCheckpoint{Plan: checkpoint.Plan, Completed: completed}, nil
+}
+
+func (store *memoryChunkCheckpoints) StartChunkCheckpoint(id ResourceID, plan ChunkPlan) error {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+	if store.checkpoints == nil {
+		store.checkpoints = make(map[ResourceID]ChunkCheckpoint)
+	}
+	store.checkpoints[checkpointKey(id)] = ChunkCheckpoint{Plan: plan, Completed: make(map[int64]bool)}
+	return nil
+}
+
+func (store *memoryChunkCheckpoints) CompleteChunk(id ResourceID, chunk int64) error {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+	checkpoint, has := store.checkpoints[checkpointKey(id)]
+	if !has {
+		return &ChunkCheckpointNotFound{id}
+	}
+	checkpoint.Completed[chunk] = true
+	return nil
+}
+
+func (store *memoryChunkCheckpoints) DeleteChunkCheckpoint(id ResourceID) error {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+	delete(store.checkpoints, checkpointKey(id))
+	return nil
+}
+
+// checkpointKey is the feature that a checkpoint of id is kept under.
+func checkpointKey(id ResourceID) ResourceID {
+	return ResourceID{Name: id.Name, Variant: id.Variant, Type: Feature}
+}
+
+// fileStoreChunkCheckpoints keeps checkpoints in a file store, with the plan
+// and each completed chunk in their own file so chunks running in separate
+// workers don't overwrite each other's. They're kept outside of the resource
+// directories, which vacuums leave alone.
+type fileStoreChunkCheckpoints struct {
+	store FileStore
+}
+
+func (c fileStoreChunkCheckpoints) dir(id ResourceID) string {
+	return c.store.PathWithPrefix(fmt.Sprintf("featureform/ChunkCheckpoint/%s/%s", id.Name, id.Variant), false)
+}
+
+func (c fileStoreChunkCheckpoints) GetChunkCheckpoint(id ResourceID) (ChunkCheckpoint, error) {
+	planKey := path.Join(c.dir(id), "plan.json")
+	exists, err := c.store.Exists(planKey)
+	if err != nil {
+		return ChunkCheckpoint{}, fmt.Errorf("could not check for chunk checkpoint: %w", err)
+	}
+	if !exists {
+		return ChunkCheckpoint{}, &ChunkCheckpointNotFound{id}
+	}
+	data, err := c.store.Read(planKey)
+	if err != nil {
+		return ChunkCheckpoint{}, fmt.Errorf("could not read chunk checkpoint: %w", err)
+	}
+	checkpoint := ChunkCheckpoint{Completed: make(map[int64]bool)}
+	if err := json.Unmarshal(data, &checkpoint.Plan); err != nil {
+		return ChunkCheckpoint{}, fmt.Errorf("could not parse chunk checkpoint: %w", err)
+	}
+	chunksDir := path.Join(c.dir(id), "chunks") + "/"
+	files, err := c.store.ListFiles(chunksDir)
+	if err != nil {
+		return ChunkCheckpoint{}, fmt.Errorf("could not list completed chunks: %w", err)
+	}
+	for _, file := range files {
+		chunk, err := strconv.ParseInt(path.Base(strings.TrimPrefix(file.Key, chunksDir)), 10, 64)
+		if err != nil {
+			continue
+		}
+		checkpoint.Completed[chunk] = true
+	}
+	return checkpoint, nil
+}
+
+func (c fileStoreChunkCheckpoints) StartChunkCheckpoint(id ResourceID, plan ChunkPlan) error {
+	if err := c.DeleteChunkCheckpoint(id); err != nil {
+		return err
+	}
+	data, err := json.Marshal(plan)
+	if err != nil {
+		return fmt.Errorf("could not serialize chunk plan: %w", err)
+	}
+	if err := c.store.Write(path.Join(c.dir(id), "plan.json"), data); err != nil {
+		return fmt.Errorf("could not write chunk checkpoint: %w", err)
+	}
+	return nil
+}
+
+func (c fileStoreChunkCheckpoints) CompleteChunk(id ResourceID, chunk int64) error {
+	key := path.Join(c.dir(id), "chunks", strconv.FormatInt(chunk, 10))
+	if err := c.store.Write(key, []byte{}); err != nil {
+		return fmt.Errorf("could not checkpoint chunk %d: %w", chunk, err)
+	}
+	return nil
+}
+
+func (c fileStoreChunkCheckpoints) DeleteChunkCheckpoint(id ResourceID) error {
+	if err := c.store.DeleteAll(c.dir(id) + "/"); err != nil {
+		return fmt.Errorf("could not delete chunk checkpoint: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,73 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package provider
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"testing"
+
+	pc "github.com/featureform/provider/provider_config"
+)
+
+func testChunkCheckpoints(t *testing.T, store ChunkCheckpointStore) {
+	id := ResourceID{Name: "feature", Variant: "v1", Type: Feature}
+	notFound := &ChunkCheckpointNotFound{}
+	if _, err := store.GetChunkCheckpoint(id); !errors.As(err, &notFound) {
+		t.Fatalf("Expected no checkpoint, got %v", err)
+	}
+	plan := ChunkPlan{Materialization: "materialization", ChunkSize: 10, NumChunks: 3}
+	if err := store.StartChunkCheckpoint(id, plan); err != nil {
+		t.Fatalf("Failed to start checkpoint: %s", err)
+	}
+	for _, chunk := range []int64{0, 2} {
+		if err := store.CompleteChunk(id, chunk); err != nil {
+			t.Fatalf("Failed to complete chunk %d: %s", chunk, err)
+		}
+	}
+	checkpoint, err := store.GetChunkCheckpoint(id)
+	if err != nil {
+		t.Fatalf("Failed to get checkpoint: %s", err)
+	}
+	expected := ChunkCheckpoint{Plan: plan, Completed: map[int64]bool{0: true, 2: true}}
+	if !reflect.DeepEqual(checkpoint, expected) {
+		t.Fatalf("Expected checkpoint %+v, got %+v", expected, checkpoint)
+	}
+	replanned := ChunkPlan{Materialization: "materialization", ChunkSize: 5, NumChunks: 6}
+	if err := store.StartChunkCheckpoint(id, replanned); err != nil {
+		t.Fatalf("Failed to restart checkpoint: %s", err)
+	}
+	checkpoint, err = store.GetChunkCheckpoint(id)
+	if err != nil {
+		t.Fatalf("Failed to get checkpoint: %s", err)
+	}
+	if checkpoint.Plan != replanned || len(checkpoint.Completed) != 0 {
+		t.Fatalf("Expected a new checkpoint to replace the old one, got %+v", checkpoint)
+	}
+	if err := store.DeleteChunkCheckpoint(id); err != nil {
+		t.Fatalf("Failed to delete checkpoint: %s", err)
+	}
+	if _, err := store.GetChunkCheckpoint(id); !errors.As(err, &notFound) {
+		t.Fatalf("Expected checkpoint to be deleted, got %v", err)
+	}
+}
+
+func TestMemoryChunkCheckpoints(t *testing.T) {
+	testChunkCheckpoints(t, NewMemoryOfflineStore())
+}
+
+func TestFileStoreChunkCheckpoints(t *testing.T) {
+	config := pc.LocalFileStoreConfig{DirPath: fmt.Sprintf("file://%s/", t.TempDir())}
+	serialized, err := config.Serialize()
+	if err != nil {
+		t.Fatalf("Failed to serialize file store config: %s", err)
+	}
+	store, err := NewLocalFileStore(serialized)
+	if err != nil {
+		t.Fatalf("Failed to create file store: %s", err)
+	}
+	testChunkCheckpoints(t, fileStoreChunkCheckpoints{store})
+}
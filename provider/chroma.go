@@ -0,0 +1,438 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package provider
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"math"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	pc "github.com/featureform/provider/provider_config"
+	pt "github.com/featureform/provider/provider_type"
+)
+
+const (
+	chromaCollectionPrefix = "featureform_"
+	chromaMetaFeature      = "featureform_feature"
+	chromaMetaVariant      = "featureform_variant"
+	chromaMetaValueType    = "featureform_value_type"
+	chromaMetaTable        = "featureform_table"
+	// Embeddings' metadata has their write time in Unix milliseconds, their
+	// namespace if they have one, and their attributes prefixed by
+	// chromaAttributePrefix, so that attribute keys can't collide with
+	// featureform's.
+	chromaMetaUpdatedAt   = "featureform_updated_at"
+	chromaMetaNamespace   = "featureform_namespace"
+	chromaAttributePrefix = "attribute:"
+)
+
+func chromaOnlineStoreFactory(serialized pc.SerializedConfig) (Provider, error) {
+	config := &pc.ChromaConfig{}
+	if err := config.Deserialize(serialized); err != nil {
+		return nil, err
+	}
+	return NewChromaOnlineStore(config)
+}
+
+// chromaOnlineStore is a VectorStore with one Chroma collection per feature
+// variant. Each entity is an embedding with the entity as its ID, so
+// writing an entity again replaces its vector.
+type chromaOnlineStore struct {
+	client *jsonHTTPClient
+	// scope is the tenant and database query string of collection
+	// requests by name, or empty to use Chroma's defaults.
+	scope string
+	BaseProvider
+}
+
+func NewChromaOnlineStore(config *pc.ChromaConfig) (*chromaOnlineStore, error) {
+	if config.URL == "" {
+		return nil, fmt.Errorf("chroma URL is required")
+	}
+	header := make(http.Header)
+	if config.APIKey != "" {
+		header.Set("Authorization", "Bearer "+config.APIKey)
+	}
+	scope := url.Values{}
+	if config.Tenant != "" {
+		scope.Set("tenant", config.Tenant)
+	}
+	if config.Database != "" {
+		scope.Set("database", config.Database)
+	}
+	store := &chromaOnlineStore{
+		client: newJSONHTTPClient(config.URL, header, nil),
+		BaseProvider: BaseProvider{
+			ProviderType:   pt.ChromaOnline,
+			ProviderConfig: config.Serialized(),
+		},
+	}
+	if len(scope) > 0 {
+		store.scope = "?" + scope.Encode()
+	}
+	return store, nil
+}
+
+// chromaCollectionName returns the collection of a feature variant.
+// Collection names are limited to 63 characters, so the feature and variant
+// are hashed and kept in the collection's metadata instead.
+func chromaCollectionName(feature, variant string) string {
+	hash := sha256.Sum256([]byte(feature + "\x00" + variant))
+	return chromaCollectionPrefix + hex.EncodeToString(hash[:16])
+}
+
+// Chroma's status codes for missing and duplicate collections differ
+// between versions, so its error messages are checked as well.
+func isChromaNotFound(err error) bool {
+	return isHTTPStatus(err, http.StatusNotFound) || (err != nil && strings.Contains(err.Error(), "does not exist"))
+}
+
+func isChromaConflict(err error) bool {
+	return isHTTPStatus(err, http.StatusConflict) || (err != nil && strings.Contains(err.Error(), "already exists"))
+}
+
+// chromaCollection is a Chroma collection. Metadata values can only be
+// scalars, so the value type is stored serialized. Like Redis,
+// materializations create a feature's index and then its table, so the
+// featureform_table key records whether CreateTable has been called.
+type chromaCollection struct {
+	ID       string                 `json:"id"`
+	Name     string                 `json:"name"`
+	Metadata map[string]interface{} `json:"metadata"`
+}
+
+func (store *chromaOnlineStore) AsOnlineStore() (OnlineStore, error) {
+	return store, nil
+}
+
+func (store *chromaOnlineStore) AsVectorStore() (VectorStore, error) {
+	return store, nil
+}
+
+func (store *chromaOnlineStore) Close() error {
+	return nil
+}
+
+func (store *chromaOnlineStore) getCollection(feature, variant string) (chromaCollection, ValueType, error) {
+	collection := chromaCollection{}
+	path := "/api/v1/collections/" + chromaCollectionName(feature, variant) + store.scope
+	err := store.client.do(context.TODO(), http.MethodGet, path, nil, &collection)
+	if isChromaNotFound(err) {
+		return collection, nil, &TableNotFound{feature, variant}
+	} else if err != nil {
+		return collection, nil, err
+	}
+	serializedType, ok := collection.Metadata[chromaMetaValueType].(string)
+	if !ok {
+		return collection, nil, fmt.Errorf("collection %s has no featureform metadata", collection.Name)
+	}
+	valueType, err := deserializeValueType(serializedType)
+	if err != nil {
+		return collection, nil, err
+	}
+	return collection, valueType, nil
+}
+
+func (store *chromaOnlineStore) table(collectionID string, valueType ValueType) (*chromaOnlineTable, error) {
+	vectorType, ok := valueType.(VectorType)
+	if !ok {
+		return nil, fmt.Errorf("chroma only stores vectors, not %v", valueType)
+	}
+	return &chromaOnlineTable{client: store.client, collectionID: collectionID, valueType: vectorType}, nil
+}
+
+func (store *chromaOnlineStore) GetTable(feature, variant string) (OnlineStoreTable, error) {
+	collection, valueType, err := store.getCollection(feature, variant)
+	if err != nil {
+		return nil, err
+	}
+	return store.table(collection.ID, valueType)
+}
+
+// CreateTable creates the feature variant's collection, or marks the
+// collection created by CreateIndex as a table.
+func (store *chromaOnlineStore) CreateTable(feature, variant string, valueType ValueType) (OnlineStoreTable, error) {
+	collection, existingType, err := store.getCollection(feature, variant)
+	if _, notFound := err.(*TableNotFound); notFound {
+		return store.createCollection(feature, variant, valueType, true)
+	} else if err != nil {
+		return nil, err
+	}
+	if isTable, _ := collection.Metadata[chromaMetaTable].(bool); isTable {
+		return nil, &TableAlreadyExists{feature, variant}
+	}
+	// The distance function can't be changed, and Chroma rejects updates
+	// that include it.
+	metadata := make(map[string]interface{}, len(collection.Metadata))
+	for key, value := range collection.Metadata {
+		if !strings.HasPrefix(key, "hnsw:") {
+			metadata[key] = value
+		}
+	}
+	metadata[chromaMetaTable] = true
+	request := map[string]interface{}{"new_metadata": metadata}
+	if err := store.client.do(context.TODO(), http.MethodPut, "/api/v1/collections/"+collection.ID, request, nil); err != nil {
+		return nil, fmt.Errorf("could not update chroma collection: %w", err)
+	}
+	return store.table(collection.ID, existingType)
+}
+
+func (store *chromaOnlineStore) CreateIndex(feature, variant string, vectorType VectorType) (VectorStoreTable, error) {
+	return store.createCollection(feature, variant, vectorType, false)
+}
+
+var chromaDistanceMetrics = map[DistanceMetric]string{
+	CosineDistance:       "cosine",
+	L2Distance:           "l2",
+	InnerProductDistance: "ip",
+}
+
+func (store *chromaOnlineStore) createCollection(feature, variant string, valueType ValueType, isTable bool) (*chromaOnlineTable, error) {
+	table, err := store.table("", valueType)
+	if err != nil {
+		return nil, err
+	}
+	serializedType, err := serializeValueType(valueType)
+	if err != nil {
+		return nil, err
+	}
+	options := table.valueType.Options()
+	if err := options.validate("chroma", false); err != nil {
+		return nil, err
+	}
+	if err := table.valueType.validateStorage("chroma", Float32); err != nil {
+		return nil, err
+	}
+	if err := table.valueType.validateNames("chroma", false); err != nil {
+		return nil, err
+	}
+	metadata := map[string]interface{}{
+		chromaMetaFeature:   feature,
+		chromaMetaVariant:   variant,
+		chromaMetaValueType: serializedType,
+		chromaMetaTable:     isTable,
+		"hnsw:space":        chromaDistanceMetrics[table.valueType.Metric()],
+	}
+	if options.M > 0 {
+		metadata["hnsw:M"] = options.M
+	}
+	if options.EfConstruction > 0 {
+		metadata["hnsw:construction_ef"] = options.EfConstruction
+	}
+	if options.EfRuntime > 0 {
+		metadata["hnsw:search_ef"] = options.EfRuntime
+	}
+	request := map[string]interface{}{
+		"name":          chromaCollectionName(feature, variant),
+		"metadata":      metadata,
+		"get_or_create": false,
+	}
+	collection := chromaCollection{}
+	err = store.client.do(context.TODO(), http.MethodPost, "/api/v1/collections"+store.scope, request, &collection)
+	if isChromaConflict(err) {
+		return nil, &TableAlreadyExists{feature, variant}
+	} else if err != nil {
+		return nil, fmt.Errorf("could not create chroma collection: %w", err)
+	}
+	table.collectionID = collection.ID
+	return table, nil
+}
+
+func (store *chromaOnlineStore) DeleteTable(feature, variant string) error {
+	path := "/api/v1/collections/" + chromaCollectionName(feature, variant) + store.scope
+	err := store.client.do(context.TODO(), http.MethodDelete, path, nil, nil)
+	if isChromaNotFound(err) {
+		return &TableNotFound{feature, variant}
+	}
+	return err
+}
+
+func (store *chromaOnlineStore) Capabilities() OnlineCapabilities {
+	return OnlineCapabilities{
+		BatchWrites:      true,
+		Vectors:          true,
+		VectorFilters:    true,
+		VectorNamespaces: true,
+		IndexStats:       true,
+	}
+}
+
+type chromaOnlineTable struct {
+	client       *jsonHTTPClient
+	collectionID string
+	valueType    VectorType
+}
+
+func (table *chromaOnlineTable) Set(entity string, value interface{}) error {
+	return table.SetBatch(map[string]interface{}{entity: value})
+}
+
+func (table *chromaOnlineTable) SetWithAttributes(entity string, vector []float32, attributes map[string]string) error {
+	if err := checkVectorDimension(table.valueType, entity, vector); err != nil {
+		return err
+	}
+	metadata := map[string]interface{}{chromaMetaUpdatedAt: time.Now().UnixMilli()}
+	for key, value := range attributes {
+		if key == "" {
+			return fmt.Errorf("invalid vector attribute key %q", key)
+		}
+		metadata[chromaAttributePrefix+key] = value
+	}
+	return table.upsert([]string{entity}, [][]float32{vector}, []map[string]interface{}{metadata})
+}
+
+func (table *chromaOnlineTable) SetInNamespace(namespace, entity string, vector []float32) error {
+	if err := validateNamespace(namespace); err != nil {
+		return err
+	}
+	if err := checkVectorDimension(table.valueType, entity, vector); err != nil {
+		return err
+	}
+	metadata := map[string]interface{}{chromaMetaUpdatedAt: time.Now().UnixMilli(), chromaMetaNamespace: namespace}
+	return table.upsert([]string{entity}, [][]float32{vector}, []map[string]interface{}{metadata})
+}
+
+// SetBatch upserts the entities' vectors in one request.
+func (table *chromaOnlineTable) SetBatch(values map[string]interface{}) error {
+	ids := make([]string, 0, len(values))
+	embeddings := make([][]float32, 0, len(values))
+	metadatas := make([]map[string]interface{}, 0, len(values))
+	now := time.Now().UnixMilli()
+	for entity, value := range values {
+		vector, err := vectorValue(table.valueType, entity, value)
+		if err != nil {
+			return err
+		}
+		ids = append(ids, entity)
+		embeddings = append(embeddings, vector)
+		metadatas = append(metadatas, map[string]interface{}{chromaMetaUpdatedAt: now})
+	}
+	return table.upsert(ids, embeddings, metadatas)
+}
+
+// upsert replaces the embeddings and their metadata, so a write without
+// attributes or a namespace clears the entity's previous ones.
+func (table *chromaOnlineTable) upsert(ids []string, embeddings [][]float32, metadatas []map[string]interface{}) error {
+	request := map[string]interface{}{"ids": ids, "embeddings": embeddings, "metadatas": metadatas}
+	return table.client.do(context.TODO(), http.MethodPost, "/api/v1/collections/"+table.collectionID+"/upsert", request, nil)
+}
+
+func (table *chromaOnlineTable) Get(entity string) (interface{}, error) {
+	request := map[string]interface{}{"ids": []string{entity}, "include": []string{"embeddings"}}
+	var response struct {
+		IDs        []string    `json:"ids"`
+		Embeddings [][]float32 `json:"embeddings"`
+	}
+	if err := table.client.do(context.TODO(), http.MethodPost, "/api/v1/collections/"+table.collectionID+"/get", request, &response); err != nil {
+		return nil, err
+	}
+	if len(response.IDs) == 0 || len(response.Embeddings) == 0 {
+		return nil, &EntityNotFound{entity}
+	}
+	return response.Embeddings[0], nil
+}
+
+func (table *chromaOnlineTable) Delete(entity string) error {
+	request := map[string]interface{}{"ids": []string{entity}}
+	return table.client.do(context.TODO(), http.MethodPost, "/api/v1/collections/"+table.collectionID+"/delete", request, nil)
+}
+
+// IndexStats counts the collection's embeddings. Chroma indexes embeddings
+// as they're written and doesn't report the index's memory usage.
+func (table *chromaOnlineTable) IndexStats() (VectorIndexStats, error) {
+	var count int64
+	if err := table.client.do(context.TODO(), http.MethodGet, "/api/v1/collections/"+table.collectionID+"/count", nil, &count); err != nil {
+		return VectorIndexStats{}, err
+	}
+	return VectorIndexStats{Vectors: count, Dimension: table.valueType.Dimension, IndexedFraction: 1}, nil
+}
+
+// chromaWhere returns the metadata filter of a VectorFilter, or nil if the
+// filter is zero. Chroma only accepts one condition per filter, so multiple
+// conditions are combined with $and.
+func chromaWhere(filter VectorFilter) map[string]interface{} {
+	conditions := []map[string]interface{}{}
+	for key, value := range filter.Attributes {
+		conditions = append(conditions, map[string]interface{}{chromaAttributePrefix + key: map[string]interface{}{"$eq": value}})
+	}
+	if !filter.UpdatedAfter.IsZero() {
+		conditions = append(conditions, map[string]interface{}{chromaMetaUpdatedAt: map[string]interface{}{"$gte": filter.UpdatedAfter.UnixMilli()}})
+	}
+	if filter.Namespace != "" {
+		conditions = append(conditions, map[string]interface{}{chromaMetaNamespace: map[string]interface{}{"$eq": filter.Namespace}})
+	}
+	switch len(conditions) {
+	case 0:
+		return nil
+	case 1:
+		return conditions[0]
+	default:
+		return map[string]interface{}{"$and": conditions}
+	}
+}
+
+// Nearest returns the entities of the k embeddings nearest to vector that
+// match filter.
+func (table *chromaOnlineTable) Nearest(feature, variant string, vector []float32, k int32, filter VectorFilter) ([]NearestResult, error) {
+	return table.nearest(vector, 0, k, filter)
+}
+
+func (table *chromaOnlineTable) NearestPage(feature, variant string, vector []float32, pageSize int32, filter VectorFilter, cursor string) (NearestPage, error) {
+	search := func(offset, k int32) ([]NearestResult, error) {
+		return table.nearest(vector, offset, k, filter)
+	}
+	return nearestPage(search, pageSize, cursor)
+}
+
+// nearest returns the k embeddings after the nearest offset embeddings.
+// Chroma queries can't skip results, so the first offset are discarded.
+func (table *chromaOnlineTable) nearest(vector []float32, offset, k int32, filter VectorFilter) ([]NearestResult, error) {
+	request := map[string]interface{}{
+		"query_embeddings": [][]float32{vector},
+		"n_results":        offset + k,
+		"include":          []string{"distances"},
+	}
+	if where := chromaWhere(filter); where != nil {
+		request["where"] = where
+	}
+	var response struct {
+		IDs       [][]string  `json:"ids"`
+		Distances [][]float32 `json:"distances"`
+	}
+	if err := table.client.do(context.TODO(), http.MethodPost, "/api/v1/collections/"+table.collectionID+"/query", request, &response); err != nil {
+		return nil, err
+	}
+	if len(response.IDs) == 0 {
+		return []NearestResult{}, nil
+	}
+	if len(response.Distances) == 0 || len(response.Distances[0]) != len(response.IDs[0]) {
+		return nil, fmt.Errorf("chroma query returned results without distances")
+	}
+	results := make([]NearestResult, 0, k)
+	for i := int(offset); i < len(response.IDs[0]) && len(results) < int(k); i++ {
+		results = append(results, NearestResult{Entity: response.IDs[0][i], Distance: chromaDistance(table.valueType.Metric(), response.Distances[0][i])})
+	}
+	return results, nil
+}
+
+// chromaDistance converts a Chroma distance to a NearestResult distance.
+// Chroma's l2 distance is squared, and its ip distance is one minus the
+// inner product.
+func chromaDistance(metric DistanceMetric, distance float32) float32 {
+	switch metric {
+	case L2Distance:
+		return float32(math.Sqrt(float64(distance)))
+	case InnerProductDistance:
+		return distance - 1
+	default:
+		return distance
+	}
+}
@@ -0,0 +1,295 @@
+package provider
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	pc "github.com/featureform/provider/provider_config"
+)
+
+// fakeChroma implements the parts of the Chroma REST API used by the store.
+// Collection IDs are their names, and queries return every embedding of the
+// collection in ID order.
+type fakeChroma struct {
+	collections map[string]chromaCollection
+	embeddings  map[string]map[string][]float32
+	auth        string
+	tenant      string
+	mu          sync.Mutex
+}
+
+func newFakeChroma() *fakeChroma {
+	return &fakeChroma{
+		collections: make(map[string]chromaCollection),
+		embeddings:  make(map[string]map[string][]float32),
+	}
+}
+
+func (f *fakeChroma) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.auth = r.Header.Get("Authorization")
+	if tenant := r.URL.Query().Get("tenant"); tenant != "" {
+		f.tenant = tenant
+	}
+	path := strings.Split(strings.TrimPrefix(r.URL.Path, "/api/v1/collections"), "/")
+	switch {
+	case r.Method == http.MethodPost && len(path) == 1:
+		var request struct {
+			Name     string                 `json:"name"`
+			Metadata map[string]interface{} `json:"metadata"`
+		}
+		json.NewDecoder(r.Body).Decode(&request)
+		if _, has := f.collections[request.Name]; has {
+			http.Error(w, `{"error":"UniqueConstraintError('Collection already exists')"}`, http.StatusInternalServerError)
+			return
+		}
+		collection := chromaCollection{ID: request.Name, Name: request.Name, Metadata: request.Metadata}
+		f.collections[request.Name] = collection
+		f.embeddings[request.Name] = make(map[string][]float32)
+		json.NewEncoder(w).Encode(collection)
+	case len(path) == 2:
+		collection, has := f.collections[path[1]]
+		if !has {
+			http.Error(w, `{"error":"ValueError('Collection does not exist.')"}`, http.StatusInternalServerError)
+			return
+		}
+		switch r.Method {
+		case http.MethodGet:
+			json.NewEncoder(w).Encode(collection)
+		case http.MethodPut:
+			var request struct {
+				NewMetadata map[string]interface{} `json:"new_metadata"`
+			}
+			json.NewDecoder(r.Body).Decode(&request)
+			if _, has := request.NewMetadata["hnsw:space"]; has {
+				http.Error(w, `{"error":"can't change distance"}`, http.StatusInternalServerError)
+				return
+			}
+			collection.Metadata = request.NewMetadata
+			f.collections[path[1]] = collection
+		case http.MethodDelete:
+			delete(f.collections, path[1])
+			delete(f.embeddings, path[1])
+		}
+	case len(path) == 3 && path[2] == "upsert":
+		var request struct {
+			IDs        []string    `json:"ids"`
+			Embeddings [][]float32 `json:"embeddings"`
+		}
+		json.NewDecoder(r.Body).Decode(&request)
+		for i, id := range request.IDs {
+			f.embeddings[path[1]][id] = request.Embeddings[i]
+		}
+		w.Write([]byte("true"))
+	case len(path) == 3 && path[2] == "get":
+		var request struct {
+			IDs []string `json:"ids"`
+		}
+		json.NewDecoder(r.Body).Decode(&request)
+		response := map[string]interface{}{"ids": []string{}, "embeddings": [][]float32{}}
+		if embedding, has := f.embeddings[path[1]][request.IDs[0]]; has {
+			response = map[string]interface{}{"ids": request.IDs, "embeddings": [][]float32{embedding}}
+		}
+		json.NewEncoder(w).Encode(response)
+	case len(path) == 3 && path[2] == "count":
+		json.NewEncoder(w).Encode(len(f.embeddings[path[1]]))
+	case len(path) == 3 && path[2] == "delete":
+		var request struct {
+			IDs []string `json:"ids"`
+		}
+		json.NewDecoder(r.Body).Decode(&request)
+		for _, id := range request.IDs {
+			delete(f.embeddings[path[1]], id)
+		}
+		w.Write([]byte("[]"))
+	case len(path) == 3 && path[2] == "query":
+		ids := []string{}
+		distances := []float32{}
+		for id := range f.embeddings[path[1]] {
+			ids = append(ids, id)
+			distances = append(distances, 0.5)
+		}
+		sort.Strings(ids)
+		json.NewEncoder(w).Encode(map[string]interface{}{"ids": [][]string{ids}, "distances": [][]float32{distances}})
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+func TestChromaOnlineStore(t *testing.T) {
+	fake := newFakeChroma()
+	server := httptest.NewServer(fake)
+	defer server.Close()
+	store, err := NewChromaOnlineStore(&pc.ChromaConfig{URL: server.URL, APIKey: "key", Tenant: "team"})
+	if err != nil {
+		t.Fatalf("Failed to create store: %s", err)
+	}
+	if _, err := store.GetTable("feature", "variant"); err == nil {
+		t.Fatalf("Expected TableNotFound")
+	} else if _, notFound := err.(*TableNotFound); !notFound {
+		t.Fatalf("Expected TableNotFound, got %T: %s", err, err)
+	}
+	if fake.tenant != "team" {
+		t.Fatalf("Expected tenant to be sent, got %q", fake.tenant)
+	}
+	if _, err := store.CreateTable("feature", "variant", Int); err == nil {
+		t.Fatalf("Succeeded in creating a non-vector table")
+	}
+	vectorType := VectorType{ScalarType: Float32, Dimension: 2, IsEmbedding: true}
+	if _, err := store.CreateIndex("feature", "variant", vectorType); err != nil {
+		t.Fatalf("Failed to create index: %s", err)
+	}
+	if _, err := store.CreateIndex("feature", "variant", vectorType); err == nil {
+		t.Fatalf("Expected TableAlreadyExists")
+	} else if _, exists := err.(*TableAlreadyExists); !exists {
+		t.Fatalf("Expected TableAlreadyExists, got %T: %s", err, err)
+	}
+	if _, err := store.CreateTable("feature", "variant", vectorType); err != nil {
+		t.Fatalf("Failed to create table for index: %s", err)
+	}
+	if _, err := store.CreateTable("feature", "variant", vectorType); err == nil {
+		t.Fatalf("Expected TableAlreadyExists")
+	}
+	table, err := store.GetTable("feature", "variant")
+	if err != nil {
+		t.Fatalf("Failed to get table: %s", err)
+	}
+	if err := table.Set("a", []float32{1, 0}); err != nil {
+		t.Fatalf("Failed to set vector: %s", err)
+	}
+	if err := table.(BatchSettableTable).SetBatch(map[string]interface{}{"a": []float32{0, 1}, "b": []float32{1, 1}}); err != nil {
+		t.Fatalf("Failed to set batch: %s", err)
+	}
+	if fake.auth != "Bearer key" {
+		t.Fatalf("Expected API key to be sent, got %q", fake.auth)
+	}
+	if value, err := table.Get("a"); err != nil || !reflect.DeepEqual(value, []float32{0, 1}) {
+		t.Fatalf("Expected overwritten vector, got %v %v", value, err)
+	}
+	if _, err := table.Get("c"); err == nil {
+		t.Fatalf("Expected EntityNotFound")
+	} else if _, notFound := err.(*EntityNotFound); !notFound {
+		t.Fatalf("Expected EntityNotFound, got %T: %s", err, err)
+	}
+	entities, err := table.(VectorStoreTable).Nearest("feature", "variant", []float32{1, 1}, 2, VectorFilter{})
+	if err != nil {
+		t.Fatalf("Failed to search: %s", err)
+	}
+	if len(entities) != 2 {
+		t.Fatalf("Expected 2 entities, got %v", entities)
+	}
+	if distance := entities[0].Distance; distance < 0.4999 || distance > 0.5001 {
+		t.Fatalf("Expected cosine distance 0.5, got %v", distance)
+	}
+	stats, err := table.(IndexStatsTable).IndexStats()
+	if err != nil {
+		t.Fatalf("Failed to get index stats: %s", err)
+	}
+	if expected := (VectorIndexStats{Vectors: 2, Dimension: 2, IndexedFraction: 1}); stats != expected {
+		t.Fatalf("Expected %+v, got %+v", expected, stats)
+	}
+	if err := table.(VectorStoreTable).Delete("b"); err != nil {
+		t.Fatalf("Failed to delete vector: %s", err)
+	}
+	if err := table.(VectorStoreTable).Delete("b"); err != nil {
+		t.Fatalf("Failed to delete missing vector: %s", err)
+	}
+	if entities, err := table.(VectorStoreTable).Nearest("feature", "variant", []float32{1, 1}, 2, VectorFilter{}); err != nil || len(entities) != 1 {
+		t.Fatalf("Expected deleted vector to be removed, got %v %v", entities, err)
+	}
+	if err := store.DeleteTable("feature", "variant"); err != nil {
+		t.Fatalf("Failed to delete table: %s", err)
+	}
+	if err := store.DeleteTable("feature", "variant"); err == nil {
+		t.Fatalf("Expected TableNotFound")
+	}
+}
+
+func TestChromaWhere(t *testing.T) {
+	if where := chromaWhere(VectorFilter{}); where != nil {
+		t.Fatalf("Expected no filter, got %v", where)
+	}
+	where := chromaWhere(VectorFilter{Attributes: map[string]string{"tier": "gold"}})
+	expected := map[string]interface{}{"attribute:tier": map[string]interface{}{"$eq": "gold"}}
+	if !reflect.DeepEqual(where, expected) {
+		t.Fatalf("Expected %v, got %v", expected, where)
+	}
+	after := time.UnixMilli(1680307200000)
+	where = chromaWhere(VectorFilter{Attributes: map[string]string{"tier": "gold"}, UpdatedAfter: after})
+	conditions, ok := where["$and"].([]map[string]interface{})
+	if !ok || len(conditions) != 2 {
+		t.Fatalf("Expected two conditions combined with $and, got %v", where)
+	}
+	if !reflect.DeepEqual(conditions[1], map[string]interface{}{chromaMetaUpdatedAt: map[string]interface{}{"$gte": int64(1680307200000)}}) {
+		t.Fatalf("Expected updated after condition, got %v", conditions[1])
+	}
+	where = chromaWhere(VectorFilter{Namespace: "tenant"})
+	if expected := (map[string]interface{}{chromaMetaNamespace: map[string]interface{}{"$eq": "tenant"}}); !reflect.DeepEqual(where, expected) {
+		t.Fatalf("Expected %v, got %v", expected, where)
+	}
+}
+
+func TestChromaDistance(t *testing.T) {
+	if distance := chromaDistance(L2Distance, 4); distance != 2 {
+		t.Fatalf("Expected squared l2 distance to be converted, got %v", distance)
+	}
+	if distance := chromaDistance(InnerProductDistance, -2); distance != -3 {
+		t.Fatalf("Expected negative inner product, got %v", distance)
+	}
+	if distance := chromaDistance(CosineDistance, 0.25); distance != 0.25 {
+		t.Fatalf("Expected cosine distance to be unchanged, got %v", distance)
+	}
+}
+
+func TestChromaNearestPage(t *testing.T) {
+	server := httptest.NewServer(newFakeChroma())
+	defer server.Close()
+	store, err := NewChromaOnlineStore(&pc.ChromaConfig{URL: server.URL})
+	if err != nil {
+		t.Fatalf("Failed to create store: %s", err)
+	}
+	index, err := store.CreateIndex("feature", "variant", VectorType{ScalarType: Float32, Dimension: 2, IsEmbedding: true})
+	if err != nil {
+		t.Fatalf("Failed to create index: %s", err)
+	}
+	for _, entity := range []string{"a", "b", "c"} {
+		if err := index.Set(entity, []float32{1, 0}); err != nil {
+			t.Fatalf("Failed to set vector: %s", err)
+		}
+	}
+	seen := map[string]bool{}
+	cursor := ""
+	for pages := 0; pages == 0 || cursor != ""; pages++ {
+		if pages > 3 {
+			t.Fatalf("Expected pagination to end")
+		}
+		page, err := index.NearestPage("feature", "variant", []float32{1, 0}, 2, VectorFilter{}, cursor)
+		if err != nil {
+			t.Fatalf("Failed to get page: %s", err)
+		}
+		for _, result := range page.Results {
+			if seen[result.Entity] {
+				t.Fatalf("Entity %s returned twice", result.Entity)
+			}
+			seen[result.Entity] = true
+		}
+		cursor = page.Cursor
+	}
+	if len(seen) != 3 {
+		t.Fatalf("Expected every entity to be returned, got %v", seen)
+	}
+	if _, err := index.NearestPage("feature", "variant", []float32{1, 0}, 2, VectorFilter{}, "not a cursor"); err == nil {
+		t.Fatalf("Succeeded with an invalid cursor")
+	}
+	if _, err := index.NearestPage("feature", "variant", []float32{1, 0}, 0, VectorFilter{}, ""); err == nil {
+		t.Fatalf("Succeeded with an empty page size")
+	}
+}
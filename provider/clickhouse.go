@@ -0,0 +1,351 @@
+package provider
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"strings"
+	"time"
+
+	_ "github.com/ClickHouse/clickhouse-go/v2"
+	pc "github.com/featureform/provider/provider_config"
+	pt "github.com/featureform/provider/provider_type"
+)
+
+type clickHouseColumnType string
+
+const (
+	chInt32     clickHouseColumnType = "Int32"
+	chInt64     clickHouseColumnType = "Int64"
+	chFloat32   clickHouseColumnType = "Float32"
+	chFloat64   clickHouseColumnType = "Float64"
+	chString    clickHouseColumnType = "String"
+	chBool      clickHouseColumnType = "Bool"
+	chTimestamp clickHouseColumnType = "DateTime64(9, 'UTC')"
+)
+
+func clickHouseOfflineStoreFactory(config pc.SerializedConfig) (Provider, error) {
+	sc := pc.ClickHouseConfig{}
+	if err := sc.Deserialize(config); err != nil {
+		return nil, errors.New("invalid clickhouse config")
+	}
+	queries := clickHouseSQLQueries{}
+	queries.setVariableBinding(MySQLBindingStyle)
+	sgConfig := SQLOfflineStoreConfig{
+		Config:        config,
+		ConnectionURL: clickHouseConnectionURL(sc),
+		Driver:        "clickhouse",
+		ProviderType:  pt.ClickHouseOffline,
+		QueryImpl:     &queries,
+	}
+
+	store, err := NewSQLOfflineStore(sgConfig)
+	if err != nil {
+		return nil, err
+	}
+	return store, nil
+}
+
+// clickHouseConnectionURL returns the DSN for the config. Mutations are made
+// synchronous so that a resource table is updated by the time Write returns.
+func clickHouseConnectionURL(sc pc.ClickHouseConfig) string {
+	params := url.Values{}
+	params.Set("secure", fmt.Sprintf("%t", sc.SSL))
+	params.Set("mutations_sync", "2")
+	dsn := url.URL{
+		Scheme:   "clickhouse",
+		User:     url.UserPassword(sc.Username, sc.Password),
+		Host:     net.JoinHostPort(sc.Host, sc.Port),
+		Path:     sc.Database,
+		RawQuery: params.Encode(),
+	}
+	return dsn.String()
+}
+
+type clickHouseSQLQueries struct {
+	defaultOfflineSQLQueries
+}
+
+func (q clickHouseSQLQueries) tableExists() string {
+	return "SELECT COUNT(*) FROM system.tables WHERE database=currentDatabase() AND engine NOT IN ('View', 'MaterializedView') AND name=?"
+}
+
+func (q clickHouseSQLQueries) viewExists() string {
+	return "SELECT COUNT(*) FROM system.tables WHERE database=currentDatabase() AND engine='View' AND name=?"
+}
+
+func (q clickHouseSQLQueries) getTable() string {
+	return "SELECT name FROM system.tables WHERE database=currentDatabase() AND name=?"
+}
+
+func (q clickHouseSQLQueries) materializationApproxNumRows() string {
+	return "SELECT ifNull(total_rows, 0) FROM system.tables WHERE database=currentDatabase() AND name=?"
+}
+
+func (q clickHouseSQLQueries) materializationExists() string {
+	return q.getTable()
+}
+
+func (q clickHouseSQLQueries) transformationExists() string {
+	return q.getTable()
+}
+
+func (q clickHouseSQLQueries) getColumns(db *sql.DB, name string) ([]TableColumn, error) {
+	rows, err := db.Query("SELECT name FROM system.columns WHERE database=currentDatabase() AND table=? ORDER BY position", name)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	columnNames := make([]TableColumn, 0)
+	for rows.Next() {
+		var column string
+		if err := rows.Scan(&column); err != nil {
+			return nil, err
+		}
+		columnNames = append(columnNames, TableColumn{Name: column})
+	}
+	return columnNames, rows.Err()
+}
+
+func (q clickHouseSQLQueries) registerResources(db *sql.DB, tableName string, schema ResourceSchema, timestamp bool) error {
+	var query string
+	if timestamp {
+		ts := fmt.Sprintf("toDateTime64(%s, 9, 'UTC')", sanitize(schema.TS))
+		if schema.TSTimezone != "" {
+			// Reinterpret the wall clock time in the source's timezone before converting it to UTC.
+			ts = fmt.Sprintf("toTimeZone(toDateTime64(toString(%s), 9, '%s'), 'UTC')", sanitize(schema.TS), schema.TSTimezone)
+		}
+		query = fmt.Sprintf("CREATE VIEW %s AS SELECT %s AS entity, %s AS value, %s AS ts FROM %s", sanitize(tableName),
+			sanitize(schema.Entity), sanitize(schema.Value), ts, sanitize(schema.SourceTable))
+	} else {
+		query = fmt.Sprintf("CREATE VIEW %s AS SELECT %s AS entity, %s AS value, toDateTime64(%d, 9, 'UTC') AS ts FROM %s", sanitize(tableName),
+			sanitize(schema.Entity), sanitize(schema.Value), time.UnixMilli(0).UTC().Unix(), sanitize(schema.SourceTable))
+	}
+	if _, err := db.Exec(query); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (q clickHouseSQLQueries) primaryTableRegister(tableName string, sourceName string) string {
+	return fmt.Sprintf("CREATE VIEW %s AS SELECT * FROM %s", sanitize(tableName), sourceName)
+}
+
+func (q clickHouseSQLQueries) primaryTableCreate(name string, columnString string) string {
+	return fmt.Sprintf("CREATE TABLE %s ( %s ) ENGINE = MergeTree ORDER BY tuple()", sanitize(name), columnString)
+}
+
+// materializationSelect keeps the latest value of each entity and numbers
+// the rows so that the materialization can be iterated in segments.
+func (q clickHouseSQLQueries) materializationSelect(tableName string, sourceName string) string {
+	return fmt.Sprintf(
+		"CREATE TABLE %s ENGINE = MergeTree ORDER BY row_number AS "+
+			"SELECT entity, value, ts, row_number() OVER (ORDER BY entity) AS row_number FROM "+
+			"(SELECT entity, argMax(value, ts) AS value, max(ts) AS ts FROM %s GROUP BY entity)",
+		tableName, sanitize(sourceName))
+}
+
+func (q clickHouseSQLQueries) materializationCreate(tableName string, sourceName string) string {
+	return q.materializationSelect(sanitize(tableName), sourceName)
+}
+
+func (q clickHouseSQLQueries) materializationUpdate(db *sql.DB, tableName string, sourceName string) error {
+	tempTable := sanitize(fmt.Sprintf("tmp_%s", tableName))
+	return q.atomicUpdate(db, tableName, tempTable, q.materializationSelect(tempTable, sourceName))
+}
+
+func (q clickHouseSQLQueries) materializationDrop(tableName string) string {
+	return fmt.Sprintf("DROP TABLE %s", sanitize(tableName))
+}
+
+func (q clickHouseSQLQueries) determineColumnType(valueType ValueType) (string, error) {
+	switch valueType {
+	case Int, Int64:
+		return string(chInt64), nil
+	case Int32:
+		return string(chInt32), nil
+	case Float32:
+		return string(chFloat32), nil
+	case Float64:
+		return string(chFloat64), nil
+	case String:
+		return string(chString), nil
+	case Bool:
+		return string(chBool), nil
+	case Timestamp:
+		return string(chTimestamp), nil
+	case NilType:
+		return string(chString), nil
+	default:
+		return "", fmt.Errorf("cannot find column type for value type: %s", valueType)
+	}
+}
+
+// clickHouseResourceTypes maps the generic column types that resource tables
+// are created with to ClickHouse's types.
+var clickHouseResourceTypes = map[string]clickHouseColumnType{
+	"INT":         chInt64,
+	"FLOAT8":      chFloat64,
+	"VARCHAR":     chString,
+	"BOOLEAN":     chBool,
+	"TIMESTAMPTZ": chTimestamp,
+}
+
+func (q clickHouseSQLQueries) newSQLOfflineTable(name string, columnType string) string {
+	if chType, ok := clickHouseResourceTypes[columnType]; ok {
+		columnType = string(chType)
+	}
+	return fmt.Sprintf("CREATE TABLE %s (entity String, value Nullable(%s), ts %s) ENGINE = MergeTree ORDER BY (entity, ts)", sanitize(name), columnType, chTimestamp)
+}
+
+// writeUpdate rewrites the value with a mutation, which the connection runs
+// synchronously.
+func (q clickHouseSQLQueries) writeUpdate(table string) string {
+	return fmt.Sprintf("ALTER TABLE %s UPDATE value=? WHERE entity=? AND ts=?", table)
+}
+
+func (q clickHouseSQLQueries) writeExists(table string) string {
+	return fmt.Sprintf("SELECT COUNT(*) FROM %s WHERE entity=? AND ts=?", table)
+}
+
+func (q clickHouseSQLQueries) trainingSetCreate(store *sqlOfflineStore, def TrainingSetDef, tableName string, labelName string) error {
+	return q.trainingSetQuery(store, def, tableName, labelName, false)
+}
+
+func (q clickHouseSQLQueries) trainingSetUpdate(store *sqlOfflineStore, def TrainingSetDef, tableName string, labelName string) error {
+	return q.trainingSetQuery(store, def, tableName, labelName, true)
+}
+
+// trainingSetQuery joins each feature's latest value at or before every
+// label with an ASOF join. Lag features shift the feature's timestamps
+// forward by the lag instead of comparing against an expression, since
+// ClickHouse only allows a column in the ASOF condition.
+func (q clickHouseSQLQueries) trainingSetQuery(store *sqlOfflineStore, def TrainingSetDef, tableName string, labelName string, isUpdate bool) error {
+	columns := make([]string, 0)
+	joins := ""
+	for i, feature := range def.Features {
+		featureTable, err := store.getResourceTableName(feature)
+		if err != nil {
+			return err
+		}
+		sanitizedName := sanitize(featureTable)
+		tableJoinAlias := fmt.Sprintf("t%d", i+1)
+		columns = append(columns, fmt.Sprintf("%s.value AS %s", tableJoinAlias, sanitizedName))
+		joins = fmt.Sprintf("%s ASOF LEFT JOIN (SELECT entity, value, ts FROM %s) AS %s ON t0.entity=%s.entity AND t0.ts>=%s.ts",
+			joins, sanitizedName, tableJoinAlias, tableJoinAlias, tableJoinAlias)
+	}
+	for i, lagFeature := range def.LagFeatures {
+		lagFeaturesOffset := len(def.Features)
+		featureTable, err := store.getResourceTableName(ResourceID{lagFeature.FeatureName, lagFeature.FeatureVariant, Feature})
+		if err != nil {
+			return err
+		}
+		lagColumnName := sanitize(lagFeature.LagName)
+		if lagFeature.LagName == "" {
+			lagColumnName = sanitize(fmt.Sprintf("%s_lag_%s", featureTable, lagFeature.LagDelta))
+		}
+		tableJoinAlias := fmt.Sprintf("t%d", lagFeaturesOffset+i+1)
+		columns = append(columns, fmt.Sprintf("%s.value AS %s", tableJoinAlias, lagColumnName))
+		joins = fmt.Sprintf("%s ASOF LEFT JOIN (SELECT entity, value, ts + toIntervalSecond(%d) AS ts FROM %s) AS %s ON t0.entity=%s.entity AND t0.ts>=%s.ts",
+			joins, int64(lagFeature.LagDelta.Seconds()), sanitize(featureTable), tableJoinAlias, tableJoinAlias, tableJoinAlias)
+	}
+	columnStr := strings.Join(columns, ", ")
+	create := func(name string) string {
+		return fmt.Sprintf(
+			"CREATE TABLE %s ENGINE = MergeTree ORDER BY tuple() AS "+
+				"SELECT %s, t0.value AS label FROM %s AS t0%s SETTINGS join_use_nulls=1",
+			name, columnStr, sanitize(labelName), joins)
+	}
+	if !isUpdate {
+		if _, err := store.db.Exec(create(sanitize(tableName))); err != nil {
+			return err
+		}
+		return nil
+	}
+	tempTable := sanitize(fmt.Sprintf("tmp_%s", tableName))
+	return q.atomicUpdate(store.db, tableName, tempTable, create(tempTable))
+}
+
+// atomicUpdate builds the new table next to the old one and swaps them with
+// EXCHANGE TABLES, which requires the Atomic database engine. ClickHouse
+// doesn't support transactions, so each statement is run on its own.
+func (q clickHouseSQLQueries) atomicUpdate(db *sql.DB, tableName string, tempName string, query string) error {
+	statements := []string{
+		fmt.Sprintf("DROP TABLE IF EXISTS %s", tempName),
+		query,
+		fmt.Sprintf("EXCHANGE TABLES %s AND %s", sanitize(tableName), tempName),
+		fmt.Sprintf("DROP TABLE %s", tempName),
+	}
+	for _, statement := range statements {
+		if _, err := db.Exec(statement); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (q clickHouseSQLQueries) castTableItemType(v interface{}, t interface{}) interface{} {
+	if v == nil {
+		return v
+	}
+	switch t {
+	case chInt32:
+		return v.(int32)
+	case chInt64:
+		return int(v.(int64))
+	case chFloat32:
+		return v.(float32)
+	case chFloat64:
+		return v.(float64)
+	case chString:
+		return v.(string)
+	case chBool:
+		return v.(bool)
+	case chTimestamp:
+		return v.(time.Time).UTC()
+	default:
+		return v
+	}
+}
+
+func (q clickHouseSQLQueries) getValueColumnType(t *sql.ColumnType) interface{} {
+	name := strings.TrimSuffix(strings.TrimPrefix(t.DatabaseTypeName(), "Nullable("), ")")
+	switch {
+	case name == "Int32":
+		return chInt32
+	case name == "Int64":
+		return chInt64
+	case name == "Float32":
+		return chFloat32
+	case name == "Float64":
+		return chFloat64
+	case name == "Bool":
+		return chBool
+	case strings.HasPrefix(name, "DateTime"):
+		return chTimestamp
+	}
+	return chString
+}
+
+func (q clickHouseSQLQueries) numRows(n interface{}) (int64, error) {
+	switch count := n.(type) {
+	case uint64:
+		return int64(count), nil
+	case int64:
+		return count, nil
+	default:
+		return 0, fmt.Errorf("unexpected row count type %T", n)
+	}
+}
+
+func (q clickHouseSQLQueries) transformationCreate(name string, query string) string {
+	return fmt.Sprintf("CREATE TABLE %s ENGINE = MergeTree ORDER BY tuple() AS %s", sanitize(name), query)
+}
+
+func (q clickHouseSQLQueries) transformationUpdate(db *sql.DB, tableName string, query string) error {
+	tempName := sanitize(fmt.Sprintf("tmp_%s", tableName))
+	fullQuery := fmt.Sprintf("CREATE TABLE %s ENGINE = MergeTree ORDER BY tuple() AS SELECT * FROM ( %s )", tempName, query)
+	return q.atomicUpdate(db, tableName, tempName, fullQuery)
+}
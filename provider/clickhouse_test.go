@@ -0,0 +1,63 @@
+package provider
+
+import (
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	pc "github.com/featureform/provider/provider_config"
+)
+
+func TestClickHouseConnectionURL(t *testing.T) {
+	dsn := clickHouseConnectionURL(pc.ClickHouseConfig{
+		Host:     "localhost",
+		Port:     "9440",
+		Username: "default",
+		Password: "p@ss/word",
+		Database: "featureform",
+		SSL:      true,
+	})
+	parsed, err := url.Parse(dsn)
+	if err != nil {
+		t.Fatalf("Failed to parse DSN %s: %s", dsn, err)
+	}
+	if parsed.Host != "localhost:9440" || parsed.Path != "/featureform" {
+		t.Fatalf("Unexpected address in DSN %s", dsn)
+	}
+	if password, _ := parsed.User.Password(); password != "p@ss/word" {
+		t.Fatalf("Password not escaped in DSN %s", dsn)
+	}
+	if parsed.Query().Get("secure") != "true" || parsed.Query().Get("mutations_sync") != "2" {
+		t.Fatalf("Unexpected settings in DSN %s", dsn)
+	}
+}
+
+func TestClickHouseSQLQueries(t *testing.T) {
+	q := clickHouseSQLQueries{}
+	for _, valueType := range []ValueType{Int, Int32, Int64, Float32, Float64, String, Bool, Timestamp, NilType} {
+		if _, err := q.determineColumnType(valueType); err != nil {
+			t.Fatalf("Failed to get column type for %s: %s", valueType, err)
+		}
+	}
+	if _, err := q.determineColumnType(VectorType{ScalarType: Float32, Dimension: 3}); err == nil {
+		t.Fatalf("Expected error for unknown value type")
+	}
+	if table := q.newSQLOfflineTable("resource", "TIMESTAMPTZ"); !strings.Contains(table, "value Nullable(DateTime64(9, 'UTC'))") {
+		t.Fatalf("Expected generic column type to be mapped: %s", table)
+	}
+	query := q.materializationCreate("mat", "source")
+	if !strings.Contains(query, "argMax(value, ts)") || !strings.Contains(query, "ORDER BY row_number") {
+		t.Fatalf("Unexpected materialization query: %s", query)
+	}
+	if q.castTableItemType(int64(3), chInt64) != 3 {
+		t.Fatalf("Expected Int64 values to be cast to int")
+	}
+	ts := time.Date(2023, 1, 1, 0, 0, 0, 0, time.FixedZone("PST", -8*60*60))
+	if q.castTableItemType(ts, chTimestamp) != ts.UTC() {
+		t.Fatalf("Expected timestamps to be converted to UTC")
+	}
+	if n, err := q.numRows(uint64(5)); err != nil || n != 5 {
+		t.Fatalf("Expected 5 rows, got %d: %v", n, err)
+	}
+}
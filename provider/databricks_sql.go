@@ -0,0 +1,317 @@
+package provider
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	pc "github.com/featureform/provider/provider_config"
+	pt "github.com/featureform/provider/provider_type"
+)
+
+type databricksSQLColumnType string
+
+const (
+	dbsInt       databricksSQLColumnType = "INT"
+	dbsBigInt    databricksSQLColumnType = "BIGINT"
+	dbsFloat     databricksSQLColumnType = "FLOAT"
+	dbsDouble    databricksSQLColumnType = "DOUBLE"
+	dbsString    databricksSQLColumnType = "STRING"
+	dbsBool      databricksSQLColumnType = "BOOLEAN"
+	dbsTimestamp databricksSQLColumnType = "TIMESTAMP"
+)
+
+func databricksSQLOfflineStoreFactory(config pc.SerializedConfig) (Provider, error) {
+	sc := pc.DatabricksSQLConfig{}
+	if err := sc.Deserialize(config); err != nil {
+		return nil, errors.New("invalid databricks sql config")
+	}
+	if sc.WarehouseID == "" {
+		return nil, errors.New("databricks sql config requires a WarehouseID to run queries on")
+	}
+	if sc.Catalog == "" {
+		return nil, errors.New("databricks sql config requires a Unity Catalog Catalog to keep tables in")
+	}
+	if sc.Schema == "" {
+		sc.Schema = "default"
+	}
+	queries := databricksSQLQueries{
+		Catalog: sc.Catalog,
+		Schema:  sc.Schema,
+	}
+	queries.setVariableBinding(MySQLBindingStyle)
+	sgConfig := SQLOfflineStoreConfig{
+		Config:        config,
+		ConnectionURL: string(sc.Serialize()),
+		Driver:        databricksSQLDriverName,
+		ProviderType:  pt.DatabricksSQLOffline,
+		QueryImpl:     &queries,
+	}
+
+	store, err := NewSQLOfflineStore(sgConfig)
+	if err != nil {
+		return nil, err
+	}
+	return store, nil
+}
+
+// databricksSQLQueries keeps its tables as Delta tables in a Unity Catalog
+// schema, which every statement runs in. Tables are replaced atomically with
+// CREATE OR REPLACE TABLE. Unity Catalog stores names in lowercase.
+type databricksSQLQueries struct {
+	defaultOfflineSQLQueries
+	Catalog string
+	Schema  string
+}
+
+// informationSchema returns the catalog's information schema, which only
+// lists the tables and columns in that catalog.
+func (q databricksSQLQueries) informationSchema(relation string) string {
+	return fmt.Sprintf("`%s`.information_schema.%s", strings.ReplaceAll(q.Catalog, "`", "``"), relation)
+}
+
+func (q databricksSQLQueries) tableExists() string {
+	return fmt.Sprintf("SELECT COUNT(*) FROM %s WHERE table_schema=%s AND table_type<>'VIEW' AND table_name=lower(?)",
+		q.informationSchema("tables"), databricksSQLString(q.Schema))
+}
+
+func (q databricksSQLQueries) viewExists() string {
+	return fmt.Sprintf("SELECT COUNT(*) FROM %s WHERE table_schema=%s AND table_type='VIEW' AND table_name=lower(?)",
+		q.informationSchema("tables"), databricksSQLString(q.Schema))
+}
+
+func (q databricksSQLQueries) getTable() string {
+	return fmt.Sprintf("SELECT table_name FROM %s WHERE table_schema=%s AND table_name=lower(?)",
+		q.informationSchema("tables"), databricksSQLString(q.Schema))
+}
+
+func (q databricksSQLQueries) materializationExists() string {
+	return q.getTable()
+}
+
+func (q databricksSQLQueries) transformationExists() string {
+	return q.getTable()
+}
+
+func (q databricksSQLQueries) getColumns(db *sql.DB, name string) ([]TableColumn, error) {
+	qry := fmt.Sprintf("SELECT column_name FROM %s WHERE table_schema=%s AND table_name=lower(?) ORDER BY ordinal_position",
+		q.informationSchema("columns"), databricksSQLString(q.Schema))
+	rows, err := db.Query(qry, name)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	columnNames := make([]TableColumn, 0)
+	for rows.Next() {
+		var column string
+		if err := rows.Scan(&column); err != nil {
+			return nil, err
+		}
+		columnNames = append(columnNames, TableColumn{Name: column})
+	}
+	return columnNames, rows.Err()
+}
+
+func (q databricksSQLQueries) getValueColumnTypes(tableName string) string {
+	return fmt.Sprintf("SELECT * FROM %s LIMIT 0", sanitize(tableName))
+}
+
+func (q databricksSQLQueries) registerResources(db *sql.DB, tableName string, schema ResourceSchema, timestamp bool) error {
+	var query string
+	if timestamp {
+		ts := fmt.Sprintf("CAST(%s AS TIMESTAMP)", sanitize(schema.TS))
+		if schema.TSTimezone != "" {
+			ts = fmt.Sprintf("to_utc_timestamp(CAST(%s AS TIMESTAMP), %s)", sanitize(schema.TS), databricksSQLString(schema.TSTimezone))
+		}
+		query = fmt.Sprintf("CREATE VIEW %s AS SELECT %s as entity, %s as value, %s as ts FROM %s", sanitize(tableName),
+			sanitize(schema.Entity), sanitize(schema.Value), ts, sanitize(schema.SourceTable))
+	} else {
+		query = fmt.Sprintf("CREATE VIEW %s AS SELECT %s as entity, %s as value, TIMESTAMP '%s' as ts FROM %s", sanitize(tableName),
+			sanitize(schema.Entity), sanitize(schema.Value), time.UnixMilli(0).UTC().Format(databricksSQLTimeLayout), sanitize(schema.SourceTable))
+	}
+	if _, err := db.Exec(query); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (q databricksSQLQueries) primaryTableRegister(tableName string, sourceName string) string {
+	return fmt.Sprintf("CREATE VIEW %s AS SELECT * FROM %s", sanitize(tableName), sourceName)
+}
+
+// replaceTable atomically replaces a table, or creates it, with the results
+// of a query.
+func (q databricksSQLQueries) replaceTable(db *sql.DB, tableName string, query string) error {
+	_, err := db.Exec(fmt.Sprintf("CREATE OR REPLACE TABLE %s AS %s", sanitize(tableName), query))
+	return err
+}
+
+func (q databricksSQLQueries) materializationSelect(sourceName string) string {
+	return fmt.Sprintf(
+		"SELECT entity, value, ts, row_number() over(ORDER BY entity) as row_number FROM "+
+			"(SELECT entity, ts, value, row_number() OVER (PARTITION BY entity ORDER BY ts desc) "+
+			"AS rn FROM %s) t WHERE rn=1", sanitize(sourceName))
+}
+
+func (q databricksSQLQueries) materializationCreate(tableName string, sourceName string) string {
+	return fmt.Sprintf("CREATE TABLE %s AS %s", sanitize(tableName), q.materializationSelect(sourceName))
+}
+
+func (q databricksSQLQueries) materializationUpdate(db *sql.DB, tableName string, sourceName string) error {
+	return q.replaceTable(db, tableName, q.materializationSelect(sourceName))
+}
+
+func (q databricksSQLQueries) materializationDrop(tableName string) string {
+	return fmt.Sprintf("DROP TABLE %s", sanitize(tableName))
+}
+
+func (q databricksSQLQueries) determineColumnType(valueType ValueType) (string, error) {
+	switch valueType {
+	case Int, Int64:
+		return string(dbsBigInt), nil
+	case Int32:
+		return string(dbsInt), nil
+	case Float32:
+		return string(dbsFloat), nil
+	case Float64:
+		return string(dbsDouble), nil
+	case String:
+		return string(dbsString), nil
+	case Bool:
+		return string(dbsBool), nil
+	case Timestamp:
+		return string(dbsTimestamp), nil
+	case NilType:
+		return string(dbsString), nil
+	default:
+		return "", fmt.Errorf("cannot find column type for value type: %s", valueType)
+	}
+}
+
+// databricksSQLResourceTypes maps the generic column types that resource
+// tables are created with to Databricks' types.
+var databricksSQLResourceTypes = map[string]databricksSQLColumnType{
+	"INT":         dbsBigInt,
+	"FLOAT8":      dbsDouble,
+	"VARCHAR":     dbsString,
+	"TIMESTAMPTZ": dbsTimestamp,
+}
+
+func (q databricksSQLQueries) newSQLOfflineTable(name string, columnType string) string {
+	if dbsType, ok := databricksSQLResourceTypes[columnType]; ok {
+		columnType = string(dbsType)
+	}
+	return fmt.Sprintf("CREATE TABLE %s (entity STRING, value %s, ts TIMESTAMP)", sanitize(name), columnType)
+}
+
+func (q databricksSQLQueries) writeExists(table string) string {
+	return fmt.Sprintf("SELECT COUNT(*) FROM %s WHERE entity=? AND ts=?", table)
+}
+
+func (q databricksSQLQueries) trainingSetCreate(store *sqlOfflineStore, def TrainingSetDef, tableName string, labelName string) error {
+	return q.trainingSetQuery(store, def, tableName, labelName, false)
+}
+
+func (q databricksSQLQueries) trainingSetUpdate(store *sqlOfflineStore, def TrainingSetDef, tableName string, labelName string) error {
+	return q.trainingSetQuery(store, def, tableName, labelName, true)
+}
+
+// trainingSetQuery finds each feature's latest value at or before every
+// label in its own CTE and joins them back to the labels, since Databricks
+// doesn't have ASOF joins.
+func (q databricksSQLQueries) trainingSetQuery(store *sqlOfflineStore, def TrainingSetDef, tableName string, labelName string, isUpdate bool) error {
+	ctes := []string{fmt.Sprintf("l AS (SELECT DISTINCT entity, value, ts FROM %s)", sanitize(labelName))}
+	columns := make([]string, 0)
+	joins := ""
+	addFeature := func(featureTable string, columnName string, lag time.Duration) {
+		alias := fmt.Sprintf("t%d", len(columns)+1)
+		lagOffset := ""
+		if lag != 0 {
+			lagOffset = fmt.Sprintf(" - INTERVAL %d MILLISECONDS", lag.Milliseconds())
+		}
+		ctes = append(ctes, fmt.Sprintf("%s AS (SELECT l.entity, l.value, l.ts, max_by(f.value, f.ts) AS value_at_ts FROM l "+
+			"LEFT JOIN %s f ON f.entity=l.entity AND f.ts<=l.ts%s GROUP BY l.entity, l.value, l.ts)", alias, sanitize(featureTable), lagOffset))
+		columns = append(columns, fmt.Sprintf("%s.value_at_ts AS %s", alias, columnName))
+		joins = fmt.Sprintf("%s LEFT JOIN %s ON %s.entity<=>l.entity AND %s.value<=>l.value AND %s.ts<=>l.ts", joins, alias, alias, alias, alias)
+	}
+	for _, feature := range def.Features {
+		featureTable, err := store.getResourceTableName(feature)
+		if err != nil {
+			return err
+		}
+		addFeature(featureTable, sanitize(featureTable), 0)
+	}
+	for _, lagFeature := range def.LagFeatures {
+		featureTable, err := store.getResourceTableName(ResourceID{lagFeature.FeatureName, lagFeature.FeatureVariant, Feature})
+		if err != nil {
+			return err
+		}
+		lagColumnName := sanitize(lagFeature.LagName)
+		if lagFeature.LagName == "" {
+			lagColumnName = sanitize(fmt.Sprintf("%s_lag_%s", featureTable, lagFeature.LagDelta))
+		}
+		addFeature(featureTable, lagColumnName, lagFeature.LagDelta)
+	}
+	query := fmt.Sprintf("WITH %s SELECT %s, l.value AS label FROM l%s", strings.Join(ctes, ", "), strings.Join(columns, ", "), joins)
+	if isUpdate {
+		return q.replaceTable(store.db, tableName, query)
+	}
+	_, err := store.db.Exec(fmt.Sprintf("CREATE TABLE %s AS %s", sanitize(tableName), query))
+	return err
+}
+
+func (q databricksSQLQueries) castTableItemType(v interface{}, t interface{}) interface{} {
+	if v == nil {
+		return v
+	}
+	switch t {
+	case dbsInt, dbsBigInt:
+		return int(v.(int64))
+	case dbsFloat:
+		return float32(v.(float64))
+	case dbsDouble:
+		return v.(float64)
+	case dbsString:
+		return v.(string)
+	case dbsBool:
+		return v.(bool)
+	case dbsTimestamp:
+		return v.(time.Time).UTC()
+	default:
+		return v
+	}
+}
+
+// getValueColumnType maps the type names that the Statement Execution API
+// returns, which aren't always the SQL names, to column types.
+func (q databricksSQLQueries) getValueColumnType(t *sql.ColumnType) interface{} {
+	switch t.DatabaseTypeName() {
+	case "BYTE", "SHORT", "INT":
+		return dbsInt
+	case "LONG":
+		return dbsBigInt
+	case "FLOAT":
+		return dbsFloat
+	case "DOUBLE":
+		return dbsDouble
+	case "BOOLEAN":
+		return dbsBool
+	case "TIMESTAMP", "TIMESTAMP_NTZ", "DATE":
+		return dbsTimestamp
+	}
+	return dbsString
+}
+
+func (q databricksSQLQueries) numRows(n interface{}) (int64, error) {
+	return n.(int64), nil
+}
+
+func (q databricksSQLQueries) transformationCreate(name string, query string) string {
+	return fmt.Sprintf("CREATE TABLE %s AS %s", sanitize(name), query)
+}
+
+func (q databricksSQLQueries) transformationUpdate(db *sql.DB, tableName string, query string) error {
+	return q.replaceTable(db, tableName, query)
+}
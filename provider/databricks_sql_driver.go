@@ -0,0 +1,376 @@
+package provider
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	pc "github.com/featureform/provider/provider_config"
+)
+
+const (
+	databricksSQLDriverName   = "databricks-sql"
+	databricksSQLPollInterval = 500 * time.Millisecond
+	// databricksSQLWaitTimeout is how long a statement request waits for the
+	// statement to finish before it's polled instead.
+	databricksSQLWaitTimeout = "30s"
+	databricksSQLTimeLayout  = "2006-01-02 15:04:05.999999Z07:00"
+	databricksStatementsPath = "/api/2.0/sql/statements"
+)
+
+func init() {
+	sql.Register(databricksSQLDriverName, databricksSQLDriver{})
+}
+
+// databricksSQLDriver is a database/sql driver that runs each statement on a
+// SQL warehouse with the Statement Execution API. Its data source name is a
+// serialized DatabricksSQLConfig. Arguments are bound as literals before the
+// statement is sent, and results are returned inline, so a single result is
+// limited to the API's 25 MiB.
+//
+// Databricks quotes names with backticks and reads double-quoted text as a
+// string, so the double-quoted names that the SQL offline store generates
+// are requoted with backticks.
+type databricksSQLDriver struct{}
+
+func (databricksSQLDriver) Open(dsn string) (driver.Conn, error) {
+	config := pc.DatabricksSQLConfig{}
+	if err := config.Deserialize(pc.SerializedConfig(dsn)); err != nil {
+		return nil, fmt.Errorf("invalid databricks sql config: %w", err)
+	}
+	header := make(http.Header)
+	header.Set("Authorization", fmt.Sprintf("Bearer %s", config.Token))
+	client := newJSONHTTPClient(fmt.Sprintf("https://%s", config.Host), header, nil)
+	return &databricksSQLConn{client: client, config: config}, nil
+}
+
+type databricksStatementRequest struct {
+	Statement     string `json:"statement"`
+	WarehouseID   string `json:"warehouse_id"`
+	Catalog       string `json:"catalog,omitempty"`
+	Schema        string `json:"schema,omitempty"`
+	WaitTimeout   string `json:"wait_timeout"`
+	OnWaitTimeout string `json:"on_wait_timeout"`
+	Disposition   string `json:"disposition"`
+	Format        string `json:"format"`
+}
+
+type databricksStatement struct {
+	StatementID string `json:"statement_id"`
+	Status      struct {
+		State string `json:"state"`
+		Error *struct {
+			ErrorCode string `json:"error_code"`
+			Message   string `json:"message"`
+		} `json:"error"`
+	} `json:"status"`
+	Manifest *struct {
+		Schema struct {
+			Columns []databricksColumn `json:"columns"`
+		} `json:"schema"`
+	} `json:"manifest"`
+	Result *databricksResultChunk `json:"result"`
+}
+
+type databricksColumn struct {
+	Name     string `json:"name"`
+	TypeName string `json:"type_name"`
+}
+
+// databricksResultChunk is a chunk of rows in the JSON_ARRAY format, where
+// every value is a string or null.
+type databricksResultChunk struct {
+	DataArray             [][]*string `json:"data_array"`
+	NextChunkInternalLink string      `json:"next_chunk_internal_link"`
+}
+
+type databricksSQLConn struct {
+	client *jsonHTTPClient
+	config pc.DatabricksSQLConfig
+}
+
+func (c *databricksSQLConn) Prepare(query string) (driver.Stmt, error) {
+	return &databricksSQLStmt{conn: c, query: query}, nil
+}
+
+func (c *databricksSQLConn) Close() error {
+	return nil
+}
+
+func (c *databricksSQLConn) Begin() (driver.Tx, error) {
+	return nil, errors.New("databricks sql doesn't support transactions")
+}
+
+func (c *databricksSQLConn) ExecContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	if _, err := c.run(ctx, query, namedValues(args)); err != nil {
+		return nil, err
+	}
+	return driver.ResultNoRows, nil
+}
+
+func (c *databricksSQLConn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	statement, err := c.run(ctx, query, namedValues(args))
+	if err != nil {
+		return nil, err
+	}
+	rows := &databricksSQLRows{ctx: ctx, client: c.client}
+	if statement.Manifest != nil {
+		rows.columns = statement.Manifest.Schema.Columns
+	}
+	if statement.Result != nil {
+		rows.chunk = *statement.Result
+	}
+	return rows, nil
+}
+
+// run sends the statement and waits for it to finish. The statement is
+// canceled if ctx is done first.
+func (c *databricksSQLConn) run(ctx context.Context, query string, args []driver.Value) (*databricksStatement, error) {
+	bound, err := databricksSQLBind(query, args)
+	if err != nil {
+		return nil, err
+	}
+	request := databricksStatementRequest{
+		Statement:     bound,
+		WarehouseID:   c.config.WarehouseID,
+		Catalog:       c.config.Catalog,
+		Schema:        c.config.Schema,
+		WaitTimeout:   databricksSQLWaitTimeout,
+		OnWaitTimeout: "CONTINUE",
+		Disposition:   "INLINE",
+		Format:        "JSON_ARRAY",
+	}
+	statement := &databricksStatement{}
+	if err := c.client.do(ctx, http.MethodPost, databricksStatementsPath, request, statement); err != nil {
+		return nil, err
+	}
+	for {
+		switch statement.Status.State {
+		case "SUCCEEDED":
+			return statement, nil
+		case "FAILED", "CANCELED", "CLOSED":
+			message := ""
+			if statement.Status.Error != nil {
+				message = statement.Status.Error.Message
+			}
+			return nil, fmt.Errorf("databricks statement %s %s: %s", statement.StatementID, strings.ToLower(statement.Status.State), message)
+		}
+		select {
+		case <-ctx.Done():
+			cancelPath := fmt.Sprintf("%s/%s/cancel", databricksStatementsPath, statement.StatementID)
+			c.client.do(context.Background(), http.MethodPost, cancelPath, nil, nil)
+			return nil, ctx.Err()
+		case <-time.After(databricksSQLPollInterval):
+		}
+		id := statement.StatementID
+		statement = &databricksStatement{}
+		if err := c.client.do(ctx, http.MethodGet, fmt.Sprintf("%s/%s", databricksStatementsPath, id), nil, statement); err != nil {
+			return nil, err
+		}
+	}
+}
+
+type databricksSQLStmt struct {
+	conn  *databricksSQLConn
+	query string
+}
+
+func (s *databricksSQLStmt) Close() error {
+	return nil
+}
+
+func (s *databricksSQLStmt) NumInput() int {
+	return -1
+}
+
+func (s *databricksSQLStmt) Exec(args []driver.Value) (driver.Result, error) {
+	if _, err := s.conn.run(context.Background(), s.query, args); err != nil {
+		return nil, err
+	}
+	return driver.ResultNoRows, nil
+}
+
+func (s *databricksSQLStmt) Query(args []driver.Value) (driver.Rows, error) {
+	named := make([]driver.NamedValue, len(args))
+	for i, arg := range args {
+		named[i] = driver.NamedValue{Ordinal: i + 1, Value: arg}
+	}
+	return s.conn.QueryContext(context.Background(), s.query, named)
+}
+
+// databricksSQLBind replaces each ? outside of quoted strings and names with
+// the next argument as a SQL literal, and requotes double-quoted names with
+// backticks. Strings escape quotes with backslashes.
+func databricksSQLBind(query string, args []driver.Value) (string, error) {
+	var bound strings.Builder
+	next := 0
+	var quote rune
+	escaped := false
+	for _, r := range query {
+		switch {
+		case quote == '\'':
+			if escaped {
+				escaped = false
+			} else if r == '\\' {
+				escaped = true
+			} else if r == '\'' {
+				quote = 0
+			}
+		case quote == '"':
+			switch r {
+			case '"':
+				quote = 0
+				r = '`'
+			case '`':
+				bound.WriteRune('`')
+			}
+		case quote == '`':
+			if r == '`' {
+				quote = 0
+			}
+		case r == '\'' || r == '`':
+			quote = r
+		case r == '"':
+			quote = r
+			r = '`'
+		case r == '?':
+			if next >= len(args) {
+				return "", fmt.Errorf("databricks statement has more placeholders than the %d arguments", len(args))
+			}
+			literal, err := databricksSQLLiteral(args[next])
+			if err != nil {
+				return "", err
+			}
+			bound.WriteString(literal)
+			next++
+			continue
+		}
+		bound.WriteRune(r)
+	}
+	if next != len(args) {
+		return "", fmt.Errorf("databricks statement has %d placeholders for %d arguments", next, len(args))
+	}
+	return bound.String(), nil
+}
+
+func databricksSQLString(value string) string {
+	escaped := strings.NewReplacer(`\`, `\\`, `'`, `\'`).Replace(value)
+	return fmt.Sprintf("'%s'", escaped)
+}
+
+func databricksSQLLiteral(v driver.Value) (string, error) {
+	switch value := v.(type) {
+	case nil:
+		return "NULL", nil
+	case string:
+		return databricksSQLString(value), nil
+	case int64:
+		return strconv.FormatInt(value, 10), nil
+	case float64:
+		return fmt.Sprintf("CAST('%s' AS DOUBLE)", strconv.FormatFloat(value, 'g', -1, 64)), nil
+	case bool:
+		return strings.ToUpper(strconv.FormatBool(value)), nil
+	case time.Time:
+		return fmt.Sprintf("TIMESTAMP '%s'", value.UTC().Format(databricksSQLTimeLayout)), nil
+	case []byte:
+		return fmt.Sprintf("X'%s'", hex.EncodeToString(value)), nil
+	default:
+		return "", fmt.Errorf("databricks sql can't bind argument of type %T", v)
+	}
+}
+
+type databricksSQLRows struct {
+	ctx     context.Context
+	client  *jsonHTTPClient
+	columns []databricksColumn
+	chunk   databricksResultChunk
+	next    int
+}
+
+func (r *databricksSQLRows) Columns() []string {
+	names := make([]string, len(r.columns))
+	for i, column := range r.columns {
+		names[i] = column.Name
+	}
+	return names
+}
+
+func (r *databricksSQLRows) ColumnTypeDatabaseTypeName(index int) string {
+	return strings.ToUpper(r.columns[index].TypeName)
+}
+
+func (r *databricksSQLRows) Close() error {
+	return nil
+}
+
+func (r *databricksSQLRows) Next(dest []driver.Value) error {
+	for r.next >= len(r.chunk.DataArray) {
+		if r.chunk.NextChunkInternalLink == "" {
+			return io.EOF
+		}
+		chunk := databricksResultChunk{}
+		if err := r.client.do(r.ctx, http.MethodGet, r.chunk.NextChunkInternalLink, nil, &chunk); err != nil {
+			return err
+		}
+		r.chunk = chunk
+		r.next = 0
+	}
+	row := r.chunk.DataArray[r.next]
+	r.next++
+	for i := range dest {
+		var value *string
+		if i < len(row) {
+			value = row[i]
+		}
+		parsed, err := databricksSQLValue(value, r.columns[i].TypeName)
+		if err != nil {
+			return err
+		}
+		dest[i] = parsed
+	}
+	return nil
+}
+
+// databricksSQLValue parses a value from its string representation in the
+// JSON_ARRAY format.
+func databricksSQLValue(value *string, typeName string) (driver.Value, error) {
+	if value == nil {
+		return nil, nil
+	}
+	switch strings.ToUpper(typeName) {
+	case "BYTE", "SHORT", "INT", "LONG":
+		return strconv.ParseInt(*value, 10, 64)
+	case "FLOAT", "DOUBLE":
+		return strconv.ParseFloat(*value, 64)
+	case "BOOLEAN":
+		return strconv.ParseBool(*value)
+	case "TIMESTAMP", "TIMESTAMP_NTZ":
+		return parseDatabricksTimestamp(*value)
+	case "DATE":
+		return time.Parse("2006-01-02", *value)
+	default:
+		return *value, nil
+	}
+}
+
+// parseDatabricksTimestamp parses ISO 8601 timestamps. Timestamps without a
+// time zone are UTC.
+func parseDatabricksTimestamp(value string) (time.Time, error) {
+	layouts := []string{time.RFC3339Nano, "2006-01-02T15:04:05.999999999", "2006-01-02 15:04:05.999999999"}
+	var err error
+	for _, layout := range layouts {
+		var ts time.Time
+		if ts, err = time.Parse(layout, value); err == nil {
+			return ts.UTC(), nil
+		}
+	}
+	return time.Time{}, err
+}
@@ -0,0 +1,171 @@
+package provider
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	pc "github.com/featureform/provider/provider_config"
+)
+
+// fakeStatementAPI runs every statement asynchronously: it's pending when
+// it's sent and finishes the first time it's polled.
+type fakeStatementAPI struct {
+	statements []databricksStatementRequest
+	fail       bool
+	result     string
+}
+
+func (f *fakeStatementAPI) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Header.Get("Authorization") != "Bearer dapi-token" {
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+	switch {
+	case r.Method == http.MethodPost && r.URL.Path == databricksStatementsPath:
+		request := databricksStatementRequest{}
+		json.NewDecoder(r.Body).Decode(&request)
+		f.statements = append(f.statements, request)
+		w.Write([]byte(`{"statement_id": "stmt", "status": {"state": "PENDING"}}`))
+	case r.Method == http.MethodGet && r.URL.Path == databricksStatementsPath+"/stmt":
+		if f.fail {
+			w.Write([]byte(`{"statement_id": "stmt", "status": {"state": "FAILED", "error": {"message": "TABLE_OR_VIEW_NOT_FOUND"}}}`))
+			return
+		}
+		w.Write([]byte(f.result))
+	case r.Method == http.MethodGet && r.URL.Path == databricksStatementsPath+"/stmt/result/chunks/1":
+		w.Write([]byte(`{"data_array": [["b", null, "2023-01-02T00:00:00.500Z"]]}`))
+	default:
+		w.WriteHeader(http.StatusNotFound)
+	}
+}
+
+type fakeDatabricksSQLConnector struct {
+	conn *databricksSQLConn
+}
+
+func (c fakeDatabricksSQLConnector) Connect(context.Context) (driver.Conn, error) {
+	return c.conn, nil
+}
+
+func (c fakeDatabricksSQLConnector) Driver() driver.Driver {
+	return databricksSQLDriver{}
+}
+
+func TestDatabricksSQLBind(t *testing.T) {
+	ts := time.Date(2023, 1, 2, 3, 4, 5, 6000000, time.UTC)
+	query, err := databricksSQLBind("SELECT 'it\\'s ?', \"a?\" FROM `b?` WHERE entity=? AND ts=? AND value=? AND b=? AND n=?",
+		[]driver.Value{"o'brien\\", ts, 1.5, true, nil})
+	if err != nil {
+		t.Fatalf("Failed to bind: %s", err)
+	}
+	expected := "SELECT 'it\\'s ?', `a?` FROM `b?` WHERE entity='o\\'brien\\\\' AND ts=TIMESTAMP '2023-01-02 03:04:05.006Z' " +
+		"AND value=CAST('1.5' AS DOUBLE) AND b=TRUE AND n=NULL"
+	if query != expected {
+		t.Fatalf("Expected %s, got %s", expected, query)
+	}
+	if _, err := databricksSQLBind("SELECT ?", nil); err == nil {
+		t.Fatalf("Expected error for missing argument")
+	}
+	if _, err := databricksSQLBind("SELECT 1", []driver.Value{int64(1)}); err == nil {
+		t.Fatalf("Expected error for extra argument")
+	}
+}
+
+func TestDatabricksSQLDriverQuery(t *testing.T) {
+	api := &fakeStatementAPI{result: `{
+		"statement_id": "stmt",
+		"status": {"state": "SUCCEEDED"},
+		"manifest": {"schema": {"columns": [
+			{"name": "entity", "type_name": "STRING"},
+			{"name": "value", "type_name": "LONG"},
+			{"name": "ts", "type_name": "TIMESTAMP"}
+		]}},
+		"result": {
+			"data_array": [["a", "1", "2023-01-01T00:00:00Z"]],
+			"next_chunk_internal_link": "/api/2.0/sql/statements/stmt/result/chunks/1"
+		}
+	}`}
+	server := httptest.NewServer(api)
+	defer server.Close()
+	header := make(http.Header)
+	header.Set("Authorization", "Bearer dapi-token")
+	conn := &databricksSQLConn{
+		client: newJSONHTTPClient(server.URL, header, nil),
+		config: pc.DatabricksSQLConfig{WarehouseID: "warehouse", Catalog: "main", Schema: "features"},
+	}
+	db := sql.OpenDB(fakeDatabricksSQLConnector{conn})
+	rows, err := db.Query("SELECT entity, value, ts FROM \"t\" WHERE entity<>?", "c")
+	if err != nil {
+		t.Fatalf("Failed to query: %s", err)
+	}
+	defer rows.Close()
+	types, err := rows.ColumnTypes()
+	if err != nil {
+		t.Fatalf("Failed to get column types: %s", err)
+	}
+	if (databricksSQLQueries{}).getValueColumnType(types[1]) != dbsBigInt {
+		t.Fatalf("Expected bigint value column, got %s", types[1].DatabaseTypeName())
+	}
+	type record struct {
+		entity string
+		value  interface{}
+		ts     time.Time
+	}
+	actual := make([]record, 0)
+	for rows.Next() {
+		var rec record
+		if err := rows.Scan(&rec.entity, &rec.value, &rec.ts); err != nil {
+			t.Fatalf("Failed to scan: %s", err)
+		}
+		actual = append(actual, rec)
+	}
+	if err := rows.Err(); err != nil {
+		t.Fatalf("Failed to iterate: %s", err)
+	}
+	expected := []record{
+		{"a", int64(1), time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)},
+		{"b", nil, time.Date(2023, 1, 2, 0, 0, 0, 500000000, time.UTC)},
+	}
+	if len(actual) != len(expected) {
+		t.Fatalf("Expected %v, got %v", expected, actual)
+	}
+	for i := range expected {
+		if actual[i].entity != expected[i].entity || actual[i].value != expected[i].value || !actual[i].ts.Equal(expected[i].ts) {
+			t.Fatalf("Expected %v, got %v", expected, actual)
+		}
+	}
+	statement := api.statements[0]
+	if statement.Statement != "SELECT entity, value, ts FROM `t` WHERE entity<>'c'" {
+		t.Fatalf("Unexpected statement %s", statement.Statement)
+	}
+	if statement.WarehouseID != "warehouse" || statement.Catalog != "main" || statement.Schema != "features" {
+		t.Fatalf("Statement isn't run in the configured warehouse and schema: %+v", statement)
+	}
+
+	api.fail = true
+	if _, err := db.Exec("DROP TABLE `t`"); err == nil || !strings.Contains(err.Error(), "TABLE_OR_VIEW_NOT_FOUND") {
+		t.Fatalf("Expected the failure message, got %v", err)
+	}
+}
+
+func TestDatabricksSQLQueries(t *testing.T) {
+	q := databricksSQLQueries{Catalog: "main", Schema: "features"}
+	expected := "SELECT COUNT(*) FROM `main`.information_schema.tables WHERE table_schema='features' AND table_type<>'VIEW' AND table_name=lower(?)"
+	if q.tableExists() != expected {
+		t.Fatalf("Expected %s, got %s", expected, q.tableExists())
+	}
+	table := q.newSQLOfflineTable("featureform_resource_feature__Amount__default", "FLOAT8")
+	if table != "CREATE TABLE \"featureform_resource_feature__Amount__default\" (entity STRING, value DOUBLE, ts TIMESTAMP)" {
+		t.Fatalf("Unexpected resource table query: %s", table)
+	}
+	if _, err := databricksSQLOfflineStoreFactory((&pc.DatabricksSQLConfig{Host: "localhost", WarehouseID: "warehouse"}).Serialize()); err == nil {
+		t.Fatalf("Expected a catalog to be required")
+	}
+}
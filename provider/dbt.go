@@ -0,0 +1,67 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package provider
+
+import (
+	"errors"
+	"fmt"
+)
+
+// DBTModel is a model in a dbt project that a DBTTransformation builds with
+// the dbt CLI.
+type DBTModel struct {
+	ProjectDir  string
+	ProfilesDir string
+	Target      string
+	Model       string
+	// Table is the table the model builds. It defaults to the model's name,
+	// and has to be set if the model has an alias or a custom schema.
+	Table string
+}
+
+func (m DBTModel) Validate() error {
+	if m.ProjectDir == "" {
+		return errors.New("dbt model must have a project directory")
+	}
+	if m.Model == "" {
+		return errors.New("dbt model must have a name")
+	}
+	return nil
+}
+
+// RunArgs are the dbt CLI arguments that build only this model.
+func (m DBTModel) RunArgs() []string {
+	args := []string{"run", "--project-dir", m.ProjectDir, "--select", m.Model}
+	if m.ProfilesDir != "" {
+		args = append(args, "--profiles-dir", m.ProfilesDir)
+	}
+	if m.Target != "" {
+		args = append(args, "--target", m.Target)
+	}
+	return args
+}
+
+func (m DBTModel) TableName() string {
+	if m.Table != "" {
+		return m.Table
+	}
+	return m.Model
+}
+
+// DBTOutputConfig returns the SQL transformation that reads the table built
+// by a dbt transformation, which the offline store creates once dbt has run.
+func (m *TransformationConfig) DBTOutputConfig() (TransformationConfig, error) {
+	if m.Type != DBTTransformation {
+		return TransformationConfig{}, fmt.Errorf("transformation %s isn't a dbt transformation", m.TargetTableID.Name)
+	}
+	if err := m.DBT.Validate(); err != nil {
+		return TransformationConfig{}, err
+	}
+	config := *m
+	config.Type = SQLTransformation
+	config.Query = fmt.Sprintf("SELECT * FROM %s", m.DBT.TableName())
+	config.DBT = DBTModel{}
+	return config, nil
+}
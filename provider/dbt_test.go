@@ -0,0 +1,67 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package provider
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestDBTModelRunArgs(t *testing.T) {
+	model := DBTModel{ProjectDir: "/dbt/project", ProfilesDir: "/dbt", Target: "prod", Model: "orders"}
+	expected := []string{"run", "--project-dir", "/dbt/project", "--select", "orders", "--profiles-dir", "/dbt", "--target", "prod"}
+	if args := model.RunArgs(); !reflect.DeepEqual(args, expected) {
+		t.Fatalf("Expected %v, got %v", expected, args)
+	}
+}
+
+func TestDBTOutputConfig(t *testing.T) {
+	id := ResourceID{Name: "orders", Variant: "v1", Type: Transformation}
+	tests := []struct {
+		name     string
+		model    DBTModel
+		expected string
+	}{
+		{"Model Name", DBTModel{ProjectDir: "/dbt/project", Model: "orders"}, "SELECT * FROM orders"},
+		{"Table", DBTModel{ProjectDir: "/dbt/project", Model: "orders", Table: "analytics.orders_v2"}, "SELECT * FROM analytics.orders_v2"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := TransformationConfig{Type: DBTTransformation, TargetTableID: id, DBT: tt.model}
+			output, err := config.DBTOutputConfig()
+			if err != nil {
+				t.Fatalf("Failed to get output config: %s", err)
+			}
+			expected := TransformationConfig{Type: SQLTransformation, TargetTableID: id, Query: tt.expected}
+			if !reflect.DeepEqual(output, expected) {
+				t.Fatalf("Expected %v, got %v", expected, output)
+			}
+		})
+	}
+	invalid := TransformationConfig{Type: DBTTransformation, TargetTableID: id, DBT: DBTModel{Model: "orders"}}
+	if _, err := invalid.DBTOutputConfig(); err == nil {
+		t.Fatalf("Expected an error for a model without a project")
+	}
+}
+
+func TestDBTTransformationConfigRoundTrip(t *testing.T) {
+	config := TransformationConfig{
+		Type:          DBTTransformation,
+		TargetTableID: ResourceID{Name: "orders", Variant: "v1", Type: Transformation},
+		DBT:           DBTModel{ProjectDir: "/dbt/project", Target: "prod", Model: "orders"},
+	}
+	serialized, err := json.Marshal(&config)
+	if err != nil {
+		t.Fatalf("Failed to marshal config: %s", err)
+	}
+	var deserialized TransformationConfig
+	if err := json.Unmarshal(serialized, &deserialized); err != nil {
+		t.Fatalf("Failed to unmarshal config: %s", err)
+	}
+	if deserialized.Type != DBTTransformation || !reflect.DeepEqual(config.DBT, deserialized.DBT) {
+		t.Fatalf("Expected %v, got %v", config, deserialized)
+	}
+}
@@ -0,0 +1,122 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package provider
+
+import (
+	"fmt"
+	"hash/fnv"
+	"sync"
+	"time"
+)
+
+type dedupEntry struct {
+	hash    uint64
+	expires time.Time
+}
+
+// DedupTable wraps an online table and skips a Set if the same value was
+// already written for the entity within the window. Upstream at-least-once
+// delivery and retried chunks then don't cause redundant provider writes.
+//
+// Only the last written value of each entity is remembered, so writing A, B,
+// then A again still writes A the second time.
+type DedupTable struct {
+	OnlineStoreTable
+	window    time.Duration
+	entries   map[string]dedupEntry
+	nextSweep time.Time
+	now       func() time.Time
+	mu        sync.Mutex
+}
+
+func NewDedupTable(table OnlineStoreTable, window time.Duration) *DedupTable {
+	return &DedupTable{
+		OnlineStoreTable: table,
+		window:           window,
+		entries:          make(map[string]dedupEntry),
+		now:              time.Now,
+	}
+}
+
+func (table *DedupTable) Set(entity string, value interface{}) error {
+	hash, err := hashValue(value)
+	if err != nil {
+		return err
+	}
+	now := table.now()
+	table.mu.Lock()
+	entry, has := table.entries[entity]
+	table.mu.Unlock()
+	if has && entry.hash == hash && now.Before(entry.expires) {
+		return nil
+	}
+	if err := table.OnlineStoreTable.Set(entity, value); err != nil {
+		return err
+	}
+	table.mu.Lock()
+	defer table.mu.Unlock()
+	table.entries[entity] = dedupEntry{hash: hash, expires: now.Add(table.window)}
+	table.sweep(now)
+	return nil
+}
+
+// SetBatch writes the values that weren't already written within the window
+// as one batch.
+func (table *DedupTable) SetBatch(values map[string]interface{}) error {
+	now := table.now()
+	changed := make(map[string]interface{}, len(values))
+	hashes := make(map[string]uint64, len(values))
+	table.mu.Lock()
+	for entity, value := range values {
+		hash, err := hashValue(value)
+		if err != nil {
+			table.mu.Unlock()
+			return err
+		}
+		if entry, has := table.entries[entity]; has && entry.hash == hash && now.Before(entry.expires) {
+			continue
+		}
+		changed[entity] = value
+		hashes[entity] = hash
+	}
+	table.mu.Unlock()
+	if len(changed) == 0 {
+		return nil
+	}
+	if err := setBatch(table.OnlineStoreTable, changed); err != nil {
+		return err
+	}
+	table.mu.Lock()
+	defer table.mu.Unlock()
+	for entity, hash := range hashes {
+		table.entries[entity] = dedupEntry{hash: hash, expires: now.Add(table.window)}
+	}
+	table.sweep(now)
+	return nil
+}
+
+// sweep removes expired entries at most once per window so memory stays
+// proportional to the entities written within the last window.
+func (table *DedupTable) sweep(now time.Time) {
+	if now.Before(table.nextSweep) {
+		return
+	}
+	for entity, entry := range table.entries {
+		if !now.Before(entry.expires) {
+			delete(table.entries, entity)
+		}
+	}
+	table.nextSweep = now.Add(table.window)
+}
+
+func hashValue(value interface{}) (uint64, error) {
+	h := fnv.New64a()
+	// The type is included so that values that print the same, like int 1
+	// and string "1", aren't treated as duplicates.
+	if _, err := fmt.Fprintf(h, "%T:%v", value, value); err != nil {
+		return 0, err
+	}
+	return h.Sum64(), nil
+}
@@ -0,0 +1,77 @@
+package provider
+
+import (
+	"testing"
+	"time"
+)
+
+type countingTable struct {
+	OnlineStoreTable
+	sets int
+}
+
+func (table *countingTable) Set(entity string, value interface{}) error {
+	table.sets++
+	return nil
+}
+
+func TestDedupTable(t *testing.T) {
+	inner := &countingTable{}
+	table := NewDedupTable(inner, time.Minute)
+	now := time.Now()
+	table.now = func() time.Time { return now }
+
+	writes := []struct {
+		entity string
+		value  interface{}
+		sets   int
+	}{
+		{"a", 1, 1},
+		{"a", 1, 1},
+		{"a", "1", 2},
+		{"b", "1", 3},
+		{"a", 2, 4},
+		{"a", "1", 5},
+		{"a", []int64{1, 2}, 6},
+		{"a", []int64{1, 2}, 6},
+	}
+	for i, w := range writes {
+		if err := table.Set(w.entity, w.value); err != nil {
+			t.Fatalf("Failed to set entity: %s", err)
+		}
+		if inner.sets != w.sets {
+			t.Fatalf("Write %d: expected %d sets, got %d", i, w.sets, inner.sets)
+		}
+	}
+
+	now = now.Add(time.Minute)
+	if err := table.Set("a", []int64{1, 2}); err != nil {
+		t.Fatalf("Failed to set entity: %s", err)
+	}
+	if inner.sets != 7 {
+		t.Fatalf("Expected write after window expired, got %d sets", inner.sets)
+	}
+	if _, has := table.entries["b"]; has {
+		t.Fatalf("Expected expired entries to be swept")
+	}
+}
+
+func TestDedupTableSetBatch(t *testing.T) {
+	inner := &countingTable{}
+	table := NewDedupTable(inner, time.Minute)
+	if err := table.Set("a", 1); err != nil {
+		t.Fatalf("Failed to set entity: %s", err)
+	}
+	if err := table.SetBatch(map[string]interface{}{"a": 1, "b": 2, "c": 3}); err != nil {
+		t.Fatalf("Failed to set batch: %s", err)
+	}
+	if inner.sets != 3 {
+		t.Fatalf("Expected only the changed values to be written, got %d sets", inner.sets)
+	}
+	if err := table.SetBatch(map[string]interface{}{"b": 2, "c": 3}); err != nil {
+		t.Fatalf("Failed to set batch: %s", err)
+	}
+	if inner.sets != 3 {
+		t.Fatalf("Expected a batch of duplicates to be skipped, got %d sets", inner.sets)
+	}
+}
@@ -0,0 +1,289 @@
+package provider
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/segmentio/parquet-go"
+
+	pc "github.com/featureform/provider/provider_config"
+)
+
+const (
+	deltaLogDirectory = "_delta_log"
+	deltaCommitFile   = FileType("json")
+	// deltaReaderVersion is the newest reader protocol that can be read.
+	// Later versions add column mapping and deletion vectors, which change
+	// how data files are read.
+	deltaReaderVersion = 1
+)
+
+// deltaTable reads a Delta Lake table whose log and data files are in a
+// FileStore. Each version's files are found by replaying the log from its
+// newest checkpoint, or from the first commit if there isn't one. Only
+// unpartitioned tables can be read, since partition values aren't in their
+// data files.
+type deltaTable struct {
+	store FileStore
+	// location is the key of the table's directory in the store.
+	location string
+}
+
+func newDeltaTable(store FileStore, location string) *deltaTable {
+	return &deltaTable{store: store, location: strings.TrimSuffix(location, "/")}
+}
+
+// deltaAction is a line of a commit, or a row of a checkpoint. Only one of
+// its actions is set.
+type deltaAction struct {
+	Add      *deltaAddAction    `json:"add" parquet:"add,optional"`
+	Remove   *deltaRemoveAction `json:"remove" parquet:"remove,optional"`
+	Protocol *deltaProtocol     `json:"protocol" parquet:"protocol,optional"`
+	MetaData *deltaMetadata     `json:"metaData" parquet:"metaData,optional"`
+}
+
+type deltaAddAction struct {
+	// Path is URL encoded and relative to the table, unless the table is a
+	// shallow clone.
+	Path string `json:"path" parquet:"path"`
+	// Stats is JSON, with the file's row count in numRecords.
+	Stats          string               `json:"stats" parquet:"stats,optional"`
+	DeletionVector *deltaDeletionVector `json:"deletionVector" parquet:"deletionVector,optional"`
+}
+
+type deltaDeletionVector struct {
+	StorageType string `json:"storageType" parquet:"storageType"`
+}
+
+type deltaRemoveAction struct {
+	Path string `json:"path" parquet:"path"`
+}
+
+type deltaProtocol struct {
+	MinReaderVersion int32 `json:"minReaderVersion" parquet:"minReaderVersion"`
+}
+
+type deltaMetadata struct {
+	PartitionColumns []string `json:"partitionColumns" parquet:"partitionColumns,list"`
+}
+
+type deltaStats struct {
+	NumRecords *int64 `json:"numRecords"`
+}
+
+type deltaCheckpoint struct {
+	Version int64 `json:"version"`
+	// Parts is the number of files that the checkpoint is split into, if
+	// it's more than one.
+	Parts int `json:"parts"`
+}
+
+func (t *deltaTable) Location() string {
+	return t.location
+}
+
+func (t *deltaTable) Format() pc.TableFormat {
+	return pc.DeltaTableFormat
+}
+
+func (t *deltaTable) CurrentVersion() (string, error) {
+	version, err := t.LatestVersion()
+	if err != nil || version < 0 {
+		return "", err
+	}
+	return strconv.FormatInt(version, 10), nil
+}
+
+func (t *deltaTable) CurrentFiles() ([]tableDataFile, error) {
+	version, err := t.LatestVersion()
+	if err != nil {
+		return nil, err
+	}
+	return t.DataFiles(version)
+}
+
+// LatestVersion returns the version of the table's newest commit, or -1 if
+// it has none.
+func (t *deltaTable) LatestVersion() (int64, error) {
+	key, err := t.store.NewestFileOfType(t.logKey(""), deltaCommitFile)
+	if err != nil {
+		return 0, fmt.Errorf("could not find the log of delta table %s: %w", t.location, err)
+	}
+	if key == "" {
+		return -1, nil
+	}
+	version, err := strconv.ParseInt(strings.TrimSuffix(path.Base(key), ".json"), 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid delta commit %s: %w", key, err)
+	}
+	return version, nil
+}
+
+// DataFiles returns the data files in a version of the table, which can be
+// read until they're vacuumed. A negative version has none.
+func (t *deltaTable) DataFiles(version int64) ([]tableDataFile, error) {
+	if version < 0 {
+		return nil, nil
+	}
+	added := make(map[string]deltaAddAction)
+	next := int64(0)
+	checkpoint, err := t.lastCheckpoint()
+	if err != nil {
+		return nil, err
+	}
+	// Versions before the checkpoint are read from their commits, which are
+	// kept for longer than the checkpoints are made.
+	if checkpoint != nil && checkpoint.Version <= version {
+		actions, err := t.readCheckpoint(*checkpoint)
+		if err != nil {
+			return nil, err
+		}
+		if err := t.apply(added, actions); err != nil {
+			return nil, err
+		}
+		next = checkpoint.Version + 1
+	}
+	for ; next <= version; next++ {
+		actions, err := t.readCommit(next)
+		if err != nil {
+			return nil, err
+		}
+		if err := t.apply(added, actions); err != nil {
+			return nil, err
+		}
+	}
+	paths := make([]string, 0, len(added))
+	for filePath := range added {
+		paths = append(paths, filePath)
+	}
+	sort.Strings(paths)
+	files := make([]tableDataFile, len(paths))
+	for i, filePath := range paths {
+		file, err := t.dataFile(added[filePath])
+		if err != nil {
+			return nil, err
+		}
+		files[i] = file
+	}
+	return files, nil
+}
+
+func (t *deltaTable) apply(added map[string]deltaAddAction, actions []deltaAction) error {
+	for _, action := range actions {
+		switch {
+		case action.Protocol != nil && action.Protocol.MinReaderVersion > deltaReaderVersion:
+			return fmt.Errorf("delta table %s needs reader version %d, but only version %d can be read", t.location, action.Protocol.MinReaderVersion, deltaReaderVersion)
+		case action.MetaData != nil && len(action.MetaData.PartitionColumns) > 0:
+			return fmt.Errorf("delta table %s is partitioned, which can't be read", t.location)
+		case action.Add != nil:
+			added[action.Add.Path] = *action.Add
+		case action.Remove != nil:
+			delete(added, action.Remove.Path)
+		}
+	}
+	return nil
+}
+
+func (t *deltaTable) dataFile(add deltaAddAction) (tableDataFile, error) {
+	if add.DeletionVector != nil {
+		return tableDataFile{}, fmt.Errorf("%s has a deletion vector, which can't be read", add.Path)
+	}
+	if strings.Contains(add.Path, "://") {
+		return tableDataFile{}, fmt.Errorf("%s is not in delta table %s", add.Path, t.location)
+	}
+	filePath, err := url.PathUnescape(add.Path)
+	if err != nil {
+		return tableDataFile{}, fmt.Errorf("invalid delta file path %s: %w", add.Path, err)
+	}
+	key := fmt.Sprintf("%s/%s", t.location, filePath)
+	stats := deltaStats{}
+	if add.Stats != "" {
+		if err := json.Unmarshal([]byte(add.Stats), &stats); err != nil {
+			return tableDataFile{}, fmt.Errorf("invalid stats for %s: %w", add.Path, err)
+		}
+	}
+	if stats.NumRecords != nil {
+		return tableDataFile{Key: key, RecordCount: *stats.NumRecords}, nil
+	}
+	rows, err := t.store.NumRows(key)
+	if err != nil {
+		return tableDataFile{}, fmt.Errorf("could not count rows of %s: %w", key, err)
+	}
+	return tableDataFile{Key: key, RecordCount: rows}, nil
+}
+
+func (t *deltaTable) logKey(name string) string {
+	return fmt.Sprintf("%s/%s/%s", t.location, deltaLogDirectory, name)
+}
+
+func (t *deltaTable) lastCheckpoint() (*deltaCheckpoint, error) {
+	key := t.logKey("_last_checkpoint")
+	exists, err := t.store.Exists(key)
+	if err != nil || !exists {
+		return nil, err
+	}
+	serialized, err := t.store.Read(key)
+	if err != nil {
+		return nil, fmt.Errorf("could not read %s: %w", key, err)
+	}
+	checkpoint := &deltaCheckpoint{}
+	if err := json.Unmarshal(serialized, checkpoint); err != nil {
+		return nil, fmt.Errorf("invalid delta checkpoint %s: %w", key, err)
+	}
+	return checkpoint, nil
+}
+
+func (t *deltaTable) readCheckpoint(checkpoint deltaCheckpoint) ([]deltaAction, error) {
+	keys := []string{t.logKey(fmt.Sprintf("%020d.checkpoint.parquet", checkpoint.Version))}
+	if checkpoint.Parts > 1 {
+		keys = make([]string, checkpoint.Parts)
+		for i := range keys {
+			keys[i] = t.logKey(fmt.Sprintf("%020d.checkpoint.%010d.%010d.parquet", checkpoint.Version, i+1, checkpoint.Parts))
+		}
+	}
+	actions := make([]deltaAction, 0)
+	for _, key := range keys {
+		serialized, err := t.store.Read(key)
+		if err != nil {
+			return nil, fmt.Errorf("could not read delta checkpoint %s: %w", key, err)
+		}
+		rows, err := parquet.Read[deltaAction](bytes.NewReader(serialized), int64(len(serialized)))
+		if err != nil {
+			return nil, fmt.Errorf("could not parse delta checkpoint %s: %w", key, err)
+		}
+		actions = append(actions, rows...)
+	}
+	return actions, nil
+}
+
+func (t *deltaTable) readCommit(version int64) ([]deltaAction, error) {
+	key := t.logKey(fmt.Sprintf("%020d.json", version))
+	serialized, err := t.store.Read(key)
+	if err != nil {
+		return nil, fmt.Errorf("could not read commit %d of delta table %s: %w", version, t.location, err)
+	}
+	actions := make([]deltaAction, 0)
+	scanner := bufio.NewScanner(bytes.NewReader(serialized))
+	// Actions with stats on many columns can be longer than the default
+	// limit of a line.
+	scanner.Buffer(nil, len(serialized)+1)
+	for scanner.Scan() {
+		line := bytes.TrimSpace(scanner.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+		action := deltaAction{}
+		if err := json.Unmarshal(line, &action); err != nil {
+			return nil, fmt.Errorf("invalid action in %s: %w", key, err)
+		}
+		actions = append(actions, action)
+	}
+	return actions, scanner.Err()
+}
@@ -0,0 +1,196 @@
+package provider
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/segmentio/parquet-go"
+)
+
+const testDeltaKey = "featureform/Materialization/feature/variant"
+
+// testDeltaCheckpointRow has more columns than are read, like a checkpoint
+// written by Spark.
+type testDeltaCheckpointRow struct {
+	Txn *struct {
+		AppID string `parquet:"appId"`
+	} `parquet:"txn,optional"`
+	Add *struct {
+		Path  string `parquet:"path"`
+		Size  int64  `parquet:"size"`
+		Stats string `parquet:"stats,optional"`
+	} `parquet:"add,optional"`
+	Protocol *deltaProtocol `parquet:"protocol,optional"`
+}
+
+func writeTestDeltaFile(t *testing.T, store FileStore, name string, rows []testIcebergRow) {
+	buf := new(bytes.Buffer)
+	if err := parquet.Write(buf, rows); err != nil {
+		t.Fatalf("Failed to write parquet: %s", err)
+	}
+	if err := store.Write(fmt.Sprintf("%s/%s", testDeltaKey, name), buf.Bytes()); err != nil {
+		t.Fatalf("Failed to write data file: %s", err)
+	}
+}
+
+func writeTestDeltaCommit(t *testing.T, store FileStore, version int, actions ...string) {
+	key := fmt.Sprintf("%s/_delta_log/%020d.json", testDeltaKey, version)
+	if err := store.Write(key, []byte(strings.Join(actions, "\n")+"\n")); err != nil {
+		t.Fatalf("Failed to write commit: %s", err)
+	}
+}
+
+// writeTestDeltaTable writes a table with three versions: 0 adds a file, 1
+// overwrites it with another, and 2 appends a third without stats.
+func writeTestDeltaTable(t *testing.T, store FileStore) {
+	writeTestDeltaFile(t, store, "part-0.parquet", []testIcebergRow{{"a", 1, "2023-01-01 00:00:00.000000 UTC"}})
+	writeTestDeltaFile(t, store, "part-1.parquet", []testIcebergRow{{"a", 2, "2023-01-02 00:00:00.000000 UTC"}})
+	writeTestDeltaFile(t, store, "date=2023 01/part-2.parquet", []testIcebergRow{
+		{"b", 3, "2023-01-03 00:00:00.000000 UTC"},
+		{"c", 4, "2023-01-03 00:00:00.000000 UTC"},
+	})
+	writeTestDeltaCommit(t, store, 0,
+		`{"protocol": {"minReaderVersion": 1, "minWriterVersion": 2}}`,
+		`{"metaData": {"id": "table", "partitionColumns": []}}`,
+		`{"add": {"path": "part-0.parquet", "size": 1, "dataChange": true, "stats": "{\"numRecords\": 1}"}}`,
+	)
+	writeTestDeltaCommit(t, store, 1,
+		`{"commitInfo": {"operation": "WRITE"}}`,
+		`{"remove": {"path": "part-0.parquet", "dataChange": true}}`,
+		`{"add": {"path": "part-1.parquet", "size": 1, "dataChange": true, "stats": "{\"numRecords\": 1}"}}`,
+	)
+	writeTestDeltaCommit(t, store, 2,
+		`{"add": {"path": "date%3D2023%2001/part-2.parquet", "size": 1, "dataChange": true}}`,
+	)
+}
+
+func TestDeltaTableVersions(t *testing.T) {
+	store := newLocalTestFileStore(t)
+	writeTestDeltaTable(t, store)
+	table := newDeltaTable(store, testDeltaKey+"/")
+	version, err := table.CurrentVersion()
+	if err != nil || version != "2" {
+		t.Fatalf("Expected version 2, got %s: %v", version, err)
+	}
+	files, err := table.CurrentFiles()
+	if err != nil {
+		t.Fatalf("Failed to get data files: %s", err)
+	}
+	expected := []tableDataFile{
+		{Key: testDeltaKey + "/date=2023 01/part-2.parquet", RecordCount: 2},
+		{Key: testDeltaKey + "/part-1.parquet", RecordCount: 1},
+	}
+	if fmt.Sprint(files) != fmt.Sprint(expected) {
+		t.Fatalf("Expected %v, got %v", expected, files)
+	}
+	files, err = table.DataFiles(0)
+	if err != nil {
+		t.Fatalf("Failed to get data files of version 0: %s", err)
+	}
+	if len(files) != 1 || files[0].Key != testDeltaKey+"/part-0.parquet" {
+		t.Fatalf("Expected part-0.parquet in version 0, got %v", files)
+	}
+	if _, err := table.DataFiles(3); err == nil {
+		t.Fatalf("Expected an error for a missing version")
+	}
+	empty := newDeltaTable(store, "featureform/Materialization/missing/variant")
+	if version, err := empty.CurrentVersion(); err != nil || version != "" {
+		t.Fatalf("Expected no version for a missing table, got %q: %v", version, err)
+	}
+}
+
+func TestDeltaTableCheckpoint(t *testing.T) {
+	store := newLocalTestFileStore(t)
+	writeTestDeltaTable(t, store)
+	row := testDeltaCheckpointRow{Add: &struct {
+		Path  string `parquet:"path"`
+		Size  int64  `parquet:"size"`
+		Stats string `parquet:"stats,optional"`
+	}{Path: "part-1.parquet", Size: 1, Stats: `{"numRecords": 1}`}}
+	buf := new(bytes.Buffer)
+	if err := parquet.Write(buf, []testDeltaCheckpointRow{{Protocol: &deltaProtocol{MinReaderVersion: 1}}, row}); err != nil {
+		t.Fatalf("Failed to write checkpoint: %s", err)
+	}
+	if err := store.Write(testDeltaKey+"/_delta_log/00000000000000000001.checkpoint.parquet", buf.Bytes()); err != nil {
+		t.Fatalf("Failed to write checkpoint: %s", err)
+	}
+	if err := store.Write(testDeltaKey+"/_delta_log/_last_checkpoint", []byte(`{"version": 1, "size": 2}`)); err != nil {
+		t.Fatalf("Failed to write checkpoint: %s", err)
+	}
+	// The commits that the checkpoint replaces have been cleaned up.
+	for _, version := range []int{0, 1} {
+		if err := store.Delete(fmt.Sprintf("%s/_delta_log/%020d.json", testDeltaKey, version)); err != nil {
+			t.Fatalf("Failed to delete commit: %s", err)
+		}
+	}
+	id := ResourceID{Name: "feature", Variant: "variant", Type: FeatureMaterialization}
+	mat, err := newTableMaterialization(id, store, newDeltaTable(store, testDeltaKey))
+	if err != nil {
+		t.Fatalf("Failed to open materialization: %s", err)
+	}
+	if rows, err := mat.NumRows(); err != nil || rows != 3 {
+		t.Fatalf("Expected 3 rows, got %d: %v", rows, err)
+	}
+	iter, err := mat.IterateSegment(0, 3)
+	if err != nil {
+		t.Fatalf("Failed to iterate: %s", err)
+	}
+	entities := make([]string, 0)
+	for iter.Next() {
+		entities = append(entities, iter.Value().Entity)
+	}
+	if iter.Err() != nil || fmt.Sprint(entities) != "[b c a]" {
+		t.Fatalf("Expected entities [b c a], got %v: %v", entities, iter.Err())
+	}
+}
+
+func TestDeltaTableUnreadable(t *testing.T) {
+	tests := []struct {
+		name   string
+		action string
+	}{
+		{"Column Mapping", `{"protocol": {"minReaderVersion": 2, "minWriterVersion": 5}}`},
+		{"Partitioned", `{"metaData": {"id": "table", "partitionColumns": ["date"]}}`},
+		{"Deletion Vector", `{"add": {"path": "part-0.parquet", "deletionVector": {"storageType": "u"}}}`},
+		{"Shallow Clone", `{"add": {"path": "s3://bucket/other/part-0.parquet"}}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := newLocalTestFileStore(t)
+			writeTestDeltaCommit(t, store, 0, tt.action)
+			if _, err := newDeltaTable(store, testDeltaKey).CurrentFiles(); err == nil {
+				t.Fatalf("Expected an error")
+			}
+		})
+	}
+}
+
+func TestDeltaMaterializationPartitions(t *testing.T) {
+	store := newLocalTestFileStore(t)
+	writeTestDeltaTable(t, store)
+	id := ResourceID{Name: "feature", Variant: "variant", Type: FeatureMaterialization}
+	mat, err := newTableMaterialization(id, store, newDeltaTable(store, testDeltaKey))
+	if err != nil {
+		t.Fatalf("Failed to open materialization: %s", err)
+	}
+	var partitioned PartitionedMaterialization = mat
+	if partitions, err := partitioned.NumPartitions(); err != nil || partitions != 2 {
+		t.Fatalf("Expected a partition per data file, got %d: %v", partitions, err)
+	}
+	iter, err := partitioned.IteratePartition(0)
+	if err != nil {
+		t.Fatalf("Failed to iterate partition: %s", err)
+	}
+	entities := make([]string, 0)
+	for iter.Next() {
+		entities = append(entities, iter.Value().Entity)
+	}
+	if iter.Err() != nil || fmt.Sprint(entities) != "[b c]" {
+		t.Fatalf("Expected entities [b c], got %v: %v", entities, iter.Err())
+	}
+	if _, err := partitioned.IteratePartition(2); err == nil {
+		t.Fatalf("Expected an error for a missing partition")
+	}
+}
@@ -0,0 +1,362 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package provider
+
+import (
+	"fmt"
+	"math/rand"
+	"reflect"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	pc "github.com/featureform/provider/provider_config"
+	pt "github.com/featureform/provider/provider_type"
+)
+
+func dualWriteOnlineStoreFactory(serialized pc.SerializedConfig) (Provider, error) {
+	config := &pc.DualWriteConfig{}
+	if err := config.Deserialize(serialized); err != nil {
+		return nil, err
+	}
+	primary, err := getDualWriteStore(config.Primary)
+	if err != nil {
+		return nil, fmt.Errorf("primary online store: %w", err)
+	}
+	var secondary OnlineStore
+	if config.Secondary.Type != "" {
+		secondary, err = getDualWriteStore(config.Secondary)
+		if err != nil {
+			primary.Close()
+			return nil, fmt.Errorf("secondary online store: %w", err)
+		}
+	}
+	store := NewDualWriteStore(primary, secondary, serialized)
+	store.TolerateSecondaryErrors = config.TolerateSecondaryErrors
+	store.CompareReadRate = config.CompareReadRate
+	return store, nil
+}
+
+func getDualWriteStore(config pc.OnlineStoreConfig) (OnlineStore, error) {
+	if pt.Type(config.Type) == pt.DualWriteOnline {
+		return nil, fmt.Errorf("dual-write stores can't be nested")
+	}
+	p, err := Get(pt.Type(config.Type), pc.SerializedConfig(config.Config))
+	if err != nil {
+		return nil, err
+	}
+	return p.AsOnlineStore()
+}
+
+// DualWriteStore writes to both a primary and a secondary online store and
+// reads only from the primary. Writes go to the primary first, so a failed
+// secondary write can leave the stores out of sync until the value is
+// written again.
+//
+// Tables that don't exist in the secondary, such as those created before
+// the secondary was added, are only written to the primary. Running the
+// feature's materialization again creates them and backfills the values.
+//
+// Failed secondary writes and a sample of reads compared between the stores
+// are counted as divergence, both on the store and in the dual-write
+// Prometheus metrics.
+type DualWriteStore struct {
+	BaseProvider
+	Primary   OnlineStore
+	Secondary OnlineStore
+	// TolerateSecondaryErrors counts failed secondary writes as divergence
+	// instead of returning them.
+	TolerateSecondaryErrors bool
+	// CompareReadRate is the fraction of reads that are compared with the
+	// secondary.
+	CompareReadRate float64
+	divergence      dualWriteCounters
+	rand            *rand.Rand
+	randMu          sync.Mutex
+}
+
+// DualWriteDivergence counts the differences a DualWriteStore has seen
+// between its primary and secondary stores.
+type DualWriteDivergence struct {
+	// SecondaryWriteErrors is the number of entities whose secondary write
+	// failed and was tolerated.
+	SecondaryWriteErrors int64
+	// ReadsCompared is the number of reads also read from the secondary.
+	ReadsCompared int64
+	// ReadsMissing is the number of compared entities the secondary didn't
+	// have.
+	ReadsMissing int64
+	// ReadsMismatched is the number of compared entities whose secondary
+	// value differed from the primary's, or couldn't be read.
+	ReadsMismatched int64
+}
+
+type dualWriteCounters struct {
+	secondaryWriteErrors int64
+	readsCompared        int64
+	readsMissing         int64
+	readsMismatched      int64
+}
+
+const (
+	dualWriteSecondaryWriteError = "secondary_write_error"
+	dualWriteReadCompared        = "read_compared"
+	dualWriteReadMissing         = "read_missing"
+	dualWriteReadMismatched      = "read_mismatched"
+)
+
+var dualWriteEvents = prometheus.NewCounterVec(
+	prometheus.CounterOpts{
+		Name: "dual_write_events_total",
+		Help: "Count of dual-write secondary write errors and read comparisons, labeled by secondary provider type and event",
+	},
+	[]string{"secondary", "event"},
+)
+
+// RegisterDualWriteMetrics registers the divergence metrics of every
+// DualWriteStore in the process.
+func RegisterDualWriteMetrics(registerer prometheus.Registerer) error {
+	if err := registerer.Register(dualWriteEvents); err != nil {
+		return fmt.Errorf("could not register dual-write metrics: %w", err)
+	}
+	return nil
+}
+
+// NewDualWriteStore creates a store that writes to both primary and
+// secondary. The secondary may be nil, in which case it only uses primary.
+func NewDualWriteStore(primary, secondary OnlineStore, config pc.SerializedConfig) *DualWriteStore {
+	return &DualWriteStore{
+		BaseProvider: BaseProvider{
+			ProviderType:   pt.DualWriteOnline,
+			ProviderConfig: config,
+		},
+		Primary:   primary,
+		Secondary: secondary,
+		rand:      rand.New(rand.NewSource(time.Now().UnixNano())),
+	}
+}
+
+// Divergence returns the divergence the store has seen since it was created.
+func (store *DualWriteStore) Divergence() DualWriteDivergence {
+	return DualWriteDivergence{
+		SecondaryWriteErrors: atomic.LoadInt64(&store.divergence.secondaryWriteErrors),
+		ReadsCompared:        atomic.LoadInt64(&store.divergence.readsCompared),
+		ReadsMissing:         atomic.LoadInt64(&store.divergence.readsMissing),
+		ReadsMismatched:      atomic.LoadInt64(&store.divergence.readsMismatched),
+	}
+}
+
+func (store *DualWriteStore) record(event string, count int64) {
+	switch event {
+	case dualWriteSecondaryWriteError:
+		atomic.AddInt64(&store.divergence.secondaryWriteErrors, count)
+	case dualWriteReadCompared:
+		atomic.AddInt64(&store.divergence.readsCompared, count)
+	case dualWriteReadMissing:
+		atomic.AddInt64(&store.divergence.readsMissing, count)
+	case dualWriteReadMismatched:
+		atomic.AddInt64(&store.divergence.readsMismatched, count)
+	}
+	dualWriteEvents.WithLabelValues(string(store.Secondary.Type()), event).Add(float64(count))
+}
+
+// sampleRead returns true if a read should be compared with the secondary.
+func (store *DualWriteStore) sampleRead() bool {
+	if store.CompareReadRate <= 0 {
+		return false
+	}
+	store.randMu.Lock()
+	defer store.randMu.Unlock()
+	return store.rand.Float64() < store.CompareReadRate
+}
+
+func (store *DualWriteStore) AsOnlineStore() (OnlineStore, error) {
+	return store, nil
+}
+
+func (store *DualWriteStore) GetTable(feature, variant string) (OnlineStoreTable, error) {
+	primary, err := store.Primary.GetTable(feature, variant)
+	if err != nil {
+		return nil, err
+	}
+	table := &dualWriteTable{store: store, primary: primary}
+	if store.Secondary == nil {
+		return table, nil
+	}
+	secondary, err := store.Secondary.GetTable(feature, variant)
+	if _, notFound := err.(*TableNotFound); notFound {
+		return table, nil
+	} else if err != nil {
+		return nil, fmt.Errorf("secondary online store: %w", err)
+	}
+	table.secondary = secondary
+	return table, nil
+}
+
+// CreateTable creates the table in both stores. If it already exists in the
+// primary, it's still created in the secondary before TableAlreadyExists is
+// returned, so that updating the materialization backfills the secondary.
+func (store *DualWriteStore) CreateTable(feature, variant string, valueType ValueType) (OnlineStoreTable, error) {
+	primary, primaryErr := store.Primary.CreateTable(feature, variant, valueType)
+	_, exists := primaryErr.(*TableAlreadyExists)
+	if primaryErr != nil && !exists {
+		return nil, primaryErr
+	}
+	var secondary OnlineStoreTable
+	if store.Secondary != nil {
+		var err error
+		secondary, err = store.Secondary.CreateTable(feature, variant, valueType)
+		if _, secondaryExists := err.(*TableAlreadyExists); secondaryExists {
+			secondary, err = store.Secondary.GetTable(feature, variant)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("secondary online store: %w", err)
+		}
+	}
+	if exists {
+		return nil, primaryErr
+	}
+	return &dualWriteTable{store: store, primary: primary, secondary: secondary}, nil
+}
+
+func (store *DualWriteStore) DeleteTable(feature, variant string) error {
+	if err := store.Primary.DeleteTable(feature, variant); err != nil {
+		return err
+	}
+	if store.Secondary == nil {
+		return nil
+	}
+	err := store.Secondary.DeleteTable(feature, variant)
+	if _, notFound := err.(*TableNotFound); notFound {
+		return nil
+	} else if err != nil {
+		return fmt.Errorf("secondary online store: %w", err)
+	}
+	return nil
+}
+
+// Truncate truncates the table in both stores. Tables missing from the
+// secondary are skipped, as they are for writes.
+func (store *DualWriteStore) Truncate(feature, variant string) error {
+	if err := truncate(store.Primary, feature, variant); err != nil {
+		return err
+	}
+	if store.Secondary == nil {
+		return nil
+	}
+	err := truncate(store.Secondary, feature, variant)
+	if _, notFound := err.(*TableNotFound); notFound {
+		return nil
+	} else if err != nil {
+		return fmt.Errorf("secondary online store: %w", err)
+	}
+	return nil
+}
+
+// Capabilities reports writes the secondary must also support, and reads
+// from the primary alone.
+func (store *DualWriteStore) Capabilities() OnlineCapabilities {
+	primary := store.Primary.Capabilities()
+	written := primary
+	if store.Secondary != nil {
+		written = primary.intersect(store.Secondary.Capabilities())
+	}
+	return OnlineCapabilities{
+		BatchWrites: written.BatchWrites,
+		Stats:       primary.Stats,
+		Timestamps:  primary.Timestamps,
+		Truncate:    written.Truncate,
+	}
+}
+
+func (store *DualWriteStore) Close() error {
+	primaryErr := store.Primary.Close()
+	if store.Secondary != nil {
+		if err := store.Secondary.Close(); err != nil && primaryErr == nil {
+			return fmt.Errorf("secondary online store: %w", err)
+		}
+	}
+	return primaryErr
+}
+
+type dualWriteTable struct {
+	store     *DualWriteStore
+	primary   OnlineStoreTable
+	secondary OnlineStoreTable
+}
+
+func (table *dualWriteTable) Set(entity string, value interface{}) error {
+	if err := table.primary.Set(entity, value); err != nil {
+		return err
+	}
+	if table.secondary == nil {
+		return nil
+	}
+	return table.secondaryWriteErr(table.secondary.Set(entity, value), 1)
+}
+
+func (table *dualWriteTable) SetBatch(values map[string]interface{}) error {
+	if err := setBatch(table.primary, values); err != nil {
+		return err
+	}
+	if table.secondary == nil {
+		return nil
+	}
+	return table.secondaryWriteErr(setBatch(table.secondary, values), len(values))
+}
+
+// secondaryWriteErr returns the error of a secondary write of entities, or
+// records it as divergence if secondary errors are tolerated.
+func (table *dualWriteTable) secondaryWriteErr(err error, entities int) error {
+	if err == nil {
+		return nil
+	}
+	if table.store.TolerateSecondaryErrors {
+		table.store.record(dualWriteSecondaryWriteError, int64(entities))
+		return nil
+	}
+	return fmt.Errorf("secondary online store: %w", err)
+}
+
+func (table *dualWriteTable) Get(entity string) (interface{}, error) {
+	value, err := table.primary.Get(entity)
+	if err == nil && table.secondary != nil && table.store.sampleRead() {
+		table.compare(entity, value)
+	}
+	return value, err
+}
+
+// compare reads entity from the secondary and records whether it matches
+// the primary's value. Secondary errors never fail the read.
+func (table *dualWriteTable) compare(entity string, primaryValue interface{}) {
+	table.store.record(dualWriteReadCompared, 1)
+	secondaryValue, err := table.secondary.Get(entity)
+	if _, notFound := err.(*EntityNotFound); notFound {
+		table.store.record(dualWriteReadMissing, 1)
+	} else if err != nil || !reflect.DeepEqual(primaryValue, secondaryValue) {
+		table.store.record(dualWriteReadMismatched, 1)
+	}
+}
+
+// GetWithTimestamp returns the primary's write time, or zero if the primary
+// doesn't record write times.
+func (table *dualWriteTable) GetWithTimestamp(entity string) (interface{}, time.Time, error) {
+	if timestamped, ok := table.primary.(TimestampedTable); ok {
+		return timestamped.GetWithTimestamp(entity)
+	}
+	value, err := table.primary.Get(entity)
+	return value, time.Time{}, err
+}
+
+// Stats returns the primary's stats, since it's the store being read from.
+func (table *dualWriteTable) Stats() (TableStats, error) {
+	statsTable, ok := table.primary.(StatsTable)
+	if !ok {
+		return TableStats{}, fmt.Errorf("%T does not support stats", table.primary)
+	}
+	return statsTable.Stats()
+}
@@ -0,0 +1,163 @@
+package provider
+
+import (
+	"fmt"
+	"testing"
+
+	pc "github.com/featureform/provider/provider_config"
+	pt "github.com/featureform/provider/provider_type"
+)
+
+func TestDualWriteStore(t *testing.T) {
+	primary, secondary := NewLocalOnlineStore(), NewLocalOnlineStore()
+	store := NewDualWriteStore(primary, secondary, nil)
+	table, err := store.CreateTable("feature", "variant", Int)
+	if err != nil {
+		t.Fatalf("Failed to create table: %s", err)
+	}
+	if err := table.Set("a", 1); err != nil {
+		t.Fatalf("Failed to set entity: %s", err)
+	}
+	if err := table.(BatchSettableTable).SetBatch(map[string]interface{}{"b": 2}); err != nil {
+		t.Fatalf("Failed to set batch: %s", err)
+	}
+	for _, inner := range []OnlineStore{primary, secondary} {
+		innerTable, err := inner.GetTable("feature", "variant")
+		if err != nil {
+			t.Fatalf("Failed to get table: %s", err)
+		}
+		for entity, expected := range map[string]interface{}{"a": 1, "b": 2} {
+			if value, err := innerTable.Get(entity); err != nil || value != expected {
+				t.Fatalf("Expected %s to be %v in both stores, got %v %v", entity, expected, value, err)
+			}
+		}
+	}
+	secondaryTable, _ := secondary.GetTable("feature", "variant")
+	if err := secondaryTable.Set("a", 100); err != nil {
+		t.Fatalf("Failed to set entity: %s", err)
+	}
+	if value, err := table.Get("a"); err != nil || value != 1 {
+		t.Fatalf("Expected reads from the primary, got %v %v", value, err)
+	}
+	if err := store.DeleteTable("feature", "variant"); err != nil {
+		t.Fatalf("Failed to delete table: %s", err)
+	}
+}
+
+func TestDualWriteStoreBackfillsSecondaryTable(t *testing.T) {
+	primary, secondary := NewLocalOnlineStore(), NewLocalOnlineStore()
+	if _, err := primary.CreateTable("feature", "variant", Int); err != nil {
+		t.Fatalf("Failed to create table: %s", err)
+	}
+	store := NewDualWriteStore(primary, secondary, nil)
+	table, err := store.GetTable("feature", "variant")
+	if err != nil {
+		t.Fatalf("Failed to get table missing from the secondary: %s", err)
+	}
+	if err := table.Set("a", 1); err != nil {
+		t.Fatalf("Failed to set entity: %s", err)
+	}
+	if _, err := store.CreateTable("feature", "variant", Int); err == nil {
+		t.Fatalf("Expected table to already exist")
+	} else if _, ok := err.(*TableAlreadyExists); !ok {
+		t.Fatalf("Expected TableAlreadyExists, got %T: %s", err, err)
+	}
+	if _, err := secondary.GetTable("feature", "variant"); err != nil {
+		t.Fatalf("Expected table to be created in the secondary: %s", err)
+	}
+}
+
+func TestDualWriteStoreFactory(t *testing.T) {
+	local := pc.OnlineStoreConfig{Type: string(pt.LocalOnline), Config: []byte("{}")}
+	config := pc.DualWriteConfig{Primary: local, Secondary: local}
+	p, err := Get(pt.DualWriteOnline, config.Serialized())
+	if err != nil {
+		t.Fatalf("Failed to create dual-write provider: %s", err)
+	}
+	store, err := p.AsOnlineStore()
+	if err != nil {
+		t.Fatalf("Failed to get online store: %s", err)
+	}
+	if dual := store.(*DualWriteStore); dual.Primary == nil || dual.Secondary == nil {
+		t.Fatalf("Expected both stores to be configured: %+v", dual)
+	}
+	nested := pc.DualWriteConfig{Primary: pc.OnlineStoreConfig{Type: string(pt.DualWriteOnline), Config: []byte(config.Serialized())}}
+	if _, err := Get(pt.DualWriteOnline, nested.Serialized()); err == nil {
+		t.Fatalf("Succeeded in nesting dual-write stores")
+	}
+}
+
+func TestDualWriteStoreCapabilities(t *testing.T) {
+	readThrough := &ReadThroughStore{OnlineStore: NewLocalOnlineStore()}
+	tests := []struct {
+		name               string
+		primary, secondary OnlineStore
+		expected           OnlineCapabilities
+	}{
+		{
+			"No Secondary",
+			NewLocalOnlineStore(), nil,
+			OnlineCapabilities{BatchWrites: true, Stats: true, Timestamps: true, Truncate: true},
+		},
+		{
+			"Limited Secondary",
+			NewLocalOnlineStore(), readThrough,
+			OnlineCapabilities{Stats: true, Timestamps: true},
+		},
+		{
+			"Limited Primary",
+			readThrough, NewLocalOnlineStore(),
+			OnlineCapabilities{Timestamps: true},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := NewDualWriteStore(tt.primary, tt.secondary, nil)
+			if capabilities := store.Capabilities(); capabilities != tt.expected {
+				t.Fatalf("Expected %+v, got %+v", tt.expected, capabilities)
+			}
+		})
+	}
+}
+
+type failingSetTable struct {
+	OnlineStoreTable
+}
+
+func (table failingSetTable) Set(entity string, value interface{}) error {
+	return fmt.Errorf("set failed")
+}
+
+func TestDualWriteStoreDivergence(t *testing.T) {
+	primary, secondary := NewLocalOnlineStore(), NewLocalOnlineStore()
+	store := NewDualWriteStore(primary, secondary, nil)
+	store.CompareReadRate = 1
+	table, err := store.CreateTable("feature", "variant", Int)
+	if err != nil {
+		t.Fatalf("Failed to create table: %s", err)
+	}
+	if err := table.(BatchSettableTable).SetBatch(map[string]interface{}{"a": 1, "b": 2, "c": 3}); err != nil {
+		t.Fatalf("Failed to set batch: %s", err)
+	}
+	secondaryTable, _ := secondary.GetTable("feature", "variant")
+	secondaryTable.Set("b", 200)
+	primaryTable, _ := primary.GetTable("feature", "variant")
+	primaryTable.Set("d", 4)
+	for _, entity := range []string{"a", "b", "d"} {
+		if _, err := table.Get(entity); err != nil {
+			t.Fatalf("Failed to get %s: %s", entity, err)
+		}
+	}
+	failing := &dualWriteTable{store: store, primary: primaryTable, secondary: failingSetTable{secondaryTable}}
+	if err := failing.Set("e", 5); err == nil {
+		t.Fatalf("Expected secondary write error")
+	}
+	store.TolerateSecondaryErrors = true
+	if err := failing.Set("e", 5); err != nil {
+		t.Fatalf("Expected secondary write error to be tolerated: %s", err)
+	}
+	expected := DualWriteDivergence{SecondaryWriteErrors: 1, ReadsCompared: 3, ReadsMissing: 1, ReadsMismatched: 1}
+	if divergence := store.Divergence(); divergence != expected {
+		t.Fatalf("Expected %+v, got %+v", expected, divergence)
+	}
+}
@@ -0,0 +1,308 @@
+//go:build cgo
+// +build cgo
+
+package provider
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	pc "github.com/featureform/provider/provider_config"
+	pt "github.com/featureform/provider/provider_type"
+	_ "github.com/marcboeker/go-duckdb"
+)
+
+// DuckDB is embedded with cgo, so the provider is only registered in builds
+// that have it.
+func init() {
+	if err := RegisterFactory(pt.DuckDBOffline, duckDBOfflineStoreFactory); err != nil {
+		panic(err)
+	}
+}
+
+type duckDBColumnType string
+
+const (
+	dkInt       duckDBColumnType = "INTEGER"
+	dkBigInt    duckDBColumnType = "BIGINT"
+	dkFloat     duckDBColumnType = "FLOAT"
+	dkDouble    duckDBColumnType = "DOUBLE"
+	dkString    duckDBColumnType = "VARCHAR"
+	dkBool      duckDBColumnType = "BOOLEAN"
+	dkTimestamp duckDBColumnType = "TIMESTAMP"
+)
+
+func duckDBOfflineStoreFactory(config pc.SerializedConfig) (Provider, error) {
+	sc := pc.DuckDBConfig{}
+	if err := sc.Deserialize(config); err != nil {
+		return nil, errors.New("invalid duckdb config")
+	}
+	queries := duckDBSQLQueries{}
+	queries.setVariableBinding(MySQLBindingStyle)
+	sgConfig := SQLOfflineStoreConfig{
+		Config:        config,
+		ConnectionURL: sc.Path,
+		Driver:        "duckdb",
+		ProviderType:  pt.DuckDBOffline,
+		QueryImpl:     &queries,
+	}
+
+	store, err := NewSQLOfflineStore(sgConfig)
+	if err != nil {
+		return nil, err
+	}
+	return store, nil
+}
+
+type duckDBSQLQueries struct {
+	defaultOfflineSQLQueries
+}
+
+// duckDBFileSource returns the table function that reads a source from a
+// Parquet or CSV file, and false if the source isn't a file.
+func duckDBFileSource(name string) (string, bool) {
+	path := strings.ReplaceAll(name, "'", "''")
+	lower := strings.ToLower(name)
+	switch {
+	case strings.HasSuffix(lower, ".parquet"):
+		return fmt.Sprintf("read_parquet('%s')", path), true
+	case strings.HasSuffix(lower, ".csv"):
+		return fmt.Sprintf("read_csv_auto('%s', header=true)", path), true
+	default:
+		return "", false
+	}
+}
+
+func (q duckDBSQLQueries) tableExists() string {
+	return "SELECT COUNT(*) FROM information_schema.tables WHERE table_type='BASE TABLE' AND table_name=?"
+}
+
+func (q duckDBSQLQueries) viewExists() string {
+	return "SELECT COUNT(*) FROM information_schema.tables WHERE table_type='VIEW' AND table_name=?"
+}
+
+func (q duckDBSQLQueries) registerResources(db *sql.DB, tableName string, schema ResourceSchema, timestamp bool) error {
+	source := sanitize(schema.SourceTable)
+	if file, ok := duckDBFileSource(schema.SourceTable); ok {
+		source = file
+	}
+	var query string
+	if timestamp {
+		ts := fmt.Sprintf("CAST(%s AS TIMESTAMP)", sanitize(schema.TS))
+		if schema.TSTimezone != "" {
+			ts = fmt.Sprintf("((CAST(%s AS TIMESTAMP) AT TIME ZONE '%s') AT TIME ZONE 'UTC')", sanitize(schema.TS), schema.TSTimezone)
+		}
+		query = fmt.Sprintf("CREATE VIEW %s AS SELECT %s as entity, %s as value, %s as ts FROM %s", sanitize(tableName),
+			sanitize(schema.Entity), sanitize(schema.Value), ts, source)
+	} else {
+		query = fmt.Sprintf("CREATE VIEW %s AS SELECT %s as entity, %s as value, TIMESTAMP '1970-01-01 00:00:00' as ts FROM %s", sanitize(tableName),
+			sanitize(schema.Entity), sanitize(schema.Value), source)
+	}
+	if _, err := db.Exec(query); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (q duckDBSQLQueries) primaryTableRegister(tableName string, sourceName string) string {
+	if file, ok := duckDBFileSource(sourceName); ok {
+		sourceName = file
+	}
+	return fmt.Sprintf("CREATE VIEW %s AS SELECT * FROM %s", sanitize(tableName), sourceName)
+}
+
+func (q duckDBSQLQueries) materializationSelect(tableName string, sourceName string) string {
+	return fmt.Sprintf(
+		"CREATE TABLE %s AS (SELECT entity, value, ts, row_number() over(ORDER BY entity) as row_number FROM "+
+			"(SELECT entity, ts, value, row_number() OVER (PARTITION BY entity ORDER BY ts desc) "+
+			"AS rn FROM %s) t WHERE rn=1)", tableName, sanitize(sourceName))
+}
+
+func (q duckDBSQLQueries) materializationCreate(tableName string, sourceName string) string {
+	return q.materializationSelect(sanitize(tableName), sourceName)
+}
+
+func (q duckDBSQLQueries) materializationUpdate(db *sql.DB, tableName string, sourceName string) error {
+	tempTable := sanitize(fmt.Sprintf("tmp_%s", tableName))
+	return q.atomicUpdate(db, tableName, tempTable, q.materializationSelect(tempTable, sourceName))
+}
+
+func (q duckDBSQLQueries) materializationApproxNumRows() string {
+	return "SELECT estimated_size FROM duckdb_tables() WHERE table_name = ?"
+}
+
+func (q duckDBSQLQueries) materializationDrop(tableName string) string {
+	return fmt.Sprintf("DROP TABLE %s", sanitize(tableName))
+}
+
+func (q duckDBSQLQueries) determineColumnType(valueType ValueType) (string, error) {
+	switch valueType {
+	case Int, Int64:
+		return string(dkBigInt), nil
+	case Int32:
+		return string(dkInt), nil
+	case Float32:
+		return string(dkFloat), nil
+	case Float64:
+		return string(dkDouble), nil
+	case String:
+		return string(dkString), nil
+	case Bool:
+		return string(dkBool), nil
+	case Timestamp:
+		return string(dkTimestamp), nil
+	case NilType:
+		return string(dkString), nil
+	default:
+		return "", fmt.Errorf("cannot find column type for value type: %s", valueType)
+	}
+}
+
+// duckDBResourceTypes maps the generic column types that resource tables are
+// created with to DuckDB's types.
+var duckDBResourceTypes = map[string]duckDBColumnType{
+	"INT":         dkBigInt,
+	"FLOAT8":      dkDouble,
+	"TIMESTAMPTZ": dkTimestamp,
+}
+
+func (q duckDBSQLQueries) newSQLOfflineTable(name string, columnType string) string {
+	if dkType, ok := duckDBResourceTypes[columnType]; ok {
+		columnType = string(dkType)
+	}
+	return fmt.Sprintf("CREATE TABLE %s (entity VARCHAR, value %s, ts TIMESTAMP, UNIQUE (entity, ts))", sanitize(name), columnType)
+}
+
+func (q duckDBSQLQueries) writeExists(table string) string {
+	return fmt.Sprintf("SELECT COUNT(*) FROM %s WHERE entity=? AND ts=?", table)
+}
+
+func (q duckDBSQLQueries) trainingSetCreate(store *sqlOfflineStore, def TrainingSetDef, tableName string, labelName string) error {
+	return q.trainingSetQuery(store, def, tableName, labelName, false)
+}
+
+func (q duckDBSQLQueries) trainingSetUpdate(store *sqlOfflineStore, def TrainingSetDef, tableName string, labelName string) error {
+	return q.trainingSetQuery(store, def, tableName, labelName, true)
+}
+
+// trainingSetQuery joins each feature's latest value at or before every
+// label with an ASOF join. Lag features shift the feature's timestamps
+// forward by the lag.
+func (q duckDBSQLQueries) trainingSetQuery(store *sqlOfflineStore, def TrainingSetDef, tableName string, labelName string, isUpdate bool) error {
+	columns := make([]string, 0)
+	joins := ""
+	for i, feature := range def.Features {
+		featureTable, err := store.getResourceTableName(feature)
+		if err != nil {
+			return err
+		}
+		sanitizedName := sanitize(featureTable)
+		tableJoinAlias := fmt.Sprintf("t%d", i+1)
+		columns = append(columns, fmt.Sprintf("%s.value AS %s", tableJoinAlias, sanitizedName))
+		joins = fmt.Sprintf("%s ASOF LEFT JOIN (SELECT entity, value, ts FROM %s) AS %s ON t0.entity=%s.entity AND t0.ts>=%s.ts",
+			joins, sanitizedName, tableJoinAlias, tableJoinAlias, tableJoinAlias)
+	}
+	for i, lagFeature := range def.LagFeatures {
+		lagFeaturesOffset := len(def.Features)
+		featureTable, err := store.getResourceTableName(ResourceID{lagFeature.FeatureName, lagFeature.FeatureVariant, Feature})
+		if err != nil {
+			return err
+		}
+		lagColumnName := sanitize(lagFeature.LagName)
+		if lagFeature.LagName == "" {
+			lagColumnName = sanitize(fmt.Sprintf("%s_lag_%s", featureTable, lagFeature.LagDelta))
+		}
+		tableJoinAlias := fmt.Sprintf("t%d", lagFeaturesOffset+i+1)
+		columns = append(columns, fmt.Sprintf("%s.value AS %s", tableJoinAlias, lagColumnName))
+		joins = fmt.Sprintf("%s ASOF LEFT JOIN (SELECT entity, value, ts + to_microseconds(%d) AS ts FROM %s) AS %s ON t0.entity=%s.entity AND t0.ts>=%s.ts",
+			joins, lagFeature.LagDelta.Microseconds(), sanitize(featureTable), tableJoinAlias, tableJoinAlias, tableJoinAlias)
+	}
+	columnStr := strings.Join(columns, ", ")
+	create := func(name string) string {
+		return fmt.Sprintf("CREATE TABLE %s AS SELECT %s, t0.value AS label FROM %s AS t0%s", name, columnStr, sanitize(labelName), joins)
+	}
+	if !isUpdate {
+		if _, err := store.db.Exec(create(sanitize(tableName))); err != nil {
+			return err
+		}
+		return nil
+	}
+	tempTable := sanitize(fmt.Sprintf("tmp_%s", tableName))
+	return q.atomicUpdate(store.db, tableName, tempTable, create(tempTable))
+}
+
+// atomicUpdate builds the new table and swaps it in for the old one in a
+// single transaction.
+func (q duckDBSQLQueries) atomicUpdate(db *sql.DB, tableName string, tempName string, query string) error {
+	transaction := fmt.Sprintf(
+		"BEGIN TRANSACTION;"+
+			"DROP TABLE IF EXISTS %s;"+
+			"%s;"+
+			"DROP TABLE %s;"+
+			"ALTER TABLE %s RENAME TO %s;"+
+			"COMMIT;", tempName, query, sanitize(tableName), tempName, sanitize(tableName))
+	if _, err := db.Exec(transaction); err != nil {
+		db.Exec("ROLLBACK")
+		return err
+	}
+	return nil
+}
+
+func (q duckDBSQLQueries) castTableItemType(v interface{}, t interface{}) interface{} {
+	if v == nil {
+		return v
+	}
+	switch t {
+	case dkInt:
+		return v.(int32)
+	case dkBigInt:
+		return int(v.(int64))
+	case dkFloat:
+		return v.(float32)
+	case dkDouble:
+		return v.(float64)
+	case dkString:
+		return v.(string)
+	case dkBool:
+		return v.(bool)
+	case dkTimestamp:
+		return v.(time.Time).UTC()
+	default:
+		return v
+	}
+}
+
+func (q duckDBSQLQueries) getValueColumnType(t *sql.ColumnType) interface{} {
+	switch t.DatabaseTypeName() {
+	case "INTEGER":
+		return dkInt
+	case "BIGINT":
+		return dkBigInt
+	case "FLOAT":
+		return dkFloat
+	case "DOUBLE":
+		return dkDouble
+	case "BOOLEAN":
+		return dkBool
+	case "TIMESTAMP", "TIMESTAMP_S", "TIMESTAMP_MS", "TIMESTAMP_NS", "TIMESTAMPTZ":
+		return dkTimestamp
+	}
+	return dkString
+}
+
+func (q duckDBSQLQueries) numRows(n interface{}) (int64, error) {
+	return n.(int64), nil
+}
+
+func (q duckDBSQLQueries) transformationCreate(name string, query string) string {
+	return fmt.Sprintf("CREATE TABLE %s AS %s", sanitize(name), query)
+}
+
+func (q duckDBSQLQueries) transformationUpdate(db *sql.DB, tableName string, query string) error {
+	tempName := sanitize(fmt.Sprintf("tmp_%s", tableName))
+	fullQuery := fmt.Sprintf("CREATE TABLE %s AS %s", tempName, query)
+	return q.atomicUpdate(db, tableName, tempName, fullQuery)
+}
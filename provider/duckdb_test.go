@@ -0,0 +1,300 @@
+//go:build cgo
+// +build cgo
+
+package provider
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	pc "github.com/featureform/provider/provider_config"
+	pt "github.com/featureform/provider/provider_type"
+)
+
+func TestDuckDBFileSource(t *testing.T) {
+	tests := map[string]string{
+		"transactions":           "",
+		"data/transactions.csv":  "read_csv_auto('data/transactions.csv', header=true)",
+		"s3://bucket/tx.PARQUET": "read_parquet('s3://bucket/tx.PARQUET')",
+		"data/o'brien.parquet":   "read_parquet('data/o''brien.parquet')",
+	}
+	for source, expected := range tests {
+		actual, ok := duckDBFileSource(source)
+		if actual != expected || ok != (expected != "") {
+			t.Errorf("Expected %s to be read with %q, got %q", source, expected, actual)
+		}
+	}
+}
+
+func TestDuckDBOfflineStore(t *testing.T) {
+	csv := filepath.Join(t.TempDir(), "transactions.csv")
+	rows := "user,amount,fraud,ts\n" +
+		"a,10,false,2023-01-01 00:00:00\n" +
+		"a,20,true,2023-01-03 00:00:00\n" +
+		"b,5,false,2023-01-02 00:00:00\n"
+	if err := os.WriteFile(csv, []byte(rows), 0644); err != nil {
+		t.Fatalf("Failed to write source: %s", err)
+	}
+	provider, err := Get(pt.DuckDBOffline, (&pc.DuckDBConfig{}).Serialize())
+	if err != nil {
+		t.Fatalf("Failed to get provider: %s", err)
+	}
+	store, err := provider.AsOfflineStore()
+	if err != nil {
+		t.Fatalf("Failed to use provider as OfflineStore: %s", err)
+	}
+	defer store.Close()
+
+	primaryID := ResourceID{"transactions", "default", Primary}
+	primary, err := store.RegisterPrimaryFromSourceTable(primaryID, csv)
+	if err != nil {
+		t.Fatalf("Failed to register primary: %s", err)
+	}
+	if n, err := primary.NumRows(); err != nil || n != 3 {
+		t.Fatalf("Expected 3 rows, got %d: %v", n, err)
+	}
+	primaryName, err := GetPrimaryTableName(primaryID)
+	if err != nil {
+		t.Fatalf("Failed to get primary name: %s", err)
+	}
+	featureID := ResourceID{"amount", "default", Feature}
+	if _, err := store.RegisterResourceFromSourceTable(featureID, ResourceSchema{Entity: "user", Value: "amount", TS: "ts", SourceTable: primaryName}); err != nil {
+		t.Fatalf("Failed to register feature: %s", err)
+	}
+	labelID := ResourceID{"fraud", "default", Label}
+	if _, err := store.RegisterResourceFromSourceTable(labelID, ResourceSchema{Entity: "user", Value: "fraud", TS: "ts", SourceTable: primaryName}); err != nil {
+		t.Fatalf("Failed to register label: %s", err)
+	}
+
+	mat, err := store.CreateMaterialization(featureID)
+	if err != nil {
+		t.Fatalf("Failed to create materialization: %s", err)
+	}
+	it, err := mat.IterateSegment(0, 10)
+	if err != nil {
+		t.Fatalf("Failed to iterate materialization: %s", err)
+	}
+	latest := map[string]interface{}{}
+	for it.Next() {
+		latest[it.Value().Entity] = it.Value().Value
+	}
+	if err := it.Err(); err != nil {
+		t.Fatalf("Failed to iterate materialization: %s", err)
+	}
+	if expected := map[string]interface{}{"a": int(20), "b": int(5)}; !reflect.DeepEqual(latest, expected) {
+		t.Fatalf("Expected latest values %v, got %v", expected, latest)
+	}
+	if _, err := store.UpdateMaterialization(featureID); err != nil {
+		t.Fatalf("Failed to update materialization: %s", err)
+	}
+
+	def := TrainingSetDef{
+		ID:       ResourceID{"fraud_training", "default", TrainingSet},
+		Label:    labelID,
+		Features: []ResourceID{featureID},
+		LagFeatures: []LagFeatureDef{
+			{FeatureName: "amount", FeatureVariant: "default", LagName: "prev_amount", LagDelta: 24 * time.Hour},
+		},
+	}
+	if err := store.CreateTrainingSet(def); err != nil {
+		t.Fatalf("Failed to create training set: %s", err)
+	}
+	if err := store.UpdateTrainingSet(def); err != nil {
+		t.Fatalf("Failed to update training set: %s", err)
+	}
+	ts, err := store.GetTrainingSet(def.ID)
+	if err != nil {
+		t.Fatalf("Failed to get training set: %s", err)
+	}
+	actual := map[string]bool{}
+	for ts.Next() {
+		actual[fmt.Sprint(ts.Features(), ts.Label())] = true
+	}
+	if err := ts.Err(); err != nil {
+		t.Fatalf("Failed to iterate training set: %s", err)
+	}
+	expected := map[string]bool{
+		fmt.Sprint([]interface{}{int(10), nil}, false):    true,
+		fmt.Sprint([]interface{}{int(20), int(10)}, true): true,
+		fmt.Sprint([]interface{}{int(5), nil}, false):     true,
+	}
+	if !reflect.DeepEqual(actual, expected) {
+		t.Fatalf("Expected training set %v, got %v", expected, actual)
+	}
+}
+
+func TestDuckDBDedupMaterialization(t *testing.T) {
+	csv := filepath.Join(t.TempDir(), "transactions.csv")
+	rows := "user,amount,ts\n" +
+		"a,10,2023-01-01 00:00:00\n" +
+		"a,30,2023-01-02 00:00:00\n" +
+		"a,20,2023-01-03 00:00:00\n" +
+		"b,5,2023-01-02 00:00:00\n"
+	if err := os.WriteFile(csv, []byte(rows), 0644); err != nil {
+		t.Fatalf("Failed to write source: %s", err)
+	}
+	provider, err := Get(pt.DuckDBOffline, (&pc.DuckDBConfig{}).Serialize())
+	if err != nil {
+		t.Fatalf("Failed to get provider: %s", err)
+	}
+	store, err := provider.AsOfflineStore()
+	if err != nil {
+		t.Fatalf("Failed to use provider as OfflineStore: %s", err)
+	}
+	defer store.Close()
+	primaryID := ResourceID{"transactions", "default", Primary}
+	if _, err := store.RegisterPrimaryFromSourceTable(primaryID, csv); err != nil {
+		t.Fatalf("Failed to register primary: %s", err)
+	}
+	primaryName, err := GetPrimaryTableName(primaryID)
+	if err != nil {
+		t.Fatalf("Failed to get primary name: %s", err)
+	}
+	tests := []struct {
+		name     string
+		strategy DedupStrategy
+		expected map[string]string
+	}{
+		{"latest", DedupStrategy{}, map[string]string{"a": "20", "b": "5"}},
+		{"max", DedupStrategy{Type: DedupMax}, map[string]string{"a": "30", "b": "5"}},
+		{"min", DedupStrategy{Type: DedupMin}, map[string]string{"a": "10", "b": "5"}},
+		{"sum", DedupStrategy{Type: DedupExpression, Expression: "CAST(SUM(value) AS BIGINT)"}, map[string]string{"a": "60", "b": "5"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			featureID := ResourceID{"amount_" + tt.name, "default", Feature}
+			schema := ResourceSchema{Entity: "user", Value: "amount", TS: "ts", SourceTable: primaryName}
+			if _, err := store.RegisterResourceFromSourceTable(featureID, schema); err != nil {
+				t.Fatalf("Failed to register feature: %s", err)
+			}
+			dedupStore := store.(DedupOfflineStore)
+			if _, err := dedupStore.CreateDedupMaterialization(featureID, tt.strategy); err != nil {
+				t.Fatalf("Failed to create materialization: %s", err)
+			}
+			mat, err := dedupStore.UpdateDedupMaterialization(featureID, tt.strategy)
+			if err != nil {
+				t.Fatalf("Failed to update materialization: %s", err)
+			}
+			it, err := mat.IterateSegment(0, 10)
+			if err != nil {
+				t.Fatalf("Failed to iterate materialization: %s", err)
+			}
+			values := map[string]string{}
+			for it.Next() {
+				values[it.Value().Entity] = fmt.Sprint(it.Value().Value)
+				if ts := it.Value().TS; it.Value().Entity == "a" && !ts.Equal(time.Date(2023, 1, 3, 0, 0, 0, 0, time.UTC)) {
+					t.Fatalf("Expected the latest timestamp, got %s", ts)
+				}
+			}
+			if err := it.Err(); err != nil {
+				t.Fatalf("Failed to iterate materialization: %s", err)
+			}
+			if !reflect.DeepEqual(values, tt.expected) {
+				t.Fatalf("Expected values %v, got %v", tt.expected, values)
+			}
+		})
+	}
+}
+
+func TestDuckDBApproxNumRows(t *testing.T) {
+	csv := filepath.Join(t.TempDir(), "transactions.csv")
+	rows := "user,amount,ts\n" +
+		"a,10,2023-01-01 00:00:00\n" +
+		"b,5,2023-01-02 00:00:00\n"
+	if err := os.WriteFile(csv, []byte(rows), 0644); err != nil {
+		t.Fatalf("Failed to write source: %s", err)
+	}
+	provider, err := Get(pt.DuckDBOffline, (&pc.DuckDBConfig{}).Serialize())
+	if err != nil {
+		t.Fatalf("Failed to get provider: %s", err)
+	}
+	store, err := provider.AsOfflineStore()
+	if err != nil {
+		t.Fatalf("Failed to use provider as OfflineStore: %s", err)
+	}
+	defer store.Close()
+	primaryID := ResourceID{"transactions", "default", Primary}
+	if _, err := store.RegisterPrimaryFromSourceTable(primaryID, csv); err != nil {
+		t.Fatalf("Failed to register primary: %s", err)
+	}
+	primaryName, err := GetPrimaryTableName(primaryID)
+	if err != nil {
+		t.Fatalf("Failed to get primary name: %s", err)
+	}
+	featureID := ResourceID{"amount", "default", Feature}
+	schema := ResourceSchema{Entity: "user", Value: "amount", TS: "ts", SourceTable: primaryName}
+	if _, err := store.RegisterResourceFromSourceTable(featureID, schema); err != nil {
+		t.Fatalf("Failed to register feature: %s", err)
+	}
+	mat, err := store.CreateMaterialization(featureID)
+	if err != nil {
+		t.Fatalf("Failed to create materialization: %s", err)
+	}
+	counter, ok := mat.(ApproximateRowCounter)
+	if !ok {
+		t.Fatalf("Expected DuckDB materializations to estimate their rows")
+	}
+	estimate, err := counter.ApproxNumRows()
+	if err != nil {
+		t.Fatalf("Failed to estimate rows: %s", err)
+	}
+	if estimate != 2 {
+		t.Fatalf("Expected an estimate of 2 rows, got %d", estimate)
+	}
+}
+
+func TestDuckDBLookupMaterializedEntities(t *testing.T) {
+	csv := filepath.Join(t.TempDir(), "transactions.csv")
+	rows := "user,amount,ts\n" +
+		"a,10,2023-01-01 00:00:00\n" +
+		"a,20,2023-01-02 00:00:00\n" +
+		"b,5,2023-01-02 00:00:00\n" +
+		"c,7,2023-01-02 00:00:00\n"
+	if err := os.WriteFile(csv, []byte(rows), 0644); err != nil {
+		t.Fatalf("Failed to write source: %s", err)
+	}
+	provider, err := Get(pt.DuckDBOffline, (&pc.DuckDBConfig{}).Serialize())
+	if err != nil {
+		t.Fatalf("Failed to get provider: %s", err)
+	}
+	store, err := provider.AsOfflineStore()
+	if err != nil {
+		t.Fatalf("Failed to use provider as OfflineStore: %s", err)
+	}
+	defer store.Close()
+	primaryID := ResourceID{"transactions", "default", Primary}
+	if _, err := store.RegisterPrimaryFromSourceTable(primaryID, csv); err != nil {
+		t.Fatalf("Failed to register primary: %s", err)
+	}
+	primaryName, err := GetPrimaryTableName(primaryID)
+	if err != nil {
+		t.Fatalf("Failed to get primary name: %s", err)
+	}
+	featureID := ResourceID{"amount", "default", Feature}
+	schema := ResourceSchema{Entity: "user", Value: "amount", TS: "ts", SourceTable: primaryName}
+	if _, err := store.RegisterResourceFromSourceTable(featureID, schema); err != nil {
+		t.Fatalf("Failed to register feature: %s", err)
+	}
+	if _, err := store.CreateMaterialization(featureID); err != nil {
+		t.Fatalf("Failed to create materialization: %s", err)
+	}
+	it, err := store.(MaterializedEntityLookup).LookupMaterializedEntities(featureID, []string{"a", "c", "missing"})
+	if err != nil {
+		t.Fatalf("Failed to look up entities: %s", err)
+	}
+	defer it.Close()
+	values := map[string]string{}
+	for it.Next() {
+		values[it.Value().Entity] = fmt.Sprint(it.Value().Value)
+	}
+	if err := it.Err(); err != nil {
+		t.Fatalf("Failed to iterate entities: %s", err)
+	}
+	if len(values) != 2 || values["a"] != "20" || values["c"] != "7" {
+		t.Fatalf("Expected the materialized values of a and c, got %v", values)
+	}
+}
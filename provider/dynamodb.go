@@ -3,10 +3,13 @@ package provider
 import (
 	"encoding/json"
 	"fmt"
+	"sort"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
 	"github.com/aws/aws-sdk-go/aws/credentials"
 	"github.com/aws/aws-sdk-go/aws/session"
 	"github.com/aws/aws-sdk-go/service/dynamodb"
@@ -30,6 +33,7 @@ type dynamodbOnlineStore struct {
 	prefix string
 	BaseProvider
 	timeout int
+	tags    []*dynamodb.Tag
 }
 
 type dynamodbOnlineTable struct {
@@ -41,6 +45,9 @@ type dynamodbOnlineTable struct {
 type dynamodbItem struct {
 	Entity string `dynamodbav:"Entity"`
 	Value  string `dynamodbav:"FeatureValue"`
+	// UpdatedAt is the last write time in Unix nanoseconds. It's zero for
+	// items written before write times were recorded.
+	UpdatedAt int64 `dynamodbav:"UpdatedAt"`
 }
 
 type Metadata struct {
@@ -68,16 +75,36 @@ func NewDynamodbOnlineStore(options *pc.DynamodbConfig) (*dynamodbOnlineStore, e
 	}
 	sess := session.Must(session.NewSession(config))
 	dynamodbClient := dynamodb.New(sess)
-	if err := CreateMetadataTable(dynamodbClient); err != nil {
+	tags := dynamodbTags(options.Tags)
+	if err := CreateMetadataTable(dynamodbClient, tags); err != nil {
 		return nil, fmt.Errorf("could not create metadata table: %v", err)
 	}
 	return &dynamodbOnlineStore{dynamodbClient, options.Prefix, BaseProvider{
 		ProviderType:   pt.DynamoDBOnline,
 		ProviderConfig: options.Serialized(),
-	}, 360,
+	}, 360, tags,
 	}, nil
 }
 
+// dynamodbTags converts the configured tags to DynamoDB's format, sorted by
+// key so that tables are created with the same request every time. It
+// returns nil if there are no tags, as DynamoDB rejects an empty list.
+func dynamodbTags(tags map[string]string) []*dynamodb.Tag {
+	if len(tags) == 0 {
+		return nil
+	}
+	keys := make([]string, 0, len(tags))
+	for key := range tags {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	dynamoTags := make([]*dynamodb.Tag, len(keys))
+	for i, key := range keys {
+		dynamoTags[i] = &dynamodb.Tag{Key: aws.String(key), Value: aws.String(tags[key])}
+	}
+	return dynamoTags
+}
+
 func (store *dynamodbOnlineStore) AsOnlineStore() (OnlineStore, error) {
 	return store, nil
 }
@@ -87,7 +114,9 @@ func (store *dynamodbOnlineStore) Close() error {
 	return nil
 }
 
-func CreateMetadataTable(dynamodbClient *dynamodb.DynamoDB) error {
+// CreateMetadataTable creates the table that records the value type of each
+// feature table, if it doesn't exist yet. The tags are applied if it's created.
+func CreateMetadataTable(dynamodbClient *dynamodb.DynamoDB, tags []*dynamodb.Tag) error {
 	params := &dynamodb.CreateTableInput{
 		TableName: aws.String("Metadata"),
 		AttributeDefinitions: []*dynamodb.AttributeDefinition{
@@ -106,6 +135,7 @@ func CreateMetadataTable(dynamodbClient *dynamodb.DynamoDB) error {
 			ReadCapacityUnits:  aws.Int64(10),
 			WriteCapacityUnits: aws.Int64(5),
 		},
+		Tags: tags,
 	}
 	describeMetadataTableParams := &dynamodb.DescribeTableInput{
 		TableName: aws.String("Metadata"),
@@ -140,10 +170,14 @@ func CreateMetadataTable(dynamodbClient *dynamodb.DynamoDB) error {
 }
 
 func (store *dynamodbOnlineStore) UpdateMetadataTable(tablename string, valueType ValueType) error {
+	serializedType, err := serializeValueType(valueType)
+	if err != nil {
+		return err
+	}
 	input := &dynamodb.UpdateItemInput{
 		ExpressionAttributeValues: map[string]*dynamodb.AttributeValue{
 			":valtype": {
-				S: aws.String(string(valueType.Scalar())),
+				S: aws.String(serializedType),
 			},
 		},
 		TableName: aws.String("Metadata"),
@@ -154,7 +188,7 @@ func (store *dynamodbOnlineStore) UpdateMetadataTable(tablename string, valueTyp
 		},
 		UpdateExpression: aws.String("set ValueType = :valtype"),
 	}
-	_, err := store.client.UpdateItem(input)
+	_, err = store.client.UpdateItem(input)
 	return err
 }
 
@@ -180,7 +214,7 @@ func (store *dynamodbOnlineStore) GetFromMetadataTable(tablename string) (ValueT
 	if err != nil {
 		return NilType, err
 	}
-	return ScalarType(metadata_item.Valuetype), nil
+	return deserializeValueType(metadata_item.Valuetype)
 }
 
 func GetTablename(prefix, feature, variant string) string {
@@ -204,6 +238,18 @@ func (store *dynamodbOnlineStore) CreateTable(feature, variant string, valueType
 	if err == nil {
 		return nil, &TableAlreadyExists{feature, variant}
 	}
+	err = store.UpdateMetadataTable(GetTablename(store.prefix, feature, variant), valueType)
+	if err != nil {
+		return nil, err
+	}
+	if err := store.createTable(feature, variant); err != nil {
+		return nil, err
+	}
+	return &dynamodbOnlineTable{store.client, key, valueType}, nil
+}
+
+// createTable creates the feature's table and waits for it to be active.
+func (store *dynamodbOnlineStore) createTable(feature, variant string) error {
 	params := &dynamodb.CreateTableInput{
 		TableName: aws.String(GetTablename(store.prefix, feature, variant)),
 		AttributeDefinitions: []*dynamodb.AttributeDefinition{
@@ -219,33 +265,46 @@ func (store *dynamodbOnlineStore) CreateTable(feature, variant string, valueType
 				KeyType:       aws.String("HASH"),
 			},
 		},
+		Tags: store.tags,
 	}
-	err = store.UpdateMetadataTable(GetTablename(store.prefix, feature, variant), valueType)
+	_, err := store.client.CreateTable(params)
 	if err != nil {
-		return nil, err
-	}
-	_, err = store.client.CreateTable(params)
-	if err != nil {
-		return nil, err
+		return err
 	}
 	describeTableParams := &dynamodb.DescribeTableInput{TableName: aws.String(GetTablename(store.prefix, feature, variant))}
 	describeTableOutput, err := store.client.DescribeTable(describeTableParams)
 	if err != nil {
-		return nil, err
+		return err
 	}
 	duration := 0
 	for describeTableOutput == nil || *describeTableOutput.Table.TableStatus != "ACTIVE" {
 		describeTableOutput, err = store.client.DescribeTable(describeTableParams)
 		if err != nil {
-			return nil, err
+			return err
 		}
 		time.Sleep(5 * time.Second)
 		duration += 5
 		if duration > store.timeout {
-			return nil, fmt.Errorf("timeout creating table")
+			return fmt.Errorf("timeout creating table")
 		}
 	}
-	return &dynamodbOnlineTable{store.client, key, valueType}, nil
+	return nil
+}
+
+// Truncate drops and recreates the table, since DynamoDB can only delete
+// items one at a time. Its entry in the metadata table is kept.
+func (store *dynamodbOnlineStore) Truncate(feature, variant string) error {
+	if _, err := store.GetTable(feature, variant); err != nil {
+		return err
+	}
+	tableName := aws.String(GetTablename(store.prefix, feature, variant))
+	if _, err := store.client.DeleteTable(&dynamodb.DeleteTableInput{TableName: tableName}); err != nil {
+		return fmt.Errorf("could not delete table: %w", err)
+	}
+	if err := store.client.WaitUntilTableNotExists(&dynamodb.DescribeTableInput{TableName: tableName}); err != nil {
+		return fmt.Errorf("could not wait for table deletion: %w", err)
+	}
+	return store.createTable(feature, variant)
 }
 
 func (store *dynamodbOnlineStore) DeleteTable(feature, variant string) error {
@@ -259,12 +318,42 @@ func (store *dynamodbOnlineStore) DeleteTable(feature, variant string) error {
 	return nil
 }
 
+func (store *dynamodbOnlineStore) Capabilities() OnlineCapabilities {
+	return OnlineCapabilities{
+		BatchWrites: true,
+		Stats:       true,
+		Increment:   true,
+		Append:      true,
+		Timestamps:  true,
+		Truncate:    true,
+	}
+}
+
+func (table dynamodbOnlineTable) serialize(value interface{}) (string, error) {
+	if t, isTime := value.(time.Time); isTime {
+		return t.Format(time.RFC3339Nano), nil
+	}
+	if composite, isComposite := table.valueType.(compositeType); isComposite {
+		encoded, err := serializeComposite(composite, value)
+		if err != nil {
+			return "", err
+		}
+		return string(encoded), nil
+	}
+	return fmt.Sprintf("%v", value), nil
+}
+
 func (table dynamodbOnlineTable) Set(entity string, value interface{}) error {
+	serialized, err := table.serialize(value)
+	if err != nil {
+		return err
+	}
 	input := &dynamodb.UpdateItemInput{
 		ExpressionAttributeValues: map[string]*dynamodb.AttributeValue{
 			":val": {
-				S: aws.String(fmt.Sprintf("%v", value)),
+				S: aws.String(serialized),
 			},
+			":updated": updatedAtAttribute(),
 		},
 		TableName: aws.String(GetTablename(table.key.Prefix, table.key.Feature, table.key.Variant)),
 		Key: map[string]*dynamodb.AttributeValue{
@@ -272,13 +361,106 @@ func (table dynamodbOnlineTable) Set(entity string, value interface{}) error {
 				S: aws.String(entity),
 			},
 		},
-		UpdateExpression: aws.String("set FeatureValue = :val"),
+		UpdateExpression: aws.String("set FeatureValue = :val, UpdatedAt = :updated"),
 	}
-	_, err := table.client.UpdateItem(input)
+	_, err = table.client.UpdateItem(input)
 	return err
 }
 
+const (
+	// dynamoBatchSize is the most items BatchWriteItem accepts per request.
+	dynamoBatchSize = 25
+	// dynamoBatchAttempts bounds how many times unprocessed items, usually
+	// caused by throttling, are retried.
+	dynamoBatchAttempts = 5
+)
+
+func (table dynamodbOnlineTable) SetBatch(values map[string]interface{}) error {
+	tableName := GetTablename(table.key.Prefix, table.key.Feature, table.key.Variant)
+	requests := make([]*dynamodb.WriteRequest, 0, len(values))
+	for entity, value := range values {
+		serialized, err := table.serialize(value)
+		if err != nil {
+			return err
+		}
+		requests = append(requests, &dynamodb.WriteRequest{
+			PutRequest: &dynamodb.PutRequest{
+				Item: map[string]*dynamodb.AttributeValue{
+					table.key.Feature: {S: aws.String(entity)},
+					"FeatureValue":    {S: aws.String(serialized)},
+					"UpdatedAt":       updatedAtAttribute(),
+				},
+			},
+		})
+	}
+	for start := 0; start < len(requests); start += dynamoBatchSize {
+		end := start + dynamoBatchSize
+		if end > len(requests) {
+			end = len(requests)
+		}
+		pending := map[string][]*dynamodb.WriteRequest{tableName: requests[start:end]}
+		for attempt := 0; len(pending) > 0; attempt++ {
+			if attempt == dynamoBatchAttempts {
+				return fmt.Errorf("%d items were still unprocessed after %d attempts", len(pending[tableName]), attempt)
+			}
+			if attempt > 0 {
+				time.Sleep(time.Duration(attempt*attempt) * 50 * time.Millisecond)
+			}
+			output, err := table.client.BatchWriteItem(&dynamodb.BatchWriteItemInput{RequestItems: pending})
+			if err != nil {
+				return err
+			}
+			pending = output.UnprocessedItems
+		}
+	}
+	return nil
+}
+
+func updatedAtAttribute() *dynamodb.AttributeValue {
+	return &dynamodb.AttributeValue{
+		N: aws.String(strconv.FormatInt(time.Now().UnixNano(), 10)),
+	}
+}
+
+// Stats uses the item count and size that DynamoDB updates about every six
+// hours, so recent writes may not be reflected.
+func (table dynamodbOnlineTable) Stats() (TableStats, error) {
+	input := &dynamodb.DescribeTableInput{
+		TableName: aws.String(GetTablename(table.key.Prefix, table.key.Feature, table.key.Variant)),
+	}
+	output, err := table.client.DescribeTable(input)
+	if err != nil {
+		return TableStats{}, err
+	}
+	return TableStats{
+		Entities: aws.Int64Value(output.Table.ItemCount),
+		Bytes:    aws.Int64Value(output.Table.TableSizeBytes),
+	}, nil
+}
+
 func (table dynamodbOnlineTable) Get(entity string) (interface{}, error) {
+	val, _, err := table.GetWithTimestamp(entity)
+	return val, err
+}
+
+func (table dynamodbOnlineTable) GetWithTimestamp(entity string) (interface{}, time.Time, error) {
+	dynamodb_item, err := table.getItem(entity)
+	if err != nil {
+		return nil, time.Time{}, err
+	}
+	val, err := parseDynamoValue(table.valueType, dynamodb_item.Value)
+	if err != nil {
+		return nil, time.Time{}, err
+	}
+	var updated time.Time
+	if dynamodb_item.UpdatedAt != 0 {
+		updated = time.Unix(0, dynamodb_item.UpdatedAt)
+	}
+	return val, updated, nil
+}
+
+// getItem returns the entity's item with its value as it's stored.
+func (table dynamodbOnlineTable) getItem(entity string) (dynamodbItem, error) {
 	input := &dynamodb.GetItemInput{
 		TableName: aws.String(GetTablename(table.key.Prefix, table.key.Feature, table.key.Variant)),
 		Key: map[string]*dynamodb.AttributeValue{
@@ -288,36 +470,177 @@ func (table dynamodbOnlineTable) Get(entity string) (interface{}, error) {
 		},
 	}
 	output_val, err := table.client.GetItem(input)
-	if len(output_val.Item) == 0 {
-		return nil, &EntityNotFound{entity}
-	}
 	if err != nil {
-		return nil, err
+		return dynamodbItem{}, err
+	}
+	if len(output_val.Item) == 0 {
+		return dynamodbItem{}, &EntityNotFound{entity}
 	}
 	dynamodb_item := dynamodbItem{}
-	err = dynamodbattribute.UnmarshalMap(output_val.Item, &dynamodb_item)
-	if err != nil {
-		return nil, &EntityNotFound{entity}
+	if err := dynamodbattribute.UnmarshalMap(output_val.Item, &dynamodb_item); err != nil {
+		return dynamodbItem{}, &EntityNotFound{entity}
+	}
+	return dynamodb_item, nil
+}
+
+func parseDynamoValue(valueType ValueType, value string) (interface{}, error) {
+	if composite, isComposite := valueType.(compositeType); isComposite {
+		return deserializeComposite(composite, []byte(value))
 	}
 	var result interface{}
 	var result_float float64
-	switch table.valueType {
+	var err error
+	switch valueType {
 	case NilType, String:
-		result, err = dynamodb_item.Value, nil
+		result, err = value, nil
 	case Int:
-		result, err = strconv.Atoi(dynamodb_item.Value)
+		result, err = strconv.Atoi(value)
 	case Int64:
-		result, err = strconv.ParseInt(dynamodb_item.Value, 0, 64)
+		result, err = strconv.ParseInt(value, 0, 64)
 	case Float32:
-		result_float, err = strconv.ParseFloat(dynamodb_item.Value, 32)
+		result_float, err = strconv.ParseFloat(value, 32)
 		result = float32(result_float)
 	case Float64:
-		result, err = strconv.ParseFloat(dynamodb_item.Value, 64)
+		result, err = strconv.ParseFloat(value, 64)
 	case Bool:
-		result, err = strconv.ParseBool(dynamodb_item.Value)
+		result, err = strconv.ParseBool(value)
+	case Timestamp, Datetime:
+		result, err = time.Parse(time.RFC3339Nano, value)
 	}
 	if err != nil {
 		return nil, err
 	}
 	return result, nil
 }
+
+// Increment uses a conditional write on the previously read value so that
+// concurrent increments never overwrite one another. Since values are stored
+// as strings, DynamoDB's native ADD action can't be used. The condition
+// compares the value as it's stored, which may not be in the form it would
+// be formatted in, like "1.50" or a value written by another client.
+func (table dynamodbOnlineTable) Increment(entity string, delta interface{}) (interface{}, error) {
+	const maxAttempts = 10
+	for attempt := 0; attempt < maxAttempts; attempt++ {
+		item, err := table.getItem(entity)
+		_, notFound := err.(*EntityNotFound)
+		if err != nil && !notFound {
+			return nil, err
+		}
+		var current interface{}
+		if !notFound {
+			if current, err = parseDynamoValue(table.valueType, item.Value); err != nil {
+				return nil, err
+			}
+		}
+		updated, err := addNumeric(table.valueType, current, delta)
+		if err != nil {
+			return nil, err
+		}
+		input := &dynamodb.UpdateItemInput{
+			ExpressionAttributeValues: map[string]*dynamodb.AttributeValue{
+				":val": {
+					S: aws.String(fmt.Sprintf("%v", updated)),
+				},
+				":updated": updatedAtAttribute(),
+			},
+			TableName: aws.String(GetTablename(table.key.Prefix, table.key.Feature, table.key.Variant)),
+			Key: map[string]*dynamodb.AttributeValue{
+				table.key.Feature: {
+					S: aws.String(entity),
+				},
+			},
+			UpdateExpression: aws.String("set FeatureValue = :val, UpdatedAt = :updated"),
+		}
+		if notFound {
+			input.ConditionExpression = aws.String("attribute_not_exists(FeatureValue)")
+		} else {
+			input.ConditionExpression = aws.String("FeatureValue = :old")
+			input.ExpressionAttributeValues[":old"] = &dynamodb.AttributeValue{
+				S: aws.String(item.Value),
+			}
+		}
+		_, err = table.client.UpdateItem(input)
+		if aerr, ok := err.(awserr.Error); ok && aerr.Code() == dynamodb.ErrCodeConditionalCheckFailedException {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		return updated, nil
+	}
+	return nil, fmt.Errorf("could not increment entity %s after %d attempts due to concurrent writes", entity, maxAttempts)
+}
+
+// Append prepends the value to the entity's FeatureList attribute and then
+// removes any items past maxLen. The removal only targets positions past the
+// cap, so a concurrent append can at worst leave the list briefly over length.
+func (table dynamodbOnlineTable) Append(entity string, value interface{}, maxLen int) error {
+	if maxLen <= 0 {
+		return fmt.Errorf("max list length must be positive: %d", maxLen)
+	}
+	tableName := aws.String(GetTablename(table.key.Prefix, table.key.Feature, table.key.Variant))
+	key := map[string]*dynamodb.AttributeValue{
+		table.key.Feature: {
+			S: aws.String(entity),
+		},
+	}
+	input := &dynamodb.UpdateItemInput{
+		ExpressionAttributeValues: map[string]*dynamodb.AttributeValue{
+			":val": {
+				L: []*dynamodb.AttributeValue{{S: aws.String(fmt.Sprintf("%v", value))}},
+			},
+			":empty": {
+				L: []*dynamodb.AttributeValue{},
+			},
+		},
+		TableName:        tableName,
+		Key:              key,
+		UpdateExpression: aws.String("set FeatureList = list_append(:val, if_not_exists(FeatureList, :empty))"),
+		ReturnValues:     aws.String(dynamodb.ReturnValueUpdatedNew),
+	}
+	output, err := table.client.UpdateItem(input)
+	if err != nil {
+		return err
+	}
+	length := len(output.Attributes["FeatureList"].L)
+	if length <= maxLen {
+		return nil
+	}
+	removals := make([]string, 0, length-maxLen)
+	for i := maxLen; i < length; i++ {
+		removals = append(removals, fmt.Sprintf("FeatureList[%d]", i))
+	}
+	_, err = table.client.UpdateItem(&dynamodb.UpdateItemInput{
+		TableName:        tableName,
+		Key:              key,
+		UpdateExpression: aws.String(fmt.Sprintf("remove %s", strings.Join(removals, ", "))),
+	})
+	return err
+}
+
+func (table dynamodbOnlineTable) GetList(entity string) ([]interface{}, error) {
+	input := &dynamodb.GetItemInput{
+		TableName: aws.String(GetTablename(table.key.Prefix, table.key.Feature, table.key.Variant)),
+		Key: map[string]*dynamodb.AttributeValue{
+			table.key.Feature: {
+				S: aws.String(entity),
+			},
+		},
+		ProjectionExpression: aws.String("FeatureList"),
+	}
+	output, err := table.client.GetItem(input)
+	if err != nil {
+		return nil, err
+	}
+	attr, has := output.Item["FeatureList"]
+	if !has {
+		return nil, &EntityNotFound{entity}
+	}
+	list := make([]interface{}, len(attr.L))
+	for i, item := range attr.L {
+		if list[i], err = parseDynamoValue(table.valueType, aws.StringValue(item.S)); err != nil {
+			return nil, err
+		}
+	}
+	return list, nil
+}
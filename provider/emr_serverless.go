@@ -0,0 +1,240 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package provider
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/credentials"
+	"github.com/aws/aws-sdk-go-v2/service/emrserverless"
+	emrsTypes "github.com/aws/aws-sdk-go-v2/service/emrserverless/types"
+	"github.com/featureform/config"
+	pc "github.com/featureform/provider/provider_config"
+	"go.uber.org/zap"
+)
+
+const emrServerlessPollInterval = 10 * time.Second
+
+// emrServerlessStreamingTimeoutMinutes is the longest a job run can be given
+// to run, which streaming jobs are so they aren't cancelled after the default
+// of 12 hours.
+const emrServerlessStreamingTimeoutMinutes = 1000000
+
+// emrServerlessClient is the subset of the EMR Serverless API the executor
+// uses, so tests can stub it out.
+type emrServerlessClient interface {
+	StartJobRun(ctx context.Context, params *emrserverless.StartJobRunInput, optFns ...func(*emrserverless.Options)) (*emrserverless.StartJobRunOutput, error)
+	GetJobRun(ctx context.Context, params *emrserverless.GetJobRunInput, optFns ...func(*emrserverless.Options)) (*emrserverless.GetJobRunOutput, error)
+	CancelJobRun(ctx context.Context, params *emrserverless.CancelJobRunInput, optFns ...func(*emrserverless.Options)) (*emrserverless.CancelJobRunOutput, error)
+}
+
+// EMRServerlessExecutor runs Spark jobs on an EMR Serverless application.
+// Unlike EMRExecutor there's no cluster to add steps to: each job is its own
+// job run, which is polled until it reaches a final state.
+type EMRServerlessExecutor struct {
+	client           emrServerlessClient
+	applicationId    string
+	executionRoleArn string
+	pollInterval     time.Duration
+	logger           *zap.SugaredLogger
+}
+
+func NewEMRServerlessExecutor(emrConfig pc.EMRServerlessConfig, logger *zap.SugaredLogger) (SparkExecutor, error) {
+	if emrConfig.ApplicationId == "" {
+		return nil, errors.New("EMR Serverless executor requires an application ID")
+	}
+	if emrConfig.ExecutionRoleArn == "" {
+		return nil, errors.New("EMR Serverless executor requires an execution role ARN")
+	}
+	client := emrserverless.New(emrserverless.Options{
+		Region:      emrConfig.Region,
+		Credentials: aws.NewCredentialsCache(credentials.NewStaticCredentialsProvider(emrConfig.Credentials.AWSAccessKeyId, emrConfig.Credentials.AWSSecretKey, "")),
+	})
+	return &EMRServerlessExecutor{
+		client:           client,
+		applicationId:    emrConfig.ApplicationId,
+		executionRoleArn: emrConfig.ExecutionRoleArn,
+		pollInterval:     emrServerlessPollInterval,
+		logger:           logger,
+	}, nil
+}
+
+func (e *EMRServerlessExecutor) InitializeExecutor(store SparkFileStore) error {
+	e.logger.Info("Uploading PySpark script to filestore")
+	sparkLocalScriptPath := config.GetSparkLocalScriptPath()
+	sparkRemoteScriptPath := config.GetSparkRemoteScriptPath()
+	sparkScriptPathWithPrefix := store.PathWithPrefix(sparkRemoteScriptPath, false)
+
+	err := readAndUploadFile(sparkLocalScriptPath, sparkScriptPathWithPrefix, store)
+	if err != nil {
+		return fmt.Errorf("could not upload '%s' to '%s': %v", sparkLocalScriptPath, sparkScriptPathWithPrefix, err)
+	}
+	scriptExists, err := store.Exists(sparkScriptPathWithPrefix)
+	if err != nil || !scriptExists {
+		return fmt.Errorf("could not upload spark script: Path: %s, Error: %v", sparkScriptPathWithPrefix, err)
+	}
+	return nil
+}
+
+func (e *EMRServerlessExecutor) PythonFileURI(store SparkFileStore) string {
+	return ""
+}
+
+// RunSparkJob starts a job run with args[0] as the entry point and the rest
+// as its arguments, then waits for it to finish.
+func (e *EMRServerlessExecutor) RunSparkJob(args []string, store SparkFileStore) error {
+	jobRunId, err := e.startJobRun(args, store, 0)
+	if err != nil {
+		return err
+	}
+	e.logger.Debugw("Waiting for EMR Serverless job run to complete", "application", e.applicationId, "job_run", jobRunId)
+	return e.waitForJobRun(jobRunId)
+}
+
+// StartSparkJob starts a job run of the Spark script without waiting for it,
+// and without a limit on how long it runs.
+func (e *EMRServerlessExecutor) StartSparkJob(args []string, store SparkFileStore) (string, error) {
+	scriptPath := store.PathWithPrefix(config.GetSparkRemoteScriptPath(), true)
+	return e.startJobRun(append([]string{scriptPath}, args...), store, emrServerlessStreamingTimeoutMinutes)
+}
+
+func (e *EMRServerlessExecutor) StopSparkJob(jobRunId string) error {
+	_, err := e.client.CancelJobRun(context.TODO(), &emrserverless.CancelJobRunInput{
+		ApplicationId: aws.String(e.applicationId),
+		JobRunId:      aws.String(jobRunId),
+	})
+	if err != nil {
+		return fmt.Errorf("could not cancel EMR Serverless job run '%s': %w", jobRunId, err)
+	}
+	return nil
+}
+
+func (e *EMRServerlessExecutor) SparkJobStatus(jobRunId string) (StreamingJobStatus, error) {
+	run, err := e.getJobRun(jobRunId)
+	if err != nil {
+		return StreamingJobStatus{}, err
+	}
+	status := StreamingJobStatus{Message: aws.ToString(run.StateDetails)}
+	switch run.State {
+	case emrsTypes.JobRunStateRunning, emrsTypes.JobRunStateCancelling:
+		status.State = StreamingJobRunning
+	case emrsTypes.JobRunStateSuccess, emrsTypes.JobRunStateCancelled:
+		status.State = StreamingJobStopped
+	case emrsTypes.JobRunStateFailed:
+		status.State = StreamingJobFailed
+	default:
+		status.State = StreamingJobPending
+	}
+	return status, nil
+}
+
+func (e *EMRServerlessExecutor) startJobRun(args []string, store SparkFileStore, timeoutMinutes int64) (string, error) {
+	if len(args) == 0 {
+		return "", errors.New("EMR Serverless job requires an entry point")
+	}
+	submit := emrsTypes.SparkSubmit{
+		EntryPoint:          aws.String(args[0]),
+		EntryPointArguments: args[1:],
+	}
+	if packages := removeEspaceCharacters(store.Packages()); len(packages) > 0 {
+		submit.SparkSubmitParameters = aws.String(strings.Join(packages, " "))
+	}
+	resp, err := e.client.StartJobRun(context.TODO(), &emrserverless.StartJobRunInput{
+		ApplicationId:           aws.String(e.applicationId),
+		ExecutionRoleArn:        aws.String(e.executionRoleArn),
+		Name:                    aws.String("Featureform execution step"),
+		JobDriver:               &emrsTypes.JobDriverMemberSparkSubmit{Value: submit},
+		ExecutionTimeoutMinutes: timeoutMinutes,
+	})
+	if err != nil {
+		e.logger.Errorw("Could not start EMR Serverless job run", "application", e.applicationId, "error", err)
+		return "", fmt.Errorf("could not start EMR Serverless job run: %w", err)
+	}
+	return aws.ToString(resp.JobRunId), nil
+}
+
+func (e *EMRServerlessExecutor) getJobRun(jobRunId string) (*emrsTypes.JobRun, error) {
+	resp, err := e.client.GetJobRun(context.TODO(), &emrserverless.GetJobRunInput{
+		ApplicationId: aws.String(e.applicationId),
+		JobRunId:      aws.String(jobRunId),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("could not get status of EMR Serverless job run '%s': %w", jobRunId, err)
+	}
+	return resp.JobRun, nil
+}
+
+func (e *EMRServerlessExecutor) waitForJobRun(jobRunId string) error {
+	for {
+		run, err := e.getJobRun(jobRunId)
+		if err != nil {
+			return err
+		}
+		switch run.State {
+		case emrsTypes.JobRunStateSuccess:
+			return nil
+		case emrsTypes.JobRunStateFailed, emrsTypes.JobRunStateCancelled:
+			return fmt.Errorf("the EMR Serverless job run '%s' %s: %s", jobRunId, strings.ToLower(string(run.State)), aws.ToString(run.StateDetails))
+		}
+		time.Sleep(e.pollInterval)
+	}
+}
+
+func (e *EMRServerlessExecutor) SparkSubmitArgs(destPath string, cleanQuery string, sourceList []string, jobType JobType, store SparkFileStore) []string {
+	e.logger.Debugw("SparkSubmitArgs", "destPath", destPath, "cleanQuery", cleanQuery, "sourceList", sourceList, "jobType", jobType, "store", store)
+	sparkScriptPath := store.PathWithPrefix(config.GetSparkRemoteScriptPath(), true)
+	argList := []string{
+		sparkScriptPath,
+		"sql",
+		"--output_uri",
+		store.PathWithPrefix(destPath, true),
+		"--sql_query",
+		cleanQuery,
+		"--job_type",
+		string(jobType),
+		"--store_type",
+		store.Type(),
+	}
+
+	sparkConfigs := removeEspaceCharacters(store.SparkConfig())
+	argList = append(argList, sparkConfigs...)
+
+	credentialConfigs := removeEspaceCharacters(store.CredentialsConfig())
+	argList = append(argList, credentialConfigs...)
+
+	argList = append(argList, "--source_list")
+	argList = append(argList, sourceList...)
+	return argList
+}
+
+func (e *EMRServerlessExecutor) GetDFArgs(outputURI string, code string, sources []string, store SparkFileStore) ([]string, error) {
+	sparkScriptPath := store.PathWithPrefix(config.GetSparkRemoteScriptPath(), true)
+	codePath := strings.Replace(code, s3aPrefix, s3Prefix, -1)
+	argList := []string{
+		sparkScriptPath,
+		"df",
+		"--output_uri",
+		outputURI,
+		"--code",
+		codePath,
+		"--store_type",
+		store.Type(),
+	}
+
+	sparkConfigs := removeEspaceCharacters(store.SparkConfig())
+	argList = append(argList, sparkConfigs...)
+
+	credentialConfigs := removeEspaceCharacters(store.CredentialsConfig())
+	argList = append(argList, credentialConfigs...)
+
+	argList = append(argList, "--source")
+	argList = append(argList, sources...)
+	return argList, nil
+}
@@ -0,0 +1,160 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/emrserverless"
+	emrsTypes "github.com/aws/aws-sdk-go-v2/service/emrserverless/types"
+	pc "github.com/featureform/provider/provider_config"
+	"go.uber.org/zap"
+)
+
+type mockEMRServerlessClient struct {
+	started  *emrserverless.StartJobRunInput
+	states   []emrsTypes.JobRunState
+	details  string
+	startErr error
+	polls    int
+}
+
+func (c *mockEMRServerlessClient) StartJobRun(ctx context.Context, params *emrserverless.StartJobRunInput, optFns ...func(*emrserverless.Options)) (*emrserverless.StartJobRunOutput, error) {
+	if c.startErr != nil {
+		return nil, c.startErr
+	}
+	c.started = params
+	return &emrserverless.StartJobRunOutput{ApplicationId: params.ApplicationId, JobRunId: aws.String("run-1")}, nil
+}
+
+func (c *mockEMRServerlessClient) GetJobRun(ctx context.Context, params *emrserverless.GetJobRunInput, optFns ...func(*emrserverless.Options)) (*emrserverless.GetJobRunOutput, error) {
+	state := c.states[c.polls]
+	c.polls++
+	return &emrserverless.GetJobRunOutput{JobRun: &emrsTypes.JobRun{State: state, StateDetails: aws.String(c.details)}}, nil
+}
+
+func (c *mockEMRServerlessClient) CancelJobRun(ctx context.Context, params *emrserverless.CancelJobRunInput, optFns ...func(*emrserverless.Options)) (*emrserverless.CancelJobRunOutput, error) {
+	c.states = append(c.states, emrsTypes.JobRunStateCancelled)
+	return &emrserverless.CancelJobRunOutput{ApplicationId: params.ApplicationId, JobRunId: params.JobRunId}, nil
+}
+
+type packagesSparkFileStore struct {
+	SparkFileStore
+}
+
+func (packagesSparkFileStore) Packages() []string {
+	return []string{"--packages", "\"org.apache.hadoop:hadoop-azure:3.2.0\""}
+}
+
+func newMockEMRServerlessExecutor(client *mockEMRServerlessClient) *EMRServerlessExecutor {
+	return &EMRServerlessExecutor{
+		client:           client,
+		applicationId:    "app-1",
+		executionRoleArn: "arn:aws:iam::123456789012:role/featureform",
+		logger:           zap.NewNop().Sugar(),
+	}
+}
+
+func TestEMRServerlessRunSparkJob(t *testing.T) {
+	client := &mockEMRServerlessClient{
+		states: []emrsTypes.JobRunState{emrsTypes.JobRunStatePending, emrsTypes.JobRunStateRunning, emrsTypes.JobRunStateSuccess},
+	}
+	executor := newMockEMRServerlessExecutor(client)
+	args := []string{"s3://bucket/scripts/offline_store_spark_runner.py", "sql", "--output_uri", "s3://bucket/out"}
+	if err := executor.RunSparkJob(args, packagesSparkFileStore{}); err != nil {
+		t.Fatalf("Failed to run job: %v", err)
+	}
+	if client.polls != 3 {
+		t.Fatalf("Expected 3 polls, got %d", client.polls)
+	}
+	if aws.ToString(client.started.ApplicationId) != "app-1" {
+		t.Fatalf("Job started on wrong application: %s", aws.ToString(client.started.ApplicationId))
+	}
+	driver, ok := client.started.JobDriver.(*emrsTypes.JobDriverMemberSparkSubmit)
+	if !ok {
+		t.Fatalf("Expected a spark submit job driver, got %T", client.started.JobDriver)
+	}
+	if aws.ToString(driver.Value.EntryPoint) != args[0] {
+		t.Fatalf("Wrong entry point: %s", aws.ToString(driver.Value.EntryPoint))
+	}
+	if strings.Join(driver.Value.EntryPointArguments, " ") != strings.Join(args[1:], " ") {
+		t.Fatalf("Wrong entry point arguments: %v", driver.Value.EntryPointArguments)
+	}
+	expectedParams := "--packages org.apache.hadoop:hadoop-azure:3.2.0"
+	if aws.ToString(driver.Value.SparkSubmitParameters) != expectedParams {
+		t.Fatalf("Expected spark submit parameters %q, got %q", expectedParams, aws.ToString(driver.Value.SparkSubmitParameters))
+	}
+}
+
+func TestEMRServerlessRunSparkJobFailure(t *testing.T) {
+	client := &mockEMRServerlessClient{
+		states:  []emrsTypes.JobRunState{emrsTypes.JobRunStateRunning, emrsTypes.JobRunStateFailed},
+		details: "Job failed, ExitCode: 1",
+	}
+	executor := newMockEMRServerlessExecutor(client)
+	err := executor.RunSparkJob([]string{"s3://bucket/script.py"}, packagesSparkFileStore{})
+	if err == nil {
+		t.Fatalf("Expected failed job run to return an error")
+	}
+	if !strings.Contains(err.Error(), client.details) {
+		t.Fatalf("Expected error to include state details, got: %v", err)
+	}
+
+	client = &mockEMRServerlessClient{startErr: errors.New("access denied")}
+	executor = newMockEMRServerlessExecutor(client)
+	if err := executor.RunSparkJob([]string{"s3://bucket/script.py"}, packagesSparkFileStore{}); err == nil {
+		t.Fatalf("Expected start failure to return an error")
+	}
+}
+
+func TestEMRServerlessStreamingJob(t *testing.T) {
+	client := &mockEMRServerlessClient{
+		states: []emrsTypes.JobRunState{emrsTypes.JobRunStateRunning},
+	}
+	executor := newMockEMRServerlessExecutor(client)
+	store := packagesSparkFileStore{newTestSparkLocalFileStore(t)}
+	jobRunId, err := executor.StartSparkJob([]string{"stream", "--sql_query", "SELECT 1"}, store)
+	if err != nil {
+		t.Fatalf("Failed to start job: %v", err)
+	}
+	if client.polls != 0 {
+		t.Fatalf("Expected the job to be started without waiting for it")
+	}
+	if client.started.ExecutionTimeoutMinutes != emrServerlessStreamingTimeoutMinutes {
+		t.Fatalf("Expected streaming job timeout of %d minutes, got %d", emrServerlessStreamingTimeoutMinutes, client.started.ExecutionTimeoutMinutes)
+	}
+	driver := client.started.JobDriver.(*emrsTypes.JobDriverMemberSparkSubmit)
+	if !strings.HasSuffix(aws.ToString(driver.Value.EntryPoint), ".py") || driver.Value.EntryPointArguments[0] != "stream" {
+		t.Fatalf("Expected the spark script to be run with the stream arguments, got %s %v", aws.ToString(driver.Value.EntryPoint), driver.Value.EntryPointArguments)
+	}
+	status, err := executor.SparkJobStatus(jobRunId)
+	if err != nil || status.State != StreamingJobRunning {
+		t.Fatalf("Expected running job, got %v: %v", status, err)
+	}
+	if err := executor.StopSparkJob(jobRunId); err != nil {
+		t.Fatalf("Failed to stop job: %v", err)
+	}
+	status, err = executor.SparkJobStatus(jobRunId)
+	if err != nil || status.State != StreamingJobStopped {
+		t.Fatalf("Expected stopped job, got %v: %v", status, err)
+	}
+}
+
+func TestNewEMRServerlessExecutorValidation(t *testing.T) {
+	logger := zap.NewNop().Sugar()
+	invalid := []pc.EMRServerlessConfig{
+		{Region: "us-east-1", ExecutionRoleArn: "arn:aws:iam::123456789012:role/featureform"},
+		{Region: "us-east-1", ApplicationId: "app-1"},
+	}
+	for _, config := range invalid {
+		if _, err := NewSparkExecutor(pc.EMRServerless, &config, logger); err == nil {
+			t.Errorf("Expected error for config %+v", config)
+		}
+	}
+	valid := pc.EMRServerlessConfig{Region: "us-east-1", ApplicationId: "app-1", ExecutionRoleArn: "arn:aws:iam::123456789012:role/featureform"}
+	if _, err := NewSparkExecutor(pc.EMRServerless, &valid, logger); err != nil {
+		t.Fatalf("Failed to create executor: %v", err)
+	}
+}
@@ -0,0 +1,88 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package provider
+
+// MaterializedEntityLookup is implemented by offline stores that can read
+// entities' materialized values without scanning the whole materialization.
+// LookupMaterializedEntity returns an EntityNotFound error if the entity has
+// no value. LookupMaterializedEntities returns the values of a set of
+// entities, like those of a batch to score, in no particular order, and
+// skips entities that have none.
+type MaterializedEntityLookup interface {
+	LookupMaterializedEntity(id ResourceID, entity string) (ResourceRecord, error)
+	LookupMaterializedEntities(id ResourceID, entities []string) (FeatureIterator, error)
+}
+
+// entityLookupBatchSize is the most entities that are looked up per query.
+const entityLookupBatchSize = 1000
+
+func uniqueEntities(entities []string) []string {
+	seen := make(map[string]bool, len(entities))
+	unique := make([]string, 0, len(entities))
+	for _, entity := range entities {
+		if !seen[entity] {
+			seen[entity] = true
+			unique = append(unique, entity)
+		}
+	}
+	return unique
+}
+
+// entityBatchIterator looks up entities in batches of entityLookupBatchSize,
+// and only looks up a batch once the one before it has been read.
+type entityBatchIterator struct {
+	entities []string
+	lookup   func(entities []string) (FeatureIterator, error)
+	current  FeatureIterator
+	err      error
+}
+
+func newEntityBatchIterator(entities []string, lookup func([]string) (FeatureIterator, error)) *entityBatchIterator {
+	return &entityBatchIterator{
+		entities: entities,
+		lookup:   lookup,
+	}
+}
+
+func (it *entityBatchIterator) Next() bool {
+	for it.err == nil {
+		if it.current != nil {
+			if it.current.Next() {
+				return true
+			}
+			it.err = it.current.Err()
+			if err := it.current.Close(); err != nil && it.err == nil {
+				it.err = err
+			}
+			it.current = nil
+			continue
+		}
+		if len(it.entities) == 0 {
+			return false
+		}
+		batch := it.entities
+		if len(batch) > entityLookupBatchSize {
+			batch = batch[:entityLookupBatchSize]
+		}
+		it.entities = it.entities[len(batch):]
+		it.current, it.err = it.lookup(batch)
+	}
+	return false
+}
+
+func (it *entityBatchIterator) Value() ResourceRecord {
+	return it.current.Value()
+}
+
+func (it *entityBatchIterator) Err() error {
+	return it.err
+}
+
+func (it *entityBatchIterator) Close() error {
+	if it.current == nil {
+		return nil
+	}
+	return it.current.Close()
+}
@@ -0,0 +1,75 @@
+package provider
+
+import (
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestMemoryLookupMaterializedEntities(t *testing.T) {
+	offline := NewMemoryOfflineStore()
+	id := ResourceID{"feature", "variant", Feature}
+	offlineTable, err := offline.CreateResourceTable(id, TableSchema{})
+	if err != nil {
+		t.Fatalf("Failed to create offline table: %s", err)
+	}
+	ts := time.UnixMilli(1000).UTC()
+	records := []ResourceRecord{
+		{Entity: "a", Value: 1, TS: ts.Add(-time.Hour)},
+		{Entity: "a", Value: 2, TS: ts},
+		{Entity: "b", Value: 3, TS: ts},
+		{Entity: "c", Value: 4, TS: ts},
+	}
+	for _, rec := range records {
+		if err := offlineTable.Write(rec); err != nil {
+			t.Fatalf("Failed to write record: %s", err)
+		}
+	}
+	it, err := offline.LookupMaterializedEntities(id, []string{"a", "b", "a", "missing"})
+	if err != nil {
+		t.Fatalf("Failed to look up entities: %s", err)
+	}
+	values := map[string]interface{}{}
+	for it.Next() {
+		values[it.Value().Entity] = it.Value().Value
+	}
+	if err := it.Err(); err != nil {
+		t.Fatalf("Failed to iterate entities: %s", err)
+	}
+	if len(values) != 2 || values["a"] != 2 || values["b"] != 3 {
+		t.Fatalf("Expected the latest values of a and b, got %v", values)
+	}
+}
+
+func TestEntityBatchIterator(t *testing.T) {
+	entities := make([]string, entityLookupBatchSize+1)
+	for i := range entities {
+		entities[i] = fmt.Sprint(i)
+	}
+	batches := make([]int, 0)
+	lookup := func(batch []string) (FeatureIterator, error) {
+		batches = append(batches, len(batch))
+		recs := make([]ResourceRecord, len(batch))
+		for i, entity := range batch {
+			recs[i] = ResourceRecord{Entity: entity}
+		}
+		return newMemoryFeatureIterator(recs), nil
+	}
+	it := newEntityBatchIterator(entities, lookup)
+	count := 0
+	for it.Next() {
+		count++
+	}
+	if err := it.Err(); err != nil {
+		t.Fatalf("Failed to iterate entities: %s", err)
+	}
+	if count != len(entities) || len(batches) != 2 || batches[0] != entityLookupBatchSize || batches[1] != 1 {
+		t.Fatalf("Expected %d entities in two batches, got %d in %v", len(entities), count, batches)
+	}
+	failing := newEntityBatchIterator(entities, func([]string) (FeatureIterator, error) {
+		return nil, fmt.Errorf("lookup failed")
+	})
+	if failing.Next() || failing.Err() == nil {
+		t.Fatalf("Expected a failed lookup to stop iteration with an error")
+	}
+}
@@ -0,0 +1,56 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package provider
+
+import (
+	"errors"
+
+	"golang.org/x/sync/errgroup"
+)
+
+// FanOutTable writes to several online tables, like a feature's table in the
+// same store in different regions, so that a materialization is read once for
+// all of them. Each write goes to every table at once, and fails if any of
+// them fails. Reads are from the first table.
+type FanOutTable struct {
+	tables []OnlineStoreTable
+}
+
+func NewFanOutTable(tables ...OnlineStoreTable) (*FanOutTable, error) {
+	if len(tables) == 0 {
+		return nil, errors.New("fan out table needs at least one table")
+	}
+	return &FanOutTable{tables: tables}, nil
+}
+
+func (table *FanOutTable) Set(entity string, value interface{}) error {
+	return table.each(func(t OnlineStoreTable) error {
+		return t.Set(entity, value)
+	})
+}
+
+func (table *FanOutTable) SetBatch(values map[string]interface{}) error {
+	return table.each(func(t OnlineStoreTable) error {
+		return setBatch(t, values)
+	})
+}
+
+func (table *FanOutTable) Get(entity string) (interface{}, error) {
+	return table.tables[0].Get(entity)
+}
+
+func (table *FanOutTable) each(write func(OnlineStoreTable) error) error {
+	if len(table.tables) == 1 {
+		return write(table.tables[0])
+	}
+	var group errgroup.Group
+	for _, t := range table.tables {
+		t := t
+		group.Go(func() error {
+			return write(t)
+		})
+	}
+	return group.Wait()
+}
@@ -0,0 +1,41 @@
+package provider
+
+import (
+	"errors"
+	"testing"
+)
+
+type failingTable struct {
+	OnlineStoreTable
+}
+
+func (table *failingTable) Set(entity string, value interface{}) error {
+	return errors.New("write failed")
+}
+
+func TestFanOutTable(t *testing.T) {
+	if _, err := NewFanOutTable(); err == nil {
+		t.Fatalf("Expected error for fan out table without tables")
+	}
+	first, second := &countingTable{}, &countingTable{}
+	table, err := NewFanOutTable(first, second)
+	if err != nil {
+		t.Fatalf("Failed to create fan out table: %s", err)
+	}
+	if err := table.Set("a", 1); err != nil {
+		t.Fatalf("Failed to set: %s", err)
+	}
+	if err := table.SetBatch(map[string]interface{}{"b": 2, "c": 3}); err != nil {
+		t.Fatalf("Failed to set batch: %s", err)
+	}
+	if first.sets != 3 || second.sets != 3 {
+		t.Fatalf("Expected 3 sets in each table, got %d and %d", first.sets, second.sets)
+	}
+	failing, err := NewFanOutTable(&countingTable{}, &failingTable{})
+	if err != nil {
+		t.Fatalf("Failed to create fan out table: %s", err)
+	}
+	if err := failing.Set("a", 1); err == nil {
+		t.Fatalf("Expected a failed write to any table to fail")
+	}
+}
@@ -4,13 +4,16 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"net/url"
 	"os"
 	"strings"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
 	awsv2cfg "github.com/aws/aws-sdk-go-v2/config"
 	"github.com/aws/aws-sdk-go-v2/credentials"
+	"github.com/aws/aws-sdk-go-v2/credentials/stscreds"
 	s3v2 "github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/sts"
 	hdfs "github.com/colinmarc/hdfs/v2"
 	pc "github.com/featureform/provider/provider_config"
 
@@ -41,6 +44,10 @@ const (
 	Parquet FileType = "parquet"
 	CSV     FileType = "csv"
 	DB      FileType = "db"
+	// ORC and Avro files can be primary sources, such as tables written by
+	// Hive, but aren't written by offline stores.
+	ORC  FileType = "orc"
+	Avro FileType = "avro"
 )
 
 func (ft FileType) Matches(file string) bool {
@@ -177,6 +184,14 @@ type S3FileStore struct {
 	BucketRegion string
 	Bucket       string
 	Path         string
+	RoleARN      string
+	ExternalID   string
+	AccessPoint  string
+	Endpoint     string
+	PathStyle    bool
+	// credentials are the ones the store accesses S3 with, which are the
+	// role's if one is assumed.
+	credentials aws.CredentialsProvider
 	genericFileStore
 }
 
@@ -189,32 +204,76 @@ func NewS3FileStore(config Config) (FileStore, error) {
 	if err := s3StoreConfig.Deserialize(pc.SerializedConfig(config)); err != nil {
 		return nil, fmt.Errorf("could not deserialize s3 store config: %v", err)
 	}
-	cfg, err := awsv2cfg.LoadDefaultConfig(context.TODO(),
-		awsv2cfg.WithCredentialsProvider(credentials.StaticCredentialsProvider{
-			Value: aws.Credentials{
-				AccessKeyID: s3StoreConfig.Credentials.AWSAccessKeyId, SecretAccessKey: s3StoreConfig.Credentials.AWSSecretKey,
-			},
-		}))
+	opts := []func(*awsv2cfg.LoadOptions) error{awsv2cfg.WithRegion(s3StoreConfig.BucketRegion)}
+	if s3StoreConfig.Credentials.AWSAccessKeyId != "" {
+		opts = append(opts, awsv2cfg.WithCredentialsProvider(credentials.NewStaticCredentialsProvider(s3StoreConfig.Credentials.AWSAccessKeyId, s3StoreConfig.Credentials.AWSSecretKey, "")))
+	}
+	cfg, err := awsv2cfg.LoadDefaultConfig(context.TODO(), opts...)
 	if err != nil {
 		return nil, fmt.Errorf("could not load aws config: %v", err)
 	}
-	cfg.Region = s3StoreConfig.BucketRegion
-	clientV2 := s3v2.NewFromConfig(cfg)
-	bucket, err := s3blob.OpenBucketV2(context.TODO(), clientV2, s3StoreConfig.BucketPath, nil)
+	if s3StoreConfig.RoleARN != "" {
+		cfg.Credentials = aws.NewCredentialsCache(stscreds.NewAssumeRoleProvider(sts.NewFromConfig(cfg), s3StoreConfig.RoleARN, func(o *stscreds.AssumeRoleOptions) {
+			if s3StoreConfig.ExternalID != "" {
+				o.ExternalID = aws.String(s3StoreConfig.ExternalID)
+			}
+		}))
+	}
+	clientV2 := s3v2.NewFromConfig(cfg, func(o *s3v2.Options) {
+		if s3StoreConfig.Endpoint != "" {
+			o.EndpointResolver = s3v2.EndpointResolverFromURL(s3StoreConfig.Endpoint)
+		}
+		o.UsePathStyle = s3StoreConfig.PathStyle
+	})
+	bucketName := s3StoreConfig.BucketPath
+	if s3StoreConfig.AccessPoint != "" {
+		bucketName = s3StoreConfig.AccessPoint
+	}
+	bucket, err := s3blob.OpenBucketV2(context.TODO(), clientV2, bucketName, nil)
 	if err != nil {
-		return nil, fmt.Errorf("could not create connection to s3 bucket: config: %v, name: %s, %v", s3StoreConfig, s3StoreConfig.BucketPath, err)
+		return nil, fmt.Errorf("could not create connection to s3 bucket: config: %v, name: %s, %v", s3StoreConfig, bucketName, err)
 	}
 	return &S3FileStore{
 		Bucket:       s3StoreConfig.BucketPath,
 		BucketRegion: s3StoreConfig.BucketRegion,
 		Credentials:  s3StoreConfig.Credentials,
 		Path:         s3StoreConfig.Path,
+		RoleARN:      s3StoreConfig.RoleARN,
+		ExternalID:   s3StoreConfig.ExternalID,
+		AccessPoint:  s3StoreConfig.AccessPoint,
+		Endpoint:     s3StoreConfig.Endpoint,
+		PathStyle:    s3StoreConfig.PathStyle,
+		credentials:  cfg.Credentials,
 		genericFileStore: genericFileStore{
-			bucket: bucket,
+			bucket:        bucket,
+			writerOptions: s3TaggingOptions(s3StoreConfig.Tags),
 		},
 	}, nil
 }
 
+// s3TaggingOptions returns writer options that tag each object written with
+// the tags, or nil if there are none. Objects written by Spark jobs rather
+// than through the file store aren't tagged.
+func s3TaggingOptions(tags map[string]string) *blob.WriterOptions {
+	if len(tags) == 0 {
+		return nil
+	}
+	values := url.Values{}
+	for key, value := range tags {
+		values.Set(key, value)
+	}
+	tagging := values.Encode()
+	return &blob.WriterOptions{
+		BeforeWrite: func(asFunc func(interface{}) bool) error {
+			var input *s3v2.PutObjectInput
+			if asFunc(&input) {
+				input.Tagging = aws.String(tagging)
+			}
+			return nil
+		},
+	}
+}
+
 func (s3 *S3FileStore) PathWithPrefix(path string, remote bool) string {
 	pathContainsS3Prefix := strings.HasPrefix(path, s3aPrefix)
 	pathContainsWorkingDirectory := s3.Path != "" && strings.HasPrefix(path, s3.Path)
@@ -263,6 +322,17 @@ func (s3 *S3FileStore) Read(key string) ([]byte, error) {
 	return data, nil
 }
 
+func (s3 *S3FileStore) ReadRange(key string, offset, length int64) ([]byte, error) {
+	fp, err := NewEmptyFilepath(S3)
+	if err != nil {
+		return nil, fmt.Errorf("error creating filepath: %v", err)
+	}
+	if err := fp.ParseFullPath(key); err != nil {
+		return nil, fmt.Errorf("could not parse path: %v", err)
+	}
+	return s3.genericFileStore.ReadRange(fp.Path(), offset, length)
+}
+
 type GCSFileStore struct {
 	Bucket      string
 	Path        string
@@ -332,14 +402,9 @@ func NewGCSFileStore(config Config) (FileStore, error) {
 		return nil, fmt.Errorf("could not deserialize config: %v", err)
 	}
 
-	serializedFile, err := json.Marshal(GCSConfig.Credentials.JSON)
-	if err != nil {
-		return nil, fmt.Errorf("could not serialize GCS config: %v", err)
-	}
-
-	creds, err := google.CredentialsFromJSON(context.TODO(), serializedFile, "https://www.googleapis.com/auth/cloud-platform")
+	creds, err := googleCredentials(context.TODO(), GCSConfig.Credentials.JSON)
 	if err != nil {
-		return nil, fmt.Errorf("could not get credentials from JSON: %v", err)
+		return nil, err
 	}
 
 	client, err := gcp.NewHTTPClient(
@@ -363,6 +428,29 @@ func NewGCSFileStore(config Config) (FileStore, error) {
 	}, nil
 }
 
+// googleCredentials returns the credentials of a service account key, or
+// Application Default Credentials, such as a GKE workload identity, if
+// there's no key.
+func googleCredentials(ctx context.Context, key map[string]interface{}) (*google.Credentials, error) {
+	const scope = "https://www.googleapis.com/auth/cloud-platform"
+	if len(key) == 0 {
+		creds, err := google.FindDefaultCredentials(ctx, scope)
+		if err != nil {
+			return nil, fmt.Errorf("could not find application default credentials: %v", err)
+		}
+		return creds, nil
+	}
+	serializedKey, err := json.Marshal(key)
+	if err != nil {
+		return nil, fmt.Errorf("could not serialize GCP credentials: %v", err)
+	}
+	creds, err := google.CredentialsFromJSON(ctx, serializedKey, scope)
+	if err != nil {
+		return nil, fmt.Errorf("could not get credentials from JSON: %v", err)
+	}
+	return creds, nil
+}
+
 func NewHDFSFileStore(config Config) (FileStore, error) {
 	HDFSConfig := pc.HDFSFileStoreConfig{}
 
@@ -598,6 +686,24 @@ func (hdfs *HDFSFileStore) NewestFileOfType(prefix string, fileType FileType) (s
 	return lastModName, nil
 }
 
+func (hdfs *HDFSFileStore) ListFiles(prefix string) ([]StoredFile, error) {
+	files := make([]StoredFile, 0)
+	err := hdfs.Client.Walk("/", func(path string, info fs.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		key := strings.TrimPrefix(path, "/")
+		if !info.IsDir() && strings.HasPrefix(key, prefix) {
+			files = append(files, StoredFile{Key: key, ModTime: info.ModTime(), Size: info.Size()})
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return files, nil
+}
+
 func (fs *HDFSFileStore) PathWithPrefix(path string, remote bool) string {
 	nofsPrefix := !strings.HasPrefix(path, HDFSPrefix)
 
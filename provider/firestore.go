@@ -8,6 +8,7 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"strings"
 
 	"google.golang.org/grpc/status"
 
@@ -99,14 +100,18 @@ func (store *firestoreOnlineStore) GetTable(feature, variant string) (OnlineStor
 	if err != nil {
 		return nil, fmt.Errorf("could not get metadata table: %v", err)
 	}
-	valueType, err := metadata.DataAt(tableName)
+	serializedType, err := metadata.DataAt(tableName)
 	if err != nil {
 		return nil, fmt.Errorf("could not get data at: %v", err)
 	}
+	valueType, err := deserializeValueType(serializedType.(string))
+	if err != nil {
+		return nil, err
+	}
 	return &firestoreOnlineTable{
 		document:  table.Ref,
 		key:       key,
-		valueType: ScalarType(valueType.(string)),
+		valueType: valueType,
 	}, nil
 }
 
@@ -118,13 +123,17 @@ func (store *firestoreOnlineStore) CreateTable(feature, variant string, valueTyp
 
 	key := firestoreTableKey{store.collection.ID, feature, variant}
 	tableName := key.String()
-	_, err := store.collection.Doc(tableName).Set(context.TODO(), map[string]interface{}{})
+	serializedType, err := serializeValueType(valueType)
+	if err != nil {
+		return nil, err
+	}
+	_, err = store.collection.Doc(tableName).Set(context.TODO(), map[string]interface{}{})
 	if err != nil {
 		return nil, err
 	}
 
 	_, err = store.collection.Doc(GetMetadataTable()).Set(context.TODO(), map[string]interface{}{
-		tableName: valueType,
+		tableName: serializedType,
 	}, firestore.MergeAll)
 	if err != nil {
 		return nil, fmt.Errorf("could not insert into metadata table: %v", err)
@@ -137,6 +146,34 @@ func (store *firestoreOnlineStore) CreateTable(feature, variant string, valueTyp
 
 }
 
+// ListTables lists the tables in the store's metadata document. Table names
+// are split on their first separator, so features whose names contain "__"
+// aren't listed correctly.
+func (store *firestoreOnlineStore) ListTables() ([]OnlineTableInfo, error) {
+	metadata, err := store.collection.Doc(GetMetadataTable()).Get(context.TODO())
+	if err != nil {
+		return nil, fmt.Errorf("could not get metadata table: %v", err)
+	}
+	prefix := store.collection.ID + "__"
+	var tables []OnlineTableInfo
+	for tableName, serializedType := range metadata.Data() {
+		parts := strings.SplitN(strings.TrimPrefix(tableName, prefix), "__", 2)
+		if !strings.HasPrefix(tableName, prefix) || len(parts) != 2 {
+			continue
+		}
+		typeString, ok := serializedType.(string)
+		if !ok {
+			return nil, fmt.Errorf("table %s has invalid type %v", tableName, serializedType)
+		}
+		valueType, err := deserializeValueType(typeString)
+		if err != nil {
+			return nil, err
+		}
+		tables = append(tables, OnlineTableInfo{parts[0], parts[1], valueType})
+	}
+	return tables, nil
+}
+
 func (store *firestoreOnlineStore) DeleteTable(feature, variant string) error {
 	key := firestoreTableKey{store.collection.ID, feature, variant}
 	tableName := key.String()
@@ -159,10 +196,24 @@ func (store *firestoreOnlineStore) DeleteTable(feature, variant string) error {
 	return nil
 }
 
+func (store *firestoreOnlineStore) Capabilities() OnlineCapabilities {
+	return OnlineCapabilities{
+		BatchWrites: true,
+		Scan:        true,
+		ListTables:  true,
+	}
+}
+
 func (table firestoreOnlineTable) Set(entity string, value interface{}) error {
-	_, err := table.document.Set(context.TODO(), map[string]interface{}{
-		entity: value,
-	}, firestore.MergeAll)
+	return table.SetBatch(map[string]interface{}{entity: value})
+}
+
+// SetBatch merges the values into the table's document in a single write.
+func (table firestoreOnlineTable) SetBatch(values map[string]interface{}) error {
+	if len(values) == 0 {
+		return nil
+	}
+	_, err := table.document.Set(context.TODO(), values, firestore.MergeAll)
 
 	return err
 }
@@ -176,8 +227,33 @@ func (table firestoreOnlineTable) Get(entity string) (interface{}, error) {
 	if err != nil {
 		return nil, &EntityNotFound{entity}
 	}
+	return castFirestoreValue(table.valueType, value)
+}
 
-	switch table.valueType {
+// Scan reads the whole table document, since each table is stored as a
+// single document with a field per entity.
+func (table firestoreOnlineTable) Scan() (FeatureIterator, error) {
+	dataSnap, err := table.document.Get(context.TODO())
+	if err != nil {
+		return nil, err
+	}
+	data := dataSnap.Data()
+	recs := make([]ResourceRecord, 0, len(data))
+	for entity, value := range data {
+		cast, err := castFirestoreValue(table.valueType, value)
+		if err != nil {
+			return nil, err
+		}
+		recs = append(recs, ResourceRecord{Entity: entity, Value: cast})
+	}
+	return newMemoryFeatureIterator(recs), nil
+}
+
+func castFirestoreValue(valueType ValueType, value interface{}) (interface{}, error) {
+	if composite, isComposite := valueType.(compositeType); isComposite {
+		return castComposite(composite, value)
+	}
+	switch valueType {
 	case Int:
 		var intVal int64 = value.(int64)
 		return int(intVal), nil
@@ -0,0 +1,448 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package provider
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	pc "github.com/featureform/provider/provider_config"
+)
+
+// flinkStatementPollInterval is how often the SQL Gateway is polled until a
+// statement has been executed.
+var flinkStatementPollInterval = 500 * time.Millisecond
+
+type FlinkAggregation string
+
+const (
+	FlinkSum   FlinkAggregation = "SUM"
+	FlinkCount FlinkAggregation = "COUNT"
+	FlinkAvg   FlinkAggregation = "AVG"
+	FlinkMin   FlinkAggregation = "MIN"
+	FlinkMax   FlinkAggregation = "MAX"
+)
+
+// SlidingWindowFeature is a feature computed by a Flink job as an
+// aggregation of each entity's events over a window of Size that slides
+// every Slide. The events are JSON messages in the Source topic, and each
+// window's result is written to the Results topic as a JSON message with
+// the entity, value and end of the window. Events later than WatermarkDelay
+// are dropped from windows that have already been written. The job reads
+// the source as the Source's GroupID, and the results are read as the
+// Results' GroupID.
+type SlidingWindowFeature struct {
+	Source pc.KafkaConfig
+	// Entity, Value and Timestamp are fields of the source's events. Value
+	// is only needed by aggregations other than COUNT.
+	Entity         string
+	Value          string
+	Timestamp      string
+	Aggregation    FlinkAggregation
+	Size           time.Duration
+	Slide          time.Duration
+	WatermarkDelay time.Duration
+	Results        pc.KafkaConfig
+}
+
+func (f SlidingWindowFeature) Validate() error {
+	if f.Source.Topic == "" || f.Source.GroupID == "" || len(f.Source.Brokers) == 0 {
+		return errors.New("sliding window feature requires a source topic, group id and brokers")
+	}
+	if f.Results.Topic == "" || f.Results.GroupID == "" || len(f.Results.Brokers) == 0 {
+		return errors.New("sliding window feature requires a results topic, group id and brokers")
+	}
+	if f.Entity == "" || f.Timestamp == "" {
+		return errors.New("sliding window feature requires entity and timestamp fields")
+	}
+	switch f.Aggregation {
+	case FlinkCount:
+	case FlinkSum, FlinkAvg, FlinkMin, FlinkMax:
+		if f.Value == "" {
+			return fmt.Errorf("%s aggregation requires a value field", f.Aggregation)
+		}
+	default:
+		return fmt.Errorf("unsupported aggregation %q", f.Aggregation)
+	}
+	for _, d := range []time.Duration{f.Size, f.Slide, f.WatermarkDelay} {
+		if d < 0 || d%time.Second != 0 {
+			return fmt.Errorf("window durations must be whole seconds, not %s", d)
+		}
+	}
+	if f.Size == 0 || f.Slide == 0 {
+		return errors.New("sliding window feature requires a window size and slide")
+	}
+	if f.Size%f.Slide != 0 {
+		return fmt.Errorf("window size %s must be a multiple of its slide %s", f.Size, f.Slide)
+	}
+	return nil
+}
+
+// ValueType is the type of the feature's values.
+func (f SlidingWindowFeature) ValueType() ValueType {
+	if f.Aggregation == FlinkCount {
+		return Int64
+	}
+	return Float64
+}
+
+// Statements returns the Flink SQL that runs the feature's job with the
+// given name. Its last statement is the INSERT that starts the job.
+func (f SlidingWindowFeature) Statements(jobName string) ([]string, error) {
+	if err := f.Validate(); err != nil {
+		return nil, err
+	}
+	valueColumn := ""
+	if f.Value != "" {
+		valueColumn = fmt.Sprintf("%s DOUBLE, ", flinkIdentifier(f.Value))
+	}
+	source := fmt.Sprintf(
+		"CREATE TEMPORARY TABLE featureform_source (%s STRING, %s%s TIMESTAMP(3), WATERMARK FOR %s AS %s - %s) WITH (%s)",
+		flinkIdentifier(f.Entity), valueColumn, flinkIdentifier(f.Timestamp),
+		flinkIdentifier(f.Timestamp), flinkIdentifier(f.Timestamp), flinkInterval(f.WatermarkDelay),
+		flinkKafkaOptions(f.Source, true),
+	)
+	resultType := "DOUBLE"
+	aggregation := fmt.Sprintf("CAST(%s(%s) AS DOUBLE)", f.Aggregation, flinkIdentifier(f.Value))
+	if f.Aggregation == FlinkCount {
+		resultType, aggregation = "BIGINT", "COUNT(*)"
+	}
+	results := fmt.Sprintf(
+		"CREATE TEMPORARY TABLE featureform_results (entity STRING, `value` %s, window_end TIMESTAMP(3)) WITH (%s)",
+		resultType, flinkKafkaOptions(f.Results, false),
+	)
+	insert := fmt.Sprintf(
+		"INSERT INTO featureform_results SELECT %s, %s, window_end FROM TABLE(HOP(TABLE featureform_source, DESCRIPTOR(%s), %s, %s)) GROUP BY %s, window_start, window_end",
+		flinkIdentifier(f.Entity), aggregation, flinkIdentifier(f.Timestamp),
+		flinkInterval(f.Slide), flinkInterval(f.Size), flinkIdentifier(f.Entity),
+	)
+	return []string{
+		fmt.Sprintf("SET 'pipeline.name' = %s", flinkString(jobName)),
+		source,
+		results,
+		insert,
+	}, nil
+}
+
+var flinkLoginModules = map[pc.KafkaSASLMechanism]string{
+	"":                      "org.apache.kafka.common.security.plain.PlainLoginModule",
+	pc.KafkaSASLPlain:       "org.apache.kafka.common.security.plain.PlainLoginModule",
+	pc.KafkaSASLScramSHA256: "org.apache.kafka.common.security.scram.ScramLoginModule",
+	pc.KafkaSASLScramSHA512: "org.apache.kafka.common.security.scram.ScramLoginModule",
+}
+
+// flinkKafkaOptions are the options of a table in a Kafka topic. Sources are
+// read from their consumer group's offsets, so a job that's submitted again
+// carries on from where the last one stopped.
+func flinkKafkaOptions(config pc.KafkaConfig, source bool) string {
+	options := [][2]string{
+		{"connector", "kafka"},
+		{"topic", config.Topic},
+		{"properties.bootstrap.servers", strings.Join(config.Brokers, ",")},
+		{"format", "json"},
+		{"json.timestamp-format.standard", "ISO-8601"},
+		{"properties.security.protocol", kafkaSecurityProtocol(config)},
+	}
+	if source {
+		options = append(options,
+			[2]string{"properties.group.id", config.GroupID},
+			[2]string{"scan.startup.mode", "group-offsets"},
+			[2]string{"properties.auto.offset.reset", "earliest"},
+			[2]string{"json.ignore-parse-errors", "true"},
+		)
+	}
+	if config.Username != "" {
+		mechanism := config.Mechanism
+		if mechanism == "" {
+			mechanism = pc.KafkaSASLPlain
+		}
+		quote := strings.NewReplacer(`\`, `\\`, `"`, `\"`)
+		jaas := fmt.Sprintf(`%s required username="%s" password="%s";`, flinkLoginModules[mechanism], quote.Replace(config.Username), quote.Replace(config.Password))
+		options = append(options,
+			[2]string{"properties.sasl.mechanism", string(mechanism)},
+			[2]string{"properties.sasl.jaas.config", jaas},
+		)
+	}
+	formatted := make([]string, len(options))
+	for i, option := range options {
+		formatted[i] = fmt.Sprintf("%s = %s", flinkString(option[0]), flinkString(option[1]))
+	}
+	return strings.Join(formatted, ", ")
+}
+
+func flinkIdentifier(name string) string {
+	return "`" + strings.ReplaceAll(name, "`", "``") + "`"
+}
+
+func flinkString(value string) string {
+	return "'" + strings.ReplaceAll(value, "'", "''") + "'"
+}
+
+// flinkInterval formats a duration in its largest whole unit. Intervals with
+// more than two digits need their precision set.
+func flinkInterval(d time.Duration) string {
+	value, unit := int64(d/time.Second), "SECOND"
+	for _, u := range []struct {
+		name     string
+		duration time.Duration
+	}{{"DAY", 24 * time.Hour}, {"HOUR", time.Hour}, {"MINUTE", time.Minute}} {
+		if d != 0 && d%u.duration == 0 {
+			value, unit = int64(d/u.duration), u.name
+			break
+		}
+	}
+	digits := len(fmt.Sprint(value))
+	if digits > 2 {
+		unit = fmt.Sprintf("%s(%d)", unit, digits)
+	}
+	return fmt.Sprintf("INTERVAL '%d' %s", value, unit)
+}
+
+// flinkWindowResult is a message that a sliding window feature's job writes
+// to its results topic.
+type flinkWindowResult struct {
+	Entity *string      `json:"entity"`
+	Value  *json.Number `json:"value"`
+}
+
+// ParseFlinkWindowResult returns the value of an entity in a window's result.
+// It returns false for results without a value, which aggregations of only
+// null values have.
+func ParseFlinkWindowResult(message []byte, valueType ValueType) (FeatureChange, bool, error) {
+	decoder := json.NewDecoder(bytes.NewReader(message))
+	decoder.UseNumber()
+	result := flinkWindowResult{}
+	if err := decoder.Decode(&result); err != nil {
+		return FeatureChange{}, false, fmt.Errorf("invalid window result: %w", err)
+	}
+	if result.Entity == nil {
+		return FeatureChange{}, false, errors.New("window result has no entity")
+	}
+	if result.Value == nil {
+		return FeatureChange{}, false, nil
+	}
+	value, err := castImportValue(valueType, *result.Value)
+	if err != nil {
+		return FeatureChange{}, false, fmt.Errorf("could not cast value of %s: %w", *result.Entity, err)
+	}
+	return FeatureChange{Operation: ChangeUpsert, Entity: *result.Entity, Value: value}, true, nil
+}
+
+type FlinkJobState string
+
+// The states of Flink jobs. Jobs that are restarting after a failure are
+// still running.
+const (
+	FlinkJobInitializing FlinkJobState = "INITIALIZING"
+	FlinkJobCreated      FlinkJobState = "CREATED"
+	FlinkJobRunning      FlinkJobState = "RUNNING"
+	FlinkJobRestarting   FlinkJobState = "RESTARTING"
+	FlinkJobFailing      FlinkJobState = "FAILING"
+	FlinkJobFailed       FlinkJobState = "FAILED"
+	FlinkJobCancelling   FlinkJobState = "CANCELLING"
+	FlinkJobCanceled     FlinkJobState = "CANCELED"
+	FlinkJobFinished     FlinkJobState = "FINISHED"
+	FlinkJobSuspended    FlinkJobState = "SUSPENDED"
+	FlinkJobReconciling  FlinkJobState = "RECONCILING"
+)
+
+// Terminal is true if the job will never run again.
+func (s FlinkJobState) Terminal() bool {
+	return s == FlinkJobFailed || s == FlinkJobCanceled || s == FlinkJobFinished || s == FlinkJobSuspended
+}
+
+type FlinkJob struct {
+	ID    string
+	Name  string
+	State FlinkJobState
+	// Error is the exception that the job failed with.
+	Error string
+}
+
+// FlinkJobClient submits and manages the jobs of a Flink cluster.
+type FlinkJobClient interface {
+	// SubmitSQL executes the statements in a session and returns the id of
+	// the job that the last one starts.
+	SubmitSQL(statements []string) (string, error)
+	// FindJob returns the most recently started job with the name, which is
+	// false if there isn't one.
+	FindJob(name string) (FlinkJob, bool, error)
+	Job(id string) (FlinkJob, error)
+	CancelJob(id string) error
+}
+
+type flinkClient struct {
+	config pc.FlinkConfig
+	client *http.Client
+}
+
+func NewFlinkClient(config pc.FlinkConfig) (FlinkJobClient, error) {
+	if config.GatewayURL == "" || config.RESTURL == "" {
+		return nil, errors.New("flink SQL gateway and REST URLs are required")
+	}
+	return &flinkClient{config: config, client: &http.Client{Timeout: 30 * time.Second}}, nil
+}
+
+func (c *flinkClient) request(method, url string, body, response interface{}) error {
+	var reader io.Reader
+	if body != nil {
+		serialized, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		reader = bytes.NewReader(serialized)
+	}
+	req, err := http.NewRequest(method, url, reader)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("flink request %s %s failed: %w", method, url, err)
+	}
+	defer resp.Body.Close()
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("flink request %s %s failed with status %d: %s", method, url, resp.StatusCode, respBody)
+	}
+	if response == nil || len(respBody) == 0 {
+		return nil
+	}
+	return json.Unmarshal(respBody, response)
+}
+
+func (c *flinkClient) gatewayURL(format string, args ...interface{}) string {
+	return strings.TrimSuffix(c.config.GatewayURL, "/") + fmt.Sprintf(format, args...)
+}
+
+func (c *flinkClient) restURL(format string, args ...interface{}) string {
+	return strings.TrimSuffix(c.config.RESTURL, "/") + fmt.Sprintf(format, args...)
+}
+
+func (c *flinkClient) SubmitSQL(statements []string) (string, error) {
+	if len(statements) == 0 {
+		return "", errors.New("no statements to submit")
+	}
+	session := struct {
+		SessionHandle string `json:"sessionHandle"`
+	}{}
+	if err := c.request(http.MethodPost, c.gatewayURL("/v1/sessions"), map[string]string{}, &session); err != nil {
+		return "", err
+	}
+	defer c.request(http.MethodDelete, c.gatewayURL("/v1/sessions/%s", session.SessionHandle), nil, nil)
+	var operation string
+	for _, statement := range statements {
+		submitted := struct {
+			OperationHandle string `json:"operationHandle"`
+		}{}
+		if err := c.request(http.MethodPost, c.gatewayURL("/v1/sessions/%s/statements", session.SessionHandle), map[string]string{"statement": statement}, &submitted); err != nil {
+			return "", err
+		}
+		operation = submitted.OperationHandle
+		if err := c.awaitOperation(session.SessionHandle, operation); err != nil {
+			return "", fmt.Errorf("could not execute %q: %w", statement, err)
+		}
+	}
+	result := struct {
+		JobID   string `json:"jobID"`
+		Results struct {
+			Data []struct {
+				Fields []interface{} `json:"fields"`
+			} `json:"data"`
+		} `json:"results"`
+	}{}
+	if err := c.request(http.MethodGet, c.gatewayURL("/v1/sessions/%s/operations/%s/result/0", session.SessionHandle, operation), nil, &result); err != nil {
+		return "", err
+	}
+	if result.JobID != "" {
+		return result.JobID, nil
+	}
+	if len(result.Results.Data) > 0 && len(result.Results.Data[0].Fields) > 0 {
+		return fmt.Sprint(result.Results.Data[0].Fields[0]), nil
+	}
+	return "", errors.New("flink did not return the id of the submitted job")
+}
+
+func (c *flinkClient) awaitOperation(session, operation string) error {
+	for {
+		status := struct {
+			Status string `json:"status"`
+		}{}
+		if err := c.request(http.MethodGet, c.gatewayURL("/v1/sessions/%s/operations/%s/status", session, operation), nil, &status); err != nil {
+			return err
+		}
+		switch status.Status {
+		case "FINISHED":
+			return nil
+		case "ERROR", "CANCELED", "CLOSED", "TIMEOUT":
+			// Failed operations return their error when their result is
+			// fetched.
+			err := c.request(http.MethodGet, c.gatewayURL("/v1/sessions/%s/operations/%s/result/0", session, operation), nil, nil)
+			if err == nil {
+				err = fmt.Errorf("statement %s", strings.ToLower(status.Status))
+			}
+			return err
+		}
+		time.Sleep(flinkStatementPollInterval)
+	}
+}
+
+func (c *flinkClient) FindJob(name string) (FlinkJob, bool, error) {
+	overview := struct {
+		Jobs []struct {
+			ID        string        `json:"jid"`
+			Name      string        `json:"name"`
+			State     FlinkJobState `json:"state"`
+			StartTime int64         `json:"start-time"`
+		} `json:"jobs"`
+	}{}
+	if err := c.request(http.MethodGet, c.restURL("/jobs/overview"), nil, &overview); err != nil {
+		return FlinkJob{}, false, err
+	}
+	var found FlinkJob
+	latest := int64(-1)
+	for _, job := range overview.Jobs {
+		if job.Name == name && job.StartTime > latest {
+			found, latest = FlinkJob{ID: job.ID, Name: job.Name, State: job.State}, job.StartTime
+		}
+	}
+	return found, latest >= 0, nil
+}
+
+func (c *flinkClient) Job(id string) (FlinkJob, error) {
+	details := struct {
+		ID    string        `json:"jid"`
+		Name  string        `json:"name"`
+		State FlinkJobState `json:"state"`
+	}{}
+	if err := c.request(http.MethodGet, c.restURL("/jobs/%s", id), nil, &details); err != nil {
+		return FlinkJob{}, err
+	}
+	job := FlinkJob{ID: details.ID, Name: details.Name, State: details.State}
+	if job.State == FlinkJobFailed {
+		exceptions := struct {
+			RootException string `json:"root-exception"`
+		}{}
+		if err := c.request(http.MethodGet, c.restURL("/jobs/%s/exceptions", id), nil, &exceptions); err != nil {
+			return FlinkJob{}, err
+		}
+		job.Error = exceptions.RootException
+	}
+	return job, nil
+}
+
+func (c *flinkClient) CancelJob(id string) error {
+	return c.request(http.MethodPatch, c.restURL("/jobs/%s?mode=cancel", id), nil, nil)
+}
@@ -0,0 +1,202 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package provider
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	pc "github.com/featureform/provider/provider_config"
+)
+
+func testSlidingWindowFeature() SlidingWindowFeature {
+	return SlidingWindowFeature{
+		Source:         pc.KafkaConfig{Brokers: []string{"broker:9092"}, Topic: "transactions", GroupID: "featureform-source"},
+		Entity:         "user",
+		Value:          "amount",
+		Timestamp:      "ts",
+		Aggregation:    FlinkSum,
+		Size:           time.Hour,
+		Slide:          5 * time.Minute,
+		WatermarkDelay: 30 * time.Second,
+		Results:        pc.KafkaConfig{Brokers: []string{"broker:9092"}, Topic: "results", GroupID: "featureform-results"},
+	}
+}
+
+func TestSlidingWindowFeatureStatements(t *testing.T) {
+	statements, err := testSlidingWindowFeature().Statements("featureform-spend-v1")
+	if err != nil {
+		t.Fatalf("Failed to create statements: %s", err)
+	}
+	if len(statements) != 4 {
+		t.Fatalf("Expected 4 statements, got %d", len(statements))
+	}
+	if statements[0] != "SET 'pipeline.name' = 'featureform-spend-v1'" {
+		t.Fatalf("Unexpected job name statement: %s", statements[0])
+	}
+	expectedSource := "(`user` STRING, `amount` DOUBLE, `ts` TIMESTAMP(3), WATERMARK FOR `ts` AS `ts` - INTERVAL '30' SECOND)"
+	if !strings.Contains(statements[1], expectedSource) || !strings.Contains(statements[1], "'scan.startup.mode' = 'group-offsets'") {
+		t.Fatalf("Unexpected source statement: %s", statements[1])
+	}
+	if !strings.Contains(statements[2], "`value` DOUBLE") || strings.Contains(statements[2], "properties.group.id") {
+		t.Fatalf("Unexpected results statement: %s", statements[2])
+	}
+	expectedInsert := "INSERT INTO featureform_results SELECT `user`, CAST(SUM(`amount`) AS DOUBLE), window_end FROM TABLE(HOP(TABLE featureform_source, DESCRIPTOR(`ts`), INTERVAL '5' MINUTE, INTERVAL '1' HOUR)) GROUP BY `user`, window_start, window_end"
+	if statements[3] != expectedInsert {
+		t.Fatalf("Expected insert:\n%s\ngot:\n%s", expectedInsert, statements[3])
+	}
+}
+
+func TestSlidingWindowFeatureValidate(t *testing.T) {
+	count := testSlidingWindowFeature()
+	count.Aggregation, count.Value = FlinkCount, ""
+	if err := count.Validate(); err != nil {
+		t.Fatalf("Expected count without a value to be valid: %s", err)
+	}
+	if count.ValueType() != Int64 {
+		t.Fatalf("Expected counts to be int64, got %v", count.ValueType())
+	}
+	invalid := map[string]func(f *SlidingWindowFeature){
+		"sum without value":    func(f *SlidingWindowFeature) { f.Value = "" },
+		"unknown aggregation":  func(f *SlidingWindowFeature) { f.Aggregation = "MEDIAN" },
+		"fractional seconds":   func(f *SlidingWindowFeature) { f.Slide = 1500 * time.Millisecond },
+		"size not a multiple":  func(f *SlidingWindowFeature) { f.Slide = 7 * time.Minute },
+		"no results group":     func(f *SlidingWindowFeature) { f.Results.GroupID = "" },
+		"no timestamp field":   func(f *SlidingWindowFeature) { f.Timestamp = "" },
+		"no window size/slide": func(f *SlidingWindowFeature) { f.Size, f.Slide = 0, 0 },
+	}
+	for name, modify := range invalid {
+		feature := testSlidingWindowFeature()
+		modify(&feature)
+		if err := feature.Validate(); err == nil {
+			t.Errorf("Expected %s to be invalid", name)
+		}
+	}
+}
+
+func TestFlinkInterval(t *testing.T) {
+	tests := map[time.Duration]string{
+		0:                     "INTERVAL '0' SECOND",
+		45 * time.Second:      "INTERVAL '45' SECOND",
+		90 * time.Second:      "INTERVAL '90' SECOND",
+		150 * time.Second:     "INTERVAL '150' SECOND(3)",
+		2 * time.Hour:         "INTERVAL '2' HOUR",
+		36 * time.Hour:        "INTERVAL '36' HOUR",
+		7 * 24 * time.Hour:    "INTERVAL '7' DAY",
+		125 * time.Minute:     "INTERVAL '125' MINUTE(3)",
+		1000 * 24 * time.Hour: "INTERVAL '1000' DAY(4)",
+	}
+	for d, expected := range tests {
+		if interval := flinkInterval(d); interval != expected {
+			t.Errorf("Expected %s for %s, got %s", expected, d, interval)
+		}
+	}
+}
+
+func TestFlinkKafkaOptionsSASL(t *testing.T) {
+	config := pc.KafkaConfig{Brokers: []string{"broker:9092"}, Topic: "results", Username: "user", Password: `pa"ss'word`, TLS: true}
+	options := flinkKafkaOptions(config, false)
+	expected := []string{
+		"'properties.security.protocol' = 'SASL_SSL'",
+		"'properties.sasl.mechanism' = 'PLAIN'",
+		`'properties.sasl.jaas.config' = 'org.apache.kafka.common.security.plain.PlainLoginModule required username="user" password="pa\"ss''word";'`,
+	}
+	for _, option := range expected {
+		if !strings.Contains(options, option) {
+			t.Errorf("Expected options to contain %s, got %s", option, options)
+		}
+	}
+}
+
+func TestParseFlinkWindowResult(t *testing.T) {
+	change, ok, err := ParseFlinkWindowResult([]byte(`{"entity": "a", "value": 12.5, "window_end": "2023-06-01T00:00:00"}`), Float64)
+	if err != nil || !ok {
+		t.Fatalf("Failed to parse result: %v", err)
+	}
+	if change.Entity != "a" || change.Value != 12.5 {
+		t.Fatalf("Unexpected change: %+v", change)
+	}
+	if _, ok, err := ParseFlinkWindowResult([]byte(`{"entity": "a", "value": null}`), Float64); err != nil || ok {
+		t.Fatalf("Expected results without a value to be skipped: %v", err)
+	}
+	if _, _, err := ParseFlinkWindowResult([]byte(`{"value": 1}`), Float64); err == nil {
+		t.Fatalf("Expected results without an entity to fail")
+	}
+}
+
+func TestFlinkClient(t *testing.T) {
+	flinkStatementPollInterval = time.Millisecond
+	var statements []string
+	var cancelled string
+	mux := http.NewServeMux()
+	mux.HandleFunc("/v1/sessions", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"sessionHandle": "session"}`))
+	})
+	mux.HandleFunc("/v1/sessions/session/statements", func(w http.ResponseWriter, r *http.Request) {
+		body := map[string]string{}
+		json.NewDecoder(r.Body).Decode(&body)
+		statements = append(statements, body["statement"])
+		w.Write([]byte(`{"operationHandle": "op"}`))
+	})
+	mux.HandleFunc("/v1/sessions/session/operations/op/status", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"status": "FINISHED"}`))
+	})
+	mux.HandleFunc("/v1/sessions/session/operations/op/result/0", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"jobID": "job-2", "results": {"data": [{"fields": ["job-2"]}]}}`))
+	})
+	mux.HandleFunc("/jobs/overview", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"jobs": [
+			{"jid": "job-1", "name": "featureform-spend-v1", "state": "CANCELED", "start-time": 1},
+			{"jid": "job-2", "name": "featureform-spend-v1", "state": "RUNNING", "start-time": 2},
+			{"jid": "job-3", "name": "other", "state": "RUNNING", "start-time": 3}
+		]}`))
+	})
+	mux.HandleFunc("/jobs/job-2", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodPatch {
+			cancelled = r.URL.Query().Get("mode")
+			w.WriteHeader(http.StatusAccepted)
+			return
+		}
+		w.Write([]byte(`{"jid": "job-2", "name": "featureform-spend-v1", "state": "FAILED"}`))
+	})
+	mux.HandleFunc("/jobs/job-2/exceptions", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"root-exception": "java.lang.RuntimeException: broken"}`))
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	client, err := NewFlinkClient(pc.FlinkConfig{GatewayURL: server.URL, RESTURL: server.URL + "/"})
+	if err != nil {
+		t.Fatalf("Failed to create client: %s", err)
+	}
+	jobID, err := client.SubmitSQL([]string{"SET 'a' = 'b'", "INSERT INTO t SELECT 1"})
+	if err != nil {
+		t.Fatalf("Failed to submit statements: %s", err)
+	}
+	if jobID != "job-2" || len(statements) != 2 {
+		t.Fatalf("Expected job-2 from 2 statements, got %s from %v", jobID, statements)
+	}
+	job, found, err := client.FindJob("featureform-spend-v1")
+	if err != nil || !found || job.ID != "job-2" || job.State != FlinkJobRunning {
+		t.Fatalf("Expected to find running job-2, got %+v %v: %v", job, found, err)
+	}
+	if _, found, err := client.FindJob("missing"); err != nil || found {
+		t.Fatalf("Expected no job to be found: %v", err)
+	}
+	job, err = client.Job("job-2")
+	if err != nil || job.State != FlinkJobFailed || !strings.Contains(job.Error, "broken") {
+		t.Fatalf("Expected failed job with its exception, got %+v: %v", job, err)
+	}
+	if err := client.CancelJob("job-2"); err != nil || cancelled != "cancel" {
+		t.Fatalf("Failed to cancel job: %v", err)
+	}
+	if _, err := NewFlinkClient(pc.FlinkConfig{GatewayURL: server.URL}); err == nil {
+		t.Fatalf("Expected client without a REST URL to fail")
+	}
+}
@@ -0,0 +1,75 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package provider
+
+import "math"
+
+// float32ToFloat16 converts f to the bits of the nearest IEEE 754
+// half-precision float, rounding ties to even. Values too large for a half
+// become infinities and values too small become zeros or subnormals.
+func float32ToFloat16(f float32) uint16 {
+	bits := math.Float32bits(f)
+	sign := uint16(bits>>16) & 0x8000
+	exponent := int32(bits>>23) & 0xff
+	mantissa := bits & 0x7fffff
+	if exponent == 0xff {
+		if mantissa != 0 {
+			return sign | 0x7e00
+		}
+		return sign | 0x7c00
+	}
+	exponent -= 127 - 15
+	if exponent >= 0x1f {
+		return sign | 0x7c00
+	}
+	if exponent <= 0 {
+		if exponent < -10 {
+			return sign
+		}
+		// Subnormal halves have no implicit leading bit, so it's added to
+		// the mantissa before shifting it into place.
+		mantissa |= 0x800000
+		shift := uint32(14 - exponent)
+		half := mantissa >> shift
+		remainder := mantissa & (1<<shift - 1)
+		midpoint := uint32(1) << (shift - 1)
+		if remainder > midpoint || (remainder == midpoint && half&1 == 1) {
+			half++
+		}
+		return sign | uint16(half)
+	}
+	half := uint32(exponent)<<10 | mantissa>>13
+	remainder := mantissa & 0x1fff
+	if remainder > 0x1000 || (remainder == 0x1000 && half&1 == 1) {
+		// A carry out of the mantissa correctly increments the exponent,
+		// overflowing to infinity at the largest exponent.
+		half++
+	}
+	return sign | uint16(half)
+}
+
+// float16ToFloat32 converts the bits of an IEEE 754 half-precision float to
+// a float32, which represents every half exactly.
+func float16ToFloat32(h uint16) float32 {
+	sign := uint32(h&0x8000) << 16
+	exponent := uint32(h>>10) & 0x1f
+	mantissa := uint32(h & 0x3ff)
+	switch {
+	case exponent == 0x1f:
+		return math.Float32frombits(sign | 0x7f800000 | mantissa<<13)
+	case exponent == 0 && mantissa == 0:
+		return math.Float32frombits(sign)
+	case exponent == 0:
+		// Normalize the subnormal half, since every half is a normal float32.
+		exponent = 127 - 15 + 1
+		for mantissa&0x400 == 0 {
+			mantissa <<= 1
+			exponent--
+		}
+		return math.Float32frombits(sign | exponent<<23 | (mantissa&0x3ff)<<13)
+	default:
+		return math.Float32frombits(sign | (exponent+127-15)<<23 | mantissa<<13)
+	}
+}
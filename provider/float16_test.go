@@ -0,0 +1,47 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package provider
+
+import (
+	"math"
+	"testing"
+)
+
+func TestFloat16Conversion(t *testing.T) {
+	tests := []struct {
+		name  string
+		value float32
+		half  uint16
+	}{
+		{"Zero", 0, 0x0000},
+		{"Negative Zero", float32(math.Copysign(0, -1)), 0x8000},
+		{"One", 1, 0x3c00},
+		{"Negative Two", -2, 0xc000},
+		{"Max", 65504, 0x7bff},
+		{"Overflow", 65536, 0x7c00},
+		{"Infinity", float32(math.Inf(-1)), 0xfc00},
+		{"Smallest Normal", float32(math.Ldexp(1, -14)), 0x0400},
+		{"Smallest Subnormal", float32(math.Ldexp(1, -24)), 0x0001},
+		{"Underflow", float32(math.Ldexp(1, -26)), 0x0000},
+		{"Round Down", 1 + float32(math.Ldexp(1, -11)), 0x3c00},
+		{"Round Up", 1 + 3*float32(math.Ldexp(1, -11)), 0x3c02},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if half := float32ToFloat16(tt.value); half != tt.half {
+				t.Fatalf("Expected %#04x, got %#04x", tt.half, half)
+			}
+		})
+	}
+	for half := 0; half < 0x7c00; half++ {
+		value := float16ToFloat32(uint16(half))
+		if roundTrip := float32ToFloat16(value); roundTrip != uint16(half) {
+			t.Fatalf("Expected %#04x to round trip through %v, got %#04x", half, value, roundTrip)
+		}
+	}
+	if value := float16ToFloat32(0x7e00); !math.IsNaN(float64(value)) {
+		t.Fatalf("Expected NaN, got %v", value)
+	}
+}
@@ -0,0 +1,54 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package provider
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	pc "github.com/featureform/provider/provider_config"
+)
+
+const testAuthorizedUser = `{"type": "authorized_user", "client_id": "id", "client_secret": "secret", "refresh_token": "token"}`
+
+func TestGoogleApplicationDefaultCredentials(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "adc.json")
+	if err := os.WriteFile(path, []byte(testAuthorizedUser), 0600); err != nil {
+		t.Fatalf("Failed to write credentials: %s", err)
+	}
+	t.Setenv("GOOGLE_APPLICATION_CREDENTIALS", path)
+	creds, err := googleCredentials(context.Background(), nil)
+	if err != nil {
+		t.Fatalf("Failed to find default credentials: %s", err)
+	}
+	if string(creds.JSON) != testAuthorizedUser {
+		t.Fatalf("Expected the application default credentials, got %s", creds.JSON)
+	}
+	key := map[string]interface{}{"type": "authorized_user", "client_id": "key", "client_secret": "secret", "refresh_token": "token"}
+	creds, err = googleCredentials(context.Background(), key)
+	if err != nil {
+		t.Fatalf("Failed to read key: %s", err)
+	}
+	if !strings.Contains(string(creds.JSON), `"client_id":"key"`) {
+		t.Fatalf("Expected the key's credentials, got %s", creds.JSON)
+	}
+}
+
+func TestSparkGCSFileStoreDefaultCredentials(t *testing.T) {
+	store := SparkGCSFileStore{GCSFileStore: &GCSFileStore{Bucket: "lake", Credentials: pc.GCPCredentials{ProjectId: "project"}}}
+	if config := strings.Join(store.SparkConfig(), " "); !strings.Contains(config, "fs.gs.auth.type=APPLICATION_DEFAULT") {
+		t.Fatalf("Expected application default credentials, got %s", config)
+	}
+	if credentials := strings.Join(store.CredentialsConfig(), " "); strings.Contains(credentials, "gcp_credentials") {
+		t.Fatalf("Expected no service account key, got %s", credentials)
+	}
+	store.SerializedCredentials = []byte(testAuthorizedUser)
+	if config := strings.Join(store.SparkConfig(), " "); !strings.Contains(config, "fs.gs.auth.type=SERVICE_ACCOUNT_JSON_KEYFILE") {
+		t.Fatalf("Expected a key file, got %s", config)
+	}
+}
@@ -0,0 +1,108 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package provider
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+const defaultHTTPTimeout = 30 * time.Second
+
+// jsonHTTPClient sends JSON requests to the REST APIs of online stores that
+// don't have a Go client, such as Weaviate and OpenSearch.
+type jsonHTTPClient struct {
+	baseURL string
+	header  http.Header
+	client  *http.Client
+}
+
+func newJSONHTTPClient(baseURL string, header http.Header, client *http.Client) *jsonHTTPClient {
+	if client == nil {
+		client = &http.Client{Timeout: defaultHTTPTimeout}
+	}
+	if header == nil {
+		header = make(http.Header)
+	}
+	return &jsonHTTPClient{
+		baseURL: strings.TrimSuffix(baseURL, "/"),
+		header:  header,
+		client:  client,
+	}
+}
+
+// httpStatusError is returned for responses with a non-2xx status code.
+type httpStatusError struct {
+	Method     string
+	Path       string
+	StatusCode int
+	Body       string
+}
+
+func (err *httpStatusError) Error() string {
+	return fmt.Sprintf("%s %s failed with status %d: %s", err.Method, err.Path, err.StatusCode, err.Body)
+}
+
+// isHTTPStatus returns true if err is an httpStatusError with the code.
+func isHTTPStatus(err error, code int) bool {
+	statusErr, ok := err.(*httpStatusError)
+	return ok && statusErr.StatusCode == code
+}
+
+// do sends request, if it's not nil, as the JSON body and decodes the JSON
+// response into response, if it's not nil.
+func (c *jsonHTTPClient) do(ctx context.Context, method, path string, request, response interface{}) error {
+	if request == nil {
+		return c.send(ctx, method, path, "", nil, response)
+	}
+	serialized, err := json.Marshal(request)
+	if err != nil {
+		return fmt.Errorf("could not serialize request: %w", err)
+	}
+	return c.send(ctx, method, path, "application/json", serialized, response)
+}
+
+// send sends body, if it's not nil, with the content type and decodes the
+// JSON response into response, if it's not nil. It's used for APIs that
+// take other formats than JSON, such as newline-delimited JSON.
+func (c *jsonHTTPClient) send(ctx context.Context, method, path, contentType string, body []byte, response interface{}) error {
+	var reader io.Reader
+	if body != nil {
+		reader = bytes.NewReader(body)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, c.baseURL+path, reader)
+	if err != nil {
+		return err
+	}
+	for key, values := range c.header {
+		req.Header[key] = values
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", contentType)
+	}
+	req.Header.Set("Accept", "application/json")
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		message, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+		return &httpStatusError{Method: method, Path: path, StatusCode: resp.StatusCode, Body: string(message)}
+	}
+	if response == nil {
+		return nil
+	}
+	if err := json.NewDecoder(resp.Body).Decode(response); err != nil {
+		return fmt.Errorf("could not parse response of %s %s: %w", method, path, err)
+	}
+	return nil
+}
@@ -0,0 +1,232 @@
+package provider
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/linkedin/goavro/v2"
+
+	pc "github.com/featureform/provider/provider_config"
+)
+
+const (
+	icebergMetadataFile FileType = "json"
+	// icebergDeletedEntry is the status of a manifest entry for a file that
+	// was removed in the manifest's snapshot.
+	icebergDeletedEntry = 2
+	icebergDataContent  = 0
+)
+
+// icebergTable reads an Iceberg table whose metadata and data files are in a
+// FileStore. Its current version is in the newest metadata file, which works
+// for tables in any catalog, including ones that don't write a version hint.
+// Only snapshots with Parquet data files can be read; row-level delete files
+// aren't applied, so snapshots with them are rejected.
+type icebergTable struct {
+	store FileStore
+	// location is the key of the table's directory in the store.
+	location string
+}
+
+func newIcebergTable(store FileStore, location string) *icebergTable {
+	return &icebergTable{store: store, location: strings.TrimSuffix(location, "/")}
+}
+
+func (t *icebergTable) Location() string {
+	return t.location
+}
+
+func (t *icebergTable) Format() pc.TableFormat {
+	return pc.IcebergTableFormat
+}
+
+// CurrentVersion is the id of the current snapshot.
+func (t *icebergTable) CurrentVersion() (string, error) {
+	snapshot, err := t.CurrentSnapshot()
+	if err != nil || snapshot == nil {
+		return "", err
+	}
+	return strconv.FormatInt(snapshot.SnapshotID, 10), nil
+}
+
+func (t *icebergTable) CurrentFiles() ([]tableDataFile, error) {
+	snapshot, err := t.CurrentSnapshot()
+	if err != nil {
+		return nil, err
+	}
+	return t.DataFiles(snapshot)
+}
+
+type icebergTableMetadata struct {
+	FormatVersion int `json:"format-version"`
+	// Location is the table's URI, which the URIs of its files start with.
+	Location          string            `json:"location"`
+	CurrentSnapshotID *int64            `json:"current-snapshot-id"`
+	Snapshots         []icebergSnapshot `json:"snapshots"`
+}
+
+type icebergSnapshot struct {
+	SnapshotID   int64             `json:"snapshot-id"`
+	TimestampMs  int64             `json:"timestamp-ms"`
+	ManifestList string            `json:"manifest-list"`
+	Summary      map[string]string `json:"summary"`
+}
+
+// Operation is how the snapshot changed the table, such as "append" or
+// "overwrite".
+func (s icebergSnapshot) Operation() string {
+	return s.Summary["operation"]
+}
+
+func (t *icebergTable) metadata() (*icebergTableMetadata, error) {
+	key, err := t.store.NewestFileOfType(fmt.Sprintf("%s/metadata/", t.location), icebergMetadataFile)
+	if err != nil {
+		return nil, fmt.Errorf("could not find metadata of iceberg table %s: %w", t.location, err)
+	}
+	if key == "" {
+		return nil, fmt.Errorf("%s is not an iceberg table: it has no metadata", t.location)
+	}
+	serialized, err := t.store.Read(key)
+	if err != nil {
+		return nil, fmt.Errorf("could not read iceberg metadata %s: %w", key, err)
+	}
+	metadata := &icebergTableMetadata{}
+	if err := json.Unmarshal(serialized, metadata); err != nil {
+		return nil, fmt.Errorf("could not parse iceberg metadata %s: %w", key, err)
+	}
+	metadata.Location = strings.TrimSuffix(metadata.Location, "/")
+	return metadata, nil
+}
+
+// CurrentSnapshot returns the table's current snapshot, or nil if nothing
+// has been written to it.
+func (t *icebergTable) CurrentSnapshot() (*icebergSnapshot, error) {
+	metadata, err := t.metadata()
+	if err != nil {
+		return nil, err
+	}
+	if metadata.CurrentSnapshotID == nil || *metadata.CurrentSnapshotID == -1 {
+		return nil, nil
+	}
+	return metadata.snapshot(*metadata.CurrentSnapshotID)
+}
+
+// Snapshot returns one of the table's snapshots, which is readable until
+// it's expired.
+func (t *icebergTable) Snapshot(id int64) (*icebergSnapshot, error) {
+	metadata, err := t.metadata()
+	if err != nil {
+		return nil, err
+	}
+	return metadata.snapshot(id)
+}
+
+func (m *icebergTableMetadata) snapshot(id int64) (*icebergSnapshot, error) {
+	for i := range m.Snapshots {
+		if m.Snapshots[i].SnapshotID == id {
+			return &m.Snapshots[i], nil
+		}
+	}
+	return nil, fmt.Errorf("iceberg table %s has no snapshot %d", m.Location, id)
+}
+
+// DataFiles returns the data files in a snapshot. A nil snapshot has none.
+func (t *icebergTable) DataFiles(snapshot *icebergSnapshot) ([]tableDataFile, error) {
+	if snapshot == nil {
+		return nil, nil
+	}
+	metadata, err := t.metadata()
+	if err != nil {
+		return nil, err
+	}
+	manifests, err := t.readAvro(metadata, snapshot.ManifestList)
+	if err != nil {
+		return nil, fmt.Errorf("could not read manifest list of snapshot %d: %w", snapshot.SnapshotID, err)
+	}
+	files := make([]tableDataFile, 0)
+	for _, manifest := range manifests {
+		// Manifests of format version 1 tables don't have a content type,
+		// since they only list data files.
+		if content, ok := manifest["content"].(int32); ok && content != icebergDataContent {
+			return nil, fmt.Errorf("snapshot %d of iceberg table %s has row-level deletes, which can't be read", snapshot.SnapshotID, t.location)
+		}
+		path, _ := manifest["manifest_path"].(string)
+		entries, err := t.readAvro(metadata, path)
+		if err != nil {
+			return nil, fmt.Errorf("could not read manifest %s: %w", path, err)
+		}
+		for _, entry := range entries {
+			if status, _ := entry["status"].(int32); status == icebergDeletedEntry {
+				continue
+			}
+			file, err := t.dataFile(metadata, entry)
+			if err != nil {
+				return nil, err
+			}
+			files = append(files, file)
+		}
+	}
+	return files, nil
+}
+
+func (t *icebergTable) dataFile(metadata *icebergTableMetadata, entry map[string]interface{}) (tableDataFile, error) {
+	dataFile, ok := entry["data_file"].(map[string]interface{})
+	if !ok {
+		return tableDataFile{}, fmt.Errorf("manifest entry has no data file: %v", entry)
+	}
+	path, _ := dataFile["file_path"].(string)
+	if content, ok := dataFile["content"].(int32); ok && content != icebergDataContent {
+		return tableDataFile{}, fmt.Errorf("%s is a delete file, which can't be read", path)
+	}
+	if format, _ := dataFile["file_format"].(string); !strings.EqualFold(format, string(Parquet)) {
+		return tableDataFile{}, fmt.Errorf("%s is a %s file, but only parquet data files can be read", path, format)
+	}
+	key, err := t.key(metadata, path)
+	if err != nil {
+		return tableDataFile{}, err
+	}
+	recordCount, _ := dataFile["record_count"].(int64)
+	return tableDataFile{Key: key, RecordCount: recordCount}, nil
+}
+
+// key returns the store key of one of the table's files, which Iceberg
+// refers to by its URI.
+func (t *icebergTable) key(metadata *icebergTableMetadata, uri string) (string, error) {
+	if !strings.HasPrefix(uri, metadata.Location+"/") {
+		return "", fmt.Errorf("%s is not in the location of iceberg table %s", uri, metadata.Location)
+	}
+	return t.location + strings.TrimPrefix(uri, metadata.Location), nil
+}
+
+// readAvro reads the records of an Avro file, such as a manifest list or a
+// manifest.
+func (t *icebergTable) readAvro(metadata *icebergTableMetadata, uri string) ([]map[string]interface{}, error) {
+	key, err := t.key(metadata, uri)
+	if err != nil {
+		return nil, err
+	}
+	serialized, err := t.store.Read(key)
+	if err != nil {
+		return nil, err
+	}
+	reader, err := goavro.NewOCFReader(bytes.NewReader(serialized))
+	if err != nil {
+		return nil, err
+	}
+	records := make([]map[string]interface{}, 0)
+	for reader.Scan() {
+		datum, err := reader.Read()
+		if err != nil {
+			return nil, err
+		}
+		record, ok := datum.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("expected a record in %s, got %T", uri, datum)
+		}
+		records = append(records, record)
+	}
+	return records, reader.Err()
+}
@@ -0,0 +1,219 @@
+package provider
+
+import (
+	"bytes"
+	"fmt"
+	"testing"
+
+	"github.com/linkedin/goavro/v2"
+	"github.com/segmentio/parquet-go"
+)
+
+const (
+	testIcebergKey      = "featureform/Materialization/feature/variant"
+	testIcebergLocation = "s3://bucket/warehouse/" + testIcebergKey
+	testManifestList    = `{"type": "record", "name": "manifest_file", "fields": [
+		{"name": "manifest_path", "type": "string"},
+		{"name": "content", "type": "int"}
+	]}`
+	testManifest = `{"type": "record", "name": "manifest_entry", "fields": [
+		{"name": "status", "type": "int"},
+		{"name": "data_file", "type": {"type": "record", "name": "r2", "fields": [
+			{"name": "content", "type": "int"},
+			{"name": "file_path", "type": "string"},
+			{"name": "file_format", "type": "string"},
+			{"name": "record_count", "type": "long"}
+		]}}
+	]}`
+)
+
+type testIcebergRow struct {
+	Entity string `parquet:"entity"`
+	Value  int64  `parquet:"value"`
+	TS     string `parquet:"ts"`
+}
+
+func writeTestAvro(t *testing.T, store FileStore, key, schema string, records []map[string]interface{}) {
+	buf := new(bytes.Buffer)
+	writer, err := goavro.NewOCFWriter(goavro.OCFConfig{W: buf, Schema: schema})
+	if err != nil {
+		t.Fatalf("Failed to create avro writer: %s", err)
+	}
+	data := make([]interface{}, len(records))
+	for i, record := range records {
+		data[i] = record
+	}
+	if err := writer.Append(data); err != nil {
+		t.Fatalf("Failed to write avro: %s", err)
+	}
+	if err := store.Write(key, buf.Bytes()); err != nil {
+		t.Fatalf("Failed to write %s: %s", key, err)
+	}
+}
+
+func testManifestEntry(status int32, file string, format string, rows int64) map[string]interface{} {
+	return map[string]interface{}{
+		"status": status,
+		"data_file": map[string]interface{}{
+			"content":      int32(0),
+			"file_path":    fmt.Sprintf("%s/data/%s", testIcebergLocation, file),
+			"file_format":  format,
+			"record_count": rows,
+		},
+	}
+}
+
+// writeTestIcebergTable writes a table with two snapshots: 1 appends a
+// file, and 2 replaces it with another.
+func writeTestIcebergTable(t *testing.T, store FileStore) {
+	files := map[string][]testIcebergRow{
+		"a.parquet": {{"a", 1, "2023-01-01 00:00:00.000000 UTC"}},
+		"b.parquet": {{"a", 2, "2023-01-02 00:00:00.000000 UTC"}, {"b", 3, "2023-01-02 00:00:00.000000 UTC"}},
+	}
+	for name, rows := range files {
+		buf := new(bytes.Buffer)
+		if err := parquet.Write(buf, rows); err != nil {
+			t.Fatalf("Failed to write parquet: %s", err)
+		}
+		if err := store.Write(fmt.Sprintf("%s/data/%s", testIcebergKey, name), buf.Bytes()); err != nil {
+			t.Fatalf("Failed to write data file: %s", err)
+		}
+	}
+	writeTestAvro(t, store, testIcebergKey+"/metadata/m1.avro", testManifest, []map[string]interface{}{
+		testManifestEntry(1, "a.parquet", "PARQUET", 1),
+	})
+	writeTestAvro(t, store, testIcebergKey+"/metadata/m2.avro", testManifest, []map[string]interface{}{
+		testManifestEntry(icebergDeletedEntry, "a.parquet", "PARQUET", 1),
+		testManifestEntry(1, "b.parquet", "PARQUET", 2),
+	})
+	for i := 1; i <= 2; i++ {
+		writeTestAvro(t, store, fmt.Sprintf("%s/metadata/snap-%d.avro", testIcebergKey, i), testManifestList, []map[string]interface{}{
+			{"manifest_path": fmt.Sprintf("%s/metadata/m%d.avro", testIcebergLocation, i), "content": int32(0)},
+		})
+	}
+	metadata := fmt.Sprintf(`{
+		"format-version": 2,
+		"location": "%[1]s",
+		"current-snapshot-id": 2,
+		"snapshots": [
+			{"snapshot-id": 1, "timestamp-ms": 1, "manifest-list": "%[1]s/metadata/snap-1.avro", "summary": {"operation": "append"}},
+			{"snapshot-id": 2, "timestamp-ms": 2, "manifest-list": "%[1]s/metadata/snap-2.avro", "summary": {"operation": "overwrite"}}
+		]
+	}`, testIcebergLocation)
+	if err := store.Write(testIcebergKey+"/metadata/v2.metadata.json", []byte(metadata)); err != nil {
+		t.Fatalf("Failed to write metadata: %s", err)
+	}
+}
+
+func TestIcebergTableSnapshots(t *testing.T) {
+	store := newLocalTestFileStore(t)
+	writeTestIcebergTable(t, store)
+	table := newIcebergTable(store, testIcebergKey+"/")
+	current, err := table.CurrentSnapshot()
+	if err != nil {
+		t.Fatalf("Failed to get current snapshot: %s", err)
+	}
+	if current.SnapshotID != 2 || current.Operation() != "overwrite" {
+		t.Fatalf("Expected overwrite snapshot 2, got %+v", current)
+	}
+	files, err := table.DataFiles(current)
+	if err != nil {
+		t.Fatalf("Failed to get data files: %s", err)
+	}
+	expected := tableDataFile{Key: testIcebergKey + "/data/b.parquet", RecordCount: 2}
+	if len(files) != 1 || files[0] != expected {
+		t.Fatalf("Expected %v, got %v", expected, files)
+	}
+	first, err := table.Snapshot(1)
+	if err != nil {
+		t.Fatalf("Failed to get snapshot 1: %s", err)
+	}
+	files, err = table.DataFiles(first)
+	if err != nil {
+		t.Fatalf("Failed to get data files: %s", err)
+	}
+	if len(files) != 1 || files[0].Key != testIcebergKey+"/data/a.parquet" {
+		t.Fatalf("Expected a.parquet in snapshot 1, got %v", files)
+	}
+	if _, err := table.Snapshot(3); err == nil {
+		t.Fatalf("Expected an error for a missing snapshot")
+	}
+	if _, err := newIcebergTable(store, "featureform/Materialization/missing/variant").CurrentSnapshot(); err == nil {
+		t.Fatalf("Expected an error for a missing table")
+	}
+}
+
+func TestIcebergTableUnreadableFiles(t *testing.T) {
+	store := newLocalTestFileStore(t)
+	writeTestIcebergTable(t, store)
+	table := newIcebergTable(store, testIcebergKey)
+	first, err := table.Snapshot(1)
+	if err != nil {
+		t.Fatalf("Failed to get snapshot 1: %s", err)
+	}
+	writeTestAvro(t, store, testIcebergKey+"/metadata/m1.avro", testManifest, []map[string]interface{}{
+		testManifestEntry(1, "a.orc", "ORC", 1),
+	})
+	if _, err := table.DataFiles(first); err == nil {
+		t.Fatalf("Expected an error for an ORC data file")
+	}
+	writeTestAvro(t, store, testIcebergKey+"/metadata/snap-1.avro", testManifestList, []map[string]interface{}{
+		{"manifest_path": testIcebergLocation + "/metadata/m1.avro", "content": int32(1)},
+	})
+	if _, err := table.DataFiles(first); err == nil {
+		t.Fatalf("Expected an error for a delete manifest")
+	}
+}
+
+func TestIcebergMaterialization(t *testing.T) {
+	store := newLocalTestFileStore(t)
+	writeTestIcebergTable(t, store)
+	id := ResourceID{Name: "feature", Variant: "variant", Type: FeatureMaterialization}
+	mat, err := newTableMaterialization(id, store, newIcebergTable(store, testIcebergKey))
+	if err != nil {
+		t.Fatalf("Failed to open materialization: %s", err)
+	}
+	if mat.ID() != "Materialization/feature/variant" {
+		t.Fatalf("Unexpected materialization id %s", mat.ID())
+	}
+	rows, err := mat.NumRows()
+	if err != nil || rows != 2 {
+		t.Fatalf("Expected 2 rows, got %d: %v", rows, err)
+	}
+	iter, err := mat.IterateSegment(1, 2)
+	if err != nil {
+		t.Fatalf("Failed to iterate: %s", err)
+	}
+	if !iter.Next() {
+		t.Fatalf("Expected a record: %v", iter.Err())
+	}
+	if record := iter.Value(); record.Entity != "b" || record.Value != int64(3) {
+		t.Fatalf("Expected b's value 3, got %+v", record)
+	}
+	if iter.Next() {
+		t.Fatalf("Expected the segment to end, got %+v", iter.Value())
+	}
+}
+
+func TestIcebergEmptyTable(t *testing.T) {
+	store := newLocalTestFileStore(t)
+	metadata := fmt.Sprintf(`{"format-version": 2, "location": "%s", "current-snapshot-id": -1, "snapshots": []}`, testIcebergLocation)
+	if err := store.Write(testIcebergKey+"/metadata/v1.metadata.json", []byte(metadata)); err != nil {
+		t.Fatalf("Failed to write metadata: %s", err)
+	}
+	id := ResourceID{Name: "feature", Variant: "variant", Type: FeatureMaterialization}
+	mat, err := newTableMaterialization(id, store, newIcebergTable(store, testIcebergKey))
+	if err != nil {
+		t.Fatalf("Failed to open materialization: %s", err)
+	}
+	if rows, _ := mat.NumRows(); rows != 0 {
+		t.Fatalf("Expected no rows, got %d", rows)
+	}
+	iter, err := mat.IterateSegment(0, 10)
+	if err != nil {
+		t.Fatalf("Failed to iterate: %s", err)
+	}
+	if iter.Next() {
+		t.Fatalf("Expected no records, got %+v", iter.Value())
+	}
+}
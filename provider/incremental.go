@@ -0,0 +1,40 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package provider
+
+import (
+	"fmt"
+)
+
+// IncrementalOfflineStore is implemented by offline stores that can update a
+// materialization from only the rows that are newer than its high watermark,
+// which is the latest timestamp that it has materialized, instead of
+// recomputing it from the whole feature table.
+//
+// Rows that arrive with a timestamp at or before the watermark are missed,
+// so features whose rows can arrive late need full updates.
+type IncrementalOfflineStore interface {
+	OfflineStore
+	// UpdateMaterializationIncrementally merges the feature's rows that are
+	// after the watermark into its materialization, and returns a
+	// materialization of only the entities whose values changed, which is
+	// what has to be copied to the online store.
+	UpdateMaterializationIncrementally(id ResourceID) (Materialization, error)
+}
+
+type IncrementalMaterializationNotSupported struct {
+	ProviderType string
+}
+
+func (err *IncrementalMaterializationNotSupported) Error() string {
+	return fmt.Sprintf("incremental materialization is not supported by %s", err.ProviderType)
+}
+
+// incrementalMaterializationID is the id of the materialization of the
+// entities that changed in a materialization's last incremental update.
+// Names can't contain double underscores, so it can't be another feature's.
+func incrementalMaterializationID(id MaterializationID) MaterializationID {
+	return MaterializationID(fmt.Sprintf("%s__incremental", id))
+}
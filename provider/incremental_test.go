@@ -0,0 +1,39 @@
+package provider
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestSnowflakeIncrementalMaterializationQueries(t *testing.T) {
+	var q incrementalMaterializationQueries = snowflakeSQLQueries{}
+	statements := q.materializationIncrementalUpdate("featureform_materialization_f", "featureform_materialization_f__incremental", "featureform_resource_feature__f__v")
+	expected := []string{
+		`DROP TABLE IF EXISTS "featureform_materialization_f__incremental"`,
+		`CREATE TABLE "featureform_materialization_f__incremental" AS (SELECT entity, value, ts, row_number() over(ORDER BY entity) as row_number FROM ` +
+			`(SELECT entity, ts, value, row_number() OVER (PARTITION BY entity ORDER BY ts desc) AS rn FROM "featureform_resource_feature__f__v" ` +
+			`WHERE ts > (SELECT MAX(ts) FROM "featureform_materialization_f") OR NOT EXISTS (SELECT 1 FROM "featureform_materialization_f")) t WHERE rn=1)`,
+		`CREATE TABLE "tmp_featureform_materialization_f" AS (SELECT entity, value, ts, row_number() over(ORDER BY entity) as row_number FROM ` +
+			`(SELECT entity, value, ts FROM "featureform_materialization_f__incremental" UNION ALL ` +
+			`SELECT entity, value, ts FROM "featureform_materialization_f" WHERE entity NOT IN (SELECT entity FROM "featureform_materialization_f__incremental")) t)`,
+		`ALTER TABLE "featureform_materialization_f" RENAME TO "old_featureform_materialization_f"`,
+		`ALTER TABLE "tmp_featureform_materialization_f" RENAME TO "featureform_materialization_f"`,
+		`DROP TABLE "old_featureform_materialization_f"`,
+	}
+	if !reflect.DeepEqual(statements, expected) {
+		t.Fatalf("expected %v, got %v", expected, statements)
+	}
+}
+
+func TestIncrementalMaterializationSupport(t *testing.T) {
+	if _, ok := interface{}(redshiftSQLQueries{}).(incrementalMaterializationQueries); !ok {
+		t.Fatalf("expected redshift to support incremental materialization")
+	}
+	// Postgres materializations are views, which are refreshed in full.
+	if _, ok := interface{}(postgresSQLQueries{}).(incrementalMaterializationQueries); ok {
+		t.Fatalf("expected postgres not to support incremental materialization")
+	}
+	if id := incrementalMaterializationID("feature"); id != "feature__incremental" {
+		t.Fatalf("unexpected incremental materialization id %s", id)
+	}
+}
@@ -0,0 +1,78 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package provider
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/featureform/types"
+)
+
+// IndexBuildingStore is implemented by vector stores that index vectors in
+// the background, so that Nearest can miss vectors that were just written.
+// WatchIndexBuild returns a watcher that completes once the feature
+// variant's index has caught up with every vector written before the call.
+type IndexBuildingStore interface {
+	VectorStore
+	WatchIndexBuild(feature, variant string) (types.CompletionWatcher, error)
+}
+
+// indexBuildWatcher polls an index until ready reports that it's built or
+// returns an error.
+type indexBuildWatcher struct {
+	done chan struct{}
+	err  error
+}
+
+func newIndexBuildWatcher(interval time.Duration, ready func() (bool, error)) *indexBuildWatcher {
+	watcher := &indexBuildWatcher{done: make(chan struct{})}
+	go func() {
+		defer close(watcher.done)
+		for {
+			built, err := ready()
+			if err != nil {
+				watcher.err = fmt.Errorf("could not check index build: %w", err)
+				return
+			}
+			if built {
+				return
+			}
+			time.Sleep(interval)
+		}
+	}()
+	return watcher
+}
+
+func (w *indexBuildWatcher) Complete() bool {
+	select {
+	case <-w.done:
+		return true
+	default:
+		return false
+	}
+}
+
+func (w *indexBuildWatcher) String() string {
+	if !w.Complete() {
+		return "Index still building."
+	}
+	if w.err != nil {
+		return fmt.Sprintf("Index build failed with error: %v", w.err)
+	}
+	return "Index built."
+}
+
+func (w *indexBuildWatcher) Wait() error {
+	<-w.done
+	return w.err
+}
+
+func (w *indexBuildWatcher) Err() error {
+	if !w.Complete() {
+		return nil
+	}
+	return w.err
+}
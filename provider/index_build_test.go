@@ -0,0 +1,47 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package provider
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestIndexBuildWatcher(t *testing.T) {
+	polls := 0
+	release := make(chan struct{})
+	watcher := newIndexBuildWatcher(time.Millisecond, func() (bool, error) {
+		polls++
+		select {
+		case <-release:
+			return true, nil
+		default:
+			return false, nil
+		}
+	})
+	if watcher.Complete() {
+		t.Fatalf("Expected watcher to wait for the index to build")
+	}
+	close(release)
+	if err := watcher.Wait(); err != nil {
+		t.Fatalf("Failed to wait for index build: %s", err)
+	}
+	if !watcher.Complete() || watcher.Err() != nil {
+		t.Fatalf("Expected completed watcher, got %s", watcher)
+	}
+	if polls < 1 {
+		t.Fatalf("Expected index to be polled")
+	}
+	failing := newIndexBuildWatcher(time.Millisecond, func() (bool, error) {
+		return false, errors.New("connection refused")
+	})
+	if err := failing.Wait(); err == nil {
+		t.Fatalf("Expected the watcher to fail when the index can't be checked")
+	}
+	if failing.Err() == nil {
+		t.Fatalf("Expected Err to report the failure")
+	}
+}
@@ -16,6 +16,7 @@ import (
 	"strings"
 	"time"
 
+	"github.com/linkedin/goavro/v2"
 	dp "github.com/novln/docker-parser"
 	"github.com/segmentio/parquet-go"
 	"go.uber.org/zap"
@@ -43,6 +44,14 @@ type pandasOfflineQueries struct {
 	defaultPythonOfflineQueries
 }
 
+// materializationCreate leaves timestamps in their source's time zone, since
+// pandasql runs queries with SQLite, which can't convert them. They're
+// converted when the materialization is read instead.
+func (q pandasOfflineQueries) materializationCreate(schema ResourceSchema) string {
+	schema.TSTimezone = ""
+	return q.defaultPythonOfflineQueries.materializationCreate(schema)
+}
+
 func (q pandasOfflineQueries) trainingSetCreate(def TrainingSetDef, featureSchemas []ResourceSchema, labelSchema ResourceSchema) string {
 	columns := make([]string, 0)
 	joinQueries := make([]string, 0)
@@ -107,6 +116,8 @@ type K8sOfflineStore struct {
 	store    FileStore
 	logger   *zap.SugaredLogger
 	query    *pandasOfflineQueries
+	// retention limits the snapshots kept of materializations.
+	retention pc.RetentionConfig
 	BaseProvider
 }
 
@@ -118,6 +129,34 @@ func (k8s *K8sOfflineStore) Close() error {
 	return k8s.store.Close()
 }
 
+// Vacuum removes the files of orphaned resources.
+func (k8s *K8sOfflineStore) Vacuum(config VacuumConfig) (VacuumResult, error) {
+	result, err := vacuumFileStore(k8s.store, config, time.Now())
+	if err != nil {
+		return result, err
+	}
+	k8s.logger.Infow("Vacuumed orphaned resources", "count", len(result.Resources), "bytes", result.ReclaimedBytes, "dry_run", config.DryRun)
+	return result, nil
+}
+
+// GetChunkCheckpoint reads the feature's chunk checkpoint, which is kept in
+// the file store.
+func (k8s *K8sOfflineStore) GetChunkCheckpoint(id ResourceID) (ChunkCheckpoint, error) {
+	return fileStoreChunkCheckpoints{k8s.store}.GetChunkCheckpoint(id)
+}
+
+func (k8s *K8sOfflineStore) StartChunkCheckpoint(id ResourceID, plan ChunkPlan) error {
+	return fileStoreChunkCheckpoints{k8s.store}.StartChunkCheckpoint(id, plan)
+}
+
+func (k8s *K8sOfflineStore) CompleteChunk(id ResourceID, chunk int64) error {
+	return fileStoreChunkCheckpoints{k8s.store}.CompleteChunk(id, chunk)
+}
+
+func (k8s *K8sOfflineStore) DeleteChunkCheckpoint(id ResourceID) error {
+	return fileStoreChunkCheckpoints{k8s.store}.DeleteChunkCheckpoint(id)
+}
+
 type Config []byte
 
 type ExecutorFactory func(config Config, logger *zap.SugaredLogger) (Executor, error)
@@ -201,6 +240,10 @@ func k8sOfflineStoreFactory(config pc.SerializedConfig) (Provider, error) {
 		logger.Errorw("Invalid config to initialize k8s offline store", "error", err)
 		return nil, fmt.Errorf("invalid k8s config: %w", err)
 	}
+	if err := k8.Retention.Validate(); err != nil {
+		logger.Errorw("Invalid retention for k8s offline store", "error", err)
+		return nil, err
+	}
 	logger.Info("Creating executor with type:", k8.ExecutorType)
 	execConfig := k8.ExecutorConfig.(pc.ExecutorConfig)
 	serializedExecutor, err := execConfig.Serialize()
@@ -228,10 +271,11 @@ func k8sOfflineStoreFactory(config pc.SerializedConfig) (Provider, error) {
 	logger.Debugf("Store type: %s", k8.StoreType)
 	queries := pandasOfflineQueries{}
 	k8sOfflineStore := K8sOfflineStore{
-		executor: executor,
-		store:    store,
-		logger:   logger,
-		query:    &queries,
+		executor:  executor,
+		store:     store,
+		logger:    logger,
+		query:     &queries,
+		retention: k8.Retention,
 		BaseProvider: BaseProvider{
 			ProviderType:   "K8S_OFFLINE",
 			ProviderConfig: config,
@@ -326,9 +370,15 @@ func (kube *KubernetesExecutor) ExecuteScript(envVars map[string]string, args *m
 		kube.setCustomImage(args.DockerImage)
 		specs = args.Specs
 	}
-	if isDefault, err := kube.isDefaultImage(); err != nil {
+	isDefault, err := kube.isDefaultImage()
+	if err != nil {
 		return fmt.Errorf("image check failed: %w", err)
-	} else if !isDefault {
+	}
+	// Custom images are used as is; only the default image has GPU and arm64 variants.
+	image := kube.image
+	if isDefault {
+		image = kubernetes.ImageVariant(image, specs)
+	} else {
 		kube.logger.Warnf("You are using a custom Docker Image (%s) for a Kubernetes job. This may have unintended behavior.", kube.image)
 	}
 	envVars["MODE"] = "k8s"
@@ -339,7 +389,7 @@ func (kube *KubernetesExecutor) ExecuteScript(envVars map[string]string, args *m
 	config := kubernetes.KubernetesRunnerConfig{
 		JobPrefix: "kcf",
 		EnvVars:   envVars,
-		Image:     kube.image,
+		Image:     image,
 		NumTasks:  1,
 		Resource: metadata.ResourceID{
 			Name:    envVars["RESOURCE_NAME"],
@@ -383,6 +433,8 @@ type FileStore interface {
 	Delete(key string) error
 	DeleteAll(dir string) error
 	NewestFileOfType(prefix string, fileType FileType) (string, error)
+	// ListFiles returns the files whose keys start with prefix.
+	ListFiles(prefix string) ([]StoredFile, error)
 	PathWithPrefix(path string, remote bool) string
 	NumRows(key string) (int64, error)
 	Close() error
@@ -392,6 +444,14 @@ type FileStore interface {
 	AddEnvVars(envVars map[string]string) map[string]string
 }
 
+// StoredFile is a file in a FileStore, when it was last written and its size
+// in bytes.
+type StoredFile struct {
+	Key     string
+	ModTime time.Time
+	Size    int64
+}
+
 type Iterator interface {
 	Next() (map[string]interface{}, error)
 	FeatureColumns() []string
@@ -401,6 +461,8 @@ type Iterator interface {
 type genericFileStore struct {
 	bucket *blob.Bucket
 	path   string
+	// writerOptions are used for every object written, if they're set.
+	writerOptions *blob.WriterOptions
 }
 
 func (store *genericFileStore) PathWithPrefix(path string, remote bool) string {
@@ -430,6 +492,25 @@ func (store *genericFileStore) NewestFileOfType(prefix string, fileType FileType
 	}
 }
 
+func (store *genericFileStore) ListFiles(prefix string) ([]StoredFile, error) {
+	opts := blob.ListOptions{
+		Prefix: prefix,
+	}
+	listIterator := store.bucket.List(&opts)
+	files := make([]StoredFile, 0)
+	for {
+		listObj, err := listIterator.Next(context.TODO())
+		if err == io.EOF {
+			return files, nil
+		} else if err != nil {
+			return nil, err
+		}
+		if !listObj.IsDir {
+			files = append(files, StoredFile{Key: listObj.Key, ModTime: listObj.ModTime, Size: listObj.Size})
+		}
+	}
+}
+
 func (store *genericFileStore) getMoreRecentFile(newObj *blob.ListObject, expectedFileType FileType, oldTime time.Time, oldKey string) (time.Time, string) {
 	pathParts := strings.Split(newObj.Key, ".")
 	fileType := pathParts[len(pathParts)-1]
@@ -495,7 +576,7 @@ func (store *genericFileStore) DeleteAll(dir string) error {
 }
 
 func (store *genericFileStore) Write(key string, data []byte) error {
-	err := store.bucket.WriteAll(context.TODO(), key, data, nil)
+	err := store.bucket.WriteAll(context.TODO(), key, data, store.writerOptions)
 	if err != nil {
 		return err
 	}
@@ -503,7 +584,7 @@ func (store *genericFileStore) Write(key string, data []byte) error {
 }
 
 func (store *genericFileStore) Writer(key string) (*blob.Writer, error) {
-	return store.bucket.NewWriter(context.TODO(), key, nil)
+	return store.bucket.NewWriter(context.TODO(), key, store.writerOptions)
 }
 
 func (store *genericFileStore) Read(key string) ([]byte, error) {
@@ -514,6 +595,15 @@ func (store *genericFileStore) Read(key string) ([]byte, error) {
 	return data, nil
 }
 
+func (store *genericFileStore) ReadRange(key string, offset, length int64) ([]byte, error) {
+	reader, err := store.bucket.NewRangeReader(context.TODO(), key, offset, length, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer reader.Close()
+	return io.ReadAll(reader)
+}
+
 func (store *genericFileStore) ServeDirectory(dir string) (Iterator, error) {
 	fileParts := store.outputFileList(dir)
 	if len(fileParts) == 0 {
@@ -581,12 +671,10 @@ func convertToParquetBytes(list []any) ([]byte, error) {
 }
 
 type ParquetIteratorMultipleFiles struct {
-	fileList       []string
-	currentFile    int64
-	fileIterator   Iterator
-	featureColumns []string
-	labelColumn    string
-	store          FileStore
+	fileList     []string
+	currentFile  int64
+	fileIterator Iterator
+	store        FileStore
 }
 
 func parquetIteratorOverMultipleFiles(fileParts []string, store FileStore) (Iterator, error) {
@@ -607,12 +695,14 @@ func parquetIteratorOverMultipleFiles(fileParts []string, store FileStore) (Iter
 	}, nil
 }
 
+// FeatureColumns and LabelColumn are the same for every file, so they're
+// those of the current one.
 func (p *ParquetIteratorMultipleFiles) FeatureColumns() []string {
-	return p.featureColumns
+	return p.fileIterator.FeatureColumns()
 }
 
 func (p *ParquetIteratorMultipleFiles) LabelColumn() string {
-	return p.labelColumn
+	return p.fileIterator.LabelColumn()
 }
 
 func (p *ParquetIteratorMultipleFiles) Next() (map[string]interface{}, error) {
@@ -749,6 +839,97 @@ func newCSVIterator(b []byte, limit int64) (GenericTableIterator, error) {
 	}, nil
 }
 
+type avroIterator struct {
+	reader        *goavro.OCFReader
+	currentValues GenericRecord
+	err           error
+	columnNames   []string
+	// unions are the columns whose values are unions, which goavro decodes
+	// as a map from the value's type to the value.
+	unions map[string]bool
+	limit  int64
+	idx    int64
+}
+
+func (a *avroIterator) Next() bool {
+	if a.idx >= a.limit || !a.reader.Scan() {
+		a.err = a.reader.Err()
+		return false
+	}
+	datum, err := a.reader.Read()
+	if err != nil {
+		a.err = err
+		return false
+	}
+	record, ok := datum.(map[string]interface{})
+	if !ok {
+		a.err = fmt.Errorf("expected an avro record, got %T", datum)
+		return false
+	}
+	values := make(GenericRecord, len(a.columnNames))
+	for i, column := range a.columnNames {
+		value := record[column]
+		if union, ok := value.(map[string]interface{}); ok && a.unions[column] {
+			for _, v := range union {
+				value = v
+			}
+		}
+		values[i] = value
+	}
+	a.currentValues = values
+	a.idx += 1
+	return true
+}
+
+func (a *avroIterator) Values() GenericRecord {
+	return a.currentValues
+}
+
+func (a *avroIterator) Columns() []string {
+	return a.columnNames
+}
+
+func (a *avroIterator) Err() error {
+	return a.err
+}
+
+func (a *avroIterator) Close() error {
+	return nil
+}
+
+func newAvroIterator(b []byte, limit int64) (GenericTableIterator, error) {
+	reader, err := goavro.NewOCFReader(bytes.NewReader(b))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create avro reader: %w", err)
+	}
+	var schema struct {
+		Fields []struct {
+			Name string
+			Type interface{}
+		}
+	}
+	if err := json.Unmarshal([]byte(reader.Codec().Schema()), &schema); err != nil {
+		return nil, fmt.Errorf("could not parse avro schema: %w", err)
+	}
+	columnNames := make([]string, len(schema.Fields))
+	unions := make(map[string]bool)
+	for i, field := range schema.Fields {
+		columnNames[i] = field.Name
+		if _, ok := field.Type.([]interface{}); ok {
+			unions[field.Name] = true
+		}
+	}
+	if limit == -1 {
+		limit = math.MaxInt64
+	}
+	return &avroIterator{
+		reader:      reader,
+		columnNames: columnNames,
+		unions:      unions,
+		limit:       limit,
+	}, nil
+}
+
 type parquetIterator struct {
 	reader        *parquet.Reader
 	currentValues GenericRecord
@@ -933,6 +1114,9 @@ func blobRegisterResource(id ResourceID, schema ResourceSchema, logger *zap.Suga
 		logger.Errorw("Failure checking ID", "error", err)
 		return nil, fmt.Errorf("ID check failed: %v", err)
 	}
+	if _, err := schema.tsLocation(); err != nil {
+		return nil, err
+	}
 	resourceKey := store.PathWithPrefix(fileStoreResourcePath(id), false)
 	resourceExists, err := store.Exists(resourceKey)
 	if err != nil {
@@ -984,6 +1168,8 @@ func (tbl *FileStorePrimaryTable) IterateSegment(n int64) (GenericTableIterator,
 		return newParquetIterator(b, n)
 	case "csv":
 		return newCSVIterator(b, n)
+	case "avro":
+		return newAvroIterator(b, n)
 	default:
 		return nil, fmt.Errorf("unsupported file type: %s", fileType)
 	}
@@ -1371,7 +1557,30 @@ func (k8s *K8sOfflineStore) CreateMaterialization(id ResourceID) (Materializatio
 }
 
 func (k8s *K8sOfflineStore) GetMaterialization(id MaterializationID) (Materialization, error) {
-	return fileStoreGetMaterialization(id, k8s.store, k8s.logger)
+	materialization, err := fileStoreGetMaterialization(id, k8s.store, k8s.logger)
+	if err != nil {
+		return nil, err
+	}
+	fileMaterialization := materialization.(*FileStoreMaterialization)
+	featureID := ResourceID{Name: fileMaterialization.id.Name, Variant: fileMaterialization.id.Variant, Type: Feature}
+	schema, err := k8s.registeredResourceSchema(featureID)
+	if err != nil {
+		return nil, err
+	}
+	if fileMaterialization.location, err = schema.tsLocation(); err != nil {
+		return nil, err
+	}
+	return fileMaterialization, nil
+}
+
+func (k8s *K8sOfflineStore) GetFeatureMaterialization(id ResourceID) (Materialization, error) {
+	return k8s.GetMaterialization(fileStoreMaterializationID(id))
+}
+
+// fileStoreMaterializationID is the id of a feature's materialization in a
+// file-based offline store.
+func fileStoreMaterializationID(id ResourceID) MaterializationID {
+	return MaterializationID(fmt.Sprintf("%s/%s/%s", FeatureMaterialization, id.Name, id.Variant))
 }
 
 func fileStoreGetMaterialization(id MaterializationID, store FileStore, logger *zap.SugaredLogger) (Materialization, error) {
@@ -1389,13 +1598,16 @@ func fileStoreGetMaterialization(id MaterializationID, store FileStore, logger *
 		return nil, fmt.Errorf("could not fetch materialization resource key: %v", err)
 	}
 	logger.Debugw("Successfully retrieved materialization", "id", id)
-	return &FileStoreMaterialization{materializationID, store, materializationExactPath}, nil
+	return &FileStoreMaterialization{materializationID, store, materializationExactPath, nil}, nil
 }
 
 type FileStoreMaterialization struct {
 	id    ResourceID
 	store FileStore
 	key   string
+	// location is the time zone of timestamps that were materialized without
+	// one. If it's nil, they're in UTC.
+	location *time.Location
 }
 
 func (mat FileStoreMaterialization) ID() MaterializationID {
@@ -1425,18 +1637,20 @@ func (mat FileStoreMaterialization) IterateSegment(begin, end int64) (FeatureIte
 		_, _ = iter.Next()
 	}
 	return &FileStoreFeatureIterator{
-		iter:   iter,
-		curIdx: 0,
-		maxIdx: end,
+		iter:     iter,
+		curIdx:   0,
+		maxIdx:   end,
+		location: mat.location,
 	}, nil
 }
 
 type FileStoreFeatureIterator struct {
-	iter   Iterator
-	err    error
-	cur    ResourceRecord
-	curIdx int64
-	maxIdx int64
+	iter     Iterator
+	err      error
+	cur      ResourceRecord
+	curIdx   int64
+	maxIdx   int64
+	location *time.Location
 }
 
 func (iter *FileStoreFeatureIterator) Next() bool {
@@ -1479,26 +1693,38 @@ func (iter *FileStoreFeatureIterator) Next() bool {
 // 2. "2006-01-02 15:04:05.000000"
 // 3. "2006-01-02 15:04:05.000000 +0000 UTC"
 // If any one of the three formats is valid, returns the parsed timestamp, otherwise it
-// returns an error
+// returns an error. Timestamps in the second format are read in the
+// iterator's location and converted to UTC.
 func (iter *FileStoreFeatureIterator) parseTimestamp(ts string) (time.Time, error) {
-	formats := []string{
-		fmt.Sprintf("%s UTC", baseDateFormat),
-		baseDateFormat,
-		fmt.Sprintf("%s +0000 UTC", baseDateFormat),
+	location := iter.location
+	if location == nil {
+		location = time.UTC
+	}
+	formats := []struct {
+		layout   string
+		location *time.Location
+	}{
+		{fmt.Sprintf("%s UTC", baseDateFormat), time.UTC},
+		{baseDateFormat, location},
+		{fmt.Sprintf("%s +0000 UTC", baseDateFormat), time.UTC},
 	}
 	for _, format := range formats {
-		timestamp, err := time.Parse(format, ts)
+		timestamp, err := time.ParseInLocation(format.layout, ts, format.location)
 		if err == nil {
-			return timestamp, nil
+			return timestamp.UTC(), nil
 		}
 	}
 	return time.Time{}, fmt.Errorf("could not parse timestamp: %v", ts)
 }
 
+func (iter *FileStoreFeatureIterator) parseValue(value interface{}) (interface{}, error) {
+	return parseParquetValue(value)
+}
+
 // Attempts to parse value in one of the following formats:
 // 1. a scalar value (string, int, float, bool)
 // 2. []float32 (i.e. vector32)
-func (iter *FileStoreFeatureIterator) parseValue(value interface{}) (interface{}, error) {
+func parseParquetValue(value interface{}) (interface{}, error) {
 	valueMap, ok := value.(map[string]interface{})
 	if !ok {
 		return value, nil
@@ -1594,8 +1820,17 @@ func (k8s *K8sOfflineStore) materialization(id ResourceID, isUpdate bool) (Mater
 	if err != nil {
 		return nil, fmt.Errorf("materialization does not exist; %v", err)
 	}
+	location, err := k8sResourceTable.schema.tsLocation()
+	if err != nil {
+		return nil, err
+	}
 	k8s.logger.Debugw("Successfully created materialization", "id", id)
-	return &FileStoreMaterialization{materializationID, k8s.store, latestMatPath}, nil
+	if deleted, err := applyMaterializationRetention(k8s.store, materializationID, k8s.retention); err != nil {
+		k8s.logger.Errorw("Could not delete expired materialization snapshots", "id", id, "error", err)
+	} else if deleted > 0 {
+		k8s.logger.Infow("Deleted expired materialization snapshots", "id", id, "count", deleted)
+	}
+	return &FileStoreMaterialization{materializationID, k8s.store, latestMatPath, location}, nil
 }
 
 func (k8s *K8sOfflineStore) DeleteMaterialization(id MaterializationID) error {
@@ -1678,6 +1913,14 @@ func (k8s *K8sOfflineStore) trainingSet(def TrainingSetDef, isUpdate bool) error
 		sourcePaths = append(sourcePaths, featurePath)
 		featureSchemas = append(featureSchemas, featureSchema)
 	}
+	// pandasql runs queries with SQLite, which can't convert time zones, so
+	// joining on timestamps in different ones would silently skew the
+	// training set.
+	for _, schema := range append(featureSchemas, labelSchema) {
+		if schema.TSTimezone != "" {
+			return fmt.Errorf("training sets with timestamps in time zone %s are not supported by the kubernetes offline store", schema.TSTimezone)
+		}
+	}
 	trainingSetQuery := k8s.query.trainingSetCreate(def, featureSchemas, labelSchema)
 	k8s.logger.Debugw("Training set query", "query", sourcePaths)
 	k8s.logger.Debugw("Source list", "list", trainingSetQuery)
@@ -977,3 +977,33 @@ func TestParquetIterator_vector32(t *testing.T) {
 		}
 	}
 }
+
+func TestFileStoreFeatureIteratorParseTimestampLocation(t *testing.T) {
+	losAngeles, err := time.LoadLocation("America/Los_Angeles")
+	if err != nil {
+		t.Fatalf("Failed to load location: %s", err)
+	}
+	tests := []struct {
+		name     string
+		location *time.Location
+		ts       string
+		expected time.Time
+	}{
+		{"Naive UTC", nil, "2023-01-01 12:00:00", time.Date(2023, 1, 1, 12, 0, 0, 0, time.UTC)},
+		{"Naive Local", losAngeles, "2023-01-01 12:00:00", time.Date(2023, 1, 1, 20, 0, 0, 0, time.UTC)},
+		{"Explicit UTC", losAngeles, "2023-01-01 12:00:00 UTC", time.Date(2023, 1, 1, 12, 0, 0, 0, time.UTC)},
+		{"Explicit Offset", losAngeles, "2023-01-01 12:00:00 +0000 UTC", time.Date(2023, 1, 1, 12, 0, 0, 0, time.UTC)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			iter := &FileStoreFeatureIterator{location: tt.location}
+			timestamp, err := iter.parseTimestamp(tt.ts)
+			if err != nil {
+				t.Fatalf("Failed to parse timestamp: %s", err)
+			}
+			if !timestamp.Equal(tt.expected) {
+				t.Fatalf("Expected %s, got %s", tt.expected, timestamp)
+			}
+		})
+	}
+}
@@ -0,0 +1,184 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package provider
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	pc "github.com/featureform/provider/provider_config"
+	pt "github.com/featureform/provider/provider_type"
+)
+
+// kafkaSourcePrefix marks a source of a Spark job that's a Kafka topic,
+// rather than a path in the file store.
+const kafkaSourcePrefix = "kafka:"
+
+type KafkaValueFormat string
+
+const (
+	KafkaRawValue  KafkaValueFormat = "raw"
+	KafkaJSONValue KafkaValueFormat = "json"
+	KafkaAvroValue KafkaValueFormat = "avro"
+)
+
+// KafkaProvider is a Kafka cluster whose topics are registered as sources.
+// It's neither an online nor an offline store: its topics are read by the
+// streaming transformations of Spark offline stores.
+type KafkaProvider struct {
+	BaseProvider
+	config pc.KafkaConfig
+}
+
+func kafkaProviderFactory(config pc.SerializedConfig) (Provider, error) {
+	kafkaConfig := pc.KafkaConfig{}
+	if err := kafkaConfig.Deserialize(config); err != nil {
+		return nil, fmt.Errorf("invalid kafka config: %w", err)
+	}
+	if len(kafkaConfig.Brokers) == 0 {
+		return nil, errors.New("kafka provider requires at least one broker")
+	}
+	return &KafkaProvider{
+		BaseProvider: BaseProvider{
+			ProviderType:   pt.Kafka,
+			ProviderConfig: config,
+		},
+		config: kafkaConfig,
+	}, nil
+}
+
+// TopicPartitions returns the partitions of a topic, which is an error if
+// the topic doesn't exist.
+func (k *KafkaProvider) TopicPartitions(topic string) ([]int, error) {
+	dialer, err := kafkaDialer(k.config)
+	if err != nil {
+		return nil, err
+	}
+	var dialErr error
+	for _, broker := range k.config.Brokers {
+		conn, err := dialer.DialContext(context.Background(), "tcp", broker)
+		if err != nil {
+			dialErr = err
+			continue
+		}
+		partitions, err := conn.ReadPartitions(topic)
+		conn.Close()
+		if err != nil {
+			return nil, fmt.Errorf("could not read partitions of kafka topic %s: %w", topic, err)
+		}
+		ids := make([]int, len(partitions))
+		for i, partition := range partitions {
+			ids[i] = partition.ID
+		}
+		return ids, nil
+	}
+	return nil, fmt.Errorf("could not connect to kafka brokers: %w", dialErr)
+}
+
+// KafkaTopicSource is a topic that a streaming transformation reads from.
+// StartingOffsets is where a job without a checkpoint starts reading: either
+// "earliest", "latest", or the offset of each partition as JSON.
+type KafkaTopicSource struct {
+	Topic           string
+	ValueFormat     KafkaValueFormat
+	StartingOffsets string
+}
+
+func (t KafkaTopicSource) Validate(config pc.KafkaConfig) error {
+	if t.Topic == "" {
+		return errors.New("kafka source requires a topic")
+	}
+	switch t.ValueFormat {
+	case KafkaRawValue, KafkaJSONValue:
+	case KafkaAvroValue:
+		if config.SchemaRegistry.URL == "" {
+			return fmt.Errorf("kafka topic %s has avro values, which requires a schema registry", t.Topic)
+		}
+	default:
+		return fmt.Errorf("unsupported kafka value format %q", t.ValueFormat)
+	}
+	return nil
+}
+
+// KafkaStartingOffsets returns the starting offsets of a topic that start
+// reading each of its partitions at the given offset.
+func KafkaStartingOffsets(topic string, offsets map[int32]int64) (string, error) {
+	partitions := make(map[string]int64, len(offsets))
+	for partition, offset := range offsets {
+		partitions[strconv.Itoa(int(partition))] = offset
+	}
+	serialized, err := json.Marshal(map[string]map[string]int64{topic: partitions})
+	if err != nil {
+		return "", err
+	}
+	return string(serialized), nil
+}
+
+// kafkaSourceSpec is how a Kafka source is passed to the Spark script,
+// which reads it with Spark's Kafka source.
+type kafkaSourceSpec struct {
+	BootstrapServers       string `json:"bootstrap_servers"`
+	Topic                  string `json:"topic"`
+	ValueFormat            string `json:"value_format"`
+	StartingOffsets        string `json:"starting_offsets"`
+	SecurityProtocol       string `json:"security_protocol"`
+	SASLMechanism          string `json:"sasl_mechanism"`
+	Username               string `json:"username"`
+	Password               string `json:"password"`
+	SchemaRegistryURL      string `json:"schema_registry_url"`
+	SchemaRegistryUsername string `json:"schema_registry_username"`
+	SchemaRegistryPassword string `json:"schema_registry_password"`
+}
+
+// KafkaSourcePath returns the source of a Spark job that reads a topic.
+func KafkaSourcePath(config pc.KafkaConfig, topic KafkaTopicSource) (string, error) {
+	if err := topic.Validate(config); err != nil {
+		return "", err
+	}
+	spec := kafkaSourceSpec{
+		BootstrapServers:       strings.Join(config.Brokers, ","),
+		Topic:                  topic.Topic,
+		ValueFormat:            string(topic.ValueFormat),
+		StartingOffsets:        topic.StartingOffsets,
+		SecurityProtocol:       kafkaSecurityProtocol(config),
+		Username:               config.Username,
+		Password:               config.Password,
+		SchemaRegistryURL:      config.SchemaRegistry.URL,
+		SchemaRegistryUsername: config.SchemaRegistry.Username,
+		SchemaRegistryPassword: config.SchemaRegistry.Password,
+	}
+	if spec.StartingOffsets == "" {
+		spec.StartingOffsets = "earliest"
+	}
+	if config.Username != "" {
+		spec.SASLMechanism = string(config.Mechanism)
+		if spec.SASLMechanism == "" {
+			spec.SASLMechanism = string(pc.KafkaSASLPlain)
+		}
+	}
+	serialized, err := json.Marshal(spec)
+	if err != nil {
+		return "", err
+	}
+	return kafkaSourcePrefix + base64.StdEncoding.EncodeToString(serialized), nil
+}
+
+func kafkaSecurityProtocol(config pc.KafkaConfig) string {
+	switch {
+	case config.TLS && config.Username != "":
+		return "SASL_SSL"
+	case config.Username != "":
+		return "SASL_PLAINTEXT"
+	case config.TLS:
+		return "SSL"
+	default:
+		return "PLAINTEXT"
+	}
+}
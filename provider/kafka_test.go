@@ -0,0 +1,109 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package provider
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	pc "github.com/featureform/provider/provider_config"
+	pt "github.com/featureform/provider/provider_type"
+)
+
+func decodeKafkaSourcePath(t *testing.T, path string) kafkaSourceSpec {
+	if !strings.HasPrefix(path, kafkaSourcePrefix) {
+		t.Fatalf("Expected kafka source path, got %s", path)
+	}
+	decoded, err := base64.StdEncoding.DecodeString(strings.TrimPrefix(path, kafkaSourcePrefix))
+	if err != nil {
+		t.Fatalf("Failed to decode source: %s", err)
+	}
+	var spec kafkaSourceSpec
+	if err := json.Unmarshal(decoded, &spec); err != nil {
+		t.Fatalf("Failed to parse source: %s", err)
+	}
+	return spec
+}
+
+func TestKafkaSourcePath(t *testing.T) {
+	config := pc.KafkaConfig{
+		Brokers:        []string{"broker-1:9092", "broker-2:9092"},
+		Username:       "user",
+		Password:       "password",
+		TLS:            true,
+		SchemaRegistry: pc.KafkaSchemaRegistryConfig{URL: "https://registry:8081"},
+	}
+	path, err := KafkaSourcePath(config, KafkaTopicSource{Topic: "clicks", ValueFormat: KafkaAvroValue})
+	if err != nil {
+		t.Fatalf("Failed to create source path: %s", err)
+	}
+	expected := kafkaSourceSpec{
+		BootstrapServers:  "broker-1:9092,broker-2:9092",
+		Topic:             "clicks",
+		ValueFormat:       "avro",
+		StartingOffsets:   "earliest",
+		SecurityProtocol:  "SASL_SSL",
+		SASLMechanism:     "PLAIN",
+		Username:          "user",
+		Password:          "password",
+		SchemaRegistryURL: "https://registry:8081",
+	}
+	if spec := decodeKafkaSourcePath(t, path); spec != expected {
+		t.Fatalf("Expected %+v, got %+v", expected, spec)
+	}
+
+	config.SchemaRegistry = pc.KafkaSchemaRegistryConfig{}
+	if _, err := KafkaSourcePath(config, KafkaTopicSource{Topic: "clicks", ValueFormat: KafkaAvroValue}); err == nil {
+		t.Fatalf("Expected avro values without a schema registry to fail")
+	}
+	if _, err := KafkaSourcePath(config, KafkaTopicSource{Topic: "clicks", ValueFormat: "protobuf"}); err == nil {
+		t.Fatalf("Expected unsupported value format to fail")
+	}
+}
+
+func TestKafkaSecurityProtocol(t *testing.T) {
+	tests := []struct {
+		config   pc.KafkaConfig
+		expected string
+	}{
+		{pc.KafkaConfig{}, "PLAINTEXT"},
+		{pc.KafkaConfig{TLS: true}, "SSL"},
+		{pc.KafkaConfig{Username: "user"}, "SASL_PLAINTEXT"},
+		{pc.KafkaConfig{Username: "user", TLS: true}, "SASL_SSL"},
+	}
+	for _, test := range tests {
+		if protocol := kafkaSecurityProtocol(test.config); protocol != test.expected {
+			t.Errorf("Expected %s for %+v, got %s", test.expected, test.config, protocol)
+		}
+	}
+}
+
+func TestKafkaStartingOffsets(t *testing.T) {
+	offsets, err := KafkaStartingOffsets("clicks", map[int32]int64{0: 12, 1: 40})
+	if err != nil {
+		t.Fatalf("Failed to create starting offsets: %s", err)
+	}
+	if offsets != `{"clicks":{"0":12,"1":40}}` {
+		t.Fatalf("Unexpected starting offsets: %s", offsets)
+	}
+}
+
+func TestKafkaProviderFactory(t *testing.T) {
+	if _, err := Get(pt.Kafka, pc.KafkaConfig{}.Serialized()); err == nil {
+		t.Fatalf("Expected kafka provider without brokers to fail")
+	}
+	p, err := Get(pt.Kafka, pc.KafkaConfig{Brokers: []string{"broker:9092"}}.Serialized())
+	if err != nil {
+		t.Fatalf("Failed to create kafka provider: %s", err)
+	}
+	if _, ok := p.(*KafkaProvider); !ok {
+		t.Fatalf("Expected a KafkaProvider, got %T", p)
+	}
+	if _, err := p.AsOfflineStore(); err == nil {
+		t.Fatalf("Expected kafka provider not to be an offline store")
+	}
+}
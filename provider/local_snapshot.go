@@ -0,0 +1,381 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package provider
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"golang.org/x/exp/mmap"
+)
+
+// Local online store snapshots hold the values and write times of every
+// table. After a header, each table is written as its name and value type
+// followed by its entries, and each entry as its entity, write time and
+// tagged value. Strings are length prefixed, integers are varints and floats
+// are little endian. Appended lists aren't included.
+const localSnapshotMagic = "FFLOCAL1"
+
+const (
+	snapshotEnd byte = iota
+	snapshotRecord
+)
+
+// Value tags. Composite values are stored as they are in blob stores and
+// decoded with the table's value type.
+const (
+	snapshotNil byte = iota
+	snapshotString
+	snapshotInt
+	snapshotInt32
+	snapshotInt64
+	snapshotFloat32
+	snapshotFloat64
+	snapshotBool
+	snapshotTime
+	snapshotVector32
+	snapshotComposite
+)
+
+// LocalSnapshotWriter writes a local online store snapshot. Tables can be
+// written from any FeatureIterator, such as a materialization's, so a
+// snapshot can be built without a running store.
+type LocalSnapshotWriter struct {
+	w       *bufio.Writer
+	scratch [binary.MaxVarintLen64]byte
+}
+
+func NewLocalSnapshotWriter(w io.Writer) (*LocalSnapshotWriter, error) {
+	writer := &LocalSnapshotWriter{w: bufio.NewWriter(w)}
+	if _, err := writer.w.WriteString(localSnapshotMagic); err != nil {
+		return nil, err
+	}
+	return writer, nil
+}
+
+// WriteTable writes a table with the records' entities, values and
+// timestamps. Later records for an entity replace earlier ones when the
+// snapshot is loaded.
+func (sw *LocalSnapshotWriter) WriteTable(feature, variant string, valueType ValueType, records FeatureIterator) error {
+	serializedType, err := serializeValueType(valueType)
+	if err != nil {
+		return err
+	}
+	sw.w.WriteByte(snapshotRecord)
+	sw.writeString(feature)
+	sw.writeString(variant)
+	sw.writeString(serializedType)
+	for records.Next() {
+		rec := records.Value()
+		sw.w.WriteByte(snapshotRecord)
+		sw.writeString(rec.Entity)
+		var nanos int64
+		if !rec.TS.IsZero() {
+			nanos = rec.TS.UnixNano()
+		}
+		sw.writeVarint(nanos)
+		if err := sw.writeValue(valueType, rec.Value); err != nil {
+			return fmt.Errorf("could not write value of %s: %w", rec.Entity, err)
+		}
+	}
+	if err := records.Err(); err != nil {
+		return err
+	}
+	return sw.w.WriteByte(snapshotEnd)
+}
+
+// Close ends the snapshot and flushes it to the underlying writer, which is
+// left open.
+func (sw *LocalSnapshotWriter) Close() error {
+	if err := sw.w.WriteByte(snapshotEnd); err != nil {
+		return err
+	}
+	return sw.w.Flush()
+}
+
+func (sw *LocalSnapshotWriter) writeVarint(v int64) {
+	n := binary.PutVarint(sw.scratch[:], v)
+	sw.w.Write(sw.scratch[:n])
+}
+
+func (sw *LocalSnapshotWriter) writeUvarint(v uint64) {
+	n := binary.PutUvarint(sw.scratch[:], v)
+	sw.w.Write(sw.scratch[:n])
+}
+
+func (sw *LocalSnapshotWriter) writeString(s string) {
+	sw.writeUvarint(uint64(len(s)))
+	sw.w.WriteString(s)
+}
+
+func (sw *LocalSnapshotWriter) writeValue(valueType ValueType, value interface{}) error {
+	if composite, isComposite := valueType.(compositeType); isComposite && value != nil {
+		serialized, err := serializeComposite(composite, value)
+		if err != nil {
+			return err
+		}
+		sw.w.WriteByte(snapshotComposite)
+		sw.writeString(string(serialized))
+		return nil
+	}
+	switch v := value.(type) {
+	case nil:
+		sw.w.WriteByte(snapshotNil)
+	case string:
+		sw.w.WriteByte(snapshotString)
+		sw.writeString(v)
+	case int:
+		sw.w.WriteByte(snapshotInt)
+		sw.writeVarint(int64(v))
+	case int32:
+		sw.w.WriteByte(snapshotInt32)
+		sw.writeVarint(int64(v))
+	case int64:
+		sw.w.WriteByte(snapshotInt64)
+		sw.writeVarint(v)
+	case float32:
+		sw.w.WriteByte(snapshotFloat32)
+		binary.Write(sw.w, binary.LittleEndian, v)
+	case float64:
+		sw.w.WriteByte(snapshotFloat64)
+		binary.Write(sw.w, binary.LittleEndian, v)
+	case bool:
+		sw.w.WriteByte(snapshotBool)
+		if v {
+			sw.w.WriteByte(1)
+		} else {
+			sw.w.WriteByte(0)
+		}
+	case time.Time:
+		sw.w.WriteByte(snapshotTime)
+		sw.writeVarint(v.UnixNano())
+	case []float32:
+		sw.w.WriteByte(snapshotVector32)
+		sw.writeUvarint(uint64(len(v)))
+		for _, f := range v {
+			binary.Write(sw.w, binary.LittleEndian, f)
+		}
+	default:
+		return fmt.Errorf("type %T of value %v is unsupported", value, value)
+	}
+	return nil
+}
+
+type snapshotReader struct {
+	r *bufio.Reader
+}
+
+func (sr snapshotReader) readString() (string, error) {
+	n, err := binary.ReadUvarint(sr.r)
+	if err != nil {
+		return "", err
+	}
+	buf := make([]byte, n)
+	if _, err := io.ReadFull(sr.r, buf); err != nil {
+		return "", err
+	}
+	return string(buf), nil
+}
+
+func (sr snapshotReader) readValue(valueType ValueType) (interface{}, error) {
+	tag, err := sr.r.ReadByte()
+	if err != nil {
+		return nil, err
+	}
+	switch tag {
+	case snapshotNil:
+		return nil, nil
+	case snapshotString:
+		return sr.readString()
+	case snapshotInt, snapshotInt32, snapshotInt64:
+		v, err := binary.ReadVarint(sr.r)
+		if tag == snapshotInt {
+			return int(v), err
+		} else if tag == snapshotInt32 {
+			return int32(v), err
+		}
+		return v, err
+	case snapshotFloat32:
+		var v float32
+		return v, binary.Read(sr.r, binary.LittleEndian, &v)
+	case snapshotFloat64:
+		var v float64
+		return v, binary.Read(sr.r, binary.LittleEndian, &v)
+	case snapshotBool:
+		b, err := sr.r.ReadByte()
+		return b == 1, err
+	case snapshotTime:
+		nanos, err := binary.ReadVarint(sr.r)
+		return time.Unix(0, nanos).UTC(), err
+	case snapshotVector32:
+		n, err := binary.ReadUvarint(sr.r)
+		if err != nil {
+			return nil, err
+		}
+		vector := make([]float32, n)
+		return vector, binary.Read(sr.r, binary.LittleEndian, vector)
+	case snapshotComposite:
+		composite, isComposite := valueType.(compositeType)
+		if !isComposite {
+			return nil, fmt.Errorf("composite value in table of type %v", valueType)
+		}
+		serialized, err := sr.readString()
+		if err != nil {
+			return nil, err
+		}
+		return deserializeComposite(composite, []byte(serialized))
+	default:
+		return nil, fmt.Errorf("unknown value tag %d", tag)
+	}
+}
+
+type snapshotTable struct {
+	key   tableKey
+	table *localOnlineTable
+}
+
+func (sr snapshotReader) readTables() ([]snapshotTable, error) {
+	magic := make([]byte, len(localSnapshotMagic))
+	if _, err := io.ReadFull(sr.r, magic); err != nil || string(magic) != localSnapshotMagic {
+		return nil, fmt.Errorf("not a local online store snapshot")
+	}
+	var tables []snapshotTable
+	for {
+		marker, err := sr.r.ReadByte()
+		if err != nil {
+			return nil, err
+		}
+		if marker == snapshotEnd {
+			return tables, nil
+		}
+		table, err := sr.readTable()
+		if err != nil {
+			return nil, err
+		}
+		tables = append(tables, table)
+	}
+}
+
+func (sr snapshotReader) readTable() (snapshotTable, error) {
+	var key tableKey
+	var err error
+	if key.feature, err = sr.readString(); err != nil {
+		return snapshotTable{}, err
+	}
+	if key.variant, err = sr.readString(); err != nil {
+		return snapshotTable{}, err
+	}
+	serializedType, err := sr.readString()
+	if err != nil {
+		return snapshotTable{}, err
+	}
+	valueType, err := deserializeValueType(serializedType)
+	if err != nil {
+		return snapshotTable{}, err
+	}
+	table := newLocalOnlineTable(valueType)
+	for {
+		marker, err := sr.r.ReadByte()
+		if err != nil {
+			return snapshotTable{}, err
+		}
+		if marker == snapshotEnd {
+			return snapshotTable{key, table}, nil
+		}
+		entity, err := sr.readString()
+		if err != nil {
+			return snapshotTable{}, err
+		}
+		nanos, err := binary.ReadVarint(sr.r)
+		if err != nil {
+			return snapshotTable{}, err
+		}
+		value, err := sr.readValue(valueType)
+		if err != nil {
+			return snapshotTable{}, fmt.Errorf("could not read value of %s in %s %s: %w", entity, key.feature, key.variant, err)
+		}
+		table.values[entity] = value
+		if nanos != 0 {
+			table.updated[entity] = time.Unix(0, nanos)
+		}
+	}
+}
+
+// SaveSnapshot writes the values of every table to a snapshot at path. The
+// snapshot is written to a temporary file first, so an existing snapshot at
+// path is only replaced once the new one is complete.
+func (store *localOnlineStore) SaveSnapshot(path string) error {
+	tmp := path + ".tmp"
+	file, err := os.Create(tmp)
+	if err != nil {
+		return err
+	}
+	if err := store.writeSnapshot(file); err != nil {
+		file.Close()
+		os.Remove(tmp)
+		return err
+	}
+	if err := file.Close(); err != nil {
+		os.Remove(tmp)
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+func (store *localOnlineStore) writeSnapshot(w io.Writer) error {
+	writer, err := NewLocalSnapshotWriter(w)
+	if err != nil {
+		return err
+	}
+	tables, err := store.ListTables()
+	if err != nil {
+		return err
+	}
+	for _, info := range tables {
+		table, err := store.GetTable(info.Feature, info.Variant)
+		if err != nil {
+			return err
+		}
+		records, err := table.(*localOnlineTable).Scan()
+		if err != nil {
+			return err
+		}
+		if err := writer.WriteTable(info.Feature, info.Variant, info.ValueType, records); err != nil {
+			return fmt.Errorf("could not write table %s %s: %w", info.Feature, info.Variant, err)
+		}
+	}
+	return writer.Close()
+}
+
+// LoadSnapshot loads the tables in the snapshot at path, replacing any
+// tables of the same name. Tables that aren't in the snapshot are kept. The
+// snapshot is memory-mapped rather than read into memory, and nothing is
+// changed if it can't be loaded.
+func (store *localOnlineStore) LoadSnapshot(path string) error {
+	file, err := mmap.Open(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	reader := snapshotReader{bufio.NewReaderSize(io.NewSectionReader(file, 0, int64(file.Len())), 1<<20)}
+	tables, err := reader.readTables()
+	if errors.Is(err, io.EOF) {
+		err = io.ErrUnexpectedEOF
+	}
+	if err != nil {
+		return fmt.Errorf("could not load snapshot %s: %w", path, err)
+	}
+	store.mu.Lock()
+	defer store.mu.Unlock()
+	for _, loaded := range tables {
+		store.tables[loaded.key] = loaded.table
+	}
+	return nil
+}
@@ -0,0 +1,148 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package provider
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestLocalSnapshotRoundTrip(t *testing.T) {
+	ts := time.Date(2023, 5, 1, 12, 0, 0, 0, time.UTC)
+	tables := []struct {
+		feature   string
+		valueType ValueType
+		value     interface{}
+	}{
+		{"nil", String, nil},
+		{"string", String, "value"},
+		{"int", Int, 1},
+		{"int32", Int32, int32(2)},
+		{"int64", Int64, int64(-3)},
+		{"float32", Float32, float32(1.5)},
+		{"float64", Float64, 2.25},
+		{"bool", Bool, true},
+		{"timestamp", Timestamp, ts},
+		{"vector", VectorType{ScalarType: Float32, Dimension: 3}, []float32{1, 2, 3}},
+		{"list", ListType{ElementType: Int64}, []int64{4, 5}},
+	}
+	store := NewLocalOnlineStore()
+	for _, test := range tables {
+		table, err := store.CreateTable(test.feature, "v", test.valueType)
+		if err != nil {
+			t.Fatalf("Failed to create table: %s", err)
+		}
+		if err := table.Set("entity", test.value); err != nil {
+			t.Fatalf("Failed to set %s: %s", test.feature, err)
+		}
+	}
+	path := filepath.Join(t.TempDir(), "snapshot")
+	if err := store.SaveSnapshot(path); err != nil {
+		t.Fatalf("Failed to save snapshot: %s", err)
+	}
+
+	loaded := NewLocalOnlineStore()
+	if err := loaded.LoadSnapshot(path); err != nil {
+		t.Fatalf("Failed to load snapshot: %s", err)
+	}
+	for _, test := range tables {
+		original, _ := store.GetTable(test.feature, "v")
+		table, err := loaded.GetTable(test.feature, "v")
+		if err != nil {
+			t.Fatalf("Failed to get loaded table %s: %s", test.feature, err)
+		}
+		value, updated, err := table.(TimestampedTable).GetWithTimestamp("entity")
+		if err != nil {
+			t.Fatalf("Failed to get %s: %s", test.feature, err)
+		}
+		if !reflect.DeepEqual(value, test.value) {
+			t.Fatalf("Expected %s to be %#v, got %#v", test.feature, test.value, value)
+		}
+		_, originalUpdated, _ := original.(TimestampedTable).GetWithTimestamp("entity")
+		if !updated.Equal(originalUpdated) {
+			t.Fatalf("Expected %s write time %v, got %v", test.feature, originalUpdated, updated)
+		}
+	}
+}
+
+func TestLocalSnapshotFromMaterialization(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "snapshot")
+	file, err := os.Create(path)
+	if err != nil {
+		t.Fatalf("Failed to create snapshot: %s", err)
+	}
+	writer, err := NewLocalSnapshotWriter(file)
+	if err != nil {
+		t.Fatalf("Failed to create writer: %s", err)
+	}
+	ts := time.Date(2023, 5, 1, 0, 0, 0, 0, time.UTC)
+	records := []ResourceRecord{
+		{Entity: "a", Value: 1, TS: ts},
+		{Entity: "b", Value: 2},
+		{Entity: "a", Value: 3, TS: ts.Add(time.Hour)},
+	}
+	if err := writer.WriteTable("feature", "variant", Int, newMemoryFeatureIterator(records)); err != nil {
+		t.Fatalf("Failed to write table: %s", err)
+	}
+	if err := writer.Close(); err != nil {
+		t.Fatalf("Failed to close writer: %s", err)
+	}
+	file.Close()
+
+	store := NewLocalOnlineStore()
+	kept, err := store.CreateTable("kept", "variant", String)
+	if err != nil {
+		t.Fatalf("Failed to create table: %s", err)
+	}
+	if err := store.LoadSnapshot(path); err != nil {
+		t.Fatalf("Failed to load snapshot: %s", err)
+	}
+	table, err := store.GetTable("feature", "variant")
+	if err != nil {
+		t.Fatalf("Failed to get loaded table: %s", err)
+	}
+	value, updated, err := table.(TimestampedTable).GetWithTimestamp("a")
+	if err != nil || value != 3 || !updated.Equal(ts.Add(time.Hour)) {
+		t.Fatalf("Expected last record for a, got %v at %v: %v", value, updated, err)
+	}
+	if _, updated, err := table.(TimestampedTable).GetWithTimestamp("b"); err != nil || !updated.IsZero() {
+		t.Fatalf("Expected b without a write time, got %v: %v", updated, err)
+	}
+	if current, _ := store.GetTable("kept", "variant"); current != kept {
+		t.Fatalf("Expected table missing from snapshot to be kept")
+	}
+}
+
+func TestLocalSnapshotTruncated(t *testing.T) {
+	store := NewLocalOnlineStore()
+	table, err := store.CreateTable("feature", "variant", String)
+	if err != nil {
+		t.Fatalf("Failed to create table: %s", err)
+	}
+	if err := table.Set("entity", "value"); err != nil {
+		t.Fatalf("Failed to set value: %s", err)
+	}
+	path := filepath.Join(t.TempDir(), "snapshot")
+	if err := store.SaveSnapshot(path); err != nil {
+		t.Fatalf("Failed to save snapshot: %s", err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("Failed to read snapshot: %s", err)
+	}
+	if err := os.WriteFile(path, data[:len(data)-2], 0644); err != nil {
+		t.Fatalf("Failed to truncate snapshot: %s", err)
+	}
+	loaded := NewLocalOnlineStore()
+	if err := loaded.LoadSnapshot(path); err == nil {
+		t.Fatalf("Expected truncated snapshot to fail to load")
+	}
+	if _, err := loaded.GetTable("feature", "variant"); err == nil {
+		t.Fatalf("Expected failed load to leave the store unchanged")
+	}
+}
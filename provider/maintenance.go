@@ -0,0 +1,51 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package provider
+
+import (
+	"fmt"
+)
+
+type MaintenanceOperation string
+
+const (
+	VacuumOperation    MaintenanceOperation = "VACUUM"
+	AnalyzeOperation   MaintenanceOperation = "ANALYZE"
+	ReclusterOperation MaintenanceOperation = "RECLUSTER"
+)
+
+// MaintenanceConfig describes the maintenance to run against a materialization
+// or transformation table. If Operations is empty, the provider's defaults are
+// used. ClusterColumns sets the clustering key for providers that support one.
+type MaintenanceConfig struct {
+	TableID        ResourceID
+	Operations     []MaintenanceOperation
+	ClusterColumns []string
+}
+
+// MaintainableOfflineStore is implemented by offline stores that can run
+// maintenance, such as VACUUM in Postgres, on the tables they create so that
+// training set queries stay fast as tables churn.
+type MaintainableOfflineStore interface {
+	OfflineStore
+	Maintain(config MaintenanceConfig) error
+}
+
+type MaintenanceNotSupported struct {
+	ProviderType string
+}
+
+func (err *MaintenanceNotSupported) Error() string {
+	return fmt.Sprintf("maintenance is not supported by %s", err.ProviderType)
+}
+
+type UnsupportedMaintenanceOperation struct {
+	Operation    MaintenanceOperation
+	ProviderType string
+}
+
+func (err *UnsupportedMaintenanceOperation) Error() string {
+	return fmt.Sprintf("maintenance operation %s is not supported by %s", err.Operation, err.ProviderType)
+}
@@ -0,0 +1,42 @@
+package provider
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestPostgresMaintenanceQueries(t *testing.T) {
+	q := postgresSQLQueries{}
+	statements, err := q.maintenance("featureform_materialization_f", MaintenanceConfig{})
+	if err != nil {
+		t.Fatalf("failed to build maintenance queries: %v", err)
+	}
+	expected := []string{
+		`VACUUM "featureform_materialization_f"`,
+		`ANALYZE "featureform_materialization_f"`,
+	}
+	if !reflect.DeepEqual(statements, expected) {
+		t.Fatalf("expected %v, got %v", expected, statements)
+	}
+	if _, err := q.maintenance("t", MaintenanceConfig{Operations: []MaintenanceOperation{ReclusterOperation}}); err == nil {
+		t.Fatalf("expected error for unsupported operation")
+	}
+}
+
+func TestSnowflakeMaintenanceQueries(t *testing.T) {
+	q := snowflakeSQLQueries{}
+	statements, err := q.maintenance("featureform_transformation__t__v", MaintenanceConfig{ClusterColumns: []string{"entity", "ts"}})
+	if err != nil {
+		t.Fatalf("failed to build maintenance queries: %v", err)
+	}
+	expected := []string{
+		`ALTER TABLE "featureform_transformation__t__v" CLUSTER BY ("entity", "ts")`,
+		`ALTER TABLE "featureform_transformation__t__v" RESUME RECLUSTER`,
+	}
+	if !reflect.DeepEqual(statements, expected) {
+		t.Fatalf("expected %v, got %v", expected, statements)
+	}
+	if _, err := q.maintenance("t", MaintenanceConfig{Operations: []MaintenanceOperation{VacuumOperation}}); err == nil {
+		t.Fatalf("expected error for unsupported operation")
+	}
+}
@@ -0,0 +1,88 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package provider
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+)
+
+// AsOfOfflineStore is implemented by offline stores that can materialize a
+// feature as it was at a point in time, from the latest row of each entity at
+// or before it. Entities with no rows by then aren't in the materialization.
+type AsOfOfflineStore interface {
+	OfflineStore
+	CreateAsOfMaterialization(id ResourceID, asOf time.Time) (Materialization, error)
+}
+
+type AsOfMaterializationNotSupported struct {
+	ProviderType string
+}
+
+func (err *AsOfMaterializationNotSupported) Error() string {
+	return fmt.Sprintf("as-of materialization is not supported by %s", err.ProviderType)
+}
+
+func (store *memoryOfflineStore) CreateAsOfMaterialization(id ResourceID, asOf time.Time) (Materialization, error) {
+	if id.Type != Feature {
+		return nil, errors.New("only features can be materialized")
+	}
+	table, err := store.getMemoryResourceTable(id)
+	if err != nil {
+		return nil, err
+	}
+	matData := make(materializedRecords, 0, len(table.entityMap))
+	for _, records := range table.entityMap {
+		asOfRecords := make([]ResourceRecord, 0, len(records))
+		for _, rec := range records {
+			if !rec.TS.After(asOf) {
+				asOfRecords = append(asOfRecords, rec)
+			}
+		}
+		if len(asOfRecords) > 0 {
+			matData = append(matData, latestRecord(asOfRecords))
+		}
+	}
+	sort.Sort(matData)
+	matId := MaterializationID(fmt.Sprintf("%s__asof_%d", id.Name, asOf.Unix()))
+	mat := &memoryMaterialization{
+		id:   matId,
+		data: matData,
+	}
+	store.materializations[matId] = mat
+	return mat, nil
+}
+
+// CreateAsOfMaterialization materializes the feature from a view of its
+// resource table's rows at or before asOf. Materializations of the same
+// feature and time are reused.
+func (store *sqlOfflineStore) CreateAsOfMaterialization(id ResourceID, asOf time.Time) (Materialization, error) {
+	if id.Type != Feature {
+		return nil, errors.New("only features can be materialized")
+	}
+	resTable, err := store.getsqlResourceTable(id)
+	if err != nil {
+		return nil, err
+	}
+	viewName := fmt.Sprintf("featureform_asof__%s__%s__%d", id.Name, id.Variant, asOf.Unix())
+	query := fmt.Sprintf("CREATE OR REPLACE VIEW %s AS SELECT entity, value, ts FROM %s WHERE ts <= '%s'",
+		sanitize(viewName), sanitize(resTable.name), asOf.UTC().Format("2006-01-02 15:04:05"))
+	if _, err := store.db.Exec(query); err != nil {
+		return nil, fmt.Errorf("could not create as-of view for %s: %w", id.Name, err)
+	}
+	matID := MaterializationID(fmt.Sprintf("%s__asof_%d", id.Name, asOf.Unix()))
+	matTableName := store.getMaterializationTableName(matID)
+	if _, err := store.db.Exec(store.query.materializationCreate(matTableName, viewName)); err != nil {
+		return nil, fmt.Errorf("could not materialize %s as of %s: %w", id.Name, asOf, err)
+	}
+	return &sqlMaterialization{
+		id:        matID,
+		db:        store.db,
+		tableName: matTableName,
+		query:     store.query,
+	}, nil
+}
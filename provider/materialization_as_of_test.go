@@ -0,0 +1,63 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package provider
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestMemoryAsOfMaterialization(t *testing.T) {
+	store := NewMemoryOfflineStore()
+	id := ResourceID{Name: "amount", Variant: "default", Type: Feature}
+	table, err := store.CreateResourceTable(id, TableSchema{})
+	if err != nil {
+		t.Fatalf("Failed to create table: %s", err)
+	}
+	records := []ResourceRecord{
+		{Entity: "a", Value: 1, TS: time.UnixMilli(1000)},
+		{Entity: "a", Value: 2, TS: time.UnixMilli(3000)},
+		{Entity: "b", Value: 3, TS: time.UnixMilli(2000)},
+		{Entity: "c", Value: 4, TS: time.UnixMilli(4000)},
+	}
+	for _, rec := range records {
+		if err := table.Write(rec); err != nil {
+			t.Fatalf("Failed to write record: %s", err)
+		}
+	}
+	tests := []struct {
+		asOf     time.Time
+		expected map[string]interface{}
+	}{
+		{time.UnixMilli(500), map[string]interface{}{}},
+		{time.UnixMilli(2000), map[string]interface{}{"a": 1, "b": 3}},
+		{time.UnixMilli(5000), map[string]interface{}{"a": 2, "b": 3, "c": 4}},
+	}
+	for _, tt := range tests {
+		mat, err := store.CreateAsOfMaterialization(id, tt.asOf)
+		if err != nil {
+			t.Fatalf("Failed to materialize as of %s: %s", tt.asOf, err)
+		}
+		numRows, err := mat.NumRows()
+		if err != nil {
+			t.Fatalf("Failed to count rows: %s", err)
+		}
+		it, err := mat.IterateSegment(0, numRows)
+		if err != nil {
+			t.Fatalf("Failed to iterate: %s", err)
+		}
+		values := make(map[string]interface{})
+		for it.Next() {
+			values[it.Value().Entity] = it.Value().Value
+		}
+		if !reflect.DeepEqual(values, tt.expected) {
+			t.Fatalf("Expected %v as of %s, got %v", tt.expected, tt.asOf, values)
+		}
+		if _, err := store.GetMaterialization(mat.ID()); err != nil {
+			t.Fatalf("Failed to get as-of materialization: %s", err)
+		}
+	}
+}
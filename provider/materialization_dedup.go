@@ -0,0 +1,141 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package provider
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+type DedupStrategyType string
+
+const (
+	// DedupLatest keeps each entity's row with the latest timestamp, which
+	// is how every materialization is built by default.
+	DedupLatest DedupStrategyType = "latest"
+	DedupMax    DedupStrategyType = "max"
+	DedupMin    DedupStrategyType = "min"
+	// DedupExpression aggregates each entity's values with a SQL expression.
+	DedupExpression DedupStrategyType = "expression"
+)
+
+// DedupStrategy is how a materialization resolves a feature's rows to one
+// per entity. Strategies other than DedupLatest aggregate all of an entity's
+// rows, and give it the latest of their timestamps.
+type DedupStrategy struct {
+	Type DedupStrategyType
+	// Expression is a SQL aggregate of the value column, like AVG(value),
+	// which DedupExpression uses.
+	Expression string
+}
+
+func (s DedupStrategy) IsLatest() bool {
+	return s.Type == "" || s.Type == DedupLatest
+}
+
+func (s DedupStrategy) Validate() error {
+	switch s.Type {
+	case "", DedupLatest, DedupMax, DedupMin:
+		if s.Expression != "" {
+			return fmt.Errorf("dedup strategy %s doesn't take an expression", s.Type)
+		}
+	case DedupExpression:
+		if strings.TrimSpace(s.Expression) == "" {
+			return errors.New("dedup expression must be set")
+		}
+		if strings.Contains(s.Expression, ";") {
+			return fmt.Errorf("dedup expression %q must be a single expression", s.Expression)
+		}
+	default:
+		return fmt.Errorf("unknown dedup strategy %q", s.Type)
+	}
+	return nil
+}
+
+// valueAggregate is the SQL that aggregates an entity's values.
+func (s DedupStrategy) valueAggregate() string {
+	switch s.Type {
+	case DedupMax:
+		return "MAX(value)"
+	case DedupMin:
+		return "MIN(value)"
+	default:
+		return s.Expression
+	}
+}
+
+// DedupOfflineStore is implemented by offline stores that can materialize a
+// feature with a dedup strategy other than the latest row of each entity.
+type DedupOfflineStore interface {
+	OfflineStore
+	CreateDedupMaterialization(id ResourceID, strategy DedupStrategy) (Materialization, error)
+	UpdateDedupMaterialization(id ResourceID, strategy DedupStrategy) (Materialization, error)
+}
+
+type DedupStrategyNotSupported struct {
+	ProviderType string
+	Strategy     DedupStrategyType
+}
+
+func (err *DedupStrategyNotSupported) Error() string {
+	return fmt.Sprintf("dedup strategy %s is not supported by %s", err.Strategy, err.ProviderType)
+}
+
+// dedupRecords resolves an entity's records with the latest, max or min
+// strategy. Values are compared as numbers if they both are, and as strings
+// otherwise.
+func dedupRecords(records []ResourceRecord, strategy DedupStrategy) ResourceRecord {
+	if strategy.IsLatest() {
+		return latestRecord(records)
+	}
+	resolved := records[0]
+	var latest time.Time
+	for _, rec := range records {
+		if rec.TS.After(latest) {
+			latest = rec.TS
+		}
+		cmp := compareDedupValues(rec.Value, resolved.Value)
+		if (strategy.Type == DedupMax && cmp > 0) || (strategy.Type == DedupMin && cmp < 0) {
+			resolved = rec
+		}
+	}
+	resolved.TS = latest
+	return resolved
+}
+
+func compareDedupValues(a, b interface{}) int {
+	aNum, aIsNum := dedupNumber(a)
+	bNum, bIsNum := dedupNumber(b)
+	if !aIsNum || !bIsNum {
+		return strings.Compare(fmt.Sprint(a), fmt.Sprint(b))
+	}
+	switch {
+	case aNum < bNum:
+		return -1
+	case aNum > bNum:
+		return 1
+	default:
+		return 0
+	}
+}
+
+func dedupNumber(value interface{}) (float64, bool) {
+	switch v := value.(type) {
+	case int:
+		return float64(v), true
+	case int32:
+		return float64(v), true
+	case int64:
+		return float64(v), true
+	case float32:
+		return float64(v), true
+	case float64:
+		return v, true
+	default:
+		return 0, false
+	}
+}
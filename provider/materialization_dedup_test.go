@@ -0,0 +1,75 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package provider
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestDedupStrategyValidate(t *testing.T) {
+	tests := []struct {
+		name     string
+		strategy DedupStrategy
+		wantErr  bool
+	}{
+		{"Default", DedupStrategy{}, false},
+		{"Max", DedupStrategy{Type: DedupMax}, false},
+		{"Expression", DedupStrategy{Type: DedupExpression, Expression: "AVG(value)"}, false},
+		{"Missing Expression", DedupStrategy{Type: DedupExpression}, true},
+		{"Multiple Statements", DedupStrategy{Type: DedupExpression, Expression: "1; DROP TABLE x"}, true},
+		{"Unexpected Expression", DedupStrategy{Type: DedupMin, Expression: "AVG(value)"}, true},
+		{"Unknown", DedupStrategy{Type: "median"}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.strategy.Validate(); (err != nil) != tt.wantErr {
+				t.Fatalf("Expected error %v, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestMemoryDedupMaterialization(t *testing.T) {
+	store := NewMemoryOfflineStore()
+	id := ResourceID{Name: "amount", Variant: "default", Type: Feature}
+	table, err := store.CreateResourceTable(id, TableSchema{})
+	if err != nil {
+		t.Fatalf("Failed to create table: %s", err)
+	}
+	records := []ResourceRecord{
+		{Entity: "a", Value: 10, TS: time.UnixMilli(1000)},
+		{Entity: "a", Value: 30.5, TS: time.UnixMilli(2000)},
+		{Entity: "a", Value: 20, TS: time.UnixMilli(3000)},
+	}
+	for _, rec := range records {
+		if err := table.Write(rec); err != nil {
+			t.Fatalf("Failed to write record: %s", err)
+		}
+	}
+	tests := map[DedupStrategyType]interface{}{DedupLatest: 20, DedupMax: 30.5, DedupMin: 10}
+	for strategy, expected := range tests {
+		mat, err := store.CreateDedupMaterialization(id, DedupStrategy{Type: strategy})
+		if err != nil {
+			t.Fatalf("Failed to create %s materialization: %s", strategy, err)
+		}
+		it, err := mat.IterateSegment(0, 1)
+		if err != nil {
+			t.Fatalf("Failed to iterate materialization: %s", err)
+		}
+		if !it.Next() {
+			t.Fatalf("Expected a row for %s", strategy)
+		}
+		if rec := it.Value(); rec.Value != expected || !rec.TS.Equal(time.UnixMilli(3000)) {
+			t.Fatalf("Expected %s value %v at the latest timestamp, got %v", strategy, expected, rec)
+		}
+	}
+	_, err = store.CreateDedupMaterialization(id, DedupStrategy{Type: DedupExpression, Expression: "SUM(value)"})
+	notSupported := &DedupStrategyNotSupported{}
+	if !errors.As(err, &notSupported) {
+		t.Fatalf("Expected expressions not to be supported, got %v", err)
+	}
+}
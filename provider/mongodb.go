@@ -108,7 +108,10 @@ func (store *mongoDBOnlineStore) GetMetadataTableName() string {
 
 func (store *mongoDBOnlineStore) CreateTable(feature, variant string, valueType ValueType) (OnlineStoreTable, error) {
 	tableName := store.GetTableName(feature, variant)
-	vType := string(valueType.Scalar())
+	vType, err := serializeValueType(valueType)
+	if err != nil {
+		return nil, err
+	}
 	getTable, _ := store.GetTable(feature, variant)
 	if getTable != nil {
 		return nil, &TableAlreadyExists{feature, variant}
@@ -116,7 +119,7 @@ func (store *mongoDBOnlineStore) CreateTable(feature, variant string, valueType
 
 	metadataTableName := store.GetMetadataTableName()
 	wConcern := writeconcern.New(writeconcern.J(true), writeconcern.WMajority())
-	_, err := store.client.Database(store.database, &options.DatabaseOptions{
+	_, err = store.client.Database(store.database, &options.DatabaseOptions{
 		WriteConcern: wConcern,
 	}).Collection(metadataTableName).InsertOne(context.TODO(), mongoDBMetadataRow{tableName, vType})
 	if err != nil {
@@ -161,6 +164,7 @@ func (store *mongoDBOnlineStore) GetTable(feature, variant string) (OnlineStoreT
 	if err != nil {
 		return nil, fmt.Errorf("could not get metadata table value: %s, %w", tableName, err)
 	}
+	valueType, err := deserializeValueType(row.T)
 	if err != nil {
 		return nil, fmt.Errorf("could not get metadata table value type: %s, %w", tableName, err)
 	}
@@ -168,7 +172,7 @@ func (store *mongoDBOnlineStore) GetTable(feature, variant string) (OnlineStoreT
 		client:    store.client,
 		database:  store.database,
 		name:      tableName,
-		valueType: ScalarType(row.T),
+		valueType: valueType,
 	}
 	return table, nil
 }
@@ -186,6 +190,27 @@ func (store *mongoDBOnlineStore) DeleteTable(feature, variant string) error {
 	return nil
 }
 
+func (store *mongoDBOnlineStore) Capabilities() OnlineCapabilities {
+	return OnlineCapabilities{
+		BatchWrites: true,
+		Truncate:    true,
+	}
+}
+
+// Truncate deletes every document rather than dropping the collection, so
+// that its throughput settings are kept.
+func (store *mongoDBOnlineStore) Truncate(feature, variant string) error {
+	if _, err := store.GetTable(feature, variant); err != nil {
+		return err
+	}
+	tableName := store.GetTableName(feature, variant)
+	_, err := store.client.Database(store.database).Collection(tableName).DeleteMany(context.TODO(), bson.D{})
+	if err != nil {
+		return fmt.Errorf("could not truncate collection: %s: %w", tableName, err)
+	}
+	return nil
+}
+
 func (table mongoDBOnlineTable) Set(entity string, value interface{}) error {
 	upsert := true
 	_, err := table.client.Database(table.database).
@@ -204,6 +229,27 @@ func (table mongoDBOnlineTable) Set(entity string, value interface{}) error {
 	return nil
 }
 
+// SetBatch upserts the entities' values with a single bulk write.
+func (table mongoDBOnlineTable) SetBatch(values map[string]interface{}) error {
+	if len(values) == 0 {
+		return nil
+	}
+	models := make([]mongo.WriteModel, 0, len(values))
+	for entity, value := range values {
+		models = append(models, mongo.NewUpdateOneModel().
+			SetFilter(bson.D{{"entity", entity}}).
+			SetUpdate(bson.D{{"$set", bson.D{{"entity", entity}, {"value", value}}}}).
+			SetUpsert(true))
+	}
+	_, err := table.client.Database(table.database).
+		Collection(table.name).
+		BulkWrite(context.TODO(), models)
+	if err != nil {
+		return fmt.Errorf("could not set values: %s: %w", table.name, err)
+	}
+	return nil
+}
+
 func (table mongoDBOnlineTable) Get(entity string) (interface{}, error) {
 
 	type tableRow struct {
@@ -221,6 +267,13 @@ func (table mongoDBOnlineTable) Get(entity string) (interface{}, error) {
 		return nil, fmt.Errorf("could not get table value: %s: %s: %w", table.name, entity, err)
 	}
 
+	if composite, isComposite := table.valueType.(compositeType); isComposite {
+		// Embedded documents are decoded as ordered key value pairs.
+		if doc, isDoc := row.Value.(primitive.D); isDoc {
+			return castComposite(composite, doc.Map())
+		}
+		return castComposite(composite, row.Value)
+	}
 	switch table.valueType {
 	case Int:
 		return int(row.Value.(int32)), nil
@@ -234,6 +287,8 @@ func (table mongoDBOnlineTable) Get(entity string) (interface{}, error) {
 		return row.Value.(bool), nil
 	case String, NilType:
 		return row.Value.(string), nil
+	case Timestamp, Datetime:
+		return row.Value.(primitive.DateTime).Time().UTC(), nil
 	default:
 		return nil, fmt.Errorf("given data type not recognized: %v", table.valueType)
 	}
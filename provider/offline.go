@@ -122,6 +122,12 @@ const (
 	NoTransformationType TransformationType = iota
 	SQLTransformation
 	DFTransformation
+	// DBTTransformation runs a dbt model, whose table is then used as the
+	// transformation.
+	DBTTransformation
+	// StreamingTransformation runs a SQL query as a long-lived streaming
+	// job, which is started and stopped rather than run to completion.
+	StreamingTransformation
 )
 
 type SourceMapping struct {
@@ -137,6 +143,8 @@ type TransformationConfig struct {
 	SourceMapping []SourceMapping
 	Args          metadata.TransformationArgs
 	ArgType       metadata.TransformationArgType
+	DBT           DBTModel
+	Streaming     StreamingConfig
 }
 
 func (m *TransformationConfig) MarshalJSON() ([]byte, error) {
@@ -167,6 +175,8 @@ func (m *TransformationConfig) UnmarshalJSON(data []byte) error {
 		SourceMapping []SourceMapping
 		Args          map[string]interface{}
 		ArgType       metadata.TransformationArgType
+		DBT           DBTModel
+		Streaming     StreamingConfig
 	}
 
 	var temp tempConfig
@@ -180,6 +190,8 @@ func (m *TransformationConfig) UnmarshalJSON(data []byte) error {
 	m.Query = temp.Query
 	m.Code = temp.Code
 	m.SourceMapping = temp.SourceMapping
+	m.DBT = temp.DBT
+	m.Streaming = temp.Streaming
 
 	err = m.decodeArgs(temp.ArgType, temp.Args)
 	if err != nil {
@@ -253,6 +265,33 @@ type Materialization interface {
 	IterateSegment(begin, end int64) (FeatureIterator, error)
 }
 
+// PartitionedMaterialization is implemented by materializations whose rows
+// are split into partitions, like the data files of a table, that can each be
+// read without reading the others. They're copied to the online store one
+// partition per chunk, instead of by ranges of rows that each have to skip
+// the rows before them.
+type PartitionedMaterialization interface {
+	Materialization
+	NumPartitions() (int, error)
+	IteratePartition(partition int) (FeatureIterator, error)
+}
+
+// ApproximateRowCounter is implemented by materializations that can estimate
+// their number of rows from table statistics, far faster than NumRows counts
+// them. Estimates can be off in either direction, so they're only used to
+// plan chunks, and segments that end past the last row have to be readable.
+type ApproximateRowCounter interface {
+	Materialization
+	ApproxNumRows() (int64, error)
+}
+
+// FeatureMaterializationGetter is implemented by offline stores that can
+// find the materialization of a feature by the feature's id, so that it can
+// be read without the MaterializationID returned when it was created.
+type FeatureMaterializationGetter interface {
+	GetFeatureMaterialization(id ResourceID) (Materialization, error)
+}
+
 type FeatureIterator interface {
 	Next() bool
 	Value() ResourceRecord
@@ -313,6 +352,22 @@ type ResourceSchema struct {
 	Value       string
 	TS          string
 	SourceTable string
+	// TSTimezone is the IANA time zone of the TS column's values if they
+	// don't store one. They're converted to UTC when the resource is
+	// registered. If it's empty, they're assumed to already be in UTC.
+	TSTimezone string
+}
+
+// tsLocation returns the location of the schema's naive timestamps.
+func (schema *ResourceSchema) tsLocation() (*time.Location, error) {
+	if schema.TSTimezone == "" {
+		return time.UTC, nil
+	}
+	location, err := time.LoadLocation(schema.TSTimezone)
+	if err != nil {
+		return nil, fmt.Errorf("invalid timestamp time zone %q: %w", schema.TSTimezone, err)
+	}
+	return location, nil
 }
 
 func (schema *ResourceSchema) Serialize() ([]byte, error) {
@@ -343,7 +398,11 @@ type TableColumn struct {
 type memoryOfflineStore struct {
 	tables           map[ResourceID]*memoryOfflineTable
 	materializations map[MaterializationID]*memoryMaterialization
-	trainingSets     map[ResourceID]trainingRows
+	// featureMaterializations are the latest materialization of each
+	// feature.
+	featureMaterializations map[ResourceID]MaterializationID
+	trainingSets            map[ResourceID]trainingRows
+	memoryChunkCheckpoints
 	BaseProvider
 }
 
@@ -353,9 +412,10 @@ func memoryOfflineStoreFactory(serializedConfig pc.SerializedConfig) (Provider,
 
 func NewMemoryOfflineStore() *memoryOfflineStore {
 	return &memoryOfflineStore{
-		tables:           make(map[ResourceID]*memoryOfflineTable),
-		materializations: make(map[MaterializationID]*memoryMaterialization),
-		trainingSets:     make(map[ResourceID]trainingRows),
+		tables:                  make(map[ResourceID]*memoryOfflineTable),
+		materializations:        make(map[MaterializationID]*memoryMaterialization),
+		featureMaterializations: make(map[ResourceID]MaterializationID),
+		trainingSets:            make(map[ResourceID]trainingRows),
 		BaseProvider: BaseProvider{
 			ProviderType:   pt.MemoryOffline,
 			ProviderConfig: []byte{},
@@ -435,16 +495,26 @@ func (recs materializedRecords) Swap(i, j int) {
 }
 
 func (store *memoryOfflineStore) CreateMaterialization(id ResourceID) (Materialization, error) {
+	return store.CreateDedupMaterialization(id, DedupStrategy{})
+}
+
+func (store *memoryOfflineStore) CreateDedupMaterialization(id ResourceID, strategy DedupStrategy) (Materialization, error) {
 	if id.Type != Feature {
 		return nil, errors.New("only features can be materialized")
 	}
+	if err := strategy.Validate(); err != nil {
+		return nil, err
+	}
+	if strategy.Type == DedupExpression {
+		return nil, &DedupStrategyNotSupported{string(store.Type()), strategy.Type}
+	}
 	table, err := store.getMemoryResourceTable(id)
 	if err != nil {
 		return nil, err
 	}
 	matData := make(materializedRecords, 0, len(table.entityMap))
 	for _, records := range table.entityMap {
-		matRec := latestRecord(records)
+		matRec := dedupRecords(records, strategy)
 		matData = append(matData, matRec)
 	}
 	sort.Sort(matData)
@@ -454,6 +524,7 @@ func (store *memoryOfflineStore) CreateMaterialization(id ResourceID) (Materiali
 		data: matData,
 	}
 	store.materializations[matId] = mat
+	store.featureMaterializations[id] = matId
 	return mat, nil
 }
 
@@ -473,10 +544,48 @@ func (store *memoryOfflineStore) GetMaterialization(id MaterializationID) (Mater
 	return mat, nil
 }
 
+func (store *memoryOfflineStore) GetFeatureMaterialization(id ResourceID) (Materialization, error) {
+	matId, has := store.featureMaterializations[ResourceID{Name: id.Name, Variant: id.Variant, Type: Feature}]
+	if !has {
+		return nil, &MaterializationNotFound{MaterializationID(fmt.Sprintf("%s/%s", id.Name, id.Variant))}
+	}
+	return store.GetMaterialization(matId)
+}
+
+func (store *memoryOfflineStore) LookupMaterializedEntity(id ResourceID, entity string) (ResourceRecord, error) {
+	table, err := store.getMemoryResourceTable(id)
+	if err != nil {
+		return ResourceRecord{}, err
+	}
+	recs, has := table.entityMap[entity]
+	if !has {
+		return ResourceRecord{}, &EntityNotFound{entity}
+	}
+	return latestRecord(recs), nil
+}
+
+func (store *memoryOfflineStore) LookupMaterializedEntities(id ResourceID, entities []string) (FeatureIterator, error) {
+	table, err := store.getMemoryResourceTable(id)
+	if err != nil {
+		return nil, err
+	}
+	recs := make([]ResourceRecord, 0, len(entities))
+	for _, entity := range uniqueEntities(entities) {
+		if entityRecs, has := table.entityMap[entity]; has {
+			recs = append(recs, latestRecord(entityRecs))
+		}
+	}
+	return newMemoryFeatureIterator(recs), nil
+}
+
 func (store *memoryOfflineStore) UpdateMaterialization(id ResourceID) (Materialization, error) {
 	return store.CreateMaterialization(id)
 }
 
+func (store *memoryOfflineStore) UpdateDedupMaterialization(id ResourceID, strategy DedupStrategy) (Materialization, error) {
+	return store.CreateDedupMaterialization(id, strategy)
+}
+
 func (store *memoryOfflineStore) DeleteMaterialization(id MaterializationID) error {
 	if _, has := store.materializations[id]; !has {
 		return &MaterializationNotFound{id}
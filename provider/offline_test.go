@@ -71,6 +71,39 @@ func TestOfflineStores(t *testing.T) {
 		return postgresConfig.Serialize()
 	}
 
+	clickHouseInit := func() pc.SerializedConfig {
+		var clickHouseConfig = pc.ClickHouseConfig{
+			Host:     "localhost",
+			Port:     "9000",
+			Database: checkEnv("CLICKHOUSE_DB"),
+			Username: checkEnv("CLICKHOUSE_USER"),
+			Password: checkEnv("CLICKHOUSE_PASSWORD"),
+		}
+		return clickHouseConfig.Serialize()
+	}
+
+	athenaInit := func() pc.SerializedConfig {
+		var athenaConfig = pc.AthenaConfig{
+			Region:         checkEnv("ATHENA_REGION"),
+			AccessKey:      checkEnv("ATHENA_ACCESS_KEY"),
+			SecretKey:      checkEnv("ATHENA_SECRET_KEY"),
+			OutputLocation: checkEnv("ATHENA_OUTPUT_LOCATION"),
+			Database:       checkEnv("ATHENA_DATABASE"),
+		}
+		return athenaConfig.Serialize()
+	}
+
+	databricksSQLInit := func() pc.SerializedConfig {
+		var databricksSQLConfig = pc.DatabricksSQLConfig{
+			Host:        checkEnv("DATABRICKS_SQL_HOST"),
+			Token:       checkEnv("DATABRICKS_SQL_TOKEN"),
+			WarehouseID: checkEnv("DATABRICKS_SQL_WAREHOUSE_ID"),
+			Catalog:     checkEnv("DATABRICKS_SQL_CATALOG"),
+			Schema:      checkEnv("DATABRICKS_SQL_SCHEMA"),
+		}
+		return databricksSQLConfig.Serialize()
+	}
+
 	snowflakeInit := func() (pc.SerializedConfig, pc.SnowflakeConfig) {
 		snowFlakeDatabase := strings.ToUpper(uuid.NewString())
 		t.Log("Snowflake Database: ", snowFlakeDatabase)
@@ -156,6 +189,18 @@ func TestOfflineStores(t *testing.T) {
 	if *provider == "postgres" || *provider == "" {
 		testList = append(testList, testMember{pt.PostgresOffline, postgresInit(), true})
 	}
+	if *provider == "clickhouse" || *provider == "" {
+		testList = append(testList, testMember{pt.ClickHouseOffline, clickHouseInit(), true})
+	}
+	if *provider == "duckdb" || *provider == "" {
+		testList = append(testList, testMember{pt.DuckDBOffline, (&pc.DuckDBConfig{}).Serialize(), false})
+	}
+	if *provider == "athena" || *provider == "" {
+		testList = append(testList, testMember{pt.AthenaOffline, athenaInit(), true})
+	}
+	if *provider == "databricks_sql" || *provider == "" {
+		testList = append(testList, testMember{pt.DatabricksSQLOffline, databricksSQLInit(), true})
+	}
 	if *provider == "snowflake" || *provider == "" {
 		serialSFConfig, snowflakeConfig := snowflakeInit()
 		testList = append(testList, testMember{pt.SnowflakeOffline, serialSFConfig, true})
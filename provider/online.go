@@ -5,7 +5,13 @@
 package provider
 
 import (
+	"encoding/base64"
 	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
 
 	pc "github.com/featureform/provider/provider_config"
 	pt "github.com/featureform/provider/provider_type"
@@ -18,21 +24,241 @@ var cassandraTypeMap = map[string]string{
 	"float32": "float",
 	"float64": "double",
 	"bool":    "boolean",
+	// Datetime is kept for compatibility with previously created tables.
+	"time.Time": "timestamp",
+	"datetime":  "timestamp",
 }
 
 type OnlineStore interface {
 	GetTable(feature, variant string) (OnlineStoreTable, error)
 	CreateTable(feature, variant string, valueType ValueType) (OnlineStoreTable, error)
 	DeleteTable(feature, variant string) error
+	// Capabilities reports the optional features the store supports.
+	Capabilities() OnlineCapabilities
 	Close() error
 	Provider
 }
 
+// OnlineCapabilities describes the optional features of an online store, so
+// that callers can choose a code path up front instead of type asserting a
+// table and falling back when it doesn't implement an interface. Wrapping
+// stores, like ShardedStore, implement many of the interfaces themselves and
+// only report a capability if the stores they wrap support it, since they
+// would otherwise fail or fall back at runtime.
+//
+// Table capabilities apply to tables created with CreateTable. Tables created
+// with VectorStore.CreateIndex may only support Set, Get and Nearest.
+type OnlineCapabilities struct {
+	// BatchWrites is set if tables implement BatchSettableTable with fewer
+	// round trips than calling Set for each entity.
+	BatchWrites bool
+	// Scan is set if tables implement ScannableTable.
+	Scan bool
+	// Stats is set if tables implement StatsTable.
+	Stats bool
+	// Increment is set if tables implement IncrementableTable.
+	Increment bool
+	// Append is set if tables implement AppendableTable.
+	Append bool
+	// Timestamps is set if tables implement TimestampedTable and record
+	// write times, rather than returning zero times.
+	Timestamps bool
+	// Truncate is set if the store implements TruncatableStore.
+	Truncate bool
+	// ListTables is set if the store implements TableListingStore.
+	ListTables bool
+	// BulkLoad is set if the store implements BulkLoadableStore and is
+	// configured to bulk load.
+	BulkLoad bool
+	// Vectors is set if the store implements VectorStore.
+	Vectors bool
+	// VectorFilters is set if vector tables implement AttributedVectorTable
+	// and push VectorFilters down into their nearest neighbor searches.
+	VectorFilters bool
+	// SparseVectors is set if the store implements SparseVectorStore.
+	SparseVectors bool
+	// VectorNamespaces is set if vector tables implement
+	// NamespacedVectorTable.
+	VectorNamespaces bool
+	// IndexStats is set if vector tables implement IndexStatsTable.
+	IndexStats bool
+	// AsyncIndexBuilds is set if the store implements IndexBuildingStore.
+	AsyncIndexBuilds bool
+	// NamedVectors is set if the store can create indexes of VectorTypes
+	// with Names, whose tables implement NamedVectorTable.
+	NamedVectors bool
+}
+
+// intersect returns the capabilities supported by both c and other.
+func (c OnlineCapabilities) intersect(other OnlineCapabilities) OnlineCapabilities {
+	return OnlineCapabilities{
+		BatchWrites:      c.BatchWrites && other.BatchWrites,
+		Scan:             c.Scan && other.Scan,
+		Stats:            c.Stats && other.Stats,
+		Increment:        c.Increment && other.Increment,
+		Append:           c.Append && other.Append,
+		Timestamps:       c.Timestamps && other.Timestamps,
+		Truncate:         c.Truncate && other.Truncate,
+		ListTables:       c.ListTables && other.ListTables,
+		BulkLoad:         c.BulkLoad && other.BulkLoad,
+		Vectors:          c.Vectors && other.Vectors,
+		VectorFilters:    c.VectorFilters && other.VectorFilters,
+		SparseVectors:    c.SparseVectors && other.SparseVectors,
+		VectorNamespaces: c.VectorNamespaces && other.VectorNamespaces,
+		IndexStats:       c.IndexStats && other.IndexStats,
+		AsyncIndexBuilds: c.AsyncIndexBuilds && other.AsyncIndexBuilds,
+		NamedVectors:     c.NamedVectors && other.NamedVectors,
+	}
+}
+
 type OnlineStoreTable interface {
 	Set(entity string, value interface{}) error
 	Get(entity string) (interface{}, error)
 }
 
+// IncrementableTable is implemented by online tables that can atomically add
+// a delta to a numeric value. It allows counter-style features to be updated
+// by concurrent writers without a read-modify-write race. Entities without a
+// value are treated as zero. The new value is returned cast to the table type.
+type IncrementableTable interface {
+	OnlineStoreTable
+	Increment(entity string, delta interface{}) (interface{}, error)
+}
+
+// AppendableTable is implemented by online tables that can keep a capped list
+// of the most recent values per entity, such as the last N events a user
+// interacted with. Lists are ordered newest first and are stored separately
+// from values written with Set.
+type AppendableTable interface {
+	OnlineStoreTable
+	Append(entity string, value interface{}, maxLen int) error
+	GetList(entity string) ([]interface{}, error)
+}
+
+// BatchSettableTable is implemented by online tables that can write the
+// values of many entities in fewer round trips than calling Set for each.
+// Providers may split large batches into multiple requests, so a failed
+// batch can be partially written.
+type BatchSettableTable interface {
+	OnlineStoreTable
+	SetBatch(values map[string]interface{}) error
+}
+
+// BulkLoadableStore is implemented by online stores that can write a whole
+// materialization in their own format faster than it can be written row by
+// row. CanBulkLoad reports whether the store is configured to do so; if it
+// is, materializations are loaded with BulkLoad instead of Set. BulkLoad
+// replaces all of the table's values.
+type BulkLoadableStore interface {
+	OnlineStore
+	CanBulkLoad() bool
+	BulkLoad(feature, variant string, records FeatureIterator) error
+}
+
+// TruncatableStore is implemented by online stores that can remove every
+// entity's value from a table while keeping the table and any index on it,
+// so that a full refresh doesn't race DeleteTable and CreateTable. Reads
+// during and after a truncate miss until the values are written again.
+type TruncatableStore interface {
+	OnlineStore
+	Truncate(feature, variant string) error
+}
+
+// OnlineTableInfo describes a table in an online store.
+type OnlineTableInfo struct {
+	Feature, Variant string
+	ValueType        ValueType
+}
+
+// TableListingStore is implemented by online stores that can list their
+// tables, so that they can be copied to another store.
+type TableListingStore interface {
+	OnlineStore
+	ListTables() ([]OnlineTableInfo, error)
+}
+
+// ScannableTable is implemented by online tables that can iterate over the
+// value of every entity. Values written during a scan may or may not be
+// returned by it.
+type ScannableTable interface {
+	OnlineStoreTable
+	Scan() (FeatureIterator, error)
+}
+
+// TableStats describes the size of an online table. Bytes is a provider
+// specific estimate of the storage used, which may lag behind recent writes.
+type TableStats struct {
+	Entities int64
+	Bytes    int64
+}
+
+// StatsTable is implemented by online tables that can report their size
+// without scanning every value.
+type StatsTable interface {
+	OnlineStoreTable
+	Stats() (TableStats, error)
+}
+
+// VectorIndexStats describes the health of a vector index. MemoryBytes is a
+// provider specific estimate of the index's size, and is zero if the store
+// doesn't report it. Building is set while the store is still indexing
+// vectors that have been written, and IndexedFraction is the fraction of
+// them that have been indexed.
+type VectorIndexStats struct {
+	Vectors         int64
+	Dimension       int32
+	MemoryBytes     int64
+	Building        bool
+	IndexedFraction float64
+}
+
+// IndexStatsTable is implemented by vector tables that can report the size
+// and build status of their index, so that operators can check its health
+// after a materialization.
+type IndexStatsTable interface {
+	VectorStoreTable
+	IndexStats() (VectorIndexStats, error)
+}
+
+// setBatch writes the values with SetBatch if the table supports it, and
+// otherwise sets each entity in turn.
+func setBatch(table OnlineStoreTable, values map[string]interface{}) error {
+	if batchTable, ok := table.(BatchSettableTable); ok {
+		return batchTable.SetBatch(values)
+	}
+	for entity, value := range values {
+		if err := table.Set(entity, value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// truncate truncates the table if the store supports it.
+func truncate(store OnlineStore, feature, variant string) error {
+	truncatable, ok := store.(TruncatableStore)
+	if !ok || !store.Capabilities().Truncate {
+		return fmt.Errorf("%T does not support truncating tables", store)
+	}
+	return truncatable.Truncate(feature, variant)
+}
+
+// SparseVectorStore is implemented by online stores that index sparse
+// vectors for nearest neighbor search. Sparse vectors are ranked by their
+// dot product, as learned sparse models and lexical term weights expect.
+type SparseVectorStore interface {
+	CreateSparseIndex(feature, variant string, vectorType SparseVectorType) (SparseVectorTable, error)
+	OnlineStore
+}
+
+type SparseVectorTable interface {
+	OnlineStoreTable
+	// NearestSparse returns the k entities whose vectors have the largest dot
+	// products with vector, nearest first. Distances are the negated
+	// products.
+	NearestSparse(feature, variant string, vector SparseVector, k int32) ([]NearestResult, error)
+}
+
 type VectorStore interface {
 	CreateIndex(feature, variant string, vectorType VectorType) (VectorStoreTable, error)
 	OnlineStore
@@ -40,7 +266,214 @@ type VectorStore interface {
 
 type VectorStoreTable interface {
 	OnlineStoreTable
-	Nearest(feature, variant string, vector []float32, k int32) ([]string, error)
+	// Nearest returns the entities of the k vectors nearest to vector that
+	// match filter, nearest first. Stores without the VectorFilters
+	// capability return an error for any filter that isn't zero.
+	Nearest(feature, variant string, vector []float32, k int32, filter VectorFilter) ([]NearestResult, error)
+	// NearestPage returns a page of up to pageSize of the entities nearest
+	// to vector that match filter, starting after cursor. The first page
+	// has an empty cursor, and each page returns the cursor of the next,
+	// which is empty after the last page.
+	NearestPage(feature, variant string, vector []float32, pageSize int32, filter VectorFilter, cursor string) (NearestPage, error)
+	// Delete removes the entity's vector, so that it's no longer returned
+	// by Nearest. Deleting an entity without a vector isn't an error.
+	Delete(entity string) error
+}
+
+// NearestPage is a page of Nearest results.
+type NearestPage struct {
+	Results []NearestResult
+	// Cursor is passed to NearestPage to get the next page. It's empty if
+	// this is the last page.
+	Cursor string
+}
+
+// Cursors of Nearest pages are the offset of the next page. Vector indexes
+// don't have stable positions to resume from, so every page searches for
+// the nearest offset+pageSize entities and skips the first offset of them.
+// Pages can overlap or skip entities if vectors are written between them.
+func encodeNearestCursor(offset int32) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(strconv.Itoa(int(offset))))
+}
+
+func decodeNearestCursor(cursor string) (int32, error) {
+	if cursor == "" {
+		return 0, nil
+	}
+	decoded, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return 0, fmt.Errorf("invalid nearest cursor %q", cursor)
+	}
+	offset, err := strconv.ParseInt(string(decoded), 10, 32)
+	if err != nil || offset < 0 {
+		return 0, fmt.Errorf("invalid nearest cursor %q", cursor)
+	}
+	return int32(offset), nil
+}
+
+// nearestPage is the NearestPage of tables that can search from an offset.
+func nearestPage(search func(offset, k int32) ([]NearestResult, error), pageSize int32, cursor string) (NearestPage, error) {
+	if pageSize <= 0 {
+		return NearestPage{}, fmt.Errorf("page size must be positive, got %d", pageSize)
+	}
+	offset, err := decodeNearestCursor(cursor)
+	if err != nil {
+		return NearestPage{}, err
+	}
+	results, err := search(offset, pageSize)
+	if err != nil {
+		return NearestPage{}, err
+	}
+	page := NearestPage{Results: results}
+	if int32(len(results)) == pageSize {
+		page.Cursor = encodeNearestCursor(offset + pageSize)
+	}
+	return page, nil
+}
+
+// NearestResult is an entity returned by Nearest and its distance from the
+// search vector. Stores report their scores in different units, so they're
+// converted to the distance of the table's metric: one minus the cosine
+// similarity, the Euclidean distance, or the negative inner product. Smaller
+// distances are always nearer.
+type NearestResult struct {
+	Entity   string
+	Distance float32
+}
+
+// VectorFilter restricts a nearest neighbor search to the entities whose
+// attributes include every one of Attributes and, if UpdatedAfter isn't
+// zero, whose vectors were written at or after it. If Namespace is set,
+// only the entities written to that namespace with SetInNamespace are
+// searched. Filters are pushed down into the store's search rather than
+// applied to the k nearest entities.
+type VectorFilter struct {
+	Attributes   map[string]string
+	UpdatedAfter time.Time
+	Namespace    string
+}
+
+// IsZero returns whether the filter matches every entity.
+func (filter VectorFilter) IsZero() bool {
+	return len(filter.Attributes) == 0 && filter.UpdatedAfter.IsZero() && filter.Namespace == ""
+}
+
+// AttributedVectorTable is implemented by vector tables that store
+// attributes, like tags, with each entity's vector for VectorFilters to
+// match. Set stores a vector with no attributes.
+type AttributedVectorTable interface {
+	VectorStoreTable
+	SetWithAttributes(entity string, vector []float32, attributes map[string]string) error
+}
+
+// NamespacedVectorTable is implemented by vector tables that can be
+// partitioned by a namespace, such as a tenant ID, so that searches with a
+// VectorFilter's Namespace only consider one partition. Each entity is in
+// at most one namespace, and writing it without one removes it from its
+// namespace.
+type NamespacedVectorTable interface {
+	VectorStoreTable
+	SetInNamespace(namespace, entity string, vector []float32) error
+}
+
+// NamedVectorTable is implemented by vector tables of VectorTypes with
+// Names, which hold several vectors per entity. SetNamed replaces all of the
+// entity's vectors, and NearestNamed searches the vectors of one name.
+type NamedVectorTable interface {
+	VectorStoreTable
+	SetNamed(entity string, vectors map[string][]float32) error
+	NearestNamed(feature, variant, name string, vector []float32, k int32, filter VectorFilter) ([]NearestResult, error)
+}
+
+// EntityNearestTable is implemented by vector tables that can search for
+// the neighbors of an entity's stored vector in one request to the store.
+type EntityNearestTable interface {
+	VectorStoreTable
+	NearestByEntity(feature, variant, entity string, k int32) ([]NearestResult, error)
+}
+
+// NearestByEntity returns the k entities nearest to entity's stored vector,
+// excluding entity itself. Tables that aren't EntityNearestTables get the
+// entity's vector and search for it.
+func NearestByEntity(table VectorStoreTable, feature, variant, entity string, k int32) ([]NearestResult, error) {
+	if entityTable, ok := table.(EntityNearestTable); ok {
+		return entityTable.NearestByEntity(feature, variant, entity, k)
+	}
+	value, err := table.Get(entity)
+	if err != nil {
+		return nil, err
+	}
+	vector, ok := float32Vector(value)
+	if !ok {
+		return nil, fmt.Errorf("value %v of entity %s is not a vector", value, entity)
+	}
+	results, err := table.Nearest(feature, variant, vector, k+1, VectorFilter{})
+	if err != nil {
+		return nil, err
+	}
+	return withoutEntity(results, entity, k), nil
+}
+
+// withoutEntity removes entity from the results of a search for k+1
+// entities near its vector, leaving at most k.
+func withoutEntity(results []NearestResult, entity string, k int32) []NearestResult {
+	filtered := make([]NearestResult, 0, len(results))
+	for _, result := range results {
+		if result.Entity != entity {
+			filtered = append(filtered, result)
+		}
+	}
+	if int32(len(filtered)) > k {
+		filtered = filtered[:k]
+	}
+	return filtered
+}
+
+// HybridSearchTable is implemented by vector tables that index a text
+// document with each vector. HybridNearest searches only the entities whose
+// text contains every term of query, ranked by vector distance.
+type HybridSearchTable interface {
+	VectorStoreTable
+	SetWithText(entity string, vector []float32, text string) error
+	HybridNearest(feature, variant string, vector []float32, k int32, query string) ([]NearestResult, error)
+}
+
+// validateNamespace returns an error if a vector namespace is empty.
+func validateNamespace(namespace string) error {
+	if namespace == "" {
+		return fmt.Errorf("vector namespace can't be empty")
+	}
+	return nil
+}
+
+// errVectorFiltersUnsupported is returned by the Nearest methods of stores
+// that can't filter their searches.
+func errVectorFiltersUnsupported(table interface{}) error {
+	return fmt.Errorf("%T does not support filtered nearest neighbor searches", table)
+}
+
+// vectorAttributeTerms returns attributes as sorted key=value terms, for
+// stores that match attributes as members of a list of strings. Keys can't
+// contain '=', so that each term has one key and value.
+func vectorAttributeTerms(attributes map[string]string) ([]string, error) {
+	terms := make([]string, 0, len(attributes))
+	for key, value := range attributes {
+		if key == "" || strings.Contains(key, "=") {
+			return nil, fmt.Errorf("invalid vector attribute key %q", key)
+		}
+		terms = append(terms, key+"="+value)
+	}
+	sort.Strings(terms)
+	return terms, nil
+}
+
+// TimestampedTable is implemented by online tables that record when each
+// entity's value was last written, so that serving can report and enforce
+// feature freshness. The returned time is zero for values written before
+// timestamps were recorded.
+type TimestampedTable interface {
+	OnlineStoreTable
+	GetWithTimestamp(entity string) (interface{}, time.Time, error)
 }
 
 type TableNotFound struct {
@@ -67,6 +500,41 @@ func (err *EntityNotFound) Error() string {
 	return fmt.Sprintf("Entity %s not found.", err.Entity)
 }
 
+// DimensionMismatch is returned when a vector written to a table doesn't
+// have the dimension of the table's VectorType.
+type DimensionMismatch struct {
+	Entity    string
+	Expected  int32
+	Dimension int
+}
+
+func (err *DimensionMismatch) Error() string {
+	return fmt.Sprintf("Vector for entity %s has dimension %d, expected %d.", err.Entity, err.Dimension, err.Expected)
+}
+
+// checkVectorDimension returns a DimensionMismatch if valueType is a vector
+// type with a dimension that vector's length doesn't match.
+func checkVectorDimension(valueType ValueType, entity string, vector []float32) error {
+	vectorType, isVector := valueType.(VectorType)
+	if !isVector || vectorType.Dimension <= 0 || int(vectorType.Dimension) == len(vector) {
+		return nil
+	}
+	return &DimensionMismatch{Entity: entity, Expected: vectorType.Dimension, Dimension: len(vector)}
+}
+
+// vectorValue asserts that an entity's value is a vector of valueType's
+// dimension, converting float64 vectors to float32.
+func vectorValue(valueType ValueType, entity string, value interface{}) ([]float32, error) {
+	vector, ok := float32Vector(value)
+	if !ok {
+		return nil, fmt.Errorf("value %v is not a vector", value)
+	}
+	if err := checkVectorDimension(valueType, entity, vector); err != nil {
+		return nil, err
+	}
+	return vector, nil
+}
+
 type tableKey struct {
 	feature, variant string
 }
@@ -84,14 +552,15 @@ func localOnlineStoreFactory(pc.SerializedConfig) (Provider, error) {
 }
 
 type localOnlineStore struct {
-	tables map[tableKey]localOnlineTable
+	tables map[tableKey]*localOnlineTable
+	mu     sync.RWMutex
 	BaseProvider
 }
 
 func NewLocalOnlineStore() *localOnlineStore {
 	return &localOnlineStore{
-		make(map[tableKey]localOnlineTable),
-		BaseProvider{
+		tables: make(map[tableKey]*localOnlineTable),
+		BaseProvider: BaseProvider{
 			ProviderType:   pt.LocalOnline,
 			ProviderConfig: []byte{},
 		},
@@ -103,6 +572,8 @@ func (store *localOnlineStore) AsOnlineStore() (OnlineStore, error) {
 }
 
 func (store *localOnlineStore) GetTable(feature, variant string) (OnlineStoreTable, error) {
+	store.mu.RLock()
+	defer store.mu.RUnlock()
 	table, has := store.tables[tableKey{feature, variant}]
 	if !has {
 		return nil, &TableNotFound{feature, variant}
@@ -111,34 +582,292 @@ func (store *localOnlineStore) GetTable(feature, variant string) (OnlineStoreTab
 }
 
 func (store *localOnlineStore) CreateTable(feature, variant string, valueType ValueType) (OnlineStoreTable, error) {
+	store.mu.Lock()
+	defer store.mu.Unlock()
 	key := tableKey{feature, variant}
 	if _, has := store.tables[key]; has {
 		return nil, &TableAlreadyExists{feature, variant}
 	}
-	table := make(localOnlineTable)
+	table := newLocalOnlineTable(valueType)
 	store.tables[key] = table
 	return table, nil
 }
 
-func (store *localOnlineStore) DeleteTable(feaute, variant string) error {
+func (store *localOnlineStore) DeleteTable(feature, variant string) error {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+	key := tableKey{feature, variant}
+	if _, has := store.tables[key]; !has {
+		return &TableNotFound{feature, variant}
+	}
+	delete(store.tables, key)
 	return nil
 }
 
+func (store *localOnlineStore) Capabilities() OnlineCapabilities {
+	return OnlineCapabilities{
+		BatchWrites: true,
+		Scan:        true,
+		Stats:       true,
+		Increment:   true,
+		Append:      true,
+		Timestamps:  true,
+		Truncate:    true,
+		ListTables:  true,
+	}
+}
+
 func (store *localOnlineStore) Close() error {
 	return nil
 }
 
-type localOnlineTable map[string]interface{}
+func (store *localOnlineStore) Truncate(feature, variant string) error {
+	store.mu.RLock()
+	table, has := store.tables[tableKey{feature, variant}]
+	store.mu.RUnlock()
+	if !has {
+		return &TableNotFound{feature, variant}
+	}
+	table.mu.Lock()
+	defer table.mu.Unlock()
+	table.values = make(map[string]interface{})
+	table.updated = make(map[string]time.Time)
+	table.lists = make(map[string][]interface{})
+	return nil
+}
+
+func (store *localOnlineStore) ListTables() ([]OnlineTableInfo, error) {
+	store.mu.RLock()
+	defer store.mu.RUnlock()
+	tables := make([]OnlineTableInfo, 0, len(store.tables))
+	for key, table := range store.tables {
+		tables = append(tables, OnlineTableInfo{key.feature, key.variant, table.valueType})
+	}
+	sort.Slice(tables, func(i, j int) bool {
+		if tables[i].Feature != tables[j].Feature {
+			return tables[i].Feature < tables[j].Feature
+		}
+		return tables[i].Variant < tables[j].Variant
+	})
+	return tables, nil
+}
+
+type localOnlineTable struct {
+	values    map[string]interface{}
+	updated   map[string]time.Time
+	lists     map[string][]interface{}
+	valueType ValueType
+	mu        sync.RWMutex
+}
+
+func newLocalOnlineTable(valueType ValueType) *localOnlineTable {
+	return &localOnlineTable{
+		values:    make(map[string]interface{}),
+		updated:   make(map[string]time.Time),
+		lists:     make(map[string][]interface{}),
+		valueType: valueType,
+	}
+}
 
-func (table localOnlineTable) Set(entity string, value interface{}) error {
-	table[entity] = value
+func (table *localOnlineTable) Set(entity string, value interface{}) error {
+	value, err := table.vectorValue(entity, value)
+	if err != nil {
+		return err
+	}
+	table.mu.Lock()
+	defer table.mu.Unlock()
+	table.values[entity] = value
+	table.updated[entity] = time.Now()
+	return nil
+}
+
+func (table *localOnlineTable) SetBatch(values map[string]interface{}) error {
+	converted := make(map[string]interface{}, len(values))
+	for entity, value := range values {
+		vector, err := table.vectorValue(entity, value)
+		if err != nil {
+			return err
+		}
+		converted[entity] = vector
+	}
+	values = converted
+	table.mu.Lock()
+	defer table.mu.Unlock()
+	now := time.Now()
+	for entity, value := range values {
+		table.values[entity] = value
+		table.updated[entity] = now
+	}
+	return nil
+}
+
+// vectorValue checks the dimension of vectors written to vector tables and
+// converts them to float32. Other values are returned unchanged.
+func (table *localOnlineTable) vectorValue(entity string, value interface{}) (interface{}, error) {
+	if !table.valueType.IsVector() {
+		return value, nil
+	}
+	vector, isVector := float32Vector(value)
+	if !isVector {
+		return value, nil
+	}
+	if err := checkVectorDimension(table.valueType, entity, vector); err != nil {
+		return nil, err
+	}
+	return vector, nil
+}
+
+func (table *localOnlineTable) Get(entity string) (interface{}, error) {
+	val, _, err := table.GetWithTimestamp(entity)
+	return val, err
+}
+
+func (table *localOnlineTable) GetWithTimestamp(entity string) (interface{}, time.Time, error) {
+	table.mu.RLock()
+	defer table.mu.RUnlock()
+	val, has := table.values[entity]
+	if !has {
+		return nil, time.Time{}, &EntityNotFound{entity}
+	}
+	return val, table.updated[entity], nil
+}
+
+// Scan iterates over a snapshot of the table's values.
+func (table *localOnlineTable) Scan() (FeatureIterator, error) {
+	table.mu.RLock()
+	defer table.mu.RUnlock()
+	recs := make([]ResourceRecord, 0, len(table.values))
+	for entity, value := range table.values {
+		recs = append(recs, ResourceRecord{Entity: entity, Value: value, TS: table.updated[entity]})
+	}
+	return newMemoryFeatureIterator(recs), nil
+}
+
+// Stats estimates each value's size by the length of its printed form.
+func (table *localOnlineTable) Stats() (TableStats, error) {
+	table.mu.RLock()
+	defer table.mu.RUnlock()
+	stats := TableStats{Entities: int64(len(table.values))}
+	for entity, value := range table.values {
+		stats.Bytes += int64(len(entity) + len(fmt.Sprint(value)))
+	}
+	return stats, nil
+}
+
+func (table *localOnlineTable) Increment(entity string, delta interface{}) (interface{}, error) {
+	table.mu.Lock()
+	defer table.mu.Unlock()
+	updated, err := addNumeric(table.valueType, table.values[entity], delta)
+	if err != nil {
+		return nil, err
+	}
+	table.values[entity] = updated
+	table.updated[entity] = time.Now()
+	return updated, nil
+}
+
+func (table *localOnlineTable) Append(entity string, value interface{}, maxLen int) error {
+	if maxLen <= 0 {
+		return fmt.Errorf("max list length must be positive: %d", maxLen)
+	}
+	table.mu.Lock()
+	defer table.mu.Unlock()
+	list := append([]interface{}{value}, table.lists[entity]...)
+	if len(list) > maxLen {
+		list = list[:maxLen]
+	}
+	table.lists[entity] = list
 	return nil
 }
 
-func (table localOnlineTable) Get(entity string) (interface{}, error) {
-	val, has := table[entity]
+func (table *localOnlineTable) GetList(entity string) ([]interface{}, error) {
+	table.mu.RLock()
+	defer table.mu.RUnlock()
+	list, has := table.lists[entity]
 	if !has {
 		return nil, &EntityNotFound{entity}
 	}
-	return val, nil
+	result := make([]interface{}, len(list))
+	copy(result, list)
+	return result, nil
+}
+
+// addNumeric adds delta to current and casts the sum to valueType. A nil
+// current value is treated as zero.
+func addNumeric(valueType ValueType, current, delta interface{}) (interface{}, error) {
+	if valueType.IsVector() {
+		return nil, fmt.Errorf("cannot increment vector type %v", valueType)
+	}
+	switch valueType.Scalar() {
+	case Int, Int32, Int64:
+		c, err := toInt64(current)
+		if err != nil {
+			return nil, err
+		}
+		d, err := toInt64(delta)
+		if err != nil {
+			return nil, err
+		}
+		return castInt64(valueType.Scalar(), c+d), nil
+	case Float32, Float64:
+		c, err := toFloat64(current)
+		if err != nil {
+			return nil, err
+		}
+		d, err := toFloat64(delta)
+		if err != nil {
+			return nil, err
+		}
+		if valueType.Scalar() == Float32 {
+			return float32(c + d), nil
+		}
+		return c + d, nil
+	default:
+		return nil, fmt.Errorf("cannot increment non-numeric type %v", valueType)
+	}
+}
+
+func castInt64(t ScalarType, v int64) interface{} {
+	switch t {
+	case Int:
+		return int(v)
+	case Int32:
+		return int32(v)
+	default:
+		return v
+	}
+}
+
+func toInt64(value interface{}) (int64, error) {
+	switch v := value.(type) {
+	case nil:
+		return 0, nil
+	case int:
+		return int64(v), nil
+	case int32:
+		return int64(v), nil
+	case int64:
+		return v, nil
+	default:
+		return 0, fmt.Errorf("type %T of value %v is not an integer", value, value)
+	}
+}
+
+func toFloat64(value interface{}) (float64, error) {
+	switch v := value.(type) {
+	case nil:
+		return 0, nil
+	case int:
+		return float64(v), nil
+	case int32:
+		return float64(v), nil
+	case int64:
+		return float64(v), nil
+	case float32:
+		return float64(v), nil
+	case float64:
+		return v, nil
+	default:
+		return 0, fmt.Errorf("type %T of value %v is not numeric", value, value)
+	}
 }
@@ -0,0 +1,156 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package provider
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/segmentio/parquet-go"
+)
+
+// exportBatchSize is the number of rows buffered before they're written to
+// a Parquet export.
+const exportBatchSize = 1000
+
+// exportRow is a row of an exported table. Tables can hold values of any
+// type, so values are written as text: strings as they are, timestamps in
+// RFC 3339 format and everything else as JSON. Nil values and values that
+// were never timestamped are written as nulls, or empty fields in CSVs.
+type exportRow struct {
+	Entity    string `parquet:"entity"`
+	Value     string `parquet:"value,optional"`
+	Timestamp int64  `parquet:"timestamp,optional,timestamp(microsecond)"`
+}
+
+func newExportRow(record ResourceRecord) (exportRow, error) {
+	value, err := formatExportValue(record.Value)
+	if err != nil {
+		return exportRow{}, fmt.Errorf("could not format value of entity %s: %w", record.Entity, err)
+	}
+	row := exportRow{Entity: record.Entity, Value: value}
+	if !record.TS.IsZero() {
+		row.Timestamp = record.TS.UnixMicro()
+	}
+	return row, nil
+}
+
+func formatExportValue(value interface{}) (string, error) {
+	switch v := value.(type) {
+	case nil:
+		return "", nil
+	case string:
+		return v, nil
+	case time.Time:
+		return v.UTC().Format(time.RFC3339Nano), nil
+	}
+	serialized, err := json.Marshal(value)
+	if err != nil {
+		return "", err
+	}
+	return string(serialized), nil
+}
+
+// ExportOnlineTable writes every value in the table to key in the file store,
+// as a CSV with a header row or as a Parquet file, and returns the number of
+// values written. It's meant for audits and for comparing served values with
+// the ones used to train models, so the file is written from a scan of the
+// table and values set during the export may not be included.
+func ExportOnlineTable(table OnlineStoreTable, store FileStore, key string, format FileType) (int64, error) {
+	scannable, ok := table.(ScannableTable)
+	if !ok {
+		return 0, fmt.Errorf("%T does not support scans", table)
+	}
+	var export func(FeatureIterator, io.Writer) (int64, error)
+	switch format {
+	case CSV:
+		export = exportCSV
+	case Parquet:
+		export = exportParquet
+	default:
+		return 0, fmt.Errorf("unknown export format: %s", format)
+	}
+	it, err := scannable.Scan()
+	if err != nil {
+		return 0, err
+	}
+	defer it.Close()
+	writer, err := store.Writer(key)
+	if err != nil {
+		return 0, fmt.Errorf("could not open %s: %w", key, err)
+	}
+	written, err := export(it, writer)
+	if err != nil {
+		writer.Close()
+		return written, err
+	}
+	if err := writer.Close(); err != nil {
+		return written, fmt.Errorf("could not write %s: %w", key, err)
+	}
+	return written, nil
+}
+
+func exportCSV(it FeatureIterator, w io.Writer) (int64, error) {
+	writer := csv.NewWriter(w)
+	if err := writer.Write([]string{"entity", "value", "timestamp"}); err != nil {
+		return 0, err
+	}
+	var written int64
+	for it.Next() {
+		row, err := newExportRow(it.Value())
+		if err != nil {
+			return written, err
+		}
+		timestamp := ""
+		if row.Timestamp != 0 {
+			timestamp = time.UnixMicro(row.Timestamp).UTC().Format(time.RFC3339Nano)
+		}
+		if err := writer.Write([]string{row.Entity, row.Value, timestamp}); err != nil {
+			return written, err
+		}
+		written++
+	}
+	if err := it.Err(); err != nil {
+		return written, err
+	}
+	writer.Flush()
+	return written, writer.Error()
+}
+
+func exportParquet(it FeatureIterator, w io.Writer) (int64, error) {
+	writer := parquet.NewGenericWriter[exportRow](w)
+	rows := make([]exportRow, 0, exportBatchSize)
+	var written int64
+	flush := func() error {
+		if _, err := writer.Write(rows); err != nil {
+			return err
+		}
+		written += int64(len(rows))
+		rows = rows[:0]
+		return nil
+	}
+	for it.Next() {
+		row, err := newExportRow(it.Value())
+		if err != nil {
+			return written, err
+		}
+		rows = append(rows, row)
+		if len(rows) == exportBatchSize {
+			if err := flush(); err != nil {
+				return written, err
+			}
+		}
+	}
+	if err := it.Err(); err != nil {
+		return written, err
+	}
+	if err := flush(); err != nil {
+		return written, err
+	}
+	return written, writer.Close()
+}
@@ -0,0 +1,110 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package provider
+
+import (
+	"bytes"
+	"encoding/csv"
+	"reflect"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/segmentio/parquet-go"
+)
+
+func newExportTestTable(t *testing.T) (OnlineStoreTable, FileStore) {
+	files := newLocalTestFileStore(t)
+	table, err := NewLocalOnlineStore().CreateTable("feature", "variant", String)
+	if err != nil {
+		t.Fatalf("Failed to create table: %s", err)
+	}
+	values := map[string]interface{}{
+		"a": "value",
+		"b": 12,
+		"c": []float32{1, 2.5},
+		"d": nil,
+	}
+	for entity, value := range values {
+		if err := table.Set(entity, value); err != nil {
+			t.Fatalf("Failed to set %s: %s", entity, err)
+		}
+	}
+	return table, files
+}
+
+func TestExportOnlineTableCSV(t *testing.T) {
+	table, files := newExportTestTable(t)
+	start := time.Now()
+	written, err := ExportOnlineTable(table, files, "export.csv", CSV)
+	if err != nil {
+		t.Fatalf("Failed to export table: %s", err)
+	}
+	if written != 4 {
+		t.Fatalf("Expected 4 rows written, got %d", written)
+	}
+	data, err := files.Read("export.csv")
+	if err != nil {
+		t.Fatalf("Failed to read export: %s", err)
+	}
+	rows, err := csv.NewReader(bytes.NewReader(data)).ReadAll()
+	if err != nil {
+		t.Fatalf("Failed to parse export: %s", err)
+	}
+	if !reflect.DeepEqual(rows[0], []string{"entity", "value", "timestamp"}) {
+		t.Fatalf("Unexpected header: %v", rows[0])
+	}
+	rows = rows[1:]
+	sort.Slice(rows, func(i, j int) bool { return rows[i][0] < rows[j][0] })
+	expected := [][]string{{"a", "value"}, {"b", "12"}, {"c", "[1,2.5]"}, {"d", ""}}
+	for i, row := range rows {
+		if !reflect.DeepEqual(row[:2], expected[i]) {
+			t.Fatalf("Expected row %v, got %v", expected[i], row[:2])
+		}
+		ts, err := time.Parse(time.RFC3339Nano, row[2])
+		if err != nil {
+			t.Fatalf("Failed to parse timestamp of %s: %s", row[0], err)
+		}
+		if ts.Before(start.Add(-time.Minute)) {
+			t.Fatalf("Unexpected timestamp for %s: %s", row[0], ts)
+		}
+	}
+}
+
+func TestExportOnlineTableParquet(t *testing.T) {
+	table, files := newExportTestTable(t)
+	written, err := ExportOnlineTable(table, files, "export.parquet", Parquet)
+	if err != nil {
+		t.Fatalf("Failed to export table: %s", err)
+	}
+	if written != 4 {
+		t.Fatalf("Expected 4 rows written, got %d", written)
+	}
+	data, err := files.Read("export.parquet")
+	if err != nil {
+		t.Fatalf("Failed to read export: %s", err)
+	}
+	rows, err := parquet.Read[exportRow](bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		t.Fatalf("Failed to parse export: %s", err)
+	}
+	sort.Slice(rows, func(i, j int) bool { return rows[i].Entity < rows[j].Entity })
+	expected := []string{"value", "12", "[1,2.5]", ""}
+	for i, row := range rows {
+		if row.Value != expected[i] {
+			t.Fatalf("Expected value %q for %s, got %q", expected[i], row.Entity, row.Value)
+		}
+		if row.Timestamp == 0 {
+			t.Fatalf("Missing timestamp for %s", row.Entity)
+		}
+	}
+}
+
+func TestExportOnlineTableUnknownFormat(t *testing.T) {
+	table, files := newExportTestTable(t)
+	if _, err := ExportOnlineTable(table, files, "export.json", "json"); err == nil {
+		t.Fatalf("Expected error for unknown export format")
+	}
+}
@@ -0,0 +1,162 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package provider
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"time"
+)
+
+type OnlineImportOptions struct {
+	// Format is the format of the file, CSV or Parquet.
+	Format FileType
+	// ValueType is the type values are converted to before they're written.
+	ValueType ValueType
+	// BatchSize is the number of values written to the table at a time. It
+	// defaults to 1000.
+	BatchSize int
+}
+
+// ImportOnlineTable writes the entity and value pairs in the file at key to
+// the table, and returns the number of values written. It lets features
+// computed outside of Featureform be served without an offline store.
+//
+// The file must have entity and value columns. CSVs must start with a header
+// row naming them, and their values are parsed like ParseValue. Other
+// columns are ignored. If an entity appears more than once, its last value
+// is kept.
+func ImportOnlineTable(table OnlineStoreTable, store FileStore, key string, options OnlineImportOptions) (int64, error) {
+	if options.BatchSize == 0 {
+		options.BatchSize = defaultMigrationBatchSize
+	} else if options.BatchSize < 0 {
+		return 0, fmt.Errorf("batch size must be positive: %d", options.BatchSize)
+	}
+	var next func() (string, interface{}, error)
+	switch options.Format {
+	case CSV:
+		data, err := store.Read(key)
+		if err != nil {
+			return 0, fmt.Errorf("could not read %s: %w", key, err)
+		}
+		next, err = csvImportRows(data)
+		if err != nil {
+			return 0, err
+		}
+	case Parquet:
+		it, err := store.Serve(key)
+		if err != nil {
+			return 0, fmt.Errorf("could not read %s: %w", key, err)
+		}
+		next = parquetImportRows(it)
+	default:
+		return 0, fmt.Errorf("unknown import format: %s", options.Format)
+	}
+	var written, row int64
+	batch := make(map[string]interface{}, options.BatchSize)
+	flush := func() error {
+		if err := setBatch(table, batch); err != nil {
+			return err
+		}
+		written += int64(len(batch))
+		batch = make(map[string]interface{}, options.BatchSize)
+		return nil
+	}
+	for {
+		row++
+		entity, raw, err := next()
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			return written, fmt.Errorf("row %d: %w", row, err)
+		}
+		value, err := castImportValue(options.ValueType, raw)
+		if err != nil {
+			return written, fmt.Errorf("row %d: entity %s: %w", row, entity, err)
+		}
+		if _, has := batch[entity]; !has && len(batch) == options.BatchSize {
+			if err := flush(); err != nil {
+				return written, err
+			}
+		}
+		batch[entity] = value
+	}
+	if len(batch) > 0 {
+		if err := flush(); err != nil {
+			return written, err
+		}
+	}
+	return written, nil
+}
+
+func csvImportRows(data []byte) (func() (string, interface{}, error), error) {
+	reader := csv.NewReader(bytes.NewReader(data))
+	header, err := reader.Read()
+	if err != nil {
+		return nil, fmt.Errorf("could not read header: %w", err)
+	}
+	entityIdx, valueIdx := -1, -1
+	for i, column := range header {
+		switch column {
+		case "entity":
+			entityIdx = i
+		case "value":
+			valueIdx = i
+		}
+	}
+	if entityIdx == -1 || valueIdx == -1 {
+		return nil, fmt.Errorf("header must have entity and value columns: %v", header)
+	}
+	return func() (string, interface{}, error) {
+		record, err := reader.Read()
+		if err != nil {
+			return "", nil, err
+		}
+		return record[entityIdx], record[valueIdx], nil
+	}, nil
+}
+
+func parquetImportRows(it Iterator) func() (string, interface{}, error) {
+	return func() (string, interface{}, error) {
+		row, err := it.Next()
+		if err != nil {
+			return "", nil, err
+		}
+		if row == nil {
+			return "", nil, io.EOF
+		}
+		entity, ok := row["entity"].(string)
+		if !ok {
+			return "", nil, fmt.Errorf("entity must be a string: %v", row["entity"])
+		}
+		value, has := row["value"]
+		if !has {
+			return "", nil, fmt.Errorf("entity %s has no value column", entity)
+		}
+		return entity, value, nil
+	}
+}
+
+// castImportValue converts a value read from a file to valueType. Values of
+// types that can't be converted directly are formatted and then parsed.
+func castImportValue(valueType ValueType, value interface{}) (interface{}, error) {
+	switch v := value.(type) {
+	case nil:
+		return nil, fmt.Errorf("value is null")
+	case string:
+		return ParseValue(valueType, v)
+	case time.Time:
+		return ParseValue(valueType, v.UTC().Format(time.RFC3339Nano))
+	case map[string]interface{}:
+		if !valueType.IsVector() {
+			return nil, fmt.Errorf("cannot cast list to %v", valueType)
+		}
+		return parseParquetValue(v)
+	default:
+		return ParseValue(valueType, fmt.Sprint(v))
+	}
+}
@@ -0,0 +1,151 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package provider
+
+import (
+	"bytes"
+	"fmt"
+	"testing"
+
+	"github.com/segmentio/parquet-go"
+
+	pc "github.com/featureform/provider/provider_config"
+)
+
+func newLocalTestFileStore(t *testing.T) FileStore {
+	config := pc.LocalFileStoreConfig{DirPath: fmt.Sprintf("file:///%s", t.TempDir())}
+	serialized, err := config.Serialize()
+	if err != nil {
+		t.Fatalf("Failed to serialize file store config: %s", err)
+	}
+	files, err := NewLocalFileStore(serialized)
+	if err != nil {
+		t.Fatalf("Failed to create file store: %s", err)
+	}
+	return files
+}
+
+func checkImportedValues(t *testing.T, table OnlineStoreTable, expected map[string]interface{}) {
+	for entity, value := range expected {
+		actual, err := table.Get(entity)
+		if err != nil {
+			t.Fatalf("Failed to get %s: %s", entity, err)
+		}
+		if actual != value {
+			t.Fatalf("Expected %v (%T) for %s, got %v (%T)", value, value, entity, actual, actual)
+		}
+	}
+}
+
+func TestImportOnlineTableCSV(t *testing.T) {
+	files := newLocalTestFileStore(t)
+	data := "id,value,entity\n1,10,a\n2,20,b\n3,30,c\n4,40,a\n"
+	if err := files.Write("import.csv", []byte(data)); err != nil {
+		t.Fatalf("Failed to write file: %s", err)
+	}
+	table, err := NewLocalOnlineStore().CreateTable("feature", "variant", Int)
+	if err != nil {
+		t.Fatalf("Failed to create table: %s", err)
+	}
+	options := OnlineImportOptions{Format: CSV, ValueType: Int, BatchSize: 2}
+	written, err := ImportOnlineTable(table, files, "import.csv", options)
+	if err != nil {
+		t.Fatalf("Failed to import: %s", err)
+	}
+	// a's second value replaces its first in the second batch.
+	if written != 4 {
+		t.Fatalf("Expected 4 values written, got %d", written)
+	}
+	checkImportedValues(t, table, map[string]interface{}{"a": 40, "b": 20, "c": 30})
+}
+
+func TestImportOnlineTableExportRoundTrip(t *testing.T) {
+	files := newLocalTestFileStore(t)
+	source, err := NewLocalOnlineStore().CreateTable("feature", "variant", Float32)
+	if err != nil {
+		t.Fatalf("Failed to create table: %s", err)
+	}
+	expected := map[string]interface{}{"a": float32(1.5), "b": float32(-2)}
+	for entity, value := range expected {
+		if err := source.Set(entity, value); err != nil {
+			t.Fatalf("Failed to set %s: %s", entity, err)
+		}
+	}
+	for _, format := range []FileType{CSV, Parquet} {
+		t.Run(string(format), func(t *testing.T) {
+			key := fmt.Sprintf("export.%s", format)
+			if _, err := ExportOnlineTable(source, files, key, format); err != nil {
+				t.Fatalf("Failed to export: %s", err)
+			}
+			table, err := NewLocalOnlineStore().CreateTable("feature", "variant", Float32)
+			if err != nil {
+				t.Fatalf("Failed to create table: %s", err)
+			}
+			options := OnlineImportOptions{Format: format, ValueType: Float32}
+			if _, err := ImportOnlineTable(table, files, key, options); err != nil {
+				t.Fatalf("Failed to import: %s", err)
+			}
+			checkImportedValues(t, table, expected)
+		})
+	}
+}
+
+func TestImportOnlineTableTypedParquet(t *testing.T) {
+	type row struct {
+		Entity string `parquet:"entity"`
+		Value  int64  `parquet:"value"`
+	}
+	buf := new(bytes.Buffer)
+	if err := parquet.Write(buf, []row{{"a", 1}, {"b", 2}}); err != nil {
+		t.Fatalf("Failed to write parquet: %s", err)
+	}
+	files := newLocalTestFileStore(t)
+	if err := files.Write("import.parquet", buf.Bytes()); err != nil {
+		t.Fatalf("Failed to write file: %s", err)
+	}
+	table, err := NewLocalOnlineStore().CreateTable("feature", "variant", Int)
+	if err != nil {
+		t.Fatalf("Failed to create table: %s", err)
+	}
+	written, err := ImportOnlineTable(table, files, "import.parquet", OnlineImportOptions{Format: Parquet, ValueType: Int})
+	if err != nil {
+		t.Fatalf("Failed to import: %s", err)
+	}
+	if written != 2 {
+		t.Fatalf("Expected 2 values written, got %d", written)
+	}
+	checkImportedValues(t, table, map[string]interface{}{"a": 1, "b": 2})
+}
+
+func TestImportOnlineTableErrors(t *testing.T) {
+	files := newLocalTestFileStore(t)
+	if err := files.Write("no_value.csv", []byte("entity,feature\na,1\n")); err != nil {
+		t.Fatalf("Failed to write file: %s", err)
+	}
+	if err := files.Write("bad_value.csv", []byte("entity,value\na,1\nb,one\n")); err != nil {
+		t.Fatalf("Failed to write file: %s", err)
+	}
+	tests := []struct {
+		name    string
+		key     string
+		options OnlineImportOptions
+	}{
+		{"Missing Column", "no_value.csv", OnlineImportOptions{Format: CSV, ValueType: Int}},
+		{"Invalid Value", "bad_value.csv", OnlineImportOptions{Format: CSV, ValueType: Int}},
+		{"Unknown Format", "bad_value.csv", OnlineImportOptions{Format: "json", ValueType: Int}},
+		{"Negative Batch Size", "bad_value.csv", OnlineImportOptions{Format: CSV, ValueType: Int, BatchSize: -1}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			table, err := NewLocalOnlineStore().CreateTable("feature", "variant", Int)
+			if err != nil {
+				t.Fatalf("Failed to create table: %s", err)
+			}
+			if _, err := ImportOnlineTable(table, files, tt.key, tt.options); err == nil {
+				t.Fatalf("Expected import to fail")
+			}
+		})
+	}
+}
@@ -0,0 +1,135 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package provider
+
+import (
+	"fmt"
+)
+
+const defaultMigrationBatchSize = 1000
+
+// OnlineMigrationProgress is reported while an online store is migrated.
+type OnlineMigrationProgress struct {
+	Feature, Variant string
+	// Entities is the number of values copied so far from the current table.
+	Entities int64
+	// TableDone is set once every value in the current table is copied.
+	TableDone bool
+	// TablesCopied is the number of tables fully copied, out of Tables.
+	TablesCopied, Tables int
+}
+
+type OnlineMigrationOptions struct {
+	// BatchSize is the number of values written to the destination at a
+	// time. It defaults to 1000.
+	BatchSize int
+	// Progress is called after each batch is written, if it's set.
+	Progress func(OnlineMigrationProgress)
+}
+
+// MigrateOnlineStore copies every table in source, and all of its values, to
+// destination. Tables that already exist in the destination have their
+// values overwritten, so a failed migration can be run again.
+//
+// Values written to source during the migration may not be copied. Writes
+// should be paused, or sent to both stores with a dual-write provider, until
+// the migration has finished.
+func MigrateOnlineStore(source TableListingStore, destination OnlineStore, options OnlineMigrationOptions) error {
+	if options.BatchSize == 0 {
+		options.BatchSize = defaultMigrationBatchSize
+	} else if options.BatchSize < 0 {
+		return fmt.Errorf("batch size must be positive: %d", options.BatchSize)
+	}
+	if !source.Capabilities().Scan {
+		return fmt.Errorf("%T does not support scans", source)
+	}
+	tables, err := source.ListTables()
+	if err != nil {
+		return fmt.Errorf("could not list tables: %w", err)
+	}
+	for i, info := range tables {
+		progress := OnlineMigrationProgress{
+			Feature:      info.Feature,
+			Variant:      info.Variant,
+			TablesCopied: i,
+			Tables:       len(tables),
+		}
+		if err := migrateOnlineTable(source, destination, info, options, progress); err != nil {
+			return fmt.Errorf("could not migrate table %s %s: %w", info.Feature, info.Variant, err)
+		}
+	}
+	return nil
+}
+
+func migrateOnlineTable(source, destination OnlineStore, info OnlineTableInfo, options OnlineMigrationOptions, progress OnlineMigrationProgress) error {
+	table, err := source.GetTable(info.Feature, info.Variant)
+	if err != nil {
+		return err
+	}
+	scannable, ok := table.(ScannableTable)
+	if !ok {
+		return fmt.Errorf("%T does not support scans", table)
+	}
+	destTable, err := createMigrationTable(destination, info)
+	if err != nil {
+		return err
+	}
+	it, err := scannable.Scan()
+	if err != nil {
+		return err
+	}
+	defer it.Close()
+	report := func() {
+		if options.Progress != nil {
+			options.Progress(progress)
+		}
+	}
+	batch := make(map[string]interface{}, options.BatchSize)
+	for it.Next() {
+		batch[it.Value().Entity] = it.Value().Value
+		if len(batch) < options.BatchSize {
+			continue
+		}
+		if err := setBatch(destTable, batch); err != nil {
+			return err
+		}
+		progress.Entities += int64(len(batch))
+		batch = make(map[string]interface{}, options.BatchSize)
+		report()
+	}
+	if err := it.Err(); err != nil {
+		return err
+	}
+	if len(batch) > 0 {
+		if err := setBatch(destTable, batch); err != nil {
+			return err
+		}
+		progress.Entities += int64(len(batch))
+	}
+	progress.TableDone = true
+	progress.TablesCopied++
+	report()
+	return nil
+}
+
+// createMigrationTable creates the table in the destination, or gets it if
+// it already exists. Embedding tables get their index created first, as
+// they do when materialized.
+func createMigrationTable(destination OnlineStore, info OnlineTableInfo) (OnlineStoreTable, error) {
+	if vectorType, ok := info.ValueType.(VectorType); ok && vectorType.IsEmbedding {
+		vectorStore, ok := destination.(VectorStore)
+		if !ok {
+			return nil, fmt.Errorf("cannot create index on non-vector store: %T", destination)
+		}
+		if _, err := vectorStore.CreateIndex(info.Feature, info.Variant, vectorType); err != nil {
+			return nil, fmt.Errorf("create index error: %w", err)
+		}
+	}
+	table, err := destination.CreateTable(info.Feature, info.Variant, info.ValueType)
+	if _, exists := err.(*TableAlreadyExists); exists {
+		return destination.GetTable(info.Feature, info.Variant)
+	}
+	return table, err
+}
@@ -0,0 +1,102 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package provider
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestMigrateOnlineStore(t *testing.T) {
+	source := NewLocalOnlineStore()
+	ints, err := source.CreateTable("ints", "v1", Int)
+	if err != nil {
+		t.Fatalf("Failed to create table: %s", err)
+	}
+	for i := 0; i < 25; i++ {
+		if err := ints.Set(fmt.Sprintf("entity%d", i), i); err != nil {
+			t.Fatalf("Failed to set value: %s", err)
+		}
+	}
+	strs, err := source.CreateTable("strings", "v1", String)
+	if err != nil {
+		t.Fatalf("Failed to create table: %s", err)
+	}
+	if err := strs.Set("a", "new"); err != nil {
+		t.Fatalf("Failed to set value: %s", err)
+	}
+
+	destination := NewLocalOnlineStore()
+	// Tables that already exist in the destination are written into.
+	existing, err := destination.CreateTable("strings", "v1", String)
+	if err != nil {
+		t.Fatalf("Failed to create table: %s", err)
+	}
+	if err := existing.Set("a", "old"); err != nil {
+		t.Fatalf("Failed to set value: %s", err)
+	}
+
+	var progress []OnlineMigrationProgress
+	options := OnlineMigrationOptions{
+		BatchSize: 10,
+		Progress: func(p OnlineMigrationProgress) {
+			progress = append(progress, p)
+		},
+	}
+	if err := MigrateOnlineStore(source, destination, options); err != nil {
+		t.Fatalf("Failed to migrate: %s", err)
+	}
+	table, err := destination.GetTable("ints", "v1")
+	if err != nil {
+		t.Fatalf("Failed to get migrated table: %s", err)
+	}
+	for i := 0; i < 25; i++ {
+		if value, err := table.Get(fmt.Sprintf("entity%d", i)); err != nil || value != i {
+			t.Fatalf("Expected entity%d to be %d, got %v: %v", i, i, value, err)
+		}
+	}
+	if value, err := existing.Get("a"); err != nil || value != "new" {
+		t.Fatalf("Expected existing value to be overwritten, got %v: %v", value, err)
+	}
+
+	expected := []OnlineMigrationProgress{
+		{Feature: "ints", Variant: "v1", Entities: 10, Tables: 2},
+		{Feature: "ints", Variant: "v1", Entities: 20, Tables: 2},
+		{Feature: "ints", Variant: "v1", Entities: 25, TableDone: true, TablesCopied: 1, Tables: 2},
+		{Feature: "strings", Variant: "v1", Entities: 1, TableDone: true, TablesCopied: 2, Tables: 2},
+	}
+	if len(progress) != len(expected) {
+		t.Fatalf("Expected progress %v, got %v", expected, progress)
+	}
+	for i := range expected {
+		if progress[i] != expected[i] {
+			t.Fatalf("Expected progress %v, got %v", expected, progress)
+		}
+	}
+}
+
+type unscannableTable struct {
+	OnlineStoreTable
+}
+
+type unscannableStore struct {
+	*localOnlineStore
+}
+
+func (store unscannableStore) GetTable(feature, variant string) (OnlineStoreTable, error) {
+	table, err := store.localOnlineStore.GetTable(feature, variant)
+	return unscannableTable{table}, err
+}
+
+func TestMigrateOnlineStoreRequiresScans(t *testing.T) {
+	source := unscannableStore{NewLocalOnlineStore()}
+	if _, err := source.CreateTable("feature", "variant", String); err != nil {
+		t.Fatalf("Failed to create table: %s", err)
+	}
+	destination := NewLocalOnlineStore()
+	if err := MigrateOnlineStore(source, destination, OnlineMigrationOptions{}); err == nil {
+		t.Fatalf("Expected migration of unscannable table to fail")
+	}
+}
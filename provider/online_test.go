@@ -18,6 +18,7 @@ import (
 	"strconv"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/featureform/helpers"
 
@@ -62,9 +63,14 @@ func TestOnlineStores(t *testing.T) {
 		"TableAlreadyExists": testTableAlreadyExists,
 		"TableNotFound":      testTableNotFound,
 		"SetGetEntity":       testSetGetEntity,
+		"SetBatch":           testSetBatch,
 		"EntityNotFound":     testEntityNotFound,
 		"MassTableWrite":     testMassTableWrite,
 		"TypeCasting":        testTypeCasting,
+		"Increment":          testIncrement,
+		"Append":             testAppend,
+		"WriteTimestamps":    testWriteTimestamps,
+		"Truncate":           testTruncate,
 	}
 
 	// Redis (Mock)
@@ -172,6 +178,16 @@ func TestOnlineStores(t *testing.T) {
 		return *mongoConfig
 	}
 
+	pgvectorInit := func() pc.PgvectorConfig {
+		return pc.PgvectorConfig{
+			Host:     helpers.GetEnv("PGVECTOR_HOST", "localhost"),
+			Port:     helpers.GetEnv("PGVECTOR_PORT", "5432"),
+			Username: helpers.GetEnv("PGVECTOR_USER", ""),
+			Password: helpers.GetEnv("PGVECTOR_PASSWORD", ""),
+			Database: helpers.GetEnv("PGVECTOR_DB", ""),
+		}
+	}
+
 	testList := []testMember{}
 
 	if *provider == "memory" || *provider == "" {
@@ -203,6 +219,9 @@ func TestOnlineStores(t *testing.T) {
 	if *provider == "mongodb" || *provider == "" {
 		testList = append(testList, testMember{pt.MongoDBOnline, "", mongoDBInit().Serialized(), true})
 	}
+	if *provider == "pgvector" || *provider == "" {
+		testList = append(testList, testMember{pt.PgvectorOnline, "", pgvectorInit().Serialized(), true})
+	}
 
 	for _, testItem := range testList {
 		if testing.Short() && testItem.integrationTest {
@@ -297,6 +316,48 @@ func testSetGetEntity(t *testing.T, store OnlineStore) {
 	}
 }
 
+func testSetBatch(t *testing.T, store OnlineStore) {
+	mockFeature, mockVariant := randomFeatureVariant()
+	defer store.DeleteTable(mockFeature, mockVariant)
+	tab, err := store.CreateTable(mockFeature, mockVariant, Int)
+	if err != nil {
+		t.Fatalf("Failed to create table: %s", err)
+	}
+	batchTable, ok := tab.(BatchSettableTable)
+	if !ok {
+		if store.Capabilities().BatchWrites {
+			t.Fatalf("%T reports batch writes but does not implement SetBatch", tab)
+		}
+		t.Skipf("%T does not support batch writes", tab)
+	}
+	values := map[string]interface{}{"a": 1, "b": 2, "c": 3}
+	before := time.Now()
+	if err := batchTable.SetBatch(values); err != nil {
+		t.Fatalf("Failed to set batch: %s", err)
+	}
+	after := time.Now()
+	for entity, val := range values {
+		gotVal, err := tab.Get(entity)
+		if err != nil {
+			t.Fatalf("Failed to get entity %s: %s", entity, err)
+		}
+		if !reflect.DeepEqual(val, gotVal) {
+			t.Fatalf("Values are not the same %v %v", val, gotVal)
+		}
+		timestamped, ok := tab.(TimestampedTable)
+		if !ok {
+			continue
+		}
+		_, updated, err := timestamped.GetWithTimestamp(entity)
+		if err != nil {
+			t.Fatalf("Failed to get entity %s with timestamp: %s", entity, err)
+		}
+		if updated.Before(before) || updated.After(after) {
+			t.Fatalf("Write time %v not between %v and %v", updated, before, after)
+		}
+	}
+}
+
 func testEntityNotFound(t *testing.T, store OnlineStore) {
 	mockFeature, mockVariant := uuid.NewString(), "v"
 	entity := "e"
@@ -385,6 +446,36 @@ func testTypeCasting(t *testing.T, store OnlineStore) {
 			Value:  false,
 			Type:   Bool,
 		},
+		{
+			Entity: "ts",
+			Value:  time.Date(2023, 1, 2, 3, 4, 5, 0, time.UTC),
+			Type:   Timestamp,
+		},
+		{
+			Entity: "g",
+			Value:  []int64{1, -2, 3},
+			Type:   ListType{ElementType: Int64},
+		},
+		{
+			Entity: "h",
+			Value:  []string{"a", "b,c", ""},
+			Type:   ListType{ElementType: String},
+		},
+		{
+			Entity: "i",
+			Value:  []float64{1.5, -0.25},
+			Type:   ListType{ElementType: Float64},
+		},
+		{
+			Entity: "j",
+			Value:  map[string]float64{"books": 0.5, "games": -1.25},
+			Type:   MapType{KeyType: String, ElementType: Float64},
+		},
+		{
+			Entity: "k",
+			Value:  map[string]string{"color": "red", "size": ""},
+			Type:   MapType{KeyType: String, ElementType: String},
+		},
 	}
 	for _, resource := range onlineResources {
 		featureName := uuid.New().String()
@@ -406,6 +497,172 @@ func testTypeCasting(t *testing.T, store OnlineStore) {
 	}
 }
 
+func testIncrement(t *testing.T, store OnlineStore) {
+	type incrementCase struct {
+		Type     ValueType
+		Deltas   []interface{}
+		Expected interface{}
+	}
+	cases := []incrementCase{
+		{Int, []interface{}{1, 2, 3}, int(6)},
+		{Int32, []interface{}{int32(5), -2}, int32(3)},
+		{Int64, []interface{}{int64(10), int64(-4)}, int64(6)},
+		{Float32, []interface{}{float32(0.5), 1}, float32(1.5)},
+		{Float64, []interface{}{0.25, 0.5}, float64(0.75)},
+	}
+	for _, c := range cases {
+		featureName := uuid.New().String()
+		tab, err := store.CreateTable(featureName, "", c.Type)
+		if err != nil {
+			t.Fatalf("Failed to create table: %s", err)
+		}
+		incrementable, ok := tab.(IncrementableTable)
+		if !ok {
+			store.DeleteTable(featureName, "")
+			t.Skipf("%T does not support increments", tab)
+		}
+		var got interface{}
+		for _, delta := range c.Deltas {
+			if got, err = incrementable.Increment("entity", delta); err != nil {
+				t.Fatalf("Failed to increment entity: %s", err)
+			}
+		}
+		if !reflect.DeepEqual(c.Expected, got) {
+			t.Fatalf("Increment returned %v, type %T. Expected %v, type %T", got, got, c.Expected, c.Expected)
+		}
+		gotVal, err := tab.Get("entity")
+		if err != nil {
+			t.Fatalf("Failed to get entity: %s", err)
+		}
+		if !reflect.DeepEqual(c.Expected, gotVal) {
+			t.Fatalf("Values are not the same %v, type %T. %v, type %T", c.Expected, c.Expected, gotVal, gotVal)
+		}
+		store.DeleteTable(featureName, "")
+	}
+	stringFeature := uuid.New().String()
+	defer store.DeleteTable(stringFeature, "")
+	tab, err := store.CreateTable(stringFeature, "", String)
+	if err != nil {
+		t.Fatalf("Failed to create table: %s", err)
+	}
+	if _, err := tab.(IncrementableTable).Increment("entity", 1); err == nil {
+		t.Fatalf("Succeeded in incrementing a string table")
+	}
+}
+
+func testWriteTimestamps(t *testing.T, store OnlineStore) {
+	featureName := uuid.New().String()
+	defer store.DeleteTable(featureName, "")
+	tab, err := store.CreateTable(featureName, "", String)
+	if err != nil {
+		t.Fatalf("Failed to create table: %s", err)
+	}
+	timestamped, ok := tab.(TimestampedTable)
+	if !ok {
+		t.Skipf("%T does not record write timestamps", tab)
+	}
+	if _, _, err := timestamped.GetWithTimestamp("entity"); err == nil {
+		t.Fatalf("Succeeded in getting non-existent entity")
+	} else if _, valid := err.(*EntityNotFound); !valid {
+		t.Fatalf("Wrong error for entity not found: %T", err)
+	}
+	before := time.Now()
+	if err := tab.Set("entity", "value"); err != nil {
+		t.Fatalf("Failed to set entity: %s", err)
+	}
+	after := time.Now()
+	val, updated, err := timestamped.GetWithTimestamp("entity")
+	if err != nil {
+		t.Fatalf("Failed to get entity: %s", err)
+	}
+	if val != "value" {
+		t.Fatalf("Values are not the same %v, %v", val, "value")
+	}
+	if updated.Before(before) || updated.After(after) {
+		t.Fatalf("Write time %v not between %v and %v", updated, before, after)
+	}
+}
+
+func testAppend(t *testing.T, store OnlineStore) {
+	mockFeature, mockVariant := randomFeatureVariant()
+	defer store.DeleteTable(mockFeature, mockVariant)
+	tab, err := store.CreateTable(mockFeature, mockVariant, Int)
+	if err != nil {
+		t.Fatalf("Failed to create table: %s", err)
+	}
+	appendable, ok := tab.(AppendableTable)
+	if !ok {
+		t.Skipf("%T does not support appends", tab)
+	}
+	if _, err := appendable.GetList("e"); err == nil {
+		t.Fatalf("Succeeded in getting non-existent list")
+	} else if _, valid := err.(*EntityNotFound); !valid {
+		t.Fatalf("Wrong error for list not found: %T", err)
+	}
+	for i := 1; i <= 5; i++ {
+		if err := appendable.Append("e", i, 3); err != nil {
+			t.Fatalf("Failed to append: %s", err)
+		}
+	}
+	list, err := appendable.GetList("e")
+	if err != nil {
+		t.Fatalf("Failed to get list: %s", err)
+	}
+	expected := []interface{}{5, 4, 3}
+	if !reflect.DeepEqual(expected, list) {
+		t.Fatalf("Lists are not the same %v %v", expected, list)
+	}
+	if err := appendable.Append("e", 6, 0); err == nil {
+		t.Fatalf("Succeeded in appending with a non-positive max length")
+	}
+}
+
+func testTruncate(t *testing.T, store OnlineStore) {
+	truncatable, ok := store.(TruncatableStore)
+	if !ok {
+		t.Skipf("%T does not support truncating", store)
+	}
+	mockFeature, mockVariant := randomFeatureVariant()
+	defer store.DeleteTable(mockFeature, mockVariant)
+	if err := truncatable.Truncate(mockFeature, mockVariant); err == nil {
+		t.Fatalf("Succeeded in truncating non-existent table")
+	} else if _, valid := err.(*TableNotFound); !valid {
+		t.Fatalf("Wrong error for table not found: %T", err)
+	}
+	tab, err := store.CreateTable(mockFeature, mockVariant, Int)
+	if err != nil {
+		t.Fatalf("Failed to create table: %s", err)
+	}
+	if err := tab.Set("a", 1); err != nil {
+		t.Fatalf("Failed to set entity: %s", err)
+	}
+	if appendable, ok := tab.(AppendableTable); ok {
+		if err := appendable.Append("a", 1, 3); err != nil {
+			t.Fatalf("Failed to append: %s", err)
+		}
+	}
+	if err := truncatable.Truncate(mockFeature, mockVariant); err != nil {
+		t.Fatalf("Failed to truncate table: %s", err)
+	}
+	tab, err = store.GetTable(mockFeature, mockVariant)
+	if err != nil {
+		t.Fatalf("Failed to get truncated table: %s", err)
+	}
+	if _, err := tab.Get("a"); err == nil {
+		t.Fatalf("Succeeded in getting truncated entity")
+	} else if _, valid := err.(*EntityNotFound); !valid {
+		t.Fatalf("Wrong error for truncated entity: %T", err)
+	}
+	if appendable, ok := tab.(AppendableTable); ok {
+		if _, err := appendable.GetList("a"); err == nil {
+			t.Fatalf("Succeeded in getting truncated list")
+		}
+	}
+	if err := tab.Set("b", 2); err != nil {
+		t.Fatalf("Failed to set entity after truncating: %s", err)
+	}
+}
+
 func TestFirestoreConfig_Deserialize(t *testing.T) {
 	content, err := ioutil.ReadFile("connection/connection_configs.json")
 	if err != nil {
@@ -478,6 +735,15 @@ func TestOnlineVectorStores(t *testing.T) {
 		"CreateIndex":              testCreateIndex,
 		"GetSet":                   testGetSet,
 		"Nearest":                  testNearest,
+		"FilteredNearest":          testFilteredNearest,
+		"DeleteVector":             testDeleteVector,
+		"NearestPage":              testNearestPage,
+		"HybridNearest":            testHybridNearest,
+		"NamespacedNearest":        testNamespacedNearest,
+		"NearestByEntity":          testNearestByEntity,
+		"IndexStats":               testIndexStats,
+		"WatchIndexBuild":          testWatchIndexBuild,
+		"DimensionMismatch":        testDimensionMismatch,
 	}
 
 	// RediSearch (hosted)
@@ -492,9 +758,65 @@ func TestOnlineVectorStores(t *testing.T) {
 
 	testList := []testMember{}
 
+	weaviateInit := func() pc.WeaviateConfig {
+		return pc.WeaviateConfig{
+			URL:    os.Getenv("WEAVIATE_URL"),
+			APIKey: os.Getenv("WEAVIATE_API_KEY"),
+		}
+	}
+
+	pgvectorInit := func() pc.PgvectorConfig {
+		return pc.PgvectorConfig{
+			Host:     helpers.GetEnv("PGVECTOR_HOST", "localhost"),
+			Port:     helpers.GetEnv("PGVECTOR_PORT", "5432"),
+			Username: helpers.GetEnv("PGVECTOR_USER", ""),
+			Password: helpers.GetEnv("PGVECTOR_PASSWORD", ""),
+			Database: helpers.GetEnv("PGVECTOR_DB", ""),
+		}
+	}
+
+	openSearchInit := func() pc.OpenSearchConfig {
+		return pc.OpenSearchConfig{
+			Engine:         pc.SearchEngine(helpers.GetEnv("OPENSEARCH_ENGINE", string(pc.OpenSearch))),
+			URL:            os.Getenv("OPENSEARCH_URL"),
+			Username:       os.Getenv("OPENSEARCH_USERNAME"),
+			Password:       os.Getenv("OPENSEARCH_PASSWORD"),
+			WaitForRefresh: true,
+		}
+	}
+
+	chromaInit := func() pc.ChromaConfig {
+		return pc.ChromaConfig{
+			URL:    helpers.GetEnv("CHROMA_URL", "http://localhost:8000"),
+			APIKey: os.Getenv("CHROMA_API_KEY"),
+		}
+	}
+
+	vespaInit := func() pc.VespaConfig {
+		return pc.VespaConfig{
+			URL:       helpers.GetEnv("VESPA_URL", "http://localhost:8080"),
+			ConfigURL: helpers.GetEnv("VESPA_CONFIG_URL", "http://localhost:19071"),
+		}
+	}
+
 	if *provider == "redis_vector" || *provider == "" {
 		testList = append(testList, testMember{pt.RedisOnline, "_VECTOR", redisInsecureInit().Serialized(), true})
 	}
+	if *provider == "weaviate" || *provider == "" {
+		testList = append(testList, testMember{pt.WeaviateOnline, "", weaviateInit().Serialized(), true})
+	}
+	if *provider == "pgvector" || *provider == "" {
+		testList = append(testList, testMember{pt.PgvectorOnline, "", pgvectorInit().Serialized(), true})
+	}
+	if *provider == "opensearch" || *provider == "" {
+		testList = append(testList, testMember{pt.OpenSearchOnline, "", openSearchInit().Serialized(), true})
+	}
+	if *provider == "chroma" || *provider == "" {
+		testList = append(testList, testMember{pt.ChromaOnline, "", chromaInit().Serialized(), true})
+	}
+	if *provider == "vespa" || *provider == "" {
+		testList = append(testList, testMember{pt.VespaOnline, "", vespaInit().Serialized(), true})
+	}
 
 	for _, testItem := range testList {
 		if testing.Short() && testItem.integrationTest {
@@ -626,13 +948,321 @@ func testNearest(t *testing.T, store OnlineStore) {
 		}
 	}
 	searchVector := getSearchVector(t)
-	results, err := vectorTable.Nearest(mockFeature, mockVariant, searchVector, 2)
+	results, err := vectorTable.Nearest(mockFeature, mockVariant, searchVector, 2, VectorFilter{})
 	if err != nil {
 		t.Fatalf("Unexpected error: %v", err)
 	}
 	if len(results) != 2 {
 		t.Fatalf("Expected 2 results but received %d", len(results))
 	}
+	if results[0].Distance > results[1].Distance {
+		t.Fatalf("Expected results nearest first, got %v", results)
+	}
+}
+
+func testNearestPage(t *testing.T, store OnlineStore) {
+	mockFeature, mockVariant := randomFeatureVariant()
+	vectorType := VectorType{ScalarType: Float32, Dimension: 768, IsEmbedding: true}
+	vectorTable, err := store.(VectorStore).CreateIndex(mockFeature, mockVariant, vectorType)
+	if err != nil {
+		t.Fatalf("Failed to create index: %s", err)
+	}
+	for _, entity := range getTestVectorEntities(t) {
+		if err := vectorTable.Set(entity.entity, entity.vector); err != nil {
+			t.Fatalf("Failed to set vector: %s", err)
+		}
+	}
+	searchVector := getSearchVector(t)
+	first, err := vectorTable.NearestPage(mockFeature, mockVariant, searchVector, 2, VectorFilter{}, "")
+	if err != nil {
+		t.Fatalf("Failed to get first page: %s", err)
+	}
+	if len(first.Results) != 2 || first.Cursor == "" {
+		t.Fatalf("Expected a full first page with a cursor, got %+v", first)
+	}
+	second, err := vectorTable.NearestPage(mockFeature, mockVariant, searchVector, 2, VectorFilter{}, first.Cursor)
+	if err != nil {
+		t.Fatalf("Failed to get second page: %s", err)
+	}
+	for _, result := range second.Results {
+		if result.Entity == first.Results[0].Entity || result.Entity == first.Results[1].Entity {
+			t.Fatalf("Entity %s returned on both pages", result.Entity)
+		}
+	}
+}
+
+func testHybridNearest(t *testing.T, store OnlineStore) {
+	mockFeature, mockVariant := randomFeatureVariant()
+	vectorType := VectorType{ScalarType: Float32, Dimension: 768, IsEmbedding: true}
+	vectorTable, err := store.(VectorStore).CreateIndex(mockFeature, mockVariant, vectorType)
+	if err != nil {
+		t.Fatalf("Failed to create index: %s", err)
+	}
+	hybridTable, isHybrid := vectorTable.(HybridSearchTable)
+	if !isHybrid {
+		t.Skipf("%T does not support hybrid search", vectorTable)
+	}
+	entities := getTestVectorEntities(t)
+	for i, entity := range entities {
+		text := "other"
+		if i%2 == 0 {
+			text = "matching document"
+		}
+		if err := hybridTable.SetWithText(entity.entity, entity.vector, text); err != nil {
+			t.Fatalf("Failed to set vector: %s", err)
+		}
+	}
+	results, err := hybridTable.HybridNearest(mockFeature, mockVariant, getSearchVector(t), int32(len(entities)), "matching")
+	if err != nil {
+		t.Fatalf("Failed to search: %s", err)
+	}
+	if expected := (len(entities) + 1) / 2; len(results) != expected {
+		t.Fatalf("Expected %d matching entities, got %v", expected, results)
+	}
+	for i := 1; i < len(results); i++ {
+		if results[i].Distance < results[i-1].Distance {
+			t.Fatalf("Expected results ordered nearest first, got %v", results)
+		}
+	}
+}
+
+func testNamespacedNearest(t *testing.T, store OnlineStore) {
+	mockFeature, mockVariant := randomFeatureVariant()
+	vectorType := VectorType{ScalarType: Float32, Dimension: 768, IsEmbedding: true}
+	vectorTable, err := store.(VectorStore).CreateIndex(mockFeature, mockVariant, vectorType)
+	if err != nil {
+		t.Fatalf("Failed to create index: %s", err)
+	}
+	namespacedTable, isNamespaced := vectorTable.(NamespacedVectorTable)
+	if !isNamespaced {
+		t.Skipf("%T does not support namespaces", vectorTable)
+	}
+	entities := getTestVectorEntities(t)
+	for i, entity := range entities {
+		namespace := "tenant_a"
+		if i%2 == 1 {
+			namespace = "tenant_b"
+		}
+		if err := namespacedTable.SetInNamespace(namespace, entity.entity, entity.vector); err != nil {
+			t.Fatalf("Failed to set vector: %s", err)
+		}
+	}
+	results, err := namespacedTable.Nearest(mockFeature, mockVariant, getSearchVector(t), int32(len(entities)), VectorFilter{Namespace: "tenant_a"})
+	if err != nil {
+		t.Fatalf("Failed to search: %s", err)
+	}
+	if expected := (len(entities) + 1) / 2; len(results) != expected {
+		t.Fatalf("Expected %d entities in namespace, got %v", expected, results)
+	}
+	if err := namespacedTable.Set(entities[0].entity, entities[0].vector); err != nil {
+		t.Fatalf("Failed to set vector: %s", err)
+	}
+	results, err = namespacedTable.Nearest(mockFeature, mockVariant, getSearchVector(t), int32(len(entities)), VectorFilter{Namespace: "tenant_a"})
+	if err != nil {
+		t.Fatalf("Failed to search: %s", err)
+	}
+	for _, result := range results {
+		if result.Entity == entities[0].entity {
+			t.Fatalf("Expected entity written without a namespace to leave it, got %v", results)
+		}
+	}
+	if err := namespacedTable.SetInNamespace("", entities[0].entity, entities[0].vector); err == nil {
+		t.Fatalf("Succeeded in writing to an empty namespace")
+	}
+}
+
+func testNearestByEntity(t *testing.T, store OnlineStore) {
+	mockFeature, mockVariant := randomFeatureVariant()
+	vectorType := VectorType{ScalarType: Float32, Dimension: 768, IsEmbedding: true}
+	vectorTable, err := store.(VectorStore).CreateIndex(mockFeature, mockVariant, vectorType)
+	if err != nil {
+		t.Fatalf("Failed to create index: %s", err)
+	}
+	entities := getTestVectorEntities(t)
+	for _, entity := range entities {
+		if err := vectorTable.Set(entity.entity, entity.vector); err != nil {
+			t.Fatalf("Failed to set vector: %s", err)
+		}
+	}
+	target := entities[0].entity
+	results, err := NearestByEntity(vectorTable, mockFeature, mockVariant, target, 2)
+	if err != nil {
+		t.Fatalf("Failed to search by entity: %s", err)
+	}
+	if len(results) != 2 {
+		t.Fatalf("Expected 2 neighbors, got %v", results)
+	}
+	for _, result := range results {
+		if result.Entity == target {
+			t.Fatalf("Expected %s to be excluded from its neighbors, got %v", target, results)
+		}
+	}
+	if _, err := NearestByEntity(vectorTable, mockFeature, mockVariant, "missing", 2); err == nil {
+		t.Fatalf("Succeeded in searching by a missing entity")
+	}
+}
+
+func testIndexStats(t *testing.T, store OnlineStore) {
+	mockFeature, mockVariant := randomFeatureVariant()
+	vectorType := VectorType{ScalarType: Float32, Dimension: 768, IsEmbedding: true}
+	vectorTable, err := store.(VectorStore).CreateIndex(mockFeature, mockVariant, vectorType)
+	if err != nil {
+		t.Fatalf("Failed to create index: %s", err)
+	}
+	statsTable, hasStats := vectorTable.(IndexStatsTable)
+	if !hasStats {
+		t.Skipf("%T does not report index stats", vectorTable)
+	}
+	entities := getTestVectorEntities(t)
+	for _, entity := range entities {
+		if err := vectorTable.Set(entity.entity, entity.vector); err != nil {
+			t.Fatalf("Failed to set vector: %s", err)
+		}
+	}
+	stats, err := statsTable.IndexStats()
+	if err != nil {
+		t.Fatalf("Failed to get index stats: %s", err)
+	}
+	if stats.Vectors != int64(len(entities)) || stats.Dimension != vectorType.Dimension {
+		t.Fatalf("Expected %d vectors of dimension %d, got %+v", len(entities), vectorType.Dimension, stats)
+	}
+}
+
+func testWatchIndexBuild(t *testing.T, store OnlineStore) {
+	building, isBuilding := store.(IndexBuildingStore)
+	if !isBuilding {
+		t.Skipf("%T does not build indexes in the background", store)
+	}
+	mockFeature, mockVariant := randomFeatureVariant()
+	vectorType := VectorType{ScalarType: Float32, Dimension: 768, IsEmbedding: true}
+	vectorTable, err := building.CreateIndex(mockFeature, mockVariant, vectorType)
+	if err != nil {
+		t.Fatalf("Failed to create index: %s", err)
+	}
+	entities := getTestVectorEntities(t)
+	for _, entity := range entities {
+		if err := vectorTable.Set(entity.entity, entity.vector); err != nil {
+			t.Fatalf("Failed to set vector: %s", err)
+		}
+	}
+	watcher, err := building.WatchIndexBuild(mockFeature, mockVariant)
+	if err != nil {
+		t.Fatalf("Failed to watch index build: %s", err)
+	}
+	if err := watcher.Wait(); err != nil {
+		t.Fatalf("Failed to wait for index build: %s", err)
+	}
+	results, err := vectorTable.Nearest(mockFeature, mockVariant, getSearchVector(t), int32(len(entities)), VectorFilter{})
+	if err != nil {
+		t.Fatalf("Failed to search: %s", err)
+	}
+	if len(results) != len(entities) {
+		t.Fatalf("Expected every vector to be indexed, got %v", results)
+	}
+}
+
+func testDimensionMismatch(t *testing.T, store OnlineStore) {
+	mockFeature, mockVariant := randomFeatureVariant()
+	vectorType := VectorType{ScalarType: Float32, Dimension: 768, IsEmbedding: true}
+	vectorTable, err := store.(VectorStore).CreateIndex(mockFeature, mockVariant, vectorType)
+	if err != nil {
+		t.Fatalf("Failed to create index: %s", err)
+	}
+	err = vectorTable.Set("a", make([]float32, 767))
+	if _, isMismatch := err.(*DimensionMismatch); !isMismatch {
+		t.Fatalf("Expected DimensionMismatch, got %T: %v", err, err)
+	}
+	if _, err := vectorTable.Get("a"); err == nil {
+		t.Fatalf("Expected vector of the wrong dimension not to be written")
+	}
+}
+
+func testDeleteVector(t *testing.T, store OnlineStore) {
+	mockFeature, mockVariant := randomFeatureVariant()
+	vectorType := VectorType{ScalarType: Float32, Dimension: 768, IsEmbedding: true}
+	if _, err := store.(VectorStore).CreateIndex(mockFeature, mockVariant, vectorType); err != nil {
+		t.Fatalf("Failed to create index: %s", err)
+	}
+	tbl, err := store.CreateTable(mockFeature, mockVariant, vectorType)
+	if err != nil {
+		t.Fatalf("Failed to create table: %s", err)
+	}
+	vectorTable := tbl.(VectorStoreTable)
+	entities := getTestVectorEntities(t)
+	for _, entity := range entities {
+		if err := vectorTable.Set(entity.entity, entity.vector); err != nil {
+			t.Fatalf("Failed to set vector: %s", err)
+		}
+	}
+	deleted := entities[0].entity
+	if err := vectorTable.Delete(deleted); err != nil {
+		t.Fatalf("Failed to delete vector: %s", err)
+	}
+	if _, err := vectorTable.Get(deleted); err == nil {
+		t.Fatalf("Expected deleted entity to be missing")
+	}
+	results, err := vectorTable.Nearest(mockFeature, mockVariant, entities[0].vector, int32(len(entities)), VectorFilter{})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	for _, result := range results {
+		if result.Entity == deleted {
+			t.Fatalf("Deleted entity %s returned by Nearest", deleted)
+		}
+	}
+	if err := store.DeleteTable(mockFeature, mockVariant); err != nil {
+		t.Fatalf("Failed to delete table: %s", err)
+	}
+	if _, err := store.GetTable(mockFeature, mockVariant); err == nil {
+		t.Fatalf("Expected table to be deleted")
+	}
+}
+
+func testFilteredNearest(t *testing.T, store OnlineStore) {
+	if !store.Capabilities().VectorFilters {
+		t.Skipf("%T does not support vector filters", store)
+	}
+	mockFeature, mockVariant := randomFeatureVariant()
+	vectorType := VectorType{ScalarType: Float32, Dimension: 768, IsEmbedding: true}
+	vTbl, err := store.(VectorStore).CreateIndex(mockFeature, mockVariant, vectorType)
+	if err != nil {
+		t.Fatalf("Failed to create index: %s", err)
+	}
+	table, ok := vTbl.(AttributedVectorTable)
+	if !ok {
+		t.Fatalf("Expected table to be AttributedVectorTable but received: %T", vTbl)
+	}
+	entities := getTestVectorEntities(t)
+	tagged := map[string]bool{}
+	for i, entity := range entities {
+		attributes := map[string]string{"parity": "odd"}
+		if i%2 == 0 {
+			attributes["parity"] = "even"
+			tagged[entity.entity] = true
+		}
+		if err := table.SetWithAttributes(entity.entity, entity.vector, attributes); err != nil {
+			t.Fatalf("Failed to set vector: %s", err)
+		}
+	}
+	results, err := table.Nearest(mockFeature, mockVariant, getSearchVector(t), 2, VectorFilter{Attributes: map[string]string{"parity": "even"}})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(results) == 0 {
+		t.Fatalf("Expected filtered results")
+	}
+	for _, result := range results {
+		if !tagged[result.Entity] {
+			t.Fatalf("Entity %s does not match the filter", result.Entity)
+		}
+	}
+	results, err = table.Nearest(mockFeature, mockVariant, getSearchVector(t), 2, VectorFilter{UpdatedAfter: time.Now().Add(time.Hour)})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(results) != 0 {
+		t.Fatalf("Expected no entities updated in the future, got %v", results)
+	}
 }
 
 type testEmbeddingRecord struct {
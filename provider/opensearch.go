@@ -0,0 +1,781 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package provider
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	pc "github.com/featureform/provider/provider_config"
+	pt "github.com/featureform/provider/provider_type"
+)
+
+const (
+	openSearchIndexPrefix     = "featureform__"
+	openSearchEntityField     = "entity"
+	openSearchValueField      = "value"
+	openSearchAttributesField = "attributes"
+	openSearchUpdatedAtField  = "updated_at"
+	openSearchNamespaceField  = "namespace"
+	openSearchBulkBatchSize   = 500
+	// openSearchMinCandidates is the fewest candidates Elasticsearch
+	// considers per shard, since recall is poor when it's close to k.
+	openSearchMinCandidates = 100
+)
+
+func openSearchOnlineStoreFactory(serialized pc.SerializedConfig) (Provider, error) {
+	config := &pc.OpenSearchConfig{}
+	if err := config.Deserialize(serialized); err != nil {
+		return nil, err
+	}
+	return NewOpenSearchOnlineStore(config)
+}
+
+// openSearchOnlineStore is a VectorStore with one OpenSearch or
+// Elasticsearch index per feature variant. Each entity is a document with
+// the entity as its ID, so writing an entity again replaces its vector.
+type openSearchOnlineStore struct {
+	client         *jsonHTTPClient
+	engine         pc.SearchEngine
+	waitForRefresh bool
+	BaseProvider
+}
+
+func NewOpenSearchOnlineStore(config *pc.OpenSearchConfig) (*openSearchOnlineStore, error) {
+	if config.URL == "" {
+		return nil, fmt.Errorf("opensearch URL is required")
+	}
+	engine := config.Engine
+	if engine == "" {
+		engine = pc.OpenSearch
+	}
+	if engine != pc.OpenSearch && engine != pc.Elasticsearch {
+		return nil, fmt.Errorf("unknown search engine %s", engine)
+	}
+	header := make(http.Header)
+	if config.APIKey != "" {
+		header.Set("Authorization", "ApiKey "+config.APIKey)
+	} else if config.Username != "" {
+		credentials := base64.StdEncoding.EncodeToString([]byte(config.Username + ":" + config.Password))
+		header.Set("Authorization", "Basic "+credentials)
+	}
+	return &openSearchOnlineStore{
+		client:         newJSONHTTPClient(config.URL, header, nil),
+		engine:         engine,
+		waitForRefresh: config.WaitForRefresh,
+		BaseProvider: BaseProvider{
+			ProviderType:   pt.OpenSearchOnline,
+			ProviderConfig: config.Serialized(),
+		},
+	}, nil
+}
+
+// openSearchIndexName returns the index of a feature variant. Index names
+// must be lowercase and can't contain many characters, so the feature and
+// variant are hashed and kept in the index's _meta instead.
+func openSearchIndexName(feature, variant string) string {
+	hash := sha256.Sum256([]byte(feature + "\x00" + variant))
+	return openSearchIndexPrefix + hex.EncodeToString(hash[:16])
+}
+
+// openSearchIndexMeta is stored in each index's mapping _meta. Like Redis,
+// materializations create a feature's index and then its table, so Table
+// records whether CreateTable has been called for the index.
+type openSearchIndexMeta struct {
+	Feature   string
+	Variant   string
+	ValueType ValueTypeJSONWrapper
+	Table     bool
+}
+
+var openSearchDistanceMetrics = map[DistanceMetric]string{
+	CosineDistance:       "cosinesimil",
+	L2Distance:           "l2",
+	InnerProductDistance: "innerproduct",
+}
+
+// elasticsearchDistanceMetrics uses max_inner_product rather than
+// dot_product for inner products, since dot_product requires unit vectors.
+var elasticsearchDistanceMetrics = map[DistanceMetric]string{
+	CosineDistance:       "cosine",
+	L2Distance:           "l2_norm",
+	InnerProductDistance: "max_inner_product",
+}
+
+// vectorMapping returns the mapping of the value field for the engine.
+func (store *openSearchOnlineStore) vectorMapping(vectorType VectorType) (map[string]interface{}, error) {
+	options := vectorType.Options()
+	if err := options.validate(string(store.engine), false); err != nil {
+		return nil, err
+	}
+	if err := vectorType.validateStorage(string(store.engine), Float32); err != nil {
+		return nil, err
+	}
+	if err := vectorType.validateNames(string(store.engine), false); err != nil {
+		return nil, err
+	}
+	parameters := map[string]interface{}{}
+	if options.M > 0 {
+		parameters["m"] = options.M
+	}
+	if options.EfConstruction > 0 {
+		parameters["ef_construction"] = options.EfConstruction
+	}
+	if store.engine == pc.Elasticsearch {
+		mapping := map[string]interface{}{
+			"type":       "dense_vector",
+			"dims":       vectorType.Dimension,
+			"index":      true,
+			"similarity": elasticsearchDistanceMetrics[vectorType.Metric()],
+		}
+		if len(parameters) > 0 {
+			parameters["type"] = "hnsw"
+			mapping["index_options"] = parameters
+		}
+		return mapping, nil
+	}
+	method := map[string]interface{}{
+		"name":       "hnsw",
+		"space_type": openSearchDistanceMetrics[vectorType.Metric()],
+		"engine":     "lucene",
+	}
+	if len(parameters) > 0 {
+		method["parameters"] = parameters
+	}
+	return map[string]interface{}{
+		"type":      "knn_vector",
+		"dimension": vectorType.Dimension,
+		"method":    method,
+	}, nil
+}
+
+func (store *openSearchOnlineStore) AsOnlineStore() (OnlineStore, error) {
+	return store, nil
+}
+
+func (store *openSearchOnlineStore) AsVectorStore() (VectorStore, error) {
+	return store, nil
+}
+
+func (store *openSearchOnlineStore) Close() error {
+	return nil
+}
+
+func (store *openSearchOnlineStore) getMeta(feature, variant string) (openSearchIndexMeta, error) {
+	index := openSearchIndexName(feature, variant)
+	meta := openSearchIndexMeta{}
+	var response map[string]struct {
+		Mappings struct {
+			Meta *openSearchIndexMeta `json:"_meta"`
+		} `json:"mappings"`
+	}
+	err := store.client.do(context.TODO(), http.MethodGet, "/"+index+"/_mapping", nil, &response)
+	if isHTTPStatus(err, http.StatusNotFound) {
+		return meta, &TableNotFound{feature, variant}
+	} else if err != nil {
+		return meta, err
+	}
+	mapping, has := response[index]
+	if !has || mapping.Mappings.Meta == nil {
+		return meta, fmt.Errorf("index %s has no featureform metadata", index)
+	}
+	return *mapping.Mappings.Meta, nil
+}
+
+func (store *openSearchOnlineStore) table(feature, variant string, valueType ValueType) (OnlineStoreTable, error) {
+	index := openSearchIndexName(feature, variant)
+	switch t := valueType.(type) {
+	case VectorType:
+		return &openSearchOnlineTable{store: store, index: index, valueType: t}, nil
+	case SparseVectorType:
+		return &openSearchSparseTable{store: store, index: index, valueType: t}, nil
+	default:
+		return nil, fmt.Errorf("%s only stores vectors, not %v", store.engine, valueType)
+	}
+}
+
+// valueMapping returns the mapping of the value field for a value type.
+// Sparse vectors are rank_features fields, which both engines support, with
+// an element's index as its feature name.
+func (store *openSearchOnlineStore) valueMapping(valueType ValueType) (map[string]interface{}, error) {
+	switch t := valueType.(type) {
+	case VectorType:
+		return store.vectorMapping(t)
+	case SparseVectorType:
+		return map[string]interface{}{"type": "rank_features"}, nil
+	default:
+		return nil, fmt.Errorf("%s only stores vectors, not %v", store.engine, valueType)
+	}
+}
+
+func (store *openSearchOnlineStore) GetTable(feature, variant string) (OnlineStoreTable, error) {
+	meta, err := store.getMeta(feature, variant)
+	if err != nil {
+		return nil, err
+	}
+	return store.table(feature, variant, meta.ValueType.ValueType)
+}
+
+// CreateTable creates the feature variant's index, or marks the index
+// created by CreateIndex as a table.
+func (store *openSearchOnlineStore) CreateTable(feature, variant string, valueType ValueType) (OnlineStoreTable, error) {
+	meta, err := store.getMeta(feature, variant)
+	if _, notFound := err.(*TableNotFound); notFound {
+		return store.createIndex(feature, variant, valueType, true)
+	} else if err != nil {
+		return nil, err
+	}
+	if meta.Table {
+		return nil, &TableAlreadyExists{feature, variant}
+	}
+	meta.Table = true
+	path := "/" + openSearchIndexName(feature, variant) + "/_mapping"
+	if err := store.client.do(context.TODO(), http.MethodPut, path, map[string]interface{}{"_meta": meta}, nil); err != nil {
+		return nil, fmt.Errorf("could not update index metadata: %w", err)
+	}
+	return store.table(feature, variant, meta.ValueType.ValueType)
+}
+
+func (store *openSearchOnlineStore) CreateIndex(feature, variant string, vectorType VectorType) (VectorStoreTable, error) {
+	table, err := store.createIndex(feature, variant, vectorType, false)
+	if err != nil {
+		return nil, err
+	}
+	return table.(VectorStoreTable), nil
+}
+
+func (store *openSearchOnlineStore) CreateSparseIndex(feature, variant string, vectorType SparseVectorType) (SparseVectorTable, error) {
+	table, err := store.createIndex(feature, variant, vectorType, false)
+	if err != nil {
+		return nil, err
+	}
+	return table.(SparseVectorTable), nil
+}
+
+func (store *openSearchOnlineStore) createIndex(feature, variant string, valueType ValueType, isTable bool) (OnlineStoreTable, error) {
+	valueMapping, err := store.valueMapping(valueType)
+	if err != nil {
+		return nil, err
+	}
+	index := openSearchIndexName(feature, variant)
+	request := map[string]interface{}{
+		"mappings": map[string]interface{}{
+			"_meta": openSearchIndexMeta{
+				Feature:   feature,
+				Variant:   variant,
+				ValueType: ValueTypeJSONWrapper{valueType},
+				Table:     isTable,
+			},
+			"properties": map[string]interface{}{
+				openSearchEntityField:     map[string]interface{}{"type": "keyword"},
+				openSearchValueField:      valueMapping,
+				openSearchAttributesField: map[string]interface{}{"type": "keyword"},
+				openSearchUpdatedAtField:  map[string]interface{}{"type": "date", "format": "epoch_millis"},
+				openSearchNamespaceField:  map[string]interface{}{"type": "keyword"},
+			},
+		},
+	}
+	if store.engine == pc.OpenSearch {
+		request["settings"] = map[string]interface{}{"index": map[string]interface{}{"knn": true}}
+	}
+	err = store.client.do(context.TODO(), http.MethodPut, "/"+index, request, nil)
+	if isHTTPStatus(err, http.StatusBadRequest) && strings.Contains(err.Error(), "resource_already_exists_exception") {
+		return nil, &TableAlreadyExists{feature, variant}
+	} else if err != nil {
+		return nil, fmt.Errorf("could not create %s index: %w", store.engine, err)
+	}
+	return store.table(feature, variant, valueType)
+}
+
+func (store *openSearchOnlineStore) DeleteTable(feature, variant string) error {
+	err := store.client.do(context.TODO(), http.MethodDelete, "/"+openSearchIndexName(feature, variant), nil, nil)
+	if isHTTPStatus(err, http.StatusNotFound) {
+		return &TableNotFound{feature, variant}
+	}
+	return err
+}
+
+// Truncate deletes every document rather than deleting the index, so that
+// its mapping is kept.
+func (store *openSearchOnlineStore) Truncate(feature, variant string) error {
+	path := "/" + openSearchIndexName(feature, variant) + "/_delete_by_query?conflicts=proceed"
+	if store.waitForRefresh {
+		path += "&refresh=true"
+	}
+	request := map[string]interface{}{"query": map[string]interface{}{"match_all": map[string]interface{}{}}}
+	err := store.client.do(context.TODO(), http.MethodPost, path, request, nil)
+	if isHTTPStatus(err, http.StatusNotFound) {
+		return &TableNotFound{feature, variant}
+	}
+	return err
+}
+
+func (store *openSearchOnlineStore) Capabilities() OnlineCapabilities {
+	return OnlineCapabilities{
+		BatchWrites:      true,
+		Truncate:         true,
+		Vectors:          true,
+		VectorFilters:    true,
+		VectorNamespaces: true,
+		SparseVectors:    true,
+	}
+}
+
+type openSearchOnlineTable struct {
+	store     *openSearchOnlineStore
+	index     string
+	valueType VectorType
+}
+
+// openSearchDocument is an entity's vector. Attributes are key=value terms
+// and UpdatedAt is the write time in Unix milliseconds. Documents are
+// indexed whole, so writing one without a Namespace removes the entity from
+// its old one.
+type openSearchDocument struct {
+	Entity     string    `json:"entity"`
+	Value      []float32 `json:"value"`
+	Attributes []string  `json:"attributes,omitempty"`
+	UpdatedAt  int64     `json:"updated_at,omitempty"`
+	Namespace  string    `json:"namespace,omitempty"`
+}
+
+func (table *openSearchOnlineTable) Set(entity string, value interface{}) error {
+	return table.SetBatch(map[string]interface{}{entity: value})
+}
+
+func (table *openSearchOnlineTable) SetWithAttributes(entity string, vector []float32, attributes map[string]string) error {
+	if err := checkVectorDimension(table.valueType, entity, vector); err != nil {
+		return err
+	}
+	terms, err := vectorAttributeTerms(attributes)
+	if err != nil {
+		return err
+	}
+	return table.bulkIndex([]openSearchDocument{{Entity: entity, Value: vector, Attributes: terms, UpdatedAt: time.Now().UnixMilli()}})
+}
+
+func (table *openSearchOnlineTable) SetInNamespace(namespace, entity string, vector []float32) error {
+	if err := validateNamespace(namespace); err != nil {
+		return err
+	}
+	if err := checkVectorDimension(table.valueType, entity, vector); err != nil {
+		return err
+	}
+	return table.bulkIndex([]openSearchDocument{{Entity: entity, Value: vector, UpdatedAt: time.Now().UnixMilli(), Namespace: namespace}})
+}
+
+// SetBatch indexes the entities' vectors.
+func (table *openSearchOnlineTable) SetBatch(values map[string]interface{}) error {
+	documents := make([]openSearchDocument, 0, len(values))
+	now := time.Now().UnixMilli()
+	for entity, value := range values {
+		vector, err := vectorValue(table.valueType, entity, value)
+		if err != nil {
+			return err
+		}
+		documents = append(documents, openSearchDocument{Entity: entity, Value: vector, UpdatedAt: now})
+	}
+	return table.bulkIndex(documents)
+}
+
+func (table *openSearchOnlineTable) bulkIndex(documents []openSearchDocument) error {
+	ids := make([]string, len(documents))
+	bodies := make([]interface{}, len(documents))
+	for i, document := range documents {
+		ids[i] = document.Entity
+		bodies[i] = document
+	}
+	return table.store.bulkIndex(table.index, ids, bodies)
+}
+
+// bulkIndex indexes the documents with the bulk API in batches of up to
+// openSearchBulkBatchSize documents. Each document's ID is the entity at
+// the same position of ids.
+func (store *openSearchOnlineStore) bulkIndex(index string, ids []string, documents []interface{}) error {
+	path := "/_bulk"
+	if store.waitForRefresh {
+		path += "?refresh=wait_for"
+	}
+	var body bytes.Buffer
+	encoder := json.NewEncoder(&body)
+	batched := 0
+	flush := func() error {
+		if batched == 0 {
+			return nil
+		}
+		var response struct {
+			Errors bool `json:"errors"`
+			Items  []map[string]struct {
+				ID    string `json:"_id"`
+				Error *struct {
+					Reason string `json:"reason"`
+				} `json:"error"`
+			} `json:"items"`
+		}
+		if err := store.client.send(context.TODO(), http.MethodPost, path, "application/x-ndjson", body.Bytes(), &response); err != nil {
+			return err
+		}
+		if response.Errors {
+			for _, item := range response.Items {
+				for _, result := range item {
+					if result.Error != nil {
+						return fmt.Errorf("could not index document %s: %s", result.ID, result.Error.Reason)
+					}
+				}
+			}
+		}
+		body.Reset()
+		batched = 0
+		return nil
+	}
+	for i, document := range documents {
+		action := map[string]interface{}{"index": map[string]string{"_index": index, "_id": ids[i]}}
+		if err := encoder.Encode(action); err != nil {
+			return err
+		}
+		if err := encoder.Encode(document); err != nil {
+			return err
+		}
+		batched++
+		if batched == openSearchBulkBatchSize {
+			if err := flush(); err != nil {
+				return err
+			}
+		}
+	}
+	return flush()
+}
+
+func (table *openSearchOnlineTable) Get(entity string) (interface{}, error) {
+	document := openSearchDocument{}
+	if err := table.store.getDocument(table.index, entity, &document); err != nil {
+		return nil, err
+	}
+	return document.Value, nil
+}
+
+// getDocument reads the entity's document by ID into source, which is
+// realtime and doesn't wait for a refresh.
+func (store *openSearchOnlineStore) getDocument(index, entity string, source interface{}) error {
+	var response struct {
+		Found  bool        `json:"found"`
+		Source interface{} `json:"_source"`
+	}
+	response.Source = source
+	path := fmt.Sprintf("/%s/_doc/%s", index, url.PathEscape(entity))
+	err := store.client.do(context.TODO(), http.MethodGet, path, nil, &response)
+	if isHTTPStatus(err, http.StatusNotFound) {
+		return &EntityNotFound{entity}
+	} else if err != nil {
+		return err
+	}
+	if !response.Found {
+		return &EntityNotFound{entity}
+	}
+	return nil
+}
+
+func (table *openSearchOnlineTable) Delete(entity string) error {
+	return table.store.deleteDocument(table.index, entity)
+}
+
+// deleteDocument deletes an entity's document, succeeding if it's already
+// missing but not if its index is.
+func (store *openSearchOnlineStore) deleteDocument(index, entity string) error {
+	path := fmt.Sprintf("/%s/_doc/%s", index, url.PathEscape(entity))
+	if store.waitForRefresh {
+		path += "?refresh=wait_for"
+	}
+	err := store.client.do(context.TODO(), http.MethodDelete, path, nil, nil)
+	if isHTTPStatus(err, http.StatusNotFound) && !strings.Contains(err.Error(), "index_not_found_exception") {
+		return nil
+	}
+	return err
+}
+
+// openSearchFilter returns the bool query of a filter, or nil if the filter
+// is zero.
+func openSearchFilter(filter VectorFilter) (map[string]interface{}, error) {
+	if filter.IsZero() {
+		return nil, nil
+	}
+	terms, err := vectorAttributeTerms(filter.Attributes)
+	if err != nil {
+		return nil, err
+	}
+	clauses := make([]map[string]interface{}, 0, len(terms)+2)
+	for _, term := range terms {
+		clauses = append(clauses, map[string]interface{}{"term": map[string]interface{}{openSearchAttributesField: term}})
+	}
+	if !filter.UpdatedAfter.IsZero() {
+		clauses = append(clauses, map[string]interface{}{
+			"range": map[string]interface{}{openSearchUpdatedAtField: map[string]interface{}{"gte": filter.UpdatedAfter.UnixMilli()}},
+		})
+	}
+	if filter.Namespace != "" {
+		clauses = append(clauses, map[string]interface{}{"term": map[string]interface{}{openSearchNamespaceField: filter.Namespace}})
+	}
+	return map[string]interface{}{"bool": map[string]interface{}{"filter": clauses}}, nil
+}
+
+// nearestQuery returns the kNN search request for the engine of the k
+// documents after the nearest offset documents. Both engines apply the
+// filter during the search, rather than to the k nearest documents, and
+// page through the nearest offset+k documents with from and size.
+func (table *openSearchOnlineTable) nearestQuery(vector []float32, offset, k int32, filter VectorFilter) (map[string]interface{}, error) {
+	boolQuery, err := openSearchFilter(filter)
+	if err != nil {
+		return nil, err
+	}
+	searched := offset + k
+	if table.store.engine == pc.Elasticsearch {
+		candidates := searched
+		if candidates < openSearchMinCandidates {
+			candidates = openSearchMinCandidates
+		}
+		if ef := table.valueType.Options().EfRuntime; ef > candidates {
+			candidates = ef
+		}
+		knn := map[string]interface{}{
+			"field":          openSearchValueField,
+			"query_vector":   vector,
+			"k":              searched,
+			"num_candidates": candidates,
+		}
+		if boolQuery != nil {
+			knn["filter"] = boolQuery
+		}
+		return map[string]interface{}{
+			"knn":     knn,
+			"from":    offset,
+			"size":    k,
+			"_source": []string{openSearchEntityField},
+		}, nil
+	}
+	knn := map[string]interface{}{
+		"vector": vector,
+		"k":      searched,
+	}
+	if boolQuery != nil {
+		knn["filter"] = boolQuery
+	}
+	if ef := table.valueType.Options().EfRuntime; ef > 0 {
+		knn["method_parameters"] = map[string]interface{}{"ef_search": ef}
+	}
+	return map[string]interface{}{
+		"from": offset,
+		"size": k,
+		"query": map[string]interface{}{
+			"knn": map[string]interface{}{openSearchValueField: knn},
+		},
+		"_source": []string{openSearchEntityField},
+	}, nil
+}
+
+// Nearest returns the entities of the k documents nearest to vector that
+// match filter with a kNN search.
+func (table *openSearchOnlineTable) Nearest(feature, variant string, vector []float32, k int32, filter VectorFilter) ([]NearestResult, error) {
+	return table.nearest(vector, 0, k, filter)
+}
+
+func (table *openSearchOnlineTable) NearestPage(feature, variant string, vector []float32, pageSize int32, filter VectorFilter, cursor string) (NearestPage, error) {
+	search := func(offset, k int32) ([]NearestResult, error) {
+		return table.nearest(vector, offset, k, filter)
+	}
+	return nearestPage(search, pageSize, cursor)
+}
+
+func (table *openSearchOnlineTable) nearest(vector []float32, offset, k int32, filter VectorFilter) ([]NearestResult, error) {
+	query, err := table.nearestQuery(vector, offset, k, filter)
+	if err != nil {
+		return nil, err
+	}
+	var response struct {
+		Hits struct {
+			Hits []struct {
+				Score  float64            `json:"_score"`
+				Source openSearchDocument `json:"_source"`
+			} `json:"hits"`
+		} `json:"hits"`
+	}
+	path := "/" + table.index + "/_search"
+	if err := table.store.client.do(context.TODO(), http.MethodPost, path, query, &response); err != nil {
+		return nil, err
+	}
+	results := make([]NearestResult, len(response.Hits.Hits))
+	for i, hit := range response.Hits.Hits {
+		results[i] = NearestResult{Entity: hit.Source.Entity, Distance: openSearchDistance(table.valueType.Metric(), hit.Score)}
+	}
+	return results, nil
+}
+
+// openSearchDistance converts a kNN score to a NearestResult distance. Both
+// engines score Lucene HNSW searches the same way, mapping distances to
+// positive scores where higher is nearer.
+func openSearchDistance(metric DistanceMetric, score float64) float32 {
+	switch metric {
+	case L2Distance:
+		// score = 1 / (1 + distance^2)
+		return float32(math.Sqrt(math.Max(1/score-1, 0)))
+	case InnerProductDistance:
+		// score = 1 + product for non-negative products, and
+		// 1 / (1 - product) otherwise.
+		if score >= 1 {
+			return float32(1 - score)
+		}
+		return float32(1/score - 1)
+	default:
+		// score = (1 + cosine) / 2
+		return float32(2 - 2*score)
+	}
+}
+
+// openSearchSparseTable stores sparse vectors in a rank_features field and
+// ranks them with rank_feature queries.
+type openSearchSparseTable struct {
+	store     *openSearchOnlineStore
+	index     string
+	valueType SparseVectorType
+}
+
+// openSearchSparseDocument is an entity's sparse vector, with the decimal
+// index of each element as its feature name.
+type openSearchSparseDocument struct {
+	Entity    string             `json:"entity"`
+	Value     map[string]float32 `json:"value"`
+	UpdatedAt int64              `json:"updated_at,omitempty"`
+}
+
+// openSearchSparseFeatures converts a sparse vector to rank features, which
+// must be positive.
+func openSearchSparseFeatures(vector SparseVector, dimension int32) (map[string]float32, error) {
+	if err := vector.validate(dimension); err != nil {
+		return nil, err
+	}
+	features := make(map[string]float32, len(vector.Indices))
+	for i, index := range vector.Indices {
+		if vector.Values[i] <= 0 {
+			return nil, fmt.Errorf("sparse vector value %v at index %d must be positive", vector.Values[i], index)
+		}
+		features[strconv.FormatUint(uint64(index), 10)] = vector.Values[i]
+	}
+	return features, nil
+}
+
+func (table *openSearchSparseTable) Set(entity string, value interface{}) error {
+	return table.SetBatch(map[string]interface{}{entity: value})
+}
+
+// SetBatch indexes the entities' sparse vectors.
+func (table *openSearchSparseTable) SetBatch(values map[string]interface{}) error {
+	ids := make([]string, 0, len(values))
+	documents := make([]interface{}, 0, len(values))
+	now := time.Now().UnixMilli()
+	for entity, value := range values {
+		vector, ok := value.(SparseVector)
+		if !ok {
+			return fmt.Errorf("value %v is not a sparse vector", value)
+		}
+		features, err := openSearchSparseFeatures(vector, table.valueType.Dimension)
+		if err != nil {
+			return err
+		}
+		ids = append(ids, entity)
+		documents = append(documents, openSearchSparseDocument{Entity: entity, Value: features, UpdatedAt: now})
+	}
+	return table.store.bulkIndex(table.index, ids, documents)
+}
+
+// Get returns the entity's sparse vector with its indices in ascending
+// order.
+func (table *openSearchSparseTable) Get(entity string) (interface{}, error) {
+	document := openSearchSparseDocument{}
+	if err := table.store.getDocument(table.index, entity, &document); err != nil {
+		return nil, err
+	}
+	vector := SparseVector{
+		Indices: make([]uint32, 0, len(document.Value)),
+		Values:  make([]float32, 0, len(document.Value)),
+	}
+	for feature := range document.Value {
+		index, err := strconv.ParseUint(feature, 10, 32)
+		if err != nil {
+			return nil, fmt.Errorf("invalid sparse vector index %q: %w", feature, err)
+		}
+		vector.Indices = append(vector.Indices, uint32(index))
+	}
+	sort.Slice(vector.Indices, func(i, j int) bool { return vector.Indices[i] < vector.Indices[j] })
+	for _, index := range vector.Indices {
+		vector.Values = append(vector.Values, document.Value[strconv.FormatUint(uint64(index), 10)])
+	}
+	return vector, nil
+}
+
+func (table *openSearchSparseTable) Delete(entity string) error {
+	return table.store.deleteDocument(table.index, entity)
+}
+
+// sparseQuery returns the search request of the k documents with the
+// largest dot products with vector. A linear rank_feature query scores a
+// document by its value of the feature times the query's boost, so a
+// should clause per element of vector sums to the dot product.
+func (table *openSearchSparseTable) sparseQuery(vector SparseVector, k int32) (map[string]interface{}, error) {
+	features, err := openSearchSparseFeatures(vector, table.valueType.Dimension)
+	if err != nil {
+		return nil, err
+	}
+	clauses := make([]map[string]interface{}, 0, len(features))
+	for _, index := range vector.Indices {
+		feature := strconv.FormatUint(uint64(index), 10)
+		clauses = append(clauses, map[string]interface{}{
+			"rank_feature": map[string]interface{}{
+				"field":  openSearchValueField + "." + feature,
+				"boost":  features[feature],
+				"linear": map[string]interface{}{},
+			},
+		})
+	}
+	return map[string]interface{}{
+		"size":    k,
+		"query":   map[string]interface{}{"bool": map[string]interface{}{"should": clauses}},
+		"_source": []string{openSearchEntityField},
+	}, nil
+}
+
+func (table *openSearchSparseTable) NearestSparse(feature, variant string, vector SparseVector, k int32) ([]NearestResult, error) {
+	query, err := table.sparseQuery(vector, k)
+	if err != nil {
+		return nil, err
+	}
+	var response struct {
+		Hits struct {
+			Hits []struct {
+				Score  float64                  `json:"_score"`
+				Source openSearchSparseDocument `json:"_source"`
+			} `json:"hits"`
+		} `json:"hits"`
+	}
+	path := "/" + table.index + "/_search"
+	if err := table.store.client.do(context.TODO(), http.MethodPost, path, query, &response); err != nil {
+		return nil, err
+	}
+	results := make([]NearestResult, len(response.Hits.Hits))
+	for i, hit := range response.Hits.Hits {
+		results[i] = NearestResult{Entity: hit.Source.Entity, Distance: float32(-hit.Score)}
+	}
+	return results, nil
+}
@@ -0,0 +1,369 @@
+package provider
+
+import (
+	"bufio"
+	"encoding/json"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	pc "github.com/featureform/provider/provider_config"
+)
+
+// fakeOpenSearch implements the parts of the OpenSearch and Elasticsearch
+// REST APIs used by the store. Documents are kept as the JSON they were
+// indexed with. Searches return every document of the index and record the
+// query.
+type fakeOpenSearch struct {
+	mappings  map[string]map[string]interface{}
+	documents map[string]map[string]json.RawMessage
+	auth      string
+	lastQuery map[string]interface{}
+	mu        sync.Mutex
+}
+
+func newFakeOpenSearch() *fakeOpenSearch {
+	return &fakeOpenSearch{
+		mappings:  make(map[string]map[string]interface{}),
+		documents: make(map[string]map[string]json.RawMessage),
+	}
+}
+
+func (f *fakeOpenSearch) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.auth = r.Header.Get("Authorization")
+	path := strings.Split(strings.TrimPrefix(r.URL.Path, "/"), "/")
+	index := path[0]
+	switch {
+	case r.Method == http.MethodPost && index == "_bulk":
+		scanner := bufio.NewScanner(r.Body)
+		for scanner.Scan() {
+			var action struct {
+				Index struct {
+					Index string `json:"_index"`
+					ID    string `json:"_id"`
+				} `json:"index"`
+			}
+			json.Unmarshal(scanner.Bytes(), &action)
+			scanner.Scan()
+			f.documents[action.Index.Index][action.Index.ID] = append(json.RawMessage{}, scanner.Bytes()...)
+		}
+		w.Write([]byte(`{"errors":false,"items":[]}`))
+	case r.Method == http.MethodPut && len(path) == 1:
+		if _, has := f.mappings[index]; has {
+			http.Error(w, `{"error":{"type":"resource_already_exists_exception"}}`, http.StatusBadRequest)
+			return
+		}
+		var request struct {
+			Mappings map[string]interface{} `json:"mappings"`
+		}
+		json.NewDecoder(r.Body).Decode(&request)
+		f.mappings[index] = request.Mappings
+		f.documents[index] = make(map[string]json.RawMessage)
+	case r.Method == http.MethodDelete && len(path) == 1:
+		if _, has := f.mappings[index]; !has {
+			http.NotFound(w, r)
+			return
+		}
+		delete(f.mappings, index)
+		delete(f.documents, index)
+	case r.Method == http.MethodGet && path[1] == "_mapping":
+		mapping, has := f.mappings[index]
+		if !has {
+			http.NotFound(w, r)
+			return
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{index: map[string]interface{}{"mappings": mapping}})
+	case r.Method == http.MethodPut && path[1] == "_mapping":
+		var request map[string]interface{}
+		json.NewDecoder(r.Body).Decode(&request)
+		f.mappings[index]["_meta"] = request["_meta"]
+	case r.Method == http.MethodGet && path[1] == "_doc":
+		document, has := f.documents[index][path[2]]
+		if !has {
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"found":false}`))
+			return
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{"found": true, "_source": document})
+	case r.Method == http.MethodDelete && path[1] == "_doc":
+		if _, has := f.documents[index][path[2]]; !has {
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"result":"not_found"}`))
+			return
+		}
+		delete(f.documents[index], path[2])
+	case r.Method == http.MethodPost && path[1] == "_delete_by_query":
+		f.documents[index] = make(map[string]json.RawMessage)
+	case r.Method == http.MethodPost && path[1] == "_search":
+		f.lastQuery = make(map[string]interface{})
+		json.NewDecoder(r.Body).Decode(&f.lastQuery)
+		hits := []map[string]interface{}{}
+		for _, document := range f.documents[index] {
+			source := map[string]interface{}{}
+			json.Unmarshal(document, &source)
+			hits = append(hits, map[string]interface{}{"_score": 0.75, "_source": map[string]interface{}{"entity": source["entity"]}})
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{"hits": map[string]interface{}{"hits": hits}})
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+func TestOpenSearchOnlineStore(t *testing.T) {
+	engines := map[pc.SearchEngine]string{
+		pc.OpenSearch:    "query",
+		pc.Elasticsearch: "knn",
+	}
+	for engine, queryKey := range engines {
+		t.Run(string(engine), func(t *testing.T) {
+			fake := newFakeOpenSearch()
+			server := httptest.NewServer(fake)
+			defer server.Close()
+			store, err := NewOpenSearchOnlineStore(&pc.OpenSearchConfig{Engine: engine, URL: server.URL, APIKey: "key"})
+			if err != nil {
+				t.Fatalf("Failed to create store: %s", err)
+			}
+			if _, err := store.GetTable("feature", "variant"); err == nil {
+				t.Fatalf("Expected TableNotFound")
+			} else if _, notFound := err.(*TableNotFound); !notFound {
+				t.Fatalf("Expected TableNotFound, got %T: %s", err, err)
+			}
+			if _, err := store.CreateTable("feature", "variant", Int); err == nil {
+				t.Fatalf("Succeeded in creating a non-vector table")
+			}
+			vectorType := VectorType{ScalarType: Float32, Dimension: 2, IsEmbedding: true}
+			if _, err := store.CreateIndex("feature", "variant", vectorType); err != nil {
+				t.Fatalf("Failed to create index: %s", err)
+			}
+			if _, err := store.CreateIndex("feature", "variant", vectorType); err == nil {
+				t.Fatalf("Expected TableAlreadyExists")
+			} else if _, exists := err.(*TableAlreadyExists); !exists {
+				t.Fatalf("Expected TableAlreadyExists, got %T: %s", err, err)
+			}
+			if _, err := store.CreateTable("feature", "variant", vectorType); err != nil {
+				t.Fatalf("Failed to create table for index: %s", err)
+			}
+			if _, err := store.CreateTable("feature", "variant", vectorType); err == nil {
+				t.Fatalf("Expected TableAlreadyExists")
+			}
+			table, err := store.GetTable("feature", "variant")
+			if err != nil {
+				t.Fatalf("Failed to get table: %s", err)
+			}
+			if err := table.Set("a", []float32{1, 0}); err != nil {
+				t.Fatalf("Failed to set vector: %s", err)
+			}
+			if err := table.(BatchSettableTable).SetBatch(map[string]interface{}{"a": []float32{0, 1}, "b": []float32{1, 1}}); err != nil {
+				t.Fatalf("Failed to set batch: %s", err)
+			}
+			if fake.auth != "ApiKey key" {
+				t.Fatalf("Expected API key to be sent, got %q", fake.auth)
+			}
+			if value, err := table.Get("a"); err != nil || !reflect.DeepEqual(value, []float32{0, 1}) {
+				t.Fatalf("Expected overwritten vector, got %v %v", value, err)
+			}
+			if _, err := table.Get("c"); err == nil {
+				t.Fatalf("Expected EntityNotFound")
+			} else if _, notFound := err.(*EntityNotFound); !notFound {
+				t.Fatalf("Expected EntityNotFound, got %T: %s", err, err)
+			}
+			entities, err := table.(VectorStoreTable).Nearest("feature", "variant", []float32{1, 1}, 2, VectorFilter{})
+			if err != nil {
+				t.Fatalf("Failed to search: %s", err)
+			}
+			if len(entities) != 2 {
+				t.Fatalf("Expected 2 entities, got %v", entities)
+			}
+			if distance := entities[0].Distance; distance < 0.4999 || distance > 0.5001 {
+				t.Fatalf("Expected cosine distance 0.5, got %v", distance)
+			}
+			if err := table.(VectorStoreTable).Delete("b"); err != nil {
+				t.Fatalf("Failed to delete vector: %s", err)
+			}
+			if err := table.(VectorStoreTable).Delete("b"); err != nil {
+				t.Fatalf("Failed to delete missing vector: %s", err)
+			}
+			if entities, err := table.(VectorStoreTable).Nearest("feature", "variant", []float32{1, 1}, 2, VectorFilter{}); err != nil || len(entities) != 1 {
+				t.Fatalf("Expected deleted vector to be removed, got %v %v", entities, err)
+			}
+			if _, has := fake.lastQuery[queryKey]; !has {
+				t.Fatalf("Expected %s kNN query to use %s, got %v", engine, queryKey, fake.lastQuery)
+			}
+			if err := store.Truncate("feature", "variant"); err != nil {
+				t.Fatalf("Failed to truncate: %s", err)
+			}
+			if _, err := table.Get("b"); err == nil {
+				t.Fatalf("Expected truncated table to be empty")
+			}
+			if err := store.DeleteTable("feature", "variant"); err != nil {
+				t.Fatalf("Failed to delete table: %s", err)
+			}
+			if err := store.DeleteTable("feature", "variant"); err == nil {
+				t.Fatalf("Expected TableNotFound")
+			}
+		})
+	}
+}
+
+func TestOpenSearchNearestQueryFilter(t *testing.T) {
+	filter := VectorFilter{
+		Attributes:   map[string]string{"tier": "gold"},
+		UpdatedAfter: time.UnixMilli(1680307200000),
+		Namespace:    "tenant",
+	}
+	expected := map[string]interface{}{"bool": map[string]interface{}{"filter": []map[string]interface{}{
+		{"term": map[string]interface{}{"attributes": "tier=gold"}},
+		{"range": map[string]interface{}{"updated_at": map[string]interface{}{"gte": int64(1680307200000)}}},
+		{"term": map[string]interface{}{"namespace": "tenant"}},
+	}}}
+	for _, engine := range []pc.SearchEngine{pc.OpenSearch, pc.Elasticsearch} {
+		table := &openSearchOnlineTable{store: &openSearchOnlineStore{engine: engine}}
+		query, err := table.nearestQuery([]float32{1, 0}, 0, 2, filter)
+		if err != nil {
+			t.Fatalf("Failed to build %s query: %s", engine, err)
+		}
+		knn, _ := query["knn"].(map[string]interface{})
+		if engine == pc.OpenSearch {
+			knn = query["query"].(map[string]interface{})["knn"].(map[string]interface{})["value"].(map[string]interface{})
+		}
+		if !reflect.DeepEqual(knn["filter"], expected) {
+			t.Fatalf("Expected %s kNN filter %v, got %v", engine, expected, knn["filter"])
+		}
+	}
+	table := &openSearchOnlineTable{store: &openSearchOnlineStore{engine: pc.Elasticsearch}}
+	query, err := table.nearestQuery([]float32{1, 0}, 0, 2, VectorFilter{})
+	if err != nil {
+		t.Fatalf("Failed to build query: %s", err)
+	}
+	if _, has := query["knn"].(map[string]interface{})["filter"]; has {
+		t.Fatalf("Expected no filter for a zero VectorFilter, got %v", query)
+	}
+}
+
+func TestOpenSearchIndexOptions(t *testing.T) {
+	vectorType := VectorType{ScalarType: Float32, Dimension: 2, Index: &IndexOptions{M: 32, EfConstruction: 200, EfRuntime: 150}}
+	openSearch := &openSearchOnlineStore{engine: pc.OpenSearch}
+	mapping, err := openSearch.vectorMapping(vectorType)
+	if err != nil {
+		t.Fatalf("Failed to build mapping: %s", err)
+	}
+	expected := map[string]interface{}{"m": int32(32), "ef_construction": int32(200)}
+	if parameters := mapping["method"].(map[string]interface{})["parameters"]; !reflect.DeepEqual(parameters, expected) {
+		t.Fatalf("Expected parameters %v, got %v", expected, parameters)
+	}
+	table := &openSearchOnlineTable{store: openSearch, valueType: vectorType}
+	query, err := table.nearestQuery([]float32{1, 0}, 0, 2, VectorFilter{})
+	if err != nil {
+		t.Fatalf("Failed to build query: %s", err)
+	}
+	knn := query["query"].(map[string]interface{})["knn"].(map[string]interface{})["value"].(map[string]interface{})
+	if !reflect.DeepEqual(knn["method_parameters"], map[string]interface{}{"ef_search": int32(150)}) {
+		t.Fatalf("Expected ef_search, got %v", knn)
+	}
+	elasticsearch := &openSearchOnlineStore{engine: pc.Elasticsearch}
+	mapping, err = elasticsearch.vectorMapping(vectorType)
+	if err != nil {
+		t.Fatalf("Failed to build mapping: %s", err)
+	}
+	if options := mapping["index_options"].(map[string]interface{}); options["type"] != "hnsw" || options["m"] != int32(32) {
+		t.Fatalf("Unexpected index options %v", options)
+	}
+	table = &openSearchOnlineTable{store: elasticsearch, valueType: vectorType}
+	query, err = table.nearestQuery([]float32{1, 0}, 0, 2, VectorFilter{})
+	if err != nil {
+		t.Fatalf("Failed to build query: %s", err)
+	}
+	if candidates := query["knn"].(map[string]interface{})["num_candidates"]; candidates != int32(150) {
+		t.Fatalf("Expected EfRuntime candidates, got %v", candidates)
+	}
+}
+
+func TestOpenSearchDistance(t *testing.T) {
+	tests := []struct {
+		metric   DistanceMetric
+		score    float64
+		expected float32
+	}{
+		{CosineDistance, 1, 0},
+		{CosineDistance, 0.5, 1},
+		{L2Distance, 1, 0},
+		{L2Distance, 0.2, 2},
+		{InnerProductDistance, 3, -2},
+		{InnerProductDistance, 0.25, 3},
+	}
+	for _, tt := range tests {
+		if distance := openSearchDistance(tt.metric, tt.score); math.Abs(float64(distance-tt.expected)) > 1e-6 {
+			t.Errorf("Expected %s score %v to be distance %v, got %v", tt.metric, tt.score, tt.expected, distance)
+		}
+	}
+}
+
+func TestOpenSearchSparseVectors(t *testing.T) {
+	fake := newFakeOpenSearch()
+	server := httptest.NewServer(fake)
+	defer server.Close()
+	store, err := NewOpenSearchOnlineStore(&pc.OpenSearchConfig{URL: server.URL})
+	if err != nil {
+		t.Fatalf("Failed to create store: %s", err)
+	}
+	sparseType := SparseVectorType{Dimension: 100}
+	table, err := store.CreateSparseIndex("feature", "variant", sparseType)
+	if err != nil {
+		t.Fatalf("Failed to create sparse index: %s", err)
+	}
+	mapping := fake.mappings[openSearchIndexName("feature", "variant")]["properties"].(map[string]interface{})[openSearchValueField]
+	if !reflect.DeepEqual(mapping, map[string]interface{}{"type": "rank_features"}) {
+		t.Fatalf("Expected rank_features mapping, got %v", mapping)
+	}
+	if _, err := store.CreateTable("feature", "variant", sparseType); err != nil {
+		t.Fatalf("Failed to create table for index: %s", err)
+	}
+	vector := SparseVector{Indices: []uint32{7, 3}, Values: []float32{0.5, 2}}
+	if err := table.Set("a", vector); err != nil {
+		t.Fatalf("Failed to set sparse vector: %s", err)
+	}
+	invalid := []SparseVector{
+		{Indices: []uint32{1}, Values: []float32{-1}},
+		{Indices: []uint32{100}, Values: []float32{1}},
+		{Indices: []uint32{1, 1}, Values: []float32{1, 1}},
+		{Indices: []uint32{1}},
+	}
+	for _, v := range invalid {
+		if err := table.Set("b", v); err == nil {
+			t.Fatalf("Succeeded in setting invalid sparse vector %v", v)
+		}
+	}
+	fetched, err := store.GetTable("feature", "variant")
+	if err != nil {
+		t.Fatalf("Failed to get table: %s", err)
+	}
+	expected := SparseVector{Indices: []uint32{3, 7}, Values: []float32{2, 0.5}}
+	if value, err := fetched.Get("a"); err != nil || !reflect.DeepEqual(value, expected) {
+		t.Fatalf("Expected %v, got %v %v", expected, value, err)
+	}
+	results, err := fetched.(SparseVectorTable).NearestSparse("feature", "variant", SparseVector{Indices: []uint32{3}, Values: []float32{1.5}}, 1)
+	if err != nil {
+		t.Fatalf("Failed to search: %s", err)
+	}
+	if len(results) != 1 || results[0].Entity != "a" || results[0].Distance != -0.75 {
+		t.Fatalf("Expected a with the negated score, got %v", results)
+	}
+	clause := fake.lastQuery["query"].(map[string]interface{})["bool"].(map[string]interface{})["should"].([]interface{})[0]
+	rankFeature := clause.(map[string]interface{})["rank_feature"].(map[string]interface{})
+	if rankFeature["field"] != "value.3" || rankFeature["boost"] != 1.5 {
+		t.Fatalf("Expected a linear rank_feature query of value.3, got %v", rankFeature)
+	}
+	if err := fetched.(SparseVectorTable).(*openSearchSparseTable).Delete("a"); err != nil {
+		t.Fatalf("Failed to delete sparse vector: %s", err)
+	}
+	if _, err := fetched.Get("a"); err == nil {
+		t.Fatalf("Expected deleted sparse vector to be missing")
+	}
+}
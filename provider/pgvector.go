@@ -0,0 +1,604 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package provider
+
+import (
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/lib/pq"
+
+	pc "github.com/featureform/provider/provider_config"
+	pt "github.com/featureform/provider/provider_type"
+)
+
+const (
+	pgvectorTablePrefix   = "featureform_online__"
+	pgvectorMetadataTable = "featureform_online__metadata"
+	// pgvectorBatchSize keeps batch upserts well under Postgres's limit of
+	// 65535 bind parameters per statement.
+	pgvectorBatchSize = 1000
+)
+
+func pgvectorOnlineStoreFactory(serialized pc.SerializedConfig) (Provider, error) {
+	config := &pc.PgvectorConfig{}
+	if err := config.Deserialize(serialized); err != nil {
+		return nil, err
+	}
+	return NewPgvectorOnlineStore(config)
+}
+
+// pgvectorOnlineStore is an OnlineStore and VectorStore in a Postgres
+// database with the pgvector extension. Each feature variant is a table
+// keyed by entity. Vectors are stored in a vector column with an HNSW index,
+// and every other value type is stored as text.
+type pgvectorOnlineStore struct {
+	db *sql.DB
+	BaseProvider
+}
+
+func pgvectorConnectionURL(config *pc.PgvectorConfig) string {
+	sslMode := config.SSLMode
+	if sslMode == "" {
+		sslMode = "disable"
+	}
+	connURL := url.URL{
+		Scheme:   "postgres",
+		User:     url.UserPassword(config.Username, config.Password),
+		Host:     net.JoinHostPort(config.Host, config.Port),
+		Path:     config.Database,
+		RawQuery: url.Values{"sslmode": []string{sslMode}}.Encode(),
+	}
+	return connURL.String()
+}
+
+func NewPgvectorOnlineStore(config *pc.PgvectorConfig) (*pgvectorOnlineStore, error) {
+	db, err := sql.Open("postgres", pgvectorConnectionURL(config))
+	if err != nil {
+		return nil, err
+	}
+	if _, err := db.Exec("CREATE EXTENSION IF NOT EXISTS vector"); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("could not create pgvector extension: %w", err)
+	}
+	query := fmt.Sprintf("CREATE TABLE IF NOT EXISTS %s (table_name text PRIMARY KEY, feature text NOT NULL, variant text NOT NULL, value_type text NOT NULL, is_table boolean NOT NULL)", pq.QuoteIdentifier(pgvectorMetadataTable))
+	if _, err := db.Exec(query); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("could not create metadata table: %w", err)
+	}
+	return &pgvectorOnlineStore{
+		db: db,
+		BaseProvider: BaseProvider{
+			ProviderType:   pt.PgvectorOnline,
+			ProviderConfig: config.Serialized(),
+		},
+	}, nil
+}
+
+// pgvectorTableName returns the table of a feature variant. Identifiers are
+// limited to 63 bytes, so the feature and variant are hashed and kept in the
+// metadata table instead.
+func pgvectorTableName(feature, variant string) string {
+	hash := sha256.Sum256([]byte(feature + "\x00" + variant))
+	return pgvectorTablePrefix + hex.EncodeToString(hash[:16])
+}
+
+// pgvectorDistanceMetric is the HNSW operator class of a distance metric and
+// the operator that orders by it. The inner product operator returns the
+// negative inner product, so every operator sorts ascending.
+type pgvectorDistanceMetric struct {
+	opclass  string
+	operator string
+}
+
+var pgvectorDistanceMetrics = map[DistanceMetric]pgvectorDistanceMetric{
+	CosineDistance:       {"vector_cosine_ops", "<=>"},
+	L2Distance:           {"vector_l2_ops", "<->"},
+	InnerProductDistance: {"vector_ip_ops", "<#>"},
+}
+
+// pgvectorIndexDefinition returns the statement creating a table's vector
+// index, which is an IVFFlat index if NLists is set and HNSW otherwise.
+func pgvectorIndexDefinition(tableName string, vectorType VectorType) (string, error) {
+	options := vectorType.Options()
+	if err := options.validate("pgvector", true); err != nil {
+		return "", err
+	}
+	if err := vectorType.validateStorage("pgvector", Float32); err != nil {
+		return "", err
+	}
+	if err := vectorType.validateNames("pgvector", false); err != nil {
+		return "", err
+	}
+	opclass := pgvectorDistanceMetrics[vectorType.Metric()].opclass
+	if options.NLists > 0 {
+		if options.M > 0 || options.EfConstruction > 0 || options.EfRuntime > 0 {
+			return "", fmt.Errorf("IVF indexes don't use HNSW options: %+v", options)
+		}
+		return fmt.Sprintf("CREATE INDEX %s ON %s USING ivfflat (value %s) WITH (lists = %d)", pq.QuoteIdentifier(tableName+"_ivfflat"), pq.QuoteIdentifier(tableName), opclass, options.NLists), nil
+	}
+	parameters := []string{}
+	if options.M > 0 {
+		parameters = append(parameters, fmt.Sprintf("m = %d", options.M))
+	}
+	if options.EfConstruction > 0 {
+		parameters = append(parameters, fmt.Sprintf("ef_construction = %d", options.EfConstruction))
+	}
+	index := fmt.Sprintf("CREATE INDEX %s ON %s USING hnsw (value %s)", pq.QuoteIdentifier(tableName+"_hnsw"), pq.QuoteIdentifier(tableName), opclass)
+	if len(parameters) > 0 {
+		index += " WITH (" + strings.Join(parameters, ", ") + ")"
+	}
+	return index, nil
+}
+
+// pgvectorColumnType returns the type of the value column of a table.
+func pgvectorColumnType(valueType ValueType) (string, error) {
+	vectorType, isVector := valueType.(VectorType)
+	if !isVector {
+		return "text", nil
+	}
+	if vectorType.Dimension <= 0 {
+		return "", fmt.Errorf("vector dimension must be positive, got %d", vectorType.Dimension)
+	}
+	return fmt.Sprintf("vector(%d)", vectorType.Dimension), nil
+}
+
+func (store *pgvectorOnlineStore) AsOnlineStore() (OnlineStore, error) {
+	return store, nil
+}
+
+func (store *pgvectorOnlineStore) AsVectorStore() (VectorStore, error) {
+	return store, nil
+}
+
+func (store *pgvectorOnlineStore) Close() error {
+	return store.db.Close()
+}
+
+// pgvectorMetadataRow is a row of the metadata table. Like Redis,
+// materializations create a feature's index and then its table, so IsTable
+// records whether CreateTable has been called for the table.
+type pgvectorMetadataRow struct {
+	ValueType ValueType
+	IsTable   bool
+}
+
+func (store *pgvectorOnlineStore) getMetadata(feature, variant string) (pgvectorMetadataRow, error) {
+	tableName := pgvectorTableName(feature, variant)
+	var serializedType string
+	row := pgvectorMetadataRow{}
+	query := fmt.Sprintf("SELECT value_type, is_table FROM %s WHERE table_name = $1", pq.QuoteIdentifier(pgvectorMetadataTable))
+	err := store.db.QueryRow(query, tableName).Scan(&serializedType, &row.IsTable)
+	if err == sql.ErrNoRows {
+		return row, &TableNotFound{feature, variant}
+	} else if err != nil {
+		return row, fmt.Errorf("could not get metadata of table %s: %w", tableName, err)
+	}
+	valueType, err := deserializeValueType(serializedType)
+	if err != nil {
+		return row, err
+	}
+	row.ValueType = valueType
+	return row, nil
+}
+
+func (store *pgvectorOnlineStore) table(feature, variant string, valueType ValueType) *pgvectorOnlineTable {
+	return &pgvectorOnlineTable{
+		db:        store.db,
+		name:      pgvectorTableName(feature, variant),
+		valueType: valueType,
+	}
+}
+
+func (store *pgvectorOnlineStore) GetTable(feature, variant string) (OnlineStoreTable, error) {
+	metadata, err := store.getMetadata(feature, variant)
+	if err != nil {
+		return nil, err
+	}
+	return store.table(feature, variant, metadata.ValueType), nil
+}
+
+// CreateTable creates the feature variant's table, or marks the table
+// created by CreateIndex as a table.
+func (store *pgvectorOnlineStore) CreateTable(feature, variant string, valueType ValueType) (OnlineStoreTable, error) {
+	metadata, err := store.getMetadata(feature, variant)
+	if _, notFound := err.(*TableNotFound); notFound {
+		return store.createTable(feature, variant, valueType, true)
+	} else if err != nil {
+		return nil, err
+	}
+	if metadata.IsTable {
+		return nil, &TableAlreadyExists{feature, variant}
+	}
+	query := fmt.Sprintf("UPDATE %s SET is_table = true WHERE table_name = $1", pq.QuoteIdentifier(pgvectorMetadataTable))
+	if _, err := store.db.Exec(query, pgvectorTableName(feature, variant)); err != nil {
+		return nil, fmt.Errorf("could not update metadata: %w", err)
+	}
+	return store.table(feature, variant, metadata.ValueType), nil
+}
+
+func (store *pgvectorOnlineStore) CreateIndex(feature, variant string, vectorType VectorType) (VectorStoreTable, error) {
+	if _, err := store.getMetadata(feature, variant); err == nil {
+		return nil, &TableAlreadyExists{feature, variant}
+	} else if _, notFound := err.(*TableNotFound); !notFound {
+		return nil, err
+	}
+	return store.createTable(feature, variant, vectorType, false)
+}
+
+// createTable records the table in the metadata table and creates it in one
+// transaction, so that concurrent creates of the same table can't both
+// succeed.
+func (store *pgvectorOnlineStore) createTable(feature, variant string, valueType ValueType, isTable bool) (*pgvectorOnlineTable, error) {
+	tableName := pgvectorTableName(feature, variant)
+	columnType, err := pgvectorColumnType(valueType)
+	if err != nil {
+		return nil, err
+	}
+	serializedType, err := serializeValueType(valueType)
+	if err != nil {
+		return nil, err
+	}
+	tx, err := store.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+	insert := fmt.Sprintf("INSERT INTO %s (table_name, feature, variant, value_type, is_table) VALUES ($1, $2, $3, $4, $5) ON CONFLICT (table_name) DO NOTHING", pq.QuoteIdentifier(pgvectorMetadataTable))
+	result, err := tx.Exec(insert, tableName, feature, variant, serializedType, isTable)
+	if err != nil {
+		return nil, fmt.Errorf("could not insert metadata: %w", err)
+	}
+	if inserted, err := result.RowsAffected(); err != nil {
+		return nil, err
+	} else if inserted == 0 {
+		return nil, &TableAlreadyExists{feature, variant}
+	}
+	create := fmt.Sprintf("CREATE TABLE %s (entity text PRIMARY KEY, value %s, updated_at timestamptz NOT NULL DEFAULT now(), attributes jsonb NOT NULL DEFAULT '{}', namespace text)", pq.QuoteIdentifier(tableName), columnType)
+	if _, err := tx.Exec(create); err != nil {
+		return nil, fmt.Errorf("could not create table %s: %w", tableName, err)
+	}
+	if vectorType, isVector := valueType.(VectorType); isVector {
+		index, err := pgvectorIndexDefinition(tableName, vectorType)
+		if err != nil {
+			return nil, err
+		}
+		if _, err := tx.Exec(index); err != nil {
+			return nil, fmt.Errorf("could not create vector index on %s: %w", tableName, err)
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return store.table(feature, variant, valueType), nil
+}
+
+func (store *pgvectorOnlineStore) DeleteTable(feature, variant string) error {
+	tableName := pgvectorTableName(feature, variant)
+	tx, err := store.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	query := fmt.Sprintf("DELETE FROM %s WHERE table_name = $1", pq.QuoteIdentifier(pgvectorMetadataTable))
+	result, err := tx.Exec(query, tableName)
+	if err != nil {
+		return fmt.Errorf("could not delete metadata: %w", err)
+	}
+	if deleted, err := result.RowsAffected(); err != nil {
+		return err
+	} else if deleted == 0 {
+		return &TableNotFound{feature, variant}
+	}
+	if _, err := tx.Exec(fmt.Sprintf("DROP TABLE IF EXISTS %s", pq.QuoteIdentifier(tableName))); err != nil {
+		return fmt.Errorf("could not drop table %s: %w", tableName, err)
+	}
+	return tx.Commit()
+}
+
+func (store *pgvectorOnlineStore) Truncate(feature, variant string) error {
+	if _, err := store.getMetadata(feature, variant); err != nil {
+		return err
+	}
+	tableName := pgvectorTableName(feature, variant)
+	if _, err := store.db.Exec(fmt.Sprintf("TRUNCATE %s", pq.QuoteIdentifier(tableName))); err != nil {
+		return fmt.Errorf("could not truncate table %s: %w", tableName, err)
+	}
+	return nil
+}
+
+func (store *pgvectorOnlineStore) Capabilities() OnlineCapabilities {
+	return OnlineCapabilities{
+		BatchWrites:      true,
+		Timestamps:       true,
+		Truncate:         true,
+		Vectors:          true,
+		VectorFilters:    true,
+		VectorNamespaces: true,
+		IndexStats:       true,
+	}
+}
+
+type pgvectorOnlineTable struct {
+	db        *sql.DB
+	name      string
+	valueType ValueType
+}
+
+// serialize converts a value to the text form of the table's value column.
+// pgvector parses vectors from the same JSON array syntax that
+// ParseValue expects.
+func (table *pgvectorOnlineTable) serialize(value interface{}) (interface{}, error) {
+	if value == nil {
+		return nil, nil
+	}
+	if table.valueType.IsVector() {
+		vector, ok := float32Vector(value)
+		if !ok {
+			return nil, fmt.Errorf("value %v is not a vector", value)
+		}
+		encoded, err := json.Marshal(vector)
+		if err != nil {
+			return nil, err
+		}
+		return string(encoded), nil
+	}
+	if composite, isComposite := table.valueType.(compositeType); isComposite {
+		encoded, err := serializeComposite(composite, value)
+		if err != nil {
+			return nil, err
+		}
+		return string(encoded), nil
+	}
+	if t, isTime := value.(time.Time); isTime {
+		return t.Format(time.RFC3339Nano), nil
+	}
+	return fmt.Sprintf("%v", value), nil
+}
+
+func (table *pgvectorOnlineTable) Set(entity string, value interface{}) error {
+	return table.SetBatch(map[string]interface{}{entity: value})
+}
+
+// SetBatch upserts the values with multi-row inserts of up to
+// pgvectorBatchSize rows.
+func (table *pgvectorOnlineTable) SetBatch(values map[string]interface{}) error {
+	rows := make([]string, 0, pgvectorBatchSize)
+	args := make([]interface{}, 0, 2*pgvectorBatchSize)
+	flush := func() error {
+		if len(rows) == 0 {
+			return nil
+		}
+		query := fmt.Sprintf("INSERT INTO %s (entity, value) VALUES %s ON CONFLICT (entity) DO UPDATE SET value = EXCLUDED.value, updated_at = now(), attributes = '{}', namespace = NULL", pq.QuoteIdentifier(table.name), strings.Join(rows, ", "))
+		if _, err := table.db.Exec(query, args...); err != nil {
+			return fmt.Errorf("could not set values in %s: %w", table.name, err)
+		}
+		rows = rows[:0]
+		args = args[:0]
+		return nil
+	}
+	for entity, value := range values {
+		if vector, isVector := float32Vector(value); isVector {
+			if err := checkVectorDimension(table.valueType, entity, vector); err != nil {
+				return err
+			}
+		}
+		serialized, err := table.serialize(value)
+		if err != nil {
+			return err
+		}
+		rows = append(rows, fmt.Sprintf("($%d, $%d)", len(args)+1, len(args)+2))
+		args = append(args, entity, serialized)
+		if len(rows) == pgvectorBatchSize {
+			if err := flush(); err != nil {
+				return err
+			}
+		}
+	}
+	return flush()
+}
+
+// SetWithAttributes upserts the entity's vector and replaces its attributes.
+func (table *pgvectorOnlineTable) SetWithAttributes(entity string, vector []float32, attributes map[string]string) error {
+	if !table.valueType.IsVector() {
+		return fmt.Errorf("table %s stores %v, not vectors", table.name, table.valueType)
+	}
+	if err := checkVectorDimension(table.valueType, entity, vector); err != nil {
+		return err
+	}
+	serialized, err := table.serialize(vector)
+	if err != nil {
+		return err
+	}
+	if attributes == nil {
+		attributes = map[string]string{}
+	}
+	serializedAttributes, err := json.Marshal(attributes)
+	if err != nil {
+		return err
+	}
+	query := fmt.Sprintf("INSERT INTO %s (entity, value, attributes) VALUES ($1, $2, $3) ON CONFLICT (entity) DO UPDATE SET value = EXCLUDED.value, updated_at = now(), attributes = EXCLUDED.attributes, namespace = NULL", pq.QuoteIdentifier(table.name))
+	if _, err := table.db.Exec(query, entity, serialized, string(serializedAttributes)); err != nil {
+		return fmt.Errorf("could not set value in %s: %w", table.name, err)
+	}
+	return nil
+}
+
+// SetInNamespace upserts the entity's vector in the namespace and clears
+// its attributes.
+func (table *pgvectorOnlineTable) SetInNamespace(namespace, entity string, vector []float32) error {
+	if err := validateNamespace(namespace); err != nil {
+		return err
+	}
+	if !table.valueType.IsVector() {
+		return fmt.Errorf("table %s stores %v, not vectors", table.name, table.valueType)
+	}
+	if err := checkVectorDimension(table.valueType, entity, vector); err != nil {
+		return err
+	}
+	serialized, err := table.serialize(vector)
+	if err != nil {
+		return err
+	}
+	query := fmt.Sprintf("INSERT INTO %s (entity, value, namespace) VALUES ($1, $2, $3) ON CONFLICT (entity) DO UPDATE SET value = EXCLUDED.value, updated_at = now(), attributes = '{}', namespace = EXCLUDED.namespace", pq.QuoteIdentifier(table.name))
+	if _, err := table.db.Exec(query, entity, serialized, namespace); err != nil {
+		return fmt.Errorf("could not set value in %s: %w", table.name, err)
+	}
+	return nil
+}
+
+func (table *pgvectorOnlineTable) Get(entity string) (interface{}, error) {
+	value, _, err := table.GetWithTimestamp(entity)
+	return value, err
+}
+
+func (table *pgvectorOnlineTable) GetWithTimestamp(entity string) (interface{}, time.Time, error) {
+	var value sql.NullString
+	var updated time.Time
+	query := fmt.Sprintf("SELECT value::text, updated_at FROM %s WHERE entity = $1", pq.QuoteIdentifier(table.name))
+	err := table.db.QueryRow(query, entity).Scan(&value, &updated)
+	if err == sql.ErrNoRows {
+		return nil, time.Time{}, &EntityNotFound{entity}
+	} else if err != nil {
+		return nil, time.Time{}, fmt.Errorf("could not get value from %s: %w", table.name, err)
+	}
+	if !value.Valid {
+		return nil, updated, nil
+	}
+	parsed, err := ParseValue(table.valueType, value.String)
+	if err != nil {
+		return nil, time.Time{}, err
+	}
+	return parsed, updated, nil
+}
+
+func (table *pgvectorOnlineTable) Delete(entity string) error {
+	query := fmt.Sprintf("DELETE FROM %s WHERE entity = $1", pq.QuoteIdentifier(table.name))
+	if _, err := table.db.Exec(query, entity); err != nil {
+		return fmt.Errorf("could not delete %s from %s: %w", entity, table.name, err)
+	}
+	return nil
+}
+
+// IndexStats counts the table's vectors and reports the on-disk size of its
+// vector index, which Postgres pages into shared buffers when it's searched.
+// The index is building while a CREATE INDEX on the table is in progress.
+func (table *pgvectorOnlineTable) IndexStats() (VectorIndexStats, error) {
+	vectorType, isVector := table.valueType.(VectorType)
+	if !isVector {
+		return VectorIndexStats{}, fmt.Errorf("table %s stores %v, not vectors", table.name, table.valueType)
+	}
+	query := fmt.Sprintf(`SELECT
+		(SELECT count(value) FROM %[1]s),
+		COALESCE(pg_relation_size(to_regclass($1)), 0) + COALESCE(pg_relation_size(to_regclass($2)), 0),
+		(SELECT COALESCE(max(tuples_done::float8 / NULLIF(tuples_total, 0)), 0) FROM pg_stat_progress_create_index WHERE relid = to_regclass($3)),
+		EXISTS (SELECT 1 FROM pg_stat_progress_create_index WHERE relid = to_regclass($3))`, pq.QuoteIdentifier(table.name))
+	stats := VectorIndexStats{Dimension: vectorType.Dimension}
+	err := table.db.QueryRow(query, pq.QuoteIdentifier(table.name+"_hnsw"), pq.QuoteIdentifier(table.name+"_ivfflat"), pq.QuoteIdentifier(table.name)).
+		Scan(&stats.Vectors, &stats.MemoryBytes, &stats.IndexedFraction, &stats.Building)
+	if err != nil {
+		return VectorIndexStats{}, fmt.Errorf("could not get index stats of %s: %w", table.name, err)
+	}
+	if !stats.Building {
+		stats.IndexedFraction = 1
+	}
+	return stats, nil
+}
+
+// pgvectorWhere returns the conditions of a filter and their arguments,
+// numbered after the first arguments of the query.
+func pgvectorWhere(filter VectorFilter, first int) (string, []interface{}, error) {
+	var where strings.Builder
+	args := []interface{}{}
+	if len(filter.Attributes) > 0 {
+		serialized, err := json.Marshal(filter.Attributes)
+		if err != nil {
+			return "", nil, err
+		}
+		args = append(args, string(serialized))
+		fmt.Fprintf(&where, " AND attributes @> $%d::jsonb", first+len(args))
+	}
+	if !filter.UpdatedAfter.IsZero() {
+		args = append(args, filter.UpdatedAfter)
+		fmt.Fprintf(&where, " AND updated_at >= $%d", first+len(args))
+	}
+	if filter.Namespace != "" {
+		args = append(args, filter.Namespace)
+		fmt.Fprintf(&where, " AND namespace = $%d", first+len(args))
+	}
+	return where.String(), args, nil
+}
+
+// Nearest returns the k entities whose vectors are closest to vector by the
+// table's distance metric and that match filter, using the table's vector
+// index. pgvector's operators return distances in NearestResult's units.
+// Postgres filters the rows the index returns, so filters that match few
+// entities can return fewer than k.
+func (table *pgvectorOnlineTable) Nearest(feature, variant string, vector []float32, k int32, filter VectorFilter) ([]NearestResult, error) {
+	return table.nearest(vector, 0, k, filter)
+}
+
+func (table *pgvectorOnlineTable) NearestPage(feature, variant string, vector []float32, pageSize int32, filter VectorFilter, cursor string) (NearestPage, error) {
+	search := func(offset, k int32) ([]NearestResult, error) {
+		return table.nearest(vector, offset, k, filter)
+	}
+	return nearestPage(search, pageSize, cursor)
+}
+
+// nearest returns the k entities after the nearest offset entities.
+func (table *pgvectorOnlineTable) nearest(vector []float32, offset, k int32, filter VectorFilter) ([]NearestResult, error) {
+	vectorType, isVector := table.valueType.(VectorType)
+	if !isVector {
+		return nil, fmt.Errorf("table %s stores %v, not vectors", table.name, table.valueType)
+	}
+	serialized, err := json.Marshal(vector)
+	if err != nil {
+		return nil, err
+	}
+	where, filterArgs, err := pgvectorWhere(filter, 2)
+	if err != nil {
+		return nil, err
+	}
+	operator := pgvectorDistanceMetrics[vectorType.Metric()].operator
+	query := fmt.Sprintf("SELECT entity, value %[3]s $1::vector FROM %[1]s WHERE value IS NOT NULL%[2]s ORDER BY value %[3]s $1::vector LIMIT $2 OFFSET $%[4]d", pq.QuoteIdentifier(table.name), where, operator, len(filterArgs)+3)
+	args := append([]interface{}{string(serialized), k}, filterArgs...)
+	args = append(args, offset)
+	var rows *sql.Rows
+	if ef := vectorType.Options().EfRuntime; ef > 0 {
+		// hnsw.ef_search is set for the transaction only, so it doesn't
+		// leak to other tables through the connection pool.
+		tx, txErr := table.db.Begin()
+		if txErr != nil {
+			return nil, txErr
+		}
+		defer tx.Rollback()
+		if _, err := tx.Exec(fmt.Sprintf("SET LOCAL hnsw.ef_search = %d", ef)); err != nil {
+			return nil, fmt.Errorf("could not set hnsw.ef_search: %w", err)
+		}
+		rows, err = tx.Query(query, args...)
+	} else {
+		rows, err = table.db.Query(query, args...)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("could not search %s: %w", table.name, err)
+	}
+	defer rows.Close()
+	results := make([]NearestResult, 0, k)
+	for rows.Next() {
+		result := NearestResult{}
+		if err := rows.Scan(&result.Entity, &result.Distance); err != nil {
+			return nil, err
+		}
+		results = append(results, result)
+	}
+	return results, rows.Err()
+}
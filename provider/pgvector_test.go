@@ -0,0 +1,135 @@
+package provider
+
+import (
+	"net/url"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	pc "github.com/featureform/provider/provider_config"
+)
+
+func TestPgvectorTableName(t *testing.T) {
+	name := pgvectorTableName(strings.Repeat("feature", 20), "variant")
+	if len(name) > 63 {
+		t.Fatalf("Table name %s is longer than a Postgres identifier", name)
+	}
+	if name != pgvectorTableName(strings.Repeat("feature", 20), "variant") {
+		t.Fatalf("Table names are not deterministic")
+	}
+	if pgvectorTableName("a", "bc") == pgvectorTableName("ab", "c") {
+		t.Fatalf("Different feature variants share a table")
+	}
+}
+
+func TestPgvectorConnectionURL(t *testing.T) {
+	config := &pc.PgvectorConfig{
+		Host:     "db.example.com",
+		Port:     "5432",
+		Username: "user",
+		Password: "p@ss/word",
+		Database: "features",
+	}
+	parsed, err := url.Parse(pgvectorConnectionURL(config))
+	if err != nil {
+		t.Fatalf("Failed to parse connection URL: %s", err)
+	}
+	if password, _ := parsed.User.Password(); password != config.Password {
+		t.Fatalf("Expected password %q, got %q", config.Password, password)
+	}
+	if parsed.Query().Get("sslmode") != "disable" {
+		t.Fatalf("Expected sslmode to default to disable, got %s", parsed.RawQuery)
+	}
+	config.SSLMode = "verify-full"
+	if !strings.HasSuffix(pgvectorConnectionURL(config), "sslmode=verify-full") {
+		t.Fatalf("Expected configured sslmode in %s", pgvectorConnectionURL(config))
+	}
+}
+
+func TestPgvectorColumnType(t *testing.T) {
+	if columnType, err := pgvectorColumnType(Int); err != nil || columnType != "text" {
+		t.Fatalf("Expected text column, got %s %v", columnType, err)
+	}
+	vectorType := VectorType{ScalarType: Float32, Dimension: 3, IsEmbedding: true}
+	if columnType, err := pgvectorColumnType(vectorType); err != nil || columnType != "vector(3)" {
+		t.Fatalf("Expected vector(3) column, got %s %v", columnType, err)
+	}
+	if _, err := pgvectorColumnType(VectorType{ScalarType: Float32}); err == nil {
+		t.Fatalf("Succeeded with a vector of no dimensions")
+	}
+}
+
+func TestPgvectorSerializeRoundTrip(t *testing.T) {
+	now := time.Now().UTC().Truncate(time.Microsecond)
+	tests := []struct {
+		valueType ValueType
+		value     interface{}
+	}{
+		{Int, 5},
+		{Float64, 1.5},
+		{Bool, true},
+		{String, "value"},
+		{Timestamp, now},
+		{ListType{ElementType: Int64}, []int64{1, 2}},
+		{VectorType{ScalarType: Float32, Dimension: 2, IsEmbedding: true}, []float32{0.5, 1}},
+	}
+	for _, tt := range tests {
+		table := &pgvectorOnlineTable{valueType: tt.valueType}
+		serialized, err := table.serialize(tt.value)
+		if err != nil {
+			t.Fatalf("Failed to serialize %v: %s", tt.value, err)
+		}
+		parsed, err := ParseValue(tt.valueType, serialized.(string))
+		if err != nil {
+			t.Fatalf("Failed to parse %v: %s", serialized, err)
+		}
+		if !reflect.DeepEqual(parsed, tt.value) {
+			t.Errorf("Expected %v (%T), got %v (%T)", tt.value, tt.value, parsed, parsed)
+		}
+	}
+}
+
+func TestPgvectorWhere(t *testing.T) {
+	if where, args, err := pgvectorWhere(VectorFilter{}, 2); err != nil || where != "" || len(args) != 0 {
+		t.Fatalf("Expected no conditions, got %q %v %v", where, args, err)
+	}
+	after := time.Date(2023, 4, 1, 0, 0, 0, 0, time.UTC)
+	where, args, err := pgvectorWhere(VectorFilter{Attributes: map[string]string{"tier": "gold"}, UpdatedAfter: after, Namespace: "tenant"}, 2)
+	if err != nil {
+		t.Fatalf("Failed to build conditions: %s", err)
+	}
+	if expected := " AND attributes @> $3::jsonb AND updated_at >= $4 AND namespace = $5"; where != expected {
+		t.Fatalf("Expected %s, got %s", expected, where)
+	}
+	if !reflect.DeepEqual(args, []interface{}{`{"tier":"gold"}`, after, "tenant"}) {
+		t.Fatalf("Unexpected arguments %v", args)
+	}
+}
+
+func TestPgvectorIndexDefinition(t *testing.T) {
+	tests := []struct {
+		name     string
+		options  *IndexOptions
+		expected string
+		wantErr  bool
+	}{
+		{"Default", nil, `CREATE INDEX "t_hnsw" ON "t" USING hnsw (value vector_cosine_ops)`, false},
+		{"HNSW", &IndexOptions{M: 32, EfConstruction: 400, EfRuntime: 100}, `CREATE INDEX "t_hnsw" ON "t" USING hnsw (value vector_cosine_ops) WITH (m = 32, ef_construction = 400)`, false},
+		{"IVF", &IndexOptions{NLists: 100}, `CREATE INDEX "t_ivfflat" ON "t" USING ivfflat (value vector_cosine_ops) WITH (lists = 100)`, false},
+		{"IVF With HNSW Options", &IndexOptions{NLists: 100, M: 16}, "", true},
+		{"Negative", &IndexOptions{EfConstruction: -1}, "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			vectorType := VectorType{ScalarType: Float32, Dimension: 3, Index: tt.options}
+			index, err := pgvectorIndexDefinition("t", vectorType)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Expected error %v, got %v", tt.wantErr, err)
+			}
+			if index != tt.expected {
+				t.Fatalf("Expected %s, got %s", tt.expected, index)
+			}
+		})
+	}
+}
@@ -27,11 +27,15 @@ func postgresOfflineStoreFactory(config pc.SerializedConfig) (Provider, error) {
 	if err := sc.Deserialize(config); err != nil {
 		return nil, fmt.Errorf("invalid postgres config: %v", config)
 	}
+	sslMode := sc.SSLMode
+	if sslMode == "" {
+		sslMode = "disable"
+	}
 	queries := postgresSQLQueries{}
 	queries.setVariableBinding(PostgresBindingStyle)
 	sgConfig := SQLOfflineStoreConfig{
 		Config:        config,
-		ConnectionURL: fmt.Sprintf("postgres://%s:%s@%s:%s/%s?sslmode=disable", sc.Username, sc.Password, sc.Host, sc.Port, sc.Database),
+		ConnectionURL: fmt.Sprintf("postgres://%s:%s@%s:%s/%s?sslmode=%s", sc.Username, sc.Password, sc.Host, sc.Port, sc.Database, sslMode),
 		Driver:        "postgres",
 		ProviderType:  pt.PostgresOffline,
 		QueryImpl:     &queries,
@@ -59,8 +63,14 @@ func (q postgresSQLQueries) viewExists() string {
 func (q postgresSQLQueries) registerResources(db *sql.DB, tableName string, schema ResourceSchema, timestamp bool) error {
 	var query string
 	if timestamp {
+		ts := sanitize(schema.TS)
+		if schema.TSTimezone != "" {
+			// AT TIME ZONE reads a timestamp without time zone as a time in the
+			// zone and returns it with the time zone.
+			ts = fmt.Sprintf("(%s AT TIME ZONE '%s')", ts, schema.TSTimezone)
+		}
 		query = fmt.Sprintf("CREATE VIEW %s AS SELECT %s as entity, %s as value, %s as ts FROM %s", sanitize(tableName),
-			sanitize(schema.Entity), sanitize(schema.Value), sanitize(schema.TS), sanitize(schema.SourceTable))
+			sanitize(schema.Entity), sanitize(schema.Value), ts, sanitize(schema.SourceTable))
 	} else {
 		query = fmt.Sprintf("CREATE VIEW %s AS SELECT %s as entity, %s as value, to_timestamp('%s', 'YYYY-DD-MM HH24:MI:SS +0000 UTC')::TIMESTAMPTZ as ts FROM %s", sanitize(tableName),
 			sanitize(schema.Entity), sanitize(schema.Value), time.UnixMilli(0).UTC(), sanitize(schema.SourceTable))
@@ -88,6 +98,10 @@ func (q postgresSQLQueries) materializationUpdate(db *sql.DB, tableName string,
 	return err
 }
 
+func (q postgresSQLQueries) materializationApproxNumRows() string {
+	return "SELECT reltuples::bigint FROM pg_class WHERE relname = $1"
+}
+
 func (q postgresSQLQueries) materializationExists() string {
 	return "SELECT * FROM pg_matviews WHERE matviewname = $1"
 }
@@ -241,3 +255,22 @@ func (q postgresSQLQueries) getColumns(db *sql.DB, tableName string) ([]TableCol
 	}
 	return columnNames, nil
 }
+
+// maintenance vacuums and analyzes tables by default. Postgres has no
+// clustering keys, so ClusterColumns is ignored.
+func (q postgresSQLQueries) maintenance(tableName string, config MaintenanceConfig) ([]string, error) {
+	operations := config.Operations
+	if len(operations) == 0 {
+		operations = []MaintenanceOperation{VacuumOperation, AnalyzeOperation}
+	}
+	statements := make([]string, len(operations))
+	for i, op := range operations {
+		switch op {
+		case VacuumOperation, AnalyzeOperation:
+			statements[i] = fmt.Sprintf("%s %s", op, sanitize(tableName))
+		default:
+			return nil, &UnsupportedMaintenanceOperation{op, string(pt.PostgresOffline)}
+		}
+	}
+	return statements, nil
+}
@@ -13,20 +13,31 @@ import (
 
 func init() {
 	unregisteredFactories := map[pt.Type]Factory{
-		pt.LocalOnline:      localOnlineStoreFactory,
-		pt.RedisOnline:      redisOnlineStoreFactory,
-		pt.CassandraOnline:  cassandraOnlineStoreFactory,
-		pt.FirestoreOnline:  firestoreOnlineStoreFactory,
-		pt.DynamoDBOnline:   dynamodbOnlineStoreFactory,
-		pt.MemoryOffline:    memoryOfflineStoreFactory,
-		pt.PostgresOffline:  postgresOfflineStoreFactory,
-		pt.SnowflakeOffline: snowflakeOfflineStoreFactory,
-		pt.RedshiftOffline:  redshiftOfflineStoreFactory,
-		pt.BigQueryOffline:  bigQueryOfflineStoreFactory,
-		pt.SparkOffline:     sparkOfflineStoreFactory,
-		pt.K8sOffline:       k8sOfflineStoreFactory,
-		pt.BlobOnline:       blobOnlineStoreFactory,
-		pt.MongoDBOnline:    mongoOnlineStoreFactory,
+		pt.LocalOnline:          localOnlineStoreFactory,
+		pt.RedisOnline:          redisOnlineStoreFactory,
+		pt.CassandraOnline:      cassandraOnlineStoreFactory,
+		pt.FirestoreOnline:      firestoreOnlineStoreFactory,
+		pt.DynamoDBOnline:       dynamodbOnlineStoreFactory,
+		pt.MemoryOffline:        memoryOfflineStoreFactory,
+		pt.PostgresOffline:      postgresOfflineStoreFactory,
+		pt.SnowflakeOffline:     snowflakeOfflineStoreFactory,
+		pt.RedshiftOffline:      redshiftOfflineStoreFactory,
+		pt.ClickHouseOffline:    clickHouseOfflineStoreFactory,
+		pt.AthenaOffline:        athenaOfflineStoreFactory,
+		pt.DatabricksSQLOffline: databricksSQLOfflineStoreFactory,
+		pt.BigQueryOffline:      bigQueryOfflineStoreFactory,
+		pt.SparkOffline:         sparkOfflineStoreFactory,
+		pt.K8sOffline:           k8sOfflineStoreFactory,
+		pt.BlobOnline:           blobOnlineStoreFactory,
+		pt.MongoDBOnline:        mongoOnlineStoreFactory,
+		pt.DualWriteOnline:      dualWriteOnlineStoreFactory,
+		pt.ShardedRedisOnline:   shardedRedisOnlineStoreFactory,
+		pt.WeaviateOnline:       weaviateOnlineStoreFactory,
+		pt.PgvectorOnline:       pgvectorOnlineStoreFactory,
+		pt.OpenSearchOnline:     openSearchOnlineStoreFactory,
+		pt.ChromaOnline:         chromaOnlineStoreFactory,
+		pt.VespaOnline:          vespaOnlineStoreFactory,
+		pt.Kafka:                kafkaProviderFactory,
 	}
 	for name, factory := range unregisteredFactories {
 		if err := RegisterFactory(name, factory); err != nil {
@@ -0,0 +1,52 @@
+package provider_config
+
+import (
+	"encoding/json"
+
+	ss "github.com/featureform/helpers/string_set"
+)
+
+// AthenaConfig configures an offline store that queries S3 with Athena and
+// keeps its tables in a data catalog such as Glue.
+type AthenaConfig struct {
+	Region    string
+	AccessKey string
+	SecretKey string
+	// Workgroup runs the queries. It defaults to Athena's primary workgroup.
+	Workgroup string
+	// OutputLocation is the S3 URI, such as s3://bucket/featureform/, that
+	// query results and the tables the provider creates are written to.
+	OutputLocation string
+	// Catalog is the data catalog. It defaults to the account's Glue catalog.
+	Catalog  string
+	Database string
+}
+
+func (a *AthenaConfig) Deserialize(config SerializedConfig) error {
+	err := json.Unmarshal(config, a)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (a *AthenaConfig) Serialize() []byte {
+	conf, err := json.Marshal(a)
+	if err != nil {
+		panic(err)
+	}
+	return conf
+}
+
+func (a AthenaConfig) MutableFields() ss.StringSet {
+	return ss.StringSet{
+		"AccessKey":      true,
+		"SecretKey":      true,
+		"Workgroup":      true,
+		"OutputLocation": true,
+	}
+}
+
+func (a AthenaConfig) DifferingFields(b AthenaConfig) (ss.StringSet, error) {
+	return differingFields(a, b)
+}
@@ -0,0 +1,68 @@
+package provider_config
+
+import (
+	"reflect"
+	"testing"
+
+	ss "github.com/featureform/helpers/string_set"
+)
+
+func TestAthenaConfigMutableFields(t *testing.T) {
+	expected := ss.StringSet{
+		"AccessKey":      true,
+		"SecretKey":      true,
+		"Workgroup":      true,
+		"OutputLocation": true,
+	}
+
+	config := AthenaConfig{
+		Region:         "us-east-1",
+		AccessKey:      "access-key",
+		SecretKey:      "secret-key",
+		OutputLocation: "s3://bucket/featureform/",
+		Database:       "default",
+	}
+	actual := config.MutableFields()
+
+	if !reflect.DeepEqual(expected, actual) {
+		t.Errorf("Expected %v but received %v", expected, actual)
+	}
+}
+
+func TestAthenaConfigDifferingFields(t *testing.T) {
+	base := AthenaConfig{
+		Region:         "us-east-1",
+		AccessKey:      "access-key",
+		SecretKey:      "secret-key",
+		OutputLocation: "s3://bucket/featureform/",
+		Database:       "default",
+	}
+	changed := base
+	changed.Workgroup = "featureform"
+	changed.Database = "features"
+
+	tests := []struct {
+		name     string
+		a        AthenaConfig
+		b        AthenaConfig
+		expected ss.StringSet
+	}{
+		{"No Differing Fields", base, base, ss.StringSet{}},
+		{"Differing Fields", base, changed, ss.StringSet{
+			"Workgroup": true,
+			"Database":  true,
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			actual, err := tt.a.DifferingFields(tt.b)
+			if err != nil {
+				t.Errorf("Failed to get differing fields due to error: %v", err)
+			}
+			if !reflect.DeepEqual(actual, tt.expected) {
+				t.Errorf("Expected %v, but instead found %v", tt.expected, actual)
+			}
+		})
+	}
+}
@@ -7,9 +7,31 @@ import (
 )
 
 type BigQueryConfig struct {
-	ProjectId   string
-	DatasetId   string
+	ProjectId string
+	DatasetId string
+	// Credentials is a service account key. If it's empty, Application
+	// Default Credentials are used instead, such as a GKE workload identity.
 	Credentials map[string]interface{}
+	// Labels are applied to the dataset, such as a team or cost center for
+	// cost allocation. Keys and values must be lowercase.
+	Labels map[string]string
+	// JobLabels are applied to every query job, so that the cost of each job
+	// can be attributed. Keys and values must be lowercase.
+	JobLabels map[string]string
+	// JobProjectId is the project that query jobs run in and are billed to,
+	// which routes them to the slot reservation assigned to that project. It
+	// defaults to ProjectId, the project of the dataset.
+	JobProjectId string
+	// Location is the region of the dataset, which query jobs run in.
+	Location string
+}
+
+// JobProject is the project that query jobs run in.
+func (bq *BigQueryConfig) JobProject() string {
+	if bq.JobProjectId != "" {
+		return bq.JobProjectId
+	}
+	return bq.ProjectId
 }
 
 func (bq *BigQueryConfig) Deserialize(config SerializedConfig) error {
@@ -30,7 +52,10 @@ func (bq *BigQueryConfig) Serialize() []byte {
 
 func (bq BigQueryConfig) MutableFields() ss.StringSet {
 	return ss.StringSet{
-		"Credentials": true,
+		"Credentials":  true,
+		"Labels":       true,
+		"JobLabels":    true,
+		"JobProjectId": true,
 	}
 }
 
@@ -11,7 +11,10 @@ import (
 
 func TestBigQueryConfigMutableFields(t *testing.T) {
 	expected := ss.StringSet{
-		"Credentials": true,
+		"Credentials":  true,
+		"Labels":       true,
+		"JobLabels":    true,
+		"JobProjectId": true,
 	}
 
 	config := BigQueryConfig{
@@ -99,3 +102,14 @@ func TestBigQueryConfigDifferingFields(t *testing.T) {
 	}
 
 }
+
+func TestBigQueryConfigJobProject(t *testing.T) {
+	config := BigQueryConfig{ProjectId: "ff-gcp-proj-id", DatasetId: "transactions-ds"}
+	if project := config.JobProject(); project != "ff-gcp-proj-id" {
+		t.Errorf("Expected jobs to run in the dataset's project, got %s", project)
+	}
+	config.JobProjectId = "ff-gcp-reservation-proj-id"
+	if project := config.JobProject(); project != "ff-gcp-reservation-proj-id" {
+		t.Errorf("Expected jobs to run in the job project, got %s", project)
+	}
+}
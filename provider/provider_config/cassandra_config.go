@@ -13,6 +13,8 @@ type CassandraConfig struct {
 	Password    string
 	Consistency string
 	Replication int
+	// TLS connects to Cassandra over TLS, verifying the hosts' certificates.
+	TLS bool
 }
 
 func (cass CassandraConfig) Serialized() SerializedConfig {
@@ -37,6 +39,7 @@ func (cass CassandraConfig) MutableFields() ss.StringSet {
 		"Password":    true,
 		"Consistency": true,
 		"Replication": true,
+		"TLS":         true,
 	}
 }
 
@@ -13,6 +13,7 @@ func TestCassandraConfigMutableFields(t *testing.T) {
 		"Password":    true,
 		"Consistency": true,
 		"Replication": true,
+		"TLS":         true,
 	}
 
 	config := CassandraConfig{
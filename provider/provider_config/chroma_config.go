@@ -0,0 +1,45 @@
+package provider_config
+
+import (
+	"encoding/json"
+
+	ss "github.com/featureform/helpers/string_set"
+)
+
+// ChromaConfig configures a Chroma vector store. APIKey is sent as a bearer
+// token if it's set. Tenant and Database select where collections are
+// created, and default to Chroma's defaults.
+type ChromaConfig struct {
+	// URL is the scheme, host and port of the Chroma server, such as
+	// "http://localhost:8000".
+	URL      string
+	APIKey   string
+	Tenant   string
+	Database string
+}
+
+func (c ChromaConfig) Serialized() SerializedConfig {
+	config, err := json.Marshal(c)
+	if err != nil {
+		panic(err)
+	}
+	return config
+}
+
+func (c *ChromaConfig) Deserialize(config SerializedConfig) error {
+	err := json.Unmarshal(config, c)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (c ChromaConfig) MutableFields() ss.StringSet {
+	return ss.StringSet{
+		"APIKey": true,
+	}
+}
+
+func (a ChromaConfig) DifferingFields(b ChromaConfig) (ss.StringSet, error) {
+	return differingFields(a, b)
+}
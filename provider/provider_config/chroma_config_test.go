@@ -0,0 +1,48 @@
+package provider_config
+
+import (
+	"reflect"
+	"testing"
+
+	ss "github.com/featureform/helpers/string_set"
+)
+
+func TestChromaConfigMutableFields(t *testing.T) {
+	expected := ss.StringSet{
+		"APIKey": true,
+	}
+
+	config := ChromaConfig{
+		URL:    "http://localhost:8000",
+		APIKey: "key",
+	}
+	actual := config.MutableFields()
+
+	if !reflect.DeepEqual(expected, actual) {
+		t.Errorf("Expected %v but received %v", expected, actual)
+	}
+}
+
+func TestChromaConfigDifferingFields(t *testing.T) {
+	a := ChromaConfig{
+		URL:    "http://localhost:8000",
+		APIKey: "key",
+	}
+	b := ChromaConfig{
+		URL:      "http://localhost:8000",
+		Tenant:   "team",
+		Database: "features",
+	}
+	expected := ss.StringSet{
+		"APIKey":   true,
+		"Tenant":   true,
+		"Database": true,
+	}
+	actual, err := a.DifferingFields(b)
+	if err != nil {
+		t.Fatalf("Failed to get differing fields due to error: %v", err)
+	}
+	if !reflect.DeepEqual(actual, expected) {
+		t.Errorf("Expected %v, but instead found %v", expected, actual)
+	}
+}
@@ -0,0 +1,46 @@
+package provider_config
+
+import (
+	"encoding/json"
+
+	ss "github.com/featureform/helpers/string_set"
+)
+
+type ClickHouseConfig struct {
+	Host     string
+	Port     string
+	Username string
+	Password string
+	Database string
+	// SSL connects over the secure native protocol port.
+	SSL bool
+}
+
+func (ch *ClickHouseConfig) Deserialize(config SerializedConfig) error {
+	err := json.Unmarshal(config, ch)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (ch *ClickHouseConfig) Serialize() []byte {
+	conf, err := json.Marshal(ch)
+	if err != nil {
+		panic(err)
+	}
+	return conf
+}
+
+func (ch ClickHouseConfig) MutableFields() ss.StringSet {
+	return ss.StringSet{
+		"Username": true,
+		"Password": true,
+		"Port":     true,
+		"SSL":      true,
+	}
+}
+
+func (a ClickHouseConfig) DifferingFields(b ClickHouseConfig) (ss.StringSet, error) {
+	return differingFields(a, b)
+}
@@ -0,0 +1,97 @@
+package provider_config
+
+import (
+	"reflect"
+	"testing"
+
+	ss "github.com/featureform/helpers/string_set"
+)
+
+func TestClickHouseConfigMutableFields(t *testing.T) {
+	expected := ss.StringSet{
+		"Username": true,
+		"Password": true,
+		"Port":     true,
+		"SSL":      true,
+	}
+
+	config := ClickHouseConfig{
+		Host:     "0.0.0.0",
+		Port:     "9000",
+		Username: "default",
+		Password: "password",
+		Database: "default",
+	}
+	actual := config.MutableFields()
+
+	if !reflect.DeepEqual(expected, actual) {
+		t.Errorf("Expected %v but received %v", expected, actual)
+	}
+}
+
+func TestClickHouseConfigDifferingFields(t *testing.T) {
+	type args struct {
+		a ClickHouseConfig
+		b ClickHouseConfig
+	}
+
+	tests := []struct {
+		name     string
+		args     args
+		expected ss.StringSet
+	}{
+		{"No Differing Fields", args{
+			a: ClickHouseConfig{
+				Host:     "0.0.0.0",
+				Port:     "9000",
+				Username: "default",
+				Password: "password",
+				Database: "default",
+			},
+			b: ClickHouseConfig{
+				Host:     "0.0.0.0",
+				Port:     "9000",
+				Username: "default",
+				Password: "password",
+				Database: "default",
+			},
+		}, ss.StringSet{}},
+		{"Differing Fields", args{
+			a: ClickHouseConfig{
+				Host:     "0.0.0.0",
+				Port:     "9000",
+				Username: "default",
+				Password: "password",
+				Database: "default",
+			},
+			b: ClickHouseConfig{
+				Host:     "clickhouse.featureform.com",
+				Port:     "9440",
+				Username: "default",
+				Password: "password",
+				Database: "default",
+				SSL:      true,
+			},
+		}, ss.StringSet{
+			"Host": true,
+			"Port": true,
+			"SSL":  true,
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			actual, err := tt.args.a.DifferingFields(tt.args.b)
+
+			if err != nil {
+				t.Errorf("Failed to get differing fields due to error: %v", err)
+			}
+
+			if !reflect.DeepEqual(actual, tt.expected) {
+				t.Errorf("Expected %v, but instead found %v", tt.expected, actual)
+			}
+
+		})
+	}
+
+}
@@ -0,0 +1,48 @@
+package provider_config
+
+import (
+	"encoding/json"
+
+	ss "github.com/featureform/helpers/string_set"
+)
+
+// DatabricksSQLConfig configures an offline store that runs its queries on a
+// Databricks SQL warehouse and keeps its tables in a Unity Catalog schema.
+type DatabricksSQLConfig struct {
+	// Host is the workspace's hostname, such as
+	// dbc-a1b2c3d4-e5f6.cloud.databricks.com.
+	Host  string
+	Token string
+	// WarehouseID is the ID of the SQL warehouse that runs the queries.
+	WarehouseID string
+	Catalog     string
+	// Schema defaults to the catalog's default schema.
+	Schema string
+}
+
+func (d *DatabricksSQLConfig) Deserialize(config SerializedConfig) error {
+	err := json.Unmarshal(config, d)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (d *DatabricksSQLConfig) Serialize() []byte {
+	conf, err := json.Marshal(d)
+	if err != nil {
+		panic(err)
+	}
+	return conf
+}
+
+func (d DatabricksSQLConfig) MutableFields() ss.StringSet {
+	return ss.StringSet{
+		"Token":       true,
+		"WarehouseID": true,
+	}
+}
+
+func (d DatabricksSQLConfig) DifferingFields(b DatabricksSQLConfig) (ss.StringSet, error) {
+	return differingFields(d, b)
+}
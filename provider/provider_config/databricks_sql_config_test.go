@@ -0,0 +1,66 @@
+package provider_config
+
+import (
+	"reflect"
+	"testing"
+
+	ss "github.com/featureform/helpers/string_set"
+)
+
+func TestDatabricksSQLConfigMutableFields(t *testing.T) {
+	expected := ss.StringSet{
+		"Token":       true,
+		"WarehouseID": true,
+	}
+
+	config := DatabricksSQLConfig{
+		Host:        "dbc-a1b2c3d4-e5f6.cloud.databricks.com",
+		Token:       "dapi-token",
+		WarehouseID: "1234567890abcdef",
+		Catalog:     "main",
+		Schema:      "featureform",
+	}
+	actual := config.MutableFields()
+
+	if !reflect.DeepEqual(expected, actual) {
+		t.Errorf("Expected %v but received %v", expected, actual)
+	}
+}
+
+func TestDatabricksSQLConfigDifferingFields(t *testing.T) {
+	base := DatabricksSQLConfig{
+		Host:        "dbc-a1b2c3d4-e5f6.cloud.databricks.com",
+		Token:       "dapi-token",
+		WarehouseID: "1234567890abcdef",
+		Catalog:     "main",
+		Schema:      "featureform",
+	}
+	changed := base
+	changed.WarehouseID = "fedcba0987654321"
+	changed.Catalog = "features"
+
+	tests := []struct {
+		name     string
+		a        DatabricksSQLConfig
+		b        DatabricksSQLConfig
+		expected ss.StringSet
+	}{
+		{"No Differing Fields", base, base, ss.StringSet{}},
+		{"Differing Fields", base, changed, ss.StringSet{
+			"WarehouseID": true,
+			"Catalog":     true,
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			actual, err := tt.a.DifferingFields(tt.b)
+			if err != nil {
+				t.Errorf("Failed to get differing fields due to error: %v", err)
+			}
+			if !reflect.DeepEqual(actual, tt.expected) {
+				t.Errorf("Expected %v, but instead found %v", tt.expected, actual)
+			}
+		})
+	}
+}
@@ -0,0 +1,74 @@
+package provider_config
+
+import (
+	"encoding/json"
+	"fmt"
+
+	ss "github.com/featureform/helpers/string_set"
+)
+
+// OnlineStoreConfig identifies one of the online stores of a dual-write
+// provider by its provider type and config.
+type OnlineStoreConfig struct {
+	Type   string
+	Config json.RawMessage
+}
+
+// DualWriteConfig configures a provider that writes to both a primary and a
+// secondary online store while reading only from the primary. It's used to
+// migrate between online stores: swapping Primary and Secondary cuts reads
+// over, and removing the Secondary ends the migration.
+type DualWriteConfig struct {
+	Primary OnlineStoreConfig
+	// Secondary is optional; if its Type is empty, writes only go to Primary.
+	Secondary OnlineStoreConfig
+	// TolerateSecondaryErrors keeps failed secondary writes from failing the
+	// write, so an unhealthy new store can't break materializations during a
+	// migration. Failures are counted as divergence instead.
+	TolerateSecondaryErrors bool
+	// CompareReadRate is the fraction of reads that are also read from the
+	// secondary and compared with the primary's value. Zero disables
+	// comparisons.
+	CompareReadRate float64
+}
+
+func (d DualWriteConfig) Serialized() SerializedConfig {
+	config, err := json.Marshal(d)
+	if err != nil {
+		panic(err)
+	}
+	return config
+}
+
+func (d *DualWriteConfig) Deserialize(config SerializedConfig) error {
+	err := json.Unmarshal(config, d)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+// CutOver returns the config with the stores swapped, so that reads are
+// served by the secondary while writes still go to both stores. Swapping them
+// back rolls the cut over back.
+func (d DualWriteConfig) CutOver() (DualWriteConfig, error) {
+	if d.Secondary.Type == "" {
+		return DualWriteConfig{}, fmt.Errorf("can't cut over without a secondary online store")
+	}
+	d.Primary, d.Secondary = d.Secondary, d.Primary
+	return d, nil
+}
+
+// Both stores can be changed, since that's how a migration progresses.
+func (d DualWriteConfig) MutableFields() ss.StringSet {
+	return ss.StringSet{
+		"Primary":                 true,
+		"Secondary":               true,
+		"TolerateSecondaryErrors": true,
+		"CompareReadRate":         true,
+	}
+}
+
+func (a DualWriteConfig) DifferingFields(b DualWriteConfig) (ss.StringSet, error) {
+	return differingFields(a, b)
+}
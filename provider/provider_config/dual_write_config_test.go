@@ -0,0 +1,72 @@
+package provider_config
+
+import (
+	"reflect"
+	"testing"
+
+	ss "github.com/featureform/helpers/string_set"
+)
+
+func TestDualWriteConfigSerialization(t *testing.T) {
+	config := DualWriteConfig{
+		Primary:   OnlineStoreConfig{Type: "CASSANDRA_ONLINE", Config: []byte(`{"Keyspace":"ff"}`)},
+		Secondary: OnlineStoreConfig{Type: "REDIS_ONLINE", Config: []byte(`{"Addr":"0.0.0.0:6379"}`)},
+	}
+	actual := DualWriteConfig{}
+	if err := actual.Deserialize(config.Serialized()); err != nil {
+		t.Fatalf("Failed to deserialize config: %s", err)
+	}
+	if !reflect.DeepEqual(config, actual) {
+		t.Errorf("Expected %v but received %v", config, actual)
+	}
+}
+
+func TestDualWriteConfigDifferingFields(t *testing.T) {
+	cassandra := OnlineStoreConfig{Type: "CASSANDRA_ONLINE", Config: []byte(`{"Keyspace":"ff"}`)}
+	redis := OnlineStoreConfig{Type: "REDIS_ONLINE", Config: []byte(`{"Addr":"0.0.0.0:6379"}`)}
+	tests := []struct {
+		name     string
+		a, b     DualWriteConfig
+		expected ss.StringSet
+	}{
+		{"No Differing Fields", DualWriteConfig{Primary: cassandra, Secondary: redis}, DualWriteConfig{Primary: cassandra, Secondary: redis}, ss.StringSet{}},
+		{"Cut Over", DualWriteConfig{Primary: cassandra, Secondary: redis}, DualWriteConfig{Primary: redis, Secondary: cassandra}, ss.StringSet{
+			"Primary":   true,
+			"Secondary": true,
+		}},
+		{"Migration Finished", DualWriteConfig{Primary: redis, Secondary: cassandra}, DualWriteConfig{Primary: redis}, ss.StringSet{
+			"Secondary": true,
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			actual, err := tt.a.DifferingFields(tt.b)
+			if err != nil {
+				t.Errorf("Failed to get differing fields due to error: %v", err)
+			}
+			if !reflect.DeepEqual(actual, tt.expected) {
+				t.Errorf("Expected %v, but instead found %v", tt.expected, actual)
+			}
+			if !tt.a.MutableFields().Contains(actual) {
+				t.Errorf("Expected %v to be mutable", actual)
+			}
+		})
+	}
+}
+
+func TestDualWriteConfigCutOver(t *testing.T) {
+	cassandra := OnlineStoreConfig{Type: "CASSANDRA_ONLINE", Config: []byte(`{"Keyspace":"ff"}`)}
+	redis := OnlineStoreConfig{Type: "REDIS_ONLINE", Config: []byte(`{"Addr":"0.0.0.0:6379"}`)}
+	config := DualWriteConfig{Primary: cassandra, Secondary: redis, TolerateSecondaryErrors: true}
+	cutOver, err := config.CutOver()
+	if err != nil {
+		t.Fatalf("Failed to cut over: %s", err)
+	}
+	expected := DualWriteConfig{Primary: redis, Secondary: cassandra, TolerateSecondaryErrors: true}
+	if !reflect.DeepEqual(cutOver, expected) {
+		t.Errorf("Expected %v but received %v", expected, cutOver)
+	}
+	if _, err := (DualWriteConfig{Primary: cassandra}).CutOver(); err == nil {
+		t.Errorf("Succeeded in cutting over without a secondary")
+	}
+}
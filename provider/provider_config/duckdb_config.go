@@ -0,0 +1,38 @@
+package provider_config
+
+import (
+	"encoding/json"
+
+	ss "github.com/featureform/helpers/string_set"
+)
+
+// DuckDBConfig configures an offline store in an embedded DuckDB database.
+type DuckDBConfig struct {
+	// Path is the database file. If it's empty, the database is in memory
+	// and lost when the provider is closed.
+	Path string
+}
+
+func (dk *DuckDBConfig) Deserialize(config SerializedConfig) error {
+	err := json.Unmarshal(config, dk)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (dk *DuckDBConfig) Serialize() []byte {
+	conf, err := json.Marshal(dk)
+	if err != nil {
+		panic(err)
+	}
+	return conf
+}
+
+func (dk DuckDBConfig) MutableFields() ss.StringSet {
+	return ss.StringSet{}
+}
+
+func (a DuckDBConfig) DifferingFields(b DuckDBConfig) (ss.StringSet, error) {
+	return differingFields(a, b)
+}
@@ -0,0 +1,41 @@
+package provider_config
+
+import (
+	"reflect"
+	"testing"
+
+	ss "github.com/featureform/helpers/string_set"
+)
+
+func TestDuckDBConfigMutableFields(t *testing.T) {
+	config := DuckDBConfig{Path: "featureform.duckdb"}
+	actual := config.MutableFields()
+
+	if !reflect.DeepEqual(ss.StringSet{}, actual) {
+		t.Errorf("Expected no mutable fields but received %v", actual)
+	}
+}
+
+func TestDuckDBConfigDifferingFields(t *testing.T) {
+	tests := []struct {
+		name     string
+		a        DuckDBConfig
+		b        DuckDBConfig
+		expected ss.StringSet
+	}{
+		{"No Differing Fields", DuckDBConfig{Path: "featureform.duckdb"}, DuckDBConfig{Path: "featureform.duckdb"}, ss.StringSet{}},
+		{"Differing Fields", DuckDBConfig{Path: "featureform.duckdb"}, DuckDBConfig{}, ss.StringSet{"Path": true}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			actual, err := tt.a.DifferingFields(tt.b)
+			if err != nil {
+				t.Errorf("Failed to get differing fields due to error: %v", err)
+			}
+			if !reflect.DeepEqual(actual, tt.expected) {
+				t.Errorf("Expected %v, but instead found %v", tt.expected, actual)
+			}
+		})
+	}
+}
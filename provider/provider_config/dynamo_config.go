@@ -11,6 +11,9 @@ type DynamodbConfig struct {
 	Region    string
 	AccessKey string
 	SecretKey string
+	// Tags are applied to every table the provider creates, such as a team or
+	// cost center for cost allocation.
+	Tags map[string]string
 }
 
 func (d DynamodbConfig) Serialized() SerializedConfig {
@@ -33,6 +36,7 @@ func (d DynamodbConfig) MutableFields() ss.StringSet {
 	return ss.StringSet{
 		"AccessKey": true,
 		"SecretKey": true,
+		"Tags":      true,
 	}
 }
 
@@ -11,6 +11,7 @@ func TestDynamoConfigMutableFields(t *testing.T) {
 	expected := ss.StringSet{
 		"AccessKey": true,
 		"SecretKey": true,
+		"Tags":      true,
 	}
 
 	config := DynamodbConfig{
@@ -0,0 +1,46 @@
+package provider_config
+
+import (
+	"encoding/json"
+
+	ss "github.com/featureform/helpers/string_set"
+)
+
+type EMRServerlessConfig struct {
+	Credentials      AWSCredentials
+	Region           string
+	ApplicationId    string
+	ExecutionRoleArn string
+}
+
+func (e *EMRServerlessConfig) Deserialize(config SerializedConfig) error {
+	err := json.Unmarshal(config, e)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (e *EMRServerlessConfig) Serialize() ([]byte, error) {
+	conf, err := json.Marshal(e)
+	if err != nil {
+		return nil, err
+	}
+	return conf, nil
+}
+
+func (e *EMRServerlessConfig) IsExecutorConfig() bool {
+	return true
+}
+
+func (e EMRServerlessConfig) MutableFields() ss.StringSet {
+	return ss.StringSet{
+		"Credentials":      true,
+		"ApplicationId":    true,
+		"ExecutionRoleArn": true,
+	}
+}
+
+func (a EMRServerlessConfig) DifferingFields(b EMRServerlessConfig) (ss.StringSet, error) {
+	return differingFields(a, b)
+}
@@ -0,0 +1,90 @@
+package provider_config
+
+import (
+	"reflect"
+	"testing"
+
+	ss "github.com/featureform/helpers/string_set"
+)
+
+func TestEMRServerlessConfigMutableFields(t *testing.T) {
+	expected := ss.StringSet{
+		"Credentials":      true,
+		"ApplicationId":    true,
+		"ExecutionRoleArn": true,
+	}
+
+	config := EMRServerlessConfig{
+		Credentials:      AWSCredentials{AWSAccessKeyId: "aws-key", AWSSecretKey: "aws-secret"},
+		Region:           "us-east-1",
+		ApplicationId:    "00f0abcdef123456",
+		ExecutionRoleArn: "arn:aws:iam::123456789012:role/featureform",
+	}
+	actual := config.MutableFields()
+
+	if !reflect.DeepEqual(expected, actual) {
+		t.Errorf("Expected %v but received %v", expected, actual)
+	}
+}
+
+func TestEMRServerlessConfigDifferingFields(t *testing.T) {
+	type args struct {
+		a EMRServerlessConfig
+		b EMRServerlessConfig
+	}
+
+	tests := []struct {
+		name     string
+		args     args
+		expected ss.StringSet
+	}{
+		{"No Differing Fields", args{
+			a: EMRServerlessConfig{
+				Credentials:      AWSCredentials{AWSAccessKeyId: "aws-key", AWSSecretKey: "aws-secret"},
+				Region:           "us-east-1",
+				ApplicationId:    "00f0abcdef123456",
+				ExecutionRoleArn: "arn:aws:iam::123456789012:role/featureform",
+			},
+			b: EMRServerlessConfig{
+				Credentials:      AWSCredentials{AWSAccessKeyId: "aws-key", AWSSecretKey: "aws-secret"},
+				Region:           "us-east-1",
+				ApplicationId:    "00f0abcdef123456",
+				ExecutionRoleArn: "arn:aws:iam::123456789012:role/featureform",
+			},
+		}, ss.StringSet{}},
+		{"Differing Fields", args{
+			a: EMRServerlessConfig{
+				Credentials:      AWSCredentials{AWSAccessKeyId: "aws-key", AWSSecretKey: "aws-secret"},
+				Region:           "us-east-1",
+				ApplicationId:    "00f0abcdef123456",
+				ExecutionRoleArn: "arn:aws:iam::123456789012:role/featureform",
+			},
+			b: EMRServerlessConfig{
+				Credentials:      AWSCredentials{AWSAccessKeyId: "aws-key2", AWSSecretKey: "aws-secret2"},
+				Region:           "us-west-2",
+				ApplicationId:    "00f0fedcba654321",
+				ExecutionRoleArn: "arn:aws:iam::123456789012:role/featureform",
+			},
+		}, ss.StringSet{
+			"Credentials":   true,
+			"Region":        true,
+			"ApplicationId": true,
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			actual, err := tt.args.a.DifferingFields(tt.args.b)
+
+			if err != nil {
+				t.Errorf("Failed to get differing fields due to error: %v", err)
+			}
+
+			if !reflect.DeepEqual(actual, tt.expected) {
+				t.Errorf("Expected %v, but instead found %v", tt.expected, actual)
+			}
+
+		})
+	}
+
+}
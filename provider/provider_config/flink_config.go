@@ -0,0 +1,29 @@
+package provider_config
+
+import (
+	"encoding/json"
+)
+
+// FlinkConfig is a Flink cluster that runs continuously computed features.
+// Jobs are submitted as Flink SQL to the cluster's SQL Gateway, and checked
+// on and cancelled through the REST API of its JobManager.
+type FlinkConfig struct {
+	GatewayURL string
+	RESTURL    string
+}
+
+func (f FlinkConfig) Serialized() SerializedConfig {
+	config, err := json.Marshal(f)
+	if err != nil {
+		panic(err)
+	}
+	return config
+}
+
+func (f *FlinkConfig) Deserialize(config SerializedConfig) error {
+	err := json.Unmarshal(config, f)
+	if err != nil {
+		return err
+	}
+	return nil
+}
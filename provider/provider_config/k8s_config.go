@@ -13,6 +13,7 @@ type K8sConfig struct {
 	ExecutorConfig interface{}
 	StoreType      FileStoreType
 	StoreConfig    FileStoreConfig
+	Retention      RetentionConfig
 }
 
 func (k8s *K8sConfig) Serialize() ([]byte, error) {
@@ -37,6 +38,7 @@ func (k8s *K8sConfig) UnmarshalJSON(data []byte) error {
 		ExecutorConfig interface{}
 		StoreType      FileStoreType
 		StoreConfig    map[string]interface{}
+		Retention      RetentionConfig
 	}
 
 	var temp tempConfig
@@ -47,6 +49,7 @@ func (k8s *K8sConfig) UnmarshalJSON(data []byte) error {
 
 	k8s.ExecutorType = temp.ExecutorType
 	k8s.StoreType = temp.StoreType
+	k8s.Retention = temp.Retention
 
 	if temp.ExecutorConfig == "" {
 		k8s.ExecutorConfig = ExecutorConfig{}
@@ -153,6 +156,10 @@ func (a K8sConfig) DifferingFields(b K8sConfig) (ss.StringSet, error) {
 		result["Store."+field] = val
 	}
 
+	if a.Retention != b.Retention {
+		result["Retention"] = true
+	}
+
 	return result, err
 }
 
@@ -0,0 +1,71 @@
+package provider_config
+
+import (
+	"encoding/json"
+
+	ss "github.com/featureform/helpers/string_set"
+)
+
+type KafkaSASLMechanism string
+
+const (
+	KafkaSASLPlain       KafkaSASLMechanism = "PLAIN"
+	KafkaSASLScramSHA256 KafkaSASLMechanism = "SCRAM-SHA-256"
+	KafkaSASLScramSHA512 KafkaSASLMechanism = "SCRAM-SHA-512"
+)
+
+// KafkaConfig configures a consumer of a Kafka topic. Consumers in the same
+// GroupID share the topic's partitions and resume from the group's committed
+// offsets. If Username is set, the consumer authenticates with SASL, using
+// PLAIN if Mechanism isn't set.
+//
+// Kafka providers use the same config without a Topic or GroupID, since
+// each source registered on them is a topic.
+type KafkaConfig struct {
+	Brokers        []string
+	Topic          string
+	GroupID        string
+	Username       string
+	Password       string
+	Mechanism      KafkaSASLMechanism
+	TLS            bool
+	SchemaRegistry KafkaSchemaRegistryConfig
+}
+
+// KafkaSchemaRegistryConfig is the schema registry that Avro messages'
+// schemas are read from. Username and Password are its basic auth
+// credentials.
+type KafkaSchemaRegistryConfig struct {
+	URL      string
+	Username string
+	Password string
+}
+
+func (k KafkaConfig) Serialized() SerializedConfig {
+	config, err := json.Marshal(k)
+	if err != nil {
+		panic(err)
+	}
+	return config
+}
+
+func (k *KafkaConfig) Deserialize(config SerializedConfig) error {
+	err := json.Unmarshal(config, k)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (k KafkaConfig) MutableFields() ss.StringSet {
+	return ss.StringSet{
+		"Brokers":        true,
+		"Username":       true,
+		"Password":       true,
+		"SchemaRegistry": true,
+	}
+}
+
+func (a KafkaConfig) DifferingFields(b KafkaConfig) (ss.StringSet, error) {
+	return differingFields(a, b)
+}
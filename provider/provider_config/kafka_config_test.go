@@ -0,0 +1,57 @@
+package provider_config
+
+import (
+	"reflect"
+	"testing"
+
+	ss "github.com/featureform/helpers/string_set"
+)
+
+func TestKafkaConfigSerialization(t *testing.T) {
+	config := KafkaConfig{
+		Brokers:   []string{"broker-1:9092", "broker-2:9092"},
+		Topic:     "postgres.public.transactions",
+		GroupID:   "featureform-cdc",
+		Username:  "user",
+		Password:  "password",
+		Mechanism: KafkaSASLScramSHA512,
+		TLS:       true,
+		SchemaRegistry: KafkaSchemaRegistryConfig{
+			URL:      "https://registry:8081",
+			Username: "registry-user",
+			Password: "registry-password",
+		},
+	}
+	deserialized := KafkaConfig{}
+	if err := deserialized.Deserialize(config.Serialized()); err != nil {
+		t.Fatalf("Failed to deserialize config: %v", err)
+	}
+	if !reflect.DeepEqual(config, deserialized) {
+		t.Fatalf("Expected %v, got %v", config, deserialized)
+	}
+}
+
+func TestKafkaConfigDifferingFields(t *testing.T) {
+	base := KafkaConfig{Brokers: []string{"broker:9092"}, Topic: "topic", GroupID: "group"}
+	tests := []struct {
+		name     string
+		b        KafkaConfig
+		expected ss.StringSet
+	}{
+		{"No Differing Fields", KafkaConfig{Brokers: []string{"broker:9092"}, Topic: "topic", GroupID: "group"}, ss.StringSet{}},
+		{"Differing Fields", KafkaConfig{Brokers: []string{"other:9092"}, Topic: "topic", GroupID: "group", Password: "password"}, ss.StringSet{"Brokers": true, "Password": true}},
+		{"Differing Schema Registry", KafkaConfig{Brokers: []string{"broker:9092"}, Topic: "topic", GroupID: "group", SchemaRegistry: KafkaSchemaRegistryConfig{URL: "http://registry:8081"}}, ss.StringSet{"SchemaRegistry": true}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			actual, err := base.DifferingFields(tt.b)
+			if err != nil {
+				t.Errorf("Failed to get differing fields due to error: %v", err)
+			}
+			if !reflect.DeepEqual(actual, tt.expected) {
+				t.Errorf("Expected %v, but instead found %v", tt.expected, actual)
+			}
+		})
+	}
+}
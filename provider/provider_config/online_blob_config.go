@@ -5,6 +5,12 @@ import "encoding/json"
 type OnlineBlobConfig struct {
 	Type   FileStoreType
 	Config AzureFileStoreConfig
+	// Format is the layout of new tables. By default each entity's value is
+	// its own blob; "sharded" writes materializations as sorted, indexed shard
+	// files instead.
+	Format string
+	// Shards is the number of shard files per sharded table.
+	Shards int
 }
 
 func (online OnlineBlobConfig) Serialized() SerializedConfig {
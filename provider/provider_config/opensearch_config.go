@@ -0,0 +1,63 @@
+package provider_config
+
+import (
+	"encoding/json"
+
+	ss "github.com/featureform/helpers/string_set"
+)
+
+// SearchEngine is the search engine behind an OpenSearchConfig. Their kNN
+// mappings and query DSLs differ, but the rest of their APIs are the same.
+type SearchEngine string
+
+const (
+	OpenSearch    SearchEngine = "opensearch"
+	Elasticsearch SearchEngine = "elasticsearch"
+)
+
+// OpenSearchConfig configures a vector store in OpenSearch, with the kNN
+// plugin, or in Elasticsearch 8+. It authenticates with APIKey if it's set,
+// and otherwise with basic auth if Username is set.
+type OpenSearchConfig struct {
+	// Engine defaults to OpenSearch.
+	Engine SearchEngine
+	// URL is the scheme, host and port of the cluster, such as
+	// "https://search-features.us-east-1.es.amazonaws.com".
+	URL      string
+	Username string
+	Password string
+	APIKey   string
+	// WaitForRefresh makes writes wait until they're visible to Nearest.
+	// Otherwise they become visible at the next index refresh, which is
+	// every second by default.
+	WaitForRefresh bool
+}
+
+func (o OpenSearchConfig) Serialized() SerializedConfig {
+	config, err := json.Marshal(o)
+	if err != nil {
+		panic(err)
+	}
+	return config
+}
+
+func (o *OpenSearchConfig) Deserialize(config SerializedConfig) error {
+	err := json.Unmarshal(config, o)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (o OpenSearchConfig) MutableFields() ss.StringSet {
+	return ss.StringSet{
+		"Username":       true,
+		"Password":       true,
+		"APIKey":         true,
+		"WaitForRefresh": true,
+	}
+}
+
+func (a OpenSearchConfig) DifferingFields(b OpenSearchConfig) (ss.StringSet, error) {
+	return differingFields(a, b)
+}
@@ -0,0 +1,56 @@
+package provider_config
+
+import (
+	"reflect"
+	"testing"
+
+	ss "github.com/featureform/helpers/string_set"
+)
+
+func TestOpenSearchConfigMutableFields(t *testing.T) {
+	expected := ss.StringSet{
+		"Username":       true,
+		"Password":       true,
+		"APIKey":         true,
+		"WaitForRefresh": true,
+	}
+
+	config := OpenSearchConfig{
+		URL:      "https://localhost:9200",
+		Username: "admin",
+		Password: "admin",
+	}
+	actual := config.MutableFields()
+
+	if !reflect.DeepEqual(expected, actual) {
+		t.Errorf("Expected %v but received %v", expected, actual)
+	}
+}
+
+func TestOpenSearchConfigDifferingFields(t *testing.T) {
+	a := OpenSearchConfig{
+		URL:      "https://localhost:9200",
+		Username: "admin",
+		Password: "admin",
+	}
+	b := OpenSearchConfig{
+		Engine:         Elasticsearch,
+		URL:            "https://localhost:9200",
+		APIKey:         "key",
+		WaitForRefresh: true,
+	}
+	expected := ss.StringSet{
+		"Engine":         true,
+		"Username":       true,
+		"Password":       true,
+		"APIKey":         true,
+		"WaitForRefresh": true,
+	}
+	actual, err := a.DifferingFields(b)
+	if err != nil {
+		t.Fatalf("Failed to get differing fields due to error: %v", err)
+	}
+	if !reflect.DeepEqual(actual, expected) {
+		t.Errorf("Expected %v, but instead found %v", expected, actual)
+	}
+}
@@ -0,0 +1,49 @@
+package provider_config
+
+import (
+	"encoding/json"
+
+	ss "github.com/featureform/helpers/string_set"
+)
+
+// PgvectorConfig configures an online and vector store in a Postgres
+// database with the pgvector extension, such as RDS or Cloud SQL.
+type PgvectorConfig struct {
+	Host     string `json:"Host"`
+	Port     string `json:"Port"`
+	Username string `json:"Username"`
+	Password string `json:"Password"`
+	Database string `json:"Database"`
+	// SSLMode is the libpq sslmode to connect with, such as "require" or
+	// "verify-full". It defaults to "disable".
+	SSLMode string `json:"SSLMode,omitempty"`
+}
+
+func (pg PgvectorConfig) Serialized() SerializedConfig {
+	config, err := json.Marshal(pg)
+	if err != nil {
+		panic(err)
+	}
+	return config
+}
+
+func (pg *PgvectorConfig) Deserialize(config SerializedConfig) error {
+	err := json.Unmarshal(config, pg)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (pg PgvectorConfig) MutableFields() ss.StringSet {
+	return ss.StringSet{
+		"Username": true,
+		"Password": true,
+		"Port":     true,
+		"SSLMode":  true,
+	}
+}
+
+func (a PgvectorConfig) DifferingFields(b PgvectorConfig) (ss.StringSet, error) {
+	return differingFields(a, b)
+}
@@ -0,0 +1,60 @@
+package provider_config
+
+import (
+	"reflect"
+	"testing"
+
+	ss "github.com/featureform/helpers/string_set"
+)
+
+func TestPgvectorConfigMutableFields(t *testing.T) {
+	expected := ss.StringSet{
+		"Username": true,
+		"Password": true,
+		"Port":     true,
+		"SSLMode":  true,
+	}
+
+	config := PgvectorConfig{
+		Host:     "localhost",
+		Port:     "5432",
+		Username: "postgres",
+		Password: "password",
+		Database: "postgres",
+	}
+	actual := config.MutableFields()
+
+	if !reflect.DeepEqual(expected, actual) {
+		t.Errorf("Expected %v but received %v", expected, actual)
+	}
+}
+
+func TestPgvectorConfigDifferingFields(t *testing.T) {
+	a := PgvectorConfig{
+		Host:     "localhost",
+		Port:     "5432",
+		Username: "postgres",
+		Password: "password",
+		Database: "postgres",
+	}
+	b := PgvectorConfig{
+		Host:     "db.example.com",
+		Port:     "5432",
+		Username: "featureform",
+		Password: "password",
+		Database: "postgres",
+		SSLMode:  "require",
+	}
+	expected := ss.StringSet{
+		"Host":     true,
+		"Username": true,
+		"SSLMode":  true,
+	}
+	actual, err := a.DifferingFields(b)
+	if err != nil {
+		t.Fatalf("Failed to get differing fields due to error: %v", err)
+	}
+	if !reflect.DeepEqual(actual, expected) {
+		t.Errorf("Expected %v, but instead found %v", expected, actual)
+	}
+}
@@ -12,6 +12,9 @@ type PostgresConfig struct {
 	Username string `json:"Username"`
 	Password string `json:"Password"`
 	Database string `json:"Database"`
+	// SSLMode is the libpq sslmode to connect with, such as "require" or
+	// "verify-full". It defaults to "disable".
+	SSLMode string `json:"SSLMode,omitempty"`
 }
 
 func (pg *PostgresConfig) Deserialize(config SerializedConfig) error {
@@ -35,6 +38,7 @@ func (pg PostgresConfig) MutableFields() ss.StringSet {
 		"Username": true,
 		"Password": true,
 		"Port":     true,
+		"SSLMode":  true,
 	}
 }
 
@@ -12,6 +12,7 @@ func TestPostgresConfigMutableFields(t *testing.T) {
 		"Username": true,
 		"Password": true,
 		"Port":     true,
+		"SSLMode":  true,
 	}
 
 	config := PostgresConfig{
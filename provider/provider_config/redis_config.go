@@ -11,6 +11,8 @@ type RedisConfig struct {
 	Addr     string
 	Password string
 	DB       int
+	// TLS connects to Redis over TLS.
+	TLS bool
 }
 
 func (r RedisConfig) Serialized() SerializedConfig {
@@ -32,6 +34,7 @@ func (r *RedisConfig) Deserialize(config SerializedConfig) error {
 func (r RedisConfig) MutableFields() ss.StringSet {
 	return ss.StringSet{
 		"Password": true,
+		"TLS":      true,
 	}
 }
 
@@ -10,6 +10,7 @@ import (
 func TestRedisConfigMutableFields(t *testing.T) {
 	expected := ss.StringSet{
 		"Password": true,
+		"TLS":      true,
 	}
 
 	config := RedisConfig{
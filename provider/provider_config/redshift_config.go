@@ -12,6 +12,23 @@ type RedshiftConfig struct {
 	Database string
 	Username string
 	Password string
+	// ClusterIdentifier or Workgroup connects with temporary credentials from
+	// IAM instead of Password. ClusterIdentifier is a provisioned cluster's,
+	// and Username is the database user to get credentials for. Workgroup is
+	// a Redshift Serverless workgroup's, whose database user is derived from
+	// the IAM identity.
+	ClusterIdentifier string
+	Workgroup         string
+	// Region and Credentials are used to get IAM credentials. Without
+	// Credentials, the default AWS credential chain is used, like the role of
+	// a Kubernetes service account.
+	Region      string
+	Credentials AWSCredentials
+}
+
+// UsesIAM is true if the connection uses temporary credentials from IAM.
+func (rs *RedshiftConfig) UsesIAM() bool {
+	return rs.ClusterIdentifier != "" || rs.Workgroup != ""
 }
 
 func (rs *RedshiftConfig) Deserialize(config SerializedConfig) error {
@@ -32,9 +49,10 @@ func (rs *RedshiftConfig) Serialize() []byte {
 
 func (rs RedshiftConfig) MutableFields() ss.StringSet {
 	return ss.StringSet{
-		"Username": true,
-		"Password": true,
-		"Port":     true,
+		"Username":    true,
+		"Password":    true,
+		"Port":        true,
+		"Credentials": true,
 	}
 }
 
@@ -9,9 +9,10 @@ import (
 
 func TestRedshiftConfigMutableFields(t *testing.T) {
 	expected := ss.StringSet{
-		"Username": true,
-		"Password": true,
-		"Port":     true,
+		"Username":    true,
+		"Password":    true,
+		"Port":        true,
+		"Credentials": true,
 	}
 
 	config := RedshiftConfig{
@@ -92,3 +93,18 @@ func TestRedshiftConfigDifferingFields(t *testing.T) {
 	}
 
 }
+
+func TestRedshiftConfigUsesIAM(t *testing.T) {
+	config := RedshiftConfig{Endpoint: "0.0.0.0", Port: "5439", Username: "root", Password: "password"}
+	if config.UsesIAM() {
+		t.Errorf("Expected a password config not to use IAM")
+	}
+	config = RedshiftConfig{Endpoint: "0.0.0.0", Port: "5439", Username: "root", ClusterIdentifier: "cluster"}
+	if !config.UsesIAM() {
+		t.Errorf("Expected a cluster identifier config to use IAM")
+	}
+	config = RedshiftConfig{Endpoint: "0.0.0.0", Port: "5439", Workgroup: "workgroup"}
+	if !config.UsesIAM() {
+		t.Errorf("Expected a workgroup config to use IAM")
+	}
+}
@@ -0,0 +1,33 @@
+package provider_config
+
+import (
+	"fmt"
+	"time"
+)
+
+// RetentionConfig limits the snapshots that a file-based offline store keeps
+// of each materialization, since every update writes a new one. Snapshots
+// beyond the newest MaxVersions, or older than MaxAgeDays, are deleted after
+// an update. A zero limit doesn't apply, and the newest snapshot is always
+// kept.
+type RetentionConfig struct {
+	MaxVersions int
+	MaxAgeDays  int
+}
+
+func (r RetentionConfig) IsZero() bool {
+	return r.MaxVersions == 0 && r.MaxAgeDays == 0
+}
+
+func (r RetentionConfig) Validate() error {
+	if r.MaxVersions < 0 || r.MaxAgeDays < 0 {
+		return fmt.Errorf("retention limits can't be negative: %+v", r)
+	}
+	return nil
+}
+
+// MaxAge is the age after which snapshots are deleted, or zero if they're
+// kept regardless of age.
+func (r RetentionConfig) MaxAge() time.Duration {
+	return time.Duration(r.MaxAgeDays) * 24 * time.Hour
+}
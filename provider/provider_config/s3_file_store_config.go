@@ -11,6 +11,24 @@ type S3FileStoreConfig struct {
 	BucketRegion string
 	BucketPath   string
 	Path         string
+	// Tags are applied to every object the provider writes, such as a team or
+	// cost center for cost allocation. S3 allows at most 10 tags per object.
+	Tags map[string]string
+	// RoleARN is an IAM role assumed with Credentials, or with the default
+	// credential chain if they're empty, to access a bucket in another
+	// account. ExternalID is passed when the role is assumed if its trust
+	// policy requires one.
+	RoleARN    string
+	ExternalID string
+	// AccessPoint is the ARN of an S3 access point that objects are read and
+	// written through instead of the bucket directly. Paths still name
+	// BucketPath.
+	AccessPoint string
+	// Endpoint is a custom S3 endpoint URL, such as a VPC endpoint or an
+	// S3-compatible store. PathStyle puts the bucket in the URL's path rather
+	// than its host, which most S3-compatible stores require.
+	Endpoint  string
+	PathStyle bool
 }
 
 func (s *S3FileStoreConfig) Deserialize(config SerializedConfig) error {
@@ -36,6 +54,9 @@ func (s *S3FileStoreConfig) IsFileStoreConfig() bool {
 func (s S3FileStoreConfig) MutableFields() ss.StringSet {
 	return ss.StringSet{
 		"Credentials": true,
+		"Tags":        true,
+		"RoleARN":     true,
+		"ExternalID":  true,
 	}
 }
 
@@ -10,6 +10,9 @@ import (
 func TestS3ConfigMutableFields(t *testing.T) {
 	expected := ss.StringSet{
 		"Credentials": true,
+		"Tags":        true,
+		"RoleARN":     true,
+		"ExternalID":  true,
 	}
 
 	config := S3FileStoreConfig{
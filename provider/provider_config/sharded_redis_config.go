@@ -0,0 +1,48 @@
+package provider_config
+
+import (
+	"encoding/json"
+
+	ss "github.com/featureform/helpers/string_set"
+)
+
+// ShardedRedisConfig configures an online store that spreads entities
+// across several non-clustered Redis instances with consistent hashing.
+// Shards are identified by their position, so new shards must be appended
+// to the end of Shards; reordering or removing shards moves most entities.
+type ShardedRedisConfig struct {
+	Shards []RedisConfig
+	// RebalancingFrom is the number of shards before shards were last added.
+	// While it's set, reads that miss on an entity's new shard fall back to
+	// its old one. It should be reset to zero once the shards are rebalanced.
+	RebalancingFrom int
+}
+
+func (s ShardedRedisConfig) Serialized() SerializedConfig {
+	config, err := json.Marshal(s)
+	if err != nil {
+		panic(err)
+	}
+	return config
+}
+
+func (s *ShardedRedisConfig) Deserialize(config SerializedConfig) error {
+	err := json.Unmarshal(config, s)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+// Shards can be changed so that they can be added and their passwords
+// rotated.
+func (s ShardedRedisConfig) MutableFields() ss.StringSet {
+	return ss.StringSet{
+		"Shards":          true,
+		"RebalancingFrom": true,
+	}
+}
+
+func (a ShardedRedisConfig) DifferingFields(b ShardedRedisConfig) (ss.StringSet, error) {
+	return differingFields(a, b)
+}
@@ -0,0 +1,58 @@
+package provider_config
+
+import (
+	"reflect"
+	"testing"
+
+	ss "github.com/featureform/helpers/string_set"
+)
+
+func TestShardedRedisConfigSerialization(t *testing.T) {
+	config := ShardedRedisConfig{
+		Shards: []RedisConfig{
+			{Prefix: "Featureform_table__", Addr: "0.0.0.0:6379"},
+			{Prefix: "Featureform_table__", Addr: "0.0.0.0:6380", Password: "password"},
+		},
+		RebalancingFrom: 1,
+	}
+	actual := ShardedRedisConfig{}
+	if err := actual.Deserialize(config.Serialized()); err != nil {
+		t.Fatalf("Failed to deserialize config: %s", err)
+	}
+	if !reflect.DeepEqual(config, actual) {
+		t.Errorf("Expected %v but received %v", config, actual)
+	}
+}
+
+func TestShardedRedisConfigDifferingFields(t *testing.T) {
+	a := RedisConfig{Addr: "0.0.0.0:6379"}
+	b := RedisConfig{Addr: "0.0.0.0:6380"}
+	tests := []struct {
+		name     string
+		a, b     ShardedRedisConfig
+		expected ss.StringSet
+	}{
+		{"No Differing Fields", ShardedRedisConfig{Shards: []RedisConfig{a, b}}, ShardedRedisConfig{Shards: []RedisConfig{a, b}}, ss.StringSet{}},
+		{"Add Shard", ShardedRedisConfig{Shards: []RedisConfig{a}}, ShardedRedisConfig{Shards: []RedisConfig{a, b}, RebalancingFrom: 1}, ss.StringSet{
+			"Shards":          true,
+			"RebalancingFrom": true,
+		}},
+		{"Rebalanced", ShardedRedisConfig{Shards: []RedisConfig{a, b}, RebalancingFrom: 1}, ShardedRedisConfig{Shards: []RedisConfig{a, b}}, ss.StringSet{
+			"RebalancingFrom": true,
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			actual, err := tt.a.DifferingFields(tt.b)
+			if err != nil {
+				t.Errorf("Failed to get differing fields due to error: %v", err)
+			}
+			if !reflect.DeepEqual(actual, tt.expected) {
+				t.Errorf("Expected %v, but instead found %v", tt.expected, actual)
+			}
+			if !tt.a.MutableFields().Contains(actual) {
+				t.Errorf("Expected %v to be mutable", actual)
+			}
+		})
+	}
+}
@@ -11,9 +11,10 @@ import (
 type SparkExecutorType string
 
 const (
-	EMR          SparkExecutorType = "EMR"
-	Databricks   SparkExecutorType = "DATABRICKS"
-	SparkGeneric SparkExecutorType = "SPARK"
+	EMR           SparkExecutorType = "EMR"
+	EMRServerless SparkExecutorType = "EMR_SERVERLESS"
+	Databricks    SparkExecutorType = "DATABRICKS"
+	SparkGeneric  SparkExecutorType = "SPARK"
 )
 
 type AWSCredentials struct {
@@ -23,7 +24,15 @@ type AWSCredentials struct {
 
 type GCPCredentials struct {
 	ProjectId string
-	JSON      map[string]interface{}
+	// JSON is a service account key. If it's empty, Application Default
+	// Credentials are used instead, such as a GKE workload identity.
+	JSON map[string]interface{}
+}
+
+// UseDefaultCredentials returns true if there's no service account key and
+// Application Default Credentials should be used.
+func (c GCPCredentials) UseDefaultCredentials() bool {
+	return len(c.JSON) == 0
 }
 
 type SparkExecutorConfig interface {
@@ -38,11 +47,63 @@ type SparkFileStoreConfig interface {
 	IsFileStoreConfig() bool
 }
 
+// TableFormat is the format that a file-based offline store writes
+// transformations, materializations and training sets in.
+type TableFormat string
+
+const (
+	// ParquetTableFormat writes each job's output to a new directory of
+	// Parquet files. It's the default.
+	ParquetTableFormat TableFormat = "parquet"
+	// IcebergTableFormat writes each resource to an Iceberg table, which is
+	// registered in a catalog and kept at the resource's path in the store.
+	IcebergTableFormat TableFormat = "iceberg"
+	// DeltaTableFormat writes each resource to a Delta Lake table at the
+	// resource's path in the store. Every write is a new version of it, and
+	// earlier versions can be read until they're vacuumed.
+	DeltaTableFormat TableFormat = "delta"
+)
+
+// IcebergConfig is the catalog that Iceberg tables are registered in. The
+// catalog is configured on the Spark cluster with its
+// spark.sql.catalog.<Catalog> settings.
+type IcebergConfig struct {
+	Catalog   string
+	Namespace string
+}
+
+// CatalogType is the metastore that a Spark offline store reads tables from
+// by name.
+type CatalogType string
+
+const (
+	HiveCatalog CatalogType = "hive"
+	GlueCatalog CatalogType = "glue"
+)
+
+// CatalogConfig is the metastore that sources can be registered from by
+// their table name, like "db.table", instead of by path. Without a Type,
+// every source is a path.
+type CatalogConfig struct {
+	Type CatalogType
+	// MetastoreURI is the thrift URI of a Hive Metastore, like
+	// thrift://metastore:9083.
+	MetastoreURI string
+	// GlueRegion is the region of a Glue Data Catalog, and GlueCatalogID is
+	// the account that it belongs to. They default to those of the cluster.
+	GlueRegion    string
+	GlueCatalogID string
+}
+
 type SparkConfig struct {
 	ExecutorType   SparkExecutorType
 	ExecutorConfig SparkExecutorConfig
 	StoreType      FileStoreType
 	StoreConfig    SparkFileStoreConfig
+	TableFormat    TableFormat
+	Iceberg        IcebergConfig
+	Catalog        CatalogConfig
+	Retention      RetentionConfig
 }
 
 func (s *SparkConfig) Deserialize(config SerializedConfig) error {
@@ -67,6 +128,10 @@ func (s *SparkConfig) UnmarshalJSON(data []byte) error {
 		ExecutorConfig map[string]interface{}
 		StoreType      FileStoreType
 		StoreConfig    map[string]interface{}
+		TableFormat    TableFormat
+		Iceberg        IcebergConfig
+		Catalog        CatalogConfig
+		Retention      RetentionConfig
 	}
 
 	var temp tempConfig
@@ -77,6 +142,10 @@ func (s *SparkConfig) UnmarshalJSON(data []byte) error {
 
 	s.ExecutorType = temp.ExecutorType
 	s.StoreType = temp.StoreType
+	s.TableFormat = temp.TableFormat
+	s.Iceberg = temp.Iceberg
+	s.Catalog = temp.Catalog
+	s.Retention = temp.Retention
 
 	err = s.decodeExecutor(temp.ExecutorType, temp.ExecutorConfig)
 	if err != nil {
@@ -99,6 +168,8 @@ func (s SparkConfig) MutableFields() ss.StringSet {
 	switch s.ExecutorType {
 	case EMR:
 		executorFields = s.ExecutorConfig.(*EMRConfig).MutableFields()
+	case EMRServerless:
+		executorFields = s.ExecutorConfig.(*EMRServerlessConfig).MutableFields()
 	case Databricks:
 		executorFields = s.ExecutorConfig.(*DatabricksConfig).MutableFields()
 	case SparkGeneric:
@@ -146,6 +217,8 @@ func (a SparkConfig) DifferingFields(b SparkConfig) (ss.StringSet, error) {
 	switch a.ExecutorType {
 	case EMR:
 		executorFields, err = a.ExecutorConfig.(*EMRConfig).DifferingFields(*b.ExecutorConfig.(*EMRConfig))
+	case EMRServerless:
+		executorFields, err = a.ExecutorConfig.(*EMRServerlessConfig).DifferingFields(*b.ExecutorConfig.(*EMRServerlessConfig))
 	case Databricks:
 		executorFields, err = a.ExecutorConfig.(*DatabricksConfig).DifferingFields(*b.ExecutorConfig.(*DatabricksConfig))
 	case SparkGeneric:
@@ -181,6 +254,22 @@ func (a SparkConfig) DifferingFields(b SparkConfig) (ss.StringSet, error) {
 		result["Store."+field] = val
 	}
 
+	if a.TableFormat != b.TableFormat {
+		result["TableFormat"] = true
+	}
+
+	if a.Iceberg != b.Iceberg {
+		result["Iceberg"] = true
+	}
+
+	if a.Catalog != b.Catalog {
+		result["Catalog"] = true
+	}
+
+	if a.Retention != b.Retention {
+		result["Retention"] = true
+	}
+
 	return result, err
 }
 
@@ -189,6 +278,8 @@ func (s *SparkConfig) decodeExecutor(executorType SparkExecutorType, configMap m
 	switch executorType {
 	case EMR:
 		executorConfig = &EMRConfig{}
+	case EMRServerless:
+		executorConfig = &EMRServerlessConfig{}
 	case Databricks:
 		executorConfig = &DatabricksConfig{}
 	case SparkGeneric:
@@ -33,6 +33,9 @@ func TestSparkConfigMutableFields(t *testing.T) {
 			expected: ss.StringSet{
 				"Executor.Credentials": true,
 				"Store.Credentials":    true,
+				"Store.Tags":           true,
+				"Store.RoleARN":        true,
+				"Store.ExternalID":     true,
 			},
 		},
 		{
@@ -363,6 +366,113 @@ func TestSparkConfigDifferingFields(t *testing.T) {
 				},
 			},
 		}, ss.StringSet{}, true},
+		{"Table Format Parquet -> Iceberg", args{
+			a: SparkConfig{
+				ExecutorType: EMR,
+				ExecutorConfig: &EMRConfig{
+					Credentials:   AWSCredentials{AWSAccessKeyId: "aws-key", AWSSecretKey: "aws-secret"},
+					ClusterRegion: "us-east-1",
+					ClusterName:   "featureform-clst",
+				},
+				StoreType: S3,
+				StoreConfig: &S3FileStoreConfig{
+					Credentials:  AWSCredentials{AWSAccessKeyId: "aws-key", AWSSecretKey: "aws-secret"},
+					BucketRegion: "us-east-1",
+					BucketPath:   "https://featureform.s3.us-east-1.amazonaws.com/transactions",
+					Path:         "https://featureform.s3.us-east-1.amazonaws.com/transactions",
+				},
+			},
+			b: SparkConfig{
+				ExecutorType: EMR,
+				ExecutorConfig: &EMRConfig{
+					Credentials:   AWSCredentials{AWSAccessKeyId: "aws-key", AWSSecretKey: "aws-secret"},
+					ClusterRegion: "us-east-1",
+					ClusterName:   "featureform-clst",
+				},
+				StoreType: S3,
+				StoreConfig: &S3FileStoreConfig{
+					Credentials:  AWSCredentials{AWSAccessKeyId: "aws-key", AWSSecretKey: "aws-secret"},
+					BucketRegion: "us-east-1",
+					BucketPath:   "https://featureform.s3.us-east-1.amazonaws.com/transactions",
+					Path:         "https://featureform.s3.us-east-1.amazonaws.com/transactions",
+				},
+				TableFormat: IcebergTableFormat,
+				Iceberg:     IcebergConfig{Catalog: "glue", Namespace: "featureform"},
+			},
+		}, ss.StringSet{
+			"TableFormat": true,
+			"Iceberg":     true,
+		}, false},
+		{"Add Glue Catalog", args{
+			a: SparkConfig{
+				ExecutorType: EMR,
+				ExecutorConfig: &EMRConfig{
+					Credentials:   AWSCredentials{AWSAccessKeyId: "aws-key", AWSSecretKey: "aws-secret"},
+					ClusterRegion: "us-east-1",
+					ClusterName:   "featureform-clst",
+				},
+				StoreType: S3,
+				StoreConfig: &S3FileStoreConfig{
+					Credentials:  AWSCredentials{AWSAccessKeyId: "aws-key", AWSSecretKey: "aws-secret"},
+					BucketRegion: "us-east-1",
+					BucketPath:   "https://featureform.s3.us-east-1.amazonaws.com/transactions",
+					Path:         "https://featureform.s3.us-east-1.amazonaws.com/transactions",
+				},
+			},
+			b: SparkConfig{
+				ExecutorType: EMR,
+				ExecutorConfig: &EMRConfig{
+					Credentials:   AWSCredentials{AWSAccessKeyId: "aws-key", AWSSecretKey: "aws-secret"},
+					ClusterRegion: "us-east-1",
+					ClusterName:   "featureform-clst",
+				},
+				StoreType: S3,
+				StoreConfig: &S3FileStoreConfig{
+					Credentials:  AWSCredentials{AWSAccessKeyId: "aws-key", AWSSecretKey: "aws-secret"},
+					BucketRegion: "us-east-1",
+					BucketPath:   "https://featureform.s3.us-east-1.amazonaws.com/transactions",
+					Path:         "https://featureform.s3.us-east-1.amazonaws.com/transactions",
+				},
+				Catalog: CatalogConfig{Type: GlueCatalog, GlueRegion: "us-east-1"},
+			},
+		}, ss.StringSet{
+			"Catalog": true,
+		}, false},
+		{"Set Retention", args{
+			a: SparkConfig{
+				ExecutorType: EMR,
+				ExecutorConfig: &EMRConfig{
+					Credentials:   AWSCredentials{AWSAccessKeyId: "aws-key", AWSSecretKey: "aws-secret"},
+					ClusterRegion: "us-east-1",
+					ClusterName:   "featureform-clst",
+				},
+				StoreType: S3,
+				StoreConfig: &S3FileStoreConfig{
+					Credentials:  AWSCredentials{AWSAccessKeyId: "aws-key", AWSSecretKey: "aws-secret"},
+					BucketRegion: "us-east-1",
+					BucketPath:   "https://featureform.s3.us-east-1.amazonaws.com/transactions",
+					Path:         "https://featureform.s3.us-east-1.amazonaws.com/transactions",
+				},
+			},
+			b: SparkConfig{
+				ExecutorType: EMR,
+				ExecutorConfig: &EMRConfig{
+					Credentials:   AWSCredentials{AWSAccessKeyId: "aws-key", AWSSecretKey: "aws-secret"},
+					ClusterRegion: "us-east-1",
+					ClusterName:   "featureform-clst",
+				},
+				StoreType: S3,
+				StoreConfig: &S3FileStoreConfig{
+					Credentials:  AWSCredentials{AWSAccessKeyId: "aws-key", AWSSecretKey: "aws-secret"},
+					BucketRegion: "us-east-1",
+					BucketPath:   "https://featureform.s3.us-east-1.amazonaws.com/transactions",
+					Path:         "https://featureform.s3.us-east-1.amazonaws.com/transactions",
+				},
+				Retention: RetentionConfig{MaxVersions: 3, MaxAgeDays: 7},
+			},
+		}, ss.StringSet{
+			"Retention": true,
+		}, false},
 	}
 
 	for _, tt := range tests {
@@ -0,0 +1,165 @@
+package provider_config
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	cfg "github.com/featureform/config"
+	pt "github.com/featureform/provider/provider_type"
+)
+
+// UsesTLS returns true if a provider of type t with the given config
+// encrypts its connections in transit. Providers that only talk to cloud
+// APIs always use TLS, and in-process providers don't connect to anything.
+func UsesTLS(t pt.Type, config SerializedConfig) (bool, error) {
+	switch t {
+	case pt.RedisOnline:
+		redisConfig := RedisConfig{}
+		if err := redisConfig.Deserialize(config); err != nil {
+			return false, err
+		}
+		return redisConfig.TLS, nil
+	case pt.ShardedRedisOnline:
+		shardedConfig := ShardedRedisConfig{}
+		if err := shardedConfig.Deserialize(config); err != nil {
+			return false, err
+		}
+		for _, shard := range shardedConfig.Shards {
+			if !shard.TLS {
+				return false, nil
+			}
+		}
+		return true, nil
+	case pt.CassandraOnline:
+		cassandraConfig := CassandraConfig{}
+		if err := cassandraConfig.Deserialize(config); err != nil {
+			return false, err
+		}
+		return cassandraConfig.TLS, nil
+	case pt.PostgresOffline:
+		postgresConfig := PostgresConfig{}
+		if err := postgresConfig.Deserialize(config); err != nil {
+			return false, err
+		}
+		return isSSLModeEncrypted(postgresConfig.SSLMode), nil
+	case pt.PgvectorOnline:
+		pgvectorConfig := PgvectorConfig{}
+		if err := pgvectorConfig.Deserialize(config); err != nil {
+			return false, err
+		}
+		return isSSLModeEncrypted(pgvectorConfig.SSLMode), nil
+	case pt.ClickHouseOffline:
+		clickHouseConfig := ClickHouseConfig{}
+		if err := clickHouseConfig.Deserialize(config); err != nil {
+			return false, err
+		}
+		return clickHouseConfig.SSL, nil
+	case pt.DualWriteOnline:
+		dualWriteConfig := DualWriteConfig{}
+		if err := dualWriteConfig.Deserialize(config); err != nil {
+			return false, err
+		}
+		for _, store := range []OnlineStoreConfig{dualWriteConfig.Primary, dualWriteConfig.Secondary} {
+			if store.Type == "" {
+				continue
+			}
+			if usesTLS, err := UsesTLS(pt.Type(store.Type), SerializedConfig(store.Config)); err != nil || !usesTLS {
+				return false, err
+			}
+		}
+		return true, nil
+	case pt.WeaviateOnline:
+		weaviateConfig := WeaviateConfig{}
+		if err := weaviateConfig.Deserialize(config); err != nil {
+			return false, err
+		}
+		return isHTTPS(weaviateConfig.URL), nil
+	case pt.OpenSearchOnline:
+		openSearchConfig := OpenSearchConfig{}
+		if err := openSearchConfig.Deserialize(config); err != nil {
+			return false, err
+		}
+		return isHTTPS(openSearchConfig.URL), nil
+	case pt.ChromaOnline:
+		chromaConfig := ChromaConfig{}
+		if err := chromaConfig.Deserialize(config); err != nil {
+			return false, err
+		}
+		return isHTTPS(chromaConfig.URL), nil
+	case pt.VespaOnline:
+		vespaConfig := VespaConfig{}
+		if err := vespaConfig.Deserialize(config); err != nil {
+			return false, err
+		}
+		return isHTTPS(vespaConfig.URL) && isHTTPS(vespaConfig.ConfigURL), nil
+	case pt.Kafka:
+		kafkaConfig := KafkaConfig{}
+		if err := kafkaConfig.Deserialize(config); err != nil {
+			return false, err
+		}
+		registryURL := kafkaConfig.SchemaRegistry.URL
+		return kafkaConfig.TLS && (registryURL == "" || isHTTPS(registryURL)), nil
+	case pt.SparkOffline, pt.K8sOffline:
+		var storeConfig struct {
+			StoreType   FileStoreType
+			StoreConfig struct {
+				Endpoint string
+			}
+		}
+		if err := json.Unmarshal(config, &storeConfig); err != nil {
+			return false, err
+		}
+		return storeConfig.StoreType != HDFS && isS3EndpointEncrypted(storeConfig.StoreConfig.Endpoint), nil
+	case pt.S3:
+		s3Config := S3FileStoreConfig{}
+		if err := s3Config.Deserialize(config); err != nil {
+			return false, err
+		}
+		return isS3EndpointEncrypted(s3Config.Endpoint), nil
+	case pt.HDFS:
+		return false, nil
+	case pt.LocalOnline, pt.MemoryOffline, pt.DuckDBOffline, pt.MongoDBOnline, pt.RedshiftOffline, pt.DynamoDBOnline,
+		pt.FirestoreOnline, pt.BlobOnline, pt.SnowflakeOffline, pt.BigQueryOffline, pt.AthenaOffline, pt.DatabricksSQLOffline, pt.GCS, pt.AZURE:
+		return true, nil
+	default:
+		return false, fmt.Errorf("unknown provider type %s", t)
+	}
+}
+
+// isSSLModeEncrypted returns true if a libpq sslmode always encrypts the
+// connection. "prefer" and "allow" fall back to plaintext.
+func isSSLModeEncrypted(sslMode string) bool {
+	switch sslMode {
+	case "require", "verify-ca", "verify-full":
+		return true
+	default:
+		return false
+	}
+}
+
+// isS3EndpointEncrypted returns true if an S3 file store's endpoint is
+// AWS's, which is always HTTPS, or a custom HTTPS endpoint.
+func isS3EndpointEncrypted(endpoint string) bool {
+	return endpoint == "" || isHTTPS(endpoint)
+}
+
+func isHTTPS(url string) bool {
+	return strings.HasPrefix(strings.ToLower(url), "https://")
+}
+
+// CheckTLSPolicy returns an error if the tunables require providers to use
+// TLS and the provider named name doesn't, unless it's allowlisted.
+func CheckTLSPolicy(tunables cfg.Tunables, name string, t pt.Type, config SerializedConfig) error {
+	if tunables.TLSExempt(name) {
+		return nil
+	}
+	usesTLS, err := UsesTLS(t, config)
+	if err != nil {
+		return fmt.Errorf("could not check TLS of provider %s: %w", name, err)
+	}
+	if !usesTLS {
+		return fmt.Errorf("provider %s connects without TLS, which this deployment doesn't allow; enable TLS in its config or add it to providerTLSAllowlist", name)
+	}
+	return nil
+}
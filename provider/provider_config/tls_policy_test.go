@@ -0,0 +1,59 @@
+package provider_config
+
+import (
+	"testing"
+
+	cfg "github.com/featureform/config"
+	pt "github.com/featureform/provider/provider_type"
+)
+
+func TestCheckTLSPolicy(t *testing.T) {
+	plaintext := RedisConfig{Addr: "0.0.0.0:6379"}.Serialized()
+	encrypted := RedisConfig{Addr: "0.0.0.0:6379", TLS: true}.Serialized()
+	dualWrite := DualWriteConfig{
+		Primary:   OnlineStoreConfig{Type: string(pt.RedisOnline), Config: []byte(encrypted)},
+		Secondary: OnlineStoreConfig{Type: string(pt.RedisOnline), Config: []byte(plaintext)},
+	}.Serialized()
+	s3Config := func(config S3FileStoreConfig) SerializedConfig {
+		serialized, _ := config.Serialize()
+		return serialized
+	}
+	required := cfg.Tunables{RequireProviderTLS: true, ProviderTLSAllowlist: "local-redis, dev-postgres"}
+	tests := []struct {
+		name         string
+		tunables     cfg.Tunables
+		providerName string
+		providerType pt.Type
+		config       SerializedConfig
+		allowed      bool
+	}{
+		{"Not Required", cfg.Tunables{}, "redis", pt.RedisOnline, plaintext, true},
+		{"Plaintext", required, "redis", pt.RedisOnline, plaintext, false},
+		{"Encrypted", required, "redis", pt.RedisOnline, encrypted, true},
+		{"Allowlisted", required, "local-redis", pt.RedisOnline, plaintext, true},
+		{"Postgres Default", required, "postgres", pt.PostgresOffline, (&PostgresConfig{}).Serialize(), false},
+		{"Postgres Required", required, "postgres", pt.PostgresOffline, (&PostgresConfig{SSLMode: "require"}).Serialize(), true},
+		{"ClickHouse Plaintext", required, "clickhouse", pt.ClickHouseOffline, (&ClickHouseConfig{}).Serialize(), false},
+		{"ClickHouse Secure", required, "clickhouse", pt.ClickHouseOffline, (&ClickHouseConfig{SSL: true}).Serialize(), true},
+		{"Pgvector Preferred", required, "pgvector", pt.PgvectorOnline, PgvectorConfig{SSLMode: "prefer"}.Serialized(), false},
+		{"Pgvector Verified", required, "pgvector", pt.PgvectorOnline, PgvectorConfig{SSLMode: "verify-full"}.Serialized(), true},
+		{"OpenSearch HTTP", required, "opensearch", pt.OpenSearchOnline, OpenSearchConfig{URL: "http://localhost:9200"}.Serialized(), false},
+		{"Dual Write Secondary", required, "dual", pt.DualWriteOnline, dualWrite, false},
+		{"Kafka TLS", required, "kafka", pt.Kafka, KafkaConfig{TLS: true}.Serialized(), true},
+		{"Kafka Registry HTTP", required, "kafka", pt.Kafka, KafkaConfig{TLS: true, SchemaRegistry: KafkaSchemaRegistryConfig{URL: "http://registry:8081"}}.Serialized(), false},
+		{"S3 Default Endpoint", required, "s3", pt.S3, s3Config(S3FileStoreConfig{BucketPath: "bucket"}), true},
+		{"S3 HTTP Endpoint", required, "s3", pt.S3, s3Config(S3FileStoreConfig{Endpoint: "http://minio:9000"}), false},
+		{"Spark S3 HTTP Endpoint", required, "spark", pt.SparkOffline, SerializedConfig(`{"StoreType": "S3", "StoreConfig": {"Endpoint": "http://minio:9000"}}`), false},
+		{"Cloud API", required, "dynamo", pt.DynamoDBOnline, DynamodbConfig{}.Serialized(), true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := CheckTLSPolicy(tt.tunables, tt.providerName, tt.providerType, tt.config)
+			if tt.allowed && err != nil {
+				t.Errorf("Expected provider to be allowed: %s", err)
+			} else if !tt.allowed && err == nil {
+				t.Errorf("Expected provider to be refused")
+			}
+		})
+	}
+}
@@ -0,0 +1,48 @@
+package provider_config
+
+import (
+	"encoding/json"
+
+	ss "github.com/featureform/helpers/string_set"
+)
+
+// VespaConfig configures a Vespa vector store. Featureform owns the Vespa
+// application: it deploys a package containing one schema per feature
+// variant to the config server, replacing any application deployed by
+// other means.
+type VespaConfig struct {
+	// URL is the container endpoint that serves the document and search
+	// APIs, such as "http://localhost:8080".
+	URL string
+	// ConfigURL is the config server endpoint that application packages are
+	// deployed to, such as "http://localhost:19071".
+	ConfigURL string
+	// Token is sent as a bearer token to both endpoints if it's set.
+	Token string
+}
+
+func (v VespaConfig) Serialized() SerializedConfig {
+	config, err := json.Marshal(v)
+	if err != nil {
+		panic(err)
+	}
+	return config
+}
+
+func (v *VespaConfig) Deserialize(config SerializedConfig) error {
+	err := json.Unmarshal(config, v)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (v VespaConfig) MutableFields() ss.StringSet {
+	return ss.StringSet{
+		"Token": true,
+	}
+}
+
+func (a VespaConfig) DifferingFields(b VespaConfig) (ss.StringSet, error) {
+	return differingFields(a, b)
+}
@@ -0,0 +1,47 @@
+package provider_config
+
+import (
+	"reflect"
+	"testing"
+
+	ss "github.com/featureform/helpers/string_set"
+)
+
+func TestVespaConfigMutableFields(t *testing.T) {
+	expected := ss.StringSet{
+		"Token": true,
+	}
+
+	config := VespaConfig{
+		URL:       "http://localhost:8080",
+		ConfigURL: "http://localhost:19071",
+	}
+	actual := config.MutableFields()
+
+	if !reflect.DeepEqual(expected, actual) {
+		t.Errorf("Expected %v but received %v", expected, actual)
+	}
+}
+
+func TestVespaConfigDifferingFields(t *testing.T) {
+	a := VespaConfig{
+		URL:       "http://localhost:8080",
+		ConfigURL: "http://localhost:19071",
+	}
+	b := VespaConfig{
+		URL:       "https://vespa.example.com",
+		ConfigURL: "http://localhost:19071",
+		Token:     "token",
+	}
+	expected := ss.StringSet{
+		"URL":   true,
+		"Token": true,
+	}
+	actual, err := a.DifferingFields(b)
+	if err != nil {
+		t.Fatalf("Failed to get differing fields due to error: %v", err)
+	}
+	if !reflect.DeepEqual(actual, expected) {
+		t.Errorf("Expected %v, but instead found %v", expected, actual)
+	}
+}
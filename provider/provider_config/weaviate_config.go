@@ -0,0 +1,49 @@
+package provider_config
+
+import (
+	"encoding/json"
+
+	ss "github.com/featureform/helpers/string_set"
+)
+
+// WeaviateConfig configures a Weaviate vector store. It authenticates with
+// APIKey if it's set, and otherwise with the OIDC client credentials flow if
+// OIDCClientID is set. The OIDC token endpoint is discovered from Weaviate.
+type WeaviateConfig struct {
+	// URL is the scheme, host and port of Weaviate, such as
+	// "https://cluster.weaviate.network".
+	URL              string
+	APIKey           string
+	OIDCClientID     string
+	OIDCClientSecret string
+	OIDCScopes       []string
+}
+
+func (w WeaviateConfig) Serialized() SerializedConfig {
+	config, err := json.Marshal(w)
+	if err != nil {
+		panic(err)
+	}
+	return config
+}
+
+func (w *WeaviateConfig) Deserialize(config SerializedConfig) error {
+	err := json.Unmarshal(config, w)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (w WeaviateConfig) MutableFields() ss.StringSet {
+	return ss.StringSet{
+		"APIKey":           true,
+		"OIDCClientID":     true,
+		"OIDCClientSecret": true,
+		"OIDCScopes":       true,
+	}
+}
+
+func (a WeaviateConfig) DifferingFields(b WeaviateConfig) (ss.StringSet, error) {
+	return differingFields(a, b)
+}
@@ -0,0 +1,52 @@
+package provider_config
+
+import (
+	"reflect"
+	"testing"
+
+	ss "github.com/featureform/helpers/string_set"
+)
+
+func TestWeaviateConfigMutableFields(t *testing.T) {
+	expected := ss.StringSet{
+		"APIKey":           true,
+		"OIDCClientID":     true,
+		"OIDCClientSecret": true,
+		"OIDCScopes":       true,
+	}
+
+	config := WeaviateConfig{
+		URL:    "https://cluster.weaviate.network",
+		APIKey: "key",
+	}
+	actual := config.MutableFields()
+
+	if !reflect.DeepEqual(expected, actual) {
+		t.Errorf("Expected %v but received %v", expected, actual)
+	}
+}
+
+func TestWeaviateConfigDifferingFields(t *testing.T) {
+	a := WeaviateConfig{
+		URL:    "https://cluster.weaviate.network",
+		APIKey: "key",
+	}
+	b := WeaviateConfig{
+		URL:          "https://other.weaviate.network",
+		OIDCClientID: "client",
+		OIDCScopes:   []string{"offline_access"},
+	}
+	expected := ss.StringSet{
+		"URL":          true,
+		"APIKey":       true,
+		"OIDCClientID": true,
+		"OIDCScopes":   true,
+	}
+	actual, err := a.DifferingFields(b)
+	if err != nil {
+		t.Fatalf("Failed to get differing fields due to error: %v", err)
+	}
+	if !reflect.DeepEqual(actual, expected) {
+		t.Errorf("Expected %v, but instead found %v", expected, actual)
+	}
+}
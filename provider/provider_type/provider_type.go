@@ -8,26 +8,40 @@ func (t Type) String() string {
 
 const (
 	// Online
-	LocalOnline     Type = "LOCAL_ONLINE"
-	RedisOnline     Type = "REDIS_ONLINE"
-	CassandraOnline Type = "CASSANDRA_ONLINE"
-	FirestoreOnline Type = "FIRESTORE_ONLINE"
-	DynamoDBOnline  Type = "DYNAMODB_ONLINE"
-	BlobOnline      Type = "BLOB_ONLINE"
-	MongoDBOnline   Type = "MONGODB_ONLINE"
+	LocalOnline        Type = "LOCAL_ONLINE"
+	RedisOnline        Type = "REDIS_ONLINE"
+	CassandraOnline    Type = "CASSANDRA_ONLINE"
+	FirestoreOnline    Type = "FIRESTORE_ONLINE"
+	DynamoDBOnline     Type = "DYNAMODB_ONLINE"
+	BlobOnline         Type = "BLOB_ONLINE"
+	MongoDBOnline      Type = "MONGODB_ONLINE"
+	DualWriteOnline    Type = "DUAL_WRITE_ONLINE"
+	ShardedRedisOnline Type = "SHARDED_REDIS_ONLINE"
+	WeaviateOnline     Type = "WEAVIATE_ONLINE"
+	PgvectorOnline     Type = "PGVECTOR_ONLINE"
+	OpenSearchOnline   Type = "OPENSEARCH_ONLINE"
+	ChromaOnline       Type = "CHROMA_ONLINE"
+	VespaOnline        Type = "VESPA_ONLINE"
 
 	// Offline
-	MemoryOffline    Type = "MEMORY_OFFLINE"
-	PostgresOffline  Type = "POSTGRES_OFFLINE"
-	SnowflakeOffline Type = "SNOWFLAKE_OFFLINE"
-	RedshiftOffline  Type = "REDSHIFT_OFFLINE"
-	SparkOffline     Type = "SPARK_OFFLINE"
-	BigQueryOffline  Type = "BIGQUERY_OFFLINE"
-	K8sOffline       Type = "K8S_OFFLINE"
-	S3               Type = "S3"
-	GCS              Type = "GCS"
-	HDFS             Type = "HDFS"
-	AZURE            Type = "AZURE"
+	MemoryOffline        Type = "MEMORY_OFFLINE"
+	PostgresOffline      Type = "POSTGRES_OFFLINE"
+	SnowflakeOffline     Type = "SNOWFLAKE_OFFLINE"
+	RedshiftOffline      Type = "REDSHIFT_OFFLINE"
+	ClickHouseOffline    Type = "CLICKHOUSE_OFFLINE"
+	DuckDBOffline        Type = "DUCKDB_OFFLINE"
+	AthenaOffline        Type = "ATHENA_OFFLINE"
+	DatabricksSQLOffline Type = "DATABRICKS_SQL_OFFLINE"
+	SparkOffline         Type = "SPARK_OFFLINE"
+	BigQueryOffline      Type = "BIGQUERY_OFFLINE"
+	K8sOffline           Type = "K8S_OFFLINE"
+	S3                   Type = "S3"
+	GCS                  Type = "GCS"
+	HDFS                 Type = "HDFS"
+	AZURE                Type = "AZURE"
+
+	// Streaming
+	Kafka Type = "KAFKA"
 )
 
 var AllProviderTypes = []Type{
@@ -38,10 +52,21 @@ var AllProviderTypes = []Type{
 	DynamoDBOnline,
 	BlobOnline,
 	MongoDBOnline,
+	DualWriteOnline,
+	ShardedRedisOnline,
+	WeaviateOnline,
+	PgvectorOnline,
+	OpenSearchOnline,
+	ChromaOnline,
+	VespaOnline,
 	MemoryOffline,
 	PostgresOffline,
 	SnowflakeOffline,
 	RedshiftOffline,
+	ClickHouseOffline,
+	DuckDBOffline,
+	AthenaOffline,
+	DatabricksSQLOffline,
 	SparkOffline,
 	BigQueryOffline,
 	K8sOffline,
@@ -49,4 +74,5 @@ var AllProviderTypes = []Type{
 	GCS,
 	HDFS,
 	AZURE,
+	Kafka,
 }
@@ -0,0 +1,116 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package provider
+
+import (
+	"context"
+	"fmt"
+	"math"
+
+	"golang.org/x/time/rate"
+)
+
+// RateLimitedTable wraps an online table and limits how many entities are
+// written to it per second, so that bulk writes don't exhaust a provider's
+// provisioned capacity or starve other users of a shared one. Writes block
+// until they're within the limit. Up to a second's worth of writes can be
+// made at once after the table has been idle.
+type RateLimitedTable struct {
+	OnlineStoreTable
+	limiter *rate.Limiter
+}
+
+func NewRateLimitedTable(table OnlineStoreTable, writesPerSecond float64) (*RateLimitedTable, error) {
+	if writesPerSecond <= 0 {
+		return nil, fmt.Errorf("write rate limit must be positive: %v", writesPerSecond)
+	}
+	burst := int(math.Max(1, writesPerSecond))
+	return &RateLimitedTable{
+		OnlineStoreTable: table,
+		limiter:          rate.NewLimiter(rate.Limit(writesPerSecond), burst),
+	}, nil
+}
+
+func (table *RateLimitedTable) Set(entity string, value interface{}) error {
+	if err := table.limiter.Wait(context.TODO()); err != nil {
+		return err
+	}
+	return table.OnlineStoreTable.Set(entity, value)
+}
+
+// SetBatch writes the values in batches of at most the burst size, waiting
+// before each for one token per entity.
+func (table *RateLimitedTable) SetBatch(values map[string]interface{}) error {
+	burst := table.limiter.Burst()
+	batch := make(map[string]interface{}, burst)
+	flush := func() error {
+		if err := table.limiter.WaitN(context.TODO(), len(batch)); err != nil {
+			return err
+		}
+		if err := setBatch(table.OnlineStoreTable, batch); err != nil {
+			return err
+		}
+		batch = make(map[string]interface{}, burst)
+		return nil
+	}
+	for entity, value := range values {
+		batch[entity] = value
+		if len(batch) == burst {
+			if err := flush(); err != nil {
+				return err
+			}
+		}
+	}
+	if len(batch) > 0 {
+		return flush()
+	}
+	return nil
+}
+
+// RequestRateLimitedTable wraps an online table and limits how many write
+// requests are made to its provider per second, rather than how many entities
+// are written. Each Set is a request, and so is each SetBatch if the table
+// can write batches, however many entities are in it. It suits providers
+// that are provisioned or throttled by requests, like shared Redis clusters.
+type RequestRateLimitedTable struct {
+	OnlineStoreTable
+	limiter *rate.Limiter
+}
+
+func NewRequestRateLimitedTable(table OnlineStoreTable, requestsPerSecond float64) (*RequestRateLimitedTable, error) {
+	if requestsPerSecond <= 0 {
+		return nil, fmt.Errorf("write request rate limit must be positive: %v", requestsPerSecond)
+	}
+	burst := int(math.Max(1, requestsPerSecond))
+	return &RequestRateLimitedTable{
+		OnlineStoreTable: table,
+		limiter:          rate.NewLimiter(rate.Limit(requestsPerSecond), burst),
+	}, nil
+}
+
+func (table *RequestRateLimitedTable) Set(entity string, value interface{}) error {
+	if err := table.limiter.Wait(context.TODO()); err != nil {
+		return err
+	}
+	return table.OnlineStoreTable.Set(entity, value)
+}
+
+// SetBatch writes the values in one request if the table can write batches,
+// and otherwise sets each entity as its own request.
+func (table *RequestRateLimitedTable) SetBatch(values map[string]interface{}) error {
+	batchTable, ok := table.OnlineStoreTable.(BatchSettableTable)
+	if !ok {
+		for entity, value := range values {
+			if err := table.Set(entity, value); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	if err := table.limiter.Wait(context.TODO()); err != nil {
+		return err
+	}
+	return batchTable.SetBatch(values)
+}
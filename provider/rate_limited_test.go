@@ -0,0 +1,89 @@
+package provider
+
+import (
+	"testing"
+	"time"
+)
+
+func TestRateLimitedTable(t *testing.T) {
+	if _, err := NewRateLimitedTable(&countingTable{}, 0); err == nil {
+		t.Fatalf("Expected error for non-positive rate limit")
+	}
+	inner := &countingTable{}
+	table, err := NewRateLimitedTable(inner, 10)
+	if err != nil {
+		t.Fatalf("Failed to create rate limited table: %s", err)
+	}
+	values := make(map[string]interface{})
+	for _, entity := range []string{"a", "b", "c", "d", "e", "f", "g", "h", "i", "j", "k", "l", "m", "n", "o"} {
+		values[entity] = 1
+	}
+	start := time.Now()
+	// The first 10 writes use the burst and the remaining 5 wait for tokens.
+	if err := table.SetBatch(values); err != nil {
+		t.Fatalf("Failed to set batch: %s", err)
+	}
+	if inner.sets != len(values) {
+		t.Fatalf("Expected %d sets, got %d", len(values), inner.sets)
+	}
+	if elapsed := time.Since(start); elapsed < 400*time.Millisecond {
+		t.Fatalf("Expected writes to be rate limited, took %s", elapsed)
+	}
+}
+
+// countingBatchTable counts the batches written to it.
+type countingBatchTable struct {
+	countingTable
+	batches int
+}
+
+func (table *countingBatchTable) SetBatch(values map[string]interface{}) error {
+	table.batches++
+	table.sets += len(values)
+	return nil
+}
+
+func TestRequestRateLimitedTable(t *testing.T) {
+	if _, err := NewRequestRateLimitedTable(&countingTable{}, 0); err == nil {
+		t.Fatalf("Expected error for non-positive rate limit")
+	}
+	inner := &countingBatchTable{}
+	table, err := NewRequestRateLimitedTable(inner, 2)
+	if err != nil {
+		t.Fatalf("Failed to create rate limited table: %s", err)
+	}
+	values := make(map[string]interface{})
+	for _, entity := range []string{"a", "b", "c", "d", "e", "f", "g", "h", "i", "j"} {
+		values[entity] = 1
+	}
+	start := time.Now()
+	// Each batch is one request, so the first 2 use the burst and the third
+	// waits for a token.
+	for i := 0; i < 3; i++ {
+		if err := table.SetBatch(values); err != nil {
+			t.Fatalf("Failed to set batch: %s", err)
+		}
+	}
+	if inner.batches != 3 || inner.sets != 3*len(values) {
+		t.Fatalf("Expected 3 batches of %d sets, got %d batches of %d sets", len(values), inner.batches, inner.sets)
+	}
+	if elapsed := time.Since(start); elapsed < 400*time.Millisecond || elapsed > 2*time.Second {
+		t.Fatalf("Expected batches to be limited by request, took %s", elapsed)
+	}
+
+	unbatched := &countingTable{}
+	table, err = NewRequestRateLimitedTable(unbatched, 2)
+	if err != nil {
+		t.Fatalf("Failed to create rate limited table: %s", err)
+	}
+	start = time.Now()
+	if err := table.SetBatch(map[string]interface{}{"a": 1, "b": 2, "c": 3}); err != nil {
+		t.Fatalf("Failed to set batch: %s", err)
+	}
+	if unbatched.sets != 3 {
+		t.Fatalf("Expected 3 sets, got %d", unbatched.sets)
+	}
+	if elapsed := time.Since(start); elapsed < 400*time.Millisecond {
+		t.Fatalf("Expected each set of a table without batches to be a request, took %s", elapsed)
+	}
+}
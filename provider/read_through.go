@@ -0,0 +1,88 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package provider
+
+import (
+	"fmt"
+	"time"
+)
+
+// ReadThroughStore wraps an online store so that entities missing from a
+// table are looked up in the feature's offline materialization. Values found
+// offline are written back to the online table, so a feature served before
+// its materialization has finished copying doesn't miss on every request.
+type ReadThroughStore struct {
+	OnlineStore
+	lookup MaterializedEntityLookup
+}
+
+func NewReadThroughStore(online OnlineStore, offline OfflineStore) (*ReadThroughStore, error) {
+	lookup, ok := offline.(MaterializedEntityLookup)
+	if !ok {
+		return nil, fmt.Errorf("%T does not support materialized entity lookups", offline)
+	}
+	return &ReadThroughStore{
+		OnlineStore: online,
+		lookup:      lookup,
+	}, nil
+}
+
+func (store *ReadThroughStore) GetTable(feature, variant string) (OnlineStoreTable, error) {
+	table, err := store.OnlineStore.GetTable(feature, variant)
+	if err != nil {
+		return nil, err
+	}
+	return &readThroughTable{
+		OnlineStoreTable: table,
+		id:               ResourceID{feature, variant, Feature},
+		lookup:           store.lookup,
+	}, nil
+}
+
+// Capabilities only reports write times, since read through tables don't
+// expose the other interfaces of the tables they wrap.
+func (store *ReadThroughStore) Capabilities() OnlineCapabilities {
+	return OnlineCapabilities{Timestamps: store.OnlineStore.Capabilities().Timestamps}
+}
+
+type readThroughTable struct {
+	OnlineStoreTable
+	id     ResourceID
+	lookup MaterializedEntityLookup
+}
+
+func (table *readThroughTable) Get(entity string) (interface{}, error) {
+	value, _, err := table.GetWithTimestamp(entity)
+	return value, err
+}
+
+// GetWithTimestamp returns the write time recorded by the online table, or
+// the offline record's timestamp if the value was read through. The time is
+// zero if the online table doesn't record write times.
+func (table *readThroughTable) GetWithTimestamp(entity string) (interface{}, time.Time, error) {
+	var value interface{}
+	var ts time.Time
+	var err error
+	if timestamped, ok := table.OnlineStoreTable.(TimestampedTable); ok {
+		value, ts, err = timestamped.GetWithTimestamp(entity)
+	} else {
+		value, err = table.OnlineStoreTable.Get(entity)
+	}
+	if _, notFound := err.(*EntityNotFound); !notFound {
+		return value, ts, err
+	}
+	rec, lookupErr := table.lookup.LookupMaterializedEntity(table.id, entity)
+	switch lookupErr.(type) {
+	case nil:
+	case *EntityNotFound, *TableNotFound, *MaterializationNotFound:
+		return nil, time.Time{}, err
+	default:
+		return nil, time.Time{}, fmt.Errorf("offline lookup of entity %s failed: %w", entity, lookupErr)
+	}
+	// The backfill is best effort. If it fails the value is still served, and
+	// the next miss tries again.
+	_ = table.OnlineStoreTable.Set(entity, rec.Value)
+	return rec.Value, rec.TS, nil
+}
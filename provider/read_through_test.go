@@ -0,0 +1,76 @@
+package provider
+
+import (
+	"testing"
+	"time"
+)
+
+func TestReadThroughStore(t *testing.T) {
+	online := NewLocalOnlineStore()
+	offline := NewMemoryOfflineStore()
+	id := ResourceID{"feature", "variant", Feature}
+	if _, err := online.CreateTable(id.Name, id.Variant, Int); err != nil {
+		t.Fatalf("Failed to create online table: %s", err)
+	}
+	offlineTable, err := offline.CreateResourceTable(id, TableSchema{})
+	if err != nil {
+		t.Fatalf("Failed to create offline table: %s", err)
+	}
+	ts := time.UnixMilli(1000).UTC()
+	records := []ResourceRecord{
+		{Entity: "a", Value: 1, TS: ts.Add(-time.Hour)},
+		{Entity: "a", Value: 2, TS: ts},
+	}
+	for _, rec := range records {
+		if err := offlineTable.Write(rec); err != nil {
+			t.Fatalf("Failed to write record: %s", err)
+		}
+	}
+	store, err := NewReadThroughStore(online, offline)
+	if err != nil {
+		t.Fatalf("Failed to create read-through store: %s", err)
+	}
+	table, err := store.GetTable(id.Name, id.Variant)
+	if err != nil {
+		t.Fatalf("Failed to get table: %s", err)
+	}
+	value, updated, err := table.(TimestampedTable).GetWithTimestamp("a")
+	if err != nil {
+		t.Fatalf("Failed to read through: %s", err)
+	}
+	if value != 2 || !updated.Equal(ts) {
+		t.Fatalf("Expected latest offline value 2 at %s, got %v at %s", ts, value, updated)
+	}
+	onlineTable, err := online.GetTable(id.Name, id.Variant)
+	if err != nil {
+		t.Fatalf("Failed to get online table: %s", err)
+	}
+	if value, err := onlineTable.Get("a"); err != nil || value != 2 {
+		t.Fatalf("Expected value to be backfilled, got %v %v", value, err)
+	}
+	if _, err := table.Get("b"); err == nil {
+		t.Fatalf("Expected missing entity to not be found")
+	} else if _, ok := err.(*EntityNotFound); !ok {
+		t.Fatalf("Expected EntityNotFound, got %T: %s", err, err)
+	}
+}
+
+func TestReadThroughStoreWithoutOfflineTable(t *testing.T) {
+	online := NewLocalOnlineStore()
+	if _, err := online.CreateTable("feature", "variant", Int); err != nil {
+		t.Fatalf("Failed to create online table: %s", err)
+	}
+	store, err := NewReadThroughStore(online, NewMemoryOfflineStore())
+	if err != nil {
+		t.Fatalf("Failed to create read-through store: %s", err)
+	}
+	table, err := store.GetTable("feature", "variant")
+	if err != nil {
+		t.Fatalf("Failed to get table: %s", err)
+	}
+	if _, err := table.Get("a"); err == nil {
+		t.Fatalf("Expected missing entity to not be found")
+	} else if _, ok := err.(*EntityNotFound); !ok {
+		t.Fatalf("Expected EntityNotFound, got %T: %s", err, err)
+	}
+}
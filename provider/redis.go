@@ -2,14 +2,20 @@ package provider
 
 import (
 	"context"
+	"crypto/tls"
 	"encoding/base64"
+	"encoding/binary"
 	"encoding/json"
 	"fmt"
+	"math"
 	"strconv"
+	"strings"
 	"time"
+	"unicode"
 
 	pc "github.com/featureform/provider/provider_config"
 	pt "github.com/featureform/provider/provider_type"
+	"github.com/featureform/types"
 
 	"github.com/redis/rueidis"
 )
@@ -59,6 +65,9 @@ func NewRedisOnlineStore(options *pc.RedisConfig) (*redisOnlineStore, error) {
 		*/
 		DisableCache: true,
 	}
+	if options.TLS {
+		redisOptions.TLSConfig = &tls.Config{}
+	}
 	redisClient, err := rueidis.NewClient(redisOptions)
 	if err != nil {
 		return nil, err
@@ -94,23 +103,12 @@ func (store *redisOnlineStore) GetTable(feature, variant string) (OnlineStoreTab
 	if err != nil {
 		return nil, &TableNotFound{feature, variant}
 	}
-	var table OnlineStoreTable
-	// This maintains backwards compatibility with the previous implementation,
-	// which wrote the scalar type string as the value to the field under the
-	// tables hash.
-	if _, isScalarString := ScalarTypes[ScalarType(vType)]; isScalarString {
-		return &redisOnlineTable{
-			client:    store.client,
-			key:       key,
-			valueType: ScalarType(vType),
-		}, nil
-	}
-	valueTypeJSON := &ValueTypeJSONWrapper{}
-	err = json.Unmarshal([]byte(vType), valueTypeJSON)
+	valueType, err := parseRedisTableType(vType)
 	if err != nil {
 		return nil, err
 	}
-	switch valueTypeJSON.ValueType.(type) {
+	var table OnlineStoreTable
+	switch valueType.(type) {
 	case VectorType:
 		table = &redisOnlineIndex{
 			client: store.client,
@@ -119,20 +117,58 @@ func (store *redisOnlineStore) GetTable(feature, variant string) (OnlineStoreTab
 				Feature: feature,
 				Variant: variant,
 			},
-			valueType: valueTypeJSON.ValueType,
+			valueType: valueType,
 		}
-	case ScalarType:
+	case ScalarType, ListType, MapType:
 		table = &redisOnlineTable{
 			client:    store.client,
 			key:       key,
-			valueType: valueTypeJSON.ValueType,
+			valueType: valueType,
 		}
 	default:
-		return nil, fmt.Errorf("unknown value type: %T", valueTypeJSON.ValueType)
+		return nil, fmt.Errorf("unknown value type: %T", valueType)
 	}
 	return table, nil
 }
 
+func parseRedisTableType(vType string) (ValueType, error) {
+	// This maintains backwards compatibility with the previous implementation,
+	// which wrote the scalar type string as the value to the field under the
+	// tables hash.
+	if _, isScalarString := ScalarTypes[ScalarType(vType)]; isScalarString {
+		return ScalarType(vType), nil
+	}
+	valueTypeJSON := &ValueTypeJSONWrapper{}
+	if err := json.Unmarshal([]byte(vType), valueTypeJSON); err != nil {
+		return nil, err
+	}
+	return valueTypeJSON.ValueType, nil
+}
+
+func (store *redisOnlineStore) ListTables() ([]OnlineTableInfo, error) {
+	cmd := store.client.B().
+		Hgetall().
+		Key(fmt.Sprintf("%s__tables", store.prefix)).
+		Build()
+	types, err := store.client.Do(context.TODO(), cmd).AsStrMap()
+	if err != nil {
+		return nil, err
+	}
+	tables := make([]OnlineTableInfo, 0, len(types))
+	for serializedKey, vType := range types {
+		var key redisTableKey
+		if err := json.Unmarshal([]byte(serializedKey), &key); err != nil {
+			return nil, fmt.Errorf("could not parse table key %s: %v", serializedKey, err)
+		}
+		valueType, err := parseRedisTableType(vType)
+		if err != nil {
+			return nil, err
+		}
+		tables = append(tables, OnlineTableInfo{key.Feature, key.Variant, valueType})
+	}
+	return tables, nil
+}
+
 func (store *redisOnlineStore) CreateTable(feature, variant string, valueType ValueType) (OnlineStoreTable, error) {
 	key := redisTableKey{store.prefix, feature, variant}
 	cmd := store.client.B().
@@ -172,7 +208,7 @@ func (store *redisOnlineStore) CreateTable(feature, variant string, valueType Va
 			},
 			valueType: valueType,
 		}
-	case ScalarType:
+	case ScalarType, ListType, MapType:
 		table = &redisOnlineTable{
 			client:    store.client,
 			key:       key,
@@ -184,8 +220,141 @@ func (store *redisOnlineStore) CreateTable(feature, variant string, valueType Va
 	return table, nil
 }
 
+// DeleteTable deletes the table's values and removes it from the tables
+// hash. For vector tables, the search index is dropped with its entities'
+// hashes, so they no longer appear in Nearest results.
 func (store *redisOnlineStore) DeleteTable(feature, variant string) error {
-	return nil
+	table, err := store.GetTable(feature, variant)
+	if err != nil {
+		return err
+	}
+	if index, isIndex := table.(*redisOnlineIndex); isIndex {
+		serializedKey, err := index.key.serialize("")
+		if err != nil {
+			return err
+		}
+		cmd := store.client.B().FtDropindex().Index(string(serializedKey)).Dd().Build()
+		if err := store.client.Do(context.TODO(), cmd).Error(); err != nil && !strings.Contains(strings.ToLower(err.Error()), "unknown index") {
+			return err
+		}
+	}
+	if err := store.Truncate(feature, variant); err != nil {
+		return err
+	}
+	key := redisTableKey{store.prefix, feature, variant}
+	cmd := store.client.B().
+		Hdel().
+		Key(fmt.Sprintf("%s__tables", store.prefix)).
+		Field(key.String()).
+		Build()
+	return store.client.Do(context.TODO(), cmd).Error()
+}
+
+func (store *redisOnlineStore) Capabilities() OnlineCapabilities {
+	return OnlineCapabilities{
+		BatchWrites:      true,
+		Scan:             true,
+		Stats:            true,
+		Increment:        true,
+		Append:           true,
+		Timestamps:       true,
+		Truncate:         true,
+		ListTables:       true,
+		Vectors:          true,
+		VectorNamespaces: true,
+		IndexStats:       true,
+		AsyncIndexBuilds: true,
+	}
+}
+
+// redisIndexPollInterval is how often WatchIndexBuild checks FT.INFO.
+const redisIndexPollInterval = time.Second
+
+// WatchIndexBuild waits for RediSearch to finish indexing the hashes that
+// existed when the index was created, which it does in the background.
+// Hashes written after that are indexed as they're written.
+func (store *redisOnlineStore) WatchIndexBuild(feature, variant string) (types.CompletionWatcher, error) {
+	table, err := store.GetTable(feature, variant)
+	if err != nil {
+		return nil, err
+	}
+	index, isIndex := table.(*redisOnlineIndex)
+	if !isIndex {
+		return nil, fmt.Errorf("table %s %s is not a vector index", feature, variant)
+	}
+	return newIndexBuildWatcher(redisIndexPollInterval, func() (bool, error) {
+		stats, err := index.IndexStats()
+		if err != nil {
+			return false, err
+		}
+		return !stats.Building, nil
+	}), nil
+}
+
+// Truncate deletes the keys holding the table's values, write times and
+// lists. For vector tables, each entity's hash is deleted and the index is
+// kept.
+func (store *redisOnlineStore) Truncate(feature, variant string) error {
+	table, err := store.GetTable(feature, variant)
+	if err != nil {
+		return err
+	}
+	switch t := table.(type) {
+	case *redisOnlineTable:
+		cmd := store.client.B().Del().Key(t.key.String(), t.updatedKey()).Build()
+		if err := store.client.Do(context.TODO(), cmd).Error(); err != nil {
+			return err
+		}
+		return store.deleteMatching(escapeRedisPattern(t.listKey("")) + "*")
+	case *redisOnlineIndex:
+		serializedKey, err := t.key.serialize("")
+		if err != nil {
+			return err
+		}
+		// Entity keys are the serialized key with the entity filled in, so
+		// they all start with the serialized key up to the empty entity.
+		prefix := strings.TrimSuffix(string(serializedKey), `"}`)
+		return store.deleteMatching(escapeRedisPattern(prefix) + "*")
+	default:
+		return fmt.Errorf("cannot truncate table of type %T", table)
+	}
+}
+
+// deleteMatching deletes every key matching the glob pattern, a page at a
+// time.
+func (store *redisOnlineStore) deleteMatching(pattern string) error {
+	var cursor uint64
+	for {
+		cmd := store.client.B().Scan().Cursor(cursor).Match(pattern).Count(1000).Build()
+		entry, err := store.client.Do(context.TODO(), cmd).AsScanEntry()
+		if err != nil {
+			return err
+		}
+		if len(entry.Elements) > 0 {
+			del := store.client.B().Del().Key(entry.Elements...).Build()
+			if err := store.client.Do(context.TODO(), del).Error(); err != nil {
+				return err
+			}
+		}
+		if entry.Cursor == 0 {
+			return nil
+		}
+		cursor = entry.Cursor
+	}
+}
+
+// escapeRedisPattern escapes the characters that are special in the glob
+// patterns used by SCAN MATCH.
+func escapeRedisPattern(s string) string {
+	var escaped strings.Builder
+	for _, r := range s {
+		switch r {
+		case '*', '?', '[', ']', '\\':
+			escaped.WriteRune('\\')
+		}
+		escaped.WriteRune(r)
+	}
+	return escaped.String()
 }
 
 func (store *redisOnlineStore) CreateIndex(feature, variant string, vectorType VectorType) (VectorStoreTable, error) {
@@ -202,22 +371,60 @@ func (store *redisOnlineStore) CreateIndex(feature, variant string, vectorType V
 	return table, nil
 }
 
+// redisDistanceMetrics maps distance metrics to RediSearch's names. Scores
+// are distances for every metric, so Nearest always sorts them ascending.
+var redisDistanceMetrics = map[DistanceMetric]string{
+	CosineDistance:       "COSINE",
+	L2Distance:           "L2",
+	InnerProductDistance: "IP",
+}
+
+// redisVectorTypes maps vector storage types to RediSearch's names.
+var redisVectorTypes = map[ScalarType]string{
+	Float16: "FLOAT16",
+	Float32: "FLOAT32",
+	Float64: "FLOAT64",
+}
+
 func (store *redisOnlineStore) createIndexCmd(key redisIndexKey, vectorType VectorType) (rueidis.Completed, error) {
 	serializedKey, err := key.serialize("")
 	if err != nil {
 		return rueidis.Completed{}, err
 	}
-	requiredParams := []string{
-		"TYPE", "FLOAT32",
+	options := vectorType.Options()
+	if err := options.validate("redis", false); err != nil {
+		return rueidis.Completed{}, err
+	}
+	if err := vectorType.validateStorage("redis", Float16, Float32, Float64); err != nil {
+		return rueidis.Completed{}, err
+	}
+	if err := vectorType.validateNames("redis", false); err != nil {
+		return rueidis.Completed{}, err
+	}
+	params := []string{
+		"TYPE", redisVectorTypes[vectorType.Storage()],
 		"DIM", strconv.FormatUint(uint64(vectorType.Dimension), 10),
-		"DISTANCE_METRIC", "COSINE",
+		"DISTANCE_METRIC", redisDistanceMetrics[vectorType.Metric()],
+	}
+	if options.M > 0 {
+		params = append(params, "M", strconv.Itoa(int(options.M)))
+	}
+	if options.EfConstruction > 0 {
+		params = append(params, "EF_CONSTRUCTION", strconv.Itoa(int(options.EfConstruction)))
+	}
+	if options.EfRuntime > 0 {
+		params = append(params, "EF_RUNTIME", strconv.Itoa(int(options.EfRuntime)))
 	}
 	return store.client.B().
 		FtCreate().
 		Index(string(serializedKey)).
 		Schema().
 		FieldName(key.getVectorField()).
-		Vector("HNSW", int64(len(requiredParams)), requiredParams...).
+		Vector("HNSW", int64(len(params)), params...).
+		FieldName(key.getTextField()).
+		Text().
+		FieldName(key.getNamespaceField()).
+		Tag().
 		Build(), nil
 }
 
@@ -228,99 +435,338 @@ type redisOnlineTable struct {
 }
 
 func (table redisOnlineTable) Set(entity string, value interface{}) error {
+	return table.SetBatch(map[string]interface{}{entity: value})
+}
+
+// SetBatch pipelines an HSET of each entity's value, along with its write
+// time, in a single round trip.
+func (table redisOnlineTable) SetBatch(values map[string]interface{}) error {
+	cmds := make(rueidis.Commands, 0, 2*len(values))
+	for entity, value := range values {
+		serialized, err := table.serialize(entity, value)
+		if err != nil {
+			return err
+		}
+		cmd := table.client.B().
+			Hset().
+			Key(table.key.String()).
+			FieldValue().
+			FieldValue(entity, serialized).
+			Build()
+		cmds = append(cmds, cmd, table.touchCmd(entity))
+	}
+	if len(cmds) == 0 {
+		return nil
+	}
+	for _, resp := range table.client.DoMulti(context.TODO(), cmds...) {
+		if err := resp.Error(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (table redisOnlineTable) serialize(entity string, value interface{}) (string, error) {
+	if vector, isVector := float32Vector(value); isVector && table.valueType.IsVector() {
+		if err := checkVectorDimension(table.valueType, entity, vector); err != nil {
+			return "", err
+		}
+		value = vector
+	}
+	if composite, isComposite := table.valueType.(compositeType); isComposite {
+		encoded, err := serializeComposite(composite, value)
+		return string(encoded), err
+	}
+	return formatRedisValue(value)
+}
+
+func (table redisOnlineTable) Get(entity string) (interface{}, error) {
+	cmd := table.client.B().
+		Hget().
+		Key(table.key.String()).
+		Field(entity).
+		Build()
+	resp := table.client.Do(context.TODO(), cmd)
+	if resp.Error() != nil {
+		return nil, &EntityNotFound{entity}
+	}
+	val, err := resp.ToString()
+	if err != nil {
+		return nil, err
+	}
+	return parseRedisValue(table.valueType, val)
+}
+
+func (table redisOnlineTable) Scan() (FeatureIterator, error) {
+	return &redisScanIterator{table: table}, nil
+}
+
+// redisScanIterator iterates over a table's hash a page at a time with HSCAN.
+type redisScanIterator struct {
+	table   redisOnlineTable
+	cursor  uint64
+	page    []string
+	done    bool
+	current ResourceRecord
+	err     error
+}
+
+func (it *redisScanIterator) Next() bool {
+	for len(it.page) == 0 {
+		if it.done || it.err != nil {
+			return false
+		}
+		cmd := it.table.client.B().
+			Hscan().
+			Key(it.table.key.String()).
+			Cursor(it.cursor).
+			Count(1000).
+			Build()
+		entry, err := it.table.client.Do(context.TODO(), cmd).AsScanEntry()
+		if err != nil {
+			it.err = err
+			return false
+		}
+		// Elements alternate between fields and their values.
+		it.page = entry.Elements
+		it.cursor = entry.Cursor
+		it.done = entry.Cursor == 0
+	}
+	entity, val := it.page[0], it.page[1]
+	it.page = it.page[2:]
+	value, err := parseRedisValue(it.table.valueType, val)
+	if err != nil {
+		it.err = fmt.Errorf("could not parse value of %s: %w", entity, err)
+		return false
+	}
+	it.current = ResourceRecord{Entity: entity, Value: value}
+	return true
+}
+
+func (it *redisScanIterator) Value() ResourceRecord {
+	return it.current
+}
+
+func (it *redisScanIterator) Err() error {
+	return it.err
+}
+
+func (it *redisScanIterator) Close() error {
+	return nil
+}
+
+// updatedKey returns the key of the hash holding the last write time of each
+// entity, in Unix nanoseconds.
+func (table redisOnlineTable) updatedKey() string {
+	return fmt.Sprintf("%s__updated", table.key.String())
+}
+
+func (table redisOnlineTable) touchCmd(entity string) rueidis.Completed {
+	return table.client.B().
+		Hset().
+		Key(table.updatedKey()).
+		FieldValue().
+		FieldValue(entity, strconv.FormatInt(time.Now().UnixNano(), 10)).
+		Build()
+}
+
+func (table redisOnlineTable) GetWithTimestamp(entity string) (interface{}, time.Time, error) {
+	resps := table.client.DoMulti(
+		context.TODO(),
+		table.client.B().Hget().Key(table.key.String()).Field(entity).Build(),
+		table.client.B().Hget().Key(table.updatedKey()).Field(entity).Build(),
+	)
+	if resps[0].Error() != nil {
+		return nil, time.Time{}, &EntityNotFound{entity}
+	}
+	val, err := resps[0].ToString()
+	if err != nil {
+		return nil, time.Time{}, err
+	}
+	parsed, err := parseRedisValue(table.valueType, val)
+	if err != nil {
+		return nil, time.Time{}, err
+	}
+	var updated time.Time
+	if nanos, err := resps[1].AsInt64(); err == nil {
+		updated = time.Unix(0, nanos)
+	} else if !rueidis.IsRedisNil(err) {
+		return nil, time.Time{}, err
+	}
+	return parsed, updated, nil
+}
+
+// Stats uses MEMORY USAGE, which samples large hashes, so Bytes is an
+// estimate that includes Redis' own overhead.
+func (table redisOnlineTable) Stats() (TableStats, error) {
+	resps := table.client.DoMulti(
+		context.TODO(),
+		table.client.B().Hlen().Key(table.key.String()).Build(),
+		table.client.B().MemoryUsage().Key(table.key.String()).Build(),
+		table.client.B().MemoryUsage().Key(table.updatedKey()).Build(),
+	)
+	entities, err := resps[0].AsInt64()
+	if err != nil {
+		return TableStats{}, err
+	}
+	stats := TableStats{Entities: entities}
+	for _, resp := range resps[1:] {
+		// Keys that don't exist yet have no usage.
+		bytes, err := resp.AsInt64()
+		if err != nil && !rueidis.IsRedisNil(err) {
+			return TableStats{}, err
+		}
+		stats.Bytes += bytes
+	}
+	return stats, nil
+}
+
+// listKey returns the key of the Redis list holding the appended values of an
+// entity. Lists can't be nested in the table's hash, so each gets its own key.
+func (table redisOnlineTable) listKey(entity string) string {
+	return fmt.Sprintf("%s__list__%s", table.key.String(), entity)
+}
+
+func (table redisOnlineTable) Append(entity string, value interface{}, maxLen int) error {
+	if maxLen <= 0 {
+		return fmt.Errorf("max list length must be positive: %d", maxLen)
+	}
+	serialized, err := formatRedisValue(value)
+	if err != nil {
+		return err
+	}
+	key := table.listKey(entity)
+	return table.client.Dedicated(func(client rueidis.DedicatedClient) error {
+		cmds := rueidis.Commands{
+			client.B().Multi().Build(),
+			client.B().Lpush().Key(key).Element(serialized).Build(),
+			client.B().Ltrim().Key(key).Start(0).Stop(int64(maxLen - 1)).Build(),
+			client.B().Exec().Build(),
+		}
+		for _, resp := range client.DoMulti(context.TODO(), cmds...) {
+			if err := resp.Error(); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func (table redisOnlineTable) GetList(entity string) ([]interface{}, error) {
+	cmd := table.client.B().
+		Lrange().
+		Key(table.listKey(entity)).
+		Start(0).
+		Stop(-1).
+		Build()
+	vals, err := table.client.Do(context.TODO(), cmd).AsStrSlice()
+	if err != nil {
+		return nil, err
+	}
+	if len(vals) == 0 {
+		return nil, &EntityNotFound{entity}
+	}
+	list := make([]interface{}, len(vals))
+	for i, val := range vals {
+		if list[i], err = parseRedisValue(table.valueType, val); err != nil {
+			return nil, err
+		}
+	}
+	return list, nil
+}
+
+func formatRedisValue(value interface{}) (string, error) {
 	switch v := value.(type) {
 	case nil:
-		value = "nil"
+		return "nil", nil
 	case string:
-		value = v
+		return v, nil
 	case int:
-		value = strconv.Itoa(v)
+		return strconv.Itoa(v), nil
 	case int32:
-		value = strconv.FormatInt(int64(v), 10)
+		return strconv.FormatInt(int64(v), 10), nil
 	case int64:
-		value = strconv.FormatInt(v, 10)
+		return strconv.FormatInt(v, 10), nil
 	case float32:
-		value = strconv.FormatFloat(float64(v), 'f', -1, 32)
+		return strconv.FormatFloat(float64(v), 'f', -1, 32), nil
 	case float64:
-		value = strconv.FormatFloat(v, 'f', -1, 64)
+		return strconv.FormatFloat(v, 'f', -1, 64), nil
 	case bool:
 		// The previous Redis client stored booleans as 1 or 0; to maintain backwards compatibility
 		// we do the same here, stringifying the value to satisfy the interface. See redis_test.go
 		// lines 59-66 for more reasons why we do this.
 		if v {
-			value = "1"
-		} else {
-			value = "0"
+			return "1", nil
 		}
+		return "0", nil
 	case time.Time:
-		value = v.Format(time.RFC3339)
+		return v.Format(time.RFC3339), nil
 	case []float32:
-		value = rueidis.VectorString32(v)
+		return rueidis.VectorString32(v), nil
 	default:
-		return fmt.Errorf("type %T of value %v is unsupported", value, value)
-	}
-	cmd := table.client.B().
-		Hset().
-		Key(table.key.String()).
-		FieldValue().
-		FieldValue(entity, value.(string)).
-		Build()
-	res := table.client.Do(context.TODO(), cmd)
-	if res.Error() != nil {
-		return res.Error()
+		return "", fmt.Errorf("type %T of value %v is unsupported", value, value)
 	}
-	return nil
 }
 
-func (table redisOnlineTable) Get(entity string) (interface{}, error) {
-	cmd := table.client.B().
-		Hget().
-		Key(table.key.String()).
-		Field(entity).
-		Build()
-	resp := table.client.Do(context.TODO(), cmd)
-	if resp.Error() != nil {
-		return nil, &EntityNotFound{entity}
+func parseRedisValue(valueType ValueType, val string) (interface{}, error) {
+	if valueType.IsVector() {
+		return rueidis.ToVector32(val), nil
 	}
-	var result interface{}
-	var err error
-	val, err := resp.ToString()
-	if err != nil {
-		return nil, err
+	if composite, isComposite := valueType.(compositeType); isComposite {
+		return deserializeComposite(composite, []byte(val))
 	}
+	return parseScalar(valueType.Scalar(), val)
+}
+
+func (table redisOnlineTable) Increment(entity string, delta interface{}) (interface{}, error) {
 	if table.valueType.IsVector() {
-		return rueidis.ToVector32(val), nil
+		return nil, fmt.Errorf("cannot increment vector type %v", table.valueType)
 	}
-	switch table.valueType {
-	case NilType, String:
-		result, err = val, nil
-	case Int:
-		result, err = strconv.Atoi(val)
-	case Int32:
-		if result, err = strconv.ParseInt(val, 10, 32); err == nil {
-			result = int32(result.(int64))
-		}
-	case Int64:
-		result, err = strconv.ParseInt(val, 10, 64)
-	case Float32:
-		if result, err = strconv.ParseFloat(val, 32); err == nil {
-			result, err = float32(result.(float64)), nil
+	switch table.valueType.Scalar() {
+	case Int, Int32, Int64:
+		d, err := toInt64(delta)
+		if err != nil {
+			return nil, err
 		}
-	case Float64:
-		result, err = strconv.ParseFloat(val, 64)
-	case Bool:
-		result, err = strconv.ParseBool(val)
-	case Timestamp, Datetime: // Including `Datetime` here maintains compatibility with previously create timestamp tables
-		// Maintains compatibility with go-redis implementation:
-		// https://github.com/redis/go-redis/blob/v8.11.5/command.go#L939
-		result, err = time.Parse(time.RFC3339Nano, val)
+		cmd := table.client.B().
+			Hincrby().
+			Key(table.key.String()).
+			Field(entity).
+			Increment(d).
+			Build()
+		result, err := table.client.Do(context.TODO(), cmd).AsInt64()
+		if err != nil {
+			return nil, err
+		}
+		if err := table.client.Do(context.TODO(), table.touchCmd(entity)).Error(); err != nil {
+			return nil, err
+		}
+		return castInt64(table.valueType.Scalar(), result), nil
+	case Float32, Float64:
+		d, err := toFloat64(delta)
+		if err != nil {
+			return nil, err
+		}
+		cmd := table.client.B().
+			Hincrbyfloat().
+			Key(table.key.String()).
+			Field(entity).
+			Increment(d).
+			Build()
+		result, err := table.client.Do(context.TODO(), cmd).AsFloat64()
+		if err != nil {
+			return nil, err
+		}
+		if err := table.client.Do(context.TODO(), table.touchCmd(entity)).Error(); err != nil {
+			return nil, err
+		}
+		if table.valueType.Scalar() == Float32 {
+			return float32(result), nil
+		}
+		return result, nil
 	default:
-		result, err = val, nil
+		return nil, fmt.Errorf("cannot increment non-numeric type %v", table.valueType)
 	}
-	if err != nil {
-		return nil, fmt.Errorf("could not cast value: %v to %s: %w", resp, table.valueType, err)
-	}
-	return result, nil
 }
 
 type redisOnlineIndex struct {
@@ -350,10 +796,25 @@ func (k redisIndexKey) getVectorField() string {
 	return fmt.Sprintf("vector_field_%s", encoded)
 }
 
+// getNamespaceField returns the name of the TAG field holding the entity's
+// namespace.
+func (k redisIndexKey) getNamespaceField() string {
+	name_variant := fmt.Sprintf("%s_%s", k.Feature, k.Variant)
+	encoded := base64.RawStdEncoding.EncodeToString([]byte(name_variant))
+	return fmt.Sprintf("namespace_field_%s", encoded)
+}
+
+// getTextField returns the name of the TEXT field searched by
+// HybridNearest. Like the vector field, it's unique to the feature variant.
+func (k redisIndexKey) getTextField() string {
+	name_variant := fmt.Sprintf("%s_%s", k.Feature, k.Variant)
+	encoded := base64.RawStdEncoding.EncodeToString([]byte(name_variant))
+	return fmt.Sprintf("text_field_%s", encoded)
+}
+
 func (table redisOnlineIndex) Set(entity string, value interface{}) error {
-	vector, ok := value.([]float32)
-	if !ok {
-		return fmt.Errorf("value %v is not a vector", value)
+	if _, err := vectorValue(table.valueType, entity, value); err != nil {
+		return err
 	}
 	serializedKey, err := table.key.serialize(entity)
 	if err != nil {
@@ -363,7 +824,8 @@ func (table redisOnlineIndex) Set(entity string, value interface{}) error {
 		Hset().
 		Key(string(serializedKey)).
 		FieldValue().
-		FieldValue(table.key.getVectorField(), rueidis.VectorString32(vector)).
+		FieldValue(table.key.getVectorField(), table.vectorString(value)).
+		FieldValue(table.key.getNamespaceField(), "").
 		Build()
 	res := table.client.Do(context.TODO(), cmd)
 	if res.Error() != nil {
@@ -372,6 +834,53 @@ func (table redisOnlineIndex) Set(entity string, value interface{}) error {
 	return nil
 }
 
+// SetInNamespace sets the entity's vector and its namespace tag. Tags are
+// separated by commas, so namespaces can't contain them.
+func (table redisOnlineIndex) SetInNamespace(namespace, entity string, vector []float32) error {
+	if err := validateNamespace(namespace); err != nil {
+		return err
+	}
+	if strings.Contains(namespace, ",") {
+		return fmt.Errorf("redis vector namespace %q can't contain commas", namespace)
+	}
+	if err := checkVectorDimension(table.valueType, entity, vector); err != nil {
+		return err
+	}
+	serializedKey, err := table.key.serialize(entity)
+	if err != nil {
+		return err
+	}
+	cmd := table.client.B().
+		Hset().
+		Key(string(serializedKey)).
+		FieldValue().
+		FieldValue(table.key.getVectorField(), table.vectorString(vector)).
+		FieldValue(table.key.getNamespaceField(), namespace).
+		Build()
+	return table.client.Do(context.TODO(), cmd).Error()
+}
+
+// SetWithText sets the entity's vector along with the text HybridNearest
+// matches against.
+func (table redisOnlineIndex) SetWithText(entity string, vector []float32, text string) error {
+	if err := checkVectorDimension(table.valueType, entity, vector); err != nil {
+		return err
+	}
+	serializedKey, err := table.key.serialize(entity)
+	if err != nil {
+		return err
+	}
+	cmd := table.client.B().
+		Hset().
+		Key(string(serializedKey)).
+		FieldValue().
+		FieldValue(table.key.getVectorField(), table.vectorString(vector)).
+		FieldValue(table.key.getTextField(), text).
+		FieldValue(table.key.getNamespaceField(), "").
+		Build()
+	return table.client.Do(context.TODO(), cmd).Error()
+}
+
 func (table redisOnlineIndex) Get(entity string) (interface{}, error) {
 	serializedKey, err := table.key.serialize(entity)
 	if err != nil {
@@ -390,11 +899,155 @@ func (table redisOnlineIndex) Get(entity string) (interface{}, error) {
 	if err != nil {
 		return nil, err
 	}
-	return rueidis.ToVector32(val), nil
+	return decodeRedisVector(table.storage(), val), nil
+}
+
+// storage returns the element type of the index's vectors.
+func (table redisOnlineIndex) storage() ScalarType {
+	if vectorType, isVector := table.valueType.(VectorType); isVector {
+		return vectorType.Storage()
+	}
+	return Float32
+}
+
+// vectorString encodes a vector of float32s or float64s as the index's
+// storage type.
+func (table redisOnlineIndex) vectorString(value interface{}) string {
+	storage := table.storage()
+	if vector, isFloat64 := value.([]float64); isFloat64 && storage == Float64 {
+		return rueidis.VectorString64(vector)
+	}
+	vector, _ := float32Vector(value)
+	return encodeRedisVector(storage, vector)
+}
+
+// encodeRedisVector encodes a vector as the binary blob of storage's
+// element type that RediSearch indexes.
+func encodeRedisVector(storage ScalarType, vector []float32) string {
+	switch storage {
+	case Float16:
+		encoded := make([]byte, 2*len(vector))
+		for i, element := range vector {
+			binary.LittleEndian.PutUint16(encoded[2*i:], float32ToFloat16(element))
+		}
+		return string(encoded)
+	case Float64:
+		converted := make([]float64, len(vector))
+		for i, element := range vector {
+			converted[i] = float64(element)
+		}
+		return rueidis.VectorString64(converted)
+	default:
+		return rueidis.VectorString32(vector)
+	}
+}
+
+// decodeRedisVector reverts encodeRedisVector, returning float32s for every
+// storage type.
+func decodeRedisVector(storage ScalarType, encoded string) []float32 {
+	switch storage {
+	case Float16:
+		vector := make([]float32, len(encoded)/2)
+		for i := range vector {
+			vector[i] = float16ToFloat32(binary.LittleEndian.Uint16([]byte(encoded[2*i:])))
+		}
+		return vector
+	case Float64:
+		vector, _ := float32Vector(rueidis.ToVector64(encoded))
+		return vector
+	default:
+		return rueidis.ToVector32(encoded)
+	}
+}
+
+// Delete deletes the entity's hash, which removes it from the index.
+func (table redisOnlineIndex) Delete(entity string) error {
+	serializedKey, err := table.key.serialize(entity)
+	if err != nil {
+		return err
+	}
+	cmd := table.client.B().Del().Key(string(serializedKey)).Build()
+	return table.client.Do(context.TODO(), cmd).Error()
+}
+
+// IndexStats reads the index's document count, vector index size and
+// background indexing progress from FT.INFO.
+func (table redisOnlineIndex) IndexStats() (VectorIndexStats, error) {
+	serializedKey, err := table.key.serialize("")
+	if err != nil {
+		return VectorIndexStats{}, err
+	}
+	cmd := table.client.B().FtInfo().Index(string(serializedKey)).Build()
+	info, err := table.client.Do(context.TODO(), cmd).AsMap()
+	if err != nil {
+		return VectorIndexStats{}, err
+	}
+	stats := VectorIndexStats{
+		Vectors:         int64(redisInfoNumber(info, "num_docs")),
+		MemoryBytes:     int64(redisInfoNumber(info, "vector_index_sz_mb") * 1024 * 1024),
+		Building:        redisInfoNumber(info, "indexing") != 0,
+		IndexedFraction: redisInfoNumber(info, "percent_indexed"),
+	}
+	if vectorType, isVector := table.valueType.(VectorType); isVector {
+		stats.Dimension = vectorType.Dimension
+	}
+	return stats, nil
+}
+
+// redisInfoNumber returns a number from FT.INFO, which is an integer or a
+// string depending on the field and protocol version. Missing and
+// malformed fields are zero.
+func redisInfoNumber(info map[string]rueidis.RedisMessage, field string) float64 {
+	value, has := info[field]
+	if !has {
+		return 0
+	}
+	if value.IsInt64() {
+		number, _ := value.AsInt64()
+		return float64(number)
+	}
+	number, err := value.AsFloat64()
+	if err != nil {
+		return 0
+	}
+	return number
+}
+
+func (table redisOnlineIndex) Nearest(feature, variant string, vector []float32, k int32, filter VectorFilter) ([]NearestResult, error) {
+	return table.nearest(vector, 0, k, filter, "")
+}
+
+// HybridNearest runs a RediSearch hybrid query, which prefilters the index
+// by a full-text match on the entities' text before the KNN search.
+func (table redisOnlineIndex) HybridNearest(feature, variant string, vector []float32, k int32, query string) ([]NearestResult, error) {
+	prefilter, err := redisTextQuery(table.key.getTextField(), query)
+	if err != nil {
+		return nil, err
+	}
+	return table.nearest(vector, 0, k, VectorFilter{}, prefilter)
 }
 
-func (table redisOnlineIndex) Nearest(feature, variant string, vector []float32, k int32) ([]string, error) {
-	cmd, err := table.createNearestCmd(vector, k)
+func (table redisOnlineIndex) NearestPage(feature, variant string, vector []float32, pageSize int32, filter VectorFilter, cursor string) (NearestPage, error) {
+	search := func(offset, k int32) ([]NearestResult, error) {
+		return table.nearest(vector, offset, k, filter, "")
+	}
+	return nearestPage(search, pageSize, cursor)
+}
+
+// nearest returns the k entities matching prefilter and filter's namespace
+// after the nearest offset entities. Only namespaces are supported of the
+// VectorFilter's conditions.
+func (table redisOnlineIndex) nearest(vector []float32, offset, k int32, filter VectorFilter, prefilter string) ([]NearestResult, error) {
+	if len(filter.Attributes) > 0 || !filter.UpdatedAfter.IsZero() {
+		return nil, errVectorFiltersUnsupported(table)
+	}
+	if filter.Namespace != "" {
+		prefilter = strings.TrimSpace(prefilter + " " + redisTagQuery(table.key.getNamespaceField(), filter.Namespace))
+	}
+	if prefilter == "" {
+		prefilter = "*"
+	}
+	cmd, err := table.createNearestCmd(vector, offset, k, prefilter)
 	if err != nil {
 		return nil, err
 	}
@@ -402,19 +1055,76 @@ func (table redisOnlineIndex) Nearest(feature, variant string, vector []float32,
 	if err != nil {
 		return nil, err
 	}
-	entities := make([]string, len(docs))
+	metric := CosineDistance
+	if vectorType, isVector := table.valueType.(VectorType); isVector {
+		metric = vectorType.Metric()
+	}
+	scoreField := fmt.Sprintf("__%s_score", table.key.getVectorField())
+	results := make([]NearestResult, len(docs))
 	for idx, doc := range docs {
 		key := redisIndexKey{}
 		err := key.deserialize([]byte(doc.Key))
 		if err != nil {
 			return nil, err
 		}
-		entities[idx] = key.Entity
+		score, err := strconv.ParseFloat(doc.Doc[scoreField], 64)
+		if err != nil {
+			return nil, fmt.Errorf("could not parse score of %s: %w", key.Entity, err)
+		}
+		results[idx] = NearestResult{Entity: key.Entity, Distance: redisDistance(metric, score)}
+	}
+	return results, nil
+}
+
+// redisDistance converts a RediSearch vector score to a NearestResult
+// distance. RediSearch scores L2 by the squared distance and inner products
+// as one minus the product.
+func redisDistance(metric DistanceMetric, score float64) float32 {
+	switch metric {
+	case L2Distance:
+		return float32(math.Sqrt(score))
+	case InnerProductDistance:
+		return float32(score - 1)
+	default:
+		return float32(score)
+	}
+}
+
+// redisTextQuery builds a query matching text fields that contain every
+// term of query. Terms are escaped, so query syntax isn't interpreted.
+func redisTextQuery(field, query string) (string, error) {
+	terms := strings.Fields(query)
+	if len(terms) == 0 {
+		return "", fmt.Errorf("hybrid search query must contain at least one term")
+	}
+	for i, term := range terms {
+		terms[i] = escapeRedisQuery(term)
+	}
+	return fmt.Sprintf("(@%s:(%s))", field, strings.Join(terms, " ")), nil
+}
+
+// redisTagQuery builds a query matching tag fields with the tag.
+func redisTagQuery(field, tag string) string {
+	return fmt.Sprintf("(@%s:{%s})", field, escapeRedisQuery(tag))
+}
+
+// escapeRedisQuery escapes the punctuation and whitespace of a query term,
+// which RediSearch would otherwise tokenize or parse as syntax.
+func escapeRedisQuery(term string) string {
+	var escaped strings.Builder
+	for _, r := range term {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '_' {
+			escaped.WriteRune('\\')
+		}
+		escaped.WriteRune(r)
 	}
-	return entities, nil
+	return escaped.String()
 }
 
-func (table redisOnlineIndex) createNearestCmd(vector []float32, k int32) (rueidis.Completed, error) {
+// createNearestCmd searches the entities matching prefilter for the nearest
+// offset+k vectors and returns the last k of them. Without LIMIT,
+// RediSearch returns at most 10.
+func (table redisOnlineIndex) createNearestCmd(vector []float32, offset, k int32, prefilter string) (rueidis.Completed, error) {
 	vectorField := table.key.getVectorField()
 	serializedKey, err := table.key.serialize("")
 	if err != nil {
@@ -423,13 +1133,15 @@ func (table redisOnlineIndex) createNearestCmd(vector []float32, k int32) (rueid
 	return table.client.B().
 		FtSearch().
 		Index(string(serializedKey)).
-		Query(fmt.Sprintf("*=>[KNN $K @%s $BLOB]", vectorField)).
+		Query(fmt.Sprintf("%s=>[KNN $K @%s $BLOB]", prefilter, vectorField)).
 		Sortby(fmt.Sprintf("__%s_score", vectorField)).
+		Limit().
+		OffsetNum(int64(offset), int64(k)).
 		Params().
 		Nargs(4).
 		NameValue().
-		NameValue("K", strconv.Itoa(int(k))).
-		NameValue("BLOB", rueidis.VectorString32(vector)).
+		NameValue("K", strconv.Itoa(int(offset+k))).
+		NameValue("BLOB", encodeRedisVector(table.storage(), vector)).
 		Dialect(2).
 		Build(), nil
 }
@@ -251,3 +251,41 @@ func instantiateMockRedisClient(addr string) (rueidis.Client, error) {
 		},
 	)
 }
+
+func TestRedisDistance(t *testing.T) {
+	if distance := redisDistance(L2Distance, 9); distance != 3 {
+		t.Fatalf("Expected squared L2 score to be converted, got %v", distance)
+	}
+	if distance := redisDistance(InnerProductDistance, -1); distance != -2 {
+		t.Fatalf("Expected negative inner product, got %v", distance)
+	}
+	if distance := redisDistance(CosineDistance, 0.25); distance != 0.25 {
+		t.Fatalf("Expected cosine score to be unchanged, got %v", distance)
+	}
+}
+
+func TestRedisTextQuery(t *testing.T) {
+	query, err := redisTextQuery("text_field", "vector  search-engine")
+	if err != nil {
+		t.Fatalf("Failed to build query: %s", err)
+	}
+	if expected := `(@text_field:(vector search\-engine))`; query != expected {
+		t.Fatalf("Expected %s, got %s", expected, query)
+	}
+	if _, err := redisTextQuery("text_field", "  "); err == nil {
+		t.Fatalf("Succeeded with an empty query")
+	}
+}
+
+func TestRedisVectorEncoding(t *testing.T) {
+	vector := []float32{0.5, -2, 1024}
+	for _, storage := range []ScalarType{Float16, Float32, Float64} {
+		encoded := encodeRedisVector(storage, vector)
+		if decoded := decodeRedisVector(storage, encoded); !reflect.DeepEqual(decoded, vector) {
+			t.Fatalf("Expected %s vector to round trip, got %v", storage, decoded)
+		}
+	}
+	if encoded := encodeRedisVector(Float16, vector); len(encoded) != 2*len(vector) {
+		t.Fatalf("Expected two bytes per half precision element, got %d", len(encoded))
+	}
+}
@@ -32,11 +32,18 @@ func redshiftOfflineStoreFactory(config pc.SerializedConfig) (Provider, error) {
 	queries.setVariableBinding(PostgresBindingStyle)
 	sgConfig := SQLOfflineStoreConfig{
 		Config:        config,
-		ConnectionURL: fmt.Sprintf("sslmode=require user=%v password=%s host=%v port=%v dbname=%v", sc.Username, sc.Password, sc.Endpoint, sc.Port, sc.Database),
+		ConnectionURL: redshiftConnectionURL(sc, sc.Username, sc.Password),
 		Driver:        "postgres",
 		ProviderType:  pt.RedshiftOffline,
 		QueryImpl:     &queries,
 	}
+	if sc.UsesIAM() {
+		connector, err := newRedshiftIAMConnector(sc)
+		if err != nil {
+			return nil, err
+		}
+		sgConfig.Connector = connector
+	}
 
 	store, err := NewSQLOfflineStore(sgConfig)
 	if err != nil {
@@ -60,8 +67,12 @@ func (q redshiftSQLQueries) viewExists() string {
 func (q redshiftSQLQueries) registerResources(db *sql.DB, tableName string, schema ResourceSchema, timestamp bool) error {
 	var query string
 	if timestamp {
+		ts := sanitize(schema.TS)
+		if schema.TSTimezone != "" {
+			ts = fmt.Sprintf("CONVERT_TIMEZONE('%s', 'UTC', %s)", schema.TSTimezone, ts)
+		}
 		query = fmt.Sprintf("CREATE VIEW %s AS SELECT %s as entity, %s as value, %s as ts FROM %s", sanitize(tableName),
-			sanitize(schema.Entity), sanitize(schema.Value), sanitize(schema.TS), sanitize(schema.SourceTable))
+			sanitize(schema.Entity), sanitize(schema.Value), ts, sanitize(schema.SourceTable))
 	} else {
 		query = fmt.Sprintf("CREATE VIEW %s AS SELECT %s as entity, %s as value, to_timestamp('%s', 'YYYY-DD-MM HH24:MI:SS +0000 UTC')::TIMESTAMPTZ as ts FROM %s", sanitize(tableName),
 			sanitize(schema.Entity), sanitize(schema.Value), time.UnixMilli(0).UTC(), sanitize(schema.SourceTable))
@@ -86,6 +97,10 @@ func (q redshiftSQLQueries) materializationCreate(tableName string, resultName s
 	return query
 }
 
+func (q redshiftSQLQueries) materializationApproxNumRows() string {
+	return "SELECT CAST(estimated_visible_rows AS BIGINT) FROM svv_table_info WHERE \"table\" = $1"
+}
+
 func (q redshiftSQLQueries) materializationUpdate(db *sql.DB, tableName string, sourceName string) error {
 	sanitizedTable := sanitize(tableName)
 	tempTable := sanitize(fmt.Sprintf("tmp_%s", tableName))
@@ -105,6 +120,10 @@ func (q redshiftSQLQueries) materializationUpdate(db *sql.DB, tableName string,
 	return err
 }
 
+func (q redshiftSQLQueries) materializationIncrementalUpdate(tableName, changedTableName, sourceName string) []string {
+	return q.incrementalMaterializationStatements(tableName, changedTableName, sourceName)
+}
+
 func (q redshiftSQLQueries) materializationDrop(tableName string) string {
 	return fmt.Sprintf("DROP TABLE %s", sanitize(tableName))
 }
@@ -0,0 +1,133 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package provider
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	awsv2cfg "github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/credentials"
+	"github.com/aws/aws-sdk-go-v2/service/redshift"
+	"github.com/aws/aws-sdk-go-v2/service/redshiftserverless"
+	pc "github.com/featureform/provider/provider_config"
+	"github.com/lib/pq"
+)
+
+// redshiftCredentialsRefreshWindow is how long before they expire that
+// temporary credentials are replaced, so connections aren't opened with
+// credentials that expire while they authenticate.
+const redshiftCredentialsRefreshWindow = time.Minute
+
+type redshiftCredentials struct {
+	User       string
+	Password   string
+	Expiration time.Time
+}
+
+// redshiftConnectionURL quotes the user and password, since temporary
+// passwords can have characters that otherwise end the value.
+func redshiftConnectionURL(sc pc.RedshiftConfig, user, password string) string {
+	quote := strings.NewReplacer(`\`, `\\`, `'`, `\'`)
+	return fmt.Sprintf("sslmode=require user='%s' password='%s' host=%v port=%v dbname=%v", quote.Replace(user), quote.Replace(password), sc.Endpoint, sc.Port, sc.Database)
+}
+
+// redshiftIAMConnector opens connections with temporary credentials from IAM,
+// which are fetched again once they're about to expire. Open connections
+// stay authenticated after the credentials they used expire.
+type redshiftIAMConnector struct {
+	config pc.RedshiftConfig
+	fetch  func(ctx context.Context) (redshiftCredentials, error)
+	now    func() time.Time
+	creds  redshiftCredentials
+	mu     sync.Mutex
+}
+
+func newRedshiftIAMConnector(sc pc.RedshiftConfig) (*redshiftIAMConnector, error) {
+	if sc.Region == "" {
+		return nil, errors.New("redshift IAM authentication requires a region")
+	}
+	if sc.ClusterIdentifier != "" && sc.Workgroup != "" {
+		return nil, errors.New("redshift config can't have both a cluster identifier and a workgroup")
+	}
+	if sc.ClusterIdentifier != "" && sc.Username == "" {
+		return nil, errors.New("redshift IAM authentication to a cluster requires a username")
+	}
+	opts := []func(*awsv2cfg.LoadOptions) error{awsv2cfg.WithRegion(sc.Region)}
+	if sc.Credentials.AWSAccessKeyId != "" {
+		opts = append(opts, awsv2cfg.WithCredentialsProvider(credentials.NewStaticCredentialsProvider(sc.Credentials.AWSAccessKeyId, sc.Credentials.AWSSecretKey, "")))
+	}
+	awsConfig, err := awsv2cfg.LoadDefaultConfig(context.TODO(), opts...)
+	if err != nil {
+		return nil, fmt.Errorf("could not load aws config: %v", err)
+	}
+	connector := &redshiftIAMConnector{
+		config: sc,
+		now:    time.Now,
+	}
+	if sc.Workgroup != "" {
+		client := redshiftserverless.NewFromConfig(awsConfig)
+		connector.fetch = func(ctx context.Context) (redshiftCredentials, error) {
+			out, err := client.GetCredentials(ctx, &redshiftserverless.GetCredentialsInput{
+				WorkgroupName: aws.String(sc.Workgroup),
+				DbName:        aws.String(sc.Database),
+			})
+			if err != nil {
+				return redshiftCredentials{}, err
+			}
+			return redshiftCredentials{User: aws.ToString(out.DbUser), Password: aws.ToString(out.DbPassword), Expiration: aws.ToTime(out.Expiration)}, nil
+		}
+	} else {
+		client := redshift.NewFromConfig(awsConfig)
+		connector.fetch = func(ctx context.Context) (redshiftCredentials, error) {
+			out, err := client.GetClusterCredentials(ctx, &redshift.GetClusterCredentialsInput{
+				ClusterIdentifier: aws.String(sc.ClusterIdentifier),
+				DbUser:            aws.String(sc.Username),
+				DbName:            aws.String(sc.Database),
+			})
+			if err != nil {
+				return redshiftCredentials{}, err
+			}
+			return redshiftCredentials{User: aws.ToString(out.DbUser), Password: aws.ToString(out.DbPassword), Expiration: aws.ToTime(out.Expiration)}, nil
+		}
+	}
+	return connector, nil
+}
+
+func (c *redshiftIAMConnector) Connect(ctx context.Context) (driver.Conn, error) {
+	creds, err := c.credentials(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("could not get redshift credentials: %w", err)
+	}
+	connector, err := pq.NewConnector(redshiftConnectionURL(c.config, creds.User, creds.Password))
+	if err != nil {
+		return nil, err
+	}
+	return connector.Connect(ctx)
+}
+
+func (c *redshiftIAMConnector) Driver() driver.Driver {
+	return &pq.Driver{}
+}
+
+func (c *redshiftIAMConnector) credentials(ctx context.Context) (redshiftCredentials, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.creds.Password != "" && c.now().Add(redshiftCredentialsRefreshWindow).Before(c.creds.Expiration) {
+		return c.creds, nil
+	}
+	creds, err := c.fetch(ctx)
+	if err != nil {
+		return redshiftCredentials{}, err
+	}
+	c.creds = creds
+	return creds, nil
+}
@@ -0,0 +1,61 @@
+package provider
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	pc "github.com/featureform/provider/provider_config"
+)
+
+func TestRedshiftConnectionURL(t *testing.T) {
+	sc := pc.RedshiftConfig{Endpoint: "host", Port: "5439", Database: "dev"}
+	expected := `sslmode=require user='IAM:admin' password='a b\'c\\d' host=host port=5439 dbname=dev`
+	if url := redshiftConnectionURL(sc, "IAM:admin", `a b'c\d`); url != expected {
+		t.Fatalf("Expected %s, got %s", expected, url)
+	}
+}
+
+func TestNewRedshiftIAMConnectorValidation(t *testing.T) {
+	invalid := []pc.RedshiftConfig{
+		{ClusterIdentifier: "cluster", Username: "admin"},
+		{ClusterIdentifier: "cluster", Workgroup: "workgroup", Region: "us-east-1"},
+		{ClusterIdentifier: "cluster", Region: "us-east-1"},
+	}
+	for _, sc := range invalid {
+		if _, err := newRedshiftIAMConnector(sc); err == nil {
+			t.Fatalf("Expected invalid IAM config to fail: %+v", sc)
+		}
+	}
+	sc := pc.RedshiftConfig{Workgroup: "workgroup", Region: "us-east-1"}
+	if _, err := newRedshiftIAMConnector(sc); err != nil {
+		t.Fatalf("Failed to create connector: %s", err)
+	}
+}
+
+func TestRedshiftIAMConnectorRefreshesCredentials(t *testing.T) {
+	now := time.Now()
+	fetches := 0
+	connector := &redshiftIAMConnector{
+		now: func() time.Time { return now },
+		fetch: func(context.Context) (redshiftCredentials, error) {
+			fetches++
+			return redshiftCredentials{User: "IAM:admin", Password: "password", Expiration: now.Add(15 * time.Minute)}, nil
+		},
+	}
+	for i := 0; i < 2; i++ {
+		if _, err := connector.credentials(context.Background()); err != nil {
+			t.Fatalf("Failed to get credentials: %s", err)
+		}
+	}
+	if fetches != 1 {
+		t.Fatalf("Expected credentials to be reused until they expire, fetched %d times", fetches)
+	}
+	now = now.Add(14*time.Minute + 30*time.Second)
+	if _, err := connector.credentials(context.Background()); err != nil {
+		t.Fatalf("Failed to get credentials: %s", err)
+	}
+	if fetches != 2 {
+		t.Fatalf("Expected credentials about to expire to be fetched again, fetched %d times", fetches)
+	}
+}
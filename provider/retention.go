@@ -0,0 +1,102 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package provider
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	pc "github.com/featureform/provider/provider_config"
+)
+
+// materializationSnapshot is the output of one run of a file-based
+// materialization: a directory of Parquet files written by Spark, or a single
+// Parquet file written by the K8s runner.
+type materializationSnapshot struct {
+	// Key is the snapshot's directory, with a trailing slash, or its file.
+	Key     string
+	ModTime time.Time
+}
+
+// materializationSnapshots returns the snapshots of a materialization, newest
+// first.
+func materializationSnapshots(store FileStore, id ResourceID) ([]materializationSnapshot, error) {
+	prefix := strings.TrimSuffix(store.PathWithPrefix(fileStoreResourcePath(id), false), "/") + "/"
+	files, err := store.ListFiles(prefix)
+	if err != nil {
+		return nil, fmt.Errorf("could not list snapshots of materialization %s: %w", id.Name, err)
+	}
+	byKey := make(map[string]*materializationSnapshot)
+	for _, file := range files {
+		name, _, isDir := strings.Cut(strings.TrimPrefix(file.Key, prefix), "/")
+		if !isDir && !Parquet.Matches(name) {
+			continue
+		}
+		key := prefix + name
+		if isDir {
+			key += "/"
+		}
+		snapshot, has := byKey[key]
+		if !has {
+			snapshot = &materializationSnapshot{Key: key}
+			byKey[key] = snapshot
+		}
+		if file.ModTime.After(snapshot.ModTime) {
+			snapshot.ModTime = file.ModTime
+		}
+	}
+	snapshots := make([]materializationSnapshot, 0, len(byKey))
+	for _, snapshot := range byKey {
+		snapshots = append(snapshots, *snapshot)
+	}
+	sort.Slice(snapshots, func(i, j int) bool {
+		return snapshots[i].ModTime.After(snapshots[j].ModTime)
+	})
+	return snapshots, nil
+}
+
+// expiredSnapshots returns the snapshots, sorted newest first, that the
+// retention config doesn't keep as of now. The newest is never expired.
+func expiredSnapshots(snapshots []materializationSnapshot, retention pc.RetentionConfig, now time.Time) []materializationSnapshot {
+	expired := make([]materializationSnapshot, 0)
+	for i, snapshot := range snapshots {
+		if i == 0 {
+			continue
+		}
+		tooMany := retention.MaxVersions > 0 && i >= retention.MaxVersions
+		tooOld := retention.MaxAgeDays > 0 && now.Sub(snapshot.ModTime) > retention.MaxAge()
+		if tooMany || tooOld {
+			expired = append(expired, snapshot)
+		}
+	}
+	return expired
+}
+
+// applyMaterializationRetention deletes the snapshots of a materialization
+// that the retention config doesn't keep, and returns how many were deleted.
+func applyMaterializationRetention(store FileStore, id ResourceID, retention pc.RetentionConfig) (int, error) {
+	if retention.IsZero() {
+		return 0, nil
+	}
+	snapshots, err := materializationSnapshots(store, id)
+	if err != nil {
+		return 0, err
+	}
+	expired := expiredSnapshots(snapshots, retention, time.Now())
+	for i, snapshot := range expired {
+		var err error
+		if strings.HasSuffix(snapshot.Key, "/") {
+			err = store.DeleteAll(snapshot.Key)
+		} else {
+			err = store.Delete(snapshot.Key)
+		}
+		if err != nil {
+			return i, fmt.Errorf("could not delete materialization snapshot %s: %w", snapshot.Key, err)
+		}
+	}
+	return len(expired), nil
+}
@@ -0,0 +1,90 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package provider
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	pc "github.com/featureform/provider/provider_config"
+)
+
+func TestExpiredSnapshots(t *testing.T) {
+	now := time.Date(2023, 6, 10, 0, 0, 0, 0, time.UTC)
+	snapshots := make([]materializationSnapshot, 4)
+	for i := range snapshots {
+		snapshots[i] = materializationSnapshot{Key: fmt.Sprintf("%d/", i), ModTime: now.AddDate(0, 0, -3*i)}
+	}
+	tests := []struct {
+		name      string
+		retention pc.RetentionConfig
+		expected  []string
+	}{
+		{"No Limits", pc.RetentionConfig{}, []string{}},
+		{"Max Versions", pc.RetentionConfig{MaxVersions: 2}, []string{"2/", "3/"}},
+		{"Max Age", pc.RetentionConfig{MaxAgeDays: 5}, []string{"2/", "3/"}},
+		{"Either Limit", pc.RetentionConfig{MaxVersions: 3, MaxAgeDays: 7}, []string{"2/", "3/"}},
+		{"Newest Is Kept", pc.RetentionConfig{MaxVersions: 1, MaxAgeDays: 1}, []string{"1/", "2/", "3/"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			expired := expiredSnapshots(snapshots, tt.retention, now.AddDate(0, 0, 2))
+			keys := make([]string, len(expired))
+			for i, snapshot := range expired {
+				keys[i] = snapshot.Key
+			}
+			if !reflect.DeepEqual(keys, tt.expected) {
+				t.Fatalf("Expected %v, got %v", tt.expected, keys)
+			}
+		})
+	}
+}
+
+func TestApplyMaterializationRetention(t *testing.T) {
+	dir := t.TempDir()
+	config := pc.LocalFileStoreConfig{DirPath: fmt.Sprintf("file://%s/", dir)}
+	serialized, err := config.Serialize()
+	if err != nil {
+		t.Fatalf("Failed to serialize file store config: %s", err)
+	}
+	store, err := NewLocalFileStore(serialized)
+	if err != nil {
+		t.Fatalf("Failed to create file store: %s", err)
+	}
+	id := ResourceID{Name: "feature", Variant: "variant", Type: FeatureMaterialization}
+	prefix := store.PathWithPrefix(fileStoreResourcePath(id), false)
+	// Spark writes a directory of part files, and the K8s runner a file.
+	snapshots := []string{"2023-06-03-00-00-00-000000/part-0.parquet", "2023-06-01.parquet", "2023-06-02-00-00-00-000000/part-0.parquet"}
+	for i, snapshot := range snapshots {
+		key := fmt.Sprintf("%s/%s", prefix, snapshot)
+		if err := store.Write(key, []byte("data")); err != nil {
+			t.Fatalf("Failed to write %s: %s", key, err)
+		}
+		modTime := time.Now().Add(-time.Duration(i) * time.Hour)
+		if err := os.Chtimes(filepath.Join(dir, key), modTime, modTime); err != nil {
+			t.Fatalf("Failed to set time of %s: %s", key, err)
+		}
+	}
+	deleted, err := applyMaterializationRetention(store, id, pc.RetentionConfig{MaxVersions: 1})
+	if err != nil {
+		t.Fatalf("Failed to apply retention: %s", err)
+	}
+	if deleted != 2 {
+		t.Fatalf("Expected 2 deleted snapshots, got %d", deleted)
+	}
+	for i, snapshot := range snapshots {
+		exists, err := store.Exists(fmt.Sprintf("%s/%s", prefix, snapshot))
+		if err != nil {
+			t.Fatalf("Failed to check %s: %s", snapshot, err)
+		}
+		if exists != (i == 0) {
+			t.Fatalf("Expected %s to exist: %v", snapshot, i == 0)
+		}
+	}
+}
@@ -0,0 +1,89 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package provider
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+
+	pc "github.com/featureform/provider/provider_config"
+)
+
+func TestS3FileStoreCustomEndpoint(t *testing.T) {
+	var written string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodPut {
+			written = r.URL.Path
+		}
+	}))
+	defer server.Close()
+	config := pc.S3FileStoreConfig{
+		Credentials:  pc.AWSCredentials{AWSAccessKeyId: "key", AWSSecretKey: "secret"},
+		BucketRegion: "us-east-1",
+		BucketPath:   "lake",
+		Endpoint:     server.URL,
+		PathStyle:    true,
+	}
+	serialized, err := config.Serialize()
+	if err != nil {
+		t.Fatalf("Failed to serialize config: %s", err)
+	}
+	store, err := NewS3FileStore(serialized)
+	if err != nil {
+		t.Fatalf("Failed to create store: %s", err)
+	}
+	if err := store.Write("features/file.csv", []byte("data")); err != nil {
+		t.Fatalf("Failed to write: %s", err)
+	}
+	if written != "/lake/features/file.csv" {
+		t.Fatalf("Expected a path style write to the endpoint, got %s", written)
+	}
+}
+
+func TestSparkS3FileStoreAssumedRole(t *testing.T) {
+	store := SparkS3FileStore{
+		S3FileStore: &S3FileStore{
+			Credentials: pc.AWSCredentials{AWSAccessKeyId: "key", AWSSecretKey: "secret"},
+			Bucket:      "lake",
+			RoleARN:     "arn:aws:iam::123456789012:role/lake",
+			AccessPoint: "arn:aws:s3:us-east-1:123456789012:accesspoint/lake",
+			Endpoint:    "https://s3.us-east-1.amazonaws.com",
+		},
+		session: aws.Credentials{AccessKeyID: "temp-key", SecretAccessKey: "temp-secret", SessionToken: "token"},
+	}
+	config := strings.Join(store.SparkConfig(), " ")
+	for _, expected := range []string{
+		"fs.s3a.aws.credentials.provider=" + s3aAssumedRoleProvider,
+		"fs.s3a.assumed.role.arn=arn:aws:iam::123456789012:role/lake",
+		"fs.s3a.assumed.role.credentials.provider=" + s3aSimpleCredentialsProvider,
+		"fs.s3a.access.key=key",
+		"fs.s3a.endpoint=https://s3.us-east-1.amazonaws.com",
+		"fs.s3a.bucket.lake.accesspoint.arn=arn:aws:s3:us-east-1:123456789012:accesspoint/lake",
+	} {
+		if !strings.Contains(config, expected) {
+			t.Errorf("Expected spark config to contain %s, got %s", expected, config)
+		}
+	}
+	credentials := strings.Join(store.CredentialsConfig(), " ")
+	for _, expected := range []string{
+		"aws_bucket_name=arn:aws:s3:us-east-1:123456789012:accesspoint/lake",
+		"aws_access_key_id=temp-key",
+		"aws_session_token=token",
+	} {
+		if !strings.Contains(credentials, expected) {
+			t.Errorf("Expected credentials to contain %s, got %s", expected, credentials)
+		}
+	}
+
+	store.ExternalID = "external"
+	config = strings.Join(store.SparkConfig(), " ")
+	if !strings.Contains(config, "fs.s3a.session.token=token") || strings.Contains(config, s3aAssumedRoleProvider) {
+		t.Fatalf("Expected roles with an external id to use temporary credentials, got %s", config)
+	}
+}
@@ -9,7 +9,10 @@ from argparse import Namespace
 import dill
 
 import boto3
+import fastavro
 import pandas as pd
+import pyarrow as pa
+import pyarrow.parquet as pq
 from pandasql import sqldf
 from azure.storage.blob import BlobServiceClient
 
@@ -63,11 +66,7 @@ class BlobStore:
 
         full_path = f"{LOCAL_DATA_PATH}/{file_path}"
 
-        if (
-            blob_path.endswith(".csv")
-            or blob_path.endswith(".parquet")
-            or blob_path.endswith(".pkl")
-        ):
+        if blob_path.endswith((".csv", ".parquet", ".pkl", ".orc", ".avro")):
             response = self.download_file(blob_path, full_path)
         else:
             print("downloading directory...")
@@ -262,15 +261,9 @@ def execute_sql_job(mode, output_uri, transformation, source_list, blob_store):
                 output_path = source
             else:
                 # download blob to local & set source to local path
-                local_file = (
-                    f"source_{i}.csv" if source.endswith(".csv") else f"source_{i}"
-                )
-                output_path = blob_store.download(source, local_file)
+                output_path = blob_store.download(source, local_source_file(i, source))
 
-            if output_path.endswith(".csv"):
-                globals()[f"source_{i}"] = pd.read_csv(output_path)
-            else:
-                globals()[f"source_{i}"] = pd.read_parquet(output_path)
+            globals()[f"source_{i}"] = read_source(output_path)
 
         pysqldf = lambda q: sqldf(q, globals())
         transformation_df = pysqldf(transformation)
@@ -316,16 +309,13 @@ def execute_df_job(mode, output_uri, code, sources, blob_store):
             source_path = source
         else:
             # download blob to local & set source to local path
-            local_file = f"source_{i}.csv" if source.endswith(".csv") else f"source_{i}"
+            local_file = local_source_file(i, source)
 
             print(f"downloading {source} to {local_file}")
             source_path = blob_store.download(source, local_file)
 
         print(f"reading '{source}' source file into dataframe")
-        if source_path.endswith(".csv"):
-            func_parameters.append(pd.read_csv(source_path))
-        else:
-            func_parameters.append(pd.read_parquet(source_path))
+        func_parameters.append(read_source(source_path))
 
     try:
         df_path = "transformation.pkl"
@@ -409,6 +399,133 @@ def column_is_bool(df: pd.DataFrame, column: str):
     return True
 
 
+# Source files are read by their extension, and are Parquet otherwise.
+SOURCE_FILE_EXTENSIONS = (".csv", ".orc", ".avro")
+
+
+def local_source_file(i, source):
+    # The name that a source is downloaded to, which keeps its extension if
+    # it's read by it.
+    extension = os.path.splitext(source)[1].lower()
+    if extension in SOURCE_FILE_EXTENSIONS:
+        return f"source_{i}{extension}"
+    return f"source_{i}"
+
+
+def read_source(path):
+    # Reads a CSV, ORC or Avro file, or a Parquet file or directory.
+
+    # Parameters:
+    #     path: string (local path)
+    # Return:
+    #     df: pandas.DataFrame
+
+    if path.endswith(".csv"):
+        return pd.read_csv(path)
+    elif path.endswith(".orc"):
+        return pd.read_orc(path)
+    elif path.endswith(".avro"):
+        with open(path, "rb") as f:
+            return pd.DataFrame.from_records(list(fastavro.reader(f)))
+    return read_parquet(path)
+
+
+def read_parquet(path):
+    # Reads a Parquet file, or a directory of them whose schemas may have
+    # evolved, such as one with a file a day where later files have more
+    # columns or wider types. Each file is cast to the schema that
+    # merge_schemas merges their schemas to before they're concatenated.
+
+    # Parameters:
+    #     path: string (path to a file or directory)
+    # Return:
+    #     df: pandas.DataFrame
+
+    if not os.path.isdir(path):
+        return pd.read_parquet(path)
+    # like pandas, files that start with "_" or "." such as _SUCCESS are skipped
+    files = sorted(
+        os.path.join(root, name)
+        for root, _, names in os.walk(path)
+        for name in names
+        if not name.startswith(("_", "."))
+    )
+    tables = [pq.read_table(file) for file in files]
+    schema = merge_schemas([table.schema for table in tables])
+    conformed = []
+    for table in tables:
+        conformed.append(
+            pa.table(
+                [
+                    table.column(field.name).cast(field.type)
+                    if field.name in table.column_names
+                    else pa.nulls(table.num_rows, field.type)
+                    for field in schema
+                ],
+                schema=schema,
+            )
+        )
+    return pa.concat_tables(conformed).to_pandas()
+
+
+def merge_schemas(schemas):
+    # Merges the schemas of a directory's files. Fields are in the order they
+    # first appear, and have the type that merge_types merges their types in
+    # each file to. Files without a field have nulls for it.
+
+    # Parameters:
+    #     schemas: List[pyarrow.Schema]
+    # Return:
+    #     schema: pyarrow.Schema
+
+    column_types = {}
+    for schema in schemas:
+        for field in schema:
+            if field.name in column_types:
+                column_types[field.name] = merge_types(
+                    field.name, column_types[field.name], field.type
+                )
+            else:
+                column_types[field.name] = field.type
+    return pa.schema(list(column_types.items()))
+
+
+# Timestamp units from coarsest to finest.
+TIMESTAMP_UNITS = ["s", "ms", "us", "ns"]
+
+
+def merge_types(name, left, right):
+    # The rules a column's type is merged by when files disagree on it:
+    #   - integers widen to the wider integer
+    #   - floats widen to double, as do integers mixed with floats
+    #   - dates widen to timestamps, and timestamps in the same time zone
+    #     widen to the finer unit
+    # Any other difference is an error, rather than values being cast to a
+    # type that could lose or change them.
+
+    if left == right:
+        return left
+    if (
+        pa.types.is_timestamp(left)
+        and pa.types.is_timestamp(right)
+        and left.tz == right.tz
+    ):
+        return max(left, right, key=lambda t: TIMESTAMP_UNITS.index(t.unit))
+    if pa.types.is_signed_integer(left) and pa.types.is_signed_integer(right):
+        return max(left, right, key=lambda t: t.bit_width)
+    numeric = [pa.types.is_signed_integer, pa.types.is_floating]
+    if any(f(left) for f in numeric) and any(f(right) for f in numeric):
+        return pa.float64()
+    if pa.types.is_date(left) and pa.types.is_timestamp(right):
+        return right
+    if pa.types.is_timestamp(left) and pa.types.is_date(right):
+        return left
+    raise ValueError(
+        f"column '{name}' has incompatible types in the source's files: "
+        f"{left} and {right}"
+    )
+
+
 def set_bool_columns(df: pd.DataFrame):
     for col in df.columns:
         if column_is_bool(df, col):
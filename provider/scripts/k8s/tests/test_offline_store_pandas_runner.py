@@ -6,6 +6,7 @@ from argparse import Namespace
 sys.path.insert(0, "provider/scripts/k8s")
 
 import pandas
+import pyarrow
 import pytest
 from dotenv import load_dotenv
 
@@ -17,6 +18,8 @@ from offline_store_pandas_runner import (
     execute_df_job,
     execute_sql_job,
     get_blob_credentials,
+    merge_types,
+    read_parquet,
 )
 
 real_path = os.path.realpath(__file__)
@@ -235,3 +238,36 @@ def load_env_file():
     )
     env_file = os.path.join(env_directory, ".env")
     load_dotenv(env_file)
+
+
+def test_read_parquet_evolved_schema(tmp_path):
+    pandas.DataFrame({"id": pandas.Series([1], dtype="int32")}).to_parquet(
+        tmp_path / "day-1.parquet"
+    )
+    pandas.DataFrame(
+        {"id": pandas.Series([2**40], dtype="int64"), "amount": [1.5]}
+    ).to_parquet(tmp_path / "day-2.parquet")
+    (tmp_path / "_SUCCESS").touch()
+
+    df = read_parquet(str(tmp_path))
+
+    assert list(df["id"]) == [1, 2**40]
+    assert df["amount"].isna().tolist() == [True, False]
+
+
+@pytest.mark.parametrize(
+    "left,right,expected",
+    [
+        (pyarrow.int32(), pyarrow.int64(), pyarrow.int64()),
+        (pyarrow.int64(), pyarrow.float32(), pyarrow.float64()),
+        (pyarrow.date32(), pyarrow.timestamp("us"), pyarrow.timestamp("us")),
+        (pyarrow.timestamp("us"), pyarrow.timestamp("ns"), pyarrow.timestamp("ns")),
+    ],
+)
+def test_merge_types(left, right, expected):
+    assert merge_types("column", left, right) == expected
+
+
+def test_merge_types_incompatible():
+    with pytest.raises(ValueError, match="'column'"):
+        merge_types("column", pyarrow.int64(), pyarrow.string())
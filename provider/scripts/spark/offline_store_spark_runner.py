@@ -1,24 +1,61 @@
 import io
 import os
 import json
+import time
 import uuid
 import types
 import base64
 import argparse
 from typing import List
 from pathlib import Path
+from decimal import Decimal
 from datetime import datetime
+from urllib.request import Request, urlopen
 
 
 import dill
 import boto3
 from google.cloud import storage
 from pyspark.sql import SparkSession
+from pyspark.sql.functions import col, expr, from_json, lit
+from pyspark.sql.types import (
+    ByteType,
+    DateType,
+    DoubleType,
+    FloatType,
+    IntegerType,
+    LongType,
+    ShortType,
+    StructField,
+    StructType,
+    TimestampType,
+)
 from google.oauth2 import service_account
 from azure.storage.blob import BlobServiceClient
 
 
 FILESTORES = ["local", "s3", "azure_blob_store", "google_cloud_storage", "hdfs"]
+TABLE_FORMATS = ["parquet", "iceberg", "delta"]
+WRITE_MODES = ["create", "overwrite"]
+# versioned sources are read at a version: "{format}:{table}@{version}"
+VERSIONED_SOURCE_FORMATS = ["iceberg", "delta"]
+# the option that each format reads a version of a table with
+VERSION_READ_OPTIONS = {"iceberg": "snapshot-id", "delta": "versionAsOf"}
+# catalog sources are read by their name in the metastore: "catalog:{db}.{table}"
+CATALOG_SOURCE_PREFIX = "catalog:"
+# file sources in these formats are files with the format's extension, or
+# directories of them in the form "{format}:{path}". Avro needs the spark-avro
+# package on the cluster.
+FILE_SOURCE_FORMATS = ["orc", "avro"]
+
+# Kafka sources are "kafka:" followed by their base64 encoded JSON spec, which
+# is only read by streaming transformations.
+KAFKA_SOURCE_PREFIX = "kafka:"
+KAFKA_LOGIN_MODULES = {
+    "PLAIN": "org.apache.kafka.common.security.plain.PlainLoginModule",
+    "SCRAM-SHA-256": "org.apache.kafka.common.security.scram.ScramLoginModule",
+    "SCRAM-SHA-512": "org.apache.kafka.common.security.scram.ScramLoginModule",
+}
 
 if os.getenv("FEATUREFORM_LOCAL_MODE"):
     real_path = os.path.realpath(__file__)
@@ -43,6 +80,10 @@ def main(args):
                 args.sql_query,
                 args.spark_config,
                 args.source_list,
+                args.table_format,
+                args.iceberg_table,
+                args.write_mode,
+                args.catalog_config,
             )
         elif args.transformation_type == "df":
             output_location = execute_df_job(
@@ -52,6 +93,20 @@ def main(args):
                 args.spark_config,
                 args.credential,
                 args.source,
+                args.table_format,
+                args.iceberg_table,
+                args.write_mode,
+                args.catalog_config,
+            )
+        elif args.transformation_type == "stream":
+            output_location = execute_streaming_query(
+                args.output_uri,
+                args.sql_query,
+                args.spark_config,
+                args.source_list,
+                args.trigger,
+                args.online_sinks,
+                args.catalog_config,
             )
 
         print(
@@ -67,19 +122,33 @@ def main(args):
     return output_location
 
 
-def execute_sql_query(job_type, output_uri, sql_query, spark_configs, source_list):
+def execute_sql_query(
+    job_type,
+    output_uri,
+    sql_query,
+    spark_configs,
+    source_list,
+    table_format="parquet",
+    iceberg_table=None,
+    write_mode="create",
+    catalog_configs=None,
+):
     # Executes the SQL Queries:
     # Parameters:
     #     job_type: string ("Transformation", "Materialization", "Training Set")
     #     output_uri: string (s3 paths)
     #     sql_query: string (eg. "SELECT * FROM source_0)
     #     spark_configs: dict (eg. {"fs.azure.account.key.account_name.dfs.core.windows.net": "aksdfkai=="})
-    #     source_list: List(string) (a list of s3 paths)
+    #     source_list: List(string) (a list of s3 paths or Iceberg tables)
+    #     table_format: string ("parquet" | "iceberg" | "delta")
+    #     iceberg_table: string (eg. "glue.featureform.featureform_transformation__name__variant")
+    #     write_mode: string ("create" | "overwrite")
+    #     catalog_configs: dict (eg. {"spark.sql.catalogImplementation": "hive"})
     # Return:
-    #     output_uri_with_timestamp: string (output s3 path)
+    #     output_location: string (output s3 path)
 
     try:
-        spark = SparkSession.builder.appName("Execute SQL Query").getOrCreate()
+        spark = create_spark_session("Execute SQL Query", catalog_configs)
         set_spark_configs(spark, spark_configs)
 
         if (
@@ -88,102 +157,553 @@ def execute_sql_query(job_type, output_uri, sql_query, spark_configs, source_lis
             or job_type == "Training Set"
         ):
             for i, source in enumerate(source_list):
-                file_extension = Path(source).suffix
-                is_directory = file_extension == ""
-
-                if file_extension == ".csv":
-                    source_df = (
-                        spark.read.option("header", "true")
-                        .option("recursiveFileLookup", "true")
-                        .csv(source)
-                    )
-                    source_df.createOrReplaceTempView(f"source_{i}")
-                elif file_extension == ".parquet" or is_directory:
-                    source_df = (
-                        spark.read.option("header", "true")
-                        .option("recursiveFileLookup", "true")
-                        .parquet(source)
-                    )
-                    source_df.createOrReplaceTempView(f"source_{i}")
-                else:
-                    raise Exception(
-                        f"the file type for '{source}' file is not supported."
-                    )
+                source_df = read_source(spark, source)
+                source_df.createOrReplaceTempView(f"source_{i}")
         else:
             raise Exception(
                 f"the '{job_type}' is not supported. Supported types: 'Transformation', 'Materialization', 'Training Set'"
             )
 
         output_dataframe = spark.sql(sql_query)
-
-        dt = datetime.now()
-        safe_datetime = dt.strftime("%Y-%m-%d-%H-%M-%S-%f")
-
-        # remove the '/' at the end of output_uri in order to avoid double slashes in the output file path.
-        output_uri_with_timestamp = f"{output_uri.rstrip('/')}/{safe_datetime}"
-
-        output_dataframe.write.option("header", "true").mode("overwrite").parquet(
-            output_uri_with_timestamp
+        return write_output(
+            output_dataframe, output_uri, table_format, iceberg_table, write_mode
         )
-        return output_uri_with_timestamp
     except Exception as e:
         print(e)
         raise e
 
 
-def execute_df_job(output_uri, code, store_type, spark_configs, credentials, sources):
+def execute_df_job(
+    output_uri,
+    code,
+    store_type,
+    spark_configs,
+    credentials,
+    sources,
+    table_format="parquet",
+    iceberg_table=None,
+    write_mode="create",
+    catalog_configs=None,
+):
     # Executes the DF transformation:
     # Parameters:
     #     output_uri: string (s3 paths)
     #     code: code (python code)
     #     sources: {parameter: s3_path} (used for passing dataframe parameters)
+    #     table_format: string ("parquet" | "iceberg" | "delta")
+    #     iceberg_table: string (eg. "glue.featureform.featureform_transformation__name__variant")
+    #     write_mode: string ("create" | "overwrite")
+    #     catalog_configs: dict (eg. {"spark.sql.catalogImplementation": "hive"})
     # Return:
-    #     output_uri_with_timestamp: string (output s3 path)
+    #     output_location: string (output s3 path)
 
-    spark = SparkSession.builder.appName("Dataframe Transformation").getOrCreate()
+    spark = create_spark_session("Dataframe Transformation", catalog_configs)
     set_spark_configs(spark, spark_configs)
 
     print(f"reading {len(sources)} source files")
     func_parameters = []
     for location in sources:
-        file_extension = Path(location).suffix
-        is_directory = file_extension == ""
-
-        if file_extension == ".csv":
-            func_parameters.append(
-                spark.read.option("header", "true")
-                .option("recursiveFileLookup", "true")
-                .csv(location)
-            )
-        elif file_extension == ".parquet" or is_directory:
-            func_parameters.append(
-                spark.read.option("header", "true")
-                .option("recursiveFileLookup", "true")
-                .parquet(location)
-            )
-        else:
-            raise Exception(f"the file type for '{location}' file is not supported.")
+        func_parameters.append(read_source(spark, location))
 
     try:
         code = get_code_from_file(code, store_type, credentials)
         func = types.FunctionType(code, globals(), "df_transformation")
         output_df = func(*func_parameters)
-
-        dt = datetime.now()
-        safe_datetime = dt.strftime("%Y-%m-%d-%H-%M-%S-%f")
-
-        # remove the '/' at the end of output_uri in order to avoid double slashes in the output file path.
-        output_uri_with_timestamp = f"{output_uri.rstrip('/')}/{safe_datetime}"
-
-        output_df.write.mode("overwrite").option("header", "true").parquet(
-            output_uri_with_timestamp
+        return write_output(
+            output_df, output_uri, table_format, iceberg_table, write_mode
         )
-        return output_uri_with_timestamp
     except (IOError, OSError) as e:
         print(f"Issue with execution of the transformation: {e}")
         raise e
 
 
+def execute_streaming_query(
+    output_uri,
+    sql_query,
+    spark_configs,
+    source_list,
+    trigger=None,
+    online_sinks=None,
+    catalog_configs=None,
+):
+    # Runs the SQL query over its sources as a stream until the job is
+    # stopped. Each micro-batch is appended to the output and written to the
+    # online sinks, and progress is checkpointed under the output so that a
+    # job that's started again carries on from where the last one stopped.
+    # Parameters:
+    #     output_uri: string (s3 paths)
+    #     sql_query: string (eg. "SELECT * FROM source_0)
+    #     spark_configs: dict (eg. {"fs.azure.account.key.account_name.dfs.core.windows.net": "aksdfkai=="})
+    #     source_list: List(string) (a list of s3 paths, or Delta, Iceberg or catalog tables)
+    #     trigger: string (eg. "1 minute")
+    #     online_sinks: List(dict) (the Redis tables that the query's output is written to)
+    #     catalog_configs: dict (eg. {"spark.sql.catalogImplementation": "hive"})
+    # Return:
+    #     output_location: string (output s3 path)
+
+    spark = create_spark_session("Streaming Transformation", catalog_configs)
+    set_spark_configs(spark, spark_configs)
+
+    for i, source in enumerate(source_list):
+        source_df = read_streaming_source(spark, source)
+        source_df.createOrReplaceTempView(f"source_{i}")
+
+    output_uri = output_uri.rstrip("/")
+    output_location = f"{output_uri}/streaming"
+
+    def write_batch(batch_df, batch_id):
+        batch_df.write.option("header", "true").mode("append").parquet(
+            output_location
+        )
+        for sink in online_sinks or []:
+            write_online_sink(batch_df, sink)
+
+    writer = (
+        spark.sql(sql_query)
+        .writeStream.outputMode("update")
+        .option("checkpointLocation", f"{output_uri}/_checkpoint")
+        .foreachBatch(write_batch)
+    )
+    if trigger:
+        writer = writer.trigger(processingTime=trigger)
+    writer.start().awaitTermination()
+    return output_location
+
+
+def read_streaming_source(spark, source):
+    # Reads a source as a stream of the rows appended to it. Sources are named
+    # the same way as in read_source, but are always read from their latest
+    # version. Files are read with the schema of the files already there.
+
+    # Parameters:
+    #     spark: SparkSession
+    #     source: string (eg. "s3a://bucket/directory" or "delta:s3a://bucket/table")
+    # Return:
+    #     source_df: streaming DataFrame
+
+    if source.startswith(CATALOG_SOURCE_PREFIX):
+        return spark.readStream.table(source[len(CATALOG_SOURCE_PREFIX) :])
+
+    if source.startswith(KAFKA_SOURCE_PREFIX):
+        return read_kafka_source(spark, parse_kafka_source(source))
+
+    table_format, _, versioned_source = source.partition(":")
+    if table_format in VERSIONED_SOURCE_FORMATS:
+        table, separator, version = versioned_source.rpartition("@")
+        if not separator or not (version == "" or version.isdigit()):
+            table = versioned_source
+        return spark.readStream.format(table_format).load(table)
+
+    schema = read_source(spark, source).schema
+    reader = (
+        spark.readStream.schema(schema)
+        .option("header", "true")
+        .option("recursiveFileLookup", "true")
+    )
+    if table_format in FILE_SOURCE_FORMATS:
+        return reader.format(table_format).load(versioned_source)
+    file_extension = Path(source).suffix[1:].lower()
+    if file_extension in FILE_SOURCE_FORMATS:
+        return reader.format(file_extension).load(source)
+    if file_extension == "csv":
+        return reader.csv(source)
+    return reader.parquet(source)
+
+
+def parse_kafka_source(source):
+    encoded = source[len(KAFKA_SOURCE_PREFIX) :]
+    return json.loads(base64.b64decode(encoded).decode("utf-8"))
+
+
+def kafka_options(spec):
+    # Returns the options of Spark's Kafka source that read the spec's topic.
+
+    # Parameters:
+    #     spec: dict (the decoded spec of a Kafka source)
+    # Return:
+    #     options: dict
+
+    options = {
+        "kafka.bootstrap.servers": spec["bootstrap_servers"],
+        "subscribe": spec["topic"],
+        "startingOffsets": spec.get("starting_offsets") or "earliest",
+        "failOnDataLoss": "false",
+        "kafka.security.protocol": spec.get("security_protocol") or "PLAINTEXT",
+    }
+    if spec.get("sasl_mechanism"):
+        mechanism = spec["sasl_mechanism"]
+        if mechanism not in KAFKA_LOGIN_MODULES:
+            raise ValueError(f"unsupported kafka SASL mechanism: {mechanism}")
+        username = spec["username"].replace('"', '\\"')
+        password = spec["password"].replace('"', '\\"')
+        options["kafka.sasl.mechanism"] = mechanism
+        options["kafka.sasl.jaas.config"] = (
+            f"{KAFKA_LOGIN_MODULES[mechanism]} required "
+            f'username="{username}" password="{password}";'
+        )
+    return options
+
+
+def read_kafka_source(spark, spec):
+    # Reads a Kafka topic as a stream. Raw values are read as strings along
+    # with their key and position in the topic; JSON and Avro values are read
+    # as their fields. JSON schemas are inferred from the topic's messages and
+    # Avro schemas are the latest of the topic's subject in the registry.
+
+    # Parameters:
+    #     spark: SparkSession
+    #     spec: dict (the decoded spec of a Kafka source)
+    # Return:
+    #     source_df: streaming DataFrame
+
+    options = kafka_options(spec)
+    reader = spark.readStream.format("kafka")
+    for key, value in options.items():
+        reader = reader.option(key, value)
+    kafka_df = reader.load()
+
+    value_format = spec.get("value_format") or "raw"
+    if value_format == "raw":
+        return kafka_df.select(
+            col("key").cast("string").alias("key"),
+            col("value").cast("string").alias("value"),
+            "topic",
+            "partition",
+            "offset",
+            "timestamp",
+        )
+    if value_format == "json":
+        schema = infer_kafka_json_schema(spark, options)
+        values = from_json(col("value").cast("string"), schema)
+        return kafka_df.select(values.alias("value")).select("value.*")
+    if value_format == "avro":
+        from pyspark.sql.avro.functions import from_avro
+
+        schema = fetch_registry_schema(spec)
+        # Confluent serializers prefix values with a magic byte and the
+        # 4 byte id of their schema, which aren't part of the Avro value.
+        values = from_avro(expr("substring(value, 6, length(value) - 5)"), schema)
+        return kafka_df.select(values.alias("value")).select("value.*")
+    raise ValueError(f"unsupported kafka value format: {value_format}")
+
+
+def infer_kafka_json_schema(spark, options):
+    reader = spark.read.format("kafka")
+    for key, value in options.items():
+        if key not in ("startingOffsets", "failOnDataLoss"):
+            reader = reader.option(key, value)
+    messages = reader.load().select(col("value").cast("string").alias("value"))
+    sample = messages.limit(1000).rdd.map(lambda row: row.value)
+    schema = spark.read.json(sample).schema
+    if not schema.fields:
+        raise ValueError("could not infer the schema of an empty kafka topic")
+    return schema
+
+
+def fetch_registry_schema(spec):
+    url = spec["schema_registry_url"].rstrip("/")
+    request = Request(f"{url}/subjects/{spec['topic']}-value/versions/latest")
+    if spec.get("schema_registry_username"):
+        credentials = (
+            f"{spec['schema_registry_username']}:{spec['schema_registry_password']}"
+        )
+        encoded = base64.b64encode(credentials.encode("utf-8")).decode("ascii")
+        request.add_header("Authorization", f"Basic {encoded}")
+    with urlopen(request) as response:
+        return json.loads(response.read().decode("utf-8"))["schema"]
+
+
+def write_online_sink(batch_df, sink):
+    # Writes a micro-batch to a feature's Redis table, the same way that the
+    # Redis online store sets values.
+
+    # Parameters:
+    #     batch_df: DataFrame
+    #     sink: dict (eg. {"type": "redis", "addr": "localhost:6379", "key": "...", "entity": "user", "value": "clicks"})
+
+    if sink["type"] != "redis":
+        raise Exception(f"the '{sink['type']}' online sink is not supported.")
+
+    def write_partition(rows):
+        import redis
+
+        host, _, port = sink["addr"].rpartition(":")
+        client = redis.Redis(
+            host=host,
+            port=int(port),
+            password=sink["password"] or None,
+            db=sink["db"],
+            ssl=sink["tls"],
+        )
+        pipeline = client.pipeline()
+        for row in rows:
+            entity = row[sink["entity"]]
+            if entity is None:
+                continue
+            pipeline.hset(
+                sink["key"], str(entity), format_redis_value(row[sink["value"]])
+            )
+            pipeline.hset(sink["updated_key"], str(entity), time.time_ns())
+        pipeline.execute()
+
+    batch_df.select(sink["entity"], sink["value"]).foreachPartition(write_partition)
+
+
+def format_redis_value(value):
+    # Formats a value the way the Redis online store serializes it, so that
+    # it's read back as the feature's type. Timestamps without a time zone are
+    # in UTC.
+
+    if value is None:
+        return "nil"
+    if isinstance(value, bool):
+        return "1" if value else "0"
+    if isinstance(value, float):
+        return format(Decimal(repr(value)), "f")
+    if isinstance(value, datetime):
+        if value.tzinfo is None:
+            return value.strftime("%Y-%m-%dT%H:%M:%SZ")
+        return value.replace(microsecond=0).isoformat().replace("+00:00", "Z")
+    return str(value)
+
+
+def create_spark_session(app_name, catalog_configs=None):
+    # Creates the job's Spark session. Catalog configs are set before the
+    # session is created, since the metastore that a session uses can't be
+    # changed once it's running.
+
+    # Parameters:
+    #     app_name: string
+    #     catalog_configs: dict (eg. {"spark.hadoop.hive.metastore.uris": "thrift://metastore:9083"})
+    # Return:
+    #     spark: SparkSession
+
+    builder = SparkSession.builder.appName(app_name)
+    for key, value in (catalog_configs or {}).items():
+        builder = builder.config(key, value)
+    return builder.getOrCreate()
+
+
+def read_source(spark, source):
+    # Reads a source into a dataframe. Sources are either files or directories
+    # of files, or versioned tables in the form "{format}:{table}@{version}",
+    # which are read at that version so that every job that reads the table
+    # sees the same version of it. Iceberg tables are either a catalog name or
+    # the table's location, and are read at a snapshot id; Delta tables are
+    # read from their location at a version number. Tables with no version are
+    # read at their latest one. Tables in the session's metastore are read by
+    # their name in the form "catalog:{db}.{table}".
+
+    # Parameters:
+    #     spark: SparkSession
+    #     source: string (eg. "s3a://bucket/file.parquet" or "delta:s3a://bucket/table@3")
+    # Return:
+    #     source_df: DataFrame
+
+    if source.startswith(CATALOG_SOURCE_PREFIX):
+        return spark.table(source[len(CATALOG_SOURCE_PREFIX) :])
+
+    if source.startswith(KAFKA_SOURCE_PREFIX):
+        raise ValueError("kafka sources can only be read by streaming transformations")
+
+    table_format, _, versioned_source = source.partition(":")
+    if table_format in FILE_SOURCE_FORMATS:
+        return read_file_source(spark, table_format, versioned_source)
+    if table_format in VERSIONED_SOURCE_FORMATS:
+        # versions are numbers, so the last "@" separates the version from
+        # table locations like abfss://container@account/path.
+        table, separator, version = versioned_source.rpartition("@")
+        if not separator or not (version == "" or version.isdigit()):
+            table, version = versioned_source, ""
+        reader = spark.read.format(table_format)
+        if version:
+            reader = reader.option(VERSION_READ_OPTIONS[table_format], version)
+        return reader.load(table)
+
+    file_extension = Path(source).suffix
+    is_directory = file_extension == ""
+
+    if file_extension[1:].lower() in FILE_SOURCE_FORMATS:
+        return read_file_source(spark, file_extension[1:].lower(), source)
+    elif file_extension == ".csv":
+        return (
+            spark.read.option("header", "true")
+            .option("recursiveFileLookup", "true")
+            .csv(source)
+        )
+    elif file_extension == ".parquet" or is_directory:
+        return read_parquet_source(spark, source)
+    else:
+        raise Exception(f"the file type for '{source}' file is not supported.")
+
+
+def read_file_source(spark, file_format, source):
+    # Reads an ORC or Avro file, or a directory of them.
+
+    # Parameters:
+    #     spark: SparkSession
+    #     file_format: string ("orc" | "avro")
+    #     source: string (eg. "s3a://bucket/hive/transactions/")
+    # Return:
+    #     source_df: DataFrame
+
+    return (
+        spark.read.format(file_format)
+        .option("recursiveFileLookup", "true")
+        .load(source)
+    )
+
+
+def read_parquet_source(spark, source):
+    # Reads Parquet files whose schemas may have evolved, such as a directory
+    # with a file a day where later files have more columns or wider types.
+    # Spark merges added columns itself, but fails on a column whose type was
+    # widened, in which case the files are read in groups that share a schema
+    # and each group is cast to the schema that merge_schemas merges them to.
+
+    # Parameters:
+    #     spark: SparkSession
+    #     source: string (eg. "s3a://bucket/transactions/")
+    # Return:
+    #     source_df: DataFrame
+
+    reader = spark.read.option("recursiveFileLookup", "true")
+    try:
+        return reader.option("mergeSchema", "true").parquet(source)
+    except Exception as e:
+        if not is_schema_merge_error(e):
+            raise
+
+    groups = {}
+    for path in reader.parquet(source).inputFiles():
+        schema = spark.read.parquet(path).schema
+        groups.setdefault(schema.json(), (schema, []))[1].append(path)
+    merged = merge_schemas([schema for schema, _ in groups.values()])
+    print(f"merged {len(groups)} schemas of '{source}' to {merged.simpleString()}")
+
+    source_df = None
+    for schema, paths in groups.values():
+        columns = []
+        for field in merged.fields:
+            column = col(f"`{field.name}`") if field.name in schema.names else lit(None)
+            columns.append(column.cast(field.dataType).alias(field.name))
+        group_df = spark.read.schema(schema).parquet(*paths).select(*columns)
+        source_df = group_df if source_df is None else source_df.unionByName(group_df)
+    return source_df
+
+
+def is_schema_merge_error(e):
+    message = str(e).lower()
+    return "failed merging schema" in message or "failed to merge" in message
+
+
+# Integral and fractional types from narrowest to widest, which columns are
+# widened along when files disagree on their type.
+INTEGRAL_TYPES = [ByteType(), ShortType(), IntegerType(), LongType()]
+FRACTIONAL_TYPES = [FloatType(), DoubleType()]
+
+
+def merge_schemas(schemas):
+    # Merges the schemas of a source's files. Columns are in the order they
+    # first appear, are nullable, since files without them have no values for
+    # them, and have the type that merge_types merges their types in each file
+    # to.
+
+    # Parameters:
+    #     schemas: List[StructType]
+    # Return:
+    #     schema: StructType
+
+    column_types = {}
+    for schema in schemas:
+        for field in schema.fields:
+            if field.name in column_types:
+                column_types[field.name] = merge_types(
+                    field.name, column_types[field.name], field.dataType
+                )
+            else:
+                column_types[field.name] = field.dataType
+    return StructType(
+        [StructField(name, t, True) for name, t in column_types.items()]
+    )
+
+
+def merge_types(name, left, right):
+    # The rules a column's type is merged by when files disagree on it:
+    #   - integers widen to the wider integer
+    #   - floats widen to double, as do integers mixed with floats
+    #   - dates widen to timestamps
+    # Any other difference is an error, rather than values being cast to a
+    # type that could lose or change them.
+
+    if left == right:
+        return left
+    if left in INTEGRAL_TYPES and right in INTEGRAL_TYPES:
+        return max(left, right, key=INTEGRAL_TYPES.index)
+    numeric = INTEGRAL_TYPES + FRACTIONAL_TYPES
+    if left in numeric and right in numeric:
+        return DoubleType()
+    if {type(left), type(right)} == {DateType, TimestampType}:
+        return TimestampType()
+    raise ValueError(
+        f"column '{name}' has incompatible types in the source's files: "
+        f"{left.simpleString()} and {right.simpleString()}"
+    )
+
+
+def write_output(output_df, output_uri, table_format, iceberg_table, write_mode):
+    # Writes the output of a job. Parquet output is written to a new directory
+    # under output_uri. Iceberg output is written to the table, which is kept
+    # at output_uri: creating it commits an append snapshot and overwriting it
+    # commits an overwrite snapshot, so earlier snapshots stay readable until
+    # they're expired. Delta output is written to the table at output_uri,
+    # and each write commits a new version, so earlier versions stay readable
+    # until they're vacuumed.
+
+    # Parameters:
+    #     output_df: DataFrame
+    #     output_uri: string (s3 paths)
+    #     table_format: string ("parquet" | "iceberg" | "delta")
+    #     iceberg_table: string (eg. "glue.featureform.featureform_transformation__name__variant")
+    #     write_mode: string ("create" | "overwrite")
+    # Return:
+    #     output_location: string (output s3 path)
+
+    # remove the '/' at the end of output_uri in order to avoid double slashes in the output file path.
+    output_uri = output_uri.rstrip("/")
+
+    if table_format == "iceberg":
+        if not iceberg_table:
+            raise Exception(
+                "an Iceberg table name is required to write Iceberg output"
+            )
+        writer = output_df.writeTo(iceberg_table)
+        if write_mode == "overwrite":
+            writer.overwrite(lit(True))
+        else:
+            writer.using("iceberg").tableProperty(
+                "location", output_uri
+            ).createOrReplace()
+        return output_uri
+
+    if table_format == "delta":
+        # a job's output replaces the table's schema, as it does for the
+        # other formats.
+        output_df.write.format("delta").mode("overwrite").option(
+            "overwriteSchema", "true"
+        ).save(output_uri)
+        return output_uri
+
+    dt = datetime.now()
+    safe_datetime = dt.strftime("%Y-%m-%d-%H-%M-%S-%f")
+    output_uri_with_timestamp = f"{output_uri}/{safe_datetime}"
+
+    output_df.write.option("header", "true").mode("overwrite").parquet(
+        output_uri_with_timestamp
+    )
+    return output_uri_with_timestamp
+
+
 def get_code_from_file(file_path, store_type=None, credentials=None):
     # Reads the code from a pkl file into a python code object.
     # Then this object will be used to execute the transformation.
@@ -203,22 +723,28 @@ def get_code_from_file(file_path, store_type=None, credentials=None):
         # the split below separates the bucket name and the key that is
         # used to read the object in the bucket.
 
+        # The bucket name is an access point's ARN if the store uses one. The
+        # keys are left empty to use the default credential chain, and are
+        # temporary ones with a session token if the store assumes a role.
         aws_region = credentials.get("aws_region")
         aws_access_key_id = credentials.get("aws_access_key_id")
         aws_secret_access_key = credentials.get("aws_secret_access_key")
         bucket_name = credentials.get("aws_bucket_name")
-        if not (
-            aws_region and aws_access_key_id and aws_secret_access_key and bucket_name
-        ):
+        if not (aws_region and bucket_name):
             raise Exception(
-                "the values for 'aws_region', 'aws_access_key_id', 'aws_secret_access_key', 'aws_bucket_name' need to be set as credential"
+                "the values for 'aws_region' and 'aws_bucket_name' need to be set as credential"
             )
 
         session = boto3.Session(
-            aws_access_key_id=aws_access_key_id,
-            aws_secret_access_key=aws_secret_access_key,
+            aws_access_key_id=aws_access_key_id or None,
+            aws_secret_access_key=aws_secret_access_key or None,
+            aws_session_token=credentials.get("aws_session_token") or None,
+        )
+        s3_resource = session.resource(
+            "s3",
+            region_name=aws_region,
+            endpoint_url=credentials.get("aws_endpoint") or None,
         )
-        s3_resource = session.resource("s3", region_name=aws_region)
         s3_object = s3_resource.Object(bucket_name, file_path)
 
         with io.BytesIO() as f:
@@ -261,11 +787,14 @@ def get_code_from_file(file_path, store_type=None, credentials=None):
         transformation_path = "transformation.pkl"
         bucket_name = credentials.get("gcp_bucket_name")
         project_id = credentials.get("gcp_project_id")
-        gcp_credentials = get_credentials_dict(credentials.get("gcp_credentials"))
-
-        credentials = service_account.Credentials.from_service_account_info(
-            gcp_credentials
-        )
+        # Without a service account key, the client uses Application Default
+        # Credentials, such as a GKE workload identity.
+        base64_creds = credentials.get("gcp_credentials")
+        credentials = None
+        if base64_creds:
+            credentials = service_account.Credentials.from_service_account_info(
+                get_credentials_dict(base64_creds)
+            )
         client = storage.Client(project=project_id, credentials=credentials)
 
         bucket = client.bucket(bucket_name)
@@ -320,8 +849,8 @@ def set_spark_configs(spark, configs):
 
 def set_gcp_credential_file_path(store_type, spark_args, creds):
     file_path = f"/tmp/{uuid.uuid4()}.json"
-    if store_type == "google_cloud_storage":
-        base64_creds = creds.get("gcp_credentials", "")
+    base64_creds = creds.get("gcp_credentials", "")
+    if store_type == "google_cloud_storage" and base64_creds:
         creds = get_credentials_dict(base64_creds)
 
         with open(file_path, "w") as f:
@@ -367,6 +896,10 @@ def split_key_value(values):
     return arguments
 
 
+def parse_online_sinks(base64_sinks):
+    return json.loads(base64.b64decode(base64_sinks).decode("utf-8"))
+
+
 def parse_args(args=None):
     parser = argparse.ArgumentParser()
     subparser = parser.add_subparsers(dest="transformation_type", required=True)
@@ -388,6 +921,22 @@ def parse_args(args=None):
         "--source_list", nargs="+", help="list of sources in the transformation string"
     )
     sql_parser.add_argument("--store_type", choices=FILESTORES)
+    sql_parser.add_argument(
+        "--table_format",
+        choices=TABLE_FORMATS,
+        default="parquet",
+        help="format of the output; Iceberg output is written to --iceberg_table",
+    )
+    sql_parser.add_argument(
+        "--iceberg_table",
+        help="catalog name of the Iceberg table to write; eg. glue.featureform.featureform_transformation__name__variant",
+    )
+    sql_parser.add_argument(
+        "--write_mode",
+        choices=WRITE_MODES,
+        default="create",
+        help="whether a versioned table is created or overwritten",
+    )
     sql_parser.add_argument(
         "--spark_config",
         "-sc",
@@ -395,6 +944,12 @@ def parse_args(args=None):
         default=[],
         help="spark config thats will be set by default",
     )
+    sql_parser.add_argument(
+        "--catalog_config",
+        action="append",
+        default=[],
+        help="spark config of the metastore that catalog sources are read from",
+    )
     sql_parser.add_argument(
         "--credential",
         "-c",
@@ -416,6 +971,22 @@ def parse_args(args=None):
         "--source", required=True, nargs="*", help="""Add a number of sources"""
     )
     df_parser.add_argument("--store_type", choices=FILESTORES)
+    df_parser.add_argument(
+        "--table_format",
+        choices=TABLE_FORMATS,
+        default="parquet",
+        help="format of the output; Iceberg output is written to --iceberg_table",
+    )
+    df_parser.add_argument(
+        "--iceberg_table",
+        help="catalog name of the Iceberg table to write; eg. glue.featureform.featureform_transformation__name__variant",
+    )
+    df_parser.add_argument(
+        "--write_mode",
+        choices=WRITE_MODES,
+        default="create",
+        help="whether a versioned table is created or overwritten",
+    )
     df_parser.add_argument(
         "--spark_config",
         "-sc",
@@ -424,6 +995,58 @@ def parse_args(args=None):
         help="spark config thats will be set by default",
     )
     df_parser.add_argument(
+        "--catalog_config",
+        action="append",
+        default=[],
+        help="spark config of the metastore that catalog sources are read from",
+    )
+    df_parser.add_argument(
+        "--credential",
+        "-c",
+        action="append",
+        default=[],
+        help="any credentials that would be need to used",
+    )
+
+    stream_parser = subparser.add_parser("stream")
+    stream_parser.add_argument(
+        "--output_uri",
+        required=True,
+        help="output file location; eg. s3a://featureform/{type}/{name}/{variant}",
+    )
+    stream_parser.add_argument(
+        "--sql_query",
+        required=True,
+        help="The SQL query you would like to run over the streamed sources. eg. SELECT user, COUNT(*) FROM source_0 GROUP BY user",
+    )
+    stream_parser.add_argument(
+        "--source_list", nargs="+", help="list of sources in the transformation string"
+    )
+    stream_parser.add_argument("--store_type", choices=FILESTORES)
+    stream_parser.add_argument(
+        "--trigger",
+        help="how often micro-batches run; eg. '1 minute'. Batches run back to back if it's not set",
+    )
+    stream_parser.add_argument(
+        "--online_sinks",
+        type=parse_online_sinks,
+        default=[],
+        help="base64 encoded JSON list of the online tables to write to",
+    )
+    stream_parser.add_argument(
+        "--spark_config",
+        "-sc",
+        action="append",
+        default=[],
+        help="spark config thats will be set by default",
+    )
+    stream_parser.add_argument(
+        "--catalog_config",
+        action="append",
+        default=[],
+        help="spark config of the metastore that catalog sources are read from",
+    )
+    stream_parser.add_argument(
         "--credential",
         "-c",
         action="append",
@@ -436,6 +1059,7 @@ def parse_args(args=None):
     # converts the key=value into a dictionary
     arguments.spark_config = split_key_value(arguments.spark_config)
     arguments.credential = split_key_value(arguments.credential)
+    arguments.catalog_config = split_key_value(arguments.catalog_config)
 
     return arguments
 
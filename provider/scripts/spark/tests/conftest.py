@@ -35,6 +35,10 @@ def sql_all_arguments():
         spark_config={},
         credential={},
         store_type=None,
+        table_format="parquet",
+        iceberg_table=None,
+        write_mode="create",
+        catalog_config={},
     )
     return (input_args, expected_args)
 
@@ -48,6 +52,10 @@ def sql_local_all_arguments():
         sql_query="SELECT * FROM source_0",
         source_list=[f"{dir_path}/test_files/input/transaction.parquet"],
         store_type="local",
+        table_format="parquet",
+        iceberg_table=None,
+        write_mode="create",
+        catalog_config={},
         spark_config={},
         credential={},
     )
@@ -72,6 +80,10 @@ def sql_partial_arguments():
         spark_config={},
         credential={},
         store_type=None,
+        table_format="parquet",
+        iceberg_table=None,
+        write_mode="create",
+        catalog_config={},
     )
     return (input_args, expected_args)
 
@@ -88,6 +100,10 @@ def sql_invaild_arguments():
         spark_config={},
         credential={},
         store_type=None,
+        table_format="parquet",
+        iceberg_table=None,
+        write_mode="create",
+        catalog_config={},
     )
     return (input_args, expected_args)
 
@@ -101,6 +117,10 @@ def sql_invalid_local_arguments():
         sql_query="SELECT * FROM source_0",
         source_list=["NONE"],
         store_type=None,
+        table_format="parquet",
+        iceberg_table=None,
+        write_mode="create",
+        catalog_config={},
     )
     return expected_args
 
@@ -125,6 +145,10 @@ def df_all_arguments():
         spark_config={},
         credential={},
         store_type=None,
+        table_format="parquet",
+        iceberg_table=None,
+        write_mode="create",
+        catalog_config={},
     )
     return (input_args, expected_args)
 
@@ -138,6 +162,10 @@ def df_partial_arguments():
         spark_config=None,
         credential=None,
         store_type=None,
+        table_format="parquet",
+        iceberg_table=None,
+        write_mode="create",
+        catalog_config={},
     )
     return (input_args, expected_args)
 
@@ -151,6 +179,10 @@ def df_invaild_arguments():
         spark_config={},
         credential={},
         store_type=None,
+        table_format="parquet",
+        iceberg_table=None,
+        write_mode="create",
+        catalog_config={},
     )
     return (input_args, expected_args)
 
@@ -183,6 +215,10 @@ def sql_databricks_all_arguments():
             "abfss://<container-name>@<storage-account-name>.blob.core.windows.net/ice_cream_100rows.csv"
         ],
         store_type="azure_blob_store",
+        table_format="parquet",
+        iceberg_table=None,
+        write_mode="create",
+        catalog_config={},
         spark_config={
             "fs.azure.account.key.account_name.dfs.core.windows.net": "adfjaidfasdklciadsj=="
         },
@@ -208,6 +244,10 @@ def df_local_all_arguments(df_transformation):
         spark_config={},
         credential={},
         store_type="local",
+        table_format="parquet",
+        iceberg_table=None,
+        write_mode="create",
+        catalog_config={},
     )
     return expected_args
 
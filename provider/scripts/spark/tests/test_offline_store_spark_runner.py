@@ -1,5 +1,8 @@
 import os
 import sys
+import json
+import base64
+from datetime import datetime
 
 sys.path.insert(0, "provider/scripts/spark")
 
@@ -15,6 +18,23 @@ from offline_store_spark_runner import (
     split_key_value,
     get_credentials_dict,
     delete_file,
+    read_source,
+    read_streaming_source,
+    format_redis_value,
+    kafka_options,
+    parse_kafka_source,
+    merge_schemas,
+    read_parquet_source,
+)
+from pyspark.sql.types import (
+    DateType,
+    DoubleType,
+    IntegerType,
+    LongType,
+    StringType,
+    StructField,
+    StructType,
+    TimestampType,
 )
 
 
@@ -160,3 +180,249 @@ def test_split_key_value():
 
     output = split_key_value(key_values)
     assert output == expected_output
+
+
+class FakeReader:
+    def __init__(self):
+        self.table_format = None
+        self.options = {}
+        self.table = None
+
+    def format(self, table_format):
+        self.table_format = table_format
+        return self
+
+    def option(self, key, value):
+        self.options[key] = value
+        return self
+
+    def load(self, table):
+        self.table = table
+        return self
+
+
+class FakeSpark:
+    def __init__(self):
+        self.read = FakeReader()
+        self.readStream = FakeReader()
+        self.tables = []
+
+    def table(self, name):
+        self.tables.append(name)
+        return name
+
+
+@pytest.mark.parametrize(
+    "source,table_format,table,options",
+    [
+        (
+            "delta:s3a://bucket/table@3",
+            "delta",
+            "s3a://bucket/table",
+            {"versionAsOf": "3"},
+        ),
+        ("delta:s3a://bucket/table@", "delta", "s3a://bucket/table", {}),
+        ("delta:s3a://bucket/table", "delta", "s3a://bucket/table", {}),
+        (
+            "delta:abfss://container@account.dfs.core.windows.net/table",
+            "delta",
+            "abfss://container@account.dfs.core.windows.net/table",
+            {},
+        ),
+        (
+            "iceberg:abfss://container@account.dfs.core.windows.net/table@42",
+            "iceberg",
+            "abfss://container@account.dfs.core.windows.net/table",
+            {"snapshot-id": "42"},
+        ),
+    ],
+)
+def test_read_source_versioned(source, table_format, table, options):
+    reader = read_source(FakeSpark(), source)
+
+    assert reader.table_format == table_format
+    assert reader.table == table
+    assert reader.options == options
+
+
+@pytest.mark.parametrize(
+    "source,table_format,path",
+    [
+        ("orc:s3a://bucket/hive/transactions", "orc", "s3a://bucket/hive/transactions"),
+        ("s3a://bucket/transactions.avro", "avro", "s3a://bucket/transactions.avro"),
+        ("s3a://bucket/transactions.ORC", "orc", "s3a://bucket/transactions.ORC"),
+    ],
+)
+def test_read_source_file_format(source, table_format, path):
+    reader = read_source(FakeSpark(), source)
+
+    assert reader.table_format == table_format
+    assert reader.table == path
+    assert reader.options == {"recursiveFileLookup": "true"}
+
+
+def test_read_source_catalog():
+    spark = FakeSpark()
+    table = read_source(spark, "catalog:glue_catalog.db.transactions")
+
+    assert table == "glue_catalog.db.transactions"
+    assert spark.tables == ["glue_catalog.db.transactions"]
+    assert spark.read.table_format is None
+
+
+def test_read_streaming_source():
+    spark = FakeSpark()
+    reader = read_streaming_source(spark, "delta:s3a://bucket/table@3")
+
+    assert reader is spark.readStream
+    assert reader.table_format == "delta"
+    assert reader.table == "s3a://bucket/table"
+    assert reader.options == {}
+
+
+def encode_kafka_source(spec):
+    encoded = base64.b64encode(json.dumps(spec).encode("utf-8")).decode("ascii")
+    return f"kafka:{encoded}"
+
+
+def test_kafka_options():
+    spec = {
+        "bootstrap_servers": "broker-1:9092,broker-2:9092",
+        "topic": "clicks",
+        "value_format": "json",
+        "starting_offsets": '{"clicks":{"0":12}}',
+        "security_protocol": "SASL_SSL",
+        "sasl_mechanism": "SCRAM-SHA-512",
+        "username": "user",
+        "password": 'pass"word',
+    }
+    options = kafka_options(parse_kafka_source(encode_kafka_source(spec)))
+
+    assert options == {
+        "kafka.bootstrap.servers": "broker-1:9092,broker-2:9092",
+        "subscribe": "clicks",
+        "startingOffsets": '{"clicks":{"0":12}}',
+        "failOnDataLoss": "false",
+        "kafka.security.protocol": "SASL_SSL",
+        "kafka.sasl.mechanism": "SCRAM-SHA-512",
+        "kafka.sasl.jaas.config": (
+            "org.apache.kafka.common.security.scram.ScramLoginModule required "
+            'username="user" password="pass\\"word";'
+        ),
+    }
+
+
+def test_kafka_options_unsupported_mechanism():
+    spec = {
+        "bootstrap_servers": "broker:9092",
+        "topic": "clicks",
+        "sasl_mechanism": "GSSAPI",
+        "username": "user",
+        "password": "password",
+    }
+    with pytest.raises(ValueError):
+        kafka_options(spec)
+
+
+def test_read_source_kafka():
+    source = encode_kafka_source({"bootstrap_servers": "broker:9092", "topic": "t"})
+    with pytest.raises(ValueError):
+        read_source(FakeSpark(), source)
+
+
+def test_parse_args_stream():
+    sinks = [{"type": "redis", "addr": "localhost:6379", "entity": "user"}]
+    encoded_sinks = base64.b64encode(json.dumps(sinks).encode("utf-8")).decode("ascii")
+    args = parse_args(
+        [
+            "stream",
+            "--output_uri",
+            "s3://featureform/Transformation/clicks/stream",
+            "--sql_query",
+            "SELECT * FROM source_0",
+            "--trigger",
+            "1 minute",
+            "--online_sinks",
+            encoded_sinks,
+            "--spark_config",
+            "a=b",
+            "--source_list",
+            "s3://featureform/events",
+        ]
+    )
+
+    assert args.transformation_type == "stream"
+    assert args.trigger == "1 minute"
+    assert args.online_sinks == sinks
+    assert args.spark_config == {"a": "b"}
+    assert args.source_list == ["s3://featureform/events"]
+
+
+@pytest.mark.parametrize(
+    "value,expected",
+    [
+        (None, "nil"),
+        (True, "1"),
+        (False, "0"),
+        (3, "3"),
+        (0.5, "0.5"),
+        (1e-07, "0.0000001"),
+        (1e21, "1000000000000000000000"),
+        (datetime(2023, 1, 2, 3, 4, 5, 6), "2023-01-02T03:04:05Z"),
+        ("a", "a"),
+    ],
+)
+def test_format_redis_value(value, expected):
+    assert format_redis_value(value) == expected
+
+
+def test_merge_schemas():
+    old = StructType(
+        [
+            StructField("id", IntegerType()),
+            StructField("amount", IntegerType()),
+            StructField("day", DateType()),
+        ]
+    )
+    new = StructType(
+        [
+            StructField("id", LongType()),
+            StructField("amount", DoubleType()),
+            StructField("day", TimestampType()),
+            StructField("country", StringType()),
+        ]
+    )
+    merged = merge_schemas([old, new])
+    assert merged == StructType(
+        [
+            StructField("id", LongType(), True),
+            StructField("amount", DoubleType(), True),
+            StructField("day", TimestampType(), True),
+            StructField("country", StringType(), True),
+        ]
+    )
+
+
+def test_merge_schemas_incompatible():
+    with pytest.raises(ValueError, match="'id'"):
+        merge_schemas(
+            [
+                StructType([StructField("id", IntegerType())]),
+                StructType([StructField("id", StringType())]),
+            ]
+        )
+
+
+def test_read_parquet_source_widened(spark, tmp_path):
+    old = StructType([StructField("id", IntegerType())])
+    new = StructType(
+        [StructField("id", LongType()), StructField("country", StringType())]
+    )
+    spark.createDataFrame([(1,)], old).write.parquet(f"{tmp_path}/day=1")
+    spark.createDataFrame([(2**40, "NZ")], new).write.parquet(f"{tmp_path}/day=2")
+
+    source_df = read_parquet_source(spark, str(tmp_path))
+
+    assert source_df.schema["id"].dataType == LongType()
+    rows = sorted(source_df.collect(), key=lambda row: row.id)
+    assert [(row.id, row.country) for row in rows] == [(1, None), (2**40, "NZ")]
@@ -0,0 +1,443 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package provider
+
+import (
+	"fmt"
+	"hash/fnv"
+	"sort"
+	"time"
+
+	pc "github.com/featureform/provider/provider_config"
+	pt "github.com/featureform/provider/provider_type"
+)
+
+// shardVirtualNodes is the number of points each shard has on the hash ring.
+// More points spread entities more evenly at the cost of a larger ring.
+const shardVirtualNodes = 128
+
+func shardedRedisOnlineStoreFactory(serialized pc.SerializedConfig) (Provider, error) {
+	config := &pc.ShardedRedisConfig{}
+	if err := config.Deserialize(serialized); err != nil {
+		return nil, err
+	}
+	shards := make([]OnlineStore, 0, len(config.Shards))
+	closeShards := func() {
+		for _, shard := range shards {
+			shard.Close()
+		}
+	}
+	for i := range config.Shards {
+		shardConfig := config.Shards[i]
+		if shardConfig.Prefix == "" {
+			shardConfig.Prefix = "Featureform_table__"
+		}
+		shard, err := NewRedisOnlineStore(&shardConfig)
+		if err != nil {
+			closeShards()
+			return nil, fmt.Errorf("shard %d: %w", i, err)
+		}
+		shards = append(shards, shard)
+	}
+	store, err := NewShardedStore(shards, config.RebalancingFrom, serialized)
+	if err != nil {
+		closeShards()
+		return nil, err
+	}
+	return store, nil
+}
+
+// hashRing consistently maps entities to shards. Shards are identified by
+// their index, so appending a shard only moves the entities it takes over
+// from the existing shards.
+type hashRing struct {
+	points []uint64
+	shards []int
+}
+
+func newHashRing(numShards int) *hashRing {
+	ring := &hashRing{
+		points: make([]uint64, 0, numShards*shardVirtualNodes),
+		shards: make([]int, 0, numShards*shardVirtualNodes),
+	}
+	type point struct {
+		hash  uint64
+		shard int
+	}
+	points := make([]point, 0, numShards*shardVirtualNodes)
+	for shard := 0; shard < numShards; shard++ {
+		for node := 0; node < shardVirtualNodes; node++ {
+			points = append(points, point{hashKey(fmt.Sprintf("shard-%d-%d", shard, node)), shard})
+		}
+	}
+	sort.Slice(points, func(i, j int) bool { return points[i].hash < points[j].hash })
+	for _, p := range points {
+		ring.points = append(ring.points, p.hash)
+		ring.shards = append(ring.shards, p.shard)
+	}
+	return ring
+}
+
+// owner returns the index of the shard that holds the entity.
+func (ring *hashRing) owner(entity string) int {
+	hash := hashKey(entity)
+	i := sort.Search(len(ring.points), func(i int) bool { return ring.points[i] >= hash })
+	if i == len(ring.points) {
+		i = 0
+	}
+	return ring.shards[i]
+}
+
+// hashKey hashes the key with FNV and then mixes the result, since FNV alone
+// maps keys that only differ in their last few bytes, like sequential IDs,
+// close together on the ring.
+func hashKey(key string) uint64 {
+	h := fnv.New64a()
+	h.Write([]byte(key))
+	hash := h.Sum64()
+	hash ^= hash >> 33
+	hash *= 0xff51afd7ed558ccd
+	hash ^= hash >> 33
+	hash *= 0xc4ceb9fe1a85ec53
+	hash ^= hash >> 33
+	return hash
+}
+
+// ShardedStore spreads each table's entities across several online stores
+// with consistent hashing, for feature sets too large for a single store.
+// Every table is created in every shard.
+//
+// When shards are added, the entities they take over stay in their old
+// shards until the store is rebalanced. Until then, the store should be
+// created with rebalancingFrom set to the previous number of shards so that
+// reads that miss on an entity's new shard fall back to its old one.
+type ShardedStore struct {
+	BaseProvider
+	Shards          []OnlineStore
+	rebalancingFrom int
+	ring            *hashRing
+	previous        *hashRing
+}
+
+// NewShardedStore creates a store over the shards. rebalancingFrom is the
+// number of shards before shards were last added, or zero if they've been
+// rebalanced since. While rebalancing, tables missing from the new shards
+// are created in them.
+func NewShardedStore(shards []OnlineStore, rebalancingFrom int, config pc.SerializedConfig) (*ShardedStore, error) {
+	if len(shards) == 0 {
+		return nil, fmt.Errorf("sharded store must have at least one shard")
+	}
+	if rebalancingFrom < 0 || rebalancingFrom > len(shards) {
+		return nil, fmt.Errorf("cannot rebalance from %d shards to %d", rebalancingFrom, len(shards))
+	}
+	store := &ShardedStore{
+		BaseProvider: BaseProvider{
+			ProviderType:   pt.ShardedRedisOnline,
+			ProviderConfig: config,
+		},
+		Shards: shards,
+		ring:   newHashRing(len(shards)),
+	}
+	if rebalancingFrom == 0 || rebalancingFrom == len(shards) {
+		return store, nil
+	}
+	store.rebalancingFrom = rebalancingFrom
+	store.previous = newHashRing(rebalancingFrom)
+	if err := store.createNewShardTables(rebalancingFrom); err != nil {
+		return nil, err
+	}
+	return store, nil
+}
+
+// createNewShardTables creates the tables of the first shard in every shard
+// from index from onwards.
+func (store *ShardedStore) createNewShardTables(from int) error {
+	tables, err := store.ListTables()
+	if err != nil {
+		return fmt.Errorf("could not list tables: %w", err)
+	}
+	for _, info := range tables {
+		for i := from; i < len(store.Shards); i++ {
+			_, err := store.Shards[i].CreateTable(info.Feature, info.Variant, info.ValueType)
+			if _, exists := err.(*TableAlreadyExists); err != nil && !exists {
+				return fmt.Errorf("shard %d: could not create table %s %s: %w", i, info.Feature, info.Variant, err)
+			}
+		}
+	}
+	return nil
+}
+
+func (store *ShardedStore) AsOnlineStore() (OnlineStore, error) {
+	return store, nil
+}
+
+func (store *ShardedStore) GetTable(feature, variant string) (OnlineStoreTable, error) {
+	tables := make([]OnlineStoreTable, len(store.Shards))
+	for i, shard := range store.Shards {
+		table, err := shard.GetTable(feature, variant)
+		if _, notFound := err.(*TableNotFound); notFound {
+			return nil, err
+		} else if err != nil {
+			return nil, fmt.Errorf("shard %d: %w", i, err)
+		}
+		tables[i] = table
+	}
+	return &shardedTable{shards: tables, ring: store.ring, previous: store.previous}, nil
+}
+
+// CreateTable creates the table in every shard. If it already exists in
+// some of them, it's still created in the rest before TableAlreadyExists is
+// returned, so that a partially failed creation can be retried.
+func (store *ShardedStore) CreateTable(feature, variant string, valueType ValueType) (OnlineStoreTable, error) {
+	tables := make([]OnlineStoreTable, len(store.Shards))
+	var existsErr error
+	for i, shard := range store.Shards {
+		table, err := shard.CreateTable(feature, variant, valueType)
+		if _, exists := err.(*TableAlreadyExists); exists {
+			existsErr = err
+			continue
+		} else if err != nil {
+			return nil, fmt.Errorf("shard %d: %w", i, err)
+		}
+		tables[i] = table
+	}
+	if existsErr != nil {
+		return nil, existsErr
+	}
+	return &shardedTable{shards: tables, ring: store.ring, previous: store.previous}, nil
+}
+
+func (store *ShardedStore) DeleteTable(feature, variant string) error {
+	for i, shard := range store.Shards {
+		if err := shard.DeleteTable(feature, variant); err != nil {
+			return fmt.Errorf("shard %d: %w", i, err)
+		}
+	}
+	return nil
+}
+
+func (store *ShardedStore) Truncate(feature, variant string) error {
+	for i, shard := range store.Shards {
+		if err := truncate(shard, feature, variant); err != nil {
+			return fmt.Errorf("shard %d: %w", i, err)
+		}
+	}
+	return nil
+}
+
+// ListTables lists the tables of the first shard, which has every table.
+func (store *ShardedStore) ListTables() ([]OnlineTableInfo, error) {
+	listing, ok := store.Shards[0].(TableListingStore)
+	if !ok {
+		return nil, fmt.Errorf("%T does not support listing tables", store.Shards[0])
+	}
+	return listing.ListTables()
+}
+
+// Capabilities reports the features sharded tables implement that every
+// shard supports. Tables are listed from the first shard alone.
+func (store *ShardedStore) Capabilities() OnlineCapabilities {
+	shared := store.Shards[0].Capabilities()
+	for _, shard := range store.Shards[1:] {
+		shared = shared.intersect(shard.Capabilities())
+	}
+	return OnlineCapabilities{
+		BatchWrites: shared.BatchWrites,
+		Stats:       shared.Stats,
+		Timestamps:  shared.Timestamps,
+		Truncate:    shared.Truncate,
+		ListTables:  store.Shards[0].Capabilities().ListTables,
+	}
+}
+
+func (store *ShardedStore) Close() error {
+	var closeErr error
+	for i, shard := range store.Shards {
+		if err := shard.Close(); err != nil && closeErr == nil {
+			closeErr = fmt.Errorf("shard %d: %w", i, err)
+		}
+	}
+	return closeErr
+}
+
+// ShardRebalanceProgress is reported while a sharded store is rebalanced.
+type ShardRebalanceProgress struct {
+	Feature, Variant string
+	// Moved is the number of entities copied to their new shard so far from
+	// the current table.
+	Moved int64
+	// TablesRebalanced is the number of tables fully rebalanced, out of Tables.
+	TablesRebalanced, Tables int
+}
+
+// Rebalance copies the entities that the newly added shards took over from
+// their old shards. Values already written to an entity's new shard are
+// newer, so they aren't overwritten. Old copies are left in place, since
+// reads no longer reach them, and a failed rebalance can be run again.
+//
+// Once it's done, the store should be reconfigured without rebalancingFrom.
+func (store *ShardedStore) Rebalance(progress func(ShardRebalanceProgress)) error {
+	if store.previous == nil {
+		return fmt.Errorf("sharded store is not rebalancing")
+	}
+	tables, err := store.ListTables()
+	if err != nil {
+		return fmt.Errorf("could not list tables: %w", err)
+	}
+	for i, info := range tables {
+		current := ShardRebalanceProgress{
+			Feature:          info.Feature,
+			Variant:          info.Variant,
+			TablesRebalanced: i,
+			Tables:           len(tables),
+		}
+		if err := store.rebalanceTable(info, current, progress); err != nil {
+			return fmt.Errorf("could not rebalance table %s %s: %w", info.Feature, info.Variant, err)
+		}
+	}
+	return nil
+}
+
+func (store *ShardedStore) rebalanceTable(info OnlineTableInfo, current ShardRebalanceProgress, progress func(ShardRebalanceProgress)) error {
+	table, err := store.GetTable(info.Feature, info.Variant)
+	if err != nil {
+		return err
+	}
+	sharded := table.(*shardedTable)
+	for shard := 0; shard < store.rebalancingFrom; shard++ {
+		if err := sharded.moveTakenOver(shard, &current, progress); err != nil {
+			return fmt.Errorf("shard %d: %w", shard, err)
+		}
+	}
+	current.TablesRebalanced++
+	if progress != nil {
+		progress(current)
+	}
+	return nil
+}
+
+type shardedTable struct {
+	shards   []OnlineStoreTable
+	ring     *hashRing
+	previous *hashRing
+}
+
+func (table *shardedTable) Set(entity string, value interface{}) error {
+	return table.shards[table.ring.owner(entity)].Set(entity, value)
+}
+
+// SetBatch writes each shard's share of the values as its own batch.
+func (table *shardedTable) SetBatch(values map[string]interface{}) error {
+	batches := make(map[int]map[string]interface{})
+	for entity, value := range values {
+		owner := table.ring.owner(entity)
+		if batches[owner] == nil {
+			batches[owner] = make(map[string]interface{})
+		}
+		batches[owner][entity] = value
+	}
+	for shard, batch := range batches {
+		if err := setBatch(table.shards[shard], batch); err != nil {
+			return fmt.Errorf("shard %d: %w", shard, err)
+		}
+	}
+	return nil
+}
+
+func (table *shardedTable) Get(entity string) (interface{}, error) {
+	owner := table.ring.owner(entity)
+	value, err := table.shards[owner].Get(entity)
+	if _, notFound := err.(*EntityNotFound); notFound {
+		if previous, moved := table.previousOwner(entity, owner); moved {
+			return table.shards[previous].Get(entity)
+		}
+	}
+	return value, err
+}
+
+// GetWithTimestamp returns zero write times from shards that don't record
+// them.
+func (table *shardedTable) GetWithTimestamp(entity string) (interface{}, time.Time, error) {
+	owner := table.ring.owner(entity)
+	value, updated, err := getWithTimestamp(table.shards[owner], entity)
+	if _, notFound := err.(*EntityNotFound); notFound {
+		if previous, moved := table.previousOwner(entity, owner); moved {
+			return getWithTimestamp(table.shards[previous], entity)
+		}
+	}
+	return value, updated, err
+}
+
+// Stats sums the stats of every shard. While rebalancing, entities copied to
+// their new shard are counted in both.
+func (table *shardedTable) Stats() (TableStats, error) {
+	var total TableStats
+	for i, shard := range table.shards {
+		statsTable, ok := shard.(StatsTable)
+		if !ok {
+			return TableStats{}, fmt.Errorf("shard %d: %T does not support stats", i, shard)
+		}
+		stats, err := statsTable.Stats()
+		if err != nil {
+			return TableStats{}, fmt.Errorf("shard %d: %w", i, err)
+		}
+		total.Entities += stats.Entities
+		total.Bytes += stats.Bytes
+	}
+	return total, nil
+}
+
+func getWithTimestamp(table OnlineStoreTable, entity string) (interface{}, time.Time, error) {
+	if timestamped, ok := table.(TimestampedTable); ok {
+		return timestamped.GetWithTimestamp(entity)
+	}
+	value, err := table.Get(entity)
+	return value, time.Time{}, err
+}
+
+// previousOwner returns the shard that held the entity before shards were
+// added, and whether it differs from its current owner.
+func (table *shardedTable) previousOwner(entity string, owner int) (int, bool) {
+	if table.previous == nil {
+		return 0, false
+	}
+	previous := table.previous.owner(entity)
+	return previous, previous != owner
+}
+
+// moveTakenOver copies the entities of the shard that are now owned by
+// another shard to their owner, unless the owner already has a value.
+func (table *shardedTable) moveTakenOver(shard int, current *ShardRebalanceProgress, progress func(ShardRebalanceProgress)) error {
+	scannable, ok := table.shards[shard].(ScannableTable)
+	if !ok {
+		return fmt.Errorf("%T does not support scans", table.shards[shard])
+	}
+	it, err := scannable.Scan()
+	if err != nil {
+		return err
+	}
+	defer it.Close()
+	for it.Next() {
+		record := it.Value()
+		owner := table.ring.owner(record.Entity)
+		if owner == shard {
+			continue
+		}
+		_, err := table.shards[owner].Get(record.Entity)
+		if err == nil {
+			continue
+		} else if _, notFound := err.(*EntityNotFound); !notFound {
+			return err
+		}
+		if err := table.shards[owner].Set(record.Entity, record.Value); err != nil {
+			return err
+		}
+		current.Moved++
+		if progress != nil && current.Moved%defaultMigrationBatchSize == 0 {
+			progress(*current)
+		}
+	}
+	return it.Err()
+}
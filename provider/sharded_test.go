@@ -0,0 +1,149 @@
+package provider
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestShardedStore(t *testing.T) {
+	shards := []OnlineStore{NewLocalOnlineStore(), NewLocalOnlineStore(), NewLocalOnlineStore()}
+	store, err := NewShardedStore(shards, 0, nil)
+	if err != nil {
+		t.Fatalf("Failed to create sharded store: %s", err)
+	}
+	table, err := store.CreateTable("feature", "variant", Int)
+	if err != nil {
+		t.Fatalf("Failed to create table: %s", err)
+	}
+	values := make(map[string]interface{})
+	for i := 0; i < 300; i++ {
+		values[fmt.Sprintf("entity-%d", i)] = i
+	}
+	if err := table.(BatchSettableTable).SetBatch(values); err != nil {
+		t.Fatalf("Failed to set batch: %s", err)
+	}
+	for entity, expected := range values {
+		if value, err := table.Get(entity); err != nil || value != expected {
+			t.Fatalf("Expected %s to be %v, got %v %v", entity, expected, value, err)
+		}
+	}
+	for i, shard := range shards {
+		shardTable, err := shard.GetTable("feature", "variant")
+		if err != nil {
+			t.Fatalf("Failed to get table from shard %d: %s", i, err)
+		}
+		if len(shardTable.(*localOnlineTable).values) == 0 {
+			t.Fatalf("Expected shard %d to hold some entities", i)
+		}
+	}
+	if _, err := store.CreateTable("feature", "variant", Int); err == nil {
+		t.Fatalf("Expected table to already exist")
+	}
+}
+
+func TestShardedStoreRebalance(t *testing.T) {
+	shards := []OnlineStore{NewLocalOnlineStore(), NewLocalOnlineStore()}
+	store, err := NewShardedStore(shards, 0, nil)
+	if err != nil {
+		t.Fatalf("Failed to create sharded store: %s", err)
+	}
+	table, err := store.CreateTable("feature", "variant", Int)
+	if err != nil {
+		t.Fatalf("Failed to create table: %s", err)
+	}
+	for i := 0; i < 300; i++ {
+		if err := table.Set(fmt.Sprintf("entity-%d", i), i); err != nil {
+			t.Fatalf("Failed to set entity: %s", err)
+		}
+	}
+	if err := store.Rebalance(nil); err == nil {
+		t.Fatalf("Expected rebalance without added shards to fail")
+	}
+
+	added := NewLocalOnlineStore()
+	store, err = NewShardedStore(append(shards, added), len(shards), nil)
+	if err != nil {
+		t.Fatalf("Failed to add shard: %s", err)
+	}
+	table, err = store.GetTable("feature", "variant")
+	if err != nil {
+		t.Fatalf("Failed to get table with added shard: %s", err)
+	}
+	var takenOver string
+	for i := 0; i < 300 && takenOver == ""; i++ {
+		if entity := fmt.Sprintf("entity-%d", i); store.ring.owner(entity) == 2 {
+			takenOver = entity
+		}
+	}
+	if takenOver == "" {
+		t.Fatalf("Expected added shard to take over some entities")
+	}
+	if _, err := table.Get(takenOver); err != nil {
+		t.Fatalf("Expected read to fall back to the old shard: %s", err)
+	}
+	if err := table.Set(takenOver, -1); err != nil {
+		t.Fatalf("Failed to set entity: %s", err)
+	}
+
+	var progress []ShardRebalanceProgress
+	if err := store.Rebalance(func(p ShardRebalanceProgress) { progress = append(progress, p) }); err != nil {
+		t.Fatalf("Failed to rebalance: %s", err)
+	}
+	if len(progress) == 0 || progress[len(progress)-1].TablesRebalanced != 1 {
+		t.Fatalf("Expected final progress report, got %v", progress)
+	}
+	store, err = NewShardedStore(append(shards, added), 0, nil)
+	if err != nil {
+		t.Fatalf("Failed to create rebalanced store: %s", err)
+	}
+	table, err = store.GetTable("feature", "variant")
+	if err != nil {
+		t.Fatalf("Failed to get table: %s", err)
+	}
+	for i := 0; i < 300; i++ {
+		entity := fmt.Sprintf("entity-%d", i)
+		var expected interface{} = i
+		if entity == takenOver {
+			expected = -1
+		}
+		if value, err := table.Get(entity); err != nil || value != expected {
+			t.Fatalf("Expected %s to be %v after rebalancing, got %v %v", entity, expected, value, err)
+		}
+	}
+}
+
+func TestShardedStoreCapabilities(t *testing.T) {
+	readThrough := &ReadThroughStore{OnlineStore: NewLocalOnlineStore()}
+	tests := []struct {
+		name     string
+		shards   []OnlineStore
+		expected OnlineCapabilities
+	}{
+		{
+			"Local",
+			[]OnlineStore{NewLocalOnlineStore(), NewLocalOnlineStore()},
+			OnlineCapabilities{BatchWrites: true, Stats: true, Timestamps: true, Truncate: true, ListTables: true},
+		},
+		{
+			"Mixed",
+			[]OnlineStore{NewLocalOnlineStore(), readThrough},
+			OnlineCapabilities{Timestamps: true, ListTables: true},
+		},
+		{
+			"First Shard Lists",
+			[]OnlineStore{readThrough, NewLocalOnlineStore()},
+			OnlineCapabilities{Timestamps: true},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store, err := NewShardedStore(tt.shards, 0, nil)
+			if err != nil {
+				t.Fatalf("Failed to create sharded store: %s", err)
+			}
+			if capabilities := store.Capabilities(); capabilities != tt.expected {
+				t.Fatalf("Expected %+v, got %+v", tt.expected, capabilities)
+			}
+		})
+	}
+}
@@ -3,6 +3,7 @@ package provider
 import (
 	"errors"
 	"fmt"
+	"strings"
 
 	pc "github.com/featureform/provider/provider_config"
 	pt "github.com/featureform/provider/provider_type"
@@ -51,6 +52,38 @@ func snowflakeOfflineStoreFactory(config pc.SerializedConfig) (Provider, error)
 	return store, nil
 }
 
+func (q snowflakeSQLQueries) materializationApproxNumRows() string {
+	return "SELECT row_count FROM information_schema.tables WHERE table_name = ?"
+}
+
+func (q snowflakeSQLQueries) materializationIncrementalUpdate(tableName, changedTableName, sourceName string) []string {
+	return q.incrementalMaterializationStatements(tableName, changedTableName, sourceName)
+}
+
 func (q snowflakeSQLQueries) materializationDrop(tableName string) string {
 	return fmt.Sprintf("DROP TABLE %s", sanitize(tableName))
 }
+
+// maintenance sets the table's clustering key, if ClusterColumns is given, and
+// resumes automatic reclustering by default.
+func (q snowflakeSQLQueries) maintenance(tableName string, config MaintenanceConfig) ([]string, error) {
+	operations := config.Operations
+	if len(operations) == 0 {
+		operations = []MaintenanceOperation{ReclusterOperation}
+	}
+	statements := make([]string, 0, len(operations)+1)
+	for _, op := range operations {
+		if op != ReclusterOperation {
+			return nil, &UnsupportedMaintenanceOperation{op, string(pt.SnowflakeOffline)}
+		}
+		if len(config.ClusterColumns) > 0 {
+			columns := make([]string, len(config.ClusterColumns))
+			for i, col := range config.ClusterColumns {
+				columns[i] = sanitize(col)
+			}
+			statements = append(statements, fmt.Sprintf("ALTER TABLE %s CLUSTER BY (%s)", sanitize(tableName), strings.Join(columns, ", ")))
+		}
+		statements = append(statements, fmt.Sprintf("ALTER TABLE %s RESUME RECLUSTER", sanitize(tableName)))
+	}
+	return statements, nil
+}
@@ -3,7 +3,9 @@ package provider
 import (
 	"bytes"
 	"context"
+	"crypto/sha256"
 	"encoding/base64"
+	"encoding/hex"
 	"encoding/json"
 	"net/http"
 	"path/filepath"
@@ -11,6 +13,7 @@ import (
 	"fmt"
 	"os"
 	"os/exec"
+	"regexp"
 	"strings"
 	"time"
 
@@ -93,38 +96,123 @@ func NewSparkS3FileStore(config Config) (SparkFileStore, error) {
 	if !ok {
 		return nil, fmt.Errorf("could not cast file store to *S3FileStore")
 	}
-
-	return &SparkS3FileStore{s3}, nil
+	sparkStore := &SparkS3FileStore{S3FileStore: s3}
+	if s3.RoleARN != "" {
+		// The runner downloads transformation code with the role's credentials
+		// rather than assuming the role itself.
+		if sparkStore.session, err = s3.credentials.Retrieve(context.TODO()); err != nil {
+			return nil, fmt.Errorf("could not assume role %s: %v", s3.RoleARN, err)
+		}
+	}
+	return sparkStore, nil
 }
 
 type SparkS3FileStore struct {
 	*S3FileStore
+	// session is the assumed role's temporary credentials, if there is one.
+	session aws.Credentials
 }
 
+const (
+	s3aSimpleCredentialsProvider    = "org.apache.hadoop.fs.s3a.SimpleAWSCredentialsProvider"
+	s3aTemporaryCredentialsProvider = "org.apache.hadoop.fs.s3a.TemporaryAWSCredentialsProvider"
+	s3aAssumedRoleProvider          = "org.apache.hadoop.fs.s3a.auth.AssumedRoleCredentialProvider"
+	awsDefaultCredentialsProvider   = "com.amazonaws.auth.DefaultAWSCredentialsProviderChain"
+)
+
 func (s3 SparkS3FileStore) SparkConfig() []string {
-	return []string{
+	config := s3.credentialsSparkConfig()
+	if s3.Endpoint != "" {
+		config = append(config,
+			"--spark_config",
+			fmt.Sprintf("\"fs.s3a.endpoint=%s\"", s3.Endpoint),
+			"--spark_config",
+			fmt.Sprintf("\"fs.s3a.path.style.access=%t\"", s3.PathStyle),
+		)
+	}
+	if s3.AccessPoint != "" {
+		config = append(config,
+			"--spark_config",
+			fmt.Sprintf("\"fs.s3a.bucket.%s.accesspoint.arn=%s\"", s3.Bucket, s3.AccessPoint),
+		)
+	}
+	return append(config,
 		"--spark_config",
-		fmt.Sprintf("\"fs.s3a.access.key=%s\"", s3.Credentials.AWSAccessKeyId),
+		"\"spark.hadoop.fs.s3.impl=org.apache.hadoop.fs.s3a.S3AFileSystem\"",
+	)
+}
+
+// credentialsSparkConfig configures how Spark authenticates to S3. Hadoop
+// assumes the store's role itself, refreshing its credentials as they
+// expire, but it can't pass an external ID, so roles that require one are
+// assumed up front and their temporary credentials, which expire after an
+// hour, are passed instead.
+func (s3 SparkS3FileStore) credentialsSparkConfig() []string {
+	if s3.RoleARN != "" && s3.ExternalID != "" {
+		return []string{
+			"--spark_config",
+			fmt.Sprintf("\"fs.s3a.access.key=%s\"", s3.session.AccessKeyID),
+			"--spark_config",
+			fmt.Sprintf("\"fs.s3a.secret.key=%s\"", s3.session.SecretAccessKey),
+			"--spark_config",
+			fmt.Sprintf("\"fs.s3a.session.token=%s\"", s3.session.SessionToken),
+			"--spark_config",
+			fmt.Sprintf("\"fs.s3a.aws.credentials.provider=%s\"", s3aTemporaryCredentialsProvider),
+		}
+	}
+	config := []string{}
+	baseProvider := awsDefaultCredentialsProvider
+	if s3.Credentials.AWSAccessKeyId != "" {
+		baseProvider = s3aSimpleCredentialsProvider
+		config = append(config,
+			"--spark_config",
+			fmt.Sprintf("\"fs.s3a.access.key=%s\"", s3.Credentials.AWSAccessKeyId),
+			"--spark_config",
+			fmt.Sprintf("\"fs.s3a.secret.key=%s\"", s3.Credentials.AWSSecretKey),
+		)
+	}
+	if s3.RoleARN == "" {
+		return append(config,
+			"--spark_config",
+			fmt.Sprintf("\"fs.s3a.aws.credentials.provider=%s\"", baseProvider),
+		)
+	}
+	return append(config,
 		"--spark_config",
-		fmt.Sprintf("\"fs.s3a.secret.key=%s\"", s3.Credentials.AWSSecretKey),
+		fmt.Sprintf("\"fs.s3a.aws.credentials.provider=%s\"", s3aAssumedRoleProvider),
 		"--spark_config",
-		"\"fs.s3a.aws.credentials.provider=org.apache.hadoop.fs.s3a.SimpleAWSCredentialsProvider\"",
+		fmt.Sprintf("\"fs.s3a.assumed.role.arn=%s\"", s3.RoleARN),
 		"--spark_config",
-		"\"spark.hadoop.fs.s3.impl=org.apache.hadoop.fs.s3a.S3AFileSystem\"",
-	}
+		fmt.Sprintf("\"fs.s3a.assumed.role.credentials.provider=%s\"", baseProvider),
+	)
 }
 
 func (s3 SparkS3FileStore) CredentialsConfig() []string {
-	return []string{
+	bucket := s3.Bucket
+	if s3.AccessPoint != "" {
+		bucket = s3.AccessPoint
+	}
+	keys := aws.Credentials{AccessKeyID: s3.Credentials.AWSAccessKeyId, SecretAccessKey: s3.Credentials.AWSSecretKey}
+	if s3.RoleARN != "" {
+		keys = s3.session
+	}
+	config := []string{
 		"--credential",
-		fmt.Sprintf("\"aws_bucket_name=%s\"", s3.Bucket),
+		fmt.Sprintf("\"aws_bucket_name=%s\"", bucket),
 		"--credential",
 		fmt.Sprintf("\"aws_region=%s\"", s3.BucketRegion),
 		"--credential",
-		fmt.Sprintf("\"aws_access_key_id=%s\"", s3.Credentials.AWSAccessKeyId),
+		fmt.Sprintf("\"aws_access_key_id=%s\"", keys.AccessKeyID),
 		"--credential",
-		fmt.Sprintf("\"aws_secret_access_key=%s\"", s3.Credentials.AWSSecretKey),
+		fmt.Sprintf("\"aws_secret_access_key=%s\"", keys.SecretAccessKey),
+	}
+	if keys.SessionToken != "" {
+		config = append(config, "--credential", fmt.Sprintf("\"aws_session_token=%s\"", keys.SessionToken))
 	}
+	if s3.Endpoint != "" {
+		config = append(config, "--credential", fmt.Sprintf("\"aws_endpoint=%s\"", s3.Endpoint))
+	}
+	return config
 }
 
 func (s3 SparkS3FileStore) Packages() []string {
@@ -216,6 +304,9 @@ func NewSparkGCSFileStore(config Config) (SparkFileStore, error) {
 	if !ok {
 		return nil, fmt.Errorf("could not cast file store to *GCSFileStore")
 	}
+	if gcs.Credentials.UseDefaultCredentials() {
+		return &SparkGCSFileStore{GCSFileStore: gcs}, nil
+	}
 	serializedCredentials, err := json.Marshal(gcs.Credentials.JSON)
 	if err != nil {
 		return nil, fmt.Errorf("could not serialize the credentials")
@@ -225,11 +316,17 @@ func NewSparkGCSFileStore(config Config) (SparkFileStore, error) {
 }
 
 type SparkGCSFileStore struct {
+	// SerializedCredentials is the service account key, which is empty if
+	// the job uses Application Default Credentials.
 	SerializedCredentials []byte
 	*GCSFileStore
 }
 
 func (gcs SparkGCSFileStore) SparkConfig() []string {
+	authType := "SERVICE_ACCOUNT_JSON_KEYFILE"
+	if len(gcs.SerializedCredentials) == 0 {
+		authType = "APPLICATION_DEFAULT"
+	}
 	return []string{
 		"--spark_config",
 		"fs.gs.impl=com.google.cloud.hadoop.fs.gcs.GoogleHadoopFileSystem",
@@ -240,21 +337,22 @@ func (gcs SparkGCSFileStore) SparkConfig() []string {
 		"--spark_config",
 		"fs.gs.impl=com.google.cloud.hadoop.fs.gcs.GoogleHadoopFileSystem",
 		"--spark_config",
-		"fs.gs.auth.type=SERVICE_ACCOUNT_JSON_KEYFILE",
+		fmt.Sprintf("fs.gs.auth.type=%s", authType),
 	}
 }
 
 func (gcs SparkGCSFileStore) CredentialsConfig() []string {
-	base64Credentials := base64.StdEncoding.EncodeToString(gcs.SerializedCredentials)
-
-	return []string{
+	config := []string{
 		"--credential",
 		fmt.Sprintf("\"gcp_project_id=%s\"", gcs.Credentials.ProjectId),
 		"--credential",
 		fmt.Sprintf("\"gcp_bucket_name=%s\"", gcs.Bucket),
-		"--credential",
-		fmt.Sprintf("\"gcp_credentials=%s\"", base64Credentials),
 	}
+	if len(gcs.SerializedCredentials) == 0 {
+		return config
+	}
+	base64Credentials := base64.StdEncoding.EncodeToString(gcs.SerializedCredentials)
+	return append(config, "--credential", fmt.Sprintf("\"gcp_credentials=%s\"", base64Credentials))
 }
 
 func (gcs SparkGCSFileStore) Packages() []string {
@@ -540,7 +638,7 @@ type PythonOfflineQueries interface {
 type defaultPythonOfflineQueries struct{}
 
 func (q defaultPythonOfflineQueries) materializationCreate(schema ResourceSchema) string {
-	timestampColumn := schema.TS
+	timestampColumn := sparkTimestampColumn(schema)
 	// without timestamp, assumes each entity only has single entry
 	if schema.TS == "" {
 		return fmt.Sprintf("SELECT %s AS entity, %s AS value, 0 as ts, ROW_NUMBER() over (ORDER BY (SELECT NULL)) AS row_number FROM source_0", schema.Entity, schema.Value)
@@ -552,6 +650,15 @@ func (q defaultPythonOfflineQueries) materializationCreate(schema ResourceSchema
 		schema.Entity, schema.Value, timestampColumn, schema.Entity, timestampColumn, "source_0")
 }
 
+// sparkTimestampColumn returns the schema's timestamp column, converted to
+// UTC if its timestamps are in another time zone.
+func sparkTimestampColumn(schema ResourceSchema) string {
+	if schema.TSTimezone == "" {
+		return schema.TS
+	}
+	return fmt.Sprintf("to_utc_timestamp(%s, '%s')", schema.TS, schema.TSTimezone)
+}
+
 func featureColumnName(id ResourceID) string {
 	return fmt.Sprintf("%s__%s__%s", id.Type, id.Name, id.Variant)
 }
@@ -568,7 +675,7 @@ func (q defaultPythonOfflineQueries) trainingSetCreate(def TrainingSetDef, featu
 		if featureSchemas[i].TS == "" {
 			featureWindowQuery = fmt.Sprintf("SELECT * FROM (SELECT %s as t%d_entity, %s as %s, 0 as t%d_ts FROM source_%d) ORDER BY t%d_ts ASC", featureSchemas[i].Entity, i+1, featureSchemas[i].Value, featureColumnName, i+1, i+1, i+1)
 		} else {
-			featureWindowQuery = fmt.Sprintf("SELECT * FROM (SELECT %s as t%d_entity, %s as %s, %s as t%d_ts FROM source_%d) ORDER BY t%d_ts ASC", featureSchemas[i].Entity, i+1, featureSchemas[i].Value, featureColumnName, featureSchemas[i].TS, i+1, i+1, i+1)
+			featureWindowQuery = fmt.Sprintf("SELECT * FROM (SELECT %s as t%d_entity, %s as %s, %s as t%d_ts FROM source_%d) ORDER BY t%d_ts ASC", featureSchemas[i].Entity, i+1, featureSchemas[i].Value, featureColumnName, sparkTimestampColumn(featureSchemas[i]), i+1, i+1, i+1)
 		}
 		featureJoinQuery := fmt.Sprintf("LEFT OUTER JOIN (%s) t%d ON (t%d_entity = entity AND t%d_ts <= label_ts)", featureWindowQuery, i+1, i+1, i+1)
 		joinQueries = append(joinQueries, featureJoinQuery)
@@ -591,7 +698,7 @@ func (q defaultPythonOfflineQueries) trainingSetCreate(def TrainingSetDef, featu
 		if featureSchemas[idx].TS == "" {
 			lagWindowQuery = fmt.Sprintf("SELECT * FROM (SELECT %s as t%d_entity, %s as %s, 0 as t%d_ts FROM %s) ORDER BY t%d_ts ASC", featureSchemas[idx].Entity, curIdx, featureSchemas[idx].Value, lagColumnName, curIdx, lagSource, curIdx)
 		} else {
-			lagWindowQuery = fmt.Sprintf("SELECT * FROM (SELECT %s as t%d_entity, %s as %s, %s as t%d_ts FROM %s) ORDER BY t%d_ts ASC", featureSchemas[idx].Entity, curIdx, featureSchemas[idx].Value, lagColumnName, featureSchemas[idx].TS, curIdx, lagSource, curIdx)
+			lagWindowQuery = fmt.Sprintf("SELECT * FROM (SELECT %s as t%d_entity, %s as %s, %s as t%d_ts FROM %s) ORDER BY t%d_ts ASC", featureSchemas[idx].Entity, curIdx, featureSchemas[idx].Value, lagColumnName, sparkTimestampColumn(featureSchemas[idx]), curIdx, lagSource, curIdx)
 		}
 		lagJoinQuery := fmt.Sprintf("LEFT OUTER JOIN (%s) t%d ON (t%d_entity = entity AND DATETIME(t%d_ts, '+%f seconds') <= label_ts)", lagWindowQuery, curIdx, curIdx, curIdx, timeDeltaSeconds)
 		joinQueries = append(joinQueries, lagJoinQuery)
@@ -603,7 +710,7 @@ func (q defaultPythonOfflineQueries) trainingSetCreate(def TrainingSetDef, featu
 	if labelSchema.TS == "" {
 		labelWindowQuery = fmt.Sprintf("SELECT %s AS entity, %s AS value, 0 AS label_ts FROM source_0", labelSchema.Entity, labelSchema.Value)
 	} else {
-		labelWindowQuery = fmt.Sprintf("SELECT %s AS entity, %s AS value, %s AS label_ts FROM source_0", labelSchema.Entity, labelSchema.Value, labelSchema.TS)
+		labelWindowQuery = fmt.Sprintf("SELECT %s AS entity, %s AS value, %s AS label_ts FROM source_0", labelSchema.Entity, labelSchema.Value, sparkTimestampColumn(labelSchema))
 	}
 	labelPartitionQuery := fmt.Sprintf("(SELECT * FROM (SELECT entity, value, label_ts FROM (%s) t ) t0)", labelWindowQuery)
 	labelJoinQuery := fmt.Sprintf("%s %s", labelPartitionQuery, joinQueryString)
@@ -620,6 +727,15 @@ type SparkOfflineStore struct {
 	Store    SparkFileStore
 	Logger   *zap.SugaredLogger
 	query    *defaultPythonOfflineQueries
+	// tableFormat is the format that jobs write their output in. Iceberg
+	// tables are registered in the iceberg catalog.
+	tableFormat pc.TableFormat
+	iceberg     pc.IcebergConfig
+	// catalogConfigs are the spark configs of the metastore that sources
+	// are read from by table name. If there are none, sources are paths.
+	catalogConfigs []string
+	// retention limits the snapshots kept of Parquet materializations.
+	retention pc.RetentionConfig
 	BaseProvider
 }
 
@@ -631,6 +747,38 @@ func (store *SparkOfflineStore) Close() error {
 	return nil
 }
 
+// Vacuum removes the files of orphaned resources. Iceberg tables aren't
+// vacuumed as their files are registered in the catalog.
+func (spark *SparkOfflineStore) Vacuum(config VacuumConfig) (VacuumResult, error) {
+	if spark.tableFormat == pc.IcebergTableFormat {
+		return VacuumResult{}, &VacuumNotSupported{ProviderType: "spark with iceberg tables"}
+	}
+	result, err := vacuumFileStore(spark.Store, config, time.Now())
+	if err != nil {
+		return result, err
+	}
+	spark.Logger.Infow("Vacuumed orphaned resources", "count", len(result.Resources), "bytes", result.ReclaimedBytes, "dry_run", config.DryRun)
+	return result, nil
+}
+
+// GetChunkCheckpoint reads the feature's chunk checkpoint, which is kept in
+// the file store.
+func (spark *SparkOfflineStore) GetChunkCheckpoint(id ResourceID) (ChunkCheckpoint, error) {
+	return fileStoreChunkCheckpoints{spark.Store}.GetChunkCheckpoint(id)
+}
+
+func (spark *SparkOfflineStore) StartChunkCheckpoint(id ResourceID, plan ChunkPlan) error {
+	return fileStoreChunkCheckpoints{spark.Store}.StartChunkCheckpoint(id, plan)
+}
+
+func (spark *SparkOfflineStore) CompleteChunk(id ResourceID, chunk int64) error {
+	return fileStoreChunkCheckpoints{spark.Store}.CompleteChunk(id, chunk)
+}
+
+func (spark *SparkOfflineStore) DeleteChunkCheckpoint(id ResourceID) error {
+	return fileStoreChunkCheckpoints{spark.Store}.DeleteChunkCheckpoint(id)
+}
+
 func sparkOfflineStoreFactory(config pc.SerializedConfig) (Provider, error) {
 	sc := pc.SparkConfig{}
 	logger := logging.NewLogger("spark")
@@ -638,6 +786,20 @@ func sparkOfflineStoreFactory(config pc.SerializedConfig) (Provider, error) {
 		logger.Errorw("Invalid config to initialize spark offline store", "error", err)
 		return nil, fmt.Errorf("invalid spark config: %v", err)
 	}
+	tableFormat, err := sparkTableFormat(sc)
+	if err != nil {
+		logger.Errorw("Invalid table format for spark offline store", "error", err)
+		return nil, err
+	}
+	catalogConfigs, err := sparkCatalogConfigs(sc.Catalog)
+	if err != nil {
+		logger.Errorw("Invalid catalog for spark offline store", "error", err)
+		return nil, err
+	}
+	if err := sc.Retention.Validate(); err != nil {
+		logger.Errorw("Invalid retention for spark offline store", "error", err)
+		return nil, err
+	}
 	logger.Infow("Creating Spark executor:", "type", sc.ExecutorType)
 	exec, err := NewSparkExecutor(sc.ExecutorType, sc.ExecutorConfig, logger)
 	if err != nil {
@@ -665,10 +827,14 @@ func sparkOfflineStoreFactory(config pc.SerializedConfig) (Provider, error) {
 	logger.Info("Created Spark Offline Store")
 	queries := defaultPythonOfflineQueries{}
 	sparkOfflineStore := SparkOfflineStore{
-		Executor: exec,
-		Store:    store,
-		Logger:   logger,
-		query:    &queries,
+		Executor:       exec,
+		Store:          store,
+		Logger:         logger,
+		query:          &queries,
+		tableFormat:    tableFormat,
+		iceberg:        sc.Iceberg,
+		catalogConfigs: catalogConfigs,
+		retention:      sc.Retention,
 		BaseProvider: BaseProvider{
 			ProviderType:   "SPARK_OFFLINE",
 			ProviderConfig: config,
@@ -677,6 +843,171 @@ func sparkOfflineStoreFactory(config pc.SerializedConfig) (Provider, error) {
 	return &sparkOfflineStore, nil
 }
 
+// sparkTableFormat returns the table format of the config, which is Parquet
+// if it isn't set.
+func sparkTableFormat(sc pc.SparkConfig) (pc.TableFormat, error) {
+	switch sc.TableFormat {
+	case "", pc.ParquetTableFormat:
+		return pc.ParquetTableFormat, nil
+	case pc.IcebergTableFormat:
+		if sc.Iceberg.Catalog == "" || sc.Iceberg.Namespace == "" {
+			return "", fmt.Errorf("an iceberg catalog and namespace are required to write iceberg tables")
+		}
+		return pc.IcebergTableFormat, nil
+	case pc.DeltaTableFormat:
+		return pc.DeltaTableFormat, nil
+	default:
+		return "", fmt.Errorf("unsupported table format: %s", sc.TableFormat)
+	}
+}
+
+// writesVersionedTables is true if jobs write to versioned tables instead of
+// new Parquet directories.
+func (spark *SparkOfflineStore) writesVersionedTables() bool {
+	return spark.tableFormat == pc.IcebergTableFormat || spark.tableFormat == pc.DeltaTableFormat
+}
+
+// icebergTableName is the catalog name of the table that a resource is
+// written to. Names are lowercased for catalogs like Glue that don't
+// preserve case, so they end with a hash of the resource to keep them unique.
+func (spark *SparkOfflineStore) icebergTableName(id ResourceID) string {
+	resource := fmt.Sprintf("%s__%s__%s", id.Type, id.Name, id.Variant)
+	sanitized := strings.Map(func(r rune) rune {
+		if (r >= 'a' && r <= 'z') || (r >= '0' && r <= '9') || r == '_' {
+			return r
+		}
+		return '_'
+	}, strings.ToLower(resource))
+	hash := sha256.Sum256([]byte(resource))
+	return fmt.Sprintf("%s.%s.featureform_%s_%s", spark.iceberg.Catalog, spark.iceberg.Namespace, sanitized, hex.EncodeToString(hash[:4]))
+}
+
+// tableFormatArgs are the arguments to add to a job that writes a resource,
+// so that it's written in the store's table format. Versioned tables are
+// created by the first job and overwritten by updates.
+func (spark *SparkOfflineStore) tableFormatArgs(id ResourceID, isUpdate bool) []string {
+	if !spark.writesVersionedTables() {
+		return nil
+	}
+	writeMode := "create"
+	if isUpdate {
+		writeMode = "overwrite"
+	}
+	args := []string{"--table_format", string(spark.tableFormat), "--write_mode", writeMode}
+	if spark.tableFormat == pc.IcebergTableFormat {
+		args = append(args, "--iceberg_table", spark.icebergTableName(id))
+	}
+	return args
+}
+
+// jobArgs are the arguments to add to a job that writes a resource: its
+// table format, and the catalog that its sources are read from.
+func (spark *SparkOfflineStore) jobArgs(id ResourceID, isUpdate bool) []string {
+	args := spark.tableFormatArgs(id, isUpdate)
+	for _, config := range spark.catalogConfigs {
+		args = append(args, "--catalog_config", fmt.Sprintf("\"%s\"", config))
+	}
+	return args
+}
+
+// sparkCatalogConfigs returns the spark configs that a job's session reads
+// tables from a catalog with. The Glue client has to be on the cluster's
+// classpath, as it is on EMR.
+func sparkCatalogConfigs(catalog pc.CatalogConfig) ([]string, error) {
+	switch catalog.Type {
+	case "":
+		return nil, nil
+	case pc.HiveCatalog:
+		if catalog.MetastoreURI == "" {
+			return nil, fmt.Errorf("a metastore uri is required to read tables from a hive catalog")
+		}
+		return []string{
+			"spark.sql.catalogImplementation=hive",
+			fmt.Sprintf("spark.hadoop.hive.metastore.uris=%s", catalog.MetastoreURI),
+		}, nil
+	case pc.GlueCatalog:
+		configs := []string{
+			"spark.sql.catalogImplementation=hive",
+			"spark.hadoop.hive.metastore.client.factory.class=com.amazonaws.glue.catalog.metastore.AWSGlueDataCatalogHiveClientFactory",
+		}
+		if catalog.GlueRegion != "" {
+			configs = append(configs, fmt.Sprintf("spark.hadoop.aws.region=%s", catalog.GlueRegion))
+		}
+		if catalog.GlueCatalogID != "" {
+			configs = append(configs, fmt.Sprintf("spark.hadoop.hive.metastore.glue.catalogid=%s", catalog.GlueCatalogID))
+		}
+		return configs, nil
+	default:
+		return nil, fmt.Errorf("unsupported catalog type: %s", catalog.Type)
+	}
+}
+
+// catalogTableName matches the names of tables in a catalog, with an
+// optional catalog before the database: "db.table" or "catalog.db.table".
+var catalogTableName = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*(\.[A-Za-z_][A-Za-z0-9_]*){1,2}$`)
+
+// catalogSourcePath returns the source that a job reads a catalog table
+// from, if the store has a catalog and name is a table's name instead of a
+// path. Names of Parquet and CSV files are paths.
+func (spark *SparkOfflineStore) catalogSourcePath(name string) (string, bool) {
+	if len(spark.catalogConfigs) == 0 || !catalogTableName.MatchString(name) {
+		return "", false
+	}
+	extension := strings.TrimPrefix(strings.ToLower(filepath.Ext(name)), ".")
+	for _, fileType := range []FileType{Parquet, CSV, ORC, Avro} {
+		if FileType(extension) == fileType {
+			return "", false
+		}
+	}
+	return fmt.Sprintf("catalog:%s", name), true
+}
+
+// isFormatSourcePath is true for ORC and Avro sources, which are either a
+// file with the format's extension or a directory of them in the form
+// "<format>:<remote location>". They're read as they are rather than from
+// their newest Parquet file.
+func isFormatSourcePath(path string) bool {
+	for _, format := range []FileType{ORC, Avro} {
+		if strings.HasPrefix(path, fmt.Sprintf("%s:", format)) || format.Matches(strings.ToLower(path)) {
+			return true
+		}
+	}
+	return false
+}
+
+// versionedTable returns the table that a resource is written to.
+func (spark *SparkOfflineStore) versionedTable(id ResourceID) versionedTable {
+	location := spark.Store.PathWithPrefix(ResourcePrefix(id), false)
+	if spark.tableFormat == pc.DeltaTableFormat {
+		return newDeltaTable(spark.Store, location)
+	}
+	return newIcebergTable(spark.Store, location)
+}
+
+// latestSourcePath returns the path that a job reads a source table from.
+// Versioned tables are read at their current version and ORC and Avro
+// sources as they are; other tables are read from their newest Parquet file.
+func (spark *SparkOfflineStore) latestSourcePath(sourceTable string) (string, error) {
+	if isVersionedSourcePath(sourceTable) || isFormatSourcePath(sourceTable) {
+		return sourceTable, nil
+	}
+	if catalogSource, ok := spark.catalogSourcePath(sourceTable); ok {
+		return catalogSource, nil
+	}
+	table, err := openVersionedTable(spark.Store, sourceTable)
+	if err != nil {
+		return "", err
+	}
+	if table != nil {
+		return versionedSourcePath(spark.Store, table)
+	}
+	latestPath, err := spark.Store.NewestFileOfType(sourceTable, Parquet)
+	if err != nil {
+		return "", err
+	}
+	return spark.Store.PathWithPrefix(latestPath, true), nil
+}
+
 type SparkExecutor interface {
 	RunSparkJob(args []string, store SparkFileStore) error
 	InitializeExecutor(store SparkFileStore) error
@@ -902,6 +1233,12 @@ func NewSparkExecutor(execType pc.SparkExecutorType, config pc.SparkExecutorConf
 			return nil, fmt.Errorf("cannot convert config into 'EMRConfig'")
 		}
 		return NewEMRExecutor(*emrConfig, logger)
+	case pc.EMRServerless:
+		emrServerlessConfig, ok := config.(*pc.EMRServerlessConfig)
+		if !ok {
+			return nil, fmt.Errorf("cannot convert config into 'EMRServerlessConfig'")
+		}
+		return NewEMRServerlessExecutor(*emrServerlessConfig, logger)
 	case pc.Databricks:
 		databricksConfig, ok := config.(*pc.DatabricksConfig)
 		if !ok {
@@ -1231,6 +1568,7 @@ func (spark *SparkOfflineStore) sqlTransformation(config TransformationConfig, i
 
 	spark.Logger.Debugw("Running SQL transformation", config)
 	sparkArgs := spark.Executor.SparkSubmitArgs(transformationDestination, updatedQuery, sources, JobType(Transform), spark.Store)
+	sparkArgs = append(sparkArgs, spark.jobArgs(config.TargetTableID, isUpdate)...)
 	if err := spark.Executor.RunSparkJob(sparkArgs, spark.Store); err != nil {
 		spark.Logger.Errorw("spark submit job for transformation failed to run", config.TargetTableID, err)
 		return fmt.Errorf("spark submit job for transformation %v failed to run: %v", config.TargetTableID, err)
@@ -1281,6 +1619,7 @@ func (spark *SparkOfflineStore) dfTransformation(config TransformationConfig, is
 		spark.Logger.Errorw("Problem creating spark dataframe arguments", err)
 		return fmt.Errorf("error with getting df arguments %v", sparkArgs)
 	}
+	sparkArgs = append(sparkArgs, spark.jobArgs(config.TargetTableID, isUpdate)...)
 	spark.Logger.Debugw("Running DF transformation")
 	if err := spark.Executor.RunSparkJob(sparkArgs, spark.Store); err != nil {
 		spark.Logger.Errorw("Error running Spark dataframe job", "error", err)
@@ -1333,6 +1672,9 @@ func (spark *SparkOfflineStore) updateQuery(query string, mapping []SourceMappin
 }
 
 func (spark *SparkOfflineStore) getSourcePath(path string) (string, error) {
+	if strings.HasPrefix(path, kafkaSourcePrefix) {
+		return path, nil
+	}
 	fileType, fileName, fileVariant := spark.getResourceInformationFromFilePath(path)
 
 	var filePath string
@@ -1344,10 +1686,16 @@ func (spark *SparkOfflineStore) getSourcePath(path string) (string, error) {
 			return "", fmt.Errorf("could not get the primary table for {%v} because %s", fileResourceId, err)
 		}
 		filePath = fileTable.GetName()
+		if catalogSource, ok := spark.catalogSourcePath(filePath); ok {
+			return catalogSource, nil
+		}
 		return filePath, nil
 	} else if fileType == "transformation" {
 		fileResourceId := ResourceID{Name: fileName, Variant: fileVariant, Type: Transformation}
 
+		if spark.writesVersionedTables() {
+			return versionedSourcePath(spark.Store, spark.versionedTable(fileResourceId))
+		}
 		transformationPath, err := spark.Store.NewestFileOfType(spark.Store.PathWithPrefix(ResourcePrefix(fileResourceId), false), Parquet)
 		if err != nil || transformationPath == "" {
 			return "", fmt.Errorf("could not get transformation file path: %v", err)
@@ -1519,12 +1867,12 @@ func blobSparkMaterialization(id ResourceID, spark *SparkOfflineStore, isUpdate
 	}
 	materializationQuery := spark.query.materializationCreate(sparkResourceTable.schema)
 
-	latestSourcePath, err := spark.Store.NewestFileOfType(sparkResourceTable.schema.SourceTable, Parquet)
-	sourcePath := spark.Store.PathWithPrefix(latestSourcePath, true)
+	sourcePath, err := spark.latestSourcePath(sparkResourceTable.schema.SourceTable)
 	if err != nil {
 		return nil, fmt.Errorf("could not get latest source file: %v", err)
 	}
 	sparkArgs := spark.Executor.SparkSubmitArgs(destinationPath, materializationQuery, []string{sourcePath}, Materialize, spark.Store)
+	sparkArgs = append(sparkArgs, spark.jobArgs(materializationID, isUpdate)...)
 	spark.Logger.Debugw("Creating materialization", "id", id)
 	if err := spark.Executor.RunSparkJob(sparkArgs, spark.Store); err != nil {
 		spark.Logger.Errorw("Spark submit job failed to run", "error", err)
@@ -1535,7 +1883,15 @@ func blobSparkMaterialization(id ResourceID, spark *SparkOfflineStore, isUpdate
 		return nil, fmt.Errorf("could not get newest materialization file: %v", err)
 	}
 	spark.Logger.Debugw("Successfully created materialization", "id", id)
-	return &FileStoreMaterialization{materializationID, spark.Store, key}, nil
+	if spark.writesVersionedTables() {
+		return newTableMaterialization(materializationID, spark.Store, spark.versionedTable(materializationID))
+	}
+	if deleted, err := applyMaterializationRetention(spark.Store, materializationID, spark.retention); err != nil {
+		spark.Logger.Errorw("Could not delete expired materialization snapshots", "id", id, "error", err)
+	} else if deleted > 0 {
+		spark.Logger.Infow("Deleted expired materialization snapshots", "id", id, "count", deleted)
+	}
+	return &FileStoreMaterialization{materializationID, spark.Store, key, nil}, nil
 }
 
 func (spark *SparkOfflineStore) CreateMaterialization(id ResourceID) (Materialization, error) {
@@ -1543,15 +1899,49 @@ func (spark *SparkOfflineStore) CreateMaterialization(id ResourceID) (Materializ
 }
 
 func (spark *SparkOfflineStore) GetMaterialization(id MaterializationID) (Materialization, error) {
+	if spark.writesVersionedTables() {
+		materializationID, err := spark.materializationResourceID(id)
+		if err != nil {
+			return nil, err
+		}
+		return newTableMaterialization(materializationID, spark.Store, spark.versionedTable(materializationID))
+	}
 	return fileStoreGetMaterialization(id, spark.Store, spark.Logger)
 }
 
+func (spark *SparkOfflineStore) GetFeatureMaterialization(id ResourceID) (Materialization, error) {
+	return spark.GetMaterialization(fileStoreMaterializationID(id))
+}
+
 func (spark *SparkOfflineStore) UpdateMaterialization(id ResourceID) (Materialization, error) {
 	return blobSparkMaterialization(id, spark, true)
 }
 
+// DeleteMaterialization can't delete Iceberg materializations, since their
+// tables are registered in a catalog that the store can't change. Delta
+// materializations are deleted with their whole table.
 func (spark *SparkOfflineStore) DeleteMaterialization(id MaterializationID) error {
-	return fileStoreDeleteMaterialization(id, spark.Store, spark.Logger)
+	switch spark.tableFormat {
+	case pc.IcebergTableFormat:
+		return fmt.Errorf("materialization %s is an iceberg table, which can't be deleted by the spark offline store", id)
+	case pc.DeltaTableFormat:
+		materializationID, err := spark.materializationResourceID(id)
+		if err != nil {
+			return err
+		}
+		return spark.Store.DeleteAll(spark.versionedTable(materializationID).Location())
+	default:
+		return fileStoreDeleteMaterialization(id, spark.Store, spark.Logger)
+	}
+}
+
+func (spark *SparkOfflineStore) materializationResourceID(id MaterializationID) (ResourceID, error) {
+	s := strings.Split(string(id), "/")
+	if len(s) != 3 {
+		spark.Logger.Errorw("Invalid materialization", "id", id)
+		return ResourceID{}, fmt.Errorf("invalid materialization id: %v", id)
+	}
+	return ResourceID{s[1], s[2], FeatureMaterialization}, nil
 }
 
 func (spark *SparkOfflineStore) registeredResourceSchema(id ResourceID) (ResourceSchema, error) {
@@ -1595,12 +1985,11 @@ func sparkTrainingSet(def TrainingSetDef, spark *SparkOfflineStore, isUpdate boo
 		spark.Logger.Errorw("Could not get schema of label in spark store", "label", def.Label, "error", err)
 		return fmt.Errorf("could not get schema of label %s: %v", def.Label, err)
 	}
-	latestLabelPath, err := spark.Store.NewestFileOfType(labelSchema.SourceTable, Parquet)
+	labelPath, err := spark.latestSourcePath(labelSchema.SourceTable)
 	if err != nil {
 		spark.Logger.Errorw("Could not get latest label file", "label", def.Label, "error", err)
 		return fmt.Errorf("could not get latest label file: %v", err)
 	}
-	labelPath := spark.Store.PathWithPrefix(latestLabelPath, true)
 	sourcePaths = append(sourcePaths, labelPath)
 	for _, feature := range def.Features {
 		featureSchema, err := spark.registeredResourceSchema(feature)
@@ -1608,17 +1997,17 @@ func sparkTrainingSet(def TrainingSetDef, spark *SparkOfflineStore, isUpdate boo
 			spark.Logger.Errorw("Could not get schema of feature in spark store", "feature", feature, "error", err)
 			return fmt.Errorf("could not get schema of feature %s: %v", feature, err)
 		}
-		latestFeaturePath, err := spark.Store.NewestFileOfType(featureSchema.SourceTable, Parquet)
+		featurePath, err := spark.latestSourcePath(featureSchema.SourceTable)
 		if err != nil {
 			spark.Logger.Errorw("Could not get latest feature file", "feature", feature, "error", err)
 			return fmt.Errorf("could not get latest feature file: %v", err)
 		}
-		featurePath := spark.Store.PathWithPrefix(latestFeaturePath, true)
 		sourcePaths = append(sourcePaths, featurePath)
 		featureSchemas = append(featureSchemas, featureSchema)
 	}
 	trainingSetQuery := spark.query.trainingSetCreate(def, featureSchemas, labelSchema)
 	sparkArgs := spark.Executor.SparkSubmitArgs(destinationPath, trainingSetQuery, sourcePaths, CreateTrainingSet, spark.Store)
+	sparkArgs = append(sparkArgs, spark.jobArgs(def.ID, isUpdate)...)
 	spark.Logger.Debugw("Creating training set", "definition", def)
 	if err := spark.Executor.RunSparkJob(sparkArgs, spark.Store); err != nil {
 		spark.Logger.Errorw("Spark submit training set job failed to run", "definition", def.ID, "error", err)
@@ -1646,9 +2035,30 @@ func (spark *SparkOfflineStore) UpdateTrainingSet(def TrainingSetDef) error {
 }
 
 func (spark *SparkOfflineStore) GetTrainingSet(id ResourceID) (TrainingSetIterator, error) {
+	if spark.writesVersionedTables() {
+		return spark.versionedTrainingSet(id)
+	}
 	return fileStoreGetTrainingSet(id, spark.Store, spark.Logger)
 }
 
+// versionedTrainingSet reads the current version of a training set's table.
+func (spark *SparkOfflineStore) versionedTrainingSet(id ResourceID) (TrainingSetIterator, error) {
+	if err := id.check(TrainingSet); err != nil {
+		spark.Logger.Errorw("Resource is not of type training set", "error", err)
+		return nil, fmt.Errorf("resource is not training set: %w", err)
+	}
+	table := spark.versionedTable(id)
+	files, err := table.CurrentFiles()
+	if err != nil {
+		return nil, fmt.Errorf("could not get training set: %w", err)
+	}
+	iterator, err := serveTableFiles(spark.Store, files)
+	if err != nil {
+		return nil, fmt.Errorf("could not serve training set: %w", err)
+	}
+	return &FileStoreTrainingSet{id: id, store: spark.Store, key: table.Location(), iter: iterator}, nil
+}
+
 func sanitizeSparkSQL(name string) string {
 	return name
 }
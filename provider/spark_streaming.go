@@ -0,0 +1,282 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package provider
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/databricks/databricks-sdk-go/service/jobs"
+	"github.com/google/uuid"
+)
+
+// streamingStopPollInterval is how often a stopped streaming job is checked
+// until it has finished, so that another can be started in its place.
+var streamingStopPollInterval = 5 * time.Second
+
+// StreamingSparkExecutor is implemented by executors that can run Spark jobs
+// without waiting for them to finish. Args are the arguments of the Spark
+// script, which the executor runs the same way as in RunSparkJob.
+type StreamingSparkExecutor interface {
+	SparkExecutor
+	StartSparkJob(args []string, store SparkFileStore) (string, error)
+	StopSparkJob(jobId string) error
+	SparkJobStatus(jobId string) (StreamingJobStatus, error)
+}
+
+// streamingJobRecord is kept in the file store so that a transformation's
+// streaming job can be stopped or checked on by any coordinator.
+type streamingJobRecord struct {
+	JobId string
+}
+
+func streamingJobRecordPath(id ResourceID) string {
+	return fmt.Sprintf("featureform/StreamingJobs/%s/%s.json", id.Name, id.Variant)
+}
+
+func (spark *SparkOfflineStore) streamingExecutor() (StreamingSparkExecutor, error) {
+	executor, ok := spark.Executor.(StreamingSparkExecutor)
+	if !ok {
+		return nil, &StreamingNotSupported{ProviderType: fmt.Sprintf("%s with a %T executor", spark.Type(), spark.Executor)}
+	}
+	return executor, nil
+}
+
+func (spark *SparkOfflineStore) streamingJob(id ResourceID) (streamingJobRecord, error) {
+	path := spark.Store.PathWithPrefix(streamingJobRecordPath(id), false)
+	exists, err := spark.Store.Exists(path)
+	if err != nil {
+		return streamingJobRecord{}, err
+	}
+	if !exists {
+		return streamingJobRecord{}, &StreamingJobNotFound{id}
+	}
+	serialized, err := spark.Store.Read(path)
+	if err != nil {
+		return streamingJobRecord{}, err
+	}
+	var record streamingJobRecord
+	if err := json.Unmarshal(serialized, &record); err != nil {
+		return streamingJobRecord{}, fmt.Errorf("could not parse streaming job of %v: %w", id, err)
+	}
+	return record, nil
+}
+
+// StartStreamingTransformation starts a job that runs the transformation's
+// query over its sources as they're appended to. Each micro-batch is appended
+// to the transformation's table and written to its online sinks, so a batch
+// that's retried after a failure can be written more than once.
+func (spark *SparkOfflineStore) StartStreamingTransformation(config TransformationConfig) error {
+	executor, err := spark.streamingExecutor()
+	if err != nil {
+		return err
+	}
+	if config.Type != StreamingTransformation {
+		return fmt.Errorf("transformation %v is not a streaming transformation", config.TargetTableID)
+	}
+	if spark.writesVersionedTables() {
+		return fmt.Errorf("streaming transformations can only be written as parquet, not %s", spark.tableFormat)
+	}
+	status, err := spark.StreamingTransformationStatus(config.TargetTableID)
+	if _, notFound := err.(*StreamingJobNotFound); err != nil && !notFound {
+		return err
+	}
+	if err == nil && status.Active() {
+		return fmt.Errorf("streaming transformation %v is already running", config.TargetTableID)
+	}
+	query, sources, err := spark.updateQuery(config.Query, config.SourceMapping)
+	if err != nil {
+		return err
+	}
+	args, err := spark.streamingArgs(config, query, sources)
+	if err != nil {
+		return err
+	}
+	spark.Logger.Debugw("Starting streaming transformation", "id", config.TargetTableID)
+	jobId, err := executor.StartSparkJob(args, spark.Store)
+	if err != nil {
+		return fmt.Errorf("could not start streaming transformation %v: %w", config.TargetTableID, err)
+	}
+	serialized, err := json.Marshal(streamingJobRecord{JobId: jobId})
+	if err != nil {
+		return err
+	}
+	return spark.Store.Write(spark.Store.PathWithPrefix(streamingJobRecordPath(config.TargetTableID), false), serialized)
+}
+
+func (spark *SparkOfflineStore) streamingArgs(config TransformationConfig, query string, sources []string) ([]string, error) {
+	args := []string{
+		"stream",
+		"--output_uri",
+		spark.Store.PathWithPrefix(ResourcePrefix(config.TargetTableID), true),
+		"--sql_query",
+		query,
+		"--store_type",
+		spark.Store.Type(),
+	}
+	if config.Streaming.Trigger != "" {
+		args = append(args, "--trigger", config.Streaming.Trigger)
+	}
+	if len(config.Streaming.OnlineSinks) > 0 {
+		sinks, err := streamingOnlineSinksArg(config.Streaming.OnlineSinks)
+		if err != nil {
+			return nil, err
+		}
+		args = append(args, "--online_sinks", sinks)
+	}
+	args = append(args, removeEspaceCharacters(spark.Store.SparkConfig())...)
+	args = append(args, removeEspaceCharacters(spark.Store.CredentialsConfig())...)
+	args = append(args, spark.jobArgs(config.TargetTableID, false)...)
+	args = append(args, "--source_list")
+	return append(args, sources...), nil
+}
+
+// StopStreamingTransformation stops the transformation's job and waits for
+// it to finish. Its output and checkpoint are kept, so a job that's started
+// again carries on from where this one stopped.
+func (spark *SparkOfflineStore) StopStreamingTransformation(id ResourceID) error {
+	executor, err := spark.streamingExecutor()
+	if err != nil {
+		return err
+	}
+	record, err := spark.streamingJob(id)
+	if err != nil {
+		return err
+	}
+	if err := executor.StopSparkJob(record.JobId); err != nil {
+		return fmt.Errorf("could not stop streaming transformation %v: %w", id, err)
+	}
+	for {
+		status, err := executor.SparkJobStatus(record.JobId)
+		if err != nil {
+			return err
+		}
+		if !status.Active() {
+			return nil
+		}
+		time.Sleep(streamingStopPollInterval)
+	}
+}
+
+func (spark *SparkOfflineStore) StreamingTransformationStatus(id ResourceID) (StreamingJobStatus, error) {
+	executor, err := spark.streamingExecutor()
+	if err != nil {
+		return StreamingJobStatus{}, err
+	}
+	record, err := spark.streamingJob(id)
+	if err != nil {
+		return StreamingJobStatus{}, err
+	}
+	return executor.SparkJobStatus(record.JobId)
+}
+
+// StreamingCheckpoint reads the latest offsets file of the job's checkpoint.
+// Spark writes a file per batch, named by its batch id, with a version line
+// and a line of metadata before a line for each of the job's sources.
+func (spark *SparkOfflineStore) StreamingCheckpoint(id ResourceID) (StreamingCheckpoint, error) {
+	prefix := spark.Store.PathWithPrefix(fmt.Sprintf("%s/_checkpoint/offsets/", ResourcePrefix(id)), false)
+	files, err := spark.Store.ListFiles(prefix)
+	if err != nil {
+		return StreamingCheckpoint{}, fmt.Errorf("could not list checkpoint of %v: %w", id, err)
+	}
+	latest, latestKey := int64(-1), ""
+	for _, file := range files {
+		batch, err := strconv.ParseInt(strings.TrimPrefix(file.Key, prefix), 10, 64)
+		if err != nil {
+			continue
+		}
+		if batch > latest {
+			latest, latestKey = batch, file.Key
+		}
+	}
+	if latestKey == "" {
+		return StreamingCheckpoint{}, &StreamingCheckpointNotFound{id}
+	}
+	serialized, err := spark.Store.Read(latestKey)
+	if err != nil {
+		return StreamingCheckpoint{}, err
+	}
+	lines := strings.Split(strings.TrimSpace(string(serialized)), "\n")
+	if len(lines) < 2 {
+		return StreamingCheckpoint{}, fmt.Errorf("invalid checkpoint offsets file %s", latestKey)
+	}
+	checkpoint := StreamingCheckpoint{Batch: latest}
+	for _, line := range lines[2:] {
+		checkpoint.Sources = append(checkpoint.Sources, json.RawMessage(line))
+	}
+	return checkpoint, nil
+}
+
+func (db *DatabricksExecutor) StartSparkJob(args []string, store SparkFileStore) (string, error) {
+	ctx := context.Background()
+	id := uuid.New().String()
+	job, err := db.client.Jobs.Create(ctx, jobs.CreateJob{
+		Name: fmt.Sprintf("featureform-job-%s", id),
+		Tasks: []jobs.JobTaskSettings{
+			{
+				TaskKey:           fmt.Sprintf("featureform-task-%s", id),
+				ExistingClusterId: db.cluster,
+				SparkPythonTask: &jobs.SparkPythonTask{
+					PythonFile: db.PythonFileURI(store),
+					Parameters: args,
+				},
+			},
+		},
+	})
+	if err != nil {
+		return "", fmt.Errorf("error creating job: %v", err)
+	}
+	run, err := db.client.Jobs.RunNow(ctx, jobs.RunNow{JobId: job.JobId})
+	if err != nil {
+		return "", fmt.Errorf("error running job '%v': %v", job.JobId, err)
+	}
+	return strconv.FormatInt(run.RunId, 10), nil
+}
+
+func (db *DatabricksExecutor) StopSparkJob(jobId string) error {
+	runId, err := strconv.ParseInt(jobId, 10, 64)
+	if err != nil {
+		return fmt.Errorf("invalid databricks run id '%s': %v", jobId, err)
+	}
+	return db.client.Jobs.CancelRun(context.Background(), jobs.CancelRun{RunId: runId})
+}
+
+func (db *DatabricksExecutor) SparkJobStatus(jobId string) (StreamingJobStatus, error) {
+	runId, err := strconv.ParseInt(jobId, 10, 64)
+	if err != nil {
+		return StreamingJobStatus{}, fmt.Errorf("invalid databricks run id '%s': %v", jobId, err)
+	}
+	run, err := db.client.Jobs.GetRun(context.Background(), jobs.GetRunRequest{RunId: runId})
+	if err != nil {
+		return StreamingJobStatus{}, err
+	}
+	if run.State == nil {
+		return StreamingJobStatus{State: StreamingJobPending}, nil
+	}
+	return databricksJobStatus(*run.State), nil
+}
+
+func databricksJobStatus(state jobs.RunState) StreamingJobStatus {
+	status := StreamingJobStatus{Message: state.StateMessage}
+	switch state.LifeCycleState {
+	case jobs.RunLifeCycleStatePending, jobs.RunLifeCycleStateBlocked, jobs.RunLifeCycleStateWaitingForRetry:
+		status.State = StreamingJobPending
+	case jobs.RunLifeCycleStateRunning, jobs.RunLifeCycleStateTerminating:
+		status.State = StreamingJobRunning
+	case jobs.RunLifeCycleStateTerminated:
+		if state.ResultState == jobs.RunResultStateSuccess || state.ResultState == jobs.RunResultStateCanceled {
+			status.State = StreamingJobStopped
+		} else {
+			status.State = StreamingJobFailed
+		}
+	default:
+		status.State = StreamingJobFailed
+	}
+	return status
+}
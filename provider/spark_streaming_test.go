@@ -0,0 +1,193 @@
+package provider
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/databricks/databricks-sdk-go/service/jobs"
+	pc "github.com/featureform/provider/provider_config"
+	pt "github.com/featureform/provider/provider_type"
+	"go.uber.org/zap"
+)
+
+func newTestSparkLocalFileStore(t *testing.T) SparkFileStore {
+	config := pc.LocalFileStoreConfig{DirPath: fmt.Sprintf("file:///%s", t.TempDir())}
+	serialized, err := config.Serialize()
+	if err != nil {
+		t.Fatalf("Failed to serialize file store config: %s", err)
+	}
+	store, err := NewSparkLocalFileStore(serialized)
+	if err != nil {
+		t.Fatalf("Failed to create file store: %s", err)
+	}
+	return store
+}
+
+type mockStreamingExecutor struct {
+	SparkExecutor
+	args   [][]string
+	states map[string]StreamingJobState
+}
+
+func (e *mockStreamingExecutor) StartSparkJob(args []string, store SparkFileStore) (string, error) {
+	e.args = append(e.args, args)
+	jobId := fmt.Sprintf("job-%d", len(e.args))
+	e.states[jobId] = StreamingJobRunning
+	return jobId, nil
+}
+
+func (e *mockStreamingExecutor) StopSparkJob(jobId string) error {
+	e.states[jobId] = StreamingJobStopped
+	return nil
+}
+
+func (e *mockStreamingExecutor) SparkJobStatus(jobId string) (StreamingJobStatus, error) {
+	return StreamingJobStatus{State: e.states[jobId]}, nil
+}
+
+func TestSparkStreamingTransformationLifecycle(t *testing.T) {
+	executor := &mockStreamingExecutor{states: map[string]StreamingJobState{}}
+	spark := &SparkOfflineStore{
+		Executor:    executor,
+		Store:       newTestSparkLocalFileStore(t),
+		Logger:      zap.NewNop().Sugar(),
+		tableFormat: pc.ParquetTableFormat,
+	}
+	id := ResourceID{Name: "clicks", Variant: "stream", Type: Transformation}
+	if _, err := spark.StreamingTransformationStatus(id); err == nil {
+		t.Fatalf("Expected an error for a transformation that hasn't been started")
+	}
+	redis := pc.RedisConfig{Addr: "localhost:6379"}
+	config := TransformationConfig{
+		Type:          StreamingTransformation,
+		TargetTableID: id,
+		Query:         "SELECT user, COUNT(*) AS clicks FROM events GROUP BY user",
+		Streaming: StreamingConfig{
+			Trigger: "1 minute",
+			OnlineSinks: []StreamingOnlineSink{
+				{Type: pt.RedisOnline, Config: redis.Serialized(), Feature: "clicks", Variant: "v1", Entity: "user", Value: "clicks"},
+			},
+		},
+	}
+	if err := spark.StartStreamingTransformation(config); err != nil {
+		t.Fatalf("Failed to start streaming transformation: %s", err)
+	}
+	args := strings.Join(executor.args[0], " ")
+	for _, expected := range []string{"stream ", "--trigger 1 minute", "--online_sinks ", "--sql_query " + config.Query} {
+		if !strings.Contains(args, expected) {
+			t.Fatalf("Expected %q in job args: %s", expected, args)
+		}
+	}
+	if err := spark.StartStreamingTransformation(config); err == nil {
+		t.Fatalf("Expected a running transformation not to be started again")
+	}
+	if err := spark.StopStreamingTransformation(id); err != nil {
+		t.Fatalf("Failed to stop streaming transformation: %s", err)
+	}
+	status, err := spark.StreamingTransformationStatus(id)
+	if err != nil || status.State != StreamingJobStopped {
+		t.Fatalf("Expected stopped job, got %v: %v", status, err)
+	}
+	if err := spark.StartStreamingTransformation(config); err != nil {
+		t.Fatalf("Failed to restart streaming transformation: %s", err)
+	}
+	if status, err := spark.StreamingTransformationStatus(id); err != nil || status.State != StreamingJobRunning {
+		t.Fatalf("Expected restarted job to be running, got %v: %v", status, err)
+	}
+}
+
+func TestSparkStreamingNotSupported(t *testing.T) {
+	spark := &SparkOfflineStore{Executor: &EMRExecutor{}, Logger: zap.NewNop().Sugar()}
+	err := spark.StartStreamingTransformation(TransformationConfig{Type: StreamingTransformation})
+	if _, ok := err.(*StreamingNotSupported); !ok {
+		t.Fatalf("Expected StreamingNotSupported, got %v", err)
+	}
+}
+
+func TestStreamingOnlineSinksArg(t *testing.T) {
+	redis := pc.RedisConfig{Addr: "localhost:6379", Password: "pass"}
+	sinks := []StreamingOnlineSink{{Type: pt.RedisOnline, Config: redis.Serialized(), Feature: "clicks", Variant: "v1", Entity: "user", Value: "clicks"}}
+	arg, err := streamingOnlineSinksArg(sinks)
+	if err != nil {
+		t.Fatalf("Failed to encode sinks: %s", err)
+	}
+	decoded, err := base64.StdEncoding.DecodeString(arg)
+	if err != nil {
+		t.Fatalf("Failed to decode sinks: %s", err)
+	}
+	var encoded []streamingRedisSink
+	if err := json.Unmarshal(decoded, &encoded); err != nil {
+		t.Fatalf("Failed to parse sinks: %s", err)
+	}
+	table := redisOnlineTable{key: redisTableKey{"Featureform_table__", "clicks", "v1"}}
+	if len(encoded) != 1 || encoded[0].Key != table.key.String() || encoded[0].UpdatedKey != table.updatedKey() {
+		t.Fatalf("Sink isn't written to the feature's table: %+v", encoded)
+	}
+	sinks[0].Type = pt.DynamoDBOnline
+	if _, err := streamingOnlineSinksArg(sinks); err == nil {
+		t.Fatalf("Expected non-Redis sinks to be rejected")
+	}
+}
+
+func TestDatabricksJobStatus(t *testing.T) {
+	tests := []struct {
+		state    jobs.RunState
+		expected StreamingJobState
+	}{
+		{jobs.RunState{LifeCycleState: jobs.RunLifeCycleStatePending}, StreamingJobPending},
+		{jobs.RunState{LifeCycleState: jobs.RunLifeCycleStateRunning}, StreamingJobRunning},
+		{jobs.RunState{LifeCycleState: jobs.RunLifeCycleStateTerminated, ResultState: jobs.RunResultStateCanceled}, StreamingJobStopped},
+		{jobs.RunState{LifeCycleState: jobs.RunLifeCycleStateTerminated, ResultState: jobs.RunResultStateFailed}, StreamingJobFailed},
+		{jobs.RunState{LifeCycleState: jobs.RunLifeCycleStateInternalError}, StreamingJobFailed},
+	}
+	for _, test := range tests {
+		if status := databricksJobStatus(test.state); status.State != test.expected {
+			t.Errorf("Expected %s for %+v, got %s", test.expected, test.state, status.State)
+		}
+	}
+}
+
+func TestSparkStreamingCheckpoint(t *testing.T) {
+	// Keys listed from the store only match its paths when they're relative.
+	config := pc.LocalFileStoreConfig{DirPath: fmt.Sprintf("file://%s/", t.TempDir())}
+	serialized, err := config.Serialize()
+	if err != nil {
+		t.Fatalf("Failed to serialize file store config: %s", err)
+	}
+	store, err := NewSparkLocalFileStore(serialized)
+	if err != nil {
+		t.Fatalf("Failed to create file store: %s", err)
+	}
+	spark := &SparkOfflineStore{Store: store, Logger: zap.NewNop().Sugar()}
+	id := ResourceID{Name: "clicks", Variant: "stream", Type: Transformation}
+	if _, err := spark.StreamingCheckpoint(id); err == nil {
+		t.Fatalf("Expected an error for a job without a checkpoint")
+	} else if _, ok := err.(*StreamingCheckpointNotFound); !ok {
+		t.Fatalf("Expected StreamingCheckpointNotFound, got %v", err)
+	}
+	offsets := map[string]string{
+		"9":  "v1\n{\"batchWatermarkMs\":0}\n{\"clicks\":{\"0\":5}}\n{\"logOffset\":2}",
+		"10": "v1\n{\"batchWatermarkMs\":0}\n{\"clicks\":{\"1\":40,\"0\":12}}\n{\"logOffset\":3}",
+	}
+	for batch, contents := range offsets {
+		path := spark.Store.PathWithPrefix(fmt.Sprintf("%s/_checkpoint/offsets/%s", ResourcePrefix(id), batch), false)
+		if err := spark.Store.Write(path, []byte(contents)); err != nil {
+			t.Fatalf("Failed to write checkpoint: %s", err)
+		}
+	}
+	checkpoint, err := spark.StreamingCheckpoint(id)
+	if err != nil {
+		t.Fatalf("Failed to read checkpoint: %s", err)
+	}
+	if checkpoint.Batch != 10 || len(checkpoint.Sources) != 2 {
+		t.Fatalf("Expected the two sources of batch 10, got %+v", checkpoint)
+	}
+	expected := []KafkaPartitionOffset{{"clicks", 0, 12}, {"clicks", 1, 40}}
+	if kafkaOffsets := checkpoint.KafkaOffsets(); !reflect.DeepEqual(kafkaOffsets, expected) {
+		t.Fatalf("Expected %v, got %v", expected, kafkaOffsets)
+	}
+}
@@ -108,7 +108,7 @@ func testCreateTrainingSet(store *SparkOfflineStore) error {
 		return err
 	}
 	testFeatureResource := sparkSafeRandomID(Feature)
-	testResourceSchema := ResourceSchema{"name", "age", "registered", path}
+	testResourceSchema := ResourceSchema{Entity: "name", Value: "age", TS: "registered", SourceTable: path}
 	table, err := store.RegisterResourceFromSourceTable(testFeatureResource, testResourceSchema)
 	if err != nil {
 		return err
@@ -121,7 +121,7 @@ func testCreateTrainingSet(store *SparkOfflineStore) error {
 		return fmt.Errorf("Did not properly register table")
 	}
 	testLabelResource := sparkSafeRandomID(Label)
-	testLabelResourceSchema := ResourceSchema{"name", "winner", "registered", path}
+	testLabelResourceSchema := ResourceSchema{Entity: "name", Value: "winner", TS: "registered", SourceTable: path}
 	labelTable, err := store.RegisterResourceFromSourceTable(testLabelResource, testLabelResourceSchema)
 	fetchedLabel, err := store.GetResourceTable(testLabelResource)
 	if err != nil {
@@ -191,7 +191,7 @@ func testMaterializeResource(store *SparkOfflineStore) error {
 	testResourceName := "test_name_materialize"
 	testResourceVariant := uuid.New().String()
 	testResource := ResourceID{testResourceName, testResourceVariant, Feature}
-	testResourceSchema := ResourceSchema{"name", "age", "registered", path}
+	testResourceSchema := ResourceSchema{Entity: "name", Value: "age", TS: "registered", SourceTable: path}
 	table, err := store.RegisterResourceFromSourceTable(testResource, testResourceSchema)
 	if err != nil {
 		return err
@@ -296,7 +296,7 @@ func testRegisterResource(store *SparkOfflineStore) error {
 	}
 	resourceVariantName := uuid.New().String()
 	testResource := ResourceID{"test_name", resourceVariantName, Feature}
-	testResourceSchema := ResourceSchema{"name", "age", "registered", path}
+	testResourceSchema := ResourceSchema{Entity: "name", Value: "age", TS: "registered", SourceTable: path}
 	table, err := store.RegisterResourceFromSourceTable(testResource, testResourceSchema)
 	if err != nil {
 		return err
@@ -686,7 +686,7 @@ func registerRandomResourceGiveTablePath(id ResourceID, path string, store *Spar
 	}
 	var schema ResourceSchema
 	if timestamp {
-		schema = ResourceSchema{"entity", "value", "ts", path}
+		schema = ResourceSchema{Entity: "entity", Value: "value", TS: "ts", SourceTable: path}
 	} else {
 		schema = ResourceSchema{Entity: "entity", Value: "value", SourceTable: path}
 	}
@@ -704,7 +704,7 @@ func registerRandomResourceGiveTable(id ResourceID, store *SparkOfflineStore, ta
 	}
 	var schema ResourceSchema
 	if timestamp {
-		schema = ResourceSchema{"entity", "value", "ts", randomSourceTablePath}
+		schema = ResourceSchema{Entity: "entity", Value: "value", TS: "ts", SourceTable: randomSourceTablePath}
 	} else {
 		schema = ResourceSchema{Entity: "entity", Value: "value", SourceTable: randomSourceTablePath}
 	}
@@ -723,7 +723,7 @@ func registerRandomResource(id ResourceID, store *SparkOfflineStore) error {
 	if err := uploadCSVTable(store.Store, randomSourceTablePath, randomSourceData); err != nil {
 		return err
 	}
-	schema := ResourceSchema{"entity", "value", "ts", randomSourceTablePath}
+	schema := ResourceSchema{Entity: "entity", Value: "value", TS: "ts", SourceTable: randomSourceTablePath}
 	_, err := store.RegisterResourceFromSourceTable(id, schema)
 	if err != nil {
 		return err
@@ -1671,6 +1671,25 @@ func TestMaterializationCreate(t *testing.T) {
 	}
 }
 
+func TestMaterializationCreateTimezone(t *testing.T) {
+	t.Parallel()
+	schema := ResourceSchema{
+		Entity:     "entity",
+		Value:      "value",
+		TS:         "timestamp",
+		TSTimezone: "America/Los_Angeles",
+	}
+	sparkQuery := defaultPythonOfflineQueries{}.materializationCreate(schema)
+	converted := "to_utc_timestamp(timestamp, 'America/Los_Angeles')"
+	if !strings.Contains(sparkQuery, converted+" AS ts") || !strings.Contains(sparkQuery, "ORDER BY "+converted+" DESC") {
+		t.Fatalf("Spark materialization did not convert timestamps to UTC: %s", sparkQuery)
+	}
+	pandasQuery := pandasOfflineQueries{}.materializationCreate(schema)
+	if strings.Contains(pandasQuery, "to_utc_timestamp") {
+		t.Fatalf("Pandas materialization should leave timestamps to be converted when read: %s", pandasQuery)
+	}
+}
+
 func TestTrainingSetCreate(t *testing.T) {
 	testTrainingSetDef := TrainingSetDef{
 		ID: ResourceID{"test_training_set", "default", TrainingSet},
@@ -7,6 +7,7 @@ package provider
 import (
 	"context"
 	"database/sql"
+	"database/sql/driver"
 	"errors"
 	"fmt"
 	"strconv"
@@ -29,6 +30,9 @@ type SQLOfflineStoreConfig struct {
 	Driver        string
 	ProviderType  pt.Type
 	QueryImpl     OfflineTableQueries
+	// Connector opens connections instead of Driver and ConnectionURL, for
+	// providers whose connection settings change, like temporary credentials.
+	Connector driver.Connector
 }
 
 type OfflineTableQueries interface {
@@ -49,6 +53,8 @@ type OfflineTableQueries interface {
 	getTable() string
 	dropTable(tableName string) string
 	materializationIterateSegment(tableName string) string
+	materializationLookup(tableName string) string
+	materializationLookupEntities(tableName string, count int) string
 	newSQLOfflineTable(name string, columnType string) string
 	writeUpdate(table string) string
 	writeInserts(table string) string
@@ -65,6 +71,26 @@ type OfflineTableQueries interface {
 	transformationExists() string
 }
 
+// maintenanceQueries is implemented by the queries of SQL providers that
+// support maintenance. It returns the statements to run, in order.
+type maintenanceQueries interface {
+	maintenance(tableName string, config MaintenanceConfig) ([]string, error)
+}
+
+// incrementalMaterializationQueries is implemented by the queries of SQL
+// providers whose materializations are tables that can be merged into. It
+// returns the statements to run in a transaction, in order.
+type incrementalMaterializationQueries interface {
+	materializationIncrementalUpdate(tableName, changedTableName, sourceName string) []string
+}
+
+// approximateRowCountQueries is implemented by the queries of SQL providers
+// that keep row count statistics for tables. The query takes a table's name,
+// and returns its estimated row count or NULL.
+type approximateRowCountQueries interface {
+	materializationApproxNumRows() string
+}
+
 type sqlOfflineStore struct {
 	db     *sql.DB
 	parent SQLOfflineStoreConfig
@@ -75,10 +101,15 @@ type sqlOfflineStore struct {
 // NewPostgresOfflineStore creates a connection to a postgres database
 // and initializes a table to track currently active Resource tables.
 func NewSQLOfflineStore(config SQLOfflineStoreConfig) (*sqlOfflineStore, error) {
-	url := config.ConnectionURL
-	db, err := sql.Open(config.Driver, url)
-	if err != nil {
-		return nil, err
+	var db *sql.DB
+	if config.Connector != nil {
+		db = sql.OpenDB(config.Connector)
+	} else {
+		var err error
+		db, err = sql.Open(config.Driver, config.ConnectionURL)
+		if err != nil {
+			return nil, err
+		}
 	}
 
 	return &sqlOfflineStore{
@@ -182,6 +213,9 @@ func (store *sqlOfflineStore) RegisterResourceFromSourceTable(id ResourceID, sch
 	if schema.Entity == "" || schema.Value == "" {
 		return nil, fmt.Errorf("non-empty entity and value columns required")
 	}
+	if _, err := schema.tsLocation(); err != nil {
+		return nil, err
+	}
 	tableName, err := store.getResourceTableName(id)
 	if err != nil {
 		return nil, fmt.Errorf("get name: %w", err)
@@ -410,6 +444,31 @@ func (mat *sqlMaterialization) NumRows() (int64, error) {
 
 }
 
+// ApproxNumRows estimates the number of rows from table statistics, and
+// counts them if the provider has none for the table.
+func (mat *sqlMaterialization) ApproxNumRows() (int64, error) {
+	queries, ok := mat.query.(approximateRowCountQueries)
+	if !ok {
+		return mat.NumRows()
+	}
+	var n interface{}
+	if err := mat.db.QueryRow(queries.materializationApproxNumRows(), mat.tableName).Scan(&n); err != nil {
+		return 0, fmt.Errorf("could not estimate rows of %s: %w", mat.tableName, err)
+	}
+	if n == nil {
+		return mat.NumRows()
+	}
+	count, err := mat.query.numRows(n)
+	if err != nil {
+		return 0, err
+	}
+	// Tables that haven't been analyzed have no estimate, or one of zero.
+	if count <= 0 {
+		return mat.NumRows()
+	}
+	return count, nil
+}
+
 func (mat *sqlMaterialization) IterateSegment(start, end int64) (FeatureIterator, error) {
 	query := mat.query.materializationIterateSegment(mat.tableName)
 
@@ -428,6 +487,44 @@ func (mat *sqlMaterialization) IterateSegment(start, end int64) (FeatureIterator
 	return newsqlFeatureIterator(rows, colType, mat.query), nil
 }
 
+func (mat *sqlMaterialization) lookup(entity string) (ResourceRecord, error) {
+	rows, err := mat.db.Query(mat.query.materializationLookup(mat.tableName), entity)
+	if err != nil {
+		return ResourceRecord{}, err
+	}
+	types, err := rows.ColumnTypes()
+	if err != nil {
+		rows.Close()
+		return ResourceRecord{}, err
+	}
+	it := newsqlFeatureIterator(rows, mat.query.getValueColumnType(types[1]), mat.query)
+	defer it.Close()
+	if !it.Next() {
+		if err := it.Err(); err != nil {
+			return ResourceRecord{}, err
+		}
+		return ResourceRecord{}, &EntityNotFound{entity}
+	}
+	return it.Value(), nil
+}
+
+func (mat *sqlMaterialization) lookupEntities(entities []string) (FeatureIterator, error) {
+	args := make([]interface{}, len(entities))
+	for i, entity := range entities {
+		args[i] = entity
+	}
+	rows, err := mat.db.Query(mat.query.materializationLookupEntities(mat.tableName, len(entities)), args...)
+	if err != nil {
+		return nil, err
+	}
+	types, err := rows.ColumnTypes()
+	if err != nil {
+		rows.Close()
+		return nil, err
+	}
+	return newsqlFeatureIterator(rows, mat.query.getValueColumnType(types[1]), mat.query), nil
+}
+
 type sqlFeatureIterator struct {
 	rows         *sql.Rows
 	err          error
@@ -478,6 +575,10 @@ func (iter *sqlFeatureIterator) Close() error {
 }
 
 func (store *sqlOfflineStore) CreateMaterialization(id ResourceID) (Materialization, error) {
+	return store.CreateDedupMaterialization(id, DedupStrategy{})
+}
+
+func (store *sqlOfflineStore) CreateDedupMaterialization(id ResourceID, strategy DedupStrategy) (Materialization, error) {
 	if id.Type != Feature {
 		return nil, errors.New("only features can be materialized")
 	}
@@ -485,10 +586,14 @@ func (store *sqlOfflineStore) CreateMaterialization(id ResourceID) (Materializat
 	if err != nil {
 		return nil, err
 	}
+	sourceName, err := store.dedupSource(id, resTable.name, strategy)
+	if err != nil {
+		return nil, err
+	}
 
 	matID := MaterializationID(id.Name)
 	matTableName := store.getMaterializationTableName(matID)
-	materializeQry := store.query.materializationCreate(matTableName, resTable.name)
+	materializeQry := store.query.materializationCreate(matTableName, sourceName)
 
 	_, err = store.db.Exec(materializeQry)
 	if err != nil {
@@ -529,7 +634,34 @@ func (store *sqlOfflineStore) GetMaterialization(id MaterializationID) (Material
 	}, err
 }
 
+func (store *sqlOfflineStore) GetFeatureMaterialization(id ResourceID) (Materialization, error) {
+	return store.GetMaterialization(MaterializationID(id.Name))
+}
+
+func (store *sqlOfflineStore) LookupMaterializedEntity(id ResourceID, entity string) (ResourceRecord, error) {
+	mat, err := store.GetMaterialization(MaterializationID(id.Name))
+	if err != nil {
+		return ResourceRecord{}, err
+	}
+	return mat.(*sqlMaterialization).lookup(entity)
+}
+
+// LookupMaterializedEntities reads the entities in batches of at most
+// entityLookupBatchSize, so that each query stays within the providers'
+// limits on bound parameters.
+func (store *sqlOfflineStore) LookupMaterializedEntities(id ResourceID, entities []string) (FeatureIterator, error) {
+	mat, err := store.GetMaterialization(MaterializationID(id.Name))
+	if err != nil {
+		return nil, err
+	}
+	return newEntityBatchIterator(uniqueEntities(entities), mat.(*sqlMaterialization).lookupEntities), nil
+}
+
 func (store *sqlOfflineStore) UpdateMaterialization(id ResourceID) (Materialization, error) {
+	return store.UpdateDedupMaterialization(id, DedupStrategy{})
+}
+
+func (store *sqlOfflineStore) UpdateDedupMaterialization(id ResourceID, strategy DedupStrategy) (Materialization, error) {
 	matID := MaterializationID(id.Name)
 	tableName := store.getMaterializationTableName(matID)
 	getMatQry := store.query.materializationExists()
@@ -537,6 +669,10 @@ func (store *sqlOfflineStore) UpdateMaterialization(id ResourceID) (Materializat
 	if err != nil {
 		return nil, err
 	}
+	sourceName, err := store.dedupSource(id, resTable.name, strategy)
+	if err != nil {
+		return nil, err
+	}
 
 	rows, err := store.db.Query(getMatQry, tableName)
 	if err != nil {
@@ -546,7 +682,7 @@ func (store *sqlOfflineStore) UpdateMaterialization(id ResourceID) (Materializat
 	if !rows.Next() {
 		return nil, &MaterializationNotFound{matID}
 	}
-	err = store.query.materializationUpdate(store.db, tableName, resTable.name)
+	err = store.query.materializationUpdate(store.db, tableName, sourceName)
 	if err != nil {
 		return nil, err
 	}
@@ -558,6 +694,63 @@ func (store *sqlOfflineStore) UpdateMaterialization(id ResourceID) (Materializat
 	}, err
 }
 
+// dedupSource returns the table that a materialization with the strategy
+// takes each entity's latest row from. Strategies other than the latest row
+// aggregate the resource table in a view, which has one row per entity.
+func (store *sqlOfflineStore) dedupSource(id ResourceID, resourceTable string, strategy DedupStrategy) (string, error) {
+	if err := strategy.Validate(); err != nil {
+		return "", err
+	}
+	if strategy.IsLatest() {
+		return resourceTable, nil
+	}
+	viewName := fmt.Sprintf("featureform_dedup__%s__%s", id.Name, id.Variant)
+	query := fmt.Sprintf("CREATE OR REPLACE VIEW %s AS SELECT entity, %s AS value, MAX(ts) AS ts FROM %s GROUP BY entity",
+		sanitize(viewName), strategy.valueAggregate(), sanitize(resourceTable))
+	if _, err := store.db.Exec(query); err != nil {
+		return "", fmt.Errorf("could not create dedup view for %s: %w", id.Name, err)
+	}
+	return viewName, nil
+}
+
+func (store *sqlOfflineStore) UpdateMaterializationIncrementally(id ResourceID) (Materialization, error) {
+	queries, ok := store.query.(incrementalMaterializationQueries)
+	if !ok {
+		return nil, &IncrementalMaterializationNotSupported{string(store.Type())}
+	}
+	matID := MaterializationID(id.Name)
+	if exists, err := store.materializationExists(matID); err != nil {
+		return nil, err
+	} else if !exists {
+		return nil, &MaterializationNotFound{matID}
+	}
+	resTable, err := store.getsqlResourceTable(id)
+	if err != nil {
+		return nil, err
+	}
+	changedID := incrementalMaterializationID(matID)
+	changedTableName := store.getMaterializationTableName(changedID)
+	tx, err := store.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	for _, statement := range queries.materializationIncrementalUpdate(store.getMaterializationTableName(matID), changedTableName, resTable.name) {
+		if _, err := tx.Exec(statement); err != nil {
+			tx.Rollback()
+			return nil, fmt.Errorf("could not update materialization %s incrementally: %w", matID, err)
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return &sqlMaterialization{
+		id:        changedID,
+		db:        store.db,
+		tableName: changedTableName,
+		query:     store.query,
+	}, nil
+}
+
 func (store *sqlOfflineStore) DeleteMaterialization(id MaterializationID) error {
 	tableName := store.getMaterializationTableName(id)
 	if exists, err := store.materializationExists(id); err != nil {
@@ -569,6 +762,14 @@ func (store *sqlOfflineStore) DeleteMaterialization(id MaterializationID) error
 	if _, err := store.db.Exec(query); err != nil {
 		return err
 	}
+	changedID := incrementalMaterializationID(id)
+	if exists, err := store.materializationExists(changedID); err != nil {
+		return err
+	} else if exists {
+		if _, err := store.db.Exec(store.query.dropTable(store.getMaterializationTableName(changedID))); err != nil {
+			return err
+		}
+	}
 	return nil
 }
 
@@ -992,6 +1193,36 @@ func (store *sqlOfflineStore) UpdateTransformation(config TransformationConfig)
 	return nil
 }
 
+func (store *sqlOfflineStore) Maintain(config MaintenanceConfig) error {
+	queries, ok := store.query.(maintenanceQueries)
+	if !ok {
+		return &MaintenanceNotSupported{string(store.Type())}
+	}
+	var tableName string
+	var err error
+	switch config.TableID.Type {
+	case Feature, FeatureMaterialization:
+		tableName = store.getMaterializationTableName(MaterializationID(config.TableID.Name))
+	case Transformation:
+		tableName, err = store.createTransformationName(config.TableID)
+	default:
+		err = fmt.Errorf("cannot run maintenance on %s tables", config.TableID.Type)
+	}
+	if err != nil {
+		return err
+	}
+	statements, err := queries.maintenance(tableName, config)
+	if err != nil {
+		return err
+	}
+	for _, statement := range statements {
+		if _, err := store.db.Exec(statement); err != nil {
+			return fmt.Errorf("could not run maintenance on %s: %w", tableName, err)
+		}
+	}
+	return nil
+}
+
 func (store *sqlOfflineStore) createTransformationName(id ResourceID) (string, error) {
 	switch id.Type {
 	case Transformation:
@@ -1132,8 +1363,12 @@ func (q defaultOfflineSQLQueries) viewExists() string {
 func (q defaultOfflineSQLQueries) registerResources(db *sql.DB, tableName string, schema ResourceSchema, timestamp bool) error {
 	var query string
 	if timestamp {
-		query = fmt.Sprintf("CREATE VIEW %s AS SELECT IDENTIFIER('%s') as entity,  IDENTIFIER('%s') as value,  IDENTIFIER('%s') as ts FROM TABLE('%s')", sanitize(tableName),
-			schema.Entity, schema.Value, schema.TS, sanitize(schema.SourceTable))
+		ts := fmt.Sprintf("IDENTIFIER('%s')", schema.TS)
+		if schema.TSTimezone != "" {
+			ts = fmt.Sprintf("CONVERT_TIMEZONE('%s', 'UTC', %s)", schema.TSTimezone, ts)
+		}
+		query = fmt.Sprintf("CREATE VIEW %s AS SELECT IDENTIFIER('%s') as entity,  IDENTIFIER('%s') as value,  %s as ts FROM TABLE('%s')", sanitize(tableName),
+			schema.Entity, schema.Value, ts, sanitize(schema.SourceTable))
 	} else {
 		query = fmt.Sprintf("CREATE VIEW %s AS SELECT IDENTIFIER('%s') as entity, IDENTIFIER('%s') as value, to_timestamp_ntz('%s', 'YYYY-DD-MM HH24:MI:SS +0000 UTC')::TIMESTAMP_NTZ as ts FROM TABLE('%s')", sanitize(tableName),
 			schema.Entity, schema.Value, time.UnixMilli(0).UTC(), sanitize(schema.SourceTable))
@@ -1196,6 +1431,34 @@ func (q defaultOfflineSQLQueries) materializationUpdate(db *sql.DB, tableName st
 	return err
 }
 
+// incrementalMaterializationStatements replace the changed table with the
+// latest rows of the entities that have rows after the materialization's
+// watermark, then rebuild the materialization with them in place of those
+// entities' old rows, so that its row numbers stay contiguous for segments.
+// An empty materialization has no watermark, so all of its rows are new.
+func (q defaultOfflineSQLQueries) incrementalMaterializationStatements(tableName, changedTableName, sourceName string) []string {
+	sanitizedTable := sanitize(tableName)
+	changedTable := sanitize(changedTableName)
+	tempTable := sanitize(fmt.Sprintf("tmp_%s", tableName))
+	oldTable := sanitize(fmt.Sprintf("old_%s", tableName))
+	return []string{
+		fmt.Sprintf("DROP TABLE IF EXISTS %s", changedTable),
+		fmt.Sprintf(
+			"CREATE TABLE %s AS (SELECT entity, value, ts, row_number() over(ORDER BY entity) as row_number FROM "+
+				"(SELECT entity, ts, value, row_number() OVER (PARTITION BY entity ORDER BY ts desc) AS rn FROM %s "+
+				"WHERE ts > (SELECT MAX(ts) FROM %s) OR NOT EXISTS (SELECT 1 FROM %s)) t WHERE rn=1)",
+			changedTable, sanitize(sourceName), sanitizedTable, sanitizedTable),
+		fmt.Sprintf(
+			"CREATE TABLE %s AS (SELECT entity, value, ts, row_number() over(ORDER BY entity) as row_number FROM "+
+				"(SELECT entity, value, ts FROM %s UNION ALL "+
+				"SELECT entity, value, ts FROM %s WHERE entity NOT IN (SELECT entity FROM %s)) t)",
+			tempTable, changedTable, sanitizedTable, changedTable),
+		fmt.Sprintf("ALTER TABLE %s RENAME TO %s", sanitizedTable, oldTable),
+		fmt.Sprintf("ALTER TABLE %s RENAME TO %s", tempTable, sanitizedTable),
+		fmt.Sprintf("DROP TABLE %s", oldTable),
+	}
+}
+
 func (q defaultOfflineSQLQueries) getTable() string {
 	bind := q.newVariableBindingIterator()
 	return fmt.Sprintf("SELECT DISTINCT (table_name) FROM information_schema.tables WHERE table_name=%s", bind.Next())
@@ -1267,6 +1530,20 @@ func (q defaultOfflineSQLQueries) materializationIterateSegment(tableName string
 	return fmt.Sprintf("SELECT entity, value, ts FROM ( SELECT * FROM %s WHERE row_number>%s AND row_number<=%s)t1", sanitize(tableName), bind.Next(), bind.Next())
 }
 
+func (q defaultOfflineSQLQueries) materializationLookup(tableName string) string {
+	bind := q.newVariableBindingIterator()
+	return fmt.Sprintf("SELECT entity, value, ts FROM %s WHERE entity=%s", sanitize(tableName), bind.Next())
+}
+
+func (q defaultOfflineSQLQueries) materializationLookupEntities(tableName string, count int) string {
+	bind := q.newVariableBindingIterator()
+	binds := make([]string, count)
+	for i := range binds {
+		binds[i] = bind.Next()
+	}
+	return fmt.Sprintf("SELECT entity, value, ts FROM %s WHERE entity IN (%s)", sanitize(tableName), strings.Join(binds, ", "))
+}
+
 func (q defaultOfflineSQLQueries) createValuePlaceholderString(columns []TableColumn) string {
 	placeholders := make([]string, 0)
 	for _ = range columns {
@@ -0,0 +1,193 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package provider
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+
+	pc "github.com/featureform/provider/provider_config"
+	pt "github.com/featureform/provider/provider_type"
+)
+
+// StreamingConfig configures a StreamingTransformation.
+type StreamingConfig struct {
+	// Trigger is how often micro-batches run, eg. "1 minute". If it's empty,
+	// each batch runs as soon as the one before it finishes.
+	Trigger string
+	// OnlineSinks are the online tables that each micro-batch is written to,
+	// along with the transformation's offline table.
+	OnlineSinks []StreamingOnlineSink
+}
+
+// StreamingOnlineSink writes the Value column of a streaming transformation
+// to a feature's online table, keyed by the Entity column. The table has to
+// exist before the job starts.
+type StreamingOnlineSink struct {
+	Type    pt.Type
+	Config  pc.SerializedConfig
+	Feature string
+	Variant string
+	Entity  string
+	Value   string
+}
+
+type StreamingJobState string
+
+const (
+	StreamingJobPending StreamingJobState = "PENDING"
+	StreamingJobRunning StreamingJobState = "RUNNING"
+	StreamingJobStopped StreamingJobState = "STOPPED"
+	StreamingJobFailed  StreamingJobState = "FAILED"
+)
+
+type StreamingJobStatus struct {
+	State   StreamingJobState
+	Message string
+}
+
+// Active is true if the job is running or about to, so another job for the
+// same transformation can't be started yet.
+func (s StreamingJobStatus) Active() bool {
+	return s.State == StreamingJobPending || s.State == StreamingJobRunning
+}
+
+// StreamingOfflineStore is implemented by offline stores that can run
+// streaming transformations. Unlike other transformations, they're started
+// and keep running until they're stopped or fail.
+type StreamingOfflineStore interface {
+	OfflineStore
+	StartStreamingTransformation(config TransformationConfig) error
+	StopStreamingTransformation(id ResourceID) error
+	StreamingTransformationStatus(id ResourceID) (StreamingJobStatus, error)
+	// StreamingCheckpoint returns the latest batch of the transformation's
+	// checkpoint, which is StreamingCheckpointNotFound until its first batch
+	// has started.
+	StreamingCheckpoint(id ResourceID) (StreamingCheckpoint, error)
+}
+
+// StreamingCheckpoint is the offsets of a streaming job's sources that its
+// latest batch reads up to. Each source's offsets are in the format of its
+// Spark source.
+type StreamingCheckpoint struct {
+	Batch   int64
+	Sources []json.RawMessage
+}
+
+// KafkaPartitionOffset is the next offset to read from a partition of a
+// topic.
+type KafkaPartitionOffset struct {
+	Topic     string
+	Partition int32
+	Offset    int64
+}
+
+// KafkaOffsets returns the offsets of the checkpoint's Kafka sources, sorted
+// by topic and partition. Kafka offsets are a map of topics to the offset of
+// each of their partitions, which other sources' offsets aren't.
+func (c StreamingCheckpoint) KafkaOffsets() []KafkaPartitionOffset {
+	offsets := []KafkaPartitionOffset{}
+	for _, source := range c.Sources {
+		var topics map[string]map[string]int64
+		if err := json.Unmarshal(source, &topics); err != nil {
+			continue
+		}
+		for topic, partitions := range topics {
+			for partition, offset := range partitions {
+				id, err := strconv.ParseInt(partition, 10, 32)
+				if err != nil {
+					continue
+				}
+				offsets = append(offsets, KafkaPartitionOffset{Topic: topic, Partition: int32(id), Offset: offset})
+			}
+		}
+	}
+	sort.Slice(offsets, func(i, j int) bool {
+		if offsets[i].Topic != offsets[j].Topic {
+			return offsets[i].Topic < offsets[j].Topic
+		}
+		return offsets[i].Partition < offsets[j].Partition
+	})
+	return offsets
+}
+
+type StreamingNotSupported struct {
+	ProviderType string
+}
+
+func (err *StreamingNotSupported) Error() string {
+	return fmt.Sprintf("streaming transformations are not supported by %s", err.ProviderType)
+}
+
+type StreamingCheckpointNotFound struct {
+	ID ResourceID
+}
+
+func (err *StreamingCheckpointNotFound) Error() string {
+	return fmt.Sprintf("streaming job of %s %s has no checkpoint", err.ID.Name, err.ID.Variant)
+}
+
+type StreamingJobNotFound struct {
+	ID ResourceID
+}
+
+func (err *StreamingJobNotFound) Error() string {
+	return fmt.Sprintf("no streaming job has been started for %s %s", err.ID.Name, err.ID.Variant)
+}
+
+// streamingRedisSink is how a Redis online sink is passed to the streaming
+// job, which writes to the table's hash the same way redisOnlineTable does.
+type streamingRedisSink struct {
+	Type       string `json:"type"`
+	Addr       string `json:"addr"`
+	Password   string `json:"password"`
+	DB         int    `json:"db"`
+	TLS        bool   `json:"tls"`
+	Key        string `json:"key"`
+	UpdatedKey string `json:"updated_key"`
+	Entity     string `json:"entity"`
+	Value      string `json:"value"`
+}
+
+// streamingOnlineSinksArg encodes the sinks as the base64 JSON that the
+// streaming job reads them from. Only Redis can be written to by the job.
+func streamingOnlineSinksArg(sinks []StreamingOnlineSink) (string, error) {
+	encoded := make([]streamingRedisSink, len(sinks))
+	for i, sink := range sinks {
+		if sink.Type != pt.RedisOnline {
+			return "", fmt.Errorf("streaming transformations can't write to %s online stores", sink.Type)
+		}
+		if sink.Entity == "" || sink.Value == "" {
+			return "", fmt.Errorf("online sink for %s %s must have an entity and value column", sink.Feature, sink.Variant)
+		}
+		redisConfig := &pc.RedisConfig{}
+		if err := redisConfig.Deserialize(sink.Config); err != nil {
+			return "", err
+		}
+		if redisConfig.Prefix == "" {
+			redisConfig.Prefix = "Featureform_table__"
+		}
+		table := redisOnlineTable{key: redisTableKey{redisConfig.Prefix, sink.Feature, sink.Variant}}
+		encoded[i] = streamingRedisSink{
+			Type:       "redis",
+			Addr:       redisConfig.Addr,
+			Password:   redisConfig.Password,
+			DB:         redisConfig.DB,
+			TLS:        redisConfig.TLS,
+			Key:        table.key.String(),
+			UpdatedKey: table.updatedKey(),
+			Entity:     sink.Entity,
+			Value:      sink.Value,
+		}
+	}
+	serialized, err := json.Marshal(encoded)
+	if err != nil {
+		return "", err
+	}
+	return base64.StdEncoding.EncodeToString(serialized), nil
+}
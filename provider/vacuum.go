@@ -0,0 +1,151 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package provider
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// VacuumConfig describes which of an offline store's resources are still in
+// use. The tables and files of any other resource, such as those left behind
+// by failed or deleted jobs, are orphaned and removed by a vacuum.
+type VacuumConfig struct {
+	// Live are the resources to keep. A source is kept whether it's live as
+	// a Primary or a Transformation, and a feature's materialization is kept
+	// if the feature is live.
+	Live []ResourceID
+	// MinAge is how long it's been since an orphaned resource was last
+	// written before it's removed, so the output of running jobs isn't.
+	MinAge time.Duration
+	// DryRun reports the orphaned resources without removing them.
+	DryRun bool
+}
+
+// VacuumedResource is an orphaned resource found by a vacuum.
+type VacuumedResource struct {
+	ID ResourceID
+	// Location is the directory or table that the resource was stored in.
+	Location string
+	Files    int
+	Bytes    int64
+}
+
+// VacuumResult is the orphaned resources found by a vacuum, and the storage
+// reclaimed by removing them, or that would be in a dry run.
+type VacuumResult struct {
+	Resources      []VacuumedResource
+	ReclaimedBytes int64
+}
+
+// VacuumableOfflineStore is implemented by offline stores that can remove
+// orphaned resources.
+type VacuumableOfflineStore interface {
+	OfflineStore
+	Vacuum(config VacuumConfig) (VacuumResult, error)
+}
+
+type VacuumNotSupported struct {
+	ProviderType string
+}
+
+func (err *VacuumNotSupported) Error() string {
+	return fmt.Sprintf("vacuum is not supported by %s", err.ProviderType)
+}
+
+// vacuumKey is the resource that an id is kept by.
+func vacuumKey(id ResourceID) ResourceID {
+	switch id.Type {
+	case FeatureMaterialization:
+		id.Type = Feature
+	case Primary:
+		id.Type = Transformation
+	}
+	return id
+}
+
+// resourceTypesByName maps the directory names of resource types in a file
+// store to the type.
+var resourceTypesByName = map[string]OfflineResourceType{
+	Label.String():                  Label,
+	Feature.String():                Feature,
+	TrainingSet.String():            TrainingSet,
+	Primary.String():                Primary,
+	Transformation.String():         Transformation,
+	FeatureMaterialization.String(): FeatureMaterialization,
+}
+
+// vacuumFileStore removes the files of orphaned resources under a file store's
+// featureform directory, as of now.
+func vacuumFileStore(store FileStore, config VacuumConfig, now time.Time) (VacuumResult, error) {
+	live := make(map[ResourceID]bool, len(config.Live))
+	for _, id := range config.Live {
+		live[vacuumKey(id)] = true
+	}
+	prefix := strings.TrimSuffix(store.PathWithPrefix("featureform", false), "/") + "/"
+	files, err := store.ListFiles(prefix)
+	if err != nil {
+		return VacuumResult{}, fmt.Errorf("could not list resource files: %w", err)
+	}
+	orphans := make(map[string]*VacuumedResource)
+	newest := make(map[string]time.Time)
+	for _, file := range files {
+		parts := strings.SplitN(strings.TrimPrefix(file.Key, prefix), "/", 4)
+		if len(parts) < 3 {
+			continue
+		}
+		resourceType, ok := resourceTypesByName[parts[0]]
+		if !ok {
+			continue
+		}
+		id := ResourceID{Name: parts[1], Variant: parts[2], Type: resourceType}
+		if live[vacuumKey(id)] {
+			continue
+		}
+		// Resources are either a directory or, like registered features and
+		// labels, a single file.
+		location := prefix + strings.Join(parts[:3], "/")
+		if len(parts) == 4 {
+			location += "/"
+		}
+		orphan, has := orphans[location]
+		if !has {
+			orphan = &VacuumedResource{ID: id, Location: location}
+			orphans[location] = orphan
+		}
+		orphan.Files++
+		orphan.Bytes += file.Size
+		if file.ModTime.After(newest[location]) {
+			newest[location] = file.ModTime
+		}
+	}
+	result := VacuumResult{Resources: make([]VacuumedResource, 0, len(orphans))}
+	for location, orphan := range orphans {
+		if now.Sub(newest[location]) < config.MinAge {
+			continue
+		}
+		result.Resources = append(result.Resources, *orphan)
+	}
+	sort.Slice(result.Resources, func(i, j int) bool {
+		return result.Resources[i].Location < result.Resources[j].Location
+	})
+	for _, orphan := range result.Resources {
+		if !config.DryRun {
+			var err error
+			if strings.HasSuffix(orphan.Location, "/") {
+				err = store.DeleteAll(orphan.Location)
+			} else {
+				err = store.Delete(orphan.Location)
+			}
+			if err != nil {
+				return result, fmt.Errorf("could not delete orphaned resource %s: %w", orphan.Location, err)
+			}
+		}
+		result.ReclaimedBytes += orphan.Bytes
+	}
+	return result, nil
+}
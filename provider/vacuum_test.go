@@ -0,0 +1,111 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package provider
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	pc "github.com/featureform/provider/provider_config"
+)
+
+func TestVacuumFileStore(t *testing.T) {
+	dir := t.TempDir()
+	config := pc.LocalFileStoreConfig{DirPath: fmt.Sprintf("file://%s/", dir)}
+	serialized, err := config.Serialize()
+	if err != nil {
+		t.Fatalf("Failed to serialize file store config: %s", err)
+	}
+	store, err := NewLocalFileStore(serialized)
+	if err != nil {
+		t.Fatalf("Failed to create file store: %s", err)
+	}
+	now := time.Now()
+	files := map[string]time.Duration{
+		"featureform/Materialization/live/v1/2023-06-01/part-0.parquet":       48 * time.Hour,
+		"featureform/Transformation/live/v1/2023-06-01/part-0.parquet":        48 * time.Hour,
+		"featureform/Materialization/deleted/v1/2023-06-01/part-0.parquet":    48 * time.Hour,
+		"featureform/Materialization/deleted/v1/2023-06-01/part-1.parquet":    48 * time.Hour,
+		"featureform/Transformation/failed/v1/2023-06-01/part-0.parquet":      48 * time.Hour,
+		"featureform/Label/deleted/v1":                                        48 * time.Hour,
+		"featureform/Transformation/running/v1/2023-06-01/part-0.parquet":     time.Minute,
+		"featureform/Materialization/deleted/v10/2023-06-01/part-0.parquet":   48 * time.Hour,
+		"featureform/Materialization/deleted/v10/2023-06-01/_SUCCESS":         48 * time.Hour,
+		"featureform/Unknown/deleted/v1/2023-06-01/part-0.parquet":            48 * time.Hour,
+		"featureform/Primary/live-source/v1/2023-06-01/part-0.parquet":        48 * time.Hour,
+		"featureform/Materialization/live-feature/v1/2023-06-01/part-0.pq":    48 * time.Hour,
+		"featureform/Transformation/live-source/v1/2023-06-01/part-0.parquet": 48 * time.Hour,
+	}
+	for key, age := range files {
+		path := store.PathWithPrefix(key, false)
+		if err := store.Write(path, []byte("data")); err != nil {
+			t.Fatalf("Failed to write %s: %s", path, err)
+		}
+		modTime := now.Add(-age)
+		if err := os.Chtimes(filepath.Join(dir, path), modTime, modTime); err != nil {
+			t.Fatalf("Failed to set time of %s: %s", path, err)
+		}
+	}
+	vacuumConfig := VacuumConfig{
+		Live: []ResourceID{
+			{Name: "live", Variant: "v1", Type: Feature},
+			{Name: "live", Variant: "v1", Type: Primary},
+			{Name: "live-source", Variant: "v1", Type: Transformation},
+			{Name: "live-feature", Variant: "v1", Type: FeatureMaterialization},
+		},
+		MinAge: time.Hour,
+		DryRun: true,
+	}
+	result, err := vacuumFileStore(store, vacuumConfig, now)
+	if err != nil {
+		t.Fatalf("Failed to vacuum: %s", err)
+	}
+	expected := []ResourceID{
+		{Name: "deleted", Variant: "v1", Type: Label},
+		{Name: "deleted", Variant: "v1", Type: FeatureMaterialization},
+		{Name: "deleted", Variant: "v10", Type: FeatureMaterialization},
+		{Name: "failed", Variant: "v1", Type: Transformation},
+	}
+	ids := make([]ResourceID, len(result.Resources))
+	for i, resource := range result.Resources {
+		ids[i] = resource.ID
+	}
+	if !reflect.DeepEqual(ids, expected) {
+		t.Fatalf("Expected orphans %v, got %v", expected, ids)
+	}
+	if result.ReclaimedBytes != 6*int64(len("data")) {
+		t.Fatalf("Expected %d reclaimable bytes, got %d", 6*len("data"), result.ReclaimedBytes)
+	}
+	for key := range files {
+		if exists, err := store.Exists(store.PathWithPrefix(key, false)); err != nil || !exists {
+			t.Fatalf("Expected a dry run to keep %s: %v", key, err)
+		}
+	}
+
+	vacuumConfig.DryRun = false
+	if _, err := vacuumFileStore(store, vacuumConfig, now); err != nil {
+		t.Fatalf("Failed to vacuum: %s", err)
+	}
+	result, err = vacuumFileStore(store, vacuumConfig, now)
+	if err != nil {
+		t.Fatalf("Failed to vacuum: %s", err)
+	}
+	if len(result.Resources) != 0 || result.ReclaimedBytes != 0 {
+		t.Fatalf("Expected orphans to be removed, got %v", result.Resources)
+	}
+	for _, key := range []string{
+		"featureform/Materialization/live/v1/2023-06-01/part-0.parquet",
+		"featureform/Transformation/running/v1/2023-06-01/part-0.parquet",
+		"featureform/Unknown/deleted/v1/2023-06-01/part-0.parquet",
+	} {
+		if exists, err := store.Exists(store.PathWithPrefix(key, false)); err != nil || !exists {
+			t.Fatalf("Expected %s to be kept: %v", key, err)
+		}
+	}
+}
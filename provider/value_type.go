@@ -7,6 +7,10 @@ package provider
 import (
 	"encoding/json"
 	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
 )
 
 type ValueType interface {
@@ -18,6 +22,179 @@ type VectorType struct {
 	ScalarType  ScalarType
 	Dimension   int32
 	IsEmbedding bool
+	// DistanceMetric is how vector stores rank vectors in Nearest. It's
+	// empty for vectors created before it existed, which use cosine.
+	DistanceMetric DistanceMetric `json:",omitempty"`
+	// Index tunes the vector store's approximate nearest neighbor index. It's
+	// nil to use the store's defaults.
+	Index *IndexOptions `json:",omitempty"`
+	// StorageType is the element type the store holds vectors as. It's
+	// empty to store them as ScalarType where the store supports it. Float16
+	// halves the size of an index at the cost of precision.
+	StorageType ScalarType `json:",omitempty"`
+	// Named lists the named vectors each entity holds. It's nil for one
+	// unnamed vector, and is a pointer like Index so that VectorTypes stay
+	// comparable.
+	Named *NamedVectors `json:",omitempty"`
+}
+
+// NamedVectors are the names of the vectors each entity of a VectorType
+// holds, like embeddings of its title and body, which all have the type's
+// dimension and metric. Set, Get and Nearest use the first name.
+type NamedVectors struct {
+	Names []string
+}
+
+// VectorNames returns the type's vector names, which are empty for one
+// unnamed vector.
+func (t VectorType) VectorNames() []string {
+	if t.Named == nil {
+		return nil
+	}
+	return t.Named.Names
+}
+
+// Storage returns the element type vectors are stored as, defaulting to
+// their scalar type. Vectors of any other scalar type are float32.
+func (t VectorType) Storage() ScalarType {
+	if t.StorageType != "" {
+		return t.StorageType
+	}
+	if t.ScalarType == Float64 {
+		return Float64
+	}
+	return Float32
+}
+
+// validateStorage returns an error if StorageType is set to a type the
+// store doesn't support. Stores without float64 support hold Float64
+// vectors as float32 unless a StorageType is set.
+func (t VectorType) validateStorage(store string, supported ...ScalarType) error {
+	if t.StorageType == "" {
+		return nil
+	}
+	for _, storage := range supported {
+		if t.StorageType == storage {
+			return nil
+		}
+	}
+	return fmt.Errorf("%s does not support storing vectors as %s", store, t.StorageType)
+}
+
+// validateNames returns an error if Names has empty or duplicate names, or
+// is set for a store that doesn't support named vectors.
+func (t VectorType) validateNames(store string, supported bool) error {
+	names := t.VectorNames()
+	if len(names) == 0 {
+		return nil
+	}
+	if !supported {
+		return fmt.Errorf("%s does not support named vectors", store)
+	}
+	seen := make(map[string]bool, len(names))
+	for _, name := range names {
+		if name == "" {
+			return fmt.Errorf("vector names can't be empty")
+		}
+		if seen[name] {
+			return fmt.Errorf("duplicate vector name %q", name)
+		}
+		seen[name] = true
+	}
+	return nil
+}
+
+// hasName returns whether name is one of the type's vector names.
+func (t VectorType) hasName(name string) bool {
+	for _, vectorName := range t.VectorNames() {
+		if vectorName == name {
+			return true
+		}
+	}
+	return false
+}
+
+// float32Vector converts a vector of float32s or float64s to float32s.
+func float32Vector(value interface{}) ([]float32, bool) {
+	switch vector := value.(type) {
+	case []float32:
+		return vector, true
+	case []float64:
+		converted := make([]float32, len(vector))
+		for i, element := range vector {
+			converted[i] = float32(element)
+		}
+		return converted, true
+	default:
+		return nil, false
+	}
+}
+
+// IndexOptions are the parameters of an approximate nearest neighbor index,
+// which trade recall for latency and memory. Zero fields use the store's
+// default. M, EfConstruction and EfRuntime tune HNSW indexes, and NLists is
+// the number of clusters of an IVF index, which stores only build if it's
+// set.
+type IndexOptions struct {
+	// M is the number of neighbors each node is linked to.
+	M int32 `json:",omitempty"`
+	// EfConstruction is the number of candidates considered when inserting.
+	EfConstruction int32 `json:",omitempty"`
+	// EfRuntime is the number of candidates considered when searching.
+	EfRuntime int32 `json:",omitempty"`
+	// NLists is the number of clusters of an IVF index.
+	NLists int32 `json:",omitempty"`
+}
+
+// Options returns the vector's index options, which are zero if unset.
+func (t VectorType) Options() IndexOptions {
+	if t.Index == nil {
+		return IndexOptions{}
+	}
+	return *t.Index
+}
+
+// validate returns an error if any option is negative, or if NLists is set
+// for a store that only builds HNSW indexes.
+func (o IndexOptions) validate(store string, supportsIVF bool) error {
+	if o.M < 0 || o.EfConstruction < 0 || o.EfRuntime < 0 || o.NLists < 0 {
+		return fmt.Errorf("index options can't be negative: %+v", o)
+	}
+	if o.NLists > 0 && !supportsIVF {
+		return fmt.Errorf("%s does not support IVF indexes", store)
+	}
+	return nil
+}
+
+// Metric returns the vector's distance metric, defaulting to cosine.
+func (t VectorType) Metric() DistanceMetric {
+	if t.DistanceMetric == "" {
+		return CosineDistance
+	}
+	return t.DistanceMetric
+}
+
+// DistanceMetric is the similarity function a vector index is built with.
+// It should match the one the embeddings were trained with.
+type DistanceMetric string
+
+const (
+	CosineDistance       DistanceMetric = "cosine"
+	L2Distance           DistanceMetric = "l2"
+	InnerProductDistance DistanceMetric = "inner_product"
+)
+
+// ParseDistanceMetric validates a distance metric's name. An empty name is
+// cosine.
+func ParseDistanceMetric(name string) (DistanceMetric, error) {
+	switch metric := DistanceMetric(name); metric {
+	case "":
+		return CosineDistance, nil
+	case CosineDistance, L2Distance, InnerProductDistance:
+		return metric, nil
+	default:
+		return "", fmt.Errorf("unknown distance metric %q: must be %s, %s or %s", name, CosineDistance, L2Distance, InnerProductDistance)
+	}
 }
 
 func (t VectorType) Scalar() ScalarType {
@@ -28,6 +205,247 @@ func (t VectorType) IsVector() bool {
 	return true
 }
 
+// SparseVectorType is a vector stored as the indices and values of its
+// non-zero elements, like the term weights of learned sparse models such as
+// SPLADE. Dimension is the size of the vocabulary, or zero if it's
+// unbounded. Values are SparseVectors. Since IsVector is only set for dense
+// vectors, it's false.
+type SparseVectorType struct {
+	Dimension int32
+}
+
+func (t SparseVectorType) Scalar() ScalarType {
+	return Float32
+}
+
+func (t SparseVectorType) IsVector() bool {
+	return false
+}
+
+// SparseVector is the value of a SparseVectorType feature. Indices[i] is
+// the index of the element with value Values[i], and elements not in
+// Indices are zero.
+type SparseVector struct {
+	Indices []uint32  `json:"indices"`
+	Values  []float32 `json:"values"`
+}
+
+// validate returns an error if the vector's indices and values don't pair
+// up, an index is repeated, or an index is out of range of dimension.
+func (v SparseVector) validate(dimension int32) error {
+	if len(v.Indices) != len(v.Values) {
+		return fmt.Errorf("sparse vector has %d indices but %d values", len(v.Indices), len(v.Values))
+	}
+	seen := make(map[uint32]bool, len(v.Indices))
+	for _, index := range v.Indices {
+		if seen[index] {
+			return fmt.Errorf("sparse vector index %d is repeated", index)
+		}
+		if dimension > 0 && index >= uint32(dimension) {
+			return fmt.Errorf("sparse vector index %d is out of range of dimension %d", index, dimension)
+		}
+		seen[index] = true
+	}
+	return nil
+}
+
+// ListType is a variable length list of scalars, such as []int64. Unlike
+// VectorType, it has no fixed dimension and isn't indexed for similarity.
+type ListType struct {
+	ElementType ScalarType
+}
+
+func (t ListType) Scalar() ScalarType {
+	return t.ElementType
+}
+
+func (t ListType) IsVector() bool {
+	return false
+}
+
+// goType returns the slice type that values of the list are returned as.
+func (t ListType) goType() (reflect.Type, error) {
+	elem, err := scalarGoType(t.ElementType)
+	if err != nil {
+		return nil, fmt.Errorf("unsupported list element type: %v", t.ElementType)
+	}
+	return reflect.SliceOf(elem), nil
+}
+
+// MapType is a map of scalars keyed by strings, such as per-category scores.
+// KeyType is kept so that other key types can be added later; only String is
+// currently supported.
+type MapType struct {
+	KeyType     ScalarType
+	ElementType ScalarType
+}
+
+func (t MapType) Scalar() ScalarType {
+	return t.ElementType
+}
+
+func (t MapType) IsVector() bool {
+	return false
+}
+
+// goType returns the map type that values of the map are returned as.
+func (t MapType) goType() (reflect.Type, error) {
+	if t.KeyType != String {
+		return nil, fmt.Errorf("unsupported map key type: %v", t.KeyType)
+	}
+	elem, err := scalarGoType(t.ElementType)
+	if err != nil {
+		return nil, fmt.Errorf("unsupported map element type: %v", t.ElementType)
+	}
+	return reflect.MapOf(reflect.TypeOf(""), elem), nil
+}
+
+// compositeType is implemented by value types made up of multiple scalars.
+// Providers without a native equivalent store their values as JSON.
+type compositeType interface {
+	ValueType
+	goType() (reflect.Type, error)
+}
+
+func scalarGoType(t ScalarType) (reflect.Type, error) {
+	var elem interface{}
+	switch t {
+	case Int:
+		elem = int(0)
+	case Int32:
+		elem = int32(0)
+	case Int64:
+		elem = int64(0)
+	case Float32:
+		elem = float32(0)
+	case Float64:
+		elem = float64(0)
+	case String:
+		elem = ""
+	case Bool:
+		elem = false
+	case Timestamp, Datetime:
+		elem = time.Time{}
+	default:
+		return nil, fmt.Errorf("unsupported scalar type: %v", t)
+	}
+	return reflect.TypeOf(elem), nil
+}
+
+// serializeComposite encodes a list or map value as JSON for providers that
+// can only store strings.
+func serializeComposite(t compositeType, value interface{}) ([]byte, error) {
+	goType, err := t.goType()
+	if err != nil {
+		return nil, err
+	}
+	kind := reflect.ValueOf(value).Kind()
+	if kind != goType.Kind() && !(kind == reflect.Array && goType.Kind() == reflect.Slice) {
+		return nil, fmt.Errorf("type %T of value %v is not a %v", value, value, t)
+	}
+	return json.Marshal(value)
+}
+
+// deserializeComposite decodes JSON into the Go type of t, such as []int64
+// or map[string]float64.
+func deserializeComposite(t compositeType, data []byte) (interface{}, error) {
+	goType, err := t.goType()
+	if err != nil {
+		return nil, err
+	}
+	ptr := reflect.New(goType)
+	if err := json.Unmarshal(data, ptr.Interface()); err != nil {
+		return nil, fmt.Errorf("could not cast value: %s to %v: %w", data, t, err)
+	}
+	return ptr.Elem().Interface(), nil
+}
+
+// castComposite converts a list or map returned by a provider's client,
+// usually a []interface{} or map[string]interface{} of the provider's native
+// types, into the Go type of t.
+func castComposite(t compositeType, value interface{}) (interface{}, error) {
+	serialized, err := serializeComposite(t, value)
+	if err != nil {
+		return nil, err
+	}
+	return deserializeComposite(t, serialized)
+}
+
+// ParseValueType parses a feature's type as stored in metadata, such as
+// "float64", "[]int64", or "map[string]float32". Embeddings are described by
+// more than their type and are constructed as a VectorType by the caller.
+func ParseValueType(featureType string) ValueType {
+	if strings.HasPrefix(featureType, "[]") {
+		return ListType{ElementType: ScalarType(strings.TrimPrefix(featureType, "[]"))}
+	}
+	if strings.HasPrefix(featureType, "map[") {
+		keyType, elemType, _ := strings.Cut(strings.TrimPrefix(featureType, "map["), "]")
+		return MapType{KeyType: ScalarType(keyType), ElementType: ScalarType(elemType)}
+	}
+	return ScalarType(featureType)
+}
+
+// ParseValue converts the string form of a value into the Go type that
+// online tables of valueType return. Vectors, lists, and maps are JSON.
+func ParseValue(valueType ValueType, value string) (interface{}, error) {
+	if sparseType, isSparse := valueType.(SparseVectorType); isSparse {
+		vector := SparseVector{}
+		if err := json.Unmarshal([]byte(value), &vector); err != nil {
+			return nil, fmt.Errorf("could not cast value: %v to %v: %w", value, valueType, err)
+		}
+		if err := vector.validate(sparseType.Dimension); err != nil {
+			return nil, err
+		}
+		return vector, nil
+	}
+	if composite, isComposite := valueType.(compositeType); isComposite {
+		return deserializeComposite(composite, []byte(value))
+	}
+	if valueType.IsVector() {
+		var vector []float32
+		if err := json.Unmarshal([]byte(value), &vector); err != nil {
+			return nil, fmt.Errorf("could not cast value: %v to %v: %w", value, valueType, err)
+		}
+		return vector, nil
+	}
+	return parseScalar(valueType.Scalar(), value)
+}
+
+func parseScalar(t ScalarType, val string) (interface{}, error) {
+	var result interface{}
+	var err error
+	switch t {
+	case NilType, String:
+		result, err = val, nil
+	case Int:
+		result, err = strconv.Atoi(val)
+	case Int32:
+		if result, err = strconv.ParseInt(val, 10, 32); err == nil {
+			result = int32(result.(int64))
+		}
+	case Int64:
+		result, err = strconv.ParseInt(val, 10, 64)
+	case Float32:
+		if result, err = strconv.ParseFloat(val, 32); err == nil {
+			result, err = float32(result.(float64)), nil
+		}
+	case Float64:
+		result, err = strconv.ParseFloat(val, 64)
+	case Bool:
+		result, err = strconv.ParseBool(val)
+	case Timestamp, Datetime: // Including `Datetime` here maintains compatibility with previously create timestamp tables
+		// Maintains compatibility with go-redis implementation:
+		// https://github.com/redis/go-redis/blob/v8.11.5/command.go#L939
+		result, err = time.Parse(time.RFC3339Nano, val)
+	default:
+		result, err = val, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("could not cast value: %v to %s: %w", val, t, err)
+	}
+	return result, nil
+}
+
 type ScalarType string
 
 func (t ScalarType) Scalar() ScalarType {
@@ -51,6 +469,10 @@ const (
 	Datetime  ScalarType = "datetime"
 )
 
+// Float16 is a half-precision float. It's only used as a VectorType's
+// StorageType, so it isn't one of the ScalarTypes.
+const Float16 ScalarType = "float16"
+
 var ScalarTypes = map[ScalarType]bool{
 	NilType:   true,
 	Int:       true,
@@ -69,6 +491,30 @@ type ValueTypeJSONWrapper struct {
 }
 
 func (vt *ValueTypeJSONWrapper) UnmarshalJSON(data []byte) error {
+	// Sparse vectors are wrapped in their own key, since they would
+	// otherwise unmarshal into a VectorType.
+	sparse := map[string]SparseVectorType{}
+	if err := json.Unmarshal(data, &sparse); err == nil {
+		if sparseType, has := sparse["SparseVectorType"]; has {
+			vt.ValueType = sparseType
+			return nil
+		}
+	}
+	// Maps and lists are checked first given they would otherwise unmarshal
+	// successfully into an empty VectorType, and a map would unmarshal into
+	// a ListType.
+	m := map[string]MapType{"ValueType": {}}
+	if err := json.Unmarshal(data, &m); err == nil && m["ValueType"].KeyType != NilType {
+		vt.ValueType = m["ValueType"]
+		return nil
+	}
+
+	l := map[string]ListType{"ValueType": {}}
+	if err := json.Unmarshal(data, &l); err == nil && l["ValueType"].ElementType != NilType {
+		vt.ValueType = l["ValueType"]
+		return nil
+	}
+
 	v := map[string]VectorType{"ValueType": {}}
 	if err := json.Unmarshal(data, &v); err == nil {
 		vt.ValueType = v["ValueType"]
@@ -88,9 +534,42 @@ func (vt ValueTypeJSONWrapper) MarshalJSON() ([]byte, error) {
 	switch vt.ValueType.(type) {
 	case VectorType:
 		return json.Marshal(map[string]VectorType{"ValueType": vt.ValueType.(VectorType)})
+	case ListType:
+		return json.Marshal(map[string]ListType{"ValueType": vt.ValueType.(ListType)})
+	case MapType:
+		return json.Marshal(map[string]MapType{"ValueType": vt.ValueType.(MapType)})
 	case ScalarType:
 		return json.Marshal(map[string]ScalarType{"ValueType": vt.ValueType.(ScalarType)})
+	case SparseVectorType:
+		return json.Marshal(map[string]SparseVectorType{"SparseVectorType": vt.ValueType.(SparseVectorType)})
 	default:
 		return nil, fmt.Errorf("could not marshal value type: %v", vt.ValueType)
 	}
 }
+
+// serializeValueType returns the string stored in provider metadata tables.
+// Scalars are stored as their bare names, as they were before other value
+// types existed, so previously created tables can still be read.
+func serializeValueType(t ValueType) (string, error) {
+	if scalar, isScalar := t.(ScalarType); isScalar {
+		return string(scalar), nil
+	}
+	serialized, err := json.Marshal(ValueTypeJSONWrapper{t})
+	if err != nil {
+		return "", err
+	}
+	return string(serialized), nil
+}
+
+func deserializeValueType(serialized string) (ValueType, error) {
+	if _, isScalar := ScalarTypes[ScalarType(serialized)]; isScalar {
+		return ScalarType(serialized), nil
+	}
+	wrapper := &ValueTypeJSONWrapper{}
+	if err := json.Unmarshal([]byte(serialized), wrapper); err != nil {
+		// Unknown bare strings are kept as scalars so that their values
+		// continue to be returned as strings.
+		return ScalarType(serialized), nil
+	}
+	return wrapper.ValueType, nil
+}
@@ -2,6 +2,7 @@ package provider
 
 import (
 	"encoding/json"
+	"reflect"
 	"testing"
 )
 
@@ -18,11 +19,26 @@ func TestVectorTypeUnmarshaling(t *testing.T) {
 			expected:   VectorType{ScalarType: Float32, Dimension: 384, IsEmbedding: true},
 			expectErr:  false,
 		},
+		{
+			serialized: []byte(`{"ValueType":{"ScalarType":"float32","Dimension":384,"IsEmbedding":true,"DistanceMetric":"l2"}}`),
+			expected:   VectorType{ScalarType: Float32, Dimension: 384, IsEmbedding: true, DistanceMetric: L2Distance},
+			expectErr:  false,
+		},
 		{
 			serialized: []byte(`{"ValueType":"float32"}`),
 			expected:   Float32,
 			expectErr:  false,
 		},
+		{
+			serialized: []byte(`{"ValueType":{"ElementType":"int64"}}`),
+			expected:   ListType{ElementType: Int64},
+			expectErr:  false,
+		},
+		{
+			serialized: []byte(`{"ValueType":{"KeyType":"string","ElementType":"float64"}}`),
+			expected:   MapType{KeyType: String, ElementType: Float64},
+			expectErr:  false,
+		},
 		{
 			serialized: []byte(`{"ValueType":{"ScalarType":"float32","Dimension":384,"IsEmbedding":true}}`),
 			expected:   Float32,
@@ -53,10 +69,26 @@ func TestVectorTypeMarshaling(t *testing.T) {
 			wrapped:  ValueTypeJSONWrapper{ValueType: VectorType{ScalarType: Float32, Dimension: 384, IsEmbedding: true}},
 			expected: []byte(`{"ValueType":{"ScalarType":"float32","Dimension":384,"IsEmbedding":true}}`),
 		},
+		{
+			wrapped:  ValueTypeJSONWrapper{ValueType: VectorType{ScalarType: Float32, Dimension: 384, IsEmbedding: true, DistanceMetric: InnerProductDistance}},
+			expected: []byte(`{"ValueType":{"ScalarType":"float32","Dimension":384,"IsEmbedding":true,"DistanceMetric":"inner_product"}}`),
+		},
+		{
+			wrapped:  ValueTypeJSONWrapper{ValueType: VectorType{ScalarType: Float32, Dimension: 384, IsEmbedding: true, Index: &IndexOptions{M: 32, EfRuntime: 64}}},
+			expected: []byte(`{"ValueType":{"ScalarType":"float32","Dimension":384,"IsEmbedding":true,"Index":{"M":32,"EfRuntime":64}}}`),
+		},
 		{
 			wrapped:  ValueTypeJSONWrapper{ValueType: Float32},
 			expected: []byte(`{"ValueType":"float32"}`),
 		},
+		{
+			wrapped:  ValueTypeJSONWrapper{ValueType: ListType{ElementType: String}},
+			expected: []byte(`{"ValueType":{"ElementType":"string"}}`),
+		},
+		{
+			wrapped:  ValueTypeJSONWrapper{ValueType: MapType{KeyType: String, ElementType: Int64}},
+			expected: []byte(`{"ValueType":{"KeyType":"string","ElementType":"int64"}}`),
+		},
 	}
 
 	for _, c := range cases {
@@ -69,3 +101,253 @@ func TestVectorTypeMarshaling(t *testing.T) {
 		}
 	}
 }
+
+func TestVectorTypeIndexOptions(t *testing.T) {
+	vectorType := VectorType{ScalarType: Float32, Dimension: 3, Index: &IndexOptions{NLists: 10}}
+	serialized, err := serializeValueType(vectorType)
+	if err != nil {
+		t.Fatalf("Failed to serialize: %s", err)
+	}
+	deserialized, err := deserializeValueType(serialized)
+	if err != nil {
+		t.Fatalf("Failed to deserialize: %s", err)
+	}
+	if !reflect.DeepEqual(deserialized, vectorType) {
+		t.Fatalf("Expected %+v, got %+v", vectorType, deserialized)
+	}
+	if options := (VectorType{}).Options(); options != (IndexOptions{}) {
+		t.Fatalf("Expected zero options, got %+v", options)
+	}
+	if err := (IndexOptions{NLists: 10}).validate("store", false); err == nil {
+		t.Fatalf("Succeeded with IVF options on an HNSW only store")
+	}
+	if err := (IndexOptions{M: -1}).validate("store", true); err == nil {
+		t.Fatalf("Succeeded with negative options")
+	}
+}
+
+func TestVectorTypeStorage(t *testing.T) {
+	tests := []struct {
+		name       string
+		vectorType VectorType
+		expected   ScalarType
+	}{
+		{"Float32", VectorType{ScalarType: Float32}, Float32},
+		{"Float64", VectorType{ScalarType: Float64}, Float64},
+		{"Unset", VectorType{}, Float32},
+		{"Half Precision", VectorType{ScalarType: Float64, StorageType: Float16}, Float16},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if storage := tt.vectorType.Storage(); storage != tt.expected {
+				t.Fatalf("Expected %s, got %s", tt.expected, storage)
+			}
+		})
+	}
+	if err := (VectorType{ScalarType: Float64}).validateStorage("store", Float32); err != nil {
+		t.Fatalf("Expected float64 vectors to be stored as float32: %s", err)
+	}
+	if err := (VectorType{ScalarType: Float32, StorageType: Float16}).validateStorage("store", Float32); err == nil {
+		t.Fatalf("Succeeded with an unsupported storage type")
+	}
+	if vector, ok := float32Vector([]float64{0.5, 2}); !ok || !reflect.DeepEqual(vector, []float32{0.5, 2}) {
+		t.Fatalf("Expected float64 vector to be converted, got %v", vector)
+	}
+	if _, ok := float32Vector([]int{1}); ok {
+		t.Fatalf("Converted a non-float vector")
+	}
+}
+
+func TestVectorTypeNames(t *testing.T) {
+	named := VectorType{ScalarType: Float32, Dimension: 2, Named: &NamedVectors{Names: []string{"title", "body"}}}
+	if err := named.validateNames("store", true); err != nil {
+		t.Fatalf("Failed to validate names: %s", err)
+	}
+	if err := named.validateNames("store", false); err == nil {
+		t.Fatalf("Succeeded with named vectors on a store without them")
+	}
+	if err := (VectorType{Named: &NamedVectors{Names: []string{"title", "title"}}}).validateNames("store", true); err == nil {
+		t.Fatalf("Succeeded with duplicate names")
+	}
+	if err := (VectorType{Named: &NamedVectors{Names: []string{""}}}).validateNames("store", true); err == nil {
+		t.Fatalf("Succeeded with an empty name")
+	}
+	if !named.hasName("body") || named.hasName("summary") {
+		t.Fatalf("Expected body and not summary to be names of %v", named)
+	}
+	wrapper := ValueTypeJSONWrapper{named}
+	serialized, err := json.Marshal(wrapper)
+	if err != nil {
+		t.Fatalf("Failed to serialize: %s", err)
+	}
+	deserialized := ValueTypeJSONWrapper{}
+	if err := json.Unmarshal(serialized, &deserialized); err != nil {
+		t.Fatalf("Failed to deserialize: %s", err)
+	}
+	if !reflect.DeepEqual(deserialized.ValueType, named) {
+		t.Fatalf("Expected %v, got %v", named, deserialized.ValueType)
+	}
+}
+
+func TestSparseVectorType(t *testing.T) {
+	sparseType := SparseVectorType{Dimension: 30522}
+	serialized, err := serializeValueType(sparseType)
+	if err != nil {
+		t.Fatalf("Failed to serialize: %s", err)
+	}
+	if deserialized, err := deserializeValueType(serialized); err != nil || deserialized != sparseType {
+		t.Fatalf("Expected %+v, got %+v %v", sparseType, deserialized, err)
+	}
+	value, err := ParseValue(sparseType, `{"indices":[4,9],"values":[0.5,1.25]}`)
+	if err != nil {
+		t.Fatalf("Failed to parse sparse vector: %s", err)
+	}
+	if expected := (SparseVector{Indices: []uint32{4, 9}, Values: []float32{0.5, 1.25}}); !reflect.DeepEqual(value, expected) {
+		t.Fatalf("Expected %v, got %v", expected, value)
+	}
+	if _, err := ParseValue(sparseType, `{"indices":[4],"values":[]}`); err == nil {
+		t.Fatalf("Succeeded in parsing a sparse vector with mismatched indices and values")
+	}
+}
+
+func TestParseDistanceMetric(t *testing.T) {
+	if metric, err := ParseDistanceMetric(""); err != nil || metric != CosineDistance {
+		t.Fatalf("Expected empty metric to be cosine, got %q %v", metric, err)
+	}
+	if metric, err := ParseDistanceMetric("inner_product"); err != nil || metric != InnerProductDistance {
+		t.Fatalf("Expected inner product, got %q %v", metric, err)
+	}
+	if _, err := ParseDistanceMetric("manhattan"); err == nil {
+		t.Fatalf("Succeeded in parsing an unknown metric")
+	}
+	if metric := (VectorType{ScalarType: Float32, Dimension: 2}).Metric(); metric != CosineDistance {
+		t.Fatalf("Expected vectors without a metric to use cosine, got %q", metric)
+	}
+}
+
+func TestListSerialization(t *testing.T) {
+	type testCase struct {
+		listType ListType
+		value    interface{}
+		expected interface{}
+	}
+
+	cases := []testCase{
+		{ListType{ElementType: Int64}, []int64{1, 2}, []int64{1, 2}},
+		{ListType{ElementType: Int}, []interface{}{int32(1), int64(2)}, []int{1, 2}},
+		{ListType{ElementType: Float32}, []float32{0.5}, []float32{0.5}},
+		{ListType{ElementType: String}, []string{}, []string{}},
+		{ListType{ElementType: Bool}, []interface{}{true, false}, []bool{true, false}},
+	}
+
+	for _, c := range cases {
+		actual, err := castComposite(c.listType, c.value)
+		if err != nil {
+			t.Errorf("failed to cast list due to unexpected error: %v", err)
+		}
+		if !reflect.DeepEqual(actual, c.expected) {
+			t.Errorf("expected %#v, got %#v", c.expected, actual)
+		}
+	}
+
+	if _, err := serializeComposite(ListType{ElementType: Int}, 1); err == nil {
+		t.Errorf("expected error serializing non-list value")
+	}
+	if _, err := deserializeComposite(ListType{ElementType: Int}, []byte(`["a"]`)); err == nil {
+		t.Errorf("expected error deserializing list of wrong type")
+	}
+}
+
+func TestMapSerialization(t *testing.T) {
+	type testCase struct {
+		mapType  MapType
+		value    interface{}
+		expected interface{}
+	}
+
+	cases := []testCase{
+		{MapType{KeyType: String, ElementType: Float64}, map[string]float64{"a": 0.5}, map[string]float64{"a": 0.5}},
+		{MapType{KeyType: String, ElementType: Int}, map[string]interface{}{"a": int32(1), "b": int64(2)}, map[string]int{"a": 1, "b": 2}},
+		{MapType{KeyType: String, ElementType: String}, map[string]string{}, map[string]string{}},
+	}
+
+	for _, c := range cases {
+		actual, err := castComposite(c.mapType, c.value)
+		if err != nil {
+			t.Errorf("failed to cast map due to unexpected error: %v", err)
+		}
+		if !reflect.DeepEqual(actual, c.expected) {
+			t.Errorf("expected %#v, got %#v", c.expected, actual)
+		}
+	}
+
+	if _, err := serializeComposite(MapType{KeyType: String, ElementType: Int}, []int{1}); err == nil {
+		t.Errorf("expected error serializing non-map value")
+	}
+	if _, err := castComposite(MapType{KeyType: Int, ElementType: Int}, map[string]int{"a": 1}); err == nil {
+		t.Errorf("expected error casting map with unsupported key type")
+	}
+}
+
+func TestValueTypeMetadataSerialization(t *testing.T) {
+	cases := []ValueType{
+		Int64,
+		ListType{ElementType: String},
+		MapType{KeyType: String, ElementType: Float32},
+		VectorType{ScalarType: Float32, Dimension: 3},
+	}
+	for _, c := range cases {
+		serialized, err := serializeValueType(c)
+		if err != nil {
+			t.Errorf("failed to serialize value type due to unexpected error: %v", err)
+		}
+		deserialized, err := deserializeValueType(serialized)
+		if err != nil {
+			t.Errorf("failed to deserialize value type due to unexpected error: %v", err)
+		}
+		if deserialized != c {
+			t.Errorf("expected %v, got %v", c, deserialized)
+		}
+	}
+	if serialized, _ := serializeValueType(Int64); serialized != "int64" {
+		t.Errorf("expected scalars to serialize to their names, got %s", serialized)
+	}
+}
+
+func TestParseValue(t *testing.T) {
+	tests := []struct {
+		featureType string
+		value       string
+		expected    interface{}
+	}{
+		{"int", "1", 1},
+		{"int32", "-2", int32(-2)},
+		{"float32", "0.5", float32(0.5)},
+		{"float64", "1.25", 1.25},
+		{"bool", "true", true},
+		{"string", "none", "none"},
+		{"[]int64", "[1, 2]", []int64{1, 2}},
+		{"map[string]float64", `{"a": 0.5}`, map[string]float64{"a": 0.5}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.featureType, func(t *testing.T) {
+			actual, err := ParseValue(ParseValueType(tt.featureType), tt.value)
+			if err != nil {
+				t.Fatalf("Failed to parse %s as %s: %s", tt.value, tt.featureType, err)
+			}
+			if !reflect.DeepEqual(actual, tt.expected) {
+				t.Fatalf("Values not equal %#v %#v", actual, tt.expected)
+			}
+		})
+	}
+	vector, err := ParseValue(VectorType{ScalarType: Float32, Dimension: 2}, "[0.5, 1]")
+	if err != nil {
+		t.Fatalf("Failed to parse vector: %s", err)
+	}
+	if !reflect.DeepEqual(vector, []float32{0.5, 1}) {
+		t.Fatalf("Values not equal %v %v", vector, []float32{0.5, 1})
+	}
+	if _, err := ParseValue(Int, "one"); err == nil {
+		t.Fatalf("Succeeded in parsing invalid int")
+	}
+}
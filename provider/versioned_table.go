@@ -0,0 +1,172 @@
+package provider
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	pc "github.com/featureform/provider/provider_config"
+)
+
+// versionedTable is a table in a FileStore whose writes are commits to a
+// log, like an Iceberg or Delta Lake table, so that each of its versions can
+// be read while newer ones are written.
+type versionedTable interface {
+	// Location is the key of the table's directory in the store.
+	Location() string
+	Format() pc.TableFormat
+	// CurrentVersion is the id of the table's current version, which is
+	// empty if nothing has been written to it.
+	CurrentVersion() (string, error)
+	// CurrentFiles are the data files of the table's current version.
+	CurrentFiles() ([]tableDataFile, error)
+}
+
+type tableDataFile struct {
+	Key         string
+	RecordCount int64
+}
+
+// openVersionedTable returns the versioned table at location, or nil if
+// there isn't one.
+func openVersionedTable(store FileStore, location string) (versionedTable, error) {
+	location = strings.TrimSuffix(location, "/")
+	candidates := []struct {
+		table versionedTable
+		log   string
+		file  FileType
+	}{
+		{newIcebergTable(store, location), fmt.Sprintf("%s/metadata/", location), icebergMetadataFile},
+		{newDeltaTable(store, location), fmt.Sprintf("%s/%s/", location, deltaLogDirectory), deltaCommitFile},
+	}
+	for _, candidate := range candidates {
+		key, err := store.NewestFileOfType(candidate.log, candidate.file)
+		if err != nil {
+			return nil, fmt.Errorf("could not check if %s is a versioned table: %w", location, err)
+		}
+		if key != "" {
+			return candidate.table, nil
+		}
+	}
+	return nil, nil
+}
+
+// versionedSourcePath is the source that a Spark job reads the current
+// version of a table from: "<format>:<remote location>@<version>". Pinning
+// the version means that all of a job's sources are read as of when it was
+// submitted, and commits while it runs aren't read.
+func versionedSourcePath(store SparkFileStore, table versionedTable) (string, error) {
+	version, err := table.CurrentVersion()
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%s:%s@%s", table.Format(), store.PathWithPrefix(table.Location(), true), version), nil
+}
+
+// isVersionedSourcePath is true for sources that are already in the form of
+// versionedSourcePath, such as primary tables that are registered with it.
+func isVersionedSourcePath(path string) bool {
+	for _, format := range []pc.TableFormat{pc.IcebergTableFormat, pc.DeltaTableFormat} {
+		if strings.HasPrefix(path, fmt.Sprintf("%s:", format)) {
+			return true
+		}
+	}
+	return false
+}
+
+// serveTableFiles iterates over the rows of the data files in order.
+func serveTableFiles(store FileStore, files []tableDataFile) (Iterator, error) {
+	if len(files) == 0 {
+		return emptyIterator{}, nil
+	}
+	keys := make([]string, len(files))
+	for i, file := range files {
+		keys[i] = file.Key
+	}
+	return parquetIteratorOverMultipleFiles(keys, store)
+}
+
+type emptyIterator struct{}
+
+func (emptyIterator) Next() (map[string]interface{}, error) {
+	return nil, nil
+}
+
+func (emptyIterator) FeatureColumns() []string {
+	return []string{}
+}
+
+func (emptyIterator) LabelColumn() string {
+	return ""
+}
+
+// tableMaterialization reads the version of a materialization's table that
+// was current when it was opened, so that every segment that's copied to the
+// online store is from the same version of it.
+type tableMaterialization struct {
+	id    ResourceID
+	store FileStore
+	files []tableDataFile
+	// location is the time zone of timestamps that were materialized without
+	// one. If it's nil, they're in UTC.
+	location *time.Location
+}
+
+func newTableMaterialization(id ResourceID, store FileStore, table versionedTable) (*tableMaterialization, error) {
+	files, err := table.CurrentFiles()
+	if err != nil {
+		return nil, err
+	}
+	return &tableMaterialization{id: id, store: store, files: files}, nil
+}
+
+func (mat *tableMaterialization) ID() MaterializationID {
+	return MaterializationID(fmt.Sprintf("%s/%s/%s", FeatureMaterialization, mat.id.Name, mat.id.Variant))
+}
+
+func (mat *tableMaterialization) NumRows() (int64, error) {
+	rows := int64(0)
+	for _, file := range mat.files {
+		rows += file.RecordCount
+	}
+	return rows, nil
+}
+
+func (mat *tableMaterialization) IterateSegment(begin, end int64) (FeatureIterator, error) {
+	iter, err := serveTableFiles(mat.store, mat.files)
+	if err != nil {
+		return nil, err
+	}
+	for i := int64(0); i < begin; i++ {
+		_, _ = iter.Next()
+	}
+	return &FileStoreFeatureIterator{
+		iter:     iter,
+		curIdx:   0,
+		maxIdx:   end,
+		location: mat.location,
+	}, nil
+}
+
+// NumPartitions is the number of the version's data files, which are each a
+// partition.
+func (mat *tableMaterialization) NumPartitions() (int, error) {
+	return len(mat.files), nil
+}
+
+func (mat *tableMaterialization) IteratePartition(partition int) (FeatureIterator, error) {
+	if partition < 0 || partition >= len(mat.files) {
+		return nil, fmt.Errorf("materialization %s has no partition %d", mat.ID(), partition)
+	}
+	file := mat.files[partition]
+	iter, err := serveTableFiles(mat.store, []tableDataFile{file})
+	if err != nil {
+		return nil, err
+	}
+	return &FileStoreFeatureIterator{
+		iter:     iter,
+		curIdx:   0,
+		maxIdx:   file.RecordCount,
+		location: mat.location,
+	}, nil
+}
@@ -0,0 +1,129 @@
+package provider
+
+import (
+	"fmt"
+	"testing"
+
+	pc "github.com/featureform/provider/provider_config"
+)
+
+func TestSparkLatestSourcePath(t *testing.T) {
+	config := pc.LocalFileStoreConfig{DirPath: fmt.Sprintf("file:///%s", t.TempDir())}
+	serialized, err := config.Serialize()
+	if err != nil {
+		t.Fatalf("Failed to serialize file store config: %s", err)
+	}
+	store, err := NewSparkLocalFileStore(serialized)
+	if err != nil {
+		t.Fatalf("Failed to create file store: %s", err)
+	}
+	writeTestDeltaTable(t, store)
+	writeTestIcebergTable(t, store)
+	spark := &SparkOfflineStore{Store: store, tableFormat: pc.ParquetTableFormat}
+	// The test tables share a location, so the Iceberg table is found first.
+	path, err := spark.latestSourcePath(testIcebergKey)
+	if err != nil {
+		t.Fatalf("Failed to get source path: %s", err)
+	}
+	if expected := fmt.Sprintf("iceberg:%s@2", store.PathWithPrefix(testIcebergKey, true)); path != expected {
+		t.Fatalf("Expected %s, got %s", expected, path)
+	}
+	delta, err := versionedSourcePath(store, newDeltaTable(store, testDeltaKey))
+	if err != nil {
+		t.Fatalf("Failed to get source path: %s", err)
+	}
+	if expected := fmt.Sprintf("delta:%s@2", store.PathWithPrefix(testDeltaKey, true)); delta != expected {
+		t.Fatalf("Expected %s, got %s", expected, delta)
+	}
+	pinned := "delta:s3://bucket/table@5"
+	if path, err := spark.latestSourcePath(pinned); err != nil || path != pinned {
+		t.Fatalf("Expected %s, got %s: %v", pinned, path, err)
+	}
+	if err := store.Write("featureform/Primary/source/file.parquet", []byte{}); err != nil {
+		t.Fatalf("Failed to write file: %s", err)
+	}
+	path, err = spark.latestSourcePath("featureform/Primary/source")
+	if err != nil {
+		t.Fatalf("Failed to get source path: %s", err)
+	}
+	if expected := store.PathWithPrefix("featureform/Primary/source/file.parquet", true); path != expected {
+		t.Fatalf("Expected %s, got %s", expected, path)
+	}
+}
+
+func TestSparkTableFormatArgs(t *testing.T) {
+	if _, err := sparkTableFormat(pc.SparkConfig{TableFormat: pc.IcebergTableFormat}); err == nil {
+		t.Fatalf("Expected a catalog and namespace to be required")
+	}
+	if _, err := sparkTableFormat(pc.SparkConfig{TableFormat: "orc"}); err == nil {
+		t.Fatalf("Expected an unknown table format to be rejected")
+	}
+	spark := &SparkOfflineStore{tableFormat: pc.ParquetTableFormat}
+	id := ResourceID{Name: "Feature Name", Variant: "v-1", Type: Feature}
+	if args := spark.tableFormatArgs(id, false); args != nil {
+		t.Fatalf("Expected no arguments for parquet, got %v", args)
+	}
+	spark.tableFormat = pc.DeltaTableFormat
+	expected := []string{"--table_format", "delta", "--write_mode", "create"}
+	if args := spark.tableFormatArgs(id, false); fmt.Sprint(args) != fmt.Sprint(expected) {
+		t.Fatalf("Expected %v, got %v", expected, args)
+	}
+	spark.tableFormat = pc.IcebergTableFormat
+	spark.iceberg = pc.IcebergConfig{Catalog: "glue", Namespace: "featureform"}
+	table := spark.icebergTableName(id)
+	expected = []string{"--table_format", "iceberg", "--write_mode", "overwrite", "--iceberg_table", table}
+	if args := spark.tableFormatArgs(id, true); fmt.Sprint(args) != fmt.Sprint(expected) {
+		t.Fatalf("Expected %v, got %v", expected, args)
+	}
+	prefix := "glue.featureform.featureform_feature__feature_name__v_1_"
+	if len(table) != len(prefix)+8 || table[:len(prefix)] != prefix {
+		t.Fatalf("Unexpected table name %s", table)
+	}
+	other := spark.icebergTableName(ResourceID{Name: "feature_name", Variant: "v_1", Type: Feature})
+	if other == table {
+		t.Fatalf("Expected names that sanitize the same to be unique, got %s", table)
+	}
+}
+
+func TestSparkCatalogSources(t *testing.T) {
+	if _, err := sparkCatalogConfigs(pc.CatalogConfig{Type: pc.HiveCatalog}); err == nil {
+		t.Fatalf("Expected a metastore uri to be required")
+	}
+	if _, err := sparkCatalogConfigs(pc.CatalogConfig{Type: "unity"}); err == nil {
+		t.Fatalf("Expected an unknown catalog to be rejected")
+	}
+	spark := &SparkOfflineStore{tableFormat: pc.ParquetTableFormat}
+	if _, ok := spark.catalogSourcePath("db.table"); ok {
+		t.Fatalf("Expected sources to be paths without a catalog")
+	}
+	configs, err := sparkCatalogConfigs(pc.CatalogConfig{Type: pc.GlueCatalog, GlueRegion: "us-east-1"})
+	if err != nil {
+		t.Fatalf("Failed to get catalog configs: %s", err)
+	}
+	spark.catalogConfigs = configs
+	id := ResourceID{Name: "feature", Variant: "variant", Type: Feature}
+	expected := []string{
+		"--catalog_config", `"spark.sql.catalogImplementation=hive"`,
+		"--catalog_config", `"spark.hadoop.hive.metastore.client.factory.class=com.amazonaws.glue.catalog.metastore.AWSGlueDataCatalogHiveClientFactory"`,
+		"--catalog_config", `"spark.hadoop.aws.region=us-east-1"`,
+	}
+	if args := spark.jobArgs(id, false); fmt.Sprint(args) != fmt.Sprint(expected) {
+		t.Fatalf("Expected %v, got %v", expected, args)
+	}
+	sources := map[string]string{
+		"db.table":                   "catalog:db.table",
+		"glue_catalog.db.table":      "catalog:glue_catalog.db.table",
+		"transactions.parquet":       "",
+		"data.csv":                   "",
+		"table":                      "",
+		"s3://bucket/db.table":       "",
+		"a.b.c.d":                    "",
+		"featureform/Primary/a/b.db": "",
+	}
+	for name, expected := range sources {
+		source, ok := spark.catalogSourcePath(name)
+		if ok != (expected != "") || source != expected {
+			t.Errorf("Expected %s to be read from %q, got %q", name, expected, source)
+		}
+	}
+}
@@ -0,0 +1,634 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package provider
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"math"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	pc "github.com/featureform/provider/provider_config"
+	pt "github.com/featureform/provider/provider_type"
+)
+
+const (
+	vespaSchemaPrefix   = "featureform_"
+	vespaMetadataSchema = "featureform_metadata"
+	vespaNamespace      = "featureform"
+	vespaCluster        = "featureform"
+	vespaRankProfile    = "featureform_closest"
+	vespaDeployPath     = "/application/v2/tenant/default/prepareandactivate"
+)
+
+// vespaConvergenceTimeout bounds how long CreateIndex waits for a newly
+// deployed schema to accept documents.
+var vespaConvergenceTimeout = 2 * time.Minute
+
+func vespaOnlineStoreFactory(serialized pc.SerializedConfig) (Provider, error) {
+	config := &pc.VespaConfig{}
+	if err := config.Deserialize(serialized); err != nil {
+		return nil, err
+	}
+	return NewVespaOnlineStore(config)
+}
+
+// vespaOnlineStore is a VectorStore with one Vespa schema per feature
+// variant. Each entity is a document with the entity as its ID, so writing
+// an entity again replaces its vector. The feature, variant and value type
+// of each schema are kept as documents of a metadata schema, which the
+// deployed application package is generated from.
+type vespaOnlineStore struct {
+	client       *jsonHTTPClient
+	configClient *jsonHTTPClient
+	// deployMu serializes deployments from this store, since each one
+	// replaces the whole application. Deployments from other processes can
+	// still race.
+	deployMu sync.Mutex
+	BaseProvider
+}
+
+func NewVespaOnlineStore(config *pc.VespaConfig) (*vespaOnlineStore, error) {
+	if config.URL == "" || config.ConfigURL == "" {
+		return nil, fmt.Errorf("vespa URL and ConfigURL are required")
+	}
+	header := make(http.Header)
+	if config.Token != "" {
+		header.Set("Authorization", "Bearer "+config.Token)
+	}
+	return &vespaOnlineStore{
+		client:       newJSONHTTPClient(config.URL, header, nil),
+		configClient: newJSONHTTPClient(config.ConfigURL, header, nil),
+		BaseProvider: BaseProvider{
+			ProviderType:   pt.VespaOnline,
+			ProviderConfig: config.Serialized(),
+		},
+	}, nil
+}
+
+// vespaSchemaName returns the schema and document type of a feature
+// variant. Schema names can only contain letters, digits and underscores,
+// so the feature and variant are hashed.
+func vespaSchemaName(feature, variant string) string {
+	hash := sha256.Sum256([]byte(feature + "\x00" + variant))
+	return vespaSchemaPrefix + hex.EncodeToString(hash[:16])
+}
+
+func vespaDocumentPath(schema, id string) string {
+	return fmt.Sprintf("/document/v1/%s/%s/docid/%s", vespaNamespace, schema, url.PathEscape(id))
+}
+
+// isVespaMissing returns true if err is caused by a document, or a document
+// type that hasn't been deployed yet, not existing.
+func isVespaMissing(err error) bool {
+	if isHTTPStatus(err, http.StatusNotFound) {
+		return true
+	}
+	return isHTTPStatus(err, http.StatusBadRequest) && strings.Contains(strings.ToLower(err.Error()), "document type")
+}
+
+// vespaMetadata is a document of the metadata schema. Like Redis,
+// materializations create a feature's index and then its table, so IsTable
+// records whether CreateTable has been called for the schema.
+type vespaMetadata struct {
+	Feature   string `json:"feature"`
+	Variant   string `json:"variant"`
+	ValueType string `json:"value_type"`
+	IsTable   bool   `json:"is_table"`
+}
+
+func (store *vespaOnlineStore) AsOnlineStore() (OnlineStore, error) {
+	return store, nil
+}
+
+func (store *vespaOnlineStore) AsVectorStore() (VectorStore, error) {
+	return store, nil
+}
+
+func (store *vespaOnlineStore) Close() error {
+	return nil
+}
+
+func (store *vespaOnlineStore) getMetadata(feature, variant string) (vespaMetadata, ValueType, error) {
+	var document struct {
+		Fields vespaMetadata `json:"fields"`
+	}
+	path := vespaDocumentPath(vespaMetadataSchema, vespaSchemaName(feature, variant))
+	err := store.client.do(context.TODO(), http.MethodGet, path, nil, &document)
+	if isVespaMissing(err) {
+		return document.Fields, nil, &TableNotFound{feature, variant}
+	} else if err != nil {
+		return document.Fields, nil, err
+	}
+	valueType, err := deserializeValueType(document.Fields.ValueType)
+	if err != nil {
+		return document.Fields, nil, err
+	}
+	return document.Fields, valueType, nil
+}
+
+// listMetadata returns the metadata of every schema by visiting the
+// metadata documents.
+func (store *vespaOnlineStore) listMetadata() ([]vespaMetadata, error) {
+	metadata := []vespaMetadata{}
+	continuation := ""
+	for {
+		query := url.Values{"cluster": []string{vespaCluster}, "wantedDocumentCount": []string{"100"}}
+		if continuation != "" {
+			query.Set("continuation", continuation)
+		}
+		var response struct {
+			Documents []struct {
+				Fields vespaMetadata `json:"fields"`
+			} `json:"documents"`
+			Continuation string `json:"continuation"`
+		}
+		path := fmt.Sprintf("/document/v1/%s/%s/docid?%s", vespaNamespace, vespaMetadataSchema, query.Encode())
+		err := store.client.do(context.TODO(), http.MethodGet, path, nil, &response)
+		if isVespaMissing(err) {
+			return metadata, nil
+		} else if err != nil {
+			return nil, fmt.Errorf("could not list vespa schemas: %w", err)
+		}
+		for _, document := range response.Documents {
+			metadata = append(metadata, document.Fields)
+		}
+		if response.Continuation == "" {
+			return metadata, nil
+		}
+		continuation = response.Continuation
+	}
+}
+
+func (store *vespaOnlineStore) table(feature, variant string, valueType ValueType) (*vespaOnlineTable, error) {
+	vectorType, ok := valueType.(VectorType)
+	if !ok {
+		return nil, fmt.Errorf("vespa only stores vectors, not %v", valueType)
+	}
+	return &vespaOnlineTable{
+		client:    store.client,
+		schema:    vespaSchemaName(feature, variant),
+		valueType: vectorType,
+	}, nil
+}
+
+func (store *vespaOnlineStore) GetTable(feature, variant string) (OnlineStoreTable, error) {
+	_, valueType, err := store.getMetadata(feature, variant)
+	if err != nil {
+		return nil, err
+	}
+	return store.table(feature, variant, valueType)
+}
+
+// CreateTable deploys the feature variant's schema, or marks the schema
+// deployed by CreateIndex as a table.
+func (store *vespaOnlineStore) CreateTable(feature, variant string, valueType ValueType) (OnlineStoreTable, error) {
+	metadata, existingType, err := store.getMetadata(feature, variant)
+	if _, notFound := err.(*TableNotFound); notFound {
+		return store.createSchema(feature, variant, valueType, true)
+	} else if err != nil {
+		return nil, err
+	}
+	if metadata.IsTable {
+		return nil, &TableAlreadyExists{feature, variant}
+	}
+	update := map[string]interface{}{"fields": map[string]interface{}{"is_table": map[string]bool{"assign": true}}}
+	path := vespaDocumentPath(vespaMetadataSchema, vespaSchemaName(feature, variant))
+	if err := store.client.do(context.TODO(), http.MethodPut, path, update, nil); err != nil {
+		return nil, fmt.Errorf("could not update vespa metadata: %w", err)
+	}
+	return store.table(feature, variant, existingType)
+}
+
+func (store *vespaOnlineStore) CreateIndex(feature, variant string, vectorType VectorType) (VectorStoreTable, error) {
+	return store.createSchema(feature, variant, vectorType, false)
+}
+
+// createSchema deploys an application package with the new schema added to
+// those of the existing tables, then records its metadata once the schema
+// accepts documents.
+func (store *vespaOnlineStore) createSchema(feature, variant string, valueType ValueType, isTable bool) (*vespaOnlineTable, error) {
+	table, err := store.table(feature, variant, valueType)
+	if err != nil {
+		return nil, err
+	}
+	if err := table.valueType.Options().validate("vespa", false); err != nil {
+		return nil, err
+	}
+	if err := table.valueType.validateStorage("vespa", Float32); err != nil {
+		return nil, err
+	}
+	if err := table.valueType.validateNames("vespa", false); err != nil {
+		return nil, err
+	}
+	serializedType, err := serializeValueType(valueType)
+	if err != nil {
+		return nil, err
+	}
+	store.deployMu.Lock()
+	defer store.deployMu.Unlock()
+	if _, _, err := store.getMetadata(feature, variant); err == nil {
+		return nil, &TableAlreadyExists{feature, variant}
+	} else if _, notFound := err.(*TableNotFound); !notFound {
+		return nil, err
+	}
+	existing, err := store.listMetadata()
+	if err != nil {
+		return nil, err
+	}
+	metadata := vespaMetadata{Feature: feature, Variant: variant, ValueType: serializedType, IsTable: isTable}
+	if err := store.deploy(append(existing, metadata), false); err != nil {
+		return nil, err
+	}
+	path := vespaDocumentPath(vespaMetadataSchema, table.schema)
+	document := map[string]interface{}{"fields": metadata}
+	deadline := time.Now().Add(vespaConvergenceTimeout)
+	for {
+		err := store.client.do(context.TODO(), http.MethodPost, path, document, nil)
+		if err == nil {
+			return table, nil
+		}
+		if time.Now().After(deadline) {
+			return nil, fmt.Errorf("vespa schema %s did not accept documents after deployment: %w", table.schema, err)
+		}
+		time.Sleep(time.Second)
+	}
+}
+
+func (store *vespaOnlineStore) DeleteTable(feature, variant string) error {
+	schema := vespaSchemaName(feature, variant)
+	store.deployMu.Lock()
+	defer store.deployMu.Unlock()
+	if _, _, err := store.getMetadata(feature, variant); err != nil {
+		return err
+	}
+	if err := store.client.do(context.TODO(), http.MethodDelete, vespaDocumentPath(vespaMetadataSchema, schema), nil, nil); err != nil {
+		return fmt.Errorf("could not delete vespa metadata: %w", err)
+	}
+	remaining, err := store.listMetadata()
+	if err != nil {
+		return err
+	}
+	kept := remaining[:0]
+	for _, metadata := range remaining {
+		if vespaSchemaName(metadata.Feature, metadata.Variant) != schema {
+			kept = append(kept, metadata)
+		}
+	}
+	return store.deploy(kept, true)
+}
+
+// Truncate deletes every document of the schema, keeping the schema.
+func (store *vespaOnlineStore) Truncate(feature, variant string) error {
+	if _, _, err := store.getMetadata(feature, variant); err != nil {
+		return err
+	}
+	query := url.Values{"cluster": []string{vespaCluster}, "selection": []string{"true"}}
+	path := fmt.Sprintf("/document/v1/%s/%s/docid?%s", vespaNamespace, vespaSchemaName(feature, variant), query.Encode())
+	return store.client.do(context.TODO(), http.MethodDelete, path, nil, nil)
+}
+
+func (store *vespaOnlineStore) Capabilities() OnlineCapabilities {
+	return OnlineCapabilities{
+		Truncate:         true,
+		Vectors:          true,
+		VectorFilters:    true,
+		VectorNamespaces: true,
+	}
+}
+
+// deploy deploys an application package with a schema for each table.
+// Removing a document type deletes its documents, which Vespa only allows
+// with a validation override, so allowRemoval adds one.
+func (store *vespaOnlineStore) deploy(tables []vespaMetadata, allowRemoval bool) error {
+	files, err := vespaApplicationPackage(tables, allowRemoval, time.Now())
+	if err != nil {
+		return err
+	}
+	var archive bytes.Buffer
+	writer := zip.NewWriter(&archive)
+	names := make([]string, 0, len(files))
+	for name := range files {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		file, err := writer.Create(name)
+		if err != nil {
+			return err
+		}
+		if _, err := file.Write([]byte(files[name])); err != nil {
+			return err
+		}
+	}
+	if err := writer.Close(); err != nil {
+		return err
+	}
+	if err := store.configClient.send(context.TODO(), http.MethodPost, vespaDeployPath, "application/zip", archive.Bytes(), nil); err != nil {
+		return fmt.Errorf("could not deploy vespa application: %w", err)
+	}
+	return nil
+}
+
+// vespaApplicationPackage returns the files of the application package,
+// keyed by path, for a single content node.
+func vespaApplicationPackage(tables []vespaMetadata, allowRemoval bool, now time.Time) (map[string]string, error) {
+	files := map[string]string{
+		"schemas/" + vespaMetadataSchema + ".sd": vespaMetadataSchemaDefinition,
+	}
+	documents := []string{vespaMetadataSchema}
+	for _, table := range tables {
+		valueType, err := deserializeValueType(table.ValueType)
+		if err != nil {
+			return nil, err
+		}
+		vectorType, ok := valueType.(VectorType)
+		if !ok {
+			return nil, fmt.Errorf("vespa only stores vectors, not %v", valueType)
+		}
+		schema := vespaSchemaName(table.Feature, table.Variant)
+		files["schemas/"+schema+".sd"] = vespaSchemaDefinition(schema, vectorType)
+		documents = append(documents, schema)
+	}
+	sort.Strings(documents)
+	var services strings.Builder
+	services.WriteString("<services version=\"1.0\">\n")
+	services.WriteString("  <container id=\"featureform_container\" version=\"1.0\">\n    <document-api/>\n    <search/>\n  </container>\n")
+	services.WriteString("  <content id=\"" + vespaCluster + "\" version=\"1.0\">\n    <redundancy>1</redundancy>\n    <documents>\n")
+	for _, document := range documents {
+		services.WriteString("      <document type=\"" + document + "\" mode=\"index\"/>\n")
+	}
+	services.WriteString("    </documents>\n    <nodes>\n      <node hostalias=\"node1\" distribution-key=\"0\"/>\n    </nodes>\n  </content>\n</services>\n")
+	files["services.xml"] = services.String()
+	if allowRemoval {
+		until := now.UTC().AddDate(0, 0, 1).Format("2006-01-02")
+		files["validation-overrides.xml"] = fmt.Sprintf("<validation-overrides>\n  <allow until=\"%s\">content-type-removal</allow>\n</validation-overrides>\n", until)
+	}
+	return files, nil
+}
+
+const vespaMetadataSchemaDefinition = `schema featureform_metadata {
+    document featureform_metadata {
+        field feature type string {
+            indexing: summary
+        }
+        field variant type string {
+            indexing: summary
+        }
+        field value_type type string {
+            indexing: summary
+        }
+        field is_table type bool {
+            indexing: summary | attribute
+        }
+    }
+}
+`
+
+var vespaDistanceMetrics = map[DistanceMetric]string{
+	CosineDistance:       "angular",
+	L2Distance:           "euclidean",
+	InnerProductDistance: "dotproduct",
+}
+
+// vespaSchemaDefinition returns a schema with an HNSW indexed tensor field
+// for the vectors and a rank profile that orders documents by closeness.
+func vespaSchemaDefinition(schema string, vectorType VectorType) string {
+	tensorType := fmt.Sprintf("tensor<float>(x[%d])", vectorType.Dimension)
+	options := vectorType.Options()
+	maxLinks, exploreAtInsert := options.M, options.EfConstruction
+	if maxLinks == 0 {
+		maxLinks = 16
+	}
+	if exploreAtInsert == 0 {
+		exploreAtInsert = 200
+	}
+	return fmt.Sprintf(`schema %[1]s {
+    document %[1]s {
+        field entity type string {
+            indexing: summary | attribute
+        }
+        field value type %[2]s {
+            indexing: summary | attribute | index
+            attribute {
+                distance-metric: %[4]s
+            }
+            index {
+                hnsw {
+                    max-links-per-node: %[5]d
+                    neighbors-to-explore-at-insert: %[6]d
+                }
+            }
+        }
+        field attributes type array<string> {
+            indexing: attribute
+            attribute: fast-search
+        }
+        field updated_at type long {
+            indexing: attribute
+            attribute: fast-search
+        }
+        field namespace type string {
+            indexing: attribute
+            attribute: fast-search
+        }
+    }
+    rank-profile %[3]s {
+        inputs {
+            query(q) %[2]s
+        }
+        first-phase {
+            expression: closeness(field, value)
+        }
+        match-features: distance(field, value)
+    }
+}
+`, schema, tensorType, vespaRankProfile, vespaDistanceMetrics[vectorType.Metric()], maxLinks, exploreAtInsert)
+}
+
+type vespaOnlineTable struct {
+	client    *jsonHTTPClient
+	schema    string
+	valueType VectorType
+}
+
+type vespaTensor struct {
+	Values []float32 `json:"values"`
+}
+
+func (table *vespaOnlineTable) Set(entity string, value interface{}) error {
+	vector, err := vectorValue(table.valueType, entity, value)
+	if err != nil {
+		return err
+	}
+	return table.SetWithAttributes(entity, vector, nil)
+}
+
+// SetWithAttributes writes the entity's document with its attributes as
+// key=value terms and its write time in Unix milliseconds.
+func (table *vespaOnlineTable) SetWithAttributes(entity string, vector []float32, attributes map[string]string) error {
+	if err := checkVectorDimension(table.valueType, entity, vector); err != nil {
+		return err
+	}
+	terms, err := vectorAttributeTerms(attributes)
+	if err != nil {
+		return err
+	}
+	return table.put(entity, vector, terms, "")
+}
+
+func (table *vespaOnlineTable) SetInNamespace(namespace, entity string, vector []float32) error {
+	if err := validateNamespace(namespace); err != nil {
+		return err
+	}
+	if err := checkVectorDimension(table.valueType, entity, vector); err != nil {
+		return err
+	}
+	return table.put(entity, vector, []string{}, namespace)
+}
+
+// put replaces the entity's document, so writing it without a namespace
+// removes it from its old one.
+func (table *vespaOnlineTable) put(entity string, vector []float32, terms []string, namespace string) error {
+	fields := map[string]interface{}{
+		"entity":     entity,
+		"value":      vespaTensor{Values: vector},
+		"attributes": terms,
+		"updated_at": time.Now().UnixMilli(),
+	}
+	if namespace != "" {
+		fields["namespace"] = namespace
+	}
+	document := map[string]interface{}{"fields": fields}
+	return table.client.do(context.TODO(), http.MethodPost, vespaDocumentPath(table.schema, entity), document, nil)
+}
+
+func (table *vespaOnlineTable) Get(entity string) (interface{}, error) {
+	var document struct {
+		Fields struct {
+			Value vespaTensor `json:"value"`
+		} `json:"fields"`
+	}
+	err := table.client.do(context.TODO(), http.MethodGet, vespaDocumentPath(table.schema, entity), nil, &document)
+	if isHTTPStatus(err, http.StatusNotFound) {
+		return nil, &EntityNotFound{entity}
+	} else if err != nil {
+		return nil, err
+	}
+	return document.Fields.Value.Values, nil
+}
+
+func (table *vespaOnlineTable) Delete(entity string) error {
+	err := table.client.do(context.TODO(), http.MethodDelete, vespaDocumentPath(table.schema, entity), nil, nil)
+	if isHTTPStatus(err, http.StatusNotFound) {
+		return nil
+	}
+	return err
+}
+
+// vespaQuote returns s as a YQL string literal.
+func vespaQuote(s string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(s) + `"`
+}
+
+// vespaWhere returns the YQL conditions of a filter to and with the
+// nearestNeighbor operator. Vespa applies them while searching the HNSW
+// index, so they don't reduce the number of hits.
+func vespaWhere(filter VectorFilter) (string, error) {
+	terms, err := vectorAttributeTerms(filter.Attributes)
+	if err != nil {
+		return "", err
+	}
+	var where strings.Builder
+	for _, term := range terms {
+		fmt.Fprintf(&where, " and attributes contains %s", vespaQuote(term))
+	}
+	if !filter.UpdatedAfter.IsZero() {
+		fmt.Fprintf(&where, " and updated_at >= %d", filter.UpdatedAfter.UnixMilli())
+	}
+	if filter.Namespace != "" {
+		fmt.Fprintf(&where, " and namespace contains %s", vespaQuote(filter.Namespace))
+	}
+	return where.String(), nil
+}
+
+// nearestAnnotations returns the nearestNeighbor annotations of a search
+// for k hits. Vespa explores targetHits plus exploreAdditionalHits
+// candidates, so EfRuntime is the sum of the two.
+func (table *vespaOnlineTable) nearestAnnotations(k int32) string {
+	annotations := fmt.Sprintf("targetHits:%d", k)
+	if ef := table.valueType.Options().EfRuntime; ef > k {
+		annotations += fmt.Sprintf(",hnsw.exploreAdditionalHits:%d", ef-k)
+	}
+	return annotations
+}
+
+// Nearest returns the entities of the k documents nearest to vector that
+// match filter with a nearestNeighbor query, which uses the schema's HNSW
+// index.
+func (table *vespaOnlineTable) Nearest(feature, variant string, vector []float32, k int32, filter VectorFilter) ([]NearestResult, error) {
+	return table.nearest(vector, 0, k, filter)
+}
+
+func (table *vespaOnlineTable) NearestPage(feature, variant string, vector []float32, pageSize int32, filter VectorFilter, cursor string) (NearestPage, error) {
+	search := func(offset, k int32) ([]NearestResult, error) {
+		return table.nearest(vector, offset, k, filter)
+	}
+	return nearestPage(search, pageSize, cursor)
+}
+
+// nearest returns the k documents after the nearest offset documents, so
+// the search targets offset+k hits.
+func (table *vespaOnlineTable) nearest(vector []float32, offset, k int32, filter VectorFilter) ([]NearestResult, error) {
+	where, err := vespaWhere(filter)
+	if err != nil {
+		return nil, err
+	}
+	request := map[string]interface{}{
+		"yql":             fmt.Sprintf("select entity from %s where {%s}nearestNeighbor(value, q)%s", table.schema, table.nearestAnnotations(offset+k), where),
+		"input.query(q)":  vector,
+		"ranking.profile": vespaRankProfile,
+		"offset":          offset,
+		"hits":            k,
+	}
+	var response struct {
+		Root struct {
+			Children []struct {
+				Fields struct {
+					Entity        string             `json:"entity"`
+					MatchFeatures map[string]float64 `json:"matchfeatures"`
+				} `json:"fields"`
+			} `json:"children"`
+			Errors []struct {
+				Message string `json:"message"`
+			} `json:"errors"`
+		} `json:"root"`
+	}
+	if err := table.client.do(context.TODO(), http.MethodPost, "/search/", request, &response); err != nil {
+		return nil, err
+	}
+	if len(response.Root.Errors) > 0 {
+		return nil, fmt.Errorf("nearestNeighbor query failed: %s", response.Root.Errors[0].Message)
+	}
+	results := make([]NearestResult, len(response.Root.Children))
+	for i, hit := range response.Root.Children {
+		distance := hit.Fields.MatchFeatures["distance(field,value)"]
+		// Vespa's angular distance is the angle between the vectors.
+		if table.valueType.Metric() == CosineDistance {
+			distance = 1 - math.Cos(distance)
+		}
+		results[i] = NearestResult{Entity: hit.Fields.Entity, Distance: float32(distance)}
+	}
+	return results, nil
+}
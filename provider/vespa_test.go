@@ -0,0 +1,276 @@
+package provider
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	"io"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	pc "github.com/featureform/provider/provider_config"
+)
+
+// fakeVespa implements the parts of the Vespa document, search and deploy
+// APIs used by the store. Document types only exist once a package with
+// their schema has been deployed, and searches return every document.
+type fakeVespa struct {
+	schemas   map[string]string
+	documents map[string]map[string]map[string]interface{}
+	auth      string
+	mu        sync.Mutex
+}
+
+func newFakeVespa() *fakeVespa {
+	return &fakeVespa{
+		schemas:   make(map[string]string),
+		documents: make(map[string]map[string]map[string]interface{}),
+	}
+}
+
+func (f *fakeVespa) deploy(w http.ResponseWriter, r *http.Request) {
+	body, _ := io.ReadAll(r.Body)
+	archive, err := zip.NewReader(bytes.NewReader(body), int64(len(body)))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	schemas := make(map[string]string)
+	for _, file := range archive.File {
+		if !strings.HasPrefix(file.Name, "schemas/") {
+			continue
+		}
+		reader, _ := file.Open()
+		definition, _ := io.ReadAll(reader)
+		schemas[strings.TrimSuffix(strings.TrimPrefix(file.Name, "schemas/"), ".sd")] = string(definition)
+	}
+	f.schemas = schemas
+	for schema := range f.documents {
+		if _, has := schemas[schema]; !has {
+			delete(f.documents, schema)
+		}
+	}
+	for schema := range schemas {
+		if _, has := f.documents[schema]; !has {
+			f.documents[schema] = make(map[string]map[string]interface{})
+		}
+	}
+	w.Write([]byte("{}"))
+}
+
+func (f *fakeVespa) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.auth = r.Header.Get("Authorization")
+	if r.URL.Path == vespaDeployPath {
+		f.deploy(w, r)
+		return
+	}
+	if r.URL.Path == "/search/" {
+		var request struct {
+			YQL string `json:"yql"`
+		}
+		json.NewDecoder(r.Body).Decode(&request)
+		schema := strings.Fields(request.YQL)[3]
+		children := []map[string]interface{}{}
+		for _, fields := range f.documents[schema] {
+			children = append(children, map[string]interface{}{"fields": map[string]interface{}{
+				"entity":        fields["entity"],
+				"matchfeatures": map[string]float64{"distance(field,value)": math.Pi / 3},
+			}})
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{"root": map[string]interface{}{"children": children}})
+		return
+	}
+	path := strings.Split(strings.TrimPrefix(r.URL.Path, "/document/v1/featureform/"), "/")
+	documents, has := f.documents[path[0]]
+	if !has {
+		http.Error(w, `{"message":"Document type `+path[0]+` does not exist"}`, http.StatusBadRequest)
+		return
+	}
+	switch {
+	case len(path) == 2 && r.Method == http.MethodGet:
+		list := []map[string]interface{}{}
+		for _, fields := range documents {
+			list = append(list, map[string]interface{}{"fields": fields})
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{"documents": list})
+	case len(path) == 2 && r.Method == http.MethodDelete:
+		f.documents[path[0]] = make(map[string]map[string]interface{})
+	case r.Method == http.MethodPost:
+		var document struct {
+			Fields map[string]interface{} `json:"fields"`
+		}
+		json.NewDecoder(r.Body).Decode(&document)
+		documents[path[2]] = document.Fields
+	case r.Method == http.MethodPut:
+		var update struct {
+			Fields map[string]map[string]interface{} `json:"fields"`
+		}
+		json.NewDecoder(r.Body).Decode(&update)
+		for field, operation := range update.Fields {
+			documents[path[2]][field] = operation["assign"]
+		}
+	case r.Method == http.MethodGet:
+		fields, has := documents[path[2]]
+		if !has {
+			http.NotFound(w, r)
+			return
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{"fields": fields})
+	case r.Method == http.MethodDelete:
+		delete(documents, path[2])
+	}
+}
+
+func TestVespaOnlineStore(t *testing.T) {
+	fake := newFakeVespa()
+	server := httptest.NewServer(fake)
+	defer server.Close()
+	store, err := NewVespaOnlineStore(&pc.VespaConfig{URL: server.URL, ConfigURL: server.URL, Token: "token"})
+	if err != nil {
+		t.Fatalf("Failed to create store: %s", err)
+	}
+	if _, err := store.GetTable("feature", "variant"); err == nil {
+		t.Fatalf("Expected TableNotFound")
+	} else if _, notFound := err.(*TableNotFound); !notFound {
+		t.Fatalf("Expected TableNotFound, got %T: %s", err, err)
+	}
+	if _, err := store.CreateTable("feature", "variant", Int); err == nil {
+		t.Fatalf("Succeeded in creating a non-vector table")
+	}
+	vectorType := VectorType{ScalarType: Float32, Dimension: 2, IsEmbedding: true}
+	if _, err := store.CreateIndex("feature", "variant", vectorType); err != nil {
+		t.Fatalf("Failed to create index: %s", err)
+	}
+	if _, err := store.CreateIndex("other", "variant", vectorType); err != nil {
+		t.Fatalf("Failed to create second index: %s", err)
+	}
+	schema := vespaSchemaName("feature", "variant")
+	if !strings.Contains(fake.schemas[schema], "tensor<float>(x[2])") {
+		t.Fatalf("Expected schema with a 2 dimensional tensor, got %q", fake.schemas[schema])
+	}
+	if _, has := fake.schemas[vespaSchemaName("other", "variant")]; !has {
+		t.Fatalf("Expected both schemas to be deployed, got %v", fake.schemas)
+	}
+	if _, err := store.CreateIndex("feature", "variant", vectorType); err == nil {
+		t.Fatalf("Expected TableAlreadyExists")
+	} else if _, exists := err.(*TableAlreadyExists); !exists {
+		t.Fatalf("Expected TableAlreadyExists, got %T: %s", err, err)
+	}
+	if _, err := store.CreateTable("feature", "variant", vectorType); err != nil {
+		t.Fatalf("Failed to create table for index: %s", err)
+	}
+	if _, err := store.CreateTable("feature", "variant", vectorType); err == nil {
+		t.Fatalf("Expected TableAlreadyExists")
+	}
+	table, err := store.GetTable("feature", "variant")
+	if err != nil {
+		t.Fatalf("Failed to get table: %s", err)
+	}
+	if err := table.Set("a", []float32{1, 0}); err != nil {
+		t.Fatalf("Failed to set vector: %s", err)
+	}
+	if err := table.Set("a", []float32{0, 1}); err != nil {
+		t.Fatalf("Failed to set vector: %s", err)
+	}
+	if err := table.Set("b", []float32{1, 1}); err != nil {
+		t.Fatalf("Failed to set vector: %s", err)
+	}
+	if fake.auth != "Bearer token" {
+		t.Fatalf("Expected token to be sent, got %q", fake.auth)
+	}
+	if value, err := table.Get("a"); err != nil || !reflect.DeepEqual(value, []float32{0, 1}) {
+		t.Fatalf("Expected overwritten vector, got %v %v", value, err)
+	}
+	if _, err := table.Get("c"); err == nil {
+		t.Fatalf("Expected EntityNotFound")
+	} else if _, notFound := err.(*EntityNotFound); !notFound {
+		t.Fatalf("Expected EntityNotFound, got %T: %s", err, err)
+	}
+	entities, err := table.(VectorStoreTable).Nearest("feature", "variant", []float32{1, 1}, 2, VectorFilter{})
+	if err != nil {
+		t.Fatalf("Failed to search: %s", err)
+	}
+	if len(entities) != 2 {
+		t.Fatalf("Expected 2 entities, got %v", entities)
+	}
+	if distance := entities[0].Distance; distance < 0.4999 || distance > 0.5001 {
+		t.Fatalf("Expected cosine distance 0.5, got %v", distance)
+	}
+	if err := table.(VectorStoreTable).Delete("b"); err != nil {
+		t.Fatalf("Failed to delete vector: %s", err)
+	}
+	if err := table.(VectorStoreTable).Delete("b"); err != nil {
+		t.Fatalf("Failed to delete missing vector: %s", err)
+	}
+	if entities, err := table.(VectorStoreTable).Nearest("feature", "variant", []float32{1, 1}, 2, VectorFilter{}); err != nil || len(entities) != 1 {
+		t.Fatalf("Expected deleted vector to be removed, got %v %v", entities, err)
+	}
+	if err := store.DeleteTable("feature", "variant"); err != nil {
+		t.Fatalf("Failed to delete table: %s", err)
+	}
+	if _, has := fake.schemas[schema]; has {
+		t.Fatalf("Expected schema to be removed from the application")
+	}
+	if _, err := store.GetTable("other", "variant"); err != nil {
+		t.Fatalf("Expected other table to be kept: %s", err)
+	}
+}
+
+func TestVespaApplicationPackageRemoval(t *testing.T) {
+	now := time.Date(2023, 4, 1, 12, 0, 0, 0, time.UTC)
+	files, err := vespaApplicationPackage(nil, true, now)
+	if err != nil {
+		t.Fatalf("Failed to build package: %s", err)
+	}
+	if !strings.Contains(files["validation-overrides.xml"], `until="2023-04-02"`) {
+		t.Fatalf("Expected removal override until tomorrow, got %q", files["validation-overrides.xml"])
+	}
+	if !strings.Contains(files["services.xml"], `<document type="featureform_metadata" mode="index"/>`) {
+		t.Fatalf("Expected metadata document type, got %q", files["services.xml"])
+	}
+	if _, err := vespaApplicationPackage([]vespaMetadata{{Feature: "f", Variant: "v", ValueType: string(Int)}}, false, now); err == nil {
+		t.Fatalf("Succeeded in building a package with a non-vector table")
+	}
+}
+
+func TestVespaWhere(t *testing.T) {
+	if where, err := vespaWhere(VectorFilter{}); err != nil || where != "" {
+		t.Fatalf("Expected no conditions, got %q %v", where, err)
+	}
+	filter := VectorFilter{
+		Attributes:   map[string]string{"tier": `gold "plus"`, "region": "us"},
+		UpdatedAfter: time.UnixMilli(1680307200000),
+		Namespace:    "tenant",
+	}
+	where, err := vespaWhere(filter)
+	if err != nil {
+		t.Fatalf("Failed to build conditions: %s", err)
+	}
+	expected := ` and attributes contains "region=us" and attributes contains "tier=gold \"plus\"" and updated_at >= 1680307200000 and namespace contains "tenant"`
+	if where != expected {
+		t.Fatalf("Expected %s, got %s", expected, where)
+	}
+}
+
+func TestVespaIndexOptions(t *testing.T) {
+	vectorType := VectorType{ScalarType: Float32, Dimension: 2, Index: &IndexOptions{M: 32, EfRuntime: 50}}
+	definition := vespaSchemaDefinition("featureform_schema", vectorType)
+	if !strings.Contains(definition, "max-links-per-node: 32") || !strings.Contains(definition, "neighbors-to-explore-at-insert: 200") {
+		t.Fatalf("Expected configured and default HNSW options, got %s", definition)
+	}
+	table := &vespaOnlineTable{valueType: vectorType}
+	if annotations := table.nearestAnnotations(10); annotations != "targetHits:10,hnsw.exploreAdditionalHits:40" {
+		t.Fatalf("Unexpected annotations %s", annotations)
+	}
+	if annotations := table.nearestAnnotations(100); annotations != "targetHits:100" {
+		t.Fatalf("Unexpected annotations %s", annotations)
+	}
+}
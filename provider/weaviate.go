@@ -0,0 +1,592 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package provider
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"golang.org/x/oauth2/clientcredentials"
+
+	pc "github.com/featureform/provider/provider_config"
+	pt "github.com/featureform/provider/provider_type"
+)
+
+const (
+	weaviateClassPrefix        = "Featureform_"
+	weaviateEntityProperty     = "entity"
+	weaviateAttributesProperty = "attributes"
+	weaviateUpdatedAtProperty  = "updatedAt"
+	weaviateNamespaceProperty  = "namespace"
+)
+
+func weaviateOnlineStoreFactory(serialized pc.SerializedConfig) (Provider, error) {
+	config := &pc.WeaviateConfig{}
+	if err := config.Deserialize(serialized); err != nil {
+		return nil, err
+	}
+	return NewWeaviateOnlineStore(config)
+}
+
+// weaviateOnlineStore is a VectorStore with one Weaviate class per feature
+// variant. Each entity is an object with an ID derived from the entity, so
+// writing an entity again replaces its vector.
+type weaviateOnlineStore struct {
+	client *jsonHTTPClient
+	BaseProvider
+}
+
+func NewWeaviateOnlineStore(config *pc.WeaviateConfig) (*weaviateOnlineStore, error) {
+	if config.URL == "" {
+		return nil, fmt.Errorf("weaviate URL is required")
+	}
+	header := make(http.Header)
+	var httpClient *http.Client
+	if config.APIKey != "" {
+		header.Set("Authorization", "Bearer "+config.APIKey)
+	} else if config.OIDCClientID != "" {
+		tokenURL, err := weaviateTokenURL(config.URL)
+		if err != nil {
+			return nil, fmt.Errorf("could not discover weaviate OIDC token endpoint: %w", err)
+		}
+		credentials := clientcredentials.Config{
+			ClientID:     config.OIDCClientID,
+			ClientSecret: config.OIDCClientSecret,
+			TokenURL:     tokenURL,
+			Scopes:       config.OIDCScopes,
+		}
+		httpClient = credentials.Client(context.Background())
+		httpClient.Timeout = defaultHTTPTimeout
+	}
+	return &weaviateOnlineStore{
+		client: newJSONHTTPClient(config.URL, header, httpClient),
+		BaseProvider: BaseProvider{
+			ProviderType:   pt.WeaviateOnline,
+			ProviderConfig: config.Serialized(),
+		},
+	}, nil
+}
+
+// weaviateTokenURL finds the token endpoint of the OIDC provider that
+// Weaviate is configured to trust.
+func weaviateTokenURL(weaviateURL string) (string, error) {
+	client := newJSONHTTPClient(weaviateURL, nil, nil)
+	var wellKnown struct {
+		Href string `json:"href"`
+	}
+	if err := client.do(context.Background(), http.MethodGet, "/v1/.well-known/openid-configuration", nil, &wellKnown); err != nil {
+		return "", err
+	}
+	var discovery struct {
+		TokenEndpoint string `json:"token_endpoint"`
+	}
+	if err := newJSONHTTPClient(wellKnown.Href, nil, nil).do(context.Background(), http.MethodGet, "", nil, &discovery); err != nil {
+		return "", err
+	}
+	if discovery.TokenEndpoint == "" {
+		return "", fmt.Errorf("OIDC discovery document at %s has no token endpoint", wellKnown.Href)
+	}
+	return discovery.TokenEndpoint, nil
+}
+
+// weaviateClassName returns the class of a feature variant. Class names are
+// restricted to letters, digits and underscores, so the feature and variant
+// are hashed and kept in the class description instead.
+func weaviateClassName(feature, variant string) string {
+	hash := sha256.Sum256([]byte(feature + "\x00" + variant))
+	return weaviateClassPrefix + hex.EncodeToString(hash[:16])
+}
+
+// weaviateClassDescription is stored as JSON in each class's description.
+// Like Redis, materializations create a feature's index and then its table,
+// so Table records whether CreateTable has been called for the class.
+type weaviateClassDescription struct {
+	Feature   string
+	Variant   string
+	ValueType ValueTypeJSONWrapper
+	Table     bool
+}
+
+var weaviateDistanceMetrics = map[DistanceMetric]string{
+	CosineDistance:       "cosine",
+	L2Distance:           "l2-squared",
+	InnerProductDistance: "dot",
+}
+
+// weaviateIndexConfig returns the HNSW vectorIndexConfig of a vector type.
+func weaviateIndexConfig(vectorType VectorType) (map[string]interface{}, error) {
+	options := vectorType.Options()
+	if err := options.validate("weaviate", false); err != nil {
+		return nil, err
+	}
+	if err := vectorType.validateStorage("weaviate", Float32); err != nil {
+		return nil, err
+	}
+	if err := vectorType.validateNames("weaviate", true); err != nil {
+		return nil, err
+	}
+	config := map[string]interface{}{"distance": weaviateDistanceMetrics[vectorType.Metric()]}
+	if options.M > 0 {
+		config["maxConnections"] = options.M
+	}
+	if options.EfConstruction > 0 {
+		config["efConstruction"] = options.EfConstruction
+	}
+	if options.EfRuntime > 0 {
+		config["ef"] = options.EfRuntime
+	}
+	return config, nil
+}
+
+// weaviateClass is a class's schema. Classes of named vectors configure
+// each vector in VectorConfig instead of the class's vectorizer and index.
+type weaviateClass struct {
+	Class             string                   `json:"class"`
+	Description       string                   `json:"description"`
+	Vectorizer        string                   `json:"vectorizer,omitempty"`
+	VectorIndexType   string                   `json:"vectorIndexType,omitempty"`
+	VectorIndexConfig map[string]interface{}   `json:"vectorIndexConfig,omitempty"`
+	VectorConfig      map[string]interface{}   `json:"vectorConfig,omitempty"`
+	Properties        []map[string]interface{} `json:"properties"`
+}
+
+func (store *weaviateOnlineStore) AsOnlineStore() (OnlineStore, error) {
+	return store, nil
+}
+
+func (store *weaviateOnlineStore) AsVectorStore() (VectorStore, error) {
+	return store, nil
+}
+
+func (store *weaviateOnlineStore) Close() error {
+	return nil
+}
+
+func (store *weaviateOnlineStore) GetTable(feature, variant string) (OnlineStoreTable, error) {
+	class, description, err := store.getClass(feature, variant)
+	if err != nil {
+		return nil, err
+	}
+	return store.table(class.Class, description.ValueType.ValueType)
+}
+
+func (store *weaviateOnlineStore) getClass(feature, variant string) (weaviateClass, weaviateClassDescription, error) {
+	className := weaviateClassName(feature, variant)
+	class := weaviateClass{}
+	description := weaviateClassDescription{}
+	err := store.client.do(context.TODO(), http.MethodGet, "/v1/schema/"+className, nil, &class)
+	if isHTTPStatus(err, http.StatusNotFound) {
+		return class, description, &TableNotFound{feature, variant}
+	} else if err != nil {
+		return class, description, err
+	}
+	if err := json.Unmarshal([]byte(class.Description), &description); err != nil {
+		return class, description, fmt.Errorf("could not parse description of class %s: %w", className, err)
+	}
+	return class, description, nil
+}
+
+func (store *weaviateOnlineStore) table(className string, valueType ValueType) (*weaviateOnlineTable, error) {
+	vectorType, ok := valueType.(VectorType)
+	if !ok {
+		return nil, fmt.Errorf("weaviate only stores vectors, not %v", valueType)
+	}
+	return &weaviateOnlineTable{client: store.client, className: className, valueType: vectorType}, nil
+}
+
+// CreateTable creates the feature variant's class, or marks the class
+// created by CreateIndex as a table.
+func (store *weaviateOnlineStore) CreateTable(feature, variant string, valueType ValueType) (OnlineStoreTable, error) {
+	class, description, err := store.getClass(feature, variant)
+	if _, notFound := err.(*TableNotFound); notFound {
+		return store.createClass(feature, variant, valueType, true)
+	} else if err != nil {
+		return nil, err
+	}
+	if description.Table {
+		return nil, &TableAlreadyExists{feature, variant}
+	}
+	description.Table = true
+	serialized, err := json.Marshal(description)
+	if err != nil {
+		return nil, err
+	}
+	class.Description = string(serialized)
+	if err := store.client.do(context.TODO(), http.MethodPut, "/v1/schema/"+class.Class, class, nil); err != nil {
+		return nil, fmt.Errorf("could not update weaviate class: %w", err)
+	}
+	return store.table(class.Class, description.ValueType.ValueType)
+}
+
+func (store *weaviateOnlineStore) CreateIndex(feature, variant string, vectorType VectorType) (VectorStoreTable, error) {
+	if _, err := store.GetTable(feature, variant); err == nil {
+		return nil, &TableAlreadyExists{feature, variant}
+	} else if _, notFound := err.(*TableNotFound); !notFound {
+		return nil, err
+	}
+	return store.createClass(feature, variant, vectorType, false)
+}
+
+func (store *weaviateOnlineStore) createClass(feature, variant string, valueType ValueType, isTable bool) (*weaviateOnlineTable, error) {
+	className := weaviateClassName(feature, variant)
+	table, err := store.table(className, valueType)
+	if err != nil {
+		return nil, err
+	}
+	description, err := json.Marshal(weaviateClassDescription{
+		Feature:   feature,
+		Variant:   variant,
+		ValueType: ValueTypeJSONWrapper{valueType},
+		Table:     isTable,
+	})
+	if err != nil {
+		return nil, err
+	}
+	indexConfig, err := weaviateIndexConfig(table.valueType)
+	if err != nil {
+		return nil, err
+	}
+	class := weaviateClass{
+		Class:             className,
+		Description:       string(description),
+		Vectorizer:        "none",
+		VectorIndexType:   "hnsw",
+		VectorIndexConfig: indexConfig,
+		// Attributes and namespaces are matched as whole terms, so they
+		// aren't split into words.
+		Properties: []map[string]interface{}{
+			{"name": weaviateEntityProperty, "dataType": []string{"text"}},
+			{"name": weaviateAttributesProperty, "dataType": []string{"text[]"}, "tokenization": "field"},
+			{"name": weaviateUpdatedAtProperty, "dataType": []string{"date"}},
+			{"name": weaviateNamespaceProperty, "dataType": []string{"text"}, "tokenization": "field"},
+		},
+	}
+	if names := table.valueType.VectorNames(); len(names) > 0 {
+		class.Vectorizer, class.VectorIndexType, class.VectorIndexConfig = "", "", nil
+		class.VectorConfig = make(map[string]interface{}, len(names))
+		for _, name := range names {
+			class.VectorConfig[name] = map[string]interface{}{
+				"vectorizer":        map[string]interface{}{"none": map[string]interface{}{}},
+				"vectorIndexType":   "hnsw",
+				"vectorIndexConfig": indexConfig,
+			}
+		}
+	}
+	if err := store.client.do(context.TODO(), http.MethodPost, "/v1/schema", class, nil); err != nil {
+		return nil, fmt.Errorf("could not create weaviate class: %w", err)
+	}
+	return table, nil
+}
+
+func (store *weaviateOnlineStore) DeleteTable(feature, variant string) error {
+	className := weaviateClassName(feature, variant)
+	err := store.client.do(context.TODO(), http.MethodDelete, "/v1/schema/"+className, nil, nil)
+	if isHTTPStatus(err, http.StatusNotFound) {
+		return &TableNotFound{feature, variant}
+	}
+	return err
+}
+
+func (store *weaviateOnlineStore) Capabilities() OnlineCapabilities {
+	return OnlineCapabilities{
+		BatchWrites:      true,
+		Vectors:          true,
+		VectorFilters:    true,
+		VectorNamespaces: true,
+		NamedVectors:     true,
+	}
+}
+
+type weaviateOnlineTable struct {
+	client    *jsonHTTPClient
+	className string
+	valueType VectorType
+}
+
+type weaviateObject struct {
+	Class      string                 `json:"class"`
+	ID         string                 `json:"id"`
+	Properties map[string]interface{} `json:"properties"`
+	Vector     []float32              `json:"vector,omitempty"`
+	Vectors    map[string][]float32   `json:"vectors,omitempty"`
+}
+
+// weaviateObjectID derives an object's UUID from its entity, so that writes
+// of the same entity replace each other.
+func weaviateObjectID(entity string) string {
+	return uuid.NewSHA1(uuid.NameSpaceOID, []byte(entity)).String()
+}
+
+func (table *weaviateOnlineTable) Set(entity string, value interface{}) error {
+	return table.SetBatch(map[string]interface{}{entity: value})
+}
+
+func (table *weaviateOnlineTable) SetWithAttributes(entity string, vector []float32, attributes map[string]string) error {
+	if err := checkVectorDimension(table.valueType, entity, vector); err != nil {
+		return err
+	}
+	terms, err := vectorAttributeTerms(attributes)
+	if err != nil {
+		return err
+	}
+	return table.writeObjects([]weaviateObject{table.object(entity, vector, terms, "", time.Now())})
+}
+
+func (table *weaviateOnlineTable) SetInNamespace(namespace, entity string, vector []float32) error {
+	if err := validateNamespace(namespace); err != nil {
+		return err
+	}
+	if err := checkVectorDimension(table.valueType, entity, vector); err != nil {
+		return err
+	}
+	return table.writeObjects([]weaviateObject{table.object(entity, vector, []string{}, namespace, time.Now())})
+}
+
+// SetNamed replaces the entity's object with one holding its named vectors.
+func (table *weaviateOnlineTable) SetNamed(entity string, vectors map[string][]float32) error {
+	if len(vectors) == 0 {
+		return fmt.Errorf("no vectors to set for entity %s", entity)
+	}
+	for name, vector := range vectors {
+		if !table.valueType.hasName(name) {
+			return fmt.Errorf("unknown vector name %q", name)
+		}
+		if err := checkVectorDimension(table.valueType, entity, vector); err != nil {
+			return err
+		}
+	}
+	object := table.object(entity, nil, []string{}, "", time.Now())
+	object.Vectors = vectors
+	return table.writeObjects([]weaviateObject{object})
+}
+
+// defaultName returns the name of the vector that Set, Get and Nearest use,
+// which is empty for classes of one unnamed vector.
+func (table *weaviateOnlineTable) defaultName() string {
+	names := table.valueType.VectorNames()
+	if len(names) == 0 {
+		return ""
+	}
+	return names[0]
+}
+
+// object returns the object of an entity's vector. Batch writes replace
+// whole objects, so writing an entity without a namespace removes it from
+// its old one, and writing one vector of a class of named vectors removes
+// the others.
+func (table *weaviateOnlineTable) object(entity string, vector []float32, terms []string, namespace string, updated time.Time) weaviateObject {
+	properties := map[string]interface{}{
+		weaviateEntityProperty:     entity,
+		weaviateAttributesProperty: terms,
+		weaviateUpdatedAtProperty:  updated.UTC().Format(time.RFC3339Nano),
+	}
+	if namespace != "" {
+		properties[weaviateNamespaceProperty] = namespace
+	}
+	object := weaviateObject{
+		Class:      table.className,
+		ID:         weaviateObjectID(entity),
+		Properties: properties,
+	}
+	if name := table.defaultName(); name != "" {
+		object.Vectors = map[string][]float32{name: vector}
+	} else {
+		object.Vector = vector
+	}
+	return object
+}
+
+// SetBatch upserts the entities' vectors with the batch API.
+func (table *weaviateOnlineTable) SetBatch(values map[string]interface{}) error {
+	objects := make([]weaviateObject, 0, len(values))
+	now := time.Now()
+	for entity, value := range values {
+		vector, err := vectorValue(table.valueType, entity, value)
+		if err != nil {
+			return err
+		}
+		objects = append(objects, table.object(entity, vector, []string{}, "", now))
+	}
+	return table.writeObjects(objects)
+}
+
+func (table *weaviateOnlineTable) writeObjects(objects []weaviateObject) error {
+	request := map[string]interface{}{"objects": objects}
+	var results []struct {
+		ID     string `json:"id"`
+		Result struct {
+			Errors *struct {
+				Error []struct {
+					Message string `json:"message"`
+				} `json:"error"`
+			} `json:"errors"`
+		} `json:"result"`
+	}
+	if err := table.client.do(context.TODO(), http.MethodPost, "/v1/batch/objects", request, &results); err != nil {
+		return err
+	}
+	for _, result := range results {
+		if result.Result.Errors != nil && len(result.Result.Errors.Error) > 0 {
+			return fmt.Errorf("could not write object %s: %s", result.ID, result.Result.Errors.Error[0].Message)
+		}
+	}
+	return nil
+}
+
+func (table *weaviateOnlineTable) Get(entity string) (interface{}, error) {
+	path := fmt.Sprintf("/v1/objects/%s/%s?include=vector", table.className, weaviateObjectID(entity))
+	object := weaviateObject{}
+	err := table.client.do(context.TODO(), http.MethodGet, path, nil, &object)
+	if isHTTPStatus(err, http.StatusNotFound) {
+		return nil, &EntityNotFound{entity}
+	} else if err != nil {
+		return nil, err
+	}
+	if name := table.defaultName(); name != "" {
+		return object.Vectors[name], nil
+	}
+	return object.Vector, nil
+}
+
+func (table *weaviateOnlineTable) Delete(entity string) error {
+	path := fmt.Sprintf("/v1/objects/%s/%s", table.className, weaviateObjectID(entity))
+	err := table.client.do(context.TODO(), http.MethodDelete, path, nil, nil)
+	if isHTTPStatus(err, http.StatusNotFound) {
+		return nil
+	}
+	return err
+}
+
+// weaviateWhere returns the GraphQL where argument of a filter, or an empty
+// string if the filter is zero.
+func weaviateWhere(filter VectorFilter) (string, error) {
+	operands := []string{}
+	if len(filter.Attributes) > 0 {
+		terms, err := vectorAttributeTerms(filter.Attributes)
+		if err != nil {
+			return "", err
+		}
+		serialized, err := json.Marshal(terms)
+		if err != nil {
+			return "", err
+		}
+		operands = append(operands, fmt.Sprintf("{path:[%q],operator:ContainsAll,valueText:%s}", weaviateAttributesProperty, serialized))
+	}
+	if !filter.UpdatedAfter.IsZero() {
+		operands = append(operands, fmt.Sprintf("{path:[%q],operator:GreaterThanEqual,valueDate:%q}", weaviateUpdatedAtProperty, filter.UpdatedAfter.UTC().Format(time.RFC3339Nano)))
+	}
+	if filter.Namespace != "" {
+		operands = append(operands, fmt.Sprintf("{path:[%q],operator:Equal,valueText:%q}", weaviateNamespaceProperty, filter.Namespace))
+	}
+	switch len(operands) {
+	case 0:
+		return "", nil
+	case 1:
+		return ",where:" + operands[0], nil
+	default:
+		return fmt.Sprintf(",where:{operator:And,operands:[%s]}", strings.Join(operands, ",")), nil
+	}
+}
+
+// Nearest returns the entities of the k objects nearest to vector that match
+// filter with a nearVector GraphQL query.
+func (table *weaviateOnlineTable) Nearest(feature, variant string, vector []float32, k int32, filter VectorFilter) ([]NearestResult, error) {
+	return table.nearest(table.defaultName(), vector, 0, k, filter)
+}
+
+// NearestNamed searches the entities' vectors of one name.
+func (table *weaviateOnlineTable) NearestNamed(feature, variant, name string, vector []float32, k int32, filter VectorFilter) ([]NearestResult, error) {
+	if !table.valueType.hasName(name) {
+		return nil, fmt.Errorf("unknown vector name %q", name)
+	}
+	return table.nearest(name, vector, 0, k, filter)
+}
+
+func (table *weaviateOnlineTable) NearestPage(feature, variant string, vector []float32, pageSize int32, filter VectorFilter, cursor string) (NearestPage, error) {
+	search := func(offset, k int32) ([]NearestResult, error) {
+		return table.nearest(table.defaultName(), vector, offset, k, filter)
+	}
+	return nearestPage(search, pageSize, cursor)
+}
+
+// NearestByEntity searches for the neighbors of the entity's object with a
+// nearObject query, so its vector isn't read by the client. The object is
+// its own nearest neighbor, so it's searched for and removed.
+func (table *weaviateOnlineTable) NearestByEntity(feature, variant, entity string, k int32) ([]NearestResult, error) {
+	near := fmt.Sprintf("nearObject:{id:%q%s}", weaviateObjectID(entity), weaviateTargetVectors(table.defaultName()))
+	results, err := table.search(near, 0, k+1, "")
+	if err != nil {
+		return nil, err
+	}
+	return withoutEntity(results, entity, k), nil
+}
+
+// weaviateTargetVectors returns the argument of a near search selecting the
+// named vector to search, or an empty string for unnamed vectors.
+func weaviateTargetVectors(name string) string {
+	if name == "" {
+		return ""
+	}
+	return fmt.Sprintf(",targetVectors:[%q]", name)
+}
+
+// nearest returns the k objects after the nearest offset objects, by their
+// vectors of name.
+func (table *weaviateOnlineTable) nearest(name string, vector []float32, offset, k int32, filter VectorFilter) ([]NearestResult, error) {
+	serializedVector, err := json.Marshal(vector)
+	if err != nil {
+		return nil, err
+	}
+	where, err := weaviateWhere(filter)
+	if err != nil {
+		return nil, err
+	}
+	return table.search(fmt.Sprintf("nearVector:{vector:%s%s}", serializedVector, weaviateTargetVectors(name)), offset, k, where)
+}
+
+// search runs a Get query for the k objects after the nearest offset
+// objects to the near argument.
+func (table *weaviateOnlineTable) search(near string, offset, k int32, where string) ([]NearestResult, error) {
+	query := fmt.Sprintf("{Get{%s(%s,limit:%d,offset:%d%s){%s _additional{distance}}}}", table.className, near, k, offset, where, weaviateEntityProperty)
+	var response struct {
+		Data struct {
+			Get map[string][]struct {
+				Entity     string `json:"entity"`
+				Additional struct {
+					Distance float32 `json:"distance"`
+				} `json:"_additional"`
+			} `json:"Get"`
+		} `json:"data"`
+		Errors []struct {
+			Message string `json:"message"`
+		} `json:"errors"`
+	}
+	if err := table.client.do(context.TODO(), http.MethodPost, "/v1/graphql", map[string]string{"query": query}, &response); err != nil {
+		return nil, err
+	}
+	if len(response.Errors) > 0 {
+		return nil, fmt.Errorf("nearest query failed: %s", response.Errors[0].Message)
+	}
+	objects := response.Data.Get[table.className]
+	results := make([]NearestResult, len(objects))
+	for i, object := range objects {
+		distance := object.Additional.Distance
+		// Weaviate's l2-squared distance is squared, and its dot distance
+		// is already the negative inner product.
+		if table.valueType.Metric() == L2Distance {
+			distance = float32(math.Sqrt(float64(distance)))
+		}
+		results[i] = NearestResult{Entity: object.Entity, Distance: distance}
+	}
+	return results, nil
+}
@@ -0,0 +1,282 @@
+package provider
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	pc "github.com/featureform/provider/provider_config"
+)
+
+// fakeWeaviate implements the parts of the Weaviate REST API used by the
+// store. Nearest queries return every object of the class.
+type fakeWeaviate struct {
+	classes map[string]weaviateClass
+	objects map[string]map[string]weaviateObject
+	auth    string
+	query   string
+	mu      sync.Mutex
+}
+
+func newFakeWeaviate() *fakeWeaviate {
+	return &fakeWeaviate{
+		classes: make(map[string]weaviateClass),
+		objects: make(map[string]map[string]weaviateObject),
+	}
+}
+
+func (f *fakeWeaviate) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.auth = r.Header.Get("Authorization")
+	path := strings.Split(strings.TrimPrefix(r.URL.Path, "/v1/"), "/")
+	switch {
+	case r.Method == http.MethodPost && path[0] == "schema":
+		class := weaviateClass{}
+		json.NewDecoder(r.Body).Decode(&class)
+		if _, has := f.classes[class.Class]; has {
+			http.Error(w, "class exists", http.StatusUnprocessableEntity)
+			return
+		}
+		f.classes[class.Class] = class
+		f.objects[class.Class] = make(map[string]weaviateObject)
+	case r.Method == http.MethodPut && path[0] == "schema":
+		class := weaviateClass{}
+		json.NewDecoder(r.Body).Decode(&class)
+		f.classes[path[1]] = class
+	case r.Method == http.MethodGet && path[0] == "schema":
+		class, has := f.classes[path[1]]
+		if !has {
+			http.NotFound(w, r)
+			return
+		}
+		json.NewEncoder(w).Encode(class)
+	case r.Method == http.MethodDelete && path[0] == "schema":
+		delete(f.classes, path[1])
+		delete(f.objects, path[1])
+	case r.Method == http.MethodPost && path[0] == "batch":
+		var request struct {
+			Objects []weaviateObject `json:"objects"`
+		}
+		json.NewDecoder(r.Body).Decode(&request)
+		for _, object := range request.Objects {
+			f.objects[object.Class][object.ID] = object
+		}
+		w.Write([]byte("[]"))
+	case r.Method == http.MethodGet && path[0] == "objects":
+		object, has := f.objects[path[1]][path[2]]
+		if !has {
+			http.NotFound(w, r)
+			return
+		}
+		json.NewEncoder(w).Encode(object)
+	case r.Method == http.MethodDelete && path[0] == "objects":
+		if _, has := f.objects[path[1]][path[2]]; !has {
+			http.NotFound(w, r)
+			return
+		}
+		delete(f.objects[path[1]], path[2])
+	case r.Method == http.MethodPost && path[0] == "graphql":
+		var request struct {
+			Query string `json:"query"`
+		}
+		json.NewDecoder(r.Body).Decode(&request)
+		f.query = request.Query
+		className := strings.TrimPrefix(strings.SplitN(request.Query, "(", 2)[0], "{Get{")
+		results := []map[string]interface{}{}
+		for _, object := range f.objects[className] {
+			results = append(results, map[string]interface{}{
+				"entity":      object.Properties["entity"],
+				"_additional": map[string]float32{"distance": 0.5},
+			})
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{"data": map[string]interface{}{"Get": map[string]interface{}{className: results}}})
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+func TestWeaviateOnlineStore(t *testing.T) {
+	fake := newFakeWeaviate()
+	server := httptest.NewServer(fake)
+	defer server.Close()
+	store, err := NewWeaviateOnlineStore(&pc.WeaviateConfig{URL: server.URL, APIKey: "key"})
+	if err != nil {
+		t.Fatalf("Failed to create store: %s", err)
+	}
+	if _, err := store.GetTable("feature", "variant"); err == nil {
+		t.Fatalf("Expected TableNotFound")
+	} else if _, notFound := err.(*TableNotFound); !notFound {
+		t.Fatalf("Expected TableNotFound, got %T: %s", err, err)
+	}
+	if _, err := store.CreateTable("feature", "variant", Int); err == nil {
+		t.Fatalf("Succeeded in creating a non-vector table")
+	}
+	vectorType := VectorType{ScalarType: Float32, Dimension: 2, IsEmbedding: true}
+	if _, err := store.CreateIndex("feature", "variant", vectorType); err != nil {
+		t.Fatalf("Failed to create index: %s", err)
+	}
+	if _, err := store.CreateTable("feature", "variant", vectorType); err != nil {
+		t.Fatalf("Failed to create table for index: %s", err)
+	}
+	if _, err := store.CreateTable("feature", "variant", vectorType); err == nil {
+		t.Fatalf("Expected TableAlreadyExists")
+	} else if _, exists := err.(*TableAlreadyExists); !exists {
+		t.Fatalf("Expected TableAlreadyExists, got %T: %s", err, err)
+	}
+	table, err := store.GetTable("feature", "variant")
+	if err != nil {
+		t.Fatalf("Failed to get table: %s", err)
+	}
+	if err := table.Set("a", []float32{1, 0}); err != nil {
+		t.Fatalf("Failed to set vector: %s", err)
+	}
+	if err := table.(BatchSettableTable).SetBatch(map[string]interface{}{"a": []float32{0, 1}, "b": []float32{1, 1}}); err != nil {
+		t.Fatalf("Failed to set batch: %s", err)
+	}
+	if err := table.Set("c", []float32{1, 0, 0}); err == nil {
+		t.Fatalf("Succeeded in setting a vector of the wrong dimension")
+	} else if mismatch, isMismatch := err.(*DimensionMismatch); !isMismatch || mismatch.Expected != 2 || mismatch.Dimension != 3 {
+		t.Fatalf("Expected DimensionMismatch, got %T: %s", err, err)
+	}
+	if fake.auth != "Bearer key" {
+		t.Fatalf("Expected API key to be sent, got %q", fake.auth)
+	}
+	if value, err := table.Get("a"); err != nil || !reflect.DeepEqual(value, []float32{0, 1}) {
+		t.Fatalf("Expected overwritten vector, got %v %v", value, err)
+	}
+	if _, err := table.Get("c"); err == nil {
+		t.Fatalf("Expected EntityNotFound")
+	} else if _, notFound := err.(*EntityNotFound); !notFound {
+		t.Fatalf("Expected EntityNotFound, got %T: %s", err, err)
+	}
+	entities, err := table.(VectorStoreTable).Nearest("feature", "variant", []float32{1, 1}, 2, VectorFilter{})
+	if err != nil {
+		t.Fatalf("Failed to search: %s", err)
+	}
+	if len(entities) != 2 {
+		t.Fatalf("Expected 2 entities, got %v", entities)
+	}
+	if distance := entities[0].Distance; distance < 0.4999 || distance > 0.5001 {
+		t.Fatalf("Expected cosine distance 0.5, got %v", distance)
+	}
+	neighbors, err := NearestByEntity(table.(VectorStoreTable), "feature", "variant", "a", 2)
+	if err != nil {
+		t.Fatalf("Failed to search by entity: %s", err)
+	}
+	if len(neighbors) != 1 || neighbors[0].Entity != "b" {
+		t.Fatalf("Expected only neighbor b, got %v", neighbors)
+	}
+	if err := table.(VectorStoreTable).Delete("b"); err != nil {
+		t.Fatalf("Failed to delete vector: %s", err)
+	}
+	if err := table.(VectorStoreTable).Delete("b"); err != nil {
+		t.Fatalf("Failed to delete missing vector: %s", err)
+	}
+	if entities, err := table.(VectorStoreTable).Nearest("feature", "variant", []float32{1, 1}, 2, VectorFilter{}); err != nil || len(entities) != 1 {
+		t.Fatalf("Expected deleted vector to be removed, got %v %v", entities, err)
+	}
+	if err := store.DeleteTable("feature", "variant"); err != nil {
+		t.Fatalf("Failed to delete table: %s", err)
+	}
+	if _, err := store.GetTable("feature", "variant"); err == nil {
+		t.Fatalf("Expected table to be deleted")
+	}
+}
+
+func TestWeaviateNamedVectors(t *testing.T) {
+	fake := newFakeWeaviate()
+	server := httptest.NewServer(fake)
+	defer server.Close()
+	store, err := NewWeaviateOnlineStore(&pc.WeaviateConfig{URL: server.URL})
+	if err != nil {
+		t.Fatalf("Failed to create store: %s", err)
+	}
+	vectorType := VectorType{ScalarType: Float32, Dimension: 2, IsEmbedding: true, Named: &NamedVectors{Names: []string{"title", "body"}}}
+	index, err := store.CreateIndex("feature", "variant", vectorType)
+	if err != nil {
+		t.Fatalf("Failed to create index: %s", err)
+	}
+	class := fake.classes[weaviateClassName("feature", "variant")]
+	if class.Vectorizer != "" || len(class.VectorConfig) != 2 {
+		t.Fatalf("Expected a vector config per name, got %+v", class)
+	}
+	table := index.(NamedVectorTable)
+	if err := table.SetNamed("a", map[string][]float32{"title": {1, 0}, "body": {0, 1}}); err != nil {
+		t.Fatalf("Failed to set named vectors: %s", err)
+	}
+	if err := table.SetNamed("a", map[string][]float32{"summary": {1, 0}}); err == nil {
+		t.Fatalf("Succeeded in setting an unknown vector name")
+	}
+	if err := table.SetNamed("a", map[string][]float32{"body": {1, 0, 0}}); err == nil {
+		t.Fatalf("Succeeded in setting a vector of the wrong dimension")
+	}
+	if value, err := table.Get("a"); err != nil || !reflect.DeepEqual(value, []float32{1, 0}) {
+		t.Fatalf("Expected the title vector, got %v %v", value, err)
+	}
+	if _, err := table.NearestNamed("feature", "variant", "body", []float32{0, 1}, 1, VectorFilter{}); err != nil {
+		t.Fatalf("Failed to search body vectors: %s", err)
+	}
+	if !strings.Contains(fake.query, `targetVectors:["body"]`) {
+		t.Fatalf("Expected body vectors to be searched, got %s", fake.query)
+	}
+	if _, err := table.NearestNamed("feature", "variant", "summary", []float32{0, 1}, 1, VectorFilter{}); err == nil {
+		t.Fatalf("Succeeded in searching an unknown vector name")
+	}
+	if _, err := table.Nearest("feature", "variant", []float32{0, 1}, 1, VectorFilter{}); err != nil {
+		t.Fatalf("Failed to search: %s", err)
+	}
+	if !strings.Contains(fake.query, `targetVectors:["title"]`) {
+		t.Fatalf("Expected Nearest to search the first name, got %s", fake.query)
+	}
+}
+
+func TestWeaviateWhere(t *testing.T) {
+	if where, err := weaviateWhere(VectorFilter{}); err != nil || where != "" {
+		t.Fatalf("Expected no where argument, got %q %v", where, err)
+	}
+	where, err := weaviateWhere(VectorFilter{Attributes: map[string]string{"tier": "gold"}})
+	if err != nil {
+		t.Fatalf("Failed to build where: %s", err)
+	}
+	if expected := `,where:{path:["attributes"],operator:ContainsAll,valueText:["tier=gold"]}`; where != expected {
+		t.Fatalf("Expected %s, got %s", expected, where)
+	}
+	after := time.Date(2023, 4, 1, 0, 0, 0, 0, time.UTC)
+	where, err = weaviateWhere(VectorFilter{Attributes: map[string]string{"tier": "gold"}, UpdatedAfter: after})
+	if err != nil {
+		t.Fatalf("Failed to build where: %s", err)
+	}
+	if !strings.HasPrefix(where, ",where:{operator:And,") || !strings.Contains(where, `valueDate:"2023-04-01T00:00:00Z"`) {
+		t.Fatalf("Expected both conditions, got %s", where)
+	}
+	where, err = weaviateWhere(VectorFilter{Namespace: "tenant"})
+	if err != nil {
+		t.Fatalf("Failed to build where: %s", err)
+	}
+	if expected := `,where:{path:["namespace"],operator:Equal,valueText:"tenant"}`; where != expected {
+		t.Fatalf("Expected %s, got %s", expected, where)
+	}
+	if _, err := weaviateWhere(VectorFilter{Attributes: map[string]string{"a=b": "c"}}); err == nil {
+		t.Fatalf("Succeeded with an attribute key containing =")
+	}
+}
+
+func TestWeaviateIndexConfig(t *testing.T) {
+	config, err := weaviateIndexConfig(VectorType{ScalarType: Float32, Dimension: 2, Index: &IndexOptions{M: 32, EfRuntime: 64}})
+	if err != nil {
+		t.Fatalf("Failed to build index config: %s", err)
+	}
+	expected := map[string]interface{}{"distance": "cosine", "maxConnections": int32(32), "ef": int32(64)}
+	if !reflect.DeepEqual(config, expected) {
+		t.Fatalf("Expected %v, got %v", expected, config)
+	}
+	if _, err := weaviateIndexConfig(VectorType{ScalarType: Float32, Dimension: 2, Index: &IndexOptions{NLists: 8}}); err == nil {
+		t.Fatalf("Succeeded with IVF options")
+	}
+}
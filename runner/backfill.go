@@ -0,0 +1,211 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package runner
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"go.uber.org/zap"
+
+	cfg "github.com/featureform/config"
+	"github.com/featureform/logging"
+	"github.com/featureform/metadata"
+	"github.com/featureform/provider"
+	"github.com/featureform/types"
+
+	pc "github.com/featureform/provider/provider_config"
+	pt "github.com/featureform/provider/provider_type"
+)
+
+// BackfillVariant is the variant of the online table that a feature variant
+// is backfilled into as of asOf.
+func BackfillVariant(variant string, asOf time.Time) string {
+	return fmt.Sprintf("%s__asof_%s", variant, asOf.UTC().Format("20060102T150405Z"))
+}
+
+// BackfillRunner materializes a feature as it was at each of a series of
+// historical timestamps, into an online table per timestamp whose variant is
+// suffixed with it, so that a newly registered feature's history can be
+// experimented with before it's promoted. Timestamps are backfilled one at a
+// time, each copied like a materialization. Tables that already exist are
+// written over.
+type BackfillRunner struct {
+	Online     provider.OnlineStore
+	Offline    provider.OfflineStore
+	ID         provider.ResourceID
+	VType      provider.ValueType
+	Timestamps []time.Time
+	Cloud      JobCloud
+	Entity     string
+	KeyRules   metadata.EntityKeyRules
+	Logger     *zap.SugaredLogger
+}
+
+func (r BackfillRunner) Run() (types.CompletionWatcher, error) {
+	return r.RunContext(context.Background())
+}
+
+// RunContext backfills the timestamps until they're done or ctx is
+// cancelled. Tables of the timestamps that were backfilled are kept.
+func (r BackfillRunner) RunContext(ctx context.Context) (types.CompletionWatcher, error) {
+	store, ok := r.Offline.(provider.AsOfOfflineStore)
+	if !ok {
+		return nil, &provider.AsOfMaterializationNotSupported{ProviderType: string(r.Offline.Type())}
+	}
+	done := make(chan interface{})
+	tracker := newProgressTracker(int64(len(r.Timestamps)))
+	backfillWatcher := &SyncWatcher{
+		ResultSync:   &ResultSync{},
+		DoneChannel:  done,
+		ProgressFunc: tracker.progress,
+	}
+	go func() {
+		for _, asOf := range r.Timestamps {
+			if err := ctx.Err(); err != nil {
+				backfillWatcher.EndWatch(err)
+				return
+			}
+			rows, err := r.backfill(ctx, store, asOf)
+			if err != nil {
+				backfillWatcher.EndWatch(fmt.Errorf("backfill as of %s: %w", asOf, err))
+				return
+			}
+			tracker.addRows(rows)
+			tracker.completeChunk()
+		}
+		backfillWatcher.EndWatch(nil)
+	}()
+	return backfillWatcher, nil
+}
+
+// backfill copies the feature as of asOf into its table, and returns the
+// number of rows copied. The as-of materialization is deleted once it's been
+// copied.
+func (r BackfillRunner) backfill(ctx context.Context, store provider.AsOfOfflineStore, asOf time.Time) (int64, error) {
+	r.Logger.Infow("Backfilling Feature", "name", r.ID.Name, "variant", r.ID.Variant, "as_of", asOf)
+	materialization, err := store.CreateAsOfMaterialization(r.ID, asOf)
+	if err != nil {
+		return 0, fmt.Errorf("create as-of materialization: %w", err)
+	}
+	defer func() {
+		if err := r.Offline.DeleteMaterialization(materialization.ID()); err != nil {
+			r.Logger.Warnw("Could not delete as-of materialization", "name", r.ID.Name, "variant", r.ID.Variant, "materialization", materialization.ID(), "error", err)
+		}
+	}()
+	materializeRunner := MaterializeRunner{
+		Online:   r.Online,
+		Offline:  r.Offline,
+		ID:       provider.ResourceID{Name: r.ID.Name, Variant: BackfillVariant(r.ID.Variant, asOf), Type: provider.Feature},
+		VType:    r.VType,
+		IsUpdate: true,
+		Cloud:    r.Cloud,
+		Entity:   r.Entity,
+		KeyRules: r.KeyRules,
+		Logger:   r.Logger,
+	}
+	if err := materializeRunner.createTable(r.Online, false, false); err != nil {
+		return 0, err
+	}
+	numRows, err := materialization.NumRows()
+	if err != nil {
+		return 0, fmt.Errorf("num rows: %w", err)
+	}
+	var watcher types.CompletionWatcher
+	if materializeRunner.bulkLoads(false) {
+		watcher, err = materializeRunner.bulkLoad(ctx, r.Online.(provider.BulkLoadableStore), materialization, numRows)
+	} else {
+		var plan provider.ChunkPlan
+		plan, err = materializeRunner.planChunks(materialization, numRows, false, cfg.GetTunables())
+		if err != nil {
+			return 0, err
+		}
+		watcher, err = materializeRunner.copyChunks(ctx, materialization, plan, false)
+	}
+	if err != nil {
+		return 0, err
+	}
+	if err := watcher.Wait(); err != nil {
+		return 0, err
+	}
+	return numRows, nil
+}
+
+func (r BackfillRunner) Resource() metadata.ResourceID {
+	return metadata.ResourceID{
+		Name:    r.ID.Name,
+		Variant: r.ID.Variant,
+		Type:    metadata.FEATURE_VARIANT,
+	}
+}
+
+func (r BackfillRunner) IsUpdateJob() bool {
+	return false
+}
+
+type BackfillRunnerConfig struct {
+	OnlineType    pt.Type
+	OfflineType   pt.Type
+	OnlineConfig  pc.SerializedConfig
+	OfflineConfig pc.SerializedConfig
+	ResourceID    provider.ResourceID
+	VType         provider.ValueTypeJSONWrapper
+	Timestamps    []time.Time
+	Cloud         JobCloud
+	Entity        string
+	KeyRules      metadata.EntityKeyRules
+}
+
+func (c *BackfillRunnerConfig) Serialize() (Config, error) {
+	config, err := json.Marshal(c)
+	if err != nil {
+		return nil, fmt.Errorf("could not marshal backfill config: %w", err)
+	}
+	return config, nil
+}
+
+func (c *BackfillRunnerConfig) Deserialize(config Config) error {
+	err := json.Unmarshal(config, c)
+	if err != nil {
+		return fmt.Errorf("could not unmarshal backfill config: %w", err)
+	}
+	return nil
+}
+
+func BackfillRunnerFactory(config Config) (types.Runner, error) {
+	backfillConfig := &BackfillRunnerConfig{}
+	if err := backfillConfig.Deserialize(config); err != nil {
+		return nil, fmt.Errorf("failed to deserialize backfill config: %w", err)
+	}
+	if len(backfillConfig.Timestamps) == 0 {
+		return nil, errors.New("backfill has no timestamps")
+	}
+	online, err := getOnlineStore(backfillConfig.OnlineType, backfillConfig.OnlineConfig)
+	if err != nil {
+		return nil, err
+	}
+	offlineProvider, err := provider.Get(backfillConfig.OfflineType, backfillConfig.OfflineConfig)
+	if err != nil {
+		return nil, fmt.Errorf("failed to configure offline provider: %w", err)
+	}
+	offline, err := offlineProvider.AsOfflineStore()
+	if err != nil {
+		return nil, fmt.Errorf("failed to convert provider to offline store: %w", err)
+	}
+	return &BackfillRunner{
+		Online:     online,
+		Offline:    offline,
+		ID:         backfillConfig.ResourceID,
+		VType:      backfillConfig.VType.ValueType,
+		Timestamps: backfillConfig.Timestamps,
+		Cloud:      backfillConfig.Cloud,
+		Entity:     backfillConfig.Entity,
+		KeyRules:   backfillConfig.KeyRules,
+		Logger:     logging.NewLogger("backfill"),
+	}, nil
+}
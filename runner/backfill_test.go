@@ -0,0 +1,109 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package runner
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"go.uber.org/zap/zaptest"
+
+	"github.com/featureform/provider"
+	"github.com/featureform/types"
+)
+
+func TestBackfillRunner(t *testing.T) {
+	offline := provider.NewMemoryOfflineStore()
+	online := provider.NewLocalOnlineStore()
+	id := provider.ResourceID{Name: "amount", Variant: "default", Type: provider.Feature}
+	resourceTable, err := offline.CreateResourceTable(id, provider.TableSchema{})
+	if err != nil {
+		t.Fatalf("Failed to create resource table: %s", err)
+	}
+	records := []provider.ResourceRecord{
+		{Entity: "a", Value: 1, TS: time.UnixMilli(1000)},
+		{Entity: "a", Value: 2, TS: time.UnixMilli(3000)},
+		{Entity: "b", Value: 3, TS: time.UnixMilli(2000)},
+	}
+	for _, rec := range records {
+		if err := resourceTable.Write(rec); err != nil {
+			t.Fatalf("Failed to write record: %s", err)
+		}
+	}
+	delete(factoryMap, string(COPY_TO_ONLINE))
+	defer delete(factoryMap, string(COPY_TO_ONLINE))
+	copyChunk := func(config Config) (types.Runner, error) {
+		chunkConfig := MaterializedChunkRunnerConfig{}
+		if err := chunkConfig.Deserialize(config); err != nil {
+			return nil, err
+		}
+		materialization, err := offline.GetMaterialization(chunkConfig.MaterializedID)
+		if err != nil {
+			return nil, err
+		}
+		table, err := online.GetTable(chunkConfig.ResourceID.Name, chunkConfig.ResourceID.Variant)
+		if err != nil {
+			return nil, err
+		}
+		return &MaterializedChunkRunner{
+			Materialized: materialization,
+			Table:        table,
+			Store:        online,
+			ChunkSize:    chunkConfig.ChunkSize,
+			NumChunks:    chunkConfig.NumChunks,
+		}, nil
+	}
+	if err := RegisterFactory(string(COPY_TO_ONLINE), copyChunk); err != nil {
+		t.Fatalf("Failed to register factory: %v", err)
+	}
+	timestamps := []time.Time{time.UnixMilli(1500), time.UnixMilli(5000)}
+	backfillRunner := BackfillRunner{
+		Online:     online,
+		Offline:    offline,
+		ID:         id,
+		VType:      provider.Int,
+		Timestamps: timestamps,
+		Cloud:      LocalMaterializeRunner,
+		Logger:     zaptest.NewLogger(t).Sugar(),
+	}
+	watcher, err := backfillRunner.Run()
+	if err != nil {
+		t.Fatalf("Failed to start backfill: %s", err)
+	}
+	if err := watcher.Wait(); err != nil {
+		t.Fatalf("Failed to backfill: %s", err)
+	}
+	expected := []map[string]interface{}{{"a": 1}, {"a": 2, "b": 3}}
+	for i, asOf := range timestamps {
+		table, err := online.GetTable(id.Name, BackfillVariant(id.Variant, asOf))
+		if err != nil {
+			t.Fatalf("Failed to get backfilled table as of %s: %s", asOf, err)
+		}
+		for entity, value := range expected[i] {
+			if actual, err := table.Get(entity); err != nil || actual != value {
+				t.Fatalf("Expected %s to be %v as of %s, got %v: %v", entity, value, asOf, actual, err)
+			}
+		}
+		if _, err := table.Get("b"); i == 0 && err == nil {
+			t.Fatalf("Expected b to be missing before its first row")
+		}
+	}
+	if progress := types.ProgressOf(watcher); progress.ChunksDone != 2 || progress.RowsWritten != 3 {
+		t.Fatalf("Expected a chunk of progress per timestamp, got %+v", progress)
+	}
+}
+
+func TestBackfillRunnerNotSupported(t *testing.T) {
+	backfillRunner := BackfillRunner{
+		Offline:    MockOfflineStore{},
+		Timestamps: []time.Time{time.Now()},
+		Logger:     zaptest.NewLogger(t).Sugar(),
+	}
+	notSupported := &provider.AsOfMaterializationNotSupported{}
+	if _, err := backfillRunner.Run(); !errors.As(err, &notSupported) {
+		t.Fatalf("Expected an as-of materialization not supported error, got %v", err)
+	}
+}
@@ -0,0 +1,90 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package runner
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/featureform/metadata"
+	"github.com/featureform/provider"
+	"github.com/featureform/types"
+)
+
+// bulkLoad writes the whole materialization with the store's BulkLoad
+// instead of running copy chunks. The store writes its own format in a
+// single pass, so it's run here rather than split across workers.
+func (m MaterializeRunner) bulkLoad(ctx context.Context, store provider.BulkLoadableStore, materialization provider.Materialization, numRows int64) (types.CompletionWatcher, error) {
+	var keys *metadata.EntityKeyNormalizer
+	if !m.KeyRules.IsZero() {
+		var err error
+		if keys, err = metadata.NewEntityKeyNormalizer(m.Entity, m.KeyRules); err != nil {
+			return nil, err
+		}
+	}
+	it, err := materialization.IterateSegment(0, numRows)
+	if err != nil {
+		return nil, fmt.Errorf("iterate materialization: %w", err)
+	}
+	m.Logger.Infow("Bulk loading materialization", "name", m.ID.Name, "variant", m.ID.Variant, "count", numRows)
+	done := make(chan interface{})
+	watcher := &SyncWatcher{
+		ResultSync:  &ResultSync{},
+		DoneChannel: done,
+	}
+	go func() {
+		defer it.Close()
+		records := &normalizedKeyIterator{FeatureIterator: it, ctx: ctx, keys: keys}
+		if err := store.BulkLoad(m.ID.Name, m.ID.Variant, records); err != nil {
+			watcher.EndWatch(fmt.Errorf("bulk load: %w", err))
+			return
+		}
+		watcher.EndWatch(m.waitForIndex(m.Online))
+	}()
+	return watcher, nil
+}
+
+// normalizedKeyIterator normalizes the entity key of each record. It stops
+// at the first key that breaks the entity's key rules, or once ctx is
+// cancelled.
+type normalizedKeyIterator struct {
+	provider.FeatureIterator
+	ctx     context.Context
+	keys    *metadata.EntityKeyNormalizer
+	current provider.ResourceRecord
+	err     error
+}
+
+func (it *normalizedKeyIterator) Next() bool {
+	if it.err != nil {
+		return false
+	}
+	if err := it.ctx.Err(); err != nil {
+		it.err = err
+		return false
+	}
+	if !it.FeatureIterator.Next() {
+		return false
+	}
+	it.current = it.FeatureIterator.Value()
+	entity, err := it.keys.Normalize(it.current.Entity)
+	if err != nil {
+		it.err = fmt.Errorf("could not normalize entity key: %w", err)
+		return false
+	}
+	it.current.Entity = entity
+	return true
+}
+
+func (it *normalizedKeyIterator) Value() provider.ResourceRecord {
+	return it.current
+}
+
+func (it *normalizedKeyIterator) Err() error {
+	if it.err != nil {
+		return it.err
+	}
+	return it.FeatureIterator.Err()
+}
@@ -0,0 +1,227 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package runner
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/featureform/logging"
+	"github.com/featureform/metadata"
+	"github.com/featureform/provider"
+	"github.com/featureform/types"
+
+	pc "github.com/featureform/provider/provider_config"
+	pt "github.com/featureform/provider/provider_type"
+)
+
+const (
+	// defaultCDCIdleTimeout is how long a CDC run waits for a change before
+	// it's caught up and ends.
+	defaultCDCIdleTimeout = 30 * time.Second
+	// cdcCommitBatch is the number of messages that are applied between
+	// commits. Messages after the last commit are applied again by the next
+	// run if one fails, which is safe as changes are idempotent.
+	cdcCommitBatch = 500
+)
+
+// deletableTable is implemented by online tables that can remove an entity's
+// value.
+type deletableTable interface {
+	provider.OnlineStoreTable
+	Delete(entity string) error
+}
+
+// CDCRunner applies the row-level changes to a feature's source, read from a
+// change stream, to the feature's online table. It runs until no change has
+// been read for IdleTimeout, so that it can be scheduled to keep the table
+// fresh between materializations. Deletes are skipped for tables that can't
+// delete entities, which keep their values until the next full refresh.
+type CDCRunner struct {
+	Online  provider.OnlineStore
+	Stream  provider.ChangeStream
+	Feature string
+	Variant string
+	// Schema has the entity and value columns of the source's rows.
+	Schema      provider.ResourceSchema
+	VType       provider.ValueType
+	IdleTimeout time.Duration
+	// Keys normalizes entity keys before they're written. It's nil if the
+	// entity has no key rules.
+	Keys   *metadata.EntityKeyNormalizer
+	Logger *zap.SugaredLogger
+}
+
+func (r CDCRunner) Run() (types.CompletionWatcher, error) {
+	done := make(chan interface{})
+	cdcWatcher := &SyncWatcher{
+		ResultSync:  &ResultSync{},
+		DoneChannel: done,
+	}
+	go func() {
+		defer r.Online.Close()
+		defer r.Stream.Close()
+		table, err := r.Online.GetTable(r.Feature, r.Variant)
+		if err != nil {
+			cdcWatcher.EndWatch(fmt.Errorf("could not get online table %s %s: %w", r.Feature, r.Variant, err))
+			return
+		}
+		if err := r.apply(table); err != nil {
+			cdcWatcher.EndWatch(err)
+			return
+		}
+		cdcWatcher.EndWatch(nil)
+	}()
+	return cdcWatcher, nil
+}
+
+func (r CDCRunner) apply(table provider.OnlineStoreTable) error {
+	idleTimeout := r.IdleTimeout
+	if idleTimeout <= 0 {
+		idleTimeout = defaultCDCIdleTimeout
+	}
+	pending := make([]provider.ChangeMessage, 0, cdcCommitBatch)
+	var applied, skipped int
+	for {
+		ctx, cancel := context.WithTimeout(context.Background(), idleTimeout)
+		message, err := r.Stream.Next(ctx)
+		cancel()
+		if errors.Is(err, context.DeadlineExceeded) {
+			break
+		} else if err != nil {
+			return fmt.Errorf("could not read change: %w", err)
+		}
+		change, ok, err := provider.ParseDebeziumChange(message.Value, r.Schema, r.VType)
+		if err != nil {
+			return err
+		}
+		if ok {
+			written, err := r.applyChange(table, change)
+			if err != nil {
+				return err
+			}
+			if written {
+				applied++
+			} else {
+				skipped++
+			}
+		}
+		pending = append(pending, message)
+		if len(pending) == cdcCommitBatch {
+			if err := r.Stream.Commit(context.Background(), pending); err != nil {
+				return fmt.Errorf("could not commit changes: %w", err)
+			}
+			pending = pending[:0]
+		}
+	}
+	if err := r.Stream.Commit(context.Background(), pending); err != nil {
+		return fmt.Errorf("could not commit changes: %w", err)
+	}
+	r.Logger.Infow("Applied changes to online table",
+		"name", r.Feature, "variant", r.Variant, "applied", applied, "skipped_deletes", skipped,
+	)
+	return nil
+}
+
+// applyChange writes a change to the table, and returns false if it's a
+// delete that the table can't apply.
+func (r CDCRunner) applyChange(table provider.OnlineStoreTable, change provider.FeatureChange) (bool, error) {
+	entity, err := r.Keys.Normalize(change.Entity)
+	if err != nil {
+		return false, fmt.Errorf("could not normalize entity key: %w", err)
+	}
+	if change.Operation == provider.ChangeUpsert {
+		err = table.Set(entity, change.Value)
+	} else if deletable, ok := table.(deletableTable); ok {
+		err = deletable.Delete(entity)
+	} else {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("could not apply %s of %s: %w", change.Operation, entity, err)
+	}
+	return true, nil
+}
+
+func (r CDCRunner) Resource() metadata.ResourceID {
+	return metadata.ResourceID{Name: r.Feature, Variant: r.Variant, Type: metadata.FEATURE_VARIANT}
+}
+
+// IsUpdateJob is true as changes are only applied to features that have
+// already been materialized.
+func (r CDCRunner) IsUpdateJob() bool {
+	return true
+}
+
+type CDCRunnerConfig struct {
+	OnlineType   pt.Type
+	OnlineConfig pc.SerializedConfig
+	Kafka        pc.KafkaConfig
+	ResourceID   provider.ResourceID
+	Schema       provider.ResourceSchema
+	VType        provider.ValueTypeJSONWrapper
+	IdleTimeout  time.Duration
+	Entity       string
+	KeyRules     metadata.EntityKeyRules
+}
+
+func (c *CDCRunnerConfig) Serialize() (Config, error) {
+	config, err := json.Marshal(c)
+	if err != nil {
+		return nil, fmt.Errorf("could not marshal cdc config: %w", err)
+	}
+	return config, nil
+}
+
+func (c *CDCRunnerConfig) Deserialize(config Config) error {
+	err := json.Unmarshal(config, c)
+	if err != nil {
+		return fmt.Errorf("could not unmarshal cdc config: %w", err)
+	}
+	return nil
+}
+
+func CDCRunnerFactory(config Config) (types.Runner, error) {
+	runnerConfig := &CDCRunnerConfig{}
+	if err := runnerConfig.Deserialize(config); err != nil {
+		return nil, fmt.Errorf("failed to deserialize cdc runner config: %w", err)
+	}
+	var keys *metadata.EntityKeyNormalizer
+	if !runnerConfig.KeyRules.IsZero() {
+		var err error
+		if keys, err = metadata.NewEntityKeyNormalizer(runnerConfig.Entity, runnerConfig.KeyRules); err != nil {
+			return nil, fmt.Errorf("invalid entity key rules: %w", err)
+		}
+	}
+	onlineProvider, err := provider.Get(runnerConfig.OnlineType, runnerConfig.OnlineConfig)
+	if err != nil {
+		return nil, fmt.Errorf("failed to configure online provider: %w", err)
+	}
+	onlineStore, err := onlineProvider.AsOnlineStore()
+	if err != nil {
+		return nil, fmt.Errorf("failed to convert provider to online store: %w", err)
+	}
+	stream, err := provider.NewKafkaChangeStream(runnerConfig.Kafka)
+	if err != nil {
+		onlineStore.Close()
+		return nil, fmt.Errorf("failed to create change stream: %w", err)
+	}
+	return &CDCRunner{
+		Online:      onlineStore,
+		Stream:      stream,
+		Feature:     runnerConfig.ResourceID.Name,
+		Variant:     runnerConfig.ResourceID.Variant,
+		Schema:      runnerConfig.Schema,
+		VType:       runnerConfig.VType.ValueType,
+		IdleTimeout: runnerConfig.IdleTimeout,
+		Keys:        keys,
+		Logger:      logging.NewLogger("cdc"),
+	}, nil
+}
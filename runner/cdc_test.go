@@ -0,0 +1,143 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package runner
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+
+	"go.uber.org/zap/zaptest"
+
+	"github.com/featureform/provider"
+	pc "github.com/featureform/provider/provider_config"
+)
+
+type mockChangeStream struct {
+	messages  []provider.ChangeMessage
+	committed []provider.ChangeMessage
+}
+
+func (s *mockChangeStream) Next(ctx context.Context) (provider.ChangeMessage, error) {
+	if len(s.messages) == 0 {
+		<-ctx.Done()
+		return provider.ChangeMessage{}, ctx.Err()
+	}
+	message := s.messages[0]
+	s.messages = s.messages[1:]
+	return message, nil
+}
+
+func (s *mockChangeStream) Commit(ctx context.Context, messages []provider.ChangeMessage) error {
+	s.committed = append(s.committed, messages...)
+	return nil
+}
+
+func (s *mockChangeStream) Close() error {
+	return nil
+}
+
+type cdcOnlineStore struct {
+	MockOnlineStore
+	table provider.OnlineStoreTable
+}
+
+func (s cdcOnlineStore) GetTable(feature, variant string) (provider.OnlineStoreTable, error) {
+	return s.table, nil
+}
+
+type deletableOnlineTable struct {
+	MockOnlineTable
+}
+
+func (m *deletableOnlineTable) Delete(entity string) error {
+	delete(m.DataTable, entity)
+	return nil
+}
+
+func changeMessages(values ...string) []provider.ChangeMessage {
+	messages := make([]provider.ChangeMessage, len(values))
+	for i, value := range values {
+		messages[i] = provider.ChangeMessage{Value: []byte(value)}
+	}
+	return messages
+}
+
+func runCDC(t *testing.T, table provider.OnlineStoreTable, stream *mockChangeStream) {
+	runner := CDCRunner{
+		Online:      cdcOnlineStore{MockOnlineStore: *NewMockOnlineStore(), table: table},
+		Stream:      stream,
+		Feature:     "feature",
+		Variant:     "variant",
+		Schema:      provider.ResourceSchema{Entity: "user", Value: "balance"},
+		VType:       provider.Int,
+		IdleTimeout: 10 * time.Millisecond,
+		Logger:      zaptest.NewLogger(t).Sugar(),
+	}
+	watcher, err := runner.Run()
+	if err != nil {
+		t.Fatalf("Failed to start cdc runner: %s", err)
+	}
+	if err := watcher.Wait(); err != nil {
+		t.Fatalf("CDC runner failed: %s", err)
+	}
+}
+
+var cdcTestMessages = []string{
+	`{"op": "c", "after": {"user": "a", "balance": 1}}`,
+	`{"op": "c", "after": {"user": "b", "balance": 2}}`,
+	`{"op": "u", "before": {"user": "a", "balance": 1}, "after": {"user": "a", "balance": 3}}`,
+	`{"op": "d", "before": {"user": "b", "balance": 2}}`,
+	``,
+}
+
+func TestCDCRunnerAppliesChanges(t *testing.T) {
+	table := &deletableOnlineTable{MockOnlineTable{DataTable: map[string]interface{}{}}}
+	stream := &mockChangeStream{messages: changeMessages(cdcTestMessages...)}
+	runCDC(t, table, stream)
+	expected := map[string]interface{}{"a": 3}
+	if !reflect.DeepEqual(table.DataTable, expected) {
+		t.Fatalf("Expected %v, got %v", expected, table.DataTable)
+	}
+	if len(stream.committed) != len(cdcTestMessages) {
+		t.Fatalf("Expected %d committed messages, got %d", len(cdcTestMessages), len(stream.committed))
+	}
+}
+
+func TestCDCRunnerSkipsUnsupportedDeletes(t *testing.T) {
+	table := &MockOnlineTable{DataTable: map[string]interface{}{}}
+	stream := &mockChangeStream{messages: changeMessages(cdcTestMessages...)}
+	runCDC(t, table, stream)
+	expected := map[string]interface{}{"a": 3, "b": 2}
+	if !reflect.DeepEqual(table.DataTable, expected) {
+		t.Fatalf("Expected %v, got %v", expected, table.DataTable)
+	}
+	if len(stream.committed) != len(cdcTestMessages) {
+		t.Fatalf("Expected %d committed messages, got %d", len(cdcTestMessages), len(stream.committed))
+	}
+}
+
+func TestCDCRunnerConfigRoundTrip(t *testing.T) {
+	config := &CDCRunnerConfig{
+		OnlineType:  "REDIS_ONLINE",
+		Kafka:       pc.KafkaConfig{Brokers: []string{"broker:9092"}, Topic: "topic", GroupID: "group"},
+		ResourceID:  provider.ResourceID{Name: "feature", Variant: "variant", Type: provider.Feature},
+		Schema:      provider.ResourceSchema{Entity: "user", Value: "balance"},
+		VType:       provider.ValueTypeJSONWrapper{ValueType: provider.Int},
+		IdleTimeout: time.Minute,
+	}
+	serialized, err := config.Serialize()
+	if err != nil {
+		t.Fatalf("Failed to serialize config: %s", err)
+	}
+	deserialized := &CDCRunnerConfig{}
+	if err := deserialized.Deserialize(serialized); err != nil {
+		t.Fatalf("Failed to deserialize config: %s", err)
+	}
+	if !reflect.DeepEqual(config, deserialized) {
+		t.Fatalf("Expected %v, got %v", config, deserialized)
+	}
+}
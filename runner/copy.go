@@ -5,16 +5,22 @@
 package runner
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"math"
 	"sync"
+	"time"
 
+	"github.com/featureform/logging"
 	"github.com/featureform/metadata"
 	"github.com/featureform/provider"
 	pc "github.com/featureform/provider/provider_config"
 	pt "github.com/featureform/provider/provider_type"
 	"github.com/featureform/types"
 	"go.uber.org/zap"
+	"golang.org/x/time/rate"
 )
 
 type IndexRunner interface {
@@ -26,8 +32,84 @@ type MaterializedChunkRunner struct {
 	Materialized provider.Materialization
 	Table        provider.OnlineStoreTable
 	Store        provider.OnlineStore
-	ChunkSize    int64
-	ChunkIdx     int64
+	// AdditionalStores are the other stores that Table writes to, which are
+	// closed with Store.
+	AdditionalStores []provider.OnlineStore
+	// Buffer is the write-behind buffer wrapping Table, if buffering is
+	// enabled. It's closed after the last row so buffered values are flushed.
+	Buffer *provider.BufferedTable
+	// Keys normalizes entity keys before they're written. It's nil if the
+	// entity has no key rules.
+	Keys      *metadata.EntityKeyNormalizer
+	ChunkSize int64
+	ChunkIdx  int64
+	NumChunks int64
+	// Approximate chunks were planned from an estimate of the rows, so the
+	// last of the NumChunks copies every row after its start.
+	Approximate bool
+	// Partitioned chunks copy partition ChunkIdx of the materialization
+	// instead of a range of ChunkSize rows.
+	Partitioned bool
+	// RowRateLimit caps the rows this chunk copies per second. Zero disables
+	// the limit.
+	RowRateLimit float64
+	// BatchSize writes up to this many rows at once if Table implements
+	// provider.BatchSettableTable. Rows are written one at a time if it's
+	// at most one, or if they're buffered by Buffer instead.
+	BatchSize int
+	// Retry copies the chunk again from its first row if it fails.
+	Retry ChunkRetryPolicy
+	// Checkpoints records the chunk as completed once it's been copied, and
+	// skips it if it already was. It's nil if chunks aren't checkpointed.
+	Checkpoints provider.ChunkCheckpointStore
+	// CopyID is the copy the chunk is part of. If it's set and the chunk is
+	// checkpointed, the chunk is skipped once the feature's checkpoint is no
+	// longer of the copy, so a retry of a superseded or finished copy doesn't
+	// write over newer values.
+	CopyID string
+	// PlannedRows is how many rows the materialization had when the chunk
+	// was planned. The chunk fails without being retried if it has changed,
+	// rather than writing rows of another version of it. Zero skips the
+	// check.
+	PlannedRows int64
+	Feature     provider.ResourceID
+	Logger      *zap.SugaredLogger
+}
+
+// ChunkRetryPolicy retries a chunk up to MaxAttempts times in all, waiting
+// InitialBackoff before the first retry and twice as long before each retry
+// after it, up to MaxBackoff. A chunk is attempted once if MaxAttempts isn't
+// more than one.
+type ChunkRetryPolicy struct {
+	MaxAttempts    int
+	InitialBackoff time.Duration
+	MaxBackoff     time.Duration
+}
+
+// backoff is how long to wait before the given retry, counting from one.
+func (p ChunkRetryPolicy) backoff(retry int) time.Duration {
+	wait := p.InitialBackoff
+	for i := 1; i < retry && (p.MaxBackoff <= 0 || wait < p.MaxBackoff); i++ {
+		wait *= 2
+	}
+	if p.MaxBackoff > 0 && wait > p.MaxBackoff {
+		wait = p.MaxBackoff
+	}
+	return wait
+}
+
+// permanentChunkError is an error that retrying a chunk won't fix, such as
+// an entity key that can't be normalized.
+type permanentChunkError struct {
+	err error
+}
+
+func (e *permanentChunkError) Error() string {
+	return e.err.Error()
+}
+
+func (e *permanentChunkError) Unwrap() error {
+	return e.err
 }
 
 type ResultSync struct {
@@ -45,62 +127,304 @@ func (m *MaterializedChunkRunner) IsUpdateJob() bool {
 }
 
 func (m *MaterializedChunkRunner) Run() (types.CompletionWatcher, error) {
+	return m.RunContext(context.Background())
+}
+
+// RunContext copies the chunk until it's done or ctx is cancelled. Rows
+// written before it's cancelled stay in the online store, and the chunk isn't
+// checkpointed.
+func (m *MaterializedChunkRunner) RunContext(ctx context.Context) (types.CompletionWatcher, error) {
 	done := make(chan interface{})
+	tracker := newProgressTracker(1)
+	metrics := newRunMetrics(string(COPY_TO_ONLINE), m.Feature)
 	jobWatcher := &SyncWatcher{
-		ResultSync:  &ResultSync{},
-		DoneChannel: done,
+		ResultSync:   &ResultSync{},
+		DoneChannel:  done,
+		ProgressFunc: tracker.progress,
+	}
+	end := func(err error) {
+		metrics.finish(err)
+		jobWatcher.EndWatch(err)
+	}
+	written := func(rows, bytes int64) {
+		tracker.addRows(rows)
+		metrics.addRows(rows, bytes)
 	}
 	go func() {
-		if m.ChunkSize == 0 {
-			jobWatcher.EndWatch(nil)
-			return
+		completed := m.checkpointed()
+		if !completed {
+			if err := m.copyWithRetries(ctx, written); err != nil {
+				end(err)
+				return
+			}
 		}
-		numRows, err := m.Materialized.NumRows()
-		if err != nil {
-			jobWatcher.EndWatch(fmt.Errorf("failed to get number of rows: %w", err))
-			return
+		if m.Buffer != nil {
+			if err := m.Buffer.Close(); err != nil {
+				end(fmt.Errorf("failed to flush buffered writes: %w", err))
+				return
+			}
 		}
-		if numRows == 0 {
-			jobWatcher.EndWatch(nil)
+		if !completed {
+			m.checkpoint()
+		}
+		for _, store := range append([]provider.OnlineStore{m.Store}, m.AdditionalStores...) {
+			if err := store.Close(); err != nil {
+				end(fmt.Errorf("failed to close Online Store: %w", err))
+				return
+			}
+		}
+		tracker.completeChunk()
+		end(nil)
+	}()
+	return jobWatcher, nil
+}
+
+// checkpointed returns true if the chunk was completed by an earlier run of
+// the same copy, or if its copy has been superseded. Chunks whose checkpoint
+// can't be read are copied again.
+func (m *MaterializedChunkRunner) checkpointed() bool {
+	if m.Checkpoints == nil {
+		return false
+	}
+	checkpoint, err := m.Checkpoints.GetChunkCheckpoint(m.Feature)
+	notFound := &provider.ChunkCheckpointNotFound{}
+	if errors.As(err, &notFound) && m.CopyID != "" {
+		m.logInfo("Skipping chunk of a finished copy", "chunk", m.ChunkIdx, "copy", m.CopyID)
+		return true
+	}
+	if err != nil && !errors.As(err, &notFound) && m.Logger != nil {
+		m.Logger.Warnw("Could not read chunk checkpoint, copying chunk", "chunk", m.ChunkIdx, "error", err)
+	}
+	if err != nil {
+		return false
+	}
+	if m.superseded(checkpoint) {
+		m.logInfo("Skipping chunk of a superseded copy", "chunk", m.ChunkIdx, "copy", m.CopyID, "current_copy", checkpoint.Plan.CopyID)
+		return true
+	}
+	if checkpoint.Plan.Materialization != m.Materialized.ID() || !checkpoint.Completed[m.ChunkIdx] {
+		return false
+	}
+	m.logInfo("Skipping chunk completed before", "chunk", m.ChunkIdx)
+	return true
+}
+
+// superseded is true if checkpoint is of a copy other than the chunk's.
+func (m *MaterializedChunkRunner) superseded(checkpoint provider.ChunkCheckpoint) bool {
+	return m.CopyID != "" && checkpoint.Plan.CopyID != m.CopyID
+}
+
+func (m *MaterializedChunkRunner) logInfo(msg string, keysAndValues ...interface{}) {
+	if m.Logger != nil {
+		m.Logger.Infow(msg, keysAndValues...)
+	}
+}
+
+// checkpoint records the chunk as completed. Its rows have been written, so
+// failing to record it only means they're written again if the copy resumes.
+// It isn't recorded if a newer copy has started since, so that copy doesn't
+// skip the chunk.
+func (m *MaterializedChunkRunner) checkpoint() {
+	if m.Checkpoints == nil {
+		return
+	}
+	if m.CopyID != "" {
+		checkpoint, err := m.Checkpoints.GetChunkCheckpoint(m.Feature)
+		if err != nil || m.superseded(checkpoint) {
+			m.logInfo("Not checkpointing chunk of a superseded copy", "chunk", m.ChunkIdx, "copy", m.CopyID)
 			return
 		}
+	}
+	if err := m.Checkpoints.CompleteChunk(m.Feature, m.ChunkIdx); err != nil && m.Logger != nil {
+		m.Logger.Warnw("Could not checkpoint chunk", "chunk", m.ChunkIdx, "error", err)
+	}
+}
 
-		rowStart := m.ChunkIdx * m.ChunkSize
-		rowEnd := rowStart + m.ChunkSize
-		if rowEnd > numRows {
-			rowEnd = numRows
+// copyWithRetries copies the chunk's rows, retrying with the chunk's retry
+// policy if it fails. Rows are written again from the first when it's
+// retried, and counted again by written. It isn't retried once ctx is
+// cancelled.
+func (m *MaterializedChunkRunner) copyWithRetries(ctx context.Context, written func(rows, bytes int64)) error {
+	for attempt := 1; ; attempt++ {
+		err := m.copyRows(ctx, written)
+		var permanent *permanentChunkError
+		if err == nil || errors.As(err, &permanent) || ctx.Err() != nil {
+			return err
 		}
-		it, err := m.Materialized.IterateSegment(rowStart, rowEnd)
+		if attempt >= m.Retry.MaxAttempts {
+			if attempt > 1 {
+				return fmt.Errorf("chunk %d failed after %d attempts: %w", m.ChunkIdx, attempt, err)
+			}
+			return err
+		}
+		wait := m.Retry.backoff(attempt)
+		if m.Logger != nil {
+			m.Logger.Warnw("Retrying chunk", "chunk", m.ChunkIdx, "attempt", attempt, "backoff", wait, "error", err)
+		}
+		select {
+		case <-time.After(wait):
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+// copyRows writes each of the chunk's rows to the table, in batches if it can
+// write them, until ctx is cancelled. Rows and their approximate size are
+// counted by written once they're written.
+func (m *MaterializedChunkRunner) copyRows(ctx context.Context, written func(rows, bytes int64)) error {
+	it, err := m.iterate()
+	if err != nil {
+		return err
+	}
+	if it == nil {
+		return nil
+	}
+	var limiter *rate.Limiter
+	if m.RowRateLimit > 0 {
+		limiter = rate.NewLimiter(rate.Limit(m.RowRateLimit), int(math.Max(1, m.RowRateLimit)))
+	}
+	batch := newRowBatch(m.Table, m.BatchSize)
+	for it.Next() {
+		if err := ctx.Err(); err != nil {
+			it.Close()
+			return err
+		}
+		if limiter != nil {
+			if err := limiter.Wait(ctx); err != nil {
+				it.Close()
+				return err
+			}
+		}
+		value := it.Value().Value
+		entity, err := m.Keys.Normalize(it.Value().Entity)
 		if err != nil {
-			jobWatcher.EndWatch(fmt.Errorf("failed to create iterator: %w", err))
-			return
+			it.Close()
+			return &permanentChunkError{fmt.Errorf("could not normalize entity key: %w", err)}
 		}
-		i := 0
-		for it.Next() {
-			i += 1
-			value := it.Value().Value
-			entity := it.Value().Entity
-			err := m.Table.Set(entity, value)
-			if err != nil {
-				jobWatcher.EndWatch(fmt.Errorf("could not set table: %w", err))
-				return
+		if batch == nil {
+			if err := m.Table.Set(entity, value); err != nil {
+				it.Close()
+				return fmt.Errorf("could not set table: %w", err)
 			}
+			written(1, int64(len(entity))+valueBytes(value))
+			continue
 		}
-		if err = it.Err(); err != nil {
-			jobWatcher.EndWatch(fmt.Errorf("iteration failed with error: %w", err))
-			return
+		if err := batch.add(entity, value, written); err != nil {
+			it.Close()
+			return err
 		}
-		err = it.Close()
+	}
+	if err := it.Err(); err != nil {
+		it.Close()
+		return fmt.Errorf("iteration failed with error: %w", err)
+	}
+	if batch != nil {
+		if err := batch.flush(written); err != nil {
+			it.Close()
+			return err
+		}
+	}
+	if err := it.Close(); err != nil {
+		return fmt.Errorf("failed to close iterator: %w", err)
+	}
+	return nil
+}
+
+// rowBatch collects a chunk's rows to write them to a table at once. Rows of
+// the same entity are coalesced, keeping the last, like writing them in turn.
+type rowBatch struct {
+	table  provider.BatchSettableTable
+	size   int
+	values map[string]interface{}
+	// rows and bytes count the rows in the batch, including coalesced ones.
+	rows  int64
+	bytes int64
+}
+
+// newRowBatch returns a batch of up to size rows for table, or nil if rows
+// should be written one at a time.
+func newRowBatch(table provider.OnlineStoreTable, size int) *rowBatch {
+	batchTable, ok := table.(provider.BatchSettableTable)
+	if !ok || size <= 1 {
+		return nil
+	}
+	return &rowBatch{
+		table:  batchTable,
+		size:   size,
+		values: make(map[string]interface{}, size),
+	}
+}
+
+// add adds the row to the batch, and writes the batch once it's full.
+func (b *rowBatch) add(entity string, value interface{}, written func(rows, bytes int64)) error {
+	b.values[entity] = value
+	b.rows++
+	b.bytes += int64(len(entity)) + valueBytes(value)
+	if len(b.values) >= b.size {
+		return b.flush(written)
+	}
+	return nil
+}
+
+// flush writes the batch and counts its rows. A batch that fails may have
+// been partially written.
+func (b *rowBatch) flush(written func(rows, bytes int64)) error {
+	if len(b.values) == 0 {
+		return nil
+	}
+	if err := b.table.SetBatch(b.values); err != nil {
+		return fmt.Errorf("could not set batch of %d entities: %w", len(b.values), err)
+	}
+	written(b.rows, b.bytes)
+	b.values = make(map[string]interface{}, b.size)
+	b.rows = 0
+	b.bytes = 0
+	return nil
+}
+
+// iterate returns an iterator over the chunk's rows, or nil if it has none.
+func (m *MaterializedChunkRunner) iterate() (provider.FeatureIterator, error) {
+	if m.Partitioned {
+		partitioned, ok := m.Materialized.(provider.PartitionedMaterialization)
+		if !ok {
+			return nil, &permanentChunkError{fmt.Errorf("materialization %s is not partitioned", m.Materialized.ID())}
+		}
+		it, err := partitioned.IteratePartition(int(m.ChunkIdx))
 		if err != nil {
-			jobWatcher.EndWatch(fmt.Errorf("failed to close iterator: %w", err))
+			return nil, fmt.Errorf("failed to create iterator: %w", err)
 		}
-		err = m.Store.Close()
+		return it, nil
+	}
+	if m.ChunkSize == 0 {
+		return nil, nil
+	}
+	rowStart := m.ChunkIdx * m.ChunkSize
+	rowEnd := rowStart + m.ChunkSize
+	if m.Approximate {
+		if m.ChunkIdx == m.NumChunks-1 {
+			rowEnd = math.MaxInt64
+		}
+	} else {
+		numRows, err := m.Materialized.NumRows()
 		if err != nil {
-			jobWatcher.EndWatch(fmt.Errorf("failed to close Online Store: %w", err))
+			return nil, fmt.Errorf("failed to get number of rows: %w", err)
 		}
-		jobWatcher.EndWatch(nil)
-	}()
-	return jobWatcher, nil
+		if m.PlannedRows > 0 && numRows != m.PlannedRows {
+			return nil, &permanentChunkError{fmt.Errorf("materialization %s has %d rows but was planned with %d, so it changed after the copy started", m.Materialized.ID(), numRows, m.PlannedRows)}
+		}
+		if numRows == 0 {
+			return nil, nil
+		}
+		if rowEnd > numRows {
+			rowEnd = numRows
+		}
+	}
+	it, err := m.Materialized.IterateSegment(rowStart, rowEnd)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create iterator: %w", err)
+	}
+	return it, nil
 }
 
 func (m *MaterializedChunkRunner) SetIndex(index int) error {
@@ -135,6 +459,20 @@ func (r *ResultSync) DoneWithError(err error) {
 type SyncWatcher struct {
 	ResultSync  *ResultSync
 	DoneChannel chan interface{}
+	// ProgressFunc reports the job's progress. If it's nil, the job is
+	// reported as a single chunk.
+	ProgressFunc func() types.Progress
+}
+
+func (m *SyncWatcher) Progress() types.Progress {
+	if m.ProgressFunc != nil {
+		return m.ProgressFunc()
+	}
+	progress := types.Progress{ChunksTotal: 1}
+	if m.Complete() {
+		progress.ChunksDone = 1
+	}
+	return progress
 }
 
 func (m *SyncWatcher) Err() error {
@@ -163,16 +501,58 @@ func (m *SyncWatcher) String() string {
 }
 
 type MaterializedChunkRunnerConfig struct {
-	OnlineType     pt.Type
-	OfflineType    pt.Type
-	OnlineConfig   pc.SerializedConfig
-	OfflineConfig  pc.SerializedConfig
-	MaterializedID provider.MaterializationID
-	ResourceID     provider.ResourceID
-	ChunkSize      int64
-	ChunkIdx       int64
-	IsUpdate       bool
-	Logger         *zap.SugaredLogger
+	OnlineType   pt.Type
+	OfflineType  pt.Type
+	OnlineConfig pc.SerializedConfig
+	// AdditionalOnline are other online stores that each row is written to as
+	// well as the one of OnlineType.
+	AdditionalOnline []OnlineTarget
+	OfflineConfig    pc.SerializedConfig
+	MaterializedID   provider.MaterializationID
+	ResourceID       provider.ResourceID
+	ChunkSize        int64
+	ChunkIdx         int64
+	NumChunks        int64
+	// Approximate chunks were planned from an estimate of the rows, and
+	// aren't checked against the materialization's row count.
+	Approximate bool
+	// Partitioned chunks copy partition ChunkIdx of the materialization, and
+	// ignore ChunkSize.
+	Partitioned bool
+	IsUpdate    bool
+	// DedupWindow skips writes of values already written for an entity within
+	// the window. Zero disables deduplication.
+	DedupWindow time.Duration
+	// BufferSize holds up to this many entities' writes in memory and writes
+	// them as a batch. Zero disables buffering.
+	BufferSize          int
+	BufferFlushInterval time.Duration
+	// BatchSize writes up to this many rows at once to online providers that
+	// can write batches. Zero or one writes each row on its own.
+	BatchSize int
+	// WriteRateLimit caps the entities this chunk writes per second. Zero
+	// disables rate limiting.
+	WriteRateLimit float64
+	// ProviderWriteRateLimit caps the write requests this chunk makes to each
+	// online provider per second, counting a batch as one request. Zero
+	// disables it.
+	ProviderWriteRateLimit float64
+	// RowRateLimit caps the rows this chunk copies per second, whether or not
+	// they're deduplicated or buffered before they're written. Zero disables
+	// it.
+	RowRateLimit float64
+	// Retry is how the chunk is retried if it fails.
+	Retry ChunkRetryPolicy
+	// Checkpoint records completed chunks in the offline store, if it can
+	// keep them, so they're skipped if the copy is resumed.
+	Checkpoint bool
+	// CopyID and PlannedRows are those of the chunk's plan, so that re-runs
+	// of the chunk are skipped or fail instead of writing stale rows.
+	CopyID      string
+	PlannedRows int64
+	Entity      string
+	KeyRules    metadata.EntityKeyRules
+	Logger      *zap.SugaredLogger
 }
 
 func (m *MaterializedChunkRunnerConfig) Serialize() (Config, error) {
@@ -209,6 +589,10 @@ func MaterializedChunkRunnerFactory(config Config) (types.Runner, error) {
 	if err != nil {
 		return nil, fmt.Errorf("failed to convert provider to online store: %v", err)
 	}
+	additionalStores, err := onlineStores(runnerConfig.AdditionalOnline)
+	if err != nil {
+		return nil, err
+	}
 	offlineStore, err := offlineProvider.AsOfflineStore()
 	if err != nil {
 		return nil, fmt.Errorf("failed to convert provider to offline store: %v", err)
@@ -217,22 +601,87 @@ func MaterializedChunkRunnerFactory(config Config) (types.Runner, error) {
 	if err != nil {
 		return nil, fmt.Errorf("cannot get materialization: %v", err)
 	}
-	numRows, err := materialization.NumRows()
-	if err != nil {
-		return nil, fmt.Errorf("cannot get materialization num rows: %v", err)
+	if runnerConfig.Partitioned {
+		if _, ok := materialization.(provider.PartitionedMaterialization); !ok {
+			return nil, fmt.Errorf("materialization %s is not partitioned", runnerConfig.MaterializedID)
+		}
+	} else if !runnerConfig.Approximate {
+		numRows, err := materialization.NumRows()
+		if err != nil {
+			return nil, fmt.Errorf("cannot get materialization num rows: %v", err)
+		}
+		if runnerConfig.ChunkSize*runnerConfig.ChunkIdx > numRows {
+			return nil, fmt.Errorf("chunk runner starts after end of materialization rows")
+		}
 	}
-	if runnerConfig.ChunkSize*runnerConfig.ChunkIdx > numRows {
-		return nil, fmt.Errorf("chunk runner starts after end of materialization rows")
+	var keys *metadata.EntityKeyNormalizer
+	if !runnerConfig.KeyRules.IsZero() {
+		keys, err = metadata.NewEntityKeyNormalizer(runnerConfig.Entity, runnerConfig.KeyRules)
+		if err != nil {
+			return nil, fmt.Errorf("invalid entity key rules: %v", err)
+		}
 	}
-	table, err := onlineStore.GetTable(runnerConfig.ResourceID.Name, runnerConfig.ResourceID.Variant)
-	if err != nil {
-		return nil, fmt.Errorf("error getting online table: %v", err)
+	tables := make([]provider.OnlineStoreTable, 0, len(additionalStores)+1)
+	for _, store := range append([]provider.OnlineStore{onlineStore}, additionalStores...) {
+		table, err := store.GetTable(runnerConfig.ResourceID.Name, runnerConfig.ResourceID.Variant)
+		if err != nil {
+			return nil, fmt.Errorf("error getting online table: %v", err)
+		}
+		if runnerConfig.ProviderWriteRateLimit > 0 {
+			table, err = provider.NewRequestRateLimitedTable(table, runnerConfig.ProviderWriteRateLimit)
+			if err != nil {
+				return nil, fmt.Errorf("error rate limiting online table requests: %v", err)
+			}
+		}
+		if runnerConfig.WriteRateLimit > 0 {
+			table, err = provider.NewRateLimitedTable(table, runnerConfig.WriteRateLimit)
+			if err != nil {
+				return nil, fmt.Errorf("error rate limiting online table: %v", err)
+			}
+		}
+		tables = append(tables, table)
+	}
+	table := tables[0]
+	if len(tables) > 1 {
+		table, err = provider.NewFanOutTable(tables...)
+		if err != nil {
+			return nil, fmt.Errorf("error fanning out online tables: %v", err)
+		}
+	}
+	var buffer *provider.BufferedTable
+	if runnerConfig.BufferSize > 0 {
+		buffer, err = provider.NewBufferedTable(table, runnerConfig.BufferSize, runnerConfig.BufferFlushInterval)
+		if err != nil {
+			return nil, fmt.Errorf("error buffering online table: %v", err)
+		}
+		table = buffer
+	}
+	if runnerConfig.DedupWindow > 0 {
+		table = provider.NewDedupTable(table, runnerConfig.DedupWindow)
+	}
+	var checkpoints provider.ChunkCheckpointStore
+	if runnerConfig.Checkpoint {
+		checkpoints, _ = offlineStore.(provider.ChunkCheckpointStore)
 	}
 	return &MaterializedChunkRunner{
-		Materialized: materialization,
-		Table:        table,
-		Store:        onlineStore,
-		ChunkSize:    runnerConfig.ChunkSize,
-		ChunkIdx:     runnerConfig.ChunkIdx,
+		Materialized:     materialization,
+		Table:            table,
+		Store:            onlineStore,
+		AdditionalStores: additionalStores,
+		Buffer:           buffer,
+		Keys:             keys,
+		ChunkSize:        runnerConfig.ChunkSize,
+		ChunkIdx:         runnerConfig.ChunkIdx,
+		NumChunks:        runnerConfig.NumChunks,
+		Approximate:      runnerConfig.Approximate,
+		Partitioned:      runnerConfig.Partitioned,
+		RowRateLimit:     runnerConfig.RowRateLimit,
+		BatchSize:        runnerConfig.BatchSize,
+		Retry:            runnerConfig.Retry,
+		Checkpoints:      checkpoints,
+		CopyID:           runnerConfig.CopyID,
+		PlannedRows:      runnerConfig.PlannedRows,
+		Feature:          runnerConfig.ResourceID,
+		Logger:           logging.NewLogger("materialize-chunk"),
 	}, nil
 }
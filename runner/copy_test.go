@@ -5,12 +5,16 @@
 package runner
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"reflect"
+	"strings"
 	"sync"
 	"testing"
+	"time"
 
+	"github.com/featureform/metadata"
 	"github.com/featureform/provider"
 	pc "github.com/featureform/provider/provider_config"
 	pt "github.com/featureform/provider/provider_type"
@@ -412,6 +416,10 @@ func (b BrokenGetTableOnlineStore) DeleteTable(feature, variant string) error {
 	return nil
 }
 
+func (b BrokenGetTableOnlineStore) Capabilities() provider.OnlineCapabilities {
+	return provider.OnlineCapabilities{}
+}
+
 func (b BrokenGetTableOnlineStore) Close() error {
 	return nil
 }
@@ -736,6 +744,10 @@ func (m MockOnlineStore) DeleteTable(feature, variant string) error {
 	return nil
 }
 
+func (m MockOnlineStore) Capabilities() provider.OnlineCapabilities {
+	return provider.OnlineCapabilities{}
+}
+
 func (m MockOnlineStore) Close() error {
 	return nil
 }
@@ -896,6 +908,39 @@ func TestChunkRunnerFactory(t *testing.T) {
 	}
 }
 
+func TestChunkRunnerFactoryFansOut(t *testing.T) {
+	offline := NewMockOfflineStore()
+	resourceID := provider.ResourceID{"test_name", "test_variant", provider.Feature}
+	if _, err := offline.CreateResourceTable(resourceID, provider.TableSchema{}); err != nil {
+		t.Fatalf("Failed to create offline resource table: %v", err)
+	}
+	materialization, err := offline.CreateMaterialization(resourceID)
+	if err != nil {
+		t.Fatalf("Failed to create materialization: %v", err)
+	}
+	chunkRunnerConfig := MaterializedChunkRunnerConfig{
+		OnlineType:       "MOCK_ONLINE",
+		OfflineType:      "MOCK_OFFLINE",
+		OnlineConfig:     []byte{},
+		AdditionalOnline: []OnlineTarget{{Type: "MOCK_ONLINE", Config: []byte{}}},
+		OfflineConfig:    []byte{},
+		MaterializedID:   materialization.ID(),
+		ResourceID:       resourceID,
+	}
+	serializedConfig, err := chunkRunnerConfig.Serialize()
+	if err != nil {
+		t.Fatalf("Failed to serialize chunk runner config: %v", err)
+	}
+	runner, err := MaterializedChunkRunnerFactory(serializedConfig)
+	if err != nil {
+		t.Fatalf("Failed to create materialized chunk runner: %v", err)
+	}
+	chunkRunner := runner.(*MaterializedChunkRunner)
+	if _, ok := chunkRunner.Table.(*provider.FanOutTable); !ok || len(chunkRunner.AdditionalStores) != 1 {
+		t.Fatalf("Expected the chunk to write to both stores, got %T", chunkRunner.Table)
+	}
+}
+
 func TestRunnerConfigDeserializeFails(t *testing.T) {
 	failConfig := []byte("this should fail when attempted to be deserialized")
 	config := &MaterializedChunkRunnerConfig{}
@@ -903,3 +948,445 @@ func TestRunnerConfigDeserializeFails(t *testing.T) {
 		t.Fatalf("Failed to report error deserializing config")
 	}
 }
+
+func TestJobNormalizesEntityKeys(t *testing.T) {
+	keys, err := metadata.NewEntityKeyNormalizer("user", metadata.EntityKeyRules{Trim: true, Lowercase: true})
+	if err != nil {
+		t.Fatalf("Failed to create normalizer: %s", err)
+	}
+	materialized := MockMaterializedFeatures{
+		id:   provider.MaterializationID(uuid.NewString()),
+		Rows: []provider.ResourceRecord{{Entity: " User1 ", Value: 1}},
+	}
+	table := &MockOnlineTable{DataTable: make(map[string]interface{})}
+	job := &MaterializedChunkRunner{
+		Materialized: &materialized,
+		Table:        table,
+		Store:        NewMockOnlineStore(),
+		Keys:         keys,
+		ChunkSize:    1,
+	}
+	completionWatcher, err := job.Run()
+	if err != nil {
+		t.Fatalf("Job failed to start: %s", err)
+	}
+	if err := completionWatcher.Wait(); err != nil {
+		t.Fatalf("Job failed: %s", err)
+	}
+	if value, err := table.Get("user1"); err != nil || value != 1 {
+		t.Fatalf("Expected value under normalized key, got %v %v", value, err)
+	}
+}
+
+type MockPartitionedFeatures struct {
+	MockMaterializedFeatures
+	Partitions [][]provider.ResourceRecord
+}
+
+func (m *MockPartitionedFeatures) NumPartitions() (int, error) {
+	return len(m.Partitions), nil
+}
+
+func (m *MockPartitionedFeatures) IteratePartition(partition int) (provider.FeatureIterator, error) {
+	return &MockFeatureIterator{
+		CurrentIndex: -1,
+		Slice:        m.Partitions[partition],
+	}, nil
+}
+
+func TestJobCopiesPartition(t *testing.T) {
+	materialized := &MockPartitionedFeatures{
+		MockMaterializedFeatures: MockMaterializedFeatures{id: provider.MaterializationID(uuid.NewString())},
+		Partitions: [][]provider.ResourceRecord{
+			{{Entity: "a", Value: 1}, {Entity: "b", Value: 2}},
+			{{Entity: "c", Value: 3}},
+		},
+	}
+	table := &MockOnlineTable{DataTable: make(map[string]interface{})}
+	job := &MaterializedChunkRunner{
+		Materialized: materialized,
+		Table:        table,
+		Store:        NewMockOnlineStore(),
+		ChunkIdx:     1,
+		Partitioned:  true,
+	}
+	completionWatcher, err := job.Run()
+	if err != nil {
+		t.Fatalf("Job failed to start: %s", err)
+	}
+	if err := completionWatcher.Wait(); err != nil {
+		t.Fatalf("Job failed: %s", err)
+	}
+	if len(table.DataTable) != 1 || table.DataTable["c"] != 3 {
+		t.Fatalf("Expected only partition 1 to be copied, got %v", table.DataTable)
+	}
+	job.Materialized = &materialized.MockMaterializedFeatures
+	completionWatcher, err = job.Run()
+	if err != nil {
+		t.Fatalf("Job failed to start: %s", err)
+	}
+	if err := completionWatcher.Wait(); err == nil {
+		t.Fatalf("Expected a partitioned chunk of an unpartitioned materialization to fail")
+	}
+}
+
+// MockEstimatedFeatures underestimates its rows, and can't be counted.
+type MockEstimatedFeatures struct {
+	MockMaterializedFeatures
+	Estimate int64
+}
+
+func (m *MockEstimatedFeatures) NumRows() (int64, error) {
+	return 0, errors.New("rows can't be counted")
+}
+
+func (m *MockEstimatedFeatures) ApproxNumRows() (int64, error) {
+	return m.Estimate, nil
+}
+
+func (m *MockEstimatedFeatures) IterateSegment(begin int64, end int64) (provider.FeatureIterator, error) {
+	if end > int64(len(m.Rows)) {
+		end = int64(len(m.Rows))
+	}
+	return m.MockMaterializedFeatures.IterateSegment(begin, end)
+}
+
+func TestJobCopiesPastEstimateInLastChunk(t *testing.T) {
+	materialized := &MockEstimatedFeatures{
+		MockMaterializedFeatures: MockMaterializedFeatures{
+			id:   provider.MaterializationID(uuid.NewString()),
+			Rows: []provider.ResourceRecord{{Entity: "a", Value: 1}, {Entity: "b", Value: 2}, {Entity: "c", Value: 3}},
+		},
+		Estimate: 2,
+	}
+	table := &MockOnlineTable{DataTable: make(map[string]interface{})}
+	job := &MaterializedChunkRunner{
+		Materialized: materialized,
+		Table:        table,
+		Store:        NewMockOnlineStore(),
+		ChunkSize:    1,
+		ChunkIdx:     1,
+		NumChunks:    2,
+		Approximate:  true,
+	}
+	completionWatcher, err := job.Run()
+	if err != nil {
+		t.Fatalf("Job failed to start: %s", err)
+	}
+	if err := completionWatcher.Wait(); err != nil {
+		t.Fatalf("Job failed: %s", err)
+	}
+	if len(table.DataTable) != 2 || table.DataTable["b"] != 2 || table.DataTable["c"] != 3 {
+		t.Fatalf("Expected the last chunk to copy every row after its start, got %v", table.DataTable)
+	}
+}
+
+// flakyOnlineTable fails the first failures sets.
+type flakyOnlineTable struct {
+	MockOnlineTable
+	failures int
+}
+
+func (m *flakyOnlineTable) Set(entity string, value interface{}) error {
+	if m.failures > 0 {
+		m.failures--
+		return errors.New("throttled")
+	}
+	return m.MockOnlineTable.Set(entity, value)
+}
+
+func TestJobRetriesFailedChunk(t *testing.T) {
+	materialized := MockMaterializedFeatures{
+		id:   provider.MaterializationID(uuid.NewString()),
+		Rows: []provider.ResourceRecord{{Entity: "a", Value: 1}, {Entity: "b", Value: 2}},
+	}
+	retry := ChunkRetryPolicy{MaxAttempts: 3, InitialBackoff: time.Millisecond}
+	table := &flakyOnlineTable{MockOnlineTable: MockOnlineTable{DataTable: make(map[string]interface{})}, failures: 2}
+	job := &MaterializedChunkRunner{
+		Materialized: &materialized,
+		Table:        table,
+		Store:        NewMockOnlineStore(),
+		ChunkSize:    2,
+		Retry:        retry,
+	}
+	watcher, err := job.Run()
+	if err != nil {
+		t.Fatalf("Job failed to start: %s", err)
+	}
+	if err := watcher.Wait(); err != nil {
+		t.Fatalf("Expected the chunk to succeed when retried: %s", err)
+	}
+	if len(table.DataTable) != 2 {
+		t.Fatalf("Expected both rows to be written, got %v", table.DataTable)
+	}
+
+	table = &flakyOnlineTable{MockOnlineTable: MockOnlineTable{DataTable: make(map[string]interface{})}, failures: 3}
+	job = &MaterializedChunkRunner{
+		Materialized: &materialized,
+		Table:        table,
+		Store:        NewMockOnlineStore(),
+		ChunkSize:    2,
+		Retry:        retry,
+	}
+	watcher, err = job.Run()
+	if err != nil {
+		t.Fatalf("Job failed to start: %s", err)
+	}
+	if err := watcher.Wait(); err == nil || !strings.Contains(err.Error(), "after 3 attempts") {
+		t.Fatalf("Expected the chunk to fail after 3 attempts, got %v", err)
+	}
+}
+
+func TestJobDoesNotRetryKeyErrors(t *testing.T) {
+	keys, err := metadata.NewEntityKeyNormalizer("user", metadata.EntityKeyRules{Pattern: "^[0-9]+$"})
+	if err != nil {
+		t.Fatalf("Failed to create normalizer: %s", err)
+	}
+	materialized := MockMaterializedFeatures{
+		id:   provider.MaterializationID(uuid.NewString()),
+		Rows: []provider.ResourceRecord{{Entity: "user1", Value: 1}},
+	}
+	job := &MaterializedChunkRunner{
+		Materialized: &materialized,
+		Table:        &MockOnlineTable{DataTable: make(map[string]interface{})},
+		Store:        NewMockOnlineStore(),
+		Keys:         keys,
+		ChunkSize:    1,
+		Retry:        ChunkRetryPolicy{MaxAttempts: 3, InitialBackoff: time.Hour},
+	}
+	watcher, err := job.Run()
+	if err != nil {
+		t.Fatalf("Job failed to start: %s", err)
+	}
+	if err := watcher.Wait(); err == nil || strings.Contains(err.Error(), "attempts") {
+		t.Fatalf("Expected the key error without retries, got %v", err)
+	}
+}
+
+func TestChunkRetryPolicyBackoff(t *testing.T) {
+	policy := ChunkRetryPolicy{MaxAttempts: 10, InitialBackoff: time.Second, MaxBackoff: 5 * time.Second}
+	expected := []time.Duration{time.Second, 2 * time.Second, 4 * time.Second, 5 * time.Second, 5 * time.Second}
+	for i, wait := range expected {
+		if backoff := policy.backoff(i + 1); backoff != wait {
+			t.Errorf("Expected %s before retry %d, got %s", wait, i+1, backoff)
+		}
+	}
+}
+
+func TestJobSkipsCheckpointedChunk(t *testing.T) {
+	materialized := MockMaterializedFeatures{
+		id:   provider.MaterializationID(uuid.NewString()),
+		Rows: []provider.ResourceRecord{{Entity: "a", Value: 1}, {Entity: "b", Value: 2}},
+	}
+	feature := provider.ResourceID{Name: "test", Variant: "test", Type: provider.Feature}
+	checkpoints := provider.NewMemoryOfflineStore()
+	plan := provider.ChunkPlan{Materialization: materialized.ID(), ChunkSize: 1, NumChunks: 2}
+	if err := checkpoints.StartChunkCheckpoint(feature, plan); err != nil {
+		t.Fatalf("Failed to start checkpoint: %s", err)
+	}
+	if err := checkpoints.CompleteChunk(feature, 0); err != nil {
+		t.Fatalf("Failed to complete chunk: %s", err)
+	}
+	table := &MockOnlineTable{DataTable: make(map[string]interface{})}
+	for i := 0; i < 2; i++ {
+		job := &MaterializedChunkRunner{
+			Materialized: &materialized,
+			Table:        table,
+			Store:        NewMockOnlineStore(),
+			ChunkSize:    1,
+			ChunkIdx:     int64(i),
+			NumChunks:    2,
+			Checkpoints:  checkpoints,
+			Feature:      feature,
+		}
+		watcher, err := job.Run()
+		if err != nil {
+			t.Fatalf("Job failed to start: %s", err)
+		}
+		if err := watcher.Wait(); err != nil {
+			t.Fatalf("Job failed: %s", err)
+		}
+	}
+	if len(table.DataTable) != 1 || table.DataTable["b"] != 2 {
+		t.Fatalf("Expected only the incomplete chunk to be copied, got %v", table.DataTable)
+	}
+	checkpoint, err := checkpoints.GetChunkCheckpoint(feature)
+	if err != nil {
+		t.Fatalf("Failed to get checkpoint: %s", err)
+	}
+	if !checkpoint.Completed[0] || !checkpoint.Completed[1] {
+		t.Fatalf("Expected both chunks to be checkpointed, got %v", checkpoint.Completed)
+	}
+}
+
+func TestJobSkipsSupersededChunk(t *testing.T) {
+	materialized := MockMaterializedFeatures{
+		id:   provider.MaterializationID(uuid.NewString()),
+		Rows: []provider.ResourceRecord{{Entity: "a", Value: 1}},
+	}
+	feature := provider.ResourceID{Name: "test", Variant: "test", Type: provider.Feature}
+	checkpoints := provider.NewMemoryOfflineStore()
+	plan := provider.ChunkPlan{CopyID: "newer", Materialization: materialized.ID(), ChunkSize: 1, NumChunks: 1}
+	if err := checkpoints.StartChunkCheckpoint(feature, plan); err != nil {
+		t.Fatalf("Failed to start checkpoint: %s", err)
+	}
+	table := &MockOnlineTable{DataTable: make(map[string]interface{})}
+	job := &MaterializedChunkRunner{
+		Materialized: &materialized,
+		Table:        table,
+		Store:        NewMockOnlineStore(),
+		ChunkSize:    1,
+		NumChunks:    1,
+		Checkpoints:  checkpoints,
+		CopyID:       "older",
+		Feature:      feature,
+	}
+	watcher, err := job.Run()
+	if err != nil {
+		t.Fatalf("Job failed to start: %s", err)
+	}
+	if err := watcher.Wait(); err != nil {
+		t.Fatalf("Job failed: %s", err)
+	}
+	if len(table.DataTable) != 0 {
+		t.Fatalf("Expected a superseded chunk not to be copied, got %v", table.DataTable)
+	}
+	checkpoint, err := checkpoints.GetChunkCheckpoint(feature)
+	if err != nil {
+		t.Fatalf("Failed to get checkpoint: %s", err)
+	}
+	if checkpoint.Completed[0] {
+		t.Fatalf("Expected the newer copy's chunk not to be checkpointed")
+	}
+}
+
+func TestJobFailsWhenMaterializationChanged(t *testing.T) {
+	materialized := MockMaterializedFeatures{
+		id:   provider.MaterializationID(uuid.NewString()),
+		Rows: []provider.ResourceRecord{{Entity: "a", Value: 1}, {Entity: "b", Value: 2}},
+	}
+	table := &MockOnlineTable{DataTable: make(map[string]interface{})}
+	job := &MaterializedChunkRunner{
+		Materialized: &materialized,
+		Table:        table,
+		Store:        NewMockOnlineStore(),
+		ChunkSize:    2,
+		PlannedRows:  3,
+		Retry:        ChunkRetryPolicy{MaxAttempts: 3, InitialBackoff: time.Hour},
+	}
+	watcher, err := job.Run()
+	if err != nil {
+		t.Fatalf("Job failed to start: %s", err)
+	}
+	if err := watcher.Wait(); err == nil || strings.Contains(err.Error(), "attempts") {
+		t.Fatalf("Expected the changed materialization to fail without retries, got %v", err)
+	}
+	if len(table.DataTable) != 0 {
+		t.Fatalf("Expected no rows to be written, got %v", table.DataTable)
+	}
+}
+
+func TestJobStopsWhenCancelled(t *testing.T) {
+	materialized := MockMaterializedFeatures{
+		id:   provider.MaterializationID(uuid.NewString()),
+		Rows: []provider.ResourceRecord{{Entity: "a", Value: 1}, {Entity: "b", Value: 2}},
+	}
+	table := &MockOnlineTable{DataTable: make(map[string]interface{})}
+	job := &MaterializedChunkRunner{
+		Materialized: &materialized,
+		Table:        table,
+		Store:        NewMockOnlineStore(),
+		ChunkSize:    2,
+		Retry:        ChunkRetryPolicy{MaxAttempts: 3, InitialBackoff: time.Millisecond},
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	watcher, err := job.RunContext(ctx)
+	if err != nil {
+		t.Fatalf("Job failed to start: %s", err)
+	}
+	if err := watcher.Wait(); !errors.Is(err, context.Canceled) {
+		t.Fatalf("Expected the cancelled chunk to fail with context.Canceled, got %v", err)
+	}
+	if len(table.DataTable) != 0 {
+		t.Fatalf("Expected no rows to be written, got %v", table.DataTable)
+	}
+}
+
+func TestJobLimitsRowRate(t *testing.T) {
+	materialized := MockMaterializedFeatures{
+		id:   provider.MaterializationID(uuid.NewString()),
+		Rows: []provider.ResourceRecord{{Entity: "a", Value: 1}, {Entity: "b", Value: 2}, {Entity: "c", Value: 3}, {Entity: "d", Value: 4}},
+	}
+	table := &MockOnlineTable{DataTable: make(map[string]interface{})}
+	job := &MaterializedChunkRunner{
+		Materialized: &materialized,
+		Table:        table,
+		Store:        NewMockOnlineStore(),
+		ChunkSize:    4,
+		RowRateLimit: 2,
+	}
+	start := time.Now()
+	watcher, err := job.Run()
+	if err != nil {
+		t.Fatalf("Job failed to start: %s", err)
+	}
+	if err := watcher.Wait(); err != nil {
+		t.Fatalf("Job failed: %s", err)
+	}
+	// The first 2 rows use the burst and the other 2 wait for tokens.
+	if elapsed := time.Since(start); elapsed < 900*time.Millisecond {
+		t.Fatalf("Expected rows to be rate limited, took %s", elapsed)
+	}
+	if len(table.DataTable) != 4 {
+		t.Fatalf("Expected every row to be written, got %v", table.DataTable)
+	}
+}
+
+type batchOnlineTable struct {
+	MockOnlineTable
+	batches []int
+}
+
+func (m *batchOnlineTable) SetBatch(values map[string]interface{}) error {
+	m.batches = append(m.batches, len(values))
+	for entity, value := range values {
+		m.DataTable[entity] = value
+	}
+	return nil
+}
+
+func TestJobWritesBatches(t *testing.T) {
+	materialized := MockMaterializedFeatures{
+		id: provider.MaterializationID(uuid.NewString()),
+		Rows: []provider.ResourceRecord{
+			{Entity: "a", Value: 1}, {Entity: "a", Value: 2}, {Entity: "b", Value: 3},
+			{Entity: "c", Value: 4}, {Entity: "d", Value: 5}, {Entity: "e", Value: 6},
+		},
+	}
+	table := &batchOnlineTable{MockOnlineTable: MockOnlineTable{DataTable: make(map[string]interface{})}}
+	job := &MaterializedChunkRunner{
+		Materialized: &materialized,
+		Table:        table,
+		Store:        NewMockOnlineStore(),
+		ChunkSize:    6,
+		BatchSize:    2,
+	}
+	watcher, err := job.Run()
+	if err != nil {
+		t.Fatalf("Job failed to start: %s", err)
+	}
+	if err := watcher.Wait(); err != nil {
+		t.Fatalf("Job failed: %s", err)
+	}
+	// The second row of entity a replaces the first in the same batch.
+	if !reflect.DeepEqual(table.batches, []int{2, 2, 1}) {
+		t.Fatalf("Expected batches of 2, 2 and 1 entities, got %v", table.batches)
+	}
+	if table.DataTable["a"] != 2 || len(table.DataTable) != 5 {
+		t.Fatalf("Expected the last value of every entity to be written, got %v", table.DataTable)
+	}
+	if progress := watcher.(*SyncWatcher).Progress(); progress.RowsWritten != 6 {
+		t.Fatalf("Expected 6 rows written, got %+v", progress)
+	}
+}
@@ -7,6 +7,7 @@ package runner
 import (
 	"encoding/json"
 	"fmt"
+	"os/exec"
 
 	"github.com/featureform/metadata"
 	"github.com/featureform/provider"
@@ -16,6 +17,16 @@ import (
 	pt "github.com/featureform/provider/provider_type"
 )
 
+// runDBT builds a dbt model with the dbt CLI, which has to be installed
+// where the runner runs.
+var runDBT = func(model provider.DBTModel) error {
+	output, err := exec.Command("dbt", model.RunArgs()...).CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("dbt run of model %s failed: %w: %s", model.Model, err, output)
+	}
+	return nil
+}
+
 func (c *CreateTransformationRunner) Run() (types.CompletionWatcher, error) {
 	done := make(chan interface{})
 	transformationWatcher := &SyncWatcher{
@@ -23,13 +34,28 @@ func (c *CreateTransformationRunner) Run() (types.CompletionWatcher, error) {
 		DoneChannel: done,
 	}
 	go func() {
+		config := c.TransformationConfig
+		if config.Type == provider.DBTTransformation {
+			// The table dbt builds is then treated like the output of a
+			// SQL transformation, so it's rebuilt on each scheduled update.
+			outputConfig, err := config.DBTOutputConfig()
+			if err != nil {
+				transformationWatcher.EndWatch(err)
+				return
+			}
+			if err := runDBT(config.DBT); err != nil {
+				transformationWatcher.EndWatch(err)
+				return
+			}
+			config = outputConfig
+		}
 		if !c.IsUpdate {
-			if err := c.Offline.CreateTransformation(c.TransformationConfig); err != nil {
+			if err := c.Offline.CreateTransformation(config); err != nil {
 				transformationWatcher.EndWatch(err)
 				return
 			}
 		} else {
-			if err := c.Offline.UpdateTransformation(c.TransformationConfig); err != nil {
+			if err := c.Offline.UpdateTransformation(config); err != nil {
 				transformationWatcher.EndWatch(err)
 				return
 			}
@@ -109,6 +109,77 @@ func TestFail(t *testing.T) {
 	}
 }
 
+type dbtOfflineStore struct {
+	MockOfflineStore
+	created []provider.TransformationConfig
+}
+
+func (m *dbtOfflineStore) CreateTransformation(config provider.TransformationConfig) error {
+	m.created = append(m.created, config)
+	return nil
+}
+
+func TestRunDBTTransformation(t *testing.T) {
+	var ran []provider.DBTModel
+	defer func(run func(provider.DBTModel) error) { runDBT = run }(runDBT)
+	runDBT = func(model provider.DBTModel) error {
+		ran = append(ran, model)
+		return nil
+	}
+	store := &dbtOfflineStore{}
+	model := provider.DBTModel{ProjectDir: "/dbt/project", Model: "orders", Table: "analytics.orders"}
+	runner := CreateTransformationRunner{
+		Offline: store,
+		TransformationConfig: provider.TransformationConfig{
+			Type:          provider.DBTTransformation,
+			TargetTableID: provider.ResourceID{Name: "orders", Variant: "v1", Type: provider.Transformation},
+			DBT:           model,
+		},
+	}
+	watcher, err := runner.Run()
+	if err != nil {
+		t.Fatalf("failed to run dbt transformation: %v", err)
+	}
+	if err := watcher.Wait(); err != nil {
+		t.Fatalf("dbt transformation failed: %v", err)
+	}
+	if !reflect.DeepEqual(ran, []provider.DBTModel{model}) {
+		t.Fatalf("expected dbt to run %v, got %v", model, ran)
+	}
+	if len(store.created) != 1 {
+		t.Fatalf("expected one transformation, got %d", len(store.created))
+	}
+	created := store.created[0]
+	if created.Type != provider.SQLTransformation || created.Query != "SELECT * FROM analytics.orders" {
+		t.Fatalf("expected a SQL transformation of the dbt table, got %v", created)
+	}
+}
+
+func TestRunDBTTransformationFail(t *testing.T) {
+	defer func(run func(provider.DBTModel) error) { runDBT = run }(runDBT)
+	runDBT = func(model provider.DBTModel) error {
+		return fmt.Errorf("compilation error")
+	}
+	store := &dbtOfflineStore{}
+	runner := CreateTransformationRunner{
+		Offline: store,
+		TransformationConfig: provider.TransformationConfig{
+			Type: provider.DBTTransformation,
+			DBT:  provider.DBTModel{ProjectDir: "/dbt/project", Model: "orders"},
+		},
+	}
+	watcher, err := runner.Run()
+	if err != nil {
+		t.Fatalf("failed to run dbt transformation: %v", err)
+	}
+	if err := watcher.Wait(); err == nil {
+		t.Fatalf("failed to report dbt run error")
+	}
+	if len(store.created) != 0 {
+		t.Fatalf("expected no transformation after a failed dbt run, got %v", store.created)
+	}
+}
+
 func testTransformationErrorConfigsFactory(config Config) error {
 	_, err := Create(CREATE_TRANSFORMATION, config)
 	return err
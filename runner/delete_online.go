@@ -0,0 +1,129 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package runner
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"go.uber.org/zap"
+
+	"github.com/featureform/logging"
+	"github.com/featureform/metadata"
+	"github.com/featureform/provider"
+	"github.com/featureform/types"
+
+	pc "github.com/featureform/provider/provider_config"
+	pt "github.com/featureform/provider/provider_type"
+)
+
+// OnlineDeletionRunner removes a deleted feature variant's table from the
+// online stores it was materialized to. Vector stores drop the variant's
+// index along with its table. Tables that don't exist are skipped, so a
+// deletion that failed part way can be run again.
+type OnlineDeletionRunner struct {
+	Online provider.OnlineStore
+	// AdditionalOnline are the other online stores that the feature was
+	// materialized to.
+	AdditionalOnline []provider.OnlineStore
+	ID               provider.ResourceID
+	Logger           *zap.SugaredLogger
+}
+
+func (r OnlineDeletionRunner) Run() (types.CompletionWatcher, error) {
+	done := make(chan interface{})
+	deletionWatcher := &SyncWatcher{
+		ResultSync:  &ResultSync{},
+		DoneChannel: done,
+	}
+	go func() {
+		stores := append([]provider.OnlineStore{r.Online}, r.AdditionalOnline...)
+		for _, store := range stores {
+			if err := r.deleteTable(store); err != nil {
+				deletionWatcher.EndWatch(err)
+				return
+			}
+		}
+		for _, store := range stores {
+			if err := store.Close(); err != nil {
+				r.Logger.Errorw("Failed to close online store", "type", store.Type(), "error", err)
+			}
+		}
+		deletionWatcher.EndWatch(nil)
+	}()
+	return deletionWatcher, nil
+}
+
+func (r OnlineDeletionRunner) deleteTable(store provider.OnlineStore) error {
+	r.Logger.Infow("Deleting Table", "name", r.ID.Name, "variant", r.ID.Variant, "type", store.Type())
+	err := store.DeleteTable(r.ID.Name, r.ID.Variant)
+	notFound := &provider.TableNotFound{}
+	if errors.As(err, &notFound) {
+		r.Logger.Infow("Table already deleted", "name", r.ID.Name, "variant", r.ID.Variant, "type", store.Type())
+		return nil
+	} else if err != nil {
+		return fmt.Errorf("delete table from %s: %w", store.Type(), err)
+	}
+	return nil
+}
+
+func (r OnlineDeletionRunner) Resource() metadata.ResourceID {
+	return metadata.ResourceID{
+		Name:    r.ID.Name,
+		Variant: r.ID.Variant,
+		Type:    metadata.FEATURE_VARIANT,
+	}
+}
+
+func (r OnlineDeletionRunner) IsUpdateJob() bool {
+	return false
+}
+
+type OnlineDeletionRunnerConfig struct {
+	OnlineType   pt.Type
+	OnlineConfig pc.SerializedConfig
+	// AdditionalOnline are the other online stores that the feature was
+	// materialized to.
+	AdditionalOnline []OnlineTarget
+	ResourceID       provider.ResourceID
+}
+
+func (c *OnlineDeletionRunnerConfig) Serialize() (Config, error) {
+	config, err := json.Marshal(c)
+	if err != nil {
+		return nil, fmt.Errorf("could not marshal online deletion config: %w", err)
+	}
+	return config, nil
+}
+
+func (c *OnlineDeletionRunnerConfig) Deserialize(config Config) error {
+	err := json.Unmarshal(config, c)
+	if err != nil {
+		return fmt.Errorf("could not unmarshal online deletion config: %w", err)
+	}
+	return nil
+}
+
+func OnlineDeletionRunnerFactory(config Config) (types.Runner, error) {
+	deletionConfig := &OnlineDeletionRunnerConfig{}
+	if err := deletionConfig.Deserialize(config); err != nil {
+		return nil, fmt.Errorf("failed to deserialize online deletion config: %w", err)
+	}
+	online, err := getOnlineStore(deletionConfig.OnlineType, deletionConfig.OnlineConfig)
+	if err != nil {
+		return nil, err
+	}
+	additionalOnline, err := onlineStores(deletionConfig.AdditionalOnline)
+	if err != nil {
+		return nil, err
+	}
+	return &OnlineDeletionRunner{
+		Online:           online,
+		AdditionalOnline: additionalOnline,
+		ID:               deletionConfig.ResourceID,
+		Logger:           logging.NewLogger("online-deletion"),
+	}, nil
+}
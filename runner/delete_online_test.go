@@ -0,0 +1,81 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package runner
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"go.uber.org/zap/zaptest"
+
+	"github.com/featureform/provider"
+	pt "github.com/featureform/provider/provider_type"
+)
+
+func TestOnlineDeletionRunner(t *testing.T) {
+	online := provider.NewLocalOnlineStore()
+	additional := provider.NewLocalOnlineStore()
+	for _, store := range []provider.OnlineStore{online, additional} {
+		if _, err := store.CreateTable("feature", "variant", provider.Int); err != nil {
+			t.Fatalf("Failed to create table: %s", err)
+		}
+	}
+	if _, err := online.CreateTable("feature", "other", provider.Int); err != nil {
+		t.Fatalf("Failed to create table: %s", err)
+	}
+	deletionRunner := OnlineDeletionRunner{
+		Online:           online,
+		AdditionalOnline: []provider.OnlineStore{additional},
+		ID:               provider.ResourceID{Name: "feature", Variant: "variant", Type: provider.Feature},
+		Logger:           zaptest.NewLogger(t).Sugar(),
+	}
+	// Deleting again succeeds, since the tables are already gone.
+	for i := 0; i < 2; i++ {
+		watcher, err := deletionRunner.Run()
+		if err != nil {
+			t.Fatalf("Failed to run deletion: %s", err)
+		}
+		if err := watcher.Wait(); err != nil {
+			t.Fatalf("Failed to delete tables: %s", err)
+		}
+	}
+	notFound := &provider.TableNotFound{}
+	for _, store := range []provider.OnlineStore{online, additional} {
+		if _, err := store.GetTable("feature", "variant"); !errors.As(err, &notFound) {
+			t.Fatalf("Expected the table to be deleted, got %v", err)
+		}
+	}
+	if _, err := online.GetTable("feature", "other"); err != nil {
+		t.Fatalf("Expected other variants' tables to be kept: %s", err)
+	}
+}
+
+func TestOnlineDeletionRunnerConfig(t *testing.T) {
+	config := OnlineDeletionRunnerConfig{
+		OnlineType:       pt.LocalOnline,
+		OnlineConfig:     []byte("{}"),
+		AdditionalOnline: []OnlineTarget{{Type: pt.LocalOnline, Config: []byte("{}")}},
+		ResourceID:       provider.ResourceID{Name: "feature", Variant: "variant", Type: provider.Feature},
+	}
+	serialized, err := config.Serialize()
+	if err != nil {
+		t.Fatalf("Failed to serialize config: %s", err)
+	}
+	deserialized := OnlineDeletionRunnerConfig{}
+	if err := deserialized.Deserialize(serialized); err != nil {
+		t.Fatalf("Failed to deserialize config: %s", err)
+	}
+	if !reflect.DeepEqual(config, deserialized) {
+		t.Fatalf("Expected %+v, got %+v", config, deserialized)
+	}
+	runner, err := OnlineDeletionRunnerFactory(serialized)
+	if err != nil {
+		t.Fatalf("Failed to create runner: %s", err)
+	}
+	if deletion := runner.(*OnlineDeletionRunner); len(deletion.AdditionalOnline) != 1 || deletion.ID != config.ResourceID {
+		t.Fatalf("Unexpected runner: %+v", deletion)
+	}
+}
@@ -0,0 +1,217 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package runner
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	cfg "github.com/featureform/config"
+	"github.com/featureform/provider"
+	pt "github.com/featureform/provider/provider_type"
+	"github.com/featureform/types"
+)
+
+// MaterializationPlan is what a materialization would do, as planned by a dry
+// run.
+type MaterializationPlan struct {
+	Materialization provider.MaterializationID
+	Rows            int64
+	// ApproximateRows is set if Rows is an estimate.
+	ApproximateRows bool
+	Incremental     bool
+	// BulkLoad is set if the materialization is bulk loaded instead of copied
+	// in Chunks.
+	BulkLoad bool
+	Chunks   provider.ChunkPlan
+	// Resuming is set if an earlier copy is resumed, which has already
+	// completed CompletedChunks of Chunks.
+	Resuming        bool
+	CompletedChunks int
+	Cloud           JobCloud
+	// Workers is how many chunks are copied at once by a local runner.
+	Workers int64
+	// RateLimits are the rate limits of each chunk.
+	RateLimits ChunkRateLimits
+	Tables     []TablePlan
+}
+
+// TablePlan is what a materialization would do to its table in an online
+// store.
+type TablePlan struct {
+	Store pt.Type
+	// Exists is set if the table already exists, in which case it's
+	// truncated if Truncate is set and otherwise written over.
+	Exists      bool
+	Truncate    bool
+	CreateIndex bool
+}
+
+func (p MaterializationPlan) String() string {
+	var b strings.Builder
+	rows := fmt.Sprintf("%d", p.Rows)
+	if p.ApproximateRows {
+		rows = "~" + rows
+	}
+	fmt.Fprintf(&b, "materialization %s: %s rows", p.Materialization, rows)
+	if p.Incremental {
+		b.WriteString(", incremental")
+	}
+	if p.BulkLoad {
+		b.WriteString(", bulk loaded")
+	} else {
+		fmt.Fprintf(&b, ", %d chunks of %d rows", p.Chunks.NumChunks, p.Chunks.ChunkSize)
+		if p.Chunks.Partitioned {
+			b.WriteString(" by partition")
+		}
+		if p.Resuming {
+			fmt.Fprintf(&b, ", resuming with %d done", p.CompletedChunks)
+		}
+		if p.Cloud == LocalMaterializeRunner {
+			fmt.Fprintf(&b, ", %d local workers", p.Workers)
+		} else {
+			fmt.Fprintf(&b, " on %s", strings.ToLower(string(p.Cloud)))
+		}
+		if p.RateLimits.Rows > 0 {
+			fmt.Fprintf(&b, ", %.0f rows/s per chunk", p.RateLimits.Rows)
+		}
+		if p.RateLimits.Entities > 0 {
+			fmt.Fprintf(&b, ", %.0f entities/s per chunk", p.RateLimits.Entities)
+		}
+		if p.RateLimits.ProviderWrites > 0 {
+			fmt.Fprintf(&b, ", %.0f provider writes/s per chunk", p.RateLimits.ProviderWrites)
+		}
+	}
+	for _, table := range p.Tables {
+		action := "create table"
+		if table.Exists && table.Truncate {
+			action = "truncate table"
+		} else if table.Exists {
+			action = "write to existing table"
+		}
+		if table.CreateIndex {
+			action += " and index"
+		}
+		fmt.Fprintf(&b, "; %s: %s", table.Store, action)
+	}
+	return b.String()
+}
+
+// PlanWatcher is the watcher of a dry run. It's complete as soon as it's
+// returned, and reports the plan instead of the job's progress.
+type PlanWatcher struct {
+	Plan MaterializationPlan
+}
+
+func (w PlanWatcher) Complete() bool {
+	return true
+}
+
+func (w PlanWatcher) String() string {
+	return w.Plan.String()
+}
+
+func (w PlanWatcher) Wait() error {
+	return nil
+}
+
+func (w PlanWatcher) Err() error {
+	return nil
+}
+
+// dryRun plans the materialization without writing to the online stores. The
+// materialization has to exist in the offline store to count its rows, so
+// updates still update it, and new materializations are created and then
+// deleted again so the real run starts from scratch. Checkpoints are read
+// but never started.
+func (m MaterializeRunner) dryRun() (types.CompletionWatcher, error) {
+	m.Logger.Infow("Planning Materialization", "name", m.ID.Name, "variant", m.ID.Variant)
+	tunables := cfg.GetTunables()
+	plan := MaterializationPlan{Cloud: m.Cloud}
+	materialization, chunks, resuming := m.resume()
+	var err error
+	if resuming {
+		plan.Resuming = true
+		checkpoint, err := m.Offline.(provider.ChunkCheckpointStore).GetChunkCheckpoint(m.ID)
+		if err != nil {
+			return nil, fmt.Errorf("get chunk checkpoint: %w", err)
+		}
+		plan.CompletedChunks = len(checkpoint.Completed)
+	} else if m.IsUpdate {
+		materialization, plan.Incremental, err = m.updateMaterialization()
+	} else {
+		materialization, err = m.createMaterialization()
+		if err == nil {
+			defer func() {
+				if err := m.Offline.DeleteMaterialization(materialization.ID()); err != nil {
+					m.Logger.Warnw("Could not delete planned materialization", "name", m.ID.Name, "variant", m.ID.Variant, "materialization", materialization.ID(), "error", err)
+				}
+			}()
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+	plan.Materialization = materialization.ID()
+	for _, store := range m.onlineStores() {
+		table, err := m.planTable(store, plan.Incremental, resuming)
+		if err != nil {
+			return nil, err
+		}
+		plan.Tables = append(plan.Tables, table)
+	}
+	if resuming {
+		plan.Rows = chunks.ChunkSize * chunks.NumChunks
+		plan.ApproximateRows = true
+	} else {
+		plan.BulkLoad = m.bulkLoads(plan.Incremental)
+		plan.Rows, plan.ApproximateRows, err = m.numRows(materialization, tunables.ApproximateChunkPlanning && !plan.BulkLoad)
+		if err != nil {
+			return nil, fmt.Errorf("num rows: %w", err)
+		}
+		if !plan.BulkLoad {
+			chunks, err = m.planChunks(materialization, plan.Rows, plan.ApproximateRows, tunables)
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+	if !plan.BulkLoad {
+		plan.Chunks = chunks
+		plan.Workers = localMaterializeWorkers(tunables.LocalMaterializeWorkers, chunks.NumChunks)
+		plan.RateLimits = m.chunkRateLimits(tunables, chunks.NumChunks, plan.Workers)
+	}
+	m.Logger.Infow("Planned Materialization", "name", m.ID.Name, "variant", m.ID.Variant, "plan", plan.String())
+	return PlanWatcher{Plan: plan}, nil
+}
+
+// planTable is what createTable would do to the feature's table in store.
+func (m MaterializeRunner) planTable(store provider.OnlineStore, incremental bool, resuming bool) (TablePlan, error) {
+	table := TablePlan{Store: store.Type()}
+	if vectorType, ok := m.VType.(provider.VectorType); ok && vectorType.IsEmbedding {
+		if _, ok := store.(provider.VectorStore); !ok || !store.Capabilities().Vectors {
+			return TablePlan{}, fmt.Errorf("cannot create index on non-vector store: %v", store)
+		}
+		table.CreateIndex = true
+	}
+	_, err := store.GetTable(m.ID.Name, m.ID.Variant)
+	notFound := &provider.TableNotFound{}
+	if errors.As(err, &notFound) {
+		return table, nil
+	} else if err != nil {
+		return TablePlan{}, fmt.Errorf("get table error: %w", err)
+	}
+	table.Exists = true
+	if resuming {
+		return table, nil
+	}
+	if !m.IsUpdate {
+		return TablePlan{}, fmt.Errorf("table already exists despite being new job")
+	}
+	_, truncatable := store.(provider.TruncatableStore)
+	table.Truncate = !incremental && cfg.GetTunables().FullRefreshMaterializations && truncatable && store.Capabilities().Truncate
+	return table, nil
+}
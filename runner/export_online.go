@@ -0,0 +1,124 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package runner
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"go.uber.org/zap"
+
+	"github.com/featureform/logging"
+	"github.com/featureform/metadata"
+	"github.com/featureform/provider"
+	"github.com/featureform/types"
+
+	pc "github.com/featureform/provider/provider_config"
+	pt "github.com/featureform/provider/provider_type"
+)
+
+// OnlineExportRunner writes the contents of an online table to a file in a
+// file store. Both stores are closed once it's done.
+type OnlineExportRunner struct {
+	Store   provider.OnlineStore
+	Files   provider.FileStore
+	Feature string
+	Variant string
+	Key     string
+	Format  provider.FileType
+	Logger  *zap.SugaredLogger
+}
+
+func (r OnlineExportRunner) Run() (types.CompletionWatcher, error) {
+	done := make(chan interface{})
+	exportWatcher := &SyncWatcher{
+		ResultSync:  &ResultSync{},
+		DoneChannel: done,
+	}
+	go func() {
+		defer r.Store.Close()
+		defer r.Files.Close()
+		if !r.Store.Capabilities().Scan {
+			exportWatcher.EndWatch(fmt.Errorf("%s does not support scans", r.Store.Type()))
+			return
+		}
+		table, err := r.Store.GetTable(r.Feature, r.Variant)
+		if err != nil {
+			exportWatcher.EndWatch(err)
+			return
+		}
+		written, err := provider.ExportOnlineTable(table, r.Files, r.Key, r.Format)
+		if err != nil {
+			exportWatcher.EndWatch(fmt.Errorf("could not export table %s %s: %w", r.Feature, r.Variant, err))
+			return
+		}
+		r.Logger.Infow("Online table export complete",
+			"name", r.Feature, "variant", r.Variant, "key", r.Key, "entities", written,
+		)
+		exportWatcher.EndWatch(nil)
+	}()
+	return exportWatcher, nil
+}
+
+func (r OnlineExportRunner) Resource() metadata.ResourceID {
+	return metadata.ResourceID{Name: r.Feature, Variant: r.Variant, Type: metadata.FEATURE_VARIANT}
+}
+
+func (r OnlineExportRunner) IsUpdateJob() bool {
+	return false
+}
+
+type OnlineExportRunnerConfig struct {
+	StoreType       pt.Type
+	StoreConfig     pc.SerializedConfig
+	FileStoreType   pc.FileStoreType
+	FileStoreConfig provider.Config
+	Feature         string
+	Variant         string
+	// Key is the path of the exported file in the file store.
+	Key    string
+	Format provider.FileType
+}
+
+func (r *OnlineExportRunnerConfig) Serialize() (Config, error) {
+	config, err := json.Marshal(r)
+	if err != nil {
+		return nil, fmt.Errorf("could not marshal online export config: %w", err)
+	}
+	return config, nil
+}
+
+func (r *OnlineExportRunnerConfig) Deserialize(config Config) error {
+	err := json.Unmarshal(config, r)
+	if err != nil {
+		return fmt.Errorf("could not unmarshal online export config: %w", err)
+	}
+	return nil
+}
+
+func OnlineExportRunnerFactory(config Config) (types.Runner, error) {
+	exportConfig := &OnlineExportRunnerConfig{}
+	if err := exportConfig.Deserialize(config); err != nil {
+		return nil, fmt.Errorf("failed to deserialize online export config: %w", err)
+	}
+	store, err := getOnlineStore(exportConfig.StoreType, exportConfig.StoreConfig)
+	if err != nil {
+		return nil, err
+	}
+	files, err := provider.CreateFileStore(string(exportConfig.FileStoreType), exportConfig.FileStoreConfig)
+	if err != nil {
+		store.Close()
+		return nil, fmt.Errorf("failed to create file store: %w", err)
+	}
+	return &OnlineExportRunner{
+		Store:   store,
+		Files:   files,
+		Feature: exportConfig.Feature,
+		Variant: exportConfig.Variant,
+		Key:     exportConfig.Key,
+		Format:  exportConfig.Format,
+		Logger:  logging.NewLogger("online-export"),
+	}, nil
+}
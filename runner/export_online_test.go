@@ -0,0 +1,76 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package runner
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"go.uber.org/zap/zaptest"
+
+	"github.com/featureform/provider"
+	pc "github.com/featureform/provider/provider_config"
+)
+
+func TestOnlineExportRunner(t *testing.T) {
+	store := provider.NewLocalOnlineStore()
+	table, err := store.CreateTable("feature", "variant", provider.String)
+	if err != nil {
+		t.Fatalf("Failed to create table: %s", err)
+	}
+	if err := table.Set("entity", "value"); err != nil {
+		t.Fatalf("Failed to set value: %s", err)
+	}
+	fileConfig := pc.LocalFileStoreConfig{DirPath: fmt.Sprintf("file:///%s", t.TempDir())}
+	serialized, err := fileConfig.Serialize()
+	if err != nil {
+		t.Fatalf("Failed to serialize file store config: %s", err)
+	}
+	files, err := provider.NewLocalFileStore(serialized)
+	if err != nil {
+		t.Fatalf("Failed to create file store: %s", err)
+	}
+	export := OnlineExportRunner{
+		Store:   store,
+		Files:   files,
+		Feature: "feature",
+		Variant: "variant",
+		Key:     "exports/feature.csv",
+		Format:  provider.CSV,
+		Logger:  zaptest.NewLogger(t).Sugar(),
+	}
+	watcher, err := export.Run()
+	if err != nil {
+		t.Fatalf("Failed to start export: %s", err)
+	}
+	if err := watcher.Wait(); err != nil {
+		t.Fatalf("Export failed: %s", err)
+	}
+	// The runner closes the file store once it's done.
+	files, err = provider.NewLocalFileStore(serialized)
+	if err != nil {
+		t.Fatalf("Failed to reopen file store: %s", err)
+	}
+	defer files.Close()
+	data, err := files.Read("exports/feature.csv")
+	if err != nil {
+		t.Fatalf("Failed to read export: %s", err)
+	}
+	if !strings.Contains(string(data), "entity,value,") {
+		t.Fatalf("Expected exported value, got %q", data)
+	}
+}
+
+func TestOnlineExportRunnerFactoryUnknownFileStore(t *testing.T) {
+	config := &OnlineExportRunnerConfig{StoreType: "LOCAL_ONLINE", FileStoreType: "UNKNOWN"}
+	serialized, err := config.Serialize()
+	if err != nil {
+		t.Fatalf("Failed to serialize config: %s", err)
+	}
+	if _, err := OnlineExportRunnerFactory(serialized); err == nil {
+		t.Fatalf("Expected factory with unknown file store to fail")
+	}
+}
@@ -0,0 +1,258 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package runner
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/featureform/logging"
+	"github.com/featureform/metadata"
+	"github.com/featureform/provider"
+	"github.com/featureform/types"
+
+	pc "github.com/featureform/provider/provider_config"
+	pt "github.com/featureform/provider/provider_type"
+)
+
+// defaultFlinkPollInterval is how often the Flink job is checked on while
+// its results are applied.
+const defaultFlinkPollInterval = 30 * time.Second
+
+// FlinkRunner continuously computes a feature with a Flink job. It deploys
+// the feature's job, unless it's already running, then writes each of the
+// job's window results to the feature's online table until the job stops.
+// Its watcher completes when the job is cancelled or finishes, and fails if
+// the job fails, so the runner can be restarted to deploy it again.
+type FlinkRunner struct {
+	Online  provider.OnlineStore
+	Flink   provider.FlinkJobClient
+	Results provider.ChangeStream
+	Feature string
+	Variant string
+	Window  provider.SlidingWindowFeature
+	// PollInterval is how often the job is checked on.
+	PollInterval time.Duration
+	// Keys normalizes entity keys before they're written. It's nil if the
+	// entity has no key rules.
+	Keys   *metadata.EntityKeyNormalizer
+	Logger *zap.SugaredLogger
+}
+
+// JobName is the name of the feature's Flink job, which the runner finds it
+// by.
+func (r FlinkRunner) JobName() string {
+	return fmt.Sprintf("featureform-%s-%s", r.Feature, r.Variant)
+}
+
+func (r FlinkRunner) Run() (types.CompletionWatcher, error) {
+	table, err := r.onlineTable()
+	if err != nil {
+		r.Online.Close()
+		r.Results.Close()
+		return nil, err
+	}
+	jobID, err := r.deploy()
+	if err != nil {
+		r.Online.Close()
+		r.Results.Close()
+		return nil, err
+	}
+	done := make(chan interface{})
+	flinkWatcher := &SyncWatcher{
+		ResultSync:  &ResultSync{},
+		DoneChannel: done,
+	}
+	go func() {
+		defer r.Online.Close()
+		defer r.Results.Close()
+		flinkWatcher.EndWatch(r.apply(table, jobID))
+	}()
+	return flinkWatcher, nil
+}
+
+func (r FlinkRunner) onlineTable() (provider.OnlineStoreTable, error) {
+	table, err := r.Online.GetTable(r.Feature, r.Variant)
+	var notFound *provider.TableNotFound
+	if errors.As(err, &notFound) {
+		table, err = r.Online.CreateTable(r.Feature, r.Variant, r.Window.ValueType())
+	}
+	if err != nil {
+		return nil, fmt.Errorf("could not get online table %s %s: %w", r.Feature, r.Variant, err)
+	}
+	return table, nil
+}
+
+// deploy returns the id of the feature's running job, submitting it if
+// there isn't one.
+func (r FlinkRunner) deploy() (string, error) {
+	job, found, err := r.Flink.FindJob(r.JobName())
+	if err != nil {
+		return "", fmt.Errorf("could not find flink job: %w", err)
+	}
+	if found && !job.State.Terminal() {
+		r.Logger.Infow("Flink job is already running", "job", job.ID, "state", job.State)
+		return job.ID, nil
+	}
+	statements, err := r.Window.Statements(r.JobName())
+	if err != nil {
+		return "", err
+	}
+	jobID, err := r.Flink.SubmitSQL(statements)
+	if err != nil {
+		return "", fmt.Errorf("could not submit flink job: %w", err)
+	}
+	r.Logger.Infow("Submitted flink job", "name", r.Feature, "variant", r.Variant, "job", jobID)
+	return jobID, nil
+}
+
+func (r FlinkRunner) apply(table provider.OnlineStoreTable, jobID string) error {
+	pollInterval := r.PollInterval
+	if pollInterval <= 0 {
+		pollInterval = defaultFlinkPollInterval
+	}
+	valueType := r.Window.ValueType()
+	pending := make([]provider.ChangeMessage, 0, cdcCommitBatch)
+	nextPoll := time.Now().Add(pollInterval)
+	for {
+		ctx, cancel := context.WithDeadline(context.Background(), nextPoll)
+		message, err := r.Results.Next(ctx)
+		cancel()
+		if err != nil && !errors.Is(err, context.DeadlineExceeded) {
+			return fmt.Errorf("could not read window result: %w", err)
+		}
+		if err == nil {
+			if err := r.applyResult(table, message, valueType); err != nil {
+				return err
+			}
+			pending = append(pending, message)
+		}
+		if len(pending) == cdcCommitBatch || (len(pending) > 0 && err != nil) {
+			if err := r.Results.Commit(context.Background(), pending); err != nil {
+				return fmt.Errorf("could not commit window results: %w", err)
+			}
+			pending = pending[:0]
+		}
+		if time.Now().Before(nextPoll) {
+			continue
+		}
+		nextPoll = time.Now().Add(pollInterval)
+		job, err := r.Flink.Job(jobID)
+		if err != nil {
+			return fmt.Errorf("could not get flink job %s: %w", jobID, err)
+		}
+		if !job.State.Terminal() {
+			continue
+		}
+		if err := r.Results.Commit(context.Background(), pending); err != nil {
+			return fmt.Errorf("could not commit window results: %w", err)
+		}
+		if job.State == provider.FlinkJobFailed {
+			return fmt.Errorf("flink job %s failed: %s", jobID, job.Error)
+		}
+		r.Logger.Infow("Flink job stopped", "name", r.Feature, "variant", r.Variant, "job", jobID, "state", job.State)
+		return nil
+	}
+}
+
+func (r FlinkRunner) applyResult(table provider.OnlineStoreTable, message provider.ChangeMessage, valueType provider.ValueType) error {
+	result, ok, err := provider.ParseFlinkWindowResult(message.Value, valueType)
+	if err != nil || !ok {
+		return err
+	}
+	entity, err := r.Keys.Normalize(result.Entity)
+	if err != nil {
+		return fmt.Errorf("could not normalize entity key: %w", err)
+	}
+	if err := table.Set(entity, result.Value); err != nil {
+		return fmt.Errorf("could not set value of %s: %w", entity, err)
+	}
+	return nil
+}
+
+func (r FlinkRunner) Resource() metadata.ResourceID {
+	return metadata.ResourceID{Name: r.Feature, Variant: r.Variant, Type: metadata.FEATURE_VARIANT}
+}
+
+func (r FlinkRunner) IsUpdateJob() bool {
+	return false
+}
+
+type FlinkRunnerConfig struct {
+	OnlineType   pt.Type
+	OnlineConfig pc.SerializedConfig
+	Flink        pc.FlinkConfig
+	ResourceID   provider.ResourceID
+	Window       provider.SlidingWindowFeature
+	PollInterval time.Duration
+	Entity       string
+	KeyRules     metadata.EntityKeyRules
+}
+
+func (c *FlinkRunnerConfig) Serialize() (Config, error) {
+	config, err := json.Marshal(c)
+	if err != nil {
+		return nil, fmt.Errorf("could not marshal flink config: %w", err)
+	}
+	return config, nil
+}
+
+func (c *FlinkRunnerConfig) Deserialize(config Config) error {
+	err := json.Unmarshal(config, c)
+	if err != nil {
+		return fmt.Errorf("could not unmarshal flink config: %w", err)
+	}
+	return nil
+}
+
+func FlinkRunnerFactory(config Config) (types.Runner, error) {
+	runnerConfig := &FlinkRunnerConfig{}
+	if err := runnerConfig.Deserialize(config); err != nil {
+		return nil, fmt.Errorf("failed to deserialize flink runner config: %w", err)
+	}
+	if err := runnerConfig.Window.Validate(); err != nil {
+		return nil, err
+	}
+	var keys *metadata.EntityKeyNormalizer
+	if !runnerConfig.KeyRules.IsZero() {
+		var err error
+		if keys, err = metadata.NewEntityKeyNormalizer(runnerConfig.Entity, runnerConfig.KeyRules); err != nil {
+			return nil, fmt.Errorf("invalid entity key rules: %w", err)
+		}
+	}
+	flink, err := provider.NewFlinkClient(runnerConfig.Flink)
+	if err != nil {
+		return nil, err
+	}
+	onlineProvider, err := provider.Get(runnerConfig.OnlineType, runnerConfig.OnlineConfig)
+	if err != nil {
+		return nil, fmt.Errorf("failed to configure online provider: %w", err)
+	}
+	onlineStore, err := onlineProvider.AsOnlineStore()
+	if err != nil {
+		return nil, fmt.Errorf("failed to convert provider to online store: %w", err)
+	}
+	results, err := provider.NewKafkaChangeStream(runnerConfig.Window.Results)
+	if err != nil {
+		onlineStore.Close()
+		return nil, fmt.Errorf("failed to read window results: %w", err)
+	}
+	return &FlinkRunner{
+		Online:       onlineStore,
+		Flink:        flink,
+		Results:      results,
+		Feature:      runnerConfig.ResourceID.Name,
+		Variant:      runnerConfig.ResourceID.Variant,
+		Window:       runnerConfig.Window,
+		PollInterval: runnerConfig.PollInterval,
+		Keys:         keys,
+		Logger:       logging.NewLogger("flink"),
+	}, nil
+}
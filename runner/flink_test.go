@@ -0,0 +1,156 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package runner
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"go.uber.org/zap/zaptest"
+
+	"github.com/featureform/provider"
+	pc "github.com/featureform/provider/provider_config"
+)
+
+type mockFlinkClient struct {
+	running   *provider.FlinkJob
+	submitted [][]string
+	// states are the states of the submitted job each time it's checked on.
+	states []provider.FlinkJobState
+}
+
+func (c *mockFlinkClient) SubmitSQL(statements []string) (string, error) {
+	c.submitted = append(c.submitted, statements)
+	return "submitted", nil
+}
+
+func (c *mockFlinkClient) FindJob(name string) (provider.FlinkJob, bool, error) {
+	if c.running == nil {
+		return provider.FlinkJob{}, false, nil
+	}
+	return *c.running, true, nil
+}
+
+func (c *mockFlinkClient) Job(id string) (provider.FlinkJob, error) {
+	state := c.states[0]
+	if len(c.states) > 1 {
+		c.states = c.states[1:]
+	}
+	job := provider.FlinkJob{ID: id, State: state}
+	if state == provider.FlinkJobFailed {
+		job.Error = "out of memory"
+	}
+	return job, nil
+}
+
+func (c *mockFlinkClient) CancelJob(id string) error {
+	return nil
+}
+
+func testFlinkWindow() provider.SlidingWindowFeature {
+	return provider.SlidingWindowFeature{
+		Source:      pc.KafkaConfig{Brokers: []string{"broker:9092"}, Topic: "transactions", GroupID: "source"},
+		Entity:      "user",
+		Value:       "amount",
+		Timestamp:   "ts",
+		Aggregation: provider.FlinkSum,
+		Size:        time.Hour,
+		Slide:       time.Minute,
+		Results:     pc.KafkaConfig{Brokers: []string{"broker:9092"}, Topic: "results", GroupID: "results"},
+	}
+}
+
+func runFlink(t *testing.T, flink *mockFlinkClient, table provider.OnlineStoreTable, stream *mockChangeStream) error {
+	runner := FlinkRunner{
+		Online:       cdcOnlineStore{MockOnlineStore: *NewMockOnlineStore(), table: table},
+		Flink:        flink,
+		Results:      stream,
+		Feature:      "spend",
+		Variant:      "v1",
+		Window:       testFlinkWindow(),
+		PollInterval: 10 * time.Millisecond,
+		Logger:       zaptest.NewLogger(t).Sugar(),
+	}
+	watcher, err := runner.Run()
+	if err != nil {
+		t.Fatalf("Failed to start flink runner: %s", err)
+	}
+	return watcher.Wait()
+}
+
+func TestFlinkRunnerAppliesResults(t *testing.T) {
+	flink := &mockFlinkClient{states: []provider.FlinkJobState{provider.FlinkJobRunning, provider.FlinkJobCanceled}}
+	table := &MockOnlineTable{DataTable: map[string]interface{}{}}
+	stream := &mockChangeStream{messages: changeMessages(
+		`{"entity": "a", "value": 10.5, "window_end": "2023-06-01T00:01:00"}`,
+		`{"entity": "b", "value": 3, "window_end": "2023-06-01T00:01:00"}`,
+		`{"entity": "a", "value": 12, "window_end": "2023-06-01T00:02:00"}`,
+	)}
+	if err := runFlink(t, flink, table, stream); err != nil {
+		t.Fatalf("Flink runner failed: %s", err)
+	}
+	if len(flink.submitted) != 1 || !strings.Contains(flink.submitted[0][0], "featureform-spend-v1") {
+		t.Fatalf("Expected the job to be submitted once with its name, got %v", flink.submitted)
+	}
+	expected := map[string]interface{}{"a": 12.0, "b": 3.0}
+	if !reflect.DeepEqual(table.DataTable, expected) {
+		t.Fatalf("Expected %v, got %v", expected, table.DataTable)
+	}
+	if len(stream.committed) != 3 {
+		t.Fatalf("Expected 3 committed results, got %d", len(stream.committed))
+	}
+}
+
+func TestFlinkRunnerReusesRunningJob(t *testing.T) {
+	flink := &mockFlinkClient{
+		running: &provider.FlinkJob{ID: "running", State: provider.FlinkJobRunning},
+		states:  []provider.FlinkJobState{provider.FlinkJobFinished},
+	}
+	table := &MockOnlineTable{DataTable: map[string]interface{}{}}
+	if err := runFlink(t, flink, table, &mockChangeStream{}); err != nil {
+		t.Fatalf("Flink runner failed: %s", err)
+	}
+	if len(flink.submitted) != 0 {
+		t.Fatalf("Expected the running job to be reused, got %v", flink.submitted)
+	}
+}
+
+func TestFlinkRunnerJobFailure(t *testing.T) {
+	flink := &mockFlinkClient{
+		running: &provider.FlinkJob{ID: "old", State: provider.FlinkJobFailed},
+		states:  []provider.FlinkJobState{provider.FlinkJobFailed},
+	}
+	table := &MockOnlineTable{DataTable: map[string]interface{}{}}
+	err := runFlink(t, flink, table, &mockChangeStream{})
+	if err == nil || !strings.Contains(err.Error(), "out of memory") {
+		t.Fatalf("Expected the job's failure, got %v", err)
+	}
+	if len(flink.submitted) != 1 {
+		t.Fatalf("Expected a failed job to be submitted again, got %v", flink.submitted)
+	}
+}
+
+func TestFlinkRunnerConfigRoundTrip(t *testing.T) {
+	config := &FlinkRunnerConfig{
+		OnlineType:   "REDIS_ONLINE",
+		Flink:        pc.FlinkConfig{GatewayURL: "http://gateway:8083", RESTURL: "http://jobmanager:8081"},
+		ResourceID:   provider.ResourceID{Name: "spend", Variant: "v1", Type: provider.Feature},
+		Window:       testFlinkWindow(),
+		PollInterval: time.Minute,
+	}
+	serialized, err := config.Serialize()
+	if err != nil {
+		t.Fatalf("Failed to serialize config: %s", err)
+	}
+	deserialized := &FlinkRunnerConfig{}
+	if err := deserialized.Deserialize(serialized); err != nil {
+		t.Fatalf("Failed to deserialize config: %s", err)
+	}
+	if !reflect.DeepEqual(config, deserialized) {
+		t.Fatalf("Expected %v, got %v", config, deserialized)
+	}
+}
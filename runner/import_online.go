@@ -0,0 +1,138 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package runner
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"go.uber.org/zap"
+
+	"github.com/featureform/logging"
+	"github.com/featureform/metadata"
+	"github.com/featureform/provider"
+	"github.com/featureform/types"
+
+	pc "github.com/featureform/provider/provider_config"
+	pt "github.com/featureform/provider/provider_type"
+)
+
+// OnlineImportRunner writes the values in a file in a file store to an online
+// table, creating the table if it doesn't exist. Both stores are closed once
+// it's done.
+type OnlineImportRunner struct {
+	Store     provider.OnlineStore
+	Files     provider.FileStore
+	Feature   string
+	Variant   string
+	Key       string
+	Format    provider.FileType
+	VType     provider.ValueType
+	BatchSize int
+	Logger    *zap.SugaredLogger
+}
+
+func (r OnlineImportRunner) Run() (types.CompletionWatcher, error) {
+	done := make(chan interface{})
+	importWatcher := &SyncWatcher{
+		ResultSync:  &ResultSync{},
+		DoneChannel: done,
+	}
+	go func() {
+		defer r.Store.Close()
+		defer r.Files.Close()
+		table, err := r.getOrCreateTable()
+		if err != nil {
+			importWatcher.EndWatch(err)
+			return
+		}
+		options := provider.OnlineImportOptions{Format: r.Format, ValueType: r.VType, BatchSize: r.BatchSize}
+		written, err := provider.ImportOnlineTable(table, r.Files, r.Key, options)
+		if err != nil {
+			importWatcher.EndWatch(fmt.Errorf("could not import %s to table %s %s: %w", r.Key, r.Feature, r.Variant, err))
+			return
+		}
+		r.Logger.Infow("Online table import complete",
+			"name", r.Feature, "variant", r.Variant, "key", r.Key, "entities", written,
+		)
+		importWatcher.EndWatch(nil)
+	}()
+	return importWatcher, nil
+}
+
+func (r OnlineImportRunner) getOrCreateTable() (provider.OnlineStoreTable, error) {
+	table, err := r.Store.CreateTable(r.Feature, r.Variant, r.VType)
+	if _, exists := err.(*provider.TableAlreadyExists); exists {
+		return r.Store.GetTable(r.Feature, r.Variant)
+	} else if err != nil {
+		return nil, fmt.Errorf("create table error: %w", err)
+	}
+	return table, nil
+}
+
+func (r OnlineImportRunner) Resource() metadata.ResourceID {
+	return metadata.ResourceID{Name: r.Feature, Variant: r.Variant, Type: metadata.FEATURE_VARIANT}
+}
+
+func (r OnlineImportRunner) IsUpdateJob() bool {
+	return false
+}
+
+type OnlineImportRunnerConfig struct {
+	StoreType       pt.Type
+	StoreConfig     pc.SerializedConfig
+	FileStoreType   pc.FileStoreType
+	FileStoreConfig provider.Config
+	Feature         string
+	Variant         string
+	// Key is the path of the imported file in the file store.
+	Key       string
+	Format    provider.FileType
+	VType     provider.ValueTypeJSONWrapper
+	BatchSize int
+}
+
+func (r *OnlineImportRunnerConfig) Serialize() (Config, error) {
+	config, err := json.Marshal(r)
+	if err != nil {
+		return nil, fmt.Errorf("could not marshal online import config: %w", err)
+	}
+	return config, nil
+}
+
+func (r *OnlineImportRunnerConfig) Deserialize(config Config) error {
+	err := json.Unmarshal(config, r)
+	if err != nil {
+		return fmt.Errorf("could not unmarshal online import config: %w", err)
+	}
+	return nil
+}
+
+func OnlineImportRunnerFactory(config Config) (types.Runner, error) {
+	importConfig := &OnlineImportRunnerConfig{}
+	if err := importConfig.Deserialize(config); err != nil {
+		return nil, fmt.Errorf("failed to deserialize online import config: %w", err)
+	}
+	store, err := getOnlineStore(importConfig.StoreType, importConfig.StoreConfig)
+	if err != nil {
+		return nil, err
+	}
+	files, err := provider.CreateFileStore(string(importConfig.FileStoreType), importConfig.FileStoreConfig)
+	if err != nil {
+		store.Close()
+		return nil, fmt.Errorf("failed to create file store: %w", err)
+	}
+	return &OnlineImportRunner{
+		Store:     store,
+		Files:     files,
+		Feature:   importConfig.Feature,
+		Variant:   importConfig.Variant,
+		Key:       importConfig.Key,
+		Format:    importConfig.Format,
+		VType:     importConfig.VType.ValueType,
+		BatchSize: importConfig.BatchSize,
+		Logger:    logging.NewLogger("online-import"),
+	}, nil
+}
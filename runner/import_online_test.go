@@ -0,0 +1,84 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package runner
+
+import (
+	"fmt"
+	"testing"
+
+	"go.uber.org/zap/zaptest"
+
+	"github.com/featureform/provider"
+	pc "github.com/featureform/provider/provider_config"
+)
+
+func TestOnlineImportRunner(t *testing.T) {
+	fileConfig := pc.LocalFileStoreConfig{DirPath: fmt.Sprintf("file:///%s", t.TempDir())}
+	serialized, err := fileConfig.Serialize()
+	if err != nil {
+		t.Fatalf("Failed to serialize file store config: %s", err)
+	}
+	files, err := provider.NewLocalFileStore(serialized)
+	if err != nil {
+		t.Fatalf("Failed to create file store: %s", err)
+	}
+	if err := files.Write("imports/feature.csv", []byte("entity,value\na,1\nb,2\n")); err != nil {
+		t.Fatalf("Failed to write file: %s", err)
+	}
+	store := provider.NewLocalOnlineStore()
+	runner := OnlineImportRunner{
+		Store:   store,
+		Files:   files,
+		Feature: "feature",
+		Variant: "variant",
+		Key:     "imports/feature.csv",
+		Format:  provider.CSV,
+		VType:   provider.Int,
+		Logger:  zaptest.NewLogger(t).Sugar(),
+	}
+	watcher, err := runner.Run()
+	if err != nil {
+		t.Fatalf("Failed to start import: %s", err)
+	}
+	if err := watcher.Wait(); err != nil {
+		t.Fatalf("Import failed: %s", err)
+	}
+	table, err := store.GetTable("feature", "variant")
+	if err != nil {
+		t.Fatalf("Failed to get imported table: %s", err)
+	}
+	for entity, expected := range map[string]int{"a": 1, "b": 2} {
+		value, err := table.Get(entity)
+		if err != nil {
+			t.Fatalf("Failed to get %s: %s", entity, err)
+		}
+		if value != expected {
+			t.Fatalf("Expected %d for %s, got %v", expected, entity, value)
+		}
+	}
+}
+
+func TestOnlineImportRunnerConfigRoundTrip(t *testing.T) {
+	config := &OnlineImportRunnerConfig{
+		StoreType: "LOCAL_ONLINE",
+		Feature:   "feature",
+		Variant:   "variant",
+		Key:       "imports/feature.parquet",
+		Format:    provider.Parquet,
+		VType:     provider.ValueTypeJSONWrapper{ValueType: provider.Float32},
+		BatchSize: 10,
+	}
+	serialized, err := config.Serialize()
+	if err != nil {
+		t.Fatalf("Failed to serialize config: %s", err)
+	}
+	deserialized := &OnlineImportRunnerConfig{}
+	if err := deserialized.Deserialize(serialized); err != nil {
+		t.Fatalf("Failed to deserialize config: %s", err)
+	}
+	if deserialized.VType.ValueType != provider.Float32 || deserialized.Format != provider.Parquet || deserialized.BatchSize != 10 {
+		t.Fatalf("Config changed in round trip: %+v", deserialized)
+	}
+}
@@ -6,6 +6,8 @@ package runner
 
 import (
 	"fmt"
+	"sort"
+	"sync"
 
 	"github.com/featureform/types"
 )
@@ -13,11 +15,23 @@ import (
 type RunnerName string
 
 const (
-	COPY_TO_ONLINE        RunnerName = "Copy to online"
-	CREATE_TRAINING_SET              = "Create training set"
-	REGISTER_SOURCE                  = "Register source"
-	CREATE_TRANSFORMATION            = "Create transformation"
-	MATERIALIZE                      = "Materialize"
+	COPY_TO_ONLINE           RunnerName = "Copy to online"
+	CREATE_TRAINING_SET                 = "Create training set"
+	REGISTER_SOURCE                     = "Register source"
+	CREATE_TRANSFORMATION               = "Create transformation"
+	MATERIALIZE                         = "Materialize"
+	MAINTAIN_OFFLINE                    = "Maintain offline"
+	MIGRATE_ONLINE                      = "Migrate online"
+	REBALANCE_SHARDS                    = "Rebalance shards"
+	EXPORT_ONLINE                       = "Export online"
+	IMPORT_ONLINE                       = "Import online"
+	REBUILD_INDEX                       = "Rebuild index"
+	CDC_TO_ONLINE                       = "CDC to online"
+	FLINK_TO_ONLINE                     = "Flink to online"
+	VACUUM_OFFLINE                      = "Vacuum offline"
+	DELETE_FROM_ONLINE                  = "Delete from online"
+	BACKFILL                            = "Backfill"
+	VALIDATE_MATERIALIZATION            = "Validate materialization"
 )
 
 type Config []byte
@@ -27,15 +41,67 @@ type RunnerConfig interface {
 	Deserialize(config Config) error
 }
 
+// RunnerFactory creates a runner from its serialized config. Custom runners
+// are added by registering a factory under a name of their own, and then run
+// by creating them by that name. The worker image and the coordinator's
+// in-process spawner both create runners with Create, so a custom runner runs
+// in any coordinator or worker binary that registers it, typically from an
+// init function alongside RegisterBuiltinFactories.
 type RunnerFactory func(config Config) (types.Runner, error)
 
-var factoryMap = make(map[string]RunnerFactory)
+var (
+	factoryMap = make(map[string]RunnerFactory)
+	factoryMtx sync.RWMutex
+)
+
+// builtinFactories are the runners Featureform ships, by name.
+var builtinFactories = map[string]RunnerFactory{
+	string(COPY_TO_ONLINE):   MaterializedChunkRunnerFactory,
+	CREATE_TRAINING_SET:      TrainingSetRunnerFactory,
+	CREATE_TRANSFORMATION:    CreateTransformationRunnerFactory,
+	MATERIALIZE:              MaterializeRunnerFactory,
+	MAINTAIN_OFFLINE:         MaintenanceRunnerFactory,
+	MIGRATE_ONLINE:           OnlineMigrationRunnerFactory,
+	REBALANCE_SHARDS:         ShardRebalanceRunnerFactory,
+	EXPORT_ONLINE:            OnlineExportRunnerFactory,
+	IMPORT_ONLINE:            OnlineImportRunnerFactory,
+	REBUILD_INDEX:            IndexRebuildRunnerFactory,
+	CDC_TO_ONLINE:            CDCRunnerFactory,
+	FLINK_TO_ONLINE:          FlinkRunnerFactory,
+	VACUUM_OFFLINE:           VacuumRunnerFactory,
+	DELETE_FROM_ONLINE:       OnlineDeletionRunnerFactory,
+	BACKFILL:                 BackfillRunnerFactory,
+	VALIDATE_MATERIALIZATION: ValidationRunnerFactory,
+}
+
+// RegisterBuiltinFactories registers every runner Featureform ships. It fails
+// if any of their names is already registered.
+func RegisterBuiltinFactories() error {
+	for name, factory := range builtinFactories {
+		if err := RegisterFactory(name, factory); err != nil {
+			return err
+		}
+	}
+	return nil
+}
 
 func ResetFactoryMap() {
+	factoryMtx.Lock()
+	defer factoryMtx.Unlock()
 	factoryMap = make(map[string]RunnerFactory)
 }
 
+// RegisterFactory registers runnerFactory under name. Names can't be
+// registered twice, so a custom runner can't replace a built-in one.
 func RegisterFactory(name string, runnerFactory RunnerFactory) error {
+	if name == "" {
+		return fmt.Errorf("factory name is empty")
+	}
+	if runnerFactory == nil {
+		return fmt.Errorf("factory %s is nil", name)
+	}
+	factoryMtx.Lock()
+	defer factoryMtx.Unlock()
 	if _, exists := factoryMap[name]; exists {
 		return fmt.Errorf("factory already registered: %s", name)
 	}
@@ -44,6 +110,8 @@ func RegisterFactory(name string, runnerFactory RunnerFactory) error {
 }
 
 func UnregisterFactory(name string) error {
+	factoryMtx.Lock()
+	defer factoryMtx.Unlock()
 	if _, exists := factoryMap[name]; !exists {
 		return fmt.Errorf("factory %s not registered", name)
 	}
@@ -51,8 +119,23 @@ func UnregisterFactory(name string) error {
 	return nil
 }
 
+// RegisteredFactories returns the names of the registered runners, sorted.
+func RegisteredFactories() []string {
+	factoryMtx.RLock()
+	defer factoryMtx.RUnlock()
+	names := make([]string, 0, len(factoryMap))
+	for name := range factoryMap {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Create creates the runner registered under name from its config.
 func Create(name string, config Config) (types.Runner, error) {
+	factoryMtx.RLock()
 	factory, exists := factoryMap[name]
+	factoryMtx.RUnlock()
 	if !exists {
 		return nil, fmt.Errorf("factory does not exist: %s", name)
 	}
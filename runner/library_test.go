@@ -75,3 +75,45 @@ func TestCreateRunnerError(t *testing.T) {
 		t.Fatalf("Failed to record error creating runner")
 	}
 }
+
+func TestRegisterBuiltinFactories(t *testing.T) {
+	saved := factoryMap
+	defer func() { factoryMap = saved }()
+	ResetFactoryMap()
+	if err := RegisterFactory("custom", func(config Config) (types.Runner, error) {
+		return &MockRunner{}, nil
+	}); err != nil {
+		t.Fatalf("Error registering factory: %v", err)
+	}
+	if err := RegisterBuiltinFactories(); err != nil {
+		t.Fatalf("Error registering builtin factories: %v", err)
+	}
+	names := RegisteredFactories()
+	if len(names) != len(builtinFactories)+1 {
+		t.Fatalf("Expected the builtin and custom factories to be registered, got %v", names)
+	}
+	for i := 1; i < len(names); i++ {
+		if names[i-1] >= names[i] {
+			t.Fatalf("Expected sorted names, got %v", names)
+		}
+	}
+	if err := RegisterBuiltinFactories(); err == nil {
+		t.Fatalf("Register builtin factories allowed duplicate registration")
+	}
+	if err := RegisterFactory(string(MATERIALIZE), func(config Config) (types.Runner, error) {
+		return &MockRunner{}, nil
+	}); err == nil {
+		t.Fatalf("Custom factory replaced a builtin one")
+	}
+}
+
+func TestRegisterFactoryInvalid(t *testing.T) {
+	if err := RegisterFactory("", func(config Config) (types.Runner, error) {
+		return &MockRunner{}, nil
+	}); err == nil {
+		t.Fatalf("Registered a factory without a name")
+	}
+	if err := RegisterFactory("nil", nil); err == nil {
+		t.Fatalf("Registered a nil factory")
+	}
+}
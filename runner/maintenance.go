@@ -0,0 +1,130 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package runner
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/featureform/metadata"
+	"github.com/featureform/provider"
+	"github.com/featureform/types"
+
+	pc "github.com/featureform/provider/provider_config"
+	pt "github.com/featureform/provider/provider_type"
+)
+
+// Resource properties used to schedule maintenance of the table backing a
+// transformation or materialized feature. Operations and cluster columns are
+// comma separated; if no operations are set the provider's defaults are used.
+const (
+	MaintenanceScheduleProperty       = "maintenance_schedule"
+	MaintenanceOperationsProperty     = "maintenance_operations"
+	MaintenanceClusterColumnsProperty = "maintenance_cluster_columns"
+)
+
+// MaintenanceFromProperties returns the cron schedule and maintenance config
+// set in a resource's properties. The schedule is empty if no maintenance has
+// been requested.
+func MaintenanceFromProperties(tableID provider.ResourceID, properties metadata.Properties) (string, provider.MaintenanceConfig) {
+	config := provider.MaintenanceConfig{TableID: tableID}
+	for _, op := range splitProperty(properties[MaintenanceOperationsProperty]) {
+		config.Operations = append(config.Operations, provider.MaintenanceOperation(strings.ToUpper(op)))
+	}
+	config.ClusterColumns = splitProperty(properties[MaintenanceClusterColumnsProperty])
+	return strings.TrimSpace(properties[MaintenanceScheduleProperty]), config
+}
+
+func splitProperty(value string) []string {
+	var values []string
+	for _, v := range strings.Split(value, ",") {
+		if v = strings.TrimSpace(v); v != "" {
+			values = append(values, v)
+		}
+	}
+	return values
+}
+
+type MaintenanceRunner struct {
+	Offline provider.OfflineStore
+	Config  provider.MaintenanceConfig
+}
+
+func (m MaintenanceRunner) Run() (types.CompletionWatcher, error) {
+	done := make(chan interface{})
+	maintenanceWatcher := &SyncWatcher{
+		ResultSync:  &ResultSync{},
+		DoneChannel: done,
+	}
+	go func() {
+		store, ok := m.Offline.(provider.MaintainableOfflineStore)
+		if !ok {
+			maintenanceWatcher.EndWatch(&provider.MaintenanceNotSupported{ProviderType: string(m.Offline.Type())})
+			return
+		}
+		if err := store.Maintain(m.Config); err != nil {
+			maintenanceWatcher.EndWatch(err)
+			return
+		}
+		maintenanceWatcher.EndWatch(nil)
+	}()
+	return maintenanceWatcher, nil
+}
+
+func (m MaintenanceRunner) Resource() metadata.ResourceID {
+	return metadata.ResourceID{
+		Name:    m.Config.TableID.Name,
+		Variant: m.Config.TableID.Variant,
+		Type:    provider.ProviderToMetadataResourceType[m.Config.TableID.Type],
+	}
+}
+
+// IsUpdateJob is true as maintenance only runs against existing tables and
+// shouldn't change the status of their resources.
+func (m MaintenanceRunner) IsUpdateJob() bool {
+	return true
+}
+
+type MaintenanceRunnerConfig struct {
+	OfflineType       pt.Type
+	OfflineConfig     pc.SerializedConfig
+	MaintenanceConfig provider.MaintenanceConfig
+}
+
+func (m *MaintenanceRunnerConfig) Serialize() (Config, error) {
+	config, err := json.Marshal(m)
+	if err != nil {
+		return nil, fmt.Errorf("could not marshal maintenance config: %w", err)
+	}
+	return config, nil
+}
+
+func (m *MaintenanceRunnerConfig) Deserialize(config Config) error {
+	err := json.Unmarshal(config, m)
+	if err != nil {
+		return fmt.Errorf("could not unmarshal maintenance config: %w", err)
+	}
+	return nil
+}
+
+func MaintenanceRunnerFactory(config Config) (types.Runner, error) {
+	maintenanceConfig := &MaintenanceRunnerConfig{}
+	if err := maintenanceConfig.Deserialize(config); err != nil {
+		return nil, fmt.Errorf("failed to deserialize maintenance config: %w", err)
+	}
+	offlineProvider, err := provider.Get(maintenanceConfig.OfflineType, maintenanceConfig.OfflineConfig)
+	if err != nil {
+		return nil, fmt.Errorf("failed to configure offline provider: %w", err)
+	}
+	offlineStore, err := offlineProvider.AsOfflineStore()
+	if err != nil {
+		return nil, fmt.Errorf("failed to convert provider to offline store: %w", err)
+	}
+	return &MaintenanceRunner{
+		Offline: offlineStore,
+		Config:  maintenanceConfig.MaintenanceConfig,
+	}, nil
+}
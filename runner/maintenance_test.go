@@ -0,0 +1,91 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package runner
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/featureform/metadata"
+	"github.com/featureform/provider"
+)
+
+type MockMaintainableOfflineStore struct {
+	MockOfflineStore
+	maintained *provider.MaintenanceConfig
+	err        error
+}
+
+func (m MockMaintainableOfflineStore) Maintain(config provider.MaintenanceConfig) error {
+	*m.maintained = config
+	return m.err
+}
+
+func TestMaintenanceRunner(t *testing.T) {
+	config := provider.MaintenanceConfig{
+		TableID:    provider.ResourceID{Name: "name", Variant: "variant", Type: provider.Transformation},
+		Operations: []provider.MaintenanceOperation{provider.VacuumOperation},
+	}
+	var maintained provider.MaintenanceConfig
+	runner := MaintenanceRunner{
+		Offline: MockMaintainableOfflineStore{maintained: &maintained},
+		Config:  config,
+	}
+	watcher, err := runner.Run()
+	if err != nil {
+		t.Fatalf("failed to run maintenance runner: %v", err)
+	}
+	if err := watcher.Wait(); err != nil {
+		t.Fatalf("maintenance runner failed: %v", err)
+	}
+	if !reflect.DeepEqual(maintained, config) {
+		t.Fatalf("expected %v to be maintained, got %v", config, maintained)
+	}
+	expectedID := metadata.ResourceID{Name: "name", Variant: "variant", Type: metadata.SOURCE_VARIANT}
+	if runner.Resource() != expectedID {
+		t.Fatalf("expected resource %v, got %v", expectedID, runner.Resource())
+	}
+}
+
+func TestMaintenanceRunnerFail(t *testing.T) {
+	var maintained provider.MaintenanceConfig
+	runners := []MaintenanceRunner{
+		{Offline: MockMaintainableOfflineStore{maintained: &maintained, err: fmt.Errorf("maintenance failed")}},
+		{Offline: MockOfflineStore{}},
+	}
+	for _, runner := range runners {
+		watcher, err := runner.Run()
+		if err != nil {
+			t.Fatalf("failed to run maintenance runner: %v", err)
+		}
+		if err := watcher.Wait(); err == nil {
+			t.Fatalf("failed to report maintenance error for %T", runner.Offline)
+		}
+	}
+}
+
+func TestMaintenanceFromProperties(t *testing.T) {
+	tableID := provider.ResourceID{Name: "name", Variant: "variant", Type: provider.FeatureMaterialization}
+	schedule, config := MaintenanceFromProperties(tableID, metadata.Properties{
+		MaintenanceScheduleProperty:       " 0 3 * * * ",
+		MaintenanceOperationsProperty:     "vacuum, ANALYZE,",
+		MaintenanceClusterColumnsProperty: "entity,ts",
+	})
+	if schedule != "0 3 * * *" {
+		t.Fatalf("unexpected schedule %q", schedule)
+	}
+	expected := provider.MaintenanceConfig{
+		TableID:        tableID,
+		Operations:     []provider.MaintenanceOperation{provider.VacuumOperation, provider.AnalyzeOperation},
+		ClusterColumns: []string{"entity", "ts"},
+	}
+	if !reflect.DeepEqual(config, expected) {
+		t.Fatalf("expected %v, got %v", expected, config)
+	}
+	if schedule, _ := MaintenanceFromProperties(tableID, metadata.Properties{}); schedule != "" {
+		t.Fatalf("expected no schedule, got %q", schedule)
+	}
+}
@@ -5,9 +5,17 @@
 package runner
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"runtime"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
 
+	"github.com/google/uuid"
 	"go.uber.org/zap"
 
 	cfg "github.com/featureform/config"
@@ -21,8 +29,6 @@ import (
 	"github.com/featureform/types"
 )
 
-const MAXIMUM_CHUNK_ROWS int64 = 16777216
-
 var WORKER_IMAGE string = helpers.GetEnv("WORKER_IMAGE", "featureformcom/worker:latest")
 
 type JobCloud string
@@ -32,14 +38,123 @@ const (
 	LocalMaterializeRunner      JobCloud = "LOCAL"
 )
 
+// IncrementalMaterializationProperty is the feature property that updates
+// its materialization incrementally, from only the rows that are newer than
+// the latest timestamp it has materialized.
+const IncrementalMaterializationProperty = "incremental_materialization"
+
+// IncrementalFromProperties is true if a feature's properties request
+// incremental materialization.
+func IncrementalFromProperties(properties metadata.Properties) bool {
+	incremental, err := strconv.ParseBool(strings.TrimSpace(properties[IncrementalMaterializationProperty]))
+	return err == nil && incremental
+}
+
+// Feature properties that choose how its materialization resolves multiple
+// rows per entity. DedupStrategyProperty is one of latest, max, min or
+// expression, and defaults to latest. DedupExpressionProperty is the SQL
+// aggregate of the value column that the expression strategy uses.
+const (
+	DedupStrategyProperty   = "dedup_strategy"
+	DedupExpressionProperty = "dedup_expression"
+)
+
+// DedupFromProperties reads and validates a feature's dedup strategy.
+func DedupFromProperties(properties metadata.Properties) (provider.DedupStrategy, error) {
+	strategy := provider.DedupStrategy{
+		Type:       provider.DedupStrategyType(strings.ToLower(strings.TrimSpace(properties[DedupStrategyProperty]))),
+		Expression: strings.TrimSpace(properties[DedupExpressionProperty]),
+	}
+	if err := strategy.Validate(); err != nil {
+		return provider.DedupStrategy{}, fmt.Errorf("invalid %s: %w", DedupStrategyProperty, err)
+	}
+	return strategy, nil
+}
+
+// AdditionalOnlineProvidersProperty is the feature property that lists, by
+// name and separated by commas, online providers that its materialization is
+// written to as well as its own, like the same store in other regions.
+const AdditionalOnlineProvidersProperty = "additional_online_providers"
+
+// AdditionalOnlineFromProperties returns the names of the additional online
+// providers in a feature's properties.
+func AdditionalOnlineFromProperties(properties metadata.Properties) []string {
+	names := make([]string, 0)
+	for _, name := range strings.Split(properties[AdditionalOnlineProvidersProperty], ",") {
+		if name = strings.TrimSpace(name); name != "" {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// OnlineTarget is an online store that a materialization is written to.
+type OnlineTarget struct {
+	Type   pt.Type
+	Config pc.SerializedConfig
+}
+
+func (t OnlineTarget) store() (provider.OnlineStore, error) {
+	onlineProvider, err := provider.Get(t.Type, t.Config)
+	if err != nil {
+		return nil, fmt.Errorf("failed to configure online provider: %v", err)
+	}
+	onlineStore, err := onlineProvider.AsOnlineStore()
+	if err != nil {
+		return nil, fmt.Errorf("failed to convert provider to online store: %v", err)
+	}
+	return onlineStore, nil
+}
+
+func onlineStores(targets []OnlineTarget) ([]provider.OnlineStore, error) {
+	stores := make([]provider.OnlineStore, len(targets))
+	for i, target := range targets {
+		store, err := target.store()
+		if err != nil {
+			return nil, err
+		}
+		stores[i] = store
+	}
+	return stores, nil
+}
+
+func onlineTargets(stores []provider.OnlineStore) []OnlineTarget {
+	targets := make([]OnlineTarget, len(stores))
+	for i, store := range stores {
+		targets[i] = OnlineTarget{Type: store.Type(), Config: store.Config()}
+	}
+	return targets
+}
+
 type MaterializeRunner struct {
-	Online   provider.OnlineStore
-	Offline  provider.OfflineStore
-	ID       provider.ResourceID
-	VType    provider.ValueType
-	IsUpdate bool
-	Cloud    JobCloud
-	Logger   *zap.SugaredLogger
+	Online provider.OnlineStore
+	// AdditionalOnline are other online stores that the materialization is
+	// written to, from the same read of the offline store as Online.
+	AdditionalOnline []provider.OnlineStore
+	Offline          provider.OfflineStore
+	ID               provider.ResourceID
+	VType            provider.ValueType
+	IsUpdate         bool
+	// Incremental updates copy only the entities whose values changed, if
+	// the offline store supports it.
+	Incremental bool
+	// Dedup is how the materialization resolves multiple rows per entity.
+	// Only the latest row's can be updated incrementally.
+	Dedup provider.DedupStrategy
+	Cloud JobCloud
+	// Entity and KeyRules are the feature's entity and its key rules, which
+	// are applied to every entity key before it's written.
+	Entity   string
+	KeyRules metadata.EntityKeyRules
+	// DryRun plans the materialization and reports the plan, without writing
+	// to or creating tables in the online stores.
+	DryRun bool
+	// Expectations are checked against the materialization before anything
+	// is written online, and the job fails if they aren't met. Incremental
+	// updates are checked on only the changed rows, without a row count
+	// delta.
+	Expectations Expectations
+	Logger       *zap.SugaredLogger
 }
 
 func (m MaterializeRunner) Resource() metadata.ResourceID {
@@ -54,8 +169,41 @@ func (m MaterializeRunner) IsUpdateJob() bool {
 	return m.IsUpdate
 }
 
+// WatcherMultiplex watches several jobs, like the chunks of a
+// materialization, as one. A watcher's index in CompletionList is its chunk's
+// index in errors.
 type WatcherMultiplex struct {
 	CompletionList []types.CompletionWatcher
+	// FailFast makes Wait return as soon as any watcher fails instead of
+	// waiting for all of them. Cancel is called first to stop the others,
+	// and if it's set Wait returns once they've stopped.
+	FailFast bool
+	Cancel   context.CancelFunc
+}
+
+// ChunkError is the error of one chunk of a job.
+type ChunkError struct {
+	Index int
+	Err   error
+}
+
+// ChunkErrors are the errors of the chunks of a job that failed, in chunk
+// order. It unwraps to the first chunk's error.
+type ChunkErrors []ChunkError
+
+func (errs ChunkErrors) Error() string {
+	if len(errs) == 1 {
+		return fmt.Sprintf("chunk %d: %v", errs[0].Index, errs[0].Err)
+	}
+	messages := make([]string, len(errs))
+	for i, err := range errs {
+		messages[i] = fmt.Sprintf("chunk %d: %v", err.Index, err.Err)
+	}
+	return fmt.Sprintf("%d chunks failed: %s", len(errs), strings.Join(messages, "; "))
+}
+
+func (errs ChunkErrors) Unwrap() error {
+	return errs[0].Err
 }
 
 func (w WatcherMultiplex) Complete() bool {
@@ -74,94 +222,209 @@ func (w WatcherMultiplex) String() string {
 	}
 	return fmt.Sprintf("%v complete out of %v", complete, len(w.CompletionList))
 }
+
+// Wait waits for every watcher and returns ChunkErrors if any failed, unless
+// FailFast is set.
 func (w WatcherMultiplex) Wait() error {
+	if w.FailFast {
+		return w.waitFailFast()
+	}
 	for _, completion := range w.CompletionList {
-		if err := completion.Wait(); err != nil {
-			return err
-		}
+		completion.Wait()
 	}
-	return nil
+	return w.Err()
+}
+
+// waitFailFast waits for the watchers at once, and cancels the rest when one
+// fails. Watchers that were stopped by the cancel aren't counted as failed.
+func (w WatcherMultiplex) waitFailFast() error {
+	failed := make(chan struct{})
+	var fail sync.Once
+	var wg sync.WaitGroup
+	for _, completion := range w.CompletionList {
+		wg.Add(1)
+		go func(completion types.CompletionWatcher) {
+			defer wg.Done()
+			if err := completion.Wait(); err != nil {
+				fail.Do(func() { close(failed) })
+			}
+		}(completion)
+	}
+	finished := make(chan struct{})
+	go func() {
+		wg.Wait()
+		close(finished)
+	}()
+	select {
+	case <-finished:
+		return w.Err()
+	case <-failed:
+	}
+	if w.Cancel == nil {
+		return w.Err()
+	}
+	w.Cancel()
+	<-finished
+	if errs := w.chunkErrors(true); errs != nil {
+		return errs
+	}
+	return w.Err()
 }
+
+// Err returns ChunkErrors of every watcher that has failed so far, or nil if
+// none have.
 func (w WatcherMultiplex) Err() error {
+	if errs := w.chunkErrors(false); errs != nil {
+		return errs
+	}
+	return nil
+}
+
+func (w WatcherMultiplex) chunkErrors(skipCancelled bool) ChunkErrors {
+	var errs ChunkErrors
+	for i, completion := range w.CompletionList {
+		err := completion.Err()
+		if err == nil || (skipCancelled && errors.Is(err, context.Canceled)) {
+			continue
+		}
+		errs = append(errs, ChunkError{Index: i, Err: err})
+	}
+	return errs
+}
+
+// Progress sums the progress of the watchers. The watchers run at once, so
+// the ETA is that of the slowest.
+func (w WatcherMultiplex) Progress() types.Progress {
+	total := types.Progress{}
 	for _, completion := range w.CompletionList {
-		if err := completion.Err(); err != nil {
-			return err
+		progress := types.ProgressOf(completion)
+		total.RowsWritten += progress.RowsWritten
+		total.ChunksDone += progress.ChunksDone
+		total.ChunksTotal += progress.ChunksTotal
+		if progress.ETA > total.ETA {
+			total.ETA = progress.ETA
 		}
 	}
-	return nil
+	return total
 }
 
 func (m MaterializeRunner) Run() (types.CompletionWatcher, error) {
+	return m.RunContext(context.Background())
+}
+
+// RunContext materializes the feature until it's done or ctx is cancelled.
+// Cancelling stops the chunks that are copying, and Kubernetes deletes their
+// job. The checkpoint of a cancelled copy is kept, so it can be resumed.
+func (m MaterializeRunner) RunContext(ctx context.Context) (types.CompletionWatcher, error) {
+	if m.DryRun {
+		return m.dryRun()
+	}
+	metrics := newRunMetrics(MATERIALIZE, m.ID)
+	watcher, err := m.materialize(ctx)
+	if err != nil {
+		metrics.finish(err)
+		return nil, err
+	}
+	metrics.watch(watcher)
+	return watcher, nil
+}
+
+func (m MaterializeRunner) materialize(ctx context.Context) (types.CompletionWatcher, error) {
 	m.Logger.Infow("Starting Materialization Runner", "name", m.ID.Name, "variant", m.ID.Variant)
-	var materialization provider.Materialization
+	var incremental bool
 	var err error
 
-	if m.IsUpdate {
-		m.Logger.Infow("Updating Materialization", "name", m.ID.Name, "variant", m.ID.Variant)
-		materialization, err = m.Offline.UpdateMaterialization(m.ID)
+	materialization, plan, resuming := m.resume()
+	var previousRows int64
+	if resuming {
+		m.Logger.Infow("Resuming Materialization", "name", m.ID.Name, "variant", m.ID.Variant, "materialization", plan.Materialization)
+	} else if m.IsUpdate {
+		previousRows = m.previousRows()
+		materialization, incremental, err = m.updateMaterialization()
 	} else {
-		m.Logger.Infow("Creating Materialization", "name", m.ID.Name, "variant", m.ID.Variant)
-		materialization, err = m.Offline.CreateMaterialization(m.ID)
+		m.Logger.Infow("Creating Materialization", "name", m.ID.Name, "variant", m.ID.Variant, "dedup", m.Dedup.Type)
+		materialization, err = m.createMaterialization()
 	}
 	if err != nil {
 		return nil, err
 	}
+	// Resumed copies were validated when they started.
+	if !resuming && !m.Expectations.IsZero() {
+		if incremental {
+			previousRows = 0
+		}
+		m.Logger.Infow("Validating Materialization", "name", m.ID.Name, "variant", m.ID.Variant, "materialization", materialization.ID())
+		if err := validateMaterialization(ctx, m.ID, materialization, m.Expectations, previousRows); err != nil {
+			m.Logger.Errorw("Materialization failed validation", "name", m.ID.Name, "variant", m.ID.Variant, "error", err)
+			return nil, err
+		}
+	}
 	// Create the vector similarity index prior to writing any values to the
 	// inference store. This is currently only required for RediSearch, but other
 	// vector databases allow for manual index configuration even if they support
 	// autogeneration of indexes.
-	if vectorType, ok := m.VType.(provider.VectorType); ok && vectorType.IsEmbedding {
-		m.Logger.Infow("Creating Index", "name", m.ID.Name, "variant", m.ID.Variant)
-		vectorStore, ok := m.Online.(provider.VectorStore)
-		if !ok {
-			return nil, fmt.Errorf("cannot create index on non-vector store: %v", m.Online)
+	for _, store := range m.onlineStores() {
+		if err := m.createTable(store, incremental, resuming); err != nil {
+			return nil, err
 		}
-		if !ok {
-			return nil, fmt.Errorf("cannot create index on non-vector type: %v", m.VType)
-		}
-		_, err := vectorStore.CreateIndex(m.ID.Name, m.ID.Variant, vectorType)
+	}
+	tunables := cfg.GetTunables()
+	checkpoint := resuming
+	if !resuming {
+		bulkLoad := m.bulkLoads(incremental)
+		m.Logger.Debugw("Getting number of rows", "name", m.ID.Name, "variant", m.ID.Variant)
+		numRows, approximate, err := m.numRows(materialization, tunables.ApproximateChunkPlanning && !bulkLoad)
 		if err != nil {
-			return nil, fmt.Errorf("create index error: %w", err)
+			return nil, fmt.Errorf("num rows: %w", err)
 		}
-	}
-	m.Logger.Infow("Creating Table", "name", m.ID.Name, "variant", m.ID.Variant)
-	_, err = m.Online.CreateTable(m.ID.Name, m.ID.Variant, m.VType)
-	_, exists := err.(*provider.TableAlreadyExists)
-	if err != nil && !exists {
-		return nil, fmt.Errorf("create table error: %w", err)
-	}
-	if exists && !m.IsUpdate {
-		return nil, fmt.Errorf("table already exists despite being new job")
-	}
-	chunkSize := MAXIMUM_CHUNK_ROWS
-	var numChunks int64
-	m.Logger.Debugw("Getting number of rows", "name", m.ID.Name, "variant", m.ID.Variant)
-	numRows, err := materialization.NumRows()
-	if err != nil {
-		return nil, fmt.Errorf("num rows: %w", err)
-	}
-	m.Logger.Debugw("Got materialization rows", "name", m.ID.Name, "variant", m.ID.Variant, "count", numRows)
-	if numRows <= MAXIMUM_CHUNK_ROWS {
-		chunkSize = numRows
-		numChunks = 1
-	} else if chunkSize == 0 {
-		numChunks = 0
-	} else if numRows > chunkSize {
-		numChunks = numRows / chunkSize
-		if chunkSize*numChunks < numRows {
-			numChunks += 1
+		m.Logger.Debugw("Got materialization rows", "name", m.ID.Name, "variant", m.ID.Variant, "count", numRows, "approximate", approximate)
+		if bulkLoad {
+			return m.bulkLoad(ctx, m.Online.(provider.BulkLoadableStore), materialization, numRows)
+		}
+		plan, err = m.planChunks(materialization, numRows, approximate, tunables)
+		if err != nil {
+			return nil, err
 		}
+		checkpoint = m.startCheckpoint(plan)
 	}
+	return m.copyChunks(ctx, materialization, plan, checkpoint)
+}
+
+// copyChunks copies the materialization to the online stores in the chunks of
+// plan, in Kubernetes or locally depending on m.Cloud. The copy's checkpoint
+// is deleted once it completes if checkpoint is set.
+func (m MaterializeRunner) copyChunks(ctx context.Context, materialization provider.Materialization, plan provider.ChunkPlan, checkpoint bool) (types.CompletionWatcher, error) {
+	tunables := cfg.GetTunables()
+	numChunks := plan.NumChunks
 	m.Logger.Infow("Creating chunks", "name", m.ID.Name, "variant", m.ID.Variant, "count", numChunks)
+	workers := localMaterializeWorkers(tunables.LocalMaterializeWorkers, numChunks)
+	rateLimits := m.chunkRateLimits(tunables, numChunks, workers)
 	config := &MaterializedChunkRunnerConfig{
-		OnlineType:     m.Online.Type(),
-		OfflineType:    m.Offline.Type(),
-		OnlineConfig:   m.Online.Config(),
-		OfflineConfig:  m.Offline.Config(),
-		MaterializedID: materialization.ID(),
-		ResourceID:     m.ID,
-		ChunkSize:      chunkSize,
-		Logger:         m.Logger,
+		OnlineType:             m.Online.Type(),
+		OfflineType:            m.Offline.Type(),
+		OnlineConfig:           m.Online.Config(),
+		AdditionalOnline:       onlineTargets(m.AdditionalOnline),
+		OfflineConfig:          m.Offline.Config(),
+		MaterializedID:         materialization.ID(),
+		ResourceID:             m.ID,
+		ChunkSize:              plan.ChunkSize,
+		NumChunks:              numChunks,
+		Approximate:            plan.Approximate,
+		Partitioned:            plan.Partitioned,
+		Checkpoint:             checkpoint,
+		CopyID:                 plan.CopyID,
+		PlannedRows:            plan.Rows,
+		DedupWindow:            time.Duration(tunables.WriteDedupWindowSeconds) * time.Second,
+		BufferSize:             tunables.WriteBufferSize,
+		BatchSize:              tunables.MaterializeBatchSize,
+		BufferFlushInterval:    time.Duration(tunables.WriteBufferFlushMillis) * time.Millisecond,
+		WriteRateLimit:         rateLimits.Entities,
+		ProviderWriteRateLimit: rateLimits.ProviderWrites,
+		RowRateLimit:           rateLimits.Rows,
+		Retry:                  chunkRetryPolicy(tunables),
+		Entity:                 m.Entity,
+		KeyRules:               m.KeyRules,
+		Logger:                 m.Logger,
 	}
 	serializedConfig, err := config.Serialize()
 	if err != nil {
@@ -183,25 +446,13 @@ func (m MaterializeRunner) Run() (types.CompletionWatcher, error) {
 		if err != nil {
 			return nil, fmt.Errorf("kubernetes runner: %w", err)
 		}
-		cloudWatcher, err = kubernetesRunner.Run()
+		cloudWatcher, err = types.RunContext(ctx, kubernetesRunner)
 		if err != nil {
 			return nil, fmt.Errorf("kubernetes run: %w", err)
 		}
 	case LocalMaterializeRunner:
-		m.Logger.Infow("Making Local Runner", "name", m.ID.Name, "variant", m.ID.Variant)
-		completionList := make([]types.CompletionWatcher, int(numChunks))
-		for i := 0; i < int(numChunks); i++ {
-			localRunner, err := Create(string(COPY_TO_ONLINE), serializedConfig)
-			if err != nil {
-				return nil, fmt.Errorf("local runner create: %w", err)
-			}
-			watcher, err := localRunner.Run()
-			if err != nil {
-				return nil, fmt.Errorf("local runner run: %w", err)
-			}
-			completionList[i] = watcher
-		}
-		cloudWatcher = WatcherMultiplex{completionList}
+		m.Logger.Infow("Making Local Runner", "name", m.ID.Name, "variant", m.ID.Variant, "workers", workers)
+		cloudWatcher = runLocalChunks(ctx, serializedConfig, numChunks, workers)
 	default:
 		return nil, fmt.Errorf("no valid job cloud set")
 	}
@@ -209,26 +460,557 @@ func (m MaterializeRunner) Run() (types.CompletionWatcher, error) {
 	materializeWatcher := &SyncWatcher{
 		ResultSync:  &ResultSync{},
 		DoneChannel: done,
+		ProgressFunc: func() types.Progress {
+			return types.ProgressOf(cloudWatcher)
+		},
 	}
 	go func() {
 		if err := cloudWatcher.Wait(); err != nil {
 			materializeWatcher.EndWatch(fmt.Errorf("cloud watch: %w", err))
 			return
 		}
+		for _, store := range m.onlineStores() {
+			if err := m.waitForIndex(store); err != nil {
+				materializeWatcher.EndWatch(err)
+				return
+			}
+		}
+		if checkpoint {
+			m.finishCheckpoint()
+		}
 		materializeWatcher.EndWatch(nil)
 	}()
 	return materializeWatcher, nil
 }
 
+// bulkLoads is whether the materialization is bulk loaded into the online
+// store instead of copied in chunks. Bulk loads replace all of a table's
+// values, so incremental updates are written row by row. They also read the
+// materialization once per store, so materializations to several stores are
+// written row by row too.
+func (m MaterializeRunner) bulkLoads(incremental bool) bool {
+	return m.Online.Capabilities().BulkLoad && !incremental && len(m.AdditionalOnline) == 0
+}
+
+// ChunkRateLimits are the rate limits of each of a materialization's chunks.
+// Zero limits are disabled.
+type ChunkRateLimits struct {
+	// Entities is the entities per second that a chunk writes.
+	Entities float64
+	// ProviderWrites is the write requests per second that a chunk makes to
+	// each online provider.
+	ProviderWrites float64
+	// Rows is the rows per second that a chunk copies.
+	Rows float64
+}
+
+// chunkRateLimits splits the configured rate limits between the chunks. Every
+// chunk runs at once in Kubernetes, and up to the number of workers locally.
+// Chunks that run at once each get an equal share of a limit. Chunks write to
+// every store at the same rate, so the lowest limit of the stores applies.
+func (m MaterializeRunner) chunkRateLimits(tunables cfg.Tunables, numChunks int64, workers int64) ChunkRateLimits {
+	concurrentChunks := numChunks
+	if m.Cloud == LocalMaterializeRunner {
+		concurrentChunks = workers
+	}
+	if concurrentChunks <= 0 {
+		return ChunkRateLimits{}
+	}
+	lowest := func(limitOf func(providerType string) float64) float64 {
+		var lowest float64
+		for _, store := range m.onlineStores() {
+			limit := limitOf(string(store.Type()))
+			if limit > 0 && (lowest == 0 || limit < lowest) {
+				lowest = limit
+			}
+		}
+		return lowest / float64(concurrentChunks)
+	}
+	return ChunkRateLimits{
+		Entities:       lowest(tunables.WriteRateLimit),
+		ProviderWrites: lowest(tunables.ProviderWriteRateLimit),
+		Rows:           float64(tunables.MaterializeRowRateLimit) / float64(concurrentChunks),
+	}
+}
+
+func chunkRetryPolicy(tunables cfg.Tunables) ChunkRetryPolicy {
+	return ChunkRetryPolicy{
+		MaxAttempts:    tunables.ChunkMaxAttempts,
+		InitialBackoff: time.Duration(tunables.ChunkRetryBackoffMillis) * time.Millisecond,
+		MaxBackoff:     time.Duration(tunables.ChunkRetryMaxBackoffMillis) * time.Millisecond,
+	}
+}
+
+// localMaterializeWorkers is how many of a local materialization's chunks are
+// copied at once: the configured number of workers, or one per CPU, but no
+// more than there are chunks.
+func localMaterializeWorkers(configured int, numChunks int64) int64 {
+	workers := int64(configured)
+	if workers <= 0 {
+		workers = int64(runtime.NumCPU())
+	}
+	if workers > numChunks {
+		workers = numChunks
+	}
+	return workers
+}
+
+// runLocalChunks copies a materialization's chunks in this process, with up
+// to workers of them running at once. Each chunk's runner is created when a
+// worker is free to run it, so only the running chunks hold connections to
+// the stores. No more chunks are started once one fails. Rows are counted in
+// the pool's progress once their chunk completes. Cancelling ctx stops the
+// running chunks and starts no more.
+func runLocalChunks(ctx context.Context, config Config, numChunks int64, workers int64) types.CompletionWatcher {
+	done := make(chan interface{})
+	tracker := newProgressTracker(numChunks)
+	poolWatcher := &SyncWatcher{
+		ResultSync:   &ResultSync{},
+		DoneChannel:  done,
+		ProgressFunc: tracker.progress,
+	}
+	go func() {
+		chunks := make(chan types.Runner)
+		failed := make(chan struct{})
+		var fail sync.Once
+		var firstErr error
+		failWith := func(err error) {
+			fail.Do(func() {
+				firstErr = err
+				close(failed)
+			})
+		}
+		var wg sync.WaitGroup
+		for w := int64(0); w < workers; w++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				for chunk := range chunks {
+					select {
+					case <-failed:
+						continue
+					default:
+					}
+					watcher, err := types.RunContext(ctx, chunk)
+					if err != nil {
+						failWith(fmt.Errorf("local runner run: %w", err))
+						continue
+					}
+					if err := watcher.Wait(); err != nil {
+						failWith(err)
+						continue
+					}
+					tracker.addRows(types.ProgressOf(watcher).RowsWritten)
+					tracker.completeChunk()
+				}
+			}()
+		}
+	dispatch:
+		for i := int64(0); i < numChunks; i++ {
+			chunk, err := localChunkRunner(config, int(i))
+			if err != nil {
+				failWith(err)
+				break
+			}
+			select {
+			case chunks <- chunk:
+			case <-failed:
+				break dispatch
+			case <-ctx.Done():
+				failWith(ctx.Err())
+				break dispatch
+			}
+		}
+		close(chunks)
+		wg.Wait()
+		poolWatcher.EndWatch(firstErr)
+	}()
+	return poolWatcher
+}
+
+// localChunkRunner creates the runner of the chunk at index.
+func localChunkRunner(config Config, index int) (types.Runner, error) {
+	chunk, err := Create(string(COPY_TO_ONLINE), config)
+	if err != nil {
+		return nil, fmt.Errorf("local runner create: %w", err)
+	}
+	if indexRunner, ok := chunk.(IndexRunner); ok {
+		if err := indexRunner.SetIndex(index); err != nil {
+			return nil, fmt.Errorf("local runner set index %d: %w", index, err)
+		}
+	}
+	return chunk, nil
+}
+
+// onlineStores returns every online store the materialization is written to.
+func (m MaterializeRunner) onlineStores() []provider.OnlineStore {
+	return append([]provider.OnlineStore{m.Online}, m.AdditionalOnline...)
+}
+
+// createTable creates the feature's table in an online store, and its vector
+// index if it's an embedding. Tables that already exist are truncated before
+// full updates if FullRefreshMaterializations is set, unless a copy into them
+// is being resumed.
+func (m MaterializeRunner) createTable(store provider.OnlineStore, incremental bool, resuming bool) error {
+	// Create the vector similarity index prior to writing any values to the
+	// inference store. This is currently only required for RediSearch, but other
+	// vector databases allow for manual index configuration even if they support
+	// autogeneration of indexes.
+	if vectorType, ok := m.VType.(provider.VectorType); ok && vectorType.IsEmbedding {
+		m.Logger.Infow("Creating Index", "name", m.ID.Name, "variant", m.ID.Variant, "type", store.Type())
+		vectorStore, ok := store.(provider.VectorStore)
+		if !ok || !store.Capabilities().Vectors {
+			return fmt.Errorf("cannot create index on non-vector store: %v", store)
+		}
+		_, err := vectorStore.CreateIndex(m.ID.Name, m.ID.Variant, vectorType)
+		if err != nil {
+			return fmt.Errorf("create index error: %w", err)
+		}
+	}
+	m.Logger.Infow("Creating Table", "name", m.ID.Name, "variant", m.ID.Variant, "type", store.Type())
+	_, err := store.CreateTable(m.ID.Name, m.ID.Variant, m.VType)
+	_, exists := err.(*provider.TableAlreadyExists)
+	if err != nil && !exists {
+		return fmt.Errorf("create table error: %w", err)
+	}
+	if exists && resuming {
+		return nil
+	}
+	if exists && !m.IsUpdate {
+		return fmt.Errorf("table already exists despite being new job")
+	}
+	// Incremental updates only have the changed entities, so truncating would
+	// remove the rest.
+	if exists && !incremental && cfg.GetTunables().FullRefreshMaterializations {
+		return m.truncate(store)
+	}
+	return nil
+}
+
+// waitForIndex waits for stores that index vectors in the background to
+// finish indexing the materialized vectors, so that the materialization
+// isn't complete until Nearest can return them.
+func (m MaterializeRunner) waitForIndex(store provider.OnlineStore) error {
+	if vectorType, ok := m.VType.(provider.VectorType); !ok || !vectorType.IsEmbedding {
+		return nil
+	}
+	building, ok := store.(provider.IndexBuildingStore)
+	if !ok || !store.Capabilities().AsyncIndexBuilds {
+		return nil
+	}
+	m.Logger.Infow("Waiting for Index", "name", m.ID.Name, "variant", m.ID.Variant, "type", store.Type())
+	watcher, err := building.WatchIndexBuild(m.ID.Name, m.ID.Variant)
+	if err != nil {
+		return fmt.Errorf("watch index build: %w", err)
+	}
+	if err := watcher.Wait(); err != nil {
+		return fmt.Errorf("index build: %w", err)
+	}
+	return nil
+}
+
+// numRows counts the materialization's rows, or estimates them if
+// approximate is set and the materialization can. It returns whether the
+// count is an estimate.
+func (m MaterializeRunner) numRows(materialization provider.Materialization, approximate bool) (int64, bool, error) {
+	if counter, ok := materialization.(provider.ApproximateRowCounter); ok && approximate {
+		numRows, err := counter.ApproxNumRows()
+		if err == nil {
+			return numRows, true, nil
+		}
+		m.Logger.Warnw("Could not estimate materialization rows, counting them", "name", m.ID.Name, "variant", m.ID.Variant, "error", err)
+	}
+	numRows, err := materialization.NumRows()
+	return numRows, false, err
+}
+
+// planChunks splits the materialization into chunks of at most the tunables'
+// chunk rows and bytes, or into its partitions if it's partitioned.
+func (m MaterializeRunner) planChunks(materialization provider.Materialization, numRows int64, approximate bool, tunables cfg.Tunables) (provider.ChunkPlan, error) {
+	numPartitions, err := m.numPartitions(materialization, numRows)
+	if err != nil {
+		return provider.ChunkPlan{}, err
+	}
+	chunkSize := tunables.MaterializeChunkRows
+	plan := provider.ChunkPlan{Materialization: materialization.ID(), ChunkSize: chunkSize, CopyID: uuid.NewString()}
+	if numPartitions > 0 {
+		plan.NumChunks = int64(numPartitions)
+		plan.Partitioned = true
+		return plan, nil
+	}
+	chunkSize = m.chunkRows(materialization, numRows, tunables)
+	plan.ChunkSize = chunkSize
+	plan.Approximate = approximate
+	if !approximate {
+		plan.Rows = numRows
+	}
+	if numRows <= chunkSize {
+		plan.ChunkSize = numRows
+		plan.NumChunks = 1
+	} else if chunkSize == 0 {
+		plan.NumChunks = 0
+	} else {
+		plan.NumChunks = numRows / chunkSize
+		if chunkSize*plan.NumChunks < numRows {
+			plan.NumChunks += 1
+		}
+	}
+	return plan, nil
+}
+
+// chunkSampleRows is how many rows are read to estimate the size of a
+// materialization's rows.
+const chunkSampleRows = 1000
+
+// recordOverheadBytes is roughly what a copied record takes in memory besides
+// its entity and value.
+const recordOverheadBytes = 64
+
+// chunkRows is how many rows a chunk of the materialization has, so that it
+// takes at most tunables.MaterializeChunkBytes in memory. Rows are sized from
+// the first rows of the materialization, and chunks have at least one row and
+// at most tunables.MaterializeChunkRows.
+func (m MaterializeRunner) chunkRows(materialization provider.Materialization, numRows int64, tunables cfg.Tunables) int64 {
+	chunkRows := tunables.MaterializeChunkRows
+	if tunables.MaterializeChunkBytes == 0 || numRows == 0 {
+		return chunkRows
+	}
+	rowBytes, err := sampleRowBytes(materialization, numRows)
+	if err != nil {
+		m.Logger.Warnw("Could not sample materialization rows, sizing chunks by rows", "name", m.ID.Name, "variant", m.ID.Variant, "error", err)
+		return chunkRows
+	}
+	if byBytes := tunables.MaterializeChunkBytes / rowBytes; byBytes < chunkRows {
+		chunkRows = byBytes
+		if chunkRows < 1 {
+			chunkRows = 1
+		}
+	}
+	m.Logger.Debugw("Sized chunks", "name", m.ID.Name, "variant", m.ID.Variant, "row_bytes", rowBytes, "chunk_rows", chunkRows)
+	return chunkRows
+}
+
+// sampleRowBytes is the average size of the first rows of materialization.
+func sampleRowBytes(materialization provider.Materialization, numRows int64) (int64, error) {
+	sampleRows := int64(chunkSampleRows)
+	if numRows < sampleRows {
+		sampleRows = numRows
+	}
+	it, err := materialization.IterateSegment(0, sampleRows)
+	if err != nil {
+		return 0, err
+	}
+	defer it.Close()
+	var rows, bytes int64
+	for it.Next() {
+		record := it.Value()
+		rows++
+		bytes += recordOverheadBytes + int64(len(record.Entity)) + valueBytes(record.Value)
+	}
+	if err := it.Err(); err != nil {
+		return 0, err
+	}
+	if rows == 0 {
+		return recordOverheadBytes, nil
+	}
+	return bytes / rows, nil
+}
+
+// valueBytes approximates the size of a feature value in memory.
+func valueBytes(value interface{}) int64 {
+	switch v := value.(type) {
+	case nil:
+		return 0
+	case string:
+		return int64(len(v))
+	case []byte:
+		return int64(len(v))
+	case []float32:
+		return 4 * int64(len(v))
+	case []float64:
+		return 8 * int64(len(v))
+	case []string:
+		size := int64(0)
+		for _, s := range v {
+			size += 16 + int64(len(s))
+		}
+		return size
+	case bool, int32, float32:
+		return 4
+	case time.Time:
+		return 24
+	default:
+		return 8
+	}
+}
+
+// resume returns the materialization and chunk plan of an earlier copy of the
+// feature that didn't finish, if the offline store kept a checkpoint of it
+// and the materialization still exists. The chunks it completed aren't copied
+// again.
+func (m MaterializeRunner) resume() (provider.Materialization, provider.ChunkPlan, bool) {
+	checkpoints, ok := m.Offline.(provider.ChunkCheckpointStore)
+	if !ok {
+		return nil, provider.ChunkPlan{}, false
+	}
+	checkpoint, err := checkpoints.GetChunkCheckpoint(m.ID)
+	notFound := &provider.ChunkCheckpointNotFound{}
+	if errors.As(err, &notFound) {
+		return nil, provider.ChunkPlan{}, false
+	} else if err != nil {
+		m.Logger.Warnw("Could not read chunk checkpoint, materializing from the start", "name", m.ID.Name, "variant", m.ID.Variant, "error", err)
+		return nil, provider.ChunkPlan{}, false
+	}
+	materialization, err := m.Offline.GetMaterialization(checkpoint.Plan.Materialization)
+	if err != nil {
+		m.Logger.Warnw("Checkpointed materialization not found, materializing from the start", "name", m.ID.Name, "variant", m.ID.Variant, "materialization", checkpoint.Plan.Materialization, "error", err)
+		return nil, provider.ChunkPlan{}, false
+	}
+	m.Logger.Infow("Found chunk checkpoint", "name", m.ID.Name, "variant", m.ID.Variant, "completed", len(checkpoint.Completed), "count", checkpoint.Plan.NumChunks)
+	return materialization, checkpoint.Plan, true
+}
+
+// startCheckpoint records the plan of a new copy of the feature, replacing
+// the checkpoint of any earlier one. It returns whether the copy's chunks are
+// checkpointed, which they aren't if the offline store can't keep them.
+func (m MaterializeRunner) startCheckpoint(plan provider.ChunkPlan) bool {
+	checkpoints, ok := m.Offline.(provider.ChunkCheckpointStore)
+	if !ok {
+		return false
+	}
+	if err := checkpoints.StartChunkCheckpoint(m.ID, plan); err != nil {
+		m.Logger.Warnw("Could not start chunk checkpoint, materializing without one", "name", m.ID.Name, "variant", m.ID.Variant, "error", err)
+		return false
+	}
+	return true
+}
+
+// finishCheckpoint deletes the checkpoint of a copy that's completed, so the
+// next materialization of the feature starts from the beginning.
+func (m MaterializeRunner) finishCheckpoint() {
+	if err := m.Offline.(provider.ChunkCheckpointStore).DeleteChunkCheckpoint(m.ID); err != nil {
+		m.Logger.Warnw("Could not delete chunk checkpoint", "name", m.ID.Name, "variant", m.ID.Variant, "error", err)
+	}
+}
+
+// numPartitions is the number of partitions to copy the materialization in,
+// one per chunk, or zero if it's copied in chunks of rows. Empty
+// materializations aren't partitioned, so they're copied by no chunks.
+func (m MaterializeRunner) numPartitions(materialization provider.Materialization, numRows int64) (int, error) {
+	partitioned, ok := materialization.(provider.PartitionedMaterialization)
+	if !ok || numRows == 0 {
+		return 0, nil
+	}
+	numPartitions, err := partitioned.NumPartitions()
+	if err != nil {
+		return 0, fmt.Errorf("num partitions: %w", err)
+	}
+	m.Logger.Debugw("Got materialization partitions", "name", m.ID.Name, "variant", m.ID.Variant, "count", numPartitions)
+	return numPartitions, nil
+}
+
+// createMaterialization materializes the feature with its dedup strategy.
+// Offline stores that can't apply a strategy other than the latest row fail.
+func (m MaterializeRunner) createMaterialization() (provider.Materialization, error) {
+	if m.Dedup.IsLatest() {
+		return m.Offline.CreateMaterialization(m.ID)
+	}
+	store, ok := m.Offline.(provider.DedupOfflineStore)
+	if !ok {
+		return nil, &provider.DedupStrategyNotSupported{ProviderType: string(m.Offline.Type()), Strategy: m.Dedup.Type}
+	}
+	return store.CreateDedupMaterialization(m.ID, m.Dedup)
+}
+
+// updateMaterialization updates the materialization incrementally if that's
+// been requested and the offline store supports it, and otherwise in full. It
+// returns whether the update was incremental. Materializations with a dedup
+// strategy other than the latest row are always updated in full.
+func (m MaterializeRunner) updateMaterialization() (provider.Materialization, bool, error) {
+	if !m.Dedup.IsLatest() {
+		store, ok := m.Offline.(provider.DedupOfflineStore)
+		if !ok {
+			return nil, false, &provider.DedupStrategyNotSupported{ProviderType: string(m.Offline.Type()), Strategy: m.Dedup.Type}
+		}
+		m.Logger.Infow("Updating Materialization", "name", m.ID.Name, "variant", m.ID.Variant, "dedup", m.Dedup.Type)
+		materialization, err := store.UpdateDedupMaterialization(m.ID, m.Dedup)
+		return materialization, false, err
+	}
+	if m.Incremental {
+		if store, ok := m.Offline.(provider.IncrementalOfflineStore); ok {
+			m.Logger.Infow("Updating Materialization Incrementally", "name", m.ID.Name, "variant", m.ID.Variant)
+			materialization, err := store.UpdateMaterializationIncrementally(m.ID)
+			notSupported := &provider.IncrementalMaterializationNotSupported{}
+			if !errors.As(err, &notSupported) {
+				return materialization, err == nil, err
+			}
+		}
+		m.Logger.Warnw("Offline store does not support incremental materialization, updating in full", "name", m.ID.Name, "variant", m.ID.Variant, "type", m.Offline.Type())
+	}
+	m.Logger.Infow("Updating Materialization", "name", m.ID.Name, "variant", m.ID.Variant)
+	materialization, err := m.Offline.UpdateMaterialization(m.ID)
+	return materialization, false, err
+}
+
+// previousRows is the number of rows of the materialization that an update
+// replaces, for the row count delta expectation. It's 0 if there's no such
+// expectation, or if the offline store can't find the materialization.
+func (m MaterializeRunner) previousRows() int64 {
+	if m.Expectations.MaxRowCountDelta == nil {
+		return 0
+	}
+	getter, ok := m.Offline.(provider.FeatureMaterializationGetter)
+	if !ok {
+		m.Logger.Warnw("Offline store cannot get previous materialization, skipping row count delta", "name", m.ID.Name, "variant", m.ID.Variant, "type", m.Offline.Type())
+		return 0
+	}
+	previous, err := getter.GetFeatureMaterialization(m.ID)
+	if err != nil {
+		m.Logger.Warnw("Could not get previous materialization, skipping row count delta", "name", m.ID.Name, "variant", m.ID.Variant, "error", err)
+		return 0
+	}
+	numRows, err := previous.NumRows()
+	if err != nil {
+		m.Logger.Warnw("Could not count previous materialization rows, skipping row count delta", "name", m.ID.Name, "variant", m.ID.Variant, "error", err)
+		return 0
+	}
+	return numRows
+}
+
+// truncate clears the table before an update rewrites it, so that entities
+// no longer in the source are removed. Stores that can't truncate keep their
+// old values and are only upserted into.
+func (m MaterializeRunner) truncate(store provider.OnlineStore) error {
+	truncatable, ok := store.(provider.TruncatableStore)
+	if !ok || !store.Capabilities().Truncate {
+		m.Logger.Warnw("Online store does not support truncating, updating in place", "name", m.ID.Name, "variant", m.ID.Variant, "type", store.Type())
+		return nil
+	}
+	m.Logger.Infow("Truncating Table", "name", m.ID.Name, "variant", m.ID.Variant, "type", store.Type())
+	if err := truncatable.Truncate(m.ID.Name, m.ID.Variant); err != nil {
+		return fmt.Errorf("truncate table error: %w", err)
+	}
+	return nil
+}
+
 type MaterializedRunnerConfig struct {
-	OnlineType    pt.Type
-	OfflineType   pt.Type
-	OnlineConfig  pc.SerializedConfig
-	OfflineConfig pc.SerializedConfig
-	ResourceID    provider.ResourceID
-	VType         provider.ValueTypeJSONWrapper
-	Cloud         JobCloud
-	IsUpdate      bool
+	OnlineType   pt.Type
+	OfflineType  pt.Type
+	OnlineConfig pc.SerializedConfig
+	// AdditionalOnline are other online stores that the materialization is
+	// written to as well as the one of OnlineType.
+	AdditionalOnline []OnlineTarget
+	OfflineConfig    pc.SerializedConfig
+	ResourceID       provider.ResourceID
+	VType            provider.ValueTypeJSONWrapper
+	Cloud            JobCloud
+	IsUpdate         bool
+	Incremental      bool
+	Dedup            provider.DedupStrategy
+	Entity           string
+	KeyRules         metadata.EntityKeyRules
+	DryRun           bool
+	Expectations     Expectations
 }
 
 func (m *MaterializedRunnerConfig) Serialize() (Config, error) {
@@ -264,17 +1046,28 @@ func MaterializeRunnerFactory(config Config) (types.Runner, error) {
 	if err != nil {
 		return nil, fmt.Errorf("failed to convert provider to online store: %v", err)
 	}
+	additionalOnline, err := onlineStores(runnerConfig.AdditionalOnline)
+	if err != nil {
+		return nil, err
+	}
 	offlineStore, err := offlineProvider.AsOfflineStore()
 	if err != nil {
 		return nil, fmt.Errorf("failed to convert provider to offline store: %v", err)
 	}
 	return &MaterializeRunner{
-		Online:   onlineStore,
-		Offline:  offlineStore,
-		ID:       runnerConfig.ResourceID,
-		VType:    runnerConfig.VType.ValueType,
-		IsUpdate: runnerConfig.IsUpdate,
-		Cloud:    runnerConfig.Cloud,
-		Logger:   logging.NewLogger("materializer"),
+		Online:           onlineStore,
+		AdditionalOnline: additionalOnline,
+		Offline:          offlineStore,
+		ID:               runnerConfig.ResourceID,
+		VType:            runnerConfig.VType.ValueType,
+		IsUpdate:         runnerConfig.IsUpdate,
+		Incremental:      runnerConfig.Incremental,
+		Dedup:            runnerConfig.Dedup,
+		Cloud:            runnerConfig.Cloud,
+		Entity:           runnerConfig.Entity,
+		KeyRules:         runnerConfig.KeyRules,
+		DryRun:           runnerConfig.DryRun,
+		Expectations:     runnerConfig.Expectations,
+		Logger:           logging.NewLogger("materializer"),
 	}, nil
 }
@@ -5,8 +5,17 @@
 package runner
 
 import (
+	"context"
+	"errors"
+	"fmt"
+	"runtime"
+	"sync/atomic"
 	"testing"
+	"time"
 
+	"github.com/google/uuid"
+
+	cfg "github.com/featureform/config"
 	"github.com/featureform/metadata"
 	"github.com/featureform/provider"
 	"github.com/featureform/types"
@@ -90,7 +99,7 @@ func TestMockMaterializeRunner(t *testing.T) {
 func TestWatcherMultiplex(t *testing.T) {
 	watcherList := make([]types.CompletionWatcher, 1)
 	watcherList[0] = &mockCompletionWatcher{}
-	multiplex := WatcherMultiplex{watcherList}
+	multiplex := WatcherMultiplex{CompletionList: watcherList}
 	if err := multiplex.Wait(); err != nil {
 		t.Fatalf("Multiplex failed: %v", err)
 	}
@@ -104,3 +113,739 @@ func TestWatcherMultiplex(t *testing.T) {
 		t.Fatalf("Failed to return multiplexer string")
 	}
 }
+
+type bulkLoadOnlineStore struct {
+	MockOnlineStore
+	loaded map[string]interface{}
+}
+
+func (m *bulkLoadOnlineStore) CanBulkLoad() bool {
+	return true
+}
+
+func (m *bulkLoadOnlineStore) Capabilities() provider.OnlineCapabilities {
+	return provider.OnlineCapabilities{BulkLoad: true}
+}
+
+func (m *bulkLoadOnlineStore) BulkLoad(feature, variant string, records provider.FeatureIterator) error {
+	for records.Next() {
+		m.loaded[records.Value().Entity] = records.Value().Value
+	}
+	return records.Err()
+}
+
+type materializedOfflineStore struct {
+	MockOfflineStore
+	materialized provider.Materialization
+}
+
+func (m materializedOfflineStore) CreateMaterialization(id provider.ResourceID) (provider.Materialization, error) {
+	return m.materialized, nil
+}
+
+func TestMaterializeRunnerBulkLoads(t *testing.T) {
+	online := &bulkLoadOnlineStore{loaded: make(map[string]interface{})}
+	offline := materializedOfflineStore{materialized: &MockMaterializedFeatures{
+		id:   provider.MaterializationID(uuid.NewString()),
+		Rows: []provider.ResourceRecord{{Entity: " A ", Value: 1}, {Entity: "b", Value: 2}},
+	}}
+	materializeRunner := MaterializeRunner{
+		Online:   online,
+		Offline:  offline,
+		ID:       provider.ResourceID{Name: "test", Variant: "test", Type: provider.Feature},
+		VType:    provider.Int,
+		Cloud:    LocalMaterializeRunner,
+		Entity:   "user",
+		KeyRules: metadata.EntityKeyRules{Trim: true, Lowercase: true},
+		Logger:   zaptest.NewLogger(t).Sugar(),
+	}
+	delete(factoryMap, string(COPY_TO_ONLINE))
+	defer delete(factoryMap, string(COPY_TO_ONLINE))
+	copyErr := func(Config) (types.Runner, error) {
+		t.Fatalf("Copy chunk created for bulk loaded store")
+		return nil, nil
+	}
+	if err := RegisterFactory(string(COPY_TO_ONLINE), copyErr); err != nil {
+		t.Fatalf("Failed to register factory: %v", err)
+	}
+	watcher, err := materializeRunner.Run()
+	if err != nil {
+		t.Fatalf("Failed to create materialize runner: %v", err)
+	}
+	if err := watcher.Wait(); err != nil {
+		t.Fatalf("Failed to run materialize runner: %v", err)
+	}
+	if len(online.loaded) != 2 || online.loaded["a"] != 1 || online.loaded["b"] != 2 {
+		t.Fatalf("Unexpected bulk loaded values: %v", online.loaded)
+	}
+}
+
+type incrementalOfflineStore struct {
+	materializedOfflineStore
+	t       *testing.T
+	changed *MockMaterializedFeatures
+}
+
+func (m incrementalOfflineStore) UpdateMaterializationIncrementally(id provider.ResourceID) (provider.Materialization, error) {
+	return m.changed, nil
+}
+
+func (m incrementalOfflineStore) UpdateMaterialization(id provider.ResourceID) (provider.Materialization, error) {
+	m.t.Fatalf("Materialization updated in full")
+	return nil, nil
+}
+
+func TestMaterializeRunnerIncrementalUpdate(t *testing.T) {
+	online := &bulkLoadOnlineStore{loaded: make(map[string]interface{})}
+	changedID := provider.MaterializationID(uuid.NewString())
+	offline := incrementalOfflineStore{
+		t: t,
+		changed: &MockMaterializedFeatures{
+			id:   changedID,
+			Rows: []provider.ResourceRecord{{Entity: "b", Value: 3}},
+		},
+	}
+	materializeRunner := MaterializeRunner{
+		Online:      online,
+		Offline:     offline,
+		ID:          provider.ResourceID{Name: "test", Variant: "test", Type: provider.Feature},
+		VType:       provider.Int,
+		IsUpdate:    true,
+		Incremental: true,
+		Cloud:       LocalMaterializeRunner,
+		Logger:      zaptest.NewLogger(t).Sugar(),
+	}
+	delete(factoryMap, string(COPY_TO_ONLINE))
+	defer delete(factoryMap, string(COPY_TO_ONLINE))
+	copied := make([]provider.MaterializationID, 0)
+	copyChanged := func(config Config) (types.Runner, error) {
+		chunkConfig := &MaterializedChunkRunnerConfig{}
+		if err := chunkConfig.Deserialize(config); err != nil {
+			return nil, err
+		}
+		copied = append(copied, chunkConfig.MaterializedID)
+		return &mockChunkRunner{}, nil
+	}
+	if err := RegisterFactory(string(COPY_TO_ONLINE), copyChanged); err != nil {
+		t.Fatalf("Failed to register factory: %v", err)
+	}
+	watcher, err := materializeRunner.Run()
+	if err != nil {
+		t.Fatalf("Failed to create materialize runner: %v", err)
+	}
+	if err := watcher.Wait(); err != nil {
+		t.Fatalf("Failed to run materialize runner: %v", err)
+	}
+	if len(online.loaded) != 0 {
+		t.Fatalf("Expected an incremental update not to replace the table, got %v", online.loaded)
+	}
+	if len(copied) != 1 || copied[0] != changedID {
+		t.Fatalf("Expected the changed entities to be copied, got %v", copied)
+	}
+}
+
+func TestIncrementalFromProperties(t *testing.T) {
+	tests := map[string]bool{"true": true, " TRUE ": true, "false": false, "": false, "yes": false}
+	for value, expected := range tests {
+		properties := metadata.Properties{IncrementalMaterializationProperty: value}
+		if incremental := IncrementalFromProperties(properties); incremental != expected {
+			t.Errorf("Expected %q to be %v, got %v", value, expected, incremental)
+		}
+	}
+}
+
+func TestDedupFromProperties(t *testing.T) {
+	tests := []struct {
+		name       string
+		properties metadata.Properties
+		expected   provider.DedupStrategy
+		wantErr    bool
+	}{
+		{"Unset", metadata.Properties{}, provider.DedupStrategy{}, false},
+		{"Max", metadata.Properties{DedupStrategyProperty: " MAX "}, provider.DedupStrategy{Type: provider.DedupMax}, false},
+		{"Expression", metadata.Properties{DedupStrategyProperty: "expression", DedupExpressionProperty: "AVG(value)"}, provider.DedupStrategy{Type: provider.DedupExpression, Expression: "AVG(value)"}, false},
+		{"Missing Expression", metadata.Properties{DedupStrategyProperty: "expression"}, provider.DedupStrategy{}, true},
+		{"Unknown", metadata.Properties{DedupStrategyProperty: "first"}, provider.DedupStrategy{}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			strategy, err := DedupFromProperties(tt.properties)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Expected error %v, got %v", tt.wantErr, err)
+			}
+			if strategy != tt.expected {
+				t.Fatalf("Expected %v, got %v", tt.expected, strategy)
+			}
+		})
+	}
+}
+
+func TestMaterializeRunnerDedupNotSupported(t *testing.T) {
+	materializeRunner := MaterializeRunner{
+		Online:  MockOnlineStore{},
+		Offline: materializedOfflineStore{},
+		ID:      provider.ResourceID{Name: "test", Variant: "test", Type: provider.Feature},
+		VType:   provider.Int,
+		Dedup:   provider.DedupStrategy{Type: provider.DedupMax},
+		Cloud:   LocalMaterializeRunner,
+		Logger:  zaptest.NewLogger(t).Sugar(),
+	}
+	_, err := materializeRunner.Run()
+	notSupported := &provider.DedupStrategyNotSupported{}
+	if !errors.As(err, &notSupported) {
+		t.Fatalf("Expected the dedup strategy not to be supported, got %v", err)
+	}
+}
+
+func TestMaterializeRunnerChunksByPartition(t *testing.T) {
+	partitioned := &MockPartitionedFeatures{
+		MockMaterializedFeatures: MockMaterializedFeatures{
+			id:   provider.MaterializationID(uuid.NewString()),
+			Rows: []provider.ResourceRecord{{Entity: "a", Value: 1}, {Entity: "b", Value: 2}, {Entity: "c", Value: 3}},
+		},
+		Partitions: [][]provider.ResourceRecord{
+			{{Entity: "a", Value: 1}, {Entity: "b", Value: 2}},
+			{{Entity: "c", Value: 3}},
+		},
+	}
+	materializeRunner := MaterializeRunner{
+		Online:  MockOnlineStore{},
+		Offline: materializedOfflineStore{materialized: partitioned},
+		ID:      provider.ResourceID{Name: "test", Variant: "test", Type: provider.Feature},
+		VType:   provider.Int,
+		Cloud:   LocalMaterializeRunner,
+		Logger:  zaptest.NewLogger(t).Sugar(),
+	}
+	delete(factoryMap, string(COPY_TO_ONLINE))
+	defer delete(factoryMap, string(COPY_TO_ONLINE))
+	chunks := make([]MaterializedChunkRunnerConfig, 0)
+	recordChunk := func(config Config) (types.Runner, error) {
+		chunkConfig := MaterializedChunkRunnerConfig{}
+		if err := chunkConfig.Deserialize(config); err != nil {
+			return nil, err
+		}
+		chunks = append(chunks, chunkConfig)
+		return &mockChunkRunner{}, nil
+	}
+	if err := RegisterFactory(string(COPY_TO_ONLINE), recordChunk); err != nil {
+		t.Fatalf("Failed to register factory: %v", err)
+	}
+	watcher, err := materializeRunner.Run()
+	if err != nil {
+		t.Fatalf("Failed to create materialize runner: %v", err)
+	}
+	if err := watcher.Wait(); err != nil {
+		t.Fatalf("Failed to run materialize runner: %v", err)
+	}
+	if len(chunks) != 2 || !chunks[0].Partitioned {
+		t.Fatalf("Expected a partitioned chunk per partition, got %+v", chunks)
+	}
+}
+
+func TestMaterializeRunnerApproximateChunkPlanning(t *testing.T) {
+	t.Setenv("APPROXIMATE_CHUNK_PLANNING", "true")
+	t.Setenv("MATERIALIZE_CHUNK_ROWS", "1")
+	estimated := &MockEstimatedFeatures{
+		MockMaterializedFeatures: MockMaterializedFeatures{
+			id:   provider.MaterializationID(uuid.NewString()),
+			Rows: []provider.ResourceRecord{{Entity: "a", Value: 1}, {Entity: "b", Value: 2}, {Entity: "c", Value: 3}},
+		},
+		Estimate: 2,
+	}
+	materializeRunner := MaterializeRunner{
+		Online:  MockOnlineStore{},
+		Offline: materializedOfflineStore{materialized: estimated},
+		ID:      provider.ResourceID{Name: "test", Variant: "test", Type: provider.Feature},
+		VType:   provider.Int,
+		Cloud:   LocalMaterializeRunner,
+		Logger:  zaptest.NewLogger(t).Sugar(),
+	}
+	delete(factoryMap, string(COPY_TO_ONLINE))
+	defer delete(factoryMap, string(COPY_TO_ONLINE))
+	chunks := make([]MaterializedChunkRunnerConfig, 0)
+	recordChunk := func(config Config) (types.Runner, error) {
+		chunkConfig := MaterializedChunkRunnerConfig{}
+		if err := chunkConfig.Deserialize(config); err != nil {
+			return nil, err
+		}
+		chunks = append(chunks, chunkConfig)
+		return &mockChunkRunner{}, nil
+	}
+	if err := RegisterFactory(string(COPY_TO_ONLINE), recordChunk); err != nil {
+		t.Fatalf("Failed to register factory: %v", err)
+	}
+	watcher, err := materializeRunner.Run()
+	if err != nil {
+		t.Fatalf("Failed to create materialize runner: %v", err)
+	}
+	if err := watcher.Wait(); err != nil {
+		t.Fatalf("Failed to run materialize runner: %v", err)
+	}
+	if len(chunks) != 2 || !chunks[0].Approximate || chunks[0].NumChunks != 2 {
+		t.Fatalf("Expected two approximate chunks from the estimate, got %+v", chunks)
+	}
+}
+
+func TestAdditionalOnlineFromProperties(t *testing.T) {
+	names := AdditionalOnlineFromProperties(metadata.Properties{AdditionalOnlineProvidersProperty: " redis-east, ,redis-west "})
+	if len(names) != 2 || names[0] != "redis-east" || names[1] != "redis-west" {
+		t.Fatalf("Unexpected additional online providers: %v", names)
+	}
+	if names := AdditionalOnlineFromProperties(metadata.Properties{}); len(names) != 0 {
+		t.Fatalf("Expected no additional online providers, got %v", names)
+	}
+}
+
+type tableCreatingOnlineStore struct {
+	MockOnlineStore
+	created *[]string
+}
+
+func (m tableCreatingOnlineStore) CreateTable(feature, variant string, valueType provider.ValueType) (provider.OnlineStoreTable, error) {
+	*m.created = append(*m.created, feature)
+	return m.MockOnlineStore.CreateTable(feature, variant, valueType)
+}
+
+func TestMaterializeRunnerAdditionalOnline(t *testing.T) {
+	online := &bulkLoadOnlineStore{MockOnlineStore: *NewMockOnlineStore(), loaded: make(map[string]interface{})}
+	created := make([]string, 0)
+	additional := tableCreatingOnlineStore{MockOnlineStore: *NewMockOnlineStore(), created: &created}
+	offline := materializedOfflineStore{materialized: &MockMaterializedFeatures{
+		id:   provider.MaterializationID(uuid.NewString()),
+		Rows: []provider.ResourceRecord{{Entity: "a", Value: 1}, {Entity: "b", Value: 2}},
+	}}
+	materializeRunner := MaterializeRunner{
+		Online:           online,
+		AdditionalOnline: []provider.OnlineStore{additional},
+		Offline:          offline,
+		ID:               provider.ResourceID{Name: "test", Variant: "test", Type: provider.Feature},
+		VType:            provider.Int,
+		Cloud:            LocalMaterializeRunner,
+		Logger:           zaptest.NewLogger(t).Sugar(),
+	}
+	delete(factoryMap, string(COPY_TO_ONLINE))
+	defer delete(factoryMap, string(COPY_TO_ONLINE))
+	chunks := make([]MaterializedChunkRunnerConfig, 0)
+	recordChunk := func(config Config) (types.Runner, error) {
+		chunkConfig := MaterializedChunkRunnerConfig{}
+		if err := chunkConfig.Deserialize(config); err != nil {
+			return nil, err
+		}
+		chunks = append(chunks, chunkConfig)
+		return &mockChunkRunner{}, nil
+	}
+	if err := RegisterFactory(string(COPY_TO_ONLINE), recordChunk); err != nil {
+		t.Fatalf("Failed to register factory: %v", err)
+	}
+	watcher, err := materializeRunner.Run()
+	if err != nil {
+		t.Fatalf("Failed to create materialize runner: %v", err)
+	}
+	if err := watcher.Wait(); err != nil {
+		t.Fatalf("Failed to run materialize runner: %v", err)
+	}
+	if len(created) != 1 || created[0] != "test" {
+		t.Fatalf("Expected the table to be created in the additional store, got %v", created)
+	}
+	if len(online.loaded) != 0 {
+		t.Fatalf("Expected chunks instead of a bulk load to several stores, got %v", online.loaded)
+	}
+	if len(chunks) != 1 || len(chunks[0].AdditionalOnline) != 1 || chunks[0].AdditionalOnline[0].Type != "MOCK_ONLINE" {
+		t.Fatalf("Expected chunks to write to the additional store, got %+v", chunks)
+	}
+}
+
+type poolChunkRunner struct {
+	index   int
+	running *int32
+	maxRun  *int32
+	ran     chan int
+	err     error
+}
+
+func (r *poolChunkRunner) SetIndex(index int) error {
+	r.index = index
+	return nil
+}
+
+func (r *poolChunkRunner) Run() (types.CompletionWatcher, error) {
+	running := atomic.AddInt32(r.running, 1)
+	for {
+		max := atomic.LoadInt32(r.maxRun)
+		if running <= max || atomic.CompareAndSwapInt32(r.maxRun, max, running) {
+			break
+		}
+	}
+	time.Sleep(10 * time.Millisecond)
+	atomic.AddInt32(r.running, -1)
+	r.ran <- r.index
+	return &SyncWatcher{ResultSync: &ResultSync{done: true, err: r.err}, DoneChannel: closedChannel()}, nil
+}
+
+func (r *poolChunkRunner) Resource() metadata.ResourceID {
+	return metadata.ResourceID{}
+}
+
+func (r *poolChunkRunner) IsUpdateJob() bool {
+	return false
+}
+
+func closedChannel() chan interface{} {
+	done := make(chan interface{})
+	close(done)
+	return done
+}
+
+func TestRunLocalChunks(t *testing.T) {
+	delete(factoryMap, string(COPY_TO_ONLINE))
+	defer delete(factoryMap, string(COPY_TO_ONLINE))
+	var running, maxRunning int32
+	ran := make(chan int, 6)
+	failAt := -1
+	created := 0
+	poolChunk := func(config Config) (types.Runner, error) {
+		runner := &poolChunkRunner{running: &running, maxRun: &maxRunning, ran: ran}
+		if created == failAt {
+			runner.err = errors.New("chunk failed")
+		}
+		created++
+		return runner, nil
+	}
+	if err := RegisterFactory(string(COPY_TO_ONLINE), poolChunk); err != nil {
+		t.Fatalf("Failed to register factory: %v", err)
+	}
+	if err := runLocalChunks(context.Background(), nil, 6, 2).Wait(); err != nil {
+		t.Fatalf("Failed to run chunks: %v", err)
+	}
+	close(ran)
+	indexes := make(map[int]bool)
+	for index := range ran {
+		indexes[index] = true
+	}
+	if len(indexes) != 6 {
+		t.Fatalf("Expected chunks 0 to 5 to run once each, got %v", indexes)
+	}
+	if maxRunning > 2 {
+		t.Fatalf("Expected at most 2 chunks at once, got %d", maxRunning)
+	}
+
+	ran = make(chan int, 6)
+	created, failAt = 0, 0
+	if err := runLocalChunks(context.Background(), nil, 6, 1).Wait(); err == nil {
+		t.Fatalf("Expected the failed chunk's error")
+	}
+	if len(ran) != 1 {
+		t.Fatalf("Expected no more chunks to run after one failed, got %d", len(ran))
+	}
+}
+
+func TestLocalMaterializeWorkers(t *testing.T) {
+	if workers := localMaterializeWorkers(4, 10); workers != 4 {
+		t.Fatalf("Expected 4 workers, got %d", workers)
+	}
+	if workers := localMaterializeWorkers(4, 2); workers != 2 {
+		t.Fatalf("Expected no more workers than chunks, got %d", workers)
+	}
+	if workers := localMaterializeWorkers(0, 1000); workers != int64(runtime.NumCPU()) {
+		t.Fatalf("Expected a worker per CPU, got %d", workers)
+	}
+}
+
+type checkpointingOfflineStore struct {
+	materializedOfflineStore
+	provider.ChunkCheckpointStore
+	t *testing.T
+}
+
+func (m checkpointingOfflineStore) CreateMaterialization(id provider.ResourceID) (provider.Materialization, error) {
+	m.t.Fatalf("Materialization created despite a checkpoint")
+	return nil, nil
+}
+
+func (m checkpointingOfflineStore) GetMaterialization(id provider.MaterializationID) (provider.Materialization, error) {
+	return m.materialized, nil
+}
+
+func TestMaterializeRunnerResumesFromCheckpoint(t *testing.T) {
+	materialized := &MockMaterializedFeatures{
+		id:   provider.MaterializationID(uuid.NewString()),
+		Rows: []provider.ResourceRecord{{Entity: "a", Value: 1}, {Entity: "b", Value: 2}, {Entity: "c", Value: 3}},
+	}
+	id := provider.ResourceID{Name: "test", Variant: "test", Type: provider.Feature}
+	checkpoints := provider.NewMemoryOfflineStore()
+	plan := provider.ChunkPlan{Materialization: materialized.ID(), ChunkSize: 1, NumChunks: 3}
+	if err := checkpoints.StartChunkCheckpoint(id, plan); err != nil {
+		t.Fatalf("Failed to start checkpoint: %s", err)
+	}
+	materializeRunner := MaterializeRunner{
+		Online: &bulkLoadOnlineStore{loaded: make(map[string]interface{})},
+		Offline: checkpointingOfflineStore{
+			materializedOfflineStore: materializedOfflineStore{materialized: materialized},
+			ChunkCheckpointStore:     checkpoints,
+			t:                        t,
+		},
+		ID:     id,
+		VType:  provider.Int,
+		Cloud:  LocalMaterializeRunner,
+		Logger: zaptest.NewLogger(t).Sugar(),
+	}
+	delete(factoryMap, string(COPY_TO_ONLINE))
+	defer delete(factoryMap, string(COPY_TO_ONLINE))
+	chunks := make([]MaterializedChunkRunnerConfig, 0)
+	recordChunk := func(config Config) (types.Runner, error) {
+		chunkConfig := MaterializedChunkRunnerConfig{}
+		if err := chunkConfig.Deserialize(config); err != nil {
+			return nil, err
+		}
+		chunks = append(chunks, chunkConfig)
+		return &mockChunkRunner{}, nil
+	}
+	if err := RegisterFactory(string(COPY_TO_ONLINE), recordChunk); err != nil {
+		t.Fatalf("Failed to register factory: %v", err)
+	}
+	watcher, err := materializeRunner.Run()
+	if err != nil {
+		t.Fatalf("Failed to create materialize runner: %v", err)
+	}
+	if err := watcher.Wait(); err != nil {
+		t.Fatalf("Failed to run materialize runner: %v", err)
+	}
+	if len(chunks) != 3 || !chunks[0].Checkpoint || chunks[0].ChunkSize != 1 {
+		t.Fatalf("Expected the checkpointed plan to be resumed instead of bulk loaded, got %+v", chunks)
+	}
+	notFound := &provider.ChunkCheckpointNotFound{}
+	if _, err := checkpoints.GetChunkCheckpoint(id); !errors.As(err, &notFound) {
+		t.Fatalf("Expected the checkpoint to be deleted once the copy completed, got %v", err)
+	}
+}
+
+func TestWatcherMultiplexProgress(t *testing.T) {
+	tracker := newProgressTracker(3)
+	tracker.addRows(10)
+	tracker.completeChunk()
+	tracked := &SyncWatcher{ResultSync: &ResultSync{}, DoneChannel: make(chan interface{}), ProgressFunc: tracker.progress}
+	multiplex := WatcherMultiplex{CompletionList: []types.CompletionWatcher{tracked, mockCompletionWatcher{}}}
+	progress := multiplex.Progress()
+	if progress.RowsWritten != 10 || progress.ChunksDone != 2 || progress.ChunksTotal != 4 {
+		t.Fatalf("Unexpected progress: %+v", progress)
+	}
+	if progress.ETA <= 0 {
+		t.Fatalf("Expected an ETA once a chunk is done, got %s", progress.ETA)
+	}
+}
+
+func TestRunLocalChunksProgress(t *testing.T) {
+	materialized := MockMaterializedFeatures{
+		id:   provider.MaterializationID(uuid.NewString()),
+		Rows: []provider.ResourceRecord{{Entity: "a", Value: 1}, {Entity: "b", Value: 2}, {Entity: "c", Value: 3}},
+	}
+	delete(factoryMap, string(COPY_TO_ONLINE))
+	defer delete(factoryMap, string(COPY_TO_ONLINE))
+	copyChunk := func(config Config) (types.Runner, error) {
+		return &MaterializedChunkRunner{
+			Materialized: &materialized,
+			Table:        &MockOnlineTable{DataTable: make(map[string]interface{})},
+			Store:        NewMockOnlineStore(),
+			ChunkSize:    2,
+			NumChunks:    2,
+		}, nil
+	}
+	if err := RegisterFactory(string(COPY_TO_ONLINE), copyChunk); err != nil {
+		t.Fatalf("Failed to register factory: %v", err)
+	}
+	watcher := runLocalChunks(context.Background(), Config{}, 2, 1)
+	if err := watcher.Wait(); err != nil {
+		t.Fatalf("Failed to run chunks: %v", err)
+	}
+	progress := types.ProgressOf(watcher)
+	if progress.RowsWritten != 3 || progress.ChunksDone != 2 || progress.ChunksTotal != 2 || progress.ETA != 0 {
+		t.Fatalf("Unexpected progress: %+v", progress)
+	}
+}
+
+// blockingRunner can't be cancelled, and its job never completes.
+type blockingRunner struct {
+	mockChunkRunner
+}
+
+func (m blockingRunner) Run() (types.CompletionWatcher, error) {
+	return &SyncWatcher{ResultSync: &ResultSync{}, DoneChannel: make(chan interface{})}, nil
+}
+
+func TestRunContextCancelsRunner(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	watcher, err := types.RunContext(ctx, blockingRunner{})
+	if err != nil {
+		t.Fatalf("Failed to run: %s", err)
+	}
+	if watcher.Complete() {
+		t.Fatalf("Expected the watcher to wait for the job")
+	}
+	cancel()
+	if err := watcher.Wait(); !errors.Is(err, context.Canceled) {
+		t.Fatalf("Expected the watcher to end with context.Canceled, got %v", err)
+	}
+}
+
+func TestMaterializeRunnerDryRun(t *testing.T) {
+	t.Setenv("MATERIALIZE_CHUNK_ROWS", "2")
+	materialized := &MockMaterializedFeatures{
+		id:   provider.MaterializationID(uuid.NewString()),
+		Rows: []provider.ResourceRecord{{Entity: "a", Value: 1}, {Entity: "b", Value: 2}, {Entity: "c", Value: 3}},
+	}
+	online := provider.NewLocalOnlineStore()
+	materializeRunner := MaterializeRunner{
+		Online:  online,
+		Offline: materializedOfflineStore{materialized: materialized},
+		ID:      provider.ResourceID{Name: "test", Variant: "test", Type: provider.Feature},
+		VType:   provider.Int,
+		Cloud:   LocalMaterializeRunner,
+		DryRun:  true,
+		Logger:  zaptest.NewLogger(t).Sugar(),
+	}
+	delete(factoryMap, string(COPY_TO_ONLINE))
+	defer delete(factoryMap, string(COPY_TO_ONLINE))
+	copyChunk := func(config Config) (types.Runner, error) {
+		t.Fatalf("Expected a dry run not to copy any chunks")
+		return nil, nil
+	}
+	if err := RegisterFactory(string(COPY_TO_ONLINE), copyChunk); err != nil {
+		t.Fatalf("Failed to register factory: %v", err)
+	}
+	watcher, err := materializeRunner.Run()
+	if err != nil {
+		t.Fatalf("Failed to plan materialization: %v", err)
+	}
+	if err := watcher.Wait(); err != nil {
+		t.Fatalf("Failed to plan materialization: %v", err)
+	}
+	plan := watcher.(PlanWatcher).Plan
+	if plan.Rows != 3 || plan.Chunks.NumChunks != 2 || plan.Chunks.ChunkSize != 2 || plan.BulkLoad {
+		t.Fatalf("Expected 3 rows in 2 chunks of 2, got %+v", plan)
+	}
+	if len(plan.Tables) != 1 || plan.Tables[0].Exists || plan.Tables[0].Store != online.Type() {
+		t.Fatalf("Expected a new table to be planned, got %+v", plan.Tables)
+	}
+	notFound := &provider.TableNotFound{}
+	if _, err := online.GetTable("test", "test"); !errors.As(err, &notFound) {
+		t.Fatalf("Expected a dry run not to create the table, got %v", err)
+	}
+}
+
+func TestChunkRateLimits(t *testing.T) {
+	tunables := cfg.Tunables{
+		OnlineWriteRateLimits:         "MOCK_ONLINE=100",
+		OnlineProviderWriteRateLimits: "MOCK_ONLINE=40,LOCAL_ONLINE=20",
+		MaterializeRowRateLimit:       400,
+	}
+	materializeRunner := MaterializeRunner{
+		Online:           NewMockOnlineStore(),
+		AdditionalOnline: []provider.OnlineStore{provider.NewLocalOnlineStore()},
+		Cloud:            LocalMaterializeRunner,
+	}
+	limits := materializeRunner.chunkRateLimits(tunables, 10, 4)
+	expected := ChunkRateLimits{Entities: 25, ProviderWrites: 5, Rows: 100}
+	if limits != expected {
+		t.Fatalf("Expected the lowest limits split between the 4 workers %+v, got %+v", expected, limits)
+	}
+	materializeRunner.Cloud = KubernetesMaterializeRunner
+	limits = materializeRunner.chunkRateLimits(tunables, 10, 4)
+	expected = ChunkRateLimits{Entities: 10, ProviderWrites: 2, Rows: 40}
+	if limits != expected {
+		t.Fatalf("Expected the lowest limits split between the 10 chunks %+v, got %+v", expected, limits)
+	}
+}
+
+func TestPlanChunksByRowBytes(t *testing.T) {
+	tunables := cfg.Tunables{MaterializeChunkRows: 100, MaterializeChunkBytes: 10000}
+	materializeRunner := MaterializeRunner{Logger: zaptest.NewLogger(t).Sugar()}
+	scalars := &MockMaterializedFeatures{id: "scalars"}
+	embeddings := &MockMaterializedFeatures{id: "embeddings"}
+	for i := 0; i < 200; i++ {
+		entity := fmt.Sprintf("%d", i)
+		scalars.Rows = append(scalars.Rows, provider.ResourceRecord{Entity: entity, Value: i})
+		embeddings.Rows = append(embeddings.Rows, provider.ResourceRecord{Entity: entity, Value: make([]float32, 256)})
+	}
+	plan, err := materializeRunner.planChunks(scalars, 200, false, tunables)
+	if err != nil {
+		t.Fatalf("Failed to plan chunks: %s", err)
+	}
+	if plan.ChunkSize != 100 || plan.NumChunks != 2 {
+		t.Fatalf("Expected scalars to be capped at 2 chunks of 100 rows, got %+v", plan)
+	}
+	plan, err = materializeRunner.planChunks(embeddings, 200, false, tunables)
+	if err != nil {
+		t.Fatalf("Failed to plan chunks: %s", err)
+	}
+	if plan.ChunkSize != 9 || plan.NumChunks != 23 {
+		t.Fatalf("Expected embeddings in 23 chunks of 9 rows, got %+v", plan)
+	}
+	tunables.MaterializeChunkBytes = 0
+	plan, err = materializeRunner.planChunks(embeddings, 200, false, tunables)
+	if err != nil {
+		t.Fatalf("Failed to plan chunks: %s", err)
+	}
+	if plan.ChunkSize != 100 {
+		t.Fatalf("Expected chunks sized by rows alone, got %+v", plan)
+	}
+}
+
+func endedWatcher(err error) *SyncWatcher {
+	watcher := &SyncWatcher{ResultSync: &ResultSync{}, DoneChannel: make(chan interface{})}
+	watcher.EndWatch(err)
+	return watcher
+}
+
+func TestWatcherMultiplexAggregatesErrors(t *testing.T) {
+	first := errors.New("first")
+	multiplex := WatcherMultiplex{CompletionList: []types.CompletionWatcher{
+		endedWatcher(nil), endedWatcher(first), endedWatcher(nil), endedWatcher(errors.New("second")),
+	}}
+	err := multiplex.Wait()
+	var errs ChunkErrors
+	if !errors.As(err, &errs) || len(errs) != 2 || errs[0].Index != 1 || errs[1].Index != 3 {
+		t.Fatalf("Expected chunks 1 and 3 to fail, got %v", err)
+	}
+	if !errors.Is(err, first) {
+		t.Fatalf("Expected the error to unwrap to the first chunk's, got %v", err)
+	}
+	if err := multiplex.Err(); err == nil || err.Error() != "2 chunks failed: chunk 1: first; chunk 3: second" {
+		t.Fatalf("Unexpected aggregated error: %v", err)
+	}
+}
+
+func TestWatcherMultiplexFailFast(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	running := make([]types.CompletionWatcher, 3)
+	for i := range running {
+		watcher := &SyncWatcher{ResultSync: &ResultSync{}, DoneChannel: make(chan interface{})}
+		go func() {
+			<-ctx.Done()
+			watcher.EndWatch(ctx.Err())
+		}()
+		running[i] = watcher
+	}
+	failure := errors.New("failed")
+	multiplex := WatcherMultiplex{
+		CompletionList: append(running, endedWatcher(failure)),
+		FailFast:       true,
+		Cancel:         cancel,
+	}
+	done := make(chan error)
+	go func() {
+		done <- multiplex.Wait()
+	}()
+	select {
+	case err := <-done:
+		var errs ChunkErrors
+		if !errors.As(err, &errs) || len(errs) != 1 || errs[0].Index != 3 || errs[0].Err != failure {
+			t.Fatalf("Expected only chunk 3 to be reported, got %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatalf("Expected the running watchers to be cancelled")
+	}
+	if !multiplex.Complete() {
+		t.Fatalf("Expected every watcher to have stopped")
+	}
+}
@@ -0,0 +1,126 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package runner
+
+import (
+	"fmt"
+	"sync/atomic"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/featureform/provider"
+	"github.com/featureform/types"
+)
+
+const (
+	runSucceeded = "success"
+	runFailed    = "error"
+)
+
+var (
+	runnerRows = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "runner_rows_total",
+			Help: "Count of rows written by runners, labeled by runner, resource name and variant",
+		},
+		[]string{"runner", "name", "variant"},
+	)
+	runnerBytes = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "runner_bytes_total",
+			Help: "Approximate bytes of entities and values written by runners, labeled by runner, resource name and variant",
+		},
+		[]string{"runner", "name", "variant"},
+	)
+	runnerDuration = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name:    "runner_duration_seconds",
+			Help:    "Duration of runner jobs, labeled by runner, resource name, variant and status",
+			Buckets: prometheus.ExponentialBuckets(1, 2, 16),
+		},
+		[]string{"runner", "name", "variant", "status"},
+	)
+	runnerErrors = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "runner_errors_total",
+			Help: "Count of failed runner jobs, labeled by runner, resource name and variant",
+		},
+		[]string{"runner", "name", "variant"},
+	)
+	runnerRowsPerSecond = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "runner_rows_per_second",
+			Help: "Rows written per second by the last completed runner job, labeled by runner, resource name and variant",
+		},
+		[]string{"runner", "name", "variant"},
+	)
+)
+
+// RegisterRunnerMetrics registers the metrics of every runner in the process.
+// Runners in Kubernetes jobs record them too, but exit before they can be
+// scraped.
+func RegisterRunnerMetrics(registerer prometheus.Registerer) error {
+	for _, collector := range []prometheus.Collector{runnerRows, runnerBytes, runnerDuration, runnerErrors, runnerRowsPerSecond} {
+		if err := registerer.Register(collector); err != nil {
+			return fmt.Errorf("could not register runner metrics: %w", err)
+		}
+	}
+	return nil
+}
+
+// runMetrics records the metrics of one run of a runner for a resource.
+type runMetrics struct {
+	labels  []string
+	rows    prometheus.Counter
+	bytes   prometheus.Counter
+	written int64
+	start   time.Time
+}
+
+func newRunMetrics(runner string, id provider.ResourceID) *runMetrics {
+	labels := []string{runner, id.Name, id.Variant}
+	return &runMetrics{
+		labels: labels,
+		rows:   runnerRows.WithLabelValues(labels...),
+		bytes:  runnerBytes.WithLabelValues(labels...),
+		start:  time.Now(),
+	}
+}
+
+// addRows counts rows written by the run, and their size in bytes if it's
+// known.
+func (r *runMetrics) addRows(rows, bytes int64) {
+	atomic.AddInt64(&r.written, rows)
+	r.rows.Add(float64(rows))
+	if bytes > 0 {
+		r.bytes.Add(float64(bytes))
+	}
+}
+
+// finish records the run's duration and outcome, and its rows per second if
+// it succeeded.
+func (r *runMetrics) finish(err error) {
+	elapsed := time.Since(r.start)
+	status := runSucceeded
+	if err != nil {
+		status = runFailed
+		runnerErrors.WithLabelValues(r.labels...).Inc()
+	}
+	runnerDuration.WithLabelValues(append(r.labels, status)...).Observe(elapsed.Seconds())
+	if err == nil && elapsed > 0 {
+		runnerRowsPerSecond.WithLabelValues(r.labels...).Set(float64(atomic.LoadInt64(&r.written)) / elapsed.Seconds())
+	}
+}
+
+// watch finishes the run once watcher completes, counting the rows that
+// watcher reports it wrote.
+func (r *runMetrics) watch(watcher types.CompletionWatcher) {
+	go func() {
+		err := watcher.Wait()
+		r.addRows(types.ProgressOf(watcher).RowsWritten, 0)
+		r.finish(err)
+	}()
+}
@@ -0,0 +1,76 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package runner
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	dto "github.com/prometheus/client_model/go"
+
+	"github.com/featureform/provider"
+)
+
+func TestChunkRunnerMetrics(t *testing.T) {
+	id := provider.ResourceID{Name: uuid.NewString(), Variant: "variant", Type: provider.Feature}
+	materialized := MockMaterializedFeatures{
+		id:   provider.MaterializationID(uuid.NewString()),
+		Rows: []provider.ResourceRecord{{Entity: "a", Value: "xyz"}, {Entity: "b", Value: "xyz"}},
+	}
+	job := &MaterializedChunkRunner{
+		Materialized: &materialized,
+		Table:        &MockOnlineTable{DataTable: make(map[string]interface{})},
+		Store:        NewMockOnlineStore(),
+		ChunkSize:    2,
+		Feature:      id,
+	}
+	watcher, err := job.Run()
+	if err != nil {
+		t.Fatalf("Job failed to start: %s", err)
+	}
+	if err := watcher.Wait(); err != nil {
+		t.Fatalf("Job failed: %s", err)
+	}
+	labels := []string{string(COPY_TO_ONLINE), id.Name, id.Variant}
+	if rows := testutil.ToFloat64(runnerRows.WithLabelValues(labels...)); rows != 2 {
+		t.Fatalf("Expected 2 rows, got %v", rows)
+	}
+	if bytes := testutil.ToFloat64(runnerBytes.WithLabelValues(labels...)); bytes != 8 {
+		t.Fatalf("Expected 8 bytes of entities and values, got %v", bytes)
+	}
+	duration := &dto.Metric{}
+	if err := runnerDuration.WithLabelValues(append(labels, runSucceeded)...).(prometheus.Metric).Write(duration); err != nil {
+		t.Fatalf("Failed to read duration: %s", err)
+	}
+	if count := duration.GetHistogram().GetSampleCount(); count != 1 {
+		t.Fatalf("Expected the duration to be observed once, got %d", count)
+	}
+
+	job.Table = &BrokenOnlineTable{}
+	watcher, err = job.Run()
+	if err != nil {
+		t.Fatalf("Job failed to start: %s", err)
+	}
+	if err := watcher.Wait(); err == nil {
+		t.Fatalf("Expected the job to fail")
+	}
+	if count := testutil.ToFloat64(runnerErrors.WithLabelValues(labels...)); count != 1 {
+		t.Fatalf("Expected 1 error, got %v", count)
+	}
+}
+
+func TestRegisterRunnerMetrics(t *testing.T) {
+	registry := prometheus.NewRegistry()
+	if err := RegisterRunnerMetrics(registry); err != nil {
+		t.Fatalf("Failed to register metrics: %s", err)
+	}
+	alreadyRegistered := prometheus.AlreadyRegisteredError{}
+	if err := RegisterRunnerMetrics(registry); !errors.As(err, &alreadyRegistered) {
+		t.Fatalf("Expected metrics to be registered once, got %v", err)
+	}
+}
@@ -0,0 +1,137 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package runner
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"go.uber.org/zap"
+
+	"github.com/featureform/logging"
+	"github.com/featureform/metadata"
+	"github.com/featureform/provider"
+	"github.com/featureform/types"
+
+	pc "github.com/featureform/provider/provider_config"
+	pt "github.com/featureform/provider/provider_type"
+)
+
+// OnlineMigrationRunner copies every table from one online store to another,
+// logging its progress as it goes. Both stores are closed once it's done.
+type OnlineMigrationRunner struct {
+	Source      provider.TableListingStore
+	Destination provider.OnlineStore
+	BatchSize   int
+	Logger      *zap.SugaredLogger
+}
+
+func (m OnlineMigrationRunner) Run() (types.CompletionWatcher, error) {
+	done := make(chan interface{})
+	migrationWatcher := &SyncWatcher{
+		ResultSync:  &ResultSync{},
+		DoneChannel: done,
+	}
+	go func() {
+		defer m.Source.Close()
+		defer m.Destination.Close()
+		options := provider.OnlineMigrationOptions{
+			BatchSize: m.BatchSize,
+			Progress:  m.logProgress,
+		}
+		if err := provider.MigrateOnlineStore(m.Source, m.Destination, options); err != nil {
+			migrationWatcher.EndWatch(err)
+			return
+		}
+		m.Logger.Infow("Online store migration complete", "source", m.Source.Type(), "destination", m.Destination.Type())
+		migrationWatcher.EndWatch(nil)
+	}()
+	return migrationWatcher, nil
+}
+
+func (m OnlineMigrationRunner) logProgress(progress provider.OnlineMigrationProgress) {
+	if progress.TableDone {
+		m.Logger.Infow("Migrated table",
+			"name", progress.Feature, "variant", progress.Variant, "entities", progress.Entities,
+			"tables_copied", progress.TablesCopied, "tables", progress.Tables,
+		)
+		return
+	}
+	m.Logger.Debugw("Migrating table", "name", progress.Feature, "variant", progress.Variant, "entities", progress.Entities)
+}
+
+// Resource is empty as migrations copy whole stores rather than a resource.
+func (m OnlineMigrationRunner) Resource() metadata.ResourceID {
+	return metadata.ResourceID{}
+}
+
+func (m OnlineMigrationRunner) IsUpdateJob() bool {
+	return false
+}
+
+type OnlineMigrationRunnerConfig struct {
+	SourceType        pt.Type
+	SourceConfig      pc.SerializedConfig
+	DestinationType   pt.Type
+	DestinationConfig pc.SerializedConfig
+	// BatchSize is the number of values written at a time. If it's zero,
+	// the default batch size is used.
+	BatchSize int
+}
+
+func (m *OnlineMigrationRunnerConfig) Serialize() (Config, error) {
+	config, err := json.Marshal(m)
+	if err != nil {
+		return nil, fmt.Errorf("could not marshal online migration config: %w", err)
+	}
+	return config, nil
+}
+
+func (m *OnlineMigrationRunnerConfig) Deserialize(config Config) error {
+	err := json.Unmarshal(config, m)
+	if err != nil {
+		return fmt.Errorf("could not unmarshal online migration config: %w", err)
+	}
+	return nil
+}
+
+func OnlineMigrationRunnerFactory(config Config) (types.Runner, error) {
+	migrationConfig := &OnlineMigrationRunnerConfig{}
+	if err := migrationConfig.Deserialize(config); err != nil {
+		return nil, fmt.Errorf("failed to deserialize online migration config: %w", err)
+	}
+	source, err := getOnlineStore(migrationConfig.SourceType, migrationConfig.SourceConfig)
+	if err != nil {
+		return nil, fmt.Errorf("source: %w", err)
+	}
+	listingSource, ok := source.(provider.TableListingStore)
+	if !ok || !source.Capabilities().ListTables {
+		source.Close()
+		return nil, fmt.Errorf("source %s does not support listing tables", migrationConfig.SourceType)
+	}
+	destination, err := getOnlineStore(migrationConfig.DestinationType, migrationConfig.DestinationConfig)
+	if err != nil {
+		source.Close()
+		return nil, fmt.Errorf("destination: %w", err)
+	}
+	return &OnlineMigrationRunner{
+		Source:      listingSource,
+		Destination: destination,
+		BatchSize:   migrationConfig.BatchSize,
+		Logger:      logging.NewLogger("online-migration"),
+	}, nil
+}
+
+func getOnlineStore(providerType pt.Type, config pc.SerializedConfig) (provider.OnlineStore, error) {
+	p, err := provider.Get(providerType, config)
+	if err != nil {
+		return nil, fmt.Errorf("failed to configure online provider: %w", err)
+	}
+	store, err := p.AsOnlineStore()
+	if err != nil {
+		return nil, fmt.Errorf("failed to convert provider to online store: %w", err)
+	}
+	return store, nil
+}
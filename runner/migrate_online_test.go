@@ -0,0 +1,55 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package runner
+
+import (
+	"testing"
+
+	"go.uber.org/zap/zaptest"
+
+	"github.com/featureform/provider"
+)
+
+func TestOnlineMigrationRunner(t *testing.T) {
+	source := provider.NewLocalOnlineStore()
+	table, err := source.CreateTable("feature", "variant", provider.String)
+	if err != nil {
+		t.Fatalf("Failed to create table: %s", err)
+	}
+	if err := table.Set("entity", "value"); err != nil {
+		t.Fatalf("Failed to set value: %s", err)
+	}
+	destination := provider.NewLocalOnlineStore()
+	migration := OnlineMigrationRunner{
+		Source:      source,
+		Destination: destination,
+		Logger:      zaptest.NewLogger(t).Sugar(),
+	}
+	watcher, err := migration.Run()
+	if err != nil {
+		t.Fatalf("Failed to start migration: %s", err)
+	}
+	if err := watcher.Wait(); err != nil {
+		t.Fatalf("Migration failed: %s", err)
+	}
+	migrated, err := destination.GetTable("feature", "variant")
+	if err != nil {
+		t.Fatalf("Failed to get migrated table: %s", err)
+	}
+	if value, err := migrated.Get("entity"); err != nil || value != "value" {
+		t.Fatalf("Expected migrated value, got %v: %v", value, err)
+	}
+}
+
+func TestOnlineMigrationRunnerFactoryUnknownSource(t *testing.T) {
+	config := &OnlineMigrationRunnerConfig{SourceType: "UNKNOWN_ONLINE"}
+	serialized, err := config.Serialize()
+	if err != nil {
+		t.Fatalf("Failed to serialize config: %s", err)
+	}
+	if _, err := OnlineMigrationRunnerFactory(serialized); err == nil {
+		t.Fatalf("Expected factory with unknown source to fail")
+	}
+}
@@ -0,0 +1,43 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package runner
+
+import (
+	"sync/atomic"
+	"time"
+
+	"github.com/featureform/types"
+)
+
+// progressTracker counts the rows and chunks a job has written as it runs.
+// It's safe for concurrent use.
+type progressTracker struct {
+	started     time.Time
+	chunksTotal int64
+	rows        int64
+	chunksDone  int64
+}
+
+func newProgressTracker(chunksTotal int64) *progressTracker {
+	return &progressTracker{started: time.Now(), chunksTotal: chunksTotal}
+}
+
+func (p *progressTracker) addRows(rows int64) {
+	atomic.AddInt64(&p.rows, rows)
+}
+
+func (p *progressTracker) completeChunk() {
+	atomic.AddInt64(&p.chunksDone, 1)
+}
+
+func (p *progressTracker) progress() types.Progress {
+	chunksDone := atomic.LoadInt64(&p.chunksDone)
+	return types.Progress{
+		RowsWritten: atomic.LoadInt64(&p.rows),
+		ChunksDone:  chunksDone,
+		ChunksTotal: p.chunksTotal,
+		ETA:         types.EstimateETA(p.started, chunksDone, p.chunksTotal),
+	}
+}
@@ -0,0 +1,103 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package runner
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"go.uber.org/zap"
+
+	"github.com/featureform/logging"
+	"github.com/featureform/metadata"
+	"github.com/featureform/provider"
+	"github.com/featureform/types"
+
+	pc "github.com/featureform/provider/provider_config"
+	pt "github.com/featureform/provider/provider_type"
+)
+
+// ShardRebalanceRunner moves the entities taken over by newly added shards
+// of a sharded online store, logging its progress as it goes. The store is
+// closed once it's done.
+type ShardRebalanceRunner struct {
+	Store  *provider.ShardedStore
+	Logger *zap.SugaredLogger
+}
+
+func (r ShardRebalanceRunner) Run() (types.CompletionWatcher, error) {
+	done := make(chan interface{})
+	rebalanceWatcher := &SyncWatcher{
+		ResultSync:  &ResultSync{},
+		DoneChannel: done,
+	}
+	go func() {
+		defer r.Store.Close()
+		if err := r.Store.Rebalance(r.logProgress); err != nil {
+			rebalanceWatcher.EndWatch(err)
+			return
+		}
+		r.Logger.Infow("Shard rebalance complete", "shards", len(r.Store.Shards))
+		rebalanceWatcher.EndWatch(nil)
+	}()
+	return rebalanceWatcher, nil
+}
+
+func (r ShardRebalanceRunner) logProgress(progress provider.ShardRebalanceProgress) {
+	r.Logger.Infow("Rebalancing table",
+		"name", progress.Feature, "variant", progress.Variant, "moved", progress.Moved,
+		"tables_rebalanced", progress.TablesRebalanced, "tables", progress.Tables,
+	)
+}
+
+// Resource is empty as rebalances move whole stores rather than a resource.
+func (r ShardRebalanceRunner) Resource() metadata.ResourceID {
+	return metadata.ResourceID{}
+}
+
+func (r ShardRebalanceRunner) IsUpdateJob() bool {
+	return false
+}
+
+type ShardRebalanceRunnerConfig struct {
+	StoreType   pt.Type
+	StoreConfig pc.SerializedConfig
+}
+
+func (r *ShardRebalanceRunnerConfig) Serialize() (Config, error) {
+	config, err := json.Marshal(r)
+	if err != nil {
+		return nil, fmt.Errorf("could not marshal shard rebalance config: %w", err)
+	}
+	return config, nil
+}
+
+func (r *ShardRebalanceRunnerConfig) Deserialize(config Config) error {
+	err := json.Unmarshal(config, r)
+	if err != nil {
+		return fmt.Errorf("could not unmarshal shard rebalance config: %w", err)
+	}
+	return nil
+}
+
+func ShardRebalanceRunnerFactory(config Config) (types.Runner, error) {
+	rebalanceConfig := &ShardRebalanceRunnerConfig{}
+	if err := rebalanceConfig.Deserialize(config); err != nil {
+		return nil, fmt.Errorf("failed to deserialize shard rebalance config: %w", err)
+	}
+	store, err := getOnlineStore(rebalanceConfig.StoreType, rebalanceConfig.StoreConfig)
+	if err != nil {
+		return nil, err
+	}
+	sharded, ok := store.(*provider.ShardedStore)
+	if !ok {
+		store.Close()
+		return nil, fmt.Errorf("%s is not a sharded online store", rebalanceConfig.StoreType)
+	}
+	return &ShardRebalanceRunner{
+		Store:  sharded,
+		Logger: logging.NewLogger("shard-rebalance"),
+	}, nil
+}
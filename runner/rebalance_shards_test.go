@@ -0,0 +1,22 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package runner
+
+import (
+	"testing"
+
+	pt "github.com/featureform/provider/provider_type"
+)
+
+func TestShardRebalanceRunnerFactoryNotSharded(t *testing.T) {
+	config := &ShardRebalanceRunnerConfig{StoreType: pt.LocalOnline}
+	serialized, err := config.Serialize()
+	if err != nil {
+		t.Fatalf("Failed to serialize config: %s", err)
+	}
+	if _, err := ShardRebalanceRunnerFactory(serialized); err == nil {
+		t.Fatalf("Expected factory with non-sharded store to fail")
+	}
+}
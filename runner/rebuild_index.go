@@ -0,0 +1,141 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package runner
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"go.uber.org/zap"
+
+	"github.com/featureform/logging"
+	"github.com/featureform/metadata"
+	"github.com/featureform/provider"
+	"github.com/featureform/types"
+
+	pc "github.com/featureform/provider/provider_config"
+	pt "github.com/featureform/provider/provider_type"
+)
+
+// IndexRebuildRunner re-creates the vector index of a materialized embedding
+// feature with a new vector type, then re-ingests its vectors from the
+// offline materialization. It's used when an embedding model upgrade changes
+// the dimension or distance metric of a feature's vectors.
+type IndexRebuildRunner struct {
+	Online  provider.OnlineStore
+	Offline provider.OfflineStore
+	ID      provider.ResourceID
+	VType   provider.VectorType
+	Cloud   JobCloud
+	// Entity and KeyRules are the feature's entity and its key rules, which
+	// are applied to every entity key as it's re-ingested.
+	Entity   string
+	KeyRules metadata.EntityKeyRules
+	Logger   *zap.SugaredLogger
+}
+
+func (r IndexRebuildRunner) Run() (types.CompletionWatcher, error) {
+	return r.RebuildIndex(r.ID.Name, r.ID.Variant, r.VType)
+}
+
+// RebuildIndex drops the feature variant's table and index, re-creates the
+// index with newType, and materializes the feature into it again. The
+// feature is missing from the online store until the rebuild completes.
+func (r IndexRebuildRunner) RebuildIndex(feature, variant string, newType provider.VectorType) (types.CompletionWatcher, error) {
+	if !newType.IsEmbedding {
+		return nil, fmt.Errorf("cannot rebuild index of non-embedding type %v", newType)
+	}
+	if _, ok := r.Online.(provider.VectorStore); !ok || !r.Online.Capabilities().Vectors {
+		return nil, fmt.Errorf("cannot rebuild index on non-vector store: %v", r.Online.Type())
+	}
+	r.Logger.Infow("Dropping Index", "name", feature, "variant", variant)
+	if err := r.Online.DeleteTable(feature, variant); err != nil {
+		if _, notFound := err.(*provider.TableNotFound); !notFound {
+			return nil, fmt.Errorf("delete table error: %w", err)
+		}
+	}
+	r.Logger.Infow("Rebuilding Index", "name", feature, "variant", variant, "dimension", newType.Dimension, "metric", newType.Metric())
+	materializeRunner := MaterializeRunner{
+		Online:   r.Online,
+		Offline:  r.Offline,
+		ID:       provider.ResourceID{Name: feature, Variant: variant, Type: provider.Feature},
+		VType:    newType,
+		IsUpdate: true,
+		Cloud:    r.Cloud,
+		Entity:   r.Entity,
+		KeyRules: r.KeyRules,
+		Logger:   r.Logger,
+	}
+	return materializeRunner.Run()
+}
+
+func (r IndexRebuildRunner) Resource() metadata.ResourceID {
+	return metadata.ResourceID{
+		Name:    r.ID.Name,
+		Variant: r.ID.Variant,
+		Type:    metadata.FEATURE_VARIANT,
+	}
+}
+
+func (r IndexRebuildRunner) IsUpdateJob() bool {
+	return true
+}
+
+type IndexRebuildRunnerConfig struct {
+	OnlineType    pt.Type
+	OfflineType   pt.Type
+	OnlineConfig  pc.SerializedConfig
+	OfflineConfig pc.SerializedConfig
+	ResourceID    provider.ResourceID
+	VType         provider.VectorType
+	Cloud         JobCloud
+	Entity        string
+	KeyRules      metadata.EntityKeyRules
+}
+
+func (r *IndexRebuildRunnerConfig) Serialize() (Config, error) {
+	config, err := json.Marshal(r)
+	if err != nil {
+		return nil, fmt.Errorf("could not marshal index rebuild config: %w", err)
+	}
+	return config, nil
+}
+
+func (r *IndexRebuildRunnerConfig) Deserialize(config Config) error {
+	err := json.Unmarshal(config, r)
+	if err != nil {
+		return fmt.Errorf("could not unmarshal index rebuild config: %w", err)
+	}
+	return nil
+}
+
+func IndexRebuildRunnerFactory(config Config) (types.Runner, error) {
+	rebuildConfig := &IndexRebuildRunnerConfig{}
+	if err := rebuildConfig.Deserialize(config); err != nil {
+		return nil, fmt.Errorf("failed to deserialize index rebuild config: %w", err)
+	}
+	online, err := getOnlineStore(rebuildConfig.OnlineType, rebuildConfig.OnlineConfig)
+	if err != nil {
+		return nil, err
+	}
+	offlineProvider, err := provider.Get(rebuildConfig.OfflineType, rebuildConfig.OfflineConfig)
+	if err != nil {
+		return nil, fmt.Errorf("failed to configure offline provider: %w", err)
+	}
+	offline, err := offlineProvider.AsOfflineStore()
+	if err != nil {
+		return nil, fmt.Errorf("failed to convert provider to offline store: %w", err)
+	}
+	return &IndexRebuildRunner{
+		Online:   online,
+		Offline:  offline,
+		ID:       rebuildConfig.ResourceID,
+		VType:    rebuildConfig.VType,
+		Cloud:    rebuildConfig.Cloud,
+		Entity:   rebuildConfig.Entity,
+		KeyRules: rebuildConfig.KeyRules,
+		Logger:   logging.NewLogger("index-rebuild"),
+	}, nil
+}
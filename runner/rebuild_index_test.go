@@ -0,0 +1,125 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package runner
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap/zaptest"
+
+	"github.com/featureform/provider"
+	"github.com/featureform/types"
+)
+
+type vectorOnlineStore struct {
+	bulkLoadOnlineStore
+	deleted bool
+	index   provider.VectorType
+}
+
+func (m *vectorOnlineStore) Capabilities() provider.OnlineCapabilities {
+	return provider.OnlineCapabilities{BulkLoad: true, Vectors: true}
+}
+
+func (m *vectorOnlineStore) DeleteTable(feature, variant string) error {
+	m.deleted = true
+	return nil
+}
+
+func (m *vectorOnlineStore) CreateIndex(feature, variant string, vectorType provider.VectorType) (provider.VectorStoreTable, error) {
+	if !m.deleted {
+		return nil, &provider.TableAlreadyExists{Feature: feature, Variant: variant}
+	}
+	m.index = vectorType
+	return nil, nil
+}
+
+type indexBuildingOnlineStore struct {
+	*vectorOnlineStore
+	watched bool
+}
+
+func (m *indexBuildingOnlineStore) Capabilities() provider.OnlineCapabilities {
+	return provider.OnlineCapabilities{BulkLoad: true, Vectors: true, AsyncIndexBuilds: true}
+}
+
+func (m *indexBuildingOnlineStore) WatchIndexBuild(feature, variant string) (types.CompletionWatcher, error) {
+	if len(m.loaded) == 0 {
+		return nil, fmt.Errorf("index watched before vectors were loaded")
+	}
+	m.watched = true
+	return mockCompletionWatcher{}, nil
+}
+
+type updatedOfflineStore struct {
+	materializedOfflineStore
+}
+
+func (m updatedOfflineStore) UpdateMaterialization(id provider.ResourceID) (provider.Materialization, error) {
+	return m.materialized, nil
+}
+
+func TestIndexRebuildRunner(t *testing.T) {
+	online := &vectorOnlineStore{bulkLoadOnlineStore: bulkLoadOnlineStore{loaded: make(map[string]interface{})}}
+	offline := updatedOfflineStore{materializedOfflineStore{materialized: &MockMaterializedFeatures{
+		id:   provider.MaterializationID(uuid.NewString()),
+		Rows: []provider.ResourceRecord{{Entity: "a", Value: []float32{1, 0, 0}}},
+	}}}
+	newType := provider.VectorType{ScalarType: provider.Float32, Dimension: 3, IsEmbedding: true, DistanceMetric: provider.L2Distance}
+	rebuild := IndexRebuildRunner{
+		Online:  online,
+		Offline: offline,
+		ID:      provider.ResourceID{Name: "embedding", Variant: "v1", Type: provider.Feature},
+		VType:   newType,
+		Cloud:   LocalMaterializeRunner,
+		Logger:  zaptest.NewLogger(t).Sugar(),
+	}
+	watcher, err := rebuild.Run()
+	if err != nil {
+		t.Fatalf("Failed to rebuild index: %v", err)
+	}
+	if err := watcher.Wait(); err != nil {
+		t.Fatalf("Failed to re-ingest vectors: %v", err)
+	}
+	if !reflect.DeepEqual(online.index, newType) {
+		t.Fatalf("Expected index to be re-created with %v, got %v", newType, online.index)
+	}
+	if !reflect.DeepEqual(online.loaded["a"], []float32{1, 0, 0}) {
+		t.Fatalf("Expected vectors to be re-ingested, got %v", online.loaded)
+	}
+	nonEmbedding := provider.VectorType{ScalarType: provider.Float32, Dimension: 3}
+	if _, err := rebuild.RebuildIndex("embedding", "v1", nonEmbedding); err == nil {
+		t.Fatalf("Succeeded in rebuilding the index of a non-embedding type")
+	}
+}
+
+func TestIndexRebuildRunnerWaitsForIndex(t *testing.T) {
+	online := &indexBuildingOnlineStore{vectorOnlineStore: &vectorOnlineStore{bulkLoadOnlineStore: bulkLoadOnlineStore{loaded: make(map[string]interface{})}}}
+	offline := updatedOfflineStore{materializedOfflineStore{materialized: &MockMaterializedFeatures{
+		id:   provider.MaterializationID(uuid.NewString()),
+		Rows: []provider.ResourceRecord{{Entity: "a", Value: []float32{1, 0, 0}}},
+	}}}
+	rebuild := IndexRebuildRunner{
+		Online:  online,
+		Offline: offline,
+		ID:      provider.ResourceID{Name: "embedding", Variant: "v1", Type: provider.Feature},
+		VType:   provider.VectorType{ScalarType: provider.Float32, Dimension: 3, IsEmbedding: true},
+		Cloud:   LocalMaterializeRunner,
+		Logger:  zaptest.NewLogger(t).Sugar(),
+	}
+	watcher, err := rebuild.Run()
+	if err != nil {
+		t.Fatalf("Failed to rebuild index: %v", err)
+	}
+	if err := watcher.Wait(); err != nil {
+		t.Fatalf("Failed to wait for index: %v", err)
+	}
+	if !online.watched {
+		t.Fatalf("Expected the rebuild to wait for the index to build")
+	}
+}
@@ -27,6 +27,9 @@ func (m TrainingSetRunner) Run() (types.CompletionWatcher, error) {
 		ResultSync:  &ResultSync{},
 		DoneChannel: done,
 	}
+	// The offline store doesn't report how many rows it wrote, so only the
+	// duration and outcome are recorded.
+	newRunMetrics(CREATE_TRAINING_SET, m.Def.ID).watch(trainingSetWatcher)
 	go func() {
 		if !m.IsUpdate {
 			if err := m.Offline.CreateTrainingSet(m.Def); err != nil {
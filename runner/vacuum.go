@@ -0,0 +1,116 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package runner
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"go.uber.org/zap"
+
+	"github.com/featureform/logging"
+	"github.com/featureform/metadata"
+	"github.com/featureform/provider"
+	"github.com/featureform/types"
+
+	pc "github.com/featureform/provider/provider_config"
+	pt "github.com/featureform/provider/provider_type"
+)
+
+// VacuumRunner removes the orphaned resources of an offline store, and logs
+// each of them and the storage reclaimed.
+type VacuumRunner struct {
+	Offline  provider.OfflineStore
+	Provider string
+	Config   provider.VacuumConfig
+	Logger   *zap.SugaredLogger
+}
+
+func (v VacuumRunner) Run() (types.CompletionWatcher, error) {
+	done := make(chan interface{})
+	vacuumWatcher := &SyncWatcher{
+		ResultSync:  &ResultSync{},
+		DoneChannel: done,
+	}
+	go func() {
+		defer v.Offline.Close()
+		store, ok := v.Offline.(provider.VacuumableOfflineStore)
+		if !ok {
+			vacuumWatcher.EndWatch(&provider.VacuumNotSupported{ProviderType: string(v.Offline.Type())})
+			return
+		}
+		result, err := store.Vacuum(v.Config)
+		for _, resource := range result.Resources {
+			v.Logger.Infow("Orphaned resource",
+				"provider", v.Provider, "type", resource.ID.Type.String(), "name", resource.ID.Name, "variant", resource.ID.Variant,
+				"location", resource.Location, "files", resource.Files, "bytes", resource.Bytes,
+			)
+		}
+		if err != nil {
+			vacuumWatcher.EndWatch(fmt.Errorf("could not vacuum %s: %w", v.Provider, err))
+			return
+		}
+		v.Logger.Infow("Offline store vacuum complete",
+			"provider", v.Provider, "resources", len(result.Resources), "reclaimed_bytes", result.ReclaimedBytes, "dry_run", v.Config.DryRun,
+		)
+		vacuumWatcher.EndWatch(nil)
+	}()
+	return vacuumWatcher, nil
+}
+
+func (v VacuumRunner) Resource() metadata.ResourceID {
+	return metadata.ResourceID{Name: v.Provider, Type: metadata.PROVIDER}
+}
+
+// IsUpdateJob is true as a vacuum only removes resources that metadata no
+// longer has, so it shouldn't change the status of any.
+func (v VacuumRunner) IsUpdateJob() bool {
+	return true
+}
+
+type VacuumRunnerConfig struct {
+	OfflineType   pt.Type
+	OfflineConfig pc.SerializedConfig
+	// Provider is the name of the offline provider.
+	Provider     string
+	VacuumConfig provider.VacuumConfig
+}
+
+func (v *VacuumRunnerConfig) Serialize() (Config, error) {
+	config, err := json.Marshal(v)
+	if err != nil {
+		return nil, fmt.Errorf("could not marshal vacuum config: %w", err)
+	}
+	return config, nil
+}
+
+func (v *VacuumRunnerConfig) Deserialize(config Config) error {
+	err := json.Unmarshal(config, v)
+	if err != nil {
+		return fmt.Errorf("could not unmarshal vacuum config: %w", err)
+	}
+	return nil
+}
+
+func VacuumRunnerFactory(config Config) (types.Runner, error) {
+	vacuumConfig := &VacuumRunnerConfig{}
+	if err := vacuumConfig.Deserialize(config); err != nil {
+		return nil, fmt.Errorf("failed to deserialize vacuum config: %w", err)
+	}
+	offlineProvider, err := provider.Get(vacuumConfig.OfflineType, vacuumConfig.OfflineConfig)
+	if err != nil {
+		return nil, fmt.Errorf("failed to configure offline provider: %w", err)
+	}
+	offlineStore, err := offlineProvider.AsOfflineStore()
+	if err != nil {
+		return nil, fmt.Errorf("failed to convert provider to offline store: %w", err)
+	}
+	return &VacuumRunner{
+		Offline:  offlineStore,
+		Provider: vacuumConfig.Provider,
+		Config:   vacuumConfig.VacuumConfig,
+		Logger:   logging.NewLogger("vacuum"),
+	}, nil
+}
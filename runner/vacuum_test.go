@@ -0,0 +1,101 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package runner
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+	"time"
+
+	"go.uber.org/zap/zaptest"
+
+	"github.com/featureform/metadata"
+	"github.com/featureform/provider"
+	pt "github.com/featureform/provider/provider_type"
+)
+
+type MockVacuumableOfflineStore struct {
+	MockOfflineStore
+	vacuumed *provider.VacuumConfig
+	err      error
+}
+
+func (m MockVacuumableOfflineStore) Vacuum(config provider.VacuumConfig) (provider.VacuumResult, error) {
+	*m.vacuumed = config
+	return provider.VacuumResult{
+		Resources:      []provider.VacuumedResource{{ID: provider.ResourceID{Name: "deleted", Variant: "v1", Type: provider.FeatureMaterialization}, Files: 2, Bytes: 100}},
+		ReclaimedBytes: 100,
+	}, m.err
+}
+
+func TestVacuumRunner(t *testing.T) {
+	config := provider.VacuumConfig{
+		Live:   []provider.ResourceID{{Name: "live", Variant: "v1", Type: provider.Feature}},
+		MinAge: time.Hour,
+	}
+	var vacuumed provider.VacuumConfig
+	runner := VacuumRunner{
+		Offline:  MockVacuumableOfflineStore{vacuumed: &vacuumed},
+		Provider: "spark",
+		Config:   config,
+		Logger:   zaptest.NewLogger(t).Sugar(),
+	}
+	watcher, err := runner.Run()
+	if err != nil {
+		t.Fatalf("failed to run vacuum runner: %v", err)
+	}
+	if err := watcher.Wait(); err != nil {
+		t.Fatalf("vacuum runner failed: %v", err)
+	}
+	if !reflect.DeepEqual(vacuumed, config) {
+		t.Fatalf("expected %v to be vacuumed, got %v", config, vacuumed)
+	}
+	expectedID := metadata.ResourceID{Name: "spark", Type: metadata.PROVIDER}
+	if runner.Resource() != expectedID {
+		t.Fatalf("expected resource %v, got %v", expectedID, runner.Resource())
+	}
+}
+
+func TestVacuumRunnerFail(t *testing.T) {
+	var vacuumed provider.VacuumConfig
+	runners := []VacuumRunner{
+		{Offline: MockVacuumableOfflineStore{vacuumed: &vacuumed, err: fmt.Errorf("vacuum failed")}},
+		{Offline: MockOfflineStore{}},
+	}
+	for _, runner := range runners {
+		runner.Logger = zaptest.NewLogger(t).Sugar()
+		watcher, err := runner.Run()
+		if err != nil {
+			t.Fatalf("failed to run vacuum runner: %v", err)
+		}
+		if err := watcher.Wait(); err == nil {
+			t.Fatalf("failed to report vacuum error for %T", runner.Offline)
+		}
+	}
+}
+
+func TestVacuumRunnerConfigRoundTrip(t *testing.T) {
+	config := &VacuumRunnerConfig{
+		OfflineType: pt.SparkOffline,
+		Provider:    "spark",
+		VacuumConfig: provider.VacuumConfig{
+			Live:   []provider.ResourceID{{Name: "live", Variant: "v1", Type: provider.Transformation}},
+			MinAge: 24 * time.Hour,
+			DryRun: true,
+		},
+	}
+	serialized, err := config.Serialize()
+	if err != nil {
+		t.Fatalf("failed to serialize config: %v", err)
+	}
+	deserialized := &VacuumRunnerConfig{}
+	if err := deserialized.Deserialize(serialized); err != nil {
+		t.Fatalf("failed to deserialize config: %v", err)
+	}
+	if !reflect.DeepEqual(config, deserialized) {
+		t.Fatalf("expected %v, got %v", config, deserialized)
+	}
+}
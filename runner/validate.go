@@ -0,0 +1,339 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package runner
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+
+	"go.uber.org/zap"
+
+	"github.com/featureform/logging"
+	"github.com/featureform/metadata"
+	"github.com/featureform/provider"
+	"github.com/featureform/types"
+
+	pc "github.com/featureform/provider/provider_config"
+	pt "github.com/featureform/provider/provider_type"
+)
+
+// Feature properties that declare the expectations its materialization has
+// to meet before it's copied online. ExpectMaxNullRateProperty is the largest
+// fraction of values that can be null. ExpectMinValueProperty and
+// ExpectMaxValueProperty bound numeric values. ExpectUniqueValuesProperty
+// requires that no two entities have the same value.
+// ExpectMaxRowCountDeltaProperty is the largest fraction that an update's
+// number of rows can change by from the materialization it replaces.
+const (
+	ExpectMaxNullRateProperty      = "expect_max_null_rate"
+	ExpectMinValueProperty         = "expect_min_value"
+	ExpectMaxValueProperty         = "expect_max_value"
+	ExpectUniqueValuesProperty     = "expect_unique_values"
+	ExpectMaxRowCountDeltaProperty = "expect_max_row_count_delta"
+)
+
+// Expectations are the thresholds that a materialization is validated
+// against. Nil thresholds aren't checked.
+type Expectations struct {
+	MaxNullRate      *float64
+	MinValue         *float64
+	MaxValue         *float64
+	UniqueValues     bool
+	MaxRowCountDelta *float64
+}
+
+// IsZero is true if there's nothing to check.
+func (e Expectations) IsZero() bool {
+	return e.MaxNullRate == nil && e.MinValue == nil && e.MaxValue == nil && !e.UniqueValues && e.MaxRowCountDelta == nil
+}
+
+// ExpectationsFromProperties returns the expectations declared in a
+// feature's properties.
+func ExpectationsFromProperties(properties metadata.Properties) (Expectations, error) {
+	expectations := Expectations{}
+	thresholds := []struct {
+		property  string
+		threshold **float64
+	}{
+		{ExpectMaxNullRateProperty, &expectations.MaxNullRate},
+		{ExpectMinValueProperty, &expectations.MinValue},
+		{ExpectMaxValueProperty, &expectations.MaxValue},
+		{ExpectMaxRowCountDeltaProperty, &expectations.MaxRowCountDelta},
+	}
+	for _, t := range thresholds {
+		value := strings.TrimSpace(properties[t.property])
+		if value == "" {
+			continue
+		}
+		threshold, err := strconv.ParseFloat(value, 64)
+		if err != nil || math.IsNaN(threshold) {
+			return Expectations{}, fmt.Errorf("invalid %s %q: not a number", t.property, value)
+		}
+		*t.threshold = &threshold
+	}
+	if value := strings.TrimSpace(properties[ExpectUniqueValuesProperty]); value != "" {
+		unique, err := strconv.ParseBool(value)
+		if err != nil {
+			return Expectations{}, fmt.Errorf("invalid %s %q: %w", ExpectUniqueValuesProperty, value, err)
+		}
+		expectations.UniqueValues = unique
+	}
+	if expectations.MaxNullRate != nil && (*expectations.MaxNullRate < 0 || *expectations.MaxNullRate > 1) {
+		return Expectations{}, fmt.Errorf("invalid %s: must be between 0 and 1", ExpectMaxNullRateProperty)
+	}
+	if expectations.MaxRowCountDelta != nil && *expectations.MaxRowCountDelta < 0 {
+		return Expectations{}, fmt.Errorf("invalid %s: must not be negative", ExpectMaxRowCountDeltaProperty)
+	}
+	if expectations.MinValue != nil && expectations.MaxValue != nil && *expectations.MinValue > *expectations.MaxValue {
+		return Expectations{}, fmt.Errorf("invalid %s: greater than %s", ExpectMinValueProperty, ExpectMaxValueProperty)
+	}
+	return expectations, nil
+}
+
+// ExpectationViolation is an expectation that a materialization didn't meet.
+type ExpectationViolation struct {
+	// Expectation is the feature property that declared it.
+	Expectation string
+	Threshold   float64
+	Actual      float64
+	// Examples are some of the entities whose values violated it, if it's
+	// checked per value.
+	Examples []string
+}
+
+func (v ExpectationViolation) String() string {
+	s := fmt.Sprintf("%s: expected %g, got %g", v.Expectation, v.Threshold, v.Actual)
+	if len(v.Examples) > 0 {
+		s += fmt.Sprintf(" (entities %s)", strings.Join(v.Examples, ", "))
+	}
+	return s
+}
+
+// ExpectationsNotMet is returned when a materialization violates its
+// feature's expectations.
+type ExpectationsNotMet struct {
+	ID         provider.ResourceID
+	Violations []ExpectationViolation
+}
+
+func (err *ExpectationsNotMet) Error() string {
+	violations := make([]string, len(err.Violations))
+	for i, violation := range err.Violations {
+		violations[i] = violation.String()
+	}
+	return fmt.Sprintf("materialization of %s %s did not meet %d expectations: %s", err.ID.Name, err.ID.Variant, len(err.Violations), strings.Join(violations, "; "))
+}
+
+// maxViolationExamples is how many entities are reported per violation.
+const maxViolationExamples = 5
+
+// validateMaterialization reads every row of materialization and checks it
+// against expectations. previousRows is the number of rows of the
+// materialization that it replaces, or 0 if there isn't one, in which case
+// the row count delta isn't checked. Unique values are checked with a set of
+// every value, so they need memory in proportion to the materialization.
+func validateMaterialization(ctx context.Context, id provider.ResourceID, materialization provider.Materialization, expectations Expectations, previousRows int64) error {
+	numRows, err := materialization.NumRows()
+	if err != nil {
+		return fmt.Errorf("num rows: %w", err)
+	}
+	it, err := materialization.IterateSegment(0, numRows)
+	if err != nil {
+		return fmt.Errorf("iterate materialization: %w", err)
+	}
+	defer it.Close()
+	var rows, nulls int64
+	min, max := math.Inf(1), math.Inf(-1)
+	var belowMin, aboveMax, duplicates []string
+	var seen map[string]struct{}
+	if expectations.UniqueValues {
+		seen = make(map[string]struct{})
+	}
+	for it.Next() {
+		if rows%1000 == 0 {
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+		}
+		rows++
+		record := it.Value()
+		if record.Value == nil {
+			nulls++
+			continue
+		}
+		if value, ok := numericValue(record.Value); ok {
+			if value < min {
+				min = value
+			}
+			if value > max {
+				max = value
+			}
+			if expectations.MinValue != nil && value < *expectations.MinValue && len(belowMin) < maxViolationExamples {
+				belowMin = append(belowMin, record.Entity)
+			}
+			if expectations.MaxValue != nil && value > *expectations.MaxValue && len(aboveMax) < maxViolationExamples {
+				aboveMax = append(aboveMax, record.Entity)
+			}
+		}
+		if seen != nil {
+			key := fmt.Sprintf("%T:%v", record.Value, record.Value)
+			if _, ok := seen[key]; ok {
+				if len(duplicates) < maxViolationExamples {
+					duplicates = append(duplicates, record.Entity)
+				}
+			} else {
+				seen[key] = struct{}{}
+			}
+		}
+	}
+	if err := it.Err(); err != nil {
+		return fmt.Errorf("iterate materialization: %w", err)
+	}
+	var violations []ExpectationViolation
+	if expectations.MaxNullRate != nil && rows > 0 {
+		if rate := float64(nulls) / float64(rows); rate > *expectations.MaxNullRate {
+			violations = append(violations, ExpectationViolation{Expectation: ExpectMaxNullRateProperty, Threshold: *expectations.MaxNullRate, Actual: rate})
+		}
+	}
+	if len(belowMin) > 0 {
+		violations = append(violations, ExpectationViolation{Expectation: ExpectMinValueProperty, Threshold: *expectations.MinValue, Actual: min, Examples: belowMin})
+	}
+	if len(aboveMax) > 0 {
+		violations = append(violations, ExpectationViolation{Expectation: ExpectMaxValueProperty, Threshold: *expectations.MaxValue, Actual: max, Examples: aboveMax})
+	}
+	if len(duplicates) > 0 {
+		violations = append(violations, ExpectationViolation{Expectation: ExpectUniqueValuesProperty, Threshold: float64(rows - nulls), Actual: float64(len(seen)), Examples: duplicates})
+	}
+	if expectations.MaxRowCountDelta != nil && previousRows > 0 {
+		if delta := math.Abs(float64(rows-previousRows)) / float64(previousRows); delta > *expectations.MaxRowCountDelta {
+			violations = append(violations, ExpectationViolation{Expectation: ExpectMaxRowCountDeltaProperty, Threshold: *expectations.MaxRowCountDelta, Actual: delta})
+		}
+	}
+	if len(violations) > 0 {
+		return &ExpectationsNotMet{ID: id, Violations: violations}
+	}
+	return nil
+}
+
+func numericValue(value interface{}) (float64, bool) {
+	switch v := value.(type) {
+	case int:
+		return float64(v), true
+	case int32:
+		return float64(v), true
+	case int64:
+		return float64(v), true
+	case float32:
+		return float64(v), true
+	case float64:
+		return v, true
+	default:
+		return 0, false
+	}
+}
+
+// ValidationRunner checks a materialization against its feature's
+// expectations, and fails with ExpectationsNotMet if any are violated. It
+// doesn't write anywhere, so it can be run on a materialization before
+// deciding whether to copy it online.
+type ValidationRunner struct {
+	Offline         provider.OfflineStore
+	ID              provider.ResourceID
+	Materialization provider.MaterializationID
+	Expectations    Expectations
+	// PreviousRows is the number of rows of the materialization being
+	// replaced, or 0 to skip the row count delta.
+	PreviousRows int64
+	Logger       *zap.SugaredLogger
+}
+
+func (r ValidationRunner) Run() (types.CompletionWatcher, error) {
+	return r.RunContext(context.Background())
+}
+
+func (r ValidationRunner) RunContext(ctx context.Context) (types.CompletionWatcher, error) {
+	materialization, err := r.Offline.GetMaterialization(r.Materialization)
+	if err != nil {
+		return nil, fmt.Errorf("get materialization: %w", err)
+	}
+	done := make(chan interface{})
+	validationWatcher := &SyncWatcher{
+		ResultSync:  &ResultSync{},
+		DoneChannel: done,
+	}
+	go func() {
+		r.Logger.Infow("Validating Materialization", "name", r.ID.Name, "variant", r.ID.Variant, "materialization", r.Materialization)
+		err := validateMaterialization(ctx, r.ID, materialization, r.Expectations, r.PreviousRows)
+		if err != nil {
+			r.Logger.Errorw("Materialization failed validation", "name", r.ID.Name, "variant", r.ID.Variant, "error", err)
+		}
+		validationWatcher.EndWatch(err)
+	}()
+	return validationWatcher, nil
+}
+
+func (r ValidationRunner) Resource() metadata.ResourceID {
+	return metadata.ResourceID{
+		Name:    r.ID.Name,
+		Variant: r.ID.Variant,
+		Type:    metadata.FEATURE_VARIANT,
+	}
+}
+
+func (r ValidationRunner) IsUpdateJob() bool {
+	return false
+}
+
+type ValidationRunnerConfig struct {
+	OfflineType     pt.Type
+	OfflineConfig   pc.SerializedConfig
+	ResourceID      provider.ResourceID
+	Materialization provider.MaterializationID
+	Expectations    Expectations
+	PreviousRows    int64
+}
+
+func (c *ValidationRunnerConfig) Serialize() (Config, error) {
+	config, err := json.Marshal(c)
+	if err != nil {
+		return nil, fmt.Errorf("could not marshal validation config: %w", err)
+	}
+	return config, nil
+}
+
+func (c *ValidationRunnerConfig) Deserialize(config Config) error {
+	err := json.Unmarshal(config, c)
+	if err != nil {
+		return fmt.Errorf("could not unmarshal validation config: %w", err)
+	}
+	return nil
+}
+
+func ValidationRunnerFactory(config Config) (types.Runner, error) {
+	validationConfig := &ValidationRunnerConfig{}
+	if err := validationConfig.Deserialize(config); err != nil {
+		return nil, fmt.Errorf("failed to deserialize validation config: %w", err)
+	}
+	offlineProvider, err := provider.Get(validationConfig.OfflineType, validationConfig.OfflineConfig)
+	if err != nil {
+		return nil, fmt.Errorf("failed to configure offline provider: %w", err)
+	}
+	offline, err := offlineProvider.AsOfflineStore()
+	if err != nil {
+		return nil, fmt.Errorf("failed to convert provider to offline store: %w", err)
+	}
+	return &ValidationRunner{
+		Offline:         offline,
+		ID:              validationConfig.ResourceID,
+		Materialization: validationConfig.Materialization,
+		Expectations:    validationConfig.Expectations,
+		PreviousRows:    validationConfig.PreviousRows,
+		Logger:          logging.NewLogger("validation"),
+	}, nil
+}
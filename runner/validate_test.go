@@ -0,0 +1,140 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package runner
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap/zaptest"
+
+	"github.com/featureform/metadata"
+	"github.com/featureform/provider"
+	"github.com/featureform/types"
+)
+
+func threshold(v float64) *float64 {
+	return &v
+}
+
+func TestExpectationsFromProperties(t *testing.T) {
+	expectations, err := ExpectationsFromProperties(metadata.Properties{
+		ExpectMaxNullRateProperty:      " 0.1 ",
+		ExpectMinValueProperty:         "-5",
+		ExpectMaxValueProperty:         "5",
+		ExpectUniqueValuesProperty:     "true",
+		ExpectMaxRowCountDeltaProperty: "0.5",
+	})
+	if err != nil {
+		t.Fatalf("Failed to parse expectations: %s", err)
+	}
+	if *expectations.MaxNullRate != 0.1 || *expectations.MinValue != -5 || *expectations.MaxValue != 5 || !expectations.UniqueValues || *expectations.MaxRowCountDelta != 0.5 {
+		t.Fatalf("Unexpected expectations: %+v", expectations)
+	}
+	if expectations, err := ExpectationsFromProperties(metadata.Properties{}); err != nil || !expectations.IsZero() {
+		t.Fatalf("Expected no expectations, got %+v, %v", expectations, err)
+	}
+	invalid := []metadata.Properties{
+		{ExpectMaxNullRateProperty: "1.5"},
+		{ExpectMinValueProperty: "low"},
+		{ExpectMinValueProperty: "2", ExpectMaxValueProperty: "1"},
+		{ExpectUniqueValuesProperty: "sometimes"},
+		{ExpectMaxRowCountDeltaProperty: "-1"},
+	}
+	for _, properties := range invalid {
+		if _, err := ExpectationsFromProperties(properties); err == nil {
+			t.Fatalf("Expected %v to be invalid", properties)
+		}
+	}
+}
+
+func TestValidateMaterialization(t *testing.T) {
+	materialization := &MockMaterializedFeatures{
+		Rows: []provider.ResourceRecord{
+			{Entity: "a", Value: 1},
+			{Entity: "b", Value: 1},
+			{Entity: "c", Value: 10},
+			{Entity: "d", Value: nil},
+		},
+	}
+	id := provider.ResourceID{Name: "feature", Variant: "variant", Type: provider.Feature}
+	type testCase struct {
+		name         string
+		expectations Expectations
+		previousRows int64
+		violated     []string
+	}
+	cases := []testCase{
+		{"met", Expectations{MaxNullRate: threshold(0.25), MinValue: threshold(0), MaxValue: threshold(10), MaxRowCountDelta: threshold(0)}, 4, nil},
+		{"null rate", Expectations{MaxNullRate: threshold(0.2)}, 0, []string{ExpectMaxNullRateProperty}},
+		{"range", Expectations{MinValue: threshold(2), MaxValue: threshold(5)}, 0, []string{ExpectMinValueProperty, ExpectMaxValueProperty}},
+		{"unique", Expectations{UniqueValues: true}, 0, []string{ExpectUniqueValuesProperty}},
+		{"row count delta", Expectations{MaxRowCountDelta: threshold(0.5)}, 10, []string{ExpectMaxRowCountDeltaProperty}},
+		{"no previous rows", Expectations{MaxRowCountDelta: threshold(0.5)}, 0, nil},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			err := validateMaterialization(context.Background(), id, materialization, c.expectations, c.previousRows)
+			if c.violated == nil {
+				if err != nil {
+					t.Fatalf("Expected expectations to be met, got %v", err)
+				}
+				return
+			}
+			notMet := &ExpectationsNotMet{}
+			if !errors.As(err, &notMet) {
+				t.Fatalf("Expected expectations not to be met, got %v", err)
+			}
+			if len(notMet.Violations) != len(c.violated) {
+				t.Fatalf("Expected %d violations, got %+v", len(c.violated), notMet.Violations)
+			}
+			for i, violation := range notMet.Violations {
+				if violation.Expectation != c.violated[i] {
+					t.Fatalf("Expected %s to be violated, got %+v", c.violated[i], violation)
+				}
+			}
+		})
+	}
+}
+
+func TestMaterializeRunnerFailsExpectations(t *testing.T) {
+	materialized := &MockMaterializedFeatures{
+		id:   provider.MaterializationID(uuid.NewString()),
+		Rows: []provider.ResourceRecord{{Entity: "a", Value: 1}, {Entity: "b", Value: -1}},
+	}
+	online := provider.NewLocalOnlineStore()
+	materializeRunner := MaterializeRunner{
+		Online:       online,
+		Offline:      materializedOfflineStore{materialized: materialized},
+		ID:           provider.ResourceID{Name: "test", Variant: "test", Type: provider.Feature},
+		VType:        provider.Int,
+		Cloud:        LocalMaterializeRunner,
+		Expectations: Expectations{MinValue: threshold(0)},
+		Logger:       zaptest.NewLogger(t).Sugar(),
+	}
+	delete(factoryMap, string(COPY_TO_ONLINE))
+	defer delete(factoryMap, string(COPY_TO_ONLINE))
+	copyChunk := func(config Config) (types.Runner, error) {
+		t.Fatalf("Expected an invalid materialization not to be copied")
+		return nil, nil
+	}
+	if err := RegisterFactory(string(COPY_TO_ONLINE), copyChunk); err != nil {
+		t.Fatalf("Failed to register factory: %v", err)
+	}
+	_, err := materializeRunner.Run()
+	notMet := &ExpectationsNotMet{}
+	if !errors.As(err, &notMet) || len(notMet.Violations) != 1 {
+		t.Fatalf("Expected the min value to be violated, got %v", err)
+	}
+	if examples := notMet.Violations[0].Examples; len(examples) != 1 || examples[0] != "b" {
+		t.Fatalf("Expected entity b to be reported, got %v", examples)
+	}
+	notFound := &provider.TableNotFound{}
+	if _, err := online.GetTable("test", "test"); !errors.As(err, &notFound) {
+		t.Fatalf("Expected no table to be created, got %v", err)
+	}
+}
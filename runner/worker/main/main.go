@@ -10,18 +10,11 @@ import (
 	"log"
 )
 
+// Worker images with custom runners build a main like this one that also
+// registers their runners' factories.
 func init() {
-	if err := runner.RegisterFactory(string(runner.CREATE_TRAINING_SET), runner.TrainingSetRunnerFactory); err != nil {
-		log.Fatalf("Failed to register training set runner factory: %v", err)
-	}
-	if err := runner.RegisterFactory(string(runner.COPY_TO_ONLINE), runner.MaterializedChunkRunnerFactory); err != nil {
-		log.Fatalf("Failed to register copy to online runner factory: %v", err)
-	}
-	if err := runner.RegisterFactory(string(runner.MATERIALIZE), runner.MaterializeRunnerFactory); err != nil {
-		log.Fatalf("Failed to register materialize runner factory: %v", err)
-	}
-	if err := runner.RegisterFactory(string(runner.CREATE_TRANSFORMATION), runner.CreateTransformationRunnerFactory); err != nil {
-		log.Fatalf("Failed to register create transformation runner factory: %v", err)
+	if err := runner.RegisterBuiltinFactories(); err != nil {
+		log.Fatalf("Failed to register runner factories: %v", err)
 	}
 }
 
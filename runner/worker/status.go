@@ -0,0 +1,105 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package worker
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/featureform/metadata"
+	"github.com/featureform/types"
+)
+
+const (
+	// terminationLogPath is where Kubernetes reads a container's termination
+	// message from. It's reported in the pod's status, so the coordinator
+	// can read the job's status without fetching its logs.
+	terminationLogPath = "/dev/termination-log"
+	// maxStatusErrorLength keeps the status within the 4096 bytes Kubernetes
+	// keeps of a termination message.
+	maxStatusErrorLength = 2048
+)
+
+const (
+	JobSucceeded = "succeeded"
+	JobFailed    = "failed"
+)
+
+// JobStatus is the outcome of a worker's job, written as JSON when the job
+// completes so failures can be diagnosed without searching its logs.
+type JobStatus struct {
+	Runner  string `json:"runner"`
+	Name    string `json:"name,omitempty"`
+	Variant string `json:"variant,omitempty"`
+	Type    string `json:"type,omitempty"`
+	Index   *int   `json:"index,omitempty"`
+	Status  string `json:"status"`
+	Error   string `json:"error,omitempty"`
+	// RowsWritten, ChunksDone and ChunksTotal are the job's progress when it
+	// completed. Runners that don't report progress count as one chunk.
+	RowsWritten     int64     `json:"rows_written"`
+	ChunksDone      int64     `json:"chunks_done"`
+	ChunksTotal     int64     `json:"chunks_total"`
+	Started         time.Time `json:"started"`
+	Completed       time.Time `json:"completed"`
+	DurationSeconds float64   `json:"duration_seconds"`
+}
+
+func newJobStatus(runner string, started time.Time) *JobStatus {
+	return &JobStatus{Runner: runner, Started: started}
+}
+
+func (s *JobStatus) setResource(id metadata.ResourceID) {
+	s.Name = id.Name
+	s.Variant = id.Variant
+	s.Type = id.Type.String()
+}
+
+func (s *JobStatus) setProgress(watcher types.CompletionWatcher) {
+	progress := types.ProgressOf(watcher)
+	s.RowsWritten = progress.RowsWritten
+	s.ChunksDone = progress.ChunksDone
+	s.ChunksTotal = progress.ChunksTotal
+}
+
+func (s *JobStatus) finish(err error, completed time.Time) {
+	s.Completed = completed
+	s.DurationSeconds = completed.Sub(s.Started).Seconds()
+	if err == nil {
+		s.Status = JobSucceeded
+		return
+	}
+	s.Status = JobFailed
+	s.Error = err.Error()
+	if len(s.Error) > maxStatusErrorLength {
+		s.Error = s.Error[:maxStatusErrorLength] + "..."
+	}
+}
+
+// statusPath returns where the job's status is written: STATUS_PATH if it's
+// set, or else the termination log if the worker runs in Kubernetes. It
+// returns false if there's nowhere to write it.
+func statusPath() (string, bool) {
+	if path, ok := os.LookupEnv("STATUS_PATH"); ok {
+		return path, path != ""
+	}
+	if _, err := os.Stat(terminationLogPath); err == nil {
+		return terminationLogPath, true
+	}
+	return "", false
+}
+
+func writeJobStatus(path string, status *JobStatus) error {
+	serialized, err := json.Marshal(status)
+	if err != nil {
+		return fmt.Errorf("could not serialize job status: %w", err)
+	}
+	if err := os.WriteFile(path, serialized, 0644); err != nil {
+		return fmt.Errorf("could not write job status to %s: %w", path, err)
+	}
+	return nil
+}
@@ -20,8 +20,22 @@ import (
 
 type Config []byte
 
+// CreateAndRun runs the job of the runner named by NAME. Its status is
+// written as JSON once it completes, whether or not it succeeded.
 func CreateAndRun() error {
 	logger := zap.NewExample().Sugar()
+	status := newJobStatus(os.Getenv("NAME"), time.Now())
+	err := run(logger, status)
+	status.finish(err, time.Now())
+	if path, ok := statusPath(); ok {
+		if err := writeJobStatus(path, status); err != nil {
+			logger.Errorw("Could not write job status", "error", err)
+		}
+	}
+	return err
+}
+
+func run(logger *zap.SugaredLogger, status *JobStatus) error {
 	config, ok := os.LookupEnv("CONFIG")
 
 	if !ok {
@@ -41,6 +55,7 @@ func CreateAndRun() error {
 	if err != nil {
 		return err
 	}
+	status.setResource(jobRunner.Resource())
 	logger.Infof("Starting job for resource: %v", jobRunner.Resource())
 	if jobRunner.IsUpdateJob() {
 		logger.Info("This is an update job")
@@ -65,13 +80,16 @@ func CreateAndRun() error {
 		if err := indexRunner.SetIndex(index); err != nil {
 			return errors.New("cannot set index")
 		}
+		status.Index = &index
 		jobRunner = indexRunner
 	}
 	watcher, err := jobRunner.Run()
 	if err != nil {
 		return err
 	}
-	if err := watcher.Wait(); err != nil {
+	err = watcher.Wait()
+	status.setProgress(watcher)
+	if err != nil {
 		return err
 	}
 	logger.Infof("Completed job for resource %v", jobRunner.Resource())
@@ -6,6 +6,7 @@ package worker
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"github.com/featureform/coordinator"
@@ -14,6 +15,8 @@ import (
 	"github.com/featureform/types"
 	"github.com/google/uuid"
 	clientv3 "go.etcd.io/etcd/client/v3"
+	"os"
+	"path/filepath"
 	"testing"
 	"time"
 )
@@ -344,6 +347,61 @@ func TestRunnerRunFail(t *testing.T) {
 	}
 }
 
+func readJobStatus(t *testing.T, path string) JobStatus {
+	serialized, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("Could not read job status: %v", err)
+	}
+	status := JobStatus{}
+	if err := json.Unmarshal(serialized, &status); err != nil {
+		t.Fatalf("Could not parse job status %s: %v", serialized, err)
+	}
+	return status
+}
+
+func TestRunnerWritesStatus(t *testing.T) {
+	runner.ResetFactoryMap()
+	if err := registerMockIndexRunnerFactory(); err != nil {
+		t.Fatalf("Error registering mock runner factory: %v", err)
+	}
+	path := filepath.Join(t.TempDir(), "status.json")
+	t.Setenv("CONFIG", string(runner.Config{}))
+	t.Setenv("NAME", "test")
+	t.Setenv("JOB_COMPLETION_INDEX", "2")
+	t.Setenv("STATUS_PATH", path)
+	if err := CreateAndRun(); err != nil {
+		t.Fatalf("Error running mock runner: %v", err)
+	}
+	status := readJobStatus(t, path)
+	if status.Runner != "test" || status.Status != JobSucceeded || status.Error != "" {
+		t.Fatalf("Expected the job to succeed, got %+v", status)
+	}
+	if status.Index == nil || *status.Index != 2 || status.ChunksTotal != 1 {
+		t.Fatalf("Expected the index and progress to be reported, got %+v", status)
+	}
+	if status.Completed.Before(status.Started) || status.DurationSeconds < 0 {
+		t.Fatalf("Expected the job's timings to be reported, got %+v", status)
+	}
+}
+
+func TestRunnerWritesFailedStatus(t *testing.T) {
+	runner.ResetFactoryMap()
+	if err := registerMockRunnerFactoryFailingWatcher(); err != nil {
+		t.Fatalf("Error registering mock runner factory: %v", err)
+	}
+	path := filepath.Join(t.TempDir(), "status.json")
+	t.Setenv("CONFIG", string(runner.Config{}))
+	t.Setenv("NAME", "test")
+	t.Setenv("STATUS_PATH", path)
+	if err := CreateAndRun(); err == nil {
+		t.Fatalf("Broken watcher does not return error")
+	}
+	status := readJobStatus(t, path)
+	if status.Status != JobFailed || status.Error != "Run failed" {
+		t.Fatalf("Expected the job's failure to be reported, got %+v", status)
+	}
+}
+
 func registerUpdateMockRunnerFactory(resID metadata.ResourceID) error {
 	mockRunner := &MockUpdateRunner{ResourceID: resID}
 	mockFactory := func(config runner.Config) (types.Runner, error) {
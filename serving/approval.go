@@ -0,0 +1,35 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package serving
+
+import (
+	"fmt"
+
+	"github.com/featureform/metadata"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+type FeatureNotApproved struct {
+	Name, Variant string
+	State         metadata.ApprovalState
+}
+
+func (err *FeatureNotApproved) Error() string {
+	return fmt.Sprintf("feature %s variant %s is %s and has not been enabled for serving", err.Name, err.Variant, err.State)
+}
+
+func (err *FeatureNotApproved) GRPCStatus() *status.Status {
+	return status.New(codes.PermissionDenied, err.Error())
+}
+
+// checkApproval returns a FeatureNotApproved error if approval is required
+// and the feature variant hasn't reached SERVING_ENABLED.
+func checkApproval(meta *metadata.FeatureVariant, required bool) error {
+	if !required || meta.ApprovalState() == metadata.SERVING_ENABLED {
+		return nil
+	}
+	return &FeatureNotApproved{Name: meta.Name(), Variant: meta.Variant(), State: meta.ApprovalState()}
+}
@@ -0,0 +1,139 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package serving
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"math/rand"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+	grpcmeta "google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+)
+
+// SensitiveTag marks a feature variant whose online lookups are audited.
+const SensitiveTag = "sensitive"
+
+// CallerMetadataKey is the gRPC metadata key clients can set to identify
+// themselves in audit records. The peer address is used when it's absent.
+const CallerMetadataKey = "featureform-caller"
+
+type AuditRecord struct {
+	Caller     string
+	Feature    string
+	Variant    string
+	EntityHash string
+	Timestamp  time.Time
+}
+
+type AuditSink interface {
+	Record(record AuditRecord) error
+}
+
+// LoggerAuditSink writes audit records as structured log lines.
+type LoggerAuditSink struct {
+	Logger *zap.SugaredLogger
+}
+
+func (sink LoggerAuditSink) Record(record AuditRecord) error {
+	sink.Logger.Infow("Sensitive feature access",
+		"Caller", record.Caller,
+		"Feature", record.Feature,
+		"Variant", record.Variant,
+		"EntityHash", record.EntityHash,
+		"Timestamp", record.Timestamp.UTC().Format(time.RFC3339Nano),
+	)
+	return nil
+}
+
+// AccessAuditor records a sample of online lookups of sensitive features.
+// Entity IDs are hashed with a keyed HMAC so the audit trail can be joined on
+// entity without exposing the raw IDs.
+type AccessAuditor struct {
+	Sink       AuditSink
+	sampleRate float64
+	salt       []byte
+	rand       *rand.Rand
+	mu         sync.Mutex
+}
+
+func NewAccessAuditor(sink AuditSink, sampleRate float64, salt string) *AccessAuditor {
+	auditor := &AccessAuditor{
+		Sink: sink,
+		salt: []byte(salt),
+		rand: rand.New(rand.NewSource(time.Now().UnixNano())),
+	}
+	auditor.SetSampleRate(sampleRate)
+	return auditor
+}
+
+// SetSampleRate changes the fraction of sensitive lookups that are recorded.
+// It's safe to call while the auditor is in use.
+func (a *AccessAuditor) SetSampleRate(sampleRate float64) {
+	if sampleRate < 0 {
+		sampleRate = 0
+	} else if sampleRate > 1 {
+		sampleRate = 1
+	}
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.sampleRate = sampleRate
+}
+
+func (a *AccessAuditor) HashEntity(entity string) string {
+	mac := hmac.New(sha256.New, a.salt)
+	mac.Write([]byte(entity))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// Observe records the access if the feature is tagged sensitive and the
+// access is selected by sampling. A nil auditor records nothing.
+func (a *AccessAuditor) Observe(ctx context.Context, name, variant string, tags []string, entity string) error {
+	if a == nil || a.Sink == nil || !isSensitive(tags) || !a.sample() {
+		return nil
+	}
+	return a.Sink.Record(AuditRecord{
+		Caller:     callerFromContext(ctx),
+		Feature:    name,
+		Variant:    variant,
+		EntityHash: a.HashEntity(entity),
+		Timestamp:  time.Now(),
+	})
+}
+
+func (a *AccessAuditor) sample() bool {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if a.sampleRate >= 1 {
+		return true
+	}
+	return a.rand.Float64() < a.sampleRate
+}
+
+func isSensitive(tags []string) bool {
+	for _, tag := range tags {
+		if tag == SensitiveTag {
+			return true
+		}
+	}
+	return false
+}
+
+func callerFromContext(ctx context.Context) string {
+	if md, ok := grpcmeta.FromIncomingContext(ctx); ok {
+		if callers := md.Get(CallerMetadataKey); len(callers) > 0 && callers[0] != "" {
+			return callers[0]
+		}
+	}
+	if p, ok := peer.FromContext(ctx); ok && p.Addr != nil {
+		return p.Addr.String()
+	}
+	return "unknown"
+}
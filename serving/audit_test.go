@@ -0,0 +1,64 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package serving
+
+import (
+	"context"
+	"testing"
+
+	grpcmeta "google.golang.org/grpc/metadata"
+)
+
+type recordingAuditSink struct {
+	records []AuditRecord
+}
+
+func (sink *recordingAuditSink) Record(record AuditRecord) error {
+	sink.records = append(sink.records, record)
+	return nil
+}
+
+func TestAccessAuditorSensitiveOnly(t *testing.T) {
+	sink := &recordingAuditSink{}
+	auditor := NewAccessAuditor(sink, 1, "salt")
+	ctx := grpcmeta.NewIncomingContext(context.Background(), grpcmeta.Pairs(CallerMetadataKey, "fraud-model"))
+	if err := auditor.Observe(ctx, "f", "v", []string{"pii"}, "user1"); err != nil {
+		t.Fatalf("Failed to observe: %s", err)
+	}
+	if len(sink.records) != 0 {
+		t.Fatalf("Recorded access to non-sensitive feature: %v", sink.records)
+	}
+	if err := auditor.Observe(ctx, "f", "v", []string{SensitiveTag}, "user1"); err != nil {
+		t.Fatalf("Failed to observe: %s", err)
+	}
+	if len(sink.records) != 1 {
+		t.Fatalf("Expected 1 record, got %d", len(sink.records))
+	}
+	record := sink.records[0]
+	if record.Caller != "fraud-model" {
+		t.Fatalf("Wrong caller: %s", record.Caller)
+	}
+	if record.EntityHash == "user1" || record.EntityHash != auditor.HashEntity("user1") {
+		t.Fatalf("Entity not hashed consistently: %s", record.EntityHash)
+	}
+	if NewAccessAuditor(sink, 1, "other").HashEntity("user1") == record.EntityHash {
+		t.Fatalf("Entity hash does not depend on salt")
+	}
+}
+
+func TestAccessAuditorSampling(t *testing.T) {
+	sink := &recordingAuditSink{}
+	auditor := NewAccessAuditor(sink, 0, "")
+	for i := 0; i < 100; i++ {
+		auditor.Observe(context.Background(), "f", "v", []string{SensitiveTag}, "e")
+	}
+	if len(sink.records) != 0 {
+		t.Fatalf("Expected no records with a zero sample rate, got %d", len(sink.records))
+	}
+	var nilAuditor *AccessAuditor
+	if err := nilAuditor.Observe(context.Background(), "f", "v", []string{SensitiveTag}, "e"); err != nil {
+		t.Fatalf("Nil auditor returned error: %s", err)
+	}
+}
@@ -0,0 +1,55 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package serving
+
+import (
+	"fmt"
+
+	"github.com/featureform/metadata"
+	"github.com/featureform/provider"
+)
+
+// DefaultValueProperty is the feature property holding the value served for
+// entities that have no value in the online store. It's written as the
+// feature's type would be, e.g. "0.5" or "true", with lists, maps, and
+// embeddings written as JSON.
+const DefaultValueProperty = "default_value"
+
+// defaultValue returns the feature's declared default cast to its value type.
+// The bool is false if the feature has no default.
+func defaultValue(meta *metadata.FeatureVariant) (interface{}, bool, error) {
+	serialized, has := meta.Properties()[DefaultValueProperty]
+	if !has {
+		return nil, false, nil
+	}
+	var valueType provider.ValueType
+	if meta.IsEmbedding() {
+		valueType = provider.VectorType{
+			ScalarType:  provider.ScalarType(meta.Type()),
+			Dimension:   meta.Dimension(),
+			IsEmbedding: true,
+		}
+	} else {
+		valueType = provider.ParseValueType(meta.Type())
+	}
+	val, err := provider.ParseValue(valueType, serialized)
+	if err != nil {
+		return nil, false, fmt.Errorf("invalid %s for feature %s variant %s: %w", DefaultValueProperty, meta.Name(), meta.Variant(), err)
+	}
+	return val, true, nil
+}
+
+// defaultOrNotFound returns the feature's default value in place of a
+// provider.EntityNotFound error, or the original error if it has none.
+func defaultOrNotFound(meta *metadata.FeatureVariant, notFound error) (interface{}, bool, error) {
+	val, has, err := defaultValue(meta)
+	if err != nil {
+		return nil, false, err
+	}
+	if !has {
+		return nil, false, notFound
+	}
+	return val, true, nil
+}
@@ -0,0 +1,32 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package serving
+
+import (
+	"context"
+
+	"github.com/featureform/metadata"
+)
+
+// normalizeEntityKey applies the entity's key rules to a requested key, so
+// that it's looked up the same way it was written when materialized.
+func (serv *FeatureServer) normalizeEntityKey(ctx context.Context, name, key string) (string, error) {
+	entity, err := serv.Metadata.GetEntity(ctx, name)
+	if err != nil {
+		return "", err
+	}
+	rules, err := entity.KeyRules()
+	if err != nil {
+		return "", err
+	}
+	if rules.IsZero() {
+		return key, nil
+	}
+	normalizer, err := metadata.NewEntityKeyNormalizer(name, rules)
+	if err != nil {
+		return "", err
+	}
+	return normalizer.Normalize(key)
+}
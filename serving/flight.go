@@ -0,0 +1,446 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package serving
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/apache/arrow/go/v11/arrow"
+	"github.com/apache/arrow/go/v11/arrow/array"
+	"github.com/apache/arrow/go/v11/arrow/flight"
+	"github.com/apache/arrow/go/v11/arrow/ipc"
+	"github.com/apache/arrow/go/v11/arrow/memory"
+	"github.com/pkg/errors"
+
+	"github.com/featureform/metadata"
+	"github.com/featureform/metrics"
+	pb "github.com/featureform/proto"
+	"github.com/featureform/provider"
+	pt "github.com/featureform/provider/provider_type"
+)
+
+const defaultFlightBatchSize = 10000
+
+type FlightResourceType string
+
+const (
+	FlightTrainingSet     FlightResourceType = "training_set"
+	FlightMaterialization FlightResourceType = "materialization"
+)
+
+// FlightTicket is the JSON ticket that a Flight DoGet reads a resource with.
+// A training set's columns are named like the ones of TrainingDataColumns,
+// and a materialization's are entity, value and ts.
+type FlightTicket struct {
+	Type    FlightResourceType `json:"type"`
+	Name    string             `json:"name"`
+	Variant string             `json:"variant"`
+}
+
+// FlightServer serves training sets and materializations as Arrow record
+// batches over Arrow Flight, which clients can read far faster than the row
+// stream of TrainingData. Feature and label columns have the Arrow type of
+// their declared value type. Other columns, and those whose type has no Arrow
+// equivalent, have the type of their values in the first batch. Columns
+// without any values, or whose values have no Arrow type, are served as
+// strings.
+type FlightServer struct {
+	flight.BaseFlightServer
+	serv      *FeatureServer
+	BatchSize int
+	alloc     memory.Allocator
+}
+
+func NewFlightServer(serv *FeatureServer) *FlightServer {
+	return &FlightServer{
+		serv:      serv,
+		BatchSize: defaultFlightBatchSize,
+		alloc:     memory.NewGoAllocator(),
+	}
+}
+
+// flightRows are the rows of a resource served over Flight.
+type flightRows interface {
+	Next() bool
+	Values() []interface{}
+	Err() error
+	Close() error
+}
+
+func (fs *FlightServer) DoGet(tkt *flight.Ticket, stream flight.FlightService_DoGetServer) error {
+	ticket := FlightTicket{}
+	if err := json.Unmarshal(tkt.GetTicket(), &ticket); err != nil {
+		return fmt.Errorf("invalid flight ticket: %w", err)
+	}
+	logger := fs.serv.Logger.With("Type", ticket.Type, "Name", ticket.Name, "Variant", ticket.Variant)
+	logger.Info("Serving flight data")
+	var columns []string
+	var types []arrow.DataType
+	var rows flightRows
+	var err error
+	switch ticket.Type {
+	case FlightTrainingSet:
+		columns, types, rows, err = fs.trainingSetRows(stream.Context(), ticket.Name, ticket.Variant)
+	case FlightMaterialization:
+		columns, types, rows, err = fs.materializationRows(stream.Context(), ticket.Name, ticket.Variant)
+	default:
+		return fmt.Errorf("unknown flight resource type %q", ticket.Type)
+	}
+	observer := fs.serv.Metrics.BeginObservingTrainingServe(ticket.Name, ticket.Variant)
+	defer observer.Finish()
+	if err != nil {
+		logger.Errorw("Failed to get flight rows", "Error", err)
+		observer.SetError()
+		return err
+	}
+	defer rows.Close()
+	if err := fs.writeBatches(columns, types, rows, stream, observer); err != nil {
+		logger.Errorw("Failed to write flight data", "Error", err)
+		observer.SetError()
+		return err
+	}
+	return nil
+}
+
+func (fs *FlightServer) trainingSetRows(ctx context.Context, name, variant string) ([]string, []arrow.DataType, flightRows, error) {
+	id := &pb.TrainingDataID{Name: name, Version: variant}
+	columns, err := fs.serv.TrainingDataColumns(ctx, &pb.TrainingDataColumnsRequest{Id: id})
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	types, err := fs.trainingSetTypes(ctx, name, variant)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	iter, err := fs.serv.getTrainingSetIterator(name, variant)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	return append(columns.GetFeatures(), columns.GetLabel()), types, trainingSetRows{iter}, nil
+}
+
+// trainingSetTypes returns the declared Arrow types of a training set's
+// feature columns followed by its label's, in the order of
+// TrainingDataColumns.
+func (fs *FlightServer) trainingSetTypes(ctx context.Context, name, variant string) ([]arrow.DataType, error) {
+	ts, err := fs.serv.Metadata.GetTrainingSetVariant(ctx, metadata.NameVariant{Name: name, Variant: variant})
+	if err != nil {
+		return nil, errors.Wrap(err, "could not get training set variant")
+	}
+	features, err := fs.serv.Metadata.GetFeatureVariants(ctx, ts.Features())
+	if err != nil {
+		return nil, errors.Wrap(err, "could not get training set features")
+	}
+	featureTypes := make(map[metadata.NameVariant]string, len(features))
+	for _, feature := range features {
+		featureTypes[metadata.NameVariant{Name: feature.Name(), Variant: feature.Variant()}] = feature.Type()
+	}
+	label, err := ts.FetchLabel(fs.serv.Metadata, ctx)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not get training set label")
+	}
+	types := make([]arrow.DataType, 0, len(features)+1)
+	for _, feature := range ts.Features() {
+		types = append(types, declaredArrowType(featureTypes[feature]))
+	}
+	return append(types, declaredArrowType(label.Type())), nil
+}
+
+type trainingSetRows struct {
+	provider.TrainingSetIterator
+}
+
+func (rows trainingSetRows) Values() []interface{} {
+	features := rows.Features()
+	values := make([]interface{}, len(features), len(features)+1)
+	copy(values, features)
+	return append(values, rows.Label())
+}
+
+func (rows trainingSetRows) Close() error {
+	return nil
+}
+
+// materializationRows reads the materialization of a feature from the
+// offline store of its source.
+func (fs *FlightServer) materializationRows(ctx context.Context, name, variant string) ([]string, []arrow.DataType, flightRows, error) {
+	feature, err := fs.serv.Metadata.GetFeatureVariant(ctx, metadata.NameVariant{Name: name, Variant: variant})
+	if err != nil {
+		return nil, nil, nil, errors.Wrap(err, "could not get feature variant")
+	}
+	source, err := feature.FetchSource(fs.serv.Metadata, ctx)
+	if err != nil {
+		return nil, nil, nil, errors.Wrap(err, "could not fetch source")
+	}
+	providerEntry, err := source.FetchProvider(fs.serv.Metadata, ctx)
+	if err != nil {
+		return nil, nil, nil, errors.Wrap(err, "could not fetch provider")
+	}
+	if err := providerEntry.CheckTLSPolicy(); err != nil {
+		return nil, nil, nil, err
+	}
+	p, err := provider.Get(pt.Type(providerEntry.Type()), providerEntry.SerializedConfig())
+	if err != nil {
+		return nil, nil, nil, errors.Wrap(err, "could not get provider")
+	}
+	store, err := p.AsOfflineStore()
+	if err != nil {
+		return nil, nil, nil, errors.Wrap(err, "could not open as offline store")
+	}
+	getter, ok := store.(provider.FeatureMaterializationGetter)
+	if !ok {
+		return nil, nil, nil, fmt.Errorf("%s can't find materializations by feature", providerEntry.Type())
+	}
+	materialization, err := getter.GetFeatureMaterialization(provider.ResourceID{Name: name, Variant: variant, Type: provider.Feature})
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	numRows, err := materialization.NumRows()
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	iter, err := materialization.IterateSegment(0, numRows)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	types := []arrow.DataType{nil, declaredArrowType(feature.Type()), nil}
+	return []string{"entity", "value", "ts"}, types, materializationRows{iter}, nil
+}
+
+type materializationRows struct {
+	provider.FeatureIterator
+}
+
+func (rows materializationRows) Values() []interface{} {
+	rec := rows.Value()
+	var ts interface{}
+	if !rec.TS.IsZero() {
+		ts = rec.TS
+	}
+	return []interface{}{rec.Entity, rec.Value, ts}
+}
+
+func (fs *FlightServer) writeBatches(columns []string, types []arrow.DataType, rows flightRows, stream flight.FlightService_DoGetServer, observer metrics.FeatureObserver) error {
+	batchSize := fs.BatchSize
+	if batchSize <= 0 {
+		batchSize = defaultFlightBatchSize
+	}
+	var writer *flight.Writer
+	var builder *array.RecordBuilder
+	defer func() {
+		if builder != nil {
+			builder.Release()
+		}
+	}()
+	batch := make([][]interface{}, 0, batchSize)
+	flush := func() error {
+		if writer == nil {
+			schema := arrowSchema(columns, types, batch)
+			writer = flight.NewRecordWriter(stream, ipc.WithSchema(schema), ipc.WithAllocator(fs.alloc))
+			builder = array.NewRecordBuilder(fs.alloc, schema)
+		}
+		for _, values := range batch {
+			for i, value := range values {
+				field := builder.Field(i)
+				if !appendArrowValue(field, value) {
+					return fmt.Errorf("could not serve column %s: value %v of type %T isn't a %s", columns[i], value, value, field.Type())
+				}
+			}
+		}
+		record := builder.NewRecord()
+		defer record.Release()
+		batch = batch[:0]
+		return writer.Write(record)
+	}
+	for rows.Next() {
+		values := rows.Values()
+		if len(values) != len(columns) {
+			return fmt.Errorf("row has %d values but there are %d columns", len(values), len(columns))
+		}
+		batch = append(batch, values)
+		observer.ServeRow()
+		if len(batch) == batchSize {
+			if err := flush(); err != nil {
+				return err
+			}
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+	if len(batch) > 0 || writer == nil {
+		if err := flush(); err != nil {
+			return err
+		}
+	}
+	return writer.Close()
+}
+
+// arrowSchema uses the declared type of each column that has one, and infers
+// the others from their first non-null value in rows. Integer columns with
+// floats in rows are widened to Float64 so that both fit.
+func arrowSchema(columns []string, types []arrow.DataType, rows [][]interface{}) *arrow.Schema {
+	fields := make([]arrow.Field, len(columns))
+	for i, column := range columns {
+		if i < len(types) && types[i] != nil {
+			fields[i] = arrow.Field{Name: column, Type: types[i], Nullable: true}
+			continue
+		}
+		var dataType arrow.DataType = arrow.BinaryTypes.String
+		inferred := false
+		for _, values := range rows {
+			if values[i] == nil {
+				continue
+			}
+			valueType := arrowType(values[i])
+			if !inferred {
+				dataType = valueType
+				inferred = true
+			} else if arrow.IsInteger(dataType.ID()) && arrow.IsFloating(valueType.ID()) {
+				dataType = arrow.PrimitiveTypes.Float64
+				break
+			}
+		}
+		fields[i] = arrow.Field{Name: column, Type: dataType, Nullable: true}
+	}
+	return arrow.NewSchema(fields, nil)
+}
+
+// declaredArrowType returns the Arrow type of a feature or label's declared
+// value type, or nil if it has none and the column's type is inferred.
+func declaredArrowType(valueType string) arrow.DataType {
+	switch provider.ScalarType(valueType) {
+	case provider.Int, provider.Int64:
+		return arrow.PrimitiveTypes.Int64
+	case provider.Int32:
+		return arrow.PrimitiveTypes.Int32
+	case provider.Float32:
+		return arrow.PrimitiveTypes.Float32
+	case provider.Float64:
+		return arrow.PrimitiveTypes.Float64
+	case provider.Bool:
+		return arrow.FixedWidthTypes.Boolean
+	case provider.String:
+		return arrow.BinaryTypes.String
+	case provider.Timestamp, provider.Datetime:
+		return arrow.FixedWidthTypes.Timestamp_us
+	default:
+		return nil
+	}
+}
+
+func arrowType(value interface{}) arrow.DataType {
+	switch value.(type) {
+	case int, int64:
+		return arrow.PrimitiveTypes.Int64
+	case int32:
+		return arrow.PrimitiveTypes.Int32
+	case float32:
+		return arrow.PrimitiveTypes.Float32
+	case float64:
+		return arrow.PrimitiveTypes.Float64
+	case bool:
+		return arrow.FixedWidthTypes.Boolean
+	case time.Time:
+		return arrow.FixedWidthTypes.Timestamp_us
+	case []float32:
+		return arrow.ListOf(arrow.PrimitiveTypes.Float32)
+	default:
+		return arrow.BinaryTypes.String
+	}
+}
+
+// appendArrowValue appends a value to a column, converting between numeric
+// types, and returns false if it doesn't match the column's type.
+func appendArrowValue(builder array.Builder, value interface{}) bool {
+	if value == nil {
+		builder.AppendNull()
+		return true
+	}
+	switch b := builder.(type) {
+	case *array.Int64Builder:
+		i, ok := arrowInt(value)
+		if ok {
+			b.Append(i)
+		}
+		return ok
+	case *array.Int32Builder:
+		i, ok := arrowInt(value)
+		if ok {
+			b.Append(int32(i))
+		}
+		return ok
+	case *array.Float64Builder:
+		f, ok := arrowFloat(value)
+		if ok {
+			b.Append(f)
+		}
+		return ok
+	case *array.Float32Builder:
+		f, ok := arrowFloat(value)
+		if ok {
+			b.Append(float32(f))
+		}
+		return ok
+	case *array.BooleanBuilder:
+		v, ok := value.(bool)
+		if ok {
+			b.Append(v)
+		}
+		return ok
+	case *array.TimestampBuilder:
+		v, ok := value.(time.Time)
+		if ok {
+			b.Append(arrow.Timestamp(v.UnixMicro()))
+		}
+		return ok
+	case *array.ListBuilder:
+		v, ok := value.([]float32)
+		if ok {
+			b.Append(true)
+			b.ValueBuilder().(*array.Float32Builder).AppendValues(v, nil)
+		}
+		return ok
+	case *array.StringBuilder:
+		if v, ok := value.(string); ok {
+			b.Append(v)
+		} else {
+			b.Append(fmt.Sprint(value))
+		}
+		return true
+	default:
+		return false
+	}
+}
+
+func arrowInt(value interface{}) (int64, bool) {
+	switch v := value.(type) {
+	case int:
+		return int64(v), true
+	case int32:
+		return int64(v), true
+	case int64:
+		return v, true
+	default:
+		return 0, false
+	}
+}
+
+func arrowFloat(value interface{}) (float64, bool) {
+	switch v := value.(type) {
+	case float32:
+		return float64(v), true
+	case float64:
+		return v, true
+	case int, int32, int64:
+		i, _ := arrowInt(v)
+		return float64(i), true
+	default:
+		return 0, false
+	}
+}
@@ -0,0 +1,222 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package serving
+
+import (
+	"context"
+	"encoding/json"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/apache/arrow/go/v11/arrow"
+	"github.com/apache/arrow/go/v11/arrow/array"
+	"github.com/apache/arrow/go/v11/arrow/flight"
+	"github.com/apache/arrow/go/v11/arrow/ipc"
+	"github.com/apache/arrow/go/v11/arrow/memory"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+
+	"github.com/featureform/metadata"
+	"github.com/featureform/provider"
+	pc "github.com/featureform/provider/provider_config"
+)
+
+func flightFeatureRecords() map[provider.ResourceID][]provider.ResourceRecord {
+	records := make(map[provider.ResourceID][]provider.ResourceRecord)
+	featureId := provider.ResourceID{Name: "feature", Variant: "variant", Type: provider.Feature}
+	records[featureId] = []provider.ResourceRecord{
+		{Entity: "a", Value: 7, TS: time.UnixMilli(1000).UTC()},
+		{Entity: "b", Value: 12.5, TS: time.UnixMilli(2000).UTC()},
+	}
+	labelId := provider.ResourceID{Name: "label", Variant: "variant", Type: provider.Label}
+	records[labelId] = []provider.ResourceRecord{
+		{Entity: "a", Value: true, TS: time.UnixMilli(3000).UTC()},
+		{Entity: "b", Value: false, TS: time.UnixMilli(3000).UTC()},
+	}
+	return records
+}
+
+// flightResourceDefsFn declares the feature and label's value types, which
+// their flight columns take.
+func flightResourceDefsFn(providerType string) []metadata.ResourceDef {
+	defs := simpleResourceDefsFn(providerType)
+	for i, def := range defs {
+		switch typed := def.(type) {
+		case metadata.FeatureDef:
+			typed.Type = string(provider.Float64)
+			defs[i] = typed
+		case metadata.LabelDef:
+			typed.Type = string(provider.Bool)
+			defs[i] = typed
+		}
+	}
+	return defs
+}
+
+func createMaterializedOfflineStoreFactory() provider.Factory {
+	factory := createMockOfflineStoreFactory(flightFeatureRecords(), simpleTrainingSetDefs())
+	return func(cfg pc.SerializedConfig) (provider.Provider, error) {
+		p, err := factory(cfg)
+		if err != nil {
+			return nil, err
+		}
+		store, _ := p.AsOfflineStore()
+		if _, err := store.CreateMaterialization(provider.ResourceID{Name: "feature", Variant: "variant", Type: provider.Feature}); err != nil {
+			return nil, err
+		}
+		return p, nil
+	}
+}
+
+// readFlight reads a resource from a Flight server for serv, and returns its
+// schema and rows.
+func readFlight(t *testing.T, serv *FeatureServer, ticket FlightTicket, batchSize int) (*arrow.Schema, [][]interface{}) {
+	server := flight.NewServerWithMiddleware(nil)
+	if err := server.Init("localhost:0"); err != nil {
+		t.Fatalf("Failed to listen: %s", err)
+	}
+	flightServer := NewFlightServer(serv)
+	flightServer.BatchSize = batchSize
+	server.RegisterFlightService(flightServer)
+	go server.Serve()
+	defer server.Shutdown()
+	client, err := flight.NewFlightClient(server.Addr().String(), nil, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatalf("Failed to create flight client: %s", err)
+	}
+	defer client.Close()
+	serialized, err := json.Marshal(ticket)
+	if err != nil {
+		t.Fatalf("Failed to marshal ticket: %s", err)
+	}
+	stream, err := client.DoGet(context.Background(), &flight.Ticket{Ticket: serialized})
+	if err != nil {
+		t.Fatalf("Failed to start DoGet: %s", err)
+	}
+	reader, err := flight.NewRecordReader(stream, ipc.WithAllocator(memory.NewGoAllocator()))
+	if err != nil {
+		t.Fatalf("Failed to read flight data: %s", err)
+	}
+	defer reader.Release()
+	rows := make([][]interface{}, 0)
+	for reader.Next() {
+		record := reader.Record()
+		for i := 0; i < int(record.NumRows()); i++ {
+			row := make([]interface{}, record.NumCols())
+			for j, column := range record.Columns() {
+				if column.IsNull(i) {
+					continue
+				}
+				switch values := column.(type) {
+				case *array.Float64:
+					row[j] = values.Value(i)
+				case *array.Boolean:
+					row[j] = values.Value(i)
+				case *array.String:
+					row[j] = values.Value(i)
+				case *array.Timestamp:
+					row[j] = values.Value(i)
+				default:
+					t.Fatalf("Unexpected column type %s", column.DataType())
+				}
+			}
+			rows = append(rows, row)
+		}
+	}
+	if err := reader.Err(); err != nil {
+		t.Fatalf("Failed to read flight data: %s", err)
+	}
+	return reader.Schema(), rows
+}
+
+func TestFlightTrainingSet(t *testing.T) {
+	ctx := onlineTestContext{
+		ResourceDefsFn: flightResourceDefsFn,
+		FactoryFn:      createMockOfflineStoreFactory(flightFeatureRecords(), simpleTrainingSetDefs()),
+	}
+	serv := ctx.Create(t)
+	defer ctx.Destroy()
+	ticket := FlightTicket{Type: FlightTrainingSet, Name: "training-set", Variant: "variant"}
+	schema, rows := readFlight(t, serv, ticket, 1)
+	expectedFields := []arrow.Field{
+		{Name: "feature__feature__variant", Type: arrow.PrimitiveTypes.Float64, Nullable: true},
+		{Name: "label__label__variant", Type: arrow.FixedWidthTypes.Boolean, Nullable: true},
+	}
+	if !reflect.DeepEqual(schema.Fields(), expectedFields) {
+		t.Fatalf("Expected fields %v, got %v", expectedFields, schema.Fields())
+	}
+	// Rows aren't ordered, so they're compared as a set.
+	expected := map[[2]interface{}]bool{{7.0, true}: true, {12.5, false}: true}
+	actual := make(map[[2]interface{}]bool)
+	for _, row := range rows {
+		actual[[2]interface{}{row[0], row[1]}] = true
+	}
+	if !reflect.DeepEqual(expected, actual) {
+		t.Fatalf("Expected rows %v, got %v", expected, actual)
+	}
+}
+
+func TestFlightMaterialization(t *testing.T) {
+	ctx := onlineTestContext{
+		ResourceDefsFn: simpleResourceDefsFn,
+		FactoryFn:      createMaterializedOfflineStoreFactory(),
+	}
+	serv := ctx.Create(t)
+	defer ctx.Destroy()
+	ticket := FlightTicket{Type: FlightMaterialization, Name: "feature", Variant: "variant"}
+	schema, rows := readFlight(t, serv, ticket, 10)
+	expectedFields := []arrow.Field{
+		{Name: "entity", Type: arrow.BinaryTypes.String, Nullable: true},
+		{Name: "value", Type: arrow.PrimitiveTypes.Float64, Nullable: true},
+		{Name: "ts", Type: arrow.FixedWidthTypes.Timestamp_us, Nullable: true},
+	}
+	if !reflect.DeepEqual(schema.Fields(), expectedFields) {
+		t.Fatalf("Expected fields %v, got %v", expectedFields, schema.Fields())
+	}
+	expected := [][]interface{}{
+		{"a", 7.0, arrow.Timestamp(1000000)},
+		{"b", 12.5, arrow.Timestamp(2000000)},
+	}
+	if !reflect.DeepEqual(expected, rows) {
+		t.Fatalf("Expected rows %v, got %v", expected, rows)
+	}
+}
+
+func TestArrowSchema(t *testing.T) {
+	columns := []string{"inferred", "widened", "declared", "empty"}
+	rows := [][]interface{}{
+		{7, 7, 7, nil},
+		{8, 12.5, 8, nil},
+	}
+	types := []arrow.DataType{nil, nil, arrow.PrimitiveTypes.Float32, nil}
+	expected := []arrow.DataType{
+		arrow.PrimitiveTypes.Int64,
+		arrow.PrimitiveTypes.Float64,
+		arrow.PrimitiveTypes.Float32,
+		arrow.BinaryTypes.String,
+	}
+	for i, field := range arrowSchema(columns, types, rows).Fields() {
+		if !arrow.TypeEqual(field.Type, expected[i]) {
+			t.Errorf("Expected column %s to be %s, got %s", field.Name, expected[i], field.Type)
+		}
+	}
+	if declaredArrowType("") != nil || declaredArrowType("[]float32") != nil {
+		t.Fatalf("Expected columns without a scalar type to be inferred")
+	}
+}
+
+func TestFlightUnknownResourceType(t *testing.T) {
+	ctx := onlineTestContext{}
+	serv := ctx.Create(t)
+	defer ctx.Destroy()
+	ticket, err := json.Marshal(FlightTicket{Type: "model", Name: "name", Variant: "variant"})
+	if err != nil {
+		t.Fatalf("Failed to marshal ticket: %s", err)
+	}
+	if err := NewFlightServer(serv).DoGet(&flight.Ticket{Ticket: ticket}, nil); err == nil {
+		t.Fatalf("Expected an error for an unknown resource type")
+	}
+}
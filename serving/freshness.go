@@ -0,0 +1,49 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package serving
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/featureform/metadata"
+)
+
+// FreshnessSLAProperty is the feature property holding the maximum age of
+// values that may be served, as a duration such as "15m". Older values are
+// rejected with a StaleFeatureError.
+const FreshnessSLAProperty = "freshness_sla"
+
+type StaleFeatureError struct {
+	Name, Variant, Entity string
+	Age, SLA              time.Duration
+}
+
+func (err *StaleFeatureError) Error() string {
+	return fmt.Sprintf("feature %s variant %s for entity %s was last updated %s ago, exceeding its freshness SLA of %s", err.Name, err.Variant, err.Entity, err.Age, err.SLA)
+}
+
+// freshnessSLA returns the feature's freshness SLA, or zero if it has none.
+func freshnessSLA(properties metadata.Properties) (time.Duration, error) {
+	sla, has := properties[FreshnessSLAProperty]
+	if !has || sla == "" {
+		return 0, nil
+	}
+	duration, err := time.ParseDuration(sla)
+	if err != nil {
+		return 0, fmt.Errorf("invalid %s %q: %w", FreshnessSLAProperty, sla, err)
+	}
+	return duration, nil
+}
+
+func checkFreshness(name, variant, entity string, sla time.Duration, lastUpdated, now time.Time) error {
+	if sla == 0 || lastUpdated.IsZero() {
+		return nil
+	}
+	if age := now.Sub(lastUpdated); age > sla {
+		return &StaleFeatureError{name, variant, entity, age, sla}
+	}
+	return nil
+}
@@ -0,0 +1,71 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package serving
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/featureform/metadata"
+)
+
+func TestFreshnessSLA(t *testing.T) {
+	tests := []struct {
+		name       string
+		properties metadata.Properties
+		expected   time.Duration
+		wantErr    bool
+	}{
+		{"Unset", metadata.Properties{}, 0, false},
+		{"Empty", metadata.Properties{FreshnessSLAProperty: ""}, 0, false},
+		{"Minutes", metadata.Properties{FreshnessSLAProperty: "15m"}, 15 * time.Minute, false},
+		{"Invalid", metadata.Properties{FreshnessSLAProperty: "soon"}, 0, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sla, err := freshnessSLA(tt.properties)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("freshnessSLA() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if sla != tt.expected {
+				t.Fatalf("freshnessSLA() = %v, expected %v", sla, tt.expected)
+			}
+		})
+	}
+}
+
+func TestCheckFreshness(t *testing.T) {
+	now := time.Date(2023, 1, 2, 3, 4, 5, 0, time.UTC)
+	tests := []struct {
+		name        string
+		sla         time.Duration
+		lastUpdated time.Time
+		stale       bool
+	}{
+		{"No SLA", 0, now.Add(-time.Hour), false},
+		{"Unknown Write Time", time.Minute, time.Time{}, false},
+		{"Fresh", time.Minute, now.Add(-time.Second), false},
+		{"Stale", time.Minute, now.Add(-time.Hour), true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := checkFreshness("feature", "variant", "entity", tt.sla, tt.lastUpdated, now)
+			if !tt.stale {
+				if err != nil {
+					t.Fatalf("Expected fresh feature, got: %v", err)
+				}
+				return
+			}
+			var staleErr *StaleFeatureError
+			if !errors.As(err, &staleErr) {
+				t.Fatalf("Expected StaleFeatureError, got: %v", err)
+			}
+			if staleErr.Age != time.Hour || staleErr.SLA != tt.sla {
+				t.Fatalf("Wrong age or SLA: %v %v", staleErr.Age, staleErr.SLA)
+			}
+		})
+	}
+}
@@ -6,13 +6,19 @@ package main
 
 import (
 	"fmt"
+	cfg "github.com/featureform/config"
 	help "github.com/featureform/helpers"
 	"github.com/featureform/metadata"
 	"github.com/featureform/metrics"
+	"github.com/featureform/provider"
 	"github.com/featureform/serving"
 	"net"
+	"net/http"
+	"time"
 
+	"github.com/apache/arrow/go/v11/arrow/flight"
 	pb "github.com/featureform/proto"
+	"github.com/prometheus/client_golang/prometheus"
 	"go.uber.org/zap"
 	"google.golang.org/grpc"
 )
@@ -44,6 +50,47 @@ func main() {
 	if err != nil {
 		logger.Panicw("Failed to create training server", "Err", err)
 	}
+	if err := provider.RegisterDualWriteMetrics(prometheus.DefaultRegisterer); err != nil {
+		logger.Panicw("Failed to register dual-write metrics", "Err", err)
+	}
+	tunables := cfg.StartTunablesWatch(logger)
+	auditSalt := help.GetEnv("AUDIT_ENTITY_SALT", "")
+	serv.Auditor = serving.NewAccessAuditor(serving.LoggerAuditSink{Logger: logger.Named("audit")}, tunables.Get().AuditSampleRate, auditSalt)
+	tunables.Subscribe(func(t cfg.Tunables) {
+		serv.Auditor.SetSampleRate(t.AuditSampleRate)
+	})
+	if interval := tunables.Get().ProviderProbeIntervalSeconds; interval > 0 {
+		prober, err := serving.NewProviderProber(meta, prometheus.DefaultRegisterer, logger.Named("prober"))
+		if err != nil {
+			logger.Panicw("Failed to create provider prober", "Err", err)
+		}
+		// The status API is served alongside the metrics endpoint.
+		http.Handle("/status/providers", prober)
+		go prober.Run(time.Duration(interval)*time.Second, make(chan struct{}))
+	}
+	if interval := tunables.Get().TableStatsIntervalSeconds; interval > 0 {
+		collector, err := serving.NewTableStatsCollector(meta, prometheus.DefaultRegisterer, logger.Named("table-stats"))
+		if err != nil {
+			logger.Panicw("Failed to create table stats collector", "Err", err)
+		}
+		http.Handle("/status/tables", collector)
+		go collector.Run(time.Duration(interval)*time.Second, make(chan struct{}))
+	}
+	// Arrow Flight reads of training sets and materializations are served on
+	// their own port, if it's set.
+	if flightPort := help.GetEnv("FLIGHT_PORT", ""); flightPort != "" {
+		flightServer := flight.NewServerWithMiddleware(nil)
+		if err := flightServer.Init(fmt.Sprintf("%s:%s", host, flightPort)); err != nil {
+			logger.Panicw("Failed to listen on flight port", "Err", err)
+		}
+		flightServer.RegisterFlightService(serving.NewFlightServer(serv))
+		logger.Infow("Flight server starting", "Port", flightPort)
+		go func() {
+			if err := flightServer.Serve(); err != nil {
+				logger.Errorw("Flight serve failed with error", "Err", err)
+			}
+		}()
+	}
 	grpcServer := grpc.NewServer()
 
 	pb.RegisterFeatureServer(grpcServer, serv)
@@ -0,0 +1,243 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package serving
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"go.uber.org/zap"
+
+	"github.com/featureform/metadata"
+	"github.com/featureform/metrics"
+	"github.com/featureform/provider"
+	pt "github.com/featureform/provider/provider_type"
+)
+
+const (
+	// The probe table is written to every online provider, so its name uses
+	// a prefix that registered features can't.
+	probeTableName    = "__featureform_probe"
+	probeTableVariant = "probe"
+	probeEntity       = "probe"
+)
+
+// Providers of these types are never online stores, so they aren't
+// constructed just to find that out. Some offline providers connect eagerly.
+var offlineProviderTypes = map[pt.Type]bool{
+	pt.MemoryOffline:        true,
+	pt.PostgresOffline:      true,
+	pt.SnowflakeOffline:     true,
+	pt.RedshiftOffline:      true,
+	pt.ClickHouseOffline:    true,
+	pt.DuckDBOffline:        true,
+	pt.AthenaOffline:        true,
+	pt.DatabricksSQLOffline: true,
+	pt.SparkOffline:         true,
+	pt.BigQueryOffline:      true,
+	pt.K8sOffline:           true,
+	pt.S3:                   true,
+	pt.GCS:                  true,
+	pt.HDFS:                 true,
+	pt.AZURE:                true,
+}
+
+// ProbeStatus is the result of the latest probe of an online provider along
+// with running totals since the prober started.
+type ProbeStatus struct {
+	Provider   string        `json:"provider"`
+	Type       string        `json:"type"`
+	Available  bool          `json:"available"`
+	LastProbe  time.Time     `json:"lastProbe"`
+	LastError  string        `json:"lastError,omitempty"`
+	SetLatency time.Duration `json:"setLatencyNanos"`
+	GetLatency time.Duration `json:"getLatencyNanos"`
+	Successes  int64         `json:"successes"`
+	Failures   int64         `json:"failures"`
+}
+
+// ProviderProber periodically writes and reads back a synthetic value in
+// every online provider, recording availability and latency per provider and
+// operation. Results are exported as Prometheus metrics and served as JSON
+// by ServeHTTP.
+type ProviderProber struct {
+	Metadata *metadata.Client
+	Logger   *zap.SugaredLogger
+	latency  *prometheus.HistogramVec
+	results  *prometheus.CounterVec
+	stores   *onlineStoreCache
+	status   map[string]*ProbeStatus
+	now      func() time.Time
+	mu       sync.RWMutex
+}
+
+func NewProviderProber(meta *metadata.Client, registerer prometheus.Registerer, logger *zap.SugaredLogger) (*ProviderProber, error) {
+	latency := prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name:    "provider_probe_duration_seconds",
+			Help:    "Latency of synthetic online provider operations, labeled by provider, operation and status",
+			Buckets: prometheus.ExponentialBuckets(0.001, 2, 12),
+		},
+		[]string{"provider", "operation", "status"},
+	)
+	results := prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "provider_probe_total",
+			Help: "Count of synthetic online provider probes, labeled by provider and status",
+		},
+		[]string{"provider", "status"},
+	)
+	for _, collector := range []prometheus.Collector{latency, results} {
+		if err := registerer.Register(collector); err != nil {
+			return nil, fmt.Errorf("could not register probe metrics: %w", err)
+		}
+	}
+	return &ProviderProber{
+		Metadata: meta,
+		Logger:   logger,
+		latency:  latency,
+		results:  results,
+		stores:   newOnlineStoreCache(logger),
+		status:   make(map[string]*ProbeStatus),
+		now:      time.Now,
+	}, nil
+}
+
+// Run probes every online provider once per interval until stop is closed.
+func (p *ProviderProber) Run(interval time.Duration, stop <-chan struct{}) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	defer p.stores.close()
+	for {
+		ctx, cancel := context.WithTimeout(context.Background(), interval)
+		if err := p.ProbeAll(ctx); err != nil {
+			p.Logger.Errorw("Failed to probe providers", "Error", err)
+		}
+		cancel()
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// ProbeAll probes each online provider registered in metadata once.
+// Providers that aren't online stores are skipped.
+func (p *ProviderProber) ProbeAll(ctx context.Context) error {
+	providers, err := p.Metadata.ListProviders(ctx)
+	if err != nil {
+		return fmt.Errorf("could not list providers: %w", err)
+	}
+	for _, entry := range providers {
+		if offlineProviderTypes[pt.Type(entry.Type())] {
+			continue
+		}
+		store, err := p.stores.get(entry)
+		if err != nil {
+			p.record(entry, 0, 0, err)
+			continue
+		}
+		if store == nil {
+			continue
+		}
+		setLatency, getLatency, err := p.probe(entry.Name(), store)
+		p.record(entry, setLatency, getLatency, err)
+	}
+	return nil
+}
+
+func (p *ProviderProber) probe(name string, store provider.OnlineStore) (time.Duration, time.Duration, error) {
+	table, err := store.GetTable(probeTableName, probeTableVariant)
+	if _, notFound := err.(*provider.TableNotFound); notFound {
+		table, err = store.CreateTable(probeTableName, probeTableVariant, provider.String)
+	}
+	if err != nil {
+		return 0, 0, fmt.Errorf("could not get probe table: %w", err)
+	}
+	value := strconv.FormatInt(p.now().UnixNano(), 10)
+	start := time.Now()
+	err = table.Set(probeEntity, value)
+	setLatency := time.Since(start)
+	p.observe(name, "set", setLatency, err)
+	if err != nil {
+		return setLatency, 0, fmt.Errorf("probe set failed: %w", err)
+	}
+	start = time.Now()
+	read, err := table.Get(probeEntity)
+	getLatency := time.Since(start)
+	p.observe(name, "get", getLatency, err)
+	if err != nil {
+		return setLatency, getLatency, fmt.Errorf("probe get failed: %w", err)
+	}
+	if read != value {
+		return setLatency, getLatency, fmt.Errorf("probe read %v, expected %s", read, value)
+	}
+	return setLatency, getLatency, nil
+}
+
+func (p *ProviderProber) observe(name, operation string, latency time.Duration, err error) {
+	status := metrics.SUCCESS
+	if err != nil {
+		status = metrics.ERROR
+	}
+	p.latency.WithLabelValues(name, operation, status).Observe(latency.Seconds())
+}
+
+func (p *ProviderProber) record(entry *metadata.Provider, setLatency, getLatency time.Duration, err error) {
+	status := metrics.SUCCESS
+	if err != nil {
+		status = metrics.ERROR
+		p.Logger.Warnw("Provider probe failed", "Provider", entry.Name(), "Error", err)
+	}
+	p.results.WithLabelValues(entry.Name(), status).Inc()
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	probeStatus, has := p.status[entry.Name()]
+	if !has {
+		probeStatus = &ProbeStatus{Provider: entry.Name()}
+		p.status[entry.Name()] = probeStatus
+	}
+	probeStatus.Type = entry.Type()
+	probeStatus.Available = err == nil
+	probeStatus.LastProbe = p.now()
+	probeStatus.SetLatency = setLatency
+	probeStatus.GetLatency = getLatency
+	probeStatus.LastError = ""
+	if err != nil {
+		probeStatus.LastError = err.Error()
+		probeStatus.Failures++
+	} else {
+		probeStatus.Successes++
+	}
+}
+
+// Status returns the probe status of every probed provider, sorted by name.
+func (p *ProviderProber) Status() []ProbeStatus {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	statuses := make([]ProbeStatus, 0, len(p.status))
+	for _, status := range p.status {
+		statuses = append(statuses, *status)
+	}
+	sort.Slice(statuses, func(i, j int) bool {
+		return statuses[i].Provider < statuses[j].Provider
+	})
+	return statuses
+}
+
+func (p *ProviderProber) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(p.Status()); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
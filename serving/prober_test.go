@@ -0,0 +1,84 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package serving
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"go.uber.org/zap/zaptest"
+
+	"github.com/featureform/metadata"
+	"github.com/featureform/provider"
+	pc "github.com/featureform/provider/provider_config"
+	pt "github.com/featureform/provider/provider_type"
+)
+
+func TestProviderProber(t *testing.T) {
+	store := provider.NewLocalOnlineStore()
+	failingType := uuid.NewString()
+	err := provider.RegisterFactory(pt.Type(failingType), func(pc.SerializedConfig) (provider.Provider, error) {
+		return nil, fmt.Errorf("unreachable")
+	})
+	if err != nil {
+		t.Fatalf("Failed to register factory: %s", err)
+	}
+	ctx := onlineTestContext{
+		ResourceDefsFn: func(providerType string) []metadata.ResourceDef {
+			return []metadata.ResourceDef{
+				metadata.UserDef{Name: "Featureform"},
+				metadata.ProviderDef{Name: "healthy", Type: providerType},
+				metadata.ProviderDef{Name: "unreachable", Type: failingType},
+				metadata.ProviderDef{Name: "offline", Type: string(pt.MemoryOffline)},
+			}
+		},
+		FactoryFn: func(pc.SerializedConfig) (provider.Provider, error) {
+			return store, nil
+		},
+	}
+	serv := ctx.Create(t)
+	defer ctx.Destroy()
+	prober, err := NewProviderProber(serv.Metadata, prometheus.NewRegistry(), zaptest.NewLogger(t).Sugar())
+	if err != nil {
+		t.Fatalf("Failed to create prober: %s", err)
+	}
+	for i := 0; i < 2; i++ {
+		if err := prober.ProbeAll(context.Background()); err != nil {
+			t.Fatalf("Failed to probe providers: %s", err)
+		}
+	}
+	if _, err := store.GetTable(probeTableName, probeTableVariant); err != nil {
+		t.Fatalf("Probe table not created: %s", err)
+	}
+
+	recorder := httptest.NewRecorder()
+	prober.ServeHTTP(recorder, httptest.NewRequest("GET", "/status/providers", nil))
+	var statuses []ProbeStatus
+	if err := json.Unmarshal(recorder.Body.Bytes(), &statuses); err != nil {
+		t.Fatalf("Failed to parse status: %s", err)
+	}
+	if len(statuses) != 2 {
+		t.Fatalf("Expected 2 probed providers, got %v", statuses)
+	}
+	healthy, unreachable := statuses[0], statuses[1]
+	if healthy.Provider != "healthy" || !healthy.Available || healthy.Successes != 2 || healthy.Failures != 0 {
+		t.Fatalf("Unexpected healthy provider status: %+v", healthy)
+	}
+	if unreachable.Provider != "unreachable" || unreachable.Available || unreachable.Failures != 2 || unreachable.LastError == "" {
+		t.Fatalf("Unexpected unreachable provider status: %+v", unreachable)
+	}
+	if count := testutil.ToFloat64(prober.results.WithLabelValues("healthy", "success")); count != 2 {
+		t.Fatalf("Expected 2 successful probes in metrics, got %v", count)
+	}
+	if count := testutil.CollectAndCount(prober.latency); count != 2 {
+		t.Fatalf("Expected set and get latency series, got %d", count)
+	}
+}
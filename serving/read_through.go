@@ -0,0 +1,51 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package serving
+
+import (
+	"context"
+
+	"github.com/featureform/metadata"
+	"github.com/featureform/provider"
+	pt "github.com/featureform/provider/provider_type"
+)
+
+// withReadThrough wraps the feature's online store so that missing entities
+// are read from its source's offline store. If the offline store can't be
+// used, the online store is returned unchanged and misses are served as
+// usual.
+func (serv *FeatureServer) withReadThrough(ctx context.Context, meta *metadata.FeatureVariant, store provider.OnlineStore) provider.OnlineStore {
+	logger := serv.Logger.With("Name", meta.Name(), "Variant", meta.Variant())
+	source, err := meta.FetchSource(serv.Metadata, ctx)
+	if err != nil {
+		logger.Warnw("read-through disabled: fetching source failed", "Error", err)
+		return store
+	}
+	providerEntry, err := source.FetchProvider(serv.Metadata, ctx)
+	if err != nil {
+		logger.Warnw("read-through disabled: fetching offline provider failed", "Error", err)
+		return store
+	}
+	if err := providerEntry.CheckTLSPolicy(); err != nil {
+		logger.Warnw("read-through disabled: offline provider violates TLS policy", "Error", err)
+		return store
+	}
+	p, err := provider.Get(pt.Type(providerEntry.Type()), providerEntry.SerializedConfig())
+	if err != nil {
+		logger.Warnw("read-through disabled: failed to get offline provider", "Error", err)
+		return store
+	}
+	offline, err := p.AsOfflineStore()
+	if err != nil {
+		logger.Warnw("read-through disabled: source provider isn't an offline store", "Error", err)
+		return store
+	}
+	readThrough, err := provider.NewReadThroughStore(store, offline)
+	if err != nil {
+		logger.Warnw("read-through disabled", "Error", err)
+		return store
+	}
+	return readThrough
+}
@@ -6,6 +6,7 @@ package serving
 
 import (
 	"fmt"
+	"reflect"
 	"time"
 
 	"github.com/featureform/metadata"
@@ -161,7 +162,14 @@ func wrapValue(value interface{}) (proto *pb.Value, err error) {
 	case []float32:
 		proto = wrapVec32(typed)
 	default:
-		err = InvalidValue{value}
+		switch reflect.TypeOf(value).Kind() {
+		case reflect.Map:
+			proto, err = wrapMap(value)
+		case reflect.Slice, reflect.Array:
+			proto, err = wrapList(value)
+		default:
+			err = InvalidValue{value}
+		}
 	}
 	return
 }
@@ -229,3 +237,50 @@ func wrapVec32(val []float32) *pb.Value {
 		},
 	}
 }
+
+// wrapMap wraps maps keyed by strings, such as the map[string]float64 values
+// of MapType features. Each element is wrapped as its own Value.
+func wrapMap(val interface{}) (*pb.Value, error) {
+	mapVal := reflect.ValueOf(val)
+	if mapVal.Type().Key().Kind() != reflect.String {
+		return nil, InvalidValue{val}
+	}
+	values := make(map[string]*pb.Value, mapVal.Len())
+	iter := mapVal.MapRange()
+	for iter.Next() {
+		wrapped, err := wrapValue(iter.Value().Interface())
+		if err != nil {
+			return nil, err
+		}
+		values[iter.Key().String()] = wrapped
+	}
+	return &pb.Value{
+		Value: &pb.Value_MapValue{
+			MapValue: &pb.MapValue{
+				Values: values,
+			},
+		},
+	}, nil
+}
+
+// wrapList wraps slices other than []float32, which are served as vectors,
+// such as the []int64 and []string values of list features. Each element is
+// wrapped as its own Value.
+func wrapList(val interface{}) (*pb.Value, error) {
+	listVal := reflect.ValueOf(val)
+	values := make([]*pb.Value, listVal.Len())
+	for i := range values {
+		wrapped, err := wrapValue(listVal.Index(i).Interface())
+		if err != nil {
+			return nil, err
+		}
+		values[i] = wrapped
+	}
+	return &pb.Value{
+		Value: &pb.Value_ListValue{
+			ListValue: &pb.ListValue{
+				Values: values,
+			},
+		},
+	}, nil
+}
@@ -7,9 +7,12 @@ package serving
 import (
 	"context"
 	"fmt"
+	"time"
 
 	"github.com/pkg/errors"
+	"google.golang.org/protobuf/types/known/timestamppb"
 
+	cfg "github.com/featureform/config"
 	"github.com/featureform/metadata"
 	"github.com/featureform/metrics"
 	pb "github.com/featureform/proto"
@@ -24,6 +27,7 @@ type FeatureServer struct {
 	Metrics  metrics.MetricsHandler
 	Metadata *metadata.Client
 	Logger   *zap.SugaredLogger
+	Auditor  *AccessAuditor
 }
 
 func NewFeatureServer(meta *metadata.Client, promMetrics metrics.MetricsHandler, logger *zap.SugaredLogger) (*FeatureServer, error) {
@@ -136,6 +140,9 @@ func (serv *FeatureServer) getTrainingSetIterator(name, variant string) (provide
 	if err != nil {
 		return nil, errors.Wrap(err, "could not get fetch provider")
 	}
+	if err := providerEntry.CheckTLSPolicy(); err != nil {
+		return nil, err
+	}
 	p, err := provider.Get(pt.Type(providerEntry.Type()), providerEntry.SerializedConfig())
 	if err != nil {
 		return nil, errors.Wrap(err, "could not get provider")
@@ -162,6 +169,9 @@ func (serv *FeatureServer) getSourceDataIterator(name, variant string, limit int
 	if err != nil {
 		return nil, errors.Wrap(err, "could not get fetch provider")
 	}
+	if err := providerEntry.CheckTLSPolicy(); err != nil {
+		return nil, err
+	}
 	p, err := provider.Get(pt.Type(providerEntry.Type()), providerEntry.SerializedConfig())
 	if err != nil {
 		return nil, errors.Wrap(err, "could not get provider")
@@ -209,21 +219,39 @@ func (serv *FeatureServer) FeatureServe(ctx context.Context, req *pb.FeatureServ
 		}
 	}
 	vals := make([]*pb.Value, len(features))
+	lastUpdated := make([]*timestamppb.Timestamp, len(features))
+	isDefault := make([]bool, len(features))
 	for i, feature := range req.GetFeatures() {
 		name, variant := feature.GetName(), feature.GetVersion()
 		serv.Logger.Infow("Serving feature", "Name", name, "Variant", variant)
-		val, err := serv.getFeatureValue(ctx, name, variant, entityMap)
+		served, err := serv.getFeatureValue(ctx, name, variant, entityMap)
 		if err != nil {
 			return nil, errors.Wrap(err, "could not get feature value")
 		}
-		vals[i] = val
+		vals[i] = served.Value
+		lastUpdated[i] = &timestamppb.Timestamp{}
+		if !served.LastUpdated.IsZero() {
+			lastUpdated[i] = timestamppb.New(served.LastUpdated)
+		}
+		isDefault[i] = served.IsDefault
 	}
 	return &pb.FeatureRow{
-		Values: vals,
+		Values:      vals,
+		LastUpdated: lastUpdated,
+		IsDefault:   isDefault,
 	}, nil
 }
 
-func (serv *FeatureServer) getFeatureValue(ctx context.Context, name, variant string, entityMap map[string]string) (*pb.Value, error) {
+// servedValue is a feature value along with when it was last written. The
+// time is zero if the provider doesn't record write times, the feature is
+// computed by the client, or the feature's default value was served.
+type servedValue struct {
+	Value       *pb.Value
+	LastUpdated time.Time
+	IsDefault   bool
+}
+
+func (serv *FeatureServer) getFeatureValue(ctx context.Context, name, variant string, entityMap map[string]string) (*servedValue, error) {
 	obs := serv.Metrics.BeginObservingOnlineServe(name, variant)
 	defer obs.Finish()
 	logger := serv.Logger.With("Name", name, "Variant", variant)
@@ -234,8 +262,15 @@ func (serv *FeatureServer) getFeatureValue(ctx context.Context, name, variant st
 		obs.SetError()
 		return nil, err
 	}
+	if err := checkApproval(meta, cfg.GetTunables().RequireFeatureApproval); err != nil {
+		logger.Errorw("feature not approved for serving", "Error", err)
+		obs.SetError()
+		return nil, err
+	}
 
 	var val interface{}
+	var lastUpdated time.Time
+	var isDefault bool
 	switch meta.Mode() {
 	case metadata.PRECOMPUTED:
 		entity, has := entityMap[meta.Entity()]
@@ -244,12 +279,23 @@ func (serv *FeatureServer) getFeatureValue(ctx context.Context, name, variant st
 			obs.SetError()
 			return nil, fmt.Errorf("No value for entity %s", meta.Entity())
 		}
+		entity, err := serv.normalizeEntityKey(ctx, meta.Entity(), entity)
+		if err != nil {
+			logger.Errorw("invalid entity key", "Entity", meta.Entity(), "Error", err)
+			obs.SetError()
+			return nil, err
+		}
 		providerEntry, err := meta.FetchProvider(serv.Metadata, ctx)
 		if err != nil {
 			logger.Errorw("fetching provider metadata failed", "Error", err)
 			obs.SetError()
 			return nil, err
 		}
+		if err := providerEntry.CheckTLSPolicy(); err != nil {
+			logger.Errorw("provider violates TLS policy", "Error", err)
+			obs.SetError()
+			return nil, err
+		}
 		p, err := provider.Get(pt.Type(providerEntry.Type()), providerEntry.SerializedConfig())
 		if err != nil {
 			logger.Errorw("failed to get provider", "Error", err)
@@ -264,18 +310,45 @@ func (serv *FeatureServer) getFeatureValue(ctx context.Context, name, variant st
 			// That shouldn't be possible.
 			return nil, err
 		}
+		if cfg.GetTunables().OnlineReadThrough {
+			store = serv.withReadThrough(ctx, meta, store)
+		}
 		table, err := store.GetTable(name, variant)
 		if err != nil {
 			logger.Errorw("feature not found", "Error", err)
 			obs.SetError()
 			return nil, err
 		}
-		val, err = table.Get(entity)
+		if timestamped, ok := table.(provider.TimestampedTable); ok {
+			val, lastUpdated, err = timestamped.GetWithTimestamp(entity)
+		} else {
+			val, err = table.Get(entity)
+		}
+		if _, notFound := err.(*provider.EntityNotFound); notFound {
+			val, isDefault, err = defaultOrNotFound(meta, err)
+		}
 		if err != nil {
 			logger.Errorw("entity not found", "Error", err)
 			obs.SetError()
 			return nil, err
 		}
+		sla, err := freshnessSLA(meta.Properties())
+		if err != nil {
+			logger.Errorw("invalid freshness SLA", "Error", err)
+			obs.SetError()
+			return nil, err
+		}
+		if sla != 0 && lastUpdated.IsZero() {
+			logger.Warnw("freshness SLA cannot be enforced without write times", "Entity", entity)
+		}
+		if err := checkFreshness(name, variant, entity, sla, lastUpdated, time.Now()); err != nil {
+			logger.Errorw("stale feature", "Error", err)
+			obs.SetError()
+			return nil, err
+		}
+		if err := serv.Auditor.Observe(ctx, name, variant, meta.Tags(), entity); err != nil {
+			logger.Errorw("failed to record access audit", "Error", err)
+		}
 	case metadata.CLIENT_COMPUTED:
 		val = meta.LocationFunction()
 	default:
@@ -288,7 +361,7 @@ func (serv *FeatureServer) getFeatureValue(ctx context.Context, name, variant st
 		return nil, err
 	}
 	obs.ServeRow()
-	return f.Serialized(), nil
+	return &servedValue{Value: f.Serialized(), LastUpdated: lastUpdated, IsDefault: isDefault}, nil
 }
 
 func (serv *FeatureServer) SourceColumns(ctx context.Context, req *pb.SourceColumnRequest) (*pb.SourceDataColumns, error) {
@@ -320,17 +393,29 @@ func (serv *FeatureServer) Nearest(ctx context.Context, req *pb.NearestRequest)
 		return nil, err
 	}
 	searchVector := req.GetVector()
+	entity := req.GetEntity()
 	k := req.GetK()
-	if searchVector == nil {
-		return nil, fmt.Errorf("no embedding provided")
+	var results []provider.NearestResult
+	if entity != "" {
+		results, err = provider.NearestByEntity(vectorTable, name, variant, entity, k)
+	} else if searchVector == nil {
+		return nil, fmt.Errorf("no embedding or entity provided")
+	} else {
+		results, err = vectorTable.Nearest(name, variant, searchVector.Value, k, provider.VectorFilter{})
 	}
-	entities, err := vectorTable.Nearest(name, variant, searchVector.Value, k)
 	if err != nil {
 		serv.Logger.Errorw("nearest search failed", "Error", err)
 		return nil, err
 	}
+	entities := make([]string, len(results))
+	distances := make([]float32, len(results))
+	for i, result := range results {
+		entities[i] = result.Entity
+		distances[i] = result.Distance
+	}
 	return &pb.NearestResponse{
-		Entities: entities,
+		Entities:  entities,
+		Distances: distances,
 	}, nil
 }
 
@@ -340,6 +425,10 @@ func (serv *FeatureServer) getVectorTable(ctx context.Context, fv *metadata.Feat
 		serv.Logger.Errorw("fetching provider metadata failed", "Error", err)
 		return nil, err
 	}
+	if err := providerEntry.CheckTLSPolicy(); err != nil {
+		serv.Logger.Errorw("provider violates TLS policy", "Error", err)
+		return nil, err
+	}
 	p, err := provider.Get(pt.Type(providerEntry.Type()), providerEntry.SerializedConfig())
 	if err != nil {
 		serv.Logger.Errorw("failed to get provider", "Error", err)
@@ -350,6 +439,11 @@ func (serv *FeatureServer) getVectorTable(ctx context.Context, fv *metadata.Feat
 		serv.Logger.Errorw("failed to use provider as online store for feature", "Error", err)
 		return nil, err
 	}
+	if !store.Capabilities().Vectors {
+		err := fmt.Errorf("%s does not support vector search", store.Type())
+		serv.Logger.Errorw("failed to use provider as vector store", "Error", err)
+		return nil, err
+	}
 	table, err := store.GetTable(fv.Name(), fv.Variant())
 	if err != nil {
 		serv.Logger.Errorw("feature not found", "Error", err)
@@ -357,7 +451,9 @@ func (serv *FeatureServer) getVectorTable(ctx context.Context, fv *metadata.Feat
 	}
 	vectorTable, ok := table.(provider.VectorStoreTable)
 	if !ok {
+		err := fmt.Errorf("table %s %s is not a vector index", fv.Name(), fv.Variant())
 		serv.Logger.Errorw("failed to use table as vector store table", "Error", err)
+		return nil, err
 	}
 	return vectorTable, nil
 }
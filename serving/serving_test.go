@@ -7,11 +7,15 @@ package serving
 import (
 	"bytes"
 	"context"
+	"errors"
 	"fmt"
+	"math"
 	"math/rand"
 	"net"
 	"reflect"
+	"sort"
 	"testing"
+	"time"
 
 	"github.com/google/uuid"
 	"go.uber.org/zap"
@@ -75,7 +79,7 @@ func invalidFeatureRecords() map[provider.ResourceID][]provider.ResourceRecord {
 		Type:    provider.Feature,
 	}
 	featureRecs := []provider.ResourceRecord{
-		{Entity: "a", Value: make([]string, 0)},
+		{Entity: "a", Value: struct{}{}},
 	}
 	labelId := provider.ResourceID{
 		Name:    "label",
@@ -106,7 +110,7 @@ func invalidLabelRecords() map[provider.ResourceID][]provider.ResourceRecord {
 		Type:    provider.Label,
 	}
 	labelRecs := []provider.ResourceRecord{
-		{Entity: "a", Value: make([]string, 0)},
+		{Entity: "a", Value: struct{}{}},
 	}
 	return map[provider.ResourceID][]provider.ResourceRecord{
 		featureId: featureRecs,
@@ -121,7 +125,7 @@ func invalidTypeFeatureRecords() map[provider.ResourceID][]provider.ResourceReco
 		Type:    provider.Feature,
 	}
 	recs := []provider.ResourceRecord{
-		{Entity: "a", Value: make([]string, 0)},
+		{Entity: "a", Value: struct{}{}},
 	}
 	return map[provider.ResourceID][]provider.ResourceRecord{
 		id: recs,
@@ -489,6 +493,79 @@ func createMockOnlineStoreFactory(recsMap map[provider.ResourceID][]provider.Res
 	}
 }
 
+// mockVectorStore is an online store whose tables search their vectors by
+// brute force, since none of the in-memory stores support vector search.
+type mockVectorStore struct {
+	provider.OnlineStore
+	table *mockVectorTable
+}
+
+func (store *mockVectorStore) AsOnlineStore() (provider.OnlineStore, error) {
+	return store, nil
+}
+
+func (store *mockVectorStore) GetTable(feature, variant string) (provider.OnlineStoreTable, error) {
+	return store.table, nil
+}
+
+func (store *mockVectorStore) Capabilities() provider.OnlineCapabilities {
+	return provider.OnlineCapabilities{Vectors: true}
+}
+
+type mockVectorTable struct {
+	vectors map[string][]float32
+}
+
+func (table *mockVectorTable) Set(entity string, value interface{}) error {
+	table.vectors[entity] = value.([]float32)
+	return nil
+}
+
+func (table *mockVectorTable) Get(entity string) (interface{}, error) {
+	vector, has := table.vectors[entity]
+	if !has {
+		return nil, &provider.EntityNotFound{Entity: entity}
+	}
+	return vector, nil
+}
+
+func (table *mockVectorTable) Nearest(feature, variant string, vector []float32, k int32, filter provider.VectorFilter) ([]provider.NearestResult, error) {
+	results := make([]provider.NearestResult, 0, len(table.vectors))
+	for entity, other := range table.vectors {
+		var sum float64
+		for i := range vector {
+			diff := float64(vector[i] - other[i])
+			sum += diff * diff
+		}
+		results = append(results, provider.NearestResult{Entity: entity, Distance: float32(math.Sqrt(sum))})
+	}
+	sort.Slice(results, func(i, j int) bool {
+		return results[i].Distance < results[j].Distance
+	})
+	if int32(len(results)) > k {
+		results = results[:k]
+	}
+	return results, nil
+}
+
+func (table *mockVectorTable) NearestPage(feature, variant string, vector []float32, pageSize int32, filter provider.VectorFilter, cursor string) (provider.NearestPage, error) {
+	return provider.NearestPage{}, fmt.Errorf("mock vector table does not support pages")
+}
+
+func (table *mockVectorTable) Delete(entity string) error {
+	delete(table.vectors, entity)
+	return nil
+}
+
+func createMockVectorStoreFactory(vectors map[string][]float32) provider.Factory {
+	return func(cfg pc.SerializedConfig) (provider.Provider, error) {
+		return &mockVectorStore{
+			OnlineStore: provider.NewLocalOnlineStore(),
+			table:       &mockVectorTable{vectors: vectors},
+		}, nil
+	}
+}
+
 func createMockOfflineStoreFactory(recsMap map[provider.ResourceID][]provider.ResourceRecord, defs []provider.TrainingSetDef) provider.Factory {
 	return func(cfg pc.SerializedConfig) (provider.Provider, error) {
 		store := provider.NewMemoryOfflineStore()
@@ -570,6 +647,12 @@ func unwrapVal(val *pb.Value) interface{} {
 		return casted.BoolValue
 	case *pb.Value_OnDemandFunction:
 		return casted.OnDemandFunction
+	case *pb.Value_ListValue:
+		list := make([]interface{}, len(casted.ListValue.Values))
+		for i, elem := range casted.ListValue.Values {
+			list[i] = unwrapVal(elem)
+		}
+		return list
 	default:
 		panic(fmt.Sprintf("Unable to unwrap value: %T", val.Value))
 	}
@@ -608,6 +691,12 @@ func TestFeatureServe(t *testing.T) {
 	if dblVal != 12.5 {
 		t.Fatalf("Wrong feature value: %v\nExpected: %v", dblVal, 12.5)
 	}
+	if len(resp.LastUpdated) != len(vals) {
+		t.Fatalf("Wrong number of write times: %d\nExpected: %d", len(resp.LastUpdated), len(vals))
+	}
+	if updated := resp.LastUpdated[0].AsTime(); updated.Unix() == 0 || updated.After(time.Now()) {
+		t.Fatalf("Invalid write time: %v", updated)
+	}
 }
 
 func TestFeatureNotFound(t *testing.T) {
@@ -740,6 +829,116 @@ func TestEntityNotFoundInOnlineStore(t *testing.T) {
 	}
 }
 
+func defaultValueResourceDefsFn(defaultValue string) func(string) []metadata.ResourceDef {
+	return func(providerType string) []metadata.ResourceDef {
+		defs := simpleResourceDefsFn(providerType)
+		for i, def := range defs {
+			if feature, ok := def.(metadata.FeatureDef); ok {
+				feature.Type = "float64"
+				feature.Properties = metadata.Properties{DefaultValueProperty: defaultValue}
+				defs[i] = feature
+			}
+		}
+		return defs
+	}
+}
+
+func TestEntityNotFoundDefaultValue(t *testing.T) {
+	ctx := onlineTestContext{
+		ResourceDefsFn: defaultValueResourceDefsFn("-1.5"),
+		FactoryFn:      createMockOnlineStoreFactory(simpleFeatureRecords()),
+	}
+	serv := ctx.Create(t)
+	defer ctx.Destroy()
+	for entity, expected := range map[string]struct {
+		val       interface{}
+		isDefault bool
+	}{
+		"a":                 {12.5, false},
+		"NonExistantEntity": {-1.5, true},
+	} {
+		req := &pb.FeatureServeRequest{
+			Features: []*pb.FeatureID{
+				&pb.FeatureID{
+					Name:    "feature",
+					Version: "variant",
+				},
+			},
+			Entities: []*pb.Entity{
+				&pb.Entity{
+					Name:  "mockEntity",
+					Value: entity,
+				},
+			},
+		}
+		resp, err := serv.FeatureServe(context.Background(), req)
+		if err != nil {
+			t.Fatalf("Failed to serve feature for %s: %s", entity, err)
+		}
+		if val := unwrapVal(resp.Values[0]); val != expected.val {
+			t.Fatalf("Wrong feature value for %s: %v\nExpected: %v", entity, val, expected.val)
+		}
+		if resp.IsDefault[0] != expected.isDefault {
+			t.Fatalf("Wrong default flag for %s: %v\nExpected: %v", entity, resp.IsDefault[0], expected.isDefault)
+		}
+	}
+}
+
+func TestInvalidDefaultValue(t *testing.T) {
+	ctx := onlineTestContext{
+		ResourceDefsFn: defaultValueResourceDefsFn("not a float"),
+		FactoryFn:      createMockOnlineStoreFactory(simpleFeatureRecords()),
+	}
+	serv := ctx.Create(t)
+	defer ctx.Destroy()
+	req := &pb.FeatureServeRequest{
+		Features: []*pb.FeatureID{
+			&pb.FeatureID{
+				Name:    "feature",
+				Version: "variant",
+			},
+		},
+		Entities: []*pb.Entity{
+			&pb.Entity{
+				Name:  "mockEntity",
+				Value: "NonExistantEntity",
+			},
+		},
+	}
+	if _, err := serv.FeatureServe(context.Background(), req); err == nil {
+		t.Fatalf("Succeeded in serving feature with an invalid default value")
+	}
+}
+
+func TestUnapprovedFeatureNotServed(t *testing.T) {
+	t.Setenv("REQUIRE_FEATURE_APPROVAL", "true")
+	ctx := onlineTestContext{
+		ResourceDefsFn: simpleResourceDefsFn,
+		FactoryFn:      createMockOnlineStoreFactory(simpleFeatureRecords()),
+	}
+	serv := ctx.Create(t)
+	defer ctx.Destroy()
+	req := &pb.FeatureServeRequest{
+		Features: []*pb.FeatureID{
+			&pb.FeatureID{
+				Name:    "feature",
+				Version: "variant",
+			},
+		},
+		Entities: []*pb.Entity{
+			&pb.Entity{
+				Name:  "mockEntity",
+				Value: "a",
+			},
+		},
+	}
+	_, err := serv.FeatureServe(context.Background(), req)
+	var notApproved *FeatureNotApproved
+	if !errors.As(err, &notApproved) {
+		t.Fatalf("Expected FeatureNotApproved, got: %v", err)
+	}
+}
+
 func TestEntityNotInRequest(t *testing.T) {
 	ctx := onlineTestContext{
 		ResourceDefsFn: simpleResourceDefsFn,
@@ -859,6 +1058,162 @@ func TestAllFeatureTypes(t *testing.T) {
 	}
 }
 
+func TestMapFeatureValue(t *testing.T) {
+	wrapped, err := wrapValue(map[string]float64{"books": 0.5, "games": 1.25})
+	if err != nil {
+		t.Fatalf("Failed to wrap map value: %s", err)
+	}
+	mapVal, ok := wrapped.Value.(*pb.Value_MapValue)
+	if !ok {
+		t.Fatalf("Expected map value, got %T", wrapped.Value)
+	}
+	actual := make(map[string]interface{})
+	for key, val := range mapVal.MapValue.Values {
+		actual[key] = unwrapVal(val)
+	}
+	expected := map[string]interface{}{"books": 0.5, "games": 1.25}
+	if !reflect.DeepEqual(actual, expected) {
+		t.Fatalf("Values not equal %v %v", actual, expected)
+	}
+	if _, err := wrapValue(map[int]string{1: "a"}); err == nil {
+		t.Fatalf("Succeeded in wrapping map with non-string keys")
+	}
+	if _, err := wrapValue(map[string]struct{}{"a": {}}); err == nil {
+		t.Fatalf("Succeeded in wrapping map with invalid element type")
+	}
+}
+
+func TestListFeatureValue(t *testing.T) {
+	wrapped, err := wrapValue([]string{"books", "games"})
+	if err != nil {
+		t.Fatalf("Failed to wrap list value: %s", err)
+	}
+	expected := []interface{}{"books", "games"}
+	if actual := unwrapVal(wrapped); !reflect.DeepEqual(actual, expected) {
+		t.Fatalf("Values not equal %v %v", actual, expected)
+	}
+	if _, err := wrapValue([]struct{}{{}}); err == nil {
+		t.Fatalf("Succeeded in wrapping list with invalid element type")
+	}
+}
+
+func TestListFeatureServe(t *testing.T) {
+	featureId := provider.ResourceID{
+		Name:    "feature",
+		Variant: "variant",
+		Type:    provider.Feature,
+	}
+	recs := map[provider.ResourceID][]provider.ResourceRecord{
+		featureId: {
+			{Entity: "a", Value: []int64{1, 2, 3}},
+		},
+	}
+	ctx := onlineTestContext{
+		ResourceDefsFn: simpleResourceDefsFn,
+		FactoryFn:      createMockOnlineStoreFactory(recs),
+	}
+	serv := ctx.Create(t)
+	defer ctx.Destroy()
+	req := &pb.FeatureServeRequest{
+		Features: []*pb.FeatureID{
+			{
+				Name:    "feature",
+				Version: "variant",
+			},
+		},
+		Entities: []*pb.Entity{
+			{
+				Name:  "mockEntity",
+				Value: "a",
+			},
+		},
+	}
+	resp, err := serv.FeatureServe(context.Background(), req)
+	if err != nil {
+		t.Fatalf("Failed to serve feature: %s", err)
+	}
+	if len(resp.Values) != 1 {
+		t.Fatalf("Wrong number of values: %d\nExpected: %d", len(resp.Values), 1)
+	}
+	if _, ok := resp.Values[0].Value.(*pb.Value_ListValue); !ok {
+		t.Fatalf("Expected list value, got %T", resp.Values[0].Value)
+	}
+	expected := []interface{}{int64(1), int64(2), int64(3)}
+	if actual := unwrapVal(resp.Values[0]); !reflect.DeepEqual(actual, expected) {
+		t.Fatalf("Wrong feature value: %v\nExpected: %v", actual, expected)
+	}
+}
+
+func nearestVectors() map[string][]float32 {
+	return map[string][]float32{
+		"a": {0, 0},
+		"b": {3, 4},
+		"c": {9, 12},
+	}
+}
+
+func TestNearest(t *testing.T) {
+	ctx := onlineTestContext{
+		ResourceDefsFn: simpleResourceDefsFn,
+		FactoryFn:      createMockVectorStoreFactory(nearestVectors()),
+	}
+	serv := ctx.Create(t)
+	defer ctx.Destroy()
+	req := &pb.NearestRequest{
+		Id: &pb.FeatureID{
+			Name:    "feature",
+			Version: "variant",
+		},
+		Vector: &pb.Vector32{Value: []float32{0, 0}},
+		K:      2,
+	}
+	resp, err := serv.Nearest(context.Background(), req)
+	if err != nil {
+		t.Fatalf("Failed to search nearest: %s", err)
+	}
+	expectedEntities := []string{"a", "b"}
+	if !reflect.DeepEqual(resp.Entities, expectedEntities) {
+		t.Fatalf("Wrong entities: %v\nExpected: %v", resp.Entities, expectedEntities)
+	}
+	expectedDistances := []float32{0, 5}
+	if !reflect.DeepEqual(resp.Distances, expectedDistances) {
+		t.Fatalf("Wrong distances: %v\nExpected: %v", resp.Distances, expectedDistances)
+	}
+}
+
+func TestNearestByEntity(t *testing.T) {
+	ctx := onlineTestContext{
+		ResourceDefsFn: simpleResourceDefsFn,
+		FactoryFn:      createMockVectorStoreFactory(nearestVectors()),
+	}
+	serv := ctx.Create(t)
+	defer ctx.Destroy()
+	req := &pb.NearestRequest{
+		Id: &pb.FeatureID{
+			Name:    "feature",
+			Version: "variant",
+		},
+		Entity: "b",
+		K:      1,
+	}
+	resp, err := serv.Nearest(context.Background(), req)
+	if err != nil {
+		t.Fatalf("Failed to search nearest: %s", err)
+	}
+	expectedEntities := []string{"a"}
+	if !reflect.DeepEqual(resp.Entities, expectedEntities) {
+		t.Fatalf("Wrong entities: %v\nExpected: %v", resp.Entities, expectedEntities)
+	}
+	expectedDistances := []float32{5}
+	if !reflect.DeepEqual(resp.Distances, expectedDistances) {
+		t.Fatalf("Wrong distances: %v\nExpected: %v", resp.Distances, expectedDistances)
+	}
+	req.Entity = "missing"
+	if _, err := serv.Nearest(context.Background(), req); err == nil {
+		t.Fatalf("Succeeded in searching near a missing entity")
+	}
+}
+
 func TestSimpleModelRegistrationFeatureServe(t *testing.T) {
 	ctx := onlineTestContext{
 		ResourceDefsFn: simpleResourceDefsFn,
@@ -1290,3 +1645,43 @@ func TestTrainingDataColumns(t *testing.T) {
 		t.Fatalf("Columns aren't equal: %v\n%v", expectedColumns, resp)
 	}
 }
+
+func TestEntityKeyNormalization(t *testing.T) {
+	ctx := onlineTestContext{
+		ResourceDefsFn: func(providerType string) []metadata.ResourceDef {
+			defs := simpleResourceDefsFn(providerType)
+			for i, def := range defs {
+				if entity, ok := def.(metadata.EntityDef); ok {
+					entity.Properties = metadata.Properties{
+						metadata.EntityKeyTrimProperty:      "true",
+						metadata.EntityKeyLowercaseProperty: "true",
+						metadata.EntityKeyMaxLengthProperty: "4",
+					}
+					defs[i] = entity
+				}
+			}
+			return defs
+		},
+		FactoryFn: createMockOnlineStoreFactory(simpleFeatureRecords()),
+	}
+	serv := ctx.Create(t)
+	defer ctx.Destroy()
+	serve := func(entity string) (*pb.FeatureRow, error) {
+		return serv.FeatureServe(context.Background(), &pb.FeatureServeRequest{
+			Features: []*pb.FeatureID{{Name: "feature", Version: "variant"}},
+			Entities: []*pb.Entity{{Name: "mockEntity", Value: entity}},
+		})
+	}
+	resp, err := serve(" A ")
+	if err != nil {
+		t.Fatalf("Failed to serve feature: %s", err)
+	}
+	if val := unwrapVal(resp.Values[0]); val != 12.5 {
+		t.Fatalf("Wrong feature value: %v\nExpected: %v", val, 12.5)
+	}
+	_, err = serve("too long")
+	var invalid *metadata.InvalidEntityKey
+	if !errors.As(err, &invalid) {
+		t.Fatalf("Expected invalid entity key to be rejected, got %v", err)
+	}
+}
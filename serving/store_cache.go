@@ -0,0 +1,83 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package serving
+
+import (
+	"bytes"
+	"sync"
+
+	"go.uber.org/zap"
+
+	"github.com/featureform/metadata"
+	"github.com/featureform/provider"
+	pt "github.com/featureform/provider/provider_type"
+)
+
+type cachedStore struct {
+	config []byte
+	store  provider.OnlineStore
+}
+
+// onlineStoreCache keeps one connection per online provider for background
+// jobs that visit every provider periodically.
+type onlineStoreCache struct {
+	logger *zap.SugaredLogger
+	stores map[string]cachedStore
+	mu     sync.Mutex
+}
+
+func newOnlineStoreCache(logger *zap.SugaredLogger) *onlineStoreCache {
+	return &onlineStoreCache{
+		logger: logger,
+		stores: make(map[string]cachedStore),
+	}
+}
+
+// get returns the cached store of the provider, or nil if the provider isn't
+// an online store. The store is recreated if the provider's config has
+// changed.
+func (c *onlineStoreCache) get(entry *metadata.Provider) (provider.OnlineStore, error) {
+	if err := entry.CheckTLSPolicy(); err != nil {
+		return nil, err
+	}
+	config := entry.SerializedConfig()
+	c.mu.Lock()
+	cached, has := c.stores[entry.Name()]
+	c.mu.Unlock()
+	if has && bytes.Equal(cached.config, config) {
+		return cached.store, nil
+	}
+	prov, err := provider.Get(pt.Type(entry.Type()), config)
+	if err != nil {
+		return nil, err
+	}
+	store, err := prov.AsOnlineStore()
+	if err != nil {
+		store = nil
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if has && cached.store != nil {
+		if err := cached.store.Close(); err != nil {
+			c.logger.Errorw("Failed to close online store", "Provider", entry.Name(), "Error", err)
+		}
+	}
+	c.stores[entry.Name()] = cachedStore{config: config, store: store}
+	return store, nil
+}
+
+func (c *onlineStoreCache) close() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for name, cached := range c.stores {
+		if cached.store == nil {
+			continue
+		}
+		if err := cached.store.Close(); err != nil {
+			c.logger.Errorw("Failed to close online store", "Provider", name, "Error", err)
+		}
+	}
+	c.stores = make(map[string]cachedStore)
+}
@@ -0,0 +1,213 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package serving
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"go.uber.org/zap"
+
+	"github.com/featureform/metadata"
+	"github.com/featureform/provider"
+	pt "github.com/featureform/provider/provider_type"
+)
+
+// TableStatus is the size of an online table at its latest collection, and
+// how much it grew since the collection before that.
+type TableStatus struct {
+	Provider      string    `json:"provider"`
+	Feature       string    `json:"feature"`
+	Variant       string    `json:"variant"`
+	Entities      int64     `json:"entities"`
+	Bytes         int64     `json:"bytes"`
+	LastCollected time.Time `json:"lastCollected"`
+	// EntitiesGrowth and BytesGrowth are the changes since the previous
+	// collection at PreviousCollected. They're zero after the first one.
+	EntitiesGrowth    int64     `json:"entitiesGrowth"`
+	BytesGrowth       int64     `json:"bytesGrowth"`
+	PreviousCollected time.Time `json:"previousCollected,omitempty"`
+}
+
+type tableStatsKey struct {
+	provider, feature, variant string
+}
+
+// TableStatsCollector periodically records the entity count and estimated
+// size of every table in every online provider that can list its tables.
+// Results are exported as Prometheus gauges and served as JSON by ServeHTTP.
+type TableStatsCollector struct {
+	Metadata *metadata.Client
+	Logger   *zap.SugaredLogger
+	entities *prometheus.GaugeVec
+	bytes    *prometheus.GaugeVec
+	stores   *onlineStoreCache
+	status   map[tableStatsKey]*TableStatus
+	now      func() time.Time
+	mu       sync.RWMutex
+}
+
+func NewTableStatsCollector(meta *metadata.Client, registerer prometheus.Registerer, logger *zap.SugaredLogger) (*TableStatsCollector, error) {
+	labels := []string{"provider", "feature", "variant"}
+	entities := prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "online_table_entities",
+			Help: "Number of entities in an online table, labeled by provider, feature and variant",
+		},
+		labels,
+	)
+	bytes := prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "online_table_bytes",
+			Help: "Estimated size of an online table in bytes, labeled by provider, feature and variant",
+		},
+		labels,
+	)
+	for _, collector := range []prometheus.Collector{entities, bytes} {
+		if err := registerer.Register(collector); err != nil {
+			return nil, fmt.Errorf("could not register table stats metrics: %w", err)
+		}
+	}
+	return &TableStatsCollector{
+		Metadata: meta,
+		Logger:   logger,
+		entities: entities,
+		bytes:    bytes,
+		stores:   newOnlineStoreCache(logger),
+		status:   make(map[tableStatsKey]*TableStatus),
+		now:      time.Now,
+	}, nil
+}
+
+// Run collects table stats once per interval until stop is closed.
+func (c *TableStatsCollector) Run(interval time.Duration, stop <-chan struct{}) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	defer c.stores.close()
+	for {
+		ctx, cancel := context.WithTimeout(context.Background(), interval)
+		if err := c.CollectAll(ctx); err != nil {
+			c.Logger.Errorw("Failed to collect table stats", "Error", err)
+		}
+		cancel()
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// CollectAll records the stats of each table in each online provider
+// registered in metadata. Providers and tables that don't support listing or
+// stats are skipped.
+func (c *TableStatsCollector) CollectAll(ctx context.Context) error {
+	providers, err := c.Metadata.ListProviders(ctx)
+	if err != nil {
+		return fmt.Errorf("could not list providers: %w", err)
+	}
+	for _, entry := range providers {
+		if offlineProviderTypes[pt.Type(entry.Type())] {
+			continue
+		}
+		store, err := c.stores.get(entry)
+		if err != nil {
+			c.Logger.Warnw("Failed to connect to online provider", "Provider", entry.Name(), "Error", err)
+			continue
+		}
+		capabilities := store.Capabilities()
+		if !capabilities.ListTables || !capabilities.Stats {
+			continue
+		}
+		listing, ok := store.(provider.TableListingStore)
+		if !ok {
+			continue
+		}
+		if err := c.collect(entry.Name(), listing); err != nil {
+			c.Logger.Warnw("Failed to collect table stats", "Provider", entry.Name(), "Error", err)
+		}
+	}
+	return nil
+}
+
+func (c *TableStatsCollector) collect(name string, store provider.TableListingStore) error {
+	tables, err := store.ListTables()
+	if err != nil {
+		return fmt.Errorf("could not list tables: %w", err)
+	}
+	for _, info := range tables {
+		if info.Feature == probeTableName {
+			continue
+		}
+		table, err := store.GetTable(info.Feature, info.Variant)
+		if err != nil {
+			return fmt.Errorf("could not get table %s %s: %w", info.Feature, info.Variant, err)
+		}
+		statsTable, ok := table.(provider.StatsTable)
+		if !ok {
+			continue
+		}
+		stats, err := statsTable.Stats()
+		if err != nil {
+			return fmt.Errorf("could not get stats of table %s %s: %w", info.Feature, info.Variant, err)
+		}
+		c.record(tableStatsKey{name, info.Feature, info.Variant}, stats)
+	}
+	return nil
+}
+
+func (c *TableStatsCollector) record(key tableStatsKey, stats provider.TableStats) {
+	c.entities.WithLabelValues(key.provider, key.feature, key.variant).Set(float64(stats.Entities))
+	c.bytes.WithLabelValues(key.provider, key.feature, key.variant).Set(float64(stats.Bytes))
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	status, has := c.status[key]
+	if !has {
+		status = &TableStatus{Provider: key.provider, Feature: key.feature, Variant: key.variant}
+		c.status[key] = status
+	} else {
+		status.EntitiesGrowth = stats.Entities - status.Entities
+		status.BytesGrowth = stats.Bytes - status.Bytes
+		status.PreviousCollected = status.LastCollected
+	}
+	status.Entities = stats.Entities
+	status.Bytes = stats.Bytes
+	status.LastCollected = c.now()
+}
+
+// Status returns the stats of every collected table, sorted by provider,
+// feature and variant.
+func (c *TableStatsCollector) Status() []TableStatus {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	statuses := make([]TableStatus, 0, len(c.status))
+	for _, status := range c.status {
+		statuses = append(statuses, *status)
+	}
+	sort.Slice(statuses, func(i, j int) bool {
+		a, b := statuses[i], statuses[j]
+		if a.Provider != b.Provider {
+			return a.Provider < b.Provider
+		}
+		if a.Feature != b.Feature {
+			return a.Feature < b.Feature
+		}
+		return a.Variant < b.Variant
+	})
+	return statuses
+}
+
+func (c *TableStatsCollector) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(c.Status()); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
@@ -0,0 +1,82 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package serving
+
+import (
+	"context"
+	"encoding/json"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"go.uber.org/zap/zaptest"
+
+	"github.com/featureform/metadata"
+	"github.com/featureform/provider"
+	pc "github.com/featureform/provider/provider_config"
+	pt "github.com/featureform/provider/provider_type"
+)
+
+func TestTableStatsCollector(t *testing.T) {
+	store := provider.NewLocalOnlineStore()
+	table, err := store.CreateTable("feature", "variant", provider.String)
+	if err != nil {
+		t.Fatalf("Failed to create table: %s", err)
+	}
+	if err := table.Set("a", "value"); err != nil {
+		t.Fatalf("Failed to set value: %s", err)
+	}
+	if _, err := store.CreateTable(probeTableName, probeTableVariant, provider.String); err != nil {
+		t.Fatalf("Failed to create probe table: %s", err)
+	}
+	ctx := onlineTestContext{
+		ResourceDefsFn: func(providerType string) []metadata.ResourceDef {
+			return []metadata.ResourceDef{
+				metadata.UserDef{Name: "Featureform"},
+				metadata.ProviderDef{Name: "online", Type: providerType},
+				metadata.ProviderDef{Name: "offline", Type: string(pt.MemoryOffline)},
+			}
+		},
+		FactoryFn: func(pc.SerializedConfig) (provider.Provider, error) {
+			return store, nil
+		},
+	}
+	serv := ctx.Create(t)
+	defer ctx.Destroy()
+	collector, err := NewTableStatsCollector(serv.Metadata, prometheus.NewRegistry(), zaptest.NewLogger(t).Sugar())
+	if err != nil {
+		t.Fatalf("Failed to create collector: %s", err)
+	}
+	if err := collector.CollectAll(context.Background()); err != nil {
+		t.Fatalf("Failed to collect stats: %s", err)
+	}
+	if err := table.Set("b", "value"); err != nil {
+		t.Fatalf("Failed to set value: %s", err)
+	}
+	if err := collector.CollectAll(context.Background()); err != nil {
+		t.Fatalf("Failed to collect stats: %s", err)
+	}
+
+	recorder := httptest.NewRecorder()
+	collector.ServeHTTP(recorder, httptest.NewRequest("GET", "/status/tables", nil))
+	var statuses []TableStatus
+	if err := json.Unmarshal(recorder.Body.Bytes(), &statuses); err != nil {
+		t.Fatalf("Failed to parse status: %s", err)
+	}
+	if len(statuses) != 1 {
+		t.Fatalf("Expected only the feature table, got %v", statuses)
+	}
+	status := statuses[0]
+	if status.Provider != "online" || status.Feature != "feature" || status.Entities != 2 || status.EntitiesGrowth != 1 {
+		t.Fatalf("Unexpected table status: %+v", status)
+	}
+	if status.Bytes <= 0 || status.BytesGrowth <= 0 || status.PreviousCollected.IsZero() {
+		t.Fatalf("Expected size and growth to be recorded: %+v", status)
+	}
+	if count := testutil.ToFloat64(collector.entities.WithLabelValues("online", "feature", "variant")); count != 2 {
+		t.Fatalf("Expected 2 entities in metrics, got %v", count)
+	}
+}
@@ -1,6 +1,10 @@
 package types
 
 import (
+	"context"
+	"fmt"
+	"time"
+
 	"github.com/featureform/metadata"
 )
 
@@ -10,6 +14,84 @@ type Runner interface {
 	IsUpdateJob() bool
 }
 
+// ContextRunner is implemented by runners whose jobs can be cancelled. The
+// job stops once ctx is cancelled, and its watcher ends with ctx's error.
+type ContextRunner interface {
+	Runner
+	RunContext(ctx context.Context) (CompletionWatcher, error)
+}
+
+// RunContext runs runner's job until it completes or ctx is cancelled.
+// Runners that can't be cancelled keep running in the background, but the
+// watcher ends with ctx's error regardless.
+func RunContext(ctx context.Context, runner Runner) (CompletionWatcher, error) {
+	if contextRunner, ok := runner.(ContextRunner); ok {
+		return contextRunner.RunContext(ctx)
+	}
+	watcher, err := runner.Run()
+	if err != nil {
+		return nil, err
+	}
+	return WatchContext(ctx, watcher), nil
+}
+
+// WatchContext returns a watcher that ends when watcher does, or with ctx's
+// error once ctx is cancelled.
+func WatchContext(ctx context.Context, watcher CompletionWatcher) CompletionWatcher {
+	w := &contextWatcher{watcher: watcher, done: make(chan struct{})}
+	go func() {
+		err := make(chan error, 1)
+		go func() {
+			err <- watcher.Wait()
+		}()
+		select {
+		case w.err = <-err:
+		case <-ctx.Done():
+			w.err = ctx.Err()
+		}
+		close(w.done)
+	}()
+	return w
+}
+
+type contextWatcher struct {
+	watcher CompletionWatcher
+	done    chan struct{}
+	err     error
+}
+
+func (w *contextWatcher) Complete() bool {
+	select {
+	case <-w.done:
+		return true
+	default:
+		return false
+	}
+}
+
+func (w *contextWatcher) String() string {
+	if w.Complete() && w.err != nil {
+		return fmt.Sprintf("Job failed with error: %v", w.err)
+	}
+	return w.watcher.String()
+}
+
+func (w *contextWatcher) Wait() error {
+	<-w.done
+	return w.err
+}
+
+func (w *contextWatcher) Err() error {
+	if !w.Complete() {
+		return nil
+	}
+	return w.err
+}
+
+func (w *contextWatcher) Progress() Progress {
+	return ProgressOf(w.watcher)
+}
+
 type IndexRunner interface {
 	Runner
 	SetIndex(index int) error
@@ -21,3 +103,51 @@ type CompletionWatcher interface {
 	Wait() error
 	Err() error
 }
+
+// Progress is how far a job has gotten. RowsWritten is zero for jobs that
+// don't count their rows.
+type Progress struct {
+	RowsWritten int64
+	ChunksDone  int64
+	ChunksTotal int64
+	// ETA is the estimated time until the job completes, or zero if it
+	// can't be estimated yet.
+	ETA time.Duration
+}
+
+func (p Progress) String() string {
+	if p.ETA > 0 {
+		return fmt.Sprintf("%d of %d chunks done, %d rows written, %s remaining", p.ChunksDone, p.ChunksTotal, p.RowsWritten, p.ETA.Round(time.Second))
+	}
+	return fmt.Sprintf("%d of %d chunks done, %d rows written", p.ChunksDone, p.ChunksTotal, p.RowsWritten)
+}
+
+// ProgressWatcher is implemented by CompletionWatchers that can report how
+// far their job has gotten.
+type ProgressWatcher interface {
+	CompletionWatcher
+	Progress() Progress
+}
+
+// ProgressOf returns the watcher's progress. Watchers that can't report it
+// are treated as a single chunk, which is done once they're complete.
+func ProgressOf(watcher CompletionWatcher) Progress {
+	if progressWatcher, ok := watcher.(ProgressWatcher); ok {
+		return progressWatcher.Progress()
+	}
+	progress := Progress{ChunksTotal: 1}
+	if watcher.Complete() {
+		progress.ChunksDone = 1
+	}
+	return progress
+}
+
+// EstimateETA estimates the time left of a job that started at started, from
+// the rate it's completed chunks at so far.
+func EstimateETA(started time.Time, chunksDone, chunksTotal int64) time.Duration {
+	if chunksDone <= 0 || chunksDone >= chunksTotal {
+		return 0
+	}
+	elapsed := time.Since(started)
+	return time.Duration(float64(elapsed) * float64(chunksTotal-chunksDone) / float64(chunksDone))
+}